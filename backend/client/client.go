@@ -0,0 +1,213 @@
+// Package client は、Simple Notion の API の公式 Go クライアントです
+// エンドポイントごとのメソッド（client_gen.go）は、ルートの登録内容から生成した OpenAPI の仕様から go generate で生成します
+// 仕様にはリクエスト・レスポンスの本文の型が含まれないため、本文は呼び出し側の構造体（または map）で送受信します
+package client
+
+//go:generate go run ../cmd/sdkgen -spec openapi.json -go client_gen.go -package client -ts ../../frontend/src/lib/types/api.gen.ts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Client は、API のベース URL とログイン中のトークンを保持するクライアントです（複数の goroutine から使えます）
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option は、NewClient の設定です
+type Option func(*Client)
+
+// WithHTTPClient は、リクエストに使う http.Client を指定します（タイムアウト・プロキシの設定など）
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithToken は、ログイン済みのトークン（Login のレスポンス、または auth_token Cookie の値）を指定します
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// NewClient は、baseURL（例: http://localhost:8080）の API に接続する Client を作成します
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token は、リクエストに付けるトークンを返します（未ログインの場合は空）
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// SetToken は、以降のリクエストに付けるトークンを変更します（空の場合はトークンを付けない）
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// User は、ログインしたユーザーです
+type User struct {
+	ID    int    `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// Login は、メールアドレスとパスワードでログインし、以降のリクエストにトークンを付けます
+func (c *Client) Login(ctx context.Context, email, password string) (*User, error) {
+	var resp struct {
+		User  User   `json:"user"`
+		Token string `json:"token"`
+	}
+	in := map[string]string{"email": email, "password": password}
+	if err := c.PostAuthLogin(ctx, in, &resp); err != nil {
+		return nil, err
+	}
+	c.SetToken(resp.Token)
+	return &resp.User, nil
+}
+
+// Logout は、ログアウトし、以降のリクエストにトークンを付けないようにします
+func (c *Client) Logout(ctx context.Context) error {
+	defer c.SetToken("")
+	return c.PostAuthLogout(ctx, nil, nil)
+}
+
+// RequestOption は、1つのリクエストの設定です（クエリパラメーター・ヘッダーなど）
+type RequestOption func(*http.Request)
+
+// WithQuery は、クエリパラメーターを追加します（例: 検索の q、一覧の limit・cursor）
+func WithQuery(query url.Values) RequestOption {
+	return func(req *http.Request) {
+		values := req.URL.Query()
+		for key, list := range query {
+			for _, value := range list {
+				values.Add(key, value)
+			}
+		}
+		req.URL.RawQuery = values.Encode()
+	}
+}
+
+// WithHeader は、リクエストヘッダーを設定します（例: If-Match、Idempotency-Key）
+func WithHeader(key, value string) RequestOption {
+	return func(req *http.Request) { req.Header.Set(key, value) }
+}
+
+// APIError は、API の統一エラーレスポンス（application/problem+json）です
+type APIError struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail"`
+	Instance  string         `json:"instance"`
+	Code      string         `json:"error"` // エラーコード（例: DOCUMENT_NOT_FOUND）
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details"`
+	RequestID string         `json:"request_id"`
+}
+
+// Error は、エラーコードとメッセージを返します
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("api error: status %d: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("api error: status %d: %s: %s", e.Status, e.Code, e.Message)
+}
+
+// do は、API を呼び出します
+// in が nil でない場合は JSON にして送り、out が io.Writer の場合は本文をそのまま書き込み、それ以外は JSON として読み取ります
+// ステータスが 400 以上の場合は *APIError を返します
+func (c *Client) do(ctx context.Context, method, path string, in, out any, opts []RequestOption) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if token := c.Token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	switch v := out.(type) {
+	case nil:
+		return nil
+	case io.Writer:
+		if _, err := io.Copy(v, resp.Body); err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil
+	default:
+		// 本文のないレスポンス（ログアウトなど）は out を変更しない
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to decode response body: %w", err)
+		}
+		return nil
+	}
+}
+
+// decodeError は、エラーのレスポンスを *APIError にします（統一エラーレスポンスでない場合は本文をメッセージにする）
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	apiErr := &APIError{}
+	if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Code == "" {
+		apiErr = &APIError{Message: strings.TrimSpace(string(data))}
+	}
+	apiErr.Status = resp.StatusCode
+	return apiErr
+}
+
+// pathInt は、数値のパスの変数をパスの一部にします
+func pathInt(v int) string {
+	return strconv.Itoa(v)
+}
+
+// pathString は、文字列のパスの変数をパスの一部にします（/ などはエスケープする）
+func pathString(v string) string {
+	return url.PathEscape(v)
+}
@@ -0,0 +1,708 @@
+// Code generated by sdkgen from the OpenAPI spec. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// PostAdminBlockTypes は POST /api/admin/block-types を呼び出します（要認証）
+func (c *Client) PostAdminBlockTypes(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/admin/block-types", in, out, opts)
+}
+
+// DeleteAdminBlockTypesByName は DELETE /api/admin/block-types/{name} を呼び出します（要認証）
+func (c *Client) DeleteAdminBlockTypesByName(ctx context.Context, name string, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/admin/block-types/"+pathString(name), nil, out, opts)
+}
+
+// PutAdminBlockTypesByName は PUT /api/admin/block-types/{name} を呼び出します（要認証）
+func (c *Client) PutAdminBlockTypesByName(ctx context.Context, name string, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/admin/block-types/"+pathString(name), in, out, opts)
+}
+
+// GetAdminMaintenanceOrphans は GET /api/admin/maintenance/orphans を呼び出します（要認証）
+func (c *Client) GetAdminMaintenanceOrphans(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/admin/maintenance/orphans", nil, out, opts)
+}
+
+// PostAdminMaintenanceOrphansRepair は POST /api/admin/maintenance/orphans/repair を呼び出します（要認証）
+func (c *Client) PostAdminMaintenanceOrphansRepair(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/admin/maintenance/orphans/repair", in, out, opts)
+}
+
+// GetAdminMaintenanceStorage は GET /api/admin/maintenance/storage を呼び出します（要認証）
+func (c *Client) GetAdminMaintenanceStorage(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/admin/maintenance/storage", nil, out, opts)
+}
+
+// GetAdminStats は GET /api/admin/stats を呼び出します（要認証）
+func (c *Client) GetAdminStats(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/admin/stats", nil, out, opts)
+}
+
+// GetAdminStorageUsage は GET /api/admin/storage/usage を呼び出します（要認証）
+func (c *Client) GetAdminStorageUsage(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/admin/storage/usage", nil, out, opts)
+}
+
+// GetAdminUsersByIDStorageQuota は GET /api/admin/users/{id}/storage-quota を呼び出します（要認証）
+func (c *Client) GetAdminUsersByIDStorageQuota(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/admin/users/"+pathInt(id)+"/storage-quota", nil, out, opts)
+}
+
+// PutAdminUsersByIDStorageQuota は PUT /api/admin/users/{id}/storage-quota を呼び出します（要認証）
+func (c *Client) PutAdminUsersByIDStorageQuota(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/admin/users/"+pathInt(id)+"/storage-quota", in, out, opts)
+}
+
+// GetAdminWorkspacesByIDStorageQuota は GET /api/admin/workspaces/{id}/storage-quota を呼び出します（要認証）
+func (c *Client) GetAdminWorkspacesByIDStorageQuota(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/admin/workspaces/"+pathInt(id)+"/storage-quota", nil, out, opts)
+}
+
+// PutAdminWorkspacesByIDStorageQuota は PUT /api/admin/workspaces/{id}/storage-quota を呼び出します（要認証）
+func (c *Client) PutAdminWorkspacesByIDStorageQuota(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/admin/workspaces/"+pathInt(id)+"/storage-quota", in, out, opts)
+}
+
+// PostAuthLogin は POST /api/auth/login を呼び出します
+func (c *Client) PostAuthLogin(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/auth/login", in, out, opts)
+}
+
+// GetAuthLoginStatus は GET /api/auth/login-status を呼び出します
+func (c *Client) GetAuthLoginStatus(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/auth/login-status", nil, out, opts)
+}
+
+// PostAuthLogout は POST /api/auth/logout を呼び出します
+func (c *Client) PostAuthLogout(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/auth/logout", in, out, opts)
+}
+
+// GetAuthMe は GET /api/auth/me を呼び出します（要認証）
+func (c *Client) GetAuthMe(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/auth/me", nil, out, opts)
+}
+
+// PostAuthRegister は POST /api/auth/register を呼び出します
+func (c *Client) PostAuthRegister(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/auth/register", in, out, opts)
+}
+
+// PutAuthWorkspace は PUT /api/auth/workspace を呼び出します（要認証）
+func (c *Client) PutAuthWorkspace(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/auth/workspace", in, out, opts)
+}
+
+// PostBatch は POST /api/batch を呼び出します（要認証）
+func (c *Client) PostBatch(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/batch", in, out, opts)
+}
+
+// GetBlockTypes は GET /api/block-types を呼び出します（要認証）
+func (c *Client) GetBlockTypes(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/block-types", nil, out, opts)
+}
+
+// PostBlocksByIDConvert は POST /api/blocks/{id}/convert を呼び出します（要認証）
+func (c *Client) PostBlocksByIDConvert(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/blocks/"+pathInt(id)+"/convert", in, out, opts)
+}
+
+// GetDocuments は GET /api/documents を呼び出します（要認証）
+func (c *Client) GetDocuments(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents", nil, out, opts)
+}
+
+// PostDocuments は POST /api/documents を呼び出します（要認証）
+func (c *Client) PostDocuments(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents", in, out, opts)
+}
+
+// GetDocumentsEvents は GET /api/documents/events を呼び出します（要認証）
+func (c *Client) GetDocumentsEvents(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/events", nil, out, opts)
+}
+
+// GetDocumentsSearch は GET /api/documents/search を呼び出します（要認証）
+func (c *Client) GetDocumentsSearch(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/search", nil, out, opts)
+}
+
+// GetDocumentsTree は GET /api/documents/tree を呼び出します（要認証）
+func (c *Client) GetDocumentsTree(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/tree", nil, out, opts)
+}
+
+// DeleteDocumentsByID は DELETE /api/documents/{id} を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByID(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id), nil, out, opts)
+}
+
+// GetDocumentsByID は GET /api/documents/{id} を呼び出します（要認証）
+func (c *Client) GetDocumentsByID(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id), nil, out, opts)
+}
+
+// PatchDocumentsByID は PATCH /api/documents/{id} を呼び出します（要認証）
+func (c *Client) PatchDocumentsByID(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPatch, "/api/documents/"+pathInt(id), in, out, opts)
+}
+
+// PutDocumentsByID は PUT /api/documents/{id} を呼び出します（要認証）
+func (c *Client) PutDocumentsByID(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/documents/"+pathInt(id), in, out, opts)
+}
+
+// PostDocumentsByIDBlocks は POST /api/documents/{id}/blocks を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDBlocks(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/blocks", in, out, opts)
+}
+
+// PostDocumentsByIDBlocksCopy は POST /api/documents/{id}/blocks/copy を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDBlocksCopy(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/blocks/copy", in, out, opts)
+}
+
+// DeleteDocumentsByIDBlocksByBlockID は DELETE /api/documents/{id}/blocks/{blockId} を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByIDBlocksByBlockID(ctx context.Context, id int, blockID int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id)+"/blocks/"+pathInt(blockID), nil, out, opts)
+}
+
+// PatchDocumentsByIDBlocksByBlockID は PATCH /api/documents/{id}/blocks/{blockId} を呼び出します（要認証）
+func (c *Client) PatchDocumentsByIDBlocksByBlockID(ctx context.Context, id int, blockID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPatch, "/api/documents/"+pathInt(id)+"/blocks/"+pathInt(blockID), in, out, opts)
+}
+
+// PutDocumentsByIDBlocksByBlockIDCollapsed は PUT /api/documents/{id}/blocks/{blockId}/collapsed を呼び出します（要認証）
+func (c *Client) PutDocumentsByIDBlocksByBlockIDCollapsed(ctx context.Context, id int, blockID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/documents/"+pathInt(id)+"/blocks/"+pathInt(blockID)+"/collapsed", in, out, opts)
+}
+
+// PostDocumentsByIDBlocksByBlockIDComments は POST /api/documents/{id}/blocks/{blockId}/comments を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDBlocksByBlockIDComments(ctx context.Context, id int, blockID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/blocks/"+pathInt(blockID)+"/comments", in, out, opts)
+}
+
+// PostDocumentsByIDBlocksByBlockIDDuplicate は POST /api/documents/{id}/blocks/{blockId}/duplicate を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDBlocksByBlockIDDuplicate(ctx context.Context, id int, blockID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/blocks/"+pathInt(blockID)+"/duplicate", in, out, opts)
+}
+
+// GetDocumentsByIDBlocksByBlockIDHistory は GET /api/documents/{id}/blocks/{blockId}/history を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDBlocksByBlockIDHistory(ctx context.Context, id int, blockID int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/blocks/"+pathInt(blockID)+"/history", nil, out, opts)
+}
+
+// PostDocumentsByIDBlocksByBlockIDPreview は POST /api/documents/{id}/blocks/{blockId}/preview を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDBlocksByBlockIDPreview(ctx context.Context, id int, blockID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/blocks/"+pathInt(blockID)+"/preview", in, out, opts)
+}
+
+// PostDocumentsByIDBlocksByBlockIDSync は POST /api/documents/{id}/blocks/{blockId}/sync を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDBlocksByBlockIDSync(ctx context.Context, id int, blockID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/blocks/"+pathInt(blockID)+"/sync", in, out, opts)
+}
+
+// GetDocumentsByIDCollaborators は GET /api/documents/{id}/collaborators を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDCollaborators(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/collaborators", nil, out, opts)
+}
+
+// PostDocumentsByIDCollaborators は POST /api/documents/{id}/collaborators を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDCollaborators(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/collaborators", in, out, opts)
+}
+
+// DeleteDocumentsByIDCollaboratorsByUserID は DELETE /api/documents/{id}/collaborators/{userId} を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByIDCollaboratorsByUserID(ctx context.Context, id int, userID int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id)+"/collaborators/"+pathInt(userID), nil, out, opts)
+}
+
+// PatchDocumentsByIDCollaboratorsByUserID は PATCH /api/documents/{id}/collaborators/{userId} を呼び出します（要認証）
+func (c *Client) PatchDocumentsByIDCollaboratorsByUserID(ctx context.Context, id int, userID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPatch, "/api/documents/"+pathInt(id)+"/collaborators/"+pathInt(userID), in, out, opts)
+}
+
+// GetDocumentsByIDComments は GET /api/documents/{id}/comments を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDComments(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/comments", nil, out, opts)
+}
+
+// DeleteDocumentsByIDCommentsByCommentID は DELETE /api/documents/{id}/comments/{commentId} を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByIDCommentsByCommentID(ctx context.Context, id int, commentID int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id)+"/comments/"+pathInt(commentID), nil, out, opts)
+}
+
+// PatchDocumentsByIDCommentsByCommentID は PATCH /api/documents/{id}/comments/{commentId} を呼び出します（要認証）
+func (c *Client) PatchDocumentsByIDCommentsByCommentID(ctx context.Context, id int, commentID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPatch, "/api/documents/"+pathInt(id)+"/comments/"+pathInt(commentID), in, out, opts)
+}
+
+// PutDocumentsByIDCursor は PUT /api/documents/{id}/cursor を呼び出します（要認証）
+func (c *Client) PutDocumentsByIDCursor(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/documents/"+pathInt(id)+"/cursor", in, out, opts)
+}
+
+// GetDocumentsByIDDiff は GET /api/documents/{id}/diff を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDDiff(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/diff", nil, out, opts)
+}
+
+// DeleteDocumentsByIDExpiration は DELETE /api/documents/{id}/expiration を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByIDExpiration(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id)+"/expiration", nil, out, opts)
+}
+
+// GetDocumentsByIDExpiration は GET /api/documents/{id}/expiration を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDExpiration(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/expiration", nil, out, opts)
+}
+
+// PutDocumentsByIDExpiration は PUT /api/documents/{id}/expiration を呼び出します（要認証）
+func (c *Client) PutDocumentsByIDExpiration(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/documents/"+pathInt(id)+"/expiration", in, out, opts)
+}
+
+// GetDocumentsByIDExport は GET /api/documents/{id}/export を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDExport(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/export", nil, out, opts)
+}
+
+// PutDocumentsByIDMove は PUT /api/documents/{id}/move を呼び出します（要認証）
+func (c *Client) PutDocumentsByIDMove(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/documents/"+pathInt(id)+"/move", in, out, opts)
+}
+
+// DeleteDocumentsByIDPermanent は DELETE /api/documents/{id}/permanent を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByIDPermanent(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id)+"/permanent", nil, out, opts)
+}
+
+// DeleteDocumentsByIDPresence は DELETE /api/documents/{id}/presence を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByIDPresence(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id)+"/presence", nil, out, opts)
+}
+
+// GetDocumentsByIDPresence は GET /api/documents/{id}/presence を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDPresence(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/presence", nil, out, opts)
+}
+
+// PutDocumentsByIDPresence は PUT /api/documents/{id}/presence を呼び出します（要認証）
+func (c *Client) PutDocumentsByIDPresence(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/documents/"+pathInt(id)+"/presence", in, out, opts)
+}
+
+// GetDocumentsByIDPublication は GET /api/documents/{id}/publication を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDPublication(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/publication", nil, out, opts)
+}
+
+// PutDocumentsByIDPublication は PUT /api/documents/{id}/publication を呼び出します（要認証）
+func (c *Client) PutDocumentsByIDPublication(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/documents/"+pathInt(id)+"/publication", in, out, opts)
+}
+
+// PostDocumentsByIDReminders は POST /api/documents/{id}/reminders を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDReminders(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/reminders", in, out, opts)
+}
+
+// PutDocumentsByIDRestore は PUT /api/documents/{id}/restore を呼び出します（要認証）
+func (c *Client) PutDocumentsByIDRestore(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/documents/"+pathInt(id)+"/restore", in, out, opts)
+}
+
+// GetDocumentsByIDShareLinks は GET /api/documents/{id}/share-links を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDShareLinks(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/share-links", nil, out, opts)
+}
+
+// PostDocumentsByIDShareLinks は POST /api/documents/{id}/share-links を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDShareLinks(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/share-links", in, out, opts)
+}
+
+// PostDocumentsByIDShareLinksSnapshot は POST /api/documents/{id}/share-links/snapshot を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDShareLinksSnapshot(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/share-links/snapshot", in, out, opts)
+}
+
+// DeleteDocumentsByIDShareLinksByLinkID は DELETE /api/documents/{id}/share-links/{linkId} を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByIDShareLinksByLinkID(ctx context.Context, id int, linkID int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id)+"/share-links/"+pathInt(linkID), nil, out, opts)
+}
+
+// GetDocumentsByIDThreads は GET /api/documents/{id}/threads を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDThreads(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/threads", nil, out, opts)
+}
+
+// PostDocumentsByIDThreads は POST /api/documents/{id}/threads を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDThreads(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/threads", in, out, opts)
+}
+
+// DeleteDocumentsByIDThreadsByCommentID は DELETE /api/documents/{id}/threads/{commentId} を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByIDThreadsByCommentID(ctx context.Context, id int, commentID int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id)+"/threads/"+pathInt(commentID), nil, out, opts)
+}
+
+// PatchDocumentsByIDThreadsByCommentID は PATCH /api/documents/{id}/threads/{commentId} を呼び出します（要認証）
+func (c *Client) PatchDocumentsByIDThreadsByCommentID(ctx context.Context, id int, commentID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPatch, "/api/documents/"+pathInt(id)+"/threads/"+pathInt(commentID), in, out, opts)
+}
+
+// PostDocumentsByIDThreadsByCommentIDReplies は POST /api/documents/{id}/threads/{commentId}/replies を呼び出します（要認証）
+func (c *Client) PostDocumentsByIDThreadsByCommentIDReplies(ctx context.Context, id int, commentID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/documents/"+pathInt(id)+"/threads/"+pathInt(commentID)+"/replies", in, out, opts)
+}
+
+// GetDocumentsByIDVersions は GET /api/documents/{id}/versions を呼び出します（要認証）
+func (c *Client) GetDocumentsByIDVersions(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id)+"/versions", nil, out, opts)
+}
+
+// DeleteDocumentsByIDWatch は DELETE /api/documents/{id}/watch を呼び出します（要認証）
+func (c *Client) DeleteDocumentsByIDWatch(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/documents/"+pathInt(id)+"/watch", nil, out, opts)
+}
+
+// PutDocumentsByIDWatch は PUT /api/documents/{id}/watch を呼び出します（要認証）
+func (c *Client) PutDocumentsByIDWatch(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/documents/"+pathInt(id)+"/watch", in, out, opts)
+}
+
+// GetEmbedsProviders は GET /api/embeds/providers を呼び出します（要認証）
+func (c *Client) GetEmbedsProviders(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/embeds/providers", nil, out, opts)
+}
+
+// GetEmbedsResolve は GET /api/embeds/resolve を呼び出します（要認証）
+func (c *Client) GetEmbedsResolve(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/embeds/resolve", nil, out, opts)
+}
+
+// GetFiles は GET /api/files を呼び出します（要認証）
+func (c *Client) GetFiles(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/files", nil, out, opts)
+}
+
+// GetFilesExport は GET /api/files/export を呼び出します（要認証）
+func (c *Client) GetFilesExport(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/files/export", nil, out, opts)
+}
+
+// PatchFilesByID は PATCH /api/files/{id} を呼び出します（要認証）
+func (c *Client) PatchFilesByID(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPatch, "/api/files/"+pathInt(id), in, out, opts)
+}
+
+// PutFilesByIDAttach は PUT /api/files/{id}/attach を呼び出します（要認証）
+func (c *Client) PutFilesByIDAttach(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/files/"+pathInt(id)+"/attach", in, out, opts)
+}
+
+// PutFilesByIDDetach は PUT /api/files/{id}/detach を呼び出します（要認証）
+func (c *Client) PutFilesByIDDetach(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/files/"+pathInt(id)+"/detach", in, out, opts)
+}
+
+// GetFilesByIDDownload は GET /api/files/{id}/download を呼び出します（要認証）
+func (c *Client) GetFilesByIDDownload(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/files/"+pathInt(id)+"/download", nil, out, opts)
+}
+
+// GetFilesByIDURL は GET /api/files/{id}/url を呼び出します（要認証）
+func (c *Client) GetFilesByIDURL(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/files/"+pathInt(id)+"/url", nil, out, opts)
+}
+
+// GetFilesByIDVersions は GET /api/files/{id}/versions を呼び出します（要認証）
+func (c *Client) GetFilesByIDVersions(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/files/"+pathInt(id)+"/versions", nil, out, opts)
+}
+
+// PostFilesByIDVersions は POST /api/files/{id}/versions を呼び出します（要認証）
+func (c *Client) PostFilesByIDVersions(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/files/"+pathInt(id)+"/versions", in, out, opts)
+}
+
+// PostFilesByIDVersionsByVersionIDRestore は POST /api/files/{id}/versions/{versionId}/restore を呼び出します（要認証）
+func (c *Client) PostFilesByIDVersionsByVersionIDRestore(ctx context.Context, id int, versionID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/files/"+pathInt(id)+"/versions/"+pathInt(versionID)+"/restore", in, out, opts)
+}
+
+// GetGraphql は GET /api/graphql を呼び出します（要認証）
+func (c *Client) GetGraphql(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/graphql", nil, out, opts)
+}
+
+// PostGraphql は POST /api/graphql を呼び出します（要認証）
+func (c *Client) PostGraphql(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/graphql", in, out, opts)
+}
+
+// GetHealth は GET /api/health を呼び出します
+func (c *Client) GetHealth(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/health", nil, out, opts)
+}
+
+// GetInvitationsByToken は GET /api/invitations/{token} を呼び出します
+func (c *Client) GetInvitationsByToken(ctx context.Context, token string, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/invitations/"+pathString(token), nil, out, opts)
+}
+
+// PostInvitationsByTokenAccept は POST /api/invitations/{token}/accept を呼び出します（要認証）
+func (c *Client) PostInvitationsByTokenAccept(ctx context.Context, token string, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/invitations/"+pathString(token)+"/accept", in, out, opts)
+}
+
+// PostInvitationsByTokenRegister は POST /api/invitations/{token}/register を呼び出します
+func (c *Client) PostInvitationsByTokenRegister(ctx context.Context, token string, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/invitations/"+pathString(token)+"/register", in, out, opts)
+}
+
+// GetNotifications は GET /api/notifications を呼び出します（要認証）
+func (c *Client) GetNotifications(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/notifications", nil, out, opts)
+}
+
+// PutNotificationsByIDRead は PUT /api/notifications/{id}/read を呼び出します（要認証）
+func (c *Client) PutNotificationsByIDRead(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/notifications/"+pathInt(id)+"/read", in, out, opts)
+}
+
+// GetOpenapiJSON は GET /api/openapi.json を呼び出します
+func (c *Client) GetOpenapiJSON(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/openapi.json", nil, out, opts)
+}
+
+// GetPublicDocumentsByID は GET /api/public/documents/{id} を呼び出します
+func (c *Client) GetPublicDocumentsByID(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/public/documents/"+pathInt(id), nil, out, opts)
+}
+
+// GetReminders は GET /api/reminders を呼び出します（要認証）
+func (c *Client) GetReminders(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/reminders", nil, out, opts)
+}
+
+// PutRemindersByIDDismiss は PUT /api/reminders/{id}/dismiss を呼び出します（要認証）
+func (c *Client) PutRemindersByIDDismiss(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/reminders/"+pathInt(id)+"/dismiss", in, out, opts)
+}
+
+// GetShareByToken は GET /api/share/{token} を呼び出します
+func (c *Client) GetShareByToken(ctx context.Context, token string, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/share/"+pathString(token), nil, out, opts)
+}
+
+// GetStatus は GET /api/status を呼び出します
+func (c *Client) GetStatus(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/status", nil, out, opts)
+}
+
+// GetStorageUsage は GET /api/storage/usage を呼び出します（要認証）
+func (c *Client) GetStorageUsage(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/storage/usage", nil, out, opts)
+}
+
+// GetTasks は GET /api/tasks を呼び出します（要認証）
+func (c *Client) GetTasks(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/tasks", nil, out, opts)
+}
+
+// PostTrashRestore は POST /api/trash/restore を呼び出します（要認証）
+func (c *Client) PostTrashRestore(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/trash/restore", in, out, opts)
+}
+
+// PostUploadAudio は POST /api/upload/audio を呼び出します（要認証）
+func (c *Client) PostUploadAudio(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/upload/audio", in, out, opts)
+}
+
+// PostUploadDirect は POST /api/upload/direct を呼び出します（要認証）
+func (c *Client) PostUploadDirect(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/upload/direct", in, out, opts)
+}
+
+// PostUploadDirectByIDConfirm は POST /api/upload/direct/{id}/confirm を呼び出します（要認証）
+func (c *Client) PostUploadDirectByIDConfirm(ctx context.Context, id string, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/upload/direct/"+pathString(id)+"/confirm", in, out, opts)
+}
+
+// PostUploadFile は POST /api/upload/file を呼び出します（要認証）
+func (c *Client) PostUploadFile(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/upload/file", in, out, opts)
+}
+
+// PostUploadImage は POST /api/upload/image を呼び出します（要認証）
+func (c *Client) PostUploadImage(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/upload/image", in, out, opts)
+}
+
+// GetUploadProgressByID は GET /api/upload/progress/{id} を呼び出します（要認証）
+func (c *Client) GetUploadProgressByID(ctx context.Context, id string, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/upload/progress/"+pathString(id), nil, out, opts)
+}
+
+// PostUploadSessions は POST /api/upload/sessions を呼び出します（要認証）
+func (c *Client) PostUploadSessions(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/upload/sessions", in, out, opts)
+}
+
+// DeleteUploadSessionsByID は DELETE /api/upload/sessions/{id} を呼び出します（要認証）
+func (c *Client) DeleteUploadSessionsByID(ctx context.Context, id string, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/upload/sessions/"+pathString(id), nil, out, opts)
+}
+
+// GetUploadSessionsByID は GET /api/upload/sessions/{id} を呼び出します（要認証）
+func (c *Client) GetUploadSessionsByID(ctx context.Context, id string, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/upload/sessions/"+pathString(id), nil, out, opts)
+}
+
+// PatchUploadSessionsByID は PATCH /api/upload/sessions/{id} を呼び出します（要認証）
+func (c *Client) PatchUploadSessionsByID(ctx context.Context, id string, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPatch, "/api/upload/sessions/"+pathString(id), in, out, opts)
+}
+
+// PostUploadSessionsByIDComplete は POST /api/upload/sessions/{id}/complete を呼び出します（要認証）
+func (c *Client) PostUploadSessionsByIDComplete(ctx context.Context, id string, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/upload/sessions/"+pathString(id)+"/complete", in, out, opts)
+}
+
+// PostUploadVideo は POST /api/upload/video を呼び出します（要認証）
+func (c *Client) PostUploadVideo(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/upload/video", in, out, opts)
+}
+
+// GetUploadsByFilename は GET /api/uploads/{filename} を呼び出します
+func (c *Client) GetUploadsByFilename(ctx context.Context, filename string, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/uploads/"+pathString(filename), nil, out, opts)
+}
+
+// GetWatches は GET /api/watches を呼び出します（要認証）
+func (c *Client) GetWatches(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/watches", nil, out, opts)
+}
+
+// GetWebhooks は GET /api/webhooks を呼び出します（要認証）
+func (c *Client) GetWebhooks(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/webhooks", nil, out, opts)
+}
+
+// PostWebhooks は POST /api/webhooks を呼び出します（要認証）
+func (c *Client) PostWebhooks(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/webhooks", in, out, opts)
+}
+
+// DeleteWebhooksByID は DELETE /api/webhooks/{id} を呼び出します（要認証）
+func (c *Client) DeleteWebhooksByID(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/webhooks/"+pathInt(id), nil, out, opts)
+}
+
+// GetWebhooksByID は GET /api/webhooks/{id} を呼び出します（要認証）
+func (c *Client) GetWebhooksByID(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/webhooks/"+pathInt(id), nil, out, opts)
+}
+
+// PatchWebhooksByID は PATCH /api/webhooks/{id} を呼び出します（要認証）
+func (c *Client) PatchWebhooksByID(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPatch, "/api/webhooks/"+pathInt(id), in, out, opts)
+}
+
+// GetWebhooksByIDDeliveries は GET /api/webhooks/{id}/deliveries を呼び出します（要認証）
+func (c *Client) GetWebhooksByIDDeliveries(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/webhooks/"+pathInt(id)+"/deliveries", nil, out, opts)
+}
+
+// PostWebhooksByIDDeliveriesByDeliveryIDRedeliver は POST /api/webhooks/{id}/deliveries/{deliveryId}/redeliver を呼び出します（要認証）
+func (c *Client) PostWebhooksByIDDeliveriesByDeliveryIDRedeliver(ctx context.Context, id int, deliveryID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/webhooks/"+pathInt(id)+"/deliveries/"+pathInt(deliveryID)+"/redeliver", in, out, opts)
+}
+
+// PostWebhooksByIDSecret は POST /api/webhooks/{id}/secret を呼び出します（要認証）
+func (c *Client) PostWebhooksByIDSecret(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/webhooks/"+pathInt(id)+"/secret", in, out, opts)
+}
+
+// GetWorkspaces は GET /api/workspaces を呼び出します（要認証）
+func (c *Client) GetWorkspaces(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/workspaces", nil, out, opts)
+}
+
+// PostWorkspaces は POST /api/workspaces を呼び出します（要認証）
+func (c *Client) PostWorkspaces(ctx context.Context, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/workspaces", in, out, opts)
+}
+
+// DeleteWorkspacesByID は DELETE /api/workspaces/{id} を呼び出します（要認証）
+func (c *Client) DeleteWorkspacesByID(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/workspaces/"+pathInt(id), nil, out, opts)
+}
+
+// GetWorkspacesByID は GET /api/workspaces/{id} を呼び出します（要認証）
+func (c *Client) GetWorkspacesByID(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/workspaces/"+pathInt(id), nil, out, opts)
+}
+
+// PatchWorkspacesByID は PATCH /api/workspaces/{id} を呼び出します（要認証）
+func (c *Client) PatchWorkspacesByID(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPatch, "/api/workspaces/"+pathInt(id), in, out, opts)
+}
+
+// GetWorkspacesByIDInvitations は GET /api/workspaces/{id}/invitations を呼び出します（要認証）
+func (c *Client) GetWorkspacesByIDInvitations(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/workspaces/"+pathInt(id)+"/invitations", nil, out, opts)
+}
+
+// PostWorkspacesByIDInvitations は POST /api/workspaces/{id}/invitations を呼び出します（要認証）
+func (c *Client) PostWorkspacesByIDInvitations(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/workspaces/"+pathInt(id)+"/invitations", in, out, opts)
+}
+
+// DeleteWorkspacesByIDInvitationsByInvitationID は DELETE /api/workspaces/{id}/invitations/{invitationId} を呼び出します（要認証）
+func (c *Client) DeleteWorkspacesByIDInvitationsByInvitationID(ctx context.Context, id int, invitationID int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/workspaces/"+pathInt(id)+"/invitations/"+pathInt(invitationID), nil, out, opts)
+}
+
+// GetWorkspacesByIDMembers は GET /api/workspaces/{id}/members を呼び出します（要認証）
+func (c *Client) GetWorkspacesByIDMembers(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/workspaces/"+pathInt(id)+"/members", nil, out, opts)
+}
+
+// PostWorkspacesByIDMembers は POST /api/workspaces/{id}/members を呼び出します（要認証）
+func (c *Client) PostWorkspacesByIDMembers(ctx context.Context, id int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPost, "/api/workspaces/"+pathInt(id)+"/members", in, out, opts)
+}
+
+// DeleteWorkspacesByIDMembersByUserID は DELETE /api/workspaces/{id}/members/{userId} を呼び出します（要認証）
+func (c *Client) DeleteWorkspacesByIDMembersByUserID(ctx context.Context, id int, userID int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/api/workspaces/"+pathInt(id)+"/members/"+pathInt(userID), nil, out, opts)
+}
+
+// PutWorkspacesByIDMembersByUserID は PUT /api/workspaces/{id}/members/{userId} を呼び出します（要認証）
+func (c *Client) PutWorkspacesByIDMembersByUserID(ctx context.Context, id int, userID int, in any, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodPut, "/api/workspaces/"+pathInt(id)+"/members/"+pathInt(userID), in, out, opts)
+}
+
+// GetWorkspacesByIDStorage は GET /api/workspaces/{id}/storage を呼び出します（要認証）
+func (c *Client) GetWorkspacesByIDStorage(ctx context.Context, id int, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/api/workspaces/"+pathInt(id)+"/storage", nil, out, opts)
+}
+
+// GetHealthz は GET /healthz を呼び出します
+func (c *Client) GetHealthz(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/healthz", nil, out, opts)
+}
+
+// GetReadyz は GET /readyz を呼び出します
+func (c *Client) GetReadyz(ctx context.Context, out any, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodGet, "/readyz", nil, out, opts)
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"simple-notion-backend/internal/app"
+	"simple-notion-backend/internal/sdkgen"
+)
+
+// TestGeneratedClientUpToDate - client_gen.go が現在のルートの登録内容から生成したものと一致するかのテスト
+func TestGeneratedClientUpToDate(t *testing.T) {
+	spec, err := app.GenerateOpenAPISpec()
+	if err != nil {
+		t.Fatalf("GenerateOpenAPISpec() error = %v", err)
+	}
+	want, err := sdkgen.GenerateGo(spec, "client")
+	if err != nil {
+		t.Fatalf("GenerateGo() error = %v", err)
+	}
+	got, err := os.ReadFile("client_gen.go")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != string(want) {
+		t.Error("client_gen.go is out of date; run `go generate ./client` in backend")
+	}
+}
+
+func TestClientLogin(t *testing.T) {
+	var gotAuth, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth/login":
+			var req map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["email"] != "user@example.com" {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"type":"about:blank","title":"Unauthorized","status":401,"error":"INVALID_CREDENTIALS","message":"メールアドレスまたはパスワードが違います","request_id":"req-1"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"user": map[string]any{"id": 7, "email": req["email"]}, "token": "jwt-token"})
+		case "/api/documents/42":
+			gotAuth, gotQuery = r.Header.Get("Authorization"), r.URL.RawQuery
+			json.NewEncoder(w).Encode(map[string]any{"id": 42, "title": "文書"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("404 page not found"))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	t.Run("正常系：ログイン後のリクエストにトークンを付ける", func(t *testing.T) {
+		c := NewClient(server.URL + "/")
+		user, err := c.Login(ctx, "user@example.com", "password")
+		if err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+		if user.ID != 7 || c.Token() != "jwt-token" {
+			t.Errorf("Login() user = %+v, token = %q", user, c.Token())
+		}
+
+		var doc struct {
+			ID    int    `json:"id"`
+			Title string `json:"title"`
+		}
+		if err := c.GetDocumentsByID(ctx, 42, &doc, WithQuery(url.Values{"blocks": {"tree"}})); err != nil {
+			t.Fatalf("GetDocumentsByID() error = %v", err)
+		}
+		if doc.ID != 42 || doc.Title != "文書" {
+			t.Errorf("GetDocumentsByID() = %+v", doc)
+		}
+		if gotAuth != "Bearer jwt-token" {
+			t.Errorf("Authorization = %q, want Bearer jwt-token", gotAuth)
+		}
+		if gotQuery != "blocks=tree" {
+			t.Errorf("query = %q, want blocks=tree", gotQuery)
+		}
+	})
+
+	t.Run("異常系：統一エラーレスポンスを APIError にする", func(t *testing.T) {
+		_, err := NewClient(server.URL).Login(ctx, "other@example.com", "password")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Login() error = %v, want *APIError", err)
+		}
+		if apiErr.Status != http.StatusUnauthorized || apiErr.Code != "INVALID_CREDENTIALS" || apiErr.RequestID != "req-1" {
+			t.Errorf("APIError = %+v", apiErr)
+		}
+	})
+
+	t.Run("異常系：統一エラーレスポンスでない本文はメッセージにする", func(t *testing.T) {
+		err := NewClient(server.URL).GetDocumentsByID(ctx, 1, nil)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("GetDocumentsByID() error = %v, want *APIError", err)
+		}
+		if apiErr.Status != http.StatusNotFound || apiErr.Message != "404 page not found" {
+			t.Errorf("APIError = %+v", apiErr)
+		}
+	})
+}
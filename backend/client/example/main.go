@@ -0,0 +1,85 @@
+// example は、公式の Go クライアントでログインし、文書の作成・取得・更新・削除を行うサンプルです
+//
+//	SIMPLE_NOTION_EMAIL=user@example.com SIMPLE_NOTION_PASSWORD=... go run ./client/example -url http://localhost:8080
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"simple-notion-backend/client"
+)
+
+// document は、文書のレスポンスのうちサンプルで使うフィールドです
+type document struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Blocks    []struct {
+		ID   int    `json:"id"`
+		Type string `json:"type"`
+	} `json:"blocks"`
+}
+
+func main() {
+	baseURL := flag.String("url", "http://localhost:8080", "API のベース URL")
+	flag.Parse()
+
+	email, password := os.Getenv("SIMPLE_NOTION_EMAIL"), os.Getenv("SIMPLE_NOTION_PASSWORD")
+	if email == "" || password == "" {
+		log.Fatal("SIMPLE_NOTION_EMAIL と SIMPLE_NOTION_PASSWORD を設定してください")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := run(ctx, client.NewClient(*baseURL), email, password); err != nil {
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) {
+			log.Fatalf("API error: %s (request_id=%s)", apiErr.Error(), apiErr.RequestID)
+		}
+		log.Fatal(err)
+	}
+}
+
+// run は、ログイン → 作成 → 取得 → 更新 → 削除 → ログアウトの順に API を呼び出します
+func run(ctx context.Context, c *client.Client, email, password string) error {
+	user, err := c.Login(ctx, email, password)
+	if err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	fmt.Printf("logged in as %s (id=%d)\n", user.Email, user.ID)
+
+	var created document
+	if err := c.PostDocuments(ctx, map[string]any{"title": "SDK のサンプル", "content": "作成した文書"}, &created); err != nil {
+		return fmt.Errorf("create document: %w", err)
+	}
+	fmt.Printf("created document %d: %q\n", created.ID, created.Title)
+
+	var fetched document
+	if err := c.GetDocumentsByID(ctx, created.ID, &fetched); err != nil {
+		return fmt.Errorf("get document: %w", err)
+	}
+	fmt.Printf("fetched document %d: %q (%d blocks)\n", fetched.ID, fetched.Title, len(fetched.Blocks))
+
+	// 本文に含めたフィールドのみを変更する（JSON Merge Patch）
+	var updated document
+	if err := c.PatchDocumentsByID(ctx, created.ID, map[string]any{"title": "SDK のサンプル（更新済み）"}, &updated); err != nil {
+		return fmt.Errorf("update document: %w", err)
+	}
+	fmt.Printf("updated document %d: %q\n", updated.ID, updated.Title)
+
+	// 削除した文書はごみ箱に移る（完全に削除する場合は DeleteDocumentsByIDPermanent）
+	if err := c.DeleteDocumentsByID(ctx, created.ID, nil); err != nil {
+		return fmt.Errorf("delete document: %w", err)
+	}
+	fmt.Printf("deleted document %d\n", created.ID)
+
+	return c.Logout(ctx)
+}
@@ -0,0 +1,55 @@
+// sdkgen は、ルートの登録内容から OpenAPI の仕様を生成し、公式の API クライアントを書き出すコマンドです
+// client パッケージの go:generate から実行します（cd backend && go generate ./client）
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"simple-notion-backend/internal/app"
+	"simple-notion-backend/internal/sdkgen"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "OpenAPI の仕様（JSON）の書き出し先")
+	goPath := flag.String("go", "", "Go のクライアントの書き出し先")
+	goPackage := flag.String("package", "client", "Go のクライアントのパッケージ名")
+	tsPath := flag.String("ts", "", "TypeScript の型の書き出し先")
+	flag.Parse()
+
+	spec, err := app.GenerateOpenAPISpec()
+	if err != nil {
+		log.Fatalf("Failed to generate OpenAPI spec: %v", err)
+	}
+
+	if *specPath != "" {
+		data, err := json.MarshalIndent(spec, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode OpenAPI spec: %v", err)
+		}
+		writeFile(*specPath, append(data, '\n'))
+	}
+	if *goPath != "" {
+		src, err := sdkgen.GenerateGo(spec, *goPackage)
+		if err != nil {
+			log.Fatalf("Failed to generate Go client: %v", err)
+		}
+		writeFile(*goPath, src)
+	}
+	if *tsPath != "" {
+		src, err := sdkgen.GenerateTypeScript(spec)
+		if err != nil {
+			log.Fatalf("Failed to generate TypeScript types: %v", err)
+		}
+		writeFile(*tsPath, src)
+	}
+}
+
+// writeFile は、生成した内容をファイルに書き出します
+func writeFile(path string, data []byte) {
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
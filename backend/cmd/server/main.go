@@ -8,9 +8,18 @@ import (
 )
 
 func main() {
-	// ヘルスチェックフラグの処理
+	// ヘルスチェックフラグの処理（--health [live|ready]、省略時は ready）
 	if len(os.Args) > 1 && os.Args[1] == "--health" {
-		os.Exit(app.RunHealthCheck())
+		probe := "ready"
+		if len(os.Args) > 2 {
+			probe = os.Args[2]
+		}
+		os.Exit(app.RunHealthCheck(probe))
+	}
+
+	// 管理用サブコマンドの処理（create-user・reset-password・purge-trash・reindex-search・storage-report）
+	if len(os.Args) > 1 && app.IsAdminCommand(os.Args[1]) {
+		os.Exit(app.RunAdminCommand(os.Args[1:]))
 	}
 
 	// アプリケーションの作成
@@ -1,7 +1,7 @@
 // Package apierror はアプリケーション全体で使う統一エラー型とヘルパーを提供する。
 // - AppError: HTTP ステータス/エラーコード/ユーザー向けメッセージ/元エラーをまとめた型
 // - sentinel error: repository 層で使う種別識別用エラー
-// - Write: 任意の error を JSON レスポンス + ログに変換するヘルパー
+// - Write: 任意の error を RFC 7807（application/problem+json）のレスポンス + ログに変換するヘルパー
 package apierror
 
 import (
@@ -18,6 +18,7 @@ type AppError struct {
 	Code       string // アプリ内エラーコード（例: "NOT_FOUND"）
 	Message    string // ユーザー向けメッセージ（日本語）
 	Err        error  // 内部ログ用の元エラー
+	Details    interface{}
 }
 
 func (e *AppError) Error() string {
@@ -30,6 +31,12 @@ func (e *AppError) Error() string {
 // Unwrap により errors.Is / errors.As がチェーンをたどれる。
 func (e *AppError) Unwrap() error { return e.Err }
 
+// WithDetails は クライアントに返す詳細情報（項目ごとの検証エラーなど）を付与する。
+func (e *AppError) WithDetails(details interface{}) *AppError {
+	e.Details = details
+	return e
+}
+
 // NewValidationError は 400 Bad Request 相当のエラーを生成する。
 func NewValidationError(code, message string, cause error) *AppError {
 	return &AppError{HTTPStatus: http.StatusBadRequest, Code: code, Message: message, Err: cause}
@@ -60,6 +67,31 @@ func NewPayloadTooLarge(code, message string, cause error) *AppError {
 	return &AppError{HTTPStatus: http.StatusRequestEntityTooLarge, Code: code, Message: message, Err: cause}
 }
 
+// NewMethodNotAllowed は 405 Method Not Allowed 相当のエラーを生成する。
+func NewMethodNotAllowed(code, message string, cause error) *AppError {
+	return &AppError{HTTPStatus: http.StatusMethodNotAllowed, Code: code, Message: message, Err: cause}
+}
+
+// NewUnsupportedMediaType は 415 Unsupported Media Type 相当のエラーを生成する。
+func NewUnsupportedMediaType(code, message string, cause error) *AppError {
+	return &AppError{HTTPStatus: http.StatusUnsupportedMediaType, Code: code, Message: message, Err: cause}
+}
+
+// NewRangeNotSatisfiable は 416 Range Not Satisfiable 相当のエラーを生成する。
+func NewRangeNotSatisfiable(code, message string, cause error) *AppError {
+	return &AppError{HTTPStatus: http.StatusRequestedRangeNotSatisfiable, Code: code, Message: message, Err: cause}
+}
+
+// NewTooManyRequests は 429 Too Many Requests 相当のエラーを生成する。
+func NewTooManyRequests(code, message string, cause error) *AppError {
+	return &AppError{HTTPStatus: http.StatusTooManyRequests, Code: code, Message: message, Err: cause}
+}
+
+// NewBadGateway は 502 Bad Gateway 相当のエラーを生成する。
+func NewBadGateway(code, message string, cause error) *AppError {
+	return &AppError{HTTPStatus: http.StatusBadGateway, Code: code, Message: message, Err: cause}
+}
+
 // NewInternal は 500 Internal Server Error 相当のエラーを生成する。
 func NewInternal(cause error) *AppError {
 	return &AppError{
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"simple-notion-backend/internal/requestid"
 )
 
 func TestAppError_Error(t *testing.T) {
@@ -70,6 +72,7 @@ func TestConstructors_HTTPStatus(t *testing.T) {
 		{"not found", NewNotFound("C", "m", nil), http.StatusNotFound},
 		{"conflict", NewConflict("C", "m", nil), http.StatusConflict},
 		{"payload too large", NewPayloadTooLarge("C", "m", nil), http.StatusRequestEntityTooLarge},
+		{"range not satisfiable", NewRangeNotSatisfiable("C", "m", nil), http.StatusRequestedRangeNotSatisfiable},
 		{"internal", NewInternal(nil), http.StatusInternalServerError},
 	}
 
@@ -122,12 +125,14 @@ func TestWrite_WritesJSON(t *testing.T) {
 		err        error
 		wantStatus int
 		wantCode   string
+		wantType   string
 	}{
 		{
 			name:       "*AppError はそのまま使われる",
 			err:        NewNotFound("DOC_NOT_FOUND", "文書が見つかりません", nil),
 			wantStatus: http.StatusNotFound,
 			wantCode:   "DOC_NOT_FOUND",
+			wantType:   "urn:simple-notion:problem:doc-not-found",
 		},
 		{
 			name:       "ErrNotFound sentinel は自動昇格する",
@@ -161,8 +166,8 @@ func TestWrite_WritesJSON(t *testing.T) {
 			}
 
 			ct := w.Header().Get("Content-Type")
-			if ct != "application/json" {
-				t.Errorf("Content-Type = %q, want %q", ct, "application/json")
+			if ct != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want %q", ct, "application/problem+json")
 			}
 
 			var resp ErrorResponse
@@ -175,10 +180,60 @@ func TestWrite_WritesJSON(t *testing.T) {
 			if resp.Message == "" {
 				t.Error("Message が空")
 			}
+
+			// RFC 7807 のメンバー
+			if resp.Status != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", resp.Status, tt.wantStatus)
+			}
+			if resp.Title != http.StatusText(tt.wantStatus) {
+				t.Errorf("Title = %q, want %q", resp.Title, http.StatusText(tt.wantStatus))
+			}
+			if resp.Detail != resp.Message {
+				t.Errorf("Detail = %q, want %q", resp.Detail, resp.Message)
+			}
+			if resp.Instance != "/api/test" {
+				t.Errorf("Instance = %q, want %q", resp.Instance, "/api/test")
+			}
+			if tt.wantType != "" && resp.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", resp.Type, tt.wantType)
+			}
 		})
 	}
 }
 
+func TestWrite_IncludesRequestID(t *testing.T) {
+	t.Run("コンテキストのリクエスト ID を返す", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+		r = r.WithContext(requestid.NewContext(r.Context(), "req-123"))
+
+		Write(w, r, errors.New("boom"))
+
+		var resp ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("JSON デコード失敗: %v", err)
+		}
+		if resp.RequestID != "req-123" {
+			t.Errorf("RequestID = %q, want %q", resp.RequestID, "req-123")
+		}
+	})
+
+	t.Run("リクエスト ID がない場合は省略する", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+
+		Write(w, r, errors.New("boom"))
+
+		var body map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("JSON デコード失敗: %v", err)
+		}
+		if _, ok := body["request_id"]; ok {
+			t.Errorf("request_id = %v, want omitted", body["request_id"])
+		}
+	})
+}
+
 func TestToAppError_Nil(t *testing.T) {
 	// nil エラーでもパニックせず 500 として扱う
 	got := toAppError(nil)
@@ -5,12 +5,31 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"strings"
+
+	"simple-notion-backend/internal/requestid"
 )
 
-// ErrorResponse は統一エラーレスポンスの JSON 形式（{error, message}）。
+// ProblemContentType はエラーレスポンスの Content-Type（RFC 7807 Problem Details）。
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix は Problem Details の type に使う URI の接頭辞（後ろにエラーコードを付ける）。
+const problemTypePrefix = "urn:simple-notion:problem:"
+
+// ErrorResponse は統一エラーレスポンスの形式（RFC 7807 Problem Details）。
+// type/title/status/detail/instance は RFC 7807 のメンバー、それ以外は拡張メンバー。
+// error はエラーコード（機械判定用）、message は detail と同じユーザー向けメッセージ（以前の {error, message} 形式との互換のため残す）。
+// RequestID は問い合わせの際にサーバーログと突き合わせるための ID（X-Request-ID と同じ値）。
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail"`
+	Instance  string      `json:"instance,omitempty"`
+	Error     string      `json:"error"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 // Write は任意の err を適切な HTTP レスポンスとサーバーログに変換する。
@@ -19,20 +38,43 @@ func Write(w http.ResponseWriter, r *http.Request, err error) {
 	appErr := toAppError(err)
 
 	// 元エラーの詳細はサーバーログに必ず記録する（クライアントには返さない）
-	method, path := "-", "-"
+	method, path, reqID := "-", "-", ""
 	if r != nil {
 		method = r.Method
 		if r.URL != nil {
 			path = r.URL.Path
 		}
+		reqID = requestid.FromContext(r.Context())
+	}
+	logID := reqID
+	if logID == "" {
+		logID = "-"
+	}
+	log.Printf("[%s] %s %s -> %d request_id=%s: %v",
+		appErr.Code, method, path, appErr.HTTPStatus, logID, appErr.Err)
+
+	problem := NewProblem(appErr)
+	if path != "-" {
+		problem.Instance = path
 	}
-	log.Printf("[%s] %s %s -> %d: %v",
-		appErr.Code, method, path, appErr.HTTPStatus, appErr.Err)
+	problem.RequestID = reqID
 
-	WriteJSON(w, appErr.HTTPStatus, ErrorResponse{
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(appErr.HTTPStatus)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// NewProblem は AppError を Problem Details に変換する（instance・request_id は設定しない）。
+func NewProblem(appErr *AppError) ErrorResponse {
+	return ErrorResponse{
+		Type:    problemTypePrefix + strings.ToLower(strings.ReplaceAll(appErr.Code, "_", "-")),
+		Title:   http.StatusText(appErr.HTTPStatus),
+		Status:  appErr.HTTPStatus,
+		Detail:  appErr.Message,
 		Error:   appErr.Code,
 		Message: appErr.Message,
-	})
+		Details: appErr.Details,
+	}
 }
 
 // WriteJSON は任意の body を JSON として書き出す汎用ヘルパー。
@@ -42,6 +84,11 @@ func WriteJSON(w http.ResponseWriter, status int, body interface{}) {
 	_ = json.NewEncoder(w).Encode(body)
 }
 
+// From は任意のエラーを *AppError に正規化する（JSON の統一エラーレスポンス以外の形式で返す場合に使う）。
+func From(err error) *AppError {
+	return toAppError(err)
+}
+
 // toAppError は任意のエラーを *AppError に正規化する。
 // - 既に *AppError ならそのまま返す
 // - sentinel error はステータスコード付きの AppError に昇格する
@@ -0,0 +1,496 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lib/pq"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/config"
+	"simple-notion-backend/internal/handlers/document"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
+
+// reindexBatchSize は reindex-search で1回に読み込む文書の件数です
+const reindexBatchSize = 200
+
+// minPasswordLength は 登録時と同じパスワードの最小の長さです
+const minPasswordLength = 6
+
+// errUsage は 引数の誤りを表すエラーです（使い方を表示し、終了コード 2 で終了する）
+var errUsage = errors.New("invalid arguments")
+
+// adminCommand は 管理用のサブコマンドです
+type adminCommand struct {
+	name    string
+	summary string
+	run     func(env *adminEnv, args []string) error
+}
+
+// adminCommands は 利用できる管理用のサブコマンドの一覧です
+var adminCommands = []adminCommand{
+	{name: "create-user", summary: "ユーザーを作成する（パスワードは標準入力から読み取る）", run: runCreateUser},
+	{name: "reset-password", summary: "ユーザーのパスワードを変更する（パスワードは標準入力から読み取る）", run: runResetPassword},
+	{name: "purge-trash", summary: "ごみ箱の文書を完全に削除する", run: runPurgeTrash},
+	{name: "reindex-search", summary: "全文検索用のテキストを全ての文書について作り直す", run: runReindexSearch},
+	{name: "storage-report", summary: "ストレージ使用量の集計を表示する", run: runStorageReport},
+}
+
+// adminEnv は サブコマンドの入出力と、必要になった時点で初期化する依存関係です
+type adminEnv struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	cfg          *config.Config
+	database     *sql.DB
+	dependencies *Dependencies
+}
+
+// deps は データベースに接続し、サーバーと同じ Repository・Service を初期化して返します
+func (e *adminEnv) deps() (*Dependencies, error) {
+	if e.dependencies != nil {
+		return e.dependencies, nil
+	}
+
+	connector, err := pq.NewConnector(e.cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	e.database = sql.OpenDB(connector)
+	if err := e.database.PingContext(e.ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	e.dependencies, err = NewDependencies(e.cfg, e.database, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dependencies: %w", err)
+	}
+	return e.dependencies, nil
+}
+
+// close は 送信待ちのメールを送り終え、データベースの接続を閉じます
+func (e *adminEnv) close() {
+	if e.dependencies != nil && e.dependencies.Mailer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := e.dependencies.Mailer.Close(ctx); err != nil {
+			fmt.Fprintf(e.stderr, "Failed to flush mail queue: %v\n", err)
+		}
+	}
+	if e.database != nil {
+		e.database.Close()
+	}
+}
+
+// IsAdminCommand は name が管理用のサブコマンドかどうかを返します
+func IsAdminCommand(name string) bool {
+	_, ok := findAdminCommand(name)
+	return ok
+}
+
+// findAdminCommand は 名前からサブコマンドを探します
+func findAdminCommand(name string) (adminCommand, bool) {
+	for _, command := range adminCommands {
+		if command.name == name {
+			return command, true
+		}
+	}
+	return adminCommand{}, false
+}
+
+// RunAdminCommand は 管理用のサブコマンドを実行し、結果を終了コードで返します
+// args: サブコマンド名とその引数（例: create-user --email a@example.com --name Alice）
+// 終了コードは 0（成功）・1（失敗）・2（引数の誤り）
+func RunAdminCommand(args []string) int {
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	env := &adminEnv{
+		ctx:    context.Background(),
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		cfg:    cfg,
+	}
+	defer env.close()
+	return runAdminCommand(env, args)
+}
+
+// runAdminCommand は サブコマンドを探して実行します
+func runAdminCommand(env *adminEnv, args []string) int {
+	if len(args) == 0 {
+		printAdminUsage(env.stderr)
+		return 2
+	}
+	command, ok := findAdminCommand(args[0])
+	if !ok {
+		fmt.Fprintf(env.stderr, "Unknown command %q\n\n", args[0])
+		printAdminUsage(env.stderr)
+		return 2
+	}
+
+	if err := command.run(env, args[1:]); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(env.stderr, "%s failed: %v\n", command.name, err)
+		return 1
+	}
+	return 0
+}
+
+// printAdminUsage は サブコマンドの一覧を表示します
+func printAdminUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: main <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, command := range adminCommands {
+		fmt.Fprintf(w, "  %-16s %s\n", command.name, command.summary)
+	}
+	fmt.Fprintln(w, "\nRun 'main <command> -h' for the flags of each command.")
+}
+
+// newAdminFlagSet は 引数の誤りを stderr に表示する FlagSet を作成します
+func newAdminFlagSet(env *adminEnv, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	return fs
+}
+
+// usageError は 引数の誤りを表示し、errUsage を返します
+func usageError(env *adminEnv, fs *flag.FlagSet, message string) error {
+	fmt.Fprintf(env.stderr, "%s: %s\n", fs.Name(), message)
+	fs.Usage()
+	return errUsage
+}
+
+// readPassword は 標準入力の1行目をパスワードとして読み取ります（コマンドライン引数に渡すとシェルの履歴に残るため）
+func readPassword(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	return password, nil
+}
+
+// runCreateUser は ユーザーを作成します
+// 例: echo 'secret123' | main create-user --email admin@example.com --name Admin
+func runCreateUser(env *adminEnv, args []string) error {
+	fs := newAdminFlagSet(env, "create-user")
+	email := fs.String("email", "", "メールアドレス（必須）")
+	name := fs.String("name", "", "表示名")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if *email == "" {
+		return usageError(env, fs, "--email is required")
+	}
+
+	plain, err := readPassword(env.stdin)
+	if err != nil {
+		return err
+	}
+	deps, err := env.deps()
+	if err != nil {
+		return err
+	}
+
+	hashed, err := deps.PasswordHasher.Hash(plain)
+	if err != nil {
+		return err
+	}
+	user := &models.User{Email: *email, PasswordHash: hashed, Name: *name}
+	if err := deps.UserRepository.Create(user); err != nil {
+		if errors.Is(err, apierror.ErrConflict) {
+			return fmt.Errorf("user %s already exists", *email)
+		}
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "Created user id=%d email=%s\n", user.ID, user.Email)
+	if isAdmin, err := deps.AdminService.IsAdmin(user.ID); err == nil && !isAdmin {
+		fmt.Fprintln(env.stdout, "To grant admin access, add the email to ADMIN_EMAILS.")
+	}
+	return nil
+}
+
+// runResetPassword は ユーザーのパスワードを変更します
+// 例: echo 'newsecret' | main reset-password --email alice@example.com
+func runResetPassword(env *adminEnv, args []string) error {
+	fs := newAdminFlagSet(env, "reset-password")
+	email := fs.String("email", "", "メールアドレス（必須）")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if *email == "" {
+		return usageError(env, fs, "--email is required")
+	}
+
+	plain, err := readPassword(env.stdin)
+	if err != nil {
+		return err
+	}
+	deps, err := env.deps()
+	if err != nil {
+		return err
+	}
+
+	user, err := deps.UserRepository.GetByEmail(*email)
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return fmt.Errorf("user %s not found", *email)
+		}
+		return err
+	}
+	hashed, err := deps.PasswordHasher.Hash(plain)
+	if err != nil {
+		return err
+	}
+	if err := deps.UserRepository.UpdatePassword(user.ID, hashed); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stdout, "Updated password for user id=%d email=%s\n", user.ID, user.Email)
+	return nil
+}
+
+// runPurgeTrash は ごみ箱の文書を完全に削除します
+// --older-than を指定した場合は、ごみ箱に移動してからその期間が経過した文書のみを削除する
+// 例: main purge-trash --all --older-than 720h --dry-run
+func runPurgeTrash(env *adminEnv, args []string) error {
+	fs := newAdminFlagSet(env, "purge-trash")
+	email := fs.String("email", "", "対象のユーザーのメールアドレス")
+	all := fs.Bool("all", false, "ごみ箱に文書がある全てのユーザーを対象にする")
+	olderThan := fs.Duration("older-than", 0, "ごみ箱に移動してからこの期間が経過した文書のみを削除する（例: 720h）")
+	dryRun := fs.Bool("dry-run", false, "削除せずに件数のみを表示する")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if (*email == "") == !*all {
+		return usageError(env, fs, "specify either --email or --all")
+	}
+	if *olderThan < 0 {
+		return usageError(env, fs, "--older-than must not be negative")
+	}
+
+	deps, err := env.deps()
+	if err != nil {
+		return err
+	}
+
+	var userIDs []int
+	if *all {
+		if userIDs, err = deps.TrashRepository.ListTrashOwnerIDs(); err != nil {
+			return err
+		}
+	} else {
+		user, err := deps.UserRepository.GetByEmail(*email)
+		if err != nil {
+			if errors.Is(err, apierror.ErrNotFound) {
+				return fmt.Errorf("user %s not found", *email)
+			}
+			return err
+		}
+		userIDs = []int{user.ID}
+	}
+
+	var filter models.TrashFilter
+	if *olderThan > 0 {
+		cutoff := time.Now().Add(-*olderThan)
+		filter.DeletedTo = &cutoff
+	}
+
+	var purged, failed int
+	for _, userID := range userIDs {
+		// 一覧の API と異なり件数を制限せずに取得する
+		trashed, err := deps.TrashRepository.GetTrashedDocuments(userID, filter, pagination.Params{})
+		if err != nil {
+			return err
+		}
+		if len(trashed.Items) == 0 {
+			continue
+		}
+		if *dryRun {
+			fmt.Fprintf(env.stdout, "user id=%d: %d documents\n", userID, len(trashed.Items))
+			purged += len(trashed.Items)
+			continue
+		}
+
+		// 期間の指定がない場合はごみ箱全体を1つのトランザクションで空にする
+		if *olderThan == 0 {
+			if err := deps.DocumentService.EmptyTrash(userID); err != nil {
+				fmt.Fprintf(env.stderr, "user id=%d: failed to empty trash: %v\n", userID, err)
+				failed++
+				continue
+			}
+			purged += len(trashed.Items)
+			fmt.Fprintf(env.stdout, "user id=%d: purged %d documents\n", userID, len(trashed.Items))
+			continue
+		}
+
+		count := 0
+		for _, doc := range trashed.Items {
+			if err := deps.DocumentService.PermanentDeleteDocument(doc.ID, userID); err != nil {
+				fmt.Fprintf(env.stderr, "document id=%d: %v\n", doc.ID, err)
+				failed++
+				continue
+			}
+			count++
+		}
+		purged += count
+		fmt.Fprintf(env.stdout, "user id=%d: purged %d documents\n", userID, count)
+	}
+
+	if *dryRun {
+		fmt.Fprintf(env.stdout, "Dry run: %d documents would be purged\n", purged)
+		return nil
+	}
+	fmt.Fprintf(env.stdout, "Purged %d documents\n", purged)
+	if failed > 0 {
+		return fmt.Errorf("%d deletions failed", failed)
+	}
+	return nil
+}
+
+// runReindexSearch は ごみ箱以外の全ての文書について全文検索用のテキストを作り直します
+// テキストの取り出し方を変えた後や、保存時の更新に失敗していた文書を直すために使う
+func runReindexSearch(env *adminEnv, args []string) error {
+	fs := newAdminFlagSet(env, "reindex-search")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	deps, err := env.deps()
+	if err != nil {
+		return err
+	}
+
+	var indexed, failed, afterID int
+	for {
+		documents, err := deps.DocumentCoreRepository.ListDocumentsForReindex(env.ctx, afterID, reindexBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(documents) == 0 {
+			break
+		}
+
+		for _, ref := range documents {
+			afterID = ref.ID
+			doc, err := deps.DocumentService.GetDocumentWithBlocks(ref.ID, ref.UserID)
+			if err == nil {
+				err = deps.DocumentService.UpdateSearchText(doc.ID, document.DocumentSearchText(doc))
+			}
+			if err != nil {
+				fmt.Fprintf(env.stderr, "document id=%d: %v\n", ref.ID, err)
+				failed++
+				continue
+			}
+			indexed++
+		}
+		fmt.Fprintf(env.stdout, "Reindexed %d documents (last id=%d)\n", indexed, afterID)
+	}
+
+	fmt.Fprintf(env.stdout, "Reindexed %d documents\n", indexed)
+	if failed > 0 {
+		return fmt.Errorf("%d documents failed", failed)
+	}
+	return nil
+}
+
+// runStorageReport は インスタンス全体のストレージ使用量（GET /api/admin/storage/usage と同じ集計）を表示します
+func runStorageReport(env *adminEnv, args []string) error {
+	fs := newAdminFlagSet(env, "storage-report")
+	asJSON := fs.Bool("json", false, "JSON で出力する")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	deps, err := env.deps()
+	if err != nil {
+		return err
+	}
+
+	report, err := deps.FileService.GetStorageUsageReport(env.ctx, env.cfg.UserStorageQuota)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(env.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	writeStorageReport(env.stdout, report)
+	return nil
+}
+
+// writeStorageReport は ストレージ使用量の集計を表形式で書き出します
+func writeStorageReport(w io.Writer, report *models.StorageUsageReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "Generated at\t%s\n", report.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(tw, "Total\t%s\t%d files\t%d users\n", formatBytes(report.TotalBytes), report.FileCount, report.UserCount)
+	fmt.Fprintf(tw, "Orphaned\t%s\n", formatBytes(report.OrphanedBytes))
+	fmt.Fprintf(tw, "Pending purge\t%s\t%d files\n", formatBytes(report.PendingPurgeBytes), report.PendingPurgeCount)
+	fmt.Fprintf(tw, "Old versions\t%s\t%d versions\n", formatBytes(report.VersionBytes), report.VersionCount)
+	fmt.Fprintf(tw, "Over quota\t%d users\n", report.QuotaViolationCount)
+
+	fmt.Fprintln(tw, "\nBy type\tSize\tFiles")
+	for _, usage := range report.ByType {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", usage.FileType, formatBytes(usage.TotalBytes), usage.FileCount)
+	}
+
+	writeUserEntries(tw, "Top consumers", report.TopConsumers)
+	writeUserEntries(tw, "Quota violations", report.QuotaViolations)
+}
+
+// writeUserEntries は ユーザーごとの使用量とクォータを書き出します
+func writeUserEntries(w io.Writer, title string, entries []models.UserStorageEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\tUsed\tQuota\tFiles\n", title)
+	for _, entry := range entries {
+		quota := formatBytes(entry.QuotaBytes)
+		if entry.IsDefault {
+			quota += " (default)"
+		}
+		fmt.Fprintf(w, "%s (id=%d)\t%s\t%s\t%d\n", entry.Email, entry.UserID, formatBytes(entry.UsedBytes), quota, entry.FileCount)
+	}
+}
+
+// formatBytes は バイト数を KiB・MiB などの単位で表します
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"simple-notion-backend/internal/config"
+)
+
+// newTestAdminEnv は データベースに接続しない（引数の検証までで終わる）テスト用の adminEnv を作成します
+func newTestAdminEnv(stdin string) (*adminEnv, *bytes.Buffer) {
+	stderr := &bytes.Buffer{}
+	return &adminEnv{
+		ctx:    context.Background(),
+		stdin:  strings.NewReader(stdin),
+		stdout: &bytes.Buffer{},
+		stderr: stderr,
+		cfg:    &config.Config{},
+	}, stderr
+}
+
+// TestRunAdminCommandUsage - 引数の誤りを終了コード 2 で返すことのテスト
+func TestRunAdminCommandUsage(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantStderr string
+	}{
+		{name: "異常系：サブコマンドなし", args: nil, wantStderr: "Commands:"},
+		{name: "異常系：不明なサブコマンド", args: []string{"drop-database"}, wantStderr: `Unknown command "drop-database"`},
+		{name: "異常系：不明なフラグ", args: []string{"storage-report", "--csv"}, wantStderr: "flag provided but not defined"},
+		{name: "異常系：create-user のメールアドレスなし", args: []string{"create-user", "--name", "Alice"}, wantStderr: "--email is required"},
+		{name: "異常系：reset-password のメールアドレスなし", args: []string{"reset-password"}, wantStderr: "--email is required"},
+		{name: "異常系：purge-trash の対象なし", args: []string{"purge-trash"}, wantStderr: "specify either --email or --all"},
+		{name: "異常系：purge-trash の対象を両方指定", args: []string{"purge-trash", "--all", "--email", "a@example.com"}, wantStderr: "specify either --email or --all"},
+		{name: "異常系：purge-trash の期間が負", args: []string{"purge-trash", "--all", "--older-than", "-1h"}, wantStderr: "--older-than must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, stderr := newTestAdminEnv("")
+			if code := runAdminCommand(env, tt.args); code != 2 {
+				t.Errorf("runAdminCommand(%v) = %d, want 2", tt.args, code)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want to contain %q", stderr.String(), tt.wantStderr)
+			}
+			if env.database != nil {
+				t.Error("database connected, want no connection on usage error")
+			}
+		})
+	}
+}
+
+// TestRunAdminCommandShortPassword - 短いパスワードはデータベースに接続する前に失敗することのテスト
+func TestRunAdminCommandShortPassword(t *testing.T) {
+	env, stderr := newTestAdminEnv("abc\n")
+	if code := runAdminCommand(env, []string{"create-user", "--email", "a@example.com"}); code != 1 {
+		t.Errorf("runAdminCommand() = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "at least 6 characters") || env.database != nil {
+		t.Errorf("stderr = %q, database = %v, want password error without connection", stderr.String(), env.database)
+	}
+}
+
+// TestReadPassword - 標準入力からのパスワードの読み取りのテスト
+func TestReadPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "正常系：改行まで", input: "secret123\nignored\n", want: "secret123"},
+		{name: "正常系：CRLF", input: "secret123\r\n", want: "secret123"},
+		{name: "正常系：改行なし", input: "secret123", want: "secret123"},
+		{name: "正常系：前後の空白は残す", input: " secret \n", want: " secret "},
+		{name: "異常系：空", input: "", wantErr: true},
+		{name: "異常系：短い", input: "abc\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPassword(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readPassword() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFormatBytes - バイト数の表示のテスト
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0 B"},
+		{n: 1023, want: "1023 B"},
+		{n: 1536, want: "1.5 KiB"},
+		{n: 5 * 1024 * 1024 * 1024, want: "5.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"simple-notion-backend/internal/config"
+	"simple-notion-backend/internal/tracing"
 )
 
 // Application は、アプリケーション全体を管理する構造体です
@@ -19,7 +24,9 @@ type Application struct {
 	server       *Server
 	logger       *Logger
 	metrics      *Metrics
+	tracer       *tracing.Tracer // OTEL_EXPORTER_OTLP_ENDPOINT が空の場合は nil
 	lifecycle    *LifecycleManager
+	scheduler    *Scheduler
 }
 
 // New は、新しいApplicationインスタンスを作成します
@@ -46,6 +53,11 @@ func New() (*Application, error) {
 		return nil, fmt.Errorf("failed to initialize lifecycle: %w", err)
 	}
 
+	// 分散トレーシングの初期化（データベースの接続に計装するため、接続より前に行う）
+	if err := app.initializeTracing(); err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+
 	// データベース接続
 	if err := app.connectDatabase(); err != nil {
 		return nil, fmt.Errorf("failed to connect database: %w", err)
@@ -56,6 +68,11 @@ func New() (*Application, error) {
 		return nil, fmt.Errorf("failed to initialize dependencies: %w", err)
 	}
 
+	// バックグラウンドジョブの初期化
+	if err := app.initializeScheduler(); err != nil {
+		return nil, fmt.Errorf("failed to initialize scheduler: %w", err)
+	}
+
 	// サーバーの初期化
 	if err := app.initializeServer(); err != nil {
 		return nil, fmt.Errorf("failed to initialize server: %w", err)
@@ -64,10 +81,10 @@ func New() (*Application, error) {
 	return app, nil
 }
 
-// loadConfig は、設定を読み込みます
+// loadConfig は、設定を読み込み、不足・不正な項目や環境に対して安全でない項目があれば起動を中止します
 func (a *Application) loadConfig() error {
 	a.config = config.Load()
-	return nil
+	return a.config.Validate()
 }
 
 // initializeMetrics は、メトリクスを初期化します
@@ -110,13 +127,47 @@ func (a *Application) initializeLifecycle() error {
 	return nil
 }
 
+// initializeTracing は、分散トレーシングを初期化します（エンドポイントが空の場合は無効）
+func (a *Application) initializeTracing() error {
+	if a.config.OTLPEndpoint == "" {
+		return nil
+	}
+
+	var err error
+	a.tracer, err = tracing.New(tracing.Options{
+		Endpoint:    a.config.OTLPEndpoint,
+		ServiceName: a.config.TracingServiceName,
+		SampleRatio: a.config.TracingSampleRatio,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 送信待ちのスパンを送り終えてから終了する
+	a.lifecycle.AddShutdownHook(func(ctx context.Context) error {
+		a.logger.Info("Flushing trace queue")
+		return a.tracer.Shutdown(ctx)
+	})
+
+	a.logger.Info("Tracing enabled", map[string]interface{}{
+		"endpoint":     a.config.OTLPEndpoint,
+		"sample_ratio": a.config.TracingSampleRatio,
+	})
+	return nil
+}
+
 // connectDatabase は、データベースに接続します
+// トレーシングが有効な場合は、実行する SQL をスパンとして記録する
 func (a *Application) connectDatabase() error {
-	var err error
-	a.database, err = sql.Open("postgres", a.config.DatabaseURL)
+	connector, err := pq.NewConnector(a.config.DatabaseURL)
 	if err != nil {
 		return fmt.Errorf("failed to open database connection: %w", err)
 	}
+	if a.tracer != nil {
+		a.database = sql.OpenDB(tracing.WrapConnector(connector))
+	} else {
+		a.database = sql.OpenDB(connector)
+	}
 
 	// 接続の確認
 	if err := a.database.Ping(); err != nil {
@@ -136,15 +187,309 @@ func (a *Application) connectDatabase() error {
 // initializeDependencies は、依存関係を初期化します
 func (a *Application) initializeDependencies() error {
 	var err error
-	a.dependencies, err = NewDependencies(a.config, a.database)
+	a.dependencies, err = NewDependencies(a.config, a.database, a.tracer)
 	if err != nil {
 		return fmt.Errorf("failed to create dependencies: %w", err)
 	}
 
+	// パスワードのハッシュ化・検証の所要時間をメトリクスに記録
+	a.dependencies.PasswordHasher.SetObserver(a.metrics)
+
+	// 送信待ちのメールを送り終えてから終了する
+	if a.dependencies.Mailer != nil {
+		a.lifecycle.AddShutdownHook(func(ctx context.Context) error {
+			a.logger.Info("Flushing mail queue")
+			return a.dependencies.Mailer.Close(ctx)
+		})
+	}
+
 	a.logger.Info("Dependencies initialized")
 	return nil
 }
 
+// initializeScheduler は、バックグラウンドジョブのスケジューラーを初期化します
+func (a *Application) initializeScheduler() error {
+	a.scheduler = NewScheduler(a.logger)
+
+	// 予約公開/非公開の反映（間隔が 0 以下の場合は無効）
+	if a.config.PublishScheduleInterval > 0 {
+		interval := time.Duration(a.config.PublishScheduleInterval) * time.Second
+		a.scheduler.AddJob("publication_schedule", interval, func(ctx context.Context) error {
+			published, unpublished, err := a.dependencies.PublicationService.ApplySchedules()
+			if err != nil {
+				return err
+			}
+			if published > 0 || unpublished > 0 {
+				a.logger.Info("Applied scheduled publication changes", map[string]interface{}{
+					"published":   published,
+					"unpublished": unpublished,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 文書の有効期限（事前通知と期限到達時の削除）
+	if a.config.ExpiryCheckInterval > 0 {
+		interval := time.Duration(a.config.ExpiryCheckInterval) * time.Second
+		a.scheduler.AddJob("document_expiration", interval, func(ctx context.Context) error {
+			warned, expired, err := a.dependencies.ExpirationService.ProcessExpirations(ctx)
+			if err != nil {
+				return err
+			}
+			if warned > 0 || expired > 0 {
+				a.logger.Info("Processed document expirations", map[string]interface{}{
+					"warned":  warned,
+					"expired": expired,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 文書を開いているユーザーの記録のうち、確認できなくなったタブの削除と通知
+	if a.config.PresenceTimeout > 0 {
+		interval := time.Duration(a.config.PresenceTimeout) * time.Second / 2
+		a.scheduler.AddJob("presence_expiration", interval, func(ctx context.Context) error {
+			if expired := a.dependencies.PresenceService.ExpireStale(); expired > 0 {
+				a.logger.Info("Expired stale document viewers", map[string]interface{}{
+					"expired": expired,
+				})
+			}
+			return nil
+		})
+	}
+
+	// リマインダーの配信
+	if a.config.ReminderCheckInterval > 0 {
+		interval := time.Duration(a.config.ReminderCheckInterval) * time.Second
+		a.scheduler.AddJob("reminder_delivery", interval, func(ctx context.Context) error {
+			delivered, err := a.dependencies.ReminderService.DeliverDueReminders(ctx)
+			if err != nil {
+				return err
+			}
+			if delivered > 0 {
+				a.logger.Info("Delivered reminders", map[string]interface{}{
+					"delivered": delivered,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 孤立データの検出（修復は管理者が API から明示的に行う）
+	if a.config.OrphanScanInterval > 0 {
+		interval := time.Duration(a.config.OrphanScanInterval) * time.Second
+		a.scheduler.AddJob("orphan_scan", interval, func(ctx context.Context) error {
+			report, err := a.dependencies.MaintenanceService.ScanOrphans()
+			if err != nil {
+				return err
+			}
+			if report.OrphanedBlockCount > 0 || report.DanglingFileCount > 0 {
+				a.logger.Warn("Orphaned data detected", map[string]interface{}{
+					"orphaned_blocks": report.OrphanedBlockCount,
+					"dangling_files":  report.DanglingFileCount,
+				})
+			}
+			return nil
+		})
+
+		// ストレージとデータベースの突き合わせ（記録のないオブジェクト・オブジェクトのないファイルの検出）
+		a.scheduler.AddJob("storage_consistency_scan", interval, func(ctx context.Context) error {
+			report, err := a.dependencies.MaintenanceService.ScanStorage(ctx)
+			if err != nil {
+				return err
+			}
+			if report.UnreferencedObjectCount > 0 || report.MissingObjectCount > 0 {
+				a.logger.Warn("Storage inconsistency detected", map[string]interface{}{
+					"unreferenced_objects":      report.UnreferencedObjectCount,
+					"unreferenced_object_bytes": report.UnreferencedObjectSize,
+					"missing_objects":           report.MissingObjectCount,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 管理者向け統計の再集計（API はキャッシュを返す）
+	if a.config.StatsRefreshInterval > 0 {
+		interval := time.Duration(a.config.StatsRefreshInterval) * time.Second
+		a.scheduler.AddJob("admin_stats", interval, func(ctx context.Context) error {
+			_, err := a.dependencies.StatsService.Refresh()
+			return err
+		})
+	}
+
+	// 画像のサムネイルの作成（アップロード時は作成待ちとして記録するだけ）
+	if a.config.ThumbnailWorkerInterval > 0 {
+		interval := time.Duration(a.config.ThumbnailWorkerInterval) * time.Second
+		a.scheduler.AddJob("thumbnail_generation", interval, func(ctx context.Context) error {
+			generated, err := a.dependencies.ThumbnailService.ProcessPending(ctx)
+			if err != nil {
+				return err
+			}
+			if generated > 0 {
+				a.logger.Info("Generated thumbnails", map[string]interface{}{
+					"generated": generated,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 期限切れの分割アップロードの破棄
+	if a.config.UploadSessionCleanupInterval > 0 {
+		interval := time.Duration(a.config.UploadSessionCleanupInterval) * time.Second
+		a.scheduler.AddJob("upload_session_cleanup", interval, func(ctx context.Context) error {
+			discarded, err := a.dependencies.UploadSessionService.CleanupExpiredSessions(ctx)
+			if err != nil {
+				return err
+			}
+			if discarded > 0 {
+				a.logger.Info("Discarded expired upload sessions", map[string]interface{}{
+					"discarded": discarded,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 削除済みファイルの完全削除（保持期間の経過後にストレージとデータベースから削除し、失敗したものは後で再試行）
+	if a.config.FilePurgeInterval > 0 {
+		interval := time.Duration(a.config.FilePurgeInterval) * time.Second
+		a.scheduler.AddJob("file_purge", interval, func(ctx context.Context) error {
+			start := time.Now()
+			result, err := a.dependencies.FilePurgeService.PurgeDeletedFiles(ctx)
+			a.metrics.ObserveLatency("file_purge", time.Since(start))
+			a.metrics.AddCount("file_purge_purged", int64(result.Purged))
+			a.metrics.AddCount("file_purge_failed", int64(result.Failed))
+			if err != nil {
+				return err
+			}
+			if result.Purged > 0 || result.Failed > 0 {
+				a.logger.Info("Purged deleted files", map[string]interface{}{
+					"purged": result.Purged,
+					"failed": result.Failed,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 新しい版で置き換えた以前の版の削除（保持期間の経過後にストレージとデータベースから削除）
+	if a.config.FilePurgeInterval > 0 {
+		interval := time.Duration(a.config.FilePurgeInterval) * time.Second
+		a.scheduler.AddJob("file_version_purge", interval, func(ctx context.Context) error {
+			result, err := a.dependencies.FilePurgeService.PurgeExpiredVersions(ctx)
+			a.metrics.AddCount("file_version_purge_purged", int64(result.Purged))
+			a.metrics.AddCount("file_version_purge_failed", int64(result.Failed))
+			if err != nil {
+				return err
+			}
+			if result.Purged > 0 || result.Failed > 0 {
+				a.logger.Info("Purged expired file versions", map[string]interface{}{
+					"purged": result.Purged,
+					"failed": result.Failed,
+				})
+			}
+			return nil
+		})
+	}
+
+	// Webhook の配信（送信する時刻になった配信を送信し、失敗したものは間隔を倍にしながら再試行）
+	if a.config.WebhookWorkerInterval > 0 {
+		interval := time.Duration(a.config.WebhookWorkerInterval) * time.Second
+		a.scheduler.AddJob("webhook_delivery", interval, func(ctx context.Context) error {
+			result, err := a.dependencies.WebhookService.DeliverDue(ctx)
+			a.metrics.AddCount("webhook_delivery_succeeded", int64(result.Succeeded))
+			a.metrics.AddCount("webhook_delivery_retrying", int64(result.Retrying))
+			a.metrics.AddCount("webhook_delivery_failed", int64(result.Failed))
+			if err != nil {
+				return err
+			}
+			if result.Retrying > 0 || result.Failed > 0 {
+				a.logger.Info("Delivered webhooks", map[string]interface{}{
+					"succeeded": result.Succeeded,
+					"retrying":  result.Retrying,
+					"failed":    result.Failed,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 送信を終えた Webhook の配信の記録の削除（保持期間の経過後、1時間ごと）
+	if a.config.WebhookDeliveryRetention > 0 {
+		retention := time.Duration(a.config.WebhookDeliveryRetention) * time.Second
+		a.scheduler.AddJob("webhook_delivery_prune", time.Hour, func(ctx context.Context) error {
+			pruned, err := a.dependencies.WebhookService.PruneDeliveries(retention)
+			if err != nil {
+				return err
+			}
+			if pruned > 0 {
+				a.logger.Info("Pruned webhook deliveries", map[string]interface{}{
+					"pruned": pruned,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 古い文書のバージョンの間引きと削除（保存のたびに記録するため、1時間ごとに整理する）
+	if a.config.DocumentVersionMergeAfter > 0 || a.config.DocumentVersionRetention > 0 {
+		mergeAfter := time.Duration(a.config.DocumentVersionMergeAfter) * time.Second
+		mergeWindow := time.Duration(a.config.DocumentVersionMergeWindow) * time.Second
+		retention := time.Duration(a.config.DocumentVersionRetention) * time.Second
+		a.scheduler.AddJob("document_version_prune", time.Hour, func(ctx context.Context) error {
+			result, err := a.dependencies.VersionService.PruneVersions(mergeAfter, mergeWindow, retention)
+			if err != nil {
+				return err
+			}
+			if result.Merged > 0 || result.Expired > 0 {
+				a.logger.Info("Pruned document versions", map[string]interface{}{
+					"merged":  result.Merged,
+					"expired": result.Expired,
+				})
+			}
+			return nil
+		})
+	}
+
+	// 期限切れのログイン失敗記録の削除（メモリ使用量を抑える）
+	if a.dependencies.LoginThrottle != nil && a.config.LoginFailureWindow > 0 {
+		interval := time.Duration(a.config.LoginFailureWindow) * time.Second
+		a.scheduler.AddJob("login_throttle_prune", interval, func(ctx context.Context) error {
+			a.dependencies.LoginThrottle.Prune(time.Now())
+			return nil
+		})
+	}
+
+	// 購読している文書の変更をまとめたメールの送信（メールを送信しない場合は無効）
+	if a.dependencies.Mailer != nil && a.config.WatchDigestInterval > 0 {
+		interval := time.Duration(a.config.WatchDigestInterval) * time.Second
+		a.scheduler.AddJob("watch_digest", interval, func(ctx context.Context) error {
+			sent, err := a.dependencies.WatchService.SendDigests(ctx)
+			if err != nil {
+				return err
+			}
+			if sent > 0 {
+				a.logger.Info("Sent document watch digests", map[string]interface{}{
+					"sent": sent,
+				})
+			}
+			return nil
+		})
+	}
+
+	// スケジューラーのシャットダウンフックを追加
+	a.lifecycle.AddShutdownHook(func(ctx context.Context) error {
+		a.logger.Info("Stopping scheduler")
+		return a.scheduler.Stop(ctx)
+	})
+
+	return nil
+}
+
 // initializeServer は、HTTPサーバーを初期化します
 func (a *Application) initializeServer() error {
 	var err error
@@ -164,6 +509,9 @@ func (a *Application) Run() error {
 	// ライフサイクル管理を開始
 	a.lifecycle.Start()
 
+	// バックグラウンドジョブを開始
+	a.scheduler.Start()
+
 	// サーバーをバックグラウンドで起動
 	serverErr := make(chan error, 1)
 	go func() {
@@ -204,23 +552,36 @@ func (a *Application) HealthCheck() error {
 	return nil
 }
 
-// RunHealthCheck は、ヘルスチェックのみを実行する静的メソッドです
-func RunHealthCheck() int {
+// healthProbePaths は、--health で呼び出すプローブと、そのエンドポイントです
+var healthProbePaths = map[string]string{
+	"live":  "/healthz",
+	"ready": "/readyz",
+}
+
+// RunHealthCheck は、起動中のサーバーのプローブ用エンドポイントを呼び出し、結果を終了コードで返します（コンテナのヘルスチェック用）
+// probe: "live"（/healthz、プロセスが応答するか。再起動の判定に使う）または "ready"（/readyz、DB・ストレージ・マイグレーションも確認する。リクエストを受け付けるかの判定に使う）
+func RunHealthCheck(probe string) int {
+	path, ok := healthProbePaths[probe]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Health check failed - unknown probe %q (use live or ready)\n", probe)
+		return 2
+	}
 	cfg := config.Load()
 
-	// データベース接続確認
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get("http://127.0.0.1:" + cfg.Port + path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Health check failed - database connection: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Health check failed - %s: %v\n", path, err)
 		return 1
 	}
-	defer db.Close()
+	defer resp.Body.Close()
 
-	if err := db.Ping(); err != nil {
-		fmt.Fprintf(os.Stderr, "Health check failed - database ping: %v\n", err)
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "Health check failed - %s returned %d: %s\n", path, resp.StatusCode, strings.TrimSpace(string(body)))
 		return 1
 	}
 
-	fmt.Println("Health check passed")
+	fmt.Printf("Health check passed (%s)\n", probe)
 	return 0
 }
@@ -3,14 +3,36 @@ package app
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
+	"simple-notion-backend/internal/cache"
 	"simple-notion-backend/internal/config"
+	"simple-notion-backend/internal/contentpolicy"
+	"simple-notion-backend/internal/events"
 	"simple-notion-backend/internal/handlers"
+	"simple-notion-backend/internal/handlers/admin"
+	"simple-notion-backend/internal/handlers/blocktype"
+	"simple-notion-backend/internal/handlers/comment"
 	"simple-notion-backend/internal/handlers/document"
+	"simple-notion-backend/internal/handlers/embed"
+	"simple-notion-backend/internal/handlers/graph"
+	"simple-notion-backend/internal/handlers/notification"
+	"simple-notion-backend/internal/handlers/reminder"
+	"simple-notion-backend/internal/handlers/share"
+	"simple-notion-backend/internal/handlers/task"
 	"simple-notion-backend/internal/handlers/upload"
+	"simple-notion-backend/internal/handlers/webhook"
+	"simple-notion-backend/internal/handlers/workspace"
+	"simple-notion-backend/internal/mailer"
+	"simple-notion-backend/internal/mediaprobe"
+	"simple-notion-backend/internal/mimetype"
+	"simple-notion-backend/internal/password"
+	"simple-notion-backend/internal/ratelimit"
 	"simple-notion-backend/internal/repository"
+	"simple-notion-backend/internal/rpc"
 	"simple-notion-backend/internal/services"
 	"simple-notion-backend/internal/storage"
+	"simple-notion-backend/internal/tracing"
 )
 
 // Dependencies は、アプリケーションの全ての依存関係を管理する構造体です
@@ -19,31 +41,129 @@ type Dependencies struct {
 	Database *sql.DB
 
 	// Repositories
-	UserRepository         *repository.UserRepository
-	DocumentCoreRepository *repository.DocumentCoreRepository
-	BlockRepository        *repository.BlockRepository
-	TreeRepository         *repository.DocumentTreeRepository
-	TrashRepository        *repository.DocumentTrashRepository
-	FileRepository         *repository.FileRepository
+	UserRepository            *repository.UserRepository
+	DocumentCoreRepository    *repository.DocumentCoreRepository
+	BlockRepository           *repository.BlockRepository
+	TreeRepository            *repository.DocumentTreeRepository
+	TrashRepository           *repository.DocumentTrashRepository
+	FileRepository            *repository.FileRepository
+	FileVersionRepository     *repository.FileVersionRepository
+	VersionRepository         *repository.DocumentVersionRepository
+	PublicationRepository     *repository.PublicationRepository
+	SecurityEventRepository   *repository.SecurityEventRepository
+	NotificationRepository    *repository.NotificationRepository
+	ExpirationRepository      *repository.ExpirationRepository
+	ReminderRepository        *repository.ReminderRepository
+	TaskRepository            *repository.TaskRepository
+	CommentRepository         *repository.BlockCommentRepository
+	BlockTypeRepository       *repository.CustomBlockTypeRepository
+	MaintenanceRepository     *repository.MaintenanceRepository
+	StatsRepository           *repository.StatsRepository
+	UploadSessionRepository   *repository.UploadSessionRepository
+	WorkspaceRepository       *repository.WorkspaceRepository
+	InvitationRepository      *repository.WorkspaceInvitationRepository
+	DocumentCommentRepository *repository.DocumentCommentRepository
+	MentionRepository         *repository.MentionRepository
+	ShareLinkRepository       *repository.ShareLinkRepository
+	CollaboratorRepository    *repository.CollaboratorRepository
+	WatchRepository           *repository.DocumentWatchRepository
+	WebhookRepository         *repository.WebhookRepository
 
 	// Services
-	DocumentService *services.DocumentService
-	FileService     *services.FileService
+	DocumentService        *services.DocumentService
+	FileService            *services.FileService
+	VersionService         *services.VersionService
+	PublicationService     *services.PublicationService
+	SecurityEventService   *services.SecurityEventService
+	NotificationService    *services.NotificationService
+	ExpirationService      *services.ExpirationService
+	ReminderService        *services.ReminderService
+	TaskService            *services.TaskService
+	CommentService         *services.BlockCommentService
+	BlockTypeService       *services.BlockTypeService
+	AdminService           *services.AdminService
+	MaintenanceService     *services.MaintenanceService
+	StatsService           *services.StatsService
+	LinkPreviewService     *services.LinkPreviewService
+	EmbedService           *services.EmbedService
+	UploadSessionService   *services.UploadSessionService
+	UploadProgress         *services.UploadProgressTracker
+	DocumentEvents         *services.DocumentEventBroker
+	PresenceService        *services.PresenceService
+	ThumbnailService       *services.ThumbnailService
+	FilePurgeService       *services.FilePurgeService
+	FileVersionService     *services.FileVersionService
+	WorkspaceService       *services.WorkspaceService
+	InvitationService      *services.WorkspaceInvitationService
+	DocumentCommentService *services.DocumentCommentService
+	MentionService         *services.MentionService
+	ShareLinkService       *services.ShareLinkService
+	CollaboratorService    *services.CollaboratorService
+	WatchService           *services.DocumentWatchService
+	WebhookService         *services.WebhookService
+
+	// Event Bus（サービスが発行するイベント。Webhook の配信が購読する）
+	EventBus *events.Bus
 
 	// Storage
 	ObjectStorage storage.ObjectStorage
+	LocalStorage  *storage.LocalStorage // ローカルディスクを保存先にした場合のみ
+
+	// Cache（署名付きURL）
+	URLCache cache.Cache
+
+	// Rate Limiter（IP アドレス・ユーザーごとのリクエストの頻度の制限）
+	RateLimiter ratelimit.Limiter
+
+	// Tracing（OTEL_EXPORTER_OTLP_ENDPOINT を設定した場合のみ）
+	Tracer *tracing.Tracer
+
+	// Mail（SMTP_HOST を設定した場合のみ）
+	Mailer *mailer.Mailer
+
+	// Password Hashing / Login Throttling
+	PasswordHasher *password.Hasher
+	LoginThrottle  *services.LoginThrottle
+
+	// Content Policy
+	ContentPolicy  *contentpolicy.Pipeline
+	SecretsChecker contentpolicy.Checker
 
 	// Handlers
-	AuthHandler     *handlers.AuthHandler
-	DocumentHandler *document.DocumentHandler
-	UploadHandler   *upload.UploadHandler
+	AuthHandler            *handlers.AuthHandler
+	DocumentHandler        *document.DocumentHandler
+	UploadHandler          *upload.UploadHandler
+	ResumableHandler       *upload.ResumableUploadHandler
+	ProgressHandler        *upload.ProgressHandler
+	FileVersionHandler     *upload.FileVersionHandler
+	SignedObjectHandler    *upload.SignedObjectHandler // ローカルディスクを保存先にした場合・ストレージを中継する場合のみ
+	NotificationHandler    *notification.NotificationHandler
+	ReminderHandler        *reminder.ReminderHandler
+	TaskHandler            *task.TaskHandler
+	CommentHandler         *comment.CommentHandler
+	EmbedHandler           *embed.EmbedHandler
+	BlockTypeHandler       *blocktype.BlockTypeHandler
+	AdminHandler           *admin.AdminHandler
+	WorkspaceHandler       *workspace.WorkspaceHandler
+	DocumentCommentHandler *comment.DocumentCommentHandler
+	ShareLinkHandler       *share.ShareLinkHandler
+	CollaboratorHandler    *share.CollaboratorHandler
+	WatchHandler           *notification.WatchHandler
+	GraphQLHandler         *graph.GraphQLHandler
+	WebhookHandler         *webhook.WebhookHandler
+	ProfilingHandler       *admin.ProfilingHandler // PPROF_ENABLED の場合のみ
+
+	// gRPC API
+	RPCServer *rpc.Server
 }
 
 // NewDependencies は、データベース接続から全ての依存関係を初期化します
-func NewDependencies(cfg *config.Config, db *sql.DB) (*Dependencies, error) {
+// tracer が nil の場合はトレーシングを無効にする
+func NewDependencies(cfg *config.Config, db *sql.DB, tracer *tracing.Tracer) (*Dependencies, error) {
 	deps := &Dependencies{
 		Config:   cfg,
 		Database: db,
+		Tracer:   tracer,
 	}
 
 	// Repository層の初期化
@@ -100,60 +220,631 @@ func (d *Dependencies) initRepositories() error {
 
 	// File Repository
 	d.FileRepository = repository.NewFileRepository(d.Database)
+	d.FileVersionRepository = repository.NewFileVersionRepository(d.Database)
+
+	// Document Version Repository
+	d.VersionRepository, err = repository.NewDocumentVersionRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create document version repository: %w", err)
+	}
+
+	// Publication Repository
+	d.PublicationRepository, err = repository.NewPublicationRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create publication repository: %w", err)
+	}
+
+	// Security Event Repository
+	d.SecurityEventRepository, err = repository.NewSecurityEventRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create security event repository: %w", err)
+	}
+
+	// Notification Repository
+	d.NotificationRepository, err = repository.NewNotificationRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create notification repository: %w", err)
+	}
+
+	// Expiration Repository
+	d.ExpirationRepository, err = repository.NewExpirationRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create expiration repository: %w", err)
+	}
+
+	// Reminder Repository
+	d.ReminderRepository, err = repository.NewReminderRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create reminder repository: %w", err)
+	}
+
+	// Task Repository
+	d.TaskRepository, err = repository.NewTaskRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create task repository: %w", err)
+	}
+
+	// Block Comment Repository
+	d.CommentRepository, err = repository.NewBlockCommentRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create block comment repository: %w", err)
+	}
+
+	// Custom Block Type Repository
+	d.BlockTypeRepository, err = repository.NewCustomBlockTypeRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create custom block type repository: %w", err)
+	}
+
+	// Maintenance Repository
+	d.MaintenanceRepository, err = repository.NewMaintenanceRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create maintenance repository: %w", err)
+	}
+
+	// Stats Repository
+	d.StatsRepository, err = repository.NewStatsRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create stats repository: %w", err)
+	}
+
+	// Upload Session Repository
+	d.UploadSessionRepository, err = repository.NewUploadSessionRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create upload session repository: %w", err)
+	}
+
+	// Workspace Repository
+	d.WorkspaceRepository, err = repository.NewWorkspaceRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace repository: %w", err)
+	}
+
+	// Workspace Invitation Repository
+	d.InvitationRepository, err = repository.NewWorkspaceInvitationRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace invitation repository: %w", err)
+	}
+
+	// Document Comment Repository
+	d.DocumentCommentRepository, err = repository.NewDocumentCommentRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create document comment repository: %w", err)
+	}
+
+	// Mention Repository
+	d.MentionRepository, err = repository.NewMentionRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create mention repository: %w", err)
+	}
+
+	// Share Link Repository
+	d.ShareLinkRepository, err = repository.NewShareLinkRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create share link repository: %w", err)
+	}
+
+	// Collaborator Repository
+	d.CollaboratorRepository, err = repository.NewCollaboratorRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create collaborator repository: %w", err)
+	}
+
+	// Document Watch Repository
+	d.WatchRepository, err = repository.NewDocumentWatchRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create document watch repository: %w", err)
+	}
+
+	// Webhook Repository
+	d.WebhookRepository, err = repository.NewWebhookRepository(d.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook repository: %w", err)
+	}
 
 	return nil
 }
 
 // initServices は、全てのServiceを初期化します
 func (d *Dependencies) initServices() error {
-	// ObjectStorageの初期化（S3互換ストレージ）
+	// ObjectStorageの初期化（STORAGE_BACKEND で S3互換ストレージ・GCS・Azure Blob・ローカルディスクを切り替える）
 	var err error
-	d.ObjectStorage, err = storage.NewS3Client(
-		d.Config.S3Endpoint,
-		d.Config.S3AccessKey,
-		d.Config.S3SecretKey,
-		d.Config.S3BucketName,
-		d.Config.S3Region,
-		d.Config.S3UseSSL,
-		d.Config.S3ExternalEndpoint,
-	)
+	switch d.Config.StorageBackend {
+	case "local":
+		d.LocalStorage, err = storage.NewLocalStorage(
+			d.Config.LocalStorageDir,
+			d.Config.LocalStorageBaseURL,
+			d.GetJWTSecret(),
+		)
+		d.ObjectStorage = d.LocalStorage
+	case "s3":
+		d.ObjectStorage, err = storage.NewS3Client(
+			d.Config.S3Endpoint,
+			d.Config.S3AccessKey,
+			d.Config.S3SecretKey,
+			d.Config.S3BucketName,
+			d.Config.S3Region,
+			d.Config.S3UseSSL,
+			d.Config.S3ExternalEndpoint,
+		)
+	case "gcs":
+		d.ObjectStorage, err = storage.NewGCSClient(
+			d.Config.GCSAccessID,
+			d.Config.GCSSecret,
+			d.Config.GCSBucketName,
+			d.Config.GCSRegion,
+		)
+	case "azure":
+		d.ObjectStorage, err = storage.NewAzureBlobClient(
+			d.Config.AzureStorageAccount,
+			d.Config.AzureStorageKey,
+			d.Config.AzureStorageContainer,
+			d.Config.AzureStorageEndpoint,
+		)
+	default:
+		err = fmt.Errorf("unknown storage backend %q", d.Config.StorageBackend)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create object storage client: %w", err)
 	}
 
+	// トレーシングが有効な場合は、ストレージへの呼び出しをスパンとして記録する（ローカルディスクは記録しない）
+	if d.Tracer != nil && d.LocalStorage == nil {
+		d.ObjectStorage = storage.NewTracedStorage(d.ObjectStorage)
+	}
+
+	// ブラウザからストレージに接続できない環境では、署名付きURLをバックエンド経由にする（ローカルディスクは元から経由する）
+	if d.Config.StorageProxy && d.LocalStorage == nil {
+		d.ObjectStorage = storage.NewProxyStorage(d.ObjectStorage, d.Config.StorageProxyBaseURL, d.GetJWTSecret())
+	}
+
+	// 署名付きURLのキャッシュ（REDIS_URL を設定した場合は複数のプロセスで共有する）
+	if d.Config.RedisURL != "" {
+		d.URLCache, err = cache.NewRedisCache(d.Config.RedisURL)
+		if err != nil {
+			return fmt.Errorf("failed to create redis cache: %w", err)
+		}
+	} else {
+		d.URLCache = cache.NewMemoryCache()
+	}
+
+	// リクエストの頻度の制限（REDIS_URL を設定した場合は複数のプロセスで共有する）
+	if d.Config.RedisURL != "" {
+		d.RateLimiter, err = ratelimit.NewRedisLimiter(d.Config.RedisURL)
+		if err != nil {
+			return fmt.Errorf("failed to create redis rate limiter: %w", err)
+		}
+	} else {
+		d.RateLimiter = ratelimit.NewMemoryLimiter()
+	}
+
+	// Event Bus と Webhook Service（イベントを購読している Webhook ごとに配信を記録し、バックグラウンドで送信）
+	d.EventBus = events.NewBus()
+	d.WebhookService = services.NewWebhookService(d.WebhookRepository, services.WebhookOptions{
+		MaxAttempts:          d.Config.WebhookMaxAttempts,
+		RetryDelay:           time.Duration(d.Config.WebhookRetryDelay) * time.Second,
+		Timeout:              time.Duration(d.Config.WebhookTimeout) * time.Second,
+		AllowPrivateNetworks: d.Config.WebhookAllowPrivateNetworks,
+	})
+	d.EventBus.Subscribe(d.WebhookService.HandleEvent)
+
+	// Mailer（SMTP_HOST を設定した場合のみ、送信待ちの列からバックグラウンドで送信する）
+	var mail services.MailerInterface
+	if d.Config.SMTPHost != "" {
+		d.Mailer, err = newMailer(d.Config)
+		if err != nil {
+			return fmt.Errorf("failed to create mailer: %w", err)
+		}
+		mail = d.Mailer
+	}
+
+	// Workspace Service（ワークスペースとメンバーの管理、文書数・メンバー数の上限とストレージクォータ）
+	d.WorkspaceService = services.NewWorkspaceService(
+		d.WorkspaceRepository,
+		d.UserRepository,
+		d.Config.WorkspaceDocumentLimit,
+		d.Config.WorkspaceMemberLimit,
+		d.Config.WorkspaceStorageQuota,
+		mail,
+	)
+
 	// Document Service
 	d.DocumentService = services.NewDocumentService(
 		d.DocumentCoreRepository,
 		d.BlockRepository,
 		d.TreeRepository,
 		d.TrashRepository,
+		d.WorkspaceService,
+		d.EventBus,
+	)
+
+	// Version Service
+	d.VersionService = services.NewVersionService(
+		d.DocumentCoreRepository,
+		d.BlockRepository,
+		d.VersionRepository,
+	)
+
+	// Publication Service
+	d.PublicationService = services.NewPublicationService(
+		d.DocumentCoreRepository,
+		d.BlockRepository,
+		d.PublicationRepository,
+	)
+
+	// Security Event Service
+	d.SecurityEventService = services.NewSecurityEventService(d.SecurityEventRepository)
+
+	// Notification Service
+	d.NotificationService = services.NewNotificationService(d.NotificationRepository)
+
+	// Expiration Service
+	d.ExpirationService = services.NewExpirationService(
+		d.DocumentCoreRepository,
+		d.TrashRepository,
+		d.ExpirationRepository,
+		d.NotificationService,
+		time.Duration(d.Config.ExpiryWarningHours)*time.Hour,
+	)
+
+	// Reminder Service
+	d.ReminderService = services.NewReminderService(
+		d.DocumentCoreRepository,
+		d.ReminderRepository,
+		d.NotificationService,
 	)
 
+	// Task Service
+	d.TaskService = services.NewTaskService(d.TaskRepository)
+
+	// Block Comment Service
+	d.CommentService = services.NewBlockCommentService(
+		d.DocumentCoreRepository,
+		d.BlockRepository,
+		d.CommentRepository,
+	)
+
+	// Document Comment Service（文書全体へのコメントのスレッド）
+	d.DocumentCommentService = services.NewDocumentCommentService(
+		d.DocumentCoreRepository,
+		d.DocumentCommentRepository,
+	)
+
+	// Block Type Service
+	d.BlockTypeService = services.NewBlockTypeService(d.BlockTypeRepository)
+
+	// Admin Service
+	d.AdminService = services.NewAdminService(d.UserRepository, d.Config.AdminEmails)
+
+	// Maintenance Service
+	d.MaintenanceService = services.NewMaintenanceService(d.MaintenanceRepository, d.ObjectStorage)
+
+	// Stats Service
+	d.StatsService = services.NewStatsService(
+		d.StatsRepository,
+		d.BlockRepository,
+		time.Duration(d.Config.StatsRefreshInterval)*time.Second,
+	)
+
+	// Link Preview Service
+	d.LinkPreviewService = services.NewLinkPreviewService(
+		time.Duration(d.Config.LinkPreviewCacheTTL) * time.Second,
+	)
+
+	// Embed Service
+	d.EmbedService, err = services.NewEmbedService(d.Config.EmbedProviders)
+	if err != nil {
+		return fmt.Errorf("failed to create embed service: %w", err)
+	}
+
+	// 動画・音声の再生時間と映像の大きさの取得（無効にした場合は取得しない）
+	var mediaProber mediaprobe.Prober
+	if d.Config.MediaProbeEnabled {
+		mediaProber = mediaprobe.Builtin{}
+	}
+
+	// アップロードを許可する MIME タイプ（設定しない種類は既定の一覧）
+	allowedTypes := mimetype.NewAllowlist(map[mimetype.Category][]string{
+		mimetype.Image:    d.Config.AllowedImageTypes,
+		mimetype.Document: d.Config.AllowedDocumentTypes,
+		mimetype.Video:    d.Config.AllowedVideoTypes,
+		mimetype.Audio:    d.Config.AllowedAudioTypes,
+	})
+
 	// File Service
 	d.FileService = services.NewFileService(
 		d.FileRepository,
 		d.ObjectStorage,
 		d.Config.MaxFileSize,
+		d.Config.MaxVideoFileSize,
+		d.Config.MaxAudioFileSize,
 		d.Config.S3PresignExpiry,
+		mediaProber,
+		allowedTypes,
+		d.WorkspaceService,
+		d.EventBus,
+	)
+
+	// Thumbnail Service（画像のサムネイルをバックグラウンドで作成し、設定に応じて WebP に変換）
+	d.ThumbnailService = services.NewThumbnailService(d.FileRepository, d.ObjectStorage, services.ImageOptimization{
+		Enabled:      d.Config.ImageWebPEnabled,
+		MinSize:      d.Config.ImageWebPMinSize,
+		KeepOriginal: d.Config.ImageWebPKeepOriginal,
+	})
+
+	// File Purge Service（削除済みファイルと置き換えた以前の版を保持期間の経過後に完全削除）
+	d.FilePurgeService = services.NewFilePurgeService(
+		d.FileRepository,
+		d.FileVersionRepository,
+		d.ObjectStorage,
+		time.Duration(d.Config.FileRetentionPeriod)*time.Second,
+		time.Duration(d.Config.FileVersionRetention)*time.Second,
+	)
+
+	// File Version Service（同じファイルIDのまま新しい版をアップロード・以前の版を復元）
+	d.FileVersionService = services.NewFileVersionService(d.FileRepository, d.FileVersionRepository, d.FileService)
+
+	// Upload Session Service（再開可能な分割アップロード）
+	d.UploadSessionService = services.NewUploadSessionService(
+		d.UploadSessionRepository,
+		d.FileRepository,
+		d.ObjectStorage,
+		d.Config.MaxResumableFileSize,
+		d.Config.MaxVideoFileSize,
+		d.Config.MaxAudioFileSize,
+		time.Duration(d.Config.UploadSessionTTL)*time.Second,
+		d.Config.S3PresignExpiry,
+		mediaProber,
+		allowedTypes,
+		d.WorkspaceService,
+		d.EventBus,
+	)
+
+	// Upload Progress（アップロードの進捗の配信）
+	d.UploadProgress = services.NewUploadProgressTracker()
+
+	// Document Events（開いているタブへの文書の変更の通知）
+	d.DocumentEvents = services.NewDocumentEventBroker()
+
+	// Presence Service（文書を開いているユーザーの記録と通知）
+	d.PresenceService = services.NewPresenceService(
+		d.UserRepository,
+		d.DocumentEvents,
+		time.Duration(d.Config.PresenceTimeout)*time.Second,
+	)
+
+	// Workspace Invitation Service（招待メールの送信と招待リンクからの参加。メールを送信しない場合は招待できない）
+	d.InvitationService = services.NewWorkspaceInvitationService(
+		d.WorkspaceRepository,
+		d.InvitationRepository,
+		d.UserRepository,
+		d.WorkspaceService,
+		mail,
+		time.Duration(d.Config.WorkspaceInvitationTTL)*time.Hour,
+	)
+
+	// Mention Service（文書・コメント内の @メンション の記録と通知）
+	d.MentionService = services.NewMentionService(
+		d.MentionRepository,
+		d.DocumentCoreRepository,
+		d.UserRepository,
+		d.WorkspaceRepository,
+		d.NotificationService,
+	)
+
+	// Document Watch Service（購読している文書の変更をアプリ内通知、またはまとめたメールで知らせる）
+	d.WatchService = services.NewDocumentWatchService(
+		d.DocumentCoreRepository,
+		d.WatchRepository,
+		d.NotificationService,
+		mail,
+	)
+
+	// Content Policy
+	d.ContentPolicy, err = newContentPolicy(d.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create content policy: %w", err)
+	}
+	if d.Config.SecretsDetectionEnabled {
+		d.SecretsChecker = contentpolicy.NewSecretsChecker()
+	}
+
+	// Password Hasher
+	d.PasswordHasher = newPasswordHasher(d.Config)
+
+	// Login Throttle（失敗回数が 0 以下の場合は制限しない）
+	if d.Config.LoginMaxFailures > 0 {
+		d.LoginThrottle = services.NewLoginThrottle(
+			d.Config.LoginMaxFailures,
+			time.Duration(d.Config.LoginFailureWindow)*time.Second,
+			time.Duration(d.Config.LoginLockoutDuration)*time.Second,
+		)
+	}
+
+	// Share Link Service（共有リンクのパスワードの試行回数もログインと同じ設定で制限する。スナップショットのファイルの複製もクォータに含める）
+	d.ShareLinkService = services.NewShareLinkService(
+		d.DocumentCoreRepository,
+		d.BlockRepository,
+		d.ShareLinkRepository,
+		d.PasswordHasher,
+		d.LoginThrottle,
+		d.FileService,
+		d.Config.UserStorageQuota,
+	)
+
+	// Collaborator Service（共同編集者の変更を開いているタブに通知する）
+	d.CollaboratorService = services.NewCollaboratorService(
+		d.DocumentCoreRepository,
+		d.CollaboratorRepository,
+		d.UserRepository,
+		d.DocumentEvents,
 	)
 
 	return nil
 }
 
+// newPasswordHasher は、設定の argon2id コストでパスワードハッシャーを作成します
+// 0 以下の値は既定値を使う
+func newPasswordHasher(cfg *config.Config) *password.Hasher {
+	positive := func(v int) int {
+		if v < 0 {
+			return 0
+		}
+		return v
+	}
+	parallelism := positive(cfg.PasswordArgon2Parallelism)
+	if parallelism > 255 {
+		parallelism = 255
+	}
+
+	return password.NewHasher(password.Params{
+		Memory:      uint32(positive(cfg.PasswordArgon2Memory)),
+		Iterations:  uint32(positive(cfg.PasswordArgon2Iterations)),
+		Parallelism: uint8(parallelism),
+	})
+}
+
+// newContentPolicy は、設定からコンテンツポリシーのパイプラインを構築します
+func newContentPolicy(cfg *config.Config) (*contentpolicy.Pipeline, error) {
+	checkers := make([]contentpolicy.Checker, 0)
+
+	if cfg.ContentPolicyRulesFile != "" {
+		rules, err := contentpolicy.LoadRulesFile(cfg.ContentPolicyRulesFile)
+		if err != nil {
+			return nil, err
+		}
+		checkers = append(checkers, contentpolicy.NewPatternChecker(rules))
+	}
+
+	if len(cfg.ContentPolicyDenyWords) > 0 {
+		checkers = append(checkers, contentpolicy.NewDenyListChecker(cfg.ContentPolicyDenyWords))
+	}
+
+	if cfg.ContentPolicyWebhookURL != "" {
+		timeout := time.Duration(cfg.ContentPolicyWebhookTimeout) * time.Millisecond
+		checkers = append(checkers, contentpolicy.NewWebhookChecker(cfg.ContentPolicyWebhookURL, timeout))
+	}
+
+	return contentpolicy.NewPipeline(contentpolicy.ParseMode(cfg.ContentPolicyMode), checkers...), nil
+}
+
+// newMailer は、設定から SMTP で送信する Mailer を構築します
+func newMailer(cfg *config.Config) (*mailer.Mailer, error) {
+	sender, err := mailer.NewSMTPSender(mailer.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		TLSMode:  cfg.SMTPTLSMode,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mailer.New(sender, mailer.Options{
+		From:        cfg.MailFrom,
+		BaseURL:     cfg.AppBaseURL,
+		QueueSize:   cfg.MailQueueSize,
+		MaxAttempts: cfg.MailMaxAttempts,
+		RetryDelay:  time.Duration(cfg.MailRetryDelay) * time.Second,
+	})
+}
+
 // initHandlers は、全てのHandlerを初期化します
 func (d *Dependencies) initHandlers() error {
 	// Auth Handler
 	d.AuthHandler = handlers.NewAuthHandler(
 		d.UserRepository,
+		d.PasswordHasher,
+		d.LoginThrottle,
+		d.WorkspaceService,
+		d.InvitationService,
+		d.EventBus,
 		[]byte(d.Config.JWTSecret),
 		d.Config,
 	)
 
 	// Document Handler
-	d.DocumentHandler = document.NewDocumentHandler(d.DocumentService)
+	d.DocumentHandler = document.NewDocumentHandler(
+		d.DocumentService,
+		d.VersionService,
+		d.PublicationService,
+		d.ExpirationService,
+		d.SecurityEventService,
+		d.BlockTypeService,
+		d.LinkPreviewService,
+		d.FileService,
+		d.Config.UserStorageQuota,
+		document.ContentLimits{
+			MaxBlockBytes:    d.Config.MaxBlockContentSize,
+			MaxDocumentBytes: d.Config.MaxDocumentContentSize,
+		},
+		d.ContentPolicy,
+		d.SecretsChecker,
+		d.DocumentEvents,
+		d.PresenceService,
+		d.WorkspaceService,
+		d.DocumentCommentService,
+		d.MentionService,
+		d.WatchService,
+	)
 
 	// Upload Handler
-	d.UploadHandler = upload.NewUploadHandler(d.FileService, d.Config.UserStorageQuota)
+	d.UploadHandler = upload.NewUploadHandler(d.FileService, d.Config.UserStorageQuota, d.URLCache, d.UploadProgress)
+	d.ResumableHandler = upload.NewResumableUploadHandler(d.UploadSessionService, d.Config.UserStorageQuota, d.UploadProgress)
+	d.ProgressHandler = upload.NewProgressHandler(d.UploadProgress)
+	d.FileVersionHandler = upload.NewFileVersionHandler(d.FileVersionService, d.FileService, d.Config.UserStorageQuota)
+	if signedStorage, ok := d.ObjectStorage.(storage.SignedURLStorage); ok {
+		d.SignedObjectHandler = upload.NewSignedObjectHandler(signedStorage, d.Config.MaxResumableFileSize)
+	}
+
+	// Notification Handler
+	d.NotificationHandler = notification.NewNotificationHandler(d.NotificationService)
+	d.WatchHandler = notification.NewWatchHandler(d.WatchService)
+
+	// Reminder Handler
+	d.ReminderHandler = reminder.NewReminderHandler(d.ReminderService)
+
+	// Task Handler
+	d.TaskHandler = task.NewTaskHandler(d.TaskService)
+
+	// GraphQL Handler
+	d.GraphQLHandler = graph.NewGraphQLHandler(d.DocumentService, d.FileService)
+
+	// gRPC Server（HTTP とは別のポートで待ち受ける。文書の変更の検証・保存後の処理は REST の DocumentHandler と共有する）
+	d.RPCServer = rpc.NewServer(
+		d.DocumentService, d.DocumentHandler,
+		d.FileService, d.Config.UserStorageQuota,
+		d.GetJWTSecret(), d.WorkspaceService,
+	)
+
+	// Comment Handler
+	d.CommentHandler = comment.NewCommentHandler(d.CommentService, d.MentionService)
+	d.DocumentCommentHandler = comment.NewDocumentCommentHandler(d.DocumentCommentService, d.MentionService)
+
+	// Share Link Handler
+	d.ShareLinkHandler = share.NewShareLinkHandler(d.ShareLinkService)
+	d.CollaboratorHandler = share.NewCollaboratorHandler(d.CollaboratorService)
+
+	// Embed Handler
+	d.EmbedHandler = embed.NewEmbedHandler(d.EmbedService)
+
+	// Block Type Handler
+	d.BlockTypeHandler = blocktype.NewBlockTypeHandler(d.BlockTypeService)
+
+	// Admin Handler
+	d.AdminHandler = admin.NewAdminHandler(d.MaintenanceService, d.StatsService, d.FileService, d.WorkspaceService, d.Config.UserStorageQuota)
+
+	// Profiling Handler（無効の場合はルートを登録しない）
+	if d.Config.PprofEnabled {
+		d.ProfilingHandler = admin.NewProfilingHandler()
+	}
+
+	// Workspace Handler
+	d.WorkspaceHandler = workspace.NewWorkspaceHandler(d.WorkspaceService, d.InvitationService)
+
+	// Webhook Handler
+	d.WebhookHandler = webhook.NewWebhookHandler(d.WebhookService)
 
 	return nil
 }
@@ -163,8 +854,14 @@ func (d *Dependencies) GetJWTSecret() []byte {
 	return []byte(d.Config.JWTSecret)
 }
 
-// Close は、データベース接続を安全に閉じます
+// Close は、キャッシュとデータベース接続を安全に閉じます
 func (d *Dependencies) Close() error {
+	if d.URLCache != nil {
+		d.URLCache.Close()
+	}
+	if d.RateLimiter != nil {
+		d.RateLimiter.Close()
+	}
 	if d.Database != nil {
 		return d.Database.Close()
 	}
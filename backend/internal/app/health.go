@@ -0,0 +1,238 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/storage"
+	"simple-notion-backend/migrations"
+)
+
+// readinessProbeKey は、ストレージへの疎通確認で情報を取得するファイルです（存在しなくてよい）
+const readinessProbeKey = ".readyz-probe"
+
+const (
+	checkStatusOK       = "ok"
+	checkStatusError    = "error"
+	checkStatusDisabled = "disabled"
+)
+
+// DependencyCheck は、依存先1つの確認結果です
+// Error は原因の概要のみで、接続先などの内部の情報は含めません（詳細はサーバーログに記録します）
+type DependencyCheck struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReadinessResponse は、/readyz のレスポンスです
+type ReadinessResponse struct {
+	Status    string                     `json:"status"` // ready / not_ready
+	Checks    map[string]DependencyCheck `json:"checks"`
+	CheckedAt time.Time                  `json:"checkedAt"`
+}
+
+// LivenessResponse は、/healthz のレスポンスです
+type LivenessResponse struct {
+	Status        string `json:"status"`
+	UptimeSeconds int64  `json:"uptimeSeconds"`
+}
+
+// HealthChecker は、コンテナのプローブ向けに生存（/healthz）と受け付け可能か（/readyz）を判定する構造体です
+// 生存はプロセスが応答できるかのみを返し、依存先の障害で再起動されないようにします
+type HealthChecker struct {
+	database  *sql.DB
+	storage   storage.ObjectStorage // nil の場合はストレージを確認しない
+	tables    []string              // 全てのマイグレーションを適用した場合に存在するテーブル
+	tablesErr error
+	startedAt time.Time
+}
+
+// NewHealthChecker は、新しいHealthCheckerインスタンスを作成します
+func NewHealthChecker(database *sql.DB, objectStorage storage.ObjectStorage) *HealthChecker {
+	tables, err := migrations.Tables()
+	return &HealthChecker{
+		database:  database,
+		storage:   objectStorage,
+		tables:    tables,
+		tablesErr: err,
+		startedAt: time.Now(),
+	}
+}
+
+// ServeLive は、プロセスが応答できることを返します（依存先は確認しません）
+func (h *HealthChecker) ServeLive(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	apierror.WriteJSON(w, http.StatusOK, LivenessResponse{
+		Status:        "ok",
+		UptimeSeconds: int64(time.Since(h.startedAt).Seconds()),
+	})
+}
+
+// ServeReady は、依存先ごとの確認結果を返します（確認に失敗した依存先がある場合は 503）
+func (h *HealthChecker) ServeReady(w http.ResponseWriter, r *http.Request) {
+	result := h.Ready(r.Context())
+
+	status := http.StatusOK
+	if result.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	apierror.WriteJSON(w, status, result)
+}
+
+// Ready は、データベース・ストレージ・マイグレーションを並行して確認します
+func (h *HealthChecker) Ready(ctx context.Context) ReadinessResponse {
+	checks := map[string]func(context.Context) error{
+		"database":   h.checkDatabase,
+		"migrations": h.checkMigrations,
+	}
+	if h.storage != nil {
+		checks["storage"] = h.checkStorage
+	}
+
+	result := ReadinessResponse{
+		Status:    "ready",
+		Checks:    make(map[string]DependencyCheck, len(checks)+1),
+		CheckedAt: time.Now(),
+	}
+	if h.storage == nil {
+		result.Checks["storage"] = DependencyCheck{Status: checkStatusDisabled}
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, statusCheckTimeout)
+			defer cancel()
+
+			started := time.Now()
+			err := check(checkCtx)
+			dep := DependencyCheck{Status: checkStatusOK, LatencyMs: time.Since(started).Milliseconds()}
+			if err != nil {
+				log.Printf("Readiness check %s failed: %v", name, err)
+				dep.Status = checkStatusError
+				dep.Error = readinessError(err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Checks[name] = dep
+			if err != nil {
+				result.Status = "not_ready"
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return result
+}
+
+// checkDatabase は、データベースに接続できるかを確認します
+func (h *HealthChecker) checkDatabase(ctx context.Context) error {
+	if h.database == nil {
+		return errors.New("database is not configured")
+	}
+	if err := h.database.PingContext(ctx); err != nil {
+		return &readinessFailure{summary: "ping failed", err: err}
+	}
+	return nil
+}
+
+// checkStorage は、オブジェクトストレージに接続できるかを確認します（確認用のファイルが存在しない応答も接続できたとみなす）
+func (h *HealthChecker) checkStorage(ctx context.Context) error {
+	if _, err := h.storage.StatFile(ctx, readinessProbeKey); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+		return &readinessFailure{summary: "unreachable", err: err}
+	}
+	return nil
+}
+
+// checkMigrations は、マイグレーションで作成するテーブルが全て存在するかを確認します
+func (h *HealthChecker) checkMigrations(ctx context.Context) error {
+	if h.tablesErr != nil {
+		return h.tablesErr
+	}
+	if h.database == nil {
+		return errors.New("database is not configured")
+	}
+
+	rows, err := h.database.QueryContext(ctx,
+		`SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ANY($1)`,
+		pq.Array(h.tables))
+	if err != nil {
+		return &readinessFailure{summary: "query failed", err: err}
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool, len(h.tables))
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return &readinessFailure{summary: "query failed", err: err}
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return &readinessFailure{summary: "query failed", err: err}
+	}
+
+	if missing := missingTables(h.tables, existing); len(missing) > 0 {
+		return &readinessFailure{summary: "missing tables: " + strings.Join(missing, ", ")}
+	}
+	return nil
+}
+
+// missingTables は、required のうち existing にないテーブルを名前順で返します
+func missingTables(required []string, existing map[string]bool) []string {
+	var missing []string
+	for _, table := range required {
+		if !existing[table] {
+			missing = append(missing, table)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// readinessFailure は、レスポンスに含める概要と、ログにのみ記録する元のエラーを持つ確認の失敗です
+type readinessFailure struct {
+	summary string
+	err     error
+}
+
+func (f *readinessFailure) Error() string {
+	if f.err != nil {
+		return fmt.Sprintf("%s: %v", f.summary, f.err)
+	}
+	return f.summary
+}
+
+func (f *readinessFailure) Unwrap() error { return f.err }
+
+// readinessError は、レスポンスに含める失敗の概要を返します
+func readinessError(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	var failure *readinessFailure
+	if errors.As(err, &failure) {
+		return failure.summary
+	}
+	return "check failed"
+}
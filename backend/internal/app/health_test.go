@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// TestHealthCheckerServeLive - 生存確認が依存先に関係なく 200 を返すことのテスト
+func TestHealthCheckerServeLive(t *testing.T) {
+	h := NewHealthChecker(nil, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeLive(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var body LivenessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Status != "ok" {
+		t.Errorf("status = %q, want %q", body.Status, "ok")
+	}
+}
+
+// TestHealthCheckerServeReady - 依存先を確認できない場合に 503 と依存先ごとの結果を返すことのテスト
+func TestHealthCheckerServeReady(t *testing.T) {
+	h := NewHealthChecker(nil, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeReady(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	var body ReadinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Status != "not_ready" {
+		t.Errorf("status = %q, want %q", body.Status, "not_ready")
+	}
+	want := map[string]string{
+		"database":   checkStatusError,
+		"migrations": checkStatusError,
+		"storage":    checkStatusDisabled,
+	}
+	for name, status := range want {
+		if got := body.Checks[name].Status; got != status {
+			t.Errorf("checks[%s].status = %q, want %q", name, got, status)
+		}
+	}
+}
+
+// TestMissingTables - 存在しないテーブルの抽出のテスト
+func TestMissingTables(t *testing.T) {
+	tests := []struct {
+		name     string
+		required []string
+		existing map[string]bool
+		want     []string
+	}{
+		{
+			name:     "正常系：全て存在する",
+			required: []string{"users", "documents"},
+			existing: map[string]bool{"users": true, "documents": true},
+			want:     nil,
+		},
+		{
+			name:     "正常系：存在しないテーブルを名前順で返す",
+			required: []string{"users", "documents", "blocks"},
+			existing: map[string]bool{"users": true},
+			want:     []string{"blocks", "documents"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingTables(tt.required, tt.existing); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingTables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestReadinessError - レスポンスに含める失敗の概要のテスト
+func TestReadinessError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "正常系：概要のみを返し、元のエラーは含めない",
+			err:  &readinessFailure{summary: "ping failed", err: errors.New("dial tcp 10.0.0.1:5432: connection refused")},
+			want: "ping failed",
+		},
+		{
+			name: "正常系：タイムアウト",
+			err:  &readinessFailure{summary: "ping failed", err: context.DeadlineExceeded},
+			want: "timeout",
+		},
+		{
+			name: "異常系：想定外のエラー",
+			err:  fmt.Errorf("unexpected: %w", errors.New("boom")),
+			want: "check failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readinessError(tt.err); got != tt.want {
+				t.Errorf("readinessError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"simple-notion-backend/internal/config"
+	"simple-notion-backend/internal/logctx"
 )
 
 // LogLevel は、ログレベルの定義です
@@ -251,27 +253,54 @@ func (l *Logger) WithFields(fields map[string]interface{}) *LogContext {
 	}
 }
 
+// WithContext は、リクエストのコンテキストに紐づくログコンテキストを作成します
+// リクエスト ID（RequestIDMiddleware が設定する）・トレース ID がある場合は request_id・trace_id フィールドに含めます
+func (l *Logger) WithContext(ctx context.Context) *LogContext {
+	return l.WithFields(logctx.Fields(ctx))
+}
+
 // LogContext は、フィールド付きのログコンテキストです
 type LogContext struct {
 	logger *Logger
 	fields map[string]interface{}
 }
 
+// Debug は、コンテキストのデバッグレベルのログを出力します
+func (c *LogContext) Debug(message string, fields ...map[string]interface{}) {
+	c.logger.log(LogLevelDebug, message, c.merge(fields))
+}
+
 // Info は、コンテキストの情報レベルのログを出力します
-func (c *LogContext) Info(message string) {
-	c.logger.log(LogLevelInfo, message, c.fields)
+func (c *LogContext) Info(message string, fields ...map[string]interface{}) {
+	c.logger.log(LogLevelInfo, message, c.merge(fields))
+}
+
+// Warn は、コンテキストの警告レベルのログを出力します
+func (c *LogContext) Warn(message string, fields ...map[string]interface{}) {
+	c.logger.log(LogLevelWarn, message, c.merge(fields))
 }
 
 // Error は、コンテキストのエラーレベルのログを出力します
-func (c *LogContext) Error(message string, err error) {
-	fields := make(map[string]interface{})
+func (c *LogContext) Error(message string, err error, fields ...map[string]interface{}) {
+	f := c.merge(fields)
+	if err != nil {
+		f["error"] = err.Error()
+	}
+	c.logger.log(LogLevelError, message, f)
+}
+
+// merge は、コンテキストのフィールドに呼び出しごとのフィールドを加えたコピーを返します
+func (c *LogContext) merge(fields []map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(c.fields))
 	for k, v := range c.fields {
-		fields[k] = v
+		merged[k] = v
 	}
-	if err != nil {
-		fields["error"] = err.Error()
+	if len(fields) > 0 {
+		for k, v := range fields[0] {
+			merged[k] = v
+		}
 	}
-	c.logger.log(LogLevelError, message, fields)
+	return merged
 }
 
 // GetStandardLogger は、標準のlog.Loggerインターフェース互換のロガーを返します
@@ -18,33 +18,62 @@ type Metrics struct {
 	httpRequestDuration int64 // ナノ秒単位
 	httpErrorsTotal     int64
 	httpActiveRequests  int64
+	httpStatusClasses   [6]int64 // ステータスコードの百の位ごと（1xx〜5xx、添字 0 は範囲外）
 
 	// アプリケーション関連メトリクス
 	databaseConnections int64
 	logCounters         map[string]int64
 	errorCounters       map[string]int64
+	counters            map[string]int64 // バックグラウンドジョブの処理件数など
+	latencies           map[string]*latencyStats
+
+	// SLO 用の SLI（ルートグループ別）
+	slo *SLOTracker
 
 	// システム関連メトリクス
 	startTime time.Time
 
 	// 並行安全性のためのミューテックス
-	logMutex   sync.RWMutex
-	errorMutex sync.RWMutex
+	logMutex     sync.RWMutex
+	errorMutex   sync.RWMutex
+	counterMutex sync.RWMutex
+	latencyMutex sync.RWMutex
 
 	config *config.Config
 }
 
 // MetricsSnapshot は、メトリクスのスナップショットです
 type MetricsSnapshot struct {
-	Timestamp           time.Time        `json:"timestamp"`
-	Uptime              string           `json:"uptime"`
-	HTTPRequestsTotal   int64            `json:"http_requests_total"`
-	HTTPErrorsTotal     int64            `json:"http_errors_total"`
-	HTTPActiveRequests  int64            `json:"http_active_requests"`
-	DatabaseConnections int64            `json:"database_connections"`
-	LogCounters         map[string]int64 `json:"log_counters"`
-	ErrorCounters       map[string]int64 `json:"error_counters"`
-	SystemInfo          SystemInfo       `json:"system_info"`
+	Timestamp           time.Time                 `json:"timestamp"`
+	Uptime              string                    `json:"uptime"`
+	HTTPRequestsTotal   int64                     `json:"http_requests_total"`
+	HTTPErrorsTotal     int64                     `json:"http_errors_total"`
+	HTTPActiveRequests  int64                     `json:"http_active_requests"`
+	DatabaseConnections int64                     `json:"database_connections"`
+	LogCounters         map[string]int64          `json:"log_counters"`
+	ErrorCounters       map[string]int64          `json:"error_counters"`
+	Counters            map[string]int64          `json:"counters"`
+	Latencies           map[string]LatencySummary `json:"latencies"`
+	SystemInfo          SystemInfo                `json:"system_info"`
+}
+
+// operationLatencyBuckets は、処理ごとの所要時間のヒストグラムのバケット境界（秒）です
+// パスワードのハッシュ化（数百ミリ秒）からバックグラウンドジョブ（数十秒）までを扱う
+var operationLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// latencyStats は、処理ごとの所要時間の集計です
+type latencyStats struct {
+	count        int64
+	total        time.Duration
+	max          time.Duration
+	bucketCounts []int64 // operationLatencyBuckets の各境界以下の件数（累積）
+}
+
+// LatencySummary は、処理ごとの所要時間の集計結果です（ミリ秒）
+type LatencySummary struct {
+	Count int64   `json:"count"`
+	AvgMs float64 `json:"avg_ms"`
+	MaxMs float64 `json:"max_ms"`
 }
 
 // SystemInfo は、システム情報です
@@ -61,6 +90,9 @@ func NewMetrics(cfg *config.Config) *Metrics {
 	return &Metrics{
 		logCounters:   make(map[string]int64),
 		errorCounters: make(map[string]int64),
+		counters:      make(map[string]int64),
+		latencies:     make(map[string]*latencyStats),
+		slo:           NewSLOTracker(cfg),
 		startTime:     time.Now(),
 		config:        cfg,
 	}
@@ -90,6 +122,9 @@ func (m *Metrics) HTTPMiddleware(next http.Handler) http.Handler {
 		if wrapper.statusCode >= 400 {
 			atomic.AddInt64(&m.httpErrorsTotal, 1)
 		}
+		atomic.AddInt64(&m.httpStatusClasses[statusClassIndex(wrapper.statusCode)], 1)
+
+		m.slo.Observe(r.URL.Path, wrapper.statusCode, duration)
 	})
 }
 
@@ -108,6 +143,11 @@ func (w *responseWrapper) Write(data []byte) (int, error) {
 	return w.ResponseWriter.Write(data)
 }
 
+// Unwrap は 元の ResponseWriter を返します（http.ResponseController で Flush・タイムアウトの変更を行うため）
+func (w *responseWrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // IncrementLogCount は、ログレベル別のカウントを増加します
 func (m *Metrics) IncrementLogCount(level string) {
 	m.logMutex.Lock()
@@ -122,6 +162,44 @@ func (m *Metrics) IncrementErrorCount(component string) {
 	m.errorCounters[component]++
 }
 
+// AddCount は、名前付きのカウンターに delta を加算します（バックグラウンドジョブの処理件数など）
+func (m *Metrics) AddCount(name string, delta int64) {
+	m.counterMutex.Lock()
+	defer m.counterMutex.Unlock()
+	m.counters[name] += delta
+}
+
+// ObserveLatency は、処理ごとの所要時間を記録します（パスワードのハッシュ化など）
+func (m *Metrics) ObserveLatency(name string, d time.Duration) {
+	m.latencyMutex.Lock()
+	defer m.latencyMutex.Unlock()
+
+	stats, ok := m.latencies[name]
+	if !ok {
+		stats = &latencyStats{bucketCounts: make([]int64, len(operationLatencyBuckets))}
+		m.latencies[name] = stats
+	}
+	stats.count++
+	stats.total += d
+	if d > stats.max {
+		stats.max = d
+	}
+	seconds := d.Seconds()
+	for i, b := range operationLatencyBuckets {
+		if seconds <= b {
+			stats.bucketCounts[i]++
+		}
+	}
+}
+
+// statusClassIndex は、ステータスコードの百の位を返します（1xx〜5xx 以外は 0）
+func statusClassIndex(status int) int {
+	if class := status / 100; class >= 1 && class <= 5 {
+		return class
+	}
+	return 0
+}
+
 // SetDatabaseConnections は、データベース接続数を設定します
 func (m *Metrics) SetDatabaseConnections(count int64) {
 	atomic.StoreInt64(&m.databaseConnections, count)
@@ -143,6 +221,24 @@ func (m *Metrics) GetSnapshot() MetricsSnapshot {
 	}
 	m.errorMutex.RUnlock()
 
+	m.counterMutex.RLock()
+	counters := make(map[string]int64, len(m.counters))
+	for k, v := range m.counters {
+		counters[k] = v
+	}
+	m.counterMutex.RUnlock()
+
+	m.latencyMutex.RLock()
+	latencies := make(map[string]LatencySummary, len(m.latencies))
+	for k, v := range m.latencies {
+		latencies[k] = LatencySummary{
+			Count: v.count,
+			AvgMs: float64(v.total.Nanoseconds()) / float64(v.count) / 1000000,
+			MaxMs: float64(v.max.Nanoseconds()) / 1000000,
+		}
+	}
+	m.latencyMutex.RUnlock()
+
 	// システム情報を取得
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
@@ -156,6 +252,8 @@ func (m *Metrics) GetSnapshot() MetricsSnapshot {
 		DatabaseConnections: atomic.LoadInt64(&m.databaseConnections),
 		LogCounters:         logCounters,
 		ErrorCounters:       errorCounters,
+		Counters:            counters,
+		Latencies:           latencies,
 		SystemInfo: SystemInfo{
 			GoVersion:       runtime.Version(),
 			Goroutines:      runtime.NumGoroutine(),
@@ -229,6 +327,9 @@ func (m *Metrics) Reset() {
 	atomic.StoreInt64(&m.httpErrorsTotal, 0)
 	atomic.StoreInt64(&m.httpActiveRequests, 0)
 	atomic.StoreInt64(&m.databaseConnections, 0)
+	for i := range m.httpStatusClasses {
+		atomic.StoreInt64(&m.httpStatusClasses[i], 0)
+	}
 
 	m.logMutex.Lock()
 	m.logCounters = make(map[string]int64)
@@ -238,5 +339,13 @@ func (m *Metrics) Reset() {
 	m.errorCounters = make(map[string]int64)
 	m.errorMutex.Unlock()
 
+	m.counterMutex.Lock()
+	m.counters = make(map[string]int64)
+	m.counterMutex.Unlock()
+
+	m.latencyMutex.Lock()
+	m.latencies = make(map[string]*latencyStats)
+	m.latencyMutex.Unlock()
+
 	m.startTime = time.Now()
 }
@@ -0,0 +1,296 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/config"
+)
+
+// openAPIVersion は、生成する仕様の OpenAPI のバージョンです
+const openAPIVersion = "3.0.3"
+
+// authCookieScheme は、認証 Cookie（auth_token）を表すセキュリティスキームの名前です
+const authCookieScheme = "cookieAuth"
+
+// openAPIAuthenticatedPaths は、認証が必要なAPIのサブルーターの外で認証を確認するエンドポイントです
+// （サブルーターに登録したエンドポイントは、ルートの親子関係から認証が必要と判定します）
+var openAPIAuthenticatedPaths = map[string]bool{
+	"/api/auth/workspace": true,
+}
+
+// pathVariablePattern は、gorilla/mux のパステンプレートの変数（{name} または {name:pattern}）です
+var pathVariablePattern = regexp.MustCompile(`\{([^{}:]+)(?::((?:[^{}]|\{[^{}]*\})+))?\}`)
+
+// OpenAPISpec は、OpenAPI 3 の仕様（ルートの登録内容から生成する範囲のみ）です
+type OpenAPISpec struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Tags       []OpenAPITag               `json:"tags"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+}
+
+// OpenAPIInfo は、APIの名前とバージョンです
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPITag は、エンドポイントの分類（パスの最初の部分）です
+type OpenAPITag struct {
+	Name string `json:"name"`
+}
+
+// OpenAPIPathItem は、1つのパスのメソッド（小文字）ごとの操作です
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation は、1つのメソッドとパスの組み合わせの操作です
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Tags        []string                   `json:"tags"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter は、パスの変数です
+type OpenAPIParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"`
+	Required bool          `json:"required"`
+	Schema   OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema は、値の型（パスの変数とエラーレスポンスに必要な範囲のみ）です
+type OpenAPISchema struct {
+	Ref        string                   `json:"$ref,omitempty"`
+	Type       string                   `json:"type,omitempty"`
+	Pattern    string                   `json:"pattern,omitempty"`
+	Properties map[string]OpenAPISchema `json:"properties,omitempty"`
+	Required   []string                 `json:"required,omitempty"`
+}
+
+// OpenAPIResponse は、ステータスコードごとのレスポンスです
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType は、レスポンスの本文の形式です
+type OpenAPIMediaType struct {
+	Schema OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIComponents は、操作から参照するスキーマとセキュリティスキームです
+type OpenAPIComponents struct {
+	Schemas         map[string]OpenAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme は、認証の方法です
+type OpenAPISecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+// BuildOpenAPISpec は、ルーターに登録された全てのエンドポイントから OpenAPI の仕様を生成します
+// エンドポイントの追加・変更は SetupRoutes の登録内容からそのまま反映されるため、仕様を別に管理する必要はありません
+func BuildOpenAPISpec(router *mux.Router) (*OpenAPISpec, error) {
+	spec := &OpenAPISpec{
+		OpenAPI: openAPIVersion,
+		Info:    OpenAPIInfo{Title: "Simple Notion API", Version: Version},
+		Paths:   make(map[string]OpenAPIPathItem),
+		Components: OpenAPIComponents{
+			Schemas: map[string]OpenAPISchema{
+				"Error": {
+					Type: "object",
+					Properties: map[string]OpenAPISchema{
+						"type":       {Type: "string"},
+						"title":      {Type: "string"},
+						"status":     {Type: "integer"},
+						"detail":     {Type: "string"},
+						"instance":   {Type: "string"},
+						"error":      {Type: "string"},
+						"message":    {Type: "string"},
+						"details":    {Type: "object"},
+						"request_id": {Type: "string"},
+					},
+					Required: []string{"type", "title", "status", "detail", "error", "message"},
+				},
+			},
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				authCookieScheme: {Type: "apiKey", In: "cookie", Name: "auth_token"},
+			},
+		},
+	}
+	tags := make(map[string]bool)
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// メソッドを指定しないルート（サブルーターの PathPrefix）は操作ではない
+			return nil
+		}
+
+		path, params := openAPIPath(template)
+		tag := openAPITag(path)
+		authenticated := len(ancestors) > 0 || openAPIAuthenticatedPaths[path]
+
+		item, ok := spec.Paths[path]
+		if !ok {
+			item = make(OpenAPIPathItem)
+			spec.Paths[path] = item
+		}
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+			op := &OpenAPIOperation{
+				OperationID: openAPIOperationID(method, path),
+				Tags:        []string{tag},
+				Parameters:  params,
+				Responses:   openAPIResponses(authenticated),
+			}
+			if authenticated {
+				op.Security = []map[string][]string{{authCookieScheme: {}}}
+			}
+			item[strings.ToLower(method)] = op
+		}
+		tags[tag] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk routes: %w", err)
+	}
+
+	for name := range tags {
+		spec.Tags = append(spec.Tags, OpenAPITag{Name: name})
+	}
+	sort.Slice(spec.Tags, func(i, j int) bool { return spec.Tags[i].Name < spec.Tags[j].Name })
+	return spec, nil
+}
+
+// GenerateOpenAPISpec は、データベース・ストレージに接続せずに全てのエンドポイントを登録し、OpenAPI の仕様を生成します
+// クライアント SDK の生成（cmd/sdkgen）で使います。設定で有効にするエンドポイント（pprof・Swagger UI など）は含みません
+func GenerateOpenAPISpec() (*OpenAPISpec, error) {
+	r := NewRouterFromDependencies(&Dependencies{Config: &config.Config{}})
+	r.SetupRoutes()
+	return BuildOpenAPISpec(r.router)
+}
+
+// openAPIPath は、mux のパステンプレートを OpenAPI のパス（変数の正規表現を除いたもの）とパスの変数に変換します
+// 正規表現が数字のみの変数は integer、それ以外は string として扱います
+func openAPIPath(template string) (string, []OpenAPIParameter) {
+	var params []OpenAPIParameter
+	path := pathVariablePattern.ReplaceAllStringFunc(template, func(variable string) string {
+		match := pathVariablePattern.FindStringSubmatch(variable)
+		name, pattern := match[1], match[2]
+
+		schema := OpenAPISchema{Type: "string"}
+		switch pattern {
+		case "":
+		case "[0-9]+":
+			schema = OpenAPISchema{Type: "integer"}
+		default:
+			schema.Pattern = "^" + pattern + "$"
+		}
+		params = append(params, OpenAPIParameter{Name: name, In: "path", Required: true, Schema: schema})
+		return "{" + name + "}"
+	})
+	return path, params
+}
+
+// openAPITag は、パスの最初の部分（/api と拡張子を除く）を分類として返します
+func openAPITag(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/api"), "/")
+	if len(segments) < 2 || segments[1] == "" {
+		return "api"
+	}
+	return strings.SplitN(segments[1], ".", 2)[0]
+}
+
+// openAPIOperationID は、メソッドとパスから一意な operationId を作ります（例: GET /api/documents/{id} → getDocumentsById）
+func openAPIOperationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api"), "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") {
+			b.WriteString("By")
+			segment = strings.Trim(segment, "{}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// openAPIResponses は、全ての操作に共通するレスポンス（成功と統一エラーレスポンス）です
+func openAPIResponses(authenticated bool) map[string]OpenAPIResponse {
+	errorResponse := func(description string) OpenAPIResponse {
+		return OpenAPIResponse{
+			Description: description,
+			Content: map[string]OpenAPIMediaType{
+				apierror.ProblemContentType: {Schema: OpenAPISchema{Ref: "#/components/schemas/Error"}},
+			},
+		}
+	}
+
+	responses := map[string]OpenAPIResponse{
+		"200":     {Description: "成功"},
+		"default": errorResponse("エラー（統一エラーレスポンス）"),
+	}
+	if authenticated {
+		responses["401"] = errorResponse("未ログイン、またはセッションの期限切れ")
+	}
+	return responses
+}
+
+// serveOpenAPISpec は、生成済みの OpenAPI の仕様を返します
+func (r *Router) serveOpenAPISpec(w http.ResponseWriter, req *http.Request) {
+	if r.openAPISpec == nil {
+		apierror.Write(w, req, apierror.NewNotFound("OPENAPI_NOT_READY", "APIの仕様はまだ生成されていません", nil))
+		return
+	}
+	apierror.WriteJSON(w, http.StatusOK, r.openAPISpec)
+}
+
+// swaggerUIPage は、/api/openapi.json を表示する Swagger UI です（スクリプトは CDN から読み込む）
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ja">
+<head>
+  <meta charset="utf-8">
+  <title>Simple Notion API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui", withCredentials: true });
+  </script>
+</body>
+</html>
+`
+
+// serveSwaggerUI は、Swagger UI のページを返します
+func serveSwaggerUI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(swaggerUIPage))
+}
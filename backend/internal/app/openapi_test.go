@@ -0,0 +1,80 @@
+package app
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestBuildOpenAPISpec(t *testing.T) {
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/auth/login", noop).Methods("POST")
+	router.HandleFunc("/api/auth/workspace", noop).Methods("PUT")
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/documents/{id:[0-9]+}", noop).Methods("GET")
+	api.HandleFunc("/upload/sessions/{id:[0-9a-f-]+}", noop).Methods("PATCH")
+	api.HandleFunc("/upload/image", noop).Methods("POST", "OPTIONS")
+
+	spec, err := BuildOpenAPISpec(router)
+	if err != nil {
+		t.Fatalf("BuildOpenAPISpec() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		method        string
+		wantID        string
+		wantTag       string
+		wantParams    []OpenAPIParameter
+		authenticated bool
+	}{
+		{name: "正常系：認証不要のエンドポイント", path: "/api/auth/login", method: "post", wantID: "postAuthLogin", wantTag: "auth"},
+		{name: "正常系：サブルーターの外で認証を確認するエンドポイント", path: "/api/auth/workspace", method: "put", wantID: "putAuthWorkspace", wantTag: "auth", authenticated: true},
+		{
+			name: "正常系：数字のみの変数は integer", path: "/api/documents/{id}", method: "get", wantID: "getDocumentsById", wantTag: "documents", authenticated: true,
+			wantParams: []OpenAPIParameter{{Name: "id", In: "path", Required: true, Schema: OpenAPISchema{Type: "integer"}}},
+		},
+		{
+			name: "正常系：それ以外の変数は正規表現付きの string", path: "/api/upload/sessions/{id}", method: "patch", wantID: "patchUploadSessionsById", wantTag: "upload", authenticated: true,
+			wantParams: []OpenAPIParameter{{Name: "id", In: "path", Required: true, Schema: OpenAPISchema{Type: "string", Pattern: "^[0-9a-f-]+$"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := spec.Paths[tt.path][tt.method]
+			if op == nil {
+				t.Fatalf("operation %s %s not found in %v", tt.method, tt.path, spec.Paths)
+			}
+			if op.OperationID != tt.wantID {
+				t.Errorf("OperationID = %q, want %q", op.OperationID, tt.wantID)
+			}
+			if !reflect.DeepEqual(op.Tags, []string{tt.wantTag}) {
+				t.Errorf("Tags = %v, want [%s]", op.Tags, tt.wantTag)
+			}
+			if !reflect.DeepEqual(op.Parameters, tt.wantParams) {
+				t.Errorf("Parameters = %+v, want %+v", op.Parameters, tt.wantParams)
+			}
+			if (len(op.Security) > 0) != tt.authenticated {
+				t.Errorf("Security = %v, want authenticated %v", op.Security, tt.authenticated)
+			}
+			if _, ok := op.Responses["401"]; ok != tt.authenticated {
+				t.Errorf("401 response present = %v, want %v", ok, tt.authenticated)
+			}
+		})
+	}
+
+	// OPTIONS（CORS のプリフライト）は操作として載せない
+	if _, ok := spec.Paths["/api/upload/image"]["options"]; ok {
+		t.Error("OPTIONS operation should not be included")
+	}
+	wantTags := []OpenAPITag{{Name: "auth"}, {Name: "documents"}, {Name: "upload"}}
+	if !reflect.DeepEqual(spec.Tags, wantTags) {
+		t.Errorf("Tags = %v, want %v", spec.Tags, wantTags)
+	}
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// PrometheusContentType は、Prometheus のテキスト形式（0.0.4）の Content-Type です
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus は、全てのメトリクスを Prometheus のテキスト形式（0.0.4）で書き出します
+// /metrics の JSON と同じ値に加えて、ステータスコード別の件数・処理ごとの所要時間のヒストグラム・SLO 用の SLI を出力する
+//
+// ダッシュボードの例（処理ごとの 95 パーセンタイル）:
+//
+//	histogram_quantile(0.95, sum by (operation, le) (rate(simple_notion_operation_duration_seconds_bucket[5m])))
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	var sb strings.Builder
+
+	m.writeHTTPMetrics(&sb)
+	m.writeApplicationMetrics(&sb)
+	m.writeOperationLatencies(&sb)
+	writeRuntimeMetrics(&sb, m.startTime.Unix())
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return err
+	}
+	return m.slo.WritePrometheus(w)
+}
+
+// acceptsPrometheus は、Accept ヘッダーが JSON ではなく Prometheus のテキスト形式を求めているかを返します
+// Prometheus のスクレイプは text/plain・application/openmetrics-text を送るため、/metrics のままで収集できる
+func acceptsPrometheus(accept string) bool {
+	accept = strings.ToLower(accept)
+	if strings.Contains(accept, "application/json") {
+		return false
+	}
+	return strings.Contains(accept, "text/plain") || strings.Contains(accept, "application/openmetrics-text")
+}
+
+// writeHTTPMetrics は、HTTP リクエストの件数（合計・ステータスコード別）と処理中の件数を書き出します
+// ルートグループ別の所要時間のヒストグラムは SLOTracker が出力する
+func (m *Metrics) writeHTTPMetrics(sb *strings.Builder) {
+	writeHeader(sb, "simple_notion_http_requests_total", "counter", "HTTP requests handled.")
+	fmt.Fprintf(sb, "simple_notion_http_requests_total %d\n", atomic.LoadInt64(&m.httpRequestsTotal))
+
+	writeHeader(sb, "simple_notion_http_errors_total", "counter", "HTTP requests answered with a 4xx or 5xx status.")
+	fmt.Fprintf(sb, "simple_notion_http_errors_total %d\n", atomic.LoadInt64(&m.httpErrorsTotal))
+
+	writeHeader(sb, "simple_notion_http_responses_total", "counter", "HTTP responses by status class.")
+	for class := 1; class <= 5; class++ {
+		fmt.Fprintf(sb, "simple_notion_http_responses_total{code=\"%dxx\"} %d\n", class, atomic.LoadInt64(&m.httpStatusClasses[class]))
+	}
+
+	writeHeader(sb, "simple_notion_http_active_requests", "gauge", "HTTP requests currently being handled.")
+	fmt.Fprintf(sb, "simple_notion_http_active_requests %d\n", atomic.LoadInt64(&m.httpActiveRequests))
+}
+
+// writeApplicationMetrics は、ログ・エラー・名前付きのカウンターとデータベース接続数を書き出します
+func (m *Metrics) writeApplicationMetrics(sb *strings.Builder) {
+	writeHeader(sb, "simple_notion_database_connections", "gauge", "Open database connections.")
+	fmt.Fprintf(sb, "simple_notion_database_connections %d\n", atomic.LoadInt64(&m.databaseConnections))
+
+	m.logMutex.RLock()
+	writeHeader(sb, "simple_notion_log_messages_total", "counter", "Log messages by level.")
+	writeLabeledCounters(sb, "simple_notion_log_messages_total", "level", m.logCounters)
+	m.logMutex.RUnlock()
+
+	m.errorMutex.RLock()
+	writeHeader(sb, "simple_notion_errors_total", "counter", "Errors logged by component.")
+	writeLabeledCounters(sb, "simple_notion_errors_total", "component", m.errorCounters)
+	m.errorMutex.RUnlock()
+
+	m.counterMutex.RLock()
+	writeHeader(sb, "simple_notion_events_total", "counter", "Named application counters (background jobs, rate limiting, ...).")
+	writeLabeledCounters(sb, "simple_notion_events_total", "name", m.counters)
+	m.counterMutex.RUnlock()
+}
+
+// writeOperationLatencies は、処理ごとの所要時間をヒストグラムとして書き出します
+func (m *Metrics) writeOperationLatencies(sb *strings.Builder) {
+	m.latencyMutex.RLock()
+	defer m.latencyMutex.RUnlock()
+
+	names := make([]string, 0, len(m.latencies))
+	for name := range m.latencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writeHeader(sb, "simple_notion_operation_duration_seconds", "histogram", "Duration of instrumented operations (password hashing, background jobs, ...).")
+	for _, name := range names {
+		stats := m.latencies[name]
+		for i, b := range operationLatencyBuckets {
+			fmt.Fprintf(sb, "simple_notion_operation_duration_seconds_bucket{operation=%q,le=%q} %d\n",
+				name, formatFloat(b), stats.bucketCounts[i])
+		}
+		fmt.Fprintf(sb, "simple_notion_operation_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", name, stats.count)
+		fmt.Fprintf(sb, "simple_notion_operation_duration_seconds_sum{operation=%q} %s\n", name, formatFloat(stats.total.Seconds()))
+		fmt.Fprintf(sb, "simple_notion_operation_duration_seconds_count{operation=%q} %d\n", name, stats.count)
+	}
+
+	writeHeader(sb, "simple_notion_operation_duration_max_seconds", "gauge", "Longest observed duration of instrumented operations since process start.")
+	for _, name := range names {
+		fmt.Fprintf(sb, "simple_notion_operation_duration_max_seconds{operation=%q} %s\n", name, formatFloat(m.latencies[name].max.Seconds()))
+	}
+}
+
+// writeRuntimeMetrics は、プロセスと Go ランタイムの情報を書き出します（一般的なダッシュボードが参照する名前で出力する）
+func writeRuntimeMetrics(sb *strings.Builder, startTime int64) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	writeHeader(sb, "process_start_time_seconds", "gauge", "Start time of the process since unix epoch in seconds.")
+	fmt.Fprintf(sb, "process_start_time_seconds %d\n", startTime)
+
+	writeHeader(sb, "go_info", "gauge", "Information about the Go environment.")
+	fmt.Fprintf(sb, "go_info{version=%q} 1\n", runtime.Version())
+
+	writeHeader(sb, "go_goroutines", "gauge", "Number of goroutines that currently exist.")
+	fmt.Fprintf(sb, "go_goroutines %d\n", runtime.NumGoroutine())
+
+	writeHeader(sb, "go_memstats_alloc_bytes", "gauge", "Number of bytes allocated and still in use.")
+	fmt.Fprintf(sb, "go_memstats_alloc_bytes %d\n", memStats.Alloc)
+
+	writeHeader(sb, "go_memstats_alloc_bytes_total", "counter", "Total number of bytes allocated, even if freed.")
+	fmt.Fprintf(sb, "go_memstats_alloc_bytes_total %d\n", memStats.TotalAlloc)
+
+	writeHeader(sb, "go_memstats_sys_bytes", "gauge", "Number of bytes obtained from system.")
+	fmt.Fprintf(sb, "go_memstats_sys_bytes %d\n", memStats.Sys)
+
+	writeHeader(sb, "go_gc_cycles_total", "counter", "Number of completed GC cycles.")
+	fmt.Fprintf(sb, "go_gc_cycles_total %d\n", memStats.NumGC)
+}
+
+// writeLabeledCounters は、ラベルの値ごとのカウンターを名前順で書き出します
+func writeLabeledCounters(sb *strings.Builder, name, label string, values map[string]int64) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(sb, "%s{%s=%q} %d\n", name, label, k, values[k])
+	}
+}
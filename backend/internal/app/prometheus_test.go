@@ -0,0 +1,77 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/config"
+)
+
+// TestMetricsWritePrometheus - Prometheus のテキスト形式の出力のテスト
+func TestMetricsWritePrometheus(t *testing.T) {
+	m := NewMetrics(&config.Config{SLOAvailabilityTarget: 0.999, SLOLatencyTarget: 0.99, SLOLatencyThresholdMs: 500})
+
+	handler := m.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/documents", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/missing", nil))
+	m.AddCount("file_purge_purged", 3)
+	m.ObserveLatency("password_hash", 200*time.Millisecond)
+	m.IncrementLogCount("ERROR")
+
+	var sb strings.Builder
+	if err := m.WritePrometheus(&sb); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	out := sb.String()
+
+	want := []string{
+		"# TYPE simple_notion_http_requests_total counter",
+		"simple_notion_http_requests_total 2\n",
+		`simple_notion_http_responses_total{code="2xx"} 1`,
+		`simple_notion_http_responses_total{code="4xx"} 1`,
+		`simple_notion_events_total{name="file_purge_purged"} 3`,
+		`simple_notion_log_messages_total{level="ERROR"} 1`,
+		"# TYPE simple_notion_operation_duration_seconds histogram",
+		`simple_notion_operation_duration_seconds_bucket{operation="password_hash",le="0.1"} 0`,
+		`simple_notion_operation_duration_seconds_bucket{operation="password_hash",le="0.25"} 1`,
+		`simple_notion_operation_duration_seconds_bucket{operation="password_hash",le="+Inf"} 1`,
+		`simple_notion_operation_duration_seconds_count{operation="password_hash"} 1`,
+		"# TYPE go_goroutines gauge",
+		`simple_notion_slo_requests_total{route_group="documents"} 1`,
+	}
+	for _, line := range want {
+		if !strings.Contains(out, line) {
+			t.Errorf("output does not contain %q", line)
+		}
+	}
+}
+
+// TestAcceptsPrometheus - Accept ヘッダーによる出力形式の判定のテスト
+func TestAcceptsPrometheus(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   bool
+	}{
+		{name: "正常系：Prometheus のスクレイプ", accept: "application/openmetrics-text;version=1.0.0;q=0.75,text/plain;version=0.0.4;q=0.5,*/*;q=0.1", want: true},
+		{name: "正常系：text/plain", accept: "text/plain", want: true},
+		{name: "正常系：JSON を求める場合は JSON", accept: "application/json, text/plain, */*", want: false},
+		{name: "正常系：指定なしは JSON", accept: "", want: false},
+		{name: "正常系：*/* は JSON", accept: "*/*", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := acceptsPrometheus(tt.accept); got != tt.want {
+				t.Errorf("acceptsPrometheus(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,26 +2,76 @@ package app
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 
+	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/config"
 	"simple-notion-backend/internal/handlers"
+	"simple-notion-backend/internal/handlers/admin"
+	"simple-notion-backend/internal/handlers/batch"
+	"simple-notion-backend/internal/handlers/blocktype"
+	"simple-notion-backend/internal/handlers/comment"
 	"simple-notion-backend/internal/handlers/document"
+	"simple-notion-backend/internal/handlers/embed"
+	"simple-notion-backend/internal/handlers/graph"
+	"simple-notion-backend/internal/handlers/notification"
+	"simple-notion-backend/internal/handlers/reminder"
+	"simple-notion-backend/internal/handlers/share"
+	"simple-notion-backend/internal/handlers/task"
 	"simple-notion-backend/internal/handlers/upload"
+	"simple-notion-backend/internal/handlers/webhook"
+	"simple-notion-backend/internal/handlers/workspace"
 	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/ratelimit"
+	"simple-notion-backend/internal/storage"
+	"simple-notion-backend/internal/tracing"
 )
 
 // Router は、アプリケーションのHTTPルーターを管理する構造体です
 type Router struct {
-	router        *mux.Router
-	authHandler   *handlers.AuthHandler
-	docHandler    *document.DocumentHandler
-	uploadHandler *upload.UploadHandler
-	jwtSecret     []byte
-	metrics       *Metrics
+	router              *mux.Router
+	authHandler         *handlers.AuthHandler
+	docHandler          *document.DocumentHandler
+	uploadHandler       *upload.UploadHandler
+	resumableHandler    *upload.ResumableUploadHandler
+	progressHandler     *upload.ProgressHandler
+	fileVersionHandler  *upload.FileVersionHandler
+	signedObjectHandler *upload.SignedObjectHandler
+	notificationHandler *notification.NotificationHandler
+	watchHandler        *notification.WatchHandler
+	graphQLHandler      *graph.GraphQLHandler
+	webhookHandler      *webhook.WebhookHandler
+	reminderHandler     *reminder.ReminderHandler
+	taskHandler         *task.TaskHandler
+	commentHandler      *comment.CommentHandler
+	docCommentHandler   *comment.DocumentCommentHandler
+	shareLinkHandler    *share.ShareLinkHandler
+	collaboratorHandler *share.CollaboratorHandler
+	embedHandler        *embed.EmbedHandler
+	blockTypeHandler    *blocktype.BlockTypeHandler
+	adminHandler        *admin.AdminHandler
+	profilingHandler    *admin.ProfilingHandler
+	adminChecker        middleware.AdminChecker
+	workspaceHandler    *workspace.WorkspaceHandler
+	workspaceChecker    middleware.WorkspaceChecker
+	documentChecker     middleware.DocumentAccessChecker
+	status              *StatusReporter
+	health              *HealthChecker
+	jwtSecret           []byte
+	metrics             *Metrics
+	rateLimiter         ratelimit.Limiter // nil の場合はリクエストの頻度を制限しない
+	ipRateLimit         ratelimit.Limit
+	userRateLimit       ratelimit.Limit
+	trustProxy          bool         // 送信元の IP アドレスを X-Forwarded-For から取得する
+	apiDocsEnabled      bool         // Swagger UI（/api/docs）を公開するか
+	openAPISpec         *OpenAPISpec // SetupRoutes で全てのエンドポイントを登録した後に生成する
 }
 
 // NewRouter は、新しいRouterインスタンスを作成します
@@ -43,28 +93,100 @@ func NewRouter(
 // NewRouterFromDependencies は、Dependenciesから新しいRouterインスタンスを作成します
 func NewRouterFromDependencies(deps *Dependencies) *Router {
 	return &Router{
-		router:        mux.NewRouter(),
-		authHandler:   deps.AuthHandler,
-		docHandler:    deps.DocumentHandler,
-		uploadHandler: deps.UploadHandler,
-		jwtSecret:     deps.GetJWTSecret(),
+		router:              mux.NewRouter(),
+		authHandler:         deps.AuthHandler,
+		docHandler:          deps.DocumentHandler,
+		uploadHandler:       deps.UploadHandler,
+		resumableHandler:    deps.ResumableHandler,
+		progressHandler:     deps.ProgressHandler,
+		fileVersionHandler:  deps.FileVersionHandler,
+		signedObjectHandler: deps.SignedObjectHandler,
+		notificationHandler: deps.NotificationHandler,
+		watchHandler:        deps.WatchHandler,
+		graphQLHandler:      deps.GraphQLHandler,
+		webhookHandler:      deps.WebhookHandler,
+		reminderHandler:     deps.ReminderHandler,
+		taskHandler:         deps.TaskHandler,
+		commentHandler:      deps.CommentHandler,
+		docCommentHandler:   deps.DocumentCommentHandler,
+		shareLinkHandler:    deps.ShareLinkHandler,
+		collaboratorHandler: deps.CollaboratorHandler,
+		embedHandler:        deps.EmbedHandler,
+		blockTypeHandler:    deps.BlockTypeHandler,
+		adminHandler:        deps.AdminHandler,
+		profilingHandler:    deps.ProfilingHandler,
+		adminChecker:        deps.AdminService,
+		workspaceHandler:    deps.WorkspaceHandler,
+		workspaceChecker:    deps.WorkspaceService,
+		documentChecker:     deps.CollaboratorService,
+		status:              NewStatusReporter(deps.Database, nil),
+		health:              NewHealthChecker(deps.Database, deps.ObjectStorage),
+		jwtSecret:           deps.GetJWTSecret(),
+		apiDocsEnabled:      deps.Config != nil && deps.Config.APIDocsEnabled,
+		rateLimiter:         deps.RateLimiter,
+		ipRateLimit:         ipRateLimit(deps.Config),
+		userRateLimit:       userRateLimit(deps.Config),
+		trustProxy:          deps.Config != nil && deps.Config.TrustProxyHeaders,
 	}
 }
 
 // NewRouterWithMetrics は、DependenciesとMetricsから新しいRouterインスタンスを作成します
 func NewRouterWithMetrics(deps *Dependencies, metrics *Metrics) *Router {
 	return &Router{
-		router:        mux.NewRouter(),
-		authHandler:   deps.AuthHandler,
-		docHandler:    deps.DocumentHandler,
-		uploadHandler: deps.UploadHandler,
-		jwtSecret:     deps.GetJWTSecret(),
-		metrics:       metrics,
+		router:              mux.NewRouter(),
+		authHandler:         deps.AuthHandler,
+		docHandler:          deps.DocumentHandler,
+		uploadHandler:       deps.UploadHandler,
+		resumableHandler:    deps.ResumableHandler,
+		progressHandler:     deps.ProgressHandler,
+		fileVersionHandler:  deps.FileVersionHandler,
+		signedObjectHandler: deps.SignedObjectHandler,
+		notificationHandler: deps.NotificationHandler,
+		watchHandler:        deps.WatchHandler,
+		graphQLHandler:      deps.GraphQLHandler,
+		webhookHandler:      deps.WebhookHandler,
+		reminderHandler:     deps.ReminderHandler,
+		taskHandler:         deps.TaskHandler,
+		commentHandler:      deps.CommentHandler,
+		docCommentHandler:   deps.DocumentCommentHandler,
+		shareLinkHandler:    deps.ShareLinkHandler,
+		collaboratorHandler: deps.CollaboratorHandler,
+		embedHandler:        deps.EmbedHandler,
+		blockTypeHandler:    deps.BlockTypeHandler,
+		adminHandler:        deps.AdminHandler,
+		profilingHandler:    deps.ProfilingHandler,
+		adminChecker:        deps.AdminService,
+		workspaceHandler:    deps.WorkspaceHandler,
+		workspaceChecker:    deps.WorkspaceService,
+		documentChecker:     deps.CollaboratorService,
+		status:              NewStatusReporter(deps.Database, metrics),
+		health:              NewHealthChecker(deps.Database, deps.ObjectStorage),
+		jwtSecret:           deps.GetJWTSecret(),
+		apiDocsEnabled:      deps.Config != nil && deps.Config.APIDocsEnabled,
+		rateLimiter:         deps.RateLimiter,
+		ipRateLimit:         ipRateLimit(deps.Config),
+		userRateLimit:       userRateLimit(deps.Config),
+		trustProxy:          deps.Config != nil && deps.Config.TrustProxyHeaders,
+		metrics:             metrics,
 	}
 }
 
 // SetupRoutes は、全てのエンドポイントを設定します
 func (r *Router) SetupRoutes() {
+	// 存在しないエンドポイント・メソッドも統一エラーレスポンスで返す（mux の既定はテキストの本文）
+	r.router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		apierror.Write(w, req, apierror.NewNotFound("ROUTE_NOT_FOUND", "エンドポイントが見つかりません", nil))
+	})
+	r.router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		apierror.Write(w, req, apierror.NewMethodNotAllowed("METHOD_NOT_ALLOWED", "このメソッドは使用できません", nil))
+	})
+
+	// トレースのスパンにルートのパターンで名前を付ける（パスをそのまま使うと ID ごとに別の名前になる）
+	r.router.Use(nameTraceSpan)
+
+	// IP アドレスごとのリクエストの頻度の制限（全てのエンドポイントの前に適用する）
+	r.setupIPRateLimit()
+
 	// ヘルスチェックエンドポイント
 	r.setupHealthCheck()
 
@@ -76,6 +198,79 @@ func (r *Router) SetupRoutes() {
 
 	// 認証必要エンドポイント
 	r.setupProtectedRoutes()
+
+	// APIの仕様（全てのエンドポイントを登録した後に、登録内容から生成する）
+	spec, err := BuildOpenAPISpec(r.router)
+	if err != nil {
+		log.Printf("Failed to build OpenAPI spec: %v", err)
+		return
+	}
+	r.openAPISpec = spec
+}
+
+// setupIPRateLimit は、IP アドレスごとのリクエストの頻度の制限を設定します
+// ヘルスチェック・メトリクスは監視から定期的に呼ばれるため制限しない
+func (r *Router) setupIPRateLimit() {
+	if r.rateLimiter == nil || !r.ipRateLimit.Enabled() {
+		return
+	}
+
+	r.router.Use(r.rateLimitByIP)
+}
+
+// rateLimitByIP は next に IP アドレスごとのレート制限を適用します（制限が無効な場合は next をそのまま返す）
+// gRPC の待ち受けにも適用し、同じクライアントからの HTTP と gRPC のリクエストで上限を共有する
+func (r *Router) rateLimitByIP(next http.Handler) http.Handler {
+	if r.rateLimiter == nil || !r.ipRateLimit.Enabled() {
+		return next
+	}
+
+	return middleware.RateLimitMiddleware(r.rateLimiter, r.ipRateLimit, func(req *http.Request) string {
+		switch {
+		case req.URL.Path == "/api/health", req.URL.Path == "/api/status", req.URL.Path == "/healthz", req.URL.Path == "/readyz",
+			strings.HasPrefix(req.URL.Path, "/metrics"):
+			return ""
+		}
+		return "ip:" + middleware.ClientIP(req, r.trustProxy)
+	}, r.countThrottled("ip"))(next)
+}
+
+// nameTraceSpan は、リクエストのスパンの名前を "メソッド ルートのパターン" にします（トレーシングが無効な場合は何もしない）
+func nameTraceSpan(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if span, route := tracing.SpanFromContext(req.Context()), mux.CurrentRoute(req); span != nil && route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				span.SetName(req.Method + " " + template)
+				span.SetAttributes(tracing.String("http.route", template))
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// countThrottled は、頻度の制限で拒否したリクエストを種類（ip / user）ごとに数える関数を返します
+func (r *Router) countThrottled(scope string) func(*http.Request) {
+	return func(*http.Request) {
+		if r.metrics != nil {
+			r.metrics.AddCount("rate_limit_throttled_"+scope, 1)
+		}
+	}
+}
+
+// ipRateLimit は、設定から IP アドレスごとの制限を作成します
+func ipRateLimit(cfg *config.Config) ratelimit.Limit {
+	if cfg == nil {
+		return ratelimit.Limit{}
+	}
+	return ratelimit.Limit{PerMinute: cfg.RateLimitIPPerMinute, Burst: cfg.RateLimitIPBurst}
+}
+
+// userRateLimit は、設定からログインユーザーごとの制限を作成します
+func userRateLimit(cfg *config.Config) ratelimit.Limit {
+	if cfg == nil {
+		return ratelimit.Limit{}
+	}
+	return ratelimit.Limit{PerMinute: cfg.RateLimitUserPerMinute, Burst: cfg.RateLimitUserBurst}
 }
 
 // setupHealthCheck は、ヘルスチェックエンドポイントを設定します
@@ -85,6 +280,17 @@ func (r *Router) setupHealthCheck() {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"status":"ok"}`))
 	}).Methods("GET")
+
+	// 公開ステータスページ向け（大まかな状態・バージョン・稼働時間のみ）
+	if r.status != nil {
+		r.router.Handle("/api/status", r.status).Methods("GET")
+	}
+
+	// コンテナのプローブ向け（生存: プロセスのみ、受け付け可能か: DB・ストレージ・マイグレーション）
+	if r.health != nil {
+		r.router.HandleFunc("/healthz", r.health.ServeLive).Methods("GET")
+		r.router.HandleFunc("/readyz", r.health.ServeReady).Methods("GET")
+	}
 }
 
 // setupMetricsEndpoints は、メトリクスエンドポイントを設定します
@@ -93,18 +299,33 @@ func (r *Router) setupMetricsEndpoints() {
 		return
 	}
 
+	// Accept で Prometheus のテキスト形式を求められた場合は /metrics/prometheus と同じ内容を返す
 	r.router.HandleFunc("/metrics", func(w http.ResponseWriter, req *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-
+		if acceptsPrometheus(req.Header.Get("Accept")) {
+			r.servePrometheus(w, req)
+			return
+		}
 		snapshot := r.metrics.GetSnapshot()
-		if data, err := json.Marshal(snapshot); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(`{"error":"failed to marshal metrics"}`))
-		} else {
-			w.WriteHeader(http.StatusOK)
-			w.Write(data)
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			apierror.Write(w, req, apierror.NewInternal(fmt.Errorf("failed to marshal metrics: %w", err)))
+			return
 		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
 	}).Methods("GET")
+
+	// 全てのメトリクスと SLO 用の SLI（Prometheus のテキスト形式、アラートルール・ダッシュボードから直接参照できる）
+	r.router.HandleFunc("/metrics/prometheus", r.servePrometheus).Methods("GET")
+}
+
+// servePrometheus は、メトリクスを Prometheus のテキスト形式で返します
+func (r *Router) servePrometheus(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", PrometheusContentType)
+	if err := r.metrics.WritePrometheus(w); err != nil {
+		log.Printf("Failed to write Prometheus metrics: %v", err)
+	}
 }
 
 // setupPublicRoutes は、認証不要エンドポイントを設定します
@@ -112,35 +333,257 @@ func (r *Router) setupPublicRoutes() {
 	r.router.HandleFunc("/api/auth/login", r.authHandler.Login).Methods("POST")
 	r.router.HandleFunc("/api/auth/register", r.authHandler.Register).Methods("POST")
 	r.router.HandleFunc("/api/auth/logout", r.authHandler.Logout).Methods("POST")
+	r.router.HandleFunc("/api/auth/login-status", r.authHandler.LoginStatus).Methods("GET")
 
 	// 静的ファイル配信（MinIO経由）
 	r.router.HandleFunc("/api/uploads/{filename}", r.uploadHandler.ServeFile).Methods("GET")
+
+	// ローカルディスク・中継するストレージのファイルの署名付きURL（認証の代わりにURLの署名を検証する）
+	if r.signedObjectHandler != nil {
+		r.router.HandleFunc(storage.SignedURLPrefix+"{key:.+}", r.signedObjectHandler.GetObject).Methods("GET")
+		r.router.HandleFunc(storage.SignedURLPrefix+"{key:.+}", r.signedObjectHandler.PutObject).Methods("PUT")
+	}
+
+	// 公開中ドキュメントの閲覧
+	r.router.HandleFunc("/api/public/documents/{id:[0-9]+}", r.docHandler.GetPublicDocument).Methods("GET")
+
+	// 共有リンクからの文書の閲覧（リンクのトークンで認証する）
+	r.router.HandleFunc("/api/share/{token}", r.shareLinkHandler.GetSharedDocument).Methods("GET")
+
+	// ワークスペースへの招待リンク（確認とアカウントの作成。ログイン済みの場合は /api/invitations/{token}/accept）
+	r.router.HandleFunc("/api/invitations/{token}", r.workspaceHandler.GetInvitation).Methods("GET")
+	r.router.HandleFunc("/api/invitations/{token}/register", r.authHandler.RegisterWithInvitation).Methods("POST")
+
+	// APIの仕様（OpenAPI 3）と、それを表示する Swagger UI（API_DOCS_ENABLED で有効にした場合のみ）
+	r.router.HandleFunc("/api/openapi.json", r.serveOpenAPISpec).Methods("GET")
+	if r.apiDocsEnabled {
+		r.router.HandleFunc("/api/docs", serveSwaggerUI).Methods("GET")
+	}
 }
 
 // setupProtectedRoutes は、認証必要エンドポイントを設定します
 func (r *Router) setupProtectedRoutes() {
+	// ワークスペースの切り替え（メンバーから外れたワークスペースからも戻れるよう、メンバーの確認より前に登録する）
+	r.router.Handle("/api/auth/workspace",
+		middleware.AuthMiddleware(r.jwtSecret)(http.HandlerFunc(r.authHandler.SwitchWorkspace)),
+	).Methods("PUT")
+
 	// 認証が必要なAPIのサブルーター
 	api := r.router.PathPrefix("/api").Subrouter()
 	api.Use(middleware.AuthMiddleware(r.jwtSecret))
+	if r.rateLimiter != nil && r.userRateLimit.Enabled() {
+		api.Use(middleware.RateLimitMiddleware(r.rateLimiter, r.userRateLimit, func(req *http.Request) string {
+			return "user:" + strconv.Itoa(middleware.GetUserIDFromContext(req.Context()))
+		}, r.countThrottled("user")))
+	}
+	if r.workspaceChecker != nil {
+		api.Use(middleware.WorkspaceMiddleware(r.workspaceChecker))
+	}
 
 	// 認証関連
 	api.HandleFunc("/auth/me", r.authHandler.Me).Methods("GET")
 
+	// 文書の変更・ファイルのアップロードは、選択中のワークスペースで閲覧のみの役割（guest）には許可しない
+	editor := middleware.RequireWorkspaceEditor
+
+	// 文書（{id}）の変更は共同編集者の役割も確認する（viewer は変更できず、削除・移動・公開・共有は所有者のみ）
+	docEditor, docOwner := editor, editor
+	if r.documentChecker != nil {
+		requireDocEditor := middleware.RequireDocumentEditor(r.documentChecker)
+		requireDocOwner := middleware.RequireDocumentOwner(r.documentChecker)
+		docEditor = func(next http.HandlerFunc) http.HandlerFunc { return editor(requireDocEditor(next)) }
+		docOwner = func(next http.HandlerFunc) http.HandlerFunc { return editor(requireDocOwner(next)) }
+	}
+
 	// ファイルアップロード関連（画像のみサポート）
-	api.HandleFunc("/upload/image", r.uploadHandler.UploadImage).Methods("POST", "OPTIONS")
+	api.HandleFunc("/upload/image", editor(r.uploadHandler.UploadImage)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/upload/file", editor(r.uploadHandler.UploadFile)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/upload/video", editor(r.uploadHandler.UploadVideo)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/upload/audio", editor(r.uploadHandler.UploadAudio)).Methods("POST", "OPTIONS")
+
+	// 再開可能な分割アップロード（作成 → PATCH でチャンクを追記 → 完了）
+	api.HandleFunc("/upload/sessions", editor(r.resumableHandler.CreateSession)).Methods("POST")
+	api.HandleFunc("/upload/sessions/{id:[0-9a-f-]+}", r.resumableHandler.GetSession).Methods("GET")
+	api.HandleFunc("/upload/sessions/{id:[0-9a-f-]+}", editor(r.resumableHandler.AppendChunk)).Methods("PATCH")
+	api.HandleFunc("/upload/sessions/{id:[0-9a-f-]+}", editor(r.resumableHandler.AbortSession)).Methods("DELETE")
+	api.HandleFunc("/upload/sessions/{id:[0-9a-f-]+}/complete", editor(r.resumableHandler.CompleteSession)).Methods("POST")
+
+	// アップロードの進捗（Server-Sent Events。X-Upload-ID に指定したID または分割アップロードのセッションIDで購読）
+	api.HandleFunc("/upload/progress/{id}", r.progressHandler.StreamProgress).Methods("GET")
+
+	// 署名付きURLによるストレージへの直接アップロード（発行 → ブラウザから PUT → 確認）
+	api.HandleFunc("/upload/direct", editor(r.resumableHandler.CreateDirectUpload)).Methods("POST")
+	api.HandleFunc("/upload/direct/{id:[0-9a-f-]+}/confirm", editor(r.resumableHandler.ConfirmDirectUpload)).Methods("POST")
+
+	// ファイル一覧（種類・MIME タイプ・アップロード日時・文書での絞り込み、並べ替え、ページング）
+	api.HandleFunc("/files", r.uploadHandler.ListFiles).Methods("GET")
+	api.HandleFunc("/files/export", r.uploadHandler.ExportFiles).Methods("GET")
 	api.HandleFunc("/files/{id:[0-9]+}/url", r.uploadHandler.GetPresignedURL).Methods("GET")
+	api.HandleFunc("/files/{id:[0-9]+}", editor(r.uploadHandler.UpdateFile)).Methods("PATCH")
+	api.HandleFunc("/files/{id:[0-9]+}/download", r.uploadHandler.DownloadFile).Methods("GET")
+	api.HandleFunc("/files/{id:[0-9]+}/attach", editor(r.uploadHandler.AttachFile)).Methods("PUT")
+	api.HandleFunc("/files/{id:[0-9]+}/detach", editor(r.uploadHandler.DetachFile)).Methods("PUT")
+
+	// ファイルの版（同じファイルIDのまま新しい版をアップロード・以前の版を復元）
+	api.HandleFunc("/files/{id:[0-9]+}/versions", r.fileVersionHandler.ListVersions).Methods("GET")
+	api.HandleFunc("/files/{id:[0-9]+}/versions", editor(r.fileVersionHandler.UploadVersion)).Methods("POST")
+	api.HandleFunc("/files/{id:[0-9]+}/versions/{versionId:[0-9]+}/restore", editor(r.fileVersionHandler.RestoreVersion)).Methods("POST")
 	api.HandleFunc("/storage/usage", r.uploadHandler.GetStorageUsage).Methods("GET")
 
+	// ごみ箱関連
+	api.HandleFunc("/trash/restore", editor(r.docHandler.BulkRestoreDocuments)).Methods("POST")
+
 	// ドキュメント関連
 	api.HandleFunc("/documents", r.docHandler.GetDocuments).Methods("GET")
-	api.HandleFunc("/documents", r.docHandler.CreateDocument).Methods("POST")
+	api.HandleFunc("/documents", editor(r.docHandler.CreateDocument)).Methods("POST")
 	api.HandleFunc("/documents/tree", r.docHandler.GetDocumentTree).Methods("GET")
+	api.HandleFunc("/documents/search", r.docHandler.SearchDocuments).Methods("GET")
+	api.HandleFunc("/documents/events", r.docHandler.StreamDocumentEvents).Methods("GET") // 変更の通知（Server-Sent Events）
 	api.HandleFunc("/documents/{id:[0-9]+}", r.docHandler.GetDocument).Methods("GET")
-	api.HandleFunc("/documents/{id:[0-9]+}", r.docHandler.UpdateDocument).Methods("PUT")
-	api.HandleFunc("/documents/{id:[0-9]+}", r.docHandler.DeleteDocument).Methods("DELETE")
-	api.HandleFunc("/documents/{id:[0-9]+}/restore", r.docHandler.RestoreDocument).Methods("PUT")
-	api.HandleFunc("/documents/{id:[0-9]+}/permanent", r.docHandler.PermanentDeleteDocument).Methods("DELETE")
-	api.HandleFunc("/documents/{id:[0-9]+}/move", r.docHandler.MoveDocument).Methods("PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}", docEditor(r.docHandler.UpdateDocument)).Methods("PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}", docEditor(r.docHandler.PatchDocument)).Methods("PATCH")
+	api.HandleFunc("/documents/{id:[0-9]+}", docOwner(r.docHandler.DeleteDocument)).Methods("DELETE")
+	api.HandleFunc("/documents/{id:[0-9]+}/restore", editor(r.docHandler.RestoreDocument)).Methods("PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}/permanent", editor(r.docHandler.PermanentDeleteDocument)).Methods("DELETE")
+	api.HandleFunc("/documents/{id:[0-9]+}/move", docOwner(r.docHandler.MoveDocument)).Methods("PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks", docEditor(r.docHandler.CreateBlock)).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/copy", docEditor(r.docHandler.CopyBlocks)).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/{blockId:[0-9]+}", docEditor(r.docHandler.UpdateBlock)).Methods("PATCH")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/{blockId:[0-9]+}", docEditor(r.docHandler.DeleteBlock)).Methods("DELETE")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/{blockId:[0-9]+}/duplicate", docEditor(r.docHandler.DuplicateBlock)).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/{blockId:[0-9]+}/sync", docEditor(r.docHandler.SyncBlock)).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/{blockId:[0-9]+}/history", r.docHandler.GetBlockHistory).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/{blockId:[0-9]+}/collapsed", r.docHandler.SetBlockCollapsed).Methods("PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/{blockId:[0-9]+}/preview", docEditor(r.docHandler.RefreshBookmarkPreview)).Methods("POST")
+	// {id} はブロックIDのため docEditor は使えない（ブロックの文書に対する編集権限は DocumentService.UpdateBlock で確認する）
+	api.HandleFunc("/blocks/{id:[0-9]+}/convert", editor(r.docHandler.ConvertBlock)).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/export", r.docHandler.ExportDocument).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/versions", r.docHandler.GetDocumentVersions).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/diff", r.docHandler.GetDocumentDiff).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/publication", r.docHandler.GetPublication).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/publication", docOwner(r.docHandler.UpdatePublication)).Methods("PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}/share-links", r.shareLinkHandler.GetShareLinks).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/share-links", docOwner(r.shareLinkHandler.CreateShareLink)).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/share-links/snapshot", docOwner(r.shareLinkHandler.CreateSnapshotLink)).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/share-links/{linkId:[0-9]+}", docOwner(r.shareLinkHandler.RevokeShareLink)).Methods("DELETE")
+	api.HandleFunc("/documents/{id:[0-9]+}/collaborators", r.collaboratorHandler.GetCollaborators).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/collaborators", docOwner(r.collaboratorHandler.AddCollaborator)).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/collaborators/{userId:[0-9]+}", docOwner(r.collaboratorHandler.UpdateCollaborator)).Methods("PATCH")
+	api.HandleFunc("/documents/{id:[0-9]+}/collaborators/{userId:[0-9]+}", r.collaboratorHandler.RemoveCollaborator).Methods("DELETE")
+	api.HandleFunc("/documents/{id:[0-9]+}/expiration", r.docHandler.GetExpiration).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/expiration", docOwner(r.docHandler.UpdateExpiration)).Methods("PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}/expiration", docOwner(r.docHandler.DeleteExpiration)).Methods("DELETE")
+	api.HandleFunc("/documents/{id:[0-9]+}/presence", r.docHandler.GetPresence).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/presence", r.docHandler.JoinPresence).Methods("PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}/presence", r.docHandler.LeavePresence).Methods("DELETE")
+	api.HandleFunc("/documents/{id:[0-9]+}/cursor", r.docHandler.UpdateCursor).Methods("PUT")
+
+	// 通知関連
+	api.HandleFunc("/notifications", r.notificationHandler.GetNotifications).Methods("GET")
+	api.HandleFunc("/notifications/{id:[0-9]+}/read", r.notificationHandler.MarkRead).Methods("PUT")
+
+	// 文書の変更の購読（他のユーザーが変更するとアプリ内通知、またはまとめたメールで知らせる）
+	api.HandleFunc("/watches", r.watchHandler.GetWatches).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/watch", r.watchHandler.WatchDocument).Methods("PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}/watch", r.watchHandler.UnwatchDocument).Methods("DELETE")
+
+	// リマインダー関連
+	api.HandleFunc("/documents/{id:[0-9]+}/reminders", r.reminderHandler.CreateReminder).Methods("POST")
+	api.HandleFunc("/reminders", r.reminderHandler.GetReminders).Methods("GET")
+	api.HandleFunc("/reminders/{id:[0-9]+}/dismiss", r.reminderHandler.DismissReminder).Methods("PUT")
+
+	// ブロックへのコメント
+	api.HandleFunc("/documents/{id:[0-9]+}/comments", r.commentHandler.GetComments).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/{blockId:[0-9]+}/comments", r.commentHandler.CreateComment).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/comments/{commentId:[0-9]+}", r.commentHandler.UpdateComment).Methods("PATCH")
+	api.HandleFunc("/documents/{id:[0-9]+}/comments/{commentId:[0-9]+}", r.commentHandler.DeleteComment).Methods("DELETE")
+
+	// 文書全体へのコメントのスレッド（{commentId} はスレッドの先頭・返信のどちらも指定できる）
+	api.HandleFunc("/documents/{id:[0-9]+}/threads", r.docCommentHandler.GetThreads).Methods("GET")
+	api.HandleFunc("/documents/{id:[0-9]+}/threads", r.docCommentHandler.CreateThread).Methods("POST")
+	api.HandleFunc("/documents/{id:[0-9]+}/threads/{commentId:[0-9]+}", r.docCommentHandler.UpdateComment).Methods("PATCH")
+	api.HandleFunc("/documents/{id:[0-9]+}/threads/{commentId:[0-9]+}", r.docCommentHandler.DeleteComment).Methods("DELETE")
+	api.HandleFunc("/documents/{id:[0-9]+}/threads/{commentId:[0-9]+}/replies", r.docCommentHandler.Reply).Methods("POST")
+
+	// GraphQL（文書・ブロック・ファイル・文書ツリーの読み取り。入れ子の子や添付ファイルを1回で取得する）
+	api.HandleFunc("/graphql", r.graphQLHandler.Query).Methods("GET", "POST")
+
+	// バッチ（複数のリクエストを1回の通信でまとめて実行する。個々のリクエストはルーター全体で処理する）
+	api.HandleFunc("/batch", batch.NewBatchHandler(r.router).Execute).Methods("POST")
+
+	// 自分のタスク（全文書の未完了 todo ブロック）
+	api.HandleFunc("/tasks", r.taskHandler.GetTasks).Methods("GET")
+
+	// 埋め込み（oEmbed）関連
+	api.HandleFunc("/embeds/resolve", r.embedHandler.Resolve).Methods("GET")
+	api.HandleFunc("/embeds/providers", r.embedHandler.GetProviders).Methods("GET")
+
+	// カスタムブロックタイプ（一覧は全ユーザー、登録・変更は管理者のみ）
+	api.HandleFunc("/block-types", r.blockTypeHandler.GetBlockTypes).Methods("GET")
+
+	// ワークスペースとメンバー（扱うワークスペースの選択は PUT /auth/workspace）
+	api.HandleFunc("/workspaces", r.workspaceHandler.GetWorkspaces).Methods("GET")
+	api.HandleFunc("/workspaces", r.workspaceHandler.CreateWorkspace).Methods("POST")
+	api.HandleFunc("/workspaces/{id:[0-9]+}", r.workspaceHandler.GetWorkspace).Methods("GET")
+	api.HandleFunc("/workspaces/{id:[0-9]+}", r.workspaceHandler.UpdateWorkspace).Methods("PATCH")
+	api.HandleFunc("/workspaces/{id:[0-9]+}", r.workspaceHandler.DeleteWorkspace).Methods("DELETE")
+	api.HandleFunc("/workspaces/{id:[0-9]+}/members", r.workspaceHandler.GetMembers).Methods("GET")
+	api.HandleFunc("/workspaces/{id:[0-9]+}/members", r.workspaceHandler.AddMember).Methods("POST")
+	api.HandleFunc("/workspaces/{id:[0-9]+}/members/{userId:[0-9]+}", r.workspaceHandler.UpdateMember).Methods("PUT")
+	api.HandleFunc("/workspaces/{id:[0-9]+}/members/{userId:[0-9]+}", r.workspaceHandler.RemoveMember).Methods("DELETE")
+	api.HandleFunc("/workspaces/{id:[0-9]+}/storage", r.workspaceHandler.GetStorageUsage).Methods("GET")
+	api.HandleFunc("/workspaces/{id:[0-9]+}/invitations", r.workspaceHandler.GetInvitations).Methods("GET")
+	api.HandleFunc("/workspaces/{id:[0-9]+}/invitations", r.workspaceHandler.CreateInvitation).Methods("POST")
+	api.HandleFunc("/workspaces/{id:[0-9]+}/invitations/{invitationId:[0-9]+}", r.workspaceHandler.RevokeInvitation).Methods("DELETE")
+	api.HandleFunc("/invitations/{token}/accept", r.workspaceHandler.AcceptInvitation).Methods("POST")
+
+	// Webhook（イベントを登録した URL に署名付きで送信する。secret は作成時と再発行時のみ返す）
+	api.HandleFunc("/webhooks", r.webhookHandler.GetWebhooks).Methods("GET")
+	api.HandleFunc("/webhooks", r.webhookHandler.CreateWebhook).Methods("POST")
+	api.HandleFunc("/webhooks/{id:[0-9]+}", r.webhookHandler.GetWebhook).Methods("GET")
+	api.HandleFunc("/webhooks/{id:[0-9]+}", r.webhookHandler.UpdateWebhook).Methods("PATCH")
+	api.HandleFunc("/webhooks/{id:[0-9]+}", r.webhookHandler.DeleteWebhook).Methods("DELETE")
+	api.HandleFunc("/webhooks/{id:[0-9]+}/secret", r.webhookHandler.RotateSecret).Methods("POST")
+	api.HandleFunc("/webhooks/{id:[0-9]+}/deliveries", r.webhookHandler.GetDeliveries).Methods("GET")
+	api.HandleFunc("/webhooks/{id:[0-9]+}/deliveries/{deliveryId:[0-9]+}/redeliver", r.webhookHandler.Redeliver).Methods("POST")
+
+	r.setupAdminRoutes(api)
+}
+
+// setupAdminRoutes は、管理者専用エンドポイントを設定します
+func (r *Router) setupAdminRoutes(api *mux.Router) {
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.AdminMiddleware(r.adminChecker))
+
+	admin.HandleFunc("/block-types", r.blockTypeHandler.CreateBlockType).Methods("POST")
+	admin.HandleFunc("/block-types/{name}", r.blockTypeHandler.UpdateBlockType).Methods("PUT")
+	admin.HandleFunc("/block-types/{name}", r.blockTypeHandler.DeleteBlockType).Methods("DELETE")
+
+	// インスタンス全体の統計
+	admin.HandleFunc("/stats", r.adminHandler.GetStats).Methods("GET")
+
+	// 孤立データの検出と修復
+	admin.HandleFunc("/maintenance/orphans", r.adminHandler.GetOrphans).Methods("GET")
+	admin.HandleFunc("/maintenance/orphans/repair", r.adminHandler.RepairOrphans).Methods("POST")
+	admin.HandleFunc("/maintenance/storage", r.adminHandler.GetStorageConsistency).Methods("GET")
+
+	// インスタンス全体のストレージ使用量
+	admin.HandleFunc("/storage/usage", r.adminHandler.GetStorageUsage).Methods("GET")
+
+	// ユーザーごとのストレージクォータ
+	admin.HandleFunc("/users/{id:[0-9]+}/storage-quota", r.adminHandler.GetStorageQuota).Methods("GET")
+	admin.HandleFunc("/users/{id:[0-9]+}/storage-quota", r.adminHandler.UpdateStorageQuota).Methods("PUT")
+	admin.HandleFunc("/workspaces/{id:[0-9]+}/storage-quota", r.adminHandler.GetWorkspaceStorageQuota).Methods("GET")
+	admin.HandleFunc("/workspaces/{id:[0-9]+}/storage-quota", r.adminHandler.UpdateWorkspaceStorageQuota).Methods("PUT")
+
+	// プロファイリング（PPROF_ENABLED の場合のみ。{name} は固定のパスより後に登録する）
+	if r.profilingHandler != nil {
+		admin.HandleFunc("/debug/pprof/", r.profilingHandler.Index).Methods("GET")
+		admin.HandleFunc("/debug/pprof/profile", r.profilingHandler.CPUProfile).Methods("GET")
+		admin.HandleFunc("/debug/pprof/trace", r.profilingHandler.Trace).Methods("GET")
+		admin.HandleFunc("/debug/pprof/symbol", r.profilingHandler.Symbol).Methods("GET", "POST")
+		admin.HandleFunc("/debug/pprof/{name}", r.profilingHandler.Lookup).Methods("GET", "POST")
+	}
 }
 
 // GetHandler は、CORS設定を適用したHTTPハンドラーを返します
@@ -152,8 +595,9 @@ func (r *Router) GetHandler(cfg *config.Config) http.Handler {
 			"http://localhost:3000", // 本番フロントエンド
 			"http://frontend:8080",  // Dockerコンテナ間通信
 		},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"Upload-Offset", "Upload-Length", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-Request-ID"},
 		AllowCredentials: true,
 	})
 
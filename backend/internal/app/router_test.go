@@ -0,0 +1,46 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"simple-notion-backend/internal/ratelimit"
+)
+
+// TestRouterRateLimitByIP - gRPC の待ち受けに適用する制限が HTTP と同じ IP アドレスごとの上限を共有することのテスト
+func TestRouterRateLimitByIP(t *testing.T) {
+	limiter := ratelimit.NewMemoryLimiter()
+	defer limiter.Close()
+	r := &Router{rateLimiter: limiter, ipRateLimit: ratelimit.Limit{PerMinute: 60, Burst: 1}}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	httpHandler := r.rateLimitByIP(ok)
+	grpcHandler := r.rateLimitByIP(ok)
+
+	request := func(path string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		return req
+	}
+
+	rec := httptest.NewRecorder()
+	httpHandler.ServeHTTP(rec, request("/api/documents"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("HTTP status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec = httptest.NewRecorder()
+	grpcHandler.ServeHTTP(rec, request("/simplenotion.v1.DocumentService/GetDocument"))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("gRPC status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+
+	// 制限が無効な場合はそのまま処理する
+	disabled := (&Router{}).rateLimitByIP(ok)
+	rec = httptest.NewRecorder()
+	disabled.ServeHTTP(rec, request("/simplenotion.v1.DocumentService/GetDocument"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("disabled status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// JobFunc は、スケジューラーが定期実行する処理の型定義です
+type JobFunc func(ctx context.Context) error
+
+// scheduledJob は、スケジューラーに登録されたジョブです
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler は、バックグラウンドジョブを一定間隔で実行する構造体です
+type Scheduler struct {
+	jobs    []scheduledJob
+	logger  *Logger
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	started bool
+}
+
+// NewScheduler は、新しいSchedulerインスタンスを作成します
+func NewScheduler(logger *Logger) *Scheduler {
+	return &Scheduler{
+		jobs:   make([]scheduledJob, 0),
+		logger: logger,
+	}
+}
+
+// AddJob は、定期実行するジョブを登録します（Start 前に呼び出す必要があります）
+func (s *Scheduler) AddJob(name string, interval time.Duration, run JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, scheduledJob{name: name, interval: interval, run: run})
+}
+
+// Start は、登録済みの全ジョブをバックグラウンドで開始します
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+
+	s.logger.Info("Scheduler started", map[string]interface{}{
+		"job_count": len(s.jobs),
+	})
+}
+
+// loop は、ジョブを interval ごとに実行します（起動直後にも1回実行します）
+func (s *Scheduler) loop(ctx context.Context, job scheduledJob) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runJob(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runJob は、ジョブを1回実行し、失敗した場合はログに記録します
+func (s *Scheduler) runJob(ctx context.Context, job scheduledJob) {
+	if err := job.run(ctx); err != nil {
+		s.logger.Error("Scheduled job failed", err, map[string]interface{}{
+			"job": job.name,
+		})
+	}
+}
+
+// Stop は、全ジョブを停止し、実行中のジョブの完了を待機します
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.cancel()
+	s.started = false
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -7,11 +7,15 @@ import (
 	"time"
 
 	"simple-notion-backend/internal/config"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/requestid"
+	"simple-notion-backend/internal/tracing"
 )
 
 // Server は、HTTPサーバーを管理する構造体です
 type Server struct {
 	httpServer   *http.Server
+	grpcServer   *http.Server // GRPCPort が空の場合は nil
 	router       *Router
 	config       *config.Config
 	dependencies *Dependencies
@@ -38,6 +42,9 @@ func NewServer(cfg *config.Config, deps *Dependencies, metrics *Metrics, logger
 		return nil, fmt.Errorf("failed to setup HTTP server: %w", err)
 	}
 
+	// gRPCサーバーの設定
+	server.setupGRPCServer()
+
 	return server, nil
 }
 
@@ -52,6 +59,9 @@ func (s *Server) setupRouter() error {
 func (s *Server) setupHTTPServer() error {
 	handler := s.router.GetHandler(s.config)
 
+	// リクエスト ID・トレースはアクセスログ・エラーレスポンスで使うため、ルーターより外側で設定する
+	handler = middleware.RequestIDMiddleware(s.traceRequests(s.logRequests(handler)))
+
 	s.httpServer = &http.Server{
 		Addr:    ":" + s.config.Port,
 		Handler: s.metrics.HTTPMiddleware(handler),
@@ -72,8 +82,99 @@ func (s *Server) setupHTTPServer() error {
 	return nil
 }
 
-// Start は、HTTPサーバーを起動します
+// logRequests は、リクエストごとにリクエスト ID 付きのアクセスログを出力します
+// 通常はデバッグレベルで出力し、5xx の場合は本番でも残るよう警告レベルで出力します
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		wrapper := &responseWrapper{ResponseWriter: w, statusCode: 200}
+
+		next.ServeHTTP(wrapper, r)
+
+		fields := map[string]interface{}{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      wrapper.statusCode,
+			"duration_ms": time.Since(startTime).Milliseconds(),
+		}
+		logger := s.logger.WithContext(r.Context())
+		if wrapper.statusCode >= 500 {
+			logger.Warn("HTTP request failed", fields)
+		} else {
+			logger.Debug("HTTP request", fields)
+		}
+	})
+}
+
+// traceRequests は、リクエストごとにサーバーのスパンを開始します（トレーシングが無効な場合は何もしない）
+// 呼び出し元が traceparent を付けている場合はそのトレースを引き継ぐ。スパンの名前はルーティング後にルートのパターンで付け直す
+func (s *Server) traceRequests(next http.Handler) http.Handler {
+	tracer := s.dependencies.Tracer
+	if tracer == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Extract(r.Context(), r.Header)
+		ctx, span := tracer.Start(ctx, r.Method, tracing.SpanKindServer,
+			tracing.String("http.request.method", r.Method),
+			tracing.String("url.path", r.URL.Path),
+			tracing.String("request.id", requestid.FromContext(ctx)),
+		)
+		defer span.End()
+
+		wrapper := &responseWrapper{ResponseWriter: w, statusCode: 200}
+		next.ServeHTTP(wrapper, r.WithContext(ctx))
+
+		span.SetAttributes(tracing.Int("http.response.status_code", wrapper.statusCode))
+		if wrapper.statusCode >= 500 {
+			span.SetError(http.StatusText(wrapper.statusCode))
+		}
+	})
+}
+
+// setupGRPCServer は、gRPC API のサーバーを設定します
+// gRPC は HTTP/2 が必要なため、TLS なしの HTTP/2（h2c）で待ち受ける（TLS はロードバランサー等で終端する）
+func (s *Server) setupGRPCServer() {
+	if s.config.GRPCPort == "" || s.dependencies.RPCServer == nil {
+		return
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+
+	// HTTP と同じ IP アドレスごとのレート制限を適用する（認証前に拒否し、トークンの検証の負荷も抑える）
+	s.grpcServer = &http.Server{
+		Addr:      ":" + s.config.GRPCPort,
+		Handler:   s.router.rateLimitByIP(s.dependencies.RPCServer),
+		Protocols: protocols,
+
+		// タイムアウト設定（呼び出しごとの期限はクライアントの grpc-timeout に従う）
+		ReadHeaderTimeout: 5 * time.Second,
+		IdleTimeout:       5 * time.Minute,
+
+		// ログ設定
+		ErrorLog: s.logger.GetStandardLogger(),
+	}
+
+	s.logger.Info("gRPC server configured", map[string]interface{}{
+		"port": s.config.GRPCPort,
+	})
+}
+
+// Start は、HTTPサーバーを起動します（gRPCサーバーが設定されている場合は合わせて起動します）
 func (s *Server) Start() error {
+	if s.grpcServer != nil {
+		s.logger.Info("Starting gRPC server", map[string]interface{}{
+			"port": s.config.GRPCPort,
+		})
+		go func() {
+			if err := s.grpcServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				s.logger.Error("gRPC server startup failed", err)
+			}
+		}()
+	}
+
 	s.logger.Info("Starting HTTP server", map[string]interface{}{
 		"port": s.config.Port,
 	})
@@ -87,6 +188,13 @@ func (s *Server) Start() error {
 
 // Shutdown は、HTTPサーバーをグレースフルにシャットダウンします
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.grpcServer != nil {
+		s.logger.Info("Shutting down gRPC server")
+		if err := s.grpcServer.Shutdown(ctx); err != nil {
+			return fmt.Errorf("gRPC server shutdown failed: %w", err)
+		}
+	}
+
 	s.logger.Info("Shutting down HTTP server")
 
 	if err := s.httpServer.Shutdown(ctx); err != nil {
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"simple-notion-backend/internal/config"
+)
+
+// sloLatencyBuckets は、レイテンシのヒストグラムのバケット境界（秒）です
+// SLO の閾値は設定から追加される
+var sloLatencyBuckets = []float64{0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// routeGroups は、SLI を集計するルートグループとパスの接頭辞です（先に一致したものを使う）
+var routeGroups = []struct {
+	name   string
+	prefix string
+}{
+	{"auth", "/api/auth/"},
+	{"debug", "/api/admin/debug/"}, // プロファイルの取得は数十秒かかるため、管理者 API のレイテンシから外す
+	{"admin", "/api/admin/"},
+	{"uploads", "/api/upload"},
+	{"public", "/api/public/"},
+	{"documents", "/api/documents"},
+	{"health", "/api/health"},
+	{"health", "/api/status"},
+	{"health", "/healthz"},
+	{"health", "/readyz"},
+	{"api", "/api/"},
+}
+
+// routeGroup は、リクエストパスから SLI のルートグループを決めます
+// パスをそのままラベルにすると系列が増え続けるため、少数のグループにまとめる
+func routeGroup(path string) string {
+	for _, g := range routeGroups {
+		if strings.HasPrefix(path, g.prefix) {
+			return g.name
+		}
+	}
+	return "other"
+}
+
+// SLOTracker は、ルートグループごとの可用性・レイテンシの SLI を集計し、Prometheus のテキスト形式で出力します
+// 可用性は 5xx 以外の割合、レイテンシは閾値以内に応答した割合として数える
+type SLOTracker struct {
+	availabilityTarget float64
+	latencyTarget      float64
+	latencyThreshold   time.Duration
+	buckets            []float64
+
+	mu     sync.Mutex
+	groups map[string]*sloGroupStats
+}
+
+// sloGroupStats は、1つのルートグループの集計です
+type sloGroupStats struct {
+	requests     int64
+	serverErrors int64
+	slowRequests int64
+	durationSum  float64
+	bucketCounts []int64
+}
+
+// NewSLOTracker は、設定の SLO 目標で SLOTracker を作成します
+func NewSLOTracker(cfg *config.Config) *SLOTracker {
+	threshold := time.Duration(cfg.SLOLatencyThresholdMs) * time.Millisecond
+
+	buckets := append([]float64{}, sloLatencyBuckets...)
+	if seconds := threshold.Seconds(); seconds > 0 {
+		found := false
+		for _, b := range buckets {
+			if b == seconds {
+				found = true
+			}
+		}
+		if !found {
+			buckets = append(buckets, seconds)
+			sort.Float64s(buckets)
+		}
+	}
+
+	return &SLOTracker{
+		availabilityTarget: cfg.SLOAvailabilityTarget,
+		latencyTarget:      cfg.SLOLatencyTarget,
+		latencyThreshold:   threshold,
+		buckets:            buckets,
+		groups:             make(map[string]*sloGroupStats),
+	}
+}
+
+// Observe は、1リクエストの結果を記録します
+func (t *SLOTracker) Observe(path string, status int, duration time.Duration) {
+	group := routeGroup(path)
+	seconds := duration.Seconds()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.groups[group]
+	if !ok {
+		stats = &sloGroupStats{bucketCounts: make([]int64, len(t.buckets))}
+		t.groups[group] = stats
+	}
+
+	stats.requests++
+	stats.durationSum += seconds
+	for i, b := range t.buckets {
+		if seconds <= b {
+			stats.bucketCounts[i]++
+		}
+	}
+	if status >= 500 {
+		stats.serverErrors++
+	}
+	if t.latencyThreshold > 0 && duration > t.latencyThreshold {
+		stats.slowRequests++
+	}
+}
+
+// WritePrometheus は、集計結果を Prometheus のテキスト形式（0.0.4）で書き出します
+//
+// アラートルールの例（バーンレート 14.4 = 30日分のエラーバジェットの2%を1時間で消費する速さ）:
+//
+//	(sum by (route_group) (rate(simple_notion_slo_availability_bad_total[1h]))
+//	  / sum by (route_group) (rate(simple_notion_slo_requests_total[1h])))
+//	  / (1 - scalar(simple_notion_slo_availability_target)) > 14.4
+func (t *SLOTracker) WritePrometheus(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	names := make([]string, 0, len(t.groups))
+	for name := range t.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+
+	writeHeader(&sb, "simple_notion_slo_availability_target", "gauge", "Target ratio of non-5xx responses.")
+	fmt.Fprintf(&sb, "simple_notion_slo_availability_target %s\n", formatFloat(t.availabilityTarget))
+	writeHeader(&sb, "simple_notion_slo_latency_target", "gauge", "Target ratio of responses within the latency threshold.")
+	fmt.Fprintf(&sb, "simple_notion_slo_latency_target %s\n", formatFloat(t.latencyTarget))
+	writeHeader(&sb, "simple_notion_slo_latency_threshold_seconds", "gauge", "Latency threshold of the latency SLO.")
+	fmt.Fprintf(&sb, "simple_notion_slo_latency_threshold_seconds %s\n", formatFloat(t.latencyThreshold.Seconds()))
+
+	writeHeader(&sb, "simple_notion_slo_requests_total", "counter", "Requests counted towards the SLOs by route group.")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "simple_notion_slo_requests_total{route_group=%q} %d\n", name, t.groups[name].requests)
+	}
+
+	writeHeader(&sb, "simple_notion_slo_availability_bad_total", "counter", "Requests that burned availability error budget (5xx) by route group.")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "simple_notion_slo_availability_bad_total{route_group=%q} %d\n", name, t.groups[name].serverErrors)
+	}
+
+	writeHeader(&sb, "simple_notion_slo_latency_bad_total", "counter", "Requests that burned latency error budget (slower than the threshold) by route group.")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "simple_notion_slo_latency_bad_total{route_group=%q} %d\n", name, t.groups[name].slowRequests)
+	}
+
+	writeHeader(&sb, "simple_notion_slo_error_budget_remaining_ratio", "gauge", "Remaining error budget since process start (1 = untouched, negative = exhausted).")
+	for _, name := range names {
+		stats := t.groups[name]
+		fmt.Fprintf(&sb, "simple_notion_slo_error_budget_remaining_ratio{route_group=%q,slo=\"availability\"} %s\n",
+			name, formatFloat(budgetRemaining(stats.serverErrors, stats.requests, t.availabilityTarget)))
+		fmt.Fprintf(&sb, "simple_notion_slo_error_budget_remaining_ratio{route_group=%q,slo=\"latency\"} %s\n",
+			name, formatFloat(budgetRemaining(stats.slowRequests, stats.requests, t.latencyTarget)))
+	}
+
+	writeHeader(&sb, "simple_notion_http_request_duration_seconds", "histogram", "HTTP request latency by route group.")
+	for _, name := range names {
+		stats := t.groups[name]
+		for i, b := range t.buckets {
+			fmt.Fprintf(&sb, "simple_notion_http_request_duration_seconds_bucket{route_group=%q,le=%q} %d\n",
+				name, formatFloat(b), stats.bucketCounts[i])
+		}
+		fmt.Fprintf(&sb, "simple_notion_http_request_duration_seconds_bucket{route_group=%q,le=\"+Inf\"} %d\n", name, stats.requests)
+		fmt.Fprintf(&sb, "simple_notion_http_request_duration_seconds_sum{route_group=%q} %s\n", name, formatFloat(stats.durationSum))
+		fmt.Fprintf(&sb, "simple_notion_http_request_duration_seconds_count{route_group=%q} %d\n", name, stats.requests)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// budgetRemaining は、目標に対するエラーバジェットの残りの割合を返します
+func budgetRemaining(bad, total int64, target float64) float64 {
+	if bad == 0 {
+		return 1
+	}
+	if target >= 1 {
+		return 0
+	}
+	allowed := (1 - target) * float64(total)
+	return 1 - float64(bad)/allowed
+}
+
+// writeHeader は、メトリクスの HELP / TYPE 行を書き出します
+func writeHeader(sb *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// formatFloat は、Prometheus のテキスト形式で数値を表します
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+)
+
+// Version は、ビルド時に -ldflags "-X simple-notion-backend/internal/app.Version=..." で埋め込むバージョンです
+var Version = "dev"
+
+// statusCacheTTL は、公開ステータスの判定結果を使い回す期間です（認証不要のため DB への問い合わせを抑える）
+const statusCacheTTL = 10 * time.Second
+
+// statusCheckTimeout は、データベースの疎通確認のタイムアウトです
+const statusCheckTimeout = 2 * time.Second
+
+const (
+	statusOperational = "operational"
+	statusDegraded    = "degraded"
+)
+
+// StatusResponse は、公開ステータスページ向けのレスポンスです
+// 内部の構成やエラー内容は含めず、大まかな状態のみを返します
+type StatusResponse struct {
+	Status        string    `json:"status"`
+	Version       string    `json:"version"`
+	UptimeSeconds int64     `json:"uptimeSeconds"`
+	CheckedAt     time.Time `json:"checkedAt"`
+}
+
+// StatusReporter は、認証不要の公開ステータスを判定する構造体です
+type StatusReporter struct {
+	database  *sql.DB
+	metrics   *Metrics
+	startedAt time.Time
+
+	mu        sync.Mutex
+	status    string
+	checkedAt time.Time
+}
+
+// NewStatusReporter は、新しいStatusReporterインスタンスを作成します
+func NewStatusReporter(database *sql.DB, metrics *Metrics) *StatusReporter {
+	return &StatusReporter{
+		database:  database,
+		metrics:   metrics,
+		startedAt: time.Now(),
+	}
+}
+
+// ServeHTTP は、現在の状態・バージョン・稼働時間を返します
+// 状態が degraded の場合もステータスページが内容を表示できるよう 200 を返します
+func (s *StatusReporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status, checkedAt := s.check(r.Context())
+
+	w.Header().Set("Cache-Control", "no-store")
+	apierror.WriteJSON(w, http.StatusOK, StatusResponse{
+		Status:        status,
+		Version:       Version,
+		UptimeSeconds: int64(time.Since(s.startedAt).Seconds()),
+		CheckedAt:     checkedAt,
+	})
+}
+
+// check は、データベースの疎通とメトリクスから状態を判定します（結果は statusCacheTTL の間キャッシュ）
+func (s *StatusReporter) check(ctx context.Context) (string, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.checkedAt.IsZero() && time.Since(s.checkedAt) < statusCacheTTL {
+		return s.status, s.checkedAt
+	}
+
+	status := statusOperational
+	if s.database != nil {
+		pingCtx, cancel := context.WithTimeout(ctx, statusCheckTimeout)
+		defer cancel()
+		if err := s.database.PingContext(pingCtx); err != nil {
+			status = statusDegraded
+		}
+	}
+	if s.metrics != nil {
+		if healthy, _ := s.metrics.IsHealthy(); !healthy {
+			status = statusDegraded
+		}
+	}
+
+	s.status = status
+	s.checkedAt = time.Now()
+	return s.status, s.checkedAt
+}
@@ -0,0 +1,236 @@
+// Package blockschema はブロックの content を検証するための JSON Schema（サブセット）を提供する。
+// 対応キーワード: type, properties, required, additionalProperties, items, enum,
+// minLength, maxLength, format（date / date-time）, minimum, maximum, minItems, maxItems
+package blockschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema は JSON Schema のうちサポートするキーワードのみを保持する構造体です
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// supportedTypes は type キーワードに指定できる値です
+var supportedTypes = map[string]bool{
+	"":        true,
+	"object":  true,
+	"array":   true,
+	"string":  true,
+	"number":  true,
+	"integer": true,
+	"boolean": true,
+	"null":    true,
+}
+
+// supportedFormats は format キーワードに指定できる値と、対応する時刻のレイアウトです
+var supportedFormats = map[string]string{
+	"":          "",
+	"date":      "2006-01-02",
+	"date-time": time.RFC3339,
+}
+
+// ValidationError は 検証に失敗した箇所（例: "content.items[2].label"）と理由です
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Parse は JSON Schema を解析し、サポート外の型指定がないことを確認します
+func Parse(raw json.RawMessage) (*Schema, error) {
+	var schema Schema
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := schema.check("schema"); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// check は スキーマ自身の妥当性を再帰的に確認します
+func (s *Schema) check(path string) error {
+	if !supportedTypes[s.Type] {
+		return fmt.Errorf("%s: unsupported type %q", path, s.Type)
+	}
+	if _, ok := supportedFormats[s.Format]; !ok {
+		return fmt.Errorf("%s: unsupported format %q", path, s.Format)
+	}
+	for name, prop := range s.Properties {
+		if prop == nil {
+			return fmt.Errorf("%s.properties.%s: schema must be an object", path, name)
+		}
+		if err := prop.check(path + ".properties." + name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.check(path + ".items"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate は content がスキーマを満たすかを検証します
+func (s *Schema) Validate(content json.RawMessage) error {
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return &ValidationError{Path: "content", Message: "invalid JSON"}
+	}
+	return s.validate("content", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) error {
+	if s.Type != "" && !matchesType(s.Type, value) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be %s", s.Type)}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		return &ValidationError{Path: path, Message: "must be one of the allowed values"}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be at least %d characters", *s.MinLength)}
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be at most %d characters", *s.MaxLength)}
+		}
+		if layout := supportedFormats[s.Format]; layout != "" {
+			if _, err := time.Parse(layout, v); err != nil {
+				return &ValidationError{Path: path, Message: fmt.Sprintf("must be a valid %s", s.Format)}
+			}
+		}
+	case json.Number:
+		n, _ := v.Float64()
+		if s.Minimum != nil && n < *s.Minimum {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be >= %v", *s.Minimum)}
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be <= %v", *s.Maximum)}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must have at least %d items", *s.MinItems)}
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must have at most %d items", *s.MaxItems)}
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return &ValidationError{Path: path + "." + name, Message: "is required"}
+			}
+		}
+		// エラーメッセージを安定させるためキー順に検証する
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return &ValidationError{Path: path + "." + key, Message: "is not allowed"}
+				}
+				continue
+			}
+			if err := prop.validate(path+"."+key, v[key]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesType は JSON 値が type キーワードに一致するかを判定します
+func matchesType(typ string, value interface{}) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+// inEnum は 値が enum のいずれかと等しいかを判定します
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if reflect.DeepEqual(normalize(candidate), normalize(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize は 数値表現の違い（1 と 1.0）を吸収して比較できるようにします
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case float64:
+		return v
+	}
+	return value
+}
@@ -0,0 +1,90 @@
+package blockschema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{name: "正常なスキーマ", schema: `{"type":"object","properties":{"title":{"type":"string"}}}`},
+		{name: "空のスキーマは任意の値を許可", schema: `{}`},
+		{name: "不正なJSON", schema: `{"type":`, wantErr: true},
+		{name: "未対応の型", schema: `{"type":"date"}`, wantErr: true},
+		{name: "ネストした未対応の型", schema: `{"type":"array","items":{"type":"tuple"}}`, wantErr: true},
+		{name: "未対応の format", schema: `{"type":"string","format":"email"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(json.RawMessage(tt.schema))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := Parse(json.RawMessage(`{
+		"type": "object",
+		"required": ["status", "items"],
+		"additionalProperties": false,
+		"properties": {
+			"status": {"type": "string", "enum": ["open", "closed"]},
+			"priority": {"type": "integer", "minimum": 1, "maximum": 5},
+			"due": {"type": "string", "format": "date"},
+			"items": {
+				"type": "array",
+				"maxItems": 3,
+				"items": {"type": "string", "minLength": 1}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		wantPath string
+	}{
+		{name: "正常", content: `{"status":"open","priority":3,"items":["a"]}`},
+		{name: "必須項目の欠落", content: `{"status":"open"}`, wantPath: "content.items"},
+		{name: "enum 外の値", content: `{"status":"draft","items":[]}`, wantPath: "content.status"},
+		{name: "整数でない", content: `{"status":"open","priority":1.5,"items":[]}`, wantPath: "content.priority"},
+		{name: "最大値超過", content: `{"status":"open","priority":9,"items":[]}`, wantPath: "content.priority"},
+		{name: "配列要素の違反", content: `{"status":"open","items":["a",""]}`, wantPath: "content.items[1]"},
+		{name: "要素数超過", content: `{"status":"open","items":["a","b","c","d"]}`, wantPath: "content.items"},
+		{name: "未定義のプロパティ", content: `{"status":"open","items":[],"extra":true}`, wantPath: "content.extra"},
+		{name: "ルートの型違い", content: `"text"`, wantPath: "content"},
+		{name: "日付の形式", content: `{"status":"open","items":[],"due":"2026-10-15"}`},
+		{name: "日付の形式違反", content: `{"status":"open","items":[],"due":"10/15"}`, wantPath: "content.due"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(json.RawMessage(tt.content))
+			if tt.wantPath == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+			if verr.Path != tt.wantPath {
+				t.Errorf("Validate() path = %q, want %q", verr.Path, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+// Package cache は 複数のプロセスで共有できるキー・値のキャッシュを提供します
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache は 有効期限付きで文字列を保存するキャッシュです
+// 実装: MemoryCache（プロセス内のメモリ）、RedisCache（Redis、複数のプロセスで共有する）
+type Cache interface {
+	// Get は キーの値を取得します
+	// キーが存在しないか有効期限が過ぎている場合は found = false を返します
+	Get(ctx context.Context, key string) (value string, found bool, err error)
+
+	// Set は キーに値を保存します
+	// ttl: 有効期限（経過すると Get で取得できなくなる）
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+
+	// Close は キャッシュが使っている接続・ゴルーチンを解放します
+	Close() error
+}
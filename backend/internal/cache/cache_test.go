@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis は GET / SET / PING / AUTH / SELECT だけに応答するテスト用の Redis サーバーです
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	dbs     []string // 接続ごとに SELECT されたデータベース
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	f := &fakeRedis{
+		listener: listener,
+		password: password,
+		values:   make(map[string]string),
+		expires:  make(map[string]time.Time),
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authenticated := f.password == ""
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			authenticated = args[len(args)-1] == f.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "PING":
+			reply = "+PONG\r\n"
+		case cmd == "SELECT":
+			f.dbs = append(f.dbs, args[1])
+			reply = "+OK\r\n"
+		case cmd == "SET":
+			ms, _ := strconv.Atoi(args[4])
+			f.values[args[1]] = args[2]
+			f.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			reply = "+OK\r\n"
+		case cmd == "GET":
+			value, ok := f.values[args[1]]
+			if !ok || time.Now().After(f.expires[args[1]]) {
+				reply = "$-1\r\n"
+			} else {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand は RESP の配列として送られたコマンドを読みます
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// TestCache は MemoryCache と RedisCache の保存・取得・有効期限のテストです
+func TestCache(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	redisCache, err := NewRedisCache("redis://:secret@" + server.listener.Addr().String() + "/2")
+	if err != nil {
+		t.Fatalf("NewRedisCache() error = %v", err)
+	}
+
+	caches := map[string]Cache{
+		"MemoryCache": NewMemoryCache(),
+		"RedisCache":  redisCache,
+	}
+
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			defer c.Close()
+			ctx := context.Background()
+
+			if err := c.Set(ctx, "presigned-url:a.png", "https://example.com/a.png?sig=1", time.Hour); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if err := c.Set(ctx, "presigned-url:expired.png", "https://example.com/expired.png", -time.Second); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			value, found, err := c.Get(ctx, "presigned-url:a.png")
+			if err != nil || !found || value != "https://example.com/a.png?sig=1" {
+				t.Errorf("Get() = %q, %v, %v, want cached URL", value, found, err)
+			}
+			if _, found, err := c.Get(ctx, "presigned-url:expired.png"); err != nil || found {
+				t.Errorf("Get() for expired key found = %v, err = %v, want not found", found, err)
+			}
+			if _, found, err := c.Get(ctx, "presigned-url:missing.png"); err != nil || found {
+				t.Errorf("Get() for missing key found = %v, err = %v, want not found", found, err)
+			}
+		})
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.dbs) == 0 || server.dbs[0] != "2" {
+		t.Errorf("selected databases = %v, want [2]", server.dbs)
+	}
+}
+
+// TestNewRedisCache_Errors は 接続できない・認証に失敗する場合のテストです
+func TestNewRedisCache_Errors(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	addr := server.listener.Addr().String()
+
+	tests := []struct {
+		name     string
+		redisURL string
+	}{
+		{name: "異常系：スキームが redis ではない", redisURL: "http://" + addr},
+		{name: "異常系：データベースが数値ではない", redisURL: "redis://" + addr + "/cache"},
+		{name: "異常系：パスワードが違う", redisURL: "redis://:wrong@" + addr},
+		{name: "異常系：パスワードなし", redisURL: "redis://" + addr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := NewRedisCache(tt.redisURL); err == nil {
+				c.Close()
+				t.Errorf("NewRedisCache(%q) error = nil, want error", tt.redisURL)
+			}
+		})
+	}
+}
+
+// TestRedisCache_Reconnect は 接続が切れた後のコマンドで接続し直すテストです
+func TestRedisCache_Reconnect(t *testing.T) {
+	server := newFakeRedis(t, "")
+	c, err := NewRedisCache("redis://" + server.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("NewRedisCache() error = %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	if err := c.Set(ctx, "k", "v", time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// サーバー側で接続が切れた状態を作る
+	c.mu.Lock()
+	c.conn.Close()
+	c.mu.Unlock()
+
+	// 切れた接続でのコマンドは失敗し、次のコマンドで接続し直す
+	if _, _, err := c.Get(ctx, "k"); err == nil {
+		t.Fatal("Get() on closed connection error = nil, want error")
+	}
+	if value, found, err := c.Get(ctx, "k"); err != nil || !found || value != "v" {
+		t.Errorf("Get() after reconnect = %q, %v, %v, want v", value, found, err)
+	}
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryCleanupInterval は 期限切れのエントリを削除する間隔です
+const memoryCleanupInterval = time.Hour
+
+// MemoryCache は プロセス内のメモリに保存するキャッシュです
+// 再起動すると失われ、複数のプロセスの間では共有されません
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+	done    chan struct{}
+	once    sync.Once
+}
+
+type memoryEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// コンパイル時にCacheインターフェースを満たすことを確認
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache は 新しい MemoryCache インスタンスを作成します
+// 期限切れのエントリを定期的に削除するゴルーチンを起動します（Close で停止する）
+func NewMemoryCache() *MemoryCache {
+	c := &MemoryCache{
+		entries: make(map[string]memoryEntry),
+		done:    make(chan struct{}),
+	}
+	go c.cleanupExpired()
+	return c
+}
+
+// Get は キーの値を取得します
+func (c *MemoryCache) Get(ctx context.Context, key string) (string, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.entries[key]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return "", false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set は キーに値を保存します
+func (c *MemoryCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = memoryEntry{value: value, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Close は 期限切れのエントリを削除するゴルーチンを停止します
+func (c *MemoryCache) Close() error {
+	c.once.Do(func() { close(c.done) })
+	return nil
+}
+
+// cleanupExpired は 期限切れのエントリを定期的に削除します
+func (c *MemoryCache) cleanupExpired() {
+	ticker := time.NewTicker(memoryCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		now := time.Now()
+		for key, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redisTimeout は Redis への接続と1つのコマンドの応答を待つ時間です（ctx に期限がない場合）
+const redisTimeout = 2 * time.Second
+
+// RedisCache は Redis に保存するキャッシュです
+// 再起動しても失われず、同じ Redis を使う複数のプロセスで共有されます
+// 1つの接続をコマンドごとに順番に使い、接続に失敗した場合は次のコマンドで接続し直す
+type RedisCache struct {
+	addr     string
+	username string
+	password string
+	db       int
+
+	mu   sync.Mutex
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// コンパイル時にCacheインターフェースを満たすことを確認
+var _ Cache = (*RedisCache)(nil)
+
+// redisError は Redis が返したエラー応答です
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// NewRedisCache は 新しい RedisCache インスタンスを作成します
+// redisURL: redis://[[username]:password@]host[:port][/db] 形式（ポートの既定値は 6379）
+// 作成時に接続して PING が成功することを確認します
+func NewRedisCache(redisURL string) (*RedisCache, error) {
+	u, err := url.Parse(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("invalid redis url: unsupported scheme %q", u.Scheme)
+	}
+
+	c := &RedisCache{addr: u.Host}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis url: database %q is not a number", db)
+		}
+	}
+
+	if _, err := c.do(context.Background(), "PING"); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return c, nil
+}
+
+// Get は キーの値を取得します
+func (c *RedisCache) Get(ctx context.Context, key string) (string, bool, error) {
+	reply, err := c.do(ctx, "GET", key)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	if reply == nil {
+		return "", false, nil
+	}
+	return *reply, true, nil
+}
+
+// Set は キーに値を保存します（有効期限はミリ秒単位で Redis に設定する）
+func (c *RedisCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if _, err := c.do(ctx, "SET", key, value, "PX", strconv.FormatInt(ttl.Milliseconds(), 10)); err != nil {
+		return fmt.Errorf("failed to set %s: %w", key, err)
+	}
+	return nil
+}
+
+// Eval は Lua スクリプトを Redis 上で実行し、文字列・整数の結果を返します（複数のコマンドを不可分に実行する用途）
+// スクリプトが nil を返した場合は空文字列を返します
+func (c *RedisCache) Eval(ctx context.Context, script string, keys []string, args ...string) (string, error) {
+	cmd := make([]string, 0, 3+len(keys)+len(args))
+	cmd = append(cmd, "EVAL", script, strconv.Itoa(len(keys)))
+	cmd = append(cmd, keys...)
+	cmd = append(cmd, args...)
+
+	reply, err := c.do(ctx, cmd...)
+	if err != nil {
+		return "", fmt.Errorf("failed to eval script: %w", err)
+	}
+	if reply == nil {
+		return "", nil
+	}
+	return *reply, nil
+}
+
+// Close は Redis との接続を閉じます
+func (c *RedisCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closeLocked()
+}
+
+// do は コマンドを送って応答を返します（nil の応答は nil）
+func (c *RedisCache) do(ctx context.Context, args ...string) (*string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connectLocked(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := c.roundTripLocked(ctx, args)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// 応答の途中で失敗した接続は使い続けられないため、次のコマンドで接続し直す
+		c.closeLocked()
+	}
+	return reply, err
+}
+
+// connectLocked は Redis に接続し、認証とデータベースの選択を行います（c.mu を保持した状態で呼び出す）
+func (c *RedisCache) connectLocked(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: redisTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.rw = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	var setup [][]string
+	switch {
+	case c.username != "":
+		setup = append(setup, []string{"AUTH", c.username, c.password})
+	case c.password != "":
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	for _, args := range setup {
+		if _, err := c.roundTripLocked(ctx, args); err != nil {
+			c.closeLocked()
+			return fmt.Errorf("%s failed: %w", args[0], err)
+		}
+	}
+
+	return nil
+}
+
+// closeLocked は 接続を閉じます（c.mu を保持した状態で呼び出す）
+func (c *RedisCache) closeLocked() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.rw = nil, nil
+	return err
+}
+
+// roundTripLocked は RESP の配列としてコマンドを送り、応答を1つ読みます（c.mu を保持した状態で呼び出す）
+func (c *RedisCache) roundTripLocked(ctx context.Context, args []string) (*string, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(c.rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := c.rw.Flush(); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.rw.Reader)
+}
+
+// readReply は RESP の応答を1つ読みます（文字列・整数・エラーのみ対応し、配列は使わない）
+func readReply(r *bufio.Reader) (*string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		value := line[1:]
+		return &value, nil
+	case '-':
+		return nil, redisError(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		value := string(buf[:n])
+		return &value, nil
+	default:
+		return nil, fmt.Errorf("redis: unsupported reply %q", line)
+	}
+}
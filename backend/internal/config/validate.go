@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// minJWTSecretLength は 本番環境で求める JWT_SECRET の最小の長さです
+const minJWTSecretLength = 32
+
+// placeholderJWTSecrets は 既定値・サンプルの設定ファイルの JWT_SECRET です（本番環境では使えない）
+var placeholderJWTSecrets = []string{
+	"your-secret-key-change-in-production",
+	"CHANGE_THIS_TO_A_STRONG_RANDOM_KEY_IN_PRODUCTION",
+}
+
+// placeholderPasswords は 既定値・サンプルの設定ファイルのデータベースとストレージのパスワードです
+var placeholderPasswords = []string{"password", "postgres", "minioadmin"}
+
+// ValidationError は 設定の誤りの一覧です
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "設定に誤りがあります:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate は 設定が欠けている・値が不正・環境に対して安全でない項目をまとめて検証します
+// Load は不正な値を既定値で補うため、起動時に呼び出して誤った設定のまま動き始めないようにする
+// 問題がある場合は全ての項目を列挙した *ValidationError を返す
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	production := c.Environment == "production"
+	if c.Environment != "development" && !production {
+		add("ENVIRONMENT は development か production を指定してください（現在の値: %q）", c.Environment)
+	}
+
+	if !validPort(c.Port) {
+		add("PORT は1〜65535のポート番号を指定してください（現在の値: %q）", c.Port)
+	}
+	if c.GRPCPort != "" && !validPort(c.GRPCPort) {
+		add("GRPC_PORT は1〜65535のポート番号を指定してください（現在の値: %q）", c.GRPCPort)
+	}
+
+	if c.DatabaseURL == "" {
+		add("DATABASE_URL を指定してください")
+	}
+	if c.JWTSecret == "" {
+		add("JWT_SECRET を指定してください")
+	}
+
+	// Cookie
+	switch c.CookieSameSite {
+	case "strict", "lax":
+	case "none":
+		if !c.CookieSecure {
+			add("COOKIE_SAMESITE=none には COOKIE_SECURE=true が必要です（Secure のない SameSite=None の Cookie はブラウザが受け付けない）")
+		}
+	default:
+		add("COOKIE_SAMESITE は strict・lax・none のいずれかを指定してください（現在の値: %q）", c.CookieSameSite)
+	}
+
+	// ファイルの保存先
+	switch c.StorageBackend {
+	case "s3", "local":
+	case "gcs":
+		if c.GCSAccessID == "" || c.GCSSecret == "" {
+			add("STORAGE_BACKEND=gcs には GCS_HMAC_ACCESS_ID と GCS_HMAC_SECRET が必要です")
+		}
+	case "azure":
+		if c.AzureStorageAccount == "" || c.AzureStorageKey == "" {
+			add("STORAGE_BACKEND=azure には AZURE_STORAGE_ACCOUNT と AZURE_STORAGE_KEY が必要です")
+		}
+	default:
+		add("STORAGE_BACKEND は s3・gcs・azure・local のいずれかを指定してください（現在の値: %q）", c.StorageBackend)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.ContentPolicyMode)) {
+	case "off", "flag", "block":
+	default:
+		add("CONTENT_POLICY_MODE は off・flag・block のいずれかを指定してください（現在の値: %q）", c.ContentPolicyMode)
+	}
+
+	if c.SMTPHost != "" {
+		switch c.SMTPTLSMode {
+		case "starttls", "tls", "none":
+		default:
+			add("SMTP_TLS_MODE は starttls・tls・none のいずれかを指定してください（現在の値: %q）", c.SMTPTLSMode)
+		}
+	}
+
+	if production {
+		problems = append(problems, c.productionProblems()...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// productionProblems は 開発用の既定値のまま本番環境で動かすと安全でない項目を返します
+func (c *Config) productionProblems() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	for _, placeholder := range placeholderJWTSecrets {
+		if c.JWTSecret == placeholder {
+			add("JWT_SECRET がサンプルの値のままです（make generate-jwt-secret で生成してください）")
+		}
+	}
+	if c.JWTSecret != "" && len(c.JWTSecret) < minJWTSecretLength {
+		add("本番環境の JWT_SECRET は%d文字以上にしてください（現在: %d文字）", minJWTSecretLength, len(c.JWTSecret))
+	}
+
+	if password, ok := databasePassword(c.DatabaseURL); ok && isPlaceholderPassword(password) {
+		add("DATABASE_URL のパスワードが既定値のままです（POSTGRES_PASSWORD に推測されにくい値を設定してください）")
+	}
+
+	if c.StorageBackend == "s3" && (isPlaceholderPassword(c.S3AccessKey) || isPlaceholderPassword(c.S3SecretKey)) {
+		add("本番環境では S3_ACCESS_KEY・S3_SECRET_KEY に MinIO の既定の認証情報を使えません")
+	}
+
+	if !c.CookieSecure {
+		add("本番環境では COOKIE_SECURE=true にしてください（認証の Cookie が暗号化されない HTTP で送られる）")
+	}
+	if c.WebhookAllowPrivateNetworks {
+		add("本番環境では WEBHOOK_ALLOW_PRIVATE_NETWORKS=false にしてください（Webhook から内部のサービスに接続できてしまう）")
+	}
+	if c.SMTPHost != "" && c.SMTPTLSMode == "none" {
+		add("本番環境では SMTP_TLS_MODE=none を使えません（認証情報とメールが暗号化されずに送られる）")
+	}
+	if c.PprofEnabled {
+		add("本番環境では PPROF_ENABLED=false にしてください（プロファイルからメモリ上の内容や内部の構成が読み取れる）")
+	}
+
+	return problems
+}
+
+// validPort は value が 1〜65535 のポート番号かどうかを返します
+func validPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port >= 1 && port <= 65535
+}
+
+// databasePassword は URL 形式の DATABASE_URL に含まれるパスワードを返します（キーと値の形式の場合は false）
+func databasePassword(databaseURL string) (string, bool) {
+	u, err := url.Parse(databaseURL)
+	if err != nil || u.User == nil {
+		return "", false
+	}
+	return u.User.Password()
+}
+
+// isPlaceholderPassword は value が既定値・サンプルの設定ファイルのパスワードかどうかを返します
+func isPlaceholderPassword(value string) bool {
+	for _, placeholder := range placeholderPasswords {
+		if value == placeholder {
+			return true
+		}
+	}
+	return false
+}
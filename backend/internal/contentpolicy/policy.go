@@ -0,0 +1,113 @@
+// Package contentpolicy は文書保存時に本文をチェックするコンテンツポリシーを提供する。
+// - Checker: 1つのルールセット（正規表現、禁止語、外部サービスなど）
+// - Pipeline: 複数の Checker を順に実行し、設定されたモードに応じて block / flag を決定する
+package contentpolicy
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// Mode は ポリシー違反時の動作です
+type Mode string
+
+const (
+	ModeOff   Mode = "off"   // チェックしない
+	ModeFlag  Mode = "flag"  // 保存は許可し、警告を返す
+	ModeBlock Mode = "block" // 保存を拒否する
+)
+
+// ParseMode は 設定値から Mode を解析します（不明な値は off として扱う）
+func ParseMode(value string) Mode {
+	switch Mode(strings.ToLower(strings.TrimSpace(value))) {
+	case ModeFlag:
+		return ModeFlag
+	case ModeBlock:
+		return ModeBlock
+	default:
+		return ModeOff
+	}
+}
+
+// Segment は チェック対象のテキスト片です
+// Location は違反箇所をクライアントに伝えるための識別子（例: "title", "block:3"）
+type Segment struct {
+	Location string
+	Text     string
+}
+
+// Violation は 検出されたポリシー違反です
+type Violation struct {
+	Rule     string `json:"rule"`
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+// Checker は 1種類のポリシーチェックを表すインターフェースです
+type Checker interface {
+	// Name は ログやエラーメッセージに使うチェッカー名を返します
+	Name() string
+	// Check は テキスト片を検査し、違反があれば返します
+	Check(ctx context.Context, segments []Segment) ([]Violation, error)
+}
+
+// Result は パイプライン実行結果です
+type Result struct {
+	Blocked    bool
+	Violations []Violation
+}
+
+// Pipeline は 登録された Checker を順に実行するコンテンツポリシーです
+type Pipeline struct {
+	mode     Mode
+	checkers []Checker
+}
+
+// NewPipeline は 新しい Pipeline を作成します
+func NewPipeline(mode Mode, checkers ...Checker) *Pipeline {
+	return &Pipeline{
+		mode:     mode,
+		checkers: checkers,
+	}
+}
+
+// Enabled は ポリシーチェックが有効かどうかを返します
+func (p *Pipeline) Enabled() bool {
+	return p != nil && p.mode != ModeOff && len(p.checkers) > 0
+}
+
+// Evaluate は 全ての Checker を実行して結果をまとめます
+// Checker 自体のエラー（外部サービス障害など）は保存を妨げないようログのみ残して続行する
+func (p *Pipeline) Evaluate(ctx context.Context, segments []Segment) *Result {
+	result := &Result{Violations: make([]Violation, 0)}
+	if !p.Enabled() {
+		return result
+	}
+
+	for _, checker := range p.checkers {
+		violations, err := checker.Check(ctx, segments)
+		if err != nil {
+			log.Printf("content policy checker %s failed: %v", checker.Name(), err)
+			continue
+		}
+		result.Violations = append(result.Violations, violations...)
+	}
+
+	result.Blocked = p.mode == ModeBlock && len(result.Violations) > 0
+	return result
+}
+
+// Summary は 違反ルール名を重複なしで連結した文字列を返します（エラーメッセージ用）
+func (r *Result) Summary() string {
+	seen := make(map[string]bool)
+	rules := make([]string, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		if seen[v.Rule] {
+			continue
+		}
+		seen[v.Rule] = true
+		rules = append(rules, v.Rule)
+	}
+	return strings.Join(rules, ", ")
+}
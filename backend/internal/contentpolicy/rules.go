@@ -0,0 +1,130 @@
+package contentpolicy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Rule は 名前付きの正規表現ルールです
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// PatternChecker は 正規表現ルールでテキストを検査する Checker です
+type PatternChecker struct {
+	rules []Rule
+}
+
+// NewPatternChecker は 新しい PatternChecker を作成します
+func NewPatternChecker(rules []Rule) *PatternChecker {
+	return &PatternChecker{rules: rules}
+}
+
+// Name は チェッカー名を返します
+func (c *PatternChecker) Name() string {
+	return "pattern"
+}
+
+// Check は 各テキスト片に対して全ルールを適用します
+func (c *PatternChecker) Check(ctx context.Context, segments []Segment) ([]Violation, error) {
+	violations := make([]Violation, 0)
+	for _, seg := range segments {
+		for _, rule := range c.rules {
+			if rule.Pattern.MatchString(seg.Text) {
+				violations = append(violations, Violation{
+					Rule:     rule.Name,
+					Location: seg.Location,
+					Message:  fmt.Sprintf("ルール %s に一致する内容が含まれています", rule.Name),
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// ruleNamePattern - ルール名として許可する文字
+var ruleNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// LoadRulesFile は ルールファイルを読み込みます
+// 1行1ルールで "名前=正規表現" の形式。空行と # で始まる行は無視する
+func LoadRulesFile(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open content policy rules file: %w", err)
+	}
+	defer f.Close()
+
+	rules := make([]Rule, 0)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, expr, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !ruleNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid content policy rule at line %d: expected name=regex", lineNo)
+		}
+
+		pattern, err := regexp.Compile(strings.TrimSpace(expr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid content policy rule at line %d: %w", lineNo, err)
+		}
+		rules = append(rules, Rule{Name: name, Pattern: pattern})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read content policy rules file: %w", err)
+	}
+
+	return rules, nil
+}
+
+// DenyListChecker は 禁止語（大文字小文字を区別しない部分一致）で検査する Checker です
+type DenyListChecker struct {
+	words []string
+}
+
+// NewDenyListChecker は 新しい DenyListChecker を作成します
+func NewDenyListChecker(words []string) *DenyListChecker {
+	normalized := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" {
+			normalized = append(normalized, w)
+		}
+	}
+	return &DenyListChecker{words: normalized}
+}
+
+// Name は チェッカー名を返します
+func (c *DenyListChecker) Name() string {
+	return "deny-list"
+}
+
+// Check は 各テキスト片に禁止語が含まれていないか検査します
+func (c *DenyListChecker) Check(ctx context.Context, segments []Segment) ([]Violation, error) {
+	violations := make([]Violation, 0)
+	for _, seg := range segments {
+		lower := strings.ToLower(seg.Text)
+		for _, w := range c.words {
+			if strings.Contains(lower, w) {
+				violations = append(violations, Violation{
+					Rule:     "deny-list",
+					Location: seg.Location,
+					Message:  "禁止語が含まれています",
+				})
+				break
+			}
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,51 @@
+package contentpolicy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// secretRules - 代表的な認証情報の形式（誤検知より見逃しを減らすことを優先）
+var secretRules = []Rule{
+	{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "aws-secret-key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?access_?key\s*[:=]\s*\S{40}`)},
+	{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[baprs]-[0-9A-Za-z-]{10,}`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+}
+
+// SecretsChecker は 認証情報（APIキー・秘密鍵・トークン）らしき文字列を検出する Checker です
+// ポリシーのモードに関係なく警告として扱うため、Pipeline とは別に直接呼び出す
+type SecretsChecker struct {
+	rules []Rule
+}
+
+// NewSecretsChecker は 組み込みの検出ルールを持つ SecretsChecker を作成します
+func NewSecretsChecker() *SecretsChecker {
+	return &SecretsChecker{rules: secretRules}
+}
+
+// Name は チェッカー名を返します
+func (c *SecretsChecker) Name() string {
+	return "secrets"
+}
+
+// Check は 各テキスト片に認証情報らしき文字列が含まれていないか検査します
+// 検出した値そのものは Violation に含めない（レスポンスやログへの再露出を避ける）
+func (c *SecretsChecker) Check(ctx context.Context, segments []Segment) ([]Violation, error) {
+	violations := make([]Violation, 0)
+	for _, seg := range segments {
+		for _, rule := range c.rules {
+			if rule.Pattern.MatchString(seg.Text) {
+				violations = append(violations, Violation{
+					Rule:     rule.Name,
+					Location: seg.Location,
+					Message:  fmt.Sprintf("秘密情報（%s）の可能性がある文字列が含まれています", rule.Name),
+				})
+			}
+		}
+	}
+	return violations, nil
+}
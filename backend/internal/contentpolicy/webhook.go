@@ -0,0 +1,81 @@
+package contentpolicy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookChecker は 外部サービスにテキストを送信して判定を委ねる Checker です
+//
+// リクエスト: POST {"segments":[{"location":"title","text":"..."}]}
+// レスポンス: {"violations":[{"rule":"...","location":"...","message":"..."}]}
+type WebhookChecker struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookChecker は 新しい WebhookChecker を作成します
+func NewWebhookChecker(url string, timeout time.Duration) *WebhookChecker {
+	return &WebhookChecker{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name は チェッカー名を返します
+func (c *WebhookChecker) Name() string {
+	return "webhook"
+}
+
+type webhookSegment struct {
+	Location string `json:"location"`
+	Text     string `json:"text"`
+}
+
+type webhookRequest struct {
+	Segments []webhookSegment `json:"segments"`
+}
+
+type webhookResponse struct {
+	Violations []Violation `json:"violations"`
+}
+
+// Check は 外部サービスに問い合わせて違反一覧を取得します
+func (c *WebhookChecker) Check(ctx context.Context, segments []Segment) ([]Violation, error) {
+	payload := webhookRequest{Segments: make([]webhookSegment, 0, len(segments))}
+	for _, seg := range segments {
+		payload.Segments = append(payload.Segments, webhookSegment{Location: seg.Location, Text: seg.Text})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("content policy webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("content policy webhook returned status %d", resp.StatusCode)
+	}
+
+	var result webhookResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook response: %w", err)
+	}
+
+	return result.Violations, nil
+}
@@ -0,0 +1,132 @@
+// Package events は サービスが発行するアプリケーションのイベントを購読者に配るイベントバスを提供します
+//
+// サービスは文書の作成・ファイルのアップロードなどの操作が完了した後に Publish し、
+// Webhook の配信などの購読者は app 層で Subscribe する（サービスは購読者を知らない）。
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type は イベントの種類です
+type Type string
+
+const (
+	DocumentCreated Type = "document.created"
+	DocumentUpdated Type = "document.updated" // タイトル・本文・ブロックの変更
+	DocumentDeleted Type = "document.deleted" // ごみ箱への移動と完全削除（DocumentData.Permanent で区別する）
+	FileUploaded    Type = "file.uploaded"
+	UserLogin       Type = "user.login"
+)
+
+// Types は 購読できるイベントの種類の一覧です
+var Types = []Type{DocumentCreated, DocumentUpdated, DocumentDeleted, FileUploaded, UserLogin}
+
+// Valid は 既知のイベントの種類かを返します
+func (t Type) Valid() bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event は 発行されたイベントです
+type Event struct {
+	ID         string      `json:"id"`
+	Type       Type        `json:"type"`
+	UserID     int         `json:"-"` // イベントを受け取るユーザー（文書の所有者・アップロードしたユーザー・ログインしたユーザー）
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// DocumentData は document.* のイベントの内容です
+type DocumentData struct {
+	DocumentID  int    `json:"documentId"`
+	Title       string `json:"title"`
+	ParentID    *int   `json:"parentId,omitempty"`
+	WorkspaceID *int   `json:"workspaceId,omitempty"`
+	ActorID     int    `json:"actorId"` // 操作したユーザー（共同編集者の場合は所有者と異なる）
+	Permanent   bool   `json:"permanent,omitempty"`
+}
+
+// FileData は file.uploaded のイベントの内容です
+type FileData struct {
+	FileID       int    `json:"fileId"`
+	OriginalName string `json:"originalName"`
+	MimeType     string `json:"mimeType"`
+	FileType     string `json:"fileType"`
+	FileSize     int64  `json:"fileSize"`
+}
+
+// LoginData は user.login のイベントの内容です
+type LoginData struct {
+	UserID    int    `json:"userId"`
+	Email     string `json:"email"`
+	IPAddress string `json:"ipAddress,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+}
+
+// Handler は イベントの購読者です
+// Publish を呼び出したゴルーチンでそのまま呼ばれるため、時間のかかる処理は記録だけ行い後で実行する
+type Handler func(ctx context.Context, event Event)
+
+// Bus は プロセス内のイベントバスです（nil の場合は Publish しても何もしない）
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus は 新しい Bus を作成します
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe は すべての種類のイベントを受け取る購読者を登録します
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish は イベントに ID と発生日時を付けて、登録されたすべての購読者に渡します
+// 購読者の panic は記録して他の購読者への配布と発行元の処理を続ける
+func (b *Bus) Publish(ctx context.Context, eventType Type, userID int, data interface{}) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	event := Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		UserID:     userID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+	for _, handler := range handlers {
+		dispatch(ctx, handler, event)
+	}
+}
+
+// dispatch は 1つの購読者にイベントを渡します
+func dispatch(ctx context.Context, handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("event handler panicked on %s %s: %v", event.Type, event.ID, r)
+		}
+	}()
+	handler(ctx, event)
+}
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBusPublish(t *testing.T) {
+	bus := NewBus()
+
+	var received []Event
+	bus.Subscribe(func(ctx context.Context, event Event) {
+		panic("broken subscriber")
+	})
+	bus.Subscribe(func(ctx context.Context, event Event) {
+		received = append(received, event)
+	})
+
+	bus.Publish(context.Background(), DocumentCreated, 7, DocumentData{DocumentID: 3, Title: "議事録", ActorID: 7})
+
+	if len(received) != 1 {
+		t.Fatalf("received %d events, want 1 (a panicking subscriber must not stop the others)", len(received))
+	}
+	event := received[0]
+	if event.ID == "" || event.OccurredAt.IsZero() {
+		t.Errorf("event = %+v, want generated ID and OccurredAt", event)
+	}
+	if event.Type != DocumentCreated || event.UserID != 7 {
+		t.Errorf("event = %+v, want document.created for user 7", event)
+	}
+	if data, ok := event.Data.(DocumentData); !ok || data.DocumentID != 3 {
+		t.Errorf("data = %#v, want DocumentData for document 3", event.Data)
+	}
+}
+
+func TestBusPublishNil(t *testing.T) {
+	var bus *Bus
+	// 購読者のいない環境（テスト・Webhook を使わない構成）ではサービスに nil を渡す
+	bus.Publish(context.Background(), UserLogin, 1, nil)
+}
+
+func TestTypeValid(t *testing.T) {
+	tests := []struct {
+		eventType Type
+		want      bool
+	}{
+		{eventType: DocumentCreated, want: true},
+		{eventType: FileUploaded, want: true},
+		{eventType: UserLogin, want: true},
+		{eventType: "document.moved", want: false},
+		{eventType: "", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.eventType.Valid(); got != tt.want {
+			t.Errorf("Type(%q).Valid() = %v, want %v", tt.eventType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,547 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"simple-notion-backend/internal/apierror"
+)
+
+// Request は GraphQL のリクエストの本文です
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response は GraphQL のレスポンスの本文です
+// クエリの解析・検証に失敗した場合は data を含めず、フィールドの取得に失敗した場合はそのフィールドを null にして errors に加える
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error は GraphQL のエラーです（extensions.code は統一エラーレスポンスの error と同じコード）
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// requestError は クエリの解析・検証のエラーを返します
+func requestError(code string, err error) *Response {
+	return &Response{Errors: []*Error{{
+		Message:    err.Error(),
+		Extensions: map[string]interface{}{"code": code},
+	}}}
+}
+
+// Execute は スキーマに対してクエリを実行します
+func Execute(ctx context.Context, schema *Schema, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return requestError("GRAPHQL_PARSE_FAILED", err)
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return requestError("GRAPHQL_VALIDATION_FAILED", err)
+	}
+	if op.kind != "query" {
+		return requestError("GRAPHQL_VALIDATION_FAILED", fmt.Errorf("%s operations are not supported", op.kind))
+	}
+
+	variables := make(map[string]interface{}, len(op.variables))
+	for _, def := range op.variables {
+		if value, ok := req.Variables[def.name]; ok {
+			variables[def.name] = value
+		} else if def.hasDefault {
+			variables[def.name] = def.defaultValue
+		}
+	}
+
+	if err := checkFragmentCycles(doc.fragments); err != nil {
+		return requestError("GRAPHQL_VALIDATION_FAILED", err)
+	}
+
+	e := &executor{
+		schema:    schema,
+		variables: variables,
+		fragments: doc.fragments,
+		args:      make(map[*selection]map[string]interface{}),
+	}
+	set, err := e.validate(schema.Query, op.selectionSet, 1, 1)
+	if err != nil {
+		return requestError("GRAPHQL_VALIDATION_FAILED", err)
+	}
+
+	data := e.executeObjects(ctx, schema.Query, []interface{}{nil}, set, [][]interface{}{nil})[0]
+	return &Response{Data: data, Errors: e.errors}
+}
+
+// selectOperation は 実行する操作を選びます（複数の操作を含む場合は operationName が必要）
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the query contains multiple operations")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// checkFragmentCycles は フラグメントが自身を（他のフラグメントを経由して）展開していないことを確認します
+func checkFragmentCycles(fragments map[string]*fragmentDefinition) error {
+	names := make([]string, 0, len(fragments))
+	for name := range fragments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const visiting, done = 1, 2
+	state := make(map[string]int, len(fragments))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("fragment %q must not spread itself", name)
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		if def, ok := fragments[name]; ok {
+			for _, spread := range fragmentSpreads(def.selectionSet, nil) {
+				if err := visit(spread); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = done
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fragmentSpreads は 選択（入れ子のフィールド・インラインフラグメントを含む）で展開するフラグメントの名前を返します
+func fragmentSpreads(set []*selection, names []string) []string {
+	for _, sel := range set {
+		if sel.spread != "" {
+			names = append(names, sel.spread)
+		}
+		names = fragmentSpreads(sel.selectionSet, names)
+	}
+	return names
+}
+
+// executor は 1回のクエリの実行状態です
+type executor struct {
+	schema     *Schema
+	variables  map[string]interface{}
+	fragments  map[string]*fragmentDefinition
+	args       map[*selection]map[string]interface{} // 検証時に変換した引数
+	complexity int                                   // 検証時に数えたクエリの複雑さ
+	errors     []*Error
+}
+
+// validate は 選択したフィールドがスキーマに存在し、引数が型に合うことを確認します
+// フラグメントを展開した選択したフィールドの一覧を返す（入れ子のフィールドの選択も展開したものに置き換える）
+// multiplier は 親のリストのフィールドから見積もった、このフィールドを取得する回数です
+func (e *executor) validate(obj *Object, set []*selection, depth, multiplier int) ([]*selection, error) {
+	if e.schema.MaxDepth > 0 && depth > e.schema.MaxDepth {
+		return nil, fmt.Errorf("query exceeds maximum depth of %d", e.schema.MaxDepth)
+	}
+
+	fields, err := e.collectFields(obj, set)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sel := range fields {
+		if sel.name == "__typename" {
+			if sel.selectionSet != nil {
+				return nil, fmt.Errorf("field %q must not have a selection", sel.name)
+			}
+			continue
+		}
+
+		field, ok := obj.Fields[sel.name]
+		if !ok {
+			return nil, fmt.Errorf("cannot query field %q on type %q", sel.name, obj.Name)
+		}
+		e.complexity += multiplier
+		if limit := e.schema.MaxComplexity; limit > 0 && e.complexity > limit {
+			return nil, fmt.Errorf("query exceeds maximum complexity of %d", limit)
+		}
+		args, err := e.coerceArguments(field, sel)
+		if err != nil {
+			return nil, err
+		}
+		e.args[sel] = args
+
+		named, childMultiplier := field.Type, multiplier
+		for {
+			list, ok := named.(*List)
+			if !ok {
+				break
+			}
+			named = list.Of
+			childMultiplier = e.multiplyComplexity(childMultiplier)
+		}
+		switch t := named.(type) {
+		case *Object:
+			if sel.selectionSet == nil {
+				return nil, fmt.Errorf("field %q of type %q must have a selection of subfields", sel.name, field.Type)
+			}
+			if sel.selectionSet, err = e.validate(t, sel.selectionSet, depth+1, childMultiplier); err != nil {
+				return nil, err
+			}
+		default:
+			if sel.selectionSet != nil {
+				return nil, fmt.Errorf("field %q must not have a selection since type %q has no subfields", sel.name, field.Type)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// multiplyComplexity は リストの要素のフィールドを取得する回数を見積もります（上限を超える値は上限 + 1 に丸める）
+func (e *executor) multiplyComplexity(multiplier int) int {
+	if e.schema.ListFactor > 1 {
+		multiplier *= e.schema.ListFactor
+	}
+	if limit := e.schema.MaxComplexity; limit > 0 && multiplier > limit {
+		multiplier = limit + 1
+	}
+	return multiplier
+}
+
+// collectFields は フラグメントを展開し、同じエイリアスのフィールドを1つにまとめた一覧を返します
+// 同じエイリアスのフィールドは、名前と引数が同じ場合に限り、選択したサブフィールドを合わせる（元の選択は変更しない）
+func (e *executor) collectFields(obj *Object, set []*selection) ([]*selection, error) {
+	var fields []*selection
+	byAlias := make(map[string]*selection)
+	visited := make(map[string]bool) // 同じ選択の中で展開済みのフラグメント
+
+	var collect func(set []*selection) error
+	collect = func(set []*selection) error {
+		for _, sel := range set {
+			switch {
+			case sel.spread != "":
+				if visited[sel.spread] {
+					continue
+				}
+				visited[sel.spread] = true
+				def, ok := e.fragments[sel.spread]
+				if !ok {
+					return fmt.Errorf("unknown fragment %q", sel.spread)
+				}
+				if def.typeCondition != obj.Name {
+					return fmt.Errorf("fragment %q on type %q cannot be spread on type %q", def.name, def.typeCondition, obj.Name)
+				}
+				if err := collect(def.selectionSet); err != nil {
+					return err
+				}
+			case sel.inline:
+				if sel.typeCondition != "" && sel.typeCondition != obj.Name {
+					return fmt.Errorf("inline fragment on type %q cannot be spread on type %q", sel.typeCondition, obj.Name)
+				}
+				if err := collect(sel.selectionSet); err != nil {
+					return err
+				}
+			default:
+				existing, ok := byAlias[sel.alias]
+				if !ok {
+					field := *sel
+					byAlias[sel.alias] = &field
+					fields = append(fields, &field)
+					continue
+				}
+				if existing.name != sel.name || !reflect.DeepEqual(existing.arguments, sel.arguments) ||
+					(existing.selectionSet == nil) != (sel.selectionSet == nil) {
+					return fmt.Errorf("fields %q conflict because they have different names, arguments or selections", sel.alias)
+				}
+				n := len(existing.selectionSet)
+				existing.selectionSet = append(existing.selectionSet[:n:n], sel.selectionSet...)
+			}
+		}
+		return nil
+	}
+
+	if err := collect(set); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// coerceArguments は フィールドの引数を変数で置き換え、引数の型に変換します
+func (e *executor) coerceArguments(field *Field, sel *selection) (map[string]interface{}, error) {
+	for name := range sel.arguments {
+		if _, ok := field.Args[name]; !ok {
+			return nil, fmt.Errorf("unknown argument %q on field %q", name, sel.name)
+		}
+	}
+
+	args := make(map[string]interface{}, len(field.Args))
+	for name, arg := range field.Args {
+		value, ok := sel.arguments[name]
+		if ref, isVar := value.(variableRef); ok && isVar {
+			value, ok = e.variables[string(ref)]
+		}
+		if !ok || value == nil {
+			if arg.Required {
+				return nil, fmt.Errorf("argument %q of field %q is required", name, sel.name)
+			}
+			if arg.Default != nil {
+				args[name] = arg.Default
+			}
+			continue
+		}
+
+		if arg.Type.Coerce != nil {
+			coerced, err := arg.Type.Coerce(value)
+			if err != nil {
+				return nil, fmt.Errorf("argument %q of field %q: %w", name, sel.name, err)
+			}
+			value = coerced
+		}
+		args[name] = value
+	}
+	return args, nil
+}
+
+// executeObjects は 同じ階層のオブジェクトの選択したフィールドを、選択した順に取得します
+// フィールドごとに同じ階層のすべてのオブジェクトの値を取得してから、次の階層を取得する（BatchResolve はフィールドごとに1回呼び出す）
+func (e *executor) executeObjects(ctx context.Context, obj *Object, sources []interface{}, set []*selection, paths [][]interface{}) []*orderedFields {
+	results := make([]*orderedFields, len(sources))
+	for i := range results {
+		results[i] = &orderedFields{}
+	}
+
+	for _, sel := range set {
+		if sel.name == "__typename" {
+			for _, result := range results {
+				result.add(sel.alias, obj.Name)
+			}
+			continue
+		}
+
+		field := obj.Fields[sel.name]
+		fieldPaths := make([][]interface{}, len(sources))
+		for i := range sources {
+			fieldPaths[i] = appendPath(paths[i], sel.alias)
+		}
+		values, errs := e.resolve(ctx, field, sources, e.args[sel])
+
+		// 取得に成功した値のみを次の階層で取得する
+		var resolved []interface{}
+		var resolvedPaths [][]interface{}
+		for i, err := range errs {
+			if err != nil {
+				e.addError(err, fieldPaths[i])
+				continue
+			}
+			resolved = append(resolved, values[i])
+			resolvedPaths = append(resolvedPaths, fieldPaths[i])
+		}
+		completed := e.completeAll(ctx, field.Type, resolved, sel.selectionSet, resolvedPaths)
+
+		next := 0
+		for i, err := range errs {
+			if err != nil {
+				results[i].add(sel.alias, nil)
+				continue
+			}
+			results[i].add(sel.alias, completed[next])
+			next++
+		}
+	}
+	return results
+}
+
+// resolve は 同じ階層のオブジェクトのフィールドの値を取得します（値ごとの取得のエラーを合わせて返す）
+func (e *executor) resolve(ctx context.Context, field *Field, sources []interface{}, args map[string]interface{}) ([]interface{}, []error) {
+	values := make([]interface{}, len(sources))
+	errs := make([]error, len(sources))
+
+	if field.BatchResolve == nil {
+		for i, source := range sources {
+			values[i], errs[i] = field.Resolve(ResolveParams{Context: ctx, Source: source, Args: args})
+		}
+		return values, errs
+	}
+
+	batch, err := field.BatchResolve(BatchResolveParams{Context: ctx, Sources: sources, Args: args})
+	if err == nil && len(batch) != len(sources) {
+		err = fmt.Errorf("batch resolver returned %d values for %d sources", len(batch), len(sources))
+	}
+	for i := range sources {
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if itemErr, ok := batch[i].(error); ok {
+			errs[i] = itemErr
+			continue
+		}
+		values[i] = batch[i]
+	}
+	return values, errs
+}
+
+// completeAll は 同じ階層で取得した値を型に従って JSON にできる値にします
+// リストの要素・オブジェクトのフィールドは、同じ階層のすべての値のものをまとめて取得する
+func (e *executor) completeAll(ctx context.Context, t Type, values []interface{}, set []*selection, paths [][]interface{}) []interface{} {
+	completed := make([]interface{}, len(values))
+
+	switch t := t.(type) {
+	case *List:
+		// すべてのリストの要素を1つの階層として取得し、取得後にリストごとに分ける
+		var items []interface{}
+		var itemPaths [][]interface{}
+		counts := make([]int, len(values))
+		for i, value := range values {
+			counts[i] = -1
+			if isNil(value) {
+				continue
+			}
+			list := reflect.ValueOf(value)
+			if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+				e.addError(fmt.Errorf("expected list for %s, got %T", t, value), paths[i])
+				continue
+			}
+			counts[i] = list.Len()
+			for j := 0; j < list.Len(); j++ {
+				items = append(items, list.Index(j).Interface())
+				itemPaths = append(itemPaths, appendPath(paths[i], j))
+			}
+		}
+
+		completedItems := e.completeAll(ctx, t.Of, items, set, itemPaths)
+		next := 0
+		for i, count := range counts {
+			if count < 0 {
+				continue
+			}
+			list := make([]interface{}, count)
+			copy(list, completedItems[next:next+count])
+			completed[i] = list
+			next += count
+		}
+	case *Object:
+		var sources []interface{}
+		var sourcePaths [][]interface{}
+		var indexes []int
+		for i, value := range values {
+			if isNil(value) {
+				continue
+			}
+			sources = append(sources, value)
+			sourcePaths = append(sourcePaths, paths[i])
+			indexes = append(indexes, i)
+		}
+		if len(sources) == 0 {
+			return completed
+		}
+		for j, result := range e.executeObjects(ctx, t, sources, set, sourcePaths) {
+			completed[indexes[j]] = result
+		}
+	default:
+		for i, value := range values {
+			if !isNil(value) {
+				completed[i] = value
+			}
+		}
+	}
+	return completed
+}
+
+// addError は フィールドの取得のエラーを記録します
+// 5xx 相当のエラーは詳細をクライアントに返さず、サーバーログにのみ記録する（apierror.Write と同じ扱い）
+func (e *executor) addError(err error, path []interface{}) {
+	appErr := apierror.From(err)
+	if appErr.HTTPStatus >= http.StatusInternalServerError {
+		log.Printf("[%s] graphql %v: %v", appErr.Code, path, appErr.Err)
+	}
+	e.errors = append(e.errors, &Error{
+		Message:    appErr.Message,
+		Path:       path,
+		Extensions: map[string]interface{}{"code": appErr.Code},
+	})
+}
+
+// appendPath は 親のパスを変更せずに要素を追加したパスを返します
+func appendPath(path []interface{}, elem interface{}) []interface{} {
+	next := make([]interface{}, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, elem)
+}
+
+// isNil は 値が nil（型付きの nil ポインター・スライス・マップを含む）かどうかを返します
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// orderedFields は 選択した順にフィールドを並べて JSON にするオブジェクトです
+type orderedFields struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *orderedFields) add(key string, value interface{}) {
+	// 同じエイリアスを複数回選択した場合は最初の位置に後の値を入れる
+	for i, k := range o.keys {
+		if k == key {
+			o.values[i] = value
+			return
+		}
+	}
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+func (o *orderedFields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
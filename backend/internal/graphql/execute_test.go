@@ -0,0 +1,184 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"simple-notion-backend/internal/apierror"
+)
+
+type testItem struct {
+	ID       int
+	Name     string
+	Children []*testItem
+}
+
+func testSchema() *Schema {
+	item := &Object{Name: "Item"}
+	item.Fields = Fields{
+		"id":   {Type: Int, Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(*testItem).ID, nil }},
+		"name": {Type: String, Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(*testItem).Name, nil }},
+		"children": {Type: ListOf(item), Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source.(*testItem).Children, nil
+		}},
+		"secret": {Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			return nil, apierror.NewForbidden("FORBIDDEN", "アクセス権限がありません", nil)
+		}},
+	}
+
+	items := map[int]*testItem{
+		1: {ID: 1, Name: "親", Children: []*testItem{{ID: 2, Name: "子1"}, {ID: 3, Name: "子2"}}},
+	}
+	query := &Object{Name: "Query", Fields: Fields{
+		"item": {
+			Type: item,
+			Args: Args{"id": {Type: Int, Required: true}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				if found, ok := items[p.Args["id"].(int)]; ok {
+					return found, nil
+				}
+				return (*testItem)(nil), nil
+			},
+		},
+		"greeting": {
+			Type: String,
+			Args: Args{"name": {Type: String, Default: "world"}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				return "hello " + p.Args["name"].(string), nil
+			},
+		},
+	}}
+	return &Schema{Query: query, MaxDepth: 3, MaxComplexity: 30, ListFactor: 10}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       Request
+		want      string
+		wantError string
+	}{
+		{
+			name: "正常系：入れ子のフィールドを選択した順に返す",
+			req:  Request{Query: `{ item(id: 1) { name id children { id name } } }`},
+			want: `{"data":{"item":{"name":"親","id":1,"children":[{"id":2,"name":"子1"},{"id":3,"name":"子2"}]}}}`,
+		},
+		{
+			name: "正常系：エイリアス・変数・__typename",
+			req: Request{
+				Query:     `query Get($id: Int!) { first: item(id: $id) { __typename id } # コメント` + "\n" + `}`,
+				Variables: map[string]interface{}{"id": float64(1)},
+			},
+			want: `{"data":{"first":{"__typename":"Item","id":1}}}`,
+		},
+		{
+			name: "正常系：省略した引数と変数の既定値",
+			req:  Request{Query: `query ($n: String = "gopher") { a: greeting b: greeting(name: $n) c: greeting(name: "あ") }`},
+			want: `{"data":{"a":"hello world","b":"hello gopher","c":"hello あ"}}`,
+		},
+		{
+			name: "正常系：存在しない値は null",
+			req:  Request{Query: `{ item(id: 9) { id } }`},
+			want: `{"data":{"item":null}}`,
+		},
+		{
+			name: "正常系：取得に失敗したフィールドは null にして errors に加える",
+			req:  Request{Query: `{ item(id: 1) { id secret } }`},
+			want: `{"data":{"item":{"id":1,"secret":null}},"errors":[{"message":"アクセス権限がありません","path":["item","secret"],"extensions":{"code":"FORBIDDEN"}}]}`,
+		},
+		{
+			name: "正常系：名前付きのフラグメントとインラインフラグメント",
+			req: Request{Query: `query { item(id: 1) { ...Fields ... on Item { children { name } } } }
+				fragment Fields on Item { id children { id } ... { name } }`},
+			want: `{"data":{"item":{"id":1,"children":[{"id":2,"name":"子1"},{"id":3,"name":"子2"}],"name":"親"}}}`,
+		},
+		{
+			name: "正常系：複数の操作から operationName で選ぶ",
+			req:  Request{Query: `query A { greeting } query B { item(id: 1) { id } }`, OperationName: "B"},
+			want: `{"data":{"item":{"id":1}}}`,
+		},
+		{name: "異常系：構文エラー", req: Request{Query: `{ item(id: 1) { id }`}, wantError: "syntax error"},
+		{name: "異常系：存在しないフィールド", req: Request{Query: `{ item(id: 1) { title } }`}, wantError: `cannot query field "title"`},
+		{name: "異常系：オブジェクトのフィールドを選択していない", req: Request{Query: `{ item(id: 1) }`}, wantError: "must have a selection"},
+		{name: "異常系：必須の引数がない", req: Request{Query: `{ item { id } }`}, wantError: `argument "id" of field "item" is required`},
+		{name: "異常系：引数の型が違う", req: Request{Query: `{ item(id: "1") { id } }`}, wantError: "expected Int"},
+		{name: "異常系：深すぎる入れ子", req: Request{Query: `{ item(id: 1) { children { children { id } } } }`}, wantError: "maximum depth"},
+		{name: "異常系：複雑すぎるクエリ", req: Request{Query: `{ a: item(id: 1) { children { id name } } b: item(id: 1) { children { id name } } }`}, wantError: "maximum complexity of 30"},
+		{name: "異常系：定義していないフラグメント", req: Request{Query: `{ item(id: 1) { ...f } }`}, wantError: `unknown fragment "f"`},
+		{name: "異常系：自身を展開するフラグメント", req: Request{Query: `{ item(id: 1) { ...f } } fragment f on Item { children { ...f } }`}, wantError: `fragment "f" must not spread itself`},
+		{name: "異常系：型の違うフラグメント", req: Request{Query: `{ item(id: 1) { ...f } } fragment f on Query { greeting }`}, wantError: "cannot be spread on type \"Item\""},
+		{name: "異常系：同じエイリアスで引数の違うフィールド", req: Request{Query: `{ x: greeting(name: "a") x: greeting(name: "b") }`}, wantError: `fields "x" conflict`},
+		{name: "異常系：ディレクティブ", req: Request{Query: `{ item(id: 1) @include(if: true) { id } }`}, wantError: "directives are not supported"},
+		{name: "異常系：mutation", req: Request{Query: `mutation { greeting }`}, wantError: "mutation operations are not supported"},
+		{name: "異常系：operationName が必要", req: Request{Query: `query A { greeting } query B { greeting }`}, wantError: "operationName is required"},
+	}
+
+	schema := testSchema()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Execute(context.Background(), schema, tt.req)
+			body, err := json.Marshal(res)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+
+			if tt.wantError != "" {
+				if res.Data != nil || len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, tt.wantError) {
+					t.Errorf("response = %s, want error containing %q without data", body, tt.wantError)
+				}
+				return
+			}
+			if string(body) != tt.want {
+				t.Errorf("response = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteBatchResolve(t *testing.T) {
+	var calls [][]interface{}
+	item := &Object{Name: "Item"}
+	item.Fields = Fields{
+		"id": {Type: Int, Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(*testItem).ID, nil }},
+		"children": {Type: ListOf(item), BatchResolve: func(p BatchResolveParams) ([]interface{}, error) {
+			calls = append(calls, p.Sources)
+			values := make([]interface{}, len(p.Sources))
+			for i, source := range p.Sources {
+				parent := source.(*testItem)
+				if parent.ID == 3 {
+					values[i] = apierror.NewNotFound("ITEM_NOT_FOUND", "見つかりません", nil)
+					continue
+				}
+				values[i] = []*testItem{{ID: parent.ID * 10}, {ID: parent.ID*10 + 1}}
+			}
+			return values, nil
+		}},
+	}
+	query := &Object{Name: "Query", Fields: Fields{
+		"items": {Type: ListOf(item), Resolve: func(p ResolveParams) (interface{}, error) {
+			return []*testItem{{ID: 1}, {ID: 2}, {ID: 3}}, nil
+		}},
+	}}
+	schema := &Schema{Query: query}
+
+	res := Execute(context.Background(), schema, Request{Query: `{ items { id children { id children { id } } } }`})
+	body, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `{"data":{"items":[` +
+		`{"id":1,"children":[{"id":10,"children":[{"id":100},{"id":101}]},{"id":11,"children":[{"id":110},{"id":111}]}]},` +
+		`{"id":2,"children":[{"id":20,"children":[{"id":200},{"id":201}]},{"id":21,"children":[{"id":210},{"id":211}]}]},` +
+		`{"id":3,"children":null}]},` +
+		`"errors":[{"message":"見つかりません","path":["items",2,"children"],"extensions":{"code":"ITEM_NOT_FOUND"}}]}`
+	if string(body) != want {
+		t.Errorf("response = %s, want %s", body, want)
+	}
+	// 同じ階層の親の値をまとめて、階層ごとに1回だけ呼び出す
+	if len(calls) != 2 || len(calls[0]) != 3 || len(calls[1]) != 4 {
+		t.Errorf("BatchResolve calls = %v, want 2 calls with 3 and 4 sources", calls)
+	}
+}
@@ -0,0 +1,599 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxQueryLength は 受け付けるクエリの最大の長さ（バイト）です
+const maxQueryLength = 64 * 1024
+
+// document は 解析したクエリの操作とフラグメントの一覧です
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragmentDefinition
+}
+
+// fragmentDefinition は 名前付きのフラグメント（fragment Name on Type { ... }）です
+type fragmentDefinition struct {
+	name          string
+	typeCondition string
+	selectionSet  []*selection
+}
+
+// operation は 1つの操作（query）です
+type operation struct {
+	kind         string // "query", "mutation" or "subscription"
+	name         string
+	variables    []variableDefinition
+	selectionSet []*selection
+}
+
+// variableDefinition は 操作で宣言した変数です
+type variableDefinition struct {
+	name         string
+	defaultValue interface{} // 省略時は nil
+	hasDefault   bool
+}
+
+// selection は 選択したフィールド、またはフラグメントの展開（...Name・... on Type { ... }）です
+type selection struct {
+	alias        string // 省略時は name と同じ
+	name         string
+	arguments    map[string]interface{}
+	selectionSet []*selection
+
+	spread        string // 名前付きのフラグメントの展開の場合はフラグメントの名前
+	inline        bool   // インラインフラグメントの場合は true
+	typeCondition string // インラインフラグメントの型の条件（省略時は空）
+}
+
+// variableRef は 引数の値に指定した変数（$name）の参照です
+type variableRef string
+
+// enumValue は 引数の値に指定した列挙値です
+type enumValue string
+
+// tokenKind は 字句の種類です
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// token は クエリの字句です
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// parser は クエリの字句を先頭から読む再帰下降パーサーです
+// ディレクティブには対応していない（使用した場合はエラーを返す）
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+// parse は クエリの文字列を解析します
+func parse(src string) (*document, error) {
+	if len(src) > maxQueryLength {
+		return nil, fmt.Errorf("query exceeds %d bytes", maxQueryLength)
+	}
+	p := &parser{src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragmentDefinition)}
+	for p.tok.kind != tokenEOF {
+		if p.tok.kind == tokenName && p.tok.value == "fragment" {
+			def, err := p.parseFragmentDefinition()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[def.name]; ok {
+				return nil, fmt.Errorf("fragment %q is defined more than once", def.name)
+			}
+			doc.fragments[def.name] = def
+			continue
+		}
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		doc.operations = append(doc.operations, op)
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("query has no operations")
+	}
+	return doc, nil
+}
+
+// parseOperation は 操作を1つ読みます（省略形の { ... } は名前のない query）
+func (p *parser) parseOperation() (*operation, error) {
+	op := &operation{kind: "query"}
+	if p.tok.kind == tokenName {
+		switch p.tok.value {
+		case "query", "mutation", "subscription":
+			op.kind = p.tok.value
+		default:
+			return nil, p.errorf("unexpected %q", p.tok.value)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenName {
+			op.name = p.tok.value
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+		if p.isPunct("(") {
+			vars, err := p.parseVariableDefinitions()
+			if err != nil {
+				return nil, err
+			}
+			op.variables = vars
+		}
+	}
+
+	set, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selectionSet = set
+	return op, nil
+}
+
+// parseFragmentDefinition は fragment Name on Type { ... } を読みます
+func (p *parser) parseFragmentDefinition() (*fragmentDefinition, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName && p.tok.value == "on" {
+		return nil, p.errorf("fragment name must not be %q", "on")
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenName || p.tok.value != "on" {
+		return nil, p.errorf("expected %q", "on")
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	typeCondition, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if p.isPunct("@") {
+		return nil, p.errorf("directives are not supported")
+	}
+	set, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragmentDefinition{name: name, typeCondition: typeCondition, selectionSet: set}, nil
+}
+
+// parseVariableDefinitions は ($name: Type = default, ...) を読みます（型はクエリの実行時に引数の型で確認する）
+func (p *parser) parseVariableDefinitions() ([]variableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var defs []variableDefinition
+	for !p.isPunct(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if err := p.skipType(); err != nil {
+			return nil, err
+		}
+		def := variableDefinition{name: name}
+		if p.isPunct("=") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+			def.defaultValue, def.hasDefault = value, true
+		}
+		defs = append(defs, def)
+	}
+	return defs, p.next()
+}
+
+// skipType は 変数の型（Name・[Type]・末尾の !）を読み飛ばします
+func (p *parser) skipType() error {
+	if p.isPunct("[") {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return err
+	}
+	if p.isPunct("!") {
+		return p.next()
+	}
+	return nil
+}
+
+// parseSelectionSet は { field, alias: field(arg: value) { ... } } を読みます
+func (p *parser) parseSelectionSet() ([]*selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var set []*selection
+	for !p.isPunct("}") {
+		read := p.parseSelection
+		if p.isPunct("...") {
+			read = p.parseFragmentSelection
+		}
+		sel, err := read()
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, sel)
+	}
+	if len(set) == 0 {
+		return nil, p.errorf("selection set must not be empty")
+	}
+	return set, p.next()
+}
+
+// parseSelection は フィールドを1つ読みます
+func (p *parser) parseSelection() (*selection, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	sel := &selection{alias: name, name: name}
+	if p.isPunct(":") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if sel.name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.isPunct("(") {
+		if sel.arguments, err = p.parseArguments(); err != nil {
+			return nil, err
+		}
+	}
+	if p.isPunct("@") {
+		return nil, p.errorf("directives are not supported")
+	}
+	if p.isPunct("{") {
+		if sel.selectionSet, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return sel, nil
+}
+
+// parseFragmentSelection は フラグメントの展開（...Name）またはインラインフラグメント（... on Type { ... }・... { ... }）を読みます
+func (p *parser) parseFragmentSelection() (*selection, error) {
+	if err := p.expect("..."); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		name := p.tok.value
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.isPunct("@") {
+			return nil, p.errorf("directives are not supported")
+		}
+		return &selection{spread: name}, nil
+	}
+
+	sel := &selection{inline: true}
+	if p.tok.kind == tokenName {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		var err error
+		if sel.typeCondition, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.isPunct("@") {
+		return nil, p.errorf("directives are not supported")
+	}
+	set, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	sel.selectionSet = set
+	return sel, nil
+}
+
+// parseArguments は (name: value, ...) を読みます
+func (p *parser) parseArguments() (map[string]interface{}, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := make(map[string]interface{})
+	for !p.isPunct(")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+		args[name] = value
+	}
+	return args, p.next()
+}
+
+// parseValue は 値を読みます（整数は int、小数は float64、変数は variableRef）
+// constant が true の場合（変数の既定値）は変数を参照できない
+func (p *parser) parseValue(constant bool) (interface{}, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokenPunct && tok.value == "$":
+		if constant {
+			return nil, p.errorf("variables are not allowed here")
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		return variableRef(name), err
+	case tok.kind == tokenPunct && tok.value == "[":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		list := []interface{}{}
+		for !p.isPunct("]") {
+			value, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, p.next()
+	case tok.kind == tokenPunct && tok.value == "{":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		object := make(map[string]interface{})
+		for !p.isPunct("}") {
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.parseValue(constant); err != nil {
+				return nil, err
+			}
+		}
+		return object, p.next()
+	case tok.kind == tokenInt:
+		n, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return nil, p.errorf("invalid integer %q", tok.value)
+		}
+		return n, p.next()
+	case tok.kind == tokenFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, p.errorf("invalid float %q", tok.value)
+		}
+		return f, p.next()
+	case tok.kind == tokenString:
+		return tok.value, p.next()
+	case tok.kind == tokenName:
+		var value interface{}
+		switch tok.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = enumValue(tok.value)
+		}
+		return value, p.next()
+	}
+	return nil, p.errorf("unexpected %q", tok.value)
+}
+
+// isPunct は 現在の字句が記号 s かどうかを返します
+func (p *parser) isPunct(s string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == s
+}
+
+// expect は 現在の字句が記号 s であることを確認して次に進みます
+func (p *parser) expect(s string) error {
+	if !p.isPunct(s) {
+		return p.errorf("expected %q", s)
+	}
+	return p.next()
+}
+
+// expectName は 現在の字句が名前であることを確認して次に進みます
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.errorf("expected name")
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+// errorf は 現在の字句の位置を含む構文エラーを返します
+func (p *parser) errorf(format string, args ...interface{}) error {
+	if p.tok.kind == tokenEOF {
+		return fmt.Errorf("syntax error at end of query: "+format, args...)
+	}
+	return fmt.Errorf("syntax error at offset %d: "+format, append([]interface{}{p.tok.pos}, args...)...)
+}
+
+// next は 次の字句を読みます（空白・カンマ・コメントは読み飛ばす）
+func (p *parser) next() error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+		} else if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		} else if strings.HasPrefix(p.src[p.pos:], "\uFEFF") {
+			p.pos += len("\uFEFF")
+		} else {
+			break
+		}
+	}
+
+	start := p.pos
+	if start >= len(p.src) {
+		p.tok = token{kind: tokenEOF, pos: start}
+		return nil
+	}
+
+	c := p.src[start]
+	switch {
+	case strings.HasPrefix(p.src[start:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokenPunct, value: "...", pos: start}
+	case strings.IndexByte("{}()[]:$!=@|&", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokenPunct, value: string(c), pos: start}
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenName, value: p.src[start:p.pos], pos: start}
+	case c == '-' || isDigit(c):
+		return p.readNumber()
+	case c == '"':
+		return p.readString()
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[start:])
+		return fmt.Errorf("syntax error at offset %d: unexpected character %q", start, r)
+	}
+	return nil
+}
+
+// readNumber は 整数・小数の字句を読みます
+func (p *parser) readNumber() error {
+	start := p.pos
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	kind := tokenInt
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case isDigit(c):
+		case c == '.' || c == 'e' || c == 'E':
+			kind = tokenFloat
+		case (c == '+' || c == '-') && (p.src[p.pos-1] == 'e' || p.src[p.pos-1] == 'E'):
+		default:
+			p.tok = token{kind: kind, value: p.src[start:p.pos], pos: start}
+			return nil
+		}
+		p.pos++
+	}
+	p.tok = token{kind: kind, value: p.src[start:p.pos], pos: start}
+	return nil
+}
+
+// readString は "..." の文字列の字句を読みます（ブロック文字列 """ には対応していない）
+func (p *parser) readString() error {
+	start := p.pos
+	if strings.HasPrefix(p.src[start:], `"""`) {
+		return fmt.Errorf("syntax error at offset %d: block strings are not supported", start)
+	}
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.src); {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			p.tok = token{kind: tokenString, value: b.String(), pos: start}
+			return nil
+		case c == '\n' || c == '\r':
+			return fmt.Errorf("syntax error at offset %d: unterminated string", start)
+		case c == '\\':
+			if p.pos+1 >= len(p.src) {
+				return fmt.Errorf("syntax error at offset %d: unterminated string", start)
+			}
+			escaped := p.src[p.pos+1]
+			p.pos += 2
+			switch escaped {
+			case '"', '\\', '/':
+				b.WriteByte(escaped)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if p.pos+4 > len(p.src) {
+					return fmt.Errorf("syntax error at offset %d: invalid unicode escape", p.pos)
+				}
+				code, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+				if err != nil {
+					return fmt.Errorf("syntax error at offset %d: invalid unicode escape", p.pos)
+				}
+				b.WriteRune(rune(code))
+				p.pos += 4
+			default:
+				return fmt.Errorf("syntax error at offset %d: invalid escape \\%c", p.pos-1, escaped)
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return fmt.Errorf("syntax error at offset %d: unterminated string", start)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
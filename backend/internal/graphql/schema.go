@@ -0,0 +1,169 @@
+// Package graphql は 読み取り専用の GraphQL クエリの解析と実行を提供します
+//
+// 標準ライブラリのみで実装しており、対応するのは query 操作・エイリアス・引数・変数・フラグメント（名前付き・インライン）・__typename のみ。
+// ディレクティブ・mutation・subscription・イントロスペクション・インターフェース・ユニオンには対応していない。
+// スキーマは Object・Field を組み合わせて Go のコードで定義し、各フィールドの Resolve（または BatchResolve）で値を取得する。
+// フィールドは階層ごとに取得するため、BatchResolve を定義したフィールドは同じ階層の親の値をまとめて1回で取得できる。
+package graphql
+
+import (
+	"context"
+	"fmt"
+)
+
+// Type は フィールド・引数の型です（*Scalar・*Object・*List）
+type Type interface {
+	String() string
+}
+
+// Scalar は 値をそのまま JSON にするスカラー型です
+type Scalar struct {
+	Name string
+	// Coerce は 引数・変数の値を Go の値に変換します（nil の場合は変換しない）
+	Coerce func(value interface{}) (interface{}, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// Object は フィールドを持つオブジェクト型です
+type Object struct {
+	Name   string
+	Fields Fields
+}
+
+func (o *Object) String() string { return o.Name }
+
+// List は 型 Of の値のリストです
+type List struct {
+	Of Type
+}
+
+func (l *List) String() string { return "[" + l.Of.String() + "]" }
+
+// ListOf は 型 t のリストを返します
+func ListOf(t Type) *List {
+	return &List{Of: t}
+}
+
+// Fields は フィールド名 → フィールドの定義です
+type Fields map[string]*Field
+
+// Field は オブジェクトのフィールドです（Resolve・BatchResolve のどちらかを指定する）
+type Field struct {
+	Type    Type
+	Args    Args
+	Resolve ResolveFunc
+	// BatchResolve は 同じ階層の親の値をまとめて取得します（指定した場合は Resolve より優先する）
+	BatchResolve BatchResolveFunc
+}
+
+// Args は 引数名 → 引数の定義です
+type Args map[string]*Arg
+
+// Arg は フィールドの引数です
+type Arg struct {
+	Type     *Scalar
+	Required bool
+	Default  interface{} // 省略時の値（nil の場合は引数を渡さない）
+}
+
+// ResolveFunc は フィールドの値を取得する関数です
+// 返した値は Field.Type に従って、オブジェクトの場合は選択したフィールドを、リストの場合は各要素を取得する
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// ResolveParams は ResolveFunc に渡す値です
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{}            // 親のオブジェクトの値（Query のフィールドでは nil）
+	Args    map[string]interface{} // 型に従って変換した引数（省略した引数は含まない）
+}
+
+// BatchResolveFunc は 同じ階層の親の値（Sources）に対するフィールドの値をまとめて取得する関数です
+// 値は Sources と同じ順に返し、一部の親だけ取得に失敗した場合はその位置に error を入れる
+// （関数が error を返した場合はすべての親でフィールドを null にする）
+type BatchResolveFunc func(p BatchResolveParams) ([]interface{}, error)
+
+// BatchResolveParams は BatchResolveFunc に渡す値です
+type BatchResolveParams struct {
+	Context context.Context
+	Sources []interface{}          // 同じ階層の親のオブジェクトの値（null の親は含まない）
+	Args    map[string]interface{} // 型に従って変換した引数（省略した引数は含まない）
+}
+
+// Schema は 実行するクエリのスキーマです
+type Schema struct {
+	Query *Object
+	// MaxDepth は 選択したフィールドの入れ子の最大の深さです（0 以下は無制限）
+	MaxDepth int
+	// MaxComplexity は クエリの複雑さ（選択したフィールドの数。リストのフィールドの下は ListFactor 倍に数える）の上限です（0 以下は無制限）
+	MaxComplexity int
+	// ListFactor は 複雑さの計算で見積もるリストの要素数です（1 未満の場合は 1）
+	ListFactor int
+}
+
+// 組み込みのスカラー型
+var (
+	// Int は 整数です（引数は int に変換する）
+	Int = &Scalar{Name: "Int", Coerce: coerceInt}
+	// Float は 小数です（引数は float64 に変換する）
+	Float = &Scalar{Name: "Float", Coerce: coerceFloat}
+	// String は 文字列です
+	String = &Scalar{Name: "String", Coerce: coerceString}
+	// Boolean は 真偽値です
+	Boolean = &Scalar{Name: "Boolean", Coerce: coerceBoolean}
+	// ID は 識別子です（引数は文字列・整数のどちらも受け付け、文字列に変換する）
+	ID = &Scalar{Name: "ID", Coerce: coerceID}
+	// JSON は 任意の JSON の値です（ブロックの content など）
+	JSON = &Scalar{Name: "JSON"}
+)
+
+func coerceInt(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case float64:
+		// JSON の変数は float64 として解析される
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return nil, fmt.Errorf("expected Int, got %v", value)
+}
+
+func coerceFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	}
+	return nil, fmt.Errorf("expected Float, got %v", value)
+}
+
+func coerceString(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("expected String, got %v", value)
+}
+
+func coerceBoolean(value interface{}) (interface{}, error) {
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("expected Boolean, got %v", value)
+}
+
+func coerceID(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int:
+		return fmt.Sprint(v), nil
+	case float64:
+		if v == float64(int(v)) {
+			return fmt.Sprint(int(v)), nil
+		}
+	}
+	return nil, fmt.Errorf("expected ID, got %v", value)
+}
@@ -0,0 +1,207 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// AdminHandler は 管理者向けのHTTPハンドラーです
+type AdminHandler struct {
+	maintenanceService  *services.MaintenanceService
+	statsService        *services.StatsService
+	fileService         *services.FileService
+	workspaceService    *services.WorkspaceService
+	defaultStorageQuota int64 // 個別に設定されていないユーザーのストレージクォータ
+}
+
+// NewAdminHandler は 新しい AdminHandler インスタンスを作成します
+func NewAdminHandler(
+	maintenanceService *services.MaintenanceService,
+	statsService *services.StatsService,
+	fileService *services.FileService,
+	workspaceService *services.WorkspaceService,
+	defaultStorageQuota int64,
+) *AdminHandler {
+	return &AdminHandler{
+		maintenanceService:  maintenanceService,
+		statsService:        statsService,
+		fileService:         fileService,
+		workspaceService:    workspaceService,
+		defaultStorageQuota: defaultStorageQuota,
+	}
+}
+
+// GetStats は インスタンス全体の統計（ユーザー数・文書/ブロック数・ストレージ使用量・日ごとの推移）を返します
+// 集計結果はキャッシュされ、generatedAt が集計日時を表す
+func (h *AdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.statsService.GetStats()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, stats)
+}
+
+// GetOrphans は 孤立したブロック・ファイル参照の検出結果を返します（データは変更しない）
+func (h *AdminHandler) GetOrphans(w http.ResponseWriter, r *http.Request) {
+	report, err := h.maintenanceService.ScanOrphans()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, report)
+}
+
+// RepairOrphans は 孤立したブロック・ファイル参照を修復します
+// deleteBlocks: 文書のないブロックを削除、fileAction: detach（参照を外す）/ delete（削除扱いにする）
+func (h *AdminHandler) RepairOrphans(w http.ResponseWriter, r *http.Request) {
+	var req models.OrphanRepairOptions
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	result, err := h.maintenanceService.RepairOrphans(req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, result)
+}
+
+// GetStorageConsistency は ストレージとデータベースの突き合わせ結果を返します（データは変更しない）
+// 通常は定期ジョブの直近の結果を返し、refresh=true の場合はその場で突き合わせる
+func (h *AdminHandler) GetStorageConsistency(w http.ResponseWriter, r *http.Request) {
+	var (
+		report *models.StorageConsistencyReport
+		err    error
+	)
+	if r.URL.Query().Get("refresh") == "true" {
+		report, err = h.maintenanceService.ScanStorage(r.Context())
+	} else {
+		report, err = h.maintenanceService.GetStorageReport(r.Context())
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, report)
+}
+
+// GetStorageUsage は インスタンス全体のストレージ使用量（種類ごとの内訳・使用量の多いユーザー・クォータ超過）を返します
+func (h *AdminHandler) GetStorageUsage(w http.ResponseWriter, r *http.Request) {
+	report, err := h.fileService.GetStorageUsageReport(r.Context(), h.defaultStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, report)
+}
+
+// GetStorageQuota は ユーザーのストレージクォータと使用量を返します
+func (h *AdminHandler) GetStorageQuota(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_USER_ID", "ユーザーIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	quota, err := h.fileService.GetStorageQuota(r.Context(), userID, h.defaultStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, quota)
+}
+
+// UpdateStorageQuota は ユーザーのストレージクォータを変更します
+// quotaBytes に null を指定すると個別の設定を解除し、全体の既定値に戻す
+func (h *AdminHandler) UpdateStorageQuota(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_USER_ID", "ユーザーIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	var req models.StorageQuotaUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	quota, err := h.fileService.SetStorageQuota(r.Context(), userID, req, h.defaultStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, quota)
+}
+
+// GetWorkspaceStorageQuota は ワークスペースのストレージクォータと、メンバー全員の使用量の合計を返します
+func (h *AdminHandler) GetWorkspaceStorageQuota(w http.ResponseWriter, r *http.Request) {
+	workspaceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_WORKSPACE_ID", "ワークスペースIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	quota, err := h.workspaceService.StorageQuota(workspaceID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, quota)
+}
+
+// UpdateWorkspaceStorageQuota は ワークスペースのストレージクォータを変更します
+// quotaBytes に null を指定すると個別の設定を解除し、全体の既定値に戻す（0 は無制限）
+func (h *AdminHandler) UpdateWorkspaceStorageQuota(w http.ResponseWriter, r *http.Request) {
+	workspaceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_WORKSPACE_ID", "ワークスペースIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	var req models.StorageQuotaUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	quota, err := h.workspaceService.SetStorageQuota(workspaceID, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, quota)
+}
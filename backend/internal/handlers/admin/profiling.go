@@ -0,0 +1,141 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+)
+
+const (
+	// defaultProfileSeconds は CPU プロファイル・実行トレースの既定の取得時間（秒）です
+	defaultProfileSeconds = 30
+	// maxProfileSeconds は CPU プロファイル・実行トレースの取得時間の上限（秒）です
+	maxProfileSeconds = 120
+	// profileWriteMargin は 取得時間に加えて、結果を書き出すために延ばす書き込みの期限です
+	profileWriteMargin = 30 * time.Second
+)
+
+// ProfilingHandler は 稼働中のプロセスのプロファイル（net/http/pprof と同じ形式）を返す管理者向けのHTTPハンドラーです
+// 管理者のトークンを Authorization ヘッダーで渡して取得し、go tool pprof で表示する（例: curl -H 'Authorization: Bearer ...' https://host/api/admin/debug/pprof/heap > heap.pb.gz）
+// コマンドライン引数には接続情報が含まれる場合があるため、cmdline は提供しない
+type ProfilingHandler struct{}
+
+// NewProfilingHandler は 新しい ProfilingHandler インスタンスを作成します
+func NewProfilingHandler() *ProfilingHandler {
+	return &ProfilingHandler{}
+}
+
+// Index は 取得できるプロファイルの一覧を返します
+func (h *ProfilingHandler) Index(w http.ResponseWriter, r *http.Request) {
+	pprof.Index(w, r)
+}
+
+// Lookup は 名前を指定したプロファイル（heap・goroutine・allocs・block・mutex・threadcreate）を返します
+// debug=1 でテキスト形式、seconds を指定すると期間中の差分を返す（net/http/pprof と同じ）
+// block・mutex は POST で rate を指定すると、取得前に記録の頻度を設定する（プロセス全体に残るため、調査後に 0 で記録を止める）
+func (h *ProfilingHandler) Lookup(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if rpprof.Lookup(name) == nil {
+		apierror.Write(w, r, apierror.NewNotFound("PROFILE_NOT_FOUND", "プロファイルが見つかりません", nil))
+		return
+	}
+
+	if value := r.URL.Query().Get("rate"); value != "" {
+		if r.Method != http.MethodPost || (name != "block" && name != "mutex") {
+			apierror.Write(w, r, apierror.NewValidationError("RATE_NOT_ALLOWED", "rate は block・mutex に POST で指定してください", nil))
+			return
+		}
+		rate, err := strconv.Atoi(value)
+		if err != nil || rate < 0 {
+			apierror.Write(w, r, apierror.NewValidationError("INVALID_RATE", "rate は0以上の整数で指定してください", err))
+			return
+		}
+		if name == "block" {
+			runtime.SetBlockProfileRate(rate)
+		} else {
+			runtime.SetMutexProfileFraction(rate)
+		}
+	}
+
+	pprof.Handler(name).ServeHTTP(w, r)
+}
+
+// CPUProfile は seconds（既定30秒、最大120秒）の間の CPU プロファイルを返します
+func (h *ProfilingHandler) CPUProfile(w http.ResponseWriter, r *http.Request) {
+	duration, appErr := profileDuration(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	// サーバーの書き込みのタイムアウトより長く取得できるよう、このリクエストの期限を延ばす
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(duration + profileWriteMargin))
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := rpprof.StartCPUProfile(w); err != nil {
+		apierror.Write(w, r, apierror.NewConflict("PROFILING_IN_PROGRESS", "別の CPU プロファイルを取得中です", err))
+		return
+	}
+	waitOrCancel(r, duration)
+	rpprof.StopCPUProfile()
+}
+
+// Trace は seconds（既定30秒、最大120秒）の間の実行トレース（go tool trace で表示する）を返します
+func (h *ProfilingHandler) Trace(w http.ResponseWriter, r *http.Request) {
+	duration, appErr := profileDuration(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(duration + profileWriteMargin))
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	if err := trace.Start(w); err != nil {
+		apierror.Write(w, r, apierror.NewConflict("TRACING_IN_PROGRESS", "別の実行トレースを取得中です", err))
+		return
+	}
+	waitOrCancel(r, duration)
+	trace.Stop()
+}
+
+// Symbol は プログラムカウンタに対応する関数名を返します（go tool pprof がシンボルの解決に使う）
+func (h *ProfilingHandler) Symbol(w http.ResponseWriter, r *http.Request) {
+	pprof.Symbol(w, r)
+}
+
+// profileDuration は クエリの seconds から取得時間を決めます
+func profileDuration(r *http.Request) (time.Duration, *apierror.AppError) {
+	value := r.URL.Query().Get("seconds")
+	if value == "" {
+		return defaultProfileSeconds * time.Second, nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 1 || seconds > maxProfileSeconds {
+		return 0, apierror.NewValidationError("INVALID_DURATION",
+			fmt.Sprintf("seconds は1〜%dの整数で指定してください", maxProfileSeconds), err)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// waitOrCancel は duration が経過するか、クライアントが接続を切るまで待ちます
+func waitOrCancel(r *http.Request, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-r.Context().Done():
+	}
+}
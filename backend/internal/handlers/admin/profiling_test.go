@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// TestProfileDuration - CPU プロファイル・実行トレースの取得時間の範囲のテスト
+func TestProfileDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "正常系：省略時は既定値", query: "", want: defaultProfileSeconds * time.Second},
+		{name: "正常系：下限", query: "seconds=1", want: time.Second},
+		{name: "正常系：上限", query: "seconds=120", want: maxProfileSeconds * time.Second},
+		{name: "異常系：0秒", query: "seconds=0", wantErr: true},
+		{name: "異常系：上限を超える", query: "seconds=121", wantErr: true},
+		{name: "異常系：負の値", query: "seconds=-5", wantErr: true},
+		{name: "異常系：数値でない", query: "seconds=10s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/debug/pprof/profile?"+tt.query, nil)
+			got, appErr := profileDuration(r)
+			if (appErr != nil) != tt.wantErr {
+				t.Fatalf("profileDuration(%q) error = %v, wantErr %v", tt.query, appErr, tt.wantErr)
+			}
+			if appErr != nil && appErr.Code != "INVALID_DURATION" {
+				t.Errorf("profileDuration(%q) code = %q, want INVALID_DURATION", tt.query, appErr.Code)
+			}
+			if got != tt.want {
+				t.Errorf("profileDuration(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProfilingHandlerLookup - 名前を指定したプロファイルの取得と記録の頻度の変更のテスト
+func TestProfilingHandlerLookup(t *testing.T) {
+	h := NewProfilingHandler()
+	serve := func(method, name, query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/admin/debug/pprof/"+name+query, nil)
+		r = mux.SetURLVars(r, map[string]string{"name": name})
+		rec := httptest.NewRecorder()
+		h.Lookup(rec, r)
+		return rec
+	}
+
+	t.Run("正常系：goroutine を返す", func(t *testing.T) {
+		if rec := serve(http.MethodGet, "goroutine", "?debug=1"); rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("正常系：POST で mutex の記録の頻度を変更する", func(t *testing.T) {
+		previous := runtime.SetMutexProfileFraction(-1)
+		defer runtime.SetMutexProfileFraction(previous)
+
+		if rec := serve(http.MethodPost, "mutex", "?rate=5"); rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		if got := runtime.SetMutexProfileFraction(-1); got != 5 {
+			t.Errorf("mutex profile fraction = %d, want 5", got)
+		}
+	})
+
+	t.Run("異常系：存在しないプロファイルは404", func(t *testing.T) {
+		rec := serve(http.MethodGet, "cmdline", "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("Content-Type = %q, want application/problem+json", ct)
+		}
+	})
+
+	t.Run("異常系：GET では記録の頻度を変更しない", func(t *testing.T) {
+		previous := runtime.SetMutexProfileFraction(-1)
+		defer runtime.SetMutexProfileFraction(previous)
+
+		if rec := serve(http.MethodGet, "mutex", "?rate=7"); rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+		if got := runtime.SetMutexProfileFraction(-1); got != previous {
+			t.Errorf("mutex profile fraction = %d, want unchanged %d", got, previous)
+		}
+	})
+
+	t.Run("異常系：rate が負の値", func(t *testing.T) {
+		if rec := serve(http.MethodPost, "block", "?rate=-1"); rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+}
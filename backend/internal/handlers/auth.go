@@ -1,19 +1,27 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/gorilla/mux"
 
 	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/config"
+	"simple-notion-backend/internal/events"
+	"simple-notion-backend/internal/logctx"
 	"simple-notion-backend/internal/middleware"
 	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/password"
 	"simple-notion-backend/internal/repository"
+	"simple-notion-backend/internal/services"
 )
 
 // UserRepositoryInterface は ユーザーリポジトリ操作のインターフェースを定義します
@@ -22,28 +30,44 @@ type UserRepositoryInterface interface {
 	GetByID(id int) (*models.User, error)
 	Create(user *models.User) error
 	Update(user *models.User) error
+	UpdatePassword(userID int, passwordHash string) error
 }
 
 type AuthHandler struct {
-	userRepo  UserRepositoryInterface
-	jwtSecret []byte
-	config    *config.Config
+	userRepo    UserRepositoryInterface
+	hasher      *password.Hasher
+	throttle    *services.LoginThrottle              // nil の場合はログイン試行を制限しない
+	workspaces  middleware.WorkspaceChecker          // nil の場合はワークスペースを選択できない
+	invitations *services.WorkspaceInvitationService // nil の場合は招待リンクからアカウントを作成できない
+	bus         *events.Bus                          // nil の場合はログインのイベントを発行しない
+	jwtSecret   []byte
+	config      *config.Config
 }
 
-func NewAuthHandler(userRepo UserRepositoryInterface, jwtSecret []byte, config *config.Config) *AuthHandler {
+func NewAuthHandler(userRepo UserRepositoryInterface, hasher *password.Hasher, throttle *services.LoginThrottle, workspaces middleware.WorkspaceChecker, invitations *services.WorkspaceInvitationService, bus *events.Bus, jwtSecret []byte, config *config.Config) *AuthHandler {
 	return &AuthHandler{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
-		config:    config,
+		userRepo:    userRepo,
+		hasher:      hasher,
+		throttle:    throttle,
+		workspaces:  workspaces,
+		invitations: invitations,
+		bus:         bus,
+		jwtSecret:   jwtSecret,
+		config:      config,
 	}
 }
 
 // NewAuthHandlerFromRepo は 具象リポジトリを使用してAuthHandlerを作成します
-func NewAuthHandlerFromRepo(userRepo *repository.UserRepository, jwtSecret []byte, config *config.Config) *AuthHandler {
+func NewAuthHandlerFromRepo(userRepo *repository.UserRepository, hasher *password.Hasher, throttle *services.LoginThrottle, workspaces middleware.WorkspaceChecker, invitations *services.WorkspaceInvitationService, bus *events.Bus, jwtSecret []byte, config *config.Config) *AuthHandler {
 	return &AuthHandler{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
-		config:    config,
+		userRepo:    userRepo,
+		hasher:      hasher,
+		throttle:    throttle,
+		workspaces:  workspaces,
+		invitations: invitations,
+		bus:         bus,
+		jwtSecret:   jwtSecret,
+		config:      config,
 	}
 }
 
@@ -94,38 +118,55 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ロック中はパスワードを検証しない
+	if h.throttle != nil {
+		if locked, remaining := h.throttle.Status(req.Email, time.Now()); locked {
+			writeLoginLocked(w, r, remaining)
+			return
+		}
+	}
+
 	// 認証失敗時は「メールが存在しない」と「パスワード不一致」を区別せず同じレスポンスを返す
 	// （メール列挙攻撃を防ぐ）
 	user, err := h.userRepo.GetByEmail(req.Email)
 	if err != nil {
-		apierror.Write(w, r, apierror.NewUnauthorized(
-			"INVALID_CREDENTIALS", "メールアドレスまたはパスワードが正しくありません", err,
-		))
+		h.writeLoginFailure(w, r, req.Email, err)
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		apierror.Write(w, r, apierror.NewUnauthorized(
-			"INVALID_CREDENTIALS", "メールアドレスまたはパスワードが正しくありません", err,
-		))
+	needsRehash, err := h.hasher.Verify(req.Password, user.PasswordHash)
+	if err != nil {
+		h.writeLoginFailure(w, r, req.Email, err)
 		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
-	})
+	if h.throttle != nil {
+		h.throttle.RecordSuccess(req.Email)
+	}
+
+	// bcrypt や古いコストのハッシュは、平文のパスワードが手元にあるログイン成功時に現在の設定で保存し直す
+	// 失敗してもログイン自体は継続する（次回のログインで再試行される）
+	if needsRehash {
+		h.rehashPassword(r.Context(), user, req.Password)
+	}
 
-	tokenString, err := token.SignedString(h.jwtSecret)
+	// ログイン直後は個人の文書を扱う（ワークスペースは選択し直す）
+	tokenString, err := h.issueToken(w, user.ID, user.Email, nil)
 	if err != nil {
 		apierror.Write(w, r, apierror.NewInternal(err))
 		return
 	}
 
-	// セキュアなCookie設定を使用
-	cookie := h.createSecureCookie("auth_token", tokenString, 86400) // 24時間
-	http.SetCookie(w, cookie)
+	ipAddress, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ipAddress = r.RemoteAddr
+	}
+	h.bus.Publish(r.Context(), events.UserLogin, user.ID, events.LoginData{
+		UserID:    user.ID,
+		Email:     user.Email,
+		IPAddress: ipAddress,
+		UserAgent: r.UserAgent(),
+	})
 
 	apierror.WriteJSON(w, http.StatusOK, map[string]interface{}{
 		"user":  user,
@@ -150,7 +191,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := h.hasher.Hash(req.Password)
 	if err != nil {
 		apierror.Write(w, r, apierror.NewInternal(err))
 		return
@@ -158,7 +199,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	user := &models.User{
 		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		Name:         req.Name,
 	}
 
@@ -175,28 +216,243 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 登録後に自動ログイン
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+	tokenString, err := h.issueToken(w, user.ID, user.Email, nil)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewInternal(err))
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"user":  user,
+		"token": tokenString,
 	})
+}
+
+// RegisterWithInvitation は 招待リンクからアカウントを作成し、招待されたワークスペースに参加します
+// メールアドレスは招待のものを使い、作成後はそのワークスペースを選択した状態でログインする
+// 既にアカウントがある場合は 409 を返す（ログインしてから POST /api/invitations/{token}/accept で参加する）
+func (h *AuthHandler) RegisterWithInvitation(w http.ResponseWriter, r *http.Request) {
+	if h.invitations == nil {
+		apierror.Write(w, r, apierror.NewNotFound(
+			"WORKSPACE_INVITATIONS_DISABLED", "招待は利用できません", nil,
+		))
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+		Name     string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+	if len(req.Password) < 6 {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"PASSWORD_TOO_SHORT", "パスワードは6文字以上で入力してください", nil,
+		))
+		return
+	}
+
+	token := mux.Vars(r)["token"]
+	invitation, err := h.invitations.GetInvitation(token)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	hashedPassword, err := h.hasher.Hash(req.Password)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewInternal(err))
+		return
+	}
+	user := &models.User{
+		Email:        invitation.Email,
+		PasswordHash: hashedPassword,
+		Name:         req.Name,
+	}
+	if err := h.userRepo.Create(user); err != nil {
+		if errors.Is(err, apierror.ErrConflict) {
+			apierror.Write(w, r, apierror.NewConflict(
+				"INVITATION_LOGIN_REQUIRED", "このメールアドレスのアカウントは既にあります。ログインしてから招待を承諾してください", err,
+			))
+			return
+		}
+		apierror.Write(w, r, apierror.NewInternal(err))
+		return
+	}
+
+	member, err := h.invitations.AcceptInvitation(token, user.ID)
+	if err != nil {
+		// アカウントは作成済みのため、参加できなかった場合もエラーを返すだけにする（ログインして招待し直してもらう）
+		apierror.Write(w, r, err)
+		return
+	}
 
-	tokenString, err := token.SignedString(h.jwtSecret)
+	tokenString, err := h.issueToken(w, user.ID, user.Email, &member.WorkspaceID)
 	if err != nil {
 		apierror.Write(w, r, apierror.NewInternal(err))
 		return
 	}
 
+	apierror.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"user":          user,
+		"token":         tokenString,
+		"workspaceId":   member.WorkspaceID,
+		"workspaceRole": member.Role,
+	})
+}
+
+// issueToken は 認証トークンを発行して Cookie に設定します
+// workspaceID を指定した場合は workspace_id クレームに含め、以降のリクエストはそのワークスペースの文書を扱う
+func (h *AuthHandler) issueToken(w http.ResponseWriter, userID int, email string, workspaceID *int) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"email":   email,
+		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+	}
+	if workspaceID != nil {
+		claims["workspace_id"] = *workspaceID
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(h.jwtSecret)
+	if err != nil {
+		return "", err
+	}
+
 	// セキュアなCookie設定を使用
 	cookie := h.createSecureCookie("auth_token", tokenString, 86400) // 24時間
 	http.SetCookie(w, cookie)
+	return tokenString, nil
+}
 
-	apierror.WriteJSON(w, http.StatusCreated, map[string]interface{}{
-		"user":  user,
-		"token": tokenString,
+// SwitchWorkspace は 扱うワークスペースを選択し、workspace_id クレームを含む認証トークンを発行し直します
+// workspaceId に null を指定すると個人の文書に戻る。メンバーでないワークスペースは 404 を返す
+// 役割はトークンに含めず、リクエストごとに WorkspaceMiddleware が確認する
+func (h *AuthHandler) SwitchWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req struct {
+		WorkspaceID *int `json:"workspaceId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	var role models.WorkspaceRole
+	if req.WorkspaceID != nil {
+		if h.workspaces != nil {
+			var err error
+			if role, err = h.workspaces.MemberRole(*req.WorkspaceID, userID); err != nil {
+				apierror.Write(w, r, err)
+				return
+			}
+		}
+		if role == "" {
+			apierror.Write(w, r, apierror.NewNotFound(
+				"WORKSPACE_NOT_FOUND", "ワークスペースが見つかりません", nil,
+			))
+			return
+		}
+	}
+
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	tokenString, err := h.issueToken(w, user.ID, user.Email, req.WorkspaceID)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewInternal(err))
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"token":         tokenString,
+		"workspaceId":   req.WorkspaceID,
+		"workspaceRole": role,
 	})
 }
 
+// writeLoginFailure は ログイン失敗を記録し、401 を返します
+// この失敗で上限に達した場合は、以降の試行と同じ 429 を返す
+func (h *AuthHandler) writeLoginFailure(w http.ResponseWriter, r *http.Request, email string, cause error) {
+	if h.throttle != nil {
+		if locked, remaining := h.throttle.RecordFailure(email, time.Now()); locked {
+			writeLoginLocked(w, r, remaining)
+			return
+		}
+	}
+
+	apierror.Write(w, r, apierror.NewUnauthorized(
+		"INVALID_CREDENTIALS", "メールアドレスまたはパスワードが正しくありません", cause,
+	))
+}
+
+// writeLoginLocked は ロック中のログイン試行に 429 と Retry-After を返します
+func writeLoginLocked(w http.ResponseWriter, r *http.Request, remaining time.Duration) {
+	seconds := cooldownSeconds(remaining)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	apierror.Write(w, r, apierror.NewTooManyRequests(
+		"LOGIN_LOCKED",
+		fmt.Sprintf("ログインの試行回数が多すぎます。%d秒後に再度お試しください", seconds),
+		nil,
+	))
+}
+
+// cooldownSeconds は 残り時間を秒単位に切り上げます
+func cooldownSeconds(remaining time.Duration) int {
+	return int((remaining + time.Second - 1) / time.Second)
+}
+
+// LoginStatusResponse は ログイン試行の制限状態です
+type LoginStatusResponse struct {
+	Locked            bool `json:"locked"`
+	RetryAfterSeconds int  `json:"retryAfterSeconds"`
+}
+
+// LoginStatus は メールアドレスが一時的にロックされているかと、解除までの残り秒数を返します
+// 失敗回数はアカウントの有無に関わらず数えているため、このレスポンスからアカウントの存在は分からない
+func (h *AuthHandler) LoginStatus(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_EMAIL", "email を指定してください", nil,
+		))
+		return
+	}
+
+	resp := LoginStatusResponse{}
+	if h.throttle != nil {
+		locked, remaining := h.throttle.Status(email, time.Now())
+		resp.Locked = locked
+		resp.RetryAfterSeconds = cooldownSeconds(remaining)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	apierror.WriteJSON(w, http.StatusOK, resp)
+}
+
+// rehashPassword は ユーザーのパスワードを現在の設定の argon2id で保存し直します
+func (h *AuthHandler) rehashPassword(ctx context.Context, user *models.User, plain string) {
+	hashed, err := h.hasher.Hash(plain)
+	if err != nil {
+		logctx.Printf(ctx, "failed to rehash password for user %d: %v", user.ID, err)
+		return
+	}
+	if err := h.userRepo.UpdatePassword(user.ID, hashed); err != nil {
+		logctx.Printf(ctx, "failed to store rehashed password for user %d: %v", user.ID, err)
+		return
+	}
+	user.PasswordHash = hashed
+}
+
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	// Cookieを削除するためにMaxAgeを-1に設定
 	cookie := h.createSecureCookie("auth_token", "", -1)
@@ -227,6 +483,8 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	}
 
 	apierror.WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"user": user,
+		"user":          user,
+		"workspaceId":   middleware.GetWorkspaceIDFromContext(r.Context()),
+		"workspaceRole": middleware.GetWorkspaceRoleFromContext(r.Context()),
 	})
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,8 +17,15 @@ import (
 	"simple-notion-backend/internal/config"
 	"simple-notion-backend/internal/middleware"
 	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/password"
+	"simple-notion-backend/internal/services"
 )
 
+// newTestHasher は テストを高速にするため低コストの argon2id パラメータで Hasher を作成します
+func newTestHasher() *password.Hasher {
+	return password.NewHasher(password.Params{Memory: 1024, Iterations: 1, Parallelism: 1})
+}
+
 // createTestConfig は セキュリティ設定を無効にしたテスト用設定を作成します
 func createTestConfig() *config.Config {
 	return &config.Config{
@@ -90,6 +98,18 @@ func (m *MockUserRepository) Update(user *models.User) error {
 	return nil
 }
 
+func (m *MockUserRepository) UpdatePassword(userID int, passwordHash string) error {
+	user, exists := m.usersByID[userID]
+	if !exists {
+		return fmt.Errorf("user id=%d: %w", userID, apierror.ErrNotFound)
+	}
+
+	user.PasswordHash = passwordHash
+	user.UpdatedAt = time.Now()
+
+	return nil
+}
+
 // decodeErrorResponse はレスポンスボディを apierror.ErrorResponse にデコードする
 func decodeErrorResponse(t *testing.T, body *bytes.Buffer) apierror.ErrorResponse {
 	t.Helper()
@@ -105,7 +125,7 @@ func TestAuthHandler_Login(t *testing.T) {
 	mockRepo := NewMockUserRepository()
 	jwtSecret := []byte("test-secret-key")
 	testConfig := createTestConfig()
-	handler := NewAuthHandler(mockRepo, jwtSecret, testConfig)
+	handler := NewAuthHandler(mockRepo, newTestHasher(), nil, nil, nil, nil, jwtSecret, testConfig)
 
 	// テストユーザーの作成
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
@@ -160,6 +180,25 @@ func TestAuthHandler_Login(t *testing.T) {
 		if authCookie == nil {
 			t.Error("Expected auth_token cookie to be set")
 		}
+
+		// bcrypt のハッシュはログイン成功時に argon2id へ再ハッシュされる
+		if !strings.HasPrefix(testUser.PasswordHash, "$argon2id$") {
+			t.Errorf("Expected password to be rehashed with argon2id, got %q", testUser.PasswordHash)
+		}
+	})
+
+	t.Run("successful login after rehash", func(t *testing.T) {
+		body, _ := json.Marshal(LoginRequest{Email: "test@example.com", Password: "password123"})
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Login(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code 200, got %d", w.Code)
+		}
 	})
 
 	t.Run("invalid email", func(t *testing.T) {
@@ -224,7 +263,7 @@ func TestAuthHandler_Register(t *testing.T) {
 	mockRepo := NewMockUserRepository()
 	jwtSecret := []byte("test-secret-key")
 	testConfig := createTestConfig()
-	handler := NewAuthHandler(mockRepo, jwtSecret, testConfig)
+	handler := NewAuthHandler(mockRepo, newTestHasher(), nil, nil, nil, nil, jwtSecret, testConfig)
 
 	t.Run("successful registration", func(t *testing.T) {
 		registerReq := RegisterRequest{
@@ -331,11 +370,82 @@ func TestAuthHandler_Register(t *testing.T) {
 	})
 }
 
+func TestAuthHandler_LoginThrottle(t *testing.T) {
+	mockRepo := NewMockUserRepository()
+	hasher := newTestHasher()
+	throttle := services.NewLoginThrottle(2, 15*time.Minute, 10*time.Minute)
+	handler := NewAuthHandler(mockRepo, hasher, throttle, nil, nil, nil, []byte("test-secret-key"), createTestConfig())
+
+	hashedPassword, _ := hasher.Hash("password123")
+	testUser := &models.User{ID: 1, Email: "test@example.com", PasswordHash: hashedPassword}
+	mockRepo.users[testUser.Email] = testUser
+	mockRepo.usersByID[testUser.ID] = testUser
+
+	login := func(email, password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(LoginRequest{Email: email, Password: password})
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Login(w, req)
+		return w
+	}
+	loginStatus := func(email string) LoginStatusResponse {
+		req := httptest.NewRequest(http.MethodGet, "/auth/login-status?email="+email, nil)
+		w := httptest.NewRecorder()
+		handler.LoginStatus(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code 200, got %d", w.Code)
+		}
+		var resp LoginStatusResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	// 存在するアカウントと存在しないアカウントで同じ結果になる
+	for _, email := range []string{"test@example.com", "nobody@example.com"} {
+		if w := login(email, "wrong"); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: Expected status code 401, got %d", email, w.Code)
+		}
+		if resp := loginStatus(email); resp.Locked {
+			t.Errorf("%s: Expected not locked after 1 failure", email)
+		}
+
+		w := login(email, "wrong")
+		if w.Code != http.StatusTooManyRequests {
+			t.Errorf("%s: Expected status code 429, got %d", email, w.Code)
+		}
+		if resp := decodeErrorResponse(t, w.Body); resp.Error != "LOGIN_LOCKED" {
+			t.Errorf("%s: Expected error code LOGIN_LOCKED, got %s", email, resp.Error)
+		}
+		if w.Header().Get("Retry-After") != "600" {
+			t.Errorf("%s: Expected Retry-After 600, got %q", email, w.Header().Get("Retry-After"))
+		}
+
+		resp := loginStatus(email)
+		if !resp.Locked || resp.RetryAfterSeconds <= 0 || resp.RetryAfterSeconds > 600 {
+			t.Errorf("%s: Expected locked with cooldown, got %+v", email, resp)
+		}
+	}
+
+	// ロック中は正しいパスワードでもログインできない
+	if w := login("test@example.com", "password123"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status code 429 while locked, got %d", w.Code)
+	}
+
+	t.Run("email is required", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/auth/login-status", nil)
+		w := httptest.NewRecorder()
+		handler.LoginStatus(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code 400, got %d", w.Code)
+		}
+	})
+}
+
 func TestAuthHandler_Logout(t *testing.T) {
 	mockRepo := NewMockUserRepository()
 	jwtSecret := []byte("test-secret-key")
 	testConfig := createTestConfig()
-	handler := NewAuthHandler(mockRepo, jwtSecret, testConfig)
+	handler := NewAuthHandler(mockRepo, newTestHasher(), nil, nil, nil, nil, jwtSecret, testConfig)
 
 	t.Run("successful logout", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
@@ -368,7 +478,7 @@ func TestAuthHandler_Me(t *testing.T) {
 	mockRepo := NewMockUserRepository()
 	jwtSecret := []byte("test-secret-key")
 	testConfig := createTestConfig()
-	handler := NewAuthHandler(mockRepo, jwtSecret, testConfig)
+	handler := NewAuthHandler(mockRepo, newTestHasher(), nil, nil, nil, nil, jwtSecret, testConfig)
 
 	// テストユーザーの作成
 	testUser := &models.User{
@@ -447,7 +557,7 @@ func TestCookieSecuritySettings(t *testing.T) {
 			CookieSameSite: "lax",
 			CookieDomain:   "",
 		}
-		handler := NewAuthHandler(mockRepo, jwtSecret, devConfig)
+		handler := NewAuthHandler(mockRepo, newTestHasher(), nil, nil, nil, nil, jwtSecret, devConfig)
 
 		// テストユーザーの作成
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
@@ -498,7 +608,7 @@ func TestCookieSecuritySettings(t *testing.T) {
 			CookieSameSite: "strict",
 			CookieDomain:   "example.com",
 		}
-		handler := NewAuthHandler(mockRepo, jwtSecret, prodConfig)
+		handler := NewAuthHandler(mockRepo, newTestHasher(), nil, nil, nil, nil, jwtSecret, prodConfig)
 
 		// テストユーザーの作成
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
@@ -547,7 +657,7 @@ func TestCookieSecuritySettings(t *testing.T) {
 
 	t.Run("logout cookie deletion", func(t *testing.T) {
 		testConfig := createTestConfig()
-		handler := NewAuthHandler(mockRepo, jwtSecret, testConfig)
+		handler := NewAuthHandler(mockRepo, newTestHasher(), nil, nil, nil, nil, jwtSecret, testConfig)
 
 		req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
 		w := httptest.NewRecorder()
@@ -0,0 +1,271 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"simple-notion-backend/internal/apierror"
+)
+
+const (
+	// MaxRequests は 1回のバッチで実行できるリクエストの最大数です
+	MaxRequests = 20
+	// maxRequestSize は バッチのリクエストの本文の最大サイズ（バイト）です
+	maxRequestSize = 4 << 20
+	// maxResponseSize は 個々のリクエストのレスポンスの本文の最大サイズ（バイト）です
+	maxResponseSize = 1 << 20
+	// batchPath は バッチのエンドポイントです（入れ子にできないよう、個々のリクエストには指定できない）
+	batchPath = "/api/batch"
+)
+
+// unbatchablePaths は バッチに含められないエンドポイントのパス（path.Match の形式）です
+// 接続を保持し続けるストリーミングと、ファイルの本文を返すエクスポート・ダウンロードはメモリに記録できないため除く
+var unbatchablePaths = []string{
+	"/api/documents/events",
+	"/api/upload/progress/*",
+	"/api/documents/*/export",
+	"/api/files/export",
+	"/api/files/*/download",
+	"/api/uploads/*",
+	"/api/storage/objects/*",
+}
+
+// errResponseTooLarge は 個々のリクエストのレスポンスが maxResponseSize を超えたエラーです
+var errResponseTooLarge = errors.New("batch: response body too large")
+
+// dispatchedContextKey は バッチから実行したリクエストのコンテキストに付ける目印のキーです
+type dispatchedContextKey struct{}
+
+// Request は バッチに含める個々のリクエストです
+type Request struct {
+	// ID は 結果と対応付けるための任意の文字列です（省略可）
+	ID     string          `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"` // クエリ文字列を含めてもよい（例: /api/documents?limit=10）
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Result は 個々のリクエストの結果です
+type Result struct {
+	ID     string `json:"id,omitempty"`
+	Status int    `json:"status"`
+	// Body は レスポンスの本文です（JSON の場合はそのまま、それ以外は文字列、本文がない場合は省略する）
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchHandler は 複数の API リクエストを1回の HTTP リクエストでまとめて実行するHTTPハンドラーです
+// 文書の保存・ファイルの紐づけ・並び替えのように続けて行う変更を、エディタから1回の通信で送れるようにする
+type BatchHandler struct {
+	dispatcher http.Handler
+}
+
+// NewBatchHandler は 新しい BatchHandler インスタンスを作成します
+// dispatcher: 個々のリクエストを処理するハンドラー（ルーター全体。認証・頻度の制限もリクエストごとに適用される）
+func NewBatchHandler(dispatcher http.Handler) *BatchHandler {
+	return &BatchHandler{
+		dispatcher: dispatcher,
+	}
+}
+
+// Execute は リクエストを順に実行し、リクエストごとの結果を返します
+// リクエスト: {"requests":[{"id":"save","method":"PUT","path":"/api/documents/1","body":{...}}],"stopOnError":false}
+// 失敗したリクエストがあっても残りは実行する（stopOnError が true の場合は以降を実行せず 424 を返す）
+// 全体をトランザクションにはしない（実行済みのリクエストの変更は、後のリクエストが失敗しても戻さない）
+func (h *BatchHandler) Execute(w http.ResponseWriter, r *http.Request) {
+	// パスの検証をすり抜けてバッチからバッチを呼び出した場合も、入れ子にはしない
+	if r.Context().Value(dispatchedContextKey{}) != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_BATCH_PATH", "バッチの中でバッチは実行できません", nil,
+		))
+		return
+	}
+
+	var req struct {
+		Requests    []Request `json:"requests"`
+		StopOnError bool      `json:"stopOnError"`
+	}
+	body := http.MaxBytesReader(w, r.Body, maxRequestSize)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+	if err := validateRequests(req.Requests); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	results := make([]Result, 0, len(req.Requests))
+	failed := false
+	for _, sub := range req.Requests {
+		if failed && req.StopOnError {
+			results = append(results, Result{ID: sub.ID, Status: http.StatusFailedDependency})
+			continue
+		}
+		result := h.dispatch(r, sub)
+		results = append(results, result)
+		if result.Status >= 400 {
+			failed = true
+		}
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"results": results,
+	})
+}
+
+// validateRequests は バッチに含めるリクエストを検証します
+func validateRequests(requests []Request) error {
+	if len(requests) == 0 {
+		return apierror.NewValidationError("BATCH_EMPTY", "requests を指定してください", nil)
+	}
+	if len(requests) > MaxRequests {
+		return apierror.NewValidationError("BATCH_TOO_LARGE", fmt.Sprintf("一度に実行できるリクエストは%d件までです", MaxRequests), nil)
+	}
+
+	for i, sub := range requests {
+		switch strings.ToUpper(sub.Method) {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return apierror.NewValidationError("INVALID_BATCH_METHOD", "method が不正です", nil).
+				WithDetails(map[string]int{"index": i})
+		}
+
+		// ルーターと同じくパーセントエンコーディングを戻したパスで検証する（/api/%62atch などで入れ子にさせない）
+		u, err := url.ParseRequestURI(sub.Path)
+		if err != nil || u.IsAbs() || u.Host != "" ||
+			!strings.HasPrefix(u.Path, "/api/") || strings.Contains(u.Path, "..") ||
+			path.Clean(u.Path) == batchPath {
+			return apierror.NewValidationError("INVALID_BATCH_PATH", "path には /api/ 以下のエンドポイントを指定してください", nil).
+				WithDetails(map[string]int{"index": i})
+		}
+		if unbatchable(u.Path) {
+			return apierror.NewValidationError("UNBATCHABLE_PATH", "ストリーミング・エクスポートのエンドポイントはバッチでは実行できません", nil).
+				WithDetails(map[string]int{"index": i})
+		}
+	}
+	return nil
+}
+
+// unbatchable は パスがバッチに含められないエンドポイントかを返します
+func unbatchable(p string) bool {
+	p = path.Clean(p)
+	for _, pattern := range unbatchablePaths {
+		// 末尾の * はファイル名など / を含む残りのパスにも一致させる
+		if matched, _ := path.Match(pattern, p); matched ||
+			(strings.HasSuffix(pattern, "/*") && strings.HasPrefix(p, strings.TrimSuffix(pattern, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch は 元のリクエストの認証情報（Cookie・Authorization ヘッダー）を引き継いで、1つのリクエストを実行します
+func (h *BatchHandler) dispatch(parent *http.Request, sub Request) Result {
+	var body []byte
+	if len(sub.Body) > 0 && string(sub.Body) != "null" {
+		body = sub.Body
+	}
+
+	ctx := context.WithValue(parent.Context(), dispatchedContextKey{}, true)
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(sub.Method), sub.Path, bytes.NewReader(body))
+	if err != nil {
+		return errorResult(parent.Context(), sub.ID, apierror.NewValidationError(
+			"INVALID_BATCH_PATH", "path が不正です", err,
+		))
+	}
+	req.Header = parent.Header.Clone()
+	req.Header.Del("Content-Length")
+	req.Header.Del("Accept-Encoding")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Del("Content-Type")
+	}
+	req.RemoteAddr = parent.RemoteAddr
+	req.Host = parent.Host
+
+	rec := newResponseRecorder()
+	h.dispatcher.ServeHTTP(rec, req)
+	// 上限を超えたレスポンスは返さない（リクエスト自体は実行済みのため、変更は戻らない）
+	if rec.tooLarge {
+		return errorResult(parent.Context(), sub.ID, apierror.NewPayloadTooLarge(
+			"BATCH_RESPONSE_TOO_LARGE",
+			fmt.Sprintf("レスポンスが%dバイトを超えるため、バッチでは返せません", maxResponseSize),
+			errResponseTooLarge,
+		))
+	}
+	return Result{ID: sub.ID, Status: rec.status, Body: responseBody(rec)}
+}
+
+// errorResult は リクエストを実行できなかった場合の結果を、統一エラーレスポンスの形式で作成します
+func errorResult(ctx context.Context, id string, err error) Result {
+	rec := newResponseRecorder()
+	req := (&http.Request{}).WithContext(ctx)
+	apierror.Write(rec, req, err)
+	return Result{ID: id, Status: rec.status, Body: responseBody(rec)}
+}
+
+// responseBody は 記録したレスポンスの本文を結果に含める形式に変換します
+func responseBody(rec *responseRecorder) json.RawMessage {
+	data := bytes.TrimSpace(rec.body.Bytes())
+	if len(data) == 0 {
+		return nil
+	}
+	if isJSONContentType(rec.header.Get("Content-Type")) && json.Valid(data) {
+		return data
+	}
+	encoded, _ := json.Marshal(string(data))
+	return encoded
+}
+
+// isJSONContentType は Content-Type が JSON（application/json・application/problem+json など）かを返します
+func isJSONContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// responseRecorder は 個々のリクエストのレスポンスをメモリに記録する ResponseWriter です
+// Flush は実装しない（Server-Sent Events のエンドポイントは最初の Flush で終了し、バッチを止めない）
+// 本文が maxResponseSize を超える書き込みはエラーを返し、それ以上は記録しない
+type responseRecorder struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	tooLarge    bool
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	if r.tooLarge || r.body.Len()+len(data) > maxResponseSize {
+		r.tooLarge = true
+		return 0, errResponseTooLarge
+	}
+	return r.body.Write(data)
+}
@@ -0,0 +1,258 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+)
+
+// newTestDispatcher は 呼び出し順・本文・Cookie を返すテスト用のルーターを作成します
+func newTestDispatcher(calls *[]string) http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/documents/{id}", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		cookie, _ := r.Cookie("auth_token")
+		token := ""
+		if cookie != nil {
+			token = cookie.Value
+		}
+		apierror.WriteJSON(w, http.StatusOK, map[string]string{
+			"id":    mux.Vars(r)["id"],
+			"body":  string(body),
+			"token": token,
+			"q":     r.URL.Query().Get("q"),
+		})
+	}).Methods("GET", "PUT")
+	router.HandleFunc("/api/text", func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "hello")
+	}).Methods("GET")
+	router.HandleFunc("/api/empty", func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("DELETE")
+	router.HandleFunc("/api/large", func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "text/plain")
+		chunk := strings.Repeat("a", 64<<10)
+		for written := 0; written <= maxResponseSize; written += len(chunk) {
+			if _, err := io.WriteString(w, chunk); err != nil {
+				return
+			}
+		}
+	}).Methods("GET")
+	router.HandleFunc("/api/fail", func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		apierror.Write(w, r, apierror.NewConflict("CONFLICT", "競合しています", nil))
+	}).Methods("POST")
+	return router
+}
+
+// executeBatch は バッチを実行し、ステータスコードと結果を返します
+func executeBatch(t *testing.T, handler *BatchHandler, body string) (int, []Result) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(body))
+	req.AddCookie(&http.Cookie{Name: "auth_token", Value: "token-1"})
+	w := httptest.NewRecorder()
+
+	handler.Execute(w, req)
+
+	var resp struct {
+		Results []Result `json:"results"`
+	}
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("JSON デコード失敗: %v", err)
+		}
+	}
+	return w.Code, resp.Results
+}
+
+// TestBatchHandlerExecute - リクエストの順次実行と結果のテスト
+func TestBatchHandlerExecute(t *testing.T) {
+	t.Run("正常系：順に実行し、リクエストごとの結果を返す", func(t *testing.T) {
+		var calls []string
+		handler := NewBatchHandler(newTestDispatcher(&calls))
+
+		status, results := executeBatch(t, handler, `{"requests":[
+			{"id":"save","method":"PUT","path":"/api/documents/1","body":{"title":"a"}},
+			{"method":"get","path":"/api/documents/2?q=x"},
+			{"method":"GET","path":"/api/text"},
+			{"method":"DELETE","path":"/api/empty"},
+			{"method":"GET","path":"/api/missing"}
+		]}`)
+
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want 200", status)
+		}
+		wantCalls := []string{"PUT /api/documents/1", "GET /api/documents/2", "GET /api/text", "DELETE /api/empty"}
+		if fmt.Sprint(calls) != fmt.Sprint(wantCalls) {
+			t.Errorf("calls = %v, want %v", calls, wantCalls)
+		}
+		if len(results) != 5 {
+			t.Fatalf("results = %d, want 5", len(results))
+		}
+
+		var saved map[string]string
+		if err := json.Unmarshal(results[0].Body, &saved); err != nil {
+			t.Fatalf("results[0].Body = %s: %v", results[0].Body, err)
+		}
+		if results[0].ID != "save" || results[0].Status != http.StatusOK ||
+			saved["body"] != `{"title":"a"}` || saved["token"] != "token-1" {
+			t.Errorf("results[0] = %+v (%v), want body and cookie forwarded", results[0], saved)
+		}
+
+		var got map[string]string
+		json.Unmarshal(results[1].Body, &got)
+		if got["id"] != "2" || got["q"] != "x" || got["body"] != "" {
+			t.Errorf("results[1].Body = %s, want id 2 with query", results[1].Body)
+		}
+		if string(results[2].Body) != `"hello"` {
+			t.Errorf("results[2].Body = %s, want text as JSON string", results[2].Body)
+		}
+		if results[3].Status != http.StatusNoContent || results[3].Body != nil {
+			t.Errorf("results[3] = %+v, want 204 without body", results[3])
+		}
+		if results[4].Status != http.StatusNotFound {
+			t.Errorf("results[4].Status = %d, want 404", results[4].Status)
+		}
+	})
+
+	t.Run("正常系：失敗しても残りを実行する", func(t *testing.T) {
+		var calls []string
+		handler := NewBatchHandler(newTestDispatcher(&calls))
+
+		_, results := executeBatch(t, handler, `{"requests":[
+			{"method":"POST","path":"/api/fail"},
+			{"method":"GET","path":"/api/text"}
+		]}`)
+
+		if len(calls) != 2 || results[0].Status != http.StatusConflict || results[1].Status != http.StatusOK {
+			t.Errorf("calls = %v, results = %+v, want both executed", calls, results)
+		}
+		var resp apierror.ErrorResponse
+		if err := json.Unmarshal(results[0].Body, &resp); err != nil || resp.Error != "CONFLICT" {
+			t.Errorf("results[0].Body = %s, want error response", results[0].Body)
+		}
+	})
+
+	t.Run("異常系：上限を超えるレスポンスは 413 の結果にする", func(t *testing.T) {
+		var calls []string
+		handler := NewBatchHandler(newTestDispatcher(&calls))
+
+		status, results := executeBatch(t, handler, `{"requests":[
+			{"id":"large","method":"GET","path":"/api/large"},
+			{"method":"GET","path":"/api/text"}
+		]}`)
+
+		if status != http.StatusOK || len(results) != 2 {
+			t.Fatalf("status = %d, results = %+v", status, results)
+		}
+		if results[0].ID != "large" || results[0].Status != http.StatusRequestEntityTooLarge {
+			t.Errorf("results[0] = %d, want 413", results[0].Status)
+		}
+		var resp apierror.ErrorResponse
+		if err := json.Unmarshal(results[0].Body, &resp); err != nil || resp.Error != "BATCH_RESPONSE_TOO_LARGE" {
+			t.Errorf("results[0].Body = %.100s, want error response", results[0].Body)
+		}
+		if results[1].Status != http.StatusOK {
+			t.Errorf("results[1].Status = %d, want 200", results[1].Status)
+		}
+	})
+
+	t.Run("正常系：stopOnError の場合は失敗以降を実行しない", func(t *testing.T) {
+		var calls []string
+		handler := NewBatchHandler(newTestDispatcher(&calls))
+
+		_, results := executeBatch(t, handler, `{"stopOnError":true,"requests":[
+			{"method":"POST","path":"/api/fail"},
+			{"id":"next","method":"GET","path":"/api/text"}
+		]}`)
+
+		if len(calls) != 1 {
+			t.Errorf("calls = %v, want only the first", calls)
+		}
+		if results[1].ID != "next" || results[1].Status != http.StatusFailedDependency {
+			t.Errorf("results[1] = %+v, want 424", results[1])
+		}
+	})
+}
+
+// TestBatchHandlerExecuteValidation - バッチの検証のテスト
+func TestBatchHandlerExecuteValidation(t *testing.T) {
+	tooMany := make([]string, MaxRequests+1)
+	for i := range tooMany {
+		tooMany[i] = `{"method":"GET","path":"/api/text"}`
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "異常系：本文が JSON でない", body: `not json`},
+		{name: "異常系：リクエストがない", body: `{"requests":[]}`},
+		{name: "異常系：リクエストが多すぎる", body: `{"requests":[` + strings.Join(tooMany, ",") + `]}`},
+		{name: "異常系：メソッドが不正", body: `{"requests":[{"method":"TRACE","path":"/api/text"}]}`},
+		{name: "異常系：/api/ 以外のパス", body: `{"requests":[{"method":"GET","path":"/metrics"}]}`},
+		{name: "異常系：絶対 URL", body: `{"requests":[{"method":"GET","path":"http://example.com/api/text"}]}`},
+		{name: "異常系：バッチの入れ子", body: `{"requests":[{"method":"POST","path":"/api/batch"}]}`},
+		{name: "異常系：親ディレクトリを含むパス", body: `{"requests":[{"method":"GET","path":"/api/../metrics"}]}`},
+		{name: "異常系：エンコードしたバッチのパス", body: `{"requests":[{"method":"POST","path":"/api/%62atch"}]}`},
+		{name: "異常系：末尾にスラッシュを重ねたバッチのパス", body: `{"requests":[{"method":"POST","path":"/api/batch//"}]}`},
+		{name: "異常系：エンコードした親ディレクトリ", body: `{"requests":[{"method":"GET","path":"/api/%2e%2e/metrics"}]}`},
+		{name: "異常系：変更の通知のストリーミング", body: `{"requests":[{"method":"GET","path":"/api/documents/events"}]}`},
+		{name: "異常系：アップロードの進捗のストリーミング", body: `{"requests":[{"method":"GET","path":"/api/upload/progress/abc"}]}`},
+		{name: "異常系：文書のエクスポート", body: `{"requests":[{"method":"GET","path":"/api/documents/1/export?format=pdf"}]}`},
+		{name: "異常系：ファイルのエクスポート", body: `{"requests":[{"method":"GET","path":"/api/files/export/"}]}`},
+		{name: "異常系：ファイルのダウンロード", body: `{"requests":[{"method":"GET","path":"/api/files/3/download"}]}`},
+		{name: "異常系：ストレージのオブジェクト", body: `{"requests":[{"method":"GET","path":"/api/storage/objects/images/1/a.png"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			handler := NewBatchHandler(newTestDispatcher(&calls))
+
+			status, _ := executeBatch(t, handler, tt.body)
+
+			if status != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", status)
+			}
+			if len(calls) != 0 {
+				t.Errorf("calls = %v, want none", calls)
+			}
+		})
+	}
+}
+
+// TestBatchHandlerExecuteNested - 検証をすり抜けたパスでもバッチを入れ子にしないことのテスト
+func TestBatchHandlerExecuteNested(t *testing.T) {
+	var calls []string
+	router := newTestDispatcher(&calls).(*mux.Router)
+	handler := NewBatchHandler(router)
+	// 別のパスからバッチのハンドラーに到達する場合を再現する
+	router.HandleFunc("/api/alias", handler.Execute).Methods("POST")
+
+	status, results := executeBatch(t, handler,
+		`{"requests":[{"method":"POST","path":"/api/alias","body":{"requests":[{"method":"GET","path":"/api/text"}]}}]}`)
+
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if len(results) != 1 || results[0].Status != http.StatusBadRequest {
+		t.Fatalf("results = %+v, want one 400 result", results)
+	}
+	if len(calls) != 0 {
+		t.Errorf("calls = %v, want none", calls)
+	}
+}
@@ -0,0 +1,109 @@
+package blocktype
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// BlockTypeHandler は カスタムブロックタイプ関連のHTTPハンドラーです
+type BlockTypeHandler struct {
+	blockTypeService *services.BlockTypeService
+}
+
+// NewBlockTypeHandler は 新しい BlockTypeHandler インスタンスを作成します
+func NewBlockTypeHandler(blockTypeService *services.BlockTypeService) *BlockTypeHandler {
+	return &BlockTypeHandler{
+		blockTypeService: blockTypeService,
+	}
+}
+
+// blockTypeRequest は カスタムブロックタイプの登録・更新リクエストです
+type blockTypeRequest struct {
+	Name           string          `json:"name"`
+	Description    string          `json:"description"`
+	Schema         json.RawMessage `json:"schema"`
+	ExportTemplate string          `json:"exportTemplate"`
+}
+
+// GetBlockTypes は 登録済みのカスタムブロックタイプ一覧を返します（エディタでの表示用）
+func (h *BlockTypeHandler) GetBlockTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := h.blockTypeService.ListBlockTypes()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, types)
+}
+
+// CreateBlockType は カスタムブロックタイプを登録します（管理者のみ）
+func (h *BlockTypeHandler) CreateBlockType(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req blockTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	bt := &models.CustomBlockType{
+		Name:           req.Name,
+		Description:    req.Description,
+		Schema:         req.Schema,
+		ExportTemplate: req.ExportTemplate,
+	}
+	// 同名のタイプが既にある場合は ErrConflict として 409 になる
+	if err := h.blockTypeService.RegisterBlockType(userID, bt); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, bt)
+}
+
+// UpdateBlockType は カスタムブロックタイプの定義を更新します（管理者のみ）
+func (h *BlockTypeHandler) UpdateBlockType(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var req blockTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	bt := &models.CustomBlockType{
+		Name:           name,
+		Description:    req.Description,
+		Schema:         req.Schema,
+		ExportTemplate: req.ExportTemplate,
+	}
+	if err := h.blockTypeService.UpdateBlockType(bt); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, bt)
+}
+
+// DeleteBlockType は カスタムブロックタイプを削除します（管理者のみ）
+func (h *BlockTypeHandler) DeleteBlockType(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if err := h.blockTypeService.DeleteBlockType(name); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Block type deleted successfully"})
+}
@@ -0,0 +1,171 @@
+package comment
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// DocumentCommentHandler は 文書全体へのコメント（スレッド）関連のHTTPハンドラーです
+type DocumentCommentHandler struct {
+	commentService *services.DocumentCommentService
+	mentions       *services.MentionService // nil の場合は @メンション を記録・通知しない
+}
+
+// NewDocumentCommentHandler は 新しい DocumentCommentHandler インスタンスを作成します
+func NewDocumentCommentHandler(commentService *services.DocumentCommentService, mentions *services.MentionService) *DocumentCommentHandler {
+	return &DocumentCommentHandler{
+		commentService: commentService,
+		mentions:       mentions,
+	}
+}
+
+// GetThreads は 文書のスレッド一覧を返信とともに返します
+// resolved（true / false）でスレッドの解決状態を絞り込める
+func (h *DocumentCommentHandler) GetThreads(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	var resolved *bool
+	if raw := r.URL.Query().Get("resolved"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			apierror.Write(w, r, apierror.NewValidationError(
+				"INVALID_REQUEST", "resolved には true または false を指定してください", err,
+			))
+			return
+		}
+		resolved = &value
+	}
+
+	threads, err := h.commentService.ListThreads(docID, userID, resolved)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, threads)
+}
+
+// CreateThread は 文書に新しいスレッドを作成します
+func (h *DocumentCommentHandler) CreateThread(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	body, appErr := decodeCommentBody(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	comment, err := h.commentService.CreateThread(docID, userID, body)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	syncCommentMentions(r.Context(), h.mentions, docID, userID, models.MentionSourceDocumentComment, comment.ID, nil, comment.Body)
+
+	apierror.WriteJSON(w, http.StatusCreated, comment)
+}
+
+// Reply は コメントに返信します（返信への返信はスレッドの先頭への返信になる）
+func (h *DocumentCommentHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, commentID, appErr := parseCommentPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	body, appErr := decodeCommentBody(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	comment, err := h.commentService.Reply(docID, userID, commentID, body)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	syncCommentMentions(r.Context(), h.mentions, docID, userID, models.MentionSourceDocumentComment, comment.ID, nil, comment.Body)
+
+	apierror.WriteJSON(w, http.StatusCreated, comment)
+}
+
+// UpdateComment は コメントの本文・スレッドの解決状態を更新します
+func (h *DocumentCommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, commentID, appErr := parseCommentPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var patch models.DocumentCommentPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(docID, userID, commentID, patch)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if patch.Body != nil {
+		syncCommentMentions(r.Context(), h.mentions, docID, userID, models.MentionSourceDocumentComment, comment.ID, nil, comment.Body)
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, comment)
+}
+
+// DeleteComment は コメントを削除します（スレッドの先頭を削除すると返信も削除される）
+func (h *DocumentCommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, commentID, appErr := parseCommentPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.commentService.DeleteComment(docID, userID, commentID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	syncCommentMentions(r.Context(), h.mentions, docID, userID, models.MentionSourceDocumentComment, commentID, nil, "")
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Comment deleted successfully"})
+}
+
+// decodeCommentBody は リクエストボディからコメント本文を取り出します
+func decodeCommentBody(r *http.Request) (string, *apierror.AppError) {
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return "", apierror.NewValidationError("INVALID_REQUEST", "リクエストボディが不正です", err)
+	}
+	return req.Body, nil
+}
@@ -0,0 +1,185 @@
+package comment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// CommentHandler は ブロックコメント関連のHTTPハンドラーです
+type CommentHandler struct {
+	commentService *services.BlockCommentService
+	mentions       *services.MentionService // nil の場合は @メンション を記録・通知しない
+}
+
+// NewCommentHandler は 新しい CommentHandler インスタンスを作成します
+func NewCommentHandler(commentService *services.BlockCommentService, mentions *services.MentionService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		mentions:       mentions,
+	}
+}
+
+// GetComments は 文書のコメント一覧を返します
+// blockId でブロックを、resolved（true / false）で解決状態を絞り込める
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	var filter models.BlockCommentFilter
+	query := r.URL.Query()
+	if raw := query.Get("blockId"); raw != "" {
+		blockID, err := strconv.Atoi(raw)
+		if err != nil {
+			apierror.Write(w, r, apierror.NewValidationError(
+				"INVALID_BLOCK_ID", "ブロックIDが不正です", err,
+			))
+			return
+		}
+		filter.BlockID = &blockID
+	}
+	if raw := query.Get("resolved"); raw != "" {
+		resolved, err := strconv.ParseBool(raw)
+		if err != nil {
+			apierror.Write(w, r, apierror.NewValidationError(
+				"INVALID_REQUEST", "resolved には true または false を指定してください", err,
+			))
+			return
+		}
+		filter.Resolved = &resolved
+	}
+
+	comments, err := h.commentService.ListComments(docID, userID, filter)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, comments)
+}
+
+// CreateComment は ブロックにコメントを追加します
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+	blockID, err := strconv.Atoi(vars["blockId"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_BLOCK_ID", "ブロックIDが不正です", err,
+		))
+		return
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(docID, userID, blockID, req.Body)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	syncCommentMentions(r.Context(), h.mentions, docID, userID, models.MentionSourceBlockComment, comment.ID, &comment.BlockID, comment.Body)
+
+	apierror.WriteJSON(w, http.StatusCreated, comment)
+}
+
+// UpdateComment は コメントの本文・解決状態を更新します
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, commentID, appErr := parseCommentPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var patch models.BlockCommentPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(docID, userID, commentID, patch)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if patch.Body != nil {
+		syncCommentMentions(r.Context(), h.mentions, docID, userID, models.MentionSourceBlockComment, comment.ID, &comment.BlockID, comment.Body)
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, comment)
+}
+
+// DeleteComment は コメントを削除します
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, commentID, appErr := parseCommentPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.commentService.DeleteComment(docID, userID, commentID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	syncCommentMentions(r.Context(), h.mentions, docID, userID, models.MentionSourceBlockComment, commentID, nil, "")
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Comment deleted successfully"})
+}
+
+// parseCommentPath は URL から文書IDとコメントIDを取り出します
+func parseCommentPath(r *http.Request) (docID, commentID int, appErr *apierror.AppError) {
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return 0, 0, apierror.NewValidationError("INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err)
+	}
+	commentID, err = strconv.Atoi(vars["commentId"])
+	if err != nil {
+		return 0, 0, apierror.NewValidationError("INVALID_COMMENT_ID", "コメントIDが不正です", err)
+	}
+	return docID, commentID, nil
+}
+
+// syncCommentMentions は 保存・削除したコメントの @メンション を記録し、新しくメンションされたユーザーに通知します
+// コメントの保存自体は完了しているため、失敗してもリクエストは成功として扱う
+func syncCommentMentions(ctx context.Context, mentions *services.MentionService, docID, userID int, sourceType models.MentionSourceType, commentID int, blockID *int, body string) {
+	if mentions == nil {
+		return
+	}
+	if err := mentions.SyncComment(ctx, docID, userID, sourceType, commentID, blockID, body); err != nil {
+		logctx.Printf(ctx, "failed to sync mentions for comment %d on document %d: %v", commentID, docID, err)
+	}
+}
@@ -0,0 +1,337 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/contentpolicy"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+)
+
+// CreateBlock は 文書にブロックを1件追加します
+// position を省略した場合は末尾に追加し、parent_block_id を指定した場合はそのブロックの子として追加する
+func (h *DocumentHandler) CreateBlock(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	var req struct {
+		Type          string          `json:"type"`
+		Content       json.RawMessage `json:"content"`
+		Position      *int            `json:"position"`
+		ParentBlockID *int            `json:"parent_block_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	block := models.Block{ParentBlockID: req.ParentBlockID, Type: req.Type, Content: req.Content, Position: -1}
+	if req.Position != nil {
+		block.Position = *req.Position
+	}
+
+	warnings, appErr := h.checkBlock(r, block)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.DocumentService.CreateBlock(docID, userID, &block); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h.recordSecretsDetected(r.Context(), userID, docID, warnings.secrets)
+	h.refreshSearchText(r.Context(), docID, userID)
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: docID})
+
+	apierror.WriteJSON(w, http.StatusCreated, blockResponse{
+		Block:    &block,
+		Warnings: warnings.all(),
+	})
+}
+
+// UpdateBlock は 文書内の単一ブロックを部分更新します
+// 指定されたフィールド（type / content / position）のみを変更する
+func (h *DocumentHandler) UpdateBlock(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, blockID, appErr := parseBlockPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var patch models.BlockPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	current, err := h.DocumentService.GetBlock(docID, userID, blockID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	block := patch.Apply(*current)
+	warnings, appErr := h.checkBlock(r, block)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.DocumentService.UpdateBlock(docID, userID, &block); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h.recordSecretsDetected(r.Context(), userID, docID, warnings.secrets)
+	h.refreshSearchText(r.Context(), docID, userID)
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: docID})
+
+	apierror.WriteJSON(w, http.StatusOK, blockResponse{
+		Block:    &block,
+		Warnings: warnings.all(),
+	})
+}
+
+// convertBlockRequest は ブロックの種類を変換するリクエストです
+type convertBlockRequest struct {
+	Type string `json:"type"`
+}
+
+// ConvertBlock は ブロックを互換性のある別の種類に変換します（例: text → heading1、text → todo、bullet → numbered）
+// content の移し替えはサーバー側の models.ConvertBlock で行い、変換後のブロックは通常の更新と同じく検証する
+func (h *DocumentHandler) ConvertBlock(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	blockID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError("INVALID_BLOCK_ID", "ブロックIDが不正です", err))
+		return
+	}
+
+	var req convertBlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	current, err := h.DocumentService.GetBlockByID(userID, blockID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	block, err := models.ConvertBlock(*current, req.Type)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INCOMPATIBLE_BLOCK_TYPE",
+			fmt.Sprintf("ブロックを %s から %s に変換できません", current.Type, req.Type),
+			err,
+		))
+		return
+	}
+
+	warnings, appErr := h.checkBlock(r, block)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.DocumentService.UpdateBlock(block.DocumentID, userID, &block); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h.recordSecretsDetected(r.Context(), userID, block.DocumentID, warnings.secrets)
+	h.refreshSearchText(r.Context(), block.DocumentID, userID)
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: block.DocumentID})
+
+	apierror.WriteJSON(w, http.StatusOK, blockResponse{
+		Block:    &block,
+		Warnings: warnings.all(),
+	})
+}
+
+// DeleteBlock は 文書内の単一ブロックを削除します
+func (h *DocumentHandler) DeleteBlock(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, blockID, appErr := parseBlockPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.DocumentService.DeleteBlock(docID, userID, blockID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h.refreshSearchText(r.Context(), docID, userID)
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: docID})
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Block deleted successfully"})
+}
+
+// GetBlockHistory は ブロックの種類・内容の変更履歴（変更前後の内容・編集者・日時）を新しい順に返します
+func (h *DocumentHandler) GetBlockHistory(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, blockID, appErr := parseBlockPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	events, err := h.DocumentService.GetBlockHistory(docID, userID, blockID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, events)
+}
+
+// SetBlockCollapsed は トグルブロックの開閉状態を閲覧しているユーザーごとに保存します
+func (h *DocumentHandler) SetBlockCollapsed(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, blockID, appErr := parseBlockPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var req struct {
+		Collapsed *bool `json:"collapsed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Collapsed == nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "collapsed を指定してください", err,
+		))
+		return
+	}
+
+	if err := h.DocumentService.SetBlockCollapsed(docID, userID, blockID, *req.Collapsed); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"blockId":   blockID,
+		"collapsed": *req.Collapsed,
+	})
+}
+
+// SyncBlock は ブロックを同期元として登録し、他の文書の synced ブロックから参照する syncId を返します
+// 同期元を編集すると、synced ブロックを含む文書の読み込み時に最新の内容が反映される
+func (h *DocumentHandler) SyncBlock(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, blockID, appErr := parseBlockPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	synced, err := h.DocumentService.CreateSyncedBlock(docID, userID, blockID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: docID})
+
+	apierror.WriteJSON(w, http.StatusCreated, synced)
+}
+
+// blockResponse は 保存後のブロックに、保存を妨げない警告を添えたレスポンスです
+type blockResponse struct {
+	*models.Block
+	Warnings []contentpolicy.Violation `json:"warnings,omitempty"`
+}
+
+// blockWarnings は 単一ブロック保存時の警告をポリシー違反と秘密情報に分けて保持します
+type blockWarnings struct {
+	policy  []contentpolicy.Violation
+	secrets []contentpolicy.Violation
+}
+
+func (bw blockWarnings) all() []contentpolicy.Violation {
+	return append(bw.policy, bw.secrets...)
+}
+
+// checkBlock は 文書全体の保存と同じ検証（リッチテキスト形式・コンテンツポリシー・秘密情報）を単一ブロックに適用します
+func (h *DocumentHandler) checkBlock(r *http.Request, block models.Block) (blockWarnings, *apierror.AppError) {
+	if strings.TrimSpace(block.Type) == "" {
+		return blockWarnings{}, apierror.NewValidationError("INVALID_BLOCK_TYPE", "ブロックの種類を指定してください", nil)
+	}
+	if len(block.Content) == 0 {
+		return blockWarnings{}, apierror.NewValidationError("INVALID_BLOCK_CONTENT", "ブロックの内容を指定してください", nil)
+	}
+
+	if limit := h.ContentLimits.MaxBlockBytes; limit > 0 && len(block.Content) > limit {
+		return blockWarnings{}, apierror.NewPayloadTooLarge(
+			"BLOCK_CONTENT_TOO_LARGE",
+			fmt.Sprintf("ブロック（%s）の内容が大きすぎます（%d / %d バイト）", block.Type, len(block.Content), limit),
+			nil,
+		).WithDetails(blockSizeError{BlockID: block.ID, Type: block.Type, Size: len(block.Content), Limit: limit})
+	}
+
+	registry, err := h.loadBlockTypeRegistry()
+	if err != nil {
+		return blockWarnings{}, apierror.NewInternal(err)
+	}
+	if verr := validateBlock(registry, block); verr != nil {
+		return blockWarnings{}, apierror.NewValidationError(
+			"INVALID_BLOCK_CONTENT",
+			fmt.Sprintf("ブロック（%s）の内容が不正です: %s %s", block.Type, verr.Path, verr.Message),
+			verr,
+		)
+	}
+
+	blocks := []models.Block{block}
+	policyResult := h.ContentPolicy.Evaluate(r.Context(), buildBlockSegments(blocks))
+	if policyResult.Blocked {
+		return blockWarnings{}, apierror.NewValidationError(
+			"CONTENT_POLICY_VIOLATION",
+			fmt.Sprintf("保存できない内容が含まれています（%s）", policyResult.Summary()),
+			nil,
+		)
+	}
+
+	return blockWarnings{
+		policy:  policyResult.Violations,
+		secrets: h.detectSecrets(r.Context(), blocks),
+	}, nil
+}
+
+// parseBlockPath は URL から文書IDとブロックIDを取り出します
+func parseBlockPath(r *http.Request) (docID, blockID int, appErr *apierror.AppError) {
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return 0, 0, apierror.NewValidationError("INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err)
+	}
+	blockID, err = strconv.Atoi(vars["blockId"])
+	if err != nil {
+		return 0, 0, apierror.NewValidationError("INVALID_BLOCK_ID", "ブロックIDが不正です", err)
+	}
+	return docID, blockID, nil
+}
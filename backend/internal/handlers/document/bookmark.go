@@ -0,0 +1,66 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+)
+
+// RefreshBookmarkPreview は ブックマークブロックのリンク先からプレビュー（OpenGraph のタイトル・説明・画像）を取得し、ブロックに保存します
+// 取得はサーバー側で行い、内部ネットワーク宛ての URL は拒否する（LinkPreviewService を参照）
+func (h *DocumentHandler) RefreshBookmarkPreview(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, blockID, appErr := parseBlockPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	block, err := h.DocumentService.GetBlock(docID, userID, blockID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if block.Type != "bookmark" {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"BLOCK_NOT_BOOKMARK", fmt.Sprintf("%s ブロックにはプレビューを取得できません", block.Type), nil,
+		))
+		return
+	}
+
+	bookmark, err := models.DecodeBookmarkContent(block.Content)
+	if err != nil || bookmark.URL == "" {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_BOOKMARK_URL", "ブックマークのURLが設定されていません", err,
+		))
+		return
+	}
+
+	preview, err := h.LinkPreviewService.Fetch(r.Context(), bookmark.URL)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	bookmark.ApplyPreview(preview)
+	content, err := json.Marshal(bookmark)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewInternal(err))
+		return
+	}
+	block.Content = content
+
+	if err := h.DocumentService.UpdateBlock(docID, userID, block); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h.refreshSearchText(r.Context(), docID, userID)
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: docID})
+
+	apierror.WriteJSON(w, http.StatusOK, blockResponse{Block: block})
+}
@@ -1,6 +1,7 @@
 package document
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -40,11 +41,43 @@ func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request)
 		Content:  req.Content,
 	}
 
+	// 子文書は親のワークスペースに、最上位の文書は選択中のワークスペースに作成される
+	workspaceID := middleware.GetWorkspaceIDFromContext(r.Context())
+	if req.ParentID != nil {
+		parent, err := h.DocumentService.GetDocument(*req.ParentID, userID)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		workspaceID = parent.WorkspaceID
+	}
+	doc.WorkspaceID = workspaceID
+	if workspaceID != nil && h.Workspaces != nil {
+		if err := h.Workspaces.CheckDocumentLimit(*workspaceID); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
 	// service 層から返る sentinel error は apierror.Write が自動で 404/409 等に変換する
 	if err := h.DocumentService.CreateDocument(doc); err != nil {
 		apierror.Write(w, r, err)
 		return
 	}
 
+	h.documentCreated(r.Context(), requestClientID(r), userID, doc)
+
 	apierror.WriteJSON(w, http.StatusCreated, doc)
 }
+
+// documentCreated は 文書の作成後の処理（検索用テキスト・メンション・ツリーの変更の通知）を行います
+// clientID: 変更を行ったタブ（HTTP 以外の API からの変更は空文字）
+func (h *DocumentHandler) documentCreated(ctx context.Context, clientID string, userID int, doc *models.Document) {
+	// タイトルは検索用の列に自動で反映されるため、本文がある場合のみテキストとメンションを保存する
+	if doc.Content != "" {
+		created := &models.DocumentWithBlocks{Document: *doc}
+		h.updateSearchText(ctx, created)
+		h.syncMentions(ctx, created, userID)
+	}
+	h.publishTree(ctx, clientID, userID, models.DocumentEvent{Type: models.DocumentEventCreated, DocumentID: doc.ID, ParentID: doc.ParentID})
+}
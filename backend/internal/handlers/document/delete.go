@@ -1,6 +1,7 @@
 package document
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
 
@@ -8,6 +9,7 @@ import (
 
 	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
 )
 
 func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
@@ -26,6 +28,7 @@ func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request)
 		apierror.Write(w, r, err)
 		return
 	}
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventDeleted, DocumentID: docID})
 
 	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Document deleted successfully"})
 }
@@ -46,10 +49,48 @@ func (h *DocumentHandler) RestoreDocument(w http.ResponseWriter, r *http.Request
 		apierror.Write(w, r, err)
 		return
 	}
+	h.publishTreeEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventRestored, DocumentID: docID})
 
 	w.WriteHeader(http.StatusOK)
 }
 
+// BulkRestoreDocuments は ごみ箱内の複数の文書を1つのトランザクションで復元します
+// リクエスト: {"ids":[1,2],"strategy":"restore-to-original-parent"}（strategy の既定は restore-to-original-parent）
+// 復元できなかった文書があっても他の文書は復元し、文書ごとの結果を返す
+func (h *DocumentHandler) BulkRestoreDocuments(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req struct {
+		IDs      []int                  `json:"ids"`
+		Strategy models.RestoreStrategy `json:"strategy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	results, err := h.DocumentService.BulkRestoreDocuments(userID, req.IDs, req.Strategy)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	restored := 0
+	for _, result := range results {
+		if result.Restored {
+			restored++
+			h.publishTreeEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventRestored, DocumentID: result.ID, ParentID: result.ParentID})
+		}
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"restored": restored,
+		"results":  results,
+	})
+}
+
 func (h *DocumentHandler) PermanentDeleteDocument(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserIDFromContext(r.Context())
 	vars := mux.Vars(r)
@@ -66,6 +107,7 @@ func (h *DocumentHandler) PermanentDeleteDocument(w http.ResponseWriter, r *http
 		apierror.Write(w, r, err)
 		return
 	}
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventDeleted, DocumentID: docID, Permanent: true})
 
 	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Document permanently deleted"})
 }
@@ -0,0 +1,75 @@
+package document
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+)
+
+// GetDocumentVersions は 文書のバージョン一覧を返します
+func (h *DocumentHandler) GetDocumentVersions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	versions, err := h.VersionService.ListVersions(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, versions)
+}
+
+// GetDocumentDiff は 2つのバージョン間のブロック差分を返します
+// to を省略した場合は現在の文書との差分を返す
+func (h *DocumentHandler) GetDocumentDiff(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil || from < 1 {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_VERSION", "from には1以上のバージョン番号を指定してください", err,
+		))
+		return
+	}
+
+	var to *int
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		toVersion, err := strconv.Atoi(toStr)
+		if err != nil || toVersion < 1 {
+			apierror.Write(w, r, apierror.NewValidationError(
+				"INVALID_VERSION", "to には1以上のバージョン番号を指定してください", err,
+			))
+			return
+		}
+		to = &toVersion
+	}
+
+	// 存在しない文書・バージョンは ErrNotFound として 404 になる
+	diff, err := h.VersionService.DiffVersions(docID, userID, from, to)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, diff)
+}
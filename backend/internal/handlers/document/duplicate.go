@@ -0,0 +1,244 @@
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// DuplicateBlock は 文書内のブロックを複製し、元のブロックの直後に追加します
+// 画像・ファイルブロックは files で元のファイルを参照する（reference、既定）か、複製したブロック専用のファイルにする（copy）か、
+// 紐付け先を複製したブロックに付け替える（relink）かを選べる
+func (h *DocumentHandler) DuplicateBlock(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, blockID, appErr := parseBlockPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	// ボディは省略可能
+	var req struct {
+		Files models.FileDuplicateMode `json:"files"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+	mode, appErr := parseFileDuplicateMode(req.Files, models.FileDuplicateReference)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	source, err := h.DocumentService.GetBlock(docID, userID, blockID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	blocks := []models.Block{source.Duplicate()}
+
+	files, err := h.prepareBlockFiles(r.Context(), userID, blocks, mode)
+	if err != nil {
+		writeFileError(w, r, err)
+		return
+	}
+
+	if err := h.DocumentService.CreateBlock(docID, userID, &blocks[0]); err != nil {
+		files.discard(r.Context(), h.FileService, userID)
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if err := files.attach(r.Context(), h.FileService, userID, docID, blocks); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h.refreshSearchText(r.Context(), docID, userID)
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: docID})
+
+	apierror.WriteJSON(w, http.StatusCreated, blockResponse{Block: &blocks[0]})
+}
+
+// CopyBlocks は 別の文書（同じ文書も可）のブロックを子孫ごと複製し、文書の指定位置に貼り付けます
+// 画像・ファイルブロックのファイルは files で扱いを選べる（既定は copy。切り取って貼り付ける場合は relink）
+func (h *DocumentHandler) CopyBlocks(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	var req struct {
+		SourceDocumentID int                      `json:"sourceDocumentId"`
+		BlockIDs         []int                    `json:"blockIds"`
+		Position         *int                     `json:"position"`
+		ParentBlockID    *int                     `json:"parent_block_id"`
+		Files            models.FileDuplicateMode `json:"files"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SourceDocumentID == 0 || len(req.BlockIDs) == 0 {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "sourceDocumentId と blockIds を指定してください", err,
+		))
+		return
+	}
+	mode, appErr := parseFileDuplicateMode(req.Files, models.FileDuplicateCopy)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	source, err := h.DocumentService.GetDocumentWithBlocks(req.SourceDocumentID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	blocks, err := models.PlanBlockCopy(source.Blocks, req.BlockIDs)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError("INVALID_BLOCK_ID", "コピーするブロックが見つかりません", err))
+		return
+	}
+
+	position := -1
+	if req.Position != nil {
+		position = *req.Position
+	}
+
+	files, err := h.prepareBlockFiles(r.Context(), userID, blocks, mode)
+	if err != nil {
+		writeFileError(w, r, err)
+		return
+	}
+
+	if err := h.DocumentService.CopyBlocks(docID, userID, req.ParentBlockID, position, blocks); err != nil {
+		files.discard(r.Context(), h.FileService, userID)
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if err := files.attach(r.Context(), h.FileService, userID, docID, blocks); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h.refreshSearchText(r.Context(), docID, userID)
+	h.publishDocumentEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: docID})
+
+	apierror.WriteJSON(w, http.StatusCreated, map[string]interface{}{"blocks": blocks})
+}
+
+// parseFileDuplicateMode は 複製時のファイルの扱いを検証します（空の場合は fallback）
+func parseFileDuplicateMode(mode, fallback models.FileDuplicateMode) (models.FileDuplicateMode, *apierror.AppError) {
+	if mode == "" {
+		return fallback, nil
+	}
+	if !mode.Valid() {
+		return "", apierror.NewValidationError(
+			"INVALID_FILE_DUPLICATE_MODE",
+			fmt.Sprintf("files には %s / %s / %s のいずれかを指定してください",
+				models.FileDuplicateReference, models.FileDuplicateCopy, models.FileDuplicateRelink),
+			nil,
+		)
+	}
+	return mode, nil
+}
+
+// blockFiles は 複製するブロックごとに、保存後に紐付けるファイルです
+type blockFiles struct {
+	links  map[int]int // blocks のインデックス → ファイルID
+	copied []int       // copy で新しく作成したファイルID（ブロックの保存に失敗した場合に削除する）
+}
+
+// prepareBlockFiles は 複製する画像・ファイルブロックのファイルを mode に応じて準備します
+// copy の場合は新しいファイルを作成し、ブロックの content を新しいファイルの参照に書き換える
+func (h *DocumentHandler) prepareBlockFiles(ctx context.Context, userID int, blocks []models.Block, mode models.FileDuplicateMode) (*blockFiles, error) {
+	files := &blockFiles{links: make(map[int]int)}
+	if mode == models.FileDuplicateReference {
+		return files, nil
+	}
+
+	for i := range blocks {
+		fileID, ok := models.MediaFileID(blocks[i].Type, blocks[i].Content)
+		if !ok {
+			continue
+		}
+
+		if mode == models.FileDuplicateRelink {
+			files.links[i] = fileID
+			continue
+		}
+
+		copied, err := h.FileService.CopyFile(ctx, fileID, userID, middleware.GetWorkspaceIDFromContext(ctx), h.UserStorageQuota)
+		if err != nil {
+			files.discard(ctx, h.FileService, userID)
+			return nil, err
+		}
+		files.copied = append(files.copied, copied.ID)
+
+		content, err := models.WithFileReference(blocks[i].Type, blocks[i].Content, copied, uploadPath(copied.FileKey))
+		if err != nil {
+			files.discard(ctx, h.FileService, userID)
+			return nil, apierror.NewInternal(err)
+		}
+		blocks[i].Content = content
+		files.links[i] = copied.ID
+	}
+	return files, nil
+}
+
+// attach は 保存したブロックにファイルを紐付けます（file_metadata の document_id / block_id を更新する）
+func (bf *blockFiles) attach(ctx context.Context, fileService *services.FileService, userID, docID int, blocks []models.Block) error {
+	for i, fileID := range bf.links {
+		if err := fileService.AttachToBlock(ctx, fileID, userID, docID, blocks[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discard は copy で作成したファイルを削除します
+func (bf *blockFiles) discard(ctx context.Context, fileService *services.FileService, userID int) {
+	for _, fileID := range bf.copied {
+		_ = fileService.DeleteFile(ctx, fileID, userID)
+	}
+}
+
+// writeFileError は ファイルの準備に失敗したエラーを返します（クォータ超過は 413）
+func writeFileError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, services.ErrWorkspaceStorageQuotaExceeded) {
+		apierror.Write(w, r, apierror.NewPayloadTooLarge(
+			"WORKSPACE_QUOTA_EXCEEDED", "ワークスペースのストレージ容量の上限を超えています", err,
+		))
+		return
+	}
+	if errors.Is(err, services.ErrStorageQuotaExceeded) {
+		apierror.Write(w, r, apierror.NewPayloadTooLarge(
+			"QUOTA_EXCEEDED", "ストレージ容量の上限を超えています", err,
+		))
+		return
+	}
+	apierror.Write(w, r, err)
+}
+
+// uploadPath は アップロードしたファイルをブロックから参照するための相対パスを返します（アップロード API のレスポンスと同じ形式）
+func uploadPath(fileKey string) string {
+	return fmt.Sprintf("/api/uploads/%s", filepath.Base(fileKey))
+}
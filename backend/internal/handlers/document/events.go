@@ -0,0 +1,155 @@
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+)
+
+const (
+	// clientIDHeader は 変更を行ったタブを識別するヘッダーです（イベントの clientId として返す）
+	clientIDHeader = "X-Client-ID"
+	// maxClientIDLength は clientId として受け付ける最大の長さです
+	maxClientIDLength = 64
+	// documentEventHeartbeat は 接続を維持するためのコメントを送る間隔です
+	documentEventHeartbeat = 25 * time.Second
+	// documentEventRetry は 切断後にブラウザが再接続するまでの時間（ミリ秒）です
+	documentEventRetry = 3000
+)
+
+// StreamDocumentEvents は ユーザーの文書の変更（作成・更新・名前の変更・削除・復元・移動）を Server-Sent Events で送ります
+// WebSocket を使えないクライアント向けで、タブは開いている文書の変更を受け取ったら最新の内容を取得し直す
+// 受け取りが追いつかない場合はサーバーから切断する（再接続したタブは開いている文書を取得し直す）
+// clientId クエリを指定した場合、接続中はそのタブの文書を開いている記録を延長し、切断時に削除する
+func (h *DocumentHandler) StreamDocumentEvents(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	if h.DocumentEvents == nil {
+		apierror.Write(w, r, apierror.NewNotFound(
+			"DOCUMENT_EVENTS_DISABLED", "変更の通知は利用できません", nil,
+		))
+		return
+	}
+
+	// 接続中はずっと書き込むため、書き込みのタイムアウトを解除する
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		apierror.Write(w, r, apierror.NewInternal(fmt.Errorf("failed to clear write deadline: %w", err)))
+		return
+	}
+
+	events, unsubscribe := h.DocumentEvents.Subscribe(userID)
+	defer unsubscribe()
+
+	clientID := r.URL.Query().Get("clientId")
+	if len(clientID) > maxClientIDLength {
+		clientID = ""
+	}
+	if h.Presence != nil && clientID != "" {
+		defer h.Presence.LeaveClient(userID, clientID)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // リバースプロキシでバッファリングしない
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", documentEventRetry); err != nil {
+		return
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(documentEventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if h.Presence != nil && clientID != "" {
+				h.Presence.Touch(userID, clientID)
+			}
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// requestClientID は 変更を行ったタブの識別子を返します（ヘッダーがない・長すぎる場合は空文字）
+func requestClientID(r *http.Request) string {
+	if clientID := r.Header.Get(clientIDHeader); len(clientID) <= maxClientIDLength {
+		return clientID
+	}
+	return ""
+}
+
+// publishDocumentEvent は 文書の変更を同じユーザーの開いているタブに通知します
+// 内容の変更（updated）は文書の購読者にも知らせる
+func (h *DocumentHandler) publishDocumentEvent(r *http.Request, userID int, event models.DocumentEvent) {
+	h.publishEvent(r.Context(), requestClientID(r), userID, event)
+}
+
+// publishEvent は publishDocumentEvent の本体です（clientID: 変更を行ったタブ。HTTP 以外の API からの変更は空文字）
+func (h *DocumentHandler) publishEvent(ctx context.Context, clientID string, userID int, event models.DocumentEvent) {
+	if event.Type == models.DocumentEventUpdated {
+		h.notifyWatchers(ctx, event.DocumentID, userID)
+	}
+	if h.DocumentEvents == nil {
+		return
+	}
+	event.ClientID = clientID
+	event.OccurredAt = time.Now()
+	h.DocumentEvents.Publish(userID, event)
+}
+
+// publishTreeEvent は 文書ツリーの変更（作成・名前の変更・移動・復元）を、ツリーに反映する文書とともに通知します
+// 変更したユーザーに加えて、ツリーに文書を持つ所有者（共同編集者が名前を変更した場合）のタブにも送る
+func (h *DocumentHandler) publishTreeEvent(r *http.Request, userID int, event models.DocumentEvent) {
+	h.publishTree(r.Context(), requestClientID(r), userID, event)
+}
+
+// publishTree は publishTreeEvent の本体です
+func (h *DocumentHandler) publishTree(ctx context.Context, clientID string, userID int, event models.DocumentEvent) {
+	if h.DocumentEvents == nil {
+		return
+	}
+
+	node, err := h.DocumentService.GetDocumentTreeNode(event.DocumentID, userID)
+	if err != nil {
+		logctx.Printf(ctx, "failed to get tree node for document %d: %v", event.DocumentID, err)
+		h.publishEvent(ctx, clientID, userID, event)
+		return
+	}
+	event.Node = node
+	event.ParentID = node.ParentID
+
+	h.publishEvent(ctx, clientID, userID, event)
+	if node.UserID != userID {
+		h.publishEvent(ctx, clientID, node.UserID, event)
+	}
+}
@@ -0,0 +1,74 @@
+package document
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+func TestStreamDocumentEvents(t *testing.T) {
+	broker := services.NewDocumentEventBroker()
+	h := &DocumentHandler{DocumentEvents: broker}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.StreamDocumentEvents(w, r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, 1)))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	// 購読が始まってから変更を通知する（ヘッダーを受け取った時点で購読済み）
+	publish := httptest.NewRequest("DELETE", "/api/documents/5", nil)
+	publish.Header.Set(clientIDHeader, "tab-1")
+	h.publishDocumentEvent(publish, 1, models.DocumentEvent{Type: models.DocumentEventDeleted, DocumentID: 5})
+
+	scanner := bufio.NewScanner(resp.Body)
+	var eventName string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			eventName = name
+			continue
+		}
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+
+		var event models.DocumentEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("Unmarshal(%q) error = %v", data, err)
+		}
+		if eventName != models.DocumentEventDeleted || event.DocumentID != 5 || event.ClientID != "tab-1" || event.OccurredAt.IsZero() {
+			t.Errorf("event %q = %+v, want document.deleted for 5 from tab-1", eventName, event)
+		}
+		return
+	}
+	t.Fatalf("stream ended without event: %v", scanner.Err())
+}
+
+func TestPublishDocumentEvent_Disabled(t *testing.T) {
+	// DocumentEvents がない場合は何もしない
+	h := &DocumentHandler{}
+	h.publishDocumentEvent(httptest.NewRequest("PUT", "/api/documents/1", nil), 1, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: 1})
+}
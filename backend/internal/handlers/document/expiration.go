@@ -0,0 +1,88 @@
+package document
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+)
+
+// GetExpiration は 文書の有効期限を返します（未設定の場合は null）
+func (h *DocumentHandler) GetExpiration(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	exp, err := h.ExpirationService.GetExpiration(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, exp)
+}
+
+// UpdateExpiration は 文書の有効期限を設定します
+// 期限到達時に action（trash / delete、省略時は trash）が実行され、事前に通知が届く
+func (h *DocumentHandler) UpdateExpiration(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	var req struct {
+		ExpiresAt *time.Time              `json:"expiresAt"`
+		Action    models.ExpirationAction `json:"action"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ExpiresAt == nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "expiresAt を指定してください", err,
+		))
+		return
+	}
+
+	exp, err := h.ExpirationService.SetExpiration(docID, userID, *req.ExpiresAt, req.Action)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, exp)
+}
+
+// DeleteExpiration は 文書の有効期限を解除します
+func (h *DocumentHandler) DeleteExpiration(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	if err := h.ExpirationService.ClearExpiration(docID, userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Expiration cleared successfully"})
+}
@@ -0,0 +1,295 @@
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// ExportDocument は 文書を Markdown（既定）または HTML（?format=html）としてエクスポートします
+// カスタムブロックは登録されたエクスポート用テンプレートで描画する
+func (h *DocumentHandler) ExportDocument(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "markdown"
+	}
+	if format != "markdown" && format != "html" {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_EXPORT_FORMAT", "format には markdown / html のいずれかを指定してください", nil,
+		))
+		return
+	}
+
+	doc, err := h.DocumentService.GetDocumentWithBlocks(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	registry, err := h.loadBlockTypeRegistry()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if format == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="document-%d.html"`, docID))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(renderHTML(r.Context(), doc, registry)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="document-%d.md"`, docID))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(renderMarkdown(r.Context(), doc, registry)))
+}
+
+// renderMarkdown は 文書のタイトルと各ブロックを Markdown に変換します
+func renderMarkdown(ctx context.Context, doc *models.DocumentWithBlocks, registry *services.BlockTypeRegistry) string {
+	var sb strings.Builder
+	sb.WriteString("# " + doc.Title + "\n")
+
+	for _, block := range doc.Blocks {
+		sb.WriteString("\n")
+		sb.WriteString(renderBlockMarkdown(ctx, block, registry))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// renderBlockMarkdown は 1つのブロックを Markdown に変換します
+// テンプレートの描画に失敗したカスタムブロックはテキストとして出力する
+func renderBlockMarkdown(ctx context.Context, block models.Block, registry *services.BlockTypeRegistry) string {
+	if out, ok, err := registry.Export(block); err != nil {
+		logctx.Printf(ctx, "failed to export block %d: %v", block.ID, err)
+	} else if ok {
+		return out
+	}
+
+	switch block.Type {
+	case "heading1":
+		return "# " + blockPlainText(block)
+	case "heading2":
+		return "## " + blockPlainText(block)
+	case "heading3":
+		return "### " + blockPlainText(block)
+	case "bullet":
+		return "- " + blockPlainText(block)
+	case "numbered":
+		return "1. " + blockPlainText(block)
+	case "quote":
+		return "> " + strings.ReplaceAll(blockPlainText(block), "\n", "\n> ")
+	case "toggle":
+		return "▸ " + blockPlainText(block)
+	case "columnList", "column":
+		// 列レイアウト自体は出力せず、列の中のブロックを順に出力する
+		return ""
+	case "synced":
+		// 同期元の現在の内容を出力する
+		if block.Synced == nil {
+			return ""
+		}
+		return renderBlockMarkdown(ctx, block.Synced.Block(block), registry)
+	case "code":
+		code, err := models.DecodeCodeContent(block.Content)
+		if err != nil {
+			return ""
+		}
+		return codeFence(code.Content) + code.Language + "\n" + code.Content + "\n" + codeFence(code.Content)
+	case "todo":
+		var todo models.TodoContent
+		if err := json.Unmarshal(models.NormalizeBlockContent(block.Type, block.Content), &todo); err != nil {
+			return ""
+		}
+		mark := " "
+		if todo.Checked {
+			mark = "x"
+		}
+		line := fmt.Sprintf("- [%s] %s", mark, ExtractPlainTextFromRichText(todo.Text))
+		if todo.DueDate != "" {
+			line += " (期限: " + todo.DueDate + ")"
+		}
+		return line
+	case "table":
+		var table models.TableContent
+		if err := json.Unmarshal(models.NormalizeBlockContent(block.Type, block.Content), &table); err != nil {
+			return ""
+		}
+		return renderTableMarkdown(table)
+	case "image", "file", "audio":
+		var media struct {
+			Src          string `json:"src"`
+			Alt          string `json:"alt"`
+			OriginalName string `json:"originalName"`
+		}
+		if err := json.Unmarshal(block.Content, &media); err != nil || media.Src == "" {
+			return ""
+		}
+		if block.Type == "image" {
+			return fmt.Sprintf("![%s](%s)", media.Alt, media.Src)
+		}
+		return fmt.Sprintf("[%s](%s)", media.OriginalName, media.Src)
+	case "bookmark":
+		bookmark, err := models.DecodeBookmarkContent(block.Content)
+		if err != nil || bookmark.URL == "" {
+			return ""
+		}
+		line := fmt.Sprintf("[%s](%s)", bookmark.Label(), bookmark.URL)
+		if bookmark.Description != "" {
+			line += "\n> " + strings.ReplaceAll(bookmark.Description, "\n", "\n> ")
+		}
+		return line
+	case "embed":
+		embed, err := models.DecodeEmbedContent(block.Content)
+		if err != nil || embed.URL == "" {
+			return ""
+		}
+		return fmt.Sprintf("[%s](%s)", embed.Label(), embed.URL)
+	case "math":
+		math, err := models.DecodeMathContent(block.Content)
+		if err != nil || math.LaTeX == "" {
+			return ""
+		}
+		return "$$\n" + math.LaTeX + "\n$$"
+	default:
+		return blockPlainText(block)
+	}
+}
+
+// codeFence は コード内のバッククォートの連続より長いフェンスを返します（最低3つ）
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// renderTableMarkdown は 表を GFM 形式の表に変換します
+// 見出し行がない表は、Markdown の表に必要な見出し行を空欄で補う
+func renderTableMarkdown(table models.TableContent) string {
+	if len(table.Rows) == 0 {
+		return ""
+	}
+
+	rows := table.Rows
+	header := make([]string, len(rows[0]))
+	if table.Header {
+		header, rows = rows[0], rows[1:]
+	}
+
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+
+	lines := []string{tableRowMarkdown(header), tableRowMarkdown(separator)}
+	for _, row := range rows {
+		lines = append(lines, tableRowMarkdown(row))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableRowMarkdown は 表の1行を "| a | b |" 形式に変換します（セル内の | と改行はエスケープする）
+func tableRowMarkdown(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", "\\|")
+		escaped[i] = strings.ReplaceAll(cell, "\n", "<br>")
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+// blockPlainText は ブロックの content からプレーンテキストを取り出します
+// content は JSON 文字列（リッチテキストを含む）または TipTap JSON のどちらでもよい
+// 表と todo はセル・本文のテキストを、ブックマークと埋め込みはタイトル・URL などを取り出す
+func blockPlainText(block models.Block) string {
+	switch block.Type {
+	case "table":
+		var table models.TableContent
+		if err := json.Unmarshal(models.NormalizeBlockContent(block.Type, block.Content), &table); err != nil {
+			return ""
+		}
+		return table.PlainText()
+	case "todo":
+		var todo models.TodoContent
+		if err := json.Unmarshal(models.NormalizeBlockContent(block.Type, block.Content), &todo); err != nil {
+			return ""
+		}
+		return ExtractPlainTextFromRichText(todo.Text)
+	case "code":
+		code, err := models.DecodeCodeContent(block.Content)
+		if err != nil {
+			return ""
+		}
+		return code.Content
+	case "bookmark":
+		bookmark, err := models.DecodeBookmarkContent(block.Content)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(bookmark.Title + "\n" + bookmark.Description + "\n" + bookmark.URL)
+	case "embed":
+		embed, err := models.DecodeEmbedContent(block.Content)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(embed.Title + "\n" + embed.URL)
+	case "math":
+		math, err := models.DecodeMathContent(block.Content)
+		if err != nil {
+			return ""
+		}
+		return math.LaTeX
+	case "synced":
+		if block.Synced == nil {
+			return ""
+		}
+		return blockPlainText(block.Synced.Block(block))
+	case "columnList", "column":
+		return ""
+	}
+
+	raw := string(block.Content)
+
+	var text string
+	if err := json.Unmarshal(block.Content, &text); err == nil {
+		raw = text
+	}
+
+	return ExtractPlainTextFromRichText(raw)
+}
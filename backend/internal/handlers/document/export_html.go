@@ -0,0 +1,159 @@
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// renderHTML は 文書のタイトルと各ブロックを1つの HTML 文書に変換します
+// 利用者の入力はすべてエスケープし、スクリプトやスタイルは出力しない
+func renderHTML(ctx context.Context, doc *models.DocumentWithBlocks, registry *services.BlockTypeRegistry) string {
+	title := html.EscapeString(doc.Title)
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>" + title + "</title>\n</head>\n<body>\n")
+	sb.WriteString("<h1>" + title + "</h1>\n")
+
+	for _, block := range doc.Blocks {
+		if out := renderBlockHTML(ctx, block, registry); out != "" {
+			sb.WriteString(out)
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// renderBlockHTML は 1つのブロックを HTML に変換します
+// カスタムブロックはテンプレートの出力をテキストとして埋め込む
+func renderBlockHTML(ctx context.Context, block models.Block, registry *services.BlockTypeRegistry) string {
+	if out, ok, err := registry.Export(block); err != nil {
+		logctx.Printf(ctx, "failed to export block %d: %v", block.ID, err)
+	} else if ok {
+		return fmt.Sprintf(`<div class="block-%s">%s</div>`, html.EscapeString(block.Type), escapeHTMLText(out))
+	}
+
+	switch block.Type {
+	case "heading1", "heading2", "heading3":
+		level := strings.TrimPrefix(block.Type, "heading")
+		return fmt.Sprintf("<h%s>%s</h%s>", level, escapeHTMLText(blockPlainText(block)), level)
+	case "bullet":
+		return "<ul><li>" + escapeHTMLText(blockPlainText(block)) + "</li></ul>"
+	case "numbered":
+		return "<ol><li>" + escapeHTMLText(blockPlainText(block)) + "</li></ol>"
+	case "quote":
+		return "<blockquote>" + escapeHTMLText(blockPlainText(block)) + "</blockquote>"
+	case "columnList", "column":
+		return ""
+	case "synced":
+		if block.Synced == nil {
+			return ""
+		}
+		return renderBlockHTML(ctx, block.Synced.Block(block), registry)
+	case "code":
+		code, err := models.DecodeCodeContent(block.Content)
+		if err != nil {
+			return ""
+		}
+		if code.Language == "" {
+			return "<pre><code>" + html.EscapeString(code.Content) + "</code></pre>"
+		}
+		return fmt.Sprintf(`<pre><code class="language-%s">%s</code></pre>`,
+			html.EscapeString(code.Language), html.EscapeString(code.Content))
+	case "todo":
+		var todo models.TodoContent
+		if err := json.Unmarshal(models.NormalizeBlockContent(block.Type, block.Content), &todo); err != nil {
+			return ""
+		}
+		checked := ""
+		if todo.Checked {
+			checked = " checked"
+		}
+		return fmt.Sprintf(`<p><input type="checkbox" disabled%s> %s</p>`, checked, escapeHTMLText(ExtractPlainTextFromRichText(todo.Text)))
+	case "table":
+		var table models.TableContent
+		if err := json.Unmarshal(models.NormalizeBlockContent(block.Type, block.Content), &table); err != nil {
+			return ""
+		}
+		return renderTableHTML(table)
+	case "image", "file", "audio":
+		var media struct {
+			Src          string `json:"src"`
+			Alt          string `json:"alt"`
+			OriginalName string `json:"originalName"`
+		}
+		if err := json.Unmarshal(models.NormalizeBlockContent(block.Type, block.Content), &media); err != nil || media.Src == "" {
+			return ""
+		}
+		switch block.Type {
+		case "image":
+			return fmt.Sprintf(`<p><img src="%s" alt="%s"></p>`, html.EscapeString(media.Src), html.EscapeString(media.Alt))
+		case "audio":
+			return fmt.Sprintf(`<p><audio controls src="%s"></audio></p>`, html.EscapeString(media.Src))
+		}
+		return fmt.Sprintf(`<p><a href="%s">%s</a></p>`, html.EscapeString(media.Src), html.EscapeString(media.OriginalName))
+	case "bookmark":
+		bookmark, err := models.DecodeBookmarkContent(block.Content)
+		if err != nil || !bookmark.HasWebURL() {
+			return ""
+		}
+		out := fmt.Sprintf(`<p><a href="%s">%s</a>`, html.EscapeString(bookmark.URL), html.EscapeString(bookmark.Label()))
+		if bookmark.Description != "" {
+			out += "<br>" + escapeHTMLText(bookmark.Description)
+		}
+		return out + "</p>"
+	case "math":
+		// KaTeX / MathJax の自動描画が扱う \[...\] で囲む
+		math, err := models.DecodeMathContent(block.Content)
+		if err != nil || math.LaTeX == "" {
+			return ""
+		}
+		return `<div class="math">\[` + html.EscapeString(math.LaTeX) + `\]</div>`
+	case "embed":
+		// 保存済みの HTML はエクスポート先で iframe を読み込ませないよう出力せず、リンクにする
+		embed, err := models.DecodeEmbedContent(block.Content)
+		if err != nil || !embed.HasWebURL() {
+			return ""
+		}
+		return fmt.Sprintf(`<p><a href="%s">%s</a></p>`, html.EscapeString(embed.URL), html.EscapeString(embed.Label()))
+	default:
+		text := blockPlainText(block)
+		if text == "" {
+			return ""
+		}
+		return "<p>" + escapeHTMLText(text) + "</p>"
+	}
+}
+
+// renderTableHTML は 表を <table> に変換します（見出し行は <th> で出力する）
+func renderTableHTML(table models.TableContent) string {
+	var sb strings.Builder
+	sb.WriteString("<table>")
+	for i, row := range table.Rows {
+		cell := "td"
+		if i == 0 && table.Header {
+			cell = "th"
+		}
+		sb.WriteString("<tr>")
+		for _, value := range row {
+			sb.WriteString(fmt.Sprintf("<%s>%s</%s>", cell, escapeHTMLText(value), cell))
+		}
+		sb.WriteString("</tr>")
+	}
+	sb.WriteString("</table>")
+	return sb.String()
+}
+
+// escapeHTMLText は テキストをエスケープし、改行を <br> に変換します
+func escapeHTMLText(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
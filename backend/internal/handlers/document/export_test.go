@@ -0,0 +1,180 @@
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+func TestRenderBlockMarkdown_Table(t *testing.T) {
+	registry := services.NewBlockTypeRegistry()
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "見出し行あり",
+			content: `{"header":true,"rows":[["名前","メモ"],["a","x|y"]]}`,
+			want:    "| 名前 | メモ |\n| --- | --- |\n| a | x\\|y |",
+		},
+		{
+			name:    "見出し行なしは空欄で補う",
+			content: `{"rows":[["1","2"]]}`,
+			want:    "|  |  |\n| --- | --- |\n| 1 | 2 |",
+		},
+		{
+			name:    "JSON文字列の content",
+			content: `"{\"rows\":[[\"改行\\nあり\"]]}"`,
+			want:    "|  |\n| --- |\n| 改行<br>あり |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := models.Block{Type: "table", Content: json.RawMessage(tt.content)}
+			if got := renderBlockMarkdown(context.Background(), block, registry); got != tt.want {
+				t.Errorf("renderBlockMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderBlock_Code(t *testing.T) {
+	registry := services.NewBlockTypeRegistry()
+
+	tests := []struct {
+		name     string
+		content  string
+		markdown string
+		html     string
+	}{
+		{
+			name:     "言語あり",
+			content:  `{"language":"go","content":"if a < b {\n}"}`,
+			markdown: "```go\nif a < b {\n}\n```",
+			html:     `<pre><code class="language-go">if a &lt; b {` + "\n" + `}</code></pre>`,
+		},
+		{
+			name:     "従来の文字列",
+			content:  `"SELECT 1;"`,
+			markdown: "```\nSELECT 1;\n```",
+			html:     "<pre><code>SELECT 1;</code></pre>",
+		},
+		{
+			name:     "バッククォートを含むコードはフェンスを伸ばす",
+			content:  `{"language":"md","content":"` + "```" + `"}`,
+			markdown: "````md\n```\n````",
+			html:     `<pre><code class="language-md">` + "```" + `</code></pre>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := models.Block{Type: "code", Content: json.RawMessage(tt.content)}
+			if got := renderBlockMarkdown(context.Background(), block, registry); got != tt.markdown {
+				t.Errorf("renderBlockMarkdown() = %q, want %q", got, tt.markdown)
+			}
+			if got := renderBlockHTML(context.Background(), block, registry); got != tt.html {
+				t.Errorf("renderBlockHTML() = %q, want %q", got, tt.html)
+			}
+		})
+	}
+}
+
+func TestRenderBlock_Math(t *testing.T) {
+	registry := services.NewBlockTypeRegistry()
+	block := models.Block{Type: "math", Content: json.RawMessage(`"{\"latex\":\"a < \\\\frac{1}{2}\"}"`)}
+
+	if got, want := renderBlockMarkdown(context.Background(), block, registry), "$$\na < \\frac{1}{2}\n$$"; got != want {
+		t.Errorf("renderBlockMarkdown() = %q, want %q", got, want)
+	}
+	if got, want := renderBlockHTML(context.Background(), block, registry), `<div class="math">\[a &lt; \frac{1}{2}\]</div>`; got != want {
+		t.Errorf("renderBlockHTML() = %q, want %q", got, want)
+	}
+	if got, want := blockPlainText(block), `a < \frac{1}{2}`; got != want {
+		t.Errorf("blockPlainText() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderBlock_Synced(t *testing.T) {
+	registry := services.NewBlockTypeRegistry()
+	block := models.Block{
+		Type:    "synced",
+		Content: json.RawMessage(`{"syncId":3}`),
+		Synced:  &models.SyncedSource{SyncID: 3, DocumentID: 1, BlockID: 9, Type: "heading2", Content: json.RawMessage(`"同期元の見出し"`)},
+	}
+
+	if got, want := renderBlockMarkdown(context.Background(), block, registry), "## 同期元の見出し"; got != want {
+		t.Errorf("renderBlockMarkdown() = %q, want %q", got, want)
+	}
+	if got, want := renderBlockHTML(context.Background(), block, registry), "<h2>同期元の見出し</h2>"; got != want {
+		t.Errorf("renderBlockHTML() = %q, want %q", got, want)
+	}
+
+	// 同期元が削除されている場合は何も出力しない
+	block.Synced = nil
+	if got := renderBlockMarkdown(context.Background(), block, registry); got != "" {
+		t.Errorf("renderBlockMarkdown() = %q, want empty", got)
+	}
+}
+
+func TestRenderHTML_EscapesUserContent(t *testing.T) {
+	doc := &models.DocumentWithBlocks{
+		Document: models.Document{Title: "<script>alert(1)</script>"},
+		Blocks: []models.Block{
+			{Type: "text", Content: json.RawMessage(`"<img src=x onerror=alert(1)>"`)},
+			{Type: "image", Content: json.RawMessage(`{"src":"\"><script>","alt":"a"}`)},
+			{Type: "audio", Content: json.RawMessage(`{"src":"\"><script>","originalName":"memo.m4a"}`)},
+			{Type: "bookmark", Content: json.RawMessage(`{"url":"javascript:alert(1)","title":"x"}`)},
+			{Type: "embed", Content: json.RawMessage(`{"url":"https://www.youtube.com/watch?v=1","html":"<script>alert(1)</script>"}`)},
+		},
+	}
+
+	out := renderHTML(context.Background(), doc, services.NewBlockTypeRegistry())
+	if strings.Contains(out, "<script>") || strings.Contains(out, "<img src=x") || strings.Contains(out, "javascript:") {
+		t.Errorf("renderHTML() should escape user content, got %s", out)
+	}
+}
+
+func TestBlockPlainText(t *testing.T) {
+	tests := []struct {
+		name  string
+		block models.Block
+		want  string
+	}{
+		{
+			name:  "テキスト",
+			block: models.Block{Type: "text", Content: json.RawMessage(`"hello"`)},
+			want:  "hello",
+		},
+		{
+			name:  "表のセルを取り出す",
+			block: models.Block{Type: "table", Content: json.RawMessage(`{"rows":[["a","b"],["c","d"]]}`)},
+			want:  "a\tb\nc\td",
+		},
+		{
+			name:  "todo の本文",
+			block: models.Block{Type: "todo", Content: json.RawMessage(`{"text":"買い物","checked":false}`)},
+			want:  "買い物",
+		},
+		{
+			name:  "ブックマークのタイトル・説明・URL",
+			block: models.Block{Type: "bookmark", Content: json.RawMessage(`{"url":"https://example.com","title":"Example","description":"説明"}`)},
+			want:  "Example\n説明\nhttps://example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blockPlainText(tt.block); got != tt.want {
+				t.Errorf("blockPlainText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,20 +1,25 @@
 package document
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/middleware"
 	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
 )
 
 func (h *DocumentHandler) GetDocumentTree(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserIDFromContext(r.Context())
 
-	tree, err := h.DocumentService.GetDocumentTree(userID)
+	tree, err := h.DocumentService.GetDocumentTree(userID, middleware.GetWorkspaceIDFromContext(r.Context()))
 	if err != nil {
 		apierror.Write(w, r, err)
 		return
@@ -34,7 +39,7 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// includeDeleted クエリパラメータをチェック
+	// includeDeleted=true の場合はごみ箱内の文書も返す（ごみ箱画面のプレビュー用、readOnly: true）
 	includeDeleted := r.URL.Query().Get("includeDeleted") == "true"
 
 	var doc *models.DocumentWithBlocks
@@ -50,6 +55,34 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// トグルの開閉状態（閲覧しているユーザーごと）
+	collapsed, err := h.DocumentService.GetCollapsedBlockIDs(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	doc.CollapsedBlockIDs = collapsed
+
+	// 編集の基準にする最新のバージョン番号
+	version, err := h.VersionService.LatestVersion(docID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	doc.Version = version
+
+	if h.Comments != nil {
+		if doc.CommentCount, err = h.Comments.CountComments(docID); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
+	// blocks=tree の場合は入れ子構造のブロックも返す
+	if r.URL.Query().Get("blocks") == "tree" {
+		doc.BlockTree = models.BuildBlockTree(doc.Blocks, collapsed)
+	}
+
 	apierror.WriteJSON(w, http.StatusOK, doc)
 }
 
@@ -58,7 +91,18 @@ func (h *DocumentHandler) GetDocuments(w http.ResponseWriter, r *http.Request) {
 	deleted := r.URL.Query().Get("deleted") == "true"
 
 	if deleted {
-		docs, err := h.DocumentService.GetTrashedDocuments(userID)
+		filter, appErr := parseTrashFilter(r.URL.Query())
+		if appErr != nil {
+			apierror.Write(w, r, appErr)
+			return
+		}
+		page, err := pagination.Parse(r.URL.Query())
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		docs, err := h.DocumentService.GetTrashedDocuments(userID, filter, page)
 		if err != nil {
 			apierror.Write(w, r, err)
 			return
@@ -67,7 +111,7 @@ func (h *DocumentHandler) GetDocuments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tree, err := h.DocumentService.GetDocumentTree(userID)
+	tree, err := h.DocumentService.GetDocumentTree(userID, middleware.GetWorkspaceIDFromContext(r.Context()))
 	if err != nil {
 		apierror.Write(w, r, err)
 		return
@@ -75,3 +119,64 @@ func (h *DocumentHandler) GetDocuments(w http.ResponseWriter, r *http.Request) {
 
 	apierror.WriteJSON(w, http.StatusOK, tree)
 }
+
+// parseTrashFilter は ごみ箱一覧のクエリパラメータを解析します
+//   - q: タイトルの部分一致
+//   - deletedFrom / deletedTo: ごみ箱に移動した日時の範囲（RFC3339 または YYYY-MM-DD、日付指定の deletedTo はその日を含む）
+//   - parentId: 削除前の親文書
+//   - sort: deletedAt（既定） / title / createdAt、order: asc / desc（既定）
+func parseTrashFilter(query url.Values) (models.TrashFilter, *apierror.AppError) {
+	filter := models.TrashFilter{Query: strings.TrimSpace(query.Get("q"))}
+
+	for _, param := range []struct {
+		name      string
+		target    **time.Time
+		inclusive bool
+	}{
+		{name: "deletedFrom", target: &filter.DeletedFrom},
+		{name: "deletedTo", target: &filter.DeletedTo, inclusive: true},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", value)
+			if err != nil {
+				return filter, apierror.NewValidationError("INVALID_DATE",
+					fmt.Sprintf("%s は RFC3339 または YYYY-MM-DD 形式で指定してください", param.name), err)
+			}
+			if param.inclusive {
+				t = t.AddDate(0, 0, 1)
+			}
+		}
+		*param.target = &t
+	}
+
+	if value := query.Get("parentId"); value != "" {
+		parentID, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, apierror.NewValidationError("INVALID_PARENT_ID", "parentId が不正です", err)
+		}
+		filter.ParentID = &parentID
+	}
+
+	switch sort := models.TrashSort(query.Get("sort")); sort {
+	case "", models.TrashSortDeletedAt, models.TrashSortTitle, models.TrashSortCreatedAt:
+		filter.Sort = sort
+	default:
+		return filter, apierror.NewValidationError("INVALID_SORT",
+			"sort には deletedAt / title / createdAt のいずれかを指定してください", nil)
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		return filter, apierror.NewValidationError("INVALID_ORDER", "order には asc / desc を指定してください", nil)
+	}
+
+	return filter, nil
+}
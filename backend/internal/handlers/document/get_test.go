@@ -0,0 +1,65 @@
+package document
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/models"
+)
+
+func TestParseTrashFilter(t *testing.T) {
+	t.Run("全パラメータの解析", func(t *testing.T) {
+		query := url.Values{
+			"q":           {" 議事録 "},
+			"deletedFrom": {"2026-01-01T00:00:00Z"},
+			"deletedTo":   {"2026-01-31"},
+			"parentId":    {"12"},
+			"sort":        {"title"},
+			"order":       {"asc"},
+		}
+
+		filter, appErr := parseTrashFilter(query)
+		if appErr != nil {
+			t.Fatalf("parseTrashFilter() error = %v", appErr)
+		}
+		if filter.Query != "議事録" {
+			t.Errorf("Query = %q, want %q", filter.Query, "議事録")
+		}
+		if filter.DeletedFrom == nil || !filter.DeletedFrom.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("DeletedFrom = %v", filter.DeletedFrom)
+		}
+		// 日付のみの deletedTo はその日を含むよう翌日0時になる
+		if filter.DeletedTo == nil || !filter.DeletedTo.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("DeletedTo = %v", filter.DeletedTo)
+		}
+		if filter.ParentID == nil || *filter.ParentID != 12 {
+			t.Errorf("ParentID = %v, want 12", filter.ParentID)
+		}
+		if filter.Sort != models.TrashSortTitle || !filter.Ascending {
+			t.Errorf("Sort = %q Ascending = %v, want title asc", filter.Sort, filter.Ascending)
+		}
+	})
+
+	t.Run("パラメータなしは条件なし", func(t *testing.T) {
+		filter, appErr := parseTrashFilter(url.Values{})
+		if appErr != nil {
+			t.Fatalf("parseTrashFilter() error = %v", appErr)
+		}
+		if filter.Query != "" || filter.DeletedFrom != nil || filter.DeletedTo != nil || filter.ParentID != nil || filter.Ascending {
+			t.Errorf("parseTrashFilter() = %+v, want zero filter", filter)
+		}
+	})
+
+	invalid := []url.Values{
+		{"deletedFrom": {"yesterday"}},
+		{"parentId": {"abc"}},
+		{"sort": {"size"}},
+		{"order": {"up"}},
+	}
+	for _, query := range invalid {
+		if _, appErr := parseTrashFilter(query); appErr == nil || appErr.HTTPStatus != 400 {
+			t.Errorf("parseTrashFilter(%v) error = %v, want 400", query, appErr)
+		}
+	}
+}
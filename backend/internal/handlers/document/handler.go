@@ -1,15 +1,69 @@
 package document
 
 import (
+	"simple-notion-backend/internal/contentpolicy"
 	"simple-notion-backend/internal/services"
 )
 
 type DocumentHandler struct {
-	DocumentService *services.DocumentService
+	DocumentService      *services.DocumentService
+	VersionService       *services.VersionService
+	PublicationService   *services.PublicationService
+	ExpirationService    *services.ExpirationService
+	SecurityEventService *services.SecurityEventService
+	BlockTypeService     *services.BlockTypeService // nil の場合はカスタムブロックタイプを扱わない
+	LinkPreviewService   *services.LinkPreviewService
+	FileService          *services.FileService
+	UserStorageQuota     int64 // ブロック複製でファイルをコピーする際のクォータ
+	ContentLimits        ContentLimits
+	ContentPolicy        *contentpolicy.Pipeline
+	SecretsChecker       contentpolicy.Checker            // nil の場合は秘密情報の検出を行わない
+	DocumentEvents       *services.DocumentEventBroker    // nil の場合は開いているタブに変更を通知しない
+	Presence             *services.PresenceService        // nil の場合は文書を開いているユーザーを記録しない
+	Workspaces           *services.WorkspaceService       // nil の場合はワークスペースの文書数の上限を確認しない
+	Comments             *services.DocumentCommentService // nil の場合は文書のコメント数を返さない
+	Mentions             *services.MentionService         // nil の場合は @メンション を記録・通知しない
+	Watches              *services.DocumentWatchService   // nil の場合は購読者に変更を知らせない
 }
 
-func NewDocumentHandler(documentService *services.DocumentService) *DocumentHandler {
+func NewDocumentHandler(
+	documentService *services.DocumentService,
+	versionService *services.VersionService,
+	publicationService *services.PublicationService,
+	expirationService *services.ExpirationService,
+	securityEventService *services.SecurityEventService,
+	blockTypeService *services.BlockTypeService,
+	linkPreviewService *services.LinkPreviewService,
+	fileService *services.FileService,
+	userStorageQuota int64,
+	contentLimits ContentLimits,
+	contentPolicy *contentpolicy.Pipeline,
+	secretsChecker contentpolicy.Checker,
+	documentEvents *services.DocumentEventBroker,
+	presence *services.PresenceService,
+	workspaces *services.WorkspaceService,
+	comments *services.DocumentCommentService,
+	mentions *services.MentionService,
+	watches *services.DocumentWatchService,
+) *DocumentHandler {
 	return &DocumentHandler{
-		DocumentService: documentService,
+		DocumentService:      documentService,
+		VersionService:       versionService,
+		PublicationService:   publicationService,
+		ExpirationService:    expirationService,
+		SecurityEventService: securityEventService,
+		BlockTypeService:     blockTypeService,
+		LinkPreviewService:   linkPreviewService,
+		FileService:          fileService,
+		UserStorageQuota:     userStorageQuota,
+		ContentLimits:        contentLimits,
+		ContentPolicy:        contentPolicy,
+		SecretsChecker:       secretsChecker,
+		DocumentEvents:       documentEvents,
+		Presence:             presence,
+		Workspaces:           workspaces,
+		Comments:             comments,
+		Mentions:             mentions,
+		Watches:              watches,
 	}
 }
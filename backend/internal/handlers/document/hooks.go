@@ -0,0 +1,38 @@
+package document
+
+import (
+	"context"
+
+	"simple-notion-backend/internal/models"
+)
+
+// REST 以外の API（gRPC）から文書を変更する場合に、REST と同じ検証と保存後の処理を行うためのメソッド
+// 変更を行ったタブはないため、通知の ClientID は空になる（開いている全てのタブが変更を反映する）
+
+// CheckDocument は 保存する文書全体（タイトル・本文・ブロックの一覧）を REST の保存と同じ規則で検証します
+func (h *DocumentHandler) CheckDocument(ctx context.Context, title, content string, blocks []models.Block) error {
+	if _, appErr := h.checkDocument(ctx, title, content, blocks, true); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// DocumentCreated は 文書の作成後の処理（検索用テキスト・メンション・ツリーの変更の通知）を行います
+func (h *DocumentHandler) DocumentCreated(ctx context.Context, userID int, doc *models.Document) {
+	h.documentCreated(ctx, "", userID, doc)
+}
+
+// DocumentUpdated は 文書の保存後の処理（秘密情報の記録・バージョン・検索用テキスト・メンション・通知）を行い、更新後の文書を返します
+func (h *DocumentHandler) DocumentUpdated(ctx context.Context, docID, userID int, previousTitle string) (*models.DocumentWithBlocks, error) {
+	updatedDoc, err := h.documentUpdated(ctx, "", docID, userID, previousTitle)
+	if err != nil {
+		return nil, err
+	}
+	h.recordSecretsDetected(ctx, userID, docID, h.detectSecrets(ctx, updatedDoc.Blocks))
+	return updatedDoc, nil
+}
+
+// DocumentDeleted は 文書をごみ箱に移した後に、開いているタブへ削除を通知します
+func (h *DocumentHandler) DocumentDeleted(ctx context.Context, docID, userID int) {
+	h.publishEvent(ctx, "", userID, models.DocumentEvent{Type: models.DocumentEventDeleted, DocumentID: docID})
+}
@@ -0,0 +1,68 @@
+package document
+
+import (
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// ContentLimits は 文書を保存する際の content のサイズ上限（バイト）です
+// 0 以下の上限は適用しない
+type ContentLimits struct {
+	MaxBlockBytes    int // 1ブロックの content（JSON）の上限
+	MaxDocumentBytes int // タイトル・本文・全ブロックの content の合計の上限
+}
+
+// blockSizeError は サイズの上限を超えたブロックです
+type blockSizeError struct {
+	Index   int    `json:"index"`
+	BlockID int    `json:"blockId,omitempty"`
+	Type    string `json:"type"`
+	Size    int    `json:"size"`
+	Limit   int    `json:"limit"`
+}
+
+// documentSizeError は 文書全体のサイズが上限を超えた場合の詳細です
+type documentSizeError struct {
+	Size  int `json:"size"`
+	Limit int `json:"limit"`
+}
+
+// checkContentSize は ブロックごと・文書全体のサイズを検証します
+// 上限を超えたブロックがある場合は、すべてのブロックの位置と ID を details に含めて返す
+func checkContentSize(limits ContentLimits, title, content string, blocks []models.Block) *apierror.AppError {
+	var errs []blockSizeError
+	total := len(title) + len(content)
+	for i, block := range blocks {
+		size := len(block.Content)
+		total += size
+		if limits.MaxBlockBytes > 0 && size > limits.MaxBlockBytes {
+			errs = append(errs, blockSizeError{
+				Index:   i,
+				BlockID: block.ID,
+				Type:    block.Type,
+				Size:    size,
+				Limit:   limits.MaxBlockBytes,
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		first := errs[0]
+		message := fmt.Sprintf("ブロック %d（%s）の内容が大きすぎます（%d / %d バイト）", first.Index, first.Type, first.Size, first.Limit)
+		if len(errs) > 1 {
+			message += fmt.Sprintf("（他 %d 件）", len(errs)-1)
+		}
+		return apierror.NewPayloadTooLarge("BLOCK_CONTENT_TOO_LARGE", message, nil).WithDetails(errs)
+	}
+
+	if limits.MaxDocumentBytes > 0 && total > limits.MaxDocumentBytes {
+		return apierror.NewPayloadTooLarge(
+			"DOCUMENT_CONTENT_TOO_LARGE",
+			fmt.Sprintf("文書の内容が大きすぎます（%d / %d バイト）", total, limits.MaxDocumentBytes),
+			nil,
+		).WithDetails(documentSizeError{Size: total, Limit: limits.MaxDocumentBytes})
+	}
+	return nil
+}
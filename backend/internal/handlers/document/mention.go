@@ -0,0 +1,33 @@
+package document
+
+import (
+	"context"
+	"strings"
+
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// syncMentions は 保存後の文書の本文・ブロックから @メンション を記録し、新しくメンションされたユーザーに通知します
+// 文書の保存自体は完了しているため、失敗してもリクエストは成功として扱う
+func (h *DocumentHandler) syncMentions(ctx context.Context, doc *models.DocumentWithBlocks, userID int) {
+	if h.Mentions == nil {
+		return
+	}
+
+	texts := make([]services.MentionText, 0, len(doc.Blocks)+1)
+	if text := ExtractPlainTextFromRichText(doc.Content); strings.Contains(text, "@") {
+		texts = append(texts, services.MentionText{Text: text})
+	}
+	for _, block := range doc.Blocks {
+		if text := blockPlainText(block); strings.Contains(text, "@") {
+			blockID := block.ID
+			texts = append(texts, services.MentionText{BlockID: &blockID, Text: text})
+		}
+	}
+
+	if err := h.Mentions.SyncDocument(ctx, &doc.Document, userID, texts); err != nil {
+		logctx.Printf(ctx, "failed to sync mentions for document %d: %v", doc.ID, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
 )
 
 func (h *DocumentHandler) MoveDocument(w http.ResponseWriter, r *http.Request) {
@@ -39,6 +40,8 @@ func (h *DocumentHandler) MoveDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.publishTreeEvent(r, userID, models.DocumentEvent{Type: models.DocumentEventMoved, DocumentID: docID, ParentID: req.NewParentID})
+
 	// 移動されたドキュメントを取得して返す
 	movedDoc, err := h.DocumentService.GetDocumentWithBlocks(docID, userID)
 	if err != nil {
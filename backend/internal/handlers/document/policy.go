@@ -0,0 +1,81 @@
+package document
+
+import (
+	"context"
+	"fmt"
+
+	"simple-notion-backend/internal/contentpolicy"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// buildPolicySegments は 文書のタイトル・本文・各ブロックをポリシーチェック用のテキスト片に変換します
+// リッチテキストはプレーンテキストに展開し、画像ブロックなどテキストを持たないものは除外する
+func buildPolicySegments(title, content string, blocks []models.Block) []contentpolicy.Segment {
+	segments := make([]contentpolicy.Segment, 0, len(blocks)+2)
+
+	if title != "" {
+		segments = append(segments, contentpolicy.Segment{Location: "title", Text: title})
+	}
+	if content != "" {
+		segments = append(segments, contentpolicy.Segment{
+			Location: "content",
+			Text:     ExtractPlainTextFromRichText(content),
+		})
+	}
+
+	return append(segments, buildBlockSegments(blocks)...)
+}
+
+// buildBlockSegments は 各ブロックのプレーンテキストをチェック用のテキスト片に変換します
+func buildBlockSegments(blocks []models.Block) []contentpolicy.Segment {
+	segments := make([]contentpolicy.Segment, 0, len(blocks))
+
+	for i, block := range blocks {
+		if block.Type == "image" || block.Type == "file" || block.Type == "audio" {
+			continue
+		}
+		text := blockPlainText(block)
+		if text == "" {
+			continue
+		}
+		segments = append(segments, contentpolicy.Segment{
+			Location: fmt.Sprintf("block:%d", i),
+			Text:     text,
+		})
+	}
+
+	return segments
+}
+
+// detectSecrets は ブロック本文から秘密情報らしき文字列を検出します
+// 検出は保存を妨げない警告として扱い、チェッカー未設定時は何もしない
+func (h *DocumentHandler) detectSecrets(ctx context.Context, blocks []models.Block) []contentpolicy.Violation {
+	if h.SecretsChecker == nil {
+		return nil
+	}
+
+	violations, err := h.SecretsChecker.Check(ctx, buildBlockSegments(blocks))
+	if err != nil {
+		logctx.Printf(ctx, "secrets detection failed: %v", err)
+		return nil
+	}
+	return violations
+}
+
+// recordSecretsDetected は 秘密情報の検出をセキュリティイベントとして記録します
+// 記録に失敗しても保存結果には影響させない
+func (h *DocumentHandler) recordSecretsDetected(ctx context.Context, userID, docID int, violations []contentpolicy.Violation) {
+	if h.SecurityEventService == nil || len(violations) == 0 {
+		return
+	}
+
+	findings := make([]services.SecretFinding, 0, len(violations))
+	for _, v := range violations {
+		findings = append(findings, services.SecretFinding{Rule: v.Rule, Location: v.Location})
+	}
+	if err := h.SecurityEventService.RecordSecretsDetected(userID, docID, findings); err != nil {
+		logctx.Printf(ctx, "failed to record security event for document %d: %v", docID, err)
+	}
+}
@@ -0,0 +1,144 @@
+package document
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+)
+
+// JoinPresence は タブが文書を開いたことを記録し、文書を開いているユーザーを返します
+// タブは X-Client-ID ヘッダーで識別する。変更の通知の接続（GET /documents/events?clientId=...）が
+// 続いている間は記録が延長され、接続がない場合は定期的にこの API を呼び直す必要がある
+func (h *DocumentHandler) JoinPresence(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parsePresencePath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+	if h.Presence == nil {
+		apierror.Write(w, r, presenceDisabledError())
+		return
+	}
+
+	clientID := r.Header.Get(clientIDHeader)
+	if len(clientID) > maxClientIDLength {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_CLIENT_ID", "X-Client-ID ヘッダーが長すぎます", nil,
+		))
+		return
+	}
+
+	doc, err := h.DocumentService.GetDocument(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	presence, err := h.Presence.Join(doc, userID, clientID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, presence)
+}
+
+// LeavePresence は タブが文書を閉じたことを記録します（記録がない場合も成功を返す）
+func (h *DocumentHandler) LeavePresence(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parsePresencePath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+	if h.Presence == nil {
+		apierror.Write(w, r, presenceDisabledError())
+		return
+	}
+
+	h.Presence.Leave(docID, userID, r.Header.Get(clientIDHeader))
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Left document successfully"})
+}
+
+// GetPresence は 文書を開いているユーザーを返します
+func (h *DocumentHandler) GetPresence(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parsePresencePath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+	if h.Presence == nil {
+		apierror.Write(w, r, presenceDisabledError())
+		return
+	}
+
+	if _, err := h.DocumentService.GetDocument(docID, userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, h.Presence.Get(docID))
+}
+
+// UpdateCursor は タブのカーソル・選択範囲を、同じ文書を開いているユーザーに cursor.updated で通知します
+// リクエスト: {"anchor":{"blockId":1,"offset":3},"focus":{"blockId":1,"offset":8}}（anchor を省略するとカーソルを外す）
+// 位置は保存せず、短い間隔で送られた移動はまとめて最後の位置だけを通知する。JoinPresence したタブのみ送れる
+func (h *DocumentHandler) UpdateCursor(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parsePresencePath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+	if h.Presence == nil {
+		apierror.Write(w, r, presenceDisabledError())
+		return
+	}
+
+	var req struct {
+		Anchor *models.DocumentCursorPosition `json:"anchor"`
+		Focus  *models.DocumentCursorPosition `json:"focus"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	// 共同編集者から外れたユーザーのカーソルを送らないよう、毎回アクセスできるかを確認する
+	if _, err := h.DocumentService.GetDocument(docID, userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if err := h.Presence.UpdateCursor(docID, userID, r.Header.Get(clientIDHeader), req.Anchor, req.Focus); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Cursor updated successfully"})
+}
+
+// parsePresencePath は URL から文書IDを取り出します
+func parsePresencePath(r *http.Request) (int, *apierror.AppError) {
+	docID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, apierror.NewValidationError("INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err)
+	}
+	return docID, nil
+}
+
+// presenceDisabledError は 文書を開いているユーザーの記録が無効な場合のエラーです
+func presenceDisabledError() *apierror.AppError {
+	return apierror.NewNotFound("PRESENCE_DISABLED", "文書を開いているユーザーの表示は利用できません", nil)
+}
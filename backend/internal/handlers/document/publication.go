@@ -0,0 +1,89 @@
+package document
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+)
+
+// GetPublication は 文書の公開設定を返します
+func (h *DocumentHandler) GetPublication(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	pub, err := h.PublicationService.GetPublication(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, pub)
+}
+
+// UpdatePublication は 文書の公開状態と公開/非公開の予約日時を更新します
+// publishAt / unpublishAt は RFC3339 形式、null で予約を解除する
+func (h *DocumentHandler) UpdatePublication(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	var req struct {
+		IsPublic    bool       `json:"isPublic"`
+		PublishAt   *time.Time `json:"publishAt"`
+		UnpublishAt *time.Time `json:"unpublishAt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	pub, err := h.PublicationService.SetPublication(docID, userID, req.IsPublic, req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, pub)
+}
+
+// GetPublicDocument は 公開中の文書を認証なしで返します
+// 非公開・削除済みの文書は存在しないものとして 404 を返す
+func (h *DocumentHandler) GetPublicDocument(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	doc, err := h.PublicationService.GetPublicDocument(docID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, doc)
+}
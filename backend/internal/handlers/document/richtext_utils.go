@@ -28,6 +28,14 @@ type RichTextMark struct {
 	Attrs map[string]interface{} `json:"attrs,omitempty"`
 }
 
+// インライン数式の表現（TipTap の拡張によって2通りある）
+const (
+	// mathMarkType は テキストの内容を LaTeX として表示するマークです
+	mathMarkType = "math"
+	// inlineMathNodeType は attrs.latex に LaTeX を持つインラインノードです
+	inlineMathNodeType = "inlineMath"
+)
+
 // IsRichTextContent は コンテンツ文字列がTipTap JSON形式かどうかを確認します
 func IsRichTextContent(content string) bool {
 	if content == "" {
@@ -106,10 +114,19 @@ func ExtractPlainTextFromRichText(richTextJSON string) string {
 }
 
 // extractTextFromNodes は TipTapノードから再帰的にテキストを抽出します
+// インライン数式は $...$ で囲んで出力する（Markdown などのエクスポートで数式として扱われるように）
 func extractTextFromNodes(nodes []RichTextNode, result *strings.Builder) {
 	for i, node := range nodes {
-		if node.Text != "" {
-			result.WriteString(node.Text)
+		if node.Type == inlineMathNodeType {
+			if latex, ok := node.Attrs["latex"].(string); ok {
+				result.WriteString("$" + latex + "$")
+			}
+		} else if node.Text != "" {
+			if node.hasMark(mathMarkType) {
+				result.WriteString("$" + node.Text + "$")
+			} else {
+				result.WriteString(node.Text)
+			}
 		}
 
 		if node.Content != nil {
@@ -146,5 +163,35 @@ func ValidateRichTextJSON(content string) error {
 		return fmt.Errorf("invalid TipTap format: root type must be 'doc', got '%s'", richContent.Type)
 	}
 
+	return validateRichTextNodes(richContent.Content)
+}
+
+// validateRichTextNodes は インライン数式が LaTeX を失わない形になっているかを再帰的に検証します
+// 数式マークはテキストノードにのみ付けられ、inlineMath ノードは attrs.latex（空でない文字列）を持つ必要がある
+func validateRichTextNodes(nodes []RichTextNode) error {
+	for _, node := range nodes {
+		if node.Type == inlineMathNodeType {
+			latex, ok := node.Attrs["latex"].(string)
+			if !ok || strings.TrimSpace(latex) == "" {
+				return fmt.Errorf("invalid TipTap format: %s node requires attrs.latex", inlineMathNodeType)
+			}
+		}
+		if node.hasMark(mathMarkType) && (node.Type != "text" || strings.TrimSpace(node.Text) == "") {
+			return fmt.Errorf("invalid TipTap format: %s mark must be on a non-empty text node", mathMarkType)
+		}
+		if err := validateRichTextNodes(node.Content); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// hasMark は ノードが指定した種類のマークを持つかどうかを返します
+func (n RichTextNode) hasMark(markType string) bool {
+	for _, mark := range n.Marks {
+		if mark.Type == markType {
+			return true
+		}
+	}
+	return false
+}
@@ -116,6 +116,11 @@ func TestExtractPlainTextFromRichText(t *testing.T) {
 			richTextJSON: `{"type":"doc","content":[]}`,
 			expected:     "",
 		},
+		{
+			name:         "Inline math mark and node",
+			richTextJSON: `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Energy "},{"type":"text","text":"E=mc^2","marks":[{"type":"math"}]},{"type":"text","text":" and "},{"type":"inlineMath","attrs":{"latex":"\\alpha"}}]}]}`,
+			expected:     "Energy $E=mc^2$ and $\\alpha$",
+		},
 	}
 
 	for _, tt := range tests {
@@ -159,6 +164,21 @@ func TestValidateRichTextJSON(t *testing.T) {
 			content:     "",
 			expectError: false,
 		},
+		{
+			name:        "Valid inline math",
+			content:     `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"x^2","marks":[{"type":"math"}]},{"type":"inlineMath","attrs":{"latex":"y"}}]}]}`,
+			expectError: false,
+		},
+		{
+			name:        "Inline math node without latex",
+			content:     `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"inlineMath","attrs":{}}]}]}`,
+			expectError: true,
+		},
+		{
+			name:        "Math mark on non-text node",
+			content:     `{"type":"doc","content":[{"type":"paragraph","marks":[{"type":"math"}],"content":[{"type":"text","text":"x"}]}]}`,
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,67 @@
+package document
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
+
+// SearchDocuments は ログインユーザーの文書をタイトルとブロックのテキストで全文検索します
+// q は websearch 形式（"語句" による完全一致・-語 による除外・or）で、limit は1ページの件数、cursor は次のページの位置
+func (h *DocumentHandler) SearchDocuments(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	page, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	results, err := h.DocumentService.SearchDocuments(userID, r.URL.Query().Get("q"), page)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, results)
+}
+
+// refreshSearchText は 保存後の文書から全文検索用のテキストとメンションを作り直します
+// 文書の保存自体は完了しているため、失敗してもリクエストは成功として扱う
+func (h *DocumentHandler) refreshSearchText(ctx context.Context, docID, userID int) {
+	doc, err := h.DocumentService.GetDocumentWithBlocks(docID, userID)
+	if err != nil {
+		logctx.Printf(ctx, "failed to load document %d for search index: %v", docID, err)
+		return
+	}
+	h.updateSearchText(ctx, doc)
+	h.syncMentions(ctx, doc, userID)
+}
+
+// updateSearchText は 読み込み済みの文書から全文検索用のテキストを保存します
+func (h *DocumentHandler) updateSearchText(ctx context.Context, doc *models.DocumentWithBlocks) {
+	if err := h.DocumentService.UpdateSearchText(doc.ID, DocumentSearchText(doc)); err != nil {
+		logctx.Printf(ctx, "failed to update search text for document %d: %v", doc.ID, err)
+	}
+}
+
+// DocumentSearchText は 文書の本文とブロックの JSON からプレーンテキストを取り出します（タイトルは含めない。全文検索の再構築でも使う）
+// リッチテキストは ExtractPlainTextFromRichText と同じく TipTap JSON のテキストノードのみを使う
+func DocumentSearchText(doc *models.DocumentWithBlocks) string {
+	var parts []string
+	if text := ExtractPlainTextFromRichText(doc.Content); strings.TrimSpace(text) != "" {
+		parts = append(parts, text)
+	}
+	for _, block := range doc.Blocks {
+		if text := blockPlainText(block); strings.TrimSpace(text) != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
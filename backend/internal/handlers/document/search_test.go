@@ -0,0 +1,25 @@
+package document
+
+import (
+	"encoding/json"
+	"testing"
+
+	"simple-notion-backend/internal/models"
+)
+
+func TestDocumentSearchText(t *testing.T) {
+	doc := &models.DocumentWithBlocks{
+		Document: models.Document{Title: "タイトル", Content: ""},
+		Blocks: []models.Block{
+			{Type: "text", Content: json.RawMessage(`"{\"type\":\"doc\",\"content\":[{\"type\":\"paragraph\",\"content\":[{\"type\":\"text\",\"text\":\"議事録\"}]}]}"`)},
+			{Type: "todo", Content: json.RawMessage(`{"text":"資料を送る","checked":false}`)},
+			{Type: "columnList", Content: json.RawMessage(`{}`)},
+			{Type: "code", Content: json.RawMessage(`{"content":"fmt.Println()","language":"go"}`)},
+		},
+	}
+
+	want := "議事録\n資料を送る\nfmt.Println()"
+	if got := DocumentSearchText(doc); got != want {
+		t.Errorf("DocumentSearchText() = %q, want %q", got, want)
+	}
+}
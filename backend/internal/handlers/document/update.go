@@ -1,7 +1,9 @@
 package document
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,8 +11,12 @@ import (
 	"github.com/gorilla/mux"
 
 	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/blockschema"
+	"simple-notion-backend/internal/contentpolicy"
+	"simple-notion-backend/internal/logctx"
 	"simple-notion-backend/internal/middleware"
 	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
 )
 
 func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request) {
@@ -24,10 +30,14 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// operations を指定した場合はブロックを全置換せず、差分操作のみを適用する
+	// baseVersion（編集を始めた時点のバージョン）を指定した一括保存は、それ以降の他の保存とマージしてから保存する
 	var req struct {
-		Title   string         `json:"title"`
-		Content string         `json:"content"`
-		Blocks  []models.Block `json:"blocks"`
+		Title       string                  `json:"title"`
+		Content     string                  `json:"content"`
+		Blocks      []models.Block          `json:"blocks"`
+		Operations  []models.BlockOperation `json:"operations"`
+		BaseVersion *int                    `json:"baseVersion"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -37,48 +47,309 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// 該当する場合はリッチテキストJSONを検証
-	if err := ValidateRichTextJSON(req.Content); err != nil {
-		apierror.Write(w, r, apierror.NewValidationError(
-			"INVALID_RICH_TEXT", "リッチテキスト形式が不正です", err,
-		))
+	// 同時編集のマージ（差分操作はブロック単位で適用されるためマージしない）
+	if req.BaseVersion != nil && req.Operations == nil {
+		h.updateMergedDocument(w, r, docID, userID, *req.BaseVersion, req.Title, req.Content, req.Blocks)
 		return
 	}
 
-	// ブロックコンテンツのリッチテキスト形式を検証
-	// ただし、image と file ブロックは除外（これらは独自のJSONフォーマットを持つ）
-	for i, block := range req.Blocks {
-		// 画像とファイルブロックはリッチテキストではないのでスキップ
-		if block.Type == "image" || block.Type == "file" {
-			continue
+	// 差分操作の場合は、適用後のブロック一覧を検証対象にする
+	blocks := req.Blocks
+	if req.Operations != nil {
+		current, err := h.DocumentService.GetDocumentWithBlocks(docID, userID)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
 		}
-
-		// json.RawMessageは[]byte型なので、string()で変換
-		contentStr := string(block.Content)
-		if err := ValidateRichTextJSON(contentStr); err != nil {
-			apierror.Write(w, r, apierror.NewValidationError(
-				"INVALID_RICH_TEXT_BLOCK",
-				fmt.Sprintf("ブロック %d のリッチテキスト形式が不正です", i),
-				err,
-			))
+		blocks, err = services.PreviewBlockOperations(current.Blocks, req.Operations)
+		if err != nil {
+			apierror.Write(w, r, err)
 			return
 		}
 	}
 
+	// 一括保存の場合は親子関係も検証（親は一覧内のブロックを指す）
+	warnings, appErr := h.checkDocument(r.Context(), req.Title, req.Content, blocks, req.Operations == nil)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	// 名前の変更をサイドバーに通知するため、更新前のタイトルを取得しておく
+	previous, err := h.DocumentService.GetDocument(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
 	// ドキュメントとブロックを統合更新
 	// service 層が ErrNotFound / ErrForbidden / ErrConflict を返す場合は
 	// apierror.Write が適切な 404/403/409 に自動変換する
-	if err := h.DocumentService.UpdateDocumentWithBlocks(docID, userID, req.Title, req.Content, req.Blocks); err != nil {
+	if req.Operations != nil {
+		err = h.DocumentService.UpdateDocumentWithOperations(r.Context(), docID, userID, req.Title, req.Content, req.Operations)
+	} else {
+		err = h.DocumentService.UpdateDocumentWithBlocks(r.Context(), docID, userID, req.Title, req.Content, req.Blocks)
+	}
+	if err != nil {
 		apierror.Write(w, r, err)
 		return
 	}
 
-	// 更新されたドキュメントを取得して返す
-	updatedDoc, err := h.DocumentService.GetDocumentWithBlocks(docID, userID)
+	h.writeUpdatedDocument(w, r, docID, userID, previous.Title, nil, warnings)
+}
+
+// mergeAttempts は マージした内容の保存が他の保存と競合した場合に、マージからやり直す上限の回数です
+const mergeAttempts = 3
+
+// updateMergedDocument は 送信された内容を基準バージョン以降の他の保存とマージして保存します
+// マージから保存までの間に他の保存があった場合は、最新の内容とマージし直す（上限を超えた場合は 409）
+func (h *DocumentHandler) updateMergedDocument(w http.ResponseWriter, r *http.Request, docID, userID, baseVersion int, title, content string, blocks []models.Block) {
+	for attempt := 0; attempt < mergeAttempts; attempt++ {
+		merged, err := h.VersionService.MergeWithBase(docID, userID, baseVersion, title, content, blocks)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		// 検証・保存の対象はマージ後の内容
+		warnings, appErr := h.checkDocument(r.Context(), merged.Title, merged.Content, merged.Blocks, true)
+		if appErr != nil {
+			apierror.Write(w, r, appErr)
+			return
+		}
+
+		err = h.DocumentService.UpdateMergedDocument(r.Context(), docID, userID, merged)
+		if errors.Is(err, apierror.ErrConflict) {
+			logctx.Printf(r.Context(), "document %d was changed during merge, retrying (attempt %d)", docID, attempt+1)
+			continue
+		}
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		h.writeUpdatedDocument(w, r, docID, userID, merged.Current.Title, &merged.Merge, warnings)
+		return
+	}
+
+	apierror.Write(w, r, apierror.NewConflict(
+		"DOCUMENT_CHANGED", "他の保存と競合したため保存できませんでした。もう一度保存してください", nil,
+	))
+}
+
+// PatchDocument は 文書を JSON Merge Patch（RFC 7396）で部分更新します
+// 本文に含めたフィールド（title / content / blocks）のみを変更し、含めなかったフィールドは保存しない
+// blocks は配列全体を置き換える（ブロック単位の変更は PUT の operations・ブロックの API を使う）
+func (h *DocumentHandler) PatchDocument(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	var patch models.DocumentPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	// 検証はパッチを適用した後の文書全体に対して行う（サイズの上限・コンテンツポリシーは文書全体で判定する）
+	current, err := h.DocumentService.GetDocumentWithBlocks(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	patched := patch.Apply(*current)
+
+	warnings, appErr := h.checkDocument(r.Context(), patched.Title, patched.Content, patched.Blocks, patch.Blocks != nil)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.DocumentService.PatchDocument(r.Context(), docID, userID, patch); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h.writeUpdatedDocument(w, r, docID, userID, current.Title, nil, warnings)
+}
+
+// checkDocument は 保存する文書全体を検証し、保存を妨げない警告（コンテンツポリシー・秘密情報）を返します
+// checkHierarchy: ブロックの一覧を全て置き換える場合に true（親は一覧内のブロックを指す必要がある）
+func (h *DocumentHandler) checkDocument(ctx context.Context, title, content string, blocks []models.Block, checkHierarchy bool) (blockWarnings, *apierror.AppError) {
+	// 該当する場合はリッチテキストJSONを検証
+	if err := ValidateRichTextJSON(content); err != nil {
+		return blockWarnings{}, apierror.NewValidationError(
+			"INVALID_RICH_TEXT", "リッチテキスト形式が不正です", err,
+		)
+	}
+
+	// ブロックごと・文書全体のサイズの上限（差分操作の場合は適用後の一覧で数える）
+	if appErr := checkContentSize(h.ContentLimits, title, content, blocks); appErr != nil {
+		return blockWarnings{}, appErr
+	}
+
+	if checkHierarchy {
+		if err := models.ValidateBlockHierarchy(blocks); err != nil {
+			return blockWarnings{}, apierror.NewValidationError(
+				"INVALID_BLOCK_HIERARCHY", "ブロックの親子関係が不正です", err,
+			)
+		}
+	}
+
+	// 列レイアウト（columnList / column）に置けるブロックの種類を検証
+	if err := models.ValidateBlockLayout(blocks); err != nil {
+		return blockWarnings{}, apierror.NewValidationError(
+			"INVALID_BLOCK_HIERARCHY", "ブロックの親子関係が不正です", err,
+		)
+	}
+
+	// ブロックコンテンツの形式をブロックタイプごとのスキーマで検証
+	registry, err := h.loadBlockTypeRegistry()
+	if err != nil {
+		return blockWarnings{}, apierror.From(err)
+	}
+	if appErr := validateBlocks(registry, blocks); appErr != nil {
+		return blockWarnings{}, appErr
+	}
+
+	// コンテンツポリシーのチェック（block モードで違反があれば保存しない）
+	policyResult := h.ContentPolicy.Evaluate(ctx, buildPolicySegments(title, content, blocks))
+	if policyResult.Blocked {
+		return blockWarnings{}, apierror.NewValidationError(
+			"CONTENT_POLICY_VIOLATION",
+			fmt.Sprintf("保存できない内容が含まれています（%s）", policyResult.Summary()),
+			nil,
+		)
+	}
+
+	// 秘密情報の検出（保存は妨げず、警告として返す）
+	return blockWarnings{
+		policy:  policyResult.Violations,
+		secrets: h.detectSecrets(ctx, blocks),
+	}, nil
+}
+
+// writeUpdatedDocument は 文書の保存後の処理（バージョンの記録・検索用テキスト・メンション・通知）を行い、更新後の文書を返します
+// previousTitle: 更新前のタイトル（変わった場合はサイドバーに名前の変更を通知する）
+func (h *DocumentHandler) writeUpdatedDocument(w http.ResponseWriter, r *http.Request, docID, userID int, previousTitle string, merge *models.DocumentMerge, warnings blockWarnings) {
+	h.recordSecretsDetected(r.Context(), userID, docID, warnings.secrets)
+
+	updatedDoc, err := h.documentUpdated(r.Context(), requestClientID(r), docID, userID, previousTitle)
 	if err != nil {
 		apierror.Write(w, r, err)
 		return
 	}
 
-	apierror.WriteJSON(w, http.StatusOK, updatedDoc)
+	apierror.WriteJSON(w, http.StatusOK, updateDocumentResponse{
+		DocumentWithBlocks: updatedDoc,
+		Merge:              merge,
+		Warnings:           warnings.all(),
+	})
+}
+
+// documentUpdated は 文書の保存後の処理（バージョンの記録・検索用テキスト・メンション・通知）を行い、更新後の文書を返します
+// clientID: 変更を行ったタブ（HTTP 以外の API からの変更は空文字）
+func (h *DocumentHandler) documentUpdated(ctx context.Context, clientID string, docID, userID int, previousTitle string) (*models.DocumentWithBlocks, error) {
+	// 保存内容をバージョンとして記録（差分表示用）
+	// 文書の更新自体は完了しているため、記録に失敗してもリクエストは成功として扱う
+	version, err := h.VersionService.CreateSnapshot(docID, userID)
+	if err != nil {
+		logctx.Printf(ctx, "failed to create version snapshot for document %d: %v", docID, err)
+	}
+
+	updatedDoc, err := h.DocumentService.GetDocumentWithBlocks(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if version != nil {
+		updatedDoc.Version = version.VersionNumber
+	}
+	h.updateSearchText(ctx, updatedDoc)
+	h.syncMentions(ctx, updatedDoc, userID)
+	h.publishEvent(ctx, clientID, userID, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: docID})
+	if updatedDoc.Title != previousTitle {
+		h.publishTree(ctx, clientID, userID, models.DocumentEvent{Type: models.DocumentEventRenamed, DocumentID: docID})
+	}
+	return updatedDoc, nil
+}
+
+// blockContentError は 検証に失敗したブロックと、content 内の該当箇所です
+type blockContentError struct {
+	Index   int    `json:"index"`
+	BlockID int    `json:"blockId,omitempty"`
+	Type    string `json:"type"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// validateBlocks は すべてのブロックを検証し、失敗したブロックの一覧を details に含めたエラーを返します
+func validateBlocks(registry *services.BlockTypeRegistry, blocks []models.Block) *apierror.AppError {
+	var errs []blockContentError
+	for i, block := range blocks {
+		if verr := validateBlock(registry, block); verr != nil {
+			errs = append(errs, blockContentError{
+				Index:   i,
+				BlockID: block.ID,
+				Type:    block.Type,
+				Path:    verr.Path,
+				Message: verr.Message,
+			})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	first := errs[0]
+	message := fmt.Sprintf("ブロック %d（%s）の内容が不正です: %s %s", first.Index, first.Type, first.Path, first.Message)
+	if len(errs) > 1 {
+		message += fmt.Sprintf("（他 %d 件）", len(errs)-1)
+	}
+	return apierror.NewValidationError("INVALID_BLOCK_CONTENT", message, nil).WithDetails(errs)
+}
+
+// validateBlock は ブロックの content をタイプごとのスキーマで検証します
+// テキスト系ブロックは文字列の中身が TipTap JSON の場合、その形式も検証する
+func validateBlock(registry *services.BlockTypeRegistry, block models.Block) *blockschema.ValidationError {
+	if err := registry.Validate(block); err != nil {
+		var verr *blockschema.ValidationError
+		if errors.As(err, &verr) {
+			return verr
+		}
+		return &blockschema.ValidationError{Path: "content", Message: err.Error()}
+	}
+
+	if registry.IsRichText(block.Type) {
+		var text string
+		if err := json.Unmarshal(block.Content, &text); err == nil {
+			if err := ValidateRichTextJSON(text); err != nil {
+				return &blockschema.ValidationError{Path: "content", Message: err.Error()}
+			}
+		}
+	}
+	return nil
+}
+
+// loadBlockTypeRegistry は 組み込みタイプと登録済みのカスタムブロックタイプを読み込みます
+// BlockTypeService が未設定の場合は組み込みタイプのみを使う
+func (h *DocumentHandler) loadBlockTypeRegistry() (*services.BlockTypeRegistry, error) {
+	if h.BlockTypeService == nil {
+		return services.NewBlockTypeRegistry(), nil
+	}
+	return h.BlockTypeService.LoadRegistry()
+}
+
+// updateDocumentResponse は 更新後の文書に、同時編集のマージ結果と保存を妨げない警告を添えたレスポンスです
+type updateDocumentResponse struct {
+	*models.DocumentWithBlocks
+	Merge    *models.DocumentMerge     `json:"merge,omitempty"`
+	Warnings []contentpolicy.Violation `json:"warnings,omitempty"`
 }
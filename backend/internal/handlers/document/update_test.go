@@ -0,0 +1,116 @@
+package document
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+func TestValidateBlocks(t *testing.T) {
+	registry := services.NewBlockTypeRegistry()
+
+	t.Run("正常なブロック", func(t *testing.T) {
+		blocks := []models.Block{
+			{Type: "text", Content: json.RawMessage(`"plain"`)},
+			{Type: "heading2", Content: json.RawMessage(`"{\"type\":\"doc\",\"content\":[]}"`)},
+			{Type: "image", Content: json.RawMessage(`"{\"src\":\"/api/uploads/a.png\"}"`)},
+		}
+		if appErr := validateBlocks(registry, blocks); appErr != nil {
+			t.Errorf("validateBlocks() error = %v, want nil", appErr)
+		}
+	})
+
+	t.Run("失敗したブロックをすべて返す", func(t *testing.T) {
+		blocks := []models.Block{
+			{ID: 10, Type: "text", Content: json.RawMessage(`"ok"`)},
+			{ID: 11, Type: "text", Content: json.RawMessage(`"{\"type\":\"paragraph\"}"`)},
+			{ID: 12, Type: "image", Content: json.RawMessage(`{"alt":"no src"}`)},
+			{Type: "unknown", Content: json.RawMessage(`"x"`)},
+		}
+
+		appErr := validateBlocks(registry, blocks)
+		if appErr == nil {
+			t.Fatal("validateBlocks() error = nil, want INVALID_BLOCK_CONTENT")
+		}
+		if appErr.Code != "INVALID_BLOCK_CONTENT" {
+			t.Errorf("Code = %q, want INVALID_BLOCK_CONTENT", appErr.Code)
+		}
+
+		details, ok := appErr.Details.([]blockContentError)
+		if !ok {
+			t.Fatalf("Details = %T, want []blockContentError", appErr.Details)
+		}
+		want := []blockContentError{
+			{Index: 1, BlockID: 11, Type: "text", Path: "content"},
+			{Index: 2, BlockID: 12, Type: "image", Path: "content.src"},
+			{Index: 3, Type: "unknown", Path: "type"},
+		}
+		if len(details) != len(want) {
+			t.Fatalf("len(Details) = %d, want %d: %+v", len(details), len(want), details)
+		}
+		for i, d := range details {
+			if d.Index != want[i].Index || d.BlockID != want[i].BlockID || d.Type != want[i].Type || d.Path != want[i].Path {
+				t.Errorf("Details[%d] = %+v, want %+v", i, d, want[i])
+			}
+		}
+	})
+}
+
+func TestCheckContentSize(t *testing.T) {
+	limits := ContentLimits{MaxBlockBytes: 10, MaxDocumentBytes: 30}
+
+	t.Run("上限以内", func(t *testing.T) {
+		blocks := []models.Block{
+			{ID: 1, Type: "text", Content: json.RawMessage(`"short"`)},
+			{ID: 2, Type: "text", Content: json.RawMessage(`"short"`)},
+		}
+		if appErr := checkContentSize(limits, "title", "", blocks); appErr != nil {
+			t.Errorf("checkContentSize() error = %v, want nil", appErr)
+		}
+	})
+
+	t.Run("上限を超えたブロックをすべて返す", func(t *testing.T) {
+		blocks := []models.Block{
+			{ID: 1, Type: "text", Content: json.RawMessage(`"short"`)},
+			{ID: 2, Type: "text", Content: json.RawMessage(`"too long text"`)},
+			{Type: "code", Content: json.RawMessage(`"another long one"`)},
+		}
+
+		appErr := checkContentSize(limits, "", "", blocks)
+		if appErr == nil {
+			t.Fatal("checkContentSize() error = nil, want BLOCK_CONTENT_TOO_LARGE")
+		}
+		if appErr.Code != "BLOCK_CONTENT_TOO_LARGE" || appErr.HTTPStatus != http.StatusRequestEntityTooLarge {
+			t.Errorf("Code = %q, Status = %d", appErr.Code, appErr.HTTPStatus)
+		}
+
+		details, ok := appErr.Details.([]blockSizeError)
+		if !ok {
+			t.Fatalf("Details = %T, want []blockSizeError", appErr.Details)
+		}
+		if len(details) != 2 || details[0].Index != 1 || details[0].BlockID != 2 || details[1].Index != 2 || details[1].Type != "code" {
+			t.Errorf("Details = %+v", details)
+		}
+	})
+
+	t.Run("文書全体の上限", func(t *testing.T) {
+		blocks := []models.Block{
+			{Type: "text", Content: json.RawMessage(`"short"`)},
+			{Type: "text", Content: json.RawMessage(`"short"`)},
+		}
+		appErr := checkContentSize(limits, "a long document title", "", blocks)
+		if appErr == nil || appErr.Code != "DOCUMENT_CONTENT_TOO_LARGE" {
+			t.Fatalf("checkContentSize() error = %v, want DOCUMENT_CONTENT_TOO_LARGE", appErr)
+		}
+	})
+
+	t.Run("上限が0の場合は制限しない", func(t *testing.T) {
+		blocks := []models.Block{{Type: "text", Content: json.RawMessage(`"too long text"`)}}
+		if appErr := checkContentSize(ContentLimits{}, "", "", blocks); appErr != nil {
+			t.Errorf("checkContentSize() error = %v, want nil", appErr)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package document
+
+import (
+	"context"
+
+	"simple-notion-backend/internal/logctx"
+)
+
+// notifyWatchers は 文書の変更を、変更したユーザー以外の購読者に知らせます
+// 文書の変更自体は完了しているため、失敗してもリクエストは成功として扱う
+func (h *DocumentHandler) notifyWatchers(ctx context.Context, docID, userID int) {
+	if h.Watches == nil {
+		return
+	}
+	if err := h.Watches.NotifyChange(ctx, docID, userID); err != nil {
+		logctx.Printf(ctx, "failed to notify watchers of document %d: %v", docID, err)
+	}
+}
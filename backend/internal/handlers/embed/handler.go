@@ -0,0 +1,58 @@
+package embed
+
+import (
+	"net/http"
+	"strconv"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/services"
+)
+
+// EmbedHandler は 埋め込みブロックの oEmbed 解決のHTTPハンドラーです
+type EmbedHandler struct {
+	embedService *services.EmbedService
+}
+
+// NewEmbedHandler は 新しい EmbedHandler インスタンスを作成します
+func NewEmbedHandler(embedService *services.EmbedService) *EmbedHandler {
+	return &EmbedHandler{
+		embedService: embedService,
+	}
+}
+
+// Resolve は URL を oEmbed で解決し、サニタイズ済みの埋め込み HTML と iframe のパラメータを返します
+// クエリ: url（必須）、maxWidth（任意、プロバイダーに渡す最大幅）
+// レスポンスはそのまま embed ブロックの content として保存できる
+func (h *EmbedHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	rawURL := query.Get("url")
+	if rawURL == "" {
+		apierror.Write(w, r, apierror.NewValidationError("INVALID_EMBED_URL", "url を指定してください", nil))
+		return
+	}
+
+	maxWidth := 0
+	if value := query.Get("maxWidth"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			apierror.Write(w, r, apierror.NewValidationError("INVALID_MAX_WIDTH", "maxWidth が不正です", err))
+			return
+		}
+		maxWidth = n
+	}
+
+	embed, err := h.embedService.Resolve(r.Context(), rawURL, maxWidth)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, embed)
+}
+
+// GetProviders は 埋め込みを許可しているプロバイダーの一覧を返します
+func (h *EmbedHandler) GetProviders(w http.ResponseWriter, r *http.Request) {
+	apierror.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"providers": h.embedService.Providers(),
+	})
+}
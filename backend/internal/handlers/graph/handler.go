@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/graphql"
+	"simple-notion-backend/internal/services"
+)
+
+// maxRequestSize は GraphQL のリクエストの本文の最大サイズ（バイト）です
+const maxRequestSize = 1 << 20
+
+// GraphQLHandler は 文書・ブロック・ファイル・文書ツリーを GraphQL で取得するHTTPハンドラーです
+// 入れ子の子の文書・ブロック・添付ファイルを1回のリクエストで取得できる（読み取り専用、変更は REST API で行う）
+//
+// GraphQL の仕様のサブセットのみに対応する（query 操作・エイリアス・引数・変数・フラグメント・__typename）。
+// mutation・subscription・ディレクティブ・イントロスペクションには対応していない。
+// 入れ子の深さ（maxQueryDepth）と複雑さ（maxQueryComplexity）の上限を超えるクエリは実行前に拒否し、
+// 子の文書・ブロック・添付ファイルは階層ごとにまとめて取得する
+type GraphQLHandler struct {
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandler は 新しい GraphQLHandler インスタンスを作成します
+func NewGraphQLHandler(documentService *services.DocumentService, fileService *services.FileService) *GraphQLHandler {
+	return &GraphQLHandler{
+		schema: newSchema(documentService, fileService),
+	}
+}
+
+// Query は GraphQL のクエリを実行します
+// POST は JSON の本文（query・operationName・variables）、GET はクエリパラメータ（variables は JSON 文字列）で受け付ける
+// クエリの誤りやフィールドの取得の失敗は、GraphQL の形式で errors に含めて 200 で返す
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				apierror.Write(w, r, apierror.NewValidationError(
+					"INVALID_REQUEST", "variables の形式が不正です", err,
+				))
+				return
+			}
+		}
+	} else {
+		body := http.MaxBytesReader(w, r.Body, maxRequestSize)
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			apierror.Write(w, r, apierror.NewValidationError(
+				"INVALID_REQUEST", "リクエストの形式が不正です", err,
+			))
+			return
+		}
+	}
+
+	if req.Query == "" {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"QUERY_REQUIRED", "query を指定してください", nil,
+		))
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, graphql.Execute(r.Context(), h.schema, req))
+}
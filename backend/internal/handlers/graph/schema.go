@@ -0,0 +1,478 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/graphql"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+	"simple-notion-backend/internal/services"
+)
+
+// maxQueryDepth は 選択したフィールドの入れ子の最大の深さです（children の入れ子で問い合わせが増えすぎないようにする）
+const maxQueryDepth = 12
+
+// maxQueryComplexity は クエリの複雑さ（選択したフィールドの数。リストの下は listFactor 倍に数える）の上限です
+// 深さの上限の中でも、リストの入れ子で取得する値が増えすぎるクエリを実行前に拒否する
+const maxQueryComplexity = 10000
+
+// listFactor は 複雑さの計算で見積もる1つのリスト（子の文書・ブロック・添付ファイル）の要素数です
+const listFactor = 10
+
+// attachmentLimit は 文書に添付したファイルを1回で取得する件数です（FileService.ListFiles の limit の上限）
+const attachmentLimit = 200
+
+// dateTime は RFC 3339 形式の日時です
+var dateTime = &graphql.Scalar{Name: "DateTime"}
+
+// documentNode は Document 型の値です
+// ブロック・子の文書・添付ファイルは、選択された場合に初めて同じ階層の文書の分をまとめて取得し、同じ文書の中で使い回す
+type documentNode struct {
+	doc      models.Document
+	blocks   []models.Block
+	children []models.DocumentTreeNode
+	files    []*models.FileMetadata
+
+	blocksLoaded   bool
+	childrenLoaded bool
+	filesLoaded    bool
+}
+
+// blockNode は Block 型の値です
+type blockNode struct {
+	block models.Block
+	doc   *documentNode
+}
+
+// resolver は スキーマのフィールドの値を既存のサービスから取得します
+type resolver struct {
+	documentService *services.DocumentService
+	fileService     *services.FileService
+}
+
+// newSchema は 文書・ブロック・ファイル・文書ツリーのスキーマを作成します
+//
+//	type Query {
+//	  document(id: Int!): Document
+//	  tree: [Document]
+//	  files(documentId: Int, type: String, limit: Int, cursor: String): FilePage
+//	}
+func newSchema(documentService *services.DocumentService, fileService *services.FileService) *graphql.Schema {
+	r := &resolver{documentService: documentService, fileService: fileService}
+
+	file := &graphql.Object{Name: "File"}
+	file.Fields = graphql.Fields{
+		"id":           fileField(graphql.Int, func(f *models.FileMetadata) interface{} { return f.ID }),
+		"documentId":   fileField(graphql.Int, func(f *models.FileMetadata) interface{} { return f.DocumentID }),
+		"blockId":      fileField(graphql.Int, func(f *models.FileMetadata) interface{} { return f.BlockID }),
+		"originalName": fileField(graphql.String, func(f *models.FileMetadata) interface{} { return f.OriginalName }),
+		"fileSize":     fileField(graphql.Float, func(f *models.FileMetadata) interface{} { return f.FileSize }),
+		"mimeType":     fileField(graphql.String, func(f *models.FileMetadata) interface{} { return f.MimeType }),
+		"fileType":     fileField(graphql.String, func(f *models.FileMetadata) interface{} { return f.FileType }),
+		"altText":      fileField(graphql.String, func(f *models.FileMetadata) interface{} { return f.AltText }),
+		"description":  fileField(graphql.String, func(f *models.FileMetadata) interface{} { return f.Description }),
+		"width":        fileField(graphql.Int, func(f *models.FileMetadata) interface{} { return f.Width }),
+		"height":       fileField(graphql.Int, func(f *models.FileMetadata) interface{} { return f.Height }),
+		"durationMs":   fileField(graphql.Float, func(f *models.FileMetadata) interface{} { return f.DurationMs }),
+		"uploadedAt":   fileField(dateTime, func(f *models.FileMetadata) interface{} { return f.UploadedAt }),
+		"url": {
+			Type:    graphql.String,
+			Args:    graphql.Args{"size": {Type: graphql.String}},
+			Resolve: r.fileURL,
+		},
+	}
+
+	block := &graphql.Object{Name: "Block"}
+	block.Fields = graphql.Fields{
+		"id":            blockField(graphql.Int, func(b *models.Block) interface{} { return b.ID }),
+		"documentId":    blockField(graphql.Int, func(b *models.Block) interface{} { return b.DocumentID }),
+		"parentBlockId": blockField(graphql.Int, func(b *models.Block) interface{} { return b.ParentBlockID }),
+		"type":          blockField(graphql.String, func(b *models.Block) interface{} { return b.Type }),
+		"content":       blockField(graphql.JSON, func(b *models.Block) interface{} { return b.Content }),
+		"position":      blockField(graphql.Int, func(b *models.Block) interface{} { return b.Position }),
+		"createdAt":     blockField(dateTime, func(b *models.Block) interface{} { return b.CreatedAt }),
+		"children":      {Type: graphql.ListOf(block), Resolve: r.blockChildren},
+		"file":          {Type: file, BatchResolve: r.blockFile},
+	}
+
+	document := &graphql.Object{Name: "Document"}
+	document.Fields = graphql.Fields{
+		"id":          documentField(graphql.Int, func(d *models.Document) interface{} { return d.ID }),
+		"userId":      documentField(graphql.Int, func(d *models.Document) interface{} { return d.UserID }),
+		"parentId":    documentField(graphql.Int, func(d *models.Document) interface{} { return d.ParentID }),
+		"workspaceId": documentField(graphql.Int, func(d *models.Document) interface{} { return d.WorkspaceID }),
+		"title":       documentField(graphql.String, func(d *models.Document) interface{} { return d.Title }),
+		"content":     documentField(graphql.String, func(d *models.Document) interface{} { return d.Content }),
+		"level":       documentField(graphql.Int, func(d *models.Document) interface{} { return d.Level }),
+		"sortOrder":   documentField(graphql.Int, func(d *models.Document) interface{} { return d.SortOrder }),
+		"role":        documentField(graphql.String, documentRole),
+		"createdAt":   documentField(dateTime, func(d *models.Document) interface{} { return d.CreatedAt }),
+		"updatedAt":   documentField(dateTime, func(d *models.Document) interface{} { return d.UpdatedAt }),
+		"parent":      {Type: document, BatchResolve: r.documentParent},
+		"children":    {Type: graphql.ListOf(document), BatchResolve: r.documentChildren},
+		"blocks": {
+			Type:         graphql.ListOf(block),
+			Args:         graphql.Args{"topLevel": {Type: graphql.Boolean, Default: false}},
+			BatchResolve: r.documentBlocks,
+		},
+		"files": {Type: graphql.ListOf(file), BatchResolve: r.documentFiles},
+	}
+
+	filePage := &graphql.Object{Name: "FilePage", Fields: graphql.Fields{
+		"items": {Type: graphql.ListOf(file), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.FileList).Items, nil
+		}},
+		"nextCursor": {Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.FileList).NextCursor, nil
+		}},
+		"total": {Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.FileList).Total, nil
+		}},
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: graphql.Fields{
+		"document": {
+			Type:    document,
+			Args:    graphql.Args{"id": {Type: graphql.Int, Required: true}},
+			Resolve: r.document,
+		},
+		"tree": {Type: graphql.ListOf(document), Resolve: r.tree},
+		"files": {
+			Type: filePage,
+			Args: graphql.Args{
+				"documentId": {Type: graphql.Int},
+				"type":       {Type: graphql.String},
+				"limit":      {Type: graphql.Int},
+				"cursor":     {Type: graphql.String},
+			},
+			Resolve: r.files,
+		},
+	}}
+
+	return &graphql.Schema{
+		Query:         query,
+		MaxDepth:      maxQueryDepth,
+		MaxComplexity: maxQueryComplexity,
+		ListFactor:    listFactor,
+	}
+}
+
+// document は ID を指定して文書をブロックと合わせて取得します（閲覧できない文書は NOT_FOUND）
+func (r *resolver) document(p graphql.ResolveParams) (interface{}, error) {
+	return r.loadDocument(p.Context, p.Args["id"].(int))
+}
+
+// tree は 選択中のワークスペース（未選択の場合は個人の文書）の文書ツリーのトップレベルの文書を取得します
+func (r *resolver) tree(p graphql.ResolveParams) (interface{}, error) {
+	userID := middleware.GetUserIDFromContext(p.Context)
+	tree, err := r.documentService.GetDocumentTree(userID, middleware.GetWorkspaceIDFromContext(p.Context))
+	if err != nil {
+		return nil, err
+	}
+	return treeNodes(tree), nil
+}
+
+// files は ログインユーザーのファイルを絞り込んで1ページ分取得します（GET /api/files と同じ条件）
+func (r *resolver) files(p graphql.ResolveParams) (interface{}, error) {
+	opts := models.FileListOptions{}
+	if documentID, ok := p.Args["documentId"].(int); ok {
+		opts.DocumentID = &documentID
+	}
+	opts.FileType, _ = p.Args["type"].(string)
+	opts.Limit, _ = p.Args["limit"].(int)
+	if cursor, ok := p.Args["cursor"].(string); ok && cursor != "" {
+		after, err := pagination.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		opts.After = after
+	}
+
+	return r.fileService.ListFiles(p.Context, middleware.GetUserIDFromContext(p.Context), opts)
+}
+
+// documentParent は 同じ階層の文書の親の文書をまとめて取得します（トップレベルの文書は null）
+func (r *resolver) documentParent(p graphql.BatchResolveParams) ([]interface{}, error) {
+	nodes := documentNodes(p.Sources)
+	var parentIDs []int
+	for _, node := range nodes {
+		if node.doc.ParentID != nil {
+			parentIDs = append(parentIDs, *node.doc.ParentID)
+		}
+	}
+	docs, err := r.documentService.GetDocumentsByIDs(uniqueIDs(parentIDs), middleware.GetUserIDFromContext(p.Context))
+	if err != nil {
+		return nil, err
+	}
+
+	// 同じ親の文書は同じ値にして、ブロック・子の文書などの取得を使い回す
+	parents := make(map[int]*documentNode, len(docs))
+	for id, doc := range docs {
+		parents[id] = &documentNode{doc: *doc}
+	}
+	values := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		if node.doc.ParentID == nil {
+			continue
+		}
+		values[i] = documentOrNotFound(parents, *node.doc.ParentID)
+	}
+	return values, nil
+}
+
+// documentChildren は 同じ階層の文書の子の文書をまとめて取得します（ツリーから取得した文書は取得済みの子を返す）
+func (r *resolver) documentChildren(p graphql.BatchResolveParams) ([]interface{}, error) {
+	nodes := documentNodes(p.Sources)
+	var pending []int
+	for _, node := range nodes {
+		if !node.childrenLoaded {
+			pending = append(pending, node.doc.ID)
+		}
+	}
+	if len(pending) > 0 {
+		treeNodesByID, err := r.documentService.GetDocumentTreeNodes(uniqueIDs(pending), middleware.GetUserIDFromContext(p.Context))
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if treeNode, ok := treeNodesByID[node.doc.ID]; ok && !node.childrenLoaded {
+				node.children, node.childrenLoaded = treeNode.Children, true
+			}
+		}
+	}
+
+	values := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		if !node.childrenLoaded {
+			values[i] = documentNotFound(node.doc.ID)
+			continue
+		}
+		values[i] = treeNodes(node.children)
+	}
+	return values, nil
+}
+
+// documentBlocks は 同じ階層の文書のブロックをまとめて並び順に取得します（topLevel: true の場合は入れ子でないブロックのみ）
+func (r *resolver) documentBlocks(p graphql.BatchResolveParams) ([]interface{}, error) {
+	nodes := documentNodes(p.Sources)
+	if err := r.loadBlocks(p.Context, nodes); err != nil {
+		return nil, err
+	}
+
+	topLevel, _ := p.Args["topLevel"].(bool)
+	values := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		if !node.blocksLoaded {
+			values[i] = documentNotFound(node.doc.ID)
+			continue
+		}
+		blocks := make([]*blockNode, 0, len(node.blocks))
+		for _, b := range node.blocks {
+			if topLevel && b.ParentBlockID != nil {
+				continue
+			}
+			blocks = append(blocks, &blockNode{block: b, doc: node})
+		}
+		values[i] = blocks
+	}
+	return values, nil
+}
+
+// documentFiles は 同じ階層の文書に添付したファイルをまとめて取得します
+func (r *resolver) documentFiles(p graphql.BatchResolveParams) ([]interface{}, error) {
+	nodes := documentNodes(p.Sources)
+	if err := r.loadFiles(p.Context, nodes); err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		values[i] = node.files
+	}
+	return values, nil
+}
+
+// blockChildren は ブロックの中に入れ子で表示するブロックを並び順に取得します
+func (r *resolver) blockChildren(p graphql.ResolveParams) (interface{}, error) {
+	node := p.Source.(*blockNode)
+	var children []*blockNode
+	for _, b := range node.doc.blocks {
+		if b.ParentBlockID != nil && *b.ParentBlockID == node.block.ID {
+			children = append(children, &blockNode{block: b, doc: node.doc})
+		}
+	}
+	return children, nil
+}
+
+// blockFile は 同じ階層の画像・ファイル・動画・音声ブロックのアップロード済みファイルをまとめて取得します（外部URLのブロックは null）
+func (r *resolver) blockFile(p graphql.BatchResolveParams) ([]interface{}, error) {
+	var docs []*documentNode
+	for _, source := range p.Sources {
+		docs = append(docs, source.(*blockNode).doc)
+	}
+	if err := r.loadFiles(p.Context, docs); err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(p.Sources))
+	for i, source := range p.Sources {
+		node := source.(*blockNode)
+		fileID, ok := models.MediaFileID(node.block.Type, node.block.Content)
+		if !ok {
+			continue
+		}
+		for _, f := range node.doc.files {
+			if f.ID == fileID {
+				values[i] = f
+				break
+			}
+		}
+	}
+	return values, nil
+}
+
+// fileURL は ファイル（size を指定した場合はサムネイル）の署名付きURLを取得します
+// File 型の値はログインユーザーの削除されていないファイルのみのため、ファイルメタデータを取得し直さずに署名する
+func (r *resolver) fileURL(p graphql.ResolveParams) (interface{}, error) {
+	f := p.Source.(*models.FileMetadata)
+	size, _ := p.Args["size"].(string)
+	fileKey, err := services.ResolveFileKey(f, size)
+	if err != nil {
+		return nil, err
+	}
+	return r.fileService.GetPresignedURLForKey(p.Context, fileKey)
+}
+
+// loadDocument は 文書をブロックと合わせて取得します
+func (r *resolver) loadDocument(ctx context.Context, docID int) (*documentNode, error) {
+	doc, err := r.documentService.GetDocumentWithBlocks(docID, middleware.GetUserIDFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &documentNode{doc: doc.Document, blocks: doc.Blocks, blocksLoaded: true}, nil
+}
+
+// loadBlocks は ブロックを取得していない文書（ツリー・親から取得した文書）のブロックをまとめて取得します
+// 閲覧できない文書は blocksLoaded を false のままにする
+func (r *resolver) loadBlocks(ctx context.Context, nodes []*documentNode) error {
+	var pending []int
+	for _, node := range nodes {
+		if !node.blocksLoaded {
+			pending = append(pending, node.doc.ID)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	blocks, err := r.documentService.GetBlocksByDocumentIDs(uniqueIDs(pending), middleware.GetUserIDFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if docBlocks, ok := blocks[node.doc.ID]; ok && !node.blocksLoaded {
+			node.blocks, node.blocksLoaded = docBlocks, true
+		}
+	}
+	return nil
+}
+
+// loadFiles は 添付ファイルを取得していない文書の添付ファイルをまとめて取得します
+func (r *resolver) loadFiles(ctx context.Context, nodes []*documentNode) error {
+	var pending []int
+	for _, node := range nodes {
+		if !node.filesLoaded {
+			pending = append(pending, node.doc.ID)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	files, err := r.fileService.ListDocumentFiles(ctx, middleware.GetUserIDFromContext(ctx), uniqueIDs(pending), attachmentLimit)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if !node.filesLoaded {
+			node.files, node.filesLoaded = files[node.doc.ID], true
+		}
+	}
+	return nil
+}
+
+// documentNodes は 同じ階層の Document 型の値を返します
+func documentNodes(sources []interface{}) []*documentNode {
+	nodes := make([]*documentNode, len(sources))
+	for i, source := range sources {
+		nodes[i] = source.(*documentNode)
+	}
+	return nodes
+}
+
+// documentOrNotFound は まとめて取得した文書を返します（閲覧できない文書は NOT_FOUND のエラー）
+func documentOrNotFound(docs map[int]*documentNode, docID int) interface{} {
+	if node, ok := docs[docID]; ok {
+		return node
+	}
+	return documentNotFound(docID)
+}
+
+// documentNotFound は まとめて取得した結果に含まれなかった文書のエラーです（GetDocument と同じく NOT_FOUND）
+func documentNotFound(docID int) error {
+	return fmt.Errorf("document id=%d: %w", docID, apierror.ErrNotFound)
+}
+
+// uniqueIDs は 重複を除いた ID の一覧を、最初に現れた順に返します
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// treeNodes は 文書ツリーのノードを、子を取得済みの Document 型の値にします
+func treeNodes(tree []models.DocumentTreeNode) []*documentNode {
+	nodes := make([]*documentNode, 0, len(tree))
+	for _, t := range tree {
+		nodes = append(nodes, &documentNode{doc: t.Document, children: t.Children, childrenLoaded: true})
+	}
+	return nodes
+}
+
+// documentRole は ログインユーザーの文書に対する役割を返します（ツリーから取得した文書は null）
+func documentRole(d *models.Document) interface{} {
+	if d.Role == "" {
+		return nil
+	}
+	return string(d.Role)
+}
+
+// documentField は 文書の値をそのまま返すフィールドです
+func documentField(t graphql.Type, get func(*models.Document) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(&p.Source.(*documentNode).doc), nil
+	}}
+}
+
+// blockField は ブロックの値をそのまま返すフィールドです
+func blockField(t graphql.Type, get func(*models.Block) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(&p.Source.(*blockNode).block), nil
+	}}
+}
+
+// fileField は ファイルの値をそのまま返すフィールドです
+func fileField(t graphql.Type, get func(*models.FileMetadata) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*models.FileMetadata)), nil
+	}}
+}
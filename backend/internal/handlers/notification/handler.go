@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/pagination"
+	"simple-notion-backend/internal/services"
+)
+
+// NotificationHandler は アプリ内通知関連のHTTPハンドラーです
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+// NewNotificationHandler は 新しい NotificationHandler インスタンスを作成します
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetNotifications は ログインユーザーの通知一覧を新しい順に1ページ分返します
+// ?unread=true を指定すると未読のみを返す（limit・cursor でページを指定する）
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	page, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	notifications, err := h.notificationService.ListNotifications(userID, unreadOnly, page)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, notifications)
+}
+
+// MarkRead は 通知を既読にします
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_NOTIFICATION_ID", "通知IDが不正です", err,
+		))
+		return
+	}
+
+	if err := h.notificationService.MarkRead(id, userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,96 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// WatchHandler は 文書の変更の購読関連のHTTPハンドラーです
+type WatchHandler struct {
+	watchService *services.DocumentWatchService
+}
+
+// NewWatchHandler は 新しい WatchHandler インスタンスを作成します
+func NewWatchHandler(watchService *services.DocumentWatchService) *WatchHandler {
+	return &WatchHandler{
+		watchService: watchService,
+	}
+}
+
+// GetWatches は ログインユーザーが購読している文書の一覧を返します
+func (h *WatchHandler) GetWatches(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	watches, err := h.watchService.ListWatches(userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, watches)
+}
+
+// WatchDocument は 文書を購読します（delivery は in_app・email_digest、省略時 in_app。本文は省略できる）
+// 既に購読している場合は知らせ方を変更する
+func (h *WatchHandler) WatchDocument(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parseWatchDocumentID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var req struct {
+		Delivery models.WatchDelivery `json:"delivery"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストの形式が不正です", err,
+		))
+		return
+	}
+
+	watch, err := h.watchService.Watch(docID, userID, req.Delivery)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, watch)
+}
+
+// UnwatchDocument は 文書の購読を解除します
+func (h *WatchHandler) UnwatchDocument(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parseWatchDocumentID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.watchService.Unwatch(docID, userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Document unwatched successfully"})
+}
+
+// parseWatchDocumentID は パスの文書IDを取得します
+func parseWatchDocumentID(r *http.Request) (int, *apierror.AppError) {
+	docID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, apierror.NewValidationError("INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err)
+	}
+	return docID, nil
+}
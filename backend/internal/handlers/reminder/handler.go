@@ -0,0 +1,91 @@
+package reminder
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/services"
+)
+
+// ReminderHandler は 文書リマインダー関連のHTTPハンドラーです
+type ReminderHandler struct {
+	reminderService *services.ReminderService
+}
+
+// NewReminderHandler は 新しい ReminderHandler インスタンスを作成します
+func NewReminderHandler(reminderService *services.ReminderService) *ReminderHandler {
+	return &ReminderHandler{
+		reminderService: reminderService,
+	}
+}
+
+// CreateReminder は 文書にリマインダーを設定します
+func (h *ReminderHandler) CreateReminder(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	docID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err,
+		))
+		return
+	}
+
+	var req struct {
+		RemindAt *time.Time `json:"remindAt"`
+		Note     string     `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RemindAt == nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "remindAt を指定してください", err,
+		))
+		return
+	}
+
+	reminder, err := h.reminderService.CreateReminder(docID, userID, *req.RemindAt, req.Note)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, reminder)
+}
+
+// GetReminders は ログインユーザーの未解除リマインダー一覧を返します
+func (h *ReminderHandler) GetReminders(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	reminders, err := h.reminderService.ListReminders(userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, reminders)
+}
+
+// DismissReminder は リマインダーを解除します
+func (h *ReminderHandler) DismissReminder(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REMINDER_ID", "リマインダーIDが不正です", err,
+		))
+		return
+	}
+
+	if err := h.reminderService.DismissReminder(id, userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,130 @@
+package share
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// CollaboratorHandler は 文書の共同編集者関連のHTTPハンドラーです
+type CollaboratorHandler struct {
+	collaboratorService *services.CollaboratorService
+}
+
+// NewCollaboratorHandler は 新しい CollaboratorHandler インスタンスを作成します
+func NewCollaboratorHandler(collaboratorService *services.CollaboratorService) *CollaboratorHandler {
+	return &CollaboratorHandler{
+		collaboratorService: collaboratorService,
+	}
+}
+
+// GetCollaborators は 文書の所有者と共同編集者の一覧を返します（所有者が先頭）
+func (h *CollaboratorHandler) GetCollaborators(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parseDocumentID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	collaborators, err := h.collaboratorService.ListCollaborators(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, collaborators)
+}
+
+// AddCollaborator は メールアドレスで指定したユーザーを共同編集者に追加します（role は editor・viewer、省略時 viewer）
+func (h *CollaboratorHandler) AddCollaborator(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parseDocumentID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var req struct {
+		Email string              `json:"email"`
+		Role  models.DocumentRole `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "email を指定してください", err,
+		))
+		return
+	}
+
+	collaborator, err := h.collaboratorService.AddCollaborator(r.Context(), docID, userID, req.Email, req.Role)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, collaborator)
+}
+
+// UpdateCollaborator は 共同編集者の役割を変更します（所有者のみ）
+func (h *CollaboratorHandler) UpdateCollaborator(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, collaboratorID, appErr := parseCollaboratorPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var req struct {
+		Role models.DocumentRole `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	if err := h.collaboratorService.UpdateCollaboratorRole(r.Context(), docID, userID, collaboratorID, req.Role); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Collaborator updated successfully"})
+}
+
+// RemoveCollaborator は 共同編集者を削除します（自分自身を指定した場合は文書から抜ける）
+func (h *CollaboratorHandler) RemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, collaboratorID, appErr := parseCollaboratorPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.collaboratorService.RemoveCollaborator(r.Context(), docID, userID, collaboratorID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Collaborator removed successfully"})
+}
+
+// parseCollaboratorPath は URL から文書IDと共同編集者のユーザーIDを取り出します
+func parseCollaboratorPath(r *http.Request) (docID, collaboratorID int, appErr *apierror.AppError) {
+	docID, appErr = parseDocumentID(r)
+	if appErr != nil {
+		return 0, 0, appErr
+	}
+	collaboratorID, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		return 0, 0, apierror.NewValidationError("INVALID_USER_ID", "ユーザーIDが不正です", err)
+	}
+	return docID, collaboratorID, nil
+}
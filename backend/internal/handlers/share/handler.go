@@ -0,0 +1,173 @@
+package share
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/services"
+)
+
+// SharePasswordHeader は パスワード付きの共有リンクを閲覧する際にパスワードを送るヘッダーです
+// （URL に含めるとアクセスログや履歴に残るため、クエリパラメータでは受け取らない）
+const SharePasswordHeader = "X-Share-Password"
+
+// ShareLinkHandler は 文書の共有リンク関連のHTTPハンドラーです
+type ShareLinkHandler struct {
+	shareLinkService *services.ShareLinkService
+}
+
+// NewShareLinkHandler は 新しい ShareLinkHandler インスタンスを作成します
+func NewShareLinkHandler(shareLinkService *services.ShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		shareLinkService: shareLinkService,
+	}
+}
+
+// CreateShareLink は 文書の共有リンクを作成します（所有者のみ）
+// expiresAt（RFC3339）と password は任意で、レスポンスの token はこの時だけ返す
+func (h *ShareLinkHandler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parseDocumentID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var req shareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	link, err := h.shareLinkService.CreateShareLink(docID, userID, req.ExpiresAt, req.Password)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, link)
+}
+
+// CreateSnapshotLink は 現在の文書の内容を固定したスナップショットの共有リンクを作成します（所有者のみ）
+// 以後に文書を編集しても、このリンクからは作成時点の内容（画像・ファイルは複製したもの）を閲覧する
+// expiresAt（RFC3339）と password は任意で、レスポンスの token はこの時だけ返す
+func (h *ShareLinkHandler) CreateSnapshotLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parseDocumentID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	// ボディは省略可能
+	var req shareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	link, err := h.shareLinkService.CreateSnapshotLink(r.Context(), docID, userID, req.ExpiresAt, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrWorkspaceStorageQuotaExceeded) || errors.Is(err, services.ErrStorageQuotaExceeded) {
+			apierror.Write(w, r, apierror.NewPayloadTooLarge(
+				"QUOTA_EXCEEDED", "ファイルを複製するためのストレージ容量が足りません", err,
+			))
+			return
+		}
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, link)
+}
+
+// shareLinkRequest は 共有リンクの作成リクエストです
+type shareLinkRequest struct {
+	ExpiresAt *time.Time `json:"expiresAt"`
+	Password  string     `json:"password"`
+}
+
+// GetShareLinks は 文書の共有リンク一覧を返します（所有者のみ、トークンは含まない）
+func (h *ShareLinkHandler) GetShareLinks(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parseDocumentID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	links, err := h.shareLinkService.ListShareLinks(docID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, links)
+}
+
+// RevokeShareLink は 共有リンクを無効化します（所有者のみ）
+func (h *ShareLinkHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	docID, appErr := parseDocumentID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+	linkID, err := strconv.Atoi(mux.Vars(r)["linkId"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_SHARE_LINK_ID", "共有リンクIDが不正です", err,
+		))
+		return
+	}
+
+	if err := h.shareLinkService.RevokeShareLink(docID, userID, linkID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Share link revoked successfully"})
+}
+
+// GetSharedDocument は 共有リンクの文書を認証なしで読み取り専用として返します
+// パスワード付きのリンクは X-Share-Password ヘッダーでパスワードを送る
+func (h *ShareLinkHandler) GetSharedDocument(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+
+	doc, err := h.shareLinkService.GetSharedDocument(r.Context(), token, r.Header.Get(SharePasswordHeader))
+	if err != nil {
+		var appErr *apierror.AppError
+		if errors.As(err, &appErr) && appErr.HTTPStatus == http.StatusTooManyRequests {
+			if details, ok := appErr.Details.(map[string]int); ok {
+				w.Header().Set("Retry-After", strconv.Itoa(details["retryAfter"]))
+			}
+		}
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// 共有リンクはブラウザ・中継サーバーにキャッシュさせない（無効化後に閲覧できないように）
+	w.Header().Set("Cache-Control", "no-store")
+	apierror.WriteJSON(w, http.StatusOK, doc)
+}
+
+// parseDocumentID は URL から文書IDを取り出します
+func parseDocumentID(r *http.Request) (int, *apierror.AppError) {
+	docID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, apierror.NewValidationError("INVALID_DOCUMENT_ID", "ドキュメントIDが不正です", err)
+	}
+	return docID, nil
+}
@@ -0,0 +1,35 @@
+package task
+
+import (
+	"net/http"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/services"
+)
+
+// TaskHandler は 「自分のタスク」関連のHTTPハンドラーです
+type TaskHandler struct {
+	taskService *services.TaskService
+}
+
+// NewTaskHandler は 新しい TaskHandler インスタンスを作成します
+func NewTaskHandler(taskService *services.TaskService) *TaskHandler {
+	return &TaskHandler{
+		taskService: taskService,
+	}
+}
+
+// GetTasks は ログインユーザーの全文書から未完了の todo ブロックを集めて返します
+func (h *TaskHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	tasks, err := h.taskService.ListOpenTasks(userID, time.Now())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, tasks)
+}
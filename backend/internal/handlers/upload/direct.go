@@ -0,0 +1,71 @@
+package upload
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+)
+
+// DirectUploadResponse は 直接アップロード用の署名付きURLのレスポンスです
+// ブラウザは URL に Method と Headers を付けてファイルを送り、その後 confirm を呼び出す
+type DirectUploadResponse struct {
+	ID        string            `json:"id"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// CreateDirectUpload は ストレージへ直接アップロードするための署名付きURLを発行します
+// リクエストボディは分割アップロードの作成と同じ（filename / contentType / size）
+func (h *ResumableUploadHandler) CreateDirectUpload(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req createSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	upload, uploadURL, err := h.sessionService.CreateDirectUpload(r.Context(), userID, middleware.GetWorkspaceIDFromContext(r.Context()), req.Filename, req.ContentType, req.Size, h.userStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, DirectUploadResponse{
+		ID:        upload.ID,
+		URL:       uploadURL,
+		Method:    http.MethodPut,
+		Headers:   h.sessionService.DirectUploadHeaders(upload.MimeType),
+		ExpiresAt: upload.ExpiresAt,
+	})
+}
+
+// ConfirmDirectUpload は 直接アップロードされたファイルのサイズと種類を確認して登録します
+func (h *ResumableUploadHandler) ConfirmDirectUpload(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	fileMeta, _, err := h.sessionService.ConfirmDirectUpload(r.Context(), userID, middleware.GetWorkspaceIDFromContext(r.Context()), mux.Vars(r)["id"], h.userStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, UploadResponse{
+		Success:  true,
+		FileID:   fileMeta.ID,
+		Filename: fileMeta.OriginalName,
+		URL:      fmt.Sprintf("/api/uploads/%s", filepath.Base(fileMeta.FileKey)),
+		Message:  "File uploaded successfully",
+	})
+}
@@ -1,19 +1,26 @@
 package upload
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"path/filepath"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 
 	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/cache"
+	"simple-notion-backend/internal/logctx"
 	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
 	"simple-notion-backend/internal/services"
 )
 
@@ -22,74 +29,45 @@ type UploadHandler struct {
 	fileService      *services.FileService
 	userStorageQuota int64
 
-	// 署名付きURLのキャッシュ（TTL: 23時間）
-	urlCache      map[string]*CachedURL
-	urlCacheMutex sync.RWMutex
-}
+	// 署名付きURLのキャッシュ（TTL: 23時間、Redis を設定した場合は複数のプロセスで共有する）
+	urlCache cache.Cache
 
-// CachedURL は キャッシュされた署名付きURL情報です
-type CachedURL struct {
-	URL       string
-	ExpiresAt time.Time
+	// アップロードの進捗の配信先
+	progressTracker *services.UploadProgressTracker
 }
 
+// urlCacheKeyPrefix は 署名付きURLのキャッシュのキーの先頭です（他の用途のキーと区別する）
+const urlCacheKeyPrefix = "presigned-url:"
+
+// redirectMaxAge は 署名付きURLへのリダイレクトをブラウザが保持する期間です
+const redirectMaxAge = 5 * time.Minute
+
 // NewUploadHandler は 新しい UploadHandler インスタンスを作成します
-func NewUploadHandler(fileService *services.FileService, userStorageQuota int64) *UploadHandler {
-	handler := &UploadHandler{
+func NewUploadHandler(fileService *services.FileService, userStorageQuota int64, urlCache cache.Cache, progressTracker *services.UploadProgressTracker) *UploadHandler {
+	return &UploadHandler{
 		fileService:      fileService,
 		userStorageQuota: userStorageQuota,
-		urlCache:         make(map[string]*CachedURL),
-	}
-
-	// キャッシュクリーンアップのゴルーチンを起動
-	go handler.cleanupExpiredCache()
-
-	return handler
-}
-
-// cleanupExpiredCache は 期限切れのキャッシュを定期的にクリーンアップします
-func (h *UploadHandler) cleanupExpiredCache() {
-	ticker := time.NewTicker(1 * time.Hour)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		h.urlCacheMutex.Lock()
-		now := time.Now()
-		for key, cached := range h.urlCache {
-			if now.After(cached.ExpiresAt) {
-				delete(h.urlCache, key)
-			}
-		}
-		h.urlCacheMutex.Unlock()
+		urlCache:         urlCache,
+		progressTracker:  progressTracker,
 	}
 }
 
 // getCachedURL は キャッシュから署名付きURLを取得します
-func (h *UploadHandler) getCachedURL(fileKey string) (string, bool) {
-	h.urlCacheMutex.RLock()
-	defer h.urlCacheMutex.RUnlock()
-
-	cached, exists := h.urlCache[fileKey]
-	if !exists {
-		return "", false
-	}
-
-	// 期限切れチェック
-	if time.Now().After(cached.ExpiresAt) {
+// キャッシュを読めない場合はキャッシュがないものとして扱う
+func (h *UploadHandler) getCachedURL(ctx context.Context, fileKey string) (string, bool) {
+	url, found, err := h.urlCache.Get(ctx, urlCacheKeyPrefix+fileKey)
+	if err != nil {
+		logctx.Printf(ctx, "Failed to read presigned URL cache: %v", err)
 		return "", false
 	}
-
-	return cached.URL, true
+	return url, found
 }
 
 // setCachedURL は 署名付きURLをキャッシュに保存します
-func (h *UploadHandler) setCachedURL(fileKey, url string, ttl time.Duration) {
-	h.urlCacheMutex.Lock()
-	defer h.urlCacheMutex.Unlock()
-
-	h.urlCache[fileKey] = &CachedURL{
-		URL:       url,
-		ExpiresAt: time.Now().Add(ttl),
+// 保存に失敗してもアップロード自体は成功しているため、ログに記録するだけにする
+func (h *UploadHandler) setCachedURL(ctx context.Context, fileKey, url string, ttl time.Duration) {
+	if err := h.urlCache.Set(ctx, urlCacheKeyPrefix+fileKey, url, ttl); err != nil {
+		logctx.Printf(ctx, "Failed to write presigned URL cache: %v", err)
 	}
 }
 
@@ -129,6 +107,10 @@ func (h *UploadHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// X-Upload-ID ヘッダーが指定されていれば、受信・保存の進捗を配信する
+	progress := startUploadProgress(h.progressTracker, r, userID)
+	defer progress.Close()
+
 	// multipart/form-dataの解析（最大32MB）
 	err := r.ParseMultipartForm(32 << 20)
 	if err != nil {
@@ -149,29 +131,30 @@ func (h *UploadHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 	defer file.Close()
 
 	// ストレージクォータチェック
-	err = h.fileService.CheckStorageQuota(r.Context(), userID, header.Size, h.userStorageQuota)
+	err = h.fileService.CheckStorageQuota(r.Context(), userID, middleware.GetWorkspaceIDFromContext(r.Context()), header.Size, h.userStorageQuota)
 	if err != nil {
-		if errors.Is(err, services.ErrStorageQuotaExceeded) {
-			apierror.Write(w, r, apierror.NewPayloadTooLarge(
-				"QUOTA_EXCEEDED", "ストレージ容量の上限を超えています", err,
-			))
-			return
-		}
-		apierror.Write(w, r, err)
+		apierror.Write(w, r, quotaError(err))
 		return
 	}
 
 	// ファイルアップロード
+	progress.Processing()
 	fileMeta, presignedURL, err := h.fileService.UploadImage(r.Context(), userID, file, header)
 	if err != nil {
+		if errors.Is(err, services.ErrContentTypeMismatch) {
+			apierror.Write(w, r, contentTypeMismatchError(err))
+			return
+		}
 		apierror.Write(w, r, apierror.NewInternal(
 			fmt.Errorf("failed to upload image: %w", err),
 		))
 		return
 	}
 
-	// キャッシュに保存（TTL: 23時間）
-	h.setCachedURL(filepath.Base(fileMeta.FileKey), presignedURL, 23*time.Hour)
+	progress.Complete(fileMeta.ID)
+
+	// キャッシュに保存（ServeFile のリダイレクトで再利用する）
+	h.cachePresignedURL(r.Context(), fileMeta.FileKey, presignedURL)
 
 	// 成功レスポンス（相対パスを返す）
 	response := UploadResponse{
@@ -185,6 +168,251 @@ func (h *UploadHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 	apierror.WriteJSON(w, http.StatusOK, response)
 }
 
+// UploadFile は 文書ファイル（PDF・Word など）のアップロードハンドラー
+func (h *UploadHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
+	// ユーザーIDを取得（認証ミドルウェアで設定済み）
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == 0 {
+		apierror.Write(w, r, apierror.NewUnauthorized(
+			"UNAUTHORIZED", "認証が必要です", nil,
+		))
+		return
+	}
+
+	// X-Upload-ID ヘッダーが指定されていれば、受信・保存の進捗を配信する
+	progress := startUploadProgress(h.progressTracker, r, userID)
+	defer progress.Close()
+
+	// multipart/form-dataの解析（最大32MB）
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FORM_DATA", "フォームデータの解析に失敗しました", err,
+		))
+		return
+	}
+
+	// ファイルの取得
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"NO_FILE_UPLOADED", "ファイルを選択してください", err,
+		))
+		return
+	}
+	defer file.Close()
+
+	// ストレージクォータチェック
+	err = h.fileService.CheckStorageQuota(r.Context(), userID, middleware.GetWorkspaceIDFromContext(r.Context()), header.Size, h.userStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, quotaError(err))
+		return
+	}
+
+	// ファイルアップロード
+	progress.Processing()
+	fileMeta, presignedURL, err := h.fileService.UploadFile(r.Context(), userID, file, header)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrFileTooLarge):
+			apierror.Write(w, r, apierror.NewPayloadTooLarge(
+				"FILE_TOO_LARGE", "ファイルサイズが上限を超えています", err,
+			))
+		case errors.Is(err, services.ErrUnsupportedFileType):
+			apierror.Write(w, r, apierror.NewValidationError(
+				"UNSUPPORTED_FILE_TYPE", "この種類のファイルはアップロードできません", err,
+			))
+		case errors.Is(err, services.ErrContentTypeMismatch):
+			apierror.Write(w, r, contentTypeMismatchError(err))
+		default:
+			apierror.Write(w, r, apierror.NewInternal(
+				fmt.Errorf("failed to upload file: %w", err),
+			))
+		}
+		return
+	}
+
+	progress.Complete(fileMeta.ID)
+
+	// キャッシュに保存（ServeFile のリダイレクトで再利用する）
+	h.cachePresignedURL(r.Context(), fileMeta.FileKey, presignedURL)
+
+	// 成功レスポンス（相対パスを返す）
+	response := UploadResponse{
+		Success:  true,
+		FileID:   fileMeta.ID,
+		Filename: fileMeta.OriginalName,
+		URL:      fmt.Sprintf("/api/uploads/%s", filepath.Base(fileMeta.FileKey)),
+		Message:  "File uploaded successfully",
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, response)
+}
+
+// UploadVideo は 動画（MP4・WebM）のアップロードハンドラー
+// 再生時間と映像の大きさを取得できた場合は、ファイルメタデータに記録する
+func (h *UploadHandler) UploadVideo(w http.ResponseWriter, r *http.Request) {
+	// ユーザーIDを取得（認証ミドルウェアで設定済み）
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == 0 {
+		apierror.Write(w, r, apierror.NewUnauthorized(
+			"UNAUTHORIZED", "認証が必要です", nil,
+		))
+		return
+	}
+
+	// X-Upload-ID ヘッダーが指定されていれば、受信・保存の進捗を配信する
+	progress := startUploadProgress(h.progressTracker, r, userID)
+	defer progress.Close()
+
+	// multipart/form-dataの解析（最大32MB）
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FORM_DATA", "フォームデータの解析に失敗しました", err,
+		))
+		return
+	}
+
+	// ファイルの取得
+	file, header, err := r.FormFile("video")
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"NO_FILE_UPLOADED", "動画ファイルを選択してください", err,
+		))
+		return
+	}
+	defer file.Close()
+
+	// ストレージクォータチェック
+	err = h.fileService.CheckStorageQuota(r.Context(), userID, middleware.GetWorkspaceIDFromContext(r.Context()), header.Size, h.userStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, quotaError(err))
+		return
+	}
+
+	// ファイルアップロード
+	progress.Processing()
+	fileMeta, presignedURL, err := h.fileService.UploadVideo(r.Context(), userID, file, header)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrFileTooLarge):
+			apierror.Write(w, r, apierror.NewPayloadTooLarge(
+				"FILE_TOO_LARGE", "ファイルサイズが上限を超えています", err,
+			))
+		case errors.Is(err, services.ErrUnsupportedFileType):
+			apierror.Write(w, r, apierror.NewValidationError(
+				"UNSUPPORTED_FILE_TYPE", "MP4 または WebM の動画を選択してください", err,
+			))
+		case errors.Is(err, services.ErrContentTypeMismatch):
+			apierror.Write(w, r, contentTypeMismatchError(err))
+		default:
+			apierror.Write(w, r, apierror.NewInternal(
+				fmt.Errorf("failed to upload video: %w", err),
+			))
+		}
+		return
+	}
+
+	progress.Complete(fileMeta.ID)
+
+	// キャッシュに保存（ServeFile のリダイレクトで再利用する）
+	h.cachePresignedURL(r.Context(), fileMeta.FileKey, presignedURL)
+
+	// 成功レスポンス（相対パスを返す）
+	response := UploadResponse{
+		Success:  true,
+		FileID:   fileMeta.ID,
+		Filename: fileMeta.OriginalName,
+		URL:      fmt.Sprintf("/api/uploads/%s", filepath.Base(fileMeta.FileKey)),
+		Message:  "Video uploaded successfully",
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, response)
+}
+
+// UploadAudio は 音声（MP3・WAV・M4A・Ogg）のアップロードハンドラー
+// 再生時間を取得できた場合は、ファイルメタデータに記録する
+func (h *UploadHandler) UploadAudio(w http.ResponseWriter, r *http.Request) {
+	// ユーザーIDを取得（認証ミドルウェアで設定済み）
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == 0 {
+		apierror.Write(w, r, apierror.NewUnauthorized(
+			"UNAUTHORIZED", "認証が必要です", nil,
+		))
+		return
+	}
+
+	// X-Upload-ID ヘッダーが指定されていれば、受信・保存の進捗を配信する
+	progress := startUploadProgress(h.progressTracker, r, userID)
+	defer progress.Close()
+
+	// multipart/form-dataの解析（最大32MB）
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FORM_DATA", "フォームデータの解析に失敗しました", err,
+		))
+		return
+	}
+
+	// ファイルの取得
+	file, header, err := r.FormFile("audio")
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"NO_FILE_UPLOADED", "音声ファイルを選択してください", err,
+		))
+		return
+	}
+	defer file.Close()
+
+	// ストレージクォータチェック
+	err = h.fileService.CheckStorageQuota(r.Context(), userID, middleware.GetWorkspaceIDFromContext(r.Context()), header.Size, h.userStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, quotaError(err))
+		return
+	}
+
+	// ファイルアップロード
+	progress.Processing()
+	fileMeta, presignedURL, err := h.fileService.UploadAudio(r.Context(), userID, file, header)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrFileTooLarge):
+			apierror.Write(w, r, apierror.NewPayloadTooLarge(
+				"FILE_TOO_LARGE", "ファイルサイズが上限を超えています", err,
+			))
+		case errors.Is(err, services.ErrUnsupportedFileType):
+			apierror.Write(w, r, apierror.NewValidationError(
+				"UNSUPPORTED_FILE_TYPE", "MP3・WAV・M4A・Ogg のいずれかの音声を選択してください", err,
+			))
+		case errors.Is(err, services.ErrContentTypeMismatch):
+			apierror.Write(w, r, contentTypeMismatchError(err))
+		default:
+			apierror.Write(w, r, apierror.NewInternal(
+				fmt.Errorf("failed to upload audio: %w", err),
+			))
+		}
+		return
+	}
+
+	progress.Complete(fileMeta.ID)
+
+	// キャッシュに保存（ServeFile のリダイレクトで再利用する）
+	h.cachePresignedURL(r.Context(), fileMeta.FileKey, presignedURL)
+
+	// 成功レスポンス（相対パスを返す）
+	response := UploadResponse{
+		Success:  true,
+		FileID:   fileMeta.ID,
+		Filename: fileMeta.OriginalName,
+		URL:      fmt.Sprintf("/api/uploads/%s", filepath.Base(fileMeta.FileKey)),
+		Message:  "Audio uploaded successfully",
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, response)
+}
+
 // GetPresignedURL は ファイルの署名付きURLを取得するハンドラー
 func (h *UploadHandler) GetPresignedURL(w http.ResponseWriter, r *http.Request) {
 	// ユーザーIDを取得
@@ -208,7 +436,8 @@ func (h *UploadHandler) GetPresignedURL(w http.ResponseWriter, r *http.Request)
 	}
 
 	// 署名付きURLを取得（repository からの ErrNotFound は自動で 404 にマップされる）
-	presignedURL, err := h.fileService.GetPresignedURL(r.Context(), fileID, userID)
+	// ?size=thumb などでサムネイルの URL を取得できる
+	presignedURL, err := h.fileService.GetPresignedURL(r.Context(), fileID, userID, r.URL.Query().Get("size"))
 	if err != nil {
 		apierror.Write(w, r, err)
 		return
@@ -237,10 +466,17 @@ func (h *UploadHandler) GetStorageUsage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// ユーザーのクォータ（個別に設定されていない場合は既定値）を取得
+	quota, err := h.fileService.GetStorageQuota(r.Context(), userID, h.userStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
 	// 使用率を計算
 	usageRate := 0.0
-	if h.userStorageQuota > 0 {
-		usageRate = (float64(usage.TotalBytes) / float64(h.userStorageQuota)) * 100
+	if quota.QuotaBytes > 0 {
+		usageRate = (float64(usage.TotalBytes) / float64(quota.QuotaBytes)) * 100
 	}
 
 	apierror.WriteJSON(w, http.StatusOK, StorageUsageResponse{
@@ -248,13 +484,15 @@ func (h *UploadHandler) GetStorageUsage(w http.ResponseWriter, r *http.Request)
 		FileCount:  usage.FileCount,
 		TotalBytes: usage.TotalBytes,
 		TotalMB:    usage.TotalMB,
-		QuotaBytes: h.userStorageQuota,
-		QuotaMB:    float64(h.userStorageQuota) / (1024 * 1024),
+		QuotaBytes: quota.QuotaBytes,
+		QuotaMB:    float64(quota.QuotaBytes) / (1024 * 1024),
 		UsageRate:  usageRate,
 	})
 }
 
-// ServeFile は ストレージからファイルを配信するハンドラー（プロキシ方式）
+// ServeFile は ファイル名からファイルを配信するハンドラー
+// 画像・動画・音声はストレージの署名付きURLにリダイレクトし、転送をストレージに任せる
+// 文書ファイル・SVG は配信時のヘッダー（Content-Disposition・nosniff・CSP）が必要なため、ストレージから中継する
 func (h *UploadHandler) ServeFile(w http.ResponseWriter, r *http.Request) {
 	// パラメータからファイル名を取得
 	vars := mux.Vars(r)
@@ -268,8 +506,68 @@ func (h *UploadHandler) ServeFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 配信するファイルを決定（?size=thumb などでサムネイルを配信する）
+	fileKey, err := services.ResolveFileKey(fileMeta, r.URL.Query().Get("size"))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	contentType, variant := fileMeta.MimeType, r.URL.Query().Get("size")
+	if fileKey != fileMeta.FileKey {
+		contentType = mime.TypeByExtension(filepath.Ext(fileKey))
+	} else if fileMeta.WebPFileKey != "" {
+		// 元の画像を残して WebP を保存している場合は、WebP に対応したブラウザに WebP を配信する
+		w.Header().Set("Vary", "Accept")
+		if acceptsWebP(r) {
+			fileKey, contentType, variant = fileMeta.WebPFileKey, "image/webp", "webp"
+		}
+	}
+
+	// 内容が変わっていなければ本文を返さない（ブラウザ・中継するプロキシのキャッシュの再検証）
+	etag := fileETag(fileMeta.ContentHash, variant)
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	w.Header().Set("Last-Modified", fileMeta.UploadedAt.UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "public, max-age=86400") // 24時間キャッシュ
+	if notModified(r, etag, fileMeta.UploadedAt) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if redirectable(fileMeta, contentType) {
+		h.redirectToStorage(w, r, fileKey)
+		return
+	}
+
+	// Range ヘッダーで指定された範囲を決定（動画のシークなど。サイズが分かる元のファイルのみ対応する）
+	// If-Range で指定された内容から変わっている場合はファイル全体を返す
+	status := http.StatusOK
+	var byteRange *httpByteRange
+	if fileKey == fileMeta.FileKey {
+		w.Header().Set("Accept-Ranges", "bytes")
+		rangeHeader := r.Header.Get("Range")
+		if !ifRangeMatches(r, etag, fileMeta.UploadedAt) {
+			rangeHeader = ""
+		}
+		var satisfiable bool
+		byteRange, satisfiable = parseByteRange(rangeHeader, fileMeta.FileSize)
+		if !satisfiable {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", fileMeta.FileSize))
+			apierror.Write(w, r, apierror.NewRangeNotSatisfiable(
+				"RANGE_NOT_SATISFIABLE", "指定された範囲はファイルの大きさを超えています", nil,
+			))
+			return
+		}
+	}
+
 	// ストレージからファイルを取得
-	object, err := h.fileService.GetFileObject(r.Context(), fileMeta.FileKey)
+	var object io.ReadCloser
+	if byteRange != nil {
+		object, err = h.fileService.GetFileObjectRange(r.Context(), fileKey, byteRange.start, byteRange.length)
+	} else {
+		object, err = h.fileService.GetFileObject(r.Context(), fileKey)
+	}
 	if err != nil {
 		apierror.Write(w, r, apierror.NewInternal(
 			fmt.Errorf("failed to retrieve file: %w", err),
@@ -278,9 +576,29 @@ func (h *UploadHandler) ServeFile(w http.ResponseWriter, r *http.Request) {
 	}
 	defer object.Close()
 
+	// 動画などの大きなファイルはサーバーの書き込みタイムアウトを超えるため、このレスポンスでは解除する
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
 	// Content-Typeを設定
-	w.Header().Set("Content-Type", fileMeta.MimeType)
-	w.Header().Set("Cache-Control", "public, max-age=86400") // 24時間キャッシュ
+	w.Header().Set("Content-Type", contentType)
+	if fileMeta.FileType == "file" {
+		// 文書ファイルはブラウザ内で開かずダウンロードさせる
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Disposition", attachmentDisposition(fileMeta.OriginalName))
+	}
+	if contentType == "image/svg+xml" {
+		// 設定で SVG を許可した場合、直接開かれても SVG 内のスクリプトを実行させない
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	}
+	if byteRange != nil {
+		status = http.StatusPartialContent
+		w.Header().Set("Content-Range", fmt.Sprintf(
+			"bytes %d-%d/%d", byteRange.start, byteRange.start+byteRange.length-1, fileMeta.FileSize,
+		))
+		w.Header().Set("Content-Length", strconv.FormatInt(byteRange.length, 10))
+	}
+	w.WriteHeader(status)
 
 	// ファイルをストリーミング
 	if _, err := io.Copy(w, object); err != nil {
@@ -288,3 +606,410 @@ func (h *UploadHandler) ServeFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// redirectable は 署名付きURLへのリダイレクトで配信できるファイルかどうかを返します
+// ストレージからの応答には独自のヘッダーを付けられないため、ヘッダーが必要なファイルは中継する
+func redirectable(fileMeta *models.FileMetadata, contentType string) bool {
+	return fileMeta.FileType != "file" && contentType != "image/svg+xml"
+}
+
+// redirectToStorage は 配信するファイルの署名付きURLにリダイレクトします
+// 同じファイルへのリクエストごとに署名し直さないよう、URL はキャッシュに保存して再利用する
+func (h *UploadHandler) redirectToStorage(w http.ResponseWriter, r *http.Request, fileKey string) {
+	presignedURL, found := h.getCachedURL(r.Context(), fileKey)
+	if !found {
+		var err error
+		presignedURL, err = h.fileService.GetPresignedURLForKey(r.Context(), fileKey)
+		if err != nil {
+			apierror.Write(w, r, apierror.NewInternal(err))
+			return
+		}
+		h.cachePresignedURL(r.Context(), fileKey, presignedURL)
+	}
+
+	// リダイレクト先の URL は期限付きのため、ブラウザには短い間だけ保持させる（中継するプロキシには保存させない）
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(redirectMaxAge.Seconds())))
+	http.Redirect(w, r, presignedURL, http.StatusFound)
+}
+
+// cachePresignedURL は 生成した署名付きURLを有効期間に合わせた期間だけキャッシュに保存します
+func (h *UploadHandler) cachePresignedURL(ctx context.Context, fileKey, url string) {
+	if ttl := presignedURLCacheTTL(h.fileService.PresignExpiry()); ttl > 0 {
+		h.setCachedURL(ctx, fileKey, url, ttl)
+	}
+}
+
+// presignedURLCacheTTL は 署名付きURLをキャッシュに保存する期間を返します
+// キャッシュから返した URL がブラウザに保持されている間（redirectMaxAge）に期限切れにならないよう、有効期間より短くする
+func presignedURLCacheTTL(expires time.Duration) time.Duration {
+	return expires - expires/24 - redirectMaxAge
+}
+
+// UpdateFile は ファイルの名前・代替テキスト・説明を変更するハンドラー
+// 指定しなかった項目は変更しない
+func (h *UploadHandler) UpdateFile(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	fileID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FILE_ID", "ファイルIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	var update models.FileDetailsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	fileMeta, err := h.fileService.UpdateFileDetails(r.Context(), fileID, userID, update)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, fileMeta)
+}
+
+// attachFileRequest は ファイルの紐付けリクエストです（blockId を省略した場合は文書のみに紐付ける）
+type attachFileRequest struct {
+	DocumentID int  `json:"documentId"`
+	BlockID    *int `json:"blockId"`
+}
+
+// AttachFile は ファイルを文書・ブロックに紐付けるハンドラー
+func (h *UploadHandler) AttachFile(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	fileID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FILE_ID", "ファイルIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	var req attachFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DocumentID <= 0 {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "documentId を指定してください", err,
+		))
+		return
+	}
+
+	if err := h.fileService.AttachFile(r.Context(), fileID, userID, req.DocumentID, req.BlockID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "File attached"})
+}
+
+// DetachFile は ファイルの紐付けを外すハンドラー（猶予期間の経過後に孤立ファイルとして削除される）
+func (h *UploadHandler) DetachFile(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	fileID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FILE_ID", "ファイルIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	if err := h.fileService.DetachFile(r.Context(), fileID, userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "File detached"})
+}
+
+// DownloadFile は ファイルを元のファイル名で保存させるダウンロードハンドラー
+func (h *UploadHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	fileID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FILE_ID", "ファイルIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	// 他のユーザーのファイルは 403、削除済みのファイルは 404 になる
+	fileMeta, object, err := h.fileService.OpenFileForDownload(r.Context(), fileID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer object.Close()
+
+	w.Header().Set("Content-Type", fileMeta.MimeType)
+	w.Header().Set("Content-Length", strconv.FormatInt(fileMeta.FileSize, 10))
+	w.Header().Set("Content-Disposition", attachmentDisposition(fileMeta.OriginalName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	// ファイルをストリーミング
+	if _, err := io.Copy(w, object); err != nil {
+		// ヘッダー送信済みのためレスポンスは書き換えられない
+		return
+	}
+}
+
+// ListFiles は ユーザーのファイル一覧（すべての添付ファイルの管理画面用）を返すハンドラー
+// type: ファイルの種類、mime: MIME タイプ（image/* で前方一致）、from / to: アップロード日時の範囲、
+// documentId: 添付先の文書、sort: uploadedAt / name / size、order: asc / desc、limit / cursor: ページング（cursor は前のページの nextCursor）
+// from / to は RFC3339 の日時または YYYY-MM-DD の日付（to に日付を指定した場合はその日を含む）
+func (h *UploadHandler) ListFiles(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == 0 {
+		apierror.Write(w, r, apierror.NewUnauthorized(
+			"UNAUTHORIZED", "認証が必要です", nil,
+		))
+		return
+	}
+
+	query := r.URL.Query()
+	opts := models.FileListOptions{
+		FileType: query.Get("type"),
+		MimeType: query.Get("mime"),
+		Sort:     query.Get("sort"),
+		Order:    query.Get("order"),
+	}
+
+	var err error
+	if opts.UploadedFrom, err = parseFileListTime(query.Get("from"), false); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DATE", "from には RFC3339 の日時または YYYY-MM-DD の日付を指定してください", err,
+		))
+		return
+	}
+	if opts.UploadedTo, err = parseFileListTime(query.Get("to"), true); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DATE", "to には RFC3339 の日時または YYYY-MM-DD の日付を指定してください", err,
+		))
+		return
+	}
+	if raw := query.Get("documentId"); raw != "" {
+		documentID, err := strconv.Atoi(raw)
+		if err != nil {
+			apierror.Write(w, r, apierror.NewValidationError(
+				"INVALID_DOCUMENT_ID", "文書IDは数値である必要があります", err,
+			))
+			return
+		}
+		opts.DocumentID = &documentID
+	}
+	page, err := pagination.Parse(query)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	opts.Limit, opts.After = page.Limit, page.After
+
+	files, err := h.fileService.ListFiles(r.Context(), userID, opts)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, files)
+}
+
+// parseFileListTime は ファイル一覧の from / to を解析します（空の場合は nil）
+// 日付のみの場合はその日の 0 時（UTC）、endOfDay が true の場合は翌日の 0 時にする
+func parseFileListTime(raw string, endOfDay bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if date, err := time.Parse(time.DateOnly, raw); err == nil {
+		if endOfDay {
+			date = date.AddDate(0, 0, 1)
+		}
+		return &date, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ExportFiles は ユーザーのすべてのファイルを ZIP にまとめてダウンロードさせるハンドラー
+// アカウントの削除や移行の前に使う。ストレージから1ファイルずつ読み込んでそのままレスポンスに書き出す
+func (h *UploadHandler) ExportFiles(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == 0 {
+		apierror.Write(w, r, apierror.NewUnauthorized(
+			"UNAUTHORIZED", "認証が必要です", nil,
+		))
+		return
+	}
+
+	files, err := h.fileService.ListUserFiles(r.Context(), userID)
+	if err != nil {
+		apierror.Write(w, r, apierror.NewInternal(err))
+		return
+	}
+
+	// ファイルの数や大きさによってはサーバーの書き込みタイムアウトを超えるため、このレスポンスでは解除する
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	filename := fmt.Sprintf("files-%s.zip", time.Now().Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", attachmentDisposition(filename))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	if err := h.fileService.WriteFilesZip(r.Context(), w, files); err != nil {
+		// ヘッダー送信済みのためレスポンスは書き換えられない（途中で切れた ZIP はクライアント側で展開に失敗する）
+		logctx.Printf(r.Context(), "failed to export files for user %d: %v", userID, err)
+	}
+}
+
+// attachmentDisposition は ブラウザに filename の名前で保存させる Content-Disposition を作成します
+// ASCII 以外の文字を含むファイル名は RFC 2231 形式（filename*）になる
+func attachmentDisposition(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
+
+// contentTypeMismatchError は ファイルの中身が申告された MIME タイプと一致しない場合のエラーを作成します
+func contentTypeMismatchError(err error) *apierror.AppError {
+	return apierror.NewValidationError(
+		"CONTENT_TYPE_MISMATCH", "ファイルの中身が指定された種類と一致しません", err,
+	)
+}
+
+// quotaError は ストレージクォータのチェックに失敗したエラーを変換します（クォータ超過は 413）
+func quotaError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrWorkspaceStorageQuotaExceeded):
+		return apierror.NewPayloadTooLarge(
+			"WORKSPACE_QUOTA_EXCEEDED", "ワークスペースのストレージ容量の上限を超えています", err,
+		)
+	case errors.Is(err, services.ErrStorageQuotaExceeded):
+		return apierror.NewPayloadTooLarge(
+			"QUOTA_EXCEEDED", "ストレージ容量の上限を超えています", err,
+		)
+	}
+	return err
+}
+
+// acceptsWebP は リクエストの Accept ヘッダーが WebP を受け付けるかを返します
+func acceptsWebP(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(strings.TrimSpace(accept), ";")
+		if strings.EqualFold(mediaType, "image/webp") {
+			return true
+		}
+	}
+	return false
+}
+
+// fileETag は ファイルの内容のハッシュから ETag を作成します（ハッシュが記録されていない場合は空）
+// サムネイル・WebP は元のファイルから作るため、variant（サイズ名・"webp"）で区別する
+func fileETag(contentHash, variant string) string {
+	if contentHash == "" {
+		return ""
+	}
+	if variant != "" {
+		return fmt.Sprintf("%q", contentHash+"-"+variant)
+	}
+	return fmt.Sprintf("%q", contentHash)
+}
+
+// notModified は 条件付きリクエスト（If-None-Match / If-Modified-Since）に対して 304 を返せるかを返します
+// If-None-Match がある場合は If-Modified-Since を使わない
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// ifRangeMatches は If-Range ヘッダー（ETag または日時）が現在の内容と一致するか（Range を使ってよいか）を返します
+// ヘッダーがない場合は true
+func ifRangeMatches(r *http.Request, etag string, modified time.Time) bool {
+	ifRange := strings.TrimSpace(r.Header.Get("If-Range"))
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return etag != "" && ifRange == etag
+	}
+
+	at, err := http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+	return modified.Truncate(time.Second).Equal(at)
+}
+
+// httpByteRange は Range ヘッダーで指定された取得範囲です
+type httpByteRange struct {
+	start  int64
+	length int64
+}
+
+// parseByteRange は Range ヘッダー（"bytes=0-499" / "bytes=500-" / "bytes=-500"）を size バイトのファイルの範囲に変換します
+// ヘッダーがない場合・解釈できない場合・複数の範囲を指定した場合は nil（ファイル全体を返す）
+// 範囲がファイルの外にある場合は satisfiable が false になる
+func parseByteRange(header string, size int64) (byteRange *httpByteRange, satisfiable bool) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, true
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, true
+	}
+
+	if first == "" {
+		// 末尾から last バイト
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return nil, true
+		}
+		if suffix == 0 || size == 0 {
+			return nil, false
+		}
+		suffix = min(suffix, size)
+		return &httpByteRange{start: size - suffix, length: suffix}, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil, true
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return nil, true
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return nil, false
+	}
+	return &httpByteRange{start: start, length: end - start + 1}, true
+}
@@ -1,13 +1,26 @@
 package upload
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"simple-notion-backend/internal/cache"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/requestid"
+	"simple-notion-backend/internal/services"
+	"simple-notion-backend/internal/storage"
 )
 
 // TestNewUploadHandler は UploadHandler の初期化テスト
 func TestNewUploadHandler(t *testing.T) {
-	handler := NewUploadHandler(nil, 100*1024*1024)
+	handler := NewUploadHandler(nil, 100*1024*1024, cache.NewMemoryCache(), nil)
 
 	if handler == nil {
 		t.Fatal("Expected handler to be non-nil")
@@ -24,15 +37,15 @@ func TestNewUploadHandler(t *testing.T) {
 
 // TestCacheOperations は キャッシュ操作のテスト
 func TestCacheOperations(t *testing.T) {
-	handler := NewUploadHandler(nil, 100*1024*1024)
+	handler := NewUploadHandler(nil, 100*1024*1024, cache.NewMemoryCache(), nil)
 
 	// キャッシュに保存
 	testFileKey := "test-file-key"
 	testURL := "https://example.com/test-url"
-	handler.setCachedURL(testFileKey, testURL, 1*time.Hour) // 1時間
+	handler.setCachedURL(context.Background(), testFileKey, testURL, 1*time.Hour) // 1時間
 
 	// キャッシュから取得
-	cachedURL, found := handler.getCachedURL(testFileKey)
+	cachedURL, found := handler.getCachedURL(context.Background(), testFileKey)
 	if !found {
 		t.Error("Expected cached URL to be found")
 	}
@@ -42,8 +55,315 @@ func TestCacheOperations(t *testing.T) {
 	}
 
 	// 存在しないキーの取得
-	_, found = handler.getCachedURL("non-existent-key")
+	_, found = handler.getCachedURL(context.Background(), "non-existent-key")
 	if found {
 		t.Error("Expected non-existent key to not be found")
 	}
 }
+
+// failingCache は 読み書きに失敗するテスト用のキャッシュです
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, key string) (string, bool, error) {
+	return "", false, errors.New("cache unavailable")
+}
+
+func (failingCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return errors.New("cache unavailable")
+}
+
+func (failingCache) Close() error { return nil }
+
+// TestCacheFailureLogsRequestID は キャッシュの失敗のログにリクエスト ID が含まれることのテスト
+func TestCacheFailureLogsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(output)
+
+	handler := NewUploadHandler(nil, 100*1024*1024, failingCache{}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/uploads/a.png", nil)
+	ctx := requestid.NewContext(req.Context(), "req-upload-1")
+
+	if _, found := handler.getCachedURL(ctx, "a.png"); found {
+		t.Error("Expected cached URL to not be found")
+	}
+	handler.setCachedURL(ctx, "a.png", "https://example.com/a.png", time.Hour)
+
+	for _, want := range []string{"Failed to read presigned URL cache", "Failed to write presigned URL cache"} {
+		found := false
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if strings.Contains(line, want) {
+				found = true
+				if !strings.Contains(line, "request_id=req-upload-1") {
+					t.Errorf("log line = %q, want request_id=req-upload-1", line)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("log = %q, want a line containing %q", buf.String(), want)
+		}
+	}
+}
+
+// TestAttachmentDisposition は attachmentDisposition のテスト
+func TestAttachmentDisposition(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		expected string
+	}{
+		{name: "ASCII のファイル名", filename: "report.pdf", expected: "attachment; filename=report.pdf"},
+		{name: "空白を含むファイル名", filename: "annual report.pdf", expected: `attachment; filename="annual report.pdf"`},
+		{name: "日本語のファイル名", filename: "議事録.pdf", expected: "attachment; filename*=utf-8''%E8%AD%B0%E4%BA%8B%E9%8C%B2.pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attachmentDisposition(tt.filename); got != tt.expected {
+				t.Errorf("attachmentDisposition(%q) = %q, want %q", tt.filename, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseFileListTime は ファイル一覧の from / to の解析のテスト
+func TestParseFileListTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		endOfDay bool
+		expected *time.Time
+		wantErr  bool
+	}{
+		{name: "空の場合は指定なし", raw: ""},
+		{name: "日付（from）", raw: "2026-03-01", expected: ptrTime(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))},
+		{name: "日付（to はその日を含む）", raw: "2026-03-01", endOfDay: true, expected: ptrTime(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))},
+		{name: "RFC3339 の日時", raw: "2026-03-01T09:30:00+09:00", endOfDay: true, expected: ptrTime(time.Date(2026, 3, 1, 0, 30, 0, 0, time.UTC))},
+		{name: "不正な形式", raw: "2026/03/01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFileListTime(tt.raw, tt.endOfDay)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseFileListTime(%q) error = nil, want error", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFileListTime(%q) error = %v", tt.raw, err)
+			}
+			if (got == nil) != (tt.expected == nil) || (got != nil && !got.Equal(*tt.expected)) {
+				t.Errorf("parseFileListTime(%q) = %v, want %v", tt.raw, got, tt.expected)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestAcceptsWebP(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		expected bool
+	}{
+		{name: "ブラウザの画像リクエスト", accept: "image/avif,image/webp,image/apng,image/*,*/*;q=0.8", expected: true},
+		{name: "パラメータ付き", accept: "image/webp;q=0.9, image/png", expected: true},
+		{name: "WebP を含まない", accept: "image/png,image/*;q=0.8", expected: false},
+		{name: "Accept なし", accept: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/files/a.png", nil)
+			r.Header.Set("Accept", tt.accept)
+			if got := acceptsWebP(r); got != tt.expected {
+				t.Errorf("acceptsWebP(%q) = %v, want %v", tt.accept, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		expected    *httpByteRange
+		satisfiable bool
+	}{
+		{name: "Range なし", header: "", expected: nil, satisfiable: true},
+		{name: "先頭から", header: "bytes=0-499", expected: &httpByteRange{start: 0, length: 500}, satisfiable: true},
+		{name: "末尾まで", header: "bytes=900-", expected: &httpByteRange{start: 900, length: 100}, satisfiable: true},
+		{name: "末尾を超える終了位置", header: "bytes=900-5000", expected: &httpByteRange{start: 900, length: 100}, satisfiable: true},
+		{name: "末尾から", header: "bytes=-200", expected: &httpByteRange{start: 800, length: 200}, satisfiable: true},
+		{name: "ファイルより大きい末尾からの範囲", header: "bytes=-5000", expected: &httpByteRange{start: 0, length: 1000}, satisfiable: true},
+		{name: "複数の範囲（全体を返す）", header: "bytes=0-1,5-6", expected: nil, satisfiable: true},
+		{name: "解釈できない", header: "items=0-1", expected: nil, satisfiable: true},
+		{name: "ファイルの外", header: "bytes=1000-", expected: nil, satisfiable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, satisfiable := parseByteRange(tt.header, 1000)
+			if satisfiable != tt.satisfiable {
+				t.Fatalf("parseByteRange(%q) satisfiable = %v, want %v", tt.header, satisfiable, tt.satisfiable)
+			}
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("parseByteRange(%q) = %+v, want %+v", tt.header, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	etag := fileETag("abc123", "")
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		etag     string
+		expected bool
+	}{
+		{name: "条件なし", headers: nil, etag: etag, expected: false},
+		{name: "ETag が一致", headers: map[string]string{"If-None-Match": `"abc123"`}, etag: etag, expected: true},
+		{name: "弱い ETag を含む複数指定", headers: map[string]string{"If-None-Match": `"other", W/"abc123"`}, etag: etag, expected: true},
+		{name: "ETag が異なる", headers: map[string]string{"If-None-Match": `"other"`}, etag: etag, expected: false},
+		{name: "ETag がないファイル", headers: map[string]string{"If-None-Match": "*"}, etag: "", expected: false},
+		{name: "更新日時以降", headers: map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, etag: etag, expected: true},
+		{name: "更新日時より前", headers: map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, etag: etag, expected: false},
+		{
+			name:     "If-None-Match を優先",
+			headers:  map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": modified.Format(http.TimeFormat)},
+			etag:     etag,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/files/a.png", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := notModified(r, tt.etag, modified); got != tt.expected {
+				t.Errorf("notModified() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIfRangeMatches(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	etag := fileETag("abc123", "")
+
+	tests := []struct {
+		name     string
+		ifRange  string
+		expected bool
+	}{
+		{name: "If-Range なし", ifRange: "", expected: true},
+		{name: "ETag が一致", ifRange: `"abc123"`, expected: true},
+		{name: "ETag が異なる", ifRange: `"other"`, expected: false},
+		{name: "日時が一致", ifRange: modified.Format(http.TimeFormat), expected: true},
+		{name: "日時が異なる", ifRange: modified.Add(-time.Hour).Format(http.TimeFormat), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/files/a.mp4", nil)
+			if tt.ifRange != "" {
+				r.Header.Set("If-Range", tt.ifRange)
+			}
+			if got := ifRangeMatches(r, etag, modified); got != tt.expected {
+				t.Errorf("ifRangeMatches(%q) = %v, want %v", tt.ifRange, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRedirectable(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileType    string
+		contentType string
+		expected    bool
+	}{
+		{name: "画像はリダイレクトする", fileType: "image", contentType: "image/png", expected: true},
+		{name: "動画はリダイレクトする", fileType: "video", contentType: "video/mp4", expected: true},
+		{name: "文書ファイルは中継する", fileType: "file", contentType: "application/pdf", expected: false},
+		{name: "SVG は中継する", fileType: "image", contentType: "image/svg+xml", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileMeta := &models.FileMetadata{FileType: tt.fileType}
+			if got := redirectable(fileMeta, tt.contentType); got != tt.expected {
+				t.Errorf("redirectable(%q, %q) = %v, want %v", tt.fileType, tt.contentType, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRedirectToStorage は 署名付きURLへのリダイレクトとキャッシュの再利用のテスト
+func TestRedirectToStorage(t *testing.T) {
+	objectStorage, err := storage.NewLocalStorage(t.TempDir(), "http://localhost:8080", []byte("test-signing-key"))
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+	fileService := services.NewFileService(nil, objectStorage, 0, 0, 0, 3600, nil, nil, nil, nil)
+	urlCache := cache.NewMemoryCache()
+	handler := NewUploadHandler(fileService, 100*1024*1024, urlCache, nil)
+
+	serve := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/api/files/a.png", nil)
+		w := httptest.NewRecorder()
+		handler.redirectToStorage(w, r, "images/1/a.png")
+		return w
+	}
+
+	first := serve()
+	if first.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", first.Code, http.StatusFound)
+	}
+	location := first.Header().Get("Location")
+	if !strings.Contains(location, "images/1/a.png") {
+		t.Errorf("Location = %q, want presigned URL for images/1/a.png", location)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "private, max-age=300" {
+		t.Errorf("Cache-Control = %q, want private, max-age=300", got)
+	}
+
+	// 2回目はキャッシュした URL を返す
+	if err := urlCache.Set(context.Background(), urlCacheKeyPrefix+"images/1/a.png", "https://cdn.example.com/cached", time.Hour); err != nil {
+		t.Fatalf("cache.Set() error = %v", err)
+	}
+	if got := serve().Header().Get("Location"); got != "https://cdn.example.com/cached" {
+		t.Errorf("Location = %q, want cached URL", got)
+	}
+}
+
+func TestPresignedURLCacheTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		expires  time.Duration
+		expected time.Duration
+	}{
+		{name: "24時間", expires: 24 * time.Hour, expected: 23*time.Hour - redirectMaxAge},
+		{name: "1時間", expires: time.Hour, expected: time.Hour - 150*time.Second - redirectMaxAge},
+		{name: "有効期間が短い場合は0以下（保存しない）", expires: 5 * time.Minute, expected: -12500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := presignedURLCacheTTL(tt.expires); got != tt.expected {
+				t.Errorf("presignedURLCacheTTL(%v) = %v, want %v", tt.expires, got, tt.expected)
+			}
+		})
+	}
+}
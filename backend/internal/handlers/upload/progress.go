@@ -0,0 +1,240 @@
+package upload
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// uploadIDHeader は アップロードの進捗を配信する場合に、クライアントが生成したアップロードIDを指定するヘッダーです
+const uploadIDHeader = "X-Upload-ID"
+
+// uploadIDPattern は アップロードIDとして受け付ける形式です（UUID など）
+var uploadIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+const (
+	// progressPublishInterval は 受信中の進捗を配信する最短の間隔です
+	progressPublishInterval = 200 * time.Millisecond
+	// progressHeartbeatInterval は 接続を維持するためのコメントを送る間隔です
+	progressHeartbeatInterval = 15 * time.Second
+)
+
+// ProgressHandler は アップロードの進捗を Server-Sent Events で配信するHTTPハンドラーです
+// エディターはアップロードを始める前に GET /api/upload/progress/{id} を購読し、同じIDを X-Upload-ID ヘッダーに付けてアップロードする
+// 分割アップロードの進捗はセッションIDで購読する
+type ProgressHandler struct {
+	tracker *services.UploadProgressTracker
+}
+
+// NewProgressHandler は 新しい ProgressHandler インスタンスを作成します
+func NewProgressHandler(tracker *services.UploadProgressTracker) *ProgressHandler {
+	return &ProgressHandler{tracker: tracker}
+}
+
+// StreamProgress は アップロードの進捗を progress イベントとして送ります
+// アップロードが完了または失敗した時点で接続を閉じる
+func (h *ProgressHandler) StreamProgress(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	uploadID := mux.Vars(r)["id"]
+	if !uploadIDPattern.MatchString(uploadID) {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_UPLOAD_ID", "アップロードIDの形式が不正です", nil,
+		))
+		return
+	}
+
+	// 進捗の配信はアップロードが終わるまで続くため、書き込みのタイムアウトを解除する
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		apierror.Write(w, r, apierror.NewInternal(fmt.Errorf("failed to clear write deadline: %w", err)))
+		return
+	}
+
+	updates, unsubscribe := h.tracker.Subscribe(userID, uploadID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // リバースプロキシでバッファリングしない
+	w.WriteHeader(http.StatusOK)
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(progressHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case progress := <-updates:
+			data, err := json.Marshal(progress)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: progress\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			if progress.Finished() {
+				controller.Flush()
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// uploadProgress は 1つのアップロードリクエストの進捗を配信します
+// X-Upload-ID ヘッダーがないリクエストでは何もしない（nil のまま使える）
+type uploadProgress struct {
+	tracker  *services.UploadProgressTracker
+	userID   int
+	uploadID string
+
+	mu       sync.Mutex
+	current  models.UploadProgress
+	lastSent time.Time
+}
+
+// startUploadProgress は X-Upload-ID ヘッダーで指定されたアップロードの進捗の配信を始めます
+// リクエストボディを、受信したバイト数を配信するリーダーに置き換える
+// 呼び出し側は Close を defer し、成功した場合は Complete を呼ぶ
+func startUploadProgress(tracker *services.UploadProgressTracker, r *http.Request, userID int) *uploadProgress {
+	uploadID := r.Header.Get(uploadIDHeader)
+	if tracker == nil || !uploadIDPattern.MatchString(uploadID) {
+		return nil
+	}
+
+	total := r.ContentLength
+	if total < 0 {
+		total = 0
+	}
+	p := newUploadProgress(tracker, userID, uploadID, 0, total)
+	p.trackBody(r)
+	return p
+}
+
+// startChunkProgress は 分割アップロードのチャンクの受信の進捗を、セッションIDをアップロードIDとして配信します
+// 受信済みのオフセットから数える。チャンクは同じオフセットから再送できるため、受信に失敗しても Close は呼ばない
+func startChunkProgress(tracker *services.UploadProgressTracker, r *http.Request, userID int, session *models.UploadSession) *uploadProgress {
+	if tracker == nil {
+		return nil
+	}
+	p := newUploadProgress(tracker, userID, session.ID, session.Offset, session.TotalSize)
+	p.trackBody(r)
+	return p
+}
+
+// startSessionProgress は 受信済みの分割アップロードの結合・保存の進捗を、セッションIDをアップロードIDとして配信します
+// 受信していないチャンクがある場合は（続きを送れば完了できるため）何も配信しない
+// 呼び出し側は Close を defer し、成功した場合は Complete を呼ぶ
+func startSessionProgress(tracker *services.UploadProgressTracker, userID int, session *models.UploadSession) *uploadProgress {
+	if tracker == nil || !session.Complete() {
+		return nil
+	}
+	return newUploadProgress(tracker, userID, session.ID, session.Offset, session.TotalSize)
+}
+
+// newUploadProgress は received バイトを受信済みとして進捗を配信します
+func newUploadProgress(tracker *services.UploadProgressTracker, userID int, uploadID string, received, total int64) *uploadProgress {
+	p := &uploadProgress{
+		tracker:  tracker,
+		userID:   userID,
+		uploadID: uploadID,
+		current: models.UploadProgress{
+			UploadID:      uploadID,
+			Stage:         models.UploadStageReceiving,
+			ReceivedBytes: received,
+			TotalBytes:    total,
+		},
+	}
+	p.mu.Lock()
+	p.publish(true)
+	p.mu.Unlock()
+	return p
+}
+
+// trackBody は リクエストボディを、受信したバイト数を配信するリーダーに置き換えます
+func (p *uploadProgress) trackBody(r *http.Request) {
+	r.Body = &progressReader{ReadCloser: r.Body, progress: p}
+}
+
+// received は 受信したバイト数を加算し、前回の配信から一定時間経っていれば配信します
+func (p *uploadProgress) received(n int64, eof bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current.ReceivedBytes += n
+	p.publish(eof || time.Since(p.lastSent) >= progressPublishInterval)
+}
+
+// Processing は 受信が終わり、変換・保存の段階に入ったことを配信します
+func (p *uploadProgress) Processing() {
+	p.setStage(models.UploadStageProcessing, 0)
+}
+
+// Complete は アップロードの完了を配信します
+func (p *uploadProgress) Complete(fileID int) {
+	p.setStage(models.UploadStageCompleted, fileID)
+}
+
+// Close は 完了していないアップロードを失敗として配信します
+func (p *uploadProgress) Close() {
+	p.setStage(models.UploadStageFailed, 0)
+}
+
+// setStage は 段階を変えて配信します（完了・失敗した後は何もしない）
+func (p *uploadProgress) setStage(stage string, fileID int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.current.Finished() {
+		return
+	}
+	p.current.Stage = stage
+	p.current.FileID = fileID
+	p.publish(true)
+}
+
+// publish は 現在の進捗を配信します（mu を取得した状態で呼ぶ）
+func (p *uploadProgress) publish(force bool) {
+	if !force {
+		return
+	}
+	p.tracker.Publish(p.userID, p.current)
+	p.lastSent = time.Now()
+}
+
+// progressReader は 読み込んだバイト数を uploadProgress に伝えるリーダーです
+type progressReader struct {
+	io.ReadCloser
+	progress *uploadProgress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.progress.received(int64(n), err == io.EOF)
+	return n, err
+}
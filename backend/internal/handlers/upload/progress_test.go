@@ -0,0 +1,111 @@
+package upload
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// TestStartUploadProgress は リクエストボディの受信に合わせて進捗が配信されるテスト
+func TestStartUploadProgress(t *testing.T) {
+	tracker := services.NewUploadProgressTracker()
+	updates, unsubscribe := tracker.Subscribe(1, "upload-0001")
+	defer unsubscribe()
+
+	r := httptest.NewRequest("POST", "/api/upload/file", strings.NewReader("hello world"))
+	r.Header.Set(uploadIDHeader, "upload-0001")
+
+	progress := startUploadProgress(tracker, r, 1)
+	if progress == nil {
+		t.Fatal("startUploadProgress() = nil")
+	}
+	if _, err := io.ReadAll(r.Body); err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if got := <-updates; got.Stage != models.UploadStageReceiving || got.ReceivedBytes != 11 || got.TotalBytes != 11 {
+		t.Errorf("progress = %+v, want receiving 11/11", got)
+	}
+
+	progress.Processing()
+	progress.Complete(7)
+	progress.Close() // 完了した後は失敗として配信しない
+	if got := <-updates; got.Stage != models.UploadStageCompleted || got.FileID != 7 {
+		t.Errorf("progress = %+v, want completed fileId 7", got)
+	}
+
+	t.Run("X-Upload-ID がない場合は配信しない", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/api/upload/file", strings.NewReader("hello"))
+		progress := startUploadProgress(tracker, r, 1)
+		if progress != nil {
+			t.Fatal("startUploadProgress() != nil")
+		}
+		// nil のまま呼び出せる
+		progress.Processing()
+		progress.Close()
+	})
+}
+
+// TestStreamProgress は 進捗が Server-Sent Events として送られ、完了で接続が閉じられるテスト
+func TestStreamProgress(t *testing.T) {
+	tracker := services.NewUploadProgressTracker()
+	handler := NewProgressHandler(tracker)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/upload/progress/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.StreamProgress(w, r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, 1)))
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// 購読より前に完了したアップロードの結果も届く
+	tracker.Publish(1, models.UploadProgress{UploadID: "upload-0001", Stage: models.UploadStageCompleted, FileID: 3})
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(server.URL + "/api/upload/progress/upload-0001")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	var events []models.UploadProgress
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			var progress models.UploadProgress
+			if err := json.Unmarshal([]byte(data), &progress); err != nil {
+				t.Fatalf("Unmarshal(%q) error = %v", data, err)
+			}
+			events = append(events, progress)
+		}
+	}
+	if len(events) != 1 || events[0].Stage != models.UploadStageCompleted || events[0].FileID != 3 {
+		t.Errorf("events = %+v, want one completed event", events)
+	}
+
+	t.Run("アップロードIDの形式が不正", func(t *testing.T) {
+		resp, err := client.Get(server.URL + "/api/upload/progress/bad")
+		if err != nil {
+			t.Fatalf("GET error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", resp.StatusCode)
+		}
+	})
+}
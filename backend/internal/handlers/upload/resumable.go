@@ -0,0 +1,178 @@
+package upload
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// 再開可能なアップロードで使うヘッダー（tus プロトコルに倣う）
+const (
+	uploadOffsetHeader = "Upload-Offset"
+	uploadLengthHeader = "Upload-Length"
+)
+
+// ResumableUploadHandler は 大きなファイルのアップロード（再開可能な分割アップロードと署名付きURLへの直接アップロード）のHTTPハンドラーです
+// 分割アップロードは 作成 → チャンクの追記（PATCH）→ 完了 の順に呼び出し、中断した場合は GET で受信済みのオフセットを確認して続きから送る
+type ResumableUploadHandler struct {
+	sessionService   *services.UploadSessionService
+	userStorageQuota int64
+	progressTracker  *services.UploadProgressTracker
+}
+
+// NewResumableUploadHandler は 新しい ResumableUploadHandler インスタンスを作成します
+func NewResumableUploadHandler(sessionService *services.UploadSessionService, userStorageQuota int64, progressTracker *services.UploadProgressTracker) *ResumableUploadHandler {
+	return &ResumableUploadHandler{
+		sessionService:   sessionService,
+		userStorageQuota: userStorageQuota,
+		progressTracker:  progressTracker,
+	}
+}
+
+// createSessionRequest は 分割アップロードの作成リクエストです
+type createSessionRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// UploadSessionResponse は 分割アップロードのセッションのレスポンスです
+type UploadSessionResponse struct {
+	*models.UploadSession
+	MinChunkSize int64 `json:"minChunkSize"`
+	MaxChunkSize int64 `json:"maxChunkSize"`
+}
+
+// CreateSession は 分割アップロードを開始します
+func (h *ResumableUploadHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req createSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	session, err := h.sessionService.CreateSession(r.Context(), userID, middleware.GetWorkspaceIDFromContext(r.Context()), req.Filename, req.ContentType, req.Size, h.userStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	writeSession(w, http.StatusCreated, session)
+}
+
+// GetSession は 分割アップロードの受信済みのオフセットを返します
+func (h *ResumableUploadHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	session, err := h.sessionService.GetSession(userID, mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	writeSession(w, http.StatusOK, session)
+}
+
+// AppendChunk は リクエストボディをチャンクとして追記します
+// Upload-Offset ヘッダーには受信済みのオフセット、Content-Length にはチャンクのサイズを指定する
+func (h *ResumableUploadHandler) AppendChunk(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	offset, err := strconv.ParseInt(r.Header.Get(uploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_UPLOAD_OFFSET", "Upload-Offset ヘッダーに受信済みのオフセットを指定してください", err,
+		))
+		return
+	}
+	if r.ContentLength < 0 {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"CONTENT_LENGTH_REQUIRED", "Content-Length ヘッダーを指定してください", nil,
+		))
+		return
+	}
+
+	// セッション全体に対する受信の進捗を、セッションIDをアップロードIDとして配信する
+	session, err := h.sessionService.GetSession(userID, mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	startChunkProgress(h.progressTracker, r, userID, session)
+
+	body := http.MaxBytesReader(w, r.Body, models.MaxUploadChunkSize)
+	session, err = h.sessionService.AppendChunk(r.Context(), userID, session.ID, offset, body, r.ContentLength)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	writeSession(w, http.StatusOK, session)
+}
+
+// CompleteSession は 受信したチャンクを結合してファイルとして登録します
+func (h *ResumableUploadHandler) CompleteSession(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	session, err := h.sessionService.GetSession(userID, mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// チャンクの結合・保存の進捗を配信する
+	progress := startSessionProgress(h.progressTracker, userID, session)
+	defer progress.Close()
+	progress.Processing()
+
+	fileMeta, _, err := h.sessionService.CompleteSession(r.Context(), userID, middleware.GetWorkspaceIDFromContext(r.Context()), session.ID, h.userStorageQuota)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	progress.Complete(fileMeta.ID)
+
+	apierror.WriteJSON(w, http.StatusOK, UploadResponse{
+		Success:  true,
+		FileID:   fileMeta.ID,
+		Filename: fileMeta.OriginalName,
+		URL:      fmt.Sprintf("/api/uploads/%s", filepath.Base(fileMeta.FileKey)),
+		Message:  "File uploaded successfully",
+	})
+}
+
+// AbortSession は 分割アップロードを中止します
+func (h *ResumableUploadHandler) AbortSession(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.sessionService.AbortSession(r.Context(), userID, mux.Vars(r)["id"]); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Upload aborted"})
+}
+
+// writeSession は セッションを JSON と Upload-Offset / Upload-Length ヘッダーで返します
+func writeSession(w http.ResponseWriter, status int, session *models.UploadSession) {
+	w.Header().Set(uploadOffsetHeader, strconv.FormatInt(session.Offset, 10))
+	w.Header().Set(uploadLengthHeader, strconv.FormatInt(session.TotalSize, 10))
+	apierror.WriteJSON(w, status, UploadSessionResponse{
+		UploadSession: session,
+		MinChunkSize:  models.MinUploadChunkSize,
+		MaxChunkSize:  models.MaxUploadChunkSize,
+	})
+}
@@ -0,0 +1,142 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/storage"
+)
+
+// SignedObjectHandler は アプリケーション自身が発行した署名付きURL（storage.SignedURLPrefix）でファイルを配信・受け付けるハンドラーです
+// ローカルディスクに保存する場合と、ストレージへの接続をバックエンドが中継する場合（STORAGE_PROXY）に使う
+// MinIO/S3 の署名付きURLの代わりとなるため、認証は不要でURLの署名のみを検証する
+type SignedObjectHandler struct {
+	storage     storage.SignedURLStorage
+	maxFileSize int64
+}
+
+// NewSignedObjectHandler は 新しい SignedObjectHandler インスタンスを作成します
+// maxFileSize は直接アップロード（PUT）で受け付ける最大サイズ
+func NewSignedObjectHandler(signedStorage storage.SignedURLStorage, maxFileSize int64) *SignedObjectHandler {
+	return &SignedObjectHandler{
+		storage:     signedStorage,
+		maxFileSize: maxFileSize,
+	}
+}
+
+// GetObject は 署名付きURLのファイルを返します
+// Range ヘッダーで指定された範囲のみの取得（動画のシーク・ダウンロードの再開）に対応する
+func (h *SignedObjectHandler) GetObject(w http.ResponseWriter, r *http.Request) {
+	fileKey := mux.Vars(r)["key"]
+	if err := h.storage.VerifySignedURL(http.MethodGet, fileKey, "", r.URL.Query()); err != nil {
+		apierror.Write(w, r, signedObjectError(err))
+		return
+	}
+
+	info, err := h.storage.StatFile(r.Context(), fileKey)
+	if err != nil {
+		apierror.Write(w, r, signedObjectError(err))
+		return
+	}
+
+	byteRange, satisfiable := parseByteRange(r.Header.Get("Range"), info.Size)
+	if !satisfiable {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+		apierror.Write(w, r, apierror.NewRangeNotSatisfiable(
+			"RANGE_NOT_SATISFIABLE", "指定された範囲はファイルの大きさを超えています", nil,
+		))
+		return
+	}
+
+	var object io.ReadCloser
+	if byteRange != nil {
+		object, err = h.storage.GetObjectRange(r.Context(), fileKey, byteRange.start, byteRange.length)
+	} else {
+		object, err = h.storage.GetObject(r.Context(), fileKey)
+	}
+	if err != nil {
+		apierror.Write(w, r, signedObjectError(err))
+		return
+	}
+	defer object.Close()
+
+	// 大きなファイルはサーバーの書き込みタイムアウトを超えるため、このレスポンスでは解除する
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// URL に有効期限があるため、ブラウザのキャッシュも共有キャッシュに保存させない範囲に留める
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+
+	status := http.StatusOK
+	length := info.Size
+	if byteRange != nil {
+		status, length = http.StatusPartialContent, byteRange.length
+		w.Header().Set("Content-Range", fmt.Sprintf(
+			"bytes %d-%d/%d", byteRange.start, byteRange.start+byteRange.length-1, info.Size,
+		))
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	w.WriteHeader(status)
+
+	if _, err := io.Copy(w, object); err != nil {
+		// ヘッダー送信済みのためレスポンスは書き換えられない
+		return
+	}
+}
+
+// PutObject は 署名付きURLへ直接アップロードされたファイルを保存します
+// URL の発行時と同じ Content-Type ヘッダーを送る必要がある
+func (h *SignedObjectHandler) PutObject(w http.ResponseWriter, r *http.Request) {
+	fileKey := mux.Vars(r)["key"]
+	contentType := r.Header.Get("Content-Type")
+	if err := h.storage.VerifySignedURL(http.MethodPut, fileKey, contentType, r.URL.Query()); err != nil {
+		apierror.Write(w, r, signedObjectError(err))
+		return
+	}
+	if r.ContentLength < 0 {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"CONTENT_LENGTH_REQUIRED", "Content-Length ヘッダーを指定してください", nil,
+		))
+		return
+	}
+	if r.ContentLength > h.maxFileSize {
+		apierror.Write(w, r, apierror.NewPayloadTooLarge(
+			"FILE_TOO_LARGE", "ファイルサイズが上限を超えています", nil,
+		))
+		return
+	}
+
+	// 大きなファイルはサーバーの読み込みタイムアウトを超えるため、このリクエストでは解除する
+	_ = http.NewResponseController(w).SetReadDeadline(time.Time{})
+
+	body := http.MaxBytesReader(w, r.Body, h.maxFileSize)
+	if err := h.storage.UploadFile(r.Context(), fileKey, body, r.ContentLength, contentType); err != nil {
+		apierror.Write(w, r, signedObjectError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// signedObjectError は 署名付きURLで配信するストレージのエラーを HTTP のエラーに変換します
+func signedObjectError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrInvalidSignature):
+		return apierror.NewForbidden("INVALID_SIGNATURE", "URLの署名が無効か、有効期限が切れています", err)
+	case errors.Is(err, storage.ErrObjectNotFound), errors.Is(err, storage.ErrInvalidObjectKey):
+		return apierror.NewNotFound("FILE_NOT_FOUND", "ファイルが見つかりません", err)
+	default:
+		return apierror.NewInternal(fmt.Errorf("signed object storage: %w", err))
+	}
+}
@@ -0,0 +1,138 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/services"
+)
+
+// FileVersionHandler は ファイルの版（同じファイルIDのまま新しい版をアップロード・以前の版を復元）のHTTPハンドラーです
+type FileVersionHandler struct {
+	versionService   *services.FileVersionService
+	fileService      *services.FileService
+	userStorageQuota int64
+}
+
+// NewFileVersionHandler は 新しい FileVersionHandler インスタンスを作成します
+func NewFileVersionHandler(versionService *services.FileVersionService, fileService *services.FileService, userStorageQuota int64) *FileVersionHandler {
+	return &FileVersionHandler{
+		versionService:   versionService,
+		fileService:      fileService,
+		userStorageQuota: userStorageQuota,
+	}
+}
+
+// ListVersions は ファイルの現在の版の番号と以前の版の一覧を返すハンドラー
+func (h *FileVersionHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	fileID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FILE_ID", "ファイルIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	versions, err := h.versionService.ListVersions(r.Context(), fileID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, versions)
+}
+
+// UploadVersion は ファイルの新しい版のアップロードハンドラー（フォームの "file" フィールド）
+// ファイルを参照しているブロックは新しい版を表示するように更新される
+func (h *FileVersionHandler) UploadVersion(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	fileID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FILE_ID", "ファイルIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	// multipart/form-dataの解析（最大32MB）
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FORM_DATA", "フォームデータの解析に失敗しました", err,
+		))
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"NO_FILE_UPLOADED", "ファイルを選択してください", err,
+		))
+		return
+	}
+	defer file.Close()
+
+	// ストレージクォータチェック（以前の版も保持期間の間はストレージに残る）
+	if err := h.fileService.CheckStorageQuota(r.Context(), userID, middleware.GetWorkspaceIDFromContext(r.Context()), header.Size, h.userStorageQuota); err != nil {
+		apierror.Write(w, r, quotaError(err))
+		return
+	}
+
+	fileMeta, err := h.versionService.UploadVersion(r.Context(), fileID, userID, file, header)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrFileTooLarge):
+			apierror.Write(w, r, apierror.NewPayloadTooLarge(
+				"FILE_TOO_LARGE", "ファイルサイズが上限を超えています", err,
+			))
+		case errors.Is(err, services.ErrUnsupportedFileType):
+			apierror.Write(w, r, apierror.NewValidationError(
+				"UNSUPPORTED_FILE_TYPE", "元のファイルと同じ種類のファイルを選択してください", err,
+			))
+		case errors.Is(err, services.ErrContentTypeMismatch):
+			apierror.Write(w, r, contentTypeMismatchError(err))
+		default:
+			apierror.Write(w, r, fmt.Errorf("failed to upload file version: %w", err))
+		}
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, fileMeta)
+}
+
+// RestoreVersion は 以前の版を復元するハンドラー（復元した内容がファイルの新しい版になる）
+func (h *FileVersionHandler) RestoreVersion(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	fileID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_FILE_ID", "ファイルIDは数値である必要があります", err,
+		))
+		return
+	}
+	versionID, err := strconv.Atoi(vars["versionId"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_VERSION_ID", "版のIDは数値である必要があります", err,
+		))
+		return
+	}
+
+	fileMeta, err := h.versionService.RestoreVersion(r.Context(), fileID, versionID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, fileMeta)
+}
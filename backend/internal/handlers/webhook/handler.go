@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+	"simple-notion-backend/internal/services"
+)
+
+// WebhookHandler は Webhook の登録・配信の記録関連のHTTPハンドラーです
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookHandler は 新しい WebhookHandler インスタンスを作成します
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhook は Webhook を登録します（レスポンスの secret は署名の検証に使う鍵で、この時だけ返す）
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req models.WebhookInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(userID, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, webhook)
+}
+
+// GetWebhooks は ログインユーザーが登録した Webhook の一覧を返します
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	webhooks, err := h.webhookService.ListWebhooks(userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, webhooks)
+}
+
+// GetWebhook は Webhook を返します
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.GetWebhook(id, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, webhook)
+}
+
+// UpdateWebhook は Webhook の URL・イベント・説明・有効かどうかを変更します（指定した項目のみ）
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.WebhookUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(id, userID, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, webhook)
+}
+
+// DeleteWebhook は Webhook とその配信の記録を削除します
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(id, userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RotateSecret は 署名の鍵を再発行し、新しい鍵を含めた Webhook を返します
+func (h *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.RotateSecret(id, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, webhook)
+}
+
+// GetDeliveries は Webhook の配信の記録を新しい順に1ページ分返します（limit・cursor でページを指定する）
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	page, err := pagination.Parse(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(id, userID, page)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, deliveries)
+}
+
+// Redeliver は 配信と同じ内容を新しい配信として送信待ちに追加し、追加した配信を返します
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.Atoi(mux.Vars(r)["deliveryId"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_DELIVERY_ID", "配信IDが不正です", err,
+		))
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(id, deliveryID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusAccepted, delivery)
+}
+
+// webhookID は パスの Webhook ID を読み取ります（不正な場合はエラーを書き込み false を返す）
+func webhookID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_WEBHOOK_ID", "Webhook IDが不正です", err,
+		))
+		return 0, false
+	}
+	return id, true
+}
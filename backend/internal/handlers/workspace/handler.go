@@ -0,0 +1,356 @@
+package workspace
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// WorkspaceHandler は ワークスペースとメンバー関連のHTTPハンドラーです
+// 扱うワークスペースの選択（トークンの発行し直し）は AuthHandler.SwitchWorkspace が行う
+type WorkspaceHandler struct {
+	workspaceService  *services.WorkspaceService
+	invitationService *services.WorkspaceInvitationService
+}
+
+// NewWorkspaceHandler は 新しい WorkspaceHandler インスタンスを作成します
+func NewWorkspaceHandler(workspaceService *services.WorkspaceService, invitationService *services.WorkspaceInvitationService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService:  workspaceService,
+		invitationService: invitationService,
+	}
+}
+
+// CreateWorkspace は ワークスペースを作成します（作成したユーザーが所有者になる）
+func (h *WorkspaceHandler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(userID, req.Name)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, workspace)
+}
+
+// GetWorkspaces は ログインユーザーがメンバーのワークスペース一覧を返します
+func (h *WorkspaceHandler) GetWorkspaces(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	workspaces, err := h.workspaceService.ListWorkspaces(userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, workspaces)
+}
+
+// GetWorkspace は ワークスペースを文書数・メンバー数と上限とともに返します
+func (h *WorkspaceHandler) GetWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, appErr := parseWorkspaceID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	workspace, err := h.workspaceService.GetWorkspace(workspaceID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, workspace)
+}
+
+// UpdateWorkspace は ワークスペースの名前を変更します（所有者・管理者のみ）
+func (h *WorkspaceHandler) UpdateWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, appErr := parseWorkspaceID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	workspace, err := h.workspaceService.RenameWorkspace(workspaceID, userID, req.Name)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, workspace)
+}
+
+// DeleteWorkspace は ワークスペースを削除します（所有者のみ。属していた文書は作成したユーザーの個人の文書に戻る）
+func (h *WorkspaceHandler) DeleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, appErr := parseWorkspaceID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.workspaceService.DeleteWorkspace(workspaceID, userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Workspace deleted successfully"})
+}
+
+// GetMembers は ワークスペースのメンバー一覧を返します（guest は閲覧できない）
+func (h *WorkspaceHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, appErr := parseWorkspaceID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	members, err := h.workspaceService.ListMembers(workspaceID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, members)
+}
+
+// GetStorageUsage は ワークスペースのストレージクォータと、メンバーごとのファイルの使用量を返します（guest は閲覧できない）
+func (h *WorkspaceHandler) GetStorageUsage(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, appErr := parseWorkspaceID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	usage, err := h.workspaceService.GetStorageUsage(workspaceID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, usage)
+}
+
+// AddMember は メールアドレスで指定したユーザーをメンバーとして招待します（role は admin・member・guest、省略時 member）
+func (h *WorkspaceHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, appErr := parseWorkspaceID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var req struct {
+		Email string               `json:"email"`
+		Role  models.WorkspaceRole `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "email を指定してください", err,
+		))
+		return
+	}
+
+	member, err := h.workspaceService.AddMember(r.Context(), workspaceID, userID, req.Email, req.Role)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, member)
+}
+
+// UpdateMember は メンバーの役割を変更します（管理者の任命・解任は所有者のみ）
+func (h *WorkspaceHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, memberID, appErr := parseMemberPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var req struct {
+		Role models.WorkspaceRole `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "リクエストボディが不正です", err,
+		))
+		return
+	}
+
+	if err := h.workspaceService.UpdateMemberRole(workspaceID, userID, memberID, req.Role); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Member updated successfully"})
+}
+
+// RemoveMember は メンバーを削除します（自分自身を指定した場合はワークスペースから退出する）
+func (h *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, memberID, appErr := parseMemberPath(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(workspaceID, userID, memberID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Member removed successfully"})
+}
+
+// CreateInvitation は メールアドレスに招待リンクを送ります（role は admin・member・guest、省略時 member）
+// リンクのトークンはメールにのみ含め、レスポンスには含めない
+func (h *WorkspaceHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, appErr := parseWorkspaceID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	var req struct {
+		Email string               `json:"email"`
+		Role  models.WorkspaceRole `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		apierror.Write(w, r, apierror.NewValidationError(
+			"INVALID_REQUEST", "email を指定してください", err,
+		))
+		return
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(r.Context(), workspaceID, userID, req.Email, req.Role)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusCreated, invitation)
+}
+
+// GetInvitations は 承諾待ちの招待一覧を返します（所有者・管理者のみ）
+func (h *WorkspaceHandler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, appErr := parseWorkspaceID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+
+	invitations, err := h.invitationService.ListInvitations(workspaceID, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, invitations)
+}
+
+// RevokeInvitation は 承諾待ちの招待を取り消します（所有者・管理者のみ）
+func (h *WorkspaceHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	workspaceID, appErr := parseWorkspaceID(r)
+	if appErr != nil {
+		apierror.Write(w, r, appErr)
+		return
+	}
+	invitationID, err := strconv.Atoi(mux.Vars(r)["invitationId"])
+	if err != nil {
+		apierror.Write(w, r, apierror.NewValidationError("INVALID_INVITATION_ID", "招待IDが不正です", err))
+		return
+	}
+
+	if err := h.invitationService.RevokeInvitation(workspaceID, userID, invitationID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, map[string]string{"message": "Invitation revoked successfully"})
+}
+
+// GetInvitation は 招待リンクの招待（ワークスペース名・招待したユーザー・役割）を返します（認証不要）
+// 招待の確認画面で使い、アカウントの作成は AuthHandler.RegisterWithInvitation、ログイン済みの場合は AcceptInvitation で参加する
+func (h *WorkspaceHandler) GetInvitation(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.invitationService.GetInvitation(mux.Vars(r)["token"])
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	apierror.WriteJSON(w, http.StatusOK, invitation)
+}
+
+// AcceptInvitation は ログイン中のユーザーとして招待を承諾し、ワークスペースに参加します
+// 招待されたメールアドレスのアカウントでのみ承諾できる
+func (h *WorkspaceHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	member, err := h.invitationService.AcceptInvitation(mux.Vars(r)["token"], userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	apierror.WriteJSON(w, http.StatusOK, member)
+}
+
+// parseWorkspaceID は URL からワークスペースIDを取り出します
+func parseWorkspaceID(r *http.Request) (int, *apierror.AppError) {
+	workspaceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, apierror.NewValidationError("INVALID_WORKSPACE_ID", "ワークスペースIDが不正です", err)
+	}
+	return workspaceID, nil
+}
+
+// parseMemberPath は URL からワークスペースIDとメンバーのユーザーIDを取り出します
+func parseMemberPath(r *http.Request) (workspaceID, memberID int, appErr *apierror.AppError) {
+	workspaceID, appErr = parseWorkspaceID(r)
+	if appErr != nil {
+		return 0, 0, appErr
+	}
+	memberID, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		return 0, 0, apierror.NewValidationError("INVALID_USER_ID", "ユーザーIDが不正です", err)
+	}
+	return workspaceID, memberID, nil
+}
@@ -0,0 +1,44 @@
+// Package logctx は リクエストのコンテキストに紐づく ID（request_id・trace_id）をログに含めるヘルパーを提供します
+// handlers・services はアプリケーションのロガー（app.Logger）を参照できないため、標準のログに同じフィールドを付けて出力する
+package logctx
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"simple-notion-backend/internal/requestid"
+	"simple-notion-backend/internal/tracing"
+)
+
+// Fields は コンテキストのリクエスト ID・トレース ID をログのフィールドとして返します（ない項目は含めない）
+func Fields(ctx context.Context) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if ctx == nil {
+		return fields
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	if traceID := tracing.SpanFromContext(ctx).TraceID(); traceID != "" {
+		fields["trace_id"] = traceID
+	}
+	return fields
+}
+
+// Printf は log.Printf と同じようにメッセージを出力し、末尾にコンテキストのリクエスト ID・トレース ID を付けます
+// 例: failed to update search text for document 42: timeout | request_id=3f2b... trace_id=4bf9...
+func Printf(ctx context.Context, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	fields := Fields(ctx)
+	suffix := ""
+	for _, key := range []string{"request_id", "trace_id"} {
+		if value, ok := fields[key]; ok {
+			suffix += fmt.Sprintf(" %s=%v", key, value)
+		}
+	}
+	if suffix != "" {
+		message += " |" + suffix
+	}
+	log.Output(2, message)
+}
@@ -0,0 +1,75 @@
+package logctx
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+
+	"simple-notion-backend/internal/requestid"
+)
+
+// captureLog は テスト中の標準のログの出力を返します
+func captureLog(t *testing.T, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	output, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	}()
+	fn()
+	return buf.String()
+}
+
+// TestPrintf - コンテキストのリクエスト ID をログに含めるテスト
+func TestPrintf(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{
+			name: "正常系：リクエスト ID を末尾に付ける",
+			ctx:  requestid.NewContext(context.Background(), "req-1"),
+			want: "failed to sync document 42: timeout | request_id=req-1\n",
+		},
+		{
+			name: "正常系：リクエスト ID がない場合はメッセージのみ",
+			ctx:  context.Background(),
+			want: "failed to sync document 42: timeout\n",
+		},
+		{
+			name: "正常系：コンテキストが nil",
+			ctx:  nil,
+			want: "failed to sync document 42: timeout\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := captureLog(t, func() {
+				Printf(tt.ctx, "failed to sync document %d: %v", 42, "timeout")
+			})
+			if got != tt.want {
+				t.Errorf("Printf() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFields - ログのフィールドのテスト
+func TestFields(t *testing.T) {
+	fields := Fields(requestid.NewContext(context.Background(), "req-2"))
+	if fields["request_id"] != "req-2" {
+		t.Errorf("request_id = %v, want req-2", fields["request_id"])
+	}
+	if _, ok := fields["trace_id"]; ok {
+		t.Errorf("trace_id = %v, want none without a span", fields["trace_id"])
+	}
+	if len(Fields(context.Background())) != 0 {
+		t.Errorf("Fields(background) = %v, want empty", Fields(context.Background()))
+	}
+}
@@ -0,0 +1,203 @@
+// Package mailer は メールの作成（テンプレート）と、送信待ちの列を使った非同期の送信を提供します
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull は 送信待ちの列が一杯でメールを受け付けられない場合のエラーです
+	ErrQueueFull = errors.New("mail queue is full")
+	// ErrClosed は Close 後にメールを送信しようとした場合のエラーです
+	ErrClosed = errors.New("mailer is closed")
+)
+
+// 既定値（Options で 0 以下を指定した場合に使う）
+const (
+	defaultQueueSize   = 100
+	defaultMaxAttempts = 3
+	defaultRetryDelay  = 30 * time.Second
+	defaultSendTimeout = 30 * time.Second
+)
+
+// Message は 送信する1通のメールです
+// From が空の場合は Mailer の差出人を使い、HTML が空の場合はテキストのみのメールになる
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender は メールを1通送信します
+// 実装: SMTPSender
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Options は Mailer の送信待ちの列と再試行の設定です
+type Options struct {
+	From        string        // 差出人（"名前 <address>" 形式も可）
+	BaseURL     string        // テンプレート内のリンクの前に付けるフロントエンドのURL
+	QueueSize   int           // 送信待ちのメールの最大件数
+	MaxAttempts int           // 1通あたりの送信試行回数
+	RetryDelay  time.Duration // 最初の再試行までの待ち時間（以降は倍にしていく）
+	SendTimeout time.Duration // 1回の送信のタイムアウト
+}
+
+// Mailer は テンプレートからメールを作成し、バックグラウンドで送信します
+// 送信に失敗したメールは待ち時間を倍にしながら MaxAttempts 回まで再試行し、それでも失敗した場合はログに残して破棄する
+type Mailer struct {
+	sender    Sender
+	templates *Templates
+	opts      Options
+
+	queue     chan *Message
+	abort     chan struct{} // Close の期限が過ぎた場合に再試行の待機を打ち切る
+	done      chan struct{} // 送信待ちの列を処理するゴルーチンの終了
+	mu        sync.RWMutex
+	closed    bool
+	abortOnce sync.Once
+}
+
+// New は 新しい Mailer インスタンスを作成します
+// 送信待ちの列を処理するゴルーチンを起動します（Close で停止する）
+func New(sender Sender, opts Options) (*Mailer, error) {
+	if strings.TrimSpace(opts.From) == "" {
+		return nil, errors.New("mailer: sender address is required")
+	}
+	templates, err := LoadTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = defaultRetryDelay
+	}
+	if opts.SendTimeout <= 0 {
+		opts.SendTimeout = defaultSendTimeout
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+
+	m := &Mailer{
+		sender:    sender,
+		templates: templates,
+		opts:      opts,
+		queue:     make(chan *Message, opts.QueueSize),
+		abort:     make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go m.run()
+	return m, nil
+}
+
+// Send は メールを送信待ちの列に追加します（送信の完了は待たない）
+// 列が一杯の場合は ErrQueueFull、Close 後は ErrClosed を返す
+func (m *Mailer) Send(msg *Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("mailer: recipient is required")
+	}
+	if msg.From == "" {
+		msg.From = m.opts.From
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return ErrClosed
+	}
+
+	select {
+	case m.queue <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// SendTemplate は テンプレートからメールを作成し、送信待ちの列に追加します
+// テンプレートには data を .Data、フロントエンドのURLを .BaseURL として渡す
+func (m *Mailer) SendTemplate(to, name string, data interface{}) error {
+	msg, err := m.templates.Render(name, TemplateData{BaseURL: m.opts.BaseURL, Data: data})
+	if err != nil {
+		return err
+	}
+	msg.To = []string{to}
+	return m.Send(msg)
+}
+
+// Close は 新しいメールの受け付けを止め、送信待ちのメールを送り終えるまで待ちます
+// ctx の期限が過ぎた場合は再試行を打ち切り、残りのメールを破棄します
+func (m *Mailer) Close(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		m.abortOnce.Do(func() { close(m.abort) })
+		<-m.done
+		return fmt.Errorf("mail queue was not flushed: %w", ctx.Err())
+	}
+}
+
+// run は 送信待ちの列のメールを順に送信します
+func (m *Mailer) run() {
+	defer close(m.done)
+	for msg := range m.queue {
+		select {
+		case <-m.abort:
+			log.Printf("Mail to %s dropped: mailer closed", strings.Join(msg.To, ", "))
+			continue
+		default:
+		}
+		m.deliver(msg)
+	}
+}
+
+// deliver は 1通のメールを送信し、失敗した場合は待ち時間を倍にしながら再試行します
+func (m *Mailer) deliver(msg *Message) {
+	delay := m.opts.RetryDelay
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), m.opts.SendTimeout)
+		err := m.sender.Send(ctx, msg)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		if attempt >= m.opts.MaxAttempts {
+			log.Printf("Failed to send mail %q to %s after %d attempts: %v",
+				msg.Subject, strings.Join(msg.To, ", "), attempt, err)
+			return
+		}
+		log.Printf("Failed to send mail %q to %s (attempt %d/%d), retrying in %s: %v",
+			msg.Subject, strings.Join(msg.To, ", "), attempt, m.opts.MaxAttempts, delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-m.abort:
+			log.Printf("Mail to %s dropped: mailer closed", strings.Join(msg.To, ", "))
+			return
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,379 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSender は 送信したメールを記録し、先頭の failures 回は失敗するテスト用の Sender です
+type fakeSender struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	sent     []*Message
+	block    chan struct{} // nil でない場合は閉じられるまで送信を待つ
+}
+
+func (s *fakeSender) Send(ctx context.Context, msg *Message) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.attempts <= s.failures {
+		return errors.New("temporary failure")
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func newTestMailer(t *testing.T, sender Sender, opts Options) *Mailer {
+	t.Helper()
+	opts.From = "Simple Notion <no-reply@example.com>"
+	m, err := New(sender, opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return m
+}
+
+func TestMailer_RetriesFailedDelivery(t *testing.T) {
+	sender := &fakeSender{failures: 2}
+	m := newTestMailer(t, sender, Options{MaxAttempts: 3, RetryDelay: time.Millisecond})
+
+	if err := m.Send(&Message{To: []string{"user@example.com"}, Subject: "件名", Text: "本文"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := m.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if sender.attempts != 3 || len(sender.sent) != 1 {
+		t.Fatalf("attempts = %d, sent = %d, want 3 attempts and 1 sent", sender.attempts, len(sender.sent))
+	}
+	if sender.sent[0].From != "Simple Notion <no-reply@example.com>" {
+		t.Errorf("From = %q, want mailer sender", sender.sent[0].From)
+	}
+}
+
+func TestMailer_GivesUpAfterMaxAttempts(t *testing.T) {
+	sender := &fakeSender{failures: 10}
+	m := newTestMailer(t, sender, Options{MaxAttempts: 2, RetryDelay: time.Millisecond})
+
+	if err := m.Send(&Message{To: []string{"user@example.com"}, Subject: "件名", Text: "本文"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	m.Close(context.Background())
+
+	if sender.attempts != 2 || len(sender.sent) != 0 {
+		t.Errorf("attempts = %d, sent = %d, want 2 attempts and none sent", sender.attempts, len(sender.sent))
+	}
+}
+
+func TestMailer_Send(t *testing.T) {
+	t.Run("異常系：送信待ちの列が一杯", func(t *testing.T) {
+		sender := &fakeSender{block: make(chan struct{})}
+		m := newTestMailer(t, sender, Options{QueueSize: 1})
+		defer func() {
+			close(sender.block)
+			m.Close(context.Background())
+		}()
+
+		msg := func() *Message { return &Message{To: []string{"user@example.com"}, Text: "本文"} }
+		// 1通目は送信中、2通目は列で待機し、3通目は受け付けられない
+		m.Send(msg())
+		deadline := time.Now().Add(time.Second)
+		for len(m.queue) > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if err := m.Send(msg()); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if err := m.Send(msg()); !errors.Is(err, ErrQueueFull) {
+			t.Errorf("Send() error = %v, want ErrQueueFull", err)
+		}
+	})
+
+	t.Run("異常系：Close 後は送信できない", func(t *testing.T) {
+		m := newTestMailer(t, &fakeSender{}, Options{})
+		m.Close(context.Background())
+
+		if err := m.Send(&Message{To: []string{"user@example.com"}}); !errors.Is(err, ErrClosed) {
+			t.Errorf("Send() error = %v, want ErrClosed", err)
+		}
+	})
+
+	t.Run("異常系：Close の期限が過ぎた場合は再試行を打ち切る", func(t *testing.T) {
+		sender := &fakeSender{failures: 10}
+		m := newTestMailer(t, sender, Options{MaxAttempts: 5, RetryDelay: time.Hour})
+		m.Send(&Message{To: []string{"user@example.com"}})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := m.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Close() error = %v, want DeadlineExceeded", err)
+		}
+	})
+}
+
+func TestMailer_SendTemplate(t *testing.T) {
+	sender := &fakeSender{}
+	m := newTestMailer(t, sender, Options{BaseURL: "https://notion.example.com/"})
+
+	err := m.SendTemplate("new@example.com", TemplateWorkspaceMemberAdded, map[string]string{
+		"InviterName":   "<Admin>",
+		"WorkspaceName": "開発チーム",
+		"Role":          "メンバー",
+	})
+	if err != nil {
+		t.Fatalf("SendTemplate() error = %v", err)
+	}
+	m.Close(context.Background())
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent = %d, want 1", len(sender.sent))
+	}
+	msg := sender.sent[0]
+	if msg.Subject != "<Admin>さんがワークスペース「開発チーム」にあなたを追加しました" {
+		t.Errorf("Subject = %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "https://notion.example.com/") {
+		t.Errorf("Text = %q, want link to app", msg.Text)
+	}
+	// HTML本文では値をエスケープする
+	if !strings.Contains(msg.HTML, "&lt;Admin&gt;") || strings.Contains(msg.HTML, "<Admin>") {
+		t.Errorf("HTML = %q, want escaped inviter name", msg.HTML)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+	if _, err := templates.Render("unknown", TemplateData{}); err == nil {
+		t.Error("Render() error = nil, want error for unknown template")
+	}
+}
+
+func TestTemplates_RenderWatchDigest(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	type document struct {
+		ID        int
+		Title     string
+		ChangedAt string
+	}
+	msg, err := templates.Render(TemplateDocumentWatchDigest, TemplateData{
+		BaseURL: "https://notion.example.com",
+		Data: map[string]interface{}{
+			"Name":      "Bob",
+			"Documents": []document{{ID: 5, Title: "設計メモ", ChangedAt: "2024-05-01 09:30"}, {ID: 6, Title: "<議事録>"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if msg.Subject != "購読している2件の文書が変更されました" {
+		t.Errorf("Subject = %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "https://notion.example.com/documents/5") {
+		t.Errorf("Text = %q, want link to document", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "&lt;議事録&gt;") {
+		t.Errorf("HTML = %q, want escaped title", msg.HTML)
+	}
+}
+
+func TestBuildMIMEMessage(t *testing.T) {
+	msg := &Message{
+		From:    "Simple Notion <no-reply@example.com>",
+		To:      []string{"user@example.com"},
+		Subject: "ワークスペースに追加されました",
+		Text:    "テキスト本文",
+		HTML:    "<p>HTML本文</p>",
+	}
+	raw, err := buildMIMEMessage(msg, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("buildMIMEMessage() error = %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("mail.ReadMessage() error = %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, msg.Subject)
+	}
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("ParseMediaType() error = %v", err)
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		decoded, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		if err != nil {
+			t.Fatalf("decode part error = %v", err)
+		}
+		bodies = append(bodies, string(decoded))
+	}
+	if len(bodies) != 2 || bodies[0] != msg.Text || bodies[1] != msg.HTML {
+		t.Errorf("bodies = %q, want text and html", bodies)
+	}
+}
+
+// fakeSMTP は 1通のメールを受け取るだけのテスト用の SMTP サーバーです（認証・STARTTLS には対応しない）
+type fakeSMTP struct {
+	listener net.Listener
+	mu       sync.Mutex
+	from     string
+	rcpts    []string
+	data     string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	f := &fakeSMTP{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			f.mu.Lock()
+			f.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			f.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			f.mu.Lock()
+			f.rcpts = append(f.rcpts, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			f.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			f.mu.Lock()
+			f.data = data.String()
+			f.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	server := newFakeSMTP(t)
+	addr := server.listener.Addr().(*net.TCPAddr)
+
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: addr.Port, TLSMode: TLSModeNone})
+	if err != nil {
+		t.Fatalf("NewSMTPSender() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = sender.Send(ctx, &Message{
+		From:    "Simple Notion <no-reply@example.com>",
+		To:      []string{"User <user@example.com>"},
+		Subject: "テスト",
+		Text:    "本文",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.from != "no-reply@example.com" || len(server.rcpts) != 1 || server.rcpts[0] != "user@example.com" {
+		t.Errorf("from = %q, rcpts = %v", server.from, server.rcpts)
+	}
+	if !strings.Contains(server.data, "Content-Type: text/plain; charset=UTF-8") {
+		t.Errorf("data = %q, want text/plain message", server.data)
+	}
+}
+
+func TestNewSMTPSender(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SMTPConfig
+	}{
+		{name: "異常系：ホストがない", config: SMTPConfig{Port: 587}},
+		{name: "異常系：ポートが不正", config: SMTPConfig{Host: "smtp.example.com"}},
+		{name: "異常系：不明な暗号化方式", config: SMTPConfig{Host: "smtp.example.com", Port: 587, TLSMode: "ssl"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSMTPSender(tt.config); err == nil {
+				t.Error("NewSMTPSender() error = nil, want error")
+			}
+		})
+	}
+}
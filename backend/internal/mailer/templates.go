@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// テンプレート名（templates/<名前>.tmpl）
+const (
+	TemplateWorkspaceMemberAdded = "workspace_member_added" // ワークスペースのメンバーに追加された
+	TemplateWorkspaceInvitation  = "workspace_invitation"   // ワークスペースに招待された（参加用のリンク付き）
+	TemplateDocumentWatchDigest  = "document_watch_digest"  // 購読している文書の変更のまとめ
+)
+
+// TemplateData は テンプレートに渡す値です
+type TemplateData struct {
+	BaseURL string      // フロントエンドのURL（末尾の / なし）
+	Data    interface{} // テンプレートごとの値
+}
+
+// Templates は メールのテンプレートの一覧です
+// 1つのファイルに "subject"（件名）・"text"（テキスト本文）・"html"（HTML本文、省略可）を define で定義する
+// 件名とテキスト本文は text/template で、HTML本文は値をエスケープするため html/template で作成する
+type Templates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// LoadTemplates は 埋め込まれたテンプレートを読み込みます
+func LoadTemplates() (*Templates, error) {
+	files, err := templateFiles.ReadDir("templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mail templates: %w", err)
+	}
+
+	t := &Templates{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".tmpl")
+		filePath := path.Join("templates", file.Name())
+
+		text, err := texttemplate.ParseFS(templateFiles, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %w", name, err)
+		}
+		if text.Lookup("subject") == nil || text.Lookup("text") == nil {
+			return nil, fmt.Errorf("mail template %s must define subject and text", name)
+		}
+		t.text[name] = text
+
+		html, err := htmltemplate.ParseFS(templateFiles, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %w", name, err)
+		}
+		if html.Lookup("html") != nil {
+			t.html[name] = html
+		}
+	}
+	return t, nil
+}
+
+// Render は テンプレートから件名と本文を作成します（宛先は呼び出し側で設定する）
+func (t *Templates) Render(name string, data TemplateData) (*Message, error) {
+	text, ok := t.text[name]
+	if !ok {
+		return nil, fmt.Errorf("mail template %s not found", name)
+	}
+
+	var subject, body bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render subject of %s: %w", name, err)
+	}
+	if err := text.ExecuteTemplate(&body, "text", data); err != nil {
+		return nil, fmt.Errorf("failed to render text of %s: %w", name, err)
+	}
+
+	msg := &Message{
+		// 件名に改行が含まれるとヘッダーが壊れるため、1行にまとめる
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+	}
+
+	if html, ok := t.html[name]; ok {
+		var htmlBody bytes.Buffer
+		if err := html.ExecuteTemplate(&htmlBody, "html", data); err != nil {
+			return nil, fmt.Errorf("failed to render html of %s: %w", name, err)
+		}
+		msg.HTML = strings.TrimSpace(htmlBody.String())
+	}
+	return msg, nil
+}
@@ -0,0 +1,269 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// secondsToDuration は 秒数を time.Duration に変換します
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// probeWAV は fmt チャンクの 1 秒あたりのバイト数と data チャンクの大きさから再生時間を求めます
+func probeWAV(r io.ReaderAt, size int64) (*Info, error) {
+	header := make([]byte, 12)
+	if err := readAt(r, header, 0); err != nil {
+		return nil, err
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("RIFF WAVE header not found: %w", ErrInvalid)
+	}
+
+	var byteRate uint32
+	chunk := make([]byte, 8)
+	for offset, count := int64(12), 0; offset+8 <= size; count++ {
+		if count > maxElements {
+			return nil, fmt.Errorf("too many chunks: %w", ErrInvalid)
+		}
+		if err := readAt(r, chunk, offset); err != nil {
+			return nil, err
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		start := offset + 8
+
+		switch string(chunk[0:4]) {
+		case "fmt ":
+			format := make([]byte, 12)
+			if chunkSize < int64(len(format)) {
+				return nil, fmt.Errorf("fmt chunk too short: %w", ErrInvalid)
+			}
+			if err := readAt(r, format, start); err != nil {
+				return nil, err
+			}
+			byteRate = binary.LittleEndian.Uint32(format[8:12])
+		case "data":
+			if byteRate == 0 {
+				return nil, fmt.Errorf("fmt chunk not found before data: %w", ErrInvalid)
+			}
+			// 録音中に書き出されたファイルなどはサイズが未記録のため、ファイルの末尾までをデータとする
+			if chunkSize == 0xFFFFFFFF || start+chunkSize > size {
+				chunkSize = size - start
+			}
+			return &Info{Duration: secondsToDuration(float64(chunkSize) / float64(byteRate))}, nil
+		}
+		// チャンクは 2 バイト境界に揃えられる
+		offset = start + chunkSize + chunkSize%2
+	}
+	return nil, fmt.Errorf("data chunk not found: %w", ErrInvalid)
+}
+
+// MP3（MPEG Audio Layer III）のビットレート（kbps）とサンプリング周波数
+var (
+	mp3BitratesV1   = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2   = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3SampleRateV1 = [4]int{44100, 48000, 32000, 0}
+)
+
+// mp3SyncSearchLimit は 最初のフレームを探す範囲（ID3 タグの後ろからのバイト数）です
+const mp3SyncSearchLimit = 64 * 1024
+
+// mp3Frame は MP3 のフレームヘッダーの内容です
+type mp3Frame struct {
+	mpeg1      bool
+	mono       bool
+	bitrate    int // bps
+	sampleRate int
+}
+
+// parseMP3Frame は 4 バイトのフレームヘッダーを解析します（Layer III 以外は false）
+func parseMP3Frame(header []byte) (mp3Frame, bool) {
+	if header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	version := (header[1] >> 3) & 0x03 // 0: MPEG 2.5, 2: MPEG 2, 3: MPEG 1
+	layer := (header[1] >> 1) & 0x03   // 1: Layer III
+	bitrateIndex := header[2] >> 4
+	sampleRateIndex := (header[2] >> 2) & 0x03
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return mp3Frame{}, false
+	}
+
+	frame := mp3Frame{mpeg1: version == 3, mono: header[3]>>6 == 3}
+	frame.sampleRate = mp3SampleRateV1[sampleRateIndex]
+	if frame.mpeg1 {
+		frame.bitrate = mp3BitratesV1[bitrateIndex] * 1000
+	} else {
+		frame.bitrate = mp3BitratesV2[bitrateIndex] * 1000
+		frame.sampleRate /= 2
+		if version == 0 {
+			frame.sampleRate /= 2
+		}
+	}
+	return frame, true
+}
+
+// samplesPerFrame は 1 フレームのサンプル数です
+func (f mp3Frame) samplesPerFrame() int {
+	if f.mpeg1 {
+		return 1152
+	}
+	return 576
+}
+
+// sideInfoSize は フレームヘッダーの後ろのサイド情報の大きさです（Xing ヘッダーはその後ろにある）
+func (f mp3Frame) sideInfoSize() int64 {
+	switch {
+	case f.mpeg1 && f.mono:
+		return 17
+	case f.mpeg1:
+		return 32
+	case f.mono:
+		return 9
+	default:
+		return 17
+	}
+}
+
+// probeMP3 は MP3 の再生時間を求めます
+// 可変ビットレートの場合は最初のフレームの Xing / VBRI ヘッダーのフレーム数、
+// 固定ビットレートの場合は音声データの大きさとビットレートから計算する
+func probeMP3(r io.ReaderAt, size int64) (*Info, error) {
+	// ID3v2 タグを読み飛ばす
+	start := int64(0)
+	tag := make([]byte, 10)
+	if size >= 10 {
+		if err := readAt(r, tag, 0); err != nil {
+			return nil, err
+		}
+		if string(tag[0:3]) == "ID3" {
+			tagSize := int64(tag[6]&0x7F)<<21 | int64(tag[7]&0x7F)<<14 | int64(tag[8]&0x7F)<<7 | int64(tag[9]&0x7F)
+			start = 10 + tagSize
+			if tag[5]&0x10 != 0 {
+				start += 10 // フッター
+			}
+		}
+	}
+
+	// 最初のフレームを探す
+	searchSize := min(size-start, mp3SyncSearchLimit+4)
+	if searchSize < 4 {
+		return nil, fmt.Errorf("mp3 frame not found: %w", ErrInvalid)
+	}
+	buf := make([]byte, searchSize)
+	if err := readAt(r, buf, start); err != nil {
+		return nil, err
+	}
+	frameOffset := int64(-1)
+	var frame mp3Frame
+	for i := 0; i+4 <= len(buf); i++ {
+		if f, ok := parseMP3Frame(buf[i : i+4]); ok {
+			frameOffset, frame = start+int64(i), f
+			break
+		}
+	}
+	if frameOffset < 0 {
+		return nil, fmt.Errorf("mp3 frame not found: %w", ErrInvalid)
+	}
+
+	// 可変ビットレートのヘッダー
+	if frames, ok := mp3VBRFrames(r, size, frameOffset, frame); ok {
+		seconds := float64(frames) * float64(frame.samplesPerFrame()) / float64(frame.sampleRate)
+		return &Info{Duration: secondsToDuration(seconds)}, nil
+	}
+
+	// 固定ビットレート（末尾の ID3v1 タグを除く）
+	end := size
+	if size-frameOffset >= 128 {
+		trailer := make([]byte, 3)
+		if err := readAt(r, trailer, size-128); err != nil {
+			return nil, err
+		}
+		if string(trailer) == "TAG" {
+			end -= 128
+		}
+	}
+	seconds := float64(end-frameOffset) * 8 / float64(frame.bitrate)
+	return &Info{Duration: secondsToDuration(seconds)}, nil
+}
+
+// mp3VBRFrames は 最初のフレームにある Xing（Info）または VBRI ヘッダーからフレーム数を読み込みます
+func mp3VBRFrames(r io.ReaderAt, size, frameOffset int64, frame mp3Frame) (uint32, bool) {
+	xingOffset := frameOffset + 4 + frame.sideInfoSize()
+	if xingOffset+12 <= size {
+		xing := make([]byte, 12)
+		if err := readAt(r, xing, xingOffset); err == nil {
+			id := string(xing[0:4])
+			flags := binary.BigEndian.Uint32(xing[4:8])
+			if (id == "Xing" || id == "Info") && flags&0x01 != 0 {
+				return binary.BigEndian.Uint32(xing[8:12]), true
+			}
+		}
+	}
+
+	vbriOffset := frameOffset + 4 + 32
+	if vbriOffset+18 <= size {
+		vbri := make([]byte, 18)
+		if err := readAt(r, vbri, vbriOffset); err == nil && string(vbri[0:4]) == "VBRI" {
+			return binary.BigEndian.Uint32(vbri[14:18]), true
+		}
+	}
+	return 0, false
+}
+
+// oggPageHeaderSize は Ogg のページヘッダーの固定部分の大きさです（後ろにセグメントテーブルが続く）
+const oggPageHeaderSize = 27
+
+// oggLastPageSearch は 最後のページを探す範囲（ファイルの末尾からのバイト数）です
+const oggLastPageSearch = 64 * 1024
+
+// probeOgg は Ogg（Vorbis / Opus）の再生時間を、最後のページのグラニュール位置とサンプリング周波数から求めます
+func probeOgg(r io.ReaderAt, size int64) (*Info, error) {
+	header := make([]byte, oggPageHeaderSize)
+	if err := readAt(r, header, 0); err != nil {
+		return nil, err
+	}
+	if string(header[0:4]) != "OggS" {
+		return nil, fmt.Errorf("ogg page not found: %w", ErrInvalid)
+	}
+
+	// 最初のパケットからコーデックとサンプリング周波数を判定する
+	segments := int64(header[26])
+	packet := make([]byte, 19)
+	if err := readAt(r, packet, oggPageHeaderSize+segments); err != nil {
+		return nil, err
+	}
+	var sampleRate, preSkip int64
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		sampleRate = int64(binary.LittleEndian.Uint32(packet[12:16]))
+	case bytes.HasPrefix(packet, []byte("OpusHead")):
+		// Opus のグラニュール位置は常に 48kHz で数え、先頭の pre-skip サンプルは再生しない
+		sampleRate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return nil, fmt.Errorf("ogg codec: %w", ErrUnsupported)
+	}
+	if sampleRate == 0 {
+		return nil, fmt.Errorf("ogg sample rate is zero: %w", ErrInvalid)
+	}
+
+	// 末尾から最後のページを探す
+	searchSize := min(size, oggLastPageSearch)
+	tail := make([]byte, searchSize)
+	if err := readAt(r, tail, size-searchSize); err != nil {
+		return nil, err
+	}
+	last := bytes.LastIndex(tail, []byte("OggS"))
+	if last < 0 || last+oggPageHeaderSize > len(tail) {
+		return nil, fmt.Errorf("last ogg page not found: %w", ErrInvalid)
+	}
+	granule := int64(binary.LittleEndian.Uint64(tail[last+6 : last+14]))
+	if granule < preSkip {
+		return &Info{}, nil
+	}
+	return &Info{Duration: secondsToDuration(float64(granule-preSkip) / float64(sampleRate))}, nil
+}
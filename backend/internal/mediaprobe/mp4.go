@@ -0,0 +1,157 @@
+package mediaprobe
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// mp4Box は MP4 のボックスの位置です（ヘッダーを除いた中身の範囲）
+type mp4Box struct {
+	kind  string
+	start int64
+	end   int64
+}
+
+// mp4Boxes は start〜end の範囲に並ぶボックスを列挙します
+func mp4Boxes(r io.ReaderAt, start, end int64, count *int) ([]mp4Box, error) {
+	var boxes []mp4Box
+	header := make([]byte, 16)
+	for offset := start; offset+8 <= end; {
+		if *count++; *count > maxElements {
+			return nil, fmt.Errorf("too many boxes: %w", ErrInvalid)
+		}
+		if err := readAt(r, header[:8], offset); err != nil {
+			return nil, err
+		}
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		headerSize := int64(8)
+		switch size {
+		case 0: // ファイルの末尾まで
+			size = end - offset
+		case 1: // 64ビットのサイズ
+			if err := readAt(r, header[8:16], offset+8); err != nil {
+				return nil, err
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if size < headerSize || offset+size > end || size < 0 {
+			return nil, fmt.Errorf("box %q has invalid size %d: %w", header[4:8], size, ErrInvalid)
+		}
+
+		boxes = append(boxes, mp4Box{kind: string(header[4:8]), start: offset + headerSize, end: offset + size})
+		offset += size
+	}
+	return boxes, nil
+}
+
+// probeMP4 は moov ボックスの mvhd から再生時間を、映像トラックの tkhd から映像の大きさを取得します
+func probeMP4(r io.ReaderAt, size int64) (*Info, error) {
+	count := 0
+	top, err := mp4Boxes(r, 0, size, &count)
+	if err != nil {
+		return nil, err
+	}
+	if len(top) == 0 || top[0].kind != "ftyp" {
+		return nil, fmt.Errorf("ftyp box not found: %w", ErrInvalid)
+	}
+
+	for _, box := range top {
+		if box.kind != "moov" {
+			continue
+		}
+		children, err := mp4Boxes(r, box.start, box.end, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		info := &Info{}
+		for _, child := range children {
+			switch child.kind {
+			case "mvhd":
+				if info.Duration, err = mp4Duration(r, child); err != nil {
+					return nil, err
+				}
+			case "trak":
+				if info.Width > 0 {
+					continue
+				}
+				if info.Width, info.Height, err = mp4TrackSize(r, child, &count); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return info, nil
+	}
+	return nil, fmt.Errorf("moov box not found: %w", ErrInvalid)
+}
+
+// mp4Duration は mvhd ボックスから再生時間を取得します（不明な場合は 0）
+func mp4Duration(r io.ReaderAt, box mp4Box) (time.Duration, error) {
+	buf := make([]byte, 32)
+	n := min(int64(len(buf)), box.end-box.start)
+	if n < 20 {
+		return 0, fmt.Errorf("mvhd box too short: %w", ErrInvalid)
+	}
+	if err := readAt(r, buf[:n], box.start); err != nil {
+		return 0, err
+	}
+
+	var timescale, duration uint64
+	if buf[0] == 1 {
+		if n < 32 {
+			return 0, fmt.Errorf("mvhd box too short: %w", ErrInvalid)
+		}
+		timescale = uint64(binary.BigEndian.Uint32(buf[20:24]))
+		duration = binary.BigEndian.Uint64(buf[24:32])
+		if duration == 1<<64-1 {
+			return 0, nil
+		}
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(buf[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(buf[16:20]))
+		if duration == 1<<32-1 {
+			return 0, nil
+		}
+	}
+	if timescale == 0 {
+		return 0, nil
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+// mp4TrackSize は trak ボックスの tkhd から映像の大きさを取得します（音声トラックは 0）
+func mp4TrackSize(r io.ReaderAt, trak mp4Box, count *int) (int, int, error) {
+	children, err := mp4Boxes(r, trak.start, trak.end, count)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, box := range children {
+		if box.kind != "tkhd" {
+			continue
+		}
+
+		version := make([]byte, 1)
+		if err := readAt(r, version, box.start); err != nil {
+			return 0, 0, err
+		}
+		// バージョン 0 は時刻と再生時間が 32 ビット、1 は 64 ビット
+		offset := int64(76)
+		if version[0] == 1 {
+			offset = 88
+		}
+		if box.end-box.start < offset+8 {
+			return 0, 0, fmt.Errorf("tkhd box too short: %w", ErrInvalid)
+		}
+
+		// 幅と高さは 16.16 の固定小数点数
+		buf := make([]byte, 8)
+		if err := readAt(r, buf, box.start+offset); err != nil {
+			return 0, 0, err
+		}
+		return int(binary.BigEndian.Uint32(buf[0:4]) >> 16), int(binary.BigEndian.Uint32(buf[4:8]) >> 16), nil
+	}
+	return 0, 0, nil
+}
@@ -0,0 +1,73 @@
+// Package mediaprobe は アップロードされた動画・音声の再生時間と映像の大きさの取得を提供します
+//
+// Prober を差し替えることで外部ツール（ffprobe など）を使った取得にも対応できる。
+// 組み込みの Builtin は標準ライブラリのみで MP4 / M4A（ISO BMFF）・WebM（Matroska）・MP3・WAV・Ogg のヘッダーを読む。
+// ファイル全体は読み込まず、必要な箇所だけを io.ReaderAt で読むため、ストレージ上の大きなファイルにも使える。
+package mediaprobe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnsupported は 対応していない形式のエラーです
+	ErrUnsupported = errors.New("unsupported media format")
+	// ErrInvalid は ヘッダーが壊れている、または必要な情報が見つからないファイルのエラーです
+	ErrInvalid = errors.New("invalid media")
+)
+
+// maxElements は 1つのファイルで読む要素（ボックス）の最大数です（壊れたファイルで処理が終わらないようにする）
+const maxElements = 10000
+
+// Info は 動画・音声の情報です（取得できなかった項目と、音声の映像の大きさは 0）
+type Info struct {
+	Width    int
+	Height   int
+	Duration time.Duration
+}
+
+// Prober は 動画・音声の情報を取得するインターフェースです
+type Prober interface {
+	// Probe は size バイトのファイル r から情報を取得します
+	Probe(ctx context.Context, r io.ReaderAt, size int64, contentType string) (*Info, error)
+}
+
+// Builtin は 標準ライブラリのみでヘッダーを読む Prober です
+type Builtin struct{}
+
+// コンパイル時にProberインターフェースを満たすことを確認
+var _ Prober = Builtin{}
+
+// Probe は contentType に応じた形式として情報を取得します
+func (Builtin) Probe(ctx context.Context, r io.ReaderAt, size int64, contentType string) (*Info, error) {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	switch strings.TrimSpace(mediaType) {
+	case "video/mp4", "audio/mp4", "audio/x-m4a":
+		return probeMP4(r, size)
+	case "video/webm":
+		return probeWebM(r, size)
+	case "audio/mpeg":
+		return probeMP3(r, size)
+	case "audio/wav", "audio/wave", "audio/x-wav":
+		return probeWAV(r, size)
+	case "audio/ogg":
+		return probeOgg(r, size)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// readAt は r の offset から len(buf) バイトを読み込みます（足りない場合は ErrInvalid）
+func readAt(r io.ReaderAt, buf []byte, offset int64) error {
+	if _, err := r.ReadAt(buf, offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrInvalid
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// mp4TestBox は テスト用の MP4 のボックスを作成します
+func mp4TestBox(kind string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(box, kind...), body...)
+}
+
+// mp4TestTkhd は バージョン 0 の tkhd ボックスを作成します
+func mp4TestTkhd(width, height int) []byte {
+	payload := make([]byte, 84)
+	binary.BigEndian.PutUint32(payload[76:80], uint32(width)<<16)
+	binary.BigEndian.PutUint32(payload[80:84], uint32(height)<<16)
+	return mp4TestBox("tkhd", payload)
+}
+
+// testMP4 は 音声トラックと映像トラックを持つ MP4 を作成します（moov はデータの後ろ）
+func testMP4(mvhdVersion byte) []byte {
+	var mvhd []byte
+	if mvhdVersion == 1 {
+		mvhd = make([]byte, 32)
+		mvhd[0] = 1
+		binary.BigEndian.PutUint32(mvhd[20:24], 1000)
+		binary.BigEndian.PutUint64(mvhd[24:32], 12500)
+	} else {
+		mvhd = make([]byte, 100)
+		binary.BigEndian.PutUint32(mvhd[12:16], 600)
+		binary.BigEndian.PutUint32(mvhd[16:20], 7500)
+	}
+
+	return bytes.Join([][]byte{
+		mp4TestBox("ftyp", []byte("isom\x00\x00\x02\x00isommp41")),
+		mp4TestBox("mdat", make([]byte, 1000)),
+		mp4TestBox("moov",
+			mp4TestBox("mvhd", mvhd),
+			mp4TestBox("trak", mp4TestTkhd(0, 0)),
+			mp4TestBox("trak", mp4TestTkhd(1920, 1080)),
+		),
+	}, nil)
+}
+
+// ebmlTestElement は テスト用の EBML 要素を作成します（サイズは 8 バイトで表す）
+func ebmlTestElement(id uint64, payload ...[]byte) []byte {
+	var element []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(element) > 0 {
+			element = append(element, b)
+		}
+	}
+	body := bytes.Join(payload, nil)
+	element = binary.BigEndian.AppendUint64(element, uint64(len(body))|0x01<<56)
+	return append(element, body...)
+}
+
+// ebmlTestUint は 符号なし整数の中身を作成します
+func ebmlTestUint(value uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, value)
+}
+
+// testWebM は 再生時間と映像の大きさを持つ WebM を作成します（Segment のサイズは不明）
+func testWebM() []byte {
+	duration := binary.BigEndian.AppendUint64(nil, math.Float64bits(4500))
+	segment := bytes.Join([][]byte{
+		ebmlTestElement(ebmlIDInfo,
+			ebmlTestElement(ebmlIDTimecodeScale, ebmlTestUint(1_000_000)),
+			ebmlTestElement(ebmlIDDuration, duration),
+		),
+		ebmlTestElement(ebmlIDTracks,
+			ebmlTestElement(ebmlIDTrackEntry,
+				ebmlTestElement(ebmlIDVideo,
+					ebmlTestElement(ebmlIDPixelWidth, ebmlTestUint(640)),
+					ebmlTestElement(ebmlIDPixelHeight, ebmlTestUint(360)),
+				),
+			),
+		),
+		ebmlTestElement(ebmlIDCluster, make([]byte, 100)),
+	}, nil)
+
+	return bytes.Join([][]byte{
+		ebmlTestElement(ebmlIDHeader, []byte{0x42, 0x82, 0x84, 'w', 'e', 'b', 'm'}),
+		{0x18, 0x53, 0x80, 0x67, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+		segment,
+	}, nil)
+}
+
+// testWAV は 1 秒あたり byteRate バイトで dataSize バイトの音声を持つ WAV を作成します（data の前に LIST チャンクを置く）
+func testWAV(byteRate, dataSize int) []byte {
+	format := make([]byte, 16)
+	binary.LittleEndian.PutUint16(format[0:2], 1)
+	binary.LittleEndian.PutUint16(format[2:4], 2)
+	binary.LittleEndian.PutUint32(format[4:8], uint32(byteRate/4))
+	binary.LittleEndian.PutUint32(format[8:12], uint32(byteRate))
+
+	chunk := func(id string, payload []byte) []byte {
+		header := binary.LittleEndian.AppendUint32([]byte(id), uint32(len(payload)))
+		if len(payload)%2 == 1 {
+			payload = append(payload, 0)
+		}
+		return append(header, payload...)
+	}
+	body := bytes.Join([][]byte{
+		[]byte("WAVE"),
+		chunk("fmt ", format),
+		chunk("LIST", []byte("INFOabc")),
+		chunk("data", make([]byte, dataSize)),
+	}, nil)
+	return append(binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body))), body...)
+}
+
+// testMP3 は 128kbps・44.1kHz・ステレオの MPEG1 Layer III を作成します
+// xingFrames が 0 より大きい場合は、最初のフレームにフレーム数を持つ Xing ヘッダーを入れる
+func testMP3(withID3 bool, audioBytes int, xingFrames uint32) []byte {
+	var data []byte
+	if withID3 {
+		// ID3v2 タグ（サイズは syncsafe 整数で 200 バイト）
+		data = append([]byte("ID3\x04\x00\x00\x00\x00\x01\x48"), make([]byte, 200)...)
+	}
+	frame := make([]byte, audioBytes)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x64})
+	if xingFrames > 0 {
+		xing := append([]byte("Xing"), 0, 0, 0, 1)
+		copy(frame[4+32:], binary.BigEndian.AppendUint32(xing, xingFrames))
+	}
+	data = append(data, frame...)
+	return append(data, append([]byte("TAG"), make([]byte, 125)...)...)
+}
+
+// testOgg は 最初のパケットが header で、最後のページのグラニュール位置が granule の Ogg を作成します
+func testOgg(header []byte, granule uint64) []byte {
+	page := func(granule uint64, packet []byte) []byte {
+		out := append([]byte("OggS"), 0, 0)
+		out = binary.LittleEndian.AppendUint64(out, granule)
+		out = append(out, make([]byte, 12)...)
+		out = append(out, 1, byte(len(packet)))
+		return append(out, packet...)
+	}
+	return bytes.Join([][]byte{
+		page(0, header),
+		page(0, make([]byte, 200)),
+		page(granule, make([]byte, 100)),
+	}, nil)
+}
+
+// testVorbisHeader は サンプリング周波数 sampleRate の Vorbis の識別ヘッダーを作成します
+func testVorbisHeader(sampleRate uint32) []byte {
+	header := append([]byte("\x01vorbis"), 0, 0, 0, 0, 2)
+	header = binary.LittleEndian.AppendUint32(header, sampleRate)
+	return append(header, make([]byte, 15)...)
+}
+
+// testOpusHeader は pre-skip が preSkip の Opus の識別ヘッダーを作成します
+func testOpusHeader(preSkip uint16) []byte {
+	header := append([]byte("OpusHead"), 1, 2)
+	header = binary.LittleEndian.AppendUint16(header, preSkip)
+	header = binary.LittleEndian.AppendUint32(header, 44100)
+	return append(header, 0, 0, 0)
+}
+
+func TestBuiltin_Probe(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		expected    Info
+		expectedErr error
+	}{
+		{
+			name:        "正常系：MP4（mvhd バージョン 0）",
+			data:        testMP4(0),
+			contentType: "video/mp4",
+			expected:    Info{Width: 1920, Height: 1080, Duration: 12500 * time.Millisecond},
+		},
+		{
+			name:        "正常系：MP4（mvhd バージョン 1）",
+			data:        testMP4(1),
+			contentType: "video/mp4",
+			expected:    Info{Width: 1920, Height: 1080, Duration: 12500 * time.Millisecond},
+		},
+		{
+			name:        "正常系：WebM",
+			data:        testWebM(),
+			contentType: "video/webm",
+			expected:    Info{Width: 640, Height: 360, Duration: 4500 * time.Millisecond},
+		},
+		{
+			name:        "正常系：M4A は MP4 として読む",
+			data:        testMP4(0),
+			contentType: "audio/x-m4a",
+			expected:    Info{Width: 1920, Height: 1080, Duration: 12500 * time.Millisecond},
+		},
+		{
+			name:        "正常系：WAV（data の前に別のチャンクがある）",
+			data:        testWAV(176400, 441000),
+			contentType: "audio/wav",
+			expected:    Info{Duration: 2500 * time.Millisecond},
+		},
+		{
+			name:        "正常系：固定ビットレートの MP3（ID3v1 タグを除く）",
+			data:        testMP3(false, 48000, 0),
+			contentType: "audio/mpeg",
+			expected:    Info{Duration: 3 * time.Second},
+		},
+		{
+			name:        "正常系：ID3v2 タグと Xing ヘッダーを持つ MP3",
+			data:        testMP3(true, 4000, 375),
+			contentType: "audio/mpeg",
+			expected:    Info{Duration: 9795918367},
+		},
+		{
+			name:        "正常系：Ogg Vorbis",
+			data:        testOgg(testVorbisHeader(44100), 198450),
+			contentType: "audio/ogg",
+			expected:    Info{Duration: 4500 * time.Millisecond},
+		},
+		{
+			name:        "正常系：Ogg Opus（pre-skip を除く）",
+			data:        testOgg(testOpusHeader(312), 96312),
+			contentType: "audio/ogg",
+			expected:    Info{Duration: 2 * time.Second},
+		},
+		{
+			name:        "異常系：moov がない MP4",
+			data:        mp4TestBox("ftyp", []byte("isom")),
+			contentType: "video/mp4",
+			expectedErr: ErrInvalid,
+		},
+		{
+			name:        "異常系：途中で切れた MP4",
+			data:        testMP4(0)[:40],
+			contentType: "video/mp4",
+			expectedErr: ErrInvalid,
+		},
+		{
+			name:        "異常系：WebM ではないデータ",
+			data:        []byte("not a video file"),
+			contentType: "video/webm",
+			expectedErr: ErrInvalid,
+		},
+		{
+			name:        "異常系：data チャンクがない WAV",
+			data:        testWAV(176400, 0)[:36],
+			contentType: "audio/wav",
+			expectedErr: ErrInvalid,
+		},
+		{
+			name:        "異常系：フレームが見つからない MP3",
+			data:        make([]byte, 1000),
+			contentType: "audio/mpeg",
+			expectedErr: ErrInvalid,
+		},
+		{
+			name:        "異常系：Vorbis・Opus 以外の Ogg",
+			data:        testOgg([]byte("\x80theora"+string(make([]byte, 20))), 100),
+			contentType: "audio/ogg",
+			expectedErr: ErrUnsupported,
+		},
+		{
+			name:        "異常系：対応していない形式",
+			data:        []byte("RIFF"),
+			contentType: "video/x-msvideo",
+			expectedErr: ErrUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Builtin{}.Probe(context.Background(), bytes.NewReader(tt.data), int64(len(tt.data)), tt.contentType)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Probe() error = %v, want %v", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Probe() error = %v", err)
+			}
+			if *info != tt.expected {
+				t.Errorf("Probe() = %+v, want %+v", *info, tt.expected)
+			}
+		})
+	}
+}
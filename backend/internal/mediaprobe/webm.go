@@ -0,0 +1,240 @@
+package mediaprobe
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// WebM（Matroska）の要素ID
+const (
+	ebmlIDHeader        = 0x1A45DFA3
+	ebmlIDSegment       = 0x18538067
+	ebmlIDInfo          = 0x1549A966
+	ebmlIDTimecodeScale = 0x2AD7B1
+	ebmlIDDuration      = 0x4489
+	ebmlIDTracks        = 0x1654AE6B
+	ebmlIDTrackEntry    = 0xAE
+	ebmlIDVideo         = 0xE0
+	ebmlIDPixelWidth    = 0xB0
+	ebmlIDPixelHeight   = 0xBA
+	ebmlIDCluster       = 0x1F43B675
+)
+
+// defaultTimecodeScale は TimecodeScale が省略された場合の値です（1 単位 = 1 ミリ秒）
+const defaultTimecodeScale = 1_000_000
+
+// ebmlElement は EBML の要素の位置です（ヘッダーを除いた中身の範囲。サイズ不明の場合は end が親の末尾）
+type ebmlElement struct {
+	id    uint64
+	start int64
+	end   int64
+}
+
+// readVint は offset から EBML の可変長整数を読み込み、値とバイト数を返します
+// keepMarker が true の場合は先頭の長さを示すビットを残す（要素IDの表現）
+// サイズとして読んだ値のすべてのビットが 1 の場合（サイズ不明）は -1 を返す
+func readVint(r io.ReaderAt, offset int64, keepMarker bool) (int64, int, error) {
+	first := make([]byte, 1)
+	if err := readAt(r, first, offset); err != nil {
+		return 0, 0, err
+	}
+	length := 1
+	for mask := byte(0x80); length <= 8 && first[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 {
+		return 0, 0, fmt.Errorf("invalid variable length integer: %w", ErrInvalid)
+	}
+
+	buf := make([]byte, length)
+	if err := readAt(r, buf, offset); err != nil {
+		return 0, 0, err
+	}
+	if !keepMarker {
+		buf[0] &= 0xFF >> length
+	}
+	var value uint64
+	allOnes := true
+	for i, b := range buf {
+		value = value<<8 | uint64(b)
+		if (i == 0 && b != 0xFF>>length) || (i > 0 && b != 0xFF) {
+			allOnes = false
+		}
+	}
+	if !keepMarker && allOnes {
+		return -1, length, nil
+	}
+	return int64(value), length, nil
+}
+
+// ebmlElements は start〜end の範囲に並ぶ要素を、stop の要素（中身は読まない）に達するまで列挙します
+func ebmlElements(r io.ReaderAt, start, end int64, stop uint64, count *int) ([]ebmlElement, error) {
+	var elements []ebmlElement
+	for offset := start; offset < end; {
+		if *count++; *count > maxElements {
+			return nil, fmt.Errorf("too many elements: %w", ErrInvalid)
+		}
+		id, idLength, err := readVint(r, offset, true)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(id) == stop {
+			break
+		}
+		size, sizeLength, err := readVint(r, offset+int64(idLength), false)
+		if err != nil {
+			return nil, err
+		}
+
+		element := ebmlElement{id: uint64(id), start: offset + int64(idLength+sizeLength), end: end}
+		if size >= 0 {
+			element.end = element.start + size
+		}
+		if element.end > end {
+			return nil, fmt.Errorf("element %x exceeds its parent: %w", id, ErrInvalid)
+		}
+		elements = append(elements, element)
+		offset = element.end
+	}
+	return elements, nil
+}
+
+// readEBMLUint は 要素の中身を符号なし整数として読み込みます
+func readEBMLUint(r io.ReaderAt, element ebmlElement) (uint64, error) {
+	length := element.end - element.start
+	if length < 1 || length > 8 {
+		return 0, fmt.Errorf("invalid integer element: %w", ErrInvalid)
+	}
+	buf := make([]byte, length)
+	if err := readAt(r, buf, element.start); err != nil {
+		return 0, err
+	}
+	var value uint64
+	for _, b := range buf {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+// readEBMLFloat は 要素の中身を浮動小数点数（4 または 8 バイト）として読み込みます
+func readEBMLFloat(r io.ReaderAt, element ebmlElement) (float64, error) {
+	buf := make([]byte, element.end-element.start)
+	switch len(buf) {
+	case 4, 8:
+	default:
+		return 0, fmt.Errorf("invalid float element: %w", ErrInvalid)
+	}
+	if err := readAt(r, buf, element.start); err != nil {
+		return 0, err
+	}
+	if len(buf) == 4 {
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(buf))), nil
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(buf)), nil
+}
+
+// probeWebM は Segment の Info から再生時間を、Tracks の映像トラックから映像の大きさを取得します
+// Info と Tracks は通常 Cluster（映像のデータ）より前にあるため、最初の Cluster で読み込みを止める
+func probeWebM(r io.ReaderAt, size int64) (*Info, error) {
+	count := 0
+	top, err := ebmlElements(r, 0, size, ebmlIDCluster, &count)
+	if err != nil {
+		return nil, err
+	}
+	if len(top) == 0 || top[0].id != ebmlIDHeader {
+		return nil, fmt.Errorf("EBML header not found: %w", ErrInvalid)
+	}
+
+	for _, segment := range top {
+		if segment.id != ebmlIDSegment {
+			continue
+		}
+		children, err := ebmlElements(r, segment.start, segment.end, ebmlIDCluster, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		info := &Info{}
+		for _, child := range children {
+			switch child.id {
+			case ebmlIDInfo:
+				if info.Duration, err = webmDuration(r, child, &count); err != nil {
+					return nil, err
+				}
+			case ebmlIDTracks:
+				if info.Width, info.Height, err = webmVideoSize(r, child, &count); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return info, nil
+	}
+	return nil, fmt.Errorf("segment not found: %w", ErrInvalid)
+}
+
+// webmDuration は Info 要素から再生時間を取得します（Duration が省略された場合は 0）
+func webmDuration(r io.ReaderAt, infoElement ebmlElement, count *int) (time.Duration, error) {
+	children, err := ebmlElements(r, infoElement.start, infoElement.end, 0, count)
+	if err != nil {
+		return 0, err
+	}
+
+	scale := uint64(defaultTimecodeScale)
+	duration := 0.0
+	for _, child := range children {
+		switch child.id {
+		case ebmlIDTimecodeScale:
+			if scale, err = readEBMLUint(r, child); err != nil {
+				return 0, err
+			}
+		case ebmlIDDuration:
+			if duration, err = readEBMLFloat(r, child); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return time.Duration(duration * float64(scale)), nil
+}
+
+// webmVideoSize は Tracks 要素の最初の映像トラックから映像の大きさを取得します
+func webmVideoSize(r io.ReaderAt, tracks ebmlElement, count *int) (int, int, error) {
+	entries, err := ebmlElements(r, tracks.start, tracks.end, 0, count)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, entry := range entries {
+		if entry.id != ebmlIDTrackEntry {
+			continue
+		}
+		fields, err := ebmlElements(r, entry.start, entry.end, 0, count)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, field := range fields {
+			if field.id != ebmlIDVideo {
+				continue
+			}
+			settings, err := ebmlElements(r, field.start, field.end, 0, count)
+			if err != nil {
+				return 0, 0, err
+			}
+			var width, height uint64
+			for _, setting := range settings {
+				switch setting.id {
+				case ebmlIDPixelWidth:
+					width, err = readEBMLUint(r, setting)
+				case ebmlIDPixelHeight:
+					height, err = readEBMLUint(r, setting)
+				}
+				if err != nil {
+					return 0, 0, err
+				}
+			}
+			return int(width), int(height), nil
+		}
+	}
+	return 0, 0, nil
+}
@@ -3,17 +3,26 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
 
 	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
 )
 
 type contextKey string
 
 const UserIDKey contextKey = "userID"
 
+// WorkspaceIDKey は 選択中のワークスペース（トークンの workspace_id クレーム）のコンテキストキーです
+const WorkspaceIDKey contextKey = "workspaceID"
+
+// WorkspaceRoleKey は 選択中のワークスペースでの役割（WorkspaceMiddleware が設定する）のコンテキストキーです
+const WorkspaceRoleKey contextKey = "workspaceRole"
+
 func AuthMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -43,54 +52,216 @@ func AuthMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 				cookie = &http.Cookie{Value: tokenString}
 			}
 
-			// JWTトークンを検証
-			token, err := jwt.Parse(cookie.Value, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return jwtSecret, nil
-			})
-
-			if err != nil || !token.Valid {
-				apierror.Write(w, r, apierror.NewUnauthorized(
-					"INVALID_TOKEN",
-					"トークンが無効または期限切れです",
-					err,
-				))
+			ctx, err := Authenticate(r.Context(), jwtSecret, cookie.Value)
+			if err != nil {
+				apierror.Write(w, r, err)
 				return
 			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
-				apierror.Write(w, r, apierror.NewUnauthorized(
-					"INVALID_TOKEN_CLAIMS",
-					"トークンのクレームが不正です",
-					nil,
-				))
+// Authenticate は JWT トークンを検証し、ユーザーID と選択中のワークスペースを設定したコンテキストを返します
+// Cookie を使わずにトークンを受け取る経路（gRPC のメタデータなど）からも使う
+func Authenticate(ctx context.Context, jwtSecret []byte, tokenString string) (context.Context, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return jwtSecret, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, apierror.NewUnauthorized(
+			"INVALID_TOKEN",
+			"トークンが無効または期限切れです",
+			err,
+		)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, apierror.NewUnauthorized(
+			"INVALID_TOKEN_CLAIMS",
+			"トークンのクレームが不正です",
+			nil,
+		)
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, apierror.NewUnauthorized(
+			"INVALID_USER_ID_CLAIM",
+			"ユーザーIDクレームが不正です",
+			nil,
+		)
+	}
+
+	// コンテキストにユーザーIDを設定
+	ctx = context.WithValue(ctx, UserIDKey, int(userID))
+
+	// ワークスペースを選択している場合のみ workspace_id クレームがある（メンバーかは WorkspaceMiddleware で確認する）
+	if workspaceID, ok := claims["workspace_id"].(float64); ok {
+		ctx = context.WithValue(ctx, WorkspaceIDKey, int(workspaceID))
+	}
+	return ctx, nil
+}
+
+func GetUserIDFromContext(ctx context.Context) int {
+	userID, ok := ctx.Value(UserIDKey).(int)
+	if !ok {
+		return 0
+	}
+	return userID
+}
+
+// GetWorkspaceIDFromContext は 選択中のワークスペースを返します（個人の文書を扱っている場合は nil）
+func GetWorkspaceIDFromContext(ctx context.Context) *int {
+	workspaceID, ok := ctx.Value(WorkspaceIDKey).(int)
+	if !ok {
+		return nil
+	}
+	return &workspaceID
+}
+
+// AdminChecker は ユーザーが管理者かどうかを判定するインターフェースです
+type AdminChecker interface {
+	IsAdmin(userID int) (bool, error)
+}
+
+// AdminMiddleware は 管理者以外のリクエストを 403 で拒否します
+// AuthMiddleware の後に適用する
+func AdminMiddleware(checker AdminChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			isAdmin, err := checker.IsAdmin(GetUserIDFromContext(r.Context()))
+			if err != nil {
+				apierror.Write(w, r, err)
 				return
 			}
-
-			userID, ok := claims["user_id"].(float64)
-			if !ok {
-				apierror.Write(w, r, apierror.NewUnauthorized(
-					"INVALID_USER_ID_CLAIM",
-					"ユーザーIDクレームが不正です",
+			if !isAdmin {
+				apierror.Write(w, r, apierror.NewForbidden(
+					"ADMIN_REQUIRED",
+					"管理者権限が必要です",
 					nil,
 				))
 				return
 			}
 
-			// コンテキストにユーザーIDを設定
-			ctx := context.WithValue(r.Context(), UserIDKey, int(userID))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WorkspaceChecker は ユーザーのワークスペースでの役割を返すインターフェースです（メンバーでない場合は空文字）
+type WorkspaceChecker interface {
+	MemberRole(workspaceID, userID int) (models.WorkspaceRole, error)
+}
+
+// WorkspaceMiddleware は トークンのワークスペースのメンバーでなくなったユーザーのリクエストを 403 で拒否し、
+// メンバーの場合は現在の役割をコンテキストに設定します
+// トークンの発行後にメンバーから削除された・役割が変わった場合も、次のリクエストから反映される
+// AuthMiddleware の後に適用する
+func WorkspaceMiddleware(checker WorkspaceChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, err := CheckWorkspaceMember(r.Context(), checker)
+			if err != nil {
+				apierror.Write(w, r, err)
+				return
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-func GetUserIDFromContext(ctx context.Context) int {
-	userID, ok := ctx.Value(UserIDKey).(int)
-	if !ok {
-		return 0
+// CheckWorkspaceMember は トークンのワークスペースのメンバーかを確認し、現在の役割を設定したコンテキストを返します
+// ワークスペースを選択していない場合は ctx をそのまま返す
+func CheckWorkspaceMember(ctx context.Context, checker WorkspaceChecker) (context.Context, error) {
+	workspaceID := GetWorkspaceIDFromContext(ctx)
+	if workspaceID == nil {
+		return ctx, nil
+	}
+
+	role, err := checker.MemberRole(*workspaceID, GetUserIDFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, apierror.NewForbidden(
+			"WORKSPACE_ACCESS_DENIED",
+			"選択中のワークスペースのメンバーではありません。ワークスペースを選択し直してください",
+			nil,
+		)
+	}
+	return context.WithValue(ctx, WorkspaceRoleKey, role), nil
+}
+
+// GetWorkspaceRoleFromContext は 選択中のワークスペースでの役割を返します（個人の文書を扱っている場合は空文字）
+func GetWorkspaceRoleFromContext(ctx context.Context) models.WorkspaceRole {
+	role, _ := ctx.Value(WorkspaceRoleKey).(models.WorkspaceRole)
+	return role
+}
+
+// RequireWorkspaceEditor は 選択中のワークスペースで編集できない役割（guest）のリクエストを 403 で拒否します
+// 文書の変更・ファイルのアップロードのハンドラーに個別に適用する。個人の文書を扱っている場合は常に通す
+func RequireWorkspaceEditor(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if GetWorkspaceIDFromContext(r.Context()) != nil && !GetWorkspaceRoleFromContext(r.Context()).CanEdit() {
+			apierror.Write(w, r, apierror.NewForbidden(
+				"WORKSPACE_READ_ONLY",
+				"このワークスペースでは閲覧のみ許可されています",
+				nil,
+			))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// DocumentAccessChecker は ユーザーの文書に対する役割を返すインターフェースです（アクセスできない場合は空文字）
+type DocumentAccessChecker interface {
+	DocumentRole(docID, userID int) (models.DocumentRole, error)
+}
+
+// RequireDocumentEditor は URL の文書（{id}）に閲覧のみの共同編集者（viewer）が行う変更を 403 で拒否します
+// 役割はリクエストごとに確認するため、役割の変更・削除は次のリクエストから反映される
+// アクセスできない文書の場合はそのまま通し、ハンドラーが 404 を返す
+func RequireDocumentEditor(checker DocumentAccessChecker) func(http.HandlerFunc) http.HandlerFunc {
+	return requireDocumentRole(checker, func(role models.DocumentRole) bool {
+		return role.CanEdit()
+	}, "DOCUMENT_READ_ONLY", "この文書は閲覧のみ許可されています")
+}
+
+// RequireDocumentOwner は URL の文書（{id}）の所有者以外（共同編集者）のリクエストを 403 で拒否します
+// 文書の削除・移動・公開・共有リンク・共同編集者の管理など、所有者のみができる操作に適用する
+func RequireDocumentOwner(checker DocumentAccessChecker) func(http.HandlerFunc) http.HandlerFunc {
+	return requireDocumentRole(checker, func(role models.DocumentRole) bool {
+		return role == models.DocumentRoleOwner
+	}, "DOCUMENT_OWNER_REQUIRED", "この操作は文書の所有者のみができます")
+}
+
+// requireDocumentRole は 文書に対する役割が allowed を満たさないリクエストを 403 で拒否します
+func requireDocumentRole(checker DocumentAccessChecker, allowed func(models.DocumentRole) bool, code, message string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			docID, err := strconv.Atoi(mux.Vars(r)["id"])
+			if err != nil {
+				next(w, r)
+				return
+			}
+
+			role, err := checker.DocumentRole(docID, GetUserIDFromContext(r.Context()))
+			if err != nil {
+				apierror.Write(w, r, err)
+				return
+			}
+			if role != "" && !allowed(role) {
+				apierror.Write(w, r, apierror.NewForbidden(code, message, nil))
+				return
+			}
+			next(w, r)
+		}
 	}
-	return userID
 }
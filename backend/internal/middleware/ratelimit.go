@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/ratelimit"
+)
+
+// RateLimitKeyFunc は リクエストを制限するキーを返します（空文字列の場合は制限しない）
+type RateLimitKeyFunc func(r *http.Request) string
+
+// RateLimitMiddleware は キーごとにリクエストの頻度をトークンバケットで制限します
+// 上限を超えたリクエストには Retry-After を付けて 429 を返し、onThrottle を呼び出す（nil の場合は呼び出さない）
+// Limiter に接続できない場合は制限せずに受け付ける（Redis の障害で全てのリクエストを拒否しない）
+func RateLimitMiddleware(limiter ratelimit.Limiter, limit ratelimit.Limit, key RateLimitKeyFunc, onThrottle func(r *http.Request)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := limiter.Allow(r.Context(), k, limit)
+			if err != nil {
+				log.Printf("Rate limiter unavailable, allowing request: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			if result.Allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if onThrottle != nil {
+				onThrottle(r)
+			}
+			seconds := int((result.RetryAfter + time.Second - 1) / time.Second)
+			if seconds < 1 {
+				seconds = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			apierror.Write(w, r, apierror.NewTooManyRequests(
+				"RATE_LIMITED",
+				fmt.Sprintf("リクエストが多すぎます。%d秒後に再度お試しください", seconds),
+				nil,
+			))
+		})
+	}
+}
+
+// ClientIP は リクエストの送信元の IP アドレスを返します
+// trustProxy が true の場合は X-Forwarded-For の最後のアドレス（手前のプロキシが見た送信元）を使う
+// 手前にプロキシがない場合に true にすると、クライアントが送信元を偽れるため注意する
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			parts := strings.Split(values[len(values)-1], ",")
+			if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
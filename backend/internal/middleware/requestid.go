@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"simple-notion-backend/internal/requestid"
+)
+
+// RequestIDMiddleware は リクエストごとの ID をコンテキストとレスポンスヘッダー（X-Request-ID）に設定します
+// クライアント・ロードバランサーが X-Request-ID を付けている場合はその値を引き継ぎ、ない場合（または形式が不正な場合）は生成する
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
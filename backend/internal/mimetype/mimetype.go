@@ -0,0 +1,119 @@
+// Package mimetype はアップロードを許可する MIME タイプの一覧を提供する。
+// 一覧はファイルの種類（画像・文書・動画・音声）ごとに設定で変更でき、設定しない種類は既定の一覧を使う。
+package mimetype
+
+import (
+	"sort"
+	"strings"
+)
+
+// Category は アップロードするファイルの種類です
+type Category string
+
+const (
+	Image    Category = "image"
+	Document Category = "document"
+	Video    Category = "video"
+	Audio    Category = "audio"
+)
+
+// defaultTypes は 種類ごとの既定の許可する MIME タイプです
+var defaultTypes = map[Category][]string{
+	Image: {
+		"image/jpeg",
+		"image/jpg",
+		"image/png",
+		"image/webp",
+		"image/gif",
+	},
+	Document: {
+		"application/pdf",
+		"application/msword",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.ms-excel",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.ms-powerpoint",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"text/plain",
+		"text/csv",
+	},
+	Video: {
+		"video/mp4",
+		"video/webm",
+	},
+	Audio: {
+		"audio/mpeg",
+		"audio/wav",
+		"audio/wave",
+		"audio/x-wav",
+		"audio/mp4",
+		"audio/x-m4a",
+		"audio/ogg",
+	},
+}
+
+// DefaultTypes は 種類の既定の許可する MIME タイプを返します
+func DefaultTypes(category Category) []string {
+	return append([]string(nil), defaultTypes[category]...)
+}
+
+// Allowlist は 種類ごとにアップロードを許可する MIME タイプの一覧です
+// nil の場合はすべての種類で既定の一覧を使う
+type Allowlist struct {
+	types map[Category]map[string]bool
+}
+
+// NewAllowlist は 種類ごとの MIME タイプから Allowlist を作成します
+// types に含まれない（または空の）種類は既定の一覧を使う。MIME タイプは大文字・小文字を区別しない
+func NewAllowlist(types map[Category][]string) *Allowlist {
+	a := &Allowlist{types: make(map[Category]map[string]bool, len(defaultTypes))}
+	for category, defaults := range defaultTypes {
+		list := types[category]
+		if len(list) == 0 {
+			list = defaults
+		}
+		set := make(map[string]bool, len(list))
+		for _, contentType := range list {
+			if contentType = normalize(contentType); contentType != "" {
+				set[contentType] = true
+			}
+		}
+		a.types[category] = set
+	}
+	return a
+}
+
+// Allowed は contentType が category のファイルとしてアップロードできるかを返します
+func (a *Allowlist) Allowed(category Category, contentType string) bool {
+	contentType = normalize(contentType)
+	if contentType == "" {
+		return false
+	}
+	if a == nil {
+		for _, allowed := range defaultTypes[category] {
+			if allowed == contentType {
+				return true
+			}
+		}
+		return false
+	}
+	return a.types[category][contentType]
+}
+
+// Types は category で許可する MIME タイプを並べ替えて返します
+func (a *Allowlist) Types(category Category) []string {
+	if a == nil {
+		return DefaultTypes(category)
+	}
+	types := make([]string, 0, len(a.types[category]))
+	for contentType := range a.types[category] {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// normalize は MIME タイプの前後の空白を除いて小文字にします
+func normalize(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
@@ -0,0 +1,152 @@
+package mimetype
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestAllowlist_DefaultImageTypes は 既定の画像の MIME タイプのテストです
+func TestAllowlist_DefaultImageTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		expected    bool
+	}{
+		{
+			name:        "JPEG画像",
+			contentType: "image/jpeg",
+			expected:    true,
+		},
+		{
+			name:        "PNG画像",
+			contentType: "image/png",
+			expected:    true,
+		},
+		{
+			name:        "WebP画像",
+			contentType: "image/webp",
+			expected:    true,
+		},
+		{
+			name:        "GIF画像",
+			contentType: "image/gif",
+			expected:    true,
+		},
+		{
+			name:        "無効なタイプ（PDF）",
+			contentType: "application/pdf",
+			expected:    false,
+		},
+		{
+			name:        "無効なタイプ（テキスト）",
+			contentType: "text/plain",
+			expected:    false,
+		},
+		{
+			name:        "空の文字列",
+			contentType: "",
+			expected:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewAllowlist(nil).Allowed(Image, tt.contentType)
+			if result != tt.expected {
+				t.Errorf("Allowed(Image, %q) = %v, want %v", tt.contentType, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestAllowlist_DefaultDocumentTypes は 既定の文書ファイルの MIME タイプのテストです
+func TestAllowlist_DefaultDocumentTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		expected    bool
+	}{
+		{
+			name:        "PDF",
+			contentType: "application/pdf",
+			expected:    true,
+		},
+		{
+			name:        "Word（docx）",
+			contentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			expected:    true,
+		},
+		{
+			name:        "大文字を含むタイプ",
+			contentType: "Application/PDF",
+			expected:    true,
+		},
+		{
+			name:        "無効なタイプ（HTML）",
+			contentType: "text/html",
+			expected:    false,
+		},
+		{
+			name:        "無効なタイプ（画像）",
+			contentType: "image/png",
+			expected:    false,
+		},
+		{
+			name:        "空の文字列",
+			contentType: "",
+			expected:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewAllowlist(nil).Allowed(Document, tt.contentType)
+			if result != tt.expected {
+				t.Errorf("Allowed(Document, %q) = %v, want %v", tt.contentType, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestAllowlist_Configured は 設定した MIME タイプの一覧のテストです
+func TestAllowlist_Configured(t *testing.T) {
+	allowlist := NewAllowlist(map[Category][]string{
+		Image: {"image/png", " Image/SVG+XML ", "image/heic"},
+	})
+
+	tests := []struct {
+		name        string
+		category    Category
+		contentType string
+		expected    bool
+	}{
+		{name: "正常系：追加した SVG", category: Image, contentType: "image/svg+xml", expected: true},
+		{name: "正常系：追加した HEIC", category: Image, contentType: "image/heic", expected: true},
+		{name: "異常系：一覧に含めなかった既定の JPEG", category: Image, contentType: "image/jpeg", expected: false},
+		{name: "正常系：設定しない種類は既定の一覧", category: Video, contentType: "video/mp4", expected: true},
+		{name: "異常系：他の種類の MIME タイプ", category: Document, contentType: "image/svg+xml", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowlist.Allowed(tt.category, tt.contentType); got != tt.expected {
+				t.Errorf("Allowed(%s, %q) = %v, want %v", tt.category, tt.contentType, got, tt.expected)
+			}
+		})
+	}
+
+	if got, want := allowlist.Types(Image), []string{"image/heic", "image/png", "image/svg+xml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Types(Image) = %v, want %v", got, want)
+	}
+}
+
+// TestAllowlist_Nil は nil の Allowlist が既定の一覧を使うテストです
+func TestAllowlist_Nil(t *testing.T) {
+	var allowlist *Allowlist
+	if !allowlist.Allowed(Audio, "audio/mpeg") {
+		t.Error("Allowed(Audio, audio/mpeg) = false, want true")
+	}
+	if allowlist.Allowed(Image, "image/svg+xml") {
+		t.Error("Allowed(Image, image/svg+xml) = true, want false")
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrIncompatibleBlockTypes は 内容を引き継いで変換できないブロックの種類の組み合わせです
+var ErrIncompatibleBlockTypes = errors.New("incompatible block types")
+
+// textBlockTypes は 本文を JSON 文字列（プレーンテキストまたは TipTap JSON）で持つブロックの種類です
+// これらの間では content をそのまま引き継いで変換できる
+var textBlockTypes = map[string]bool{
+	"text":     true,
+	"heading1": true,
+	"heading2": true,
+	"heading3": true,
+	"bullet":   true,
+	"numbered": true,
+	"quote":    true,
+	"toggle":   true,
+}
+
+// CanConvertBlockType は from の種類のブロックを to の種類に変換できるかを返します
+func CanConvertBlockType(from, to string) bool {
+	convertible := func(t string) bool { return textBlockTypes[t] || t == "todo" }
+	return convertible(from) && convertible(to)
+}
+
+// ConvertBlock は ブロックを toType の種類に変換し、content を変換先の形式に移し替えたブロックを返します
+// テキスト系のブロック同士は content をそのまま使い、todo との間では本文を text に移す（チェック状態と期限は引き継がない）
+func ConvertBlock(block Block, toType string) (Block, error) {
+	if !CanConvertBlockType(block.Type, toType) {
+		return Block{}, fmt.Errorf("%s to %s: %w", block.Type, toType, ErrIncompatibleBlockTypes)
+	}
+
+	text, err := blockText(block)
+	if err != nil {
+		return Block{}, err
+	}
+
+	var content []byte
+	if toType == "todo" {
+		if block.Type == "todo" {
+			content = NormalizeBlockContent(block.Type, block.Content)
+		} else {
+			content, err = json.Marshal(TodoContent{Text: text})
+		}
+	} else {
+		content, err = json.Marshal(text)
+	}
+	if err != nil {
+		return Block{}, err
+	}
+
+	converted := block
+	converted.Type = toType
+	converted.Content = content
+	return converted, nil
+}
+
+// blockText は テキスト系・todo ブロックの本文（TipTap JSON の場合はその文字列）を取り出します
+func blockText(block Block) (string, error) {
+	if block.Type == "todo" {
+		var todo TodoContent
+		if err := json.Unmarshal(NormalizeBlockContent(block.Type, block.Content), &todo); err != nil {
+			return "", fmt.Errorf("invalid todo content: %w", err)
+		}
+		return todo.Text, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(block.Content, &text); err != nil {
+		return "", fmt.Errorf("invalid %s content: %w", block.Type, err)
+	}
+	return text, nil
+}
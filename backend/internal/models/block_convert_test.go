@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestConvertBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		block   Block
+		toType  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "正常系：段落から見出し",
+			block:  Block{Type: "text", Content: json.RawMessage(`"hello"`)},
+			toType: "heading1",
+			want:   `"hello"`,
+		},
+		{
+			name:   "正常系：箇条書きから番号付きリスト（TipTap JSON）",
+			block:  Block{Type: "bullet", Content: json.RawMessage(`"{\"type\":\"doc\"}"`)},
+			toType: "numbered",
+			want:   `"{\"type\":\"doc\"}"`,
+		},
+		{
+			name:   "正常系：段落から todo",
+			block:  Block{Type: "text", Content: json.RawMessage(`"buy milk"`)},
+			toType: "todo",
+			want:   `{"text":"buy milk","checked":false}`,
+		},
+		{
+			name:   "正常系：todo から段落（チェック状態は引き継がない）",
+			block:  Block{Type: "todo", Content: json.RawMessage(`{"text":"done","checked":true}`)},
+			toType: "text",
+			want:   `"done"`,
+		},
+		{
+			name:    "異常系：互換性のない種類",
+			block:   Block{Type: "text", Content: json.RawMessage(`"hello"`)},
+			toType:  "image",
+			wantErr: true,
+		},
+		{
+			name:    "異常系：変換元が互換性のない種類",
+			block:   Block{Type: "code", Content: json.RawMessage(`{"language":"go","content":"x"}`)},
+			toType:  "text",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertBlock(tt.block, tt.toType)
+			if tt.wantErr {
+				if !errors.Is(err, ErrIncompatibleBlockTypes) {
+					t.Fatalf("ConvertBlock() error = %v, want ErrIncompatibleBlockTypes", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConvertBlock() error = %v", err)
+			}
+			if got.Type != tt.toType {
+				t.Errorf("Type = %q, want %q", got.Type, tt.toType)
+			}
+			if string(got.Content) != tt.want {
+				t.Errorf("Content = %s, want %s", got.Content, tt.want)
+			}
+		})
+	}
+}
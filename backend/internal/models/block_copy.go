@@ -0,0 +1,82 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MaxCopyBlocks は 1回のコピーで扱えるブロック数の上限です（子孫を含む）
+const MaxCopyBlocks = 500
+
+// PlanBlockCopy は コピー元の文書のブロック一覧から、指定したブロックとその子孫の複製を作ります
+// 複製は親が子より先に、兄弟は Position の昇順に並ぶ（この順に挿入すれば元の並びを保てる）
+// 複製の ID にはコピー元のブロックIDを残し、保存時に親子関係を新しい ID へ付け替えるために使う
+// 親も複製するブロックは親のコピー元のIDを ParentBlockID に持ち、それ以外（貼り付け先で最上位になるブロック）は nil にする
+func PlanBlockCopy(source []Block, ids []int) ([]Block, error) {
+	sorted := make([]Block, len(source))
+	copy(sorted, source)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Position < sorted[j].Position })
+
+	byID := make(map[int]Block, len(sorted))
+	children := make(map[int][]Block)
+	for _, b := range sorted {
+		byID[b.ID] = b
+		if b.ParentBlockID != nil {
+			children[*b.ParentBlockID] = append(children[*b.ParentBlockID], b)
+		}
+	}
+
+	selected := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := byID[id]; !ok {
+			return nil, fmt.Errorf("block id=%d is not in the source document", id)
+		}
+		selected[id] = true
+	}
+
+	// 祖先が選択されているブロックは祖先と一緒に複製される
+	isCovered := func(b Block) bool {
+		for depth := 0; b.ParentBlockID != nil && depth < MaxBlockDepth; depth++ {
+			parent, ok := byID[*b.ParentBlockID]
+			if !ok {
+				return false
+			}
+			if selected[parent.ID] {
+				return true
+			}
+			b = parent
+		}
+		return false
+	}
+
+	var planned []Block
+	visited := make(map[int]bool)
+	var visit func(b Block, parent *int)
+	visit = func(b Block, parent *int) {
+		if visited[b.ID] {
+			return
+		}
+		visited[b.ID] = true
+
+		dup := b.Duplicate()
+		dup.ID = b.ID
+		dup.ParentBlockID = parent
+		planned = append(planned, dup)
+
+		id := b.ID
+		for _, child := range children[b.ID] {
+			visit(child, &id)
+		}
+	}
+
+	for _, b := range sorted {
+		if selected[b.ID] && !isCovered(b) {
+			visit(b, nil)
+		}
+	}
+
+	if len(planned) > MaxCopyBlocks {
+		return nil, fmt.Errorf("%d blocks exceed the limit of %d", len(planned), MaxCopyBlocks)
+	}
+	return planned, nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import "testing"
+
+func TestPlanBlockCopy(t *testing.T) {
+	source := []Block{
+		{ID: 1, Type: "toggle", Position: 0},
+		{ID: 2, ParentBlockID: intPtr(1), Type: "text", Position: 2},
+		{ID: 3, ParentBlockID: intPtr(2), Type: "text", Position: 3},
+		{ID: 4, ParentBlockID: intPtr(1), Type: "image", Position: 1},
+		{ID: 5, Type: "text", Position: 4},
+	}
+
+	t.Run("正常系：子孫も含めて親から順に複製する", func(t *testing.T) {
+		// 3 は 1 の子孫として複製されるため重複させない
+		planned, err := PlanBlockCopy(source, []int{5, 3, 1})
+		if err != nil {
+			t.Fatalf("PlanBlockCopy() error = %v", err)
+		}
+
+		want := []struct {
+			id     int
+			parent *int
+		}{
+			{1, nil},
+			{4, intPtr(1)},
+			{2, intPtr(1)},
+			{3, intPtr(2)},
+			{5, nil},
+		}
+		if len(planned) != len(want) {
+			t.Fatalf("len(planned) = %d, want %d: %+v", len(planned), len(want), planned)
+		}
+		for i, w := range want {
+			if planned[i].ID != w.id || !equalIntPtr(planned[i].ParentBlockID, w.parent) {
+				t.Errorf("planned[%d] = id %d parent %v, want id %d parent %v",
+					i, planned[i].ID, planned[i].ParentBlockID, w.id, w.parent)
+			}
+		}
+	})
+
+	t.Run("正常系：子だけを選んだ場合は貼り付け先で最上位にする", func(t *testing.T) {
+		planned, err := PlanBlockCopy(source, []int{2})
+		if err != nil {
+			t.Fatalf("PlanBlockCopy() error = %v", err)
+		}
+		if len(planned) != 2 || planned[0].ParentBlockID != nil || !equalIntPtr(planned[1].ParentBlockID, intPtr(2)) {
+			t.Errorf("planned = %+v", planned)
+		}
+	})
+
+	t.Run("異常系：コピー元にないブロック", func(t *testing.T) {
+		if _, err := PlanBlockCopy(source, []int{99}); err == nil {
+			t.Error("expected error for unknown block id")
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MaxBlockHistoryEvents は ブロックの変更履歴として返す最大件数です（新しいものから）
+const MaxBlockHistoryEvents = 100
+
+// BlockEditEvent は ブロックの種類・内容の変更1回分の記録です
+// 文書全体の保存でブロックが作り直されても、同じブロックとして保存されたものは履歴を引き継ぐ
+type BlockEditEvent struct {
+	ID         int             `json:"id" db:"id"`
+	DocumentID int             `json:"documentId" db:"document_id"`
+	BlockID    int             `json:"blockId" db:"block_id"`
+	EditorID   *int            `json:"editorId" db:"editor_id"` // 編集者が不明・退会済みの場合は nil
+	OldType    string          `json:"oldType" db:"old_type"`
+	NewType    string          `json:"newType" db:"new_type"`
+	OldContent json.RawMessage `json:"oldContent" db:"old_content"`
+	NewContent json.RawMessage `json:"newContent" db:"new_content"`
+	CreatedAt  time.Time       `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "encoding/json"
+
+// BlockOperationType は 差分保存で使うブロック操作の種類です
+type BlockOperationType string
+
+const (
+	BlockOpInsert BlockOperationType = "insert" // 新しいブロックを position に挿入
+	BlockOpUpdate BlockOperationType = "update" // blockId のブロックの type / content を変更
+	BlockOpDelete BlockOperationType = "delete" // blockId のブロックを削除
+	BlockOpMove   BlockOperationType = "move"   // blockId のブロックを position に移動
+)
+
+// BlockOperation は 差分保存リクエストの1操作です
+// 操作は配列の順に適用され、position は直前の操作を適用した後の位置を指す
+type BlockOperation struct {
+	Op       BlockOperationType `json:"op"`
+	BlockID  int                `json:"blockId,omitempty"`
+	Type     string             `json:"type,omitempty"`
+	Content  json.RawMessage    `json:"content,omitempty"`
+	Position *int               `json:"position,omitempty"`
+	// ParentBlockID は insert で入れ子のブロックを追加する場合の親です
+	ParentBlockID *int `json:"parentBlockId,omitempty"`
+}
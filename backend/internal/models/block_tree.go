@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MaxBlockDepth は ブロックを入れ子にできる最大の深さです（トップレベルは 1）
+const MaxBlockDepth = 8
+
+// BlockTreeNode は 入れ子構造で返すブロックです
+type BlockTreeNode struct {
+	Block
+	Collapsed bool            `json:"collapsed,omitempty"` // トグルを折りたたんでいる（ユーザーごと）
+	Children  []BlockTreeNode `json:"children"`
+}
+
+// BuildBlockTree は フラットなブロック一覧を親子関係に沿った木構造に変換します
+// 兄弟の順序は Position の昇順で、親が一覧に含まれないブロックはトップレベルとして扱う
+// collapsedIDs に含まれるブロックは Collapsed を true にする
+func BuildBlockTree(blocks []Block, collapsedIDs []int) []BlockTreeNode {
+	sorted := make([]Block, len(blocks))
+	copy(sorted, blocks)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Position < sorted[j].Position })
+
+	collapsed := make(map[int]bool, len(collapsedIDs))
+	for _, id := range collapsedIDs {
+		collapsed[id] = true
+	}
+
+	exists := make(map[int]bool, len(sorted))
+	for _, b := range sorted {
+		exists[b.ID] = true
+	}
+
+	children := make(map[int][]Block)
+	var roots []Block
+	for _, b := range sorted {
+		if b.ParentBlockID != nil && exists[*b.ParentBlockID] && *b.ParentBlockID != b.ID {
+			children[*b.ParentBlockID] = append(children[*b.ParentBlockID], b)
+			continue
+		}
+		roots = append(roots, b)
+	}
+
+	var build func(level []Block) []BlockTreeNode
+	build = func(level []Block) []BlockTreeNode {
+		nodes := make([]BlockTreeNode, 0, len(level))
+		for _, b := range level {
+			nodes = append(nodes, BlockTreeNode{Block: b, Collapsed: collapsed[b.ID], Children: build(children[b.ID])})
+		}
+		return nodes
+	}
+
+	return build(roots)
+}
+
+// ValidateBlockHierarchy は 一括保存するブロック一覧の親子関係を検証します
+// 親は同じ一覧内のブロックの ID を指す必要があり（保存時に新しい ID へ置き換えるため）、循環や深すぎる入れ子は許可しない
+func ValidateBlockHierarchy(blocks []Block) error {
+	parents := make(map[int]*int, len(blocks))
+	for i, b := range blocks {
+		if b.ID == 0 {
+			continue
+		}
+		if _, dup := parents[b.ID]; dup {
+			return fmt.Errorf("block %d: duplicate block id=%d", i, b.ID)
+		}
+		parents[b.ID] = b.ParentBlockID
+	}
+
+	for i, b := range blocks {
+		depth := 1
+		for parentID := b.ParentBlockID; parentID != nil; depth++ {
+			next, ok := parents[*parentID]
+			if !ok {
+				return fmt.Errorf("block %d: parent block id=%d is not in the list", i, *parentID)
+			}
+			if depth >= MaxBlockDepth {
+				// 循環している場合もここで打ち切られる
+				return fmt.Errorf("block %d: nesting deeper than %d levels or circular", i, MaxBlockDepth)
+			}
+			parentID = next
+		}
+	}
+	return nil
+}
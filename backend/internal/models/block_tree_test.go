@@ -0,0 +1,90 @@
+package models
+
+import "testing"
+
+func intPtr(v int) *int { return &v }
+
+func TestBuildBlockTree(t *testing.T) {
+	// 親より前に位置する子・一覧にない親を含む
+	blocks := []Block{
+		{ID: 3, ParentBlockID: intPtr(1), Position: 2},
+		{ID: 1, Position: 0},
+		{ID: 2, ParentBlockID: intPtr(1), Position: 1},
+		{ID: 4, ParentBlockID: intPtr(2), Position: 3},
+		{ID: 5, Position: 4},
+		{ID: 6, ParentBlockID: intPtr(99), Position: 5},
+	}
+
+	tree := BuildBlockTree(blocks, []int{2})
+
+	if len(tree) != 3 || tree[0].ID != 1 || tree[1].ID != 5 || tree[2].ID != 6 {
+		t.Fatalf("roots = %+v, want ids [1 5 6]", tree)
+	}
+	children := tree[0].Children
+	if len(children) != 2 || children[0].ID != 2 || children[1].ID != 3 {
+		t.Fatalf("children of 1 = %+v, want ids [2 3]", children)
+	}
+	if !children[0].Collapsed || children[1].Collapsed {
+		t.Errorf("collapsed = [%v %v], want [true false]", children[0].Collapsed, children[1].Collapsed)
+	}
+	if len(children[0].Children) != 1 || children[0].Children[0].ID != 4 {
+		t.Errorf("children of 2 = %+v, want ids [4]", children[0].Children)
+	}
+	if tree[1].Children == nil || len(tree[1].Children) != 0 {
+		t.Errorf("children of 5 = %#v, want empty slice", tree[1].Children)
+	}
+}
+
+func TestValidateBlockHierarchy(t *testing.T) {
+	deep := make([]Block, MaxBlockDepth+1)
+	for i := range deep {
+		deep[i] = Block{ID: i + 1}
+		if i > 0 {
+			deep[i].ParentBlockID = intPtr(i)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		blocks  []Block
+		wantErr bool
+	}{
+		{
+			name:   "正常系：親が後ろにあってもよい",
+			blocks: []Block{{ID: 2, ParentBlockID: intPtr(1)}, {ID: 1}},
+		},
+		{
+			name:   "正常系：最大の深さ",
+			blocks: deep[:MaxBlockDepth],
+		},
+		{
+			name:    "異常系：一覧にない親",
+			blocks:  []Block{{ID: 1}, {ID: 2, ParentBlockID: intPtr(9)}},
+			wantErr: true,
+		},
+		{
+			name:    "異常系：ID の重複",
+			blocks:  []Block{{ID: 1}, {ID: 1}},
+			wantErr: true,
+		},
+		{
+			name:    "異常系：循環",
+			blocks:  []Block{{ID: 1, ParentBlockID: intPtr(2)}, {ID: 2, ParentBlockID: intPtr(1)}},
+			wantErr: true,
+		},
+		{
+			name:    "異常系：深すぎる入れ子",
+			blocks:  deep,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBlockHierarchy(tt.blocks)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBlockHierarchy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
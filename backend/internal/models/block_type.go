@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// BuiltinBlockTypes は サーバーに組み込まれているブロックの種類です
+// カスタムブロックタイプはこれらと同じ名前では登録できない
+var BuiltinBlockTypes = map[string]bool{
+	"text":       true,
+	"heading1":   true,
+	"heading2":   true,
+	"heading3":   true,
+	"bullet":     true,
+	"numbered":   true,
+	"quote":      true,
+	"code":       true,
+	"todo":       true,
+	"toggle":     true,
+	"table":      true,
+	"image":      true,
+	"file":       true,
+	"audio":      true,
+	"bookmark":   true,
+	"embed":      true,
+	"math":       true,
+	"synced":     true,
+	"columnList": true,
+	"column":     true,
+}
+
+// CollapsibleBlockTypes は 子ブロックを折りたたんで表示できるブロックの種類です
+var CollapsibleBlockTypes = map[string]bool{
+	"toggle": true,
+}
+
+// StructuredBlockTypes は content をオブジェクトとして保存する組み込みブロックの種類です
+var StructuredBlockTypes = map[string]bool{
+	"image":      true,
+	"file":       true,
+	"audio":      true,
+	"todo":       true,
+	"table":      true,
+	"bookmark":   true,
+	"embed":      true,
+	"math":       true,
+	"synced":     true,
+	"columnList": true,
+	"column":     true,
+}
+
+// NormalizeBlockContent は JSON 文字列として送られてきたオブジェクトの content をオブジェクトに戻します
+// JSONB の演算子（@> など）と GIN インデックスで content の中身を検索できるようにするため、保存前に呼び出す
+// 対象外のブロックや解析できない content はそのまま返す
+func NormalizeBlockContent(blockType string, content json.RawMessage) json.RawMessage {
+	if !StructuredBlockTypes[blockType] {
+		return content
+	}
+
+	var encoded string
+	if err := json.Unmarshal(content, &encoded); err != nil {
+		return content
+	}
+	if !strings.HasPrefix(strings.TrimSpace(encoded), "{") || !json.Valid([]byte(encoded)) {
+		return content
+	}
+	return json.RawMessage(encoded)
+}
+
+// CustomBlockType は 管理者が登録したカスタムブロックの定義です
+// Schema でブロックの content を検証し、ExportTemplate（text/template 形式）でエクスポート時の出力を決める
+type CustomBlockType struct {
+	ID             int             `json:"id" db:"id"`
+	Name           string          `json:"name" db:"name"`
+	Description    string          `json:"description" db:"description"`
+	Schema         json.RawMessage `json:"schema" db:"schema"`
+	ExportTemplate string          `json:"exportTemplate" db:"export_template"`
+	CreatedBy      int             `json:"createdBy" db:"created_by"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updatedAt" db:"updated_at"`
+}
+
+// BlockTypeCount は ブロックタイプごとの件数です
+type BlockTypeCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNormalizeBlockContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		blockType string
+		content   string
+		want      string
+	}{
+		{
+			name:      "JSON文字列の画像 content をオブジェクトに戻す",
+			blockType: "image",
+			content:   `"{\"src\":\"/api/uploads/a.png\",\"fileKey\":\"images/1/a.png\"}"`,
+			want:      `{"src":"/api/uploads/a.png","fileKey":"images/1/a.png"}`,
+		},
+		{
+			name:      "オブジェクトの content はそのまま",
+			blockType: "image",
+			content:   `{"src":"/api/uploads/a.png"}`,
+			want:      `{"src":"/api/uploads/a.png"}`,
+		},
+		{
+			name:      "アップロード前の空文字はそのまま",
+			blockType: "image",
+			content:   `""`,
+			want:      `""`,
+		},
+		{
+			name:      "解析できない文字列はそのまま",
+			blockType: "file",
+			content:   `"{broken"`,
+			want:      `"{broken"`,
+		},
+		{
+			name:      "テキストブロックの TipTap JSON は文字列のまま",
+			blockType: "text",
+			content:   `"{\"type\":\"doc\",\"content\":[]}"`,
+			want:      `"{\"type\":\"doc\",\"content\":[]}"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeBlockContent(tt.blockType, json.RawMessage(tt.content))
+			if string(got) != tt.want {
+				t.Errorf("NormalizeBlockContent() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
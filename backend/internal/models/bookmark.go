@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// BookmarkContent は bookmark ブロックの content です
+// URL 以外はリンク先から取得したプレビュー情報で、取得前や取得に失敗した場合は空
+type BookmarkContent struct {
+	URL         string     `json:"url"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Image       string     `json:"image,omitempty"`
+	SiteName    string     `json:"siteName,omitempty"`
+	FetchedAt   *time.Time `json:"fetchedAt,omitempty"`
+}
+
+// LinkPreview は リンク先のページから取得した OpenGraph のメタデータです
+type LinkPreview struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Image       string    `json:"image,omitempty"`
+	SiteName    string    `json:"siteName,omitempty"`
+	FetchedAt   time.Time `json:"fetchedAt"`
+}
+
+// DecodeBookmarkContent は bookmark ブロックの content を解析します
+// JSON 文字列として送られてきたオブジェクトも受け付ける
+func DecodeBookmarkContent(raw json.RawMessage) (BookmarkContent, error) {
+	var bookmark BookmarkContent
+	err := json.Unmarshal(NormalizeBlockContent("bookmark", raw), &bookmark)
+	return bookmark, err
+}
+
+// ApplyPreview は 取得したプレビュー情報を content に反映します
+// URL はユーザーが入力したものを保持する
+func (b *BookmarkContent) ApplyPreview(preview *LinkPreview) {
+	fetchedAt := preview.FetchedAt
+	b.Title = preview.Title
+	b.Description = preview.Description
+	b.Image = preview.Image
+	b.SiteName = preview.SiteName
+	b.FetchedAt = &fetchedAt
+}
+
+// Label は リンクの表示名を返します（タイトルを取得できていない場合は URL）
+func (b BookmarkContent) Label() string {
+	if b.Title != "" {
+		return b.Title
+	}
+	return b.URL
+}
+
+// HasWebURL は URL が http(s) の絶対 URL かどうかを返します
+// javascript: などのスキームをリンクとして出力しないために使う
+func (b BookmarkContent) HasWebURL() bool {
+	u, err := url.Parse(b.URL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
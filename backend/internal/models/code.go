@@ -0,0 +1,22 @@
+package models
+
+import "encoding/json"
+
+// CodeContent は code ブロックの content です（Language は "go" などの言語名、未指定の場合は空）
+type CodeContent struct {
+	Language string `json:"language,omitempty"`
+	Content  string `json:"content"`
+}
+
+// DecodeCodeContent は code ブロックの content を解析します
+// 言語を持たない従来の形式（コードそのものの JSON 文字列）も Content として扱う
+func DecodeCodeContent(raw json.RawMessage) (CodeContent, error) {
+	var code CodeContent
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		code.Content = text
+		return code, nil
+	}
+	err := json.Unmarshal(raw, &code)
+	return code, err
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// DocumentRole は 文書に対するユーザーの役割です
+type DocumentRole string
+
+const (
+	DocumentRoleOwner  DocumentRole = "owner"  // 作成者。共同編集者の管理・公開・共有リンク・削除ができる
+	DocumentRoleEditor DocumentRole = "editor" // 文書とブロックの編集ができる
+	DocumentRoleViewer DocumentRole = "viewer" // 閲覧とコメントのみ
+)
+
+// ValidCollaborator は 共同編集者に設定できる役割（editor・viewer）かを返します
+func (r DocumentRole) ValidCollaborator() bool {
+	return r == DocumentRoleEditor || r == DocumentRoleViewer
+}
+
+// CanEdit は 文書とブロックを編集できるかを返します
+func (r DocumentRole) CanEdit() bool {
+	return r == DocumentRoleOwner || r == DocumentRoleEditor
+}
+
+// DocumentCollaborator は 文書にアクセスできるユーザーです（一覧では所有者を先頭に含める）
+type DocumentCollaborator struct {
+	DocumentID int          `json:"documentId" db:"document_id"`
+	UserID     int          `json:"userId" db:"user_id"`
+	Email      string       `json:"email" db:"email"`
+	Name       string       `json:"name" db:"name"`
+	Role       DocumentRole `json:"role" db:"role"`
+	AddedBy    *int         `json:"addedBy,omitempty" db:"added_by"`
+	CreatedAt  time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updatedAt" db:"updated_at"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "fmt"
+
+// MaxColumns は 1つの columnList に並べられる列の最大数です
+const MaxColumns = 5
+
+// ColumnContent は column ブロックの content です
+// Width は列幅の比率（0〜1）で、省略した場合は残りの幅を均等に分ける
+type ColumnContent struct {
+	Width float64 `json:"width,omitempty"`
+}
+
+// ValidateBlockPlacement は parentType のブロックの子として childType のブロックを置けるかを検証します
+// parentType が空の場合はトップレベルに置くものとして扱う
+// columnList の子は column のみ、column は columnList の直下のみに置け、列の中に列レイアウトは入れ子にできない
+func ValidateBlockPlacement(parentType, childType string) error {
+	switch {
+	case parentType == "columnList" && childType != "column":
+		return fmt.Errorf("columnList can only contain column blocks, got %s", childType)
+	case childType == "column" && parentType != "columnList":
+		return fmt.Errorf("column must be placed directly in a columnList")
+	case childType == "columnList" && parentType == "column":
+		return fmt.Errorf("columnList cannot be nested in a column")
+	}
+	return nil
+}
+
+// ValidateBlockLayout は ブロック一覧の親子の種類の組み合わせ（列レイアウト）を検証します
+// 親が一覧に含まれないブロックは親の種類が分からないため検証しない
+func ValidateBlockLayout(blocks []Block) error {
+	types := make(map[int]string, len(blocks))
+	for _, b := range blocks {
+		if b.ID != 0 {
+			types[b.ID] = b.Type
+		}
+	}
+
+	columns := make(map[int]int)
+	for i, b := range blocks {
+		parentType := ""
+		if b.ParentBlockID != nil {
+			t, ok := types[*b.ParentBlockID]
+			if !ok {
+				continue
+			}
+			parentType = t
+		}
+		if err := ValidateBlockPlacement(parentType, b.Type); err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
+		}
+
+		if b.Type == "column" {
+			columns[*b.ParentBlockID]++
+			if columns[*b.ParentBlockID] > MaxColumns {
+				return fmt.Errorf("block %d: columnList id=%d has more than %d columns", i, *b.ParentBlockID, MaxColumns)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import "testing"
+
+func TestValidateBlockPlacement(t *testing.T) {
+	tests := []struct {
+		parent  string
+		child   string
+		wantErr bool
+	}{
+		{parent: "", child: "columnList"},
+		{parent: "columnList", child: "column"},
+		{parent: "column", child: "text"},
+		{parent: "column", child: "toggle"},
+		{parent: "toggle", child: "columnList"},
+		{parent: "columnList", child: "text", wantErr: true},
+		{parent: "", child: "column", wantErr: true},
+		{parent: "toggle", child: "column", wantErr: true},
+		{parent: "column", child: "column", wantErr: true},
+		{parent: "column", child: "columnList", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateBlockPlacement(tt.parent, tt.child); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateBlockPlacement(%q, %q) error = %v, wantErr %v", tt.parent, tt.child, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateBlockLayout(t *testing.T) {
+	t.Run("正常系：2列のレイアウト", func(t *testing.T) {
+		blocks := []Block{
+			{ID: 1, Type: "columnList"},
+			{ID: 2, ParentBlockID: intPtr(1), Type: "column"},
+			{ID: 3, ParentBlockID: intPtr(2), Type: "text"},
+			{ID: 4, ParentBlockID: intPtr(1), Type: "column"},
+			{ID: 5, ParentBlockID: intPtr(4), Type: "image"},
+			// 親が一覧にない（差分操作で既存ブロックの子として追加する）場合は検証しない
+			{ParentBlockID: intPtr(99), Type: "column"},
+		}
+		if err := ValidateBlockLayout(blocks); err != nil {
+			t.Errorf("ValidateBlockLayout() error = %v", err)
+		}
+	})
+
+	t.Run("異常系：列の外に置いた column", func(t *testing.T) {
+		if err := ValidateBlockLayout([]Block{{ID: 1, Type: "column"}}); err == nil {
+			t.Error("expected error for top-level column")
+		}
+	})
+
+	t.Run("異常系：列が多すぎる", func(t *testing.T) {
+		blocks := []Block{{ID: 1, Type: "columnList"}}
+		for i := 0; i <= MaxColumns; i++ {
+			blocks = append(blocks, Block{ID: 10 + i, ParentBlockID: intPtr(1), Type: "column"})
+		}
+		if err := ValidateBlockLayout(blocks); err == nil {
+			t.Errorf("expected error for more than %d columns", MaxColumns)
+		}
+	})
+}
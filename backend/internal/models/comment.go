@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// MaxCommentLength は コメント本文の最大文字数です
+const MaxCommentLength = 5000
+
+// BlockComment は 文書内のブロックに付けられたコメントです
+// ResolvedAt が設定されているコメントは解決済みとして扱う
+type BlockComment struct {
+	ID         int        `json:"id" db:"id"`
+	DocumentID int        `json:"documentId" db:"document_id"`
+	BlockID    int        `json:"blockId" db:"block_id"`
+	UserID     int        `json:"userId" db:"user_id"`
+	Body       string     `json:"body" db:"body"`
+	Resolved   bool       `json:"resolved"`
+	ResolvedAt *time.Time `json:"resolvedAt" db:"resolved_at"`
+	ResolvedBy *int       `json:"resolvedBy" db:"resolved_by"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// BlockCommentFilter は コメント一覧の絞り込み条件です（nil の項目は絞り込まない）
+type BlockCommentFilter struct {
+	BlockID  *int
+	Resolved *bool
+}
+
+// BlockCommentPatch は コメントの部分更新リクエストです（nil のフィールドは変更しない）
+type BlockCommentPatch struct {
+	Body     *string `json:"body"`
+	Resolved *bool   `json:"resolved"`
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -17,6 +20,12 @@ type Document struct {
 	IsDeleted bool      `json:"isDeleted" db:"is_deleted"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	// DeletedAt は ごみ箱一覧と、削除済みを含めて取得した場合にのみ設定される
+	DeletedAt *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+	// WorkspaceID は 文書が属するワークスペースです（個人の文書は nil）。単一の文書の取得・作成・ツリーでのみ設定される
+	WorkspaceID *int `json:"workspaceId,omitempty" db:"workspace_id"`
+	// Role は 取得したユーザーの文書に対する役割です（単一の文書の取得でのみ設定される）
+	Role DocumentRole `json:"role,omitempty" db:"role"`
 }
 
 type DocumentTreeNode struct {
@@ -27,13 +36,140 @@ type DocumentTreeNode struct {
 type DocumentWithBlocks struct {
 	Document
 	Blocks []Block `json:"blocks"`
+	// ReadOnly は ごみ箱内の文書（復元するまで編集できない）、共有リンクから閲覧した文書と、閲覧のみの共同編集者の場合に true です
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// CollapsedBlockIDs は 閲覧しているユーザーが折りたたんでいるトグルブロックです
+	CollapsedBlockIDs []int `json:"collapsed_block_ids,omitempty"`
+	// BlockTree は ?blocks=tree を指定した場合のみ設定される
+	BlockTree []BlockTreeNode `json:"block_tree,omitempty"`
+	// Version は 最新のバージョン番号です（文書の取得・保存時のみ）。保存時に baseVersion として送ると同時編集をマージする
+	Version int `json:"version,omitempty"`
+	// CommentCount は 文書へのコメント数（返信を含む）です（文書の取得時のみ）
+	CommentCount int `json:"commentCount,omitempty"`
+	// SnapshotAt は 固定した内容の共有リンクから閲覧した場合に、内容を固定した日時です
+	SnapshotAt *time.Time `json:"snapshotAt,omitempty"`
 }
 
+// DocumentPatch は 文書の部分更新リクエストです（JSON Merge Patch, RFC 7396）
+// 本文に含まれないフィールドは nil で、変更しない
+// content・blocks に null を指定した場合は空にする（title は null にできない）。blocks は配列全体を置き換える
+type DocumentPatch struct {
+	Title   *string
+	Content *string
+	Blocks  *[]Block
+}
+
+// UnmarshalJSON は 含まれていないフィールドと null を区別して読み取ります
+func (p *DocumentPatch) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if fields == nil {
+		return errors.New("patch must be a JSON object")
+	}
+
+	*p = DocumentPatch{}
+	if raw, ok := fields["title"]; ok {
+		if isJSONNull(raw) {
+			return errors.New("title cannot be null")
+		}
+		var title string
+		if err := json.Unmarshal(raw, &title); err != nil {
+			return fmt.Errorf("title: %w", err)
+		}
+		p.Title = &title
+	}
+	if raw, ok := fields["content"]; ok {
+		var content string
+		if !isJSONNull(raw) {
+			if err := json.Unmarshal(raw, &content); err != nil {
+				return fmt.Errorf("content: %w", err)
+			}
+		}
+		p.Content = &content
+	}
+	if raw, ok := fields["blocks"]; ok {
+		blocks := []Block{}
+		if !isJSONNull(raw) {
+			if err := json.Unmarshal(raw, &blocks); err != nil {
+				return fmt.Errorf("blocks: %w", err)
+			}
+		}
+		p.Blocks = &blocks
+	}
+	return nil
+}
+
+// IsEmpty は 変更するフィールドがないかを返します
+func (p DocumentPatch) IsEmpty() bool {
+	return p.Title == nil && p.Content == nil && p.Blocks == nil
+}
+
+// Apply は パッチを適用した文書のコピーを返します（保存前の検証に使う）
+func (p DocumentPatch) Apply(doc DocumentWithBlocks) DocumentWithBlocks {
+	if p.Title != nil {
+		doc.Title = *p.Title
+	}
+	if p.Content != nil {
+		doc.Content = *p.Content
+	}
+	if p.Blocks != nil {
+		doc.Blocks = *p.Blocks
+	}
+	return doc
+}
+
+// isJSONNull は JSON の値が null かどうかを返します
+func isJSONNull(raw json.RawMessage) bool {
+	return string(bytes.TrimSpace(raw)) == "null"
+}
+
+// Block は 文書内のブロックです
+// ParentBlockID を持つブロックは親の中に入れ子で表示される（トグルの中身・インデントした箇条書き・カラム）
+// Position は文書全体での並び順で、同じ親を持つブロック同士の順序にも使われる
+// DB では並び順キー（SortKeyBetween）で順序を保存し、Position は読み込み時にキーの順で数えた位置を返す
 type Block struct {
-	ID         int             `json:"id" db:"id"`
-	DocumentID int             `json:"document_id" db:"document_id"`
-	Type       string          `json:"type" db:"type"`
-	Content    json.RawMessage `json:"content" db:"content"`
-	Position   int             `json:"position" db:"position"`
-	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	ID            int             `json:"id" db:"id"`
+	DocumentID    int             `json:"document_id" db:"document_id"`
+	ParentBlockID *int            `json:"parent_block_id,omitempty" db:"parent_block_id"` // nil はトップレベル
+	Type          string          `json:"type" db:"type"`
+	Content       json.RawMessage `json:"content" db:"content"`
+	Position      int             `json:"position" db:"position"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	// synced ブロックの場合の同期元の内容（読み込み時に解決する。同期元が削除されている場合は nil）
+	Synced *SyncedSource `json:"synced,omitempty" db:"-"`
+}
+
+// Duplicate は 同じ親・種類・内容を持ち、元のブロックの直後に配置する複製を返します
+// ID と作成日時は保存時に採番されるため空にする
+func (b Block) Duplicate() Block {
+	return Block{
+		DocumentID:    b.DocumentID,
+		ParentBlockID: b.ParentBlockID,
+		Type:          b.Type,
+		Content:       append(json.RawMessage(nil), b.Content...),
+		Position:      b.Position + 1,
+	}
+}
+
+// BlockPatch は 単一ブロックの部分更新リクエストです（nil のフィールドは変更しない）
+type BlockPatch struct {
+	Type     *string         `json:"type"`
+	Content  json.RawMessage `json:"content"`
+	Position *int            `json:"position"`
+}
+
+// Apply は パッチを適用したブロックのコピーを返します
+func (p BlockPatch) Apply(block Block) Block {
+	if p.Type != nil {
+		block.Type = *p.Type
+	}
+	if p.Content != nil {
+		block.Content = p.Content
+	}
+	if p.Position != nil {
+		block.Position = *p.Position
+	}
+	return block
 }
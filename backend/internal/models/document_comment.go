@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// DocumentComment は 文書全体に付けられたコメントです
+// ParentID が nil のコメントがスレッドの先頭で、返信は先頭のコメントの Replies に作成日時順で入る
+// 解決状態はスレッドの先頭のコメントのみが持つ
+type DocumentComment struct {
+	ID         int               `json:"id" db:"id"`
+	DocumentID int               `json:"documentId" db:"document_id"`
+	ParentID   *int              `json:"parentId" db:"parent_id"`
+	UserID     int               `json:"userId" db:"user_id"`
+	Body       string            `json:"body" db:"body"`
+	Resolved   bool              `json:"resolved"`
+	ResolvedAt *time.Time        `json:"resolvedAt" db:"resolved_at"`
+	ResolvedBy *int              `json:"resolvedBy" db:"resolved_by"`
+	CreatedAt  time.Time         `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time         `json:"updatedAt" db:"updated_at"`
+	Replies    []DocumentComment `json:"replies,omitempty"`
+}
+
+// DocumentCommentPatch は 文書へのコメントの部分更新リクエストです（nil のフィールドは変更しない）
+type DocumentCommentPatch struct {
+	Body     *string `json:"body"`
+	Resolved *bool   `json:"resolved"`
+}
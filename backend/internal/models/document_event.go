@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// 文書の変更イベントの種類
+const (
+	DocumentEventCreated  = "document.created"
+	DocumentEventUpdated  = "document.updated" // タイトル・本文・ブロックの変更
+	DocumentEventRenamed  = "document.renamed" // タイトルの変更（document.updated に加えて送る）
+	DocumentEventDeleted  = "document.deleted" // ごみ箱への移動と完全削除（Permanent で区別する）
+	DocumentEventRestored = "document.restored"
+	DocumentEventMoved    = "document.moved"
+	DocumentEventPresence = "presence.updated" // 文書を開いているユーザーの変化
+	DocumentEventAccess   = "access.updated"   // 共同編集者の追加・役割の変更・削除
+	DocumentEventCursor   = "cursor.updated"   // 文書を開いているタブのカーソル・選択範囲の移動（保存しない）
+)
+
+// DocumentEvent は 同じユーザーの開いているタブに通知する文書の変更です
+// タブは自分が開いている文書の変更を受け取ったら、保存する前に最新の内容を取得し直す
+type DocumentEvent struct {
+	Type       string    `json:"type"`
+	DocumentID int       `json:"documentId"`
+	ParentID   *int      `json:"parentId,omitempty"` // 作成・移動・復元した文書の親（ルートの場合は省略）
+	Permanent  bool      `json:"permanent,omitempty"`
+	ClientID   string    `json:"clientId,omitempty"` // 変更したタブ（X-Client-ID ヘッダー）。自分の変更を無視するために使う
+	OccurredAt time.Time `json:"occurredAt"`
+
+	// Node は サイドバーの文書ツリーに反映する文書です（作成・名前の変更・移動・復元のみ。復元の場合は一緒に戻った子孫を含む）
+	// 文書の WorkspaceID で、表示しているツリーの文書かを判定する。取得できなかった場合は省略し、タブはツリーを取得し直す
+	Node *DocumentTreeNode `json:"node,omitempty"`
+
+	// Viewers は 文書を開いているユーザーです（presence.updated のみ）
+	Viewers []DocumentViewer `json:"viewers,omitempty"`
+
+	// Cursor は 移動したタブのカーソル・選択範囲です（cursor.updated のみ）
+	Cursor *DocumentCursor `json:"cursor,omitempty"`
+
+	// Role は 受け取ったユーザーの変更後の役割です（access.updated のみ。アクセスできなくなった場合は省略し Revoked を true にする）
+	Role    DocumentRole `json:"role,omitempty"`
+	Revoked bool         `json:"revoked,omitempty"`
+}
+
+// DocumentViewer は 文書を開いているユーザーです（同じユーザーの複数のタブは1人にまとめる）
+type DocumentViewer struct {
+	UserID     int       `json:"userId"`
+	Name       string    `json:"name"`
+	Tabs       int       `json:"tabs"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+// DocumentCursorPosition は ブロック内のカーソルの位置です（Offset はブロックのテキストの先頭からの文字数）
+type DocumentCursorPosition struct {
+	BlockID int `json:"blockId"`
+	Offset  int `json:"offset"`
+}
+
+// DocumentCursor は 文書を開いているタブのカーソル・選択範囲です
+// Anchor は選択を始めた位置、Focus はカーソルのある位置で、同じ場合は範囲を選択していない
+// タブが文書から離れた（閉じた・カーソルを外した）場合は Anchor と Focus を省略する
+type DocumentCursor struct {
+	UserID   int                     `json:"userId"`
+	Name     string                  `json:"name"`
+	ClientID string                  `json:"clientId"`
+	Anchor   *DocumentCursorPosition `json:"anchor,omitempty"`
+	Focus    *DocumentCursorPosition `json:"focus,omitempty"`
+}
+
+// DocumentPresence は 文書を開いているユーザーの一覧です
+type DocumentPresence struct {
+	DocumentID int              `json:"documentId"`
+	Viewers    []DocumentViewer `json:"viewers"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// DocumentSnapshot は 共有リンクで公開するために、作成時点の内容を固定した文書です
+// 画像・ファイルブロックは複製したファイル（FileIDs）を参照する
+type DocumentSnapshot struct {
+	ID         int       `json:"id" db:"id"`
+	DocumentID int       `json:"documentId" db:"document_id"`
+	Title      string    `json:"title" db:"title"`
+	Content    string    `json:"content" db:"content"`
+	Blocks     []Block   `json:"blocks" db:"blocks"`
+	FileIDs    []int     `json:"fileIds" db:"file_ids"`
+	CreatedBy  *int      `json:"createdBy" db:"created_by"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
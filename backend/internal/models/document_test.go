@@ -252,3 +252,107 @@ func TestDocumentWithBlocks(t *testing.T) {
 		}
 	})
 }
+
+func TestBlockDuplicate(t *testing.T) {
+	parentID := 3
+	source := Block{
+		ID:            7,
+		DocumentID:    1,
+		ParentBlockID: &parentID,
+		Type:          "text",
+		Content:       json.RawMessage(`"hello"`),
+		Position:      4,
+		CreatedAt:     time.Now(),
+	}
+
+	dup := source.Duplicate()
+	if dup.ID != 0 || !dup.CreatedAt.IsZero() {
+		t.Errorf("Duplicate() should reset ID and CreatedAt, got id=%d createdAt=%v", dup.ID, dup.CreatedAt)
+	}
+	if dup.Position != 5 || dup.DocumentID != 1 || dup.Type != "text" || !equalIntPtr(dup.ParentBlockID, &parentID) {
+		t.Errorf("Duplicate() = %+v", dup)
+	}
+
+	// 内容は元のブロックと共有しない
+	dup.Content[1] = 'j'
+	if string(source.Content) != `"hello"` {
+		t.Errorf("source content changed to %s", source.Content)
+	}
+}
+
+func TestDocumentPatch(t *testing.T) {
+	current := DocumentWithBlocks{
+		Document: Document{ID: 1, Title: "元のタイトル", Content: "元の内容"},
+		Blocks:   []Block{{ID: 1, Type: "text", Content: json.RawMessage(`"a"`)}},
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		wantErr     bool
+		wantEmpty   bool
+		wantTitle   string
+		wantContent string
+		wantBlocks  int
+	}{
+		{
+			name:        "正常系：含めたフィールドのみ変更する",
+			body:        `{"title":"新しいタイトル"}`,
+			wantTitle:   "新しいタイトル",
+			wantContent: "元の内容",
+			wantBlocks:  1,
+		},
+		{
+			name:        "正常系：blocks は配列全体を置き換える",
+			body:        `{"blocks":[{"type":"text","content":"\"b\""},{"type":"text","content":"\"c\""}]}`,
+			wantTitle:   "元のタイトル",
+			wantContent: "元の内容",
+			wantBlocks:  2,
+		},
+		{
+			name:        "正常系：null の content・blocks は空にする",
+			body:        `{"content":null,"blocks":null}`,
+			wantTitle:   "元のタイトル",
+			wantContent: "",
+			wantBlocks:  0,
+		},
+		{
+			name:        "正常系：空のパッチは何も変更しない",
+			body:        `{}`,
+			wantEmpty:   true,
+			wantTitle:   "元のタイトル",
+			wantContent: "元の内容",
+			wantBlocks:  1,
+		},
+		{name: "異常系：title は null にできない", body: `{"title":null}`, wantErr: true},
+		{name: "異常系：型が不正", body: `{"content":1}`, wantErr: true},
+		{name: "異常系：オブジェクトでない", body: `null`, wantErr: true},
+		{name: "異常系：配列", body: `[]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch DocumentPatch
+			err := json.Unmarshal([]byte(tt.body), &patch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if patch.IsEmpty() != tt.wantEmpty {
+				t.Errorf("IsEmpty() = %v, want %v", patch.IsEmpty(), tt.wantEmpty)
+			}
+
+			got := patch.Apply(current)
+			if got.Title != tt.wantTitle || got.Content != tt.wantContent || len(got.Blocks) != tt.wantBlocks {
+				t.Errorf("Apply() = {%q, %q, %d blocks}, want {%q, %q, %d blocks}",
+					got.Title, got.Content, len(got.Blocks), tt.wantTitle, tt.wantContent, tt.wantBlocks)
+			}
+		})
+	}
+
+	if current.Title != "元のタイトル" || len(current.Blocks) != 1 {
+		t.Errorf("Apply() modified the original document: %+v", current)
+	}
+}
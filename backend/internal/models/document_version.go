@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DocumentVersion は 文書保存時点のスナップショットを表します
+type DocumentVersion struct {
+	ID            int       `json:"id" db:"id"`
+	DocumentID    int       `json:"documentId" db:"document_id"`
+	VersionNumber int       `json:"versionNumber" db:"version_number"`
+	Title         string    `json:"title" db:"title"`
+	Content       string    `json:"content,omitempty" db:"content"`
+	Blocks        []Block   `json:"blocks,omitempty" db:"blocks"`
+	CreatedBy     *int      `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+}
+
+// BlockChangeType は ブロック差分の種類です
+type BlockChangeType string
+
+const (
+	BlockAdded   BlockChangeType = "added"
+	BlockRemoved BlockChangeType = "removed"
+	BlockChanged BlockChangeType = "changed"
+)
+
+// BlockChange は 2つのバージョン間における1ブロック分の差分です
+// added は After のみ、removed は Before のみ、changed は両方を持ちます
+type BlockChange struct {
+	Change BlockChangeType `json:"change"`
+	Before *BlockSnapshot  `json:"before,omitempty"`
+	After  *BlockSnapshot  `json:"after,omitempty"`
+}
+
+// BlockSnapshot は 差分表示に必要なブロック情報のみを保持します
+type BlockSnapshot struct {
+	Type     string          `json:"type"`
+	Content  json.RawMessage `json:"content"`
+	Position int             `json:"position"`
+}
+
+// DocumentDiff は 2つのバージョン間の構造化された差分です
+// ToVersion が nil の場合は現在の文書との比較を表します
+type DocumentDiff struct {
+	DocumentID   int           `json:"documentId"`
+	FromVersion  int           `json:"fromVersion"`
+	ToVersion    *int          `json:"toVersion"`
+	TitleChanged bool          `json:"titleChanged"`
+	FromTitle    string        `json:"fromTitle"`
+	ToTitle      string        `json:"toTitle"`
+	Changes      []BlockChange `json:"changes"`
+	Summary      DiffSummary   `json:"summary"`
+}
+
+// DiffSummary は 差分種類ごとの件数です
+type DiffSummary struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// DocumentMerge は 基準バージョン（編集を始めた時点）以降の他の保存と、送信された内容を3方向マージした結果です
+type DocumentMerge struct {
+	BaseVersion int `json:"baseVersion"`
+	// Merged は 基準バージョン以降に他の変更があり、その変更を取り込んだ場合に true です
+	Merged    bool            `json:"merged"`
+	Conflicts []MergeConflict `json:"conflicts"`
+}
+
+// MergeConflict は 双方が同じ箇所を変更した競合です
+// 送信された内容を優先し、ブロックの場合は他の変更で追加・変更されたブロックもその直後に残す
+type MergeConflict struct {
+	Field string `json:"field"` // title / content / blocks
+	// Position は blocks の場合の、マージ後の一覧で競合した区間の先頭の位置です
+	Position *int `json:"position,omitempty"`
+}
+
+// MergedDocument は 3方向マージ後に保存する文書の内容です
+type MergedDocument struct {
+	Title   string
+	Content string
+	Blocks  []Block
+	Merge   DocumentMerge
+	// Current は マージで他の保存の内容として使った、マージ時点の文書です（保存時に変わっていないことを確認する）
+	Current DocumentContent
+}
+
+// DocumentContent は 文書のタイトル・本文・ブロックです
+type DocumentContent struct {
+	Title   string
+	Content string
+	Blocks  []Block
+}
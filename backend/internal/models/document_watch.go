@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// WatchDelivery は 購読している文書の変更の知らせ方です
+type WatchDelivery string
+
+const (
+	WatchDeliveryInApp       WatchDelivery = "in_app"       // 変更ごとにアプリ内通知（未読の通知がある間は追加しない）
+	WatchDeliveryEmailDigest WatchDelivery = "email_digest" // 変更をまとめて定期的にメールで送る
+)
+
+// Valid は 知らせ方が既知の値かを返します
+func (d WatchDelivery) Valid() bool {
+	return d == WatchDeliveryInApp || d == WatchDeliveryEmailDigest
+}
+
+// DocumentWatch は ユーザーが購読している文書です
+type DocumentWatch struct {
+	DocumentID    int           `json:"documentId" db:"document_id"`
+	UserID        int           `json:"userId" db:"user_id"`
+	DocumentTitle string        `json:"documentTitle" db:"title"`
+	Delivery      WatchDelivery `json:"delivery" db:"delivery"`
+	CreatedAt     time.Time     `json:"createdAt" db:"created_at"`
+}
+
+// DocumentWatcher は 文書の変更を知らせる購読者です（変更したユーザーと、文書にアクセスできないユーザーを除く）
+type DocumentWatcher struct {
+	UserID        int
+	DocumentTitle string
+	Delivery      WatchDelivery
+	HasUnread     bool // この文書の変更の未読のアプリ内通知がある
+}
+
+// WatchDigestEntry は 変更をまとめたメールに含める文書です
+type WatchDigestEntry struct {
+	UserID        int
+	Email         string
+	Name          string
+	DocumentID    int
+	DocumentTitle string
+	ChangedAt     time.Time
+}
@@ -0,0 +1,48 @@
+package models
+
+import "encoding/json"
+
+// EmbedIframe は 埋め込みを iframe で表示するためのパラメータです
+// Src は許可したプロバイダーのホストのみで、Sandbox はサーバーが決めた値を使う
+type EmbedIframe struct {
+	Src     string `json:"src"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Allow   string `json:"allow,omitempty"`
+	Sandbox string `json:"sandbox"`
+}
+
+// EmbedContent は embed ブロックの content です
+// URL 以外は oEmbed の解決結果で、HTML はサーバーでサニタイズ済みのもののみ保存できる
+type EmbedContent struct {
+	URL          string       `json:"url"`
+	Provider     string       `json:"provider,omitempty"`
+	Type         string       `json:"type,omitempty"` // oEmbed の type（video / rich / photo / link）
+	Title        string       `json:"title,omitempty"`
+	HTML         string       `json:"html,omitempty"`
+	Iframe       *EmbedIframe `json:"iframe,omitempty"`
+	ThumbnailURL string       `json:"thumbnailUrl,omitempty"`
+	Width        int          `json:"width,omitempty"`
+	Height       int          `json:"height,omitempty"`
+}
+
+// DecodeEmbedContent は embed ブロックの content を解析します
+// JSON 文字列として送られてきたオブジェクトも受け付ける
+func DecodeEmbedContent(raw json.RawMessage) (EmbedContent, error) {
+	var embed EmbedContent
+	err := json.Unmarshal(NormalizeBlockContent("embed", raw), &embed)
+	return embed, err
+}
+
+// Label は リンクの表示名を返します（タイトルがない場合は URL）
+func (e EmbedContent) Label() string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return e.URL
+}
+
+// HasWebURL は URL が http(s) の絶対 URL かどうかを返します
+func (e EmbedContent) HasWebURL() bool {
+	return BookmarkContent{URL: e.URL}.HasWebURL()
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ExpirationAction は 有効期限到達時の動作です
+type ExpirationAction string
+
+const (
+	ExpirationActionTrash  ExpirationAction = "trash"  // ごみ箱へ移動
+	ExpirationActionDelete ExpirationAction = "delete" // 完全削除
+)
+
+// IsValid は 定義済みの動作かどうかを返します
+func (a ExpirationAction) IsValid() bool {
+	return a == ExpirationActionTrash || a == ExpirationActionDelete
+}
+
+// DocumentExpiration は 文書の有効期限設定です
+type DocumentExpiration struct {
+	DocumentID int              `json:"documentId" db:"document_id"`
+	ExpiresAt  time.Time        `json:"expiresAt" db:"expires_at"`
+	Action     ExpirationAction `json:"action" db:"action"`
+	WarnedAt   *time.Time       `json:"warnedAt" db:"warned_at"`
+	CreatedAt  time.Time        `json:"createdAt" db:"created_at"`
+}
+
+// ExpiringDocument は スケジューラーが処理する期限付き文書です（所有者とタイトルを含む）
+type ExpiringDocument struct {
+	DocumentID int
+	UserID     int
+	Title      string
+	ExpiresAt  time.Time
+	Action     ExpirationAction
+}
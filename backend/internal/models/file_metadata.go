@@ -2,7 +2,10 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
+
+	"simple-notion-backend/internal/pagination"
 )
 
 // FileMetadata は MinIO/S3 に保存されたファイルのメタデータを表します
@@ -18,18 +21,56 @@ type FileMetadata struct {
 	OriginalName string `json:"originalName"`
 	FileSize     int64  `json:"fileSize"`
 	MimeType     string `json:"mimeType"`
-	FileType     string `json:"fileType"` // "image" or "file"
+	FileType     string `json:"fileType"` // "image", "file", "video" or "audio"
+	AltText      string `json:"altText,omitempty"`
+	Description  string `json:"description,omitempty"`
 
 	Width  *int `json:"width,omitempty"`
 	Height *int `json:"height,omitempty"`
 
+	// 動画・音声の再生時間（ミリ秒。取得できなかった場合は nil）
+	DurationMs *int64 `json:"durationMs,omitempty"`
+
 	UploadedAt time.Time  `json:"uploadedAt"`
 	Status     string     `json:"status"` // "active", "deleted", "orphaned"
 	DeletedAt  *time.Time `json:"deletedAt,omitempty"`
 
+	// サムネイル（画像のみ。サイズ名 → ストレージのキー）
+	Thumbnails      map[string]string `json:"thumbnails,omitempty"`
+	ThumbnailStatus string            `json:"thumbnailStatus,omitempty"` // "none", "pending", "processing", "ready", "failed"
+
+	// ファイルの内容の SHA-256（16進数）。同じユーザーの同じ内容のファイルは FileKey のオブジェクトを共有する
+	ContentHash string `json:"contentHash,omitempty"`
+
+	// 保存している画像の形式（"original" または "webp"）
+	// WebPFileKey が空でない場合は元の画像を残し、WebP を別のオブジェクトとして保存している
+	Variant     string `json:"variant,omitempty"`
+	WebPFileKey string `json:"webpFileKey,omitempty"`
+
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// サムネイルの作成状況
+const (
+	ThumbnailStatusNone       = "none"       // サムネイルを作成しない（画像以外）
+	ThumbnailStatusPending    = "pending"    // 作成待ち
+	ThumbnailStatusProcessing = "processing" // 作成中
+	ThumbnailStatusReady      = "ready"      // 作成済み
+	ThumbnailStatusFailed     = "failed"     // 作成に失敗（元画像を配信する）
+)
+
+// 保存している画像の形式
+const (
+	FileVariantOriginal = "original" // 変換していない
+	FileVariantWebP     = "webp"     // WebP に変換済み
+)
+
+// ThumbnailSizes は サムネイルのサイズ名と長辺の最大ピクセル数です
+var ThumbnailSizes = map[string]int{
+	"thumb":  200,
+	"medium": 800,
+}
+
 // FileMetadataRow は データベースから取得した生のデータを表します
 type FileMetadataRow struct {
 	ID           int
@@ -47,6 +88,19 @@ type FileMetadataRow struct {
 	UploadedAt   time.Time
 	Status       string
 	DeletedAt    sql.NullTime
+
+	Thumbnails      []byte
+	ThumbnailStatus string
+
+	ContentHash sql.NullString
+
+	AltText     string
+	Description string
+
+	Variant     string
+	WebPFileKey sql.NullString
+
+	DurationMs sql.NullInt64
 }
 
 // ToFileMetadata は FileMetadataRow を FileMetadata に変換します
@@ -84,13 +138,35 @@ func (r *FileMetadataRow) ToFileMetadata() *FileMetadata {
 		fm.Height = &height
 	}
 
+	if r.DurationMs.Valid {
+		durationMs := r.DurationMs.Int64
+		fm.DurationMs = &durationMs
+	}
+
 	if r.DeletedAt.Valid {
 		fm.DeletedAt = &r.DeletedAt.Time
 	}
 
+	if len(r.Thumbnails) > 0 {
+		_ = json.Unmarshal(r.Thumbnails, &fm.Thumbnails)
+	}
+	fm.ThumbnailStatus = r.ThumbnailStatus
+	fm.ContentHash = r.ContentHash.String
+	fm.AltText = r.AltText
+	fm.Description = r.Description
+	fm.Variant = r.Variant
+	fm.WebPFileKey = r.WebPFileKey.String
+
 	return fm
 }
 
+// FileDetailsUpdate は ファイルの名前・代替テキスト・説明の変更内容です（nil のフィールドは変更しない）
+type FileDetailsUpdate struct {
+	OriginalName *string `json:"originalName"`
+	AltText      *string `json:"altText"`
+	Description  *string `json:"description"`
+}
+
 // UserStorageUsage は ユーザーのストレージ使用量を表します
 type UserStorageUsage struct {
 	UserID     int     `json:"userId"`
@@ -98,3 +174,135 @@ type UserStorageUsage struct {
 	TotalBytes int64   `json:"totalBytes"`
 	TotalMB    float64 `json:"totalMb"`
 }
+
+// UserStorageQuota は ユーザーのストレージクォータと使用量を表します
+type UserStorageQuota struct {
+	UserID     int   `json:"userId"`
+	QuotaBytes int64 `json:"quotaBytes"`
+	IsDefault  bool  `json:"isDefault"` // 個別に設定されておらず、全体の既定値を使っている
+	UsedBytes  int64 `json:"usedBytes"`
+}
+
+// StorageQuotaUpdate は ユーザーのストレージクォータの変更内容です（quotaBytes が null の場合は既定値に戻す）
+type StorageQuotaUpdate struct {
+	QuotaBytes *int64 `json:"quotaBytes"`
+}
+
+// StorageUsageReport は インスタンス全体のストレージ使用量です（管理者向け、容量の見積もりに使う）
+type StorageUsageReport struct {
+	TotalBytes int64 `json:"totalBytes"` // 使用中（ユーザーの使用量に数える）ファイルの合計サイズ
+	FileCount  int   `json:"fileCount"`
+	UserCount  int   `json:"userCount"` // ファイルを持っているユーザー数
+
+	// ストレージに残っているが使用量に数えないファイル
+	OrphanedBytes     int64 `json:"orphanedBytes"`     // 文書から外れたファイル
+	PendingPurgeCount int   `json:"pendingPurgeCount"` // 削除済みで完全削除を待っているファイル
+	PendingPurgeBytes int64 `json:"pendingPurgeBytes"`
+	VersionCount      int   `json:"versionCount"` // 新しい版で置き換えた以前の版
+	VersionBytes      int64 `json:"versionBytes"`
+
+	QuotaViolationCount int `json:"quotaViolationCount"` // 使用量がクォータを超えているユーザー数
+
+	ByType          []FileTypeUsage    `json:"byType"`
+	TopConsumers    []UserStorageEntry `json:"topConsumers"`    // 使用量の多い順
+	QuotaViolations []UserStorageEntry `json:"quotaViolations"` // クォータを超えている量の多い順
+	GeneratedAt     time.Time          `json:"generatedAt"`
+}
+
+// FileTypeUsage は ファイルの種類ごとの使用量です
+type FileTypeUsage struct {
+	FileType   string `json:"fileType"`
+	FileCount  int    `json:"fileCount"`
+	TotalBytes int64  `json:"totalBytes"`
+}
+
+// UserStorageEntry は ストレージ使用量の集計に含めるユーザーごとの使用量とクォータです
+type UserStorageEntry struct {
+	UserID     int    `json:"userId"`
+	Email      string `json:"email"`
+	FileCount  int    `json:"fileCount"`
+	UsedBytes  int64  `json:"usedBytes"`
+	QuotaBytes int64  `json:"quotaBytes"`
+	IsDefault  bool   `json:"isDefault"` // 個別に設定されておらず、全体の既定値を使っている
+}
+
+// FileListOptions は ファイル一覧の絞り込み・並び順・ページングの条件です（空のフィールドは絞り込まない）
+type FileListOptions struct {
+	FileType     string     // "image", "file", "video" or "audio"
+	MimeType     string     // 完全一致（"image/png"）または種類の前方一致（"image/*"）
+	UploadedFrom *time.Time // この日時以降にアップロードしたファイル
+	UploadedTo   *time.Time // この日時より前にアップロードしたファイル
+	DocumentID   *int       // 添付先の文書
+
+	Sort  string // "uploadedAt", "name" or "size"
+	Order string // "asc" or "desc"
+
+	Limit int
+	After *pagination.Cursor // 前のページの nextCursor（nil の場合は先頭のページ）
+}
+
+// FileList は ファイル一覧の1ページ分です（total は条件に一致する全件数）
+type FileList struct {
+	pagination.Page[*FileMetadata]
+	Total int `json:"total"`
+}
+
+// FileDuplicateMode は 画像・ファイル・音声ブロックを複製するときの元ファイルの扱いです
+type FileDuplicateMode string
+
+const (
+	// FileDuplicateReference は 複製元と同じファイルを参照します（ストレージ容量を消費しない）
+	FileDuplicateReference FileDuplicateMode = "reference"
+	// FileDuplicateCopy は 複製したブロック専用のファイルメタデータを作成します
+	// ストレージ上のオブジェクトは複製元と共有し、参照数で管理する（どちらを削除してももう一方は残る）
+	FileDuplicateCopy FileDuplicateMode = "copy"
+	// FileDuplicateRelink は 元のファイルの紐付け先（文書・ブロック）を複製したブロックに付け替えます
+	// 切り取って別の文書に貼り付けるなど、元のブロックを削除する場合に使う
+	FileDuplicateRelink FileDuplicateMode = "relink"
+)
+
+// Valid は 定義済みのモードかどうかを返します
+func (m FileDuplicateMode) Valid() bool {
+	return m == FileDuplicateReference || m == FileDuplicateCopy || m == FileDuplicateRelink
+}
+
+// MediaFileID は 画像・ファイル・音声ブロックの content からアップロード済みファイルのIDを取り出します
+// 外部URLを直接指定したブロックなど、fileId を持たない場合は false を返す
+func MediaFileID(blockType string, content json.RawMessage) (int, bool) {
+	if blockType != "image" && blockType != "file" && blockType != "audio" {
+		return 0, false
+	}
+
+	var media struct {
+		FileID *int `json:"fileId"`
+	}
+	if err := json.Unmarshal(NormalizeBlockContent(blockType, content), &media); err != nil || media.FileID == nil {
+		return 0, false
+	}
+	return *media.FileID, true
+}
+
+// WithFileReference は content のファイル参照（fileId / fileKey / bucketName / src）を file に差し替えた content を返します
+// 代替テキストやキャプションなど、その他のフィールドはそのまま残す
+func WithFileReference(blockType string, content json.RawMessage, file *FileMetadata, src string) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(NormalizeBlockContent(blockType, content), &fields); err != nil {
+		return nil, err
+	}
+
+	replacements := map[string]interface{}{
+		"fileId":     file.ID,
+		"fileKey":    file.FileKey,
+		"bucketName": file.BucketName,
+		"src":        src,
+	}
+	for key, value := range replacements {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = encoded
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMediaFileID(t *testing.T) {
+	tests := []struct {
+		name      string
+		blockType string
+		content   string
+		wantID    int
+		wantOK    bool
+	}{
+		{name: "画像ブロック", blockType: "image", content: `{"src":"/api/uploads/a.png","fileId":12}`, wantID: 12, wantOK: true},
+		{name: "JSON 文字列の content", blockType: "file", content: `"{\"src\":\"/api/uploads/a.pdf\",\"fileId\":3}"`, wantID: 3, wantOK: true},
+		{name: "外部 URL の画像", blockType: "image", content: `{"src":"https://example.com/a.png"}`},
+		{name: "画像・ファイル以外", blockType: "bookmark", content: `{"url":"https://example.com","fileId":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := MediaFileID(tt.blockType, json.RawMessage(tt.content))
+			if id != tt.wantID || ok != tt.wantOK {
+				t.Errorf("MediaFileID() = (%d, %v), want (%d, %v)", id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWithFileReference(t *testing.T) {
+	content := json.RawMessage(`{"src":"/api/uploads/old.png","alt":"図1","fileId":1,"fileKey":"images/1/old.png","bucketName":"old"}`)
+	file := &FileMetadata{ID: 2, FileKey: "images/1/new.png", BucketName: "uploads"}
+
+	got, err := WithFileReference("image", content, file, "/api/uploads/new.png")
+	if err != nil {
+		t.Fatalf("WithFileReference() error = %v", err)
+	}
+
+	var media struct {
+		Src        string `json:"src"`
+		Alt        string `json:"alt"`
+		FileID     int    `json:"fileId"`
+		FileKey    string `json:"fileKey"`
+		BucketName string `json:"bucketName"`
+	}
+	if err := json.Unmarshal(got, &media); err != nil {
+		t.Fatalf("failed to decode content: %v", err)
+	}
+	if media.Src != "/api/uploads/new.png" || media.FileID != 2 || media.FileKey != "images/1/new.png" || media.BucketName != "uploads" {
+		t.Errorf("WithFileReference() = %s", got)
+	}
+	if media.Alt != "図1" {
+		t.Errorf("alt = %q, want it to be kept", media.Alt)
+	}
+}
+
+func TestFileDuplicateModeValid(t *testing.T) {
+	for mode, want := range map[FileDuplicateMode]bool{
+		FileDuplicateReference: true,
+		FileDuplicateCopy:      true,
+		FileDuplicateRelink:    true,
+		"move":                 false,
+		"":                     false,
+	} {
+		if got := mode.Valid(); got != want {
+			t.Errorf("FileDuplicateMode(%q).Valid() = %v, want %v", mode, got, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// FileVersion は 新しい版で置き換えたファイルの以前の版です
+// 版のオブジェクトは保持期間が経過するまでストレージに残し、その間は復元できる
+type FileVersion struct {
+	ID            int    `json:"id"`
+	FileID        int    `json:"fileId"`
+	VersionNumber int    `json:"versionNumber"`
+	FileKey       string `json:"-"`
+
+	OriginalName string `json:"originalName"`
+	FileSize     int64  `json:"fileSize"`
+	MimeType     string `json:"mimeType"`
+	ContentHash  string `json:"contentHash,omitempty"`
+	Width        *int   `json:"width,omitempty"`
+	Height       *int   `json:"height,omitempty"`
+	DurationMs   *int64 `json:"durationMs,omitempty"`
+
+	// 版のオブジェクトから作成したサムネイルと WebP（復元時に戻す）
+	Thumbnails  map[string]string `json:"-"`
+	Variant     string            `json:"-"`
+	WebPFileKey string            `json:"-"`
+
+	UploadedAt time.Time `json:"uploadedAt"` // この版をアップロードした日時
+	ReplacedAt time.Time `json:"replacedAt"` // この版を新しい版で置き換えた日時
+}
+
+// FileVersionList は ファイルの現在の版と以前の版の一覧です（以前の版は新しい順）
+type FileVersionList struct {
+	FileID         int            `json:"fileId"`
+	CurrentVersion int            `json:"currentVersion"`
+	Versions       []*FileVersion `json:"versions"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// OrphanedBlock は 所属する文書が存在しないブロックです
+type OrphanedBlock struct {
+	ID         int       `json:"id" db:"id"`
+	DocumentID *int      `json:"documentId" db:"document_id"`
+	Type       string    `json:"type" db:"type"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
+
+// DanglingFileReference は 存在しないブロックを参照しているファイルメタデータです
+type DanglingFileReference struct {
+	FileID     int       `json:"fileId" db:"id"`
+	UserID     int       `json:"userId" db:"user_id"`
+	BlockID    int       `json:"blockId" db:"block_id"`
+	FileKey    string    `json:"fileKey" db:"file_key"`
+	UploadedAt time.Time `json:"uploadedAt" db:"uploaded_at"`
+}
+
+// OrphanReport は 孤立データの検出結果です（一覧は先頭から最大 limit 件）
+type OrphanReport struct {
+	OrphanedBlockCount int                     `json:"orphanedBlockCount"`
+	OrphanedBlocks     []OrphanedBlock         `json:"orphanedBlocks"`
+	DanglingFileCount  int                     `json:"danglingFileCount"`
+	DanglingFiles      []DanglingFileReference `json:"danglingFiles"`
+	ScannedAt          time.Time               `json:"scannedAt"`
+}
+
+// DanglingFileAction は 存在しないブロックを参照するファイルの修復方法です
+type DanglingFileAction string
+
+const (
+	DanglingFileNone   DanglingFileAction = ""       // 何もしない
+	DanglingFileDetach DanglingFileAction = "detach" // ブロック参照を外して orphaned にする
+	DanglingFileDelete DanglingFileAction = "delete" // deleted にする（ストレージ上の実体は別途削除）
+)
+
+// OrphanRepairOptions は 孤立データの修復内容です
+type OrphanRepairOptions struct {
+	DeleteBlocks bool               `json:"deleteBlocks"`
+	FileAction   DanglingFileAction `json:"fileAction"`
+}
+
+// OrphanRepairResult は 修復した件数です
+type OrphanRepairResult struct {
+	DeletedBlocks int64 `json:"deletedBlocks"`
+	RepairedFiles int64 `json:"repairedFiles"`
+}
+
+// UnreferencedObject は どのメタデータからも参照されていないストレージ上のオブジェクトです
+type UnreferencedObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// FileObjectReference は ファイルメタデータが参照しているオブジェクトです
+type FileObjectReference struct {
+	FileID     int       `json:"fileId" db:"id"`
+	UserID     int       `json:"userId" db:"user_id"`
+	FileKey    string    `json:"fileKey" db:"file_key"`
+	Status     string    `json:"status" db:"status"`
+	UploadedAt time.Time `json:"uploadedAt" db:"uploaded_at"`
+}
+
+// StorageConsistencyReport は ストレージとデータベースの突き合わせ結果です（一覧は先頭から最大 limit 件）
+type StorageConsistencyReport struct {
+	ObjectCount             int                   `json:"objectCount"`
+	UnreferencedObjectCount int                   `json:"unreferencedObjectCount"`
+	UnreferencedObjectSize  int64                 `json:"unreferencedObjectSize"`
+	UnreferencedObjects     []UnreferencedObject  `json:"unreferencedObjects"`
+	MissingObjectCount      int                   `json:"missingObjectCount"`
+	MissingObjects          []FileObjectReference `json:"missingObjects"`
+	ScannedAt               time.Time             `json:"scannedAt"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "encoding/json"
+
+// MathContent は math ブロックの content です（LaTeX の数式をブロックとして表示する）
+type MathContent struct {
+	LaTeX string `json:"latex"`
+}
+
+// DecodeMathContent は math ブロックの content を解析します
+// JSON 文字列として送られてきたオブジェクトも受け付ける
+func DecodeMathContent(raw json.RawMessage) (MathContent, error) {
+	var math MathContent
+	err := json.Unmarshal(NormalizeBlockContent("math", raw), &math)
+	return math, err
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// MentionSourceType は メンションの書き込み元の種類です
+type MentionSourceType string
+
+const (
+	MentionSourceDocument        MentionSourceType = "document"         // 文書の本文・ブロック（SourceID は文書ID）
+	MentionSourceBlockComment    MentionSourceType = "block_comment"    // ブロックへのコメント
+	MentionSourceDocumentComment MentionSourceType = "document_comment" // 文書へのコメント（スレッド）
+)
+
+// Mention は 文書・コメント内の @メンション です
+// 書き込み元（SourceType / SourceID）とユーザーの組ごとに1件だけ記録する
+type Mention struct {
+	ID          int               `json:"id" db:"id"`
+	DocumentID  int               `json:"documentId" db:"document_id"`
+	SourceType  MentionSourceType `json:"sourceType" db:"source_type"`
+	SourceID    int               `json:"sourceId" db:"source_id"`
+	BlockID     *int              `json:"blockId" db:"block_id"`
+	UserID      int               `json:"userId" db:"user_id"`
+	MentionedBy int               `json:"mentionedBy" db:"mentioned_by"`
+	CreatedAt   time.Time         `json:"createdAt" db:"created_at"`
+}
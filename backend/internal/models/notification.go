@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// NotificationType は 通知の種類です
+type NotificationType string
+
+const (
+	NotificationExpiryWarning NotificationType = "expiry_warning" // 有効期限が近づいている
+	NotificationExpired       NotificationType = "expired"        // 有効期限により削除された
+	NotificationReminder      NotificationType = "reminder"       // リマインダーの日時になった
+	NotificationMention       NotificationType = "mention"        // 文書・コメントでメンションされた
+	NotificationWatch         NotificationType = "watch"          // 購読している文書が変更された
+)
+
+// Notification は ユーザーへのアプリ内通知です
+type Notification struct {
+	ID         int              `json:"id" db:"id"`
+	UserID     int              `json:"userId" db:"user_id"`
+	DocumentID *int             `json:"documentId" db:"document_id"`
+	Type       NotificationType `json:"type" db:"type"`
+	Message    string           `json:"message" db:"message"`
+	Link       string           `json:"link,omitempty" db:"link"` // 通知から開く文書・ブロック・コメントへのリンク（アプリ内の相対パス）
+	ReadAt     *time.Time       `json:"readAt" db:"read_at"`
+	CreatedAt  time.Time        `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// DocumentPublication は 文書の公開設定を表します
+// PublishAt / UnpublishAt は予約日時で、到達するとスケジューラーが IsPublic を切り替えます
+type DocumentPublication struct {
+	DocumentID  int        `json:"documentId" db:"document_id"`
+	IsPublic    bool       `json:"isPublic" db:"is_public"`
+	PublishAt   *time.Time `json:"publishAt" db:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublishAt" db:"unpublish_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+}
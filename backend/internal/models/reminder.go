@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Reminder は 文書に設定されたリマインダーです
+type Reminder struct {
+	ID            int        `json:"id" db:"id"`
+	DocumentID    int        `json:"documentId" db:"document_id"`
+	UserID        int        `json:"userId" db:"user_id"`
+	DocumentTitle string     `json:"documentTitle" db:"title"`
+	RemindAt      time.Time  `json:"remindAt" db:"remind_at"`
+	Note          string     `json:"note" db:"note"`
+	DeliveredAt   *time.Time `json:"deliveredAt" db:"delivered_at"`
+	DismissedAt   *time.Time `json:"dismissedAt" db:"dismissed_at"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+}
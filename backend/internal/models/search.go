@@ -0,0 +1,31 @@
+package models
+
+import (
+	"html"
+	"strings"
+	"time"
+)
+
+// 文書検索の件数
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// DocumentSearchResult は 全文検索で見つかった文書です
+// Snippet は一致した語を <mark> で囲んだブロックのテキストの抜粋で、それ以外の部分は HTML エスケープ済み
+type DocumentSearchResult struct {
+	ID        int       `json:"id" db:"id"`
+	ParentID  *int      `json:"parentId" db:"parent_id"`
+	Title     string    `json:"title" db:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// EscapeSearchSnippet は ts_headline の抜粋を HTML エスケープし、一致箇所の <mark> だけを残します
+func EscapeSearchSnippet(headline string) string {
+	escaped := html.EscapeString(headline)
+	escaped = strings.ReplaceAll(escaped, "&lt;mark&gt;", "<mark>")
+	return strings.ReplaceAll(escaped, "&lt;/mark&gt;", "</mark>")
+}
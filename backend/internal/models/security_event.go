@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SecurityEventType は セキュリティイベントの種類です
+type SecurityEventType string
+
+const (
+	// SecurityEventSecretDetected は 文書保存時に秘密情報らしき文字列を検出したことを表します
+	SecurityEventSecretDetected SecurityEventType = "secret_detected"
+)
+
+// SecurityEvent は 監査用に記録するセキュリティイベントです
+type SecurityEvent struct {
+	ID         int               `json:"id" db:"id"`
+	UserID     *int              `json:"userId" db:"user_id"`
+	DocumentID *int              `json:"documentId" db:"document_id"`
+	EventType  SecurityEventType `json:"eventType" db:"event_type"`
+	Details    json.RawMessage   `json:"details" db:"details"`
+	CreatedAt  time.Time         `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ShareLink は アカウントなしで単一の文書を読み取り専用で閲覧できる共有リンクです
+// Token は作成時のレスポンスにのみ含まれる（保存するのはハッシュのみ）
+// SnapshotID を持つリンクは、最新の文書ではなく作成時点で固定した内容を公開する
+type ShareLink struct {
+	ID             int        `json:"id" db:"id"`
+	DocumentID     int        `json:"documentId" db:"document_id"`
+	SnapshotID     *int       `json:"snapshotId,omitempty" db:"snapshot_id"`
+	Token          string     `json:"token,omitempty"`
+	PasswordHash   string     `json:"-" db:"password_hash"`
+	HasPassword    bool       `json:"hasPassword"`
+	ExpiresAt      *time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedBy      *int       `json:"createdBy" db:"created_by"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	RevokedAt      *time.Time `json:"revokedAt" db:"revoked_at"`
+	LastAccessedAt *time.Time `json:"lastAccessedAt" db:"last_accessed_at"`
+	AccessCount    int        `json:"accessCount" db:"access_count"`
+}
+
+// Expired は 有効期限が過ぎているかを返します
+func (l *ShareLink) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
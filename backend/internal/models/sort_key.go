@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sortKeyDigits は ブロックの並び順キーに使う文字です（バイト順が値の順になる）
+const sortKeyDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// MaxSortKeyLength は 並び順キーの長さの目安です
+// 同じ場所への挿入を繰り返してこれより長くなった場合は、文書全体のキーを振り直す
+const MaxSortKeyLength = 32
+
+// SortKeyBetween は before と after の間に並ぶ並び順キーを返します
+// キーは 0 より大きく 1 より小さい 62 進数の小数部として比較され、前後のキーを書き換えずに間へ挿入できる
+// before が空の場合は先頭、after が空の場合は末尾として扱う
+func SortKeyBetween(before, after string) (string, error) {
+	for _, key := range []string{before, after} {
+		if err := validateSortKey(key); err != nil {
+			return "", err
+		}
+	}
+	if after != "" && before >= after {
+		return "", fmt.Errorf("sort key %q is not before %q", before, after)
+	}
+	return sortKeyMidpoint(before, after), nil
+}
+
+// SortKeysBetween は before と after の間に昇順に並ぶ n 個の並び順キーを返します
+// 二分して中央から決めるため、n が大きくてもキーは短く保たれる
+func SortKeysBetween(before, after string, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	mid, err := SortKeyBetween(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := SortKeysBetween(before, mid, n/2)
+	if err != nil {
+		return nil, err
+	}
+	right, err := SortKeysBetween(mid, after, n-n/2-1)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, n)
+	keys = append(keys, left...)
+	keys = append(keys, mid)
+	return append(keys, right...), nil
+}
+
+// validateSortKey は 並び順キーの形式を検証します（空は前後の端を表す）
+// 末尾が最小の文字だと直前に並ぶキーを作れないため許可しない
+func validateSortKey(key string) error {
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(sortKeyDigits, key[i]) < 0 {
+			return fmt.Errorf("sort key %q contains invalid character %q", key, key[i])
+		}
+	}
+	if strings.HasSuffix(key, sortKeyDigits[:1]) {
+		return fmt.Errorf("sort key %q must not end with %q", key, sortKeyDigits[:1])
+	}
+	return nil
+}
+
+// sortKeyMidpoint は a < b の間のキーを求めます（b が空の場合は上限なし）
+func sortKeyMidpoint(a, b string) string {
+	// 共通の先頭部分はそのまま残す（a が短い場合は最小の文字が続くとみなす）
+	if b != "" {
+		n := 0
+		for n < len(b) && sortKeyDigitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + sortKeyMidpoint(rest, b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(sortKeyDigits, a[0])
+	}
+	digitB := len(sortKeyDigits)
+	if b != "" {
+		digitB = strings.IndexByte(sortKeyDigits, b[0])
+	}
+
+	// 先頭の文字の間に余裕があれば1文字で決まる
+	if digitB-digitA > 1 {
+		return string(sortKeyDigits[(digitA+digitB)/2])
+	}
+
+	// 先頭の文字が隣り合う場合、b がより長ければ b の先頭1文字が間に入る
+	if len(b) > 1 {
+		return b[:1]
+	}
+
+	// それ以外は a の先頭を残し、残りの桁で a より後ろのキーを作る
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(sortKeyDigits[digitA]) + sortKeyMidpoint(rest, "")
+}
+
+// sortKeyDigitAt は キーの i 文字目を返します（範囲外は最小の文字）
+func sortKeyDigitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return sortKeyDigits[0]
+}
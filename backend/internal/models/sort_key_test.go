@@ -0,0 +1,112 @@
+package models
+
+import "testing"
+
+func TestSortKeyBetween(t *testing.T) {
+	tests := []struct {
+		before string
+		after  string
+	}{
+		{before: "", after: ""},
+		{before: "", after: "V"},
+		{before: "V", after: ""},
+		{before: "V", after: "W"},
+		{before: "V5", after: "W"},
+		{before: "V", after: "V1"},
+		{before: "", after: "01"},
+		{before: "zzz", after: ""},
+		// 移行時に振った固定長のキー
+		{before: "00000001V", after: "00000002V"},
+	}
+
+	for _, tt := range tests {
+		key, err := SortKeyBetween(tt.before, tt.after)
+		if err != nil {
+			t.Errorf("SortKeyBetween(%q, %q) error = %v", tt.before, tt.after, err)
+			continue
+		}
+		if key <= tt.before || (tt.after != "" && key >= tt.after) {
+			t.Errorf("SortKeyBetween(%q, %q) = %q, not between", tt.before, tt.after, key)
+		}
+		if err := validateSortKey(key); err != nil {
+			t.Errorf("SortKeyBetween(%q, %q) = %q: %v", tt.before, tt.after, key, err)
+		}
+	}
+}
+
+func TestSortKeyBetween_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{name: "逆順", before: "W", after: "V"},
+		{name: "同じキー", before: "V", after: "V"},
+		{name: "使えない文字", before: "V-", after: ""},
+		{name: "末尾が最小の文字", before: "", after: "V0"},
+	}
+
+	for _, tt := range tests {
+		t.Run("異常系："+tt.name, func(t *testing.T) {
+			if _, err := SortKeyBetween(tt.before, tt.after); err == nil {
+				t.Errorf("SortKeyBetween(%q, %q) expected error", tt.before, tt.after)
+			}
+		})
+	}
+}
+
+func TestSortKeyBetween_RepeatedInsert(t *testing.T) {
+	t.Run("正常系：同じ位置への挿入を繰り返しても順序を保つ", func(t *testing.T) {
+		before, after := "V", "W"
+		for i := 0; i < 200; i++ {
+			key, err := SortKeyBetween(before, after)
+			if err != nil {
+				t.Fatalf("insert %d: %v", i, err)
+			}
+			if key <= before || key >= after {
+				t.Fatalf("insert %d: %q is not between %q and %q", i, key, before, after)
+			}
+			after = key
+		}
+	})
+
+	t.Run("正常系：末尾への追加", func(t *testing.T) {
+		last := ""
+		for i := 0; i < 200; i++ {
+			key, err := SortKeyBetween(last, "")
+			if err != nil {
+				t.Fatalf("append %d: %v", i, err)
+			}
+			if key <= last {
+				t.Fatalf("append %d: %q is not after %q", i, key, last)
+			}
+			last = key
+		}
+	})
+}
+
+func TestSortKeysBetween(t *testing.T) {
+	keys, err := SortKeysBetween("", "", 1000)
+	if err != nil {
+		t.Fatalf("SortKeysBetween() error = %v", err)
+	}
+	if len(keys) != 1000 {
+		t.Fatalf("len = %d, want 1000", len(keys))
+	}
+	for i, key := range keys {
+		if i > 0 && key <= keys[i-1] {
+			t.Fatalf("keys[%d] = %q is not after %q", i, key, keys[i-1])
+		}
+		if len(key) > 3 {
+			t.Errorf("keys[%d] = %q is longer than expected", i, key)
+		}
+	}
+
+	keys, err = SortKeysBetween("V", "W", 3)
+	if err != nil {
+		t.Fatalf("SortKeysBetween() error = %v", err)
+	}
+	if len(keys) != 3 || keys[0] <= "V" || keys[2] >= "W" {
+		t.Errorf("SortKeysBetween(V, W, 3) = %v", keys)
+	}
+}
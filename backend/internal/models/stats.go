@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// InstanceStats は 管理者向けのインスタンス全体の統計です
+type InstanceStats struct {
+	Users             UserStats     `json:"users"`
+	Documents         DocumentStats `json:"documents"`
+	Blocks            BlockStats    `json:"blocks"`
+	Storage           StorageStats  `json:"storage"`
+	UploadsPerDay     []DailyCount  `json:"uploadsPerDay"`
+	ActiveUsersPerDay []DailyCount  `json:"activeUsersPerDay"`
+	GeneratedAt       time.Time     `json:"generatedAt"`
+}
+
+// UserStats は ユーザー数の統計です
+type UserStats struct {
+	Total     int `json:"total"`
+	NewInTerm int `json:"newInTerm"` // 集計期間内に登録したユーザー数
+}
+
+// DocumentStats は 文書数の統計です（Total はごみ箱内の文書を含む）
+type DocumentStats struct {
+	Total   int `json:"total"`
+	Trashed int `json:"trashed"`
+}
+
+// BlockStats は ブロック数の統計です
+type BlockStats struct {
+	Total  int              `json:"total"`
+	ByType []BlockTypeCount `json:"byType"`
+}
+
+// StorageStats は 有効なアップロードファイルの件数と合計サイズです
+type StorageStats struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+// DailyCount は 日ごとの件数です（Date は YYYY-MM-DD）
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
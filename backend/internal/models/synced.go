@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SyncedContent は synced ブロックの content です（同期元は SyncID で参照する）
+type SyncedContent struct {
+	SyncID int `json:"syncId"`
+}
+
+// DecodeSyncedContent は synced ブロックの content を解析します
+// JSON 文字列として送られてきたオブジェクトも受け付ける
+func DecodeSyncedContent(raw json.RawMessage) (SyncedContent, error) {
+	var synced SyncedContent
+	err := json.Unmarshal(NormalizeBlockContent("synced", raw), &synced)
+	return synced, err
+}
+
+// SyncedBlock は 他の文書に同期ブロックとして埋め込めるよう登録した同期元ブロックです
+// 同期元のブロックIDは文書全体の保存で作り直されても付け替えて追跡する
+type SyncedBlock struct {
+	ID               int       `json:"syncId" db:"id"`
+	UserID           int       `json:"userId" db:"user_id"`
+	SourceDocumentID int       `json:"sourceDocumentId" db:"source_document_id"`
+	SourceBlockID    int       `json:"sourceBlockId" db:"source_block_id"`
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
+}
+
+// SyncedSource は synced ブロックが表示する同期元ブロックの現在の内容です
+// 同期元を編集すると、読み込み時に全ての synced ブロックへ反映される
+type SyncedSource struct {
+	SyncID     int             `json:"syncId"`
+	DocumentID int             `json:"documentId"`
+	BlockID    int             `json:"blockId"`
+	Type       string          `json:"type"`
+	Content    json.RawMessage `json:"content"`
+}
+
+// Block は 同期元の内容を、synced ブロックの位置に置いたブロックとして返します（エクスポート用）
+func (s *SyncedSource) Block(at Block) Block {
+	at.Type = s.Type
+	at.Content = s.Content
+	at.Synced = nil
+	return at
+}
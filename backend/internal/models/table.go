@@ -0,0 +1,20 @@
+package models
+
+import "strings"
+
+// TableContent は table ブロックの content です
+// Rows はすべて同じ列数で、Header が true の場合は先頭行を見出しとして扱う
+type TableContent struct {
+	Header bool       `json:"header"`
+	Rows   [][]string `json:"rows"`
+}
+
+// PlainText は 表の内容をプレーンテキストに変換します（セルはタブ、行は改行で区切る）
+// 検索やコンテンツポリシーのチェックで本文と同様に扱うために使う
+func (t TableContent) PlainText() string {
+	lines := make([]string, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		lines = append(lines, strings.Join(row, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// TodoContent は todo ブロックの content です（DueDate は YYYY-MM-DD、未設定の場合は空）
+type TodoContent struct {
+	Text    string `json:"text"`
+	Checked bool   `json:"checked"`
+	DueDate string `json:"dueDate,omitempty"`
+}
+
+// Task は 「自分のタスク」一覧に表示する未完了の todo ブロックです
+type Task struct {
+	BlockID       int       `json:"blockId" db:"block_id"`
+	DocumentID    int       `json:"documentId" db:"document_id"`
+	DocumentTitle string    `json:"documentTitle" db:"document_title"`
+	Text          string    `json:"text" db:"text"`
+	DueDate       *string   `json:"dueDate,omitempty" db:"due_date"`
+	Overdue       bool      `json:"overdue"`
+	Position      int       `json:"position" db:"position"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import "time"
+
+// TrashSort は ごみ箱一覧の並び替えキーです
+type TrashSort string
+
+const (
+	TrashSortDeletedAt TrashSort = "deletedAt" // ごみ箱に移動した日時（既定）
+	TrashSortTitle     TrashSort = "title"
+	TrashSortCreatedAt TrashSort = "createdAt"
+)
+
+// TrashFilter は ごみ箱一覧の絞り込み・並び替え条件です（ゼロ値は条件なし）
+type TrashFilter struct {
+	Query       string     // タイトルの部分一致
+	DeletedFrom *time.Time // この日時以降にごみ箱へ移動したもの
+	DeletedTo   *time.Time // この日時より前にごみ箱へ移動したもの
+	ParentID    *int       // 削除前の親文書
+	Sort        TrashSort
+	Ascending   bool
+}
+
+// RestoreStrategy は ごみ箱から一括復元する際の親文書の扱いです
+type RestoreStrategy string
+
+const (
+	// RestoreToOriginalParent は 削除前の親文書の下に戻します
+	// 親文書もごみ箱内にあり、同時に復元しない場合はルートに戻す
+	RestoreToOriginalParent RestoreStrategy = "restore-to-original-parent"
+	// RestoreToRoot は 親文書に関わらずルートに戻します
+	RestoreToRoot RestoreStrategy = "restore-to-root"
+)
+
+// MaxBulkRestore は 一括復元で一度に指定できる文書の最大数です
+const MaxBulkRestore = 500
+
+// 一括復元で復元できなかった文書の理由
+const (
+	RestoreErrorNotFound   = "NOT_FOUND"    // 存在しない、または他ユーザーの文書
+	RestoreErrorNotInTrash = "NOT_IN_TRASH" // ごみ箱に入っていない
+)
+
+// RestoreDocumentState は 一括復元の判定に使う文書の状態です
+type RestoreDocumentState struct {
+	ID        int
+	ParentID  *int
+	IsDeleted bool
+	Level     int
+}
+
+// RestoreResult は 一括復元の文書ごとの結果です
+type RestoreResult struct {
+	ID       int  `json:"id"`
+	Restored bool `json:"restored"`
+	// ParentID は 復元後の親文書です（nil はルート）
+	ParentID *int `json:"parentId"`
+	// Reattached は 削除前の親文書に戻せずルートに付け替えた場合に true です
+	Reattached bool   `json:"reattached,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// PlanBulkRestore は 一括復元の結果（復元先の親文書）を求めます
+// states には復元対象と、その削除前の親文書のうちユーザーが所有するものを渡す
+// 結果は ids の順（重複は除く）で返し、復元できない文書は Error を設定する
+func PlanBulkRestore(ids []int, strategy RestoreStrategy, states map[int]RestoreDocumentState) []RestoreResult {
+	requested := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		requested[id] = true
+	}
+
+	results := make([]RestoreResult, 0, len(requested))
+	seen := make(map[int]bool, len(requested))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		state, ok := states[id]
+		switch {
+		case !ok:
+			results = append(results, RestoreResult{ID: id, Error: RestoreErrorNotFound})
+			continue
+		case !state.IsDeleted:
+			results = append(results, RestoreResult{ID: id, ParentID: state.ParentID, Error: RestoreErrorNotInTrash})
+			continue
+		}
+
+		result := RestoreResult{ID: id, Restored: true}
+		if strategy == RestoreToOriginalParent && state.ParentID != nil {
+			// 親文書が存在し、ごみ箱外にある（または同時に復元される）場合のみ元の親に戻す
+			parent, ok := states[*state.ParentID]
+			if ok && (!parent.IsDeleted || requested[parent.ID]) {
+				result.ParentID = state.ParentID
+			} else {
+				result.Reattached = true
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
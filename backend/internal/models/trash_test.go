@@ -0,0 +1,90 @@
+package models
+
+import "testing"
+
+func TestPlanBulkRestore(t *testing.T) {
+	// 1: ごみ箱外の親、2: 1 の子（ごみ箱内）、3: ごみ箱内の親、4: 3 の子（ごみ箱内）、5: ごみ箱外
+	states := map[int]RestoreDocumentState{
+		1: {ID: 1},
+		2: {ID: 2, ParentID: intPtr(1), IsDeleted: true, Level: 1},
+		3: {ID: 3, IsDeleted: true},
+		4: {ID: 4, ParentID: intPtr(3), IsDeleted: true, Level: 1},
+		5: {ID: 5},
+		6: {ID: 6, ParentID: intPtr(99), IsDeleted: true, Level: 1}, // 親は他ユーザーのもの、または完全削除済み
+	}
+
+	type want struct {
+		restored   bool
+		parentID   *int
+		reattached bool
+		err        string
+	}
+	tests := []struct {
+		name     string
+		ids      []int
+		strategy RestoreStrategy
+		want     map[int]want
+	}{
+		{
+			name:     "元の親：親がごみ箱外なら親の下に戻す",
+			ids:      []int{2},
+			strategy: RestoreToOriginalParent,
+			want:     map[int]want{2: {restored: true, parentID: intPtr(1)}},
+		},
+		{
+			name:     "元の親：親がごみ箱内で同時に復元しない場合はルート",
+			ids:      []int{4},
+			strategy: RestoreToOriginalParent,
+			want:     map[int]want{4: {restored: true, reattached: true}},
+		},
+		{
+			name:     "元の親：親も同時に復元する場合は親の下に戻す",
+			ids:      []int{4, 3},
+			strategy: RestoreToOriginalParent,
+			want:     map[int]want{3: {restored: true}, 4: {restored: true, parentID: intPtr(3)}},
+		},
+		{
+			name:     "元の親：親を取得できない場合はルート",
+			ids:      []int{6},
+			strategy: RestoreToOriginalParent,
+			want:     map[int]want{6: {restored: true, reattached: true}},
+		},
+		{
+			name:     "ルート：すべてルートに戻す",
+			ids:      []int{2, 3, 4},
+			strategy: RestoreToRoot,
+			want:     map[int]want{2: {restored: true}, 3: {restored: true}, 4: {restored: true}},
+		},
+		{
+			name:     "復元できない文書は理由を返す",
+			ids:      []int{5, 42, 5},
+			strategy: RestoreToOriginalParent,
+			want:     map[int]want{5: {err: RestoreErrorNotInTrash}, 42: {err: RestoreErrorNotFound}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := PlanBulkRestore(tt.ids, tt.strategy, states)
+			if len(results) != len(tt.want) {
+				t.Fatalf("len(results) = %d, want %d", len(results), len(tt.want))
+			}
+			for _, got := range results {
+				w := tt.want[got.ID]
+				if got.Restored != w.restored || got.Reattached != w.reattached || got.Error != w.err {
+					t.Errorf("result %d = %+v, want %+v", got.ID, got, w)
+				}
+				if w.restored && !equalIntPtr(got.ParentID, w.parentID) {
+					t.Errorf("result %d ParentID = %v, want %v", got.ID, got.ParentID, w.parentID)
+				}
+			}
+		})
+	}
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
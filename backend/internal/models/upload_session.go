@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// 分割アップロードのチャンクの制約（S3 マルチパートアップロードの制約に合わせる）
+const (
+	MinUploadChunkSize = 5 << 20  // 最後以外のチャンクの最小サイズ
+	MaxUploadChunkSize = 64 << 20 // 1チャンクの最大サイズ
+	MaxUploadParts     = 10000    // 1ファイルあたりのチャンク数の上限
+)
+
+// UploadSession は 再開可能な分割アップロードのセッションです
+// クライアントは Offset から続きのチャンクを送る
+type UploadSession struct {
+	ID              string    `json:"id"`
+	UserID          int       `json:"-"`
+	FileKey         string    `json:"-"`
+	StorageUploadID string    `json:"-"`
+	OriginalName    string    `json:"filename"`
+	MimeType        string    `json:"contentType"`
+	TotalSize       int64     `json:"size"`
+	Offset          int64     `json:"offset"`
+	PartCount       int       `json:"-"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// Complete は すべてのチャンクを受信済みかを返します
+func (s *UploadSession) Complete() bool {
+	return s.Offset == s.TotalSize
+}
+
+// UploadPart は 分割アップロードで受信済みのパートです
+type UploadPart struct {
+	PartNumber int
+	ETag       string
+	Size       int64
+}
+
+// DirectUpload は 署名付きURLでストレージへ直接アップロードする、確認前のファイルです
+type DirectUpload struct {
+	ID           string    `json:"id"`
+	UserID       int       `json:"-"`
+	FileKey      string    `json:"-"`
+	OriginalName string    `json:"filename"`
+	MimeType     string    `json:"contentType"`
+	FileSize     int64     `json:"size"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// アップロードの進捗の段階
+const (
+	UploadStageReceiving  = "receiving"  // リクエストボディを受信中
+	UploadStageProcessing = "processing" // 受信済みで、変換・ストレージへの保存中
+	UploadStageCompleted  = "completed"
+	UploadStageFailed     = "failed"
+)
+
+// UploadProgress は アップロードの進捗です（SSE でエディターに送る）
+type UploadProgress struct {
+	UploadID      string `json:"uploadId"`
+	Stage         string `json:"stage"`
+	ReceivedBytes int64  `json:"receivedBytes"`
+	TotalBytes    int64  `json:"totalBytes"` // 分からない場合は 0
+	FileID        int    `json:"fileId,omitempty"`
+}
+
+// Finished は アップロードが完了または失敗したか（これ以上進捗が届かないか）を返します
+func (p UploadProgress) Finished() bool {
+	return p.Stage == UploadStageCompleted || p.Stage == UploadStageFailed
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook は ユーザーが登録した、イベントを送る URL です
+type Webhook struct {
+	ID          int       `json:"id" db:"id"`
+	UserID      int       `json:"userId" db:"user_id"`
+	URL         string    `json:"url" db:"url"`
+	Events      []string  `json:"events" db:"events"`
+	Description string    `json:"description" db:"description"`
+	Active      bool      `json:"active" db:"active"`
+	Secret      string    `json:"secret,omitempty" db:"secret"` // 署名の鍵（作成時と再発行時のみ返す）
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// WebhookInput は Webhook の作成のリクエストです
+type WebhookInput struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+}
+
+// WebhookUpdate は Webhook の変更のリクエストです（nil の項目は変更しない）
+type WebhookUpdate struct {
+	URL         *string   `json:"url"`
+	Events      *[]string `json:"events"`
+	Description *string   `json:"description"`
+	Active      *bool     `json:"active"`
+}
+
+// WebhookDeliveryStatus は Webhook の配信の状態です
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // 送信待ち・再試行待ち
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded" // 2xx の応答を受け取った
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // 試行回数の上限まで失敗した
+)
+
+// WebhookDelivery は 1つのイベントを1つの Webhook に送る配信と、最後の試行の記録です
+type WebhookDelivery struct {
+	ID             int                   `json:"id" db:"id"`
+	WebhookID      int                   `json:"webhookId" db:"webhook_id"`
+	EventID        string                `json:"eventId" db:"event_id"`
+	EventType      string                `json:"eventType" db:"event_type"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time            `json:"nextAttemptAt,omitempty" db:"next_attempt_at"` // pending のみ
+	LastAttemptAt  *time.Time            `json:"lastAttemptAt" db:"last_attempt_at"`
+	ResponseStatus *int                  `json:"responseStatus" db:"response_status"`
+	ResponseBody   string                `json:"responseBody" db:"response_body"` // 先頭の一部のみ保存する
+	Error          string                `json:"error" db:"error"`                // 接続できなかった場合などの理由
+	DurationMs     *int                  `json:"durationMs" db:"duration_ms"`
+	CreatedAt      time.Time             `json:"createdAt" db:"created_at"`
+	DeliveredAt    *time.Time            `json:"deliveredAt" db:"delivered_at"`
+}
+
+// WebhookDispatch は 送信する配信と送信先です（バックグラウンドの配信処理が取得する）
+type WebhookDispatch struct {
+	DeliveryID int
+	WebhookID  int
+	EventID    string
+	EventType  string
+	Payload    []byte
+	Attempts   int // これまでの試行回数
+	URL        string
+	Secret     string
+}
+
+// WebhookAttempt は 1回の送信の結果です
+type WebhookAttempt struct {
+	Status         WebhookDeliveryStatus
+	NextAttemptAt  *time.Time // 再試行する場合のみ
+	ResponseStatus *int
+	ResponseBody   string
+	Error          string
+	Duration       time.Duration
+}
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// WorkspaceRole は ワークスペースでのメンバーの役割です
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "owner"  // 作成者。ワークスペースの削除と、管理者の任命ができる
+	WorkspaceRoleAdmin  WorkspaceRole = "admin"  // 名前の変更と、メンバーの追加・削除ができる
+	WorkspaceRoleMember WorkspaceRole = "member" // 文書の作成・編集とファイルのアップロードができる
+	WorkspaceRoleGuest  WorkspaceRole = "guest"  // 閲覧のみ。文書の変更・ファイルのアップロードとメンバー一覧の閲覧はできない
+)
+
+// Valid は 役割が既知の値かを返します
+func (r WorkspaceRole) Valid() bool {
+	switch r {
+	case WorkspaceRoleOwner, WorkspaceRoleAdmin, WorkspaceRoleMember, WorkspaceRoleGuest:
+		return true
+	}
+	return false
+}
+
+// CanManageMembers は メンバーの追加・削除とワークスペースの名前の変更ができるかを返します
+func (r WorkspaceRole) CanManageMembers() bool {
+	return r == WorkspaceRoleOwner || r == WorkspaceRoleAdmin
+}
+
+// CanEdit は 文書の作成・変更とファイルのアップロードができるかを返します
+func (r WorkspaceRole) CanEdit() bool {
+	return r == WorkspaceRoleOwner || r == WorkspaceRoleAdmin || r == WorkspaceRoleMember
+}
+
+// Workspace は 文書をまとめるワークスペースです
+type Workspace struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	OwnerID   int       `json:"ownerId" db:"owner_id"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	// Role は 取得したユーザーの役割です
+	Role WorkspaceRole `json:"role" db:"role"`
+	// Usage は 単一のワークスペースを取得した場合のみ設定される
+	Usage *WorkspaceUsage `json:"usage,omitempty"`
+}
+
+// WorkspaceUsage は ワークスペースの文書数・メンバー数と上限です（上限の 0 は無制限）
+type WorkspaceUsage struct {
+	Documents     int `json:"documents"`
+	DocumentLimit int `json:"documentLimit"`
+	Members       int `json:"members"`
+	MemberLimit   int `json:"memberLimit"`
+}
+
+// WorkspaceStorageUsage は ワークスペースのストレージクォータと、メンバー全員のファイルの使用量です
+type WorkspaceStorageUsage struct {
+	WorkspaceID int   `json:"workspaceId"`
+	QuotaBytes  int64 `json:"quotaBytes"` // 0 は無制限
+	IsDefault   bool  `json:"isDefault"`  // 個別に設定されておらず、全体の既定値を使っている
+	UsedBytes   int64 `json:"usedBytes"`
+	FileCount   int   `json:"fileCount"`
+	// Members は メンバーごとの使用量です（使用量の多い順、管理者向けのクォータの変更では設定しない）
+	Members []WorkspaceMemberStorage `json:"members,omitempty"`
+}
+
+// WorkspaceMemberStorage は ワークスペースのメンバー1人のファイルの使用量です
+type WorkspaceMemberStorage struct {
+	UserID    int           `json:"userId"`
+	Email     string        `json:"email"`
+	Name      string        `json:"name"`
+	Role      WorkspaceRole `json:"role"`
+	FileCount int           `json:"fileCount"`
+	UsedBytes int64         `json:"usedBytes"`
+}
+
+// WorkspaceMember は ワークスペースのメンバーです
+type WorkspaceMember struct {
+	WorkspaceID int           `json:"workspaceId" db:"workspace_id"`
+	UserID      int           `json:"userId" db:"user_id"`
+	Email       string        `json:"email" db:"email"`
+	Name        string        `json:"name" db:"name"`
+	Role        WorkspaceRole `json:"role" db:"role"`
+	JoinedAt    time.Time     `json:"joinedAt" db:"joined_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// WorkspaceInvitation は メールで送るワークスペースへの招待です
+// Token は招待メールにのみ含める（保存するのはハッシュのみ）
+type WorkspaceInvitation struct {
+	ID          int           `json:"id" db:"id"`
+	WorkspaceID int           `json:"workspaceId" db:"workspace_id"`
+	Email       string        `json:"email" db:"email"`
+	Role        WorkspaceRole `json:"role" db:"role"`
+	Token       string        `json:"-"`
+	InvitedBy   *int          `json:"invitedBy" db:"invited_by"`
+	CreatedAt   time.Time     `json:"createdAt" db:"created_at"`
+	ExpiresAt   time.Time     `json:"expiresAt" db:"expires_at"`
+	AcceptedAt  *time.Time    `json:"acceptedAt,omitempty" db:"accepted_at"`
+	RevokedAt   *time.Time    `json:"revokedAt,omitempty" db:"revoked_at"`
+	// WorkspaceName・InviterName は 招待リンクから取得した場合のみ設定される
+	WorkspaceName string `json:"workspaceName,omitempty"`
+	InviterName   string `json:"inviterName,omitempty"`
+}
+
+// Expired は 有効期限が過ぎているかを返します
+func (i *WorkspaceInvitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// Pending は 承諾・取り消しのどちらもされていないかを返します
+func (i *WorkspaceInvitation) Pending() bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil
+}
@@ -0,0 +1,182 @@
+// Package pagination は 一覧 API に共通のカーソル方式のページ分割を提供します
+//
+// クエリパラメータ cursor（前のページのレスポンスの nextCursor）と limit（1ページの件数）で次のページを取得する。
+// カーソルは前のページの最後の要素の並び替えキーと ID を持ち、リポジトリはその位置より後ろの行を
+// キーセット（(並び替えキー, id) の行値の比較）で取得するため、ページ間で行が追加・削除されても重複・欠落しない。
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+)
+
+// 1ページの件数の既定値と上限（一覧ごとに別の値を使う場合はサービス層で定義する）
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// ErrInvalidCursor は 読み込めない・一覧の並び順と合わないカーソルのエラーです
+// リポジトリは fmt.Errorf の %w でラップして返し、apierror.Write で 400 になる
+var ErrInvalidCursor = apierror.NewValidationError(
+	"INVALID_CURSOR", "cursor が不正です。前のページの nextCursor をそのまま指定してください", nil,
+)
+
+// Cursor は 前のページの最後の要素の位置です
+type Cursor struct {
+	Sort string   `json:"s,omitempty"` // カーソルを作ったときの並び順（並び順を変えた場合は使えない）
+	Keys []string `json:"k,omitempty"` // 並び替えキーの値（SQL の ::text の表現）
+	ID   int      `json:"id"`
+}
+
+// Encode は カーソルを URL に含められる文字列にします
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode は Encode した文字列からカーソルを読み込みます
+func Decode(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode cursor: %w", ErrInvalidCursor)
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("unmarshal cursor: %w", ErrInvalidCursor)
+	}
+	return &c, nil
+}
+
+// timeKeyLayout は timestamp の並び替えキーの形式です（PostgreSQL の timestamp の ::text と同じ）
+const timeKeyLayout = "2006-01-02 15:04:05.999999"
+
+// FormatTime は timestamp の列から読み込んだ日時を並び替えキーにします
+func FormatTime(t time.Time) string {
+	return t.Format(timeKeyLayout)
+}
+
+// KeyKind は 並び替えキーの型です（改ざんされたカーソルで SQL の型変換が失敗しないよう、使う前に確認する）
+type KeyKind int
+
+const (
+	TextKey   KeyKind = iota // text（任意の文字列）
+	TimeKey                  // timestamp（"2006-01-02 15:04:05.999999" の形式）
+	NumberKey                // 整数・浮動小数点数
+)
+
+// Params は 取得するページの指定です
+type Params struct {
+	Limit int     // 1ページの件数（0 の場合はリポジトリで件数を制限しない）
+	After *Cursor // nil の場合は先頭のページ
+}
+
+// Parse は クエリパラメータ cursor・limit を読み込みます（limit を省略した場合は 0）
+func Parse(query url.Values) (Params, error) {
+	var p Params
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return p, apierror.NewValidationError(
+				"INVALID_PAGINATION", "limit には 1 以上の整数を指定してください", err,
+			)
+		}
+		p.Limit = limit
+	}
+	if raw := query.Get("cursor"); raw != "" {
+		after, err := Decode(raw)
+		if err != nil {
+			return p, err
+		}
+		p.After = after
+	}
+	return p, nil
+}
+
+// Check は カーソルが sort の並び順で作られ、並び替えキーが kinds と同じ数・型かを確認します（先頭のページは常に nil）
+func (p Params) Check(sort string, kinds ...KeyKind) error {
+	c := p.After
+	if c == nil {
+		return nil
+	}
+	if c.Sort != sort || len(c.Keys) != len(kinds) {
+		return fmt.Errorf("cursor for sort %q with %d keys: %w", c.Sort, len(c.Keys), ErrInvalidCursor)
+	}
+	for i, kind := range kinds {
+		var err error
+		switch kind {
+		case TimeKey:
+			_, err = time.Parse(timeKeyLayout, c.Keys[i])
+		case NumberKey:
+			_, err = strconv.ParseFloat(c.Keys[i], 64)
+		}
+		if err != nil {
+			return fmt.Errorf("cursor key %d: %w", i, ErrInvalidCursor)
+		}
+	}
+	return nil
+}
+
+// Clamp は limit が 0 の場合は defaultLimit、maxLimit を超える場合は maxLimit にした Params を返します
+func (p Params) Clamp(defaultLimit, maxLimit int) Params {
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+	return p
+}
+
+// FetchLimit は SQL の LIMIT に渡す件数です
+// 次のページがあるかを判定するため1件多く取得する（Limit が 0 の場合は nil で LIMIT NULL = 制限なし）
+func (p Params) FetchLimit() interface{} {
+	if p.Limit <= 0 {
+		return nil
+	}
+	return p.Limit + 1
+}
+
+// AfterKey は カーソルの i 番目の並び替えキーを SQL の引数として返します（先頭のページは nil）
+func (p Params) AfterKey(i int) interface{} {
+	if p.After == nil || i >= len(p.After.Keys) {
+		return nil
+	}
+	return p.After.Keys[i]
+}
+
+// AfterID は カーソルの ID を SQL の引数として返します（先頭のページは nil）
+func (p Params) AfterID() interface{} {
+	if p.After == nil {
+		return nil
+	}
+	return p.After.ID
+}
+
+// Page は 一覧の1ページ分です（nextCursor は最後のページの場合 null）
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+// NewPage は FetchLimit 件まで取得した items から1ページ分を作ります
+// Limit より多く取得できた場合は次のページがあるとして、ページの最後の要素（items[Limit-1]）の位置を
+// cursor で作り nextCursor にする
+func NewPage[T any](items []T, p Params, cursor func(i int) Cursor) Page[T] {
+	if items == nil {
+		items = make([]T, 0)
+	}
+	page := Page[T]{Items: items}
+	if p.Limit > 0 && len(items) > p.Limit {
+		page.Items = items[:p.Limit]
+		next := cursor(p.Limit - 1).Encode()
+		page.NextCursor = &next
+	}
+	return page
+}
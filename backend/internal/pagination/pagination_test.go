@@ -0,0 +1,125 @@
+package pagination
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+)
+
+func TestParse(t *testing.T) {
+	cursor := Cursor{Sort: "name:asc", Keys: []string{"議事録.pdf"}, ID: 12}
+
+	tests := []struct {
+		name      string
+		query     url.Values
+		wantLimit int
+		wantAfter *Cursor
+		wantCode  string
+	}{
+		{name: "正常系：指定なし", query: url.Values{}},
+		{name: "正常系：limit と cursor", query: url.Values{"limit": {"20"}, "cursor": {cursor.Encode()}}, wantLimit: 20, wantAfter: &cursor},
+		{name: "異常系：limit が 0", query: url.Values{"limit": {"0"}}, wantCode: "INVALID_PAGINATION"},
+		{name: "異常系：limit が数値でない", query: url.Values{"limit": {"many"}}, wantCode: "INVALID_PAGINATION"},
+		{name: "異常系：cursor が base64 でない", query: url.Values{"cursor": {"!!"}}, wantCode: "INVALID_CURSOR"},
+		{name: "異常系：cursor が JSON でない", query: url.Values{"cursor": {"bm90LWpzb24"}}, wantCode: "INVALID_CURSOR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.query)
+			if tt.wantCode != "" {
+				var appErr *apierror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Fatalf("error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if p.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", p.Limit, tt.wantLimit)
+			}
+			if (p.After == nil) != (tt.wantAfter == nil) {
+				t.Fatalf("After = %+v, want %+v", p.After, tt.wantAfter)
+			}
+			if p.After != nil && (p.After.Sort != tt.wantAfter.Sort || p.After.ID != tt.wantAfter.ID ||
+				len(p.After.Keys) != 1 || p.After.Keys[0] != tt.wantAfter.Keys[0]) {
+				t.Errorf("After = %+v, want %+v", p.After, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestParamsCheck(t *testing.T) {
+	uploadedAt := FormatTime(time.Date(2026, 3, 1, 9, 30, 0, 123456000, time.UTC))
+
+	tests := []struct {
+		name    string
+		after   *Cursor
+		sort    string
+		kinds   []KeyKind
+		wantErr bool
+	}{
+		{name: "正常系：先頭のページ", sort: "uploadedAt:desc", kinds: []KeyKind{TimeKey}},
+		{name: "正常系：日時のキー", after: &Cursor{Sort: "uploadedAt:desc", Keys: []string{uploadedAt}, ID: 3}, sort: "uploadedAt:desc", kinds: []KeyKind{TimeKey}},
+		{name: "正常系：数値と日時のキー", after: &Cursor{Keys: []string{"0.0607927", uploadedAt}, ID: 3}, kinds: []KeyKind{NumberKey, TimeKey}},
+		{name: "異常系：並び順が異なる", after: &Cursor{Sort: "name:asc", Keys: []string{"a"}, ID: 3}, sort: "size:desc", kinds: []KeyKind{NumberKey}, wantErr: true},
+		{name: "異常系：キーの数が異なる", after: &Cursor{ID: 3}, kinds: []KeyKind{TimeKey}, wantErr: true},
+		{name: "異常系：日時として読めないキー", after: &Cursor{Keys: []string{"yesterday"}, ID: 3}, kinds: []KeyKind{TimeKey}, wantErr: true},
+		{name: "異常系：数値として読めないキー", after: &Cursor{Keys: []string{"1; DROP TABLE"}, ID: 3}, kinds: []KeyKind{NumberKey}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Params{Limit: 10, After: tt.after}.Check(tt.sort, tt.kinds...)
+			if tt.wantErr != errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	cursorOf := func(items []int) func(i int) Cursor {
+		return func(i int) Cursor { return Cursor{ID: items[i]} }
+	}
+
+	tests := []struct {
+		name       string
+		items      []int
+		limit      int
+		wantItems  int
+		wantNextID int // 0 の場合は次のページなし
+	}{
+		{name: "正常系：次のページあり", items: []int{1, 2, 3}, limit: 2, wantItems: 2, wantNextID: 2},
+		{name: "正常系：ちょうど最後のページ", items: []int{1, 2}, limit: 2, wantItems: 2},
+		{name: "正常系：件数の制限なし", items: []int{1, 2, 3}, limit: 0, wantItems: 3},
+		{name: "正常系：0件", items: nil, limit: 2, wantItems: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewPage(tt.items, Params{Limit: tt.limit}, cursorOf(tt.items))
+			if page.Items == nil || len(page.Items) != tt.wantItems {
+				t.Fatalf("Items = %v, want %d items", page.Items, tt.wantItems)
+			}
+			if tt.wantNextID == 0 {
+				if page.NextCursor != nil {
+					t.Errorf("NextCursor = %q, want nil", *page.NextCursor)
+				}
+				return
+			}
+			if page.NextCursor == nil {
+				t.Fatal("NextCursor = nil")
+			}
+			next, err := Decode(*page.NextCursor)
+			if err != nil || next.ID != tt.wantNextID {
+				t.Errorf("next cursor = %+v (err %v), want id %d", next, err, tt.wantNextID)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Package password は パスワードのハッシュ化と検証を提供します
+//
+// 新しいハッシュは argon2id（PHC 文字列形式）で作成し、従来の bcrypt ハッシュも検証できる。
+// 検証時に bcrypt や古いパラメータの argon2id だった場合は再ハッシュが必要であることを返すため、
+// ログイン成功時に現在の設定で保存し直すことで段階的に移行できる。
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrMismatch は パスワードがハッシュと一致しない場合のエラーです
+var ErrMismatch = errors.New("password does not match")
+
+// ErrUnknownFormat は 対応していない形式のハッシュの場合のエラーです
+var ErrUnknownFormat = errors.New("unknown password hash format")
+
+// 検証・ハッシュ化の所要時間を記録する際の操作名
+const (
+	OpHashArgon2id   = "hash_argon2id"
+	OpVerifyArgon2id = "verify_argon2id"
+	OpVerifyBcrypt   = "verify_bcrypt"
+)
+
+// Params は argon2id のコストパラメータです
+type Params struct {
+	Memory      uint32 // メモリ使用量（KiB）
+	Iterations  uint32 // 反復回数
+	Parallelism uint8  // 並列度
+	SaltLength  uint32 // ソルトの長さ（バイト）
+	KeyLength   uint32 // 出力するハッシュの長さ（バイト）
+}
+
+// DefaultParams は OWASP の推奨値を基にした既定のパラメータです
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// LatencyObserver は ハッシュ化・検証の所要時間を受け取ります（メトリクス収集用）
+type LatencyObserver interface {
+	ObserveLatency(name string, d time.Duration)
+}
+
+// Hasher は 設定されたパラメータでパスワードをハッシュ化・検証します
+type Hasher struct {
+	params   Params
+	observer LatencyObserver
+}
+
+// NewHasher は Hasher を作成します
+// 0 のパラメータは DefaultParams の値で補う
+func NewHasher(params Params) *Hasher {
+	if params.Memory == 0 {
+		params.Memory = DefaultParams.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = DefaultParams.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = DefaultParams.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultParams.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultParams.KeyLength
+	}
+	return &Hasher{params: params}
+}
+
+// SetObserver は 所要時間の通知先を設定します（起動時に1度だけ呼ぶ）
+func (h *Hasher) SetObserver(observer LatencyObserver) {
+	h.observer = observer
+}
+
+// Hash は パスワードを argon2id でハッシュ化し、PHC 文字列形式で返します
+func (h *Hasher) Hash(password string) (string, error) {
+	defer h.observe(OpHashArgon2id, time.Now())
+
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify は パスワードがハッシュと一致するかを検証します
+// 一致した場合、ハッシュが bcrypt または現在と異なるパラメータの argon2id であれば needsRehash=true を返す
+// 一致しない場合は ErrMismatch を返す
+func (h *Hasher) Verify(password, encoded string) (needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		defer h.observe(OpVerifyArgon2id, time.Now())
+		return h.verifyArgon2id(password, encoded)
+
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		defer h.observe(OpVerifyBcrypt, time.Now())
+		if err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, ErrMismatch
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, ErrUnknownFormat
+}
+
+// verifyArgon2id は PHC 文字列形式の argon2id ハッシュを検証します
+func (h *Hasher) verifyArgon2id(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, ErrMismatch
+	}
+
+	needsRehash := params.Memory != h.params.Memory ||
+		params.Iterations != h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism ||
+		params.SaltLength != h.params.SaltLength ||
+		params.KeyLength != h.params.KeyLength
+	return needsRehash, nil
+}
+
+// decodeArgon2id は "$argon2id$v=19$m=...,t=...,p=...$<salt>$<hash>" を分解します
+func decodeArgon2id(encoded string) (Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return Params{}, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Params{}, nil, nil, fmt.Errorf("unsupported argon2 version %q: %w", parts[2], ErrUnknownFormat)
+	}
+
+	var params Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Params{}, nil, nil, fmt.Errorf("invalid argon2 parameters %q: %w", parts[3], ErrUnknownFormat)
+	}
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		return Params{}, nil, nil, fmt.Errorf("invalid argon2 parameters %q: %w", parts[3], ErrUnknownFormat)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Params{}, nil, nil, fmt.Errorf("invalid argon2 salt: %w", ErrUnknownFormat)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Params{}, nil, nil, fmt.Errorf("invalid argon2 hash: %w", ErrUnknownFormat)
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
+
+// observe は start からの経過時間を通知します
+func (h *Hasher) observe(op string, start time.Time) {
+	if h.observer != nil {
+		h.observer.ObserveLatency("password_"+op, time.Since(start))
+	}
+}
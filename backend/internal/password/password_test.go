@@ -0,0 +1,90 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// テストを高速にするための低コストのパラメータ
+var testParams = Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+type recordingObserver struct {
+	names []string
+}
+
+func (o *recordingObserver) ObserveLatency(name string, d time.Duration) {
+	o.names = append(o.names, name)
+}
+
+func TestHasher_HashAndVerify(t *testing.T) {
+	hasher := NewHasher(testParams)
+
+	encoded, err := hasher.Hash("password123")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Hash() = %q, want argon2id PHC string", encoded)
+	}
+
+	t.Run("正常系：一致", func(t *testing.T) {
+		needsRehash, err := hasher.Verify("password123", encoded)
+		if err != nil || needsRehash {
+			t.Errorf("Verify() = (%v, %v), want (false, nil)", needsRehash, err)
+		}
+	})
+
+	t.Run("異常系：不一致", func(t *testing.T) {
+		if _, err := hasher.Verify("wrong", encoded); !errors.Is(err, ErrMismatch) {
+			t.Errorf("Verify() error = %v, want ErrMismatch", err)
+		}
+	})
+
+	t.Run("正常系：パラメータ変更後は再ハッシュが必要", func(t *testing.T) {
+		stronger := NewHasher(Params{Memory: 2048, Iterations: 1, Parallelism: 1})
+		needsRehash, err := stronger.Verify("password123", encoded)
+		if err != nil || !needsRehash {
+			t.Errorf("Verify() = (%v, %v), want (true, nil)", needsRehash, err)
+		}
+	})
+
+	t.Run("異常系：壊れたハッシュ", func(t *testing.T) {
+		for _, broken := range []string{"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$aGFzaA", "$argon2id$v=19$x", "plain"} {
+			if _, err := hasher.Verify("password123", broken); !errors.Is(err, ErrUnknownFormat) {
+				t.Errorf("Verify(%q) error = %v, want ErrUnknownFormat", broken, err)
+			}
+		}
+	})
+}
+
+func TestHasher_VerifyBcrypt(t *testing.T) {
+	hasher := NewHasher(testParams)
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+
+	needsRehash, err := hasher.Verify("password123", string(legacy))
+	if err != nil || !needsRehash {
+		t.Errorf("Verify() = (%v, %v), want (true, nil)", needsRehash, err)
+	}
+
+	if _, err := hasher.Verify("wrong", string(legacy)); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify() error = %v, want ErrMismatch", err)
+	}
+}
+
+func TestHasher_Observer(t *testing.T) {
+	hasher := NewHasher(testParams)
+	observer := &recordingObserver{}
+	hasher.SetObserver(observer)
+
+	encoded, _ := hasher.Hash("password123")
+	hasher.Verify("password123", encoded)
+
+	want := []string{"password_" + OpHashArgon2id, "password_" + OpVerifyArgon2id}
+	if strings.Join(observer.names, ",") != strings.Join(want, ",") {
+		t.Errorf("observed = %v, want %v", observer.names, want)
+	}
+}
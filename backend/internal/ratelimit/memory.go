@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryCleanupInterval は 満杯に戻ったバケットを削除する間隔です
+const memoryCleanupInterval = 10 * time.Minute
+
+// MemoryLimiter は プロセス内のメモリにバケットを保持する Limiter です
+// 複数のプロセスで動かす場合、制限はプロセスごとにかかる（全体では最大でプロセス数倍まで受け付ける）
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	now     func() time.Time
+	done    chan struct{}
+	once    sync.Once
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // この時刻を過ぎるとバケットは満杯に戻る（削除しても結果は変わらない）
+}
+
+// コンパイル時にLimiterインターフェースを満たすことを確認
+var _ Limiter = (*MemoryLimiter)(nil)
+
+// NewMemoryLimiter は 新しい MemoryLimiter インスタンスを作成します
+// 満杯に戻ったバケットを定期的に削除するゴルーチンを起動します（Close で停止する）
+func NewMemoryLimiter() *MemoryLimiter {
+	l := &MemoryLimiter{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+		done:    make(chan struct{}),
+	}
+	go l.cleanupFull()
+	return l
+}
+
+// Allow は キーのバケットからトークンを1つ取り出します
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	interval := limit.interval()
+	burst := float64(limit.Burst)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: burst, updated: now}
+		l.buckets[key] = bucket
+	}
+	if elapsed := now.Sub(bucket.updated); elapsed > 0 {
+		bucket.tokens += float64(elapsed) / float64(interval)
+		if bucket.tokens > burst {
+			bucket.tokens = burst
+		}
+	}
+	bucket.updated = now
+
+	var result Result
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result = Result{Allowed: true, Remaining: int(bucket.tokens)}
+	} else {
+		result = Result{RetryAfter: time.Duration((1 - bucket.tokens) * float64(interval))}
+	}
+	bucket.full = now.Add(time.Duration((burst - bucket.tokens) * float64(interval)))
+	return result, nil
+}
+
+// Close は バケットを削除するゴルーチンを停止します
+func (l *MemoryLimiter) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+// cleanupFull は 満杯に戻ったバケットを定期的に削除します（アクセスのなくなったキーでメモリを使い続けない）
+func (l *MemoryLimiter) cleanupFull() {
+	ticker := time.NewTicker(memoryCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+		}
+		l.prune(l.now())
+	}
+}
+
+// prune は now の時点で満杯に戻っているバケットを削除します
+func (l *MemoryLimiter) prune(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, bucket := range l.buckets {
+		if !now.Before(bucket.full) {
+			delete(l.buckets, key)
+		}
+	}
+}
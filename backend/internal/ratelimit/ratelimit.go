@@ -0,0 +1,41 @@
+// Package ratelimit は キー（IP アドレス・ユーザー）ごとにリクエストの頻度を制限するトークンバケットを提供します
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit は トークンバケットの設定です
+// Burst 個まで連続して受け付け、以降は1分あたり PerMinute 個の割合で受け付けられる数が回復する
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// Enabled は 制限する設定かどうかを返します（どちらかが 0 以下の場合は制限しない）
+func (l Limit) Enabled() bool {
+	return l.PerMinute > 0 && l.Burst > 0
+}
+
+// interval は トークンが1つ回復するまでの時間です
+func (l Limit) interval() time.Duration {
+	return time.Minute / time.Duration(l.PerMinute)
+}
+
+// Result は 1回のリクエストの判定結果です
+type Result struct {
+	Allowed    bool
+	Remaining  int           // 続けて受け付けられる残りの数
+	RetryAfter time.Duration // 拒否した場合、次に受け付けられるまでの時間
+}
+
+// Limiter は キーごとのトークンバケットです
+// 実装: MemoryLimiter（プロセス内のメモリ）、RedisLimiter（Redis、複数のプロセスで共有する）
+type Limiter interface {
+	// Allow は キーのバケットからトークンを1つ取り出し、受け付けるかどうかを返します
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+
+	// Close は 使っている接続・ゴルーチンを解放します
+	Close() error
+}
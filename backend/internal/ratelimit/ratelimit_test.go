@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestMemoryLimiterAllow - トークンバケットの取り出しと回復のテスト
+func TestMemoryLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{PerMinute: 60, Burst: 3} // 1秒に1つ回復する
+
+	t.Run("正常系：バケットの大きさまで連続して受け付け、超えると拒否する", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		l := NewMemoryLimiter()
+		defer l.Close()
+		l.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			result, _ := l.Allow(ctx, "ip:1.2.3.4", limit)
+			if !result.Allowed || result.Remaining != 2-i {
+				t.Fatalf("request %d = %+v, want allowed with %d remaining", i, result, 2-i)
+			}
+		}
+		result, _ := l.Allow(ctx, "ip:1.2.3.4", limit)
+		if result.Allowed || result.RetryAfter != time.Second {
+			t.Errorf("4th request = %+v, want denied with RetryAfter 1s", result)
+		}
+
+		// 別のキーは別のバケット
+		if result, _ := l.Allow(ctx, "ip:5.6.7.8", limit); !result.Allowed {
+			t.Errorf("other key = %+v, want allowed", result)
+		}
+	})
+
+	t.Run("正常系：時間が経つと回復し、バケットの大きさを超えない", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		l := NewMemoryLimiter()
+		defer l.Close()
+		l.now = func() time.Time { return now }
+
+		for i := 0; i < 4; i++ {
+			l.Allow(ctx, "user:1", limit)
+		}
+		now = now.Add(1500 * time.Millisecond)
+		if result, _ := l.Allow(ctx, "user:1", limit); !result.Allowed {
+			t.Errorf("after 1.5s = %+v, want allowed", result)
+		}
+		result, _ := l.Allow(ctx, "user:1", limit)
+		if result.Allowed || result.RetryAfter != 500*time.Millisecond {
+			t.Errorf("second request after 1.5s = %+v, want denied with RetryAfter 500ms", result)
+		}
+
+		now = now.Add(time.Hour)
+		if result, _ := l.Allow(ctx, "user:1", limit); !result.Allowed || result.Remaining != 2 {
+			t.Errorf("after 1h = %+v, want allowed with 2 remaining", result)
+		}
+	})
+
+	t.Run("正常系：無効な設定では制限しない", func(t *testing.T) {
+		l := NewMemoryLimiter()
+		defer l.Close()
+
+		for i := 0; i < 10; i++ {
+			if result, _ := l.Allow(ctx, "ip:1.2.3.4", Limit{}); !result.Allowed {
+				t.Fatalf("request %d = %+v, want allowed", i, result)
+			}
+		}
+	})
+}
+
+// TestMemoryLimiterPrune - 満杯に戻ったバケットの削除のテスト
+func TestMemoryLimiterPrune(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewMemoryLimiter()
+	defer l.Close()
+	l.now = func() time.Time { return now }
+
+	limit := Limit{PerMinute: 60, Burst: 3}
+	l.Allow(context.Background(), "ip:1.2.3.4", limit)
+
+	l.prune(now.Add(500 * time.Millisecond))
+	if len(l.buckets) != 1 {
+		t.Fatalf("buckets = %d before refill, want 1", len(l.buckets))
+	}
+	l.prune(now.Add(time.Second))
+	if len(l.buckets) != 0 {
+		t.Errorf("buckets = %d after refill, want 0", len(l.buckets))
+	}
+}
+
+// TestParseScriptResult - Redis のスクリプトの戻り値の読み取りのテスト
+func TestParseScriptResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    Result
+		wantErr bool
+	}{
+		{name: "正常系：受け付けた", reply: "1:9:0", want: Result{Allowed: true, Remaining: 9}},
+		{name: "正常系：拒否した", reply: "0:0:1500", want: Result{RetryAfter: 1500 * time.Millisecond}},
+		{name: "異常系：形式が不正", reply: "OK", wantErr: true},
+		{name: "異常系：数値でない", reply: "1:x:0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseScriptResult(tt.reply)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseScriptResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("parseScriptResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"simple-notion-backend/internal/cache"
+)
+
+// redisKeyPrefix は Redis に保存するバケットのキーの接頭辞です
+const redisKeyPrefix = "ratelimit:"
+
+// tokenBucketScript は バケットの補充と取り出しを Redis 上で不可分に行うスクリプトです
+// 時刻は Redis の TIME を使う（プロセスごとの時計のずれの影響を受けない）
+// ARGV[1]: トークンが1つ回復するまでの時間（ミリ秒）、ARGV[2]: バケットの大きさ
+// 戻り値: "受け付けたか(1/0):残りの数:次に受け付けられるまでの時間（ミリ秒）"
+const tokenBucketScript = `
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+if now > ts then
+  tokens = math.min(burst, tokens + (now - ts) / interval)
+end
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) * interval)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * interval) + 1000)
+return allowed .. ':' .. math.floor(tokens) .. ':' .. wait
+`
+
+// RedisLimiter は Redis にバケットを保存する Limiter です
+// 同じ Redis を使う全てのプロセスで制限を共有する
+type RedisLimiter struct {
+	redis *cache.RedisCache
+}
+
+// コンパイル時にLimiterインターフェースを満たすことを確認
+var _ Limiter = (*RedisLimiter)(nil)
+
+// NewRedisLimiter は 新しい RedisLimiter インスタンスを作成します
+// redisURL: cache.NewRedisCache と同じ形式（リクエストごとに使うため、キャッシュとは別の接続を作る）
+func NewRedisLimiter(redisURL string) (*RedisLimiter, error) {
+	redis, err := cache.NewRedisCache(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisLimiter{redis: redis}, nil
+}
+
+// Allow は キーのバケットからトークンを1つ取り出します
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	intervalMs := float64(limit.interval()) / float64(time.Millisecond)
+	reply, err := l.redis.Eval(ctx, tokenBucketScript, []string{redisKeyPrefix + key},
+		strconv.FormatFloat(intervalMs, 'f', -1, 64), strconv.Itoa(limit.Burst))
+	if err != nil {
+		return Result{}, err
+	}
+	return parseScriptResult(reply)
+}
+
+// Close は Redis との接続を閉じます
+func (l *RedisLimiter) Close() error {
+	return l.redis.Close()
+}
+
+// parseScriptResult は tokenBucketScript の戻り値を読み取ります
+func parseScriptResult(reply string) (Result, error) {
+	parts := strings.Split(reply, ":")
+	if len(parts) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %q", reply)
+	}
+	remaining, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %q: %w", reply, err)
+	}
+	waitMs, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %q: %w", reply, err)
+	}
+	return Result{
+		Allowed:    parts[0] == "1",
+		Remaining:  remaining,
+		RetryAfter: time.Duration(waitMs) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// BlockCommentRepository - ブロックコメント操作専用リポジトリ
+type BlockCommentRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewBlockCommentRepository - BlockCommentRepositoryを初期化
+func NewBlockCommentRepository(db *sql.DB) (*BlockCommentRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &BlockCommentRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateComment - コメントを作成
+func (r *BlockCommentRepository) CreateComment(comment *models.BlockComment) error {
+	query, err := r.queries.Get("CreateBlockComment")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, comment.DocumentID, comment.BlockID, comment.UserID, comment.Body).Scan(
+		&comment.ID, &comment.CreatedAt, &comment.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create block comment: %w", err)
+	}
+
+	return nil
+}
+
+// ListComments - 文書のコメントをブロック・作成日時順に取得
+func (r *BlockCommentRepository) ListComments(docID int, filter models.BlockCommentFilter) ([]models.BlockComment, error) {
+	query, err := r.queries.Get("ListBlockComments")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, docID, filter.BlockID, filter.Resolved)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := make([]models.BlockComment, 0)
+	for rows.Next() {
+		comment, err := scanBlockComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *comment)
+	}
+
+	return comments, rows.Err()
+}
+
+// GetComment - 文書内の単一コメントを取得
+func (r *BlockCommentRepository) GetComment(docID, commentID int) (*models.BlockComment, error) {
+	query, err := r.queries.Get("GetBlockComment")
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := scanBlockComment(r.db.QueryRow(query, commentID, docID))
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("block comment id=%d document=%d", commentID, docID))
+	}
+	return comment, nil
+}
+
+// UpdateCommentBody - コメント本文を更新（投稿者のみ）
+func (r *BlockCommentRepository) UpdateCommentBody(docID, commentID, userID int, body string) error {
+	query, err := r.queries.Get("UpdateBlockCommentBody")
+	if err != nil {
+		return err
+	}
+	return r.execComment(query, commentID, body, commentID, docID, userID)
+}
+
+// SetCommentResolved - コメントを解決済み／未解決にする
+func (r *BlockCommentRepository) SetCommentResolved(docID, commentID, userID int, resolved bool) error {
+	if resolved {
+		query, err := r.queries.Get("ResolveBlockComment")
+		if err != nil {
+			return err
+		}
+		return r.execComment(query, commentID, commentID, docID, userID)
+	}
+
+	query, err := r.queries.Get("ReopenBlockComment")
+	if err != nil {
+		return err
+	}
+	return r.execComment(query, commentID, commentID, docID)
+}
+
+// DeleteComment - コメントを削除（投稿者のみ）
+func (r *BlockCommentRepository) DeleteComment(docID, commentID, userID int) error {
+	query, err := r.queries.Get("DeleteBlockComment")
+	if err != nil {
+		return err
+	}
+	return r.execComment(query, commentID, commentID, docID, userID)
+}
+
+// execComment - 単一コメントの更新・削除を実行し、対象がない場合は ErrNotFound を返す
+func (r *BlockCommentRepository) execComment(query string, commentID int, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update block comment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("block comment id=%d or access denied: %w", commentID, apierror.ErrNotFound)
+	}
+
+	return nil
+}
+
+// rowScanner - *sql.Row と *sql.Rows の共通部分
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanBlockComment - コメント1行を読み取る
+func scanBlockComment(row rowScanner) (*models.BlockComment, error) {
+	var comment models.BlockComment
+	err := row.Scan(&comment.ID, &comment.DocumentID, &comment.BlockID, &comment.UserID, &comment.Body,
+		&comment.ResolvedAt, &comment.ResolvedBy, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	comment.Resolved = comment.ResolvedAt != nil
+	return &comment, nil
+}
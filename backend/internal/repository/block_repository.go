@@ -1,9 +1,17 @@
 package repository
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 
+	"github.com/lib/pq"
+
+	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/models"
 )
 
@@ -39,11 +47,184 @@ func (r *BlockRepository) GetBlocksByDocumentID(docID int) ([]models.Block, erro
 	}
 	defer rows.Close()
 
+	blocks, err := scanBlocks(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.resolveSyncedBlocks(docID, blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// GetBlocksByDocumentIDs - 複数の文書のブロック一覧を1回の問い合わせで取得（文書ID → ブロック一覧）
+// ブロックのない文書は含まない。synced ブロックの同期元は文書ごとに解決する
+func (r *BlockRepository) GetBlocksByDocumentIDs(docIDs []int) (map[int][]models.Block, error) {
+	result := make(map[int][]models.Block, len(docIDs))
+	if len(docIDs) == 0 {
+		return result, nil
+	}
+
+	query, err := r.queries.Get("GetBlocksByDocumentIDs")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, pq.Array(docIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks, err := scanBlocks(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		result[block.DocumentID] = append(result[block.DocumentID], block)
+	}
+
+	for docID, docBlocks := range result {
+		if err := r.resolveSyncedBlocks(docID, docBlocks); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// resolveSyncedBlocks - synced ブロックに同期元ブロックの現在の内容を設定
+// 同期元が削除された・他ユーザーのもの・ゴミ箱内の場合と、登録したユーザーが同期元の文書にアクセスできなくなった場合は
+// Synced を nil のままにする（プレースホルダーとして表示される）
+func (r *BlockRepository) resolveSyncedBlocks(docID int, blocks []models.Block) error {
+	var syncIDs []int64
+	for _, block := range blocks {
+		if block.Type != "synced" {
+			continue
+		}
+		if synced, err := models.DecodeSyncedContent(block.Content); err == nil && synced.SyncID > 0 {
+			syncIDs = append(syncIDs, int64(synced.SyncID))
+		}
+	}
+	if len(syncIDs) == 0 {
+		return nil
+	}
+
+	query, err := r.queries.Get("ResolveSyncedBlocks")
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.Query(query, pq.Array(syncIDs), docID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve synced blocks: %w", err)
+	}
+	defer rows.Close()
+
+	sources := make(map[int]*models.SyncedSource, len(syncIDs))
+	for rows.Next() {
+		var source models.SyncedSource
+		if err := rows.Scan(&source.SyncID, &source.DocumentID, &source.BlockID, &source.Type, &source.Content); err != nil {
+			return err
+		}
+		sources[source.SyncID] = &source
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range blocks {
+		if blocks[i].Type != "synced" {
+			continue
+		}
+		if synced, err := models.DecodeSyncedContent(blocks[i].Content); err == nil {
+			blocks[i].Synced = sources[synced.SyncID]
+		}
+	}
+	return nil
+}
+
+// CreateSyncedBlock - ブロックを同期元として登録（登録済みの場合は既存の登録を返す）
+func (r *BlockRepository) CreateSyncedBlock(synced *models.SyncedBlock) error {
+	query, err := r.queries.Get("CreateSyncedBlock")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, synced.UserID, synced.SourceDocumentID, synced.SourceBlockID).Scan(
+		&synced.ID, &synced.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create synced block: %w", err)
+	}
+	return nil
+}
+
+// FindBlocksByType - ユーザーの文書（ごみ箱を除く）から指定タイプのブロックを文書・位置順に取得
+func (r *BlockRepository) FindBlocksByType(userID int, blockType string) ([]models.Block, error) {
+	query, err := r.queries.Get("FindBlocksByType")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, userID, blockType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blocks by type: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBlocks(rows)
+}
+
+// FindBlocksByFileKey - content の fileKey が一致する画像・ファイル・音声ブロックを取得
+// 添付ファイルがどのブロックから参照されているかの追跡に使う
+func (r *BlockRepository) FindBlocksByFileKey(fileKey string) ([]models.Block, error) {
+	query, err := r.queries.Get("FindBlocksByFileKey")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, fileKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blocks by file key: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBlocks(rows)
+}
+
+// CountBlocksByType - ブロックタイプごとの件数を集計（全ユーザー分）
+func (r *BlockRepository) CountBlocksByType() ([]models.BlockTypeCount, error) {
+	query, err := r.queries.Get("CountBlocksByType")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count blocks by type: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]models.BlockTypeCount, 0)
+	for rows.Next() {
+		var c models.BlockTypeCount
+		if err := rows.Scan(&c.Type, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+// scanBlocks - ブロック一覧の結果セットを読み込む
+func scanBlocks(rows *sql.Rows) ([]models.Block, error) {
 	var blocks []models.Block
 	for rows.Next() {
 		var block models.Block
 
-		err := rows.Scan(&block.ID, &block.DocumentID, &block.Type,
+		err := rows.Scan(&block.ID, &block.DocumentID, &block.ParentBlockID, &block.Type,
 			&block.Content, &block.Position, &block.CreatedAt)
 		if err != nil {
 			return nil, err
@@ -52,18 +233,117 @@ func (r *BlockRepository) GetBlocksByDocumentID(docID int) ([]models.Block, erro
 		blocks = append(blocks, block)
 	}
 
-	return blocks, nil
+	return blocks, rows.Err()
 }
 
 // UpdateBlocks - 文書のブロック情報を一括更新（既存削除→新規挿入）
-func (r *BlockRepository) UpdateBlocks(docID int, blocks []models.Block) error {
+// 親ブロックは一覧内のブロックの ID で指定し、挿入後の新しい ID へ置き換える
+// 既存のブロックの ID で保存したブロックは、種類・内容が変わっていれば editorID の変更として履歴に記録する（0 は編集者不明）
+func (r *BlockRepository) UpdateBlocks(ctx context.Context, docID, editorID int, blocks []models.Block) error {
 	// トランザクション開始
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.replaceBlocks(tx, docID, editorID, blocks); err != nil {
+		return err
+	}
+
+	// トランザクションコミット
+	return tx.Commit()
+}
+
+// UpdateDocumentIfUnchanged - 文書の行をロックし、タイトル・本文・ブロックが expected から変わっていない場合のみ
+// タイトル・本文を更新してブロックを一括更新する（3方向マージの結果の保存）
+// マージ後に他の保存で変わっていた場合は ErrConflict を返し、何も変更しない（呼び出し側でマージし直す）
+func (r *BlockRepository) UpdateDocumentIfUnchanged(ctx context.Context, docID, editorID int, title, content string, blocks []models.Block, expected models.DocumentContent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	lockQuery, err := r.queries.Get("LockDocumentContent")
+	if err != nil {
+		return err
+	}
+	var currentTitle, currentContent string
+	if err := tx.QueryRow(lockQuery, docID).Scan(&currentTitle, &currentContent); err != nil {
+		return apierror.WrapNotFound(err, fmt.Sprintf("document id=%d", docID))
+	}
+
+	// 行ロックの取得後に読むため、先に保存した他のトランザクションのブロックが見える
+	blocksQuery, err := r.queries.Get("GetBlocksByDocumentID")
+	if err != nil {
+		return err
+	}
+	rows, err := tx.Query(blocksQuery, docID)
+	if err != nil {
+		return fmt.Errorf("failed to get blocks: %w", err)
+	}
+	currentBlocks, err := scanBlocks(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	if currentTitle != expected.Title || currentContent != expected.Content || !sameBlockContents(currentBlocks, expected.Blocks) {
+		return fmt.Errorf("document id=%d was changed after merge: %w", docID, apierror.ErrConflict)
+	}
+
+	updateQuery, err := r.queries.Get("UpdateDocument")
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(updateQuery, title, content, docID, editorID); err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	if err := r.replaceBlocks(tx, docID, editorID, blocks); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// sameBlockContents - 2つのブロック列の種類・内容（JSON を正規化して比較）が順に一致するか
+func sameBlockContents(a, b []models.Block) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || !sameJSON(a[i].Content, b[i].Content) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameJSON - 空白を除いた JSON が一致するか（解析できない場合はそのまま比較する）
+func sameJSON(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+// replaceBlocks - トランザクション内で文書のブロックを作り直す（UpdateBlocks の本体）
+func (r *BlockRepository) replaceBlocks(tx *sql.Tx, docID, editorID int, blocks []models.Block) error {
+	// ブロックの作り直しで消えるトグルの開閉状態を退避
+	collapseStates, err := r.getCollapseStates(tx, docID)
+	if err != nil {
+		return err
+	}
+
+	// 変更履歴の比較用に、作り直す前の種類と内容を退避
+	previous, err := r.getBlockContents(tx, docID)
+	if err != nil {
+		return err
+	}
+
 	// 既存ブロックを削除
 	deleteQuery, err := r.queries.Get("DeleteBlocksByDocumentID")
 	if err != nil {
@@ -75,34 +355,764 @@ func (r *BlockRepository) UpdateBlocks(docID int, blocks []models.Block) error {
 		return fmt.Errorf("failed to delete existing blocks: %w", err)
 	}
 
-	// 新しいブロックを挿入
-	insertQuery, err := r.queries.Get("BulkInsertBlocks")
+	// 並び順キーは Position の順に振り直す（作り直すたびにキーの長さも元に戻る）
+	order := make([]int, len(blocks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return blocks[order[i]].Position < blocks[order[j]].Position })
+	keys, err := models.SortKeysBetween("", "", len(blocks))
+	if err != nil {
+		return fmt.Errorf("failed to generate sort keys: %w", err)
+	}
+	sortKeys := make([]string, len(blocks))
+	for rank, i := range order {
+		sortKeys[i] = keys[rank]
+	}
+
+	// オブジェクトの content は JSONB の演算子で検索できるよう正規化して保存
+	normalized := make([]models.Block, len(blocks))
+	for i, block := range blocks {
+		normalized[i] = models.Block{DocumentID: docID, Type: block.Type,
+			Content: models.NormalizeBlockContent(block.Type, block.Content)}
+	}
+
+	// 新しいブロックを1回の INSERT でまとめて挿入
+	insertedIDs, err := r.bulkInsertBlocks(tx, docID, normalized, sortKeys)
 	if err != nil {
 		return err
 	}
 
-	for _, block := range blocks {
-		// json.RawMessageをそのままバイト列として使用
-		_, err = tx.Exec(insertQuery, docID, block.Type, block.Content, block.Position)
+	// リクエスト上の ID → 挿入後の ID
+	newIDs := make(map[int]int, len(blocks))
+	for i, block := range blocks {
+		normalized[i].ID = insertedIDs[i]
+		if block.ID != 0 {
+			newIDs[block.ID] = insertedIDs[i]
+		}
+	}
+
+	// 親は一覧内のどこにあってもよいため、全件の挿入後に新しい ID で親子関係をまとめて設定する
+	var childIDs, parentIDs []int64
+	for i, block := range blocks {
+		if block.ParentBlockID == nil {
+			continue
+		}
+		parentID, ok := newIDs[*block.ParentBlockID]
+		if !ok {
+			return fmt.Errorf("block %d: parent block id=%d is not in the list", i, *block.ParentBlockID)
+		}
+		childIDs = append(childIDs, int64(insertedIDs[i]))
+		parentIDs = append(parentIDs, int64(parentID))
+	}
+	if len(childIDs) > 0 {
+		parentQuery, err := r.queries.Get("SetBlockParents")
 		if err != nil {
-			return fmt.Errorf("failed to insert block: %w", err)
+			return err
+		}
+		if _, err := tx.Exec(parentQuery, pq.Array(childIDs), pq.Array(parentIDs)); err != nil {
+			return fmt.Errorf("failed to set parent blocks: %w", err)
 		}
 	}
 
-	// トランザクションコミット
-	return tx.Commit()
+	// ブロックへのコメントと同期元の登録を新しい ID へ付け替える（一覧から消えたブロックへの参照は元の ID のまま残る）
+	if err := r.remapBlockReferences(tx, docID, newIDs); err != nil {
+		return err
+	}
+
+	// 一覧に残ったブロックの変更を新しい ID で履歴に記録
+	var before, after []models.Block
+	for i, block := range blocks {
+		old, ok := previous[block.ID]
+		if block.ID == 0 || !ok {
+			continue
+		}
+		before = append(before, old)
+		after = append(after, normalized[i])
+	}
+	if err := r.recordEdits(tx, docID, editorID, before, after); err != nil {
+		return err
+	}
+
+	// 一覧に残ったブロックの開閉状態を新しい ID でまとめて復元
+	var collapsedUsers, collapsedBlocks []int64
+	for _, state := range collapseStates {
+		if newID, ok := newIDs[state.blockID]; ok {
+			collapsedUsers = append(collapsedUsers, int64(state.userID))
+			collapsedBlocks = append(collapsedBlocks, int64(newID))
+		}
+	}
+	if len(collapsedUsers) > 0 {
+		collapseQuery, err := r.queries.Get("RestoreCollapseStates")
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(collapseQuery, pq.Array(collapsedUsers), pq.Array(collapsedBlocks)); err != nil {
+			return fmt.Errorf("failed to restore collapse states: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// bulkInsertBlocks - トランザクション内で親を持たないブロックを1回の INSERT でまとめて挿入し、blocks と同じ順に ID を返す
+// sortKeys は一覧内で重複しない並び順キーで、RETURNING の行を元のブロックに対応付けるのにも使う
+func (r *BlockRepository) bulkInsertBlocks(tx *sql.Tx, docID int, blocks []models.Block, sortKeys []string) ([]int, error) {
+	ids := make([]int, len(blocks))
+	if len(blocks) == 0 {
+		return ids, nil
+	}
+
+	query, err := r.queries.Get("BulkInsertBlocks")
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]string, len(blocks))
+	contents := make([]string, len(blocks))
+	for i, block := range blocks {
+		types[i] = block.Type
+		contents[i] = string(block.Content)
+	}
+
+	rows, err := tx.Query(query, docID, pq.Array(types), pq.Array(contents), pq.Array(sortKeys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert blocks: %w", err)
+	}
+	defer rows.Close()
+
+	idByKey := make(map[string]int, len(blocks))
+	for rows.Next() {
+		var id int
+		var sortKey string
+		if err := rows.Scan(&id, &sortKey); err != nil {
+			return nil, err
+		}
+		idByKey[sortKey] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to insert blocks: %w", err)
+	}
+
+	for i, sortKey := range sortKeys {
+		id, ok := idByKey[sortKey]
+		if !ok {
+			return nil, fmt.Errorf("inserted block for sort key %q is missing", sortKey)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// remapBlockReferences - トランザクション内でブロックIDを参照する表（コメント・同期元・変更履歴）を作り直し後の ID に付け替える
+func (r *BlockRepository) remapBlockReferences(tx *sql.Tx, docID int, newIDs map[int]int) error {
+	if len(newIDs) == 0 {
+		return nil
+	}
+
+	oldIDs := make([]int64, 0, len(newIDs))
+	replacedIDs := make([]int64, 0, len(newIDs))
+	for oldID, newID := range newIDs {
+		oldIDs = append(oldIDs, int64(oldID))
+		replacedIDs = append(replacedIDs, int64(newID))
+	}
+
+	for _, name := range []string{"RemapBlockComments", "RemapSyncedBlockSources", "RemapBlockEditEvents"} {
+		query, err := r.queries.Get(name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(query, docID, pq.Array(oldIDs), pq.Array(replacedIDs)); err != nil {
+			return fmt.Errorf("failed to remap block references (%s): %w", name, err)
+		}
+	}
+	return nil
+}
+
+// getBlockContents - トランザクション内で文書のブロックの種類と内容を ID ごとに取得
+func (r *BlockRepository) getBlockContents(tx *sql.Tx, docID int) (map[int]models.Block, error) {
+	query, err := r.queries.Get("GetBlockContentsByDocumentID")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(query, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block contents: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := make(map[int]models.Block)
+	for rows.Next() {
+		block := models.Block{DocumentID: docID}
+		if err := rows.Scan(&block.ID, &block.Type, &block.Content); err != nil {
+			return nil, err
+		}
+		blocks[block.ID] = block
+	}
+	return blocks, rows.Err()
+}
+
+// recordEdits - トランザクション内でブロックの変更をまとめて履歴に記録（種類・内容が同じものは記録しない）
+// before[i] から after[i] への変更を after[i].ID のブロックの履歴として記録する
+func (r *BlockRepository) recordEdits(tx *sql.Tx, docID, editorID int, before, after []models.Block) error {
+	if len(after) == 0 {
+		return nil
+	}
+
+	query, err := r.queries.Get("CreateBlockEditEvents")
+	if err != nil {
+		return err
+	}
+
+	blockIDs := make([]int64, len(after))
+	oldTypes := make([]string, len(after))
+	newTypes := make([]string, len(after))
+	oldContents := make([]string, len(after))
+	newContents := make([]string, len(after))
+	for i := range after {
+		blockIDs[i] = int64(after[i].ID)
+		oldTypes[i], newTypes[i] = before[i].Type, after[i].Type
+		oldContents[i], newContents[i] = string(before[i].Content), string(after[i].Content)
+	}
+
+	var editor interface{}
+	if editorID != 0 {
+		editor = editorID
+	}
+	_, err = tx.Exec(query, docID, editor, pq.Array(blockIDs),
+		pq.Array(oldTypes), pq.Array(newTypes), pq.Array(oldContents), pq.Array(newContents))
+	if err != nil {
+		return fmt.Errorf("failed to record block edits: %w", err)
+	}
+	return nil
+}
+
+// ListBlockHistory - ブロックの変更履歴を新しい順に取得（削除済みのブロックの履歴も返す）
+func (r *BlockRepository) ListBlockHistory(docID, blockID int) ([]models.BlockEditEvent, error) {
+	query, err := r.queries.Get("ListBlockEditEvents")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, docID, blockID, models.MaxBlockHistoryEvents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block history: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]models.BlockEditEvent, 0)
+	for rows.Next() {
+		var e models.BlockEditEvent
+		err := rows.Scan(&e.ID, &e.DocumentID, &e.BlockID, &e.EditorID, &e.OldType, &e.NewType,
+			&e.OldContent, &e.NewContent, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// collapseState - ユーザーごとのトグルの開閉状態（折りたたまれているブロック）
+type collapseState struct {
+	userID  int
+	blockID int
+}
+
+// getCollapseStates - トランザクション内で文書の全ユーザー分の開閉状態を取得
+func (r *BlockRepository) getCollapseStates(tx *sql.Tx, docID int) ([]collapseState, error) {
+	query, err := r.queries.Get("GetCollapseStatesByDocumentID")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(query, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collapse states: %w", err)
+	}
+	defer rows.Close()
+
+	var states []collapseState
+	for rows.Next() {
+		var s collapseState
+		if err := rows.Scan(&s.userID, &s.blockID); err != nil {
+			return nil, err
+		}
+		states = append(states, s)
+	}
+	return states, rows.Err()
+}
+
+// GetCollapsedBlockIDs - ユーザーが折りたたんでいる文書内のブロックIDを取得
+func (r *BlockRepository) GetCollapsedBlockIDs(userID, docID int) ([]int, error) {
+	query, err := r.queries.Get("GetCollapsedBlockIDs")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, userID, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collapsed blocks: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetBlockCollapsed - ユーザーごとのブロックの開閉状態を保存
+func (r *BlockRepository) SetBlockCollapsed(userID, blockID int, collapsed bool) error {
+	name := "ExpandBlock"
+	if collapsed {
+		name = "CollapseBlock"
+	}
+	query, err := r.queries.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(query, userID, blockID); err != nil {
+		return fmt.Errorf("failed to save collapse state: %w", err)
+	}
+	return nil
+}
+
+// GetBlock - 文書内の単一ブロックを取得
+func (r *BlockRepository) GetBlock(docID, blockID int) (*models.Block, error) {
+	return r.getBlock(r.db, docID, blockID)
+}
+
+// GetBlockByID - ブロックIDのみで単一ブロックを取得（文書の所有者は確認しない）
+func (r *BlockRepository) GetBlockByID(blockID int) (*models.Block, error) {
+	query, err := r.queries.Get("GetBlockByID")
+	if err != nil {
+		return nil, err
+	}
+
+	var block models.Block
+	err = r.db.QueryRow(query, blockID).Scan(&block.ID, &block.DocumentID, &block.ParentBlockID, &block.Type,
+		&block.Content, &block.Position, &block.CreatedAt)
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("block id=%d", blockID))
+	}
+
+	return &block, nil
 }
 
-// CreateBlock - 単一ブロックを作成
+// CreateBlock - 単一ブロックを指定位置に挿入（前後のブロックの間の並び順キーを振るため、他の行は書き換えない）
+// 位置が範囲外（負の値を含む）の場合は末尾に追加する
 func (r *BlockRepository) CreateBlock(block *models.Block) error {
-	// json.RawMessageをそのままバイト列として使用
-	insertQuery, err := r.queries.Get("BulkInsertBlocks")
+	tx, err := r.db.Begin()
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.insertBlock(tx, block); err != nil {
 		return err
 	}
 
-	_, err = r.db.Exec(insertQuery, block.DocumentID, block.Type, block.Content, block.Position)
-	return err
+	return tx.Commit()
+}
+
+// CopyBlocks - 他の文書などから複製したブロックを、指定位置から連続して挿入
+// ParentBlockID が nil のブロックは parentID の下に置き、それ以外は一覧内の先に挿入したブロック（複製元の ID）を親として新しい ID へ付け替える
+// 位置が範囲外（負の値を含む）の場合は末尾に追加する
+func (r *BlockRepository) CopyBlocks(docID int, parentID *int, position int, blocks []models.Block) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	keys, position, err := r.sortKeysAt(tx, docID, position, 0, len(blocks))
+	if err != nil {
+		return err
+	}
+
+	// 複製元の ID → 挿入後の ID
+	newIDs := make(map[int]int, len(blocks))
+	for i := range blocks {
+		block := &blocks[i]
+		sourceID := block.ID
+
+		if block.ParentBlockID == nil {
+			block.ParentBlockID = parentID
+		} else {
+			newParentID, ok := newIDs[*block.ParentBlockID]
+			if !ok {
+				return fmt.Errorf("block %d: parent block id=%d is not copied before its child", i, *block.ParentBlockID)
+			}
+			block.ParentBlockID = &newParentID
+		}
+		block.DocumentID = docID
+		block.Position = position + i
+
+		if err := r.insertBlockWithKey(tx, block, keys[i]); err != nil {
+			return err
+		}
+		newIDs[sourceID] = block.ID
+	}
+
+	return tx.Commit()
+}
+
+// UpdateBlock - 単一ブロックの種類・内容・位置を更新し、種類・内容の変更を editorID の編集として履歴に記録
+// 位置が変わる場合は移動先の前後のブロックの間の並び順キーを振り直す
+func (r *BlockRepository) UpdateBlock(block *models.Block, editorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.updateBlock(tx, block, editorID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteBlock - 単一ブロックを子孫ごと削除
+func (r *BlockRepository) DeleteBlock(docID, blockID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.deleteBlock(tx, docID, blockID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ApplyOperations - 差分保存の操作列を1トランザクションで順に適用（update / move は editorID の編集として履歴に記録）
+// 対象ブロックが既に存在しない（他の編集で削除された）場合は ErrConflict を返し、全体をロールバックする
+func (r *BlockRepository) ApplyOperations(ctx context.Context, docID, editorID int, ops []models.BlockOperation) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, op := range ops {
+		if err := r.applyOperation(tx, docID, editorID, op); err != nil {
+			if errors.Is(err, apierror.ErrNotFound) {
+				return fmt.Errorf("operation %d (%s block id=%d): %w", i, op.Op, op.BlockID, apierror.ErrConflict)
+			}
+			return fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// applyOperation - トランザクション内で1操作を適用
+func (r *BlockRepository) applyOperation(tx *sql.Tx, docID, editorID int, op models.BlockOperation) error {
+	switch op.Op {
+	case models.BlockOpInsert:
+		block := &models.Block{DocumentID: docID, ParentBlockID: op.ParentBlockID, Type: op.Type, Content: op.Content, Position: -1}
+		if op.Position != nil {
+			block.Position = *op.Position
+		}
+		return r.insertBlock(tx, block)
+
+	case models.BlockOpUpdate, models.BlockOpMove:
+		block, err := r.getBlock(tx, docID, op.BlockID)
+		if err != nil {
+			return err
+		}
+		if op.Type != "" {
+			block.Type = op.Type
+		}
+		if op.Content != nil {
+			block.Content = op.Content
+		}
+		if op.Position != nil {
+			block.Position = *op.Position
+		}
+		return r.updateBlock(tx, block, editorID)
+
+	case models.BlockOpDelete:
+		return r.deleteBlock(tx, docID, op.BlockID)
+	}
+
+	return fmt.Errorf("unknown block operation %q", op.Op)
+}
+
+// rowQuerier - *sql.DB と *sql.Tx の共通部分
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// getBlock - 単一ブロックを取得（トランザクション内からも利用する）
+func (r *BlockRepository) getBlock(q rowQuerier, docID, blockID int) (*models.Block, error) {
+	query, err := r.queries.Get("GetBlock")
+	if err != nil {
+		return nil, err
+	}
+
+	var block models.Block
+	err = q.QueryRow(query, blockID, docID).Scan(&block.ID, &block.DocumentID, &block.ParentBlockID, &block.Type,
+		&block.Content, &block.Position, &block.CreatedAt)
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("block id=%d document=%d", blockID, docID))
+	}
+
+	return &block, nil
+}
+
+// insertBlock - トランザクション内でブロックを Position の位置に挿入（範囲外は末尾）
+func (r *BlockRepository) insertBlock(tx *sql.Tx, block *models.Block) error {
+	keys, position, err := r.sortKeysAt(tx, block.DocumentID, block.Position, 0, 1)
+	if err != nil {
+		return err
+	}
+	block.Position = position
+
+	return r.insertBlockWithKey(tx, block, keys[0])
+}
+
+// insertBlockWithKey - トランザクション内で並び順キーを指定してブロックを挿入
+func (r *BlockRepository) insertBlockWithKey(tx *sql.Tx, block *models.Block, sortKey string) error {
+	// 親ブロックは同じ文書内に存在する必要がある
+	if block.ParentBlockID != nil {
+		if _, err := r.getBlock(tx, block.DocumentID, *block.ParentBlockID); err != nil {
+			return err
+		}
+	}
+
+	insertQuery, err := r.queries.Get("CreateBlock")
+	if err != nil {
+		return err
+	}
+
+	block.Content = models.NormalizeBlockContent(block.Type, block.Content)
+	err = tx.QueryRow(insertQuery, block.DocumentID, block.ParentBlockID, block.Type, block.Content, sortKey).Scan(
+		&block.ID, &block.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert block: %w", err)
+	}
+
+	return nil
+}
+
+// updateBlock - トランザクション内でブロックを更新し、位置が変わる場合は並び順キーだけを振り直す
+// 種類・内容が変わった場合は変更履歴に記録する
+func (r *BlockRepository) updateBlock(tx *sql.Tx, block *models.Block, editorID int) error {
+	keyQuery, err := r.queries.Get("GetBlockSortKeyForUpdate")
+	if err != nil {
+		return err
+	}
+
+	var sortKey string
+	if err := tx.QueryRow(keyQuery, block.ID, block.DocumentID).Scan(&sortKey); err != nil {
+		return apierror.WrapNotFound(err, fmt.Sprintf("block id=%d document=%d", block.ID, block.DocumentID))
+	}
+
+	current, err := r.getBlock(tx, block.DocumentID, block.ID)
+	if err != nil {
+		return err
+	}
+	if block.Position != current.Position {
+		keys, position, err := r.sortKeysAt(tx, block.DocumentID, block.Position, block.ID, 1)
+		if err != nil {
+			return err
+		}
+		sortKey = keys[0]
+		block.Position = position
+	}
+
+	updateQuery, err := r.queries.Get("UpdateBlock")
+	if err != nil {
+		return err
+	}
+
+	block.Content = models.NormalizeBlockContent(block.Type, block.Content)
+	if _, err := tx.Exec(updateQuery, block.Type, block.Content, sortKey, block.ID, block.DocumentID); err != nil {
+		return fmt.Errorf("failed to update block: %w", err)
+	}
+
+	return r.recordEdits(tx, block.DocumentID, editorID, []models.Block{*current}, []models.Block{*block})
+}
+
+// deleteBlock - トランザクション内でブロックを子孫ごと削除
+// 位置は並び順キーから読み込み時に求めるため、残ったブロックは書き換えない
+func (r *BlockRepository) deleteBlock(tx *sql.Tx, docID, blockID int) error {
+	deleteQuery, err := r.queries.Get("DeleteBlock")
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(deleteQuery, blockID, docID)
+	if err != nil {
+		return fmt.Errorf("failed to delete block: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("block id=%d document=%d: %w", blockID, docID, apierror.ErrNotFound)
+	}
+	return nil
+}
+
+// sortKeysAt - 位置 position から連続して n 件のブロックを置くための並び順キーを求める
+// excludeID のブロック（移動するブロック自身）は数えない。位置が範囲外（負の値を含む）の場合は末尾とし、丸めた位置も返す
+// キーが作れない（同じキーが並んでいる）か長くなりすぎた場合は、文書全体のキーを振り直してから求め直す
+func (r *BlockRepository) sortKeysAt(tx *sql.Tx, docID, position, excludeID, n int) ([]string, int, error) {
+	count, err := r.countBlocks(tx, docID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if excludeID != 0 {
+		count--
+	}
+	if position < 0 || position > count {
+		position = count
+	}
+
+	for rebalanced := false; ; rebalanced = true {
+		before, after, err := r.neighbourSortKeys(tx, docID, position, excludeID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		keys, err := models.SortKeysBetween(before, after, n)
+		if err == nil && (rebalanced || longestKey(keys) <= models.MaxSortKeyLength) {
+			return keys, position, nil
+		}
+		if rebalanced {
+			return nil, 0, fmt.Errorf("failed to generate sort keys: %w", err)
+		}
+
+		if err := r.rebalanceSortKeys(tx, docID); err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+// neighbourSortKeys - 位置 position の直前と直後のブロックの並び順キーを取得（端の場合は空）
+func (r *BlockRepository) neighbourSortKeys(tx *sql.Tx, docID, position, excludeID int) (string, string, error) {
+	query, err := r.queries.Get("GetNeighbourSortKeys")
+	if err != nil {
+		return "", "", err
+	}
+
+	offset := position - 1
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := tx.Query(query, docID, offset, excludeID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get neighbour sort keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return "", "", err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return "", "", err
+	}
+
+	// 先頭に置く場合は取得した最初のキーが直後になる
+	if position == 0 {
+		keys = append([]string{""}, keys...)
+	}
+	before, after := "", ""
+	if len(keys) > 0 {
+		before = keys[0]
+	}
+	if len(keys) > 1 {
+		after = keys[1]
+	}
+	return before, after, nil
+}
+
+// rebalanceSortKeys - 文書のブロックの並び順キーを、順序を保ったまま短いキーに振り直す
+func (r *BlockRepository) rebalanceSortKeys(tx *sql.Tx, docID int) error {
+	listQuery, err := r.queries.Get("ListBlockIDsForRebalance")
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(listQuery, docID)
+	if err != nil {
+		return fmt.Errorf("failed to list blocks for rebalance: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	keys, err := models.SortKeysBetween("", "", len(ids))
+	if err != nil {
+		return fmt.Errorf("failed to generate sort keys: %w", err)
+	}
+
+	updateQuery, err := r.queries.Get("SetBlockSortKey")
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if _, err := tx.Exec(updateQuery, keys[i], id); err != nil {
+			return fmt.Errorf("failed to rebalance sort keys: %w", err)
+		}
+	}
+	return nil
+}
+
+// longestKey - キーの最大の長さ
+func longestKey(keys []string) int {
+	longest := 0
+	for _, key := range keys {
+		if len(key) > longest {
+			longest = len(key)
+		}
+	}
+	return longest
+}
+
+// countBlocks - トランザクション内で文書のブロック数を取得
+func (r *BlockRepository) countBlocks(tx *sql.Tx, docID int) (int, error) {
+	query, err := r.queries.Get("GetBlockCount")
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := tx.QueryRow(query, docID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count blocks: %w", err)
+	}
+	return count, nil
 }
 
 // DeleteBlocksByDocumentID - 文書IDに紐づく全ブロックを削除
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// CollaboratorRepository - 文書の共同編集者の永続化を担当
+type CollaboratorRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewCollaboratorRepository - CollaboratorRepositoryを初期化
+func NewCollaboratorRepository(db *sql.DB) (*CollaboratorRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &CollaboratorRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// ListCollaborators - 文書の所有者と共同編集者を取得（所有者が先頭）
+func (r *CollaboratorRepository) ListCollaborators(docID int) ([]models.DocumentCollaborator, error) {
+	query, err := r.queries.Get("ListDocumentCollaborators")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document collaborators: %w", err)
+	}
+	defer rows.Close()
+
+	collaborators := make([]models.DocumentCollaborator, 0)
+	for rows.Next() {
+		var collaborator models.DocumentCollaborator
+		if err := rows.Scan(&collaborator.DocumentID, &collaborator.UserID, &collaborator.Email, &collaborator.Name,
+			&collaborator.Role, &collaborator.AddedBy, &collaborator.CreatedAt, &collaborator.UpdatedAt); err != nil {
+			return nil, err
+		}
+		collaborators = append(collaborators, collaborator)
+	}
+
+	return collaborators, rows.Err()
+}
+
+// GetCollaboratorRole - 共同編集者の役割を取得（共同編集者でない場合は ErrNotFound）
+func (r *CollaboratorRepository) GetCollaboratorRole(docID, userID int) (models.DocumentRole, error) {
+	query, err := r.queries.Get("GetDocumentCollaboratorRole")
+	if err != nil {
+		return "", err
+	}
+
+	var role models.DocumentRole
+	if err := r.db.QueryRow(query, docID, userID).Scan(&role); err != nil {
+		return "", apierror.WrapNotFound(err, fmt.Sprintf("document collaborator document=%d user=%d", docID, userID))
+	}
+	return role, nil
+}
+
+// AddCollaborator - 共同編集者を追加（既に共同編集者の場合は ErrConflict）
+func (r *CollaboratorRepository) AddCollaborator(collaborator *models.DocumentCollaborator) error {
+	query, err := r.queries.Get("AddDocumentCollaborator")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, collaborator.DocumentID, collaborator.UserID, collaborator.Role, collaborator.AddedBy).
+		Scan(&collaborator.CreatedAt, &collaborator.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("document collaborator document=%d user=%d: %w",
+			collaborator.DocumentID, collaborator.UserID, apierror.ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add document collaborator: %w", err)
+	}
+	return nil
+}
+
+// UpdateCollaboratorRole - 共同編集者の役割を変更
+func (r *CollaboratorRepository) UpdateCollaboratorRole(docID, userID int, role models.DocumentRole) error {
+	query, err := r.queries.Get("UpdateDocumentCollaboratorRole")
+	if err != nil {
+		return err
+	}
+	return r.execCollaborator(query, docID, userID, role, docID, userID)
+}
+
+// RemoveCollaborator - 共同編集者を削除
+func (r *CollaboratorRepository) RemoveCollaborator(docID, userID int) error {
+	query, err := r.queries.Get("RemoveDocumentCollaborator")
+	if err != nil {
+		return err
+	}
+	return r.execCollaborator(query, docID, userID, docID, userID)
+}
+
+// execCollaborator - 更新系クエリを実行し、対象の共同編集者がいない場合は ErrNotFound を返す
+func (r *CollaboratorRepository) execCollaborator(query string, docID, userID int, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update document collaborator: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("document collaborator document=%d user=%d: %w", docID, userID, apierror.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// CustomBlockTypeRepository - カスタムブロックタイプ操作専用リポジトリ
+type CustomBlockTypeRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewCustomBlockTypeRepository - CustomBlockTypeRepositoryを初期化
+func NewCustomBlockTypeRepository(db *sql.DB) (*CustomBlockTypeRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &CustomBlockTypeRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// ListBlockTypes - 登録済みのカスタムブロックタイプを名前順に取得
+func (r *CustomBlockTypeRepository) ListBlockTypes() ([]models.CustomBlockType, error) {
+	query, err := r.queries.Get("ListCustomBlockTypes")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := make([]models.CustomBlockType, 0)
+	for rows.Next() {
+		var bt models.CustomBlockType
+		err := rows.Scan(&bt.ID, &bt.Name, &bt.Description, &bt.Schema, &bt.ExportTemplate,
+			&bt.CreatedBy, &bt.CreatedAt, &bt.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, bt)
+	}
+
+	return types, rows.Err()
+}
+
+// GetBlockType - 名前でカスタムブロックタイプを取得
+func (r *CustomBlockTypeRepository) GetBlockType(name string) (*models.CustomBlockType, error) {
+	query, err := r.queries.Get("GetCustomBlockType")
+	if err != nil {
+		return nil, err
+	}
+
+	var bt models.CustomBlockType
+	err = r.db.QueryRow(query, name).Scan(&bt.ID, &bt.Name, &bt.Description, &bt.Schema, &bt.ExportTemplate,
+		&bt.CreatedBy, &bt.CreatedAt, &bt.UpdatedAt)
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("custom block type name=%s", name))
+	}
+
+	return &bt, nil
+}
+
+// CreateBlockType - カスタムブロックタイプを登録
+// 同名のタイプが既にある場合は ErrConflict を返す
+func (r *CustomBlockTypeRepository) CreateBlockType(bt *models.CustomBlockType) error {
+	query, err := r.queries.Get("CreateCustomBlockType")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, bt.Name, bt.Description, []byte(bt.Schema), bt.ExportTemplate, bt.CreatedBy).Scan(
+		&bt.ID, &bt.CreatedAt, &bt.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("custom block type name=%s: %w", bt.Name, apierror.ErrConflict)
+		}
+		return fmt.Errorf("failed to create custom block type: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateBlockType - カスタムブロックタイプの定義を更新
+func (r *CustomBlockTypeRepository) UpdateBlockType(bt *models.CustomBlockType) error {
+	query, err := r.queries.Get("UpdateCustomBlockType")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, bt.Name, bt.Description, []byte(bt.Schema), bt.ExportTemplate).Scan(
+		&bt.ID, &bt.CreatedBy, &bt.CreatedAt, &bt.UpdatedAt,
+	)
+	if err != nil {
+		return apierror.WrapNotFound(err, fmt.Sprintf("custom block type name=%s", bt.Name))
+	}
+
+	return nil
+}
+
+// DeleteBlockType - カスタムブロックタイプを削除
+// 既存のブロックはそのまま残り、以後は検証・専用エクスポートの対象外になる
+func (r *CustomBlockTypeRepository) DeleteBlockType(name string) error {
+	query, err := r.queries.Get("DeleteCustomBlockType")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete custom block type: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("custom block type name=%s: %w", name, apierror.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// DocumentCommentRepository - 文書へのコメント（スレッド）操作専用リポジトリ
+type DocumentCommentRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewDocumentCommentRepository - DocumentCommentRepositoryを初期化
+func NewDocumentCommentRepository(db *sql.DB) (*DocumentCommentRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DocumentCommentRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateComment - コメントを作成（ParentID を指定した場合は返信）
+func (r *DocumentCommentRepository) CreateComment(comment *models.DocumentComment) error {
+	query, err := r.queries.Get("CreateDocumentComment")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, comment.DocumentID, comment.ParentID, comment.UserID, comment.Body).Scan(
+		&comment.ID, &comment.CreatedAt, &comment.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create document comment: %w", err)
+	}
+
+	return nil
+}
+
+// ListComments - 文書のコメント（スレッドの先頭と返信）を作成日時順に取得
+func (r *DocumentCommentRepository) ListComments(docID int) ([]models.DocumentComment, error) {
+	query, err := r.queries.Get("ListDocumentComments")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, docID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := make([]models.DocumentComment, 0)
+	for rows.Next() {
+		comment, err := scanDocumentComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *comment)
+	}
+
+	return comments, rows.Err()
+}
+
+// GetComment - 文書内の単一コメントを取得
+func (r *DocumentCommentRepository) GetComment(docID, commentID int) (*models.DocumentComment, error) {
+	query, err := r.queries.Get("GetDocumentComment")
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := scanDocumentComment(r.db.QueryRow(query, commentID, docID))
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("document comment id=%d document=%d", commentID, docID))
+	}
+	return comment, nil
+}
+
+// CountComments - 文書のコメント数（返信を含む）を取得
+func (r *DocumentCommentRepository) CountComments(docID int) (int, error) {
+	query, err := r.queries.Get("CountDocumentComments")
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.db.QueryRow(query, docID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count document comments: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateCommentBody - コメント本文を更新（投稿者のみ）
+func (r *DocumentCommentRepository) UpdateCommentBody(docID, commentID, userID int, body string) error {
+	query, err := r.queries.Get("UpdateDocumentCommentBody")
+	if err != nil {
+		return err
+	}
+	return r.execComment(query, commentID, body, commentID, docID, userID)
+}
+
+// SetCommentResolved - スレッドを解決済み／未解決にする（スレッドの先頭のコメントのみ）
+func (r *DocumentCommentRepository) SetCommentResolved(docID, commentID, userID int, resolved bool) error {
+	if resolved {
+		query, err := r.queries.Get("ResolveDocumentComment")
+		if err != nil {
+			return err
+		}
+		return r.execComment(query, commentID, commentID, docID, userID)
+	}
+
+	query, err := r.queries.Get("ReopenDocumentComment")
+	if err != nil {
+		return err
+	}
+	return r.execComment(query, commentID, commentID, docID)
+}
+
+// DeleteComment - コメントを削除（投稿者のみ。スレッドの先頭を削除すると返信も削除される）
+func (r *DocumentCommentRepository) DeleteComment(docID, commentID, userID int) error {
+	query, err := r.queries.Get("DeleteDocumentComment")
+	if err != nil {
+		return err
+	}
+	return r.execComment(query, commentID, commentID, docID, userID)
+}
+
+// execComment - 単一コメントの更新・削除を実行し、対象がない場合は ErrNotFound を返す
+func (r *DocumentCommentRepository) execComment(query string, commentID int, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update document comment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("document comment id=%d or access denied: %w", commentID, apierror.ErrNotFound)
+	}
+
+	return nil
+}
+
+// scanDocumentComment - コメント1行を読み取る
+func scanDocumentComment(row rowScanner) (*models.DocumentComment, error) {
+	var comment models.DocumentComment
+	err := row.Scan(&comment.ID, &comment.DocumentID, &comment.ParentID, &comment.UserID, &comment.Body,
+		&comment.ResolvedAt, &comment.ResolvedBy, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	comment.Resolved = comment.ResolvedAt != nil
+	return &comment, nil
+}
@@ -1,11 +1,15 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
+	"github.com/lib/pq"
+
 	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
 )
 
 // DocumentCoreRepository - 基本的な文書CRUD操作を担当
@@ -27,32 +31,121 @@ func NewDocumentCoreRepository(db *sql.DB) (*DocumentCoreRepository, error) {
 	}, nil
 }
 
-// CreateDocument - 文書を新規作成
+// CreateDocument - 文書を新規作成（子文書は WorkspaceID によらず親のワークスペースに属する）
 func (r *DocumentCoreRepository) CreateDocument(doc *models.Document) error {
 	query, err := r.queries.Get("CreateDocument")
 	if err != nil {
 		return err
 	}
 
-	err = r.db.QueryRow(query, doc.UserID, doc.ParentID, doc.Title, doc.Content).Scan(
-		&doc.ID, &doc.CreatedAt, &doc.UpdatedAt,
+	err = r.db.QueryRow(query, doc.UserID, doc.ParentID, doc.Title, doc.Content, doc.WorkspaceID).Scan(
+		&doc.ID, &doc.WorkspaceID, &doc.CreatedAt, &doc.UpdatedAt,
 	)
 
 	return err
 }
 
-// UpdateDocument - 文書のタイトルと内容を更新
-func (r *DocumentCoreRepository) UpdateDocument(docID, userID int, title, content string) error {
+// UpdateDocument - 文書のタイトルと内容を更新（所有者・editor の共同編集者・ワークスペースの guest 以外のメンバーのみ）
+func (r *DocumentCoreRepository) UpdateDocument(ctx context.Context, docID, userID int, title, content string) error {
 	query, err := r.queries.Get("UpdateDocument")
 	if err != nil {
 		return err
 	}
 
-	_, err = r.db.Exec(query, title, content, docID, userID)
+	_, err = r.db.ExecContext(ctx, query, title, content, docID, userID)
 	return err
 }
 
+// PatchDocument - 文書のタイトルと内容のうち、nil でないものだけを更新（更新できるユーザーは UpdateDocument と同じ）
+func (r *DocumentCoreRepository) PatchDocument(ctx context.Context, docID, userID int, title, content *string) error {
+	query, err := r.queries.Get("PatchDocument")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, query, title, content, docID, userID)
+	return err
+}
+
+// UpdateSearchText - 全文検索用のテキスト（ブロックの JSON から取り出したテキスト）を更新
+func (r *DocumentCoreRepository) UpdateSearchText(docID int, text string) error {
+	query, err := r.queries.Get("UpdateDocumentSearchText")
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(query, text, docID); err != nil {
+		return fmt.Errorf("failed to update search text: %w", err)
+	}
+	return nil
+}
+
+// ListDocumentsForReindex - 全文検索のテキストを作り直す文書（ごみ箱以外）を afterID より後から ID 順に limit 件取得
+// 返す文書は ID と UserID のみを設定する
+func (r *DocumentCoreRepository) ListDocumentsForReindex(ctx context.Context, afterID, limit int) ([]models.Document, error) {
+	query, err := r.queries.Get("ListDocumentsForReindex")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents for reindex: %w", err)
+	}
+	defer rows.Close()
+
+	var documents []models.Document
+	for rows.Next() {
+		var doc models.Document
+		if err := rows.Scan(&doc.ID, &doc.UserID); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, doc)
+	}
+	return documents, rows.Err()
+}
+
+// SearchDocuments - ユーザーの文書をタイトルとブロックのテキストで全文検索し、関連度順に1ページ分取得
+// カーソルの関連度は ts_rank の ::text をそのまま使う（float64 で持つと real に戻したときに値がずれる）
+func (r *DocumentCoreRepository) SearchDocuments(userID int, keyword string, page pagination.Params) (pagination.Page[models.DocumentSearchResult], error) {
+	query, err := r.queries.Get("SearchDocuments")
+	if err != nil {
+		return pagination.Page[models.DocumentSearchResult]{}, err
+	}
+	if err := page.Check("", pagination.NumberKey, pagination.TimeKey); err != nil {
+		return pagination.Page[models.DocumentSearchResult]{}, err
+	}
+
+	rows, err := r.db.Query(query, userID, keyword, page.FetchLimit(), page.AfterKey(0), page.AfterKey(1), page.AfterID())
+	if err != nil {
+		return pagination.Page[models.DocumentSearchResult]{}, fmt.Errorf("failed to search documents: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]models.DocumentSearchResult, 0)
+	var ranks []string
+	for rows.Next() {
+		var result models.DocumentSearchResult
+		var headline, rank string
+		if err := rows.Scan(&result.ID, &result.ParentID, &result.Title, &result.UpdatedAt, &headline, &result.Rank, &rank); err != nil {
+			return pagination.Page[models.DocumentSearchResult]{}, err
+		}
+		result.Snippet = models.EscapeSearchSnippet(headline)
+		results = append(results, result)
+		ranks = append(ranks, rank)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.DocumentSearchResult]{}, err
+	}
+
+	return pagination.NewPage(results, page, func(i int) pagination.Cursor {
+		keys := []string{ranks[i], pagination.FormatTime(results[i].UpdatedAt)}
+		return pagination.Cursor{Keys: keys, ID: results[i].ID}
+	}), nil
+}
+
 // GetDocument - 単一文書を取得（ブロック情報は含まない）
+// 所有者・共同編集者と、文書が属するワークスペースのメンバーが取得でき、Role に取得したユーザーの役割を設定する
 func (r *DocumentCoreRepository) GetDocument(docID, userID int) (*models.Document, error) {
 	query, err := r.queries.Get("GetDocumentWithBlocks")
 	if err != nil {
@@ -63,7 +156,7 @@ func (r *DocumentCoreRepository) GetDocument(docID, userID int) (*models.Documen
 	err = r.db.QueryRow(query, docID, userID).Scan(
 		&doc.ID, &doc.UserID, &doc.ParentID, &doc.Title,
 		&doc.Content, &doc.TreePath, &doc.Level, &doc.SortOrder,
-		&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt,
+		&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt, &doc.WorkspaceID, &doc.Role,
 	)
 
 	if err != nil {
@@ -73,7 +166,41 @@ func (r *DocumentCoreRepository) GetDocument(docID, userID int) (*models.Documen
 	return &doc, nil
 }
 
+// GetDocumentsByIDs - 複数の文書を1回の問い合わせで取得（ブロック情報は含まない）
+// GetDocument と同じく所有者・共同編集者・ワークスペースのメンバーが取得でき、アクセスできない文書は結果に含めない
+func (r *DocumentCoreRepository) GetDocumentsByIDs(docIDs []int, userID int) ([]models.Document, error) {
+	if len(docIDs) == 0 {
+		return nil, nil
+	}
+
+	query, err := r.queries.Get("GetDocumentsByIDs")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, pq.Array(docIDs), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []models.Document
+	for rows.Next() {
+		var doc models.Document
+		err := rows.Scan(&doc.ID, &doc.UserID, &doc.ParentID, &doc.Title,
+			&doc.Content, &doc.TreePath, &doc.Level, &doc.SortOrder,
+			&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt, &doc.WorkspaceID, &doc.Role)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, doc)
+	}
+
+	return documents, rows.Err()
+}
+
 // GetDocumentIncludingDeleted - 削除されたドキュメントも含めて単一文書を取得
+// ごみ箱内の文書の場合は DeletedAt も設定する
 func (r *DocumentCoreRepository) GetDocumentIncludingDeleted(docID, userID int) (*models.Document, error) {
 	query, err := r.queries.Get("GetDocumentWithBlocksIncludingDeleted")
 	if err != nil {
@@ -84,7 +211,7 @@ func (r *DocumentCoreRepository) GetDocumentIncludingDeleted(docID, userID int)
 	err = r.db.QueryRow(query, docID, userID).Scan(
 		&doc.ID, &doc.UserID, &doc.ParentID, &doc.Title,
 		&doc.Content, &doc.TreePath, &doc.Level, &doc.SortOrder,
-		&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt,
+		&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt, &doc.DeletedAt, &doc.WorkspaceID,
 	)
 
 	if err != nil {
@@ -3,9 +3,14 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
 
 	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
 )
 
 // DocumentTrashRepository - ごみ箱操作専用リポジトリ
@@ -75,6 +80,67 @@ func (r *DocumentTrashRepository) RestoreDocument(docID, userID int) error {
 	return nil
 }
 
+// BulkRestoreDocuments - 複数の文書をごみ箱から1つのトランザクションで復元
+// 復元先の親文書は strategy に従って決め（models.PlanBulkRestore）、文書ごとの結果を返す
+// 存在しない・ごみ箱にない文書は結果に理由を設定して飛ばし、DB エラーの場合は全体をロールバックする
+func (r *DocumentTrashRepository) BulkRestoreDocuments(userID int, ids []int, strategy models.RestoreStrategy) ([]models.RestoreResult, error) {
+	lockQuery, err := r.queries.Get("LockDocumentsForRestore")
+	if err != nil {
+		return nil, err
+	}
+	restoreQuery, err := r.queries.Get("RestoreDocumentWithParent")
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(lockQuery, userID, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock documents: %w", err)
+	}
+	states := make(map[int]models.RestoreDocumentState)
+	for rows.Next() {
+		var state models.RestoreDocumentState
+		if err := rows.Scan(&state.ID, &state.ParentID, &state.IsDeleted, &state.Level); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		states[state.ID] = state
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	results := models.PlanBulkRestore(ids, strategy, states)
+
+	// 親文書の tree_path が確定してから子を更新するよう、階層の浅い順に復元する
+	order := make([]int, 0, len(results))
+	for i, result := range results {
+		if result.Restored {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return states[results[order[a]].ID].Level < states[results[order[b]].ID].Level
+	})
+
+	for _, i := range order {
+		if _, err := tx.Exec(restoreQuery, results[i].ID, userID, results[i].ParentID); err != nil {
+			return nil, fmt.Errorf("failed to restore document id=%d: %w", results[i].ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk restore: %w", err)
+	}
+	return results, nil
+}
+
 // PermanentDeleteDocument - 文書を完全削除
 func (r *DocumentTrashRepository) PermanentDeleteDocument(docID, userID int) error {
 	// トランザクション開始（ブロックも同時削除）
@@ -118,16 +184,71 @@ func (r *DocumentTrashRepository) PermanentDeleteDocument(docID, userID int) err
 	return tx.Commit()
 }
 
-// GetTrashedDocuments - ごみ箱内の文書一覧を取得
-func (r *DocumentTrashRepository) GetTrashedDocuments(userID int) ([]models.Document, error) {
+// trashOrders は ごみ箱一覧の並び替えキーの式と、カーソルに入れる値です（ORDER BY に埋め込むため固定の式のみ）
+var trashOrders = map[models.TrashSort]struct {
+	column string
+	kind   pagination.KeyKind
+	key    func(doc *models.Document) string
+}{
+	models.TrashSortDeletedAt: {
+		column: "COALESCE(deleted_at, updated_at)",
+		kind:   pagination.TimeKey,
+		key:    func(doc *models.Document) string { return pagination.FormatTime(*doc.DeletedAt) },
+	},
+	models.TrashSortTitle: {
+		column: "title",
+		kind:   pagination.TextKey,
+		key:    func(doc *models.Document) string { return doc.Title },
+	},
+	models.TrashSortCreatedAt: {
+		column: "created_at",
+		kind:   pagination.TimeKey,
+		key:    func(doc *models.Document) string { return pagination.FormatTime(doc.CreatedAt) },
+	},
+}
+
+// GetTrashedDocuments - ごみ箱内の文書一覧を1ページ分取得
+// 絞り込み・並び替えは SQL 側で行い、同じ並び替えキーの文書は ID 順に並べる
+// page.Limit が 0 の場合は全件を取得する
+func (r *DocumentTrashRepository) GetTrashedDocuments(userID int, filter models.TrashFilter, page pagination.Params) (pagination.Page[models.Document], error) {
 	query, err := r.queries.Get("GetTrashedDocuments")
 	if err != nil {
-		return nil, err
+		return pagination.Page[models.Document]{}, err
 	}
 
-	rows, err := r.db.Query(query, userID)
+	sortKey := filter.Sort
+	if sortKey == "" {
+		sortKey = models.TrashSortDeletedAt
+	}
+	order, ok := trashOrders[sortKey]
+	if !ok {
+		return pagination.Page[models.Document]{}, fmt.Errorf("unknown trash sort %q", sortKey)
+	}
+	direction, comparison := "DESC", "<"
+	if filter.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+	cursorSort := string(sortKey) + ":" + strings.ToLower(direction)
+	if err := page.Check(cursorSort, order.kind); err != nil {
+		return pagination.Page[models.Document]{}, err
+	}
+
+	var titlePattern *string
+	if filter.Query != "" {
+		pattern := "%" + escapeLikePattern(filter.Query) + "%"
+		titlePattern = &pattern
+	}
+
+	query += fmt.Sprintf(`
+		  AND ($7::int IS NULL OR (%[1]s, id) %[2]s ($6, $7))
+		ORDER BY %[1]s %[3]s, id %[3]s
+		LIMIT $8
+	`, order.column, comparison, direction)
+
+	rows, err := r.db.Query(query, userID, titlePattern, filter.DeletedFrom, filter.DeletedTo,
+		filter.ParentID, page.AfterKey(0), page.AfterID(), page.FetchLimit())
 	if err != nil {
-		return nil, err
+		return pagination.Page[models.Document]{}, err
 	}
 	defer rows.Close()
 
@@ -136,14 +257,19 @@ func (r *DocumentTrashRepository) GetTrashedDocuments(userID int) ([]models.Docu
 		var doc models.Document
 		err := rows.Scan(&doc.ID, &doc.UserID, &doc.ParentID, &doc.Title,
 			&doc.Content, &doc.TreePath, &doc.Level, &doc.SortOrder,
-			&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt)
+			&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt, &doc.DeletedAt)
 		if err != nil {
-			return nil, err
+			return pagination.Page[models.Document]{}, err
 		}
 		documents = append(documents, doc)
 	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.Document]{}, err
+	}
 
-	return documents, nil
+	return pagination.NewPage(documents, page, func(i int) pagination.Cursor {
+		return pagination.Cursor{Sort: cursorSort, Keys: []string{order.key(&documents[i])}, ID: documents[i].ID}
+	}), nil
 }
 
 // EmptyTrash - ユーザーのごみ箱を完全に空にする
@@ -156,7 +282,7 @@ func (r *DocumentTrashRepository) EmptyTrash(userID int) error {
 	defer tx.Rollback()
 
 	// ごみ箱内の文書一覧を取得
-	trashedDocs, err := r.GetTrashedDocuments(userID)
+	trashed, err := r.GetTrashedDocuments(userID, models.TrashFilter{}, pagination.Params{})
 	if err != nil {
 		return fmt.Errorf("failed to get trashed documents: %w", err)
 	}
@@ -167,7 +293,7 @@ func (r *DocumentTrashRepository) EmptyTrash(userID int) error {
 		return err
 	}
 
-	for _, doc := range trashedDocs {
+	for _, doc := range trashed.Items {
 		_, err = tx.Exec(deleteBlocksQuery, doc.ID)
 		if err != nil {
 			return fmt.Errorf("failed to delete blocks for document %d: %w", doc.ID, err)
@@ -183,3 +309,32 @@ func (r *DocumentTrashRepository) EmptyTrash(userID int) error {
 
 	return tx.Commit()
 }
+
+// ListTrashOwnerIDs - ごみ箱に文書があるユーザーのIDを取得
+func (r *DocumentTrashRepository) ListTrashOwnerIDs() ([]int, error) {
+	query, err := r.queries.Get("ListTrashOwnerIDs")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash owners: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan trash owner: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// escapeLikePattern - LIKE のワイルドカード文字をエスケープ
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -26,14 +26,15 @@ func NewDocumentTreeRepository(db *sql.DB) (*DocumentTreeRepository, error) {
 	}, nil
 }
 
-// GetDocumentTree - ユーザーの文書ツリー構造を取得
-func (r *DocumentTreeRepository) GetDocumentTree(userID int) ([]models.DocumentTreeNode, error) {
-	query, err := r.queries.Get("GetDocumentTree")
+// GetDocumentTree - ワークスペースの文書ツリー構造を取得（nil の場合はユーザーの個人の文書）
+// ワークスペースの場合は全メンバーの文書を含み、ユーザーがメンバーでない場合は空のツリーを返す
+func (r *DocumentTreeRepository) GetDocumentTree(userID int, workspaceID *int) ([]models.DocumentTreeNode, error) {
+	query, err := r.queries.Get("GetWorkspaceDocumentTree")
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.Query(query, userID, workspaceID)
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +45,7 @@ func (r *DocumentTreeRepository) GetDocumentTree(userID int) ([]models.DocumentT
 		var doc models.Document
 		err := rows.Scan(&doc.ID, &doc.UserID, &doc.ParentID, &doc.Title,
 			&doc.Content, &doc.TreePath, &doc.Level, &doc.SortOrder,
-			&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt)
+			&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt, &doc.WorkspaceID)
 		if err != nil {
 			return nil, err
 		}
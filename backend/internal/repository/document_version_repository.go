@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// DocumentVersionRepository - 文書バージョン（スナップショット）操作専用リポジトリ
+type DocumentVersionRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewDocumentVersionRepository - DocumentVersionRepositoryを初期化
+func NewDocumentVersionRepository(db *sql.DB) (*DocumentVersionRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DocumentVersionRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateVersion - 文書の現在状態を新しいバージョンとして保存（version_number は自動採番）
+func (r *DocumentVersionRepository) CreateVersion(version *models.DocumentVersion) error {
+	query, err := r.queries.Get("CreateDocumentVersion")
+	if err != nil {
+		return err
+	}
+
+	blocks := version.Blocks
+	if blocks == nil {
+		blocks = []models.Block{}
+	}
+	blocksJSON, err := json.Marshal(blocks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal version blocks: %w", err)
+	}
+
+	err = r.db.QueryRow(query, version.DocumentID, version.Title, version.Content,
+		blocksJSON, version.CreatedBy).Scan(
+		&version.ID, &version.VersionNumber, &version.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create document version: %w", err)
+	}
+
+	return nil
+}
+
+// GetVersion - 指定バージョン番号のスナップショットを取得
+func (r *DocumentVersionRepository) GetVersion(docID, versionNumber int) (*models.DocumentVersion, error) {
+	return r.getVersion("GetDocumentVersion", docID, versionNumber)
+}
+
+// GetVersionAtOrBefore - 指定バージョン番号以前で最も新しいスナップショットを取得（古い版の整理で削除された版の代わりに使う）
+func (r *DocumentVersionRepository) GetVersionAtOrBefore(docID, versionNumber int) (*models.DocumentVersion, error) {
+	return r.getVersion("GetDocumentVersionAtOrBefore", docID, versionNumber)
+}
+
+// getVersion - 名前付きクエリでスナップショットを1件取得
+func (r *DocumentVersionRepository) getVersion(name string, docID, versionNumber int) (*models.DocumentVersion, error) {
+	query, err := r.queries.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var version models.DocumentVersion
+	var blocksJSON []byte
+	err = r.db.QueryRow(query, docID, versionNumber).Scan(
+		&version.ID, &version.DocumentID, &version.VersionNumber, &version.Title,
+		&version.Content, &blocksJSON, &version.CreatedBy, &version.CreatedAt,
+	)
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("document version document=%d version=%d", docID, versionNumber))
+	}
+
+	if err := json.Unmarshal(blocksJSON, &version.Blocks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal version blocks: %w", err)
+	}
+
+	return &version, nil
+}
+
+// ListVersions - 文書のバージョン一覧を新しい順に取得（ブロック本体は含まない）
+func (r *DocumentVersionRepository) ListVersions(docID int) ([]models.DocumentVersion, error) {
+	query, err := r.queries.Get("ListDocumentVersions")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, docID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]models.DocumentVersion, 0)
+	for rows.Next() {
+		var version models.DocumentVersion
+		err := rows.Scan(&version.ID, &version.DocumentID, &version.VersionNumber,
+			&version.Title, &version.CreatedBy, &version.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// MergeVersions - before より前に作成した版を、文書・作成者・window の区間ごとに最後の1つにまとめる（削除した版の数を返す）
+// 文書の最新の版は残す
+func (r *DocumentVersionRepository) MergeVersions(before time.Time, window time.Duration) (int, error) {
+	query, err := r.queries.Get("MergeDocumentVersions")
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.Exec(query, before, int64(window/time.Second))
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge document versions: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// DeleteVersionsBefore - before より前に作成した版を削除する（削除した版の数を返す）
+// 文書の最新の版は編集の基準（baseVersion）にするため残す
+func (r *DocumentVersionRepository) DeleteVersionsBefore(before time.Time) (int, error) {
+	query, err := r.queries.Get("DeleteExpiredDocumentVersions")
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.Exec(query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired document versions: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// DocumentWatchRepository - 文書の変更の購読操作専用リポジトリ
+type DocumentWatchRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewDocumentWatchRepository - DocumentWatchRepositoryを初期化
+func NewDocumentWatchRepository(db *sql.DB) (*DocumentWatchRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DocumentWatchRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// UpsertWatch - 文書を購読する（既に購読している場合は知らせ方を変更）
+func (r *DocumentWatchRepository) UpsertWatch(watch *models.DocumentWatch) error {
+	query, err := r.queries.Get("UpsertDocumentWatch")
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.QueryRow(query, watch.DocumentID, watch.UserID, watch.Delivery).Scan(&watch.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save document watch: %w", err)
+	}
+	return nil
+}
+
+// DeleteWatch - 文書の購読を解除する（購読していない場合は ErrNotFound）
+func (r *DocumentWatchRepository) DeleteWatch(docID, userID int) error {
+	query, err := r.queries.Get("DeleteDocumentWatch")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, docID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete document watch: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("document watch document=%d user=%d: %w", docID, userID, apierror.ErrNotFound)
+	}
+	return nil
+}
+
+// ListWatches - ユーザーが購読している文書を新しい順に取得
+func (r *DocumentWatchRepository) ListWatches(userID int) ([]models.DocumentWatch, error) {
+	query, err := r.queries.Get("ListUserDocumentWatches")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document watches: %w", err)
+	}
+	defer rows.Close()
+
+	watches := make([]models.DocumentWatch, 0)
+	for rows.Next() {
+		var watch models.DocumentWatch
+		if err := rows.Scan(&watch.DocumentID, &watch.UserID, &watch.DocumentTitle, &watch.Delivery, &watch.CreatedAt); err != nil {
+			return nil, err
+		}
+		watches = append(watches, watch)
+	}
+
+	return watches, rows.Err()
+}
+
+// ListWatchers - 文書の変更を知らせる購読者を取得（変更したユーザーとアクセスできないユーザーを除く）
+func (r *DocumentWatchRepository) ListWatchers(docID, changedBy int) ([]models.DocumentWatcher, error) {
+	query, err := r.queries.Get("ListDocumentWatchers")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, docID, changedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document watchers: %w", err)
+	}
+	defer rows.Close()
+
+	watchers := make([]models.DocumentWatcher, 0)
+	for rows.Next() {
+		var watcher models.DocumentWatcher
+		if err := rows.Scan(&watcher.UserID, &watcher.DocumentTitle, &watcher.Delivery, &watcher.HasUnread); err != nil {
+			return nil, err
+		}
+		watchers = append(watchers, watcher)
+	}
+
+	return watchers, rows.Err()
+}
+
+// MarkChanged - まとめて送るメールに含めるよう、購読者にとっての文書の変更日時を記録
+func (r *DocumentWatchRepository) MarkChanged(docID, userID int) error {
+	query, err := r.queries.Get("MarkDocumentWatchChanged")
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(query, docID, userID); err != nil {
+		return fmt.Errorf("failed to mark document watch changed: %w", err)
+	}
+	return nil
+}
+
+// ListPendingDigests - 前回のメールの後に変更された購読中の文書を、ユーザーごとにまとめた順で取得
+func (r *DocumentWatchRepository) ListPendingDigests() ([]models.WatchDigestEntry, error) {
+	query, err := r.queries.Get("ListPendingWatchDigests")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending watch digests: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.WatchDigestEntry, 0)
+	for rows.Next() {
+		var entry models.WatchDigestEntry
+		if err := rows.Scan(&entry.UserID, &entry.Email, &entry.Name, &entry.DocumentID, &entry.DocumentTitle, &entry.ChangedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// MarkDigested - 指定した時点までの文書の変更をメールで送信済みにする
+func (r *DocumentWatchRepository) MarkDigested(userID int, docIDs []int, at time.Time) error {
+	query, err := r.queries.Get("MarkWatchDigested")
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(docIDs))
+	for _, id := range docIDs {
+		ids = append(ids, int64(id))
+	}
+	if _, err := r.db.Exec(query, userID, pq.Array(ids), at); err != nil {
+		return fmt.Errorf("failed to mark watch digested: %w", err)
+	}
+	return nil
+}
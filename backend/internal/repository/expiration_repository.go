@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"simple-notion-backend/internal/models"
+)
+
+// ExpirationRepository - 文書の有効期限操作専用リポジトリ
+type ExpirationRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewExpirationRepository - ExpirationRepositoryを初期化
+func NewExpirationRepository(db *sql.DB) (*ExpirationRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &ExpirationRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// GetExpiration - 文書の有効期限を取得（未設定の場合は nil）
+func (r *ExpirationRepository) GetExpiration(docID int) (*models.DocumentExpiration, error) {
+	query, err := r.queries.Get("GetDocumentExpiration")
+	if err != nil {
+		return nil, err
+	}
+
+	var exp models.DocumentExpiration
+	err = r.db.QueryRow(query, docID).Scan(
+		&exp.DocumentID, &exp.ExpiresAt, &exp.Action, &exp.WarnedAt, &exp.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document expiration: %w", err)
+	}
+
+	return &exp, nil
+}
+
+// SaveExpiration - 文書の有効期限を保存（再設定時は事前警告の通知状態をリセット）
+func (r *ExpirationRepository) SaveExpiration(exp *models.DocumentExpiration) error {
+	query, err := r.queries.Get("UpsertDocumentExpiration")
+	if err != nil {
+		return err
+	}
+
+	exp.WarnedAt = nil
+	err = r.db.QueryRow(query, exp.DocumentID, exp.ExpiresAt, exp.Action).Scan(&exp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save document expiration: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiration - 文書の有効期限を解除
+func (r *ExpirationRepository) DeleteExpiration(docID int) error {
+	query, err := r.queries.Get("DeleteDocumentExpiration")
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(query, docID); err != nil {
+		return fmt.Errorf("failed to delete document expiration: %w", err)
+	}
+
+	return nil
+}
+
+// ListExpirationsToWarn - warnBefore 以内に期限を迎える未警告の文書を取得
+func (r *ExpirationRepository) ListExpirationsToWarn(warnBefore time.Duration) ([]models.ExpiringDocument, error) {
+	query, err := r.queries.Get("ListExpirationsToWarn")
+	if err != nil {
+		return nil, err
+	}
+	return r.listExpiringDocuments(query, warnBefore.Seconds())
+}
+
+// MarkWarned - 事前警告を通知済みにする
+func (r *ExpirationRepository) MarkWarned(docID int) error {
+	query, err := r.queries.Get("MarkExpirationWarned")
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(query, docID); err != nil {
+		return fmt.Errorf("failed to mark expiration as warned: %w", err)
+	}
+
+	return nil
+}
+
+// ListDueExpirations - 期限に到達した文書を取得
+func (r *ExpirationRepository) ListDueExpirations() ([]models.ExpiringDocument, error) {
+	query, err := r.queries.Get("ListDueExpirations")
+	if err != nil {
+		return nil, err
+	}
+	return r.listExpiringDocuments(query)
+}
+
+// listExpiringDocuments - 期限付き文書一覧クエリを実行
+func (r *ExpirationRepository) listExpiringDocuments(query string, args ...interface{}) ([]models.ExpiringDocument, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := make([]models.ExpiringDocument, 0)
+	for rows.Next() {
+		var d models.ExpiringDocument
+		if err := rows.Scan(&d.DocumentID, &d.UserID, &d.Title, &d.ExpiresAt, &d.Action); err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+
+	return docs, rows.Err()
+}
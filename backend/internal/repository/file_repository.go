@@ -3,11 +3,17 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
 )
 
 // FileRepository は file_metadata テーブルへのデータアクセスを提供します
@@ -21,16 +27,88 @@ func NewFileRepository(db *sql.DB) *FileRepository {
 }
 
 // Create は 新しいファイルメタデータをデータベースに保存します
+// file.FileKey は新しく保存したオブジェクトとして参照数 1 で登録する
 func (r *FileRepository) Create(ctx context.Context, file *models.FileMetadata) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO file_objects (file_key, ref_count) VALUES ($1, 1)`, file.FileKey); err != nil {
+		return fmt.Errorf("failed to register file object: %w", err)
+	}
+	if err := insertFileMetadata(ctx, tx, file); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateReference は 保存済みのオブジェクト（file.FileKey）を参照するファイルメタデータを保存し、参照数を増やします
+// オブジェクトの参照がすべて削除されている（削除処理中を含む）場合は ErrNotFound を返す
+func (r *FileRepository) CreateReference(ctx context.Context, file *models.FileMetadata) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE file_objects
+		SET ref_count = ref_count + 1
+		WHERE file_key = $1 AND ref_count > 0
+	`
+
+	result, err := tx.ExecContext(ctx, query, file.FileKey)
+	if err != nil {
+		return fmt.Errorf("failed to add file object reference: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("file object fileKey=%s: %w", file.FileKey, apierror.ErrNotFound)
+	}
+
+	if err := insertFileMetadata(ctx, tx, file); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertFileMetadata は ファイルメタデータの行を追加し、ID とアップロード日時を file に設定します
+func insertFileMetadata(ctx context.Context, tx *sql.Tx, file *models.FileMetadata) error {
+	thumbnails := file.Thumbnails
+	if thumbnails == nil {
+		thumbnails = map[string]string{}
+	}
+	encodedThumbnails, err := json.Marshal(thumbnails)
+	if err != nil {
+		return fmt.Errorf("failed to encode thumbnails: %w", err)
+	}
+
 	query := `
 		INSERT INTO file_metadata 
 		(user_id, document_id, block_id, file_key, bucket_name, original_name, 
-		 file_size, mime_type, file_type, width, height, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 file_size, mime_type, file_type, width, height, status, thumbnail_status,
+		 thumbnails, content_hash, alt_text, description, variant, webp_file_key, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE(NULLIF($13, ''), 'none'),
+		        $14, NULLIF($15, ''), $16, $17, COALESCE(NULLIF($18, ''), 'original'), NULLIF($19, ''), $20)
 		RETURNING id, uploaded_at
 	`
 
-	err := r.db.QueryRowContext(
+	err = tx.QueryRowContext(
 		ctx, query,
 		file.UserID,
 		file.DocumentID,
@@ -44,6 +122,14 @@ func (r *FileRepository) Create(ctx context.Context, file *models.FileMetadata)
 		file.Width,
 		file.Height,
 		file.Status,
+		file.ThumbnailStatus,
+		encodedThumbnails,
+		file.ContentHash,
+		file.AltText,
+		file.Description,
+		file.Variant,
+		file.WebPFileKey,
+		file.DurationMs,
 	).Scan(&file.ID, &file.UploadedAt)
 
 	if err != nil {
@@ -58,7 +144,8 @@ func (r *FileRepository) GetByID(ctx context.Context, id int) (*models.FileMetad
 	query := `
 		SELECT id, user_id, document_id, block_id, file_key, bucket_name,
 		       original_name, file_size, mime_type, file_type, width, height,
-		       uploaded_at, status, deleted_at
+		       uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		       alt_text, description, variant, webp_file_key, duration_ms
 		FROM file_metadata
 		WHERE id = $1
 	`
@@ -80,6 +167,14 @@ func (r *FileRepository) GetByID(ctx context.Context, id int) (*models.FileMetad
 		&row.UploadedAt,
 		&row.Status,
 		&row.DeletedAt,
+		&row.Thumbnails,
+		&row.ThumbnailStatus,
+		&row.ContentHash,
+		&row.AltText,
+		&row.Description,
+		&row.Variant,
+		&row.WebPFileKey,
+		&row.DurationMs,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -93,13 +188,17 @@ func (r *FileRepository) GetByID(ctx context.Context, id int) (*models.FileMetad
 }
 
 // GetByFileKey は ファイルキーでファイルメタデータを取得します
+// 同じオブジェクトを複数のファイルメタデータが参照している場合は、有効なもののうち最新のものを返す
 func (r *FileRepository) GetByFileKey(ctx context.Context, fileKey string) (*models.FileMetadata, error) {
 	query := `
 		SELECT id, user_id, document_id, block_id, file_key, bucket_name,
 		       original_name, file_size, mime_type, file_type, width, height,
-		       uploaded_at, status, deleted_at
+		       uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		       alt_text, description, variant, webp_file_key, duration_ms
 		FROM file_metadata
 		WHERE file_key = $1
+		ORDER BY (status = 'active') DESC, uploaded_at DESC
+		LIMIT 1
 	`
 
 	var row models.FileMetadataRow
@@ -119,6 +218,14 @@ func (r *FileRepository) GetByFileKey(ctx context.Context, fileKey string) (*mod
 		&row.UploadedAt,
 		&row.Status,
 		&row.DeletedAt,
+		&row.Thumbnails,
+		&row.ThumbnailStatus,
+		&row.ContentHash,
+		&row.AltText,
+		&row.Description,
+		&row.Variant,
+		&row.WebPFileKey,
+		&row.DurationMs,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -131,12 +238,63 @@ func (r *FileRepository) GetByFileKey(ctx context.Context, fileKey string) (*mod
 	return row.ToFileMetadata(), nil
 }
 
+// GetActiveByContentHash は ユーザーの有効なファイルのうち、種類と内容の SHA-256 が一致する最新のものを取得します
+func (r *FileRepository) GetActiveByContentHash(ctx context.Context, userID int, fileType string, contentHash string) (*models.FileMetadata, error) {
+	query := `
+		SELECT id, user_id, document_id, block_id, file_key, bucket_name,
+		       original_name, file_size, mime_type, file_type, width, height,
+		       uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		       alt_text, description, variant, webp_file_key, duration_ms
+		FROM file_metadata
+		WHERE user_id = $1 AND file_type = $2 AND content_hash = $3 AND status = 'active'
+		ORDER BY uploaded_at DESC
+		LIMIT 1
+	`
+
+	var row models.FileMetadataRow
+	err := r.db.QueryRowContext(ctx, query, userID, fileType, contentHash).Scan(
+		&row.ID,
+		&row.UserID,
+		&row.DocumentID,
+		&row.BlockID,
+		&row.FileKey,
+		&row.BucketName,
+		&row.OriginalName,
+		&row.FileSize,
+		&row.MimeType,
+		&row.FileType,
+		&row.Width,
+		&row.Height,
+		&row.UploadedAt,
+		&row.Status,
+		&row.DeletedAt,
+		&row.Thumbnails,
+		&row.ThumbnailStatus,
+		&row.ContentHash,
+		&row.AltText,
+		&row.Description,
+		&row.Variant,
+		&row.WebPFileKey,
+		&row.DurationMs,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("file metadata contentHash=%s: %w", contentHash, apierror.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+
+	return row.ToFileMetadata(), nil
+}
+
 // GetByBlockID は ブロックIDでファイルメタデータを取得します
 func (r *FileRepository) GetByBlockID(ctx context.Context, blockID int) (*models.FileMetadata, error) {
 	query := `
 		SELECT id, user_id, document_id, block_id, file_key, bucket_name,
 		       original_name, file_size, mime_type, file_type, width, height,
-		       uploaded_at, status, deleted_at
+		       uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		       alt_text, description, variant, webp_file_key, duration_ms
 		FROM file_metadata
 		WHERE block_id = $1 AND status = 'active'
 	`
@@ -158,6 +316,14 @@ func (r *FileRepository) GetByBlockID(ctx context.Context, blockID int) (*models
 		&row.UploadedAt,
 		&row.Status,
 		&row.DeletedAt,
+		&row.Thumbnails,
+		&row.ThumbnailStatus,
+		&row.ContentHash,
+		&row.AltText,
+		&row.Description,
+		&row.Variant,
+		&row.WebPFileKey,
+		&row.DurationMs,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -175,7 +341,8 @@ func (r *FileRepository) ListByUserID(ctx context.Context, userID int) ([]*model
 	query := `
 		SELECT id, user_id, document_id, block_id, file_key, bucket_name,
 		       original_name, file_size, mime_type, file_type, width, height,
-		       uploaded_at, status, deleted_at
+		       uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		       alt_text, description, variant, webp_file_key, duration_ms
 		FROM file_metadata
 		WHERE user_id = $1 AND status = 'active'
 		ORDER BY uploaded_at DESC
@@ -206,6 +373,14 @@ func (r *FileRepository) ListByUserID(ctx context.Context, userID int) ([]*model
 			&row.UploadedAt,
 			&row.Status,
 			&row.DeletedAt,
+			&row.Thumbnails,
+			&row.ThumbnailStatus,
+			&row.ContentHash,
+			&row.AltText,
+			&row.Description,
+			&row.Variant,
+			&row.WebPFileKey,
+			&row.DurationMs,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan file metadata: %w", err)
@@ -217,6 +392,187 @@ func (r *FileRepository) ListByUserID(ctx context.Context, userID int) ([]*model
 	return files, nil
 }
 
+// fileListOrderColumns は ファイル一覧の並び順に指定できる列です（ORDER BY に埋め込むため固定の列のみ）
+var fileListOrderColumns = map[string]struct {
+	column string
+	kind   pagination.KeyKind
+}{
+	"uploadedAt": {column: "uploaded_at", kind: pagination.TimeKey},
+	"name":       {column: "LOWER(original_name)", kind: pagination.TextKey},
+	"size":       {column: "file_size", kind: pagination.NumberKey},
+}
+
+// fileListConditions は ファイル一覧の絞り込み条件です（$1〜$7 は ListFiles の引数の順）
+const fileListConditions = `
+		WHERE user_id = $1 AND status = 'active'
+		  AND ($2 = '' OR file_type = $2)
+		  AND ($3 = '' OR mime_type = $3)
+		  AND ($4 = '' OR starts_with(mime_type, $4))
+		  AND ($5::timestamp IS NULL OR uploaded_at >= $5)
+		  AND ($6::timestamp IS NULL OR uploaded_at < $6)
+		  AND ($7::integer IS NULL OR document_id = $7)
+`
+
+// ListFiles は ユーザーの削除されていないファイルを条件で絞り込み、1ページ分と条件に一致する全件数を取得します
+// opts.MimeType が "image/*" の形式の場合は "image/" で始まる MIME タイプに一致する
+// カーソルの並び替えキーは列の値の ::text（LOWER などの式の結果を Go で再現しないため）
+func (r *FileRepository) ListFiles(ctx context.Context, userID int, opts models.FileListOptions) (pagination.Page[*models.FileMetadata], int, error) {
+	sort := opts.Sort
+	order, ok := fileListOrderColumns[sort]
+	if !ok {
+		sort = "uploadedAt"
+		order = fileListOrderColumns[sort]
+	}
+	direction, comparison := "DESC", "<"
+	if opts.Order == "asc" {
+		direction, comparison = "ASC", ">"
+	}
+	page := pagination.Params{Limit: opts.Limit, After: opts.After}
+	cursorSort := sort + ":" + strings.ToLower(direction)
+	if err := page.Check(cursorSort, order.kind); err != nil {
+		return pagination.Page[*models.FileMetadata]{}, 0, err
+	}
+
+	mimeType, mimePrefix := opts.MimeType, ""
+	if strings.HasSuffix(mimeType, "/*") {
+		mimeType, mimePrefix = "", strings.TrimSuffix(opts.MimeType, "*")
+	}
+	args := []interface{}{userID, opts.FileType, mimeType, mimePrefix, opts.UploadedFrom, opts.UploadedTo, opts.DocumentID}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM file_metadata`+fileListConditions, args...).Scan(&total); err != nil {
+		return pagination.Page[*models.FileMetadata]{}, 0, fmt.Errorf("failed to count file metadata: %w", err)
+	}
+
+	query := `
+		SELECT id, user_id, document_id, block_id, file_key, bucket_name,
+		       original_name, file_size, mime_type, file_type, width, height,
+		       uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		       alt_text, description, variant, webp_file_key, duration_ms, (` + order.column + `)::text
+		FROM file_metadata` + fileListConditions + fmt.Sprintf(`
+		  AND ($9::int IS NULL OR (%[1]s, id) %[2]s ($8, $9))
+		ORDER BY %[1]s %[3]s, id %[3]s
+		LIMIT $10
+	`, order.column, comparison, direction)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, page.AfterKey(0), page.AfterID(), page.FetchLimit())...)
+	if err != nil {
+		return pagination.Page[*models.FileMetadata]{}, 0, fmt.Errorf("failed to list file metadata: %w", err)
+	}
+	defer rows.Close()
+
+	files := make([]*models.FileMetadata, 0)
+	var keys []string
+	for rows.Next() {
+		var row models.FileMetadataRow
+		var key string
+		err := rows.Scan(
+			&row.ID,
+			&row.UserID,
+			&row.DocumentID,
+			&row.BlockID,
+			&row.FileKey,
+			&row.BucketName,
+			&row.OriginalName,
+			&row.FileSize,
+			&row.MimeType,
+			&row.FileType,
+			&row.Width,
+			&row.Height,
+			&row.UploadedAt,
+			&row.Status,
+			&row.DeletedAt,
+			&row.Thumbnails,
+			&row.ThumbnailStatus,
+			&row.ContentHash,
+			&row.AltText,
+			&row.Description,
+			&row.Variant,
+			&row.WebPFileKey,
+			&row.DurationMs,
+			&key,
+		)
+		if err != nil {
+			return pagination.Page[*models.FileMetadata]{}, 0, fmt.Errorf("failed to scan file metadata: %w", err)
+		}
+
+		files = append(files, row.ToFileMetadata())
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*models.FileMetadata]{}, 0, fmt.Errorf("failed to list file metadata: %w", err)
+	}
+
+	return pagination.NewPage(files, page, func(i int) pagination.Cursor {
+		return pagination.Cursor{Sort: cursorSort, Keys: []string{keys[i]}, ID: files[i].ID}
+	}), total, nil
+}
+
+// ListByDocumentIDs は 複数の文書に紐付いたユーザーの削除されていないファイルを、文書ごとに新しい順で最大 limit 件ずつ取得します
+// 文書ごとに ListFiles（documentId で絞り込み）を呼ぶ代わりに、1回の問い合わせで取得する
+func (r *FileRepository) ListByDocumentIDs(ctx context.Context, userID int, documentIDs []int, limit int) ([]*models.FileMetadata, error) {
+	if len(documentIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, user_id, document_id, block_id, file_key, bucket_name,
+		       original_name, file_size, mime_type, file_type, width, height,
+		       uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		       alt_text, description, variant, webp_file_key, duration_ms
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY document_id ORDER BY uploaded_at DESC, id DESC) AS file_rank
+			FROM file_metadata
+			WHERE user_id = $1 AND status = 'active' AND document_id = ANY($2)
+		) ranked
+		WHERE file_rank <= $3
+		ORDER BY document_id, uploaded_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(documentIDs), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list file metadata by documents: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*models.FileMetadata
+	for rows.Next() {
+		var row models.FileMetadataRow
+		err := rows.Scan(
+			&row.ID,
+			&row.UserID,
+			&row.DocumentID,
+			&row.BlockID,
+			&row.FileKey,
+			&row.BucketName,
+			&row.OriginalName,
+			&row.FileSize,
+			&row.MimeType,
+			&row.FileType,
+			&row.Width,
+			&row.Height,
+			&row.UploadedAt,
+			&row.Status,
+			&row.DeletedAt,
+			&row.Thumbnails,
+			&row.ThumbnailStatus,
+			&row.ContentHash,
+			&row.AltText,
+			&row.Description,
+			&row.Variant,
+			&row.WebPFileKey,
+			&row.DurationMs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file metadata: %w", err)
+		}
+
+		files = append(files, row.ToFileMetadata())
+	}
+
+	return files, rows.Err()
+}
+
 // UpdateStatus は ファイルのステータスを更新します
 func (r *FileRepository) UpdateStatus(ctx context.Context, id int, status string) error {
 	query := `
@@ -242,19 +598,163 @@ func (r *FileRepository) UpdateStatus(ctx context.Context, id int, status string
 	return nil
 }
 
-// MarkAsDeleted は ファイルを削除済みとしてマークします（ソフトデリート）
+// MarkAsDeleted は ファイルを削除済みとしてマークし（ソフトデリート）、オブジェクトの参照数を減らします
+// ストレージからの削除は保持期間の経過後に ClaimPurgeableFiles で取得して行う
+// 削除済みのファイルは ErrNotFound を返す
 func (r *FileRepository) MarkAsDeleted(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE file_metadata
 		SET status = 'deleted', deleted_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND status <> 'deleted'
+		RETURNING file_key
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	var fileKey string
+	err = tx.QueryRowContext(ctx, query, id).Scan(&fileKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("file metadata id=%d: %w", id, apierror.ErrNotFound)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to mark file as deleted: %w", err)
 	}
 
+	if err := releaseFileObject(ctx, tx, fileKey); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// releaseFileObject は オブジェクトの参照数を減らし、参照がなくなった場合は記録を削除します
+func releaseFileObject(ctx context.Context, tx *sql.Tx, fileKey string) error {
+	query := `
+		UPDATE file_objects
+		SET ref_count = ref_count - 1
+		WHERE file_key = $1 AND ref_count > 0
+		RETURNING ref_count
+	`
+
+	var refCount int
+	err := tx.QueryRowContext(ctx, query, fileKey).Scan(&refCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release file object: %w", err)
+	}
+	if refCount > 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM file_objects WHERE file_key = $1`, fileKey); err != nil {
+		return fmt.Errorf("failed to delete file object: %w", err)
+	}
+	return nil
+}
+
+// ClaimPurgeableFiles は 削除から retention 以上経過したファイルを最大 limit 件取得し、lease の間は他の処理で取得されないようにします
+// 取得したファイルは削除の試行回数を増やす。削除に失敗したものは RecordPurgeFailure で次の試行日時を設定する
+func (r *FileRepository) ClaimPurgeableFiles(ctx context.Context, retention time.Duration, limit int, lease time.Duration) ([]*models.FileMetadata, error) {
+	query := `
+		UPDATE file_metadata
+		SET purge_attempts = purge_attempts + 1, next_purge_at = NOW() + $3 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM file_metadata
+			WHERE status = 'deleted'
+			  AND deleted_at < NOW() - $1 * INTERVAL '1 second'
+			  AND (next_purge_at IS NULL OR next_purge_at <= NOW())
+			ORDER BY deleted_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, document_id, block_id, file_key, bucket_name,
+		          original_name, file_size, mime_type, file_type, width, height,
+		          uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		          alt_text, description, variant, webp_file_key, duration_ms
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, int64(retention/time.Second), limit, int64(lease/time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim purgeable files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*models.FileMetadata
+	for rows.Next() {
+		var row models.FileMetadataRow
+		err := rows.Scan(
+			&row.ID,
+			&row.UserID,
+			&row.DocumentID,
+			&row.BlockID,
+			&row.FileKey,
+			&row.BucketName,
+			&row.OriginalName,
+			&row.FileSize,
+			&row.MimeType,
+			&row.FileType,
+			&row.Width,
+			&row.Height,
+			&row.UploadedAt,
+			&row.Status,
+			&row.DeletedAt,
+			&row.Thumbnails,
+			&row.ThumbnailStatus,
+			&row.ContentHash,
+			&row.AltText,
+			&row.Description,
+			&row.Variant,
+			&row.WebPFileKey,
+			&row.DurationMs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file metadata: %w", err)
+		}
+
+		files = append(files, row.ToFileMetadata())
+	}
+
+	return files, rows.Err()
+}
+
+// IsObjectReferenced は 削除されていないファイルがオブジェクトを参照しているかを返します
+func (r *FileRepository) IsObjectReferenced(ctx context.Context, fileKey string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM file_objects WHERE file_key = $1 AND ref_count > 0
+		) OR EXISTS (
+			SELECT 1 FROM file_metadata WHERE file_key = $1 AND status <> 'deleted'
+		)
+	`
+
+	var referenced bool
+	if err := r.db.QueryRowContext(ctx, query, fileKey).Scan(&referenced); err != nil {
+		return false, fmt.Errorf("failed to check file object references: %w", err)
+	}
+	return referenced, nil
+}
+
+// HardDelete は 削除済みのファイルメタデータを完全に削除します
+func (r *FileRepository) HardDelete(ctx context.Context, id int) error {
+	query := `
+		DELETE FROM file_metadata
+		WHERE id = $1 AND status = 'deleted'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to hard delete file metadata: %w", err)
+	}
+
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
@@ -267,12 +767,28 @@ func (r *FileRepository) MarkAsDeleted(ctx context.Context, id int) error {
 	return nil
 }
 
+// RecordPurgeFailure は 完全削除に失敗したことを記録し、試行回数に応じて次の試行を遅らせます（最大1日）
+func (r *FileRepository) RecordPurgeFailure(ctx context.Context, id int, purgeErr error) error {
+	query := `
+		UPDATE file_metadata
+		SET last_purge_error = $1,
+		    next_purge_at = NOW() + LEAST(POWER(2, purge_attempts), 1440) * INTERVAL '1 minute'
+		WHERE id = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, purgeErr.Error(), id); err != nil {
+		return fmt.Errorf("failed to record purge failure: %w", err)
+	}
+	return nil
+}
+
 // GetOrphanedFiles は 孤立したファイルのリストを取得します
 func (r *FileRepository) GetOrphanedFiles(ctx context.Context) ([]*models.FileMetadata, error) {
 	query := `
 		SELECT id, user_id, document_id, block_id, file_key, bucket_name,
 		       original_name, file_size, mime_type, file_type, width, height,
-		       uploaded_at, status, deleted_at
+		       uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		       alt_text, description, variant, webp_file_key, duration_ms
 		FROM orphaned_files
 	`
 
@@ -301,6 +817,14 @@ func (r *FileRepository) GetOrphanedFiles(ctx context.Context) ([]*models.FileMe
 			&row.UploadedAt,
 			&row.Status,
 			&row.DeletedAt,
+			&row.Thumbnails,
+			&row.ThumbnailStatus,
+			&row.ContentHash,
+			&row.AltText,
+			&row.Description,
+			&row.Variant,
+			&row.WebPFileKey,
+			&row.DurationMs,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan orphaned file: %w", err)
@@ -312,6 +836,42 @@ func (r *FileRepository) GetOrphanedFiles(ctx context.Context) ([]*models.FileMe
 	return files, nil
 }
 
+// GetUserStorageQuota は ユーザーに個別に設定されたストレージクォータを取得します（未設定の場合は nil）
+func (r *FileRepository) GetUserStorageQuota(ctx context.Context, userID int) (*int64, error) {
+	query := `SELECT storage_quota FROM users WHERE id = $1`
+
+	var quota sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&quota); err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("user id=%d", userID))
+	}
+
+	if !quota.Valid {
+		return nil, nil
+	}
+	return &quota.Int64, nil
+}
+
+// SetUserStorageQuota は ユーザーのストレージクォータを設定します（nil の場合は個別の設定を解除する）
+func (r *FileRepository) SetUserStorageQuota(ctx context.Context, userID int, quota *int64) error {
+	query := `UPDATE users SET storage_quota = $1, updated_at = NOW() WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, quota, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set storage quota: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user id=%d: %w", userID, apierror.ErrNotFound)
+	}
+
+	return nil
+}
+
 // GetUserStorageUsage は ユーザーのストレージ使用量を取得します
 func (r *FileRepository) GetUserStorageUsage(ctx context.Context, userID int) (*models.UserStorageUsage, error) {
 	query := `
@@ -344,6 +904,135 @@ func (r *FileRepository) GetUserStorageUsage(ctx context.Context, userID int) (*
 	return &usage, nil
 }
 
+// GetStorageUsageTotals は インスタンス全体のファイルの件数と合計サイズを状態ごとに集計します
+// 戻り値は StorageUsageReport の合計の項目だけを設定したもの
+func (r *FileRepository) GetStorageUsageTotals(ctx context.Context) (*models.StorageUsageReport, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'active'),
+			COALESCE(SUM(file_size) FILTER (WHERE status = 'active'), 0),
+			COUNT(DISTINCT user_id) FILTER (WHERE status = 'active'),
+			COALESCE(SUM(file_size) FILTER (WHERE status = 'orphaned'), 0),
+			COUNT(*) FILTER (WHERE status = 'deleted'),
+			COALESCE(SUM(file_size) FILTER (WHERE status = 'deleted'), 0),
+			(SELECT COUNT(*) FROM file_versions),
+			(SELECT COALESCE(SUM(file_size), 0) FROM file_versions)
+		FROM file_metadata
+	`
+
+	var report models.StorageUsageReport
+	err := r.db.QueryRowContext(ctx, query).Scan(
+		&report.FileCount,
+		&report.TotalBytes,
+		&report.UserCount,
+		&report.OrphanedBytes,
+		&report.PendingPurgeCount,
+		&report.PendingPurgeBytes,
+		&report.VersionCount,
+		&report.VersionBytes,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage usage totals: %w", err)
+	}
+
+	return &report, nil
+}
+
+// GetStorageUsageByType は 使用中のファイルの件数と合計サイズを種類ごとに集計します（合計サイズの大きい順）
+func (r *FileRepository) GetStorageUsageByType(ctx context.Context) ([]models.FileTypeUsage, error) {
+	query := `
+		SELECT file_type, COUNT(*), SUM(file_size)
+		FROM file_metadata
+		WHERE status = 'active'
+		GROUP BY file_type
+		ORDER BY SUM(file_size) DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage usage by type: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make([]models.FileTypeUsage, 0)
+	for rows.Next() {
+		var u models.FileTypeUsage
+		if err := rows.Scan(&u.FileType, &u.FileCount, &u.TotalBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan storage usage by type: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
+
+// ListTopStorageUsers は 使用量の多いユーザーを最大 limit 件取得します
+// クォータが個別に設定されていないユーザーは defaultQuota を使う
+func (r *FileRepository) ListTopStorageUsers(ctx context.Context, defaultQuota int64, limit int) ([]models.UserStorageEntry, error) {
+	query := `
+		SELECT u.id, u.email, usage.file_count, usage.total_bytes,
+		       COALESCE(u.storage_quota, $1), u.storage_quota IS NULL
+		FROM user_storage_usage usage
+		JOIN users u ON u.id = usage.user_id
+		ORDER BY usage.total_bytes DESC, u.id
+		LIMIT $2
+	`
+
+	return r.listUserStorage(ctx, query, defaultQuota, limit)
+}
+
+// CountQuotaViolations は 使用量がクォータを超えているユーザー数を取得します
+func (r *FileRepository) CountQuotaViolations(ctx context.Context, defaultQuota int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM user_storage_usage usage
+		JOIN users u ON u.id = usage.user_id
+		WHERE usage.total_bytes > COALESCE(u.storage_quota, $1)
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, defaultQuota).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count quota violations: %w", err)
+	}
+	return count, nil
+}
+
+// ListQuotaViolations は 使用量がクォータを超えているユーザーを、超えている量の多い順に最大 limit 件取得します
+// クォータを使用量より小さく変更した場合や、クォータの確認とアップロードが同時に行われた場合に発生する
+func (r *FileRepository) ListQuotaViolations(ctx context.Context, defaultQuota int64, limit int) ([]models.UserStorageEntry, error) {
+	query := `
+		SELECT u.id, u.email, usage.file_count, usage.total_bytes,
+		       COALESCE(u.storage_quota, $1), u.storage_quota IS NULL
+		FROM user_storage_usage usage
+		JOIN users u ON u.id = usage.user_id
+		WHERE usage.total_bytes > COALESCE(u.storage_quota, $1)
+		ORDER BY usage.total_bytes - COALESCE(u.storage_quota, $1) DESC, u.id
+		LIMIT $2
+	`
+
+	return r.listUserStorage(ctx, query, defaultQuota, limit)
+}
+
+// listUserStorage は ユーザーごとの使用量とクォータを返すクエリを実行します
+func (r *FileRepository) listUserStorage(ctx context.Context, query string, args ...interface{}) ([]models.UserStorageEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user storage usage: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.UserStorageEntry, 0)
+	for rows.Next() {
+		var e models.UserStorageEntry
+		if err := rows.Scan(&e.UserID, &e.Email, &e.FileCount, &e.UsedBytes, &e.QuotaBytes, &e.IsDefault); err != nil {
+			return nil, fmt.Errorf("failed to scan user storage usage: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
 // UpdateBlockID は ファイルメタデータのblock_idを更新します
 func (r *FileRepository) UpdateBlockID(ctx context.Context, fileID int, blockID int) error {
 	query := `
@@ -369,6 +1058,152 @@ func (r *FileRepository) UpdateBlockID(ctx context.Context, fileID int, blockID
 	return nil
 }
 
+// UpdateAttachment は ファイルメタデータの紐付け先（document_id と block_id）を更新します
+func (r *FileRepository) UpdateAttachment(ctx context.Context, fileID int, documentID int, blockID int) error {
+	query := `
+		UPDATE file_metadata
+		SET document_id = $1, block_id = $2
+		WHERE id = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, documentID, blockID, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to update attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("file metadata id=%d: %w", fileID, apierror.ErrNotFound)
+	}
+
+	return nil
+}
+
+// Attach は ファイルをユーザーが編集できる文書（blockID を指定した場合はそのブロック）に紐付け、orphaned のファイルを有効に戻します
+// 編集できるのは所有者・editor の共同編集者・文書が属するワークスペースの guest 以外のメンバー
+// 文書を編集できない・削除済みの場合、ブロックがその文書にない場合は ErrNotFound を返す
+func (r *FileRepository) Attach(ctx context.Context, fileID int, userID int, documentID int, blockID *int) error {
+	query := `
+		UPDATE file_metadata
+		SET document_id = $1, block_id = $2, status = 'active', orphaned_at = NULL
+		WHERE id = $3
+		  AND status <> 'deleted'
+		  AND EXISTS (
+			SELECT 1 FROM documents d
+			WHERE d.id = $1 AND d.deleted_at IS NULL
+			  AND (d.user_id = $4
+			    OR EXISTS (
+				SELECT 1 FROM document_collaborators c
+				WHERE c.document_id = d.id AND c.user_id = $4 AND c.role = 'editor'
+			    )
+			    OR EXISTS (
+				SELECT 1 FROM workspace_members m
+				WHERE m.workspace_id = d.workspace_id AND m.user_id = $4 AND m.role IN ('owner', 'admin', 'member')
+			    ))
+		  )
+		  AND ($2::int IS NULL OR EXISTS (
+			SELECT 1 FROM blocks b WHERE b.id = $2 AND b.document_id = $1
+		  ))
+	`
+
+	result, err := r.db.ExecContext(ctx, query, documentID, blockID, fileID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to attach file: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("file metadata id=%d document id=%d: %w", fileID, documentID, apierror.ErrNotFound)
+	}
+
+	return nil
+}
+
+// Detach は ファイルの紐付けを外して orphaned にします（猶予期間の経過後に孤立ファイルとしてクリーンアップされる）
+// 既に orphaned のファイルは外した時刻を変えない
+func (r *FileRepository) Detach(ctx context.Context, fileID int) error {
+	query := `
+		UPDATE file_metadata
+		SET document_id = NULL, block_id = NULL, status = 'orphaned', orphaned_at = COALESCE(orphaned_at, NOW())
+		WHERE id = $1 AND status IN ('active', 'orphaned')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to detach file: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("file metadata id=%d: %w", fileID, apierror.ErrNotFound)
+	}
+
+	return nil
+}
+
+// UpdateDetails は ファイルの名前・代替テキスト・説明を更新し、ファイルを参照しているユーザーの画像・ファイル・音声ブロックにも反映します
+// ブロックの content の originalName は常に更新し、alt は syncAlt が true の場合のみ更新する
+func (r *FileRepository) UpdateDetails(ctx context.Context, file *models.FileMetadata, syncAlt bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE file_metadata
+		SET original_name = $1, alt_text = $2, description = $3
+		WHERE id = $4 AND status = 'active'
+	`
+
+	result, err := tx.ExecContext(ctx, query, file.OriginalName, file.AltText, file.Description, file.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update file details: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("file metadata id=%d: %w", file.ID, apierror.ErrNotFound)
+	}
+
+	blockQuery := `
+		UPDATE blocks b
+		SET content = b.content || jsonb_build_object('originalName', $1::text)
+		                        || CASE WHEN $2 THEN jsonb_build_object('alt', $3::text) ELSE '{}'::jsonb END
+		FROM documents d
+		WHERE b.document_id = d.id
+		  AND d.user_id = $4
+		  AND b.type IN ('image', 'file', 'audio')
+		  AND jsonb_typeof(b.content) = 'object'
+		  AND b.content->>'fileId' = $5::text
+	`
+
+	if _, err := tx.ExecContext(ctx, blockQuery, file.OriginalName, syncAlt, file.AltText, file.UserID, file.ID); err != nil {
+		return fmt.Errorf("failed to update file blocks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // GetByFilename は ファイル名の末尾からファイルメタデータを検索します
 // file_keyが "prefix/userID/uuid_filename" の形式になっているため、
 // ファイル名の末尾でマッチするレコードを検索します
@@ -376,7 +1211,8 @@ func (r *FileRepository) GetByFilename(ctx context.Context, filename string) (*m
 	query := `
 		SELECT id, user_id, document_id, block_id, file_key, bucket_name,
 		       original_name, file_size, mime_type, file_type, width, height,
-		       uploaded_at, status, deleted_at
+		       uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		       alt_text, description, variant, webp_file_key, duration_ms
 		FROM file_metadata
 		WHERE file_key LIKE '%' || $1 AND status = 'active'
 		ORDER BY uploaded_at DESC
@@ -400,6 +1236,14 @@ func (r *FileRepository) GetByFilename(ctx context.Context, filename string) (*m
 		&row.UploadedAt,
 		&row.Status,
 		&row.DeletedAt,
+		&row.Thumbnails,
+		&row.ThumbnailStatus,
+		&row.ContentHash,
+		&row.AltText,
+		&row.Description,
+		&row.Variant,
+		&row.WebPFileKey,
+		&row.DurationMs,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -411,3 +1255,130 @@ func (r *FileRepository) GetByFilename(ctx context.Context, filename string) (*m
 
 	return row.ToFileMetadata(), nil
 }
+
+// ClaimPendingThumbnails は サムネイルの作成待ちの画像を最大 limit 件取得し、作成中に変更します
+// 作成中のまま staleAfter を過ぎたもの（処理中に停止した場合など）も再度取得する
+// 複数のプロセスで同時に実行しても同じ画像を重複して取得しない
+func (r *FileRepository) ClaimPendingThumbnails(ctx context.Context, limit int, staleAfter time.Duration) ([]*models.FileMetadata, error) {
+	query := `
+		UPDATE file_metadata
+		SET thumbnail_status = 'processing', thumbnail_updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM file_metadata
+			WHERE status = 'active'
+			  AND (thumbnail_status = 'pending'
+			       OR (thumbnail_status = 'processing' AND thumbnail_updated_at < NOW() - $2 * INTERVAL '1 second'))
+			ORDER BY uploaded_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, document_id, block_id, file_key, bucket_name,
+		          original_name, file_size, mime_type, file_type, width, height,
+		          uploaded_at, status, deleted_at, thumbnails, thumbnail_status, content_hash,
+		          alt_text, description, variant, webp_file_key, duration_ms
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, int64(staleAfter/time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending thumbnails: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*models.FileMetadata
+	for rows.Next() {
+		var row models.FileMetadataRow
+		err := rows.Scan(
+			&row.ID,
+			&row.UserID,
+			&row.DocumentID,
+			&row.BlockID,
+			&row.FileKey,
+			&row.BucketName,
+			&row.OriginalName,
+			&row.FileSize,
+			&row.MimeType,
+			&row.FileType,
+			&row.Width,
+			&row.Height,
+			&row.UploadedAt,
+			&row.Status,
+			&row.DeletedAt,
+			&row.Thumbnails,
+			&row.ThumbnailStatus,
+			&row.ContentHash,
+			&row.AltText,
+			&row.Description,
+			&row.Variant,
+			&row.WebPFileKey,
+			&row.DurationMs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file metadata: %w", err)
+		}
+
+		files = append(files, row.ToFileMetadata())
+	}
+
+	return files, rows.Err()
+}
+
+// UpdateThumbnails は サムネイルのキーと作成状況を記録します
+func (r *FileRepository) UpdateThumbnails(ctx context.Context, id int, thumbnails map[string]string, status string) error {
+	encoded, err := json.Marshal(thumbnails)
+	if err != nil {
+		return fmt.Errorf("failed to encode thumbnails: %w", err)
+	}
+
+	query := `
+		UPDATE file_metadata
+		SET thumbnails = $1, thumbnail_status = $2, thumbnail_updated_at = NOW()
+		WHERE id = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, encoded, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update thumbnails: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("file metadata id=%d: %w", id, apierror.ErrNotFound)
+	}
+
+	return nil
+}
+
+// SetWebPVariant は 元の画像を残して WebP を別に保存したことを記録します
+// 同じオブジェクトを参照するすべてのファイルメタデータを更新する
+func (r *FileRepository) SetWebPVariant(ctx context.Context, fileKey, webpFileKey string) error {
+	query := `
+		UPDATE file_metadata
+		SET variant = 'webp', webp_file_key = $2
+		WHERE file_key = $1 AND variant = 'original'
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, fileKey, webpFileKey); err != nil {
+		return fmt.Errorf("failed to set webp variant: %w", err)
+	}
+	return nil
+}
+
+// ReplaceWithWebP は オブジェクトの内容を WebP で置き換えたことを記録します
+// 同じオブジェクトを参照するすべてのファイルメタデータの形式・サイズ・内容のハッシュを更新する
+func (r *FileRepository) ReplaceWithWebP(ctx context.Context, fileKey string, fileSize int64, contentHash string) error {
+	query := `
+		UPDATE file_metadata
+		SET variant = 'webp', webp_file_key = NULL, mime_type = 'image/webp',
+		    file_size = $2, content_hash = $3
+		WHERE file_key = $1 AND variant = 'original'
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, fileKey, fileSize, contentHash); err != nil {
+		return fmt.Errorf("failed to replace with webp: %w", err)
+	}
+	return nil
+}
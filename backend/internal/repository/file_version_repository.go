@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// FileVersionRepository は file_versions テーブル（ファイルの以前の版）へのデータアクセスを提供します
+// 以前の版は ストレージ上のオブジェクトの参照数（file_objects.ref_count）を1つ持つ
+type FileVersionRepository struct {
+	db *sql.DB
+}
+
+// NewFileVersionRepository は 新しい FileVersionRepository インスタンスを作成します
+func NewFileVersionRepository(db *sql.DB) *FileVersionRepository {
+	return &FileVersionRepository{db: db}
+}
+
+// fileVersionColumns は 以前の版を取得するときの列です（scanFileVersions と同じ順）
+const fileVersionColumns = `
+	id, file_id, version_number, file_key, original_name, file_size, mime_type,
+	content_hash, width, height, duration_ms, thumbnails, variant, webp_file_key,
+	uploaded_at, replaced_at
+`
+
+// GetCurrentVersion は 削除されていないファイルの現在の版の番号を取得します
+func (r *FileVersionRepository) GetCurrentVersion(ctx context.Context, fileID int) (int, error) {
+	query := `
+		SELECT version FROM file_metadata
+		WHERE id = $1 AND status <> 'deleted'
+	`
+
+	var version int
+	err := r.db.QueryRowContext(ctx, query, fileID).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("file metadata id=%d: %w", fileID, apierror.ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current file version: %w", err)
+	}
+	return version, nil
+}
+
+// ListByFileID は ファイルの以前の版を新しい順に取得します
+func (r *FileVersionRepository) ListByFileID(ctx context.Context, fileID int) ([]*models.FileVersion, error) {
+	query := `SELECT ` + fileVersionColumns + `
+		FROM file_versions
+		WHERE file_id = $1
+		ORDER BY version_number DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list file versions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanFileVersions(rows)
+}
+
+// GetByID は ファイルの以前の版を取得します（別のファイルの版の場合は ErrNotFound）
+func (r *FileVersionRepository) GetByID(ctx context.Context, fileID, versionID int) (*models.FileVersion, error) {
+	query := `SELECT ` + fileVersionColumns + `
+		FROM file_versions
+		WHERE id = $1 AND file_id = $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, versionID, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file version: %w", err)
+	}
+	defer rows.Close()
+
+	versions, err := scanFileVersions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("file version id=%d: %w", versionID, apierror.ErrNotFound)
+	}
+	return versions[0], nil
+}
+
+// ListExpired は 置き換えてから retention 以上経過した以前の版を古い順に最大 limit 件取得します
+func (r *FileVersionRepository) ListExpired(ctx context.Context, retention time.Duration, limit int) ([]*models.FileVersion, error) {
+	query := `SELECT ` + fileVersionColumns + `
+		FROM file_versions
+		WHERE replaced_at < NOW() - $1 * INTERVAL '1 second'
+		ORDER BY replaced_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, int64(retention/time.Second), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired file versions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanFileVersions(rows)
+}
+
+// ReplaceCurrent は 新しく保存したオブジェクト（file.FileKey）をファイルの新しい版にします
+// 置き換える前の版は以前の版として記録し、オブジェクトの参照はその版に引き継ぐ
+// ファイルを参照しているユーザーのブロックの fileKey・src なども新しい版に合わせて更新する
+// 削除済みのファイルは ErrNotFound を返す
+func (r *FileVersionRepository) ReplaceCurrent(ctx context.Context, file *models.FileMetadata, src string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := archiveCurrentVersion(ctx, tx, file.ID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO file_objects (file_key, ref_count) VALUES ($1, 1)`, file.FileKey); err != nil {
+		return fmt.Errorf("failed to register file object: %w", err)
+	}
+	if err := updateCurrentVersion(ctx, tx, file, src); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Restore は 以前の版（versionID）の内容を file に設定した内容で、ファイルの新しい版にします
+// 置き換える前の版は以前の版として記録する。復元した版の記録は残し、オブジェクトの参照数を増やす
+// 削除済みのファイル・存在しない版は ErrNotFound を返す
+func (r *FileVersionRepository) Restore(ctx context.Context, file *models.FileMetadata, versionID int, src string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := archiveCurrentVersion(ctx, tx, file.ID); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE file_objects
+		SET ref_count = ref_count + 1
+		WHERE ref_count > 0
+		  AND file_key = (SELECT file_key FROM file_versions WHERE id = $1 AND file_id = $2 AND file_key = $3)
+	`
+
+	result, err := tx.ExecContext(ctx, query, versionID, file.ID, file.FileKey)
+	if err != nil {
+		return fmt.Errorf("failed to add file object reference: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("file version id=%d: %w", versionID, apierror.ErrNotFound)
+	}
+
+	if err := updateCurrentVersion(ctx, tx, file, src); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// IsObjectShared は 以前の版のオブジェクトを、その版以外（他の版・ファイル）も参照しているかを返します
+func (r *FileVersionRepository) IsObjectShared(ctx context.Context, fileKey string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM file_objects WHERE file_key = $1 AND ref_count > 1
+		) OR EXISTS (
+			SELECT 1 FROM file_metadata WHERE file_key = $1 AND status <> 'deleted'
+		)
+	`
+
+	var shared bool
+	if err := r.db.QueryRowContext(ctx, query, fileKey).Scan(&shared); err != nil {
+		return false, fmt.Errorf("failed to check file object references: %w", err)
+	}
+	return shared, nil
+}
+
+// Delete は 以前の版の記録を削除し、オブジェクトの参照数を減らします
+func (r *FileVersionRepository) Delete(ctx context.Context, versionID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var fileKey string
+	err = tx.QueryRowContext(ctx, `DELETE FROM file_versions WHERE id = $1 RETURNING file_key`, versionID).Scan(&fileKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("file version id=%d: %w", versionID, apierror.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete file version: %w", err)
+	}
+
+	if err := releaseFileObject(ctx, tx, fileKey); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// archiveCurrentVersion は ファイルの現在の版を以前の版として記録します（ファイルの行はトランザクションの終了までロックする）
+func archiveCurrentVersion(ctx context.Context, tx *sql.Tx, fileID int) error {
+	var locked int
+	err := tx.QueryRowContext(ctx, `SELECT id FROM file_metadata WHERE id = $1 AND status = 'active' FOR UPDATE`, fileID).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("file metadata id=%d: %w", fileID, apierror.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock file metadata: %w", err)
+	}
+
+	query := `
+		INSERT INTO file_versions
+		(file_id, version_number, file_key, original_name, file_size, mime_type,
+		 content_hash, width, height, duration_ms, thumbnails, variant, webp_file_key, uploaded_at)
+		SELECT id, version, file_key, original_name, file_size, mime_type,
+		       content_hash, width, height, duration_ms, thumbnails, variant, webp_file_key, uploaded_at
+		FROM file_metadata
+		WHERE id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, fileID); err != nil {
+		return fmt.Errorf("failed to archive file version: %w", err)
+	}
+	return nil
+}
+
+// updateCurrentVersion は ファイルの内容を file の内容に置き換えて版の番号を増やし、file に版の番号とアップロード日時を設定します
+// ファイルを参照しているユーザーの画像・ファイル・音声ブロックの content も更新する
+func updateCurrentVersion(ctx context.Context, tx *sql.Tx, file *models.FileMetadata, src string) error {
+	thumbnails := file.Thumbnails
+	if thumbnails == nil {
+		thumbnails = map[string]string{}
+	}
+	encodedThumbnails, err := json.Marshal(thumbnails)
+	if err != nil {
+		return fmt.Errorf("failed to encode thumbnails: %w", err)
+	}
+
+	query := `
+		UPDATE file_metadata
+		SET file_key = $2, original_name = $3, file_size = $4, mime_type = $5,
+		    content_hash = NULLIF($6, ''), width = $7, height = $8, duration_ms = $9,
+		    thumbnails = $10, thumbnail_status = COALESCE(NULLIF($11, ''), 'none'),
+		    variant = COALESCE(NULLIF($12, ''), 'original'), webp_file_key = NULLIF($13, ''),
+		    version = version + 1, uploaded_at = NOW()
+		WHERE id = $1
+		RETURNING version, uploaded_at
+	`
+
+	var version int
+	err = tx.QueryRowContext(
+		ctx, query,
+		file.ID,
+		file.FileKey,
+		file.OriginalName,
+		file.FileSize,
+		file.MimeType,
+		file.ContentHash,
+		file.Width,
+		file.Height,
+		file.DurationMs,
+		encodedThumbnails,
+		file.ThumbnailStatus,
+		file.Variant,
+		file.WebPFileKey,
+	).Scan(&version, &file.UploadedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update file version: %w", err)
+	}
+
+	blockQuery := `
+		UPDATE blocks b
+		SET content = b.content || jsonb_build_object(
+		        'fileKey', $1::text, 'src', $2::text, 'originalName', $3::text,
+		        'fileSize', $4::bigint, 'mimeType', $5::text)
+		FROM documents d
+		WHERE b.document_id = d.id
+		  AND d.user_id = $6
+		  AND b.type IN ('image', 'file', 'audio')
+		  AND jsonb_typeof(b.content) = 'object'
+		  AND b.content->>'fileId' = $7::text
+	`
+
+	_, err = tx.ExecContext(ctx, blockQuery, file.FileKey, src, file.OriginalName, file.FileSize, file.MimeType, file.UserID, file.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update file blocks: %w", err)
+	}
+	return nil
+}
+
+// scanFileVersions は 以前の版の行を読み込みます
+func scanFileVersions(rows *sql.Rows) ([]*models.FileVersion, error) {
+	var versions []*models.FileVersion
+	for rows.Next() {
+		var (
+			version     models.FileVersion
+			contentHash sql.NullString
+			width       sql.NullInt64
+			height      sql.NullInt64
+			durationMs  sql.NullInt64
+			thumbnails  []byte
+			webpFileKey sql.NullString
+		)
+		err := rows.Scan(
+			&version.ID,
+			&version.FileID,
+			&version.VersionNumber,
+			&version.FileKey,
+			&version.OriginalName,
+			&version.FileSize,
+			&version.MimeType,
+			&contentHash,
+			&width,
+			&height,
+			&durationMs,
+			&thumbnails,
+			&version.Variant,
+			&webpFileKey,
+			&version.UploadedAt,
+			&version.ReplacedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file version: %w", err)
+		}
+
+		version.ContentHash = contentHash.String
+		version.WebPFileKey = webpFileKey.String
+		if width.Valid && height.Valid {
+			w, h := int(width.Int64), int(height.Int64)
+			version.Width, version.Height = &w, &h
+		}
+		if durationMs.Valid {
+			version.DurationMs = &durationMs.Int64
+		}
+		if len(thumbnails) > 0 {
+			_ = json.Unmarshal(thumbnails, &version.Thumbnails)
+		}
+		versions = append(versions, &version)
+	}
+
+	return versions, rows.Err()
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/models"
+)
+
+// MaintenanceRepository - 孤立データの検出・修復専用リポジトリ
+type MaintenanceRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewMaintenanceRepository - MaintenanceRepositoryを初期化
+func NewMaintenanceRepository(db *sql.DB) (*MaintenanceRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &MaintenanceRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CountOrphanedBlocks - 文書が存在しないブロックの件数を取得
+func (r *MaintenanceRepository) CountOrphanedBlocks() (int, error) {
+	return r.count("CountOrphanedBlocks")
+}
+
+// ListOrphanedBlocks - 文書が存在しないブロックを最大 limit 件取得
+func (r *MaintenanceRepository) ListOrphanedBlocks(limit int) ([]models.OrphanedBlock, error) {
+	query, err := r.queries.Get("ListOrphanedBlocks")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := make([]models.OrphanedBlock, 0)
+	for rows.Next() {
+		var b models.OrphanedBlock
+		if err := rows.Scan(&b.ID, &b.DocumentID, &b.Type, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+
+	return blocks, rows.Err()
+}
+
+// DeleteOrphanedBlocks - 文書が存在しないブロックを削除
+func (r *MaintenanceRepository) DeleteOrphanedBlocks() (int64, error) {
+	return r.exec("DeleteOrphanedBlocks")
+}
+
+// CountDanglingFileReferences - 存在しないブロックを参照するファイルの件数を取得
+func (r *MaintenanceRepository) CountDanglingFileReferences() (int, error) {
+	return r.count("CountDanglingFileReferences")
+}
+
+// ListDanglingFileReferences - 存在しないブロックを参照するファイルを最大 limit 件取得
+func (r *MaintenanceRepository) ListDanglingFileReferences(limit int) ([]models.DanglingFileReference, error) {
+	query, err := r.queries.Get("ListDanglingFileReferences")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := make([]models.DanglingFileReference, 0)
+	for rows.Next() {
+		var f models.DanglingFileReference
+		if err := rows.Scan(&f.FileID, &f.UserID, &f.BlockID, &f.FileKey, &f.UploadedAt); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	return files, rows.Err()
+}
+
+// DetachDanglingFileReferences - 存在しないブロックへの参照を外し、ファイルを orphaned にする
+func (r *MaintenanceRepository) DetachDanglingFileReferences() (int64, error) {
+	return r.exec("DetachDanglingFileReferences")
+}
+
+// DeleteDanglingFileReferences - 存在しないブロックを参照するファイルを deleted にする
+func (r *MaintenanceRepository) DeleteDanglingFileReferences() (int64, error) {
+	return r.exec("DeleteDanglingFileReferences")
+}
+
+// ListReferencedObjectKeys - ファイル・以前の版・サムネイル・WebP・アップロード中のデータが参照するオブジェクトキーを取得
+func (r *MaintenanceRepository) ListReferencedObjectKeys() ([]string, error) {
+	query, err := r.queries.Get("ListReferencedObjectKeys")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// ListFileObjectReferences - 削除されていないファイルが参照するオブジェクトを取得
+func (r *MaintenanceRepository) ListFileObjectReferences() ([]models.FileObjectReference, error) {
+	query, err := r.queries.Get("ListFileObjectReferences")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := make([]models.FileObjectReference, 0)
+	for rows.Next() {
+		var f models.FileObjectReference
+		if err := rows.Scan(&f.FileID, &f.UserID, &f.FileKey, &f.Status, &f.UploadedAt); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	return files, rows.Err()
+}
+
+// count - 件数を返すクエリを実行
+func (r *MaintenanceRepository) count(name string) (int, error) {
+	query, err := r.queries.Get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	if err := r.db.QueryRow(query).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	return n, nil
+}
+
+// exec - 更新系クエリを実行して影響行数を返す
+func (r *MaintenanceRepository) exec(name string) (int64, error) {
+	query, err := r.queries.Get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.Exec(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	return result.RowsAffected()
+}
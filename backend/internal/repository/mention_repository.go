@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"simple-notion-backend/internal/models"
+)
+
+// MentionRepository - 文書・コメント内のメンション操作専用リポジトリ
+type MentionRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewMentionRepository - MentionRepositoryを初期化
+func NewMentionRepository(db *sql.DB) (*MentionRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &MentionRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// SyncMentions - 書き込み元のメンションを mentions の内容に置き換え、新しく追加されたメンションを返す
+// mentions に含まれないユーザーへの既存のメンションは削除する（空の場合はすべて削除）
+func (r *MentionRepository) SyncMentions(docID int, sourceType models.MentionSourceType, sourceID, mentionedBy int, mentions []models.Mention) ([]models.Mention, error) {
+	listQuery, err := r.queries.Get("ListMentionUsers")
+	if err != nil {
+		return nil, err
+	}
+	deleteQuery, err := r.queries.Get("DeleteStaleMentions")
+	if err != nil {
+		return nil, err
+	}
+	upsertQuery, err := r.queries.Get("UpsertMention")
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(listQuery, docID, sourceType, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mentions: %w", err)
+	}
+	existing := make(map[int]bool)
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		existing[userID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(mentions))
+	for _, m := range mentions {
+		userIDs = append(userIDs, int64(m.UserID))
+	}
+	if _, err := tx.Exec(deleteQuery, docID, sourceType, sourceID, pq.Array(userIDs)); err != nil {
+		return nil, fmt.Errorf("failed to delete stale mentions: %w", err)
+	}
+
+	added := make([]models.Mention, 0)
+	for _, m := range mentions {
+		if _, err := tx.Exec(upsertQuery, docID, sourceType, sourceID, m.BlockID, m.UserID, mentionedBy); err != nil {
+			return nil, fmt.Errorf("failed to save mention: %w", err)
+		}
+		if !existing[m.UserID] {
+			m.DocumentID = docID
+			m.SourceType = sourceType
+			m.SourceID = sourceID
+			m.MentionedBy = mentionedBy
+			added = append(added, m)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit mentions: %w", err)
+	}
+	return added, nil
+}
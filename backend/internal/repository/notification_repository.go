@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
+
+// NotificationRepository - アプリ内通知操作専用リポジトリ
+type NotificationRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewNotificationRepository - NotificationRepositoryを初期化
+func NewNotificationRepository(db *sql.DB) (*NotificationRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &NotificationRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateNotification - 通知を作成
+func (r *NotificationRepository) CreateNotification(n *models.Notification) error {
+	query, err := r.queries.Get("CreateNotification")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, n.UserID, n.DocumentID, n.Type, n.Message, n.Link).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
+// ListNotifications - ユーザーの通知を新しい順に1ページ分取得
+func (r *NotificationRepository) ListNotifications(userID int, unreadOnly bool, page pagination.Params) (pagination.Page[models.Notification], error) {
+	query, err := r.queries.Get("ListNotifications")
+	if err != nil {
+		return pagination.Page[models.Notification]{}, err
+	}
+	if err := page.Check("", pagination.TimeKey); err != nil {
+		return pagination.Page[models.Notification]{}, err
+	}
+
+	rows, err := r.db.Query(query, userID, unreadOnly, page.AfterKey(0), page.AfterID(), page.FetchLimit())
+	if err != nil {
+		return pagination.Page[models.Notification]{}, err
+	}
+	defer rows.Close()
+
+	notifications := make([]models.Notification, 0)
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(&n.ID, &n.UserID, &n.DocumentID, &n.Type, &n.Message, &n.Link, &n.ReadAt, &n.CreatedAt)
+		if err != nil {
+			return pagination.Page[models.Notification]{}, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.Notification]{}, err
+	}
+
+	return pagination.NewPage(notifications, page, func(i int) pagination.Cursor {
+		n := notifications[i]
+		return pagination.Cursor{Keys: []string{pagination.FormatTime(n.CreatedAt)}, ID: n.ID}
+	}), nil
+}
+
+// MarkRead - 通知を既読にする
+func (r *NotificationRepository) MarkRead(id, userID int) error {
+	query, err := r.queries.Get("MarkNotificationRead")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("notification id=%d or access denied: %w", id, apierror.ErrNotFound)
+	}
+
+	return nil
+}
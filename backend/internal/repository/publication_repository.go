@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// PublicationRepository - 文書の公開設定操作専用リポジトリ
+type PublicationRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewPublicationRepository - PublicationRepositoryを初期化
+func NewPublicationRepository(db *sql.DB) (*PublicationRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &PublicationRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// GetPublication - 文書の公開設定を取得（未設定の場合は非公開として返す）
+func (r *PublicationRepository) GetPublication(docID int) (*models.DocumentPublication, error) {
+	query, err := r.queries.Get("GetDocumentPublication")
+	if err != nil {
+		return nil, err
+	}
+
+	var pub models.DocumentPublication
+	err = r.db.QueryRow(query, docID).Scan(
+		&pub.DocumentID, &pub.IsPublic, &pub.PublishAt, &pub.UnpublishAt, &pub.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.DocumentPublication{DocumentID: docID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document publication: %w", err)
+	}
+
+	return &pub, nil
+}
+
+// SavePublication - 文書の公開設定を保存（存在しなければ作成）
+func (r *PublicationRepository) SavePublication(pub *models.DocumentPublication) error {
+	query, err := r.queries.Get("UpsertDocumentPublication")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, pub.DocumentID, pub.IsPublic, pub.PublishAt, pub.UnpublishAt).Scan(&pub.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save document publication: %w", err)
+	}
+
+	return nil
+}
+
+// ApplySchedules - 予約日時に到達した公開/非公開を反映し、それぞれの件数を返す
+func (r *PublicationRepository) ApplySchedules() (published, unpublished int64, err error) {
+	publishQuery, err := r.queries.Get("ApplyScheduledPublish")
+	if err != nil {
+		return 0, 0, err
+	}
+	unpublishQuery, err := r.queries.Get("ApplyScheduledUnpublish")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 公開 → 非公開の順に適用し、両方到達済みなら最終的に非公開になる
+	result, err := tx.Exec(publishQuery)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to apply scheduled publish: %w", err)
+	}
+	if published, err = result.RowsAffected(); err != nil {
+		return 0, 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	result, err = tx.Exec(unpublishQuery)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to apply scheduled unpublish: %w", err)
+	}
+	if unpublished, err = result.RowsAffected(); err != nil {
+		return 0, 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return published, unpublished, nil
+}
+
+// GetPublicDocument - 公開中の文書を所有者に関係なく取得
+func (r *PublicationRepository) GetPublicDocument(docID int) (*models.Document, error) {
+	query, err := r.queries.Get("GetPublicDocument")
+	if err != nil {
+		return nil, err
+	}
+
+	var doc models.Document
+	err = r.db.QueryRow(query, docID).Scan(
+		&doc.ID, &doc.UserID, &doc.ParentID, &doc.Title,
+		&doc.Content, &doc.TreePath, &doc.Level, &doc.SortOrder,
+		&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("public document id=%d", docID))
+	}
+
+	return &doc, nil
+}
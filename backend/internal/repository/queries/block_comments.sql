@@ -0,0 +1,46 @@
+-- name: CreateBlockComment
+INSERT INTO block_comments (document_id, block_id, user_id, body)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at, updated_at;
+
+-- name: ListBlockComments
+-- $2 / $3 が NULL の場合はブロック・解決状態で絞り込まない
+SELECT id, document_id, block_id, user_id, body, resolved_at, resolved_by, created_at, updated_at
+FROM block_comments
+WHERE document_id = $1
+  AND ($2::int IS NULL OR block_id = $2)
+  AND ($3::boolean IS NULL OR (resolved_at IS NOT NULL) = $3)
+ORDER BY block_id, created_at, id;
+
+-- name: GetBlockComment
+SELECT id, document_id, block_id, user_id, body, resolved_at, resolved_by, created_at, updated_at
+FROM block_comments
+WHERE id = $1 AND document_id = $2;
+
+-- name: UpdateBlockCommentBody
+UPDATE block_comments
+SET body = $1, updated_at = NOW()
+WHERE id = $2 AND document_id = $3 AND user_id = $4;
+
+-- name: ResolveBlockComment
+-- 解決済みのコメントを再度解決しても日時・解決者は変えない
+UPDATE block_comments
+SET resolved_at = COALESCE(resolved_at, NOW()),
+    resolved_by = CASE WHEN resolved_at IS NULL THEN $3 ELSE resolved_by END
+WHERE id = $1 AND document_id = $2;
+
+-- name: ReopenBlockComment
+UPDATE block_comments
+SET resolved_at = NULL, resolved_by = NULL
+WHERE id = $1 AND document_id = $2;
+
+-- name: DeleteBlockComment
+DELETE FROM block_comments
+WHERE id = $1 AND document_id = $2 AND user_id = $3;
+
+-- name: RemapBlockComments
+-- 文書全体の保存で作り直したブロックの新しい ID へコメントを付け替える
+UPDATE block_comments c
+SET block_id = m.new_id
+FROM unnest($2::int[], $3::int[]) AS m(old_id, new_id)
+WHERE c.document_id = $1 AND c.block_id = m.old_id;
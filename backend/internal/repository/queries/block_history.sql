@@ -0,0 +1,29 @@
+-- name: CreateBlockEditEvents
+-- $3〜$7: ブロックID・変更前後の種類・変更前後の content の配列
+-- 種類も内容も変わっていない場合（位置の移動のみなど）は記録しない。内容は JSONB として比較する
+INSERT INTO block_edit_events (document_id, block_id, editor_id, old_type, new_type, old_content, new_content)
+SELECT $1::int, e.block_id, $2::int, e.old_type, e.new_type, e.old_content::jsonb, e.new_content::jsonb
+FROM unnest($3::int[], $4::text[], $5::text[], $6::text[], $7::text[])
+    AS e(block_id, old_type, new_type, old_content, new_content)
+WHERE e.old_type <> e.new_type OR e.old_content::jsonb IS DISTINCT FROM e.new_content::jsonb;
+
+-- name: ListBlockEditEvents
+-- 新しい順に最大 $3 件
+SELECT id, document_id, block_id, editor_id, old_type, new_type, old_content, new_content, created_at
+FROM block_edit_events
+WHERE document_id = $1 AND block_id = $2
+ORDER BY created_at DESC, id DESC
+LIMIT $3;
+
+-- name: GetBlockContentsByDocumentID
+-- 文書全体の保存で作り直す前に、変更履歴の比較用に現在の種類と内容を取得する
+SELECT id, type, content
+FROM blocks
+WHERE document_id = $1;
+
+-- name: RemapBlockEditEvents
+-- 文書全体の保存で作り直したブロックの新しい ID へ履歴を付け替える
+UPDATE block_edit_events e
+SET block_id = m.new_id
+FROM unnest($2::int[], $3::int[]) AS m(old_id, new_id)
+WHERE e.document_id = $1 AND e.block_id = m.old_id;
@@ -1,20 +1,52 @@
 -- name: GetBlocksByDocumentID
-SELECT id, document_id, type, content, position, created_at
+-- position は並び順キーの順で数えた文書内の位置
+SELECT id, document_id, parent_block_id, type, content,
+       (ROW_NUMBER() OVER (ORDER BY sort_key, id) - 1)::int AS position, created_at
 FROM blocks 
 WHERE document_id = $1 
-ORDER BY position;
+ORDER BY sort_key, id;
+
+-- name: GetBlocksByDocumentIDs
+-- GetBlocksByDocumentID の複数の文書版（position は文書ごとに数える）
+SELECT id, document_id, parent_block_id, type, content,
+       (ROW_NUMBER() OVER (PARTITION BY document_id ORDER BY sort_key, id) - 1)::int AS position, created_at
+FROM blocks
+WHERE document_id = ANY($1)
+ORDER BY document_id, sort_key, id;
+
+-- name: GetBlock
+SELECT b.id, b.document_id, b.parent_block_id, b.type, b.content,
+       (SELECT COUNT(*) FROM blocks o
+        WHERE o.document_id = b.document_id AND (o.sort_key, o.id) < (b.sort_key, b.id))::int AS position, b.created_at
+FROM blocks b
+WHERE b.id = $1 AND b.document_id = $2;
+
+-- name: GetBlockByID
+-- 文書を指定せずにブロックを取得する（所有者の確認は呼び出し側で行う）
+SELECT b.id, b.document_id, b.parent_block_id, b.type, b.content,
+       (SELECT COUNT(*) FROM blocks o
+        WHERE o.document_id = b.document_id AND (o.sort_key, o.id) < (b.sort_key, b.id))::int AS position, b.created_at
+FROM blocks b
+WHERE b.id = $1;
+
+-- name: GetBlockSortKeyForUpdate
+SELECT sort_key
+FROM blocks
+WHERE id = $1 AND document_id = $2
+FOR UPDATE;
 
 -- name: CreateBlock
-INSERT INTO blocks (document_id, type, content, position)
-VALUES ($1, $2, $3, $4)
+INSERT INTO blocks (document_id, parent_block_id, type, content, sort_key)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING id, created_at;
 
 -- name: UpdateBlock
 UPDATE blocks 
-SET type = $1, content = $2, position = $3
+SET type = $1, content = $2, sort_key = $3
 WHERE id = $4 AND document_id = $5;
 
 -- name: DeleteBlock
+-- 子孫ブロックは parent_block_id の ON DELETE CASCADE で同時に削除される
 DELETE FROM blocks 
 WHERE id = $1 AND document_id = $2;
 
@@ -23,15 +55,133 @@ DELETE FROM blocks
 WHERE document_id = $1;
 
 -- name: BulkInsertBlocks
-INSERT INTO blocks (document_id, type, content, position)
-VALUES ($1, $2, $3, $4);
+-- 文書全体の保存で全ブロックを1回の INSERT で挿入する（$2〜$4: 種類・content・並び順キーの配列）
+-- RETURNING の順序は保証されないため、並び順キーで元のブロックに対応付ける
+INSERT INTO blocks (document_id, type, content, sort_key)
+SELECT $1::int, b.type, b.content::jsonb, b.sort_key
+FROM unnest($2::text[], $3::text[], $4::text[]) AS b(type, content, sort_key)
+RETURNING id, sort_key;
+
+-- name: SetBlockParents
+-- $1: 子ブロックの ID の配列、$2: 親ブロックの ID の配列
+UPDATE blocks b
+SET parent_block_id = m.parent_id
+FROM unnest($1::int[], $2::int[]) AS m(id, parent_id)
+WHERE b.id = m.id;
 
 -- name: GetBlockCount
 SELECT COUNT(*) 
 FROM blocks 
 WHERE document_id = $1;
 
--- name: ReorderBlocks
-UPDATE blocks 
-SET position = $1 
-WHERE id = $2 AND document_id = $3;
+-- name: GetNeighbourSortKeys
+-- 位置 $2 の直前から2件の並び順キーを取得する（$3 のブロックは移動するため除く）
+SELECT sort_key
+FROM blocks
+WHERE document_id = $1 AND id <> $3
+ORDER BY sort_key, id
+OFFSET $2
+LIMIT 2;
+
+-- name: ListBlockIDsForRebalance
+-- 並び順キーを振り直すため、文書のブロックを順に取得して行をロックする
+SELECT id
+FROM blocks
+WHERE document_id = $1
+ORDER BY sort_key, id
+FOR UPDATE;
+
+-- name: SetBlockSortKey
+UPDATE blocks
+SET sort_key = $1
+WHERE id = $2;
+
+-- name: FindBlocksByType
+-- ユーザーの（ごみ箱以外の）文書から指定タイプのブロックを探す
+SELECT b.id, b.document_id, b.parent_block_id, b.type, b.content,
+       (SELECT COUNT(*) FROM blocks o
+        WHERE o.document_id = b.document_id AND (o.sort_key, o.id) < (b.sort_key, b.id))::int AS position, b.created_at
+FROM blocks b
+JOIN documents d ON d.id = b.document_id
+WHERE d.user_id = $1 AND d.is_deleted = false AND b.type = $2
+ORDER BY b.document_id, b.sort_key, b.id;
+
+-- name: FindBlocksByFileKey
+-- content の fileKey で画像・ファイル・音声ブロックを探す（idx_blocks_content の GIN インデックスを利用）
+SELECT b.id, b.document_id, b.parent_block_id, b.type, b.content,
+       (SELECT COUNT(*) FROM blocks o
+        WHERE o.document_id = b.document_id AND (o.sort_key, o.id) < (b.sort_key, b.id))::int AS position, b.created_at
+FROM blocks b
+WHERE b.content @> jsonb_build_object('fileKey', $1::text)
+  AND b.type IN ('image', 'file', 'audio')
+ORDER BY b.id;
+
+-- name: CountBlocksByType
+SELECT type, COUNT(*)
+FROM blocks
+GROUP BY type
+ORDER BY COUNT(*) DESC, type;
+
+-- name: GetCollapsedBlockIDs
+-- ユーザーが折りたたんでいる文書内のブロック
+SELECT c.block_id
+FROM block_collapse_states c
+JOIN blocks b ON b.id = c.block_id
+WHERE c.user_id = $1 AND b.document_id = $2
+ORDER BY b.sort_key, b.id;
+
+-- name: CollapseBlock
+INSERT INTO block_collapse_states (user_id, block_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, block_id) DO NOTHING;
+
+-- name: RestoreCollapseStates
+-- 一括保存で作り直したブロックの開閉状態をまとめて復元する（$1: ユーザーIDの配列、$2: ブロックIDの配列。同じ位置の要素を1組として挿入する）
+INSERT INTO block_collapse_states (user_id, block_id)
+SELECT * FROM unnest($1::int[], $2::int[])
+ON CONFLICT (user_id, block_id) DO NOTHING;
+
+-- name: ExpandBlock
+DELETE FROM block_collapse_states
+WHERE user_id = $1 AND block_id = $2;
+
+-- name: GetCollapseStatesByDocumentID
+-- 一括保存でブロックを作り直す前に、全ユーザーの開閉状態を退避する
+SELECT c.user_id, c.block_id
+FROM block_collapse_states c
+JOIN blocks b ON b.id = c.block_id
+WHERE b.document_id = $1;
+
+-- name: CreateSyncedBlock
+-- 登録済みのブロックは既存の syncId を返す
+INSERT INTO synced_blocks (user_id, source_document_id, source_block_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (source_document_id, source_block_id) DO UPDATE SET source_block_id = EXCLUDED.source_block_id
+RETURNING id, created_at;
+
+-- name: ResolveSyncedBlocks
+-- 同期元は表示する文書の所有者のもので、ゴミ箱にない文書のブロックに限る
+-- 登録したユーザーが同期元の文書の所有者・共同編集者・ワークスペースのメンバーでなくなった場合は解決しない
+SELECT s.id, b.document_id, b.id, b.type, b.content
+FROM synced_blocks s
+JOIN documents d ON d.id = s.source_document_id
+JOIN blocks b ON b.id = s.source_block_id AND b.document_id = s.source_document_id
+WHERE s.id = ANY($1)
+  AND d.is_deleted = false
+  AND s.user_id = (SELECT user_id FROM documents WHERE id = $2)
+  AND (d.user_id = s.user_id
+    OR EXISTS (
+        SELECT 1 FROM document_collaborators c
+        WHERE c.document_id = d.id AND c.user_id = s.user_id
+    )
+    OR EXISTS (
+        SELECT 1 FROM workspace_members wm
+        WHERE wm.workspace_id = d.workspace_id AND wm.user_id = s.user_id
+    ));
+
+-- name: RemapSyncedBlockSources
+-- 文書全体の保存で作り直したブロックの新しい ID へ同期元を付け替える
+UPDATE synced_blocks s
+SET source_block_id = m.new_id
+FROM unnest($2::int[], $3::int[]) AS m(old_id, new_id)
+WHERE s.source_document_id = $1 AND s.source_block_id = m.old_id;
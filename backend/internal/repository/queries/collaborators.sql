@@ -0,0 +1,38 @@
+-- name: ListDocumentCollaborators
+-- 所有者を先頭に、共同編集者を追加順に返す
+SELECT document_id, user_id, email, name, role, added_by, created_at, updated_at
+FROM (
+    SELECT d.id AS document_id, d.user_id, u.email, u.name, 'owner' AS role, NULL::INTEGER AS added_by,
+           d.created_at, d.created_at AS updated_at, 0 AS sort_group
+    FROM documents d
+    JOIN users u ON u.id = d.user_id
+    WHERE d.id = $1
+    UNION ALL
+    SELECT c.document_id, c.user_id, u.email, u.name, c.role, c.added_by,
+           c.created_at, c.updated_at, 1 AS sort_group
+    FROM document_collaborators c
+    JOIN users u ON u.id = c.user_id
+    WHERE c.document_id = $1
+) collaborators
+ORDER BY sort_group, created_at, user_id;
+
+-- name: GetDocumentCollaboratorRole
+SELECT role
+FROM document_collaborators
+WHERE document_id = $1 AND user_id = $2;
+
+-- name: AddDocumentCollaborator
+-- 既に共同編集者の場合は何もしない（0 行）
+INSERT INTO document_collaborators (document_id, user_id, role, added_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (document_id, user_id) DO NOTHING
+RETURNING created_at, updated_at;
+
+-- name: UpdateDocumentCollaboratorRole
+UPDATE document_collaborators
+SET role = $1, updated_at = NOW()
+WHERE document_id = $2 AND user_id = $3;
+
+-- name: RemoveDocumentCollaborator
+DELETE FROM document_collaborators
+WHERE document_id = $1 AND user_id = $2;
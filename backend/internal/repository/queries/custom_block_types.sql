@@ -0,0 +1,24 @@
+-- name: ListCustomBlockTypes
+SELECT id, name, description, schema, export_template, COALESCE(created_by, 0), created_at, updated_at
+FROM custom_block_types
+ORDER BY name;
+
+-- name: GetCustomBlockType
+SELECT id, name, description, schema, export_template, COALESCE(created_by, 0), created_at, updated_at
+FROM custom_block_types
+WHERE name = $1;
+
+-- name: CreateCustomBlockType
+INSERT INTO custom_block_types (name, description, schema, export_template, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, updated_at;
+
+-- name: UpdateCustomBlockType
+UPDATE custom_block_types
+SET description = $2, schema = $3, export_template = $4, updated_at = NOW()
+WHERE name = $1
+RETURNING id, COALESCE(created_by, 0), created_at, updated_at;
+
+-- name: DeleteCustomBlockType
+DELETE FROM custom_block_types
+WHERE name = $1;
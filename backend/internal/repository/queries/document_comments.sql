@@ -0,0 +1,42 @@
+-- name: CreateDocumentComment
+INSERT INTO document_comments (document_id, parent_id, user_id, body)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at, updated_at;
+
+-- name: ListDocumentComments
+-- スレッドの先頭と返信をまとめて作成日時順に返す（スレッドへの組み立てはサービス層で行う）
+SELECT id, document_id, parent_id, user_id, body, resolved_at, resolved_by, created_at, updated_at
+FROM document_comments
+WHERE document_id = $1
+ORDER BY created_at, id;
+
+-- name: GetDocumentComment
+SELECT id, document_id, parent_id, user_id, body, resolved_at, resolved_by, created_at, updated_at
+FROM document_comments
+WHERE id = $1 AND document_id = $2;
+
+-- name: CountDocumentComments
+SELECT COUNT(*)
+FROM document_comments
+WHERE document_id = $1;
+
+-- name: UpdateDocumentCommentBody
+UPDATE document_comments
+SET body = $1, updated_at = NOW()
+WHERE id = $2 AND document_id = $3 AND user_id = $4;
+
+-- name: ResolveDocumentComment
+-- 解決済みのスレッドを再度解決しても日時・解決者は変えない
+UPDATE document_comments
+SET resolved_at = COALESCE(resolved_at, NOW()),
+    resolved_by = CASE WHEN resolved_at IS NULL THEN $3 ELSE resolved_by END
+WHERE id = $1 AND document_id = $2 AND parent_id IS NULL;
+
+-- name: ReopenDocumentComment
+UPDATE document_comments
+SET resolved_at = NULL, resolved_by = NULL
+WHERE id = $1 AND document_id = $2 AND parent_id IS NULL;
+
+-- name: DeleteDocumentComment
+DELETE FROM document_comments
+WHERE id = $1 AND document_id = $2 AND user_id = $3;
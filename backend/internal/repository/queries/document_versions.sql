@@ -0,0 +1,46 @@
+-- name: CreateDocumentVersion
+INSERT INTO document_versions (document_id, version_number, title, content, blocks, created_by)
+SELECT $1, COALESCE(MAX(version_number), 0) + 1, $2, $3, $4, $5
+FROM document_versions
+WHERE document_id = $1
+RETURNING id, version_number, created_at;
+
+-- name: GetDocumentVersion
+SELECT id, document_id, version_number, title, content, blocks, created_by, created_at
+FROM document_versions
+WHERE document_id = $1 AND version_number = $2;
+
+-- name: GetDocumentVersionAtOrBefore
+-- 指定したバージョン番号以前で最も新しいバージョン（間引き・削除された版の代わりに使う）
+SELECT id, document_id, version_number, title, content, blocks, created_by, created_at
+FROM document_versions
+WHERE document_id = $1 AND version_number <= $2
+ORDER BY version_number DESC
+LIMIT 1;
+
+-- name: ListDocumentVersions
+SELECT id, document_id, version_number, title, created_by, created_at
+FROM document_versions
+WHERE document_id = $1
+ORDER BY version_number DESC;
+
+-- name: MergeDocumentVersions
+-- $1 より前に作成した版を、文書・作成者・$2 秒の区間ごとに最後の1つにまとめる（文書の最新の版は残す）
+DELETE FROM document_versions v
+USING (
+    SELECT id, ROW_NUMBER() OVER (
+               PARTITION BY document_id, created_by, FLOOR(EXTRACT(EPOCH FROM created_at) / $2)
+               ORDER BY version_number DESC
+           ) AS bucket_rank
+    FROM document_versions
+    WHERE created_at < $1
+) old
+WHERE v.id = old.id
+  AND old.bucket_rank > 1
+  AND v.version_number < (SELECT MAX(l.version_number) FROM document_versions l WHERE l.document_id = v.document_id);
+
+-- name: DeleteExpiredDocumentVersions
+-- $1 より前に作成した版を削除する（文書の最新の版は編集の基準にするため残す）
+DELETE FROM document_versions v
+WHERE v.created_at < $1
+  AND v.version_number < (SELECT MAX(l.version_number) FROM document_versions l WHERE l.document_id = v.document_id);
@@ -0,0 +1,65 @@
+-- name: UpsertDocumentWatch
+-- 既に購読している場合は知らせ方だけを変更する
+INSERT INTO document_watches (document_id, user_id, delivery)
+VALUES ($1, $2, $3)
+ON CONFLICT (document_id, user_id)
+DO UPDATE SET delivery = EXCLUDED.delivery
+RETURNING created_at;
+
+-- name: DeleteDocumentWatch
+DELETE FROM document_watches
+WHERE document_id = $1 AND user_id = $2;
+
+-- name: ListUserDocumentWatches
+-- ごみ箱内の文書と、アクセスできなくなった文書は含めない
+SELECT w.document_id, w.user_id, d.title, w.delivery, w.created_at
+FROM document_watches w
+JOIN documents d ON d.id = w.document_id
+WHERE w.user_id = $1 AND d.is_deleted = false
+  AND (d.user_id = w.user_id OR EXISTS (
+      SELECT 1 FROM document_collaborators c
+      WHERE c.document_id = d.id AND c.user_id = w.user_id
+  ))
+ORDER BY w.created_at DESC, w.document_id DESC;
+
+-- name: ListDocumentWatchers
+-- 変更したユーザー（$2）と、所有者・共同編集者でなくなったユーザーを除く
+SELECT w.user_id, d.title, w.delivery,
+       EXISTS (
+           SELECT 1 FROM notifications n
+           WHERE n.user_id = w.user_id AND n.document_id = w.document_id
+             AND n.type = 'watch' AND n.read_at IS NULL
+       )
+FROM document_watches w
+JOIN documents d ON d.id = w.document_id
+WHERE w.document_id = $1 AND w.user_id <> $2 AND d.is_deleted = false
+  AND (d.user_id = w.user_id OR EXISTS (
+      SELECT 1 FROM document_collaborators c
+      WHERE c.document_id = d.id AND c.user_id = w.user_id
+  ));
+
+-- name: MarkDocumentWatchChanged
+UPDATE document_watches
+SET last_changed_at = NOW()
+WHERE document_id = $1 AND user_id = $2;
+
+-- name: ListPendingWatchDigests
+-- 前回のメールの後に変更された文書を、ユーザーごとにまとめて返す
+SELECT w.user_id, u.email, u.name, w.document_id, d.title, w.last_changed_at
+FROM document_watches w
+JOIN documents d ON d.id = w.document_id
+JOIN users u ON u.id = w.user_id
+WHERE w.delivery = 'email_digest' AND w.last_changed_at IS NOT NULL
+  AND (w.digested_at IS NULL OR w.last_changed_at > w.digested_at)
+  AND d.is_deleted = false
+  AND (d.user_id = w.user_id OR EXISTS (
+      SELECT 1 FROM document_collaborators c
+      WHERE c.document_id = d.id AND c.user_id = w.user_id
+  ))
+ORDER BY w.user_id, w.last_changed_at DESC;
+
+-- name: MarkWatchDigested
+-- 送信した時点（$3）までの変更を送信済みにする
+UPDATE document_watches
+SET digested_at = $3
+WHERE user_id = $1 AND document_id = ANY($2::int[]);
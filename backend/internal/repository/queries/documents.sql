@@ -1,12 +1,33 @@
 -- name: GetDocumentWithBlocks
-SELECT id, user_id, parent_id, title, content, tree_path, level, sort_order, 
-       is_deleted, created_at, updated_at
-FROM documents 
-WHERE id = $1 AND user_id = $2 AND is_deleted = false;
+-- 所有者・共同編集者と、文書が属するワークスペースのメンバーが取得でき、取得したユーザーの役割も返す
+-- ワークスペースの owner / admin / member は editor、guest は（editor の共同編集者でなければ）viewer として返す
+SELECT d.id, d.user_id, d.parent_id, d.title, d.content, d.tree_path, d.level, d.sort_order,
+       d.is_deleted, d.created_at, d.updated_at, d.workspace_id,
+       CASE WHEN d.user_id = $2 THEN 'owner'
+            WHEN c.role = 'editor' OR wm.role IN ('owner', 'admin', 'member') THEN 'editor'
+            ELSE 'viewer' END
+FROM documents d
+LEFT JOIN document_collaborators c ON c.document_id = d.id AND c.user_id = $2
+LEFT JOIN workspace_members wm ON wm.workspace_id = d.workspace_id AND wm.user_id = $2
+WHERE d.id = $1 AND d.is_deleted = false
+  AND (d.user_id = $2 OR c.user_id IS NOT NULL OR wm.user_id IS NOT NULL);
+
+-- name: GetDocumentsByIDs
+-- GetDocumentWithBlocks の複数の文書版（アクセスできない文書・ごみ箱内の文書は含まない）
+SELECT d.id, d.user_id, d.parent_id, d.title, d.content, d.tree_path, d.level, d.sort_order,
+       d.is_deleted, d.created_at, d.updated_at, d.workspace_id,
+       CASE WHEN d.user_id = $2 THEN 'owner'
+            WHEN c.role = 'editor' OR wm.role IN ('owner', 'admin', 'member') THEN 'editor'
+            ELSE 'viewer' END
+FROM documents d
+LEFT JOIN document_collaborators c ON c.document_id = d.id AND c.user_id = $2
+LEFT JOIN workspace_members wm ON wm.workspace_id = d.workspace_id AND wm.user_id = $2
+WHERE d.id = ANY($1) AND d.is_deleted = false
+  AND (d.user_id = $2 OR c.user_id IS NOT NULL OR wm.user_id IS NOT NULL);
 
 -- name: GetDocumentWithBlocksIncludingDeleted
 SELECT id, user_id, parent_id, title, content, tree_path, level, sort_order, 
-       is_deleted, created_at, updated_at
+       is_deleted, created_at, updated_at, deleted_at, workspace_id
 FROM documents 
 WHERE id = $1 AND user_id = $2;
 
@@ -17,32 +38,95 @@ FROM documents
 WHERE user_id = $1 AND is_deleted = false
 ORDER BY tree_path, sort_order;
 
+-- name: GetWorkspaceDocumentTree
+-- ワークスペース（$2）に属する全メンバーの文書（ユーザー $1 がメンバーの場合のみ）
+-- $2 が NULL の場合はユーザーの個人の文書
+SELECT id, user_id, parent_id, title, content, tree_path, level, sort_order, 
+       is_deleted, created_at, updated_at, workspace_id
+FROM documents 
+WHERE is_deleted = false
+  AND (($2::integer IS NULL AND user_id = $1 AND workspace_id IS NULL)
+    OR (workspace_id = $2 AND EXISTS (
+        SELECT 1 FROM workspace_members m
+        WHERE m.workspace_id = $2 AND m.user_id = $1
+    )))
+ORDER BY tree_path, sort_order;
+
 -- name: CreateDocument
-INSERT INTO documents (user_id, parent_id, title, content, created_at, updated_at)
-VALUES ($1, $2, $3, $4, NOW(), NOW())
-RETURNING id, created_at, updated_at;
+-- 子文書は親と同じワークスペースに属する（$5 は親がない場合のワークスペース）
+INSERT INTO documents (user_id, parent_id, title, content, workspace_id, created_at, updated_at)
+VALUES ($1, $2, $3, $4,
+        CASE WHEN $2::integer IS NULL THEN $5::integer
+             ELSE (SELECT workspace_id FROM documents WHERE id = $2) END,
+        NOW(), NOW())
+RETURNING id, workspace_id, created_at, updated_at;
 
 -- name: UpdateDocument
+-- 所有者・editor の共同編集者と、文書が属するワークスペースの guest 以外のメンバーが更新できる
 UPDATE documents 
 SET title = $1, content = $2, updated_at = NOW()
-WHERE id = $3 AND user_id = $4;
+WHERE id = $3 AND (user_id = $4 OR EXISTS (
+    SELECT 1 FROM document_collaborators c
+    WHERE c.document_id = documents.id AND c.user_id = $4 AND c.role = 'editor'
+) OR EXISTS (
+    SELECT 1 FROM workspace_members m
+    WHERE m.workspace_id = documents.workspace_id AND m.user_id = $4 AND m.role IN ('owner', 'admin', 'member')
+));
+
+-- name: LockDocumentContent
+-- 保存前に文書の行をロックし、現在のタイトル・本文を取得する（同じ文書の保存を直列にする）
+SELECT title, content
+FROM documents
+WHERE id = $1 AND is_deleted = false
+FOR UPDATE;
+
+-- name: PatchDocument
+-- NULL のフィールドは変更しない（更新できるユーザーは UpdateDocument と同じ）
+UPDATE documents
+SET title = COALESCE($1, title), content = COALESCE($2, content), updated_at = NOW()
+WHERE id = $3 AND (user_id = $4 OR EXISTS (
+    SELECT 1 FROM document_collaborators c
+    WHERE c.document_id = documents.id AND c.user_id = $4 AND c.role = 'editor'
+) OR EXISTS (
+    SELECT 1 FROM workspace_members m
+    WHERE m.workspace_id = documents.workspace_id AND m.user_id = $4 AND m.role IN ('owner', 'admin', 'member')
+));
 
 -- name: SoftDeleteDocument
 UPDATE documents 
-SET is_deleted = true, updated_at = NOW()
+SET is_deleted = true, deleted_at = NOW(), updated_at = NOW()
 WHERE id = $1 AND user_id = $2;
 
 -- name: RestoreDocument
 UPDATE documents 
-SET is_deleted = false, updated_at = NOW()
+SET is_deleted = false, deleted_at = NULL, updated_at = NOW()
 WHERE id = $1 AND user_id = $2;
 
+-- name: LockDocumentsForRestore
+-- 一括復元の対象（$2: 文書IDの配列）と、その削除前の親文書を行ロックして取得する
+SELECT id, parent_id, is_deleted, level
+FROM documents
+WHERE user_id = $1
+  AND (id = ANY($2) OR id IN (SELECT parent_id FROM documents WHERE user_id = $1 AND id = ANY($2)))
+FOR UPDATE;
+
+-- name: RestoreDocumentWithParent
+UPDATE documents 
+SET is_deleted = false, deleted_at = NULL, parent_id = $3, updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND is_deleted = true;
+
 -- name: GetTrashedDocuments
+-- $2: タイトルの部分一致（LIKE パターン、NULL で条件なし）
+-- $3/$4: ごみ箱移動日時の範囲、$5: 削除前の親文書
+-- ページ位置の条件（$6/$7）・並び順・件数（$8）は並び替えキーに合わせて GetTrashedDocuments で付け足す
 SELECT id, user_id, parent_id, title, content, tree_path, level, sort_order, 
-       is_deleted, created_at, updated_at
+       is_deleted, created_at, updated_at, COALESCE(deleted_at, updated_at)
 FROM documents 
 WHERE user_id = $1 AND is_deleted = true
-ORDER BY updated_at DESC;
+  AND ($2::text IS NULL OR title ILIKE $2)
+  AND ($3::timestamp IS NULL OR COALESCE(deleted_at, updated_at) >= $3)
+  AND ($4::timestamp IS NULL OR COALESCE(deleted_at, updated_at) < $4)
+  AND ($5::int IS NULL OR parent_id = $5)
 
 -- name: PermanentDeleteDocument
 DELETE FROM documents 
@@ -64,3 +148,38 @@ ORDER BY sort_order;
 UPDATE documents 
 SET sort_order = $1, updated_at = NOW()
 WHERE id = $2 AND user_id = $3;
+
+-- name: UpdateDocumentSearchText
+-- search_vector は生成列のため search_text の更新で再計算される
+UPDATE documents
+SET search_text = $1
+WHERE id = $2;
+
+-- name: SearchDocuments
+-- ユーザーの（ごみ箱以外の）文書をタイトルとブロックのテキストで検索し、関連度順に返す
+-- $2: 検索語（websearch_to_tsquery の構文）、$3: 最大件数
+-- $4/$5/$6: 前のページの最後の結果の関連度（ts_rank の ::text）・更新日時・ID（NULL で先頭のページ）
+SELECT d.id, d.parent_id, d.title, d.updated_at,
+       ts_headline('simple', d.search_text, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5, MaxFragments=2'),
+       ts_rank(d.search_vector, q.query), ts_rank(d.search_vector, q.query)::text
+FROM documents d, websearch_to_tsquery('simple', $2) AS q(query)
+WHERE d.user_id = $1
+  AND d.is_deleted = false
+  AND d.search_vector @@ q.query
+  AND ($6::int IS NULL OR (ts_rank(d.search_vector, q.query), d.updated_at, d.id) < ($4::real, $5::timestamp, $6))
+ORDER BY ts_rank(d.search_vector, q.query) DESC, d.updated_at DESC, d.id DESC
+LIMIT $3;
+
+-- name: ListDocumentsForReindex
+-- 全文検索のテキストを作り直す文書（ごみ箱以外）を ID 順に返す。$1: 前のページの最後の ID、$2: 最大件数
+SELECT id, user_id
+FROM documents
+WHERE is_deleted = false AND id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: ListTrashOwnerIDs
+SELECT DISTINCT user_id
+FROM documents
+WHERE is_deleted = true
+ORDER BY user_id;
@@ -0,0 +1,37 @@
+-- name: GetDocumentExpiration
+SELECT document_id, expires_at, action, warned_at, created_at
+FROM document_expirations
+WHERE document_id = $1;
+
+-- name: UpsertDocumentExpiration
+INSERT INTO document_expirations (document_id, expires_at, action)
+VALUES ($1, $2, $3)
+ON CONFLICT (document_id) DO UPDATE
+SET expires_at = EXCLUDED.expires_at,
+    action = EXCLUDED.action,
+    warned_at = NULL
+RETURNING created_at;
+
+-- name: DeleteDocumentExpiration
+DELETE FROM document_expirations
+WHERE document_id = $1;
+
+-- name: ListExpirationsToWarn
+SELECT e.document_id, d.user_id, d.title, e.expires_at, e.action
+FROM document_expirations e
+JOIN documents d ON d.id = e.document_id
+WHERE e.warned_at IS NULL
+  AND e.expires_at > NOW()
+  AND e.expires_at <= NOW() + make_interval(secs => $1)
+  AND d.is_deleted = false;
+
+-- name: MarkExpirationWarned
+UPDATE document_expirations
+SET warned_at = NOW()
+WHERE document_id = $1;
+
+-- name: ListDueExpirations
+SELECT e.document_id, d.user_id, d.title, e.expires_at, e.action
+FROM document_expirations e
+JOIN documents d ON d.id = e.document_id
+WHERE e.expires_at <= NOW();
@@ -0,0 +1,68 @@
+-- name: CountOrphanedBlocks
+SELECT COUNT(*)
+FROM blocks b
+WHERE NOT EXISTS (SELECT 1 FROM documents d WHERE d.id = b.document_id);
+
+-- name: ListOrphanedBlocks
+SELECT b.id, b.document_id, b.type, b.created_at
+FROM blocks b
+WHERE NOT EXISTS (SELECT 1 FROM documents d WHERE d.id = b.document_id)
+ORDER BY b.id
+LIMIT $1;
+
+-- name: DeleteOrphanedBlocks
+DELETE FROM blocks b
+WHERE NOT EXISTS (SELECT 1 FROM documents d WHERE d.id = b.document_id);
+
+-- name: CountDanglingFileReferences
+SELECT COUNT(*)
+FROM file_metadata fm
+WHERE fm.block_id IS NOT NULL
+  AND fm.status = 'active'
+  AND NOT EXISTS (SELECT 1 FROM blocks b WHERE b.id = fm.block_id);
+
+-- name: ListDanglingFileReferences
+SELECT fm.id, fm.user_id, fm.block_id, fm.file_key, fm.uploaded_at
+FROM file_metadata fm
+WHERE fm.block_id IS NOT NULL
+  AND fm.status = 'active'
+  AND NOT EXISTS (SELECT 1 FROM blocks b WHERE b.id = fm.block_id)
+ORDER BY fm.id
+LIMIT $1;
+
+-- name: DetachDanglingFileReferences
+UPDATE file_metadata fm
+SET block_id = NULL, status = 'orphaned'
+WHERE fm.block_id IS NOT NULL
+  AND fm.status = 'active'
+  AND NOT EXISTS (SELECT 1 FROM blocks b WHERE b.id = fm.block_id);
+
+-- name: DeleteDanglingFileReferences
+UPDATE file_metadata fm
+SET status = 'deleted', deleted_at = NOW()
+WHERE fm.block_id IS NOT NULL
+  AND fm.status = 'active'
+  AND NOT EXISTS (SELECT 1 FROM blocks b WHERE b.id = fm.block_id);
+
+-- name: ListReferencedObjectKeys
+SELECT fm.file_key FROM file_metadata fm
+UNION
+SELECT fm.webp_file_key FROM file_metadata fm WHERE fm.webp_file_key <> ''
+UNION
+SELECT t.value FROM file_metadata fm, jsonb_each_text(fm.thumbnails) t
+UNION
+SELECT fv.file_key FROM file_versions fv
+UNION
+SELECT fv.webp_file_key FROM file_versions fv WHERE fv.webp_file_key <> ''
+UNION
+SELECT t.value FROM file_versions fv, jsonb_each_text(fv.thumbnails) t
+UNION
+SELECT du.file_key FROM direct_uploads du
+UNION
+SELECT us.file_key FROM upload_sessions us;
+
+-- name: ListFileObjectReferences
+SELECT fm.id, fm.user_id, fm.file_key, fm.status, fm.uploaded_at
+FROM file_metadata fm
+WHERE fm.status <> 'deleted'
+ORDER BY fm.id;
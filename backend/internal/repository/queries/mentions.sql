@@ -0,0 +1,17 @@
+-- name: ListMentionUsers
+SELECT user_id
+FROM mentions
+WHERE document_id = $1 AND source_type = $2 AND source_id = $3;
+
+-- name: DeleteStaleMentions
+-- 今回の保存に含まれない（$4 にない）ユーザーへのメンションを削除する
+DELETE FROM mentions
+WHERE document_id = $1 AND source_type = $2 AND source_id = $3
+  AND NOT (user_id = ANY($4::int[]));
+
+-- name: UpsertMention
+-- 既に記録済みの場合は、メンションを含むブロックだけを最新にする
+INSERT INTO mentions (document_id, source_type, source_id, block_id, user_id, mentioned_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (document_id, source_type, source_id, user_id)
+DO UPDATE SET block_id = EXCLUDED.block_id;
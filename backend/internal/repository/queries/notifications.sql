@@ -0,0 +1,18 @@
+-- name: CreateNotification
+INSERT INTO notifications (user_id, document_id, type, message, link)
+VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+RETURNING id, created_at;
+
+-- name: ListNotifications
+-- $3/$4: 前のページの最後の通知の作成日時と ID（NULL で先頭のページ）、$5: 最大件数
+SELECT id, user_id, document_id, type, message, COALESCE(link, ''), read_at, created_at
+FROM notifications
+WHERE user_id = $1 AND ($2 = false OR read_at IS NULL)
+  AND ($4::int IS NULL OR (created_at, id) < ($3::timestamp, $4))
+ORDER BY created_at DESC, id DESC
+LIMIT $5;
+
+-- name: MarkNotificationRead
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1 AND user_id = $2;
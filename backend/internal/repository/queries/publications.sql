@@ -0,0 +1,31 @@
+-- name: GetDocumentPublication
+SELECT document_id, is_public, publish_at, unpublish_at, updated_at
+FROM document_publications
+WHERE document_id = $1;
+
+-- name: UpsertDocumentPublication
+INSERT INTO document_publications (document_id, is_public, publish_at, unpublish_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (document_id) DO UPDATE
+SET is_public = EXCLUDED.is_public,
+    publish_at = EXCLUDED.publish_at,
+    unpublish_at = EXCLUDED.unpublish_at,
+    updated_at = NOW()
+RETURNING updated_at;
+
+-- name: ApplyScheduledPublish
+UPDATE document_publications
+SET is_public = true, publish_at = NULL, updated_at = NOW()
+WHERE publish_at IS NOT NULL AND publish_at <= NOW();
+
+-- name: ApplyScheduledUnpublish
+UPDATE document_publications
+SET is_public = false, unpublish_at = NULL, updated_at = NOW()
+WHERE unpublish_at IS NOT NULL AND unpublish_at <= NOW();
+
+-- name: GetPublicDocument
+SELECT d.id, d.user_id, d.parent_id, d.title, d.content, d.tree_path, d.level, d.sort_order,
+       d.is_deleted, d.created_at, d.updated_at
+FROM documents d
+JOIN document_publications p ON p.document_id = d.id
+WHERE d.id = $1 AND p.is_public = true AND d.is_deleted = false;
@@ -0,0 +1,31 @@
+-- name: CreateReminder
+INSERT INTO document_reminders (document_id, user_id, remind_at, note)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at;
+
+-- name: ListReminders
+SELECT r.id, r.document_id, r.user_id, d.title, r.remind_at, r.note, r.delivered_at, r.dismissed_at, r.created_at
+FROM document_reminders r
+JOIN documents d ON d.id = r.document_id
+WHERE r.user_id = $1 AND r.dismissed_at IS NULL AND d.is_deleted = false
+ORDER BY r.remind_at ASC;
+
+-- name: DismissReminder
+UPDATE document_reminders
+SET dismissed_at = NOW()
+WHERE id = $1 AND user_id = $2 AND dismissed_at IS NULL;
+
+-- name: ListDueReminders
+SELECT r.id, r.document_id, r.user_id, d.title, r.remind_at, r.note, r.delivered_at, r.dismissed_at, r.created_at
+FROM document_reminders r
+JOIN documents d ON d.id = r.document_id
+WHERE r.remind_at <= NOW()
+  AND r.delivered_at IS NULL
+  AND r.dismissed_at IS NULL
+  AND d.is_deleted = false
+ORDER BY r.remind_at ASC;
+
+-- name: MarkReminderDelivered
+UPDATE document_reminders
+SET delivered_at = NOW()
+WHERE id = $1;
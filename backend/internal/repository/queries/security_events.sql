@@ -0,0 +1,4 @@
+-- name: CreateSecurityEvent
+INSERT INTO security_events (user_id, document_id, event_type, details)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at;
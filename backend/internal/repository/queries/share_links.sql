@@ -0,0 +1,45 @@
+-- name: CreateShareLink
+INSERT INTO document_share_links (document_id, token_hash, password_hash, expires_at, created_by, snapshot_id)
+VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+RETURNING id, created_at;
+
+-- name: ListShareLinks
+SELECT id, document_id, snapshot_id, COALESCE(password_hash, ''), expires_at, created_by, created_at,
+       revoked_at, last_accessed_at, access_count
+FROM document_share_links
+WHERE document_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetShareLinkByTokenHash
+-- ごみ箱内の文書のリンクは存在しないものとして扱う
+SELECT l.id, l.document_id, l.snapshot_id, COALESCE(l.password_hash, ''), l.expires_at, l.created_by, l.created_at,
+       l.revoked_at, l.last_accessed_at, l.access_count
+FROM document_share_links l
+JOIN documents d ON d.id = l.document_id
+WHERE l.token_hash = $1 AND d.is_deleted = false;
+
+-- name: RevokeShareLink
+UPDATE document_share_links
+SET revoked_at = NOW()
+WHERE id = $1 AND document_id = $2 AND revoked_at IS NULL;
+
+-- name: RecordShareLinkAccess
+UPDATE document_share_links
+SET last_accessed_at = NOW(), access_count = access_count + 1
+WHERE id = $1;
+
+-- name: GetSharedDocument
+SELECT id, user_id, parent_id, title, content, tree_path, level, sort_order,
+       is_deleted, created_at, updated_at
+FROM documents
+WHERE id = $1 AND is_deleted = false;
+
+-- name: CreateDocumentSnapshot
+INSERT INTO document_snapshots (document_id, title, content, blocks, file_ids, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at;
+
+-- name: GetDocumentSnapshot
+SELECT id, document_id, title, content, blocks, file_ids, created_by, created_at
+FROM document_snapshots
+WHERE id = $1;
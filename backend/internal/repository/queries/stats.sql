@@ -0,0 +1,29 @@
+-- name: GetUserStats
+-- $1: 集計期間の開始日時
+SELECT COUNT(*), COUNT(*) FILTER (WHERE created_at >= $1)
+FROM users;
+
+-- name: GetDocumentStats
+SELECT COUNT(*), COUNT(*) FILTER (WHERE is_deleted = true)
+FROM documents;
+
+-- name: GetStorageStats
+SELECT COUNT(*), COALESCE(SUM(file_size), 0)
+FROM file_metadata
+WHERE status = 'active';
+
+-- name: GetUploadsPerDay
+-- 期間内の日ごとのアップロード件数（アップロードのない日も 0 として返す）
+SELECT to_char(day, 'YYYY-MM-DD'), COUNT(fm.id)
+FROM generate_series($1::date, CURRENT_DATE, INTERVAL '1 day') AS day
+LEFT JOIN file_metadata fm ON fm.uploaded_at::date = day::date
+GROUP BY day
+ORDER BY day;
+
+-- name: GetActiveUsersPerDay
+-- 期間内の日ごとに文書を保存したユーザー数（保存履歴から集計）
+SELECT to_char(day, 'YYYY-MM-DD'), COUNT(DISTINCT dv.created_by)
+FROM generate_series($1::date, CURRENT_DATE, INTERVAL '1 day') AS day
+LEFT JOIN document_versions dv ON dv.created_at::date = day::date
+GROUP BY day
+ORDER BY day;
@@ -0,0 +1,16 @@
+-- name: ListOpenTasks
+-- ユーザーの（ごみ箱以外の）全文書から未完了の todo ブロックを集める
+-- 期限のあるものを期限順に先に、期限のないものは文書の更新が新しい順に並べる
+SELECT b.id, b.document_id, d.title,
+       COALESCE(b.content->>'text', ''), b.content->>'dueDate',
+       (SELECT COUNT(*) FROM blocks o
+        WHERE o.document_id = b.document_id AND (o.sort_key, o.id) < (b.sort_key, b.id))::int AS position,
+       b.created_at
+FROM blocks b
+JOIN documents d ON d.id = b.document_id
+WHERE d.user_id = $1
+  AND d.is_deleted = false
+  AND b.type = 'todo'
+  AND jsonb_typeof(b.content) = 'object'
+  AND NOT (b.content @> '{"checked": true}')
+ORDER BY (b.content->>'dueDate') IS NULL, b.content->>'dueDate', d.updated_at DESC, b.sort_key, b.id;
@@ -0,0 +1,64 @@
+-- name: CreateUploadSession
+INSERT INTO upload_sessions (id, user_id, file_key, storage_upload_id, original_name, mime_type, total_size, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING created_at;
+
+-- name: GetUploadSession
+-- 期限切れのセッションは存在しないものとして扱う
+SELECT s.id, s.user_id, s.file_key, s.storage_upload_id, s.original_name, s.mime_type,
+       s.total_size, s.received_size,
+       (SELECT COUNT(*) FROM upload_session_parts p WHERE p.session_id = s.id)::int AS part_count,
+       s.expires_at, s.created_at
+FROM upload_sessions s
+WHERE s.id = $1 AND s.user_id = $2 AND s.expires_at > NOW();
+
+-- name: AdvanceUploadSession
+-- 受信済みサイズが offset と一致する場合のみ進める（同じ位置への同時の追記は一方だけが成功する）
+UPDATE upload_sessions
+SET received_size = received_size + $3, expires_at = $4, updated_at = NOW()
+WHERE id = $1 AND received_size = $2 AND expires_at > NOW();
+
+-- name: SaveUploadPart
+INSERT INTO upload_session_parts (session_id, part_number, etag, size)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (session_id, part_number) DO UPDATE SET etag = EXCLUDED.etag, size = EXCLUDED.size;
+
+-- name: ListUploadParts
+SELECT part_number, etag, size
+FROM upload_session_parts
+WHERE session_id = $1
+ORDER BY part_number;
+
+-- name: DeleteUploadSession
+DELETE FROM upload_sessions WHERE id = $1;
+
+-- name: ListExpiredUploadSessions
+SELECT s.id, s.user_id, s.file_key, s.storage_upload_id, s.original_name, s.mime_type,
+       s.total_size, s.received_size,
+       (SELECT COUNT(*) FROM upload_session_parts p WHERE p.session_id = s.id)::int AS part_count,
+       s.expires_at, s.created_at
+FROM upload_sessions s
+WHERE s.expires_at <= NOW()
+ORDER BY s.expires_at
+LIMIT $1;
+
+-- name: CreateDirectUpload
+INSERT INTO direct_uploads (id, user_id, file_key, original_name, mime_type, file_size, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING created_at;
+
+-- name: GetDirectUpload
+-- 期限切れのアップロードは存在しないものとして扱う
+SELECT id, user_id, file_key, original_name, mime_type, file_size, expires_at, created_at
+FROM direct_uploads
+WHERE id = $1 AND user_id = $2 AND expires_at > NOW();
+
+-- name: DeleteDirectUpload
+DELETE FROM direct_uploads WHERE id = $1;
+
+-- name: ListExpiredDirectUploads
+SELECT id, user_id, file_key, original_name, mime_type, file_size, expires_at, created_at
+FROM direct_uploads
+WHERE expires_at <= NOW()
+ORDER BY expires_at
+LIMIT $1;
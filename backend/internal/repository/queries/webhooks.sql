@@ -0,0 +1,100 @@
+-- name: CreateWebhook
+INSERT INTO webhooks (user_id, url, secret, events, description)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, active, created_at, updated_at;
+
+-- name: CountUserWebhooks
+SELECT COUNT(*) FROM webhooks WHERE user_id = $1;
+
+-- name: ListUserWebhooks
+SELECT id, user_id, url, events, description, active, created_at, updated_at
+FROM webhooks
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetWebhook
+SELECT id, user_id, url, events, description, active, created_at, updated_at
+FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateWebhook
+UPDATE webhooks
+SET url = $3, events = $4, description = $5, active = $6, updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING updated_at;
+
+-- name: UpdateWebhookSecret
+UPDATE webhooks
+SET secret = $3, updated_at = NOW()
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteWebhook
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: EnqueueWebhookDeliveries
+-- ユーザー（$1）の有効な Webhook のうち、イベントの種類（$3）を購読しているものごとに配信を1件追加する
+INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+SELECT id, $2, $3, $4
+FROM webhooks
+WHERE user_id = $1 AND active = true AND $3 = ANY(events);
+
+-- name: ListWebhookDeliveries
+-- $3/$4: 前のページの最後の配信の作成日時と ID（NULL で先頭のページ）、$5: 最大件数
+SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+       CASE WHEN d.status = 'pending' THEN d.next_attempt_at END,
+       d.last_attempt_at, d.response_status, d.response_body, d.error, d.duration_ms,
+       d.created_at, d.delivered_at
+FROM webhook_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+WHERE d.webhook_id = $1 AND w.user_id = $2
+  AND ($4::int IS NULL OR (d.created_at, d.id) < ($3::timestamp, $4))
+ORDER BY d.created_at DESC, d.id DESC
+LIMIT $5;
+
+-- name: RedeliverWebhookDelivery
+-- 同じイベント・同じ内容の配信を新しく追加する（元の配信の記録は残す）
+INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+SELECT d.webhook_id, d.event_id, d.event_type, d.payload
+FROM webhook_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+WHERE d.id = $1 AND d.webhook_id = $2 AND w.user_id = $3
+RETURNING id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+          last_attempt_at, response_status, response_body, error, duration_ms, created_at, delivered_at;
+
+-- name: ClaimDueWebhookDeliveries
+-- 送信する時刻になった有効な Webhook の配信を最大 $1 件取得する
+-- 同時に動く他のプロセスが同じ配信を送らないよう、取得した配信の次の送信日時を $2 秒後に延ばしてから返す
+UPDATE webhook_deliveries d
+SET next_attempt_at = NOW() + make_interval(secs => $2)
+FROM webhooks w
+WHERE w.id = d.webhook_id
+  AND d.id IN (
+      SELECT due.id
+      FROM webhook_deliveries due
+      JOIN webhooks active_hook ON active_hook.id = due.webhook_id
+      WHERE due.status = 'pending' AND due.next_attempt_at <= NOW() AND active_hook.active = true
+      ORDER BY due.next_attempt_at, due.id
+      LIMIT $1
+      FOR UPDATE OF due SKIP LOCKED
+  )
+RETURNING d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.attempts, w.url, w.secret;
+
+-- name: RecordWebhookDeliveryAttempt
+-- $3: 再試行する日時（再試行しない場合は NULL で、次の送信日時は変えない）
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    next_attempt_at = COALESCE($3, next_attempt_at),
+    last_attempt_at = NOW(),
+    response_status = $4,
+    response_body = $5,
+    error = $6,
+    duration_ms = $7,
+    delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() ELSE delivered_at END
+WHERE id = $1;
+
+-- name: PruneWebhookDeliveries
+-- 送信を終えた（成功・失敗が確定した）配信のうち、$1 より前に作成したものを削除する
+DELETE FROM webhook_deliveries
+WHERE status <> 'pending' AND created_at < $1;
@@ -0,0 +1,35 @@
+-- name: RevokePendingWorkspaceInvitationsForEmail
+-- 同じメールアドレスへの未承諾の招待を取り消す（招待し直すと以前のリンクは使えなくなる）
+UPDATE workspace_invitations
+SET revoked_at = NOW()
+WHERE workspace_id = $1 AND LOWER(email) = LOWER($2) AND accepted_at IS NULL AND revoked_at IS NULL;
+
+-- name: CreateWorkspaceInvitation
+INSERT INTO workspace_invitations (workspace_id, email, role, token_hash, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at;
+
+-- name: ListPendingWorkspaceInvitations
+SELECT id, workspace_id, email, role, invited_by, created_at, expires_at, accepted_at, revoked_at
+FROM workspace_invitations
+WHERE workspace_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetWorkspaceInvitationByTokenHash
+SELECT i.id, i.workspace_id, i.email, i.role, i.invited_by, i.created_at, i.expires_at, i.accepted_at, i.revoked_at,
+       w.name, COALESCE(NULLIF(u.name, ''), u.email, '')
+FROM workspace_invitations i
+JOIN workspaces w ON w.id = i.workspace_id
+LEFT JOIN users u ON u.id = i.invited_by
+WHERE i.token_hash = $1;
+
+-- name: RevokeWorkspaceInvitation
+UPDATE workspace_invitations
+SET revoked_at = NOW()
+WHERE id = $1 AND workspace_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL;
+
+-- name: AcceptWorkspaceInvitation
+-- 承諾と同時に期限切れ・取り消しを確認する（同時に承諾された場合は 0 行）
+UPDATE workspace_invitations
+SET accepted_at = NOW(), accepted_by = $2
+WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW();
@@ -0,0 +1,84 @@
+-- name: CreateWorkspace
+INSERT INTO workspaces (name, owner_id)
+VALUES ($1, $2)
+RETURNING id, created_at, updated_at;
+
+-- name: AddWorkspaceMember
+-- 既にメンバーの場合は何もしない（0 行）
+INSERT INTO workspace_members (workspace_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (workspace_id, user_id) DO NOTHING;
+
+-- name: GetWorkspaceForMember
+SELECT w.id, w.name, w.owner_id, w.created_at, w.updated_at, m.role
+FROM workspaces w
+JOIN workspace_members m ON m.workspace_id = w.id
+WHERE w.id = $1 AND m.user_id = $2;
+
+-- name: ListWorkspacesForUser
+SELECT w.id, w.name, w.owner_id, w.created_at, w.updated_at, m.role
+FROM workspaces w
+JOIN workspace_members m ON m.workspace_id = w.id
+WHERE m.user_id = $1
+ORDER BY w.name, w.id;
+
+-- name: RenameWorkspace
+UPDATE workspaces
+SET name = $1, updated_at = NOW()
+WHERE id = $2;
+
+-- name: DeleteWorkspace
+DELETE FROM workspaces
+WHERE id = $1;
+
+-- name: ListWorkspaceMembers
+SELECT m.workspace_id, m.user_id, u.email, u.name, m.role, m.joined_at
+FROM workspace_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.workspace_id = $1
+ORDER BY m.joined_at, m.user_id;
+
+-- name: GetWorkspaceMemberRole
+SELECT role
+FROM workspace_members
+WHERE workspace_id = $1 AND user_id = $2;
+
+-- name: UpdateWorkspaceMemberRole
+UPDATE workspace_members
+SET role = $1
+WHERE workspace_id = $2 AND user_id = $3;
+
+-- name: RemoveWorkspaceMember
+DELETE FROM workspace_members
+WHERE workspace_id = $1 AND user_id = $2;
+
+-- name: GetWorkspaceUsage
+-- ごみ箱内の文書も数える（復元すると戻るため）
+SELECT (SELECT COUNT(*) FROM documents WHERE workspace_id = $1),
+       (SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1);
+
+-- name: GetWorkspaceStorageQuota
+SELECT storage_quota
+FROM workspaces
+WHERE id = $1;
+
+-- name: SetWorkspaceStorageQuota
+UPDATE workspaces
+SET storage_quota = $1, updated_at = NOW()
+WHERE id = $2;
+
+-- name: GetWorkspaceStorageUsedBytes
+-- 現在のメンバーが持っている使用中のファイルの合計（メンバーから外れたユーザーのファイルは数えない）
+SELECT COALESCE(SUM(usage.total_bytes), 0)
+FROM workspace_members m
+JOIN user_storage_usage usage ON usage.user_id = m.user_id
+WHERE m.workspace_id = $1;
+
+-- name: ListWorkspaceMemberStorageUsage
+SELECT m.user_id, u.email, u.name, m.role,
+       COALESCE(usage.file_count, 0), COALESCE(usage.total_bytes, 0)
+FROM workspace_members m
+JOIN users u ON u.id = m.user_id
+LEFT JOIN user_storage_usage usage ON usage.user_id = m.user_id
+WHERE m.workspace_id = $1
+ORDER BY COALESCE(usage.total_bytes, 0) DESC, m.user_id;
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// ReminderRepository - 文書リマインダー操作専用リポジトリ
+type ReminderRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewReminderRepository - ReminderRepositoryを初期化
+func NewReminderRepository(db *sql.DB) (*ReminderRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &ReminderRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateReminder - リマインダーを作成
+func (r *ReminderRepository) CreateReminder(reminder *models.Reminder) error {
+	query, err := r.queries.Get("CreateReminder")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, reminder.DocumentID, reminder.UserID, reminder.RemindAt, reminder.Note).Scan(
+		&reminder.ID, &reminder.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create reminder: %w", err)
+	}
+
+	return nil
+}
+
+// ListReminders - ユーザーの未解除リマインダーを日時順に取得
+func (r *ReminderRepository) ListReminders(userID int) ([]models.Reminder, error) {
+	query, err := r.queries.Get("ListReminders")
+	if err != nil {
+		return nil, err
+	}
+	return r.listReminders(query, userID)
+}
+
+// DismissReminder - リマインダーを解除
+func (r *ReminderRepository) DismissReminder(id, userID int) error {
+	query, err := r.queries.Get("DismissReminder")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to dismiss reminder: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("reminder id=%d or access denied: %w", id, apierror.ErrNotFound)
+	}
+
+	return nil
+}
+
+// ListDueReminders - 通知日時に到達した未送信のリマインダーを取得
+func (r *ReminderRepository) ListDueReminders() ([]models.Reminder, error) {
+	query, err := r.queries.Get("ListDueReminders")
+	if err != nil {
+		return nil, err
+	}
+	return r.listReminders(query)
+}
+
+// MarkDelivered - リマインダーを送信済みにする
+func (r *ReminderRepository) MarkDelivered(id int) error {
+	query, err := r.queries.Get("MarkReminderDelivered")
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to mark reminder as delivered: %w", err)
+	}
+
+	return nil
+}
+
+// listReminders - リマインダー一覧クエリを実行
+func (r *ReminderRepository) listReminders(query string, args ...interface{}) ([]models.Reminder, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := make([]models.Reminder, 0)
+	for rows.Next() {
+		var rem models.Reminder
+		err := rows.Scan(&rem.ID, &rem.DocumentID, &rem.UserID, &rem.DocumentTitle, &rem.RemindAt,
+			&rem.Note, &rem.DeliveredAt, &rem.DismissedAt, &rem.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, rem)
+	}
+
+	return reminders, rows.Err()
+}
@@ -1,7 +1,20 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/lib/pq"
 
 	"simple-notion-backend/internal/models"
 )
@@ -92,3 +105,182 @@ func TestRepositoryInterfaces(t *testing.T) {
 		}
 	})
 }
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "UNIQUE 制約違反", err: &pq.Error{Code: postgresUniqueViolation}, want: true},
+		{name: "ラップされた UNIQUE 制約違反", err: fmt.Errorf("insert user: %w", &pq.Error{Code: postgresUniqueViolation}), want: true},
+		{name: "他の制約違反", err: &pq.Error{Code: "23503"}, want: false},
+		{name: "PostgreSQL 以外のエラー", err: errors.New("connection refused"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUniqueViolation(tt.err); got != tt.want {
+				t.Errorf("isUniqueViolation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// BenchmarkUpdateBlocks - 500 ブロックの文書全体の保存（挿入・親子関係の設定を1回ずつの SQL で行う）
+// マイグレーション済みの DB を TEST_DATABASE_URL に指定した場合のみ実行する
+//
+//	TEST_DATABASE_URL=postgres://... go test ./internal/repository -run '^$' -bench UpdateBlocks
+func BenchmarkUpdateBlocks(b *testing.B) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	var userID, docID int
+	email := fmt.Sprintf("bench-%d@example.com", time.Now().UnixNano())
+	if err := db.QueryRow(`INSERT INTO users (email, password_hash, name) VALUES ($1, 'x', 'bench') RETURNING id`, email).Scan(&userID); err != nil {
+		b.Fatal(err)
+	}
+	defer db.Exec(`DELETE FROM users WHERE id = $1`, userID)
+	if err := db.QueryRow(`INSERT INTO documents (user_id, title) VALUES ($1, 'bench') RETURNING id`, userID).Scan(&docID); err != nil {
+		b.Fatal(err)
+	}
+
+	repo, err := NewBlockRepository(db)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	blocks := benchmarkBlocks(500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := repo.UpdateBlocks(context.Background(), docID, userID, blocks); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// roundTripLatency は BenchmarkUpdateBlocksRoundTrips で1回の SQL の往復にかかるものとする時間です（同じデータセンター内の DB を想定）
+const roundTripLatency = 200 * time.Microsecond
+
+// roundTripDriver は SQL を実行せず、1回の往復ごとに roundTripLatency だけ待つ database/sql のドライバーです
+// DB なしで、文書の保存で DB と何回往復するかの違いを測るために使う
+type roundTripDriver struct {
+	roundTrips int64
+}
+
+func (d *roundTripDriver) Open(string) (driver.Conn, error) { return &roundTripConn{driver: d}, nil }
+
+// wait は 1回の往復を記録して待ちます
+func (d *roundTripDriver) wait() {
+	atomic.AddInt64(&d.roundTrips, 1)
+	// time.Sleep は短い時間では 1ms 程度まで延びるため、経過するまで待ち続ける
+	for start := time.Now(); time.Since(start) < roundTripLatency; {
+	}
+}
+
+type roundTripConn struct {
+	driver *roundTripDriver
+	nextID int
+}
+
+func (c *roundTripConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *roundTripConn) Close() error                        { return nil }
+func (c *roundTripConn) Begin() (driver.Tx, error)           { c.driver.wait(); return c, nil }
+func (c *roundTripConn) Commit() error                       { c.driver.wait(); return nil }
+func (c *roundTripConn) Rollback() error                     { return nil }
+
+func (c *roundTripConn) ExecContext(_ context.Context, _ string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.wait()
+	return driver.RowsAffected(1), nil
+}
+
+// QueryContext は INSERT ... RETURNING には挿入したものとして ID を返し、それ以外の SELECT には0行を返します
+func (c *roundTripConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.wait()
+	rows := &roundTripRows{}
+	switch {
+	case strings.Contains(query, "RETURNING id, sort_key"):
+		// 一括挿入: 並び順キーの配列（$4）の要素ごとに1行
+		keys := strings.Split(strings.Trim(args[3].Value.(string), "{}"), ",")
+		for _, key := range keys {
+			c.nextID++
+			rows.values = append(rows.values, []driver.Value{int64(c.nextID), strings.Trim(key, `"`)})
+		}
+	case strings.Contains(query, "RETURNING id"):
+		c.nextID++
+		rows.values = append(rows.values, []driver.Value{int64(c.nextID)})
+	}
+	return rows, nil
+}
+
+type roundTripRows struct {
+	values [][]driver.Value
+}
+
+func (r *roundTripRows) Columns() []string {
+	if len(r.values) == 0 {
+		return []string{"id"}
+	}
+	return make([]string, len(r.values[0]))
+}
+func (r *roundTripRows) Close() error { return nil }
+func (r *roundTripRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// benchmarkBlocks は 10 件ごとにトグルとその子ブロックを置いた n 件のブロックを作成します
+func benchmarkBlocks(n int) []models.Block {
+	blocks := make([]models.Block, n)
+	for i := range blocks {
+		blocks[i] = models.Block{ID: i + 1, Type: "text", Content: json.RawMessage(fmt.Sprintf(`"paragraph %d"`, i)), Position: i}
+		if i%10 == 0 {
+			blocks[i].Type = "toggle"
+		} else {
+			parentID := i/10*10 + 1
+			blocks[i].ParentBlockID = &parentID
+		}
+	}
+	return blocks
+}
+
+// BenchmarkUpdateBlocksRoundTrips - 500 ブロックの文書全体の保存で DB と往復する回数と、往復ごとに 0.2ms かかる場合の所要時間
+// 実際の DB での所要時間は BenchmarkUpdateBlocks で測る
+func BenchmarkUpdateBlocksRoundTrips(b *testing.B) {
+	rt := &roundTripDriver{}
+	db := sql.OpenDB(roundTripConnector{rt})
+	defer db.Close()
+
+	repo, err := NewBlockRepository(db)
+	if err != nil {
+		b.Fatal(err)
+	}
+	blocks := benchmarkBlocks(500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := repo.UpdateBlocks(context.Background(), 1, 1, blocks); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(atomic.LoadInt64(&rt.roundTrips))/float64(b.N), "roundtrips/op")
+}
+
+type roundTripConnector struct{ driver *roundTripDriver }
+
+func (c roundTripConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open("") }
+func (c roundTripConnector) Driver() driver.Driver                        { return c.driver }
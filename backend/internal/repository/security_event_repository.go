@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/models"
+)
+
+// SecurityEventRepository - セキュリティイベント記録専用リポジトリ
+type SecurityEventRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewSecurityEventRepository - SecurityEventRepositoryを初期化
+func NewSecurityEventRepository(db *sql.DB) (*SecurityEventRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &SecurityEventRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateEvent - セキュリティイベントを記録
+func (r *SecurityEventRepository) CreateEvent(event *models.SecurityEvent) error {
+	query, err := r.queries.Get("CreateSecurityEvent")
+	if err != nil {
+		return err
+	}
+
+	details := event.Details
+	if len(details) == 0 {
+		details = []byte("{}")
+	}
+
+	err = r.db.QueryRow(query, event.UserID, event.DocumentID, event.EventType, []byte(details)).Scan(
+		&event.ID, &event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create security event: %w", err)
+	}
+
+	return nil
+}
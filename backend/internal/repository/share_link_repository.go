@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// ShareLinkRepository - 文書の共有リンク操作専用リポジトリ
+type ShareLinkRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewShareLinkRepository - ShareLinkRepositoryを初期化
+func NewShareLinkRepository(db *sql.DB) (*ShareLinkRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &ShareLinkRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateShareLink - 共有リンクを作成（トークンはハッシュのみを保存する）
+func (r *ShareLinkRepository) CreateShareLink(link *models.ShareLink, tokenHash string) error {
+	query, err := r.queries.Get("CreateShareLink")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, link.DocumentID, tokenHash, link.PasswordHash, link.ExpiresAt, link.CreatedBy, link.SnapshotID).Scan(
+		&link.ID, &link.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create share link: %w", err)
+	}
+	link.HasPassword = link.PasswordHash != ""
+	return nil
+}
+
+// ListShareLinks - 文書の共有リンク一覧を新しい順に取得（無効化したリンクを含む）
+func (r *ShareLinkRepository) ListShareLinks(docID int) ([]models.ShareLink, error) {
+	query, err := r.queries.Get("ListShareLinks")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, docID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]models.ShareLink, 0)
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+	return links, rows.Err()
+}
+
+// GetShareLinkByTokenHash - トークンのハッシュから共有リンクを取得（文書がごみ箱内の場合は ErrNotFound）
+func (r *ShareLinkRepository) GetShareLinkByTokenHash(tokenHash string) (*models.ShareLink, error) {
+	query, err := r.queries.Get("GetShareLinkByTokenHash")
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := scanShareLink(r.db.QueryRow(query, tokenHash))
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, "share link")
+	}
+	return link, nil
+}
+
+// RevokeShareLink - 共有リンクを無効化（無効化済み・存在しない場合は ErrNotFound）
+func (r *ShareLinkRepository) RevokeShareLink(docID, linkID int) error {
+	query, err := r.queries.Get("RevokeShareLink")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, linkID, docID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("share link id=%d document=%d: %w", linkID, docID, apierror.ErrNotFound)
+	}
+	return nil
+}
+
+// RecordAccess - 共有リンクの最終アクセス日時と閲覧回数を更新
+func (r *ShareLinkRepository) RecordAccess(linkID int) error {
+	query, err := r.queries.Get("RecordShareLinkAccess")
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.Exec(query, linkID); err != nil {
+		return fmt.Errorf("failed to record share link access: %w", err)
+	}
+	return nil
+}
+
+// GetSharedDocument - 共有リンクの文書を取得（所有者の確認はしない。ごみ箱内の場合は ErrNotFound）
+func (r *ShareLinkRepository) GetSharedDocument(docID int) (*models.Document, error) {
+	query, err := r.queries.Get("GetSharedDocument")
+	if err != nil {
+		return nil, err
+	}
+
+	var doc models.Document
+	err = r.db.QueryRow(query, docID).Scan(
+		&doc.ID, &doc.UserID, &doc.ParentID, &doc.Title,
+		&doc.Content, &doc.TreePath, &doc.Level, &doc.SortOrder,
+		&doc.IsDeleted, &doc.CreatedAt, &doc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("shared document id=%d", docID))
+	}
+	return &doc, nil
+}
+
+// CreateSnapshot - 内容を固定した文書を保存
+func (r *ShareLinkRepository) CreateSnapshot(snapshot *models.DocumentSnapshot) error {
+	query, err := r.queries.Get("CreateDocumentSnapshot")
+	if err != nil {
+		return err
+	}
+
+	blocks, err := json.Marshal(snapshot.Blocks)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot blocks: %w", err)
+	}
+	fileIDs := make([]int64, 0, len(snapshot.FileIDs))
+	for _, id := range snapshot.FileIDs {
+		fileIDs = append(fileIDs, int64(id))
+	}
+
+	err = r.db.QueryRow(query, snapshot.DocumentID, snapshot.Title, snapshot.Content, blocks, pq.Array(fileIDs), snapshot.CreatedBy).
+		Scan(&snapshot.ID, &snapshot.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create document snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetSnapshot - 内容を固定した文書を取得
+func (r *ShareLinkRepository) GetSnapshot(snapshotID int) (*models.DocumentSnapshot, error) {
+	query, err := r.queries.Get("GetDocumentSnapshot")
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot models.DocumentSnapshot
+	var blocks []byte
+	var fileIDs pq.Int64Array
+	err = r.db.QueryRow(query, snapshotID).Scan(&snapshot.ID, &snapshot.DocumentID, &snapshot.Title, &snapshot.Content,
+		&blocks, &fileIDs, &snapshot.CreatedBy, &snapshot.CreatedAt)
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("document snapshot id=%d", snapshotID))
+	}
+	if err := json.Unmarshal(blocks, &snapshot.Blocks); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot blocks: %w", err)
+	}
+	for _, id := range fileIDs {
+		snapshot.FileIDs = append(snapshot.FileIDs, int(id))
+	}
+	return &snapshot, nil
+}
+
+// scanShareLink - 共有リンク1行を読み取る
+func scanShareLink(row rowScanner) (*models.ShareLink, error) {
+	var link models.ShareLink
+	err := row.Scan(&link.ID, &link.DocumentID, &link.SnapshotID, &link.PasswordHash, &link.ExpiresAt, &link.CreatedBy,
+		&link.CreatedAt, &link.RevokedAt, &link.LastAccessedAt, &link.AccessCount)
+	if err != nil {
+		return nil, err
+	}
+	link.HasPassword = link.PasswordHash != ""
+	return &link, nil
+}
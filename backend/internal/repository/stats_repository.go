@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"simple-notion-backend/internal/models"
+)
+
+// StatsRepository - 管理者向け統計の集計専用リポジトリ
+type StatsRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewStatsRepository - StatsRepositoryを初期化
+func NewStatsRepository(db *sql.DB) (*StatsRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &StatsRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// GetUserStats - 全ユーザー数と since 以降に登録したユーザー数を取得
+func (r *StatsRepository) GetUserStats(since time.Time) (models.UserStats, error) {
+	var stats models.UserStats
+	query, err := r.queries.Get("GetUserStats")
+	if err != nil {
+		return stats, err
+	}
+
+	err = r.db.QueryRow(query, since).Scan(&stats.Total, &stats.NewInTerm)
+	return stats, err
+}
+
+// GetDocumentStats - 全文書数とごみ箱内の文書数を取得
+func (r *StatsRepository) GetDocumentStats() (models.DocumentStats, error) {
+	var stats models.DocumentStats
+	query, err := r.queries.Get("GetDocumentStats")
+	if err != nil {
+		return stats, err
+	}
+
+	err = r.db.QueryRow(query).Scan(&stats.Total, &stats.Trashed)
+	return stats, err
+}
+
+// GetStorageStats - 有効なファイルの件数と合計サイズを取得
+func (r *StatsRepository) GetStorageStats() (models.StorageStats, error) {
+	var stats models.StorageStats
+	query, err := r.queries.Get("GetStorageStats")
+	if err != nil {
+		return stats, err
+	}
+
+	err = r.db.QueryRow(query).Scan(&stats.Files, &stats.Bytes)
+	return stats, err
+}
+
+// GetUploadsPerDay - since の日付から今日までの日ごとのアップロード件数を取得
+func (r *StatsRepository) GetUploadsPerDay(since time.Time) ([]models.DailyCount, error) {
+	return r.dailyCounts("GetUploadsPerDay", since)
+}
+
+// GetActiveUsersPerDay - since の日付から今日までの日ごとのアクティブユーザー数を取得
+func (r *StatsRepository) GetActiveUsersPerDay(since time.Time) ([]models.DailyCount, error) {
+	return r.dailyCounts("GetActiveUsersPerDay", since)
+}
+
+// dailyCounts - (日付, 件数) を返す集計クエリを実行
+func (r *StatsRepository) dailyCounts(name string, since time.Time) ([]models.DailyCount, error) {
+	query, err := r.queries.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	counts := make([]models.DailyCount, 0)
+	for rows.Next() {
+		var c models.DailyCount
+		if err := rows.Scan(&c.Date, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/models"
+)
+
+// TaskRepository - todo ブロックをタスクとして集計する専用リポジトリ
+type TaskRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewTaskRepository - TaskRepositoryを初期化
+func NewTaskRepository(db *sql.DB) (*TaskRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &TaskRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// ListOpenTasks - ユーザーの全文書から未完了の todo ブロックを取得
+func (r *TaskRepository) ListOpenTasks(userID int) ([]models.Task, error) {
+	query, err := r.queries.Get("ListOpenTasks")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0)
+	for rows.Next() {
+		var t models.Task
+		err := rows.Scan(&t.BlockID, &t.DocumentID, &t.DocumentTitle,
+			&t.Text, &t.DueDate, &t.Position, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rows.Err()
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// UploadSessionRepository - 再開可能な分割アップロードのセッション操作専用リポジトリ
+type UploadSessionRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewUploadSessionRepository - UploadSessionRepositoryを初期化
+func NewUploadSessionRepository(db *sql.DB) (*UploadSessionRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &UploadSessionRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateSession - セッションを作成
+func (r *UploadSessionRepository) CreateSession(session *models.UploadSession) error {
+	query, err := r.queries.Get("CreateUploadSession")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, session.ID, session.UserID, session.FileKey, session.StorageUploadID,
+		session.OriginalName, session.MimeType, session.TotalSize, session.ExpiresAt).Scan(&session.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	return nil
+}
+
+// GetSession - ユーザーの有効なセッションを取得（期限切れ・他ユーザーのセッションは ErrNotFound）
+func (r *UploadSessionRepository) GetSession(id string, userID int) (*models.UploadSession, error) {
+	query, err := r.queries.Get("GetUploadSession")
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := scanUploadSession(r.db.QueryRow(query, id, userID))
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("upload session id=%s", id))
+	}
+
+	return session, nil
+}
+
+// AppendPart - 受信したパートを保存し、受信済みサイズを offset から進めて期限を延長
+// 受信済みサイズが offset と異なる（他のリクエストが先に追記した）場合は ErrConflict を返す
+func (r *UploadSessionRepository) AppendPart(id string, offset int64, part models.UploadPart, expiresAt time.Time) error {
+	advanceQuery, err := r.queries.Get("AdvanceUploadSession")
+	if err != nil {
+		return err
+	}
+	saveQuery, err := r.queries.Get("SaveUploadPart")
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(advanceQuery, id, offset, part.Size, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to advance upload session: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("upload session id=%s offset=%d: %w", id, offset, apierror.ErrConflict)
+	}
+
+	if _, err := tx.Exec(saveQuery, id, part.PartNumber, part.ETag, part.Size); err != nil {
+		return fmt.Errorf("failed to save upload part: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ListParts - セッションの受信済みパートをパート番号順に取得
+func (r *UploadSessionRepository) ListParts(id string) ([]models.UploadPart, error) {
+	query, err := r.queries.Get("ListUploadParts")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload parts: %w", err)
+	}
+	defer rows.Close()
+
+	var parts []models.UploadPart
+	for rows.Next() {
+		var part models.UploadPart
+		if err := rows.Scan(&part.PartNumber, &part.ETag, &part.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan upload part: %w", err)
+		}
+		parts = append(parts, part)
+	}
+	return parts, rows.Err()
+}
+
+// DeleteSession - セッションと受信済みパートの記録を削除
+func (r *UploadSessionRepository) DeleteSession(id string) error {
+	query, err := r.queries.Get("DeleteUploadSession")
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to delete upload session: %w", err)
+	}
+	return nil
+}
+
+// ListExpiredSessions - 期限切れのセッションを古い順に最大 limit 件取得
+func (r *UploadSessionRepository) ListExpiredSessions(limit int) ([]models.UploadSession, error) {
+	query, err := r.queries.Get("ListExpiredUploadSessions")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired upload sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.UploadSession
+	for rows.Next() {
+		session, err := scanUploadSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan upload session: %w", err)
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+// scanUploadSession - セッションの1行を読み取る
+func scanUploadSession(row rowScanner) (*models.UploadSession, error) {
+	var session models.UploadSession
+	err := row.Scan(&session.ID, &session.UserID, &session.FileKey, &session.StorageUploadID,
+		&session.OriginalName, &session.MimeType, &session.TotalSize, &session.Offset,
+		&session.PartCount, &session.ExpiresAt, &session.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// CreateDirectUpload - 直接アップロードの発行を記録
+func (r *UploadSessionRepository) CreateDirectUpload(upload *models.DirectUpload) error {
+	query, err := r.queries.Get("CreateDirectUpload")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, upload.ID, upload.UserID, upload.FileKey, upload.OriginalName,
+		upload.MimeType, upload.FileSize, upload.ExpiresAt).Scan(&upload.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create direct upload: %w", err)
+	}
+
+	return nil
+}
+
+// GetDirectUpload - ユーザーの確認前の直接アップロードを取得（期限切れ・他ユーザーのものは ErrNotFound）
+func (r *UploadSessionRepository) GetDirectUpload(id string, userID int) (*models.DirectUpload, error) {
+	query, err := r.queries.Get("GetDirectUpload")
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := scanDirectUpload(r.db.QueryRow(query, id, userID))
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("direct upload id=%s", id))
+	}
+
+	return upload, nil
+}
+
+// DeleteDirectUpload - 直接アップロードの記録を削除
+func (r *UploadSessionRepository) DeleteDirectUpload(id string) error {
+	query, err := r.queries.Get("DeleteDirectUpload")
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to delete direct upload: %w", err)
+	}
+	return nil
+}
+
+// ListExpiredDirectUploads - 期限切れの直接アップロードを古い順に最大 limit 件取得
+func (r *UploadSessionRepository) ListExpiredDirectUploads(limit int) ([]models.DirectUpload, error) {
+	query, err := r.queries.Get("ListExpiredDirectUploads")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired direct uploads: %w", err)
+	}
+	defer rows.Close()
+
+	var uploads []models.DirectUpload
+	for rows.Next() {
+		upload, err := scanDirectUpload(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan direct upload: %w", err)
+		}
+		uploads = append(uploads, *upload)
+	}
+	return uploads, rows.Err()
+}
+
+// scanDirectUpload - 直接アップロードの1行を読み取る
+func scanDirectUpload(row rowScanner) (*models.DirectUpload, error) {
+	var upload models.DirectUpload
+	err := row.Scan(&upload.ID, &upload.UserID, &upload.FileKey, &upload.OriginalName,
+		&upload.MimeType, &upload.FileSize, &upload.ExpiresAt, &upload.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
@@ -14,6 +14,12 @@ import (
 // postgresUniqueViolation は PostgreSQL の UNIQUE 制約違反エラーコード
 const postgresUniqueViolation = "23505"
 
+// isUniqueViolation は err（ラップされていてもよい）が UNIQUE 制約違反かどうかを判定する
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == postgresUniqueViolation
+}
+
 type UserRepository struct {
 	db      *sql.DB
 	queries *SQLQueries
@@ -80,8 +86,7 @@ func (r *UserRepository) Create(user *models.User) error {
 	)
 	if err != nil {
 		// UNIQUE 制約違反（email 重複など）は ErrConflict にラップする
-		var pqErr *pq.Error
-		if errors.As(err, &pqErr) && pqErr.Code == postgresUniqueViolation {
+		if isUniqueViolation(err) {
 			return fmt.Errorf("user email=%s: %w", user.Email, apierror.ErrConflict)
 		}
 		return err
@@ -99,3 +104,26 @@ func (r *UserRepository) Update(user *models.User) error {
 	_, err = r.db.Exec(query, user.Email, user.Name, user.ID)
 	return err
 }
+
+// UpdatePassword - パスワードハッシュのみを更新（ログイン時の再ハッシュなど）
+func (r *UserRepository) UpdatePassword(userID int, passwordHash string) error {
+	query, err := r.queries.Get("UpdateUserPassword")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user id=%d: %w", userID, apierror.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
+
+// WebhookRepository - Webhook とその配信の操作専用リポジトリ
+type WebhookRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewWebhookRepository - WebhookRepositoryを初期化
+func NewWebhookRepository(db *sql.DB) (*WebhookRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &WebhookRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateWebhook - Webhook を登録する（ID・有効かどうか・作成日時を webhook に設定する）
+func (r *WebhookRepository) CreateWebhook(webhook *models.Webhook) error {
+	query, err := r.queries.Get("CreateWebhook")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, webhook.UserID, webhook.URL, webhook.Secret, pq.Array(webhook.Events), webhook.Description).
+		Scan(&webhook.ID, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// CountWebhooks - ユーザーが登録した Webhook の数を取得
+func (r *WebhookRepository) CountWebhooks(userID int) (int, error) {
+	query, err := r.queries.Get("CountUserWebhooks")
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count webhooks: %w", err)
+	}
+	return count, nil
+}
+
+// ListWebhooks - ユーザーが登録した Webhook を新しい順に取得（署名の鍵は含めない）
+func (r *WebhookRepository) ListWebhooks(userID int) ([]models.Webhook, error) {
+	query, err := r.queries.Get("ListUserWebhooks")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := make([]models.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// GetWebhook - ユーザーの Webhook を取得（署名の鍵は含めない。他のユーザーの Webhook は ErrNotFound）
+func (r *WebhookRepository) GetWebhook(id, userID int) (*models.Webhook, error) {
+	query, err := r.queries.Get("GetWebhook")
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := scanWebhook(r.db.QueryRow(query, id, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("webhook id=%d: %w", id, apierror.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// UpdateWebhook - Webhook の URL・イベント・説明・有効かどうかを保存する（他のユーザーの Webhook は ErrNotFound）
+func (r *WebhookRepository) UpdateWebhook(webhook *models.Webhook) error {
+	query, err := r.queries.Get("UpdateWebhook")
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(query, webhook.ID, webhook.UserID, webhook.URL, pq.Array(webhook.Events), webhook.Description, webhook.Active).
+		Scan(&webhook.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("webhook id=%d: %w", webhook.ID, apierror.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return nil
+}
+
+// UpdateSecret - Webhook の署名の鍵を置き換える（他のユーザーの Webhook は ErrNotFound）
+func (r *WebhookRepository) UpdateSecret(id, userID int, secret string) error {
+	query, err := r.queries.Get("UpdateWebhookSecret")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, id, userID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook secret: %w", err)
+	}
+	return requireAffected(result, fmt.Sprintf("webhook id=%d", id))
+}
+
+// DeleteWebhook - Webhook とその配信の記録を削除する（他のユーザーの Webhook は ErrNotFound）
+func (r *WebhookRepository) DeleteWebhook(id, userID int) error {
+	query, err := r.queries.Get("DeleteWebhook")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return requireAffected(result, fmt.Sprintf("webhook id=%d", id))
+}
+
+// EnqueueDeliveries - ユーザーの有効な Webhook のうち、イベントの種類を購読しているものごとに配信を追加する
+func (r *WebhookRepository) EnqueueDeliveries(userID int, eventID, eventType string, payload []byte) (int, error) {
+	query, err := r.queries.Get("EnqueueWebhookDeliveries")
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.Exec(query, userID, eventID, eventType, string(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// ListDeliveries - Webhook の配信を新しい順に1ページ分取得（他のユーザーの Webhook の配信は取得しない）
+func (r *WebhookRepository) ListDeliveries(webhookID, userID int, page pagination.Params) (pagination.Page[models.WebhookDelivery], error) {
+	query, err := r.queries.Get("ListWebhookDeliveries")
+	if err != nil {
+		return pagination.Page[models.WebhookDelivery]{}, err
+	}
+	if err := page.Check("", pagination.TimeKey); err != nil {
+		return pagination.Page[models.WebhookDelivery]{}, err
+	}
+
+	rows, err := r.db.Query(query, webhookID, userID, page.AfterKey(0), page.AfterID(), page.FetchLimit())
+	if err != nil {
+		return pagination.Page[models.WebhookDelivery]{}, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return pagination.Page[models.WebhookDelivery]{}, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.WebhookDelivery]{}, err
+	}
+
+	return pagination.NewPage(deliveries, page, func(i int) pagination.Cursor {
+		d := deliveries[i]
+		return pagination.Cursor{Keys: []string{pagination.FormatTime(d.CreatedAt)}, ID: d.ID}
+	}), nil
+}
+
+// Redeliver - 配信と同じイベント・同じ内容の配信を新しく追加する（他のユーザーの Webhook の配信は ErrNotFound）
+func (r *WebhookRepository) Redeliver(deliveryID, webhookID, userID int) (*models.WebhookDelivery, error) {
+	query, err := r.queries.Get("RedeliverWebhookDelivery")
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRow(query, deliveryID, webhookID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("webhook delivery id=%d: %w", deliveryID, apierror.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// ClaimDueDeliveries - 送信する時刻になった配信を最大 limit 件取得し、lease の間は他のプロセスが取得しないようにする
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDispatch, error) {
+	query, err := r.queries.Get("ClaimDueWebhookDeliveries")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	dispatches := make([]models.WebhookDispatch, 0)
+	for rows.Next() {
+		var d models.WebhookDispatch
+		if err := rows.Scan(&d.DeliveryID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Attempts, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		dispatches = append(dispatches, d)
+	}
+
+	return dispatches, rows.Err()
+}
+
+// RecordAttempt - 1回の送信の結果を配信に記録する
+func (r *WebhookRepository) RecordAttempt(deliveryID int, attempt models.WebhookAttempt) error {
+	query, err := r.queries.Get("RecordWebhookDeliveryAttempt")
+	if err != nil {
+		return err
+	}
+
+	durationMs := int(attempt.Duration.Milliseconds())
+	_, err = r.db.Exec(query, deliveryID, attempt.Status, attempt.NextAttemptAt,
+		attempt.ResponseStatus, attempt.ResponseBody, attempt.Error, durationMs)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+	return nil
+}
+
+// PruneDeliveries - 送信を終えた配信のうち、before より前に作成したものを削除する
+func (r *WebhookRepository) PruneDeliveries(before time.Time) (int, error) {
+	query, err := r.queries.Get("PruneWebhookDeliveries")
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.Exec(query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune webhook deliveries: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// scanWebhook - Webhook の1行を読み込む（署名の鍵は含めない）
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var events pq.StringArray
+	err := row.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &events,
+		&webhook.Description, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Events = []string(events)
+	return &webhook, nil
+}
+
+// scanWebhookDelivery - 配信の1行を読み込む
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload []byte
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.ResponseBody, &d.Error, &d.DurationMs,
+		&d.CreatedAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return &d, nil
+}
+
+// requireAffected - 1行も変更しなかった場合に ErrNotFound を返す
+func requireAffected(result sql.Result, target string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", target, apierror.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// WorkspaceInvitationRepository - ワークスペースへの招待操作専用リポジトリ
+type WorkspaceInvitationRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewWorkspaceInvitationRepository - WorkspaceInvitationRepositoryを初期化
+func NewWorkspaceInvitationRepository(db *sql.DB) (*WorkspaceInvitationRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &WorkspaceInvitationRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateInvitation - 招待を作成（トークンはハッシュのみを保存する）
+// 同じメールアドレスへの未承諾の招待は取り消し、新しい招待のリンクだけを使えるようにする
+func (r *WorkspaceInvitationRepository) CreateInvitation(invitation *models.WorkspaceInvitation, tokenHash string) error {
+	revokeQuery, err := r.queries.Get("RevokePendingWorkspaceInvitationsForEmail")
+	if err != nil {
+		return err
+	}
+	createQuery, err := r.queries.Get("CreateWorkspaceInvitation")
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(revokeQuery, invitation.WorkspaceID, invitation.Email); err != nil {
+		return fmt.Errorf("failed to revoke previous invitations: %w", err)
+	}
+	err = tx.QueryRow(createQuery, invitation.WorkspaceID, invitation.Email, invitation.Role, tokenHash,
+		invitation.InvitedBy, invitation.ExpiresAt).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace invitation: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListPendingInvitations - 承諾・取り消しされておらず期限内の招待を新しい順に取得
+func (r *WorkspaceInvitationRepository) ListPendingInvitations(workspaceID int) ([]models.WorkspaceInvitation, error) {
+	query, err := r.queries.Get("ListPendingWorkspaceInvitations")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := make([]models.WorkspaceInvitation, 0)
+	for rows.Next() {
+		var invitation models.WorkspaceInvitation
+		if err := rows.Scan(&invitation.ID, &invitation.WorkspaceID, &invitation.Email, &invitation.Role,
+			&invitation.InvitedBy, &invitation.CreatedAt, &invitation.ExpiresAt,
+			&invitation.AcceptedAt, &invitation.RevokedAt); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
+
+// GetInvitationByTokenHash - トークンのハッシュから招待をワークスペース名・招待したユーザーの名前とともに取得
+// 承諾・取り消し済み・期限切れの招待も返す（呼び出し側で確認する）
+func (r *WorkspaceInvitationRepository) GetInvitationByTokenHash(tokenHash string) (*models.WorkspaceInvitation, error) {
+	query, err := r.queries.Get("GetWorkspaceInvitationByTokenHash")
+	if err != nil {
+		return nil, err
+	}
+
+	var invitation models.WorkspaceInvitation
+	err = r.db.QueryRow(query, tokenHash).Scan(&invitation.ID, &invitation.WorkspaceID, &invitation.Email,
+		&invitation.Role, &invitation.InvitedBy, &invitation.CreatedAt, &invitation.ExpiresAt,
+		&invitation.AcceptedAt, &invitation.RevokedAt, &invitation.WorkspaceName, &invitation.InviterName)
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, "workspace invitation")
+	}
+	return &invitation, nil
+}
+
+// RevokeInvitation - 未承諾の招待を取り消す（承諾・取り消し済み・存在しない場合は ErrNotFound）
+func (r *WorkspaceInvitationRepository) RevokeInvitation(workspaceID, invitationID int) error {
+	query, err := r.queries.Get("RevokeWorkspaceInvitation")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, invitationID, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke workspace invitation: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("workspace invitation id=%d workspace=%d: %w", invitationID, workspaceID, apierror.ErrNotFound)
+	}
+	return nil
+}
+
+// AcceptInvitation - 招待を承諾済みにし、ユーザーを招待の役割でメンバーに追加する（同じトランザクションで行う）
+// 承諾・取り消し済み・期限切れの場合は ErrNotFound。既にメンバーの場合は役割を変えずに承諾済みにし、added は false
+func (r *WorkspaceInvitationRepository) AcceptInvitation(invitation *models.WorkspaceInvitation, userID int) (added bool, err error) {
+	acceptQuery, err := r.queries.Get("AcceptWorkspaceInvitation")
+	if err != nil {
+		return false, err
+	}
+	memberQuery, err := r.queries.Get("AddWorkspaceMember")
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(acceptQuery, invitation.ID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to accept workspace invitation: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, fmt.Errorf("workspace invitation id=%d: %w", invitation.ID, apierror.ErrNotFound)
+	}
+
+	result, err = tx.Exec(memberQuery, invitation.WorkspaceID, userID, invitation.Role)
+	if err != nil {
+		return false, fmt.Errorf("failed to add workspace member: %w", err)
+	}
+	if rowsAffected, err = result.RowsAffected(); err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
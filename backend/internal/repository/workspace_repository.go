@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// WorkspaceRepository - ワークスペースとメンバー操作専用リポジトリ
+type WorkspaceRepository struct {
+	db      *sql.DB
+	queries *SQLQueries
+}
+
+// NewWorkspaceRepository - WorkspaceRepositoryを初期化
+func NewWorkspaceRepository(db *sql.DB) (*WorkspaceRepository, error) {
+	queries, err := NewSQLQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &WorkspaceRepository{
+		db:      db,
+		queries: queries,
+	}, nil
+}
+
+// CreateWorkspace - ワークスペースを作成し、所有者をメンバーとして登録
+func (r *WorkspaceRepository) CreateWorkspace(workspace *models.Workspace) error {
+	createQuery, err := r.queries.Get("CreateWorkspace")
+	if err != nil {
+		return err
+	}
+	memberQuery, err := r.queries.Get("AddWorkspaceMember")
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(createQuery, workspace.Name, workspace.OwnerID).Scan(
+		&workspace.ID, &workspace.CreatedAt, &workspace.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+	if _, err := tx.Exec(memberQuery, workspace.ID, workspace.OwnerID, models.WorkspaceRoleOwner); err != nil {
+		return fmt.Errorf("failed to add workspace owner: %w", err)
+	}
+	workspace.Role = models.WorkspaceRoleOwner
+
+	return tx.Commit()
+}
+
+// GetWorkspace - ユーザーがメンバーのワークスペースを、そのユーザーの役割とともに取得
+// メンバーでない場合は ErrNotFound を返す
+func (r *WorkspaceRepository) GetWorkspace(workspaceID, userID int) (*models.Workspace, error) {
+	query, err := r.queries.Get("GetWorkspaceForMember")
+	if err != nil {
+		return nil, err
+	}
+
+	var workspace models.Workspace
+	err = r.db.QueryRow(query, workspaceID, userID).Scan(
+		&workspace.ID, &workspace.Name, &workspace.OwnerID, &workspace.CreatedAt, &workspace.UpdatedAt, &workspace.Role,
+	)
+	if err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("workspace id=%d user=%d", workspaceID, userID))
+	}
+
+	return &workspace, nil
+}
+
+// ListWorkspaces - ユーザーがメンバーのワークスペースを名前順に取得
+func (r *WorkspaceRepository) ListWorkspaces(userID int) ([]models.Workspace, error) {
+	query, err := r.queries.Get("ListWorkspacesForUser")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := make([]models.Workspace, 0)
+	for rows.Next() {
+		var workspace models.Workspace
+		if err := rows.Scan(&workspace.ID, &workspace.Name, &workspace.OwnerID,
+			&workspace.CreatedAt, &workspace.UpdatedAt, &workspace.Role); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// RenameWorkspace - ワークスペースの名前を変更
+func (r *WorkspaceRepository) RenameWorkspace(workspaceID int, name string) error {
+	query, err := r.queries.Get("RenameWorkspace")
+	if err != nil {
+		return err
+	}
+	return r.execWorkspace(query, fmt.Sprintf("workspace id=%d", workspaceID), name, workspaceID)
+}
+
+// DeleteWorkspace - ワークスペースを削除（属していた文書は作成したユーザーの個人の文書に戻る）
+func (r *WorkspaceRepository) DeleteWorkspace(workspaceID int) error {
+	query, err := r.queries.Get("DeleteWorkspace")
+	if err != nil {
+		return err
+	}
+	return r.execWorkspace(query, fmt.Sprintf("workspace id=%d", workspaceID), workspaceID)
+}
+
+// ListMembers - ワークスペースのメンバーを参加順に取得
+func (r *WorkspaceRepository) ListMembers(workspaceID int) ([]models.WorkspaceMember, error) {
+	query, err := r.queries.Get("ListWorkspaceMembers")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]models.WorkspaceMember, 0)
+	for rows.Next() {
+		var member models.WorkspaceMember
+		if err := rows.Scan(&member.WorkspaceID, &member.UserID, &member.Email, &member.Name,
+			&member.Role, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetMemberRole - メンバーの役割を取得（メンバーでない場合は ErrNotFound）
+func (r *WorkspaceRepository) GetMemberRole(workspaceID, userID int) (models.WorkspaceRole, error) {
+	query, err := r.queries.Get("GetWorkspaceMemberRole")
+	if err != nil {
+		return "", err
+	}
+
+	var role models.WorkspaceRole
+	if err := r.db.QueryRow(query, workspaceID, userID).Scan(&role); err != nil {
+		return "", apierror.WrapNotFound(err, fmt.Sprintf("workspace member workspace=%d user=%d", workspaceID, userID))
+	}
+	return role, nil
+}
+
+// AddMember - メンバーを追加（既にメンバーの場合は ErrConflict）
+func (r *WorkspaceRepository) AddMember(workspaceID, userID int, role models.WorkspaceRole) error {
+	query, err := r.queries.Get("AddWorkspaceMember")
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(query, workspaceID, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to add workspace member: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("workspace member workspace=%d user=%d: %w", workspaceID, userID, apierror.ErrConflict)
+	}
+	return nil
+}
+
+// UpdateMemberRole - メンバーの役割を変更
+func (r *WorkspaceRepository) UpdateMemberRole(workspaceID, userID int, role models.WorkspaceRole) error {
+	query, err := r.queries.Get("UpdateWorkspaceMemberRole")
+	if err != nil {
+		return err
+	}
+	return r.execWorkspace(query, fmt.Sprintf("workspace member workspace=%d user=%d", workspaceID, userID),
+		role, workspaceID, userID)
+}
+
+// RemoveMember - メンバーを削除
+func (r *WorkspaceRepository) RemoveMember(workspaceID, userID int) error {
+	query, err := r.queries.Get("RemoveWorkspaceMember")
+	if err != nil {
+		return err
+	}
+	return r.execWorkspace(query, fmt.Sprintf("workspace member workspace=%d user=%d", workspaceID, userID),
+		workspaceID, userID)
+}
+
+// GetUsage - ワークスペースの文書数（ごみ箱内を含む）とメンバー数を取得
+func (r *WorkspaceRepository) GetUsage(workspaceID int) (documents, members int, err error) {
+	query, err := r.queries.Get("GetWorkspaceUsage")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := r.db.QueryRow(query, workspaceID).Scan(&documents, &members); err != nil {
+		return 0, 0, fmt.Errorf("failed to get workspace usage: %w", err)
+	}
+	return documents, members, nil
+}
+
+// GetStorageQuota - ワークスペースに個別に設定されたストレージクォータを取得（未設定の場合は nil）
+func (r *WorkspaceRepository) GetStorageQuota(workspaceID int) (*int64, error) {
+	query, err := r.queries.Get("GetWorkspaceStorageQuota")
+	if err != nil {
+		return nil, err
+	}
+
+	var quota sql.NullInt64
+	if err := r.db.QueryRow(query, workspaceID).Scan(&quota); err != nil {
+		return nil, apierror.WrapNotFound(err, fmt.Sprintf("workspace id=%d", workspaceID))
+	}
+	if !quota.Valid {
+		return nil, nil
+	}
+	return &quota.Int64, nil
+}
+
+// SetStorageQuota - ワークスペースのストレージクォータを設定（nil の場合は個別の設定を解除する）
+func (r *WorkspaceRepository) SetStorageQuota(workspaceID int, quota *int64) error {
+	query, err := r.queries.Get("SetWorkspaceStorageQuota")
+	if err != nil {
+		return err
+	}
+	return r.execWorkspace(query, fmt.Sprintf("workspace id=%d", workspaceID), quota, workspaceID)
+}
+
+// GetStorageUsedBytes - 現在のメンバー全員の使用中のファイルの合計サイズを取得
+func (r *WorkspaceRepository) GetStorageUsedBytes(workspaceID int) (int64, error) {
+	query, err := r.queries.Get("GetWorkspaceStorageUsedBytes")
+	if err != nil {
+		return 0, err
+	}
+
+	var used int64
+	if err := r.db.QueryRow(query, workspaceID).Scan(&used); err != nil {
+		return 0, fmt.Errorf("failed to get workspace storage usage: %w", err)
+	}
+	return used, nil
+}
+
+// ListMemberStorageUsage - メンバーごとのファイルの使用量を使用量の多い順に取得
+func (r *WorkspaceRepository) ListMemberStorageUsage(workspaceID int) ([]models.WorkspaceMemberStorage, error) {
+	query, err := r.queries.Get("ListWorkspaceMemberStorageUsage")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace member storage usage: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]models.WorkspaceMemberStorage, 0)
+	for rows.Next() {
+		var member models.WorkspaceMemberStorage
+		if err := rows.Scan(&member.UserID, &member.Email, &member.Name, &member.Role,
+			&member.FileCount, &member.UsedBytes); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// execWorkspace - 更新系クエリを実行し、対象がない場合は ErrNotFound を返す
+func (r *WorkspaceRepository) execWorkspace(query, target string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", target, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", target, apierror.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package requestid は リクエストごとの ID（X-Request-ID）をコンテキストで受け渡すヘルパーを提供します
+// ログ・エラーレスポンスに同じ ID を含めることで、利用者からの問い合わせとサーバーのログを突き合わせられる
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header は リクエスト ID を受け渡す HTTP ヘッダーです
+const Header = "X-Request-ID"
+
+// maxLength は クライアントから受け取るリクエスト ID の最大長です
+const maxLength = 128
+
+type contextKey struct{}
+
+// New は 新しいリクエスト ID を生成します
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid は クライアントから受け取ったリクエスト ID をそのまま使えるかを返します
+// ログを崩さないよう、英数字と「-_.:」以外の文字を含む ID は使わない
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext は リクエスト ID を設定したコンテキストを返します
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext は コンテキストのリクエスト ID を返します（設定されていない場合は空文字列）
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestValid - クライアントから受け取るリクエスト ID の検証のテスト
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "正常系：UUID", id: "3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b", want: true},
+		{name: "正常系：記号を含む", id: "lb-01:req_42.7", want: true},
+		{name: "異常系：空文字列", id: "", want: false},
+		{name: "異常系：長すぎる", id: strings.Repeat("a", maxLength+1), want: false},
+		{name: "異常系：空白を含む", id: "abc def", want: false},
+		{name: "異常系：改行を含む", id: "abc\nlevel=ERROR", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.id); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestContext - コンテキストでの受け渡しのテスト
+func TestContext(t *testing.T) {
+	t.Run("正常系：設定した ID を取り出せる", func(t *testing.T) {
+		ctx := NewContext(context.Background(), "req-1")
+		if got := FromContext(ctx); got != "req-1" {
+			t.Errorf("FromContext() = %q, want %q", got, "req-1")
+		}
+	})
+
+	t.Run("正常系：設定していない場合は空文字列", func(t *testing.T) {
+		if got := FromContext(context.Background()); got != "" {
+			t.Errorf("FromContext() = %q, want empty", got)
+		}
+	})
+
+	t.Run("正常系：生成した ID はそのまま使える", func(t *testing.T) {
+		if id := New(); !Valid(id) {
+			t.Errorf("New() = %q, want valid", id)
+		}
+	})
+}
@@ -0,0 +1,371 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/textproto"
+
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
+
+// proto/simplenotion/v1/api.proto のメッセージの読み書き
+// リクエストは読み込みのみ、レスポンスは書き出しのみ（モデルから直接書き出す）
+
+// idRequest は GetDocumentRequest のように id（1番）のみを持つリクエストです
+type idRequest struct {
+	ID int
+}
+
+func (m *idRequest) unmarshal(b []byte) error {
+	return decodeFields(b, func(f field) error {
+		if f.num == 1 {
+			if err := expectWireType(f, wireVarint); err != nil {
+				return err
+			}
+			m.ID = f.int()
+		}
+		return nil
+	})
+}
+
+// searchDocumentsRequest は SearchDocumentsRequest です
+type searchDocumentsRequest struct {
+	Query  string
+	Limit  int
+	Cursor string
+}
+
+func (m *searchDocumentsRequest) unmarshal(b []byte) error {
+	return decodeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			if err := expectWireType(f, wireBytes); err != nil {
+				return err
+			}
+			m.Query = string(f.bytes)
+		case 2:
+			if err := expectWireType(f, wireVarint); err != nil {
+				return err
+			}
+			m.Limit = f.int()
+		case 3:
+			if err := expectWireType(f, wireBytes); err != nil {
+				return err
+			}
+			m.Cursor = string(f.bytes)
+		}
+		return nil
+	})
+}
+
+// createDocumentRequest は CreateDocumentRequest です
+type createDocumentRequest struct {
+	Title    string
+	Content  string
+	ParentID *int
+}
+
+func (m *createDocumentRequest) unmarshal(b []byte) error {
+	return decodeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			if err := expectWireType(f, wireBytes); err != nil {
+				return err
+			}
+			m.Title = string(f.bytes)
+		case 2:
+			if err := expectWireType(f, wireBytes); err != nil {
+				return err
+			}
+			m.Content = string(f.bytes)
+		case 3:
+			if err := expectWireType(f, wireVarint); err != nil {
+				return err
+			}
+			parentID := f.int()
+			m.ParentID = &parentID
+		}
+		return nil
+	})
+}
+
+// updateDocumentRequest は UpdateDocumentRequest です（blocks は文書のブロックの一覧全体を置き換える）
+type updateDocumentRequest struct {
+	ID      int
+	Title   string
+	Content string
+	Blocks  []models.Block
+}
+
+func (m *updateDocumentRequest) unmarshal(b []byte) error {
+	m.Blocks = []models.Block{}
+	return decodeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			if err := expectWireType(f, wireVarint); err != nil {
+				return err
+			}
+			m.ID = f.int()
+		case 2:
+			if err := expectWireType(f, wireBytes); err != nil {
+				return err
+			}
+			m.Title = string(f.bytes)
+		case 3:
+			if err := expectWireType(f, wireBytes); err != nil {
+				return err
+			}
+			m.Content = string(f.bytes)
+		case 4:
+			if err := expectWireType(f, wireBytes); err != nil {
+				return err
+			}
+			block, err := unmarshalBlock(f.bytes)
+			if err != nil {
+				return err
+			}
+			m.Blocks = append(m.Blocks, block)
+		}
+		return nil
+	})
+}
+
+// unmarshalBlock は Block を読み込みます（created_at は保存時に設定されるため読み込まない）
+func unmarshalBlock(b []byte) (models.Block, error) {
+	var block models.Block
+	err := decodeFields(b, func(f field) error {
+		wireType := wireVarint
+		if f.num == 3 || f.num == 4 {
+			wireType = wireBytes
+		}
+		if f.num >= 1 && f.num <= 5 {
+			if err := expectWireType(f, wireType); err != nil {
+				return err
+			}
+		}
+
+		switch f.num {
+		case 1:
+			block.ID = f.int()
+		case 2:
+			parentBlockID := f.int()
+			block.ParentBlockID = &parentBlockID
+		case 3:
+			block.Type = string(f.bytes)
+		case 4:
+			block.Content = json.RawMessage(f.bytes)
+		case 5:
+			block.Position = f.int()
+		}
+		return nil
+	})
+	return block, err
+}
+
+// uploadFileRequest は UploadFileRequest です
+type uploadFileRequest struct {
+	OriginalName string
+	MimeType     string
+	Data         []byte
+}
+
+func (m *uploadFileRequest) unmarshal(b []byte) error {
+	return decodeFields(b, func(f field) error {
+		if f.num >= 1 && f.num <= 3 {
+			if err := expectWireType(f, wireBytes); err != nil {
+				return err
+			}
+		}
+
+		switch f.num {
+		case 1:
+			m.OriginalName = string(f.bytes)
+		case 2:
+			m.MimeType = string(f.bytes)
+		case 3:
+			m.Data = f.bytes
+		}
+		return nil
+	})
+}
+
+// file は アップロードするファイルを、REST のフォームから受け取ったファイルと同じ形で返します
+func (m *uploadFileRequest) file() (multipart.File, *multipart.FileHeader) {
+	header := &multipart.FileHeader{
+		Filename: m.OriginalName,
+		Header:   textproto.MIMEHeader{"Content-Type": {m.MimeType}},
+		Size:     int64(len(m.Data)),
+	}
+	return memoryFile{bytes.NewReader(m.Data)}, header
+}
+
+// memoryFile は メモリ上のバイト列を multipart.File として読み込みます
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
+
+// getFileURLRequest は GetFileURLRequest です
+type getFileURLRequest struct {
+	ID   int
+	Size string
+}
+
+func (m *getFileURLRequest) unmarshal(b []byte) error {
+	return decodeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			if err := expectWireType(f, wireVarint); err != nil {
+				return err
+			}
+			m.ID = f.int()
+		case 2:
+			if err := expectWireType(f, wireBytes); err != nil {
+				return err
+			}
+			m.Size = string(f.bytes)
+		}
+		return nil
+	})
+}
+
+// unmarshalListFilesRequest は ListFilesRequest をファイル一覧の条件として読み込みます
+// cursor はそのまま返し、呼び出し側で pagination.Decode する
+func unmarshalListFilesRequest(b []byte) (models.FileListOptions, string, error) {
+	var opts models.FileListOptions
+	var cursor string
+	err := decodeFields(b, func(f field) error {
+		wireType := wireBytes
+		if f.num == 1 || f.num == 6 {
+			wireType = wireVarint
+		}
+		if (f.num >= 1 && f.num <= 6) || f.num == 8 {
+			if err := expectWireType(f, wireType); err != nil {
+				return err
+			}
+		}
+
+		switch f.num {
+		case 1:
+			documentID := f.int()
+			opts.DocumentID = &documentID
+		case 2:
+			opts.FileType = string(f.bytes)
+		case 3:
+			opts.MimeType = string(f.bytes)
+		case 4:
+			opts.Sort = string(f.bytes)
+		case 5:
+			opts.Order = string(f.bytes)
+		case 6:
+			opts.Limit = f.int()
+		case 8:
+			cursor = string(f.bytes)
+		}
+		return nil
+	})
+	return opts, cursor, err
+}
+
+// marshalDocument は Document を書き出します
+func marshalDocument(doc *models.DocumentWithBlocks) []byte {
+	e := &encoder{}
+	e.int64(1, int64(doc.ID))
+	e.int64(2, int64(doc.UserID))
+	e.optionalInt64(3, doc.ParentID)
+	e.optionalInt64(4, doc.WorkspaceID)
+	e.string(5, doc.Title)
+	e.string(6, doc.Content)
+	e.string(7, string(doc.Role))
+	e.timestamp(8, doc.CreatedAt)
+	e.timestamp(9, doc.UpdatedAt)
+	for _, block := range doc.Blocks {
+		e.message(10, func(m *encoder) {
+			m.int64(1, int64(block.ID))
+			m.optionalInt64(2, block.ParentBlockID)
+			m.string(3, block.Type)
+			m.string(4, string(block.Content))
+			m.int64(5, int64(block.Position))
+			m.timestamp(6, block.CreatedAt)
+		})
+	}
+	return e.buf
+}
+
+// marshalDocumentTree は ListDocumentTreeResponse を書き出します
+func marshalDocumentTree(tree []models.DocumentTreeNode) []byte {
+	e := &encoder{}
+	encodeTreeNodes(e, 1, tree)
+	return e.buf
+}
+
+// encodeTreeNodes は TreeNode を num 番のフィールドとして再帰的に書き出します
+func encodeTreeNodes(e *encoder, num int, nodes []models.DocumentTreeNode) {
+	for _, node := range nodes {
+		e.message(num, func(m *encoder) {
+			m.int64(1, int64(node.ID))
+			m.optionalInt64(2, node.ParentID)
+			m.string(3, node.Title)
+			encodeTreeNodes(m, 4, node.Children)
+		})
+	}
+}
+
+// marshalSearchResults は SearchDocumentsResponse を書き出します
+func marshalSearchResults(results pagination.Page[models.DocumentSearchResult]) []byte {
+	e := &encoder{}
+	for _, result := range results.Items {
+		e.message(1, func(m *encoder) {
+			m.int64(1, int64(result.ID))
+			m.optionalInt64(2, result.ParentID)
+			m.string(3, result.Title)
+			m.string(4, result.Snippet)
+			m.double(5, result.Rank)
+			m.timestamp(6, result.UpdatedAt)
+		})
+	}
+	e.optionalString(2, results.NextCursor)
+	return e.buf
+}
+
+// marshalFileList は ListFilesResponse を書き出します
+func marshalFileList(list *models.FileList) []byte {
+	e := &encoder{}
+	for _, file := range list.Items {
+		e.message(1, func(m *encoder) {
+			encodeFile(m, file)
+		})
+	}
+	e.int64(2, int64(list.Total))
+	e.optionalString(3, list.NextCursor)
+	return e.buf
+}
+
+// marshalFile は File を書き出します
+func marshalFile(file *models.FileMetadata) []byte {
+	e := &encoder{}
+	encodeFile(e, file)
+	return e.buf
+}
+
+// encodeFile は File のフィールドを書き出します
+func encodeFile(e *encoder, file *models.FileMetadata) {
+	e.int64(1, int64(file.ID))
+	e.optionalInt64(2, file.DocumentID)
+	e.optionalInt64(3, file.BlockID)
+	e.string(4, file.OriginalName)
+	e.int64(5, file.FileSize)
+	e.string(6, file.MimeType)
+	e.string(7, file.FileType)
+	e.timestamp(8, file.UploadedAt)
+}
+
+// marshalFileURL は GetFileURLResponse を書き出します
+func marshalFileURL(url string) []byte {
+	e := &encoder{}
+	e.string(1, url)
+	return e.buf
+}
@@ -0,0 +1,290 @@
+// Package rpc は 文書・ファイルの gRPC API を提供します
+//
+// 標準ライブラリのみで gRPC の単項呼び出し（HTTP/2 上の長さ付きメッセージと、トレーラーのステータス）を実装し、
+// メッセージは proto/simplenotion/v1/api.proto の定義に従って protobuf のワイヤー形式で読み書きする。
+// ストリーミング・メッセージの圧縮・gRPC-Web・リフレクションには対応していない。
+package rpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+// Code は gRPC のステータスコードです
+type Code int
+
+// 使用する gRPC のステータスコード（https://grpc.github.io/grpc/core/md_doc_statuscodes.html）
+const (
+	CodeOK                Code = 0
+	CodeInvalidArgument   Code = 3
+	CodeDeadlineExceeded  Code = 4
+	CodeNotFound          Code = 5
+	CodeAlreadyExists     Code = 6
+	CodePermissionDenied  Code = 7
+	CodeResourceExhausted Code = 8
+	CodeUnimplemented     Code = 12
+	CodeInternal          Code = 13
+	CodeUnavailable       Code = 14
+	CodeUnauthenticated   Code = 16
+)
+
+// maxMessageSize は リクエストのメッセージの最大サイズ（バイト、gRPC の既定値と同じ）です
+const maxMessageSize = 4 << 20
+
+// handlerFunc は 1つのメソッドの処理です（リクエスト・レスポンスは protobuf のワイヤー形式のバイト列）
+type handlerFunc func(ctx context.Context, req []byte) ([]byte, error)
+
+// Server は gRPC のリクエストを処理する http.Handler です（HTTP/2 で待ち受ける http.Server に設定する）
+type Server struct {
+	methods    map[string]handlerFunc // "/simplenotion.v1.DocumentService/GetDocument" → 処理
+	jwtSecret  []byte
+	workspaces WorkspaceChecker // nil の場合はワークスペースのメンバーか・文書数の上限を確認しない
+}
+
+// WorkspaceChecker は ワークスペースのメンバーの確認と、文書数の上限の確認です
+type WorkspaceChecker interface {
+	middleware.WorkspaceChecker
+	CheckDocumentLimit(workspaceID int) error
+}
+
+// DocumentHooks は 文書の変更に REST API と同じ検証と保存後の処理を行います（document.DocumentHandler が実装する）
+type DocumentHooks interface {
+	CheckDocument(ctx context.Context, title, content string, blocks []models.Block) error
+	DocumentCreated(ctx context.Context, userID int, doc *models.Document)
+	DocumentUpdated(ctx context.Context, docID, userID int, previousTitle string) (*models.DocumentWithBlocks, error)
+	DocumentDeleted(ctx context.Context, docID, userID int)
+}
+
+// NewServer は 新しい Server インスタンスを作成します
+// userStorageQuota: ユーザーごとのストレージ容量の既定の上限（REST のアップロードと同じ値）
+func NewServer(
+	documentService *services.DocumentService,
+	hooks DocumentHooks,
+	fileService *services.FileService,
+	userStorageQuota int64,
+	jwtSecret []byte,
+	workspaces WorkspaceChecker,
+) *Server {
+	s := &Server{
+		methods:    make(map[string]handlerFunc),
+		jwtSecret:  jwtSecret,
+		workspaces: workspaces,
+	}
+	registerDocumentService(s, documentService, hooks)
+	registerFileService(s, fileService, userStorageQuota)
+	return s
+}
+
+// handle は メソッドの処理を登録します
+func (s *Server) handle(service, method string, fn handlerFunc) {
+	s.methods["/"+service+"/"+method] = fn
+}
+
+// ServeHTTP は gRPC の単項呼び出しを1つ処理します
+// メタデータ authorization: Bearer <JWT> でログイン時と同じトークンを受け取り、REST API と同じ権限で処理する
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, r, apierror.NewMethodNotAllowed("GRPC_METHOD_NOT_ALLOWED", "gRPC は POST で呼び出してください", nil))
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/grpc" && !strings.HasPrefix(contentType, "application/grpc+proto") &&
+		!strings.HasPrefix(contentType, "application/grpc;") {
+		apierror.Write(w, r, apierror.NewUnsupportedMediaType("GRPC_UNSUPPORTED_CONTENT_TYPE", "Content-Type は application/grpc を指定してください", nil))
+		return
+	}
+
+	fn, ok := s.methods[r.URL.Path]
+	if !ok {
+		writeStatus(w, CodeUnimplemented, "unknown method "+r.URL.Path)
+		return
+	}
+
+	ctx, err := s.authenticate(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if timeout, ok := parseTimeout(r.Header.Get("Grpc-Timeout")); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := readMessage(r.Body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	res, err := fn(ctx, req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			writeStatus(w, CodeDeadlineExceeded, "deadline exceeded")
+			return
+		}
+		writeError(w, r, err)
+		return
+	}
+
+	frame := make([]byte, 5, 5+len(res))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(res)))
+	frame = append(frame, res...)
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(frame); err != nil {
+		log.Printf("Failed to write gRPC response for %s: %v", r.URL.Path, err)
+		return
+	}
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(int(CodeOK)))
+}
+
+// authenticate は メタデータのトークンを検証し、ユーザーと選択中のワークスペースを設定したコンテキストを返します
+func (s *Server) authenticate(r *http.Request) (context.Context, error) {
+	authorization := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if authorization == "" || token == authorization {
+		return nil, apierror.NewUnauthorized(
+			"NO_AUTH_TOKEN", "メタデータ authorization に Bearer トークンを指定してください", nil,
+		)
+	}
+
+	ctx, err := middleware.Authenticate(r.Context(), s.jwtSecret, token)
+	if err != nil {
+		return nil, err
+	}
+	if s.workspaces != nil {
+		return middleware.CheckWorkspaceMember(ctx, s.workspaces)
+	}
+	return ctx, nil
+}
+
+// readMessage は リクエストの本文から長さ付きのメッセージを1つ読み込みます
+func readMessage(body io.Reader) ([]byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(body, header); err != nil {
+		return nil, apierror.NewValidationError("INVALID_MESSAGE", "リクエストのメッセージがありません", err)
+	}
+	if header[0] != 0 {
+		return nil, &statusError{code: CodeUnimplemented, message: "message compression is not supported"}
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > maxMessageSize {
+		return nil, &statusError{
+			code:    CodeResourceExhausted,
+			message: fmt.Sprintf("message larger than max (%d vs. %d)", length, maxMessageSize),
+		}
+	}
+
+	message := make([]byte, length)
+	if _, err := io.ReadFull(body, message); err != nil {
+		return nil, apierror.NewValidationError("INVALID_MESSAGE", "リクエストのメッセージが途中で終わっています", err)
+	}
+	return message, nil
+}
+
+// statusError は apierror を経由せずに gRPC のステータスを返すエラーです
+type statusError struct {
+	code    Code
+	message string
+}
+
+func (e *statusError) Error() string { return e.message }
+
+// writeError は エラーを gRPC のステータスとして返します
+// REST と同じく、5xx 相当のエラーの詳細はクライアントに返さずサーバーログに記録する
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var status *statusError
+	if errors.As(err, &status) {
+		writeStatus(w, status.code, status.message)
+		return
+	}
+
+	appErr := apierror.From(err)
+	log.Printf("[%s] gRPC %s -> %d: %v", appErr.Code, r.URL.Path, appErr.HTTPStatus, appErr.Err)
+	writeStatus(w, codeFromHTTPStatus(appErr.HTTPStatus), appErr.Message)
+}
+
+// writeStatus は 本文のないレスポンス（Trailers-Only）で gRPC のステータスを返します
+func writeStatus(w http.ResponseWriter, code Code, message string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.Itoa(int(code)))
+	if message != "" {
+		w.Header().Set("Grpc-Message", encodeGRPCMessage(message))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// codeFromHTTPStatus は apierror の HTTP ステータスに対応する gRPC のステータスコードを返します
+func codeFromHTTPStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusRequestedRangeNotSatisfiable:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeAlreadyExists
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return CodeResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	return CodeInternal
+}
+
+// encodeGRPCMessage は grpc-message の値をパーセントエンコードします（日本語のメッセージを HTTP/2 のヘッダーで送るため）
+func encodeGRPCMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c < 0x20 || c > 0x7e || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// parseTimeout は grpc-timeout（"100m" のように8桁以内の数値と単位）を解析します
+func parseTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,416 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/services"
+)
+
+var testSecret = []byte("test-secret")
+
+func testToken(t *testing.T, secret []byte) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 7,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString(secret)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func frame(message []byte) []byte {
+	b := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(b[1:], uint32(len(message)))
+	return append(b, message...)
+}
+
+func testServer() *Server {
+	s := &Server{methods: make(map[string]handlerFunc), jwtSecret: testSecret}
+	s.handle(documentServiceName, "GetDocument", func(ctx context.Context, b []byte) ([]byte, error) {
+		var req idRequest
+		if err := req.unmarshal(b); err != nil {
+			return nil, invalidMessage(err)
+		}
+		if req.ID != 1 {
+			return nil, apierror.NewNotFound("DOCUMENT_NOT_FOUND", "ドキュメントが見つかりません", nil)
+		}
+		title := fmt.Sprintf("ユーザー%d", middleware.GetUserIDFromContext(ctx))
+		return marshalDocument(&models.DocumentWithBlocks{
+			Document: models.Document{ID: req.ID, Title: title},
+		}), nil
+	})
+	return s
+}
+
+func TestServeHTTP(t *testing.T) {
+	validToken := testToken(t, testSecret)
+	getDocument := "/" + documentServiceName + "/GetDocument"
+	idMessage := func(id int64) []byte {
+		e := &encoder{}
+		e.int64(1, id)
+		return e.buf
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		token       string
+		body        []byte
+		wantStatus  string
+		wantMessage string
+		wantBody    bool
+	}{
+		{
+			name:       "正常系：メッセージを返し、トレーラーで OK を返す",
+			path:       getDocument,
+			token:      validToken,
+			body:       frame(idMessage(1)),
+			wantStatus: "",
+			wantBody:   true,
+		},
+		{
+			name:        "異常系：トークンがない場合は UNAUTHENTICATED",
+			path:        getDocument,
+			body:        frame(idMessage(1)),
+			wantStatus:  "16",
+			wantMessage: encodeGRPCMessage("メタデータ authorization に Bearer トークンを指定してください"),
+		},
+		{
+			name:       "異常系：署名が異なるトークンは UNAUTHENTICATED",
+			path:       getDocument,
+			token:      testToken(t, []byte("other-secret")),
+			body:       frame(idMessage(1)),
+			wantStatus: "16",
+		},
+		{
+			name:       "異常系：存在しないメソッドは UNIMPLEMENTED",
+			path:       "/" + documentServiceName + "/ArchiveDocument",
+			token:      validToken,
+			body:       frame(nil),
+			wantStatus: "12",
+		},
+		{
+			name:        "異常系：見つからない文書は NOT_FOUND",
+			path:        getDocument,
+			token:       validToken,
+			body:        frame(idMessage(2)),
+			wantStatus:  "5",
+			wantMessage: encodeGRPCMessage("ドキュメントが見つかりません"),
+		},
+		{
+			name:       "異常系：読めないメッセージは INVALID_ARGUMENT",
+			path:       getDocument,
+			token:      validToken,
+			body:       frame([]byte{0x08}),
+			wantStatus: "3",
+		},
+		{
+			name:       "異常系：圧縮されたメッセージは UNIMPLEMENTED",
+			path:       getDocument,
+			token:      validToken,
+			body:       append([]byte{1}, frame(idMessage(1))[1:]...),
+			wantStatus: "12",
+		},
+		{
+			name:       "異常系：上限を超えるメッセージは RESOURCE_EXHAUSTED",
+			path:       getDocument,
+			token:      validToken,
+			body:       []byte{0, 0xff, 0xff, 0xff, 0xff},
+			wantStatus: "8",
+		},
+	}
+
+	s := testServer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/grpc")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("HTTP status = %d, want 200", rec.Code)
+			}
+			if got := rec.Header().Get("Grpc-Status"); got != tt.wantStatus {
+				t.Errorf("grpc-status = %q, want %q", got, tt.wantStatus)
+			}
+			if tt.wantMessage != "" && rec.Header().Get("Grpc-Message") != tt.wantMessage {
+				t.Errorf("grpc-message = %q, want %q", rec.Header().Get("Grpc-Message"), tt.wantMessage)
+			}
+			if !tt.wantBody {
+				if rec.Body.Len() != 0 {
+					t.Errorf("body length = %d, want 0", rec.Body.Len())
+				}
+				return
+			}
+
+			if got := rec.Result().Trailer.Get("Grpc-Status"); got != "0" {
+				t.Errorf("trailer grpc-status = %q, want 0", got)
+			}
+			body := rec.Body.Bytes()
+			if len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+				t.Fatalf("invalid response frame: %v", body)
+			}
+			var title string
+			err := decodeFields(body[5:], func(f field) error {
+				if f.num == 5 {
+					title = string(f.bytes)
+				}
+				return nil
+			})
+			if err != nil || title != "ユーザー7" {
+				t.Errorf("title = %q (err %v), want ユーザー7", title, err)
+			}
+		})
+	}
+}
+
+func TestDecodeFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		want    searchDocumentsRequest
+		wantErr bool
+	}{
+		{
+			name:  "正常系：文字列と数値のフィールドを読み込む",
+			input: []byte{0x0a, 0x03, 'a', 'b', 'c', 0x10, 0x05},
+			want:  searchDocumentsRequest{Query: "abc", Limit: 5},
+		},
+		{
+			name:  "正常系：知らないフィールドは読み飛ばす",
+			input: []byte{0x25, 1, 2, 3, 4, 0x29, 1, 2, 3, 4, 5, 6, 7, 8, 0x10, 0x02},
+			want:  searchDocumentsRequest{Limit: 2},
+		},
+		{
+			name:  "正常系：負の値を読み込む",
+			input: append([]byte{0x10}, binary.AppendUvarint(nil, uint64(0xffffffffffffffff))...),
+			want:  searchDocumentsRequest{Limit: -1},
+		},
+		{
+			name:    "異常系：長さがメッセージを超える",
+			input:   []byte{0x0a, 0x10, 'a'},
+			wantErr: true,
+		},
+		{
+			name:    "異常系：宣言と異なるワイヤー形式",
+			input:   []byte{0x08, 0x01},
+			wantErr: true,
+		},
+		{
+			name:    "異常系：フィールド番号 0",
+			input:   []byte{0x00, 0x01},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got searchDocumentsRequest
+			err := got.unmarshal(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMessage) {
+					t.Fatalf("err = %v, want ErrInvalidMessage", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateDocumentRequest(t *testing.T) {
+	parentBlockID := 10
+	e := &encoder{}
+	e.int64(1, 3)
+	e.string(2, "タイトル")
+	e.string(3, "本文")
+	e.message(4, func(m *encoder) {
+		m.int64(1, 10)
+		m.string(3, "text")
+		m.string(4, `{"text":"a"}`)
+	})
+	e.message(4, func(m *encoder) {
+		m.int64(1, 11)
+		m.optionalInt64(2, &parentBlockID)
+		m.string(3, "text")
+		m.string(4, `{"text":"b"}`)
+		m.int64(5, 1)
+	})
+
+	var got updateDocumentRequest
+	if err := got.unmarshal(e.buf); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.ID != 3 || got.Title != "タイトル" || got.Content != "本文" {
+		t.Errorf("got %+v", got)
+	}
+	if len(got.Blocks) != 2 {
+		t.Fatalf("len(Blocks) = %d, want 2", len(got.Blocks))
+	}
+	if got.Blocks[0].ParentBlockID != nil || string(got.Blocks[0].Content) != `{"text":"a"}` {
+		t.Errorf("Blocks[0] = %+v", got.Blocks[0])
+	}
+	if got.Blocks[1].ParentBlockID == nil || *got.Blocks[1].ParentBlockID != 10 || got.Blocks[1].Position != 1 {
+		t.Errorf("Blocks[1] = %+v", got.Blocks[1])
+	}
+
+	// ブロックの種類を数値で送った場合はメッセージの形式が不正
+	invalid := &encoder{}
+	invalid.message(4, func(m *encoder) {
+		m.int64(3, 1)
+	})
+	if err := got.unmarshal(invalid.buf); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("err = %v, want ErrInvalidMessage", err)
+	}
+}
+
+func TestUploadFileRequest(t *testing.T) {
+	e := &encoder{}
+	e.string(1, "memo.txt")
+	e.string(2, "text/plain")
+	e.bytes(3, []byte("hello"))
+
+	var req uploadFileRequest
+	if err := req.unmarshal(e.buf); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	file, header := req.file()
+	if header.Filename != "memo.txt" || header.Size != 5 || header.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("header = %+v", header)
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("content = %q, want %q", content, "hello")
+	}
+}
+
+func TestWriteMethods_Validation(t *testing.T) {
+	s := &Server{methods: make(map[string]handlerFunc)}
+	registerDocumentService(s, nil, nil)
+	registerFileService(s, nil, 0)
+
+	createDocument := &encoder{}
+	createDocument.string(2, "本文")
+	updateDocument := &encoder{}
+	updateDocument.int64(1, 1)
+	updateDocument.message(4, func(m *encoder) {
+		m.string(3, "text")
+		m.string(4, "{")
+	})
+	uploadFile := &encoder{}
+	uploadFile.string(1, "empty.txt")
+
+	tests := []struct {
+		name     string
+		method   string
+		message  []byte
+		wantCode string
+	}{
+		{
+			name:     "異常系：タイトルのない文書は作成できない",
+			method:   "/" + documentServiceName + "/CreateDocument",
+			message:  createDocument.buf,
+			wantCode: "TITLE_REQUIRED",
+		},
+		{
+			name:     "異常系：ブロックの内容が JSON でない",
+			method:   "/" + documentServiceName + "/UpdateDocument",
+			message:  updateDocument.buf,
+			wantCode: "INVALID_BLOCK_CONTENT",
+		},
+		{
+			name:     "異常系：空のファイルはアップロードできない",
+			method:   "/" + fileServiceName + "/UploadFile",
+			message:  uploadFile.buf,
+			wantCode: "NO_FILE_UPLOADED",
+		},
+		{
+			name:     "異常系：読み込めないメッセージ",
+			method:   "/" + fileServiceName + "/DeleteFile",
+			message:  []byte{0x0a, 0x01, 'a'},
+			wantCode: "INVALID_MESSAGE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), middleware.UserIDKey, 7)
+			_, err := s.methods[tt.method](ctx, tt.message)
+			if code := apierror.From(err).Code; code != tt.wantCode {
+				t.Errorf("code = %q, want %q (err = %v)", code, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestUploadError(t *testing.T) {
+	tests := []struct {
+		err      error
+		wantCode string
+	}{
+		{err: fmt.Errorf("upload: %w", services.ErrStorageQuotaExceeded), wantCode: "QUOTA_EXCEEDED"},
+		{err: fmt.Errorf("upload: %w", services.ErrWorkspaceStorageQuotaExceeded), wantCode: "WORKSPACE_QUOTA_EXCEEDED"},
+		{err: fmt.Errorf("upload: %w", services.ErrFileTooLarge), wantCode: "FILE_TOO_LARGE"},
+		{err: fmt.Errorf("upload: %w", services.ErrUnsupportedFileType), wantCode: "UNSUPPORTED_FILE_TYPE"},
+		{err: fmt.Errorf("upload: %w", services.ErrContentTypeMismatch), wantCode: "CONTENT_TYPE_MISMATCH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			if code := apierror.From(uploadError(tt.err)).Code; code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "100m", want: 100 * time.Millisecond, wantOK: true},
+		{value: "5S", want: 5 * time.Second, wantOK: true},
+		{value: "1H", want: time.Hour, wantOK: true},
+		{value: "", wantOK: false},
+		{value: "10x", wantOK: false},
+		{value: "123456789S", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseTimeout(tt.value)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseTimeout(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/middleware"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+	"simple-notion-backend/internal/services"
+)
+
+const (
+	documentServiceName = "simplenotion.v1.DocumentService"
+	fileServiceName     = "simplenotion.v1.FileService"
+)
+
+// invalidMessage は 読み込めないリクエストのメッセージのエラーです
+func invalidMessage(err error) error {
+	return apierror.NewValidationError("INVALID_MESSAGE", "リクエストのメッセージの形式が不正です", err)
+}
+
+// registerDocumentService は DocumentService のメソッドを登録します
+// 文書の変更は、REST API と同じくコンテンツポリシーの検証・版の記録・変更の通知を hooks で行う
+func registerDocumentService(s *Server, documentService *services.DocumentService, hooks DocumentHooks) {
+	s.handle(documentServiceName, "GetDocument", func(ctx context.Context, b []byte) ([]byte, error) {
+		var req idRequest
+		if err := req.unmarshal(b); err != nil {
+			return nil, invalidMessage(err)
+		}
+		doc, err := documentService.GetDocumentWithBlocks(req.ID, middleware.GetUserIDFromContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return marshalDocument(doc), nil
+	})
+
+	s.handle(documentServiceName, "ListDocumentTree", func(ctx context.Context, b []byte) ([]byte, error) {
+		tree, err := documentService.GetDocumentTree(
+			middleware.GetUserIDFromContext(ctx), middleware.GetWorkspaceIDFromContext(ctx),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return marshalDocumentTree(tree), nil
+	})
+
+	s.handle(documentServiceName, "SearchDocuments", func(ctx context.Context, b []byte) ([]byte, error) {
+		var req searchDocumentsRequest
+		if err := req.unmarshal(b); err != nil {
+			return nil, invalidMessage(err)
+		}
+		if req.Limit < 0 {
+			return nil, apierror.NewValidationError(
+				"INVALID_PAGINATION", "limit には 1 以上の整数を指定してください", nil,
+			)
+		}
+		page := pagination.Params{Limit: req.Limit}
+		if req.Cursor != "" {
+			after, err := pagination.Decode(req.Cursor)
+			if err != nil {
+				return nil, err
+			}
+			page.After = after
+		}
+		results, err := documentService.SearchDocuments(middleware.GetUserIDFromContext(ctx), req.Query, page)
+		if err != nil {
+			return nil, err
+		}
+		return marshalSearchResults(results), nil
+	})
+
+	s.handle(documentServiceName, "CreateDocument", func(ctx context.Context, b []byte) ([]byte, error) {
+		var req createDocumentRequest
+		if err := req.unmarshal(b); err != nil {
+			return nil, invalidMessage(err)
+		}
+		if req.Title == "" {
+			return nil, apierror.NewValidationError("TITLE_REQUIRED", "タイトルを入力してください", nil)
+		}
+
+		userID := middleware.GetUserIDFromContext(ctx)
+		doc := &models.Document{
+			UserID:   userID,
+			ParentID: req.ParentID,
+			Title:    req.Title,
+			Content:  req.Content,
+		}
+
+		// 子文書は親のワークスペースに、最上位の文書は選択中のワークスペースに作成される
+		doc.WorkspaceID = middleware.GetWorkspaceIDFromContext(ctx)
+		if req.ParentID != nil {
+			parent, err := documentService.GetDocument(*req.ParentID, userID)
+			if err != nil {
+				return nil, err
+			}
+			doc.WorkspaceID = parent.WorkspaceID
+		}
+		if doc.WorkspaceID != nil && s.workspaces != nil {
+			if err := s.workspaces.CheckDocumentLimit(*doc.WorkspaceID); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := documentService.CreateDocument(doc); err != nil {
+			return nil, err
+		}
+		hooks.DocumentCreated(ctx, userID, doc)
+		return marshalDocument(&models.DocumentWithBlocks{Document: *doc, Blocks: []models.Block{}}), nil
+	})
+
+	s.handle(documentServiceName, "UpdateDocument", func(ctx context.Context, b []byte) ([]byte, error) {
+		var req updateDocumentRequest
+		if err := req.unmarshal(b); err != nil {
+			return nil, invalidMessage(err)
+		}
+		for _, block := range req.Blocks {
+			if !json.Valid(block.Content) {
+				return nil, apierror.NewValidationError("INVALID_BLOCK_CONTENT", "ブロックの内容の JSON が不正です", nil)
+			}
+		}
+		if err := hooks.CheckDocument(ctx, req.Title, req.Content, req.Blocks); err != nil {
+			return nil, err
+		}
+
+		// 名前の変更をサイドバーに通知するため、更新前のタイトルを取得しておく
+		userID := middleware.GetUserIDFromContext(ctx)
+		previous, err := documentService.GetDocument(req.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := documentService.UpdateDocumentWithBlocks(ctx, req.ID, userID, req.Title, req.Content, req.Blocks); err != nil {
+			return nil, err
+		}
+
+		doc, err := hooks.DocumentUpdated(ctx, req.ID, userID, previous.Title)
+		if err != nil {
+			return nil, err
+		}
+		return marshalDocument(doc), nil
+	})
+
+	s.handle(documentServiceName, "DeleteDocument", func(ctx context.Context, b []byte) ([]byte, error) {
+		var req idRequest
+		if err := req.unmarshal(b); err != nil {
+			return nil, invalidMessage(err)
+		}
+		userID := middleware.GetUserIDFromContext(ctx)
+		// 該当文書が存在しなければ NOT_FOUND、既にゴミ箱なら ALREADY_EXISTS（service 層の ErrConflict）
+		if err := documentService.SoftDeleteDocument(req.ID, userID); err != nil {
+			return nil, err
+		}
+		hooks.DocumentDeleted(ctx, req.ID, userID)
+		return nil, nil
+	})
+}
+
+// registerFileService は FileService のメソッドを登録します
+func registerFileService(s *Server, fileService *services.FileService, userStorageQuota int64) {
+	s.handle(fileServiceName, "ListFiles", func(ctx context.Context, b []byte) ([]byte, error) {
+		opts, cursor, err := unmarshalListFilesRequest(b)
+		if err != nil {
+			return nil, invalidMessage(err)
+		}
+		if cursor != "" {
+			if opts.After, err = pagination.Decode(cursor); err != nil {
+				return nil, err
+			}
+		}
+		list, err := fileService.ListFiles(ctx, middleware.GetUserIDFromContext(ctx), opts)
+		if err != nil {
+			return nil, err
+		}
+		return marshalFileList(list), nil
+	})
+
+	s.handle(fileServiceName, "GetFileURL", func(ctx context.Context, b []byte) ([]byte, error) {
+		var req getFileURLRequest
+		if err := req.unmarshal(b); err != nil {
+			return nil, invalidMessage(err)
+		}
+		url, err := fileService.GetPresignedURL(ctx, req.ID, middleware.GetUserIDFromContext(ctx), req.Size)
+		if err != nil {
+			return nil, err
+		}
+		return marshalFileURL(url), nil
+	})
+	s.handle(fileServiceName, "UploadFile", func(ctx context.Context, b []byte) ([]byte, error) {
+		var req uploadFileRequest
+		if err := req.unmarshal(b); err != nil {
+			return nil, invalidMessage(err)
+		}
+		if len(req.Data) == 0 {
+			return nil, apierror.NewValidationError("NO_FILE_UPLOADED", "ファイルを選択してください", nil)
+		}
+
+		userID := middleware.GetUserIDFromContext(ctx)
+		file, header := req.file()
+		if err := fileService.CheckStorageQuota(ctx, userID, middleware.GetWorkspaceIDFromContext(ctx), header.Size, userStorageQuota); err != nil {
+			return nil, uploadError(err)
+		}
+		fileMeta, err := fileService.Upload(ctx, userID, file, header)
+		if err != nil {
+			return nil, uploadError(err)
+		}
+		return marshalFile(fileMeta), nil
+	})
+
+	s.handle(fileServiceName, "DeleteFile", func(ctx context.Context, b []byte) ([]byte, error) {
+		var req idRequest
+		if err := req.unmarshal(b); err != nil {
+			return nil, invalidMessage(err)
+		}
+		if err := fileService.DeleteFile(ctx, req.ID, middleware.GetUserIDFromContext(ctx)); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+}
+
+// uploadError は アップロードのエラーを REST のアップロードと同じエラーコードに変換します
+func uploadError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrWorkspaceStorageQuotaExceeded):
+		return apierror.NewPayloadTooLarge(
+			"WORKSPACE_QUOTA_EXCEEDED", "ワークスペースのストレージ容量の上限を超えています", err,
+		)
+	case errors.Is(err, services.ErrStorageQuotaExceeded):
+		return apierror.NewPayloadTooLarge(
+			"QUOTA_EXCEEDED", "ストレージ容量の上限を超えています", err,
+		)
+	case errors.Is(err, services.ErrFileTooLarge):
+		return apierror.NewPayloadTooLarge(
+			"FILE_TOO_LARGE", "ファイルサイズが上限を超えています", err,
+		)
+	case errors.Is(err, services.ErrUnsupportedFileType):
+		return apierror.NewValidationError(
+			"UNSUPPORTED_FILE_TYPE", "この種類のファイルはアップロードできません", err,
+		)
+	case errors.Is(err, services.ErrContentTypeMismatch):
+		return apierror.NewValidationError(
+			"CONTENT_TYPE_MISMATCH", "ファイルの中身が指定された種類と一致しません", err,
+		)
+	}
+	return err
+}
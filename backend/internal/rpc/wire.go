@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrInvalidMessage は protobuf のメッセージとして読めないバイト列のエラーです
+var ErrInvalidMessage = errors.New("invalid protobuf message")
+
+// protobuf のワイヤー形式（https://protobuf.dev/programming-guides/encoding/）
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// encoder は protobuf のメッセージを組み立てます
+// proto3 の既定値（0・空文字・空のリスト）のフィールドは書き出さない
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) tag(num, wireType int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(num)<<3|uint64(wireType))
+}
+
+// int64 は int32・int64 のフィールドを書き出します（負の値は 10 バイトの2の補数になる）
+func (e *encoder) int64(num int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(num, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(v))
+}
+
+// optionalInt64 は optional int64 のフィールドを書き出します（nil の場合は書き出さず、0 は書き出す）
+func (e *encoder) optionalInt64(num int, v *int) {
+	if v == nil {
+		return
+	}
+	e.tag(num, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(int64(*v)))
+}
+
+func (e *encoder) double(num int, v float64) {
+	if v == 0 {
+		return
+	}
+	e.tag(num, wireFixed64)
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+}
+
+func (e *encoder) string(num int, v string) {
+	if v == "" {
+		return
+	}
+	e.bytes(num, []byte(v))
+}
+
+// optionalString は nil の場合に書き出さない string のフィールドを書き出します
+func (e *encoder) optionalString(num int, v *string) {
+	if v == nil {
+		return
+	}
+	e.string(num, *v)
+}
+
+func (e *encoder) bytes(num int, v []byte) {
+	e.tag(num, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+// message は 入れ子のメッセージのフィールドを書き出します（中身が空の場合も書き出す）
+func (e *encoder) message(num int, encode func(*encoder)) {
+	inner := &encoder{}
+	encode(inner)
+	e.bytes(num, inner.buf)
+}
+
+// timestamp は google.protobuf.Timestamp のフィールドを書き出します（ゼロ値の場合は書き出さない）
+func (e *encoder) timestamp(num int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	e.message(num, func(m *encoder) {
+		m.int64(1, t.Unix())
+		m.int64(2, int64(t.Nanosecond()))
+	})
+}
+
+// field は 読み込んだ1つのフィールドです
+type field struct {
+	num      int
+	wireType int
+	varint   uint64
+	bytes    []byte
+}
+
+// int は varint のフィールドの値を int として返します（int32・int64 の負の値も戻す）
+func (f field) int() int {
+	return int(int64(f.varint))
+}
+
+// decodeFields は メッセージのフィールドを先頭から順に fn に渡します（同じ番号のフィールドは出現するたびに渡す）
+// 知らないフィールドは fn で無視すればよく、固定長のフィールドは読み飛ばす
+func decodeFields(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return fmt.Errorf("field key: %w", ErrInvalidMessage)
+		}
+		b = b[n:]
+
+		f := field{num: int(key >> 3), wireType: int(key & 7)}
+		if f.num == 0 {
+			return fmt.Errorf("field number 0: %w", ErrInvalidMessage)
+		}
+		switch f.wireType {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return fmt.Errorf("field %d varint: %w", f.num, ErrInvalidMessage)
+			}
+			f.varint, b = v, b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return fmt.Errorf("field %d fixed64: %w", f.num, ErrInvalidMessage)
+			}
+			f.bytes, b = b[:8], b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return fmt.Errorf("field %d fixed32: %w", f.num, ErrInvalidMessage)
+			}
+			f.bytes, b = b[:4], b[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || length > uint64(len(b)-n) {
+				return fmt.Errorf("field %d length: %w", f.num, ErrInvalidMessage)
+			}
+			f.bytes, b = b[n:n+int(length)], b[n+int(length):]
+		default:
+			return fmt.Errorf("field %d wire type %d: %w", f.num, f.wireType, ErrInvalidMessage)
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expectWireType は 宣言した型とワイヤー形式が一致しないフィールドのエラーを返します
+func expectWireType(f field, wireType int) error {
+	if f.wireType != wireType {
+		return fmt.Errorf("field %d has wire type %d, want %d: %w", f.num, f.wireType, wireType, ErrInvalidMessage)
+	}
+	return nil
+}
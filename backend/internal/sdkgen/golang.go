@@ -0,0 +1,134 @@
+package sdkgen
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"simple-notion-backend/internal/app"
+)
+
+// goInitialismPattern は、Go の命名の慣習で全て大文字にする略語です（例: ById → ByID）
+var goInitialismPattern = regexp.MustCompile(`(Id|Url|Api|Json)([A-Z]|$)`)
+
+// goReservedParams は、生成するメソッドの引数と衝突するため、パスの変数に使えない名前です
+var goReservedParams = map[string]bool{"c": true, "ctx": true, "in": true, "out": true, "opts": true}
+
+// goHTTPMethods は、メソッドと net/http の定数の対応です
+var goHTTPMethods = map[string]string{
+	"GET":    "http.MethodGet",
+	"POST":   "http.MethodPost",
+	"PUT":    "http.MethodPut",
+	"PATCH":  "http.MethodPatch",
+	"DELETE": "http.MethodDelete",
+	"HEAD":   "http.MethodHead",
+}
+
+// GenerateGo は、仕様の全ての操作を呼び出す Client のメソッドを、パッケージ pkg の Go のソースとして生成します
+// Client 本体（do・pathInt・pathString など）は生成しないため、同じパッケージに手で書いたものを置きます
+func GenerateGo(spec *app.OpenAPISpec, pkg string) ([]byte, error) {
+	ops, err := operations(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s\n\npackage %s\n\n", generatedHeader, pkg)
+	b.WriteString("import (\n\t\"context\"\n\t\"net/http\"\n)\n")
+
+	for _, op := range ops {
+		method, ok := goHTTPMethods[op.Method]
+		if !ok {
+			return nil, fmt.Errorf("unsupported method %s for %s", op.Method, op.Path)
+		}
+
+		args := []string{"ctx context.Context"}
+		names := make(map[string]string, len(op.Params))
+		for _, param := range op.Params {
+			name := goParamName(param.Name)
+			names[param.Name] = name
+			args = append(args, name+" "+goParamType(param))
+		}
+		body := "nil"
+		if op.hasBody() {
+			args = append(args, "in any")
+			body = "in"
+		}
+		args = append(args, "out any", "opts ...RequestOption")
+
+		auth := ""
+		if op.Authenticated {
+			auth = "（要認証）"
+		}
+		fmt.Fprintf(&b, "\n// %s は %s %s を呼び出します%s\n", goMethodName(op.ID), op.Method, op.Path, auth)
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) error {\n", goMethodName(op.ID), strings.Join(args, ", "))
+		fmt.Fprintf(&b, "\treturn c.do(ctx, %s, %s, %s, out, opts)\n}\n", method, goPathExpr(op, names), body)
+	}
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated Go source: %w", err)
+	}
+	return src, nil
+}
+
+// goMethodName は、operationId を公開するメソッド名にします（例: getDocumentsById → GetDocumentsByID）
+func goMethodName(operationID string) string {
+	name := strings.ToUpper(operationID[:1]) + operationID[1:]
+	return goInitialisms(name)
+}
+
+// goParamName は、パスの変数名を引数名にします（例: blockId → blockID、予約語・衝突する名前には Param を付ける）
+func goParamName(name string) string {
+	name = goInitialisms(strings.ToLower(name[:1]) + name[1:])
+	if token.IsKeyword(name) || goReservedParams[name] {
+		name += "Param"
+	}
+	return name
+}
+
+// goInitialisms は、名前に含まれる略語を全て大文字にします
+func goInitialisms(name string) string {
+	// 連続する略語（例: IdId）も置き換えられるよう、置き換えがなくなるまで繰り返す
+	for {
+		replaced := goInitialismPattern.ReplaceAllStringFunc(name, func(match string) string {
+			word := strings.TrimRight(match, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+			return strings.ToUpper(word) + match[len(word):]
+		})
+		if replaced == name {
+			return name
+		}
+		name = replaced
+	}
+}
+
+// goParamType は、パスの変数の Go の型です
+func goParamType(param app.OpenAPIParameter) string {
+	if param.Schema.Type == "integer" {
+		return "int"
+	}
+	return "string"
+}
+
+// goPathExpr は、パスの変数を埋め込んだパスを組み立てる Go の式です（例: "/api/documents/" + pathInt(id)）
+func goPathExpr(op operation, names map[string]string) string {
+	var parts []string
+	for _, part := range splitPath(op.Path) {
+		if !strings.HasPrefix(part, "{") {
+			parts = append(parts, strconv.Quote(part))
+			continue
+		}
+		variable := strings.Trim(part, "{}")
+		helper := "pathString"
+		for _, param := range op.Params {
+			if param.Name == variable && goParamType(param) == "int" {
+				helper = "pathInt"
+			}
+		}
+		parts = append(parts, helper+"("+names[variable]+")")
+	}
+	return strings.Join(parts, " + ")
+}
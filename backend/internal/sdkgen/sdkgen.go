@@ -0,0 +1,83 @@
+// Package sdkgen は、OpenAPI の仕様から公式の API クライアント（Go のパッケージと TypeScript の型）を生成します
+// 仕様はルートの登録内容から生成するため、リクエスト・レスポンスの本文の型は含みません（本文は呼び出し側が指定する）
+package sdkgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"simple-notion-backend/internal/app"
+)
+
+// generatedHeader は、生成したファイルの先頭に付ける注記です（go generate で上書きするため編集しない）
+const generatedHeader = "Code generated by sdkgen from the OpenAPI spec. DO NOT EDIT."
+
+// pathVariablePattern は、OpenAPI のパスの変数（{name}）です
+var pathVariablePattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// operation は、クライアントの1つのメソッドにする操作です
+type operation struct {
+	ID            string // operationId（例: getDocumentsById）
+	Method        string // 大文字のメソッド（例: GET）
+	Path          string // OpenAPI のパス（例: /api/documents/{id}）
+	Params        []app.OpenAPIParameter
+	Authenticated bool
+}
+
+// hasBody は、リクエストの本文を送るメソッドかどうかを返します
+func (op operation) hasBody() bool {
+	switch op.Method {
+	case "POST", "PUT", "PATCH":
+		return true
+	}
+	return false
+}
+
+// operations は、仕様の全ての操作をパス・メソッドの順に並べて返します
+// operationId が重複している場合は、生成するメソッド名が衝突するためエラーにします
+func operations(spec *app.OpenAPISpec) ([]operation, error) {
+	var ops []operation
+	seen := make(map[string]string)
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			key := strings.ToUpper(method) + " " + path
+			if other, ok := seen[op.OperationID]; ok {
+				return nil, fmt.Errorf("duplicate operationId %q: %s and %s", op.OperationID, other, key)
+			}
+			seen[op.OperationID] = key
+			ops = append(ops, operation{
+				ID:            op.OperationID,
+				Method:        strings.ToUpper(method),
+				Path:          path,
+				Params:        op.Parameters,
+				Authenticated: len(op.Security) > 0,
+			})
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+	return ops, nil
+}
+
+// splitPath は、パスを固定の部分と変数に分けます（例: /a/{id}/b → ["/a/", "{id}", "/b"]）
+func splitPath(path string) []string {
+	var parts []string
+	last := 0
+	for _, loc := range pathVariablePattern.FindAllStringIndex(path, -1) {
+		if loc[0] > last {
+			parts = append(parts, path[last:loc[0]])
+		}
+		parts = append(parts, path[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	if last < len(path) {
+		parts = append(parts, path[last:])
+	}
+	return parts
+}
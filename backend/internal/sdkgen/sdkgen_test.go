@@ -0,0 +1,116 @@
+package sdkgen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"simple-notion-backend/internal/app"
+)
+
+// testSpec は、パスの変数・本文・認証の有無を含む小さなルーターの仕様です
+func testSpec(t *testing.T) *app.OpenAPISpec {
+	t.Helper()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/auth/login", noop).Methods("POST")
+	router.HandleFunc("/api/share/{token}", noop).Methods("GET")
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/documents/{id:[0-9]+}", noop).Methods("GET", "PUT")
+	api.HandleFunc("/documents/{id:[0-9]+}/blocks/{blockId:[0-9]+}", noop).Methods("DELETE")
+
+	spec, err := app.BuildOpenAPISpec(router)
+	if err != nil {
+		t.Fatalf("BuildOpenAPISpec() error = %v", err)
+	}
+	return spec
+}
+
+func TestGenerateGo(t *testing.T) {
+	src, err := GenerateGo(testSpec(t), "client")
+	if err != nil {
+		t.Fatalf("GenerateGo() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "正常系：生成したファイルの注記", want: "// Code generated by sdkgen from the OpenAPI spec. DO NOT EDIT."},
+		{name: "正常系：本文を送るメソッド", want: "func (c *Client) PostAuthLogin(ctx context.Context, in any, out any, opts ...RequestOption) error {"},
+		{name: "正常系：数値のパスの変数", want: `return c.do(ctx, http.MethodGet, "/api/documents/"+pathInt(id), nil, out, opts)`},
+		{name: "正常系：文字列のパスの変数", want: `return c.do(ctx, http.MethodGet, "/api/share/"+pathString(token), nil, out, opts)`},
+		{name: "正常系：複数のパスの変数と略語", want: "func (c *Client) DeleteDocumentsByIDBlocksByBlockID(ctx context.Context, id int, blockID int, out any, opts ...RequestOption) error {"},
+		{name: "正常系：認証が必要な操作の注記", want: "// PutDocumentsByID は PUT /api/documents/{id} を呼び出します（要認証）"},
+		{name: "正常系：認証が不要な操作の注記", want: "// PostAuthLogin は POST /api/auth/login を呼び出します\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(string(src), tt.want) {
+				t.Errorf("generated source does not contain %q\n%s", tt.want, src)
+			}
+		})
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	src, err := GenerateTypeScript(testSpec(t))
+	if err != nil {
+		t.Fatalf("GenerateTypeScript() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "正常系：エラーレスポンスの型", want: "export interface ApiError {\n  detail: string\n  details?: Record<string, unknown>\n"},
+		{name: "正常系：パスの変数の型", want: "  deleteDocumentsByIdBlocksByBlockId: {\n    method: 'DELETE'\n    path: '/api/documents/{id}/blocks/{blockId}'\n    params: { id: number; blockId: number }\n    authenticated: true\n  }\n"},
+		{name: "正常系：パスの変数がない操作", want: "  postAuthLogin: {\n    method: 'POST'\n    path: '/api/auth/login'\n    params: Record<string, never>\n    authenticated: false\n  }\n"},
+		{name: "正常系：操作の名前の型", want: "export type OperationId = keyof Operations\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(string(src), tt.want) {
+				t.Errorf("generated source does not contain %q\n%s", tt.want, src)
+			}
+		})
+	}
+}
+
+func TestOperationsDuplicateID(t *testing.T) {
+	spec := &app.OpenAPISpec{Paths: map[string]app.OpenAPIPathItem{
+		"/api/a-b": {"get": {OperationID: "getAB"}},
+		"/api/a_b": {"get": {OperationID: "getAB"}},
+	}}
+	if _, err := operations(spec); err == nil || !strings.Contains(err.Error(), "duplicate operationId") {
+		t.Errorf("operations() error = %v, want duplicate operationId", err)
+	}
+}
+
+func TestGoNames(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "正常系：メソッド名の先頭を大文字にする", got: goMethodName("getDocumentsTree"), want: "GetDocumentsTree"},
+		{name: "正常系：連続する略語", got: goMethodName("getFilesByIdUrl"), want: "GetFilesByIDURL"},
+		{name: "正常系：略語で始まらない単語は変えない", got: goMethodName("getIdentity"), want: "GetIdentity"},
+		{name: "正常系：引数名の略語", got: goParamName("blockId"), want: "blockID"},
+		{name: "正常系：予約語の引数名", got: goParamName("type"), want: "typeParam"},
+		{name: "正常系：生成する引数と衝突する名前", got: goParamName("ctx"), want: "ctxParam"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
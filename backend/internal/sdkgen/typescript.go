@@ -0,0 +1,94 @@
+package sdkgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"simple-notion-backend/internal/app"
+)
+
+// GenerateTypeScript は、仕様のスキーマと全ての操作（メソッド・パス・パスの変数・認証の要否）の TypeScript の型を生成します
+// スキーマの名前には Api を付けます（Error など組み込みの型との衝突を避ける）
+// 書式はフロントエンドの Prettier の設定（セミコロンなし・シングルクォート・2スペース）に合わせます
+func GenerateTypeScript(spec *app.OpenAPISpec) ([]byte, error) {
+	ops, err := operations(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s\n", generatedHeader)
+
+	names := make([]string, 0, len(spec.Components.Schemas))
+	for name := range spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema := spec.Components.Schemas[name]
+		fmt.Fprintf(&b, "\nexport interface %s {\n", tsSchemaName(name))
+		required := make(map[string]bool, len(schema.Required))
+		for _, property := range schema.Required {
+			required[property] = true
+		}
+		properties := make([]string, 0, len(schema.Properties))
+		for property := range schema.Properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+		for _, property := range properties {
+			optional := "?"
+			if required[property] {
+				optional = ""
+			}
+			fmt.Fprintf(&b, "  %s%s: %s\n", property, optional, tsType(schema.Properties[property]))
+		}
+		b.WriteString("}\n")
+	}
+
+	b.WriteString("\nexport interface Operations {\n")
+	for _, op := range ops {
+		fmt.Fprintf(&b, "  %s: {\n", op.ID)
+		fmt.Fprintf(&b, "    method: '%s'\n", op.Method)
+		fmt.Fprintf(&b, "    path: '%s'\n", op.Path)
+		if len(op.Params) == 0 {
+			b.WriteString("    params: Record<string, never>\n")
+		} else {
+			params := make([]string, 0, len(op.Params))
+			for _, param := range op.Params {
+				params = append(params, param.Name+": "+tsType(param.Schema))
+			}
+			fmt.Fprintf(&b, "    params: { %s }\n", strings.Join(params, "; "))
+		}
+		fmt.Fprintf(&b, "    authenticated: %t\n", op.Authenticated)
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	b.WriteString("\nexport type OperationId = keyof Operations\n")
+
+	return []byte(b.String()), nil
+}
+
+// tsSchemaName は、スキーマの名前を TypeScript の型名にします（例: Error → ApiError）
+func tsSchemaName(name string) string {
+	return "Api" + name
+}
+
+// tsType は、スキーマの TypeScript の型です
+func tsType(schema app.OpenAPISchema) string {
+	if schema.Ref != "" {
+		return tsSchemaName(strings.TrimPrefix(schema.Ref, "#/components/schemas/"))
+	}
+	switch schema.Type {
+	case "integer", "number":
+		return "number"
+	case "string":
+		return "string"
+	case "boolean":
+		return "boolean"
+	case "object":
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
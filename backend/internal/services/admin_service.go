@@ -0,0 +1,46 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"simple-notion-backend/internal/apierror"
+)
+
+// AdminService - 管理者権限の判定を担当するサービス
+// 管理者は設定（ADMIN_EMAILS）に列挙されたメールアドレスのユーザー
+type AdminService struct {
+	userRepo    UserRepositoryInterface
+	adminEmails map[string]bool
+}
+
+// NewAdminService - AdminServiceを初期化
+func NewAdminService(userRepo UserRepositoryInterface, adminEmails []string) *AdminService {
+	emails := make(map[string]bool, len(adminEmails))
+	for _, email := range adminEmails {
+		emails[strings.ToLower(strings.TrimSpace(email))] = true
+	}
+
+	return &AdminService{
+		userRepo:    userRepo,
+		adminEmails: emails,
+	}
+}
+
+// IsAdmin - ユーザーが管理者かどうかを判定
+// 存在しないユーザーは管理者ではないものとして扱う
+func (s *AdminService) IsAdmin(userID int) (bool, error) {
+	if len(s.adminEmails) == 0 {
+		return false, nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return s.adminEmails[strings.ToLower(user.Email)], nil
+}
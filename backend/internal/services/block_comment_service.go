@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// BlockCommentService - 文書内のブロックへのコメントを担当するサービス
+type BlockCommentService struct {
+	documentRepo DocumentCoreRepositoryInterface
+	blockRepo    BlockRepositoryInterface
+	commentRepo  BlockCommentRepositoryInterface
+}
+
+// NewBlockCommentService - BlockCommentServiceを初期化
+func NewBlockCommentService(
+	documentRepo DocumentCoreRepositoryInterface,
+	blockRepo BlockRepositoryInterface,
+	commentRepo BlockCommentRepositoryInterface,
+) *BlockCommentService {
+	return &BlockCommentService{
+		documentRepo: documentRepo,
+		blockRepo:    blockRepo,
+		commentRepo:  commentRepo,
+	}
+}
+
+// ListComments - 文書のコメント一覧を取得（ブロック・解決状態で絞り込み可能）
+func (s *BlockCommentService) ListComments(docID, userID int, filter models.BlockCommentFilter) ([]models.BlockComment, error) {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	return s.commentRepo.ListComments(docID, filter)
+}
+
+// CreateComment - ブロックにコメントを追加
+func (s *BlockCommentService) CreateComment(docID, userID, blockID int, body string) (*models.BlockComment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.blockRepo.GetBlock(docID, blockID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.BlockComment{
+		DocumentID: docID,
+		BlockID:    blockID,
+		UserID:     userID,
+		Body:       body,
+	}
+	if err := s.commentRepo.CreateComment(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// UpdateComment - コメントの本文・解決状態を更新し、更新後のコメントを返す
+// 本文は投稿者のみ変更でき、解決状態は文書を編集できるユーザーであれば変更できる
+func (s *BlockCommentService) UpdateComment(docID, userID, commentID int, patch models.BlockCommentPatch) (*models.BlockComment, error) {
+	if patch.Body == nil && patch.Resolved == nil {
+		return nil, apierror.NewValidationError("INVALID_REQUEST", "body または resolved を指定してください", nil)
+	}
+
+	var body string
+	if patch.Body != nil {
+		var err error
+		if body, err = validateCommentBody(*patch.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+
+	if patch.Body != nil {
+		if err := s.commentRepo.UpdateCommentBody(docID, commentID, userID, body); err != nil {
+			return nil, err
+		}
+	}
+	if patch.Resolved != nil {
+		if err := s.commentRepo.SetCommentResolved(docID, commentID, userID, *patch.Resolved); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.commentRepo.GetComment(docID, commentID)
+}
+
+// DeleteComment - コメントを削除（投稿者のみ）
+func (s *BlockCommentService) DeleteComment(docID, userID, commentID int) error {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return err
+	}
+	return s.commentRepo.DeleteComment(docID, commentID, userID)
+}
+
+// validateCommentBody - コメント本文の前後の空白を除き、空・長すぎる本文を拒否
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", apierror.NewValidationError("INVALID_COMMENT", "コメントを入力してください", nil)
+	}
+	if len([]rune(body)) > models.MaxCommentLength {
+		return "", apierror.NewValidationError("COMMENT_TOO_LONG",
+			fmt.Sprintf("コメントは%d文字以内で入力してください", models.MaxCommentLength), nil)
+	}
+	return body, nil
+}
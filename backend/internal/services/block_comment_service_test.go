@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// MockBlockCommentRepository - BlockCommentRepositoryのモック
+type MockBlockCommentRepository struct {
+	CreateCommentFunc      func(comment *models.BlockComment) error
+	ListCommentsFunc       func(docID int, filter models.BlockCommentFilter) ([]models.BlockComment, error)
+	GetCommentFunc         func(docID, commentID int) (*models.BlockComment, error)
+	UpdateCommentBodyFunc  func(docID, commentID, userID int, body string) error
+	SetCommentResolvedFunc func(docID, commentID, userID int, resolved bool) error
+	DeleteCommentFunc      func(docID, commentID, userID int) error
+}
+
+func (m *MockBlockCommentRepository) CreateComment(comment *models.BlockComment) error {
+	if m.CreateCommentFunc != nil {
+		return m.CreateCommentFunc(comment)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockCommentRepository) ListComments(docID int, filter models.BlockCommentFilter) ([]models.BlockComment, error) {
+	if m.ListCommentsFunc != nil {
+		return m.ListCommentsFunc(docID, filter)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBlockCommentRepository) GetComment(docID, commentID int) (*models.BlockComment, error) {
+	if m.GetCommentFunc != nil {
+		return m.GetCommentFunc(docID, commentID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBlockCommentRepository) UpdateCommentBody(docID, commentID, userID int, body string) error {
+	if m.UpdateCommentBodyFunc != nil {
+		return m.UpdateCommentBodyFunc(docID, commentID, userID, body)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockCommentRepository) SetCommentResolved(docID, commentID, userID int, resolved bool) error {
+	if m.SetCommentResolvedFunc != nil {
+		return m.SetCommentResolvedFunc(docID, commentID, userID, resolved)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockCommentRepository) DeleteComment(docID, commentID, userID int) error {
+	if m.DeleteCommentFunc != nil {
+		return m.DeleteCommentFunc(docID, commentID, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func ownedDocumentRepo() *MockDocumentCoreRepository {
+	return &MockDocumentCoreRepository{
+		GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+			return &models.Document{ID: docID, UserID: userID}, nil
+		},
+	}
+}
+
+func TestBlockCommentService_CreateComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		blockErr error
+		wantBody string
+		wantCode string
+		wantErr  error
+	}{
+		{name: "正常系：前後の空白を除いて保存", body: "  ここを確認してください \n", wantBody: "ここを確認してください"},
+		{name: "異常系：空のコメント", body: " \n ", wantCode: "INVALID_COMMENT"},
+		{name: "異常系：長すぎるコメント", body: strings.Repeat("あ", models.MaxCommentLength+1), wantCode: "COMMENT_TOO_LONG"},
+		{name: "異常系：ブロックが存在しない", body: "コメント", blockErr: apierror.ErrNotFound, wantErr: apierror.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockRepo := &MockBlockRepository{
+				GetBlockFunc: func(docID, blockID int) (*models.Block, error) {
+					return &models.Block{ID: blockID, DocumentID: docID}, tt.blockErr
+				},
+			}
+			var saved *models.BlockComment
+			commentRepo := &MockBlockCommentRepository{
+				CreateCommentFunc: func(comment *models.BlockComment) error {
+					saved = comment
+					comment.ID = 1
+					return nil
+				},
+			}
+			service := NewBlockCommentService(ownedDocumentRepo(), blockRepo, commentRepo)
+
+			comment, err := service.CreateComment(2, 10, 7, tt.body)
+			switch {
+			case tt.wantCode != "":
+				var appErr *apierror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			case err != nil:
+				t.Fatalf("CreateComment() error = %v", err)
+			}
+
+			if saved == nil || saved.DocumentID != 2 || saved.BlockID != 7 || saved.UserID != 10 || saved.Body != tt.wantBody {
+				t.Errorf("saved comment = %+v", saved)
+			}
+			if comment.ID != 1 {
+				t.Errorf("comment.ID = %d, want 1", comment.ID)
+			}
+		})
+	}
+}
+
+func TestBlockCommentService_UpdateComment(t *testing.T) {
+	body := "修正しました"
+	resolved := true
+
+	t.Run("正常系：本文と解決状態を更新", func(t *testing.T) {
+		var gotBody string
+		var gotResolved *bool
+		commentRepo := &MockBlockCommentRepository{
+			UpdateCommentBodyFunc: func(docID, commentID, userID int, body string) error {
+				gotBody = body
+				return nil
+			},
+			SetCommentResolvedFunc: func(docID, commentID, userID int, resolved bool) error {
+				gotResolved = &resolved
+				return nil
+			},
+			GetCommentFunc: func(docID, commentID int) (*models.BlockComment, error) {
+				return &models.BlockComment{ID: commentID, Body: gotBody, Resolved: *gotResolved}, nil
+			},
+		}
+		service := NewBlockCommentService(ownedDocumentRepo(), &MockBlockRepository{}, commentRepo)
+
+		comment, err := service.UpdateComment(2, 10, 5, models.BlockCommentPatch{Body: &body, Resolved: &resolved})
+		if err != nil {
+			t.Fatalf("UpdateComment() error = %v", err)
+		}
+		if comment.Body != body || !comment.Resolved {
+			t.Errorf("comment = %+v", comment)
+		}
+	})
+
+	t.Run("異常系：変更する項目がない", func(t *testing.T) {
+		service := NewBlockCommentService(ownedDocumentRepo(), &MockBlockRepository{}, &MockBlockCommentRepository{})
+
+		_, err := service.UpdateComment(2, 10, 5, models.BlockCommentPatch{})
+		var appErr *apierror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_REQUEST" {
+			t.Errorf("error = %v, want code INVALID_REQUEST", err)
+		}
+	})
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"simple-notion-backend/internal/models"
+)
+
+// mergeBlocks - 基準（base）から他の保存で変わった現在のブロック（current）と、送信されたブロック（incoming）を3方向マージする
+// ブロックIDは保存のたびに振り直されるため、diffBlocks と同じく type + content の一致で同一ブロックとみなし、
+// base と両者の共通部分列で一致したブロックを境に区間ごとに次のように決める（diff3）
+//   - 片方だけが変更した区間はその変更を採用する
+//   - 双方が同じ内容に変更した区間はそのまま採用する
+//   - 双方が同じ位置に追加しただけの区間は、送信された側・他の保存の順に両方を残す
+//   - 双方が異なる内容に変更した区間は競合とし、送信された区間の直後に、他の保存で追加・変更されたブロックを残す
+//
+// 戻り値の Position は一覧内の順に振り直し、一覧にない親を指すブロックは最上位に移す
+func mergeBlocks(base, current, incoming []models.Block) ([]models.Block, []models.MergeConflict) {
+	baseKeys := blockSignatures(base)
+	currentKeys := blockSignatures(current)
+	incomingKeys := blockSignatures(incoming)
+
+	toCurrent := matchBlocks(baseKeys, currentKeys)
+	toIncoming := matchBlocks(baseKeys, incomingKeys)
+
+	result := make([]models.Block, 0, len(incoming))
+	conflicts := make([]models.MergeConflict, 0)
+	// 現在のブロックの ID → 採用したブロックの ID（他の保存で追加された子ブロックの親の付け替えに使う）
+	currentIDs := make(map[int]int)
+
+	takeCurrent := func(blocks []models.Block) {
+		for _, b := range blocks {
+			result = append(result, b)
+			if b.ID != 0 {
+				currentIDs[b.ID] = b.ID
+			}
+		}
+	}
+
+	b, c, x := 0, 0, 0
+	for {
+		// 次に3者で一致するブロック（見つからない場合は末尾）までを1区間とする
+		next := b
+		for next < len(base) && (toCurrent[next] < 0 || toIncoming[next] < 0) {
+			next++
+		}
+		cEnd, xEnd := len(current), len(incoming)
+		if next < len(base) {
+			cEnd, xEnd = toCurrent[next], toIncoming[next]
+		}
+
+		baseHunk := baseKeys[b:next]
+		currentHunk := currentKeys[c:cEnd]
+		incomingHunk := incomingKeys[x:xEnd]
+		switch {
+		case equalKeys(currentHunk, baseHunk), equalKeys(currentHunk, incomingHunk):
+			result = append(result, incoming[x:xEnd]...)
+		case equalKeys(incomingHunk, baseHunk):
+			takeCurrent(current[c:cEnd])
+		case len(baseHunk) == 0:
+			// 同じ位置への追加同士は競合にせず、送信された側の後ろに他の保存で追加されたブロックを並べる
+			result = append(result, incoming[x:xEnd]...)
+			takeCurrent(current[c:cEnd])
+		default:
+			position := len(result)
+			conflicts = append(conflicts, models.MergeConflict{Field: "blocks", Position: &position})
+			result = append(result, incoming[x:xEnd]...)
+
+			// 送信された区間・基準にない（他の保存で追加・変更された）ブロックだけを残す
+			seen := make(map[string]bool, len(baseHunk)+len(incomingHunk))
+			for _, key := range baseHunk {
+				seen[key] = true
+			}
+			for _, key := range incomingHunk {
+				seen[key] = true
+			}
+			for i := c; i < cEnd; i++ {
+				if !seen[currentKeys[i]] {
+					takeCurrent(current[i : i+1])
+				}
+			}
+		}
+
+		if next >= len(base) {
+			break
+		}
+		result = append(result, incoming[xEnd])
+		if current[cEnd].ID != 0 {
+			currentIDs[current[cEnd].ID] = incoming[xEnd].ID
+		}
+		b, c, x = next+1, cEnd+1, xEnd+1
+	}
+
+	return normalizeMergedBlocks(result, currentIDs), conflicts
+}
+
+// normalizeMergedBlocks - マージ後の一覧の ID・親子関係・位置を保存できる形に整える
+// 重複した ID は新しいブロックとして扱い（0 にする）、一覧にない親を指すブロックは最上位に移す
+func normalizeMergedBlocks(blocks []models.Block, currentIDs map[int]int) []models.Block {
+	ids := make(map[int]bool, len(blocks))
+	for i := range blocks {
+		if blocks[i].ID == 0 {
+			continue
+		}
+		if ids[blocks[i].ID] {
+			blocks[i].ID = 0
+			continue
+		}
+		ids[blocks[i].ID] = true
+	}
+
+	for i := range blocks {
+		blocks[i].Position = i
+		parentID := blocks[i].ParentBlockID
+		if parentID == nil {
+			continue
+		}
+		if ids[*parentID] && *parentID != blocks[i].ID {
+			continue
+		}
+		if mapped, ok := currentIDs[*parentID]; ok && ids[mapped] && mapped != blocks[i].ID {
+			blocks[i].ParentBlockID = &mapped
+			continue
+		}
+		blocks[i].ParentBlockID = nil
+	}
+	return blocks
+}
+
+// maxLCSCells - 最長共通部分列を表で求めるブロック数の積の上限（1000 ブロック同士程度、表は約 8MB）
+// 超える場合は、両方に1回ずつ現れるブロックのみを対応付ける（patience diff）
+const maxLCSCells = 1 << 20
+
+// matchBlocks - 2つのブロック列の最長共通部分列を求め、a の各ブロックに対応する b の位置（なければ -1）を返す
+// 先頭・末尾の一致する区間は表を使わずに対応付け、残りの区間が大きすぎる場合は patienceMatch で近似する
+func matchBlocks(a, b []string) []int {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		match[start] = start
+		start++
+	}
+	endA, endB := len(a), len(b)
+	for endA > start && endB > start && a[endA-1] == b[endB-1] {
+		endA--
+		endB--
+		match[endA] = endB
+	}
+
+	n, m := endA-start, endB-start
+	if n == 0 || m == 0 {
+		return match
+	}
+	if n*m > maxLCSCells {
+		patienceMatch(a[start:endA], b[start:endB], match[start:endA], start)
+		return match
+	}
+
+	a, b = a[start:endA], b[start:endB]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			match[start+i] = start + j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match
+}
+
+// patienceMatch - a・b の両方に1回ずつ現れるブロックのうち、順序を保てる最大の組を対応付ける（O((n+m) log n)）
+// match[i] には a[i] に対応する b の位置に offset を足した値を設定する
+func patienceMatch(a, b []string, match []int, offset int) {
+	counts := make(map[string][2]int, len(a))
+	for _, key := range a {
+		c := counts[key]
+		c[0]++
+		counts[key] = c
+	}
+	positions := make(map[string]int, len(b))
+	for j, key := range b {
+		if c, ok := counts[key]; ok {
+			c[1]++
+			counts[key] = c
+			positions[key] = j
+		}
+	}
+
+	// a の順に並べた一意なブロックの組から、b の位置が増加する最長の部分列を求める
+	type pair struct{ i, j int }
+	var pairs []pair
+	for i, key := range a {
+		if c := counts[key]; c[0] == 1 && c[1] == 1 {
+			pairs = append(pairs, pair{i: i, j: positions[key]})
+		}
+	}
+	tails := make([]int, 0, len(pairs)) // 長さ k+1 の部分列の末尾の pairs の添字
+	prev := make([]int, len(pairs))
+	for k, p := range pairs {
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if pairs[tails[mid]].j < p.j {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		prev[k] = -1
+		if lo > 0 {
+			prev[k] = tails[lo-1]
+		}
+		if lo == len(tails) {
+			tails = append(tails, k)
+		} else {
+			tails[lo] = k
+		}
+	}
+	if len(tails) == 0 {
+		return
+	}
+	for k := tails[len(tails)-1]; k >= 0; k = prev[k] {
+		match[pairs[k].i] = pairs[k].j + offset
+	}
+}
+
+// blockSignatures - ブロック列の比較キーの一覧
+func blockSignatures(blocks []models.Block) []string {
+	keys := make([]string, len(blocks))
+	for i, b := range blocks {
+		keys[i] = blockSignature(b)
+	}
+	return keys
+}
+
+// equalKeys - 2つの比較キーの列が一致するか
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+
+	"simple-notion-backend/internal/models"
+)
+
+// blockTexts - ブロック列の text の一覧（比較用）
+func blockTexts(t *testing.T, blocks []models.Block) []string {
+	t.Helper()
+	texts := make([]string, len(blocks))
+	for i, b := range blocks {
+		texts[i] = string(b.Content)
+	}
+	return texts
+}
+
+// textBlocks - text の一覧から段落ブロック列を生成
+func textBlocks(texts ...string) []models.Block {
+	blocks := make([]models.Block, len(texts))
+	for i, text := range texts {
+		blocks[i] = textBlock(text, i)
+	}
+	return blocks
+}
+
+// TestMergeBlocks - ブロック列の3方向マージのテスト
+func TestMergeBlocks(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          []models.Block
+		current       []models.Block
+		incoming      []models.Block
+		want          []models.Block
+		wantConflicts int
+	}{
+		{
+			name:     "正常系：別々の箇所の変更を両方残す",
+			base:     textBlocks("a", "b", "c"),
+			current:  textBlocks("a", "b2", "c"),
+			incoming: textBlocks("a", "b", "c", "d"),
+			want:     textBlocks("a", "b2", "c", "d"),
+		},
+		{
+			name:     "正常系：他の保存で削除したブロックは削除したまま",
+			base:     textBlocks("a", "b", "c"),
+			current:  textBlocks("a", "c"),
+			incoming: textBlocks("x", "a", "b", "c"),
+			want:     textBlocks("x", "a", "c"),
+		},
+		{
+			name:     "正常系：双方が同じ内容に変更した場合は競合にしない",
+			base:     textBlocks("a", "b"),
+			current:  textBlocks("a", "b2"),
+			incoming: textBlocks("a", "b2"),
+			want:     textBlocks("a", "b2"),
+		},
+		{
+			name:          "正常系：同じブロックの変更は競合として送信側を優先し、他の変更も残す",
+			base:          textBlocks("a", "b", "c"),
+			current:       textBlocks("a", "b-other", "c"),
+			incoming:      textBlocks("a", "b-mine", "c"),
+			want:          textBlocks("a", "b-mine", "b-other", "c"),
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := mergeBlocks(tt.base, tt.current, tt.incoming)
+
+			gotTexts, wantTexts := blockTexts(t, got), blockTexts(t, tt.want)
+			if !equalKeys(gotTexts, wantTexts) {
+				t.Errorf("mergeBlocks() = %v, want %v", gotTexts, wantTexts)
+			}
+			if len(conflicts) != tt.wantConflicts {
+				t.Errorf("mergeBlocks() conflicts = %d, want %d", len(conflicts), tt.wantConflicts)
+			}
+			for i, b := range got {
+				if b.Position != i {
+					t.Errorf("block %d Position = %d, want %d", i, b.Position, i)
+				}
+			}
+		})
+	}
+
+	t.Run("正常系：他の保存で追加した子ブロックの親を付け替える", func(t *testing.T) {
+		base := textBlocks("toggle")
+		base[0].ID = 1
+		current := textBlocks("toggle", "child")
+		current[0].ID = 5
+		current[1].ID = 6
+		parentID := 5
+		current[1].ParentBlockID = &parentID
+		incoming := textBlocks("toggle", "tail")
+		incoming[0].ID = 1
+
+		got, _ := mergeBlocks(base, current, incoming)
+
+		if len(got) != 3 {
+			t.Fatalf("mergeBlocks() = %d blocks, want 3", len(got))
+		}
+		if got[2].ParentBlockID == nil || *got[2].ParentBlockID != 1 {
+			t.Errorf("child ParentBlockID = %v, want 1", got[2].ParentBlockID)
+		}
+	})
+}
+
+// TestMatchBlocks - ブロック列の対応付けのテスト（大きい区間は表を使わずに近似する）
+func TestMatchBlocks(t *testing.T) {
+	t.Run("正常系：先頭・末尾の一致と間の区間の最長共通部分列", func(t *testing.T) {
+		got := matchBlocks([]string{"a", "b", "c", "d", "e"}, []string{"a", "c", "x", "b", "e"})
+		want := []int{0, -1, 1, -1, 4}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("matchBlocks() = %v, want %v", got, want)
+			}
+		}
+	})
+
+	t.Run("正常系：大きすぎる区間は一意なブロックのみを順序を保って対応付ける", func(t *testing.T) {
+		// 先頭・末尾が異なり、間の区間が maxLCSCells を超える
+		n := 1200
+		a := []string{"first-a"}
+		b := []string{"first-b"}
+		for i := 0; i < n; i++ {
+			a = append(a, strconv.Itoa(i))
+			if i%100 == 50 {
+				b = append(b, "changed-"+strconv.Itoa(i))
+			} else {
+				b = append(b, strconv.Itoa(i))
+			}
+		}
+		a, b = append(a, "last-a", "dup", "dup"), append(b, "last-b", "dup", "dup")
+		if (len(a)-2)*(len(b)-2) <= maxLCSCells {
+			t.Fatalf("test input is too small: %d x %d", len(a), len(b))
+		}
+
+		got := matchBlocks(a, b)
+		for i := 1; i <= n; i++ {
+			want := i
+			if (i-1)%100 == 50 {
+				want = -1
+			}
+			if got[i] != want {
+				t.Fatalf("matchBlocks()[%d] = %d, want %d", i, got[i], want)
+			}
+		}
+		// 末尾の一致は表を使わずに対応付ける
+		if got[len(a)-1] != len(b)-1 || got[len(a)-2] != len(b)-2 {
+			t.Errorf("matchBlocks() tail = %v, want matched", got[len(a)-3:])
+		}
+	})
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"simple-notion-backend/internal/blockschema"
+	"simple-notion-backend/internal/models"
+)
+
+// richTextSchema - テキスト系ブロックの content（プレーンテキストまたは TipTap JSON を含む文字列）
+const richTextSchema = `{"type":"string"}`
+
+// mediaSchema - 画像・ファイル・音声ブロックの content（アップロード済みファイルへの参照）
+const mediaSchema = `{
+	"type": "object",
+	"required": ["src"],
+	"properties": {
+		"src": {"type": "string", "minLength": 1},
+		"alt": {"type": "string"},
+		"caption": {"type": "string"},
+		"width": {"type": "number", "minimum": 0},
+		"height": {"type": "number", "minimum": 0},
+		"originalName": {"type": "string"},
+		"fileSize": {"type": "integer", "minimum": 0},
+		"mimeType": {"type": "string"},
+		"durationMs": {"type": "integer", "minimum": 0},
+		"fileKey": {"type": "string"},
+		"fileId": {"type": "integer"},
+		"bucketName": {"type": "string"},
+		"uploadedAt": {"type": "string"},
+		"status": {"type": "string", "enum": ["active", "deleted", "orphaned"]}
+	}
+}`
+
+// tableSchema - 表ブロックの content（header が true の場合は先頭行を見出しとして扱う）
+const tableSchema = `{
+	"type": "object",
+	"required": ["rows"],
+	"properties": {
+		"header": {"type": "boolean"},
+		"rows": {
+			"type": "array",
+			"minItems": 1,
+			"maxItems": 500,
+			"items": {
+				"type": "array",
+				"minItems": 1,
+				"maxItems": 50,
+				"items": {"type": "string"}
+			}
+		}
+	}
+}`
+
+// codeSchema - コードブロックの content（language は任意、従来の文字列のみの content も受け付ける）
+const codeSchema = `{
+	"type": "object",
+	"required": ["content"],
+	"properties": {
+		"language": {"type": "string", "maxLength": 50},
+		"content": {"type": "string"}
+	}
+}`
+
+// bookmarkSchema - ブックマークブロックの content（url 以外はサーバーが取得したリンクのプレビュー）
+const bookmarkSchema = `{
+	"type": "object",
+	"required": ["url"],
+	"properties": {
+		"url": {"type": "string", "minLength": 1, "maxLength": 2048},
+		"title": {"type": "string"},
+		"description": {"type": "string"},
+		"image": {"type": "string"},
+		"siteName": {"type": "string"},
+		"fetchedAt": {"type": "string"}
+	}
+}`
+
+// embedSchema - 埋め込みブロックの content（url 以外は oEmbed の解決結果、HTML と iframe は checkEmbedContent で検証する）
+const embedSchema = `{
+	"type": "object",
+	"required": ["url"],
+	"properties": {
+		"url": {"type": "string", "minLength": 1, "maxLength": 2048},
+		"provider": {"type": "string"},
+		"type": {"type": "string", "enum": ["video", "rich", "photo", "link"]},
+		"title": {"type": "string"},
+		"html": {"type": "string", "maxLength": 20000},
+		"iframe": {
+			"type": "object",
+			"required": ["src", "sandbox"],
+			"properties": {
+				"src": {"type": "string", "maxLength": 2048},
+				"width": {"type": "integer", "minimum": 0},
+				"height": {"type": "integer", "minimum": 0},
+				"allow": {"type": "string"},
+				"sandbox": {"type": "string"}
+			}
+		},
+		"thumbnailUrl": {"type": "string"},
+		"width": {"type": "integer", "minimum": 0},
+		"height": {"type": "integer", "minimum": 0}
+	}
+}`
+
+// builtinBlockTypeDefs - 組み込みブロックタイプごとの content スキーマ
+// models.BuiltinBlockTypes と同じキーを持つ
+var builtinBlockTypeDefs = map[string]struct {
+	schema   string
+	richText bool
+	media    bool
+	// plainString - スキーマに加えて、従来形式の文字列の content もそのまま受け付ける
+	plainString bool
+	// check - スキーマでは表現できない追加の検証（スキーマの検証後に呼ばれる）
+	check func(content json.RawMessage) error
+}{
+	"text":     {schema: richTextSchema, richText: true},
+	"heading1": {schema: richTextSchema, richText: true},
+	"heading2": {schema: richTextSchema, richText: true},
+	"heading3": {schema: richTextSchema, richText: true},
+	"bullet":   {schema: richTextSchema, richText: true},
+	"numbered": {schema: richTextSchema, richText: true},
+	"quote":    {schema: richTextSchema, richText: true},
+	"toggle":   {schema: richTextSchema, richText: true}, // 見出し部分のテキスト（中身は子ブロック）
+	"code":     {schema: codeSchema, plainString: true},
+	"todo": {schema: `{
+		"type": "object",
+		"required": ["text"],
+		"properties": {
+			"text": {"type": "string"},
+			"checked": {"type": "boolean"},
+			"dueDate": {"type": "string", "format": "date"}
+		}
+	}`},
+	"table":    {schema: tableSchema, check: checkTableShape},
+	"image":    {schema: mediaSchema, media: true},
+	"file":     {schema: mediaSchema, media: true},
+	"audio":    {schema: mediaSchema, media: true}, // 音声メモなど（再生時間は durationMs）
+	"bookmark": {schema: bookmarkSchema},
+	"embed":    {schema: embedSchema, check: checkEmbedContent},
+	"math": {schema: `{
+		"type": "object",
+		"required": ["latex"],
+		"properties": {
+			"latex": {"type": "string", "maxLength": 10000}
+		}
+	}`},
+	// 同期元は POST /api/documents/{id}/blocks/{blockId}/sync で登録した syncId で参照する
+	"synced": {schema: `{
+		"type": "object",
+		"required": ["syncId"],
+		"properties": {
+			"syncId": {"type": "integer", "minimum": 1}
+		}
+	}`},
+	// 列レイアウト（中身は子ブロック。置ける子の種類は models.ValidateBlockPlacement を参照）
+	"columnList": {schema: `{"type": "object"}`},
+	"column": {schema: `{
+		"type": "object",
+		"properties": {
+			"width": {"type": "number", "minimum": 0, "maximum": 1}
+		}
+	}`},
+}
+
+// builtinBlockTypes - 解析済みの組み込みブロックタイプ（起動時に1度だけ解析する）
+var builtinBlockTypes = func() map[string]*compiledBlockType {
+	compiled := make(map[string]*compiledBlockType, len(builtinBlockTypeDefs))
+	for name, def := range builtinBlockTypeDefs {
+		schema, err := blockschema.Parse(json.RawMessage(def.schema))
+		if err != nil {
+			panic(fmt.Sprintf("invalid builtin schema for block type %s: %v", name, err))
+		}
+		compiled[name] = &compiledBlockType{
+			schema:      schema,
+			richText:    def.richText,
+			media:       def.media,
+			plainString: def.plainString,
+			check:       def.check,
+		}
+	}
+	return compiled
+}()
+
+// compiledBlockType - 解析済みのスキーマとテンプレート
+type compiledBlockType struct {
+	schema   *blockschema.Schema
+	template *template.Template
+	// richText - content の文字列をリッチテキストとしても検証する
+	richText bool
+	// media - アップロード前の空の content を許可する
+	media bool
+	// plainString - 文字列の content をそのまま受け付ける（JSON 文字列として展開しない）
+	plainString bool
+	check       func(content json.RawMessage) error
+	custom      bool
+}
+
+// BlockTypeRegistry - ブロックタイプごとの content スキーマとエクスポート用テンプレートの集合
+// 組み込みタイプと、管理者が登録したカスタムタイプの両方を扱う
+type BlockTypeRegistry struct {
+	types map[string]*compiledBlockType
+}
+
+// NewBlockTypeRegistry - 組み込みタイプのみを持つレジストリを作成
+func NewBlockTypeRegistry() *BlockTypeRegistry {
+	types := make(map[string]*compiledBlockType, len(builtinBlockTypes))
+	for name, c := range builtinBlockTypes {
+		types[name] = c
+	}
+	return &BlockTypeRegistry{types: types}
+}
+
+// Has - 指定した名前のブロックタイプが登録されているかどうか
+func (r *BlockTypeRegistry) Has(name string) bool {
+	_, ok := r.types[name]
+	return ok
+}
+
+// IsCustom - 指定した名前がカスタムブロックタイプかどうか
+func (r *BlockTypeRegistry) IsCustom(name string) bool {
+	c, ok := r.types[name]
+	return ok && c.custom
+}
+
+// IsRichText - content がリッチテキストの文字列であるブロックタイプかどうか
+func (r *BlockTypeRegistry) IsRichText(name string) bool {
+	c, ok := r.types[name]
+	return ok && c.richText
+}
+
+// Validate - ブロックの content をタイプごとのスキーマで検証
+// 失敗した場合は箇所を示す *blockschema.ValidationError を返す
+func (r *BlockTypeRegistry) Validate(block models.Block) error {
+	c, ok := r.types[block.Type]
+	if !ok {
+		return &blockschema.ValidationError{Path: "type", Message: fmt.Sprintf("unknown block type %q", block.Type)}
+	}
+	if len(block.Content) == 0 {
+		return &blockschema.ValidationError{Path: "content", Message: "is required"}
+	}
+
+	content := block.Content
+	if c.schema.Type == "object" || c.schema.Type == "array" {
+		// フロントエンドはオブジェクトの content を JSON 文字列にして送るため、中身を取り出して検証する
+		var encoded string
+		if err := json.Unmarshal(content, &encoded); err == nil {
+			if c.plainString || (c.media && strings.TrimSpace(encoded) == "") {
+				return nil
+			}
+			content = json.RawMessage(encoded)
+		}
+	}
+
+	if err := c.schema.Validate(content); err != nil {
+		return err
+	}
+	if c.check != nil {
+		return c.check(content)
+	}
+	return nil
+}
+
+// checkTableShape - 表のすべての行が同じ列数であることを確認
+func checkTableShape(content json.RawMessage) error {
+	var table models.TableContent
+	if err := json.Unmarshal(content, &table); err != nil {
+		return &blockschema.ValidationError{Path: "content", Message: "invalid JSON"}
+	}
+	columns := len(table.Rows[0])
+	for i, row := range table.Rows {
+		if len(row) != columns {
+			return &blockschema.ValidationError{
+				Path:    fmt.Sprintf("content.rows[%d]", i),
+				Message: fmt.Sprintf("must have %d cells like the first row", columns),
+			}
+		}
+	}
+	return nil
+}
+
+// Export - カスタムタイプのテンプレートでブロックを描画
+// カスタムタイプでない、またはテンプレートが未設定の場合は ok=false を返す
+func (r *BlockTypeRegistry) Export(block models.Block) (output string, ok bool, err error) {
+	c, found := r.types[block.Type]
+	if !found || c.template == nil {
+		return "", false, nil
+	}
+
+	var content interface{}
+	if err := json.Unmarshal(block.Content, &content); err != nil {
+		return "", false, fmt.Errorf("failed to decode block content: %w", err)
+	}
+
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Type":     block.Type,
+		"Position": block.Position,
+		"Content":  content,
+	}
+	if err := c.template.Execute(&buf, data); err != nil {
+		return "", false, fmt.Errorf("failed to render block type %s: %w", block.Type, err)
+	}
+
+	return buf.String(), true, nil
+}
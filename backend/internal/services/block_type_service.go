@@ -0,0 +1,109 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/blockschema"
+	"simple-notion-backend/internal/models"
+)
+
+// blockTypeNamePattern - カスタムブロックタイプ名の形式（blocks.type の長さ上限に合わせる）
+var blockTypeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// BlockTypeService - 管理者が登録するカスタムブロックタイプを扱うサービス
+type BlockTypeService struct {
+	blockTypeRepo CustomBlockTypeRepositoryInterface
+}
+
+// NewBlockTypeService - BlockTypeServiceを初期化
+func NewBlockTypeService(blockTypeRepo CustomBlockTypeRepositoryInterface) *BlockTypeService {
+	return &BlockTypeService{
+		blockTypeRepo: blockTypeRepo,
+	}
+}
+
+// ListBlockTypes - 登録済みのカスタムブロックタイプ一覧を取得
+func (s *BlockTypeService) ListBlockTypes() ([]models.CustomBlockType, error) {
+	return s.blockTypeRepo.ListBlockTypes()
+}
+
+// RegisterBlockType - カスタムブロックタイプを登録
+// 組み込みタイプと同じ名前や、解析できないスキーマ・テンプレートは ValidationError を返す
+func (s *BlockTypeService) RegisterBlockType(userID int, bt *models.CustomBlockType) error {
+	bt.Name = strings.TrimSpace(bt.Name)
+	if !blockTypeNamePattern.MatchString(bt.Name) {
+		return apierror.NewValidationError("INVALID_BLOCK_TYPE_NAME",
+			"ブロックタイプ名は英小文字で始まる2〜50文字（英小文字・数字・_・-）で指定してください", nil)
+	}
+	if models.BuiltinBlockTypes[bt.Name] {
+		return apierror.NewConflict("BUILTIN_BLOCK_TYPE",
+			fmt.Sprintf("%s は組み込みのブロックタイプです", bt.Name), nil)
+	}
+	if _, err := compileBlockType(bt); err != nil {
+		return err
+	}
+
+	bt.CreatedBy = userID
+	return s.blockTypeRepo.CreateBlockType(bt)
+}
+
+// UpdateBlockType - カスタムブロックタイプのスキーマ・テンプレートを更新
+func (s *BlockTypeService) UpdateBlockType(bt *models.CustomBlockType) error {
+	if _, err := compileBlockType(bt); err != nil {
+		return err
+	}
+	return s.blockTypeRepo.UpdateBlockType(bt)
+}
+
+// DeleteBlockType - カスタムブロックタイプを削除
+func (s *BlockTypeService) DeleteBlockType(name string) error {
+	return s.blockTypeRepo.DeleteBlockType(name)
+}
+
+// LoadRegistry - 組み込みタイプに登録済みのカスタムブロックタイプを加えたレジストリを読み込む
+// 保存やエクスポートのリクエストごとに1回呼び出す想定
+func (s *BlockTypeService) LoadRegistry() (*BlockTypeRegistry, error) {
+	types, err := s.blockTypeRepo.ListBlockTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom block types: %w", err)
+	}
+
+	registry := NewBlockTypeRegistry()
+	for i := range types {
+		c, err := compileBlockType(&types[i])
+		if err != nil {
+			// 登録時に検証済みのため通常は起こらない
+			return nil, fmt.Errorf("custom block type %s: %w", types[i].Name, err)
+		}
+		registry.types[types[i].Name] = c
+	}
+
+	return registry, nil
+}
+
+// compileBlockType - スキーマとエクスポート用テンプレートを解析
+func compileBlockType(bt *models.CustomBlockType) (*compiledBlockType, error) {
+	if len(bt.Schema) == 0 {
+		bt.Schema = json.RawMessage(`{}`)
+	}
+	schema, err := blockschema.Parse(bt.Schema)
+	if err != nil {
+		return nil, apierror.NewValidationError("INVALID_BLOCK_SCHEMA", "スキーマの形式が不正です", err)
+	}
+
+	compiled := &compiledBlockType{schema: schema, custom: true}
+	if bt.ExportTemplate != "" {
+		tmpl, err := template.New(bt.Name).Option("missingkey=zero").Parse(bt.ExportTemplate)
+		if err != nil {
+			return nil, apierror.NewValidationError("INVALID_EXPORT_TEMPLATE", "エクスポート用テンプレートの形式が不正です", err)
+		}
+		compiled.template = tmpl
+	}
+
+	return compiled, nil
+}
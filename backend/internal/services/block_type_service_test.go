@@ -0,0 +1,225 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/blockschema"
+	"simple-notion-backend/internal/models"
+)
+
+// MockCustomBlockTypeRepository - CustomBlockTypeRepositoryのモック
+type MockCustomBlockTypeRepository struct {
+	ListBlockTypesFunc  func() ([]models.CustomBlockType, error)
+	CreateBlockTypeFunc func(bt *models.CustomBlockType) error
+	UpdateBlockTypeFunc func(bt *models.CustomBlockType) error
+	DeleteBlockTypeFunc func(name string) error
+}
+
+func (m *MockCustomBlockTypeRepository) ListBlockTypes() ([]models.CustomBlockType, error) {
+	if m.ListBlockTypesFunc != nil {
+		return m.ListBlockTypesFunc()
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockCustomBlockTypeRepository) CreateBlockType(bt *models.CustomBlockType) error {
+	if m.CreateBlockTypeFunc != nil {
+		return m.CreateBlockTypeFunc(bt)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockCustomBlockTypeRepository) UpdateBlockType(bt *models.CustomBlockType) error {
+	if m.UpdateBlockTypeFunc != nil {
+		return m.UpdateBlockTypeFunc(bt)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockCustomBlockTypeRepository) DeleteBlockType(name string) error {
+	if m.DeleteBlockTypeFunc != nil {
+		return m.DeleteBlockTypeFunc(name)
+	}
+	return errors.New("not implemented")
+}
+
+// TestRegisterBlockType - カスタムブロックタイプ登録のテスト
+func TestRegisterBlockType(t *testing.T) {
+	tests := []struct {
+		name       string
+		blockType  models.CustomBlockType
+		wantStatus int
+	}{
+		{
+			name: "正常系：スキーマとテンプレート付きで登録",
+			blockType: models.CustomBlockType{
+				Name:           "callout",
+				Schema:         json.RawMessage(`{"type":"object","required":["text"]}`),
+				ExportTemplate: `> **{{.Content.icon}}** {{.Content.text}}`,
+			},
+		},
+		{
+			name:       "異常系：組み込みタイプと同名 → 409",
+			blockType:  models.CustomBlockType{Name: "text"},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "異常系：名前の形式が不正 → 400",
+			blockType:  models.CustomBlockType{Name: "Call Out"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "異常系：スキーマが不正 → 400",
+			blockType:  models.CustomBlockType{Name: "callout", Schema: json.RawMessage(`{"type":"date"}`)},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "異常系：テンプレートが不正 → 400",
+			blockType:  models.CustomBlockType{Name: "callout", ExportTemplate: `{{.Content.text`},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *models.CustomBlockType
+			repo := &MockCustomBlockTypeRepository{
+				CreateBlockTypeFunc: func(bt *models.CustomBlockType) error {
+					created = bt
+					return nil
+				},
+			}
+			service := NewBlockTypeService(repo)
+
+			bt := tt.blockType
+			err := service.RegisterBlockType(7, &bt)
+
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("RegisterBlockType() error = %v", err)
+				}
+				if created == nil || created.CreatedBy != 7 {
+					t.Errorf("RegisterBlockType() created = %+v, want created_by=7", created)
+				}
+				return
+			}
+
+			var appErr *apierror.AppError
+			if !errors.As(err, &appErr) || appErr.HTTPStatus != tt.wantStatus {
+				t.Errorf("RegisterBlockType() error = %v, want status %d", err, tt.wantStatus)
+			}
+			if created != nil {
+				t.Error("RegisterBlockType() should not save an invalid block type")
+			}
+		})
+	}
+}
+
+// TestBlockTypeRegistry - 組み込みタイプと読み込んだカスタムタイプによる検証とエクスポートのテスト
+func TestBlockTypeRegistry(t *testing.T) {
+	repo := &MockCustomBlockTypeRepository{
+		ListBlockTypesFunc: func() ([]models.CustomBlockType, error) {
+			return []models.CustomBlockType{
+				{
+					Name:           "callout",
+					Schema:         json.RawMessage(`{"type":"object","required":["text"],"properties":{"text":{"type":"string"}}}`),
+					ExportTemplate: `> {{.Content.icon}} {{.Content.text}}`,
+				},
+				{Name: "raw"},
+			}, nil
+		},
+	}
+	registry, err := NewBlockTypeService(repo).LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+
+	t.Run("組み込みタイプはすべてスキーマを持つ", func(t *testing.T) {
+		for name := range models.BuiltinBlockTypes {
+			if !registry.Has(name) || registry.IsCustom(name) {
+				t.Errorf("builtin block type %s should be registered as builtin", name)
+			}
+		}
+		if !registry.IsCustom("callout") {
+			t.Error("IsCustom() should report registered custom types")
+		}
+	})
+
+	validateTests := []struct {
+		name     string
+		block    models.Block
+		wantPath string
+	}{
+		{name: "テキスト", block: models.Block{Type: "text", Content: json.RawMessage(`"hello"`)}},
+		{name: "テキストに文字列以外", block: models.Block{Type: "heading1", Content: json.RawMessage(`123`)}, wantPath: "content"},
+		{name: "画像（オブジェクト）", block: models.Block{Type: "image", Content: json.RawMessage(`{"src":"/api/uploads/a.png","width":120}`)}},
+		{name: "画像（JSON文字列）", block: models.Block{Type: "image", Content: json.RawMessage(`"{\"src\":\"/api/uploads/a.png\"}"`)}},
+		{name: "アップロード前の画像", block: models.Block{Type: "image", Content: json.RawMessage(`""`)}},
+		{name: "画像の src 欠落", block: models.Block{Type: "image", Content: json.RawMessage(`{"alt":"x"}`)}, wantPath: "content.src"},
+		{name: "ファイルサイズが整数でない", block: models.Block{Type: "file", Content: json.RawMessage(`{"src":"/f","fileSize":"1MB"}`)}, wantPath: "content.fileSize"},
+		{name: "ToDo", block: models.Block{Type: "todo", Content: json.RawMessage(`{"text":"買い物","checked":true}`)}},
+		{name: "ToDo の期限", block: models.Block{Type: "todo", Content: json.RawMessage(`{"text":"提出","dueDate":"2026-10-31"}`)}},
+		{name: "ToDo の期限が日付でない", block: models.Block{Type: "todo", Content: json.RawMessage(`{"text":"提出","dueDate":"来週"}`)}, wantPath: "content.dueDate"},
+		{name: "ToDo の checked が真偽値でない", block: models.Block{Type: "todo", Content: json.RawMessage(`{"text":"a","checked":"yes"}`)}, wantPath: "content.checked"},
+		{name: "コード（言語あり）", block: models.Block{Type: "code", Content: json.RawMessage(`{"language":"go","content":"fmt.Println(1)"}`)}},
+		{name: "コード（従来の文字列）", block: models.Block{Type: "code", Content: json.RawMessage(`"SELECT 1;"`)}},
+		{name: "コードの本文欠落", block: models.Block{Type: "code", Content: json.RawMessage(`{"language":"go"}`)}, wantPath: "content.content"},
+		{name: "表", block: models.Block{Type: "table", Content: json.RawMessage(`{"header":true,"rows":[["名前","数"],["a","1"]]}`)}},
+		{name: "表の列数が揃っていない", block: models.Block{Type: "table", Content: json.RawMessage(`{"rows":[["a","b"],["c"]]}`)}, wantPath: "content.rows[1]"},
+		{name: "表のセルが文字列でない", block: models.Block{Type: "table", Content: json.RawMessage(`{"rows":[["a",1]]}`)}, wantPath: "content.rows[0][1]"},
+		{name: "空の表", block: models.Block{Type: "table", Content: json.RawMessage(`{"rows":[]}`)}, wantPath: "content.rows"},
+		{name: "カスタムタイプのスキーマ違反", block: models.Block{Type: "callout", Content: json.RawMessage(`{"icon":"!"}`)}, wantPath: "content.text"},
+		{name: "未登録のタイプ", block: models.Block{Type: "unknown", Content: json.RawMessage(`"x"`)}, wantPath: "type"},
+		{name: "content 欠落", block: models.Block{Type: "text"}, wantPath: "content"},
+	}
+	for _, tt := range validateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Validate(tt.block)
+			if tt.wantPath == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var verr *blockschema.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %v, want *blockschema.ValidationError", err)
+			}
+			if verr.Path != tt.wantPath {
+				t.Errorf("Validate() path = %q, want %q", verr.Path, tt.wantPath)
+			}
+		})
+	}
+
+	t.Run("リッチテキストの判定", func(t *testing.T) {
+		if !registry.IsRichText("quote") || registry.IsRichText("code") || registry.IsRichText("callout") {
+			t.Error("IsRichText() should only report text-like builtin types")
+		}
+	})
+
+	t.Run("テンプレートでエクスポート", func(t *testing.T) {
+		block := models.Block{Type: "callout", Content: json.RawMessage(`{"icon":"💡","text":"メモ"}`)}
+		out, ok, err := registry.Export(block)
+		if err != nil || !ok {
+			t.Fatalf("Export() ok = %v, error = %v", ok, err)
+		}
+		if out != "> 💡 メモ" {
+			t.Errorf("Export() = %q, want %q", out, "> 💡 メモ")
+		}
+	})
+
+	t.Run("テンプレート未設定は既定の出力に任せる", func(t *testing.T) {
+		for _, block := range []models.Block{
+			{Type: "raw", Content: json.RawMessage(`{}`)},
+			{Type: "text", Content: json.RawMessage(`"hello"`)},
+		} {
+			if _, ok, err := registry.Export(block); err != nil || ok {
+				t.Errorf("Export(%s) ok = %v, error = %v, want ok=false", block.Type, ok, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+)
+
+// CollaboratorService - 文書の共同編集者の追加・役割の変更・削除と、文書に対する役割の確認を担当するサービス
+// 共同編集者の管理は文書の所有者のみができる（共同編集者は自分自身を削除して抜けることだけができる）
+// 権限はリクエストごとに DocumentRole で確認するため、変更は次のリクエストから反映される
+type CollaboratorService struct {
+	documentRepo     DocumentCoreRepositoryInterface
+	collaboratorRepo CollaboratorRepositoryInterface
+	userRepo         UserRepositoryInterface
+	events           *DocumentEventBroker // nil の場合は変更を通知しない
+}
+
+// NewCollaboratorService - CollaboratorServiceを初期化
+func NewCollaboratorService(
+	documentRepo DocumentCoreRepositoryInterface,
+	collaboratorRepo CollaboratorRepositoryInterface,
+	userRepo UserRepositoryInterface,
+	events *DocumentEventBroker,
+) *CollaboratorService {
+	return &CollaboratorService{
+		documentRepo:     documentRepo,
+		collaboratorRepo: collaboratorRepo,
+		userRepo:         userRepo,
+		events:           events,
+	}
+}
+
+// ListCollaborators - 文書の所有者と共同編集者の一覧を取得（文書にアクセスできるユーザーなら取得できる）
+func (s *CollaboratorService) ListCollaborators(docID, userID int) ([]models.DocumentCollaborator, error) {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	return s.collaboratorRepo.ListCollaborators(docID)
+}
+
+// AddCollaborator - メールアドレスで指定したユーザーを共同編集者に追加（role は editor・viewer、省略時 viewer）
+func (s *CollaboratorService) AddCollaborator(ctx context.Context, docID, userID int, email string, role models.DocumentRole) (*models.DocumentCollaborator, error) {
+	if role == "" {
+		role = models.DocumentRoleViewer
+	}
+	if !role.ValidCollaborator() {
+		return nil, invalidCollaboratorRole()
+	}
+
+	doc, err := s.ownedDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return nil, apierror.NewNotFound("USER_NOT_FOUND", "指定したメールアドレスのユーザーが見つかりません", err)
+		}
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+	if user.ID == doc.UserID {
+		return nil, apierror.NewValidationError("CANNOT_ADD_OWNER", "文書の所有者は共同編集者に追加できません", nil)
+	}
+
+	addedBy := userID
+	collaborator := &models.DocumentCollaborator{
+		DocumentID: docID,
+		UserID:     user.ID,
+		Email:      user.Email,
+		Name:       user.Name,
+		Role:       role,
+		AddedBy:    &addedBy,
+	}
+	if err := s.collaboratorRepo.AddCollaborator(collaborator); err != nil {
+		if errors.Is(err, apierror.ErrConflict) {
+			return nil, apierror.NewConflict("ALREADY_DOCUMENT_COLLABORATOR", "既に文書の共同編集者です", err)
+		}
+		return nil, err
+	}
+
+	s.publishAccessChanged(ctx, doc, user.ID, false)
+	return collaborator, nil
+}
+
+// UpdateCollaboratorRole - 共同編集者の役割を editor / viewer に変更する（所有者の役割は変更できない）
+func (s *CollaboratorService) UpdateCollaboratorRole(ctx context.Context, docID, userID, collaboratorID int, role models.DocumentRole) error {
+	if !role.ValidCollaborator() {
+		return invalidCollaboratorRole()
+	}
+
+	doc, err := s.ownedDocument(docID, userID)
+	if err != nil {
+		return err
+	}
+	if collaboratorID == doc.UserID {
+		return apierror.NewValidationError("CANNOT_CHANGE_OWNER_ROLE", "所有者の役割は変更できません", nil)
+	}
+
+	if err := s.collaboratorRepo.UpdateCollaboratorRole(docID, collaboratorID, role); err != nil {
+		return collaboratorNotFound(err)
+	}
+
+	s.publishAccessChanged(ctx, doc, collaboratorID, false)
+	return nil
+}
+
+// RemoveCollaborator - 共同編集者を削除する（自分自身の場合は文書から抜ける。所有者は削除できない）
+func (s *CollaboratorService) RemoveCollaborator(ctx context.Context, docID, userID, collaboratorID int) error {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return err
+	}
+	if collaboratorID == doc.UserID {
+		return apierror.NewValidationError("CANNOT_REMOVE_OWNER", "文書の所有者は削除できません", nil)
+	}
+	if userID != doc.UserID && userID != collaboratorID {
+		return documentOwnerRequired()
+	}
+
+	if err := s.collaboratorRepo.RemoveCollaborator(docID, collaboratorID); err != nil {
+		return collaboratorNotFound(err)
+	}
+
+	s.publishAccessChanged(ctx, doc, collaboratorID, true)
+	return nil
+}
+
+// DocumentRole - ユーザーの文書に対する役割を返す（アクセスできない場合は空文字。権限の確認用）
+func (s *CollaboratorService) DocumentRole(docID, userID int) (models.DocumentRole, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	if doc.UserID == userID {
+		return models.DocumentRoleOwner, nil
+	}
+	return doc.Role, nil
+}
+
+// ownedDocument - 文書を取得し、所有者でない場合は Forbidden を返す
+func (s *CollaboratorService) ownedDocument(docID, userID int) (*models.Document, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.UserID != userID {
+		return nil, documentOwnerRequired()
+	}
+	return doc, nil
+}
+
+// publishAccessChanged - 共同編集者の変更を、文書の所有者・共同編集者と変更されたユーザーの開いているタブに通知する
+// 受け取ったユーザーごとに変更後の役割を Role に設定し、アクセスできなくなったユーザーには Revoked を送る
+func (s *CollaboratorService) publishAccessChanged(ctx context.Context, doc *models.Document, changedUserID int, revoked bool) {
+	if s.events == nil {
+		return
+	}
+
+	collaborators, err := s.collaboratorRepo.ListCollaborators(doc.ID)
+	if err != nil {
+		logctx.Printf(ctx, "Failed to list collaborators of document %d for access event: %v", doc.ID, err)
+		return
+	}
+
+	now := time.Now()
+	for _, collaborator := range collaborators {
+		s.events.Publish(collaborator.UserID, models.DocumentEvent{
+			Type:       models.DocumentEventAccess,
+			DocumentID: doc.ID,
+			Role:       collaborator.Role,
+			OccurredAt: now,
+		})
+	}
+	if revoked {
+		s.events.Publish(changedUserID, models.DocumentEvent{
+			Type:       models.DocumentEventAccess,
+			DocumentID: doc.ID,
+			Revoked:    true,
+			OccurredAt: now,
+		})
+	}
+}
+
+// invalidCollaboratorRole - 共同編集者に設定できない役割を指定された場合のエラー
+func invalidCollaboratorRole() error {
+	return apierror.NewValidationError("INVALID_DOCUMENT_ROLE", "role には editor・viewer のいずれかを指定してください", nil)
+}
+
+// documentOwnerRequired - 所有者のみができる操作を所有者以外が行った場合のエラー
+func documentOwnerRequired() error {
+	return apierror.NewForbidden("DOCUMENT_OWNER_REQUIRED", "文書の所有者のみが共同編集者を管理できます", nil)
+}
+
+// collaboratorNotFound - 対象のユーザーが共同編集者でない場合のエラーに変換する
+func collaboratorNotFound(err error) error {
+	if errors.Is(err, apierror.ErrNotFound) {
+		return apierror.NewNotFound("COLLABORATOR_NOT_FOUND", "指定したユーザーは文書の共同編集者ではありません", err)
+	}
+	return err
+}
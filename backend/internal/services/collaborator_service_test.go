@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// MockCollaboratorRepository - CollaboratorRepositoryのモック
+type MockCollaboratorRepository struct {
+	ListCollaboratorsFunc      func(docID int) ([]models.DocumentCollaborator, error)
+	GetCollaboratorRoleFunc    func(docID, userID int) (models.DocumentRole, error)
+	AddCollaboratorFunc        func(collaborator *models.DocumentCollaborator) error
+	UpdateCollaboratorRoleFunc func(docID, userID int, role models.DocumentRole) error
+	RemoveCollaboratorFunc     func(docID, userID int) error
+}
+
+func (m *MockCollaboratorRepository) ListCollaborators(docID int) ([]models.DocumentCollaborator, error) {
+	if m.ListCollaboratorsFunc != nil {
+		return m.ListCollaboratorsFunc(docID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockCollaboratorRepository) GetCollaboratorRole(docID, userID int) (models.DocumentRole, error) {
+	if m.GetCollaboratorRoleFunc != nil {
+		return m.GetCollaboratorRoleFunc(docID, userID)
+	}
+	return "", errors.New("not implemented")
+}
+
+func (m *MockCollaboratorRepository) AddCollaborator(collaborator *models.DocumentCollaborator) error {
+	if m.AddCollaboratorFunc != nil {
+		return m.AddCollaboratorFunc(collaborator)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockCollaboratorRepository) UpdateCollaboratorRole(docID, userID int, role models.DocumentRole) error {
+	if m.UpdateCollaboratorRoleFunc != nil {
+		return m.UpdateCollaboratorRoleFunc(docID, userID, role)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockCollaboratorRepository) RemoveCollaborator(docID, userID int) error {
+	if m.RemoveCollaboratorFunc != nil {
+		return m.RemoveCollaboratorFunc(docID, userID)
+	}
+	return errors.New("not implemented")
+}
+
+// sharedDocumentRepo - ユーザー10が所有し、roles のユーザーが共同編集者の文書を返すモック
+func sharedDocumentRepo(roles map[int]models.DocumentRole) *MockDocumentCoreRepository {
+	return &MockDocumentCoreRepository{
+		GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+			if userID == 10 {
+				return &models.Document{ID: docID, UserID: 10, Role: models.DocumentRoleOwner}, nil
+			}
+			if role, ok := roles[userID]; ok {
+				return &models.Document{ID: docID, UserID: 10, Role: role}, nil
+			}
+			return nil, fmt.Errorf("document id=%d user=%d: %w", docID, userID, apierror.ErrNotFound)
+		},
+	}
+}
+
+func TestCollaboratorService_AddCollaborator(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     int
+		email      string
+		role       models.DocumentRole
+		addErr     error
+		wantRole   models.DocumentRole
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "正常系：editor として追加", userID: 10, email: "bob@example.com", role: models.DocumentRoleEditor, wantRole: models.DocumentRoleEditor},
+		{name: "正常系：役割の省略時は viewer", userID: 10, email: " bob@example.com ", wantRole: models.DocumentRoleViewer},
+		{name: "異常系：owner は指定できない", userID: 10, email: "bob@example.com", role: models.DocumentRoleOwner, wantStatus: http.StatusBadRequest, wantCode: "INVALID_DOCUMENT_ROLE"},
+		{name: "異常系：共同編集者は追加できない", userID: 11, email: "bob@example.com", wantStatus: http.StatusForbidden, wantCode: "DOCUMENT_OWNER_REQUIRED"},
+		{name: "異常系：存在しないユーザー", userID: 10, email: "nobody@example.com", wantStatus: http.StatusNotFound, wantCode: "USER_NOT_FOUND"},
+		{name: "異常系：所有者自身", userID: 10, email: "owner@example.com", wantStatus: http.StatusBadRequest, wantCode: "CANNOT_ADD_OWNER"},
+		{name: "異常系：既に共同編集者", userID: 10, email: "bob@example.com", addErr: apierror.ErrConflict, wantStatus: http.StatusConflict, wantCode: "ALREADY_DOCUMENT_COLLABORATOR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := map[string]*models.User{
+				"owner@example.com": {ID: 10, Email: "owner@example.com"},
+				"bob@example.com":   {ID: 12, Email: "bob@example.com", Name: "Bob"},
+			}
+			userRepo := &MockUserRepository{
+				GetByEmailFunc: func(email string) (*models.User, error) {
+					if user, ok := users[email]; ok {
+						return user, nil
+					}
+					return nil, apierror.ErrNotFound
+				},
+			}
+			var added *models.DocumentCollaborator
+			collaboratorRepo := &MockCollaboratorRepository{
+				AddCollaboratorFunc: func(collaborator *models.DocumentCollaborator) error {
+					if tt.addErr != nil {
+						return tt.addErr
+					}
+					added = collaborator
+					return nil
+				},
+			}
+			service := NewCollaboratorService(sharedDocumentRepo(map[int]models.DocumentRole{11: models.DocumentRoleEditor}),
+				collaboratorRepo, userRepo, nil)
+
+			collaborator, err := service.AddCollaborator(context.Background(), 2, tt.userID, tt.email, tt.role)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddCollaborator() error = %v", err)
+			}
+			if added == nil || added.DocumentID != 2 || added.UserID != 12 || added.Role != tt.wantRole {
+				t.Errorf("added = %+v, want document 2 user 12 role %s", added, tt.wantRole)
+			}
+			if collaborator.Name != "Bob" || added.AddedBy == nil || *added.AddedBy != 10 {
+				t.Errorf("collaborator = %+v", collaborator)
+			}
+		})
+	}
+}
+
+func TestCollaboratorService_UpdateCollaboratorRole(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         int
+		collaboratorID int
+		role           models.DocumentRole
+		updateErr      error
+		wantStatus     int
+		wantCode       string
+	}{
+		{name: "正常系：viewer に変更", userID: 10, collaboratorID: 11, role: models.DocumentRoleViewer},
+		{name: "異常系：不正な役割", userID: 10, collaboratorID: 11, role: "admin", wantStatus: http.StatusBadRequest, wantCode: "INVALID_DOCUMENT_ROLE"},
+		{name: "異常系：所有者の役割", userID: 10, collaboratorID: 10, role: models.DocumentRoleViewer, wantStatus: http.StatusBadRequest, wantCode: "CANNOT_CHANGE_OWNER_ROLE"},
+		{name: "異常系：editor は変更できない", userID: 11, collaboratorID: 12, role: models.DocumentRoleEditor, wantStatus: http.StatusForbidden, wantCode: "DOCUMENT_OWNER_REQUIRED"},
+		{name: "異常系：共同編集者でないユーザー", userID: 10, collaboratorID: 99, role: models.DocumentRoleEditor, updateErr: apierror.ErrNotFound, wantStatus: http.StatusNotFound, wantCode: "COLLABORATOR_NOT_FOUND"},
+		{name: "異常系：アクセスできない文書", userID: 99, collaboratorID: 11, role: models.DocumentRoleViewer, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated models.DocumentRole
+			collaboratorRepo := &MockCollaboratorRepository{
+				UpdateCollaboratorRoleFunc: func(docID, userID int, role models.DocumentRole) error {
+					if tt.updateErr != nil {
+						return tt.updateErr
+					}
+					updated = role
+					return nil
+				},
+			}
+			service := NewCollaboratorService(sharedDocumentRepo(map[int]models.DocumentRole{11: models.DocumentRoleEditor}),
+				collaboratorRepo, &MockUserRepository{}, nil)
+
+			err := service.UpdateCollaboratorRole(context.Background(), 2, tt.userID, tt.collaboratorID, tt.role)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				return
+			}
+			if tt.wantStatus != 0 {
+				if !errors.Is(err, apierror.ErrNotFound) {
+					t.Fatalf("err = %v, want ErrNotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateCollaboratorRole() error = %v", err)
+			}
+			if updated != tt.role {
+				t.Errorf("updated role = %s, want %s", updated, tt.role)
+			}
+		})
+	}
+}
+
+func TestCollaboratorService_RemoveCollaborator(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         int
+		collaboratorID int
+		wantStatus     int
+		wantCode       string
+	}{
+		{name: "正常系：所有者が削除", userID: 10, collaboratorID: 11},
+		{name: "正常系：共同編集者が自分で抜ける", userID: 12, collaboratorID: 12},
+		{name: "異常系：他の共同編集者は削除できない", userID: 12, collaboratorID: 11, wantStatus: http.StatusForbidden, wantCode: "DOCUMENT_OWNER_REQUIRED"},
+		{name: "異常系：所有者は削除できない", userID: 10, collaboratorID: 10, wantStatus: http.StatusBadRequest, wantCode: "CANNOT_REMOVE_OWNER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := 0
+			collaboratorRepo := &MockCollaboratorRepository{
+				RemoveCollaboratorFunc: func(docID, userID int) error {
+					removed = userID
+					return nil
+				},
+			}
+			roles := map[int]models.DocumentRole{11: models.DocumentRoleEditor, 12: models.DocumentRoleViewer}
+			service := NewCollaboratorService(sharedDocumentRepo(roles), collaboratorRepo, &MockUserRepository{}, nil)
+
+			err := service.RemoveCollaborator(context.Background(), 2, tt.userID, tt.collaboratorID)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				if removed != 0 {
+					t.Error("collaborator should not be removed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RemoveCollaborator() error = %v", err)
+			}
+			if removed != tt.collaboratorID {
+				t.Errorf("removed = %d, want %d", removed, tt.collaboratorID)
+			}
+		})
+	}
+}
+
+func TestCollaboratorService_DocumentRole(t *testing.T) {
+	roles := map[int]models.DocumentRole{11: models.DocumentRoleEditor, 12: models.DocumentRoleViewer}
+	service := NewCollaboratorService(sharedDocumentRepo(roles), &MockCollaboratorRepository{}, &MockUserRepository{}, nil)
+
+	tests := []struct {
+		name   string
+		userID int
+		want   models.DocumentRole
+	}{
+		{name: "正常系：所有者", userID: 10, want: models.DocumentRoleOwner},
+		{name: "正常系：editor", userID: 11, want: models.DocumentRoleEditor},
+		{name: "正常系：viewer", userID: 12, want: models.DocumentRoleViewer},
+		{name: "正常系：アクセスできないユーザーは空文字", userID: 99, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, err := service.DocumentRole(2, tt.userID)
+			if err != nil {
+				t.Fatalf("DocumentRole() error = %v", err)
+			}
+			if role != tt.want {
+				t.Errorf("role = %q, want %q", role, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollaboratorService_PublishAccessChanged(t *testing.T) {
+	t.Run("正常系：役割の変更を所有者と共同編集者に、削除を削除されたユーザーに通知する", func(t *testing.T) {
+		broker := NewDocumentEventBroker()
+		ownerEvents, unsubscribeOwner := broker.Subscribe(10)
+		defer unsubscribeOwner()
+		editorEvents, unsubscribeEditor := broker.Subscribe(11)
+		defer unsubscribeEditor()
+		removedEvents, unsubscribeRemoved := broker.Subscribe(12)
+		defer unsubscribeRemoved()
+
+		collaboratorRepo := &MockCollaboratorRepository{
+			RemoveCollaboratorFunc: func(docID, userID int) error { return nil },
+			ListCollaboratorsFunc: func(docID int) ([]models.DocumentCollaborator, error) {
+				return []models.DocumentCollaborator{
+					{DocumentID: docID, UserID: 10, Role: models.DocumentRoleOwner},
+					{DocumentID: docID, UserID: 11, Role: models.DocumentRoleEditor},
+				}, nil
+			},
+		}
+		roles := map[int]models.DocumentRole{11: models.DocumentRoleEditor, 12: models.DocumentRoleViewer}
+		service := NewCollaboratorService(sharedDocumentRepo(roles), collaboratorRepo, &MockUserRepository{}, broker)
+
+		if err := service.RemoveCollaborator(context.Background(), 2, 10, 12); err != nil {
+			t.Fatalf("RemoveCollaborator() error = %v", err)
+		}
+
+		for userID, ch := range map[int]<-chan models.DocumentEvent{10: ownerEvents, 11: editorEvents} {
+			select {
+			case event := <-ch:
+				if event.Type != models.DocumentEventAccess || event.DocumentID != 2 || event.Revoked {
+					t.Errorf("user %d event = %+v", userID, event)
+				}
+			default:
+				t.Errorf("user %d should receive access event", userID)
+			}
+		}
+		select {
+		case event := <-removedEvents:
+			if event.Type != models.DocumentEventAccess || !event.Revoked || event.Role != "" {
+				t.Errorf("removed user event = %+v", event)
+			}
+		default:
+			t.Error("removed user should receive revoked event")
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// DocumentCommentService - 文書全体へのコメントのスレッドを担当するサービス
+// 返信は1階層のみで、返信への返信はスレッドの先頭のコメントへの返信として保存する
+type DocumentCommentService struct {
+	documentRepo DocumentCoreRepositoryInterface
+	commentRepo  DocumentCommentRepositoryInterface
+}
+
+// NewDocumentCommentService - DocumentCommentServiceを初期化
+func NewDocumentCommentService(
+	documentRepo DocumentCoreRepositoryInterface,
+	commentRepo DocumentCommentRepositoryInterface,
+) *DocumentCommentService {
+	return &DocumentCommentService{
+		documentRepo: documentRepo,
+		commentRepo:  commentRepo,
+	}
+}
+
+// ListThreads - 文書のスレッド一覧を作成日時順に取得（resolved を指定した場合はスレッドの解決状態で絞り込む）
+func (s *DocumentCommentService) ListThreads(docID, userID int, resolved *bool) ([]models.DocumentComment, error) {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.ListComments(docID)
+	if err != nil {
+		return nil, err
+	}
+
+	threads := buildCommentThreads(comments)
+	if resolved == nil {
+		return threads, nil
+	}
+	filtered := make([]models.DocumentComment, 0, len(threads))
+	for _, thread := range threads {
+		if thread.Resolved == *resolved {
+			filtered = append(filtered, thread)
+		}
+	}
+	return filtered, nil
+}
+
+// CreateThread - 文書に新しいスレッドを作成
+func (s *DocumentCommentService) CreateThread(docID, userID int, body string) (*models.DocumentComment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.createComment(docID, userID, nil, body)
+}
+
+// Reply - コメントに返信（返信への返信はスレッドの先頭への返信になる）
+func (s *DocumentCommentService) Reply(docID, userID, commentID int, body string) (*models.DocumentComment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+
+	parent, err := s.commentRepo.GetComment(docID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	rootID := parent.ID
+	if parent.ParentID != nil {
+		rootID = *parent.ParentID
+	}
+
+	return s.createComment(docID, userID, &rootID, body)
+}
+
+// UpdateComment - コメントの本文・スレッドの解決状態を更新し、更新後のコメントを返す
+// 本文は投稿者のみ変更でき、解決状態はスレッドの先頭のコメントに対してのみ変更できる
+func (s *DocumentCommentService) UpdateComment(docID, userID, commentID int, patch models.DocumentCommentPatch) (*models.DocumentComment, error) {
+	if patch.Body == nil && patch.Resolved == nil {
+		return nil, apierror.NewValidationError("INVALID_REQUEST", "body または resolved を指定してください", nil)
+	}
+
+	var body string
+	if patch.Body != nil {
+		var err error
+		if body, err = validateCommentBody(*patch.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+
+	if patch.Resolved != nil {
+		comment, err := s.commentRepo.GetComment(docID, commentID)
+		if err != nil {
+			return nil, err
+		}
+		if comment.ParentID != nil {
+			return nil, apierror.NewValidationError("REPLY_NOT_RESOLVABLE", "解決状態はスレッドの先頭のコメントで変更してください", nil)
+		}
+	}
+
+	if patch.Body != nil {
+		if err := s.commentRepo.UpdateCommentBody(docID, commentID, userID, body); err != nil {
+			return nil, err
+		}
+	}
+	if patch.Resolved != nil {
+		if err := s.commentRepo.SetCommentResolved(docID, commentID, userID, *patch.Resolved); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.commentRepo.GetComment(docID, commentID)
+}
+
+// DeleteComment - コメントを削除（投稿者のみ。スレッドの先頭を削除すると返信も削除される）
+func (s *DocumentCommentService) DeleteComment(docID, userID, commentID int) error {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return err
+	}
+	return s.commentRepo.DeleteComment(docID, commentID, userID)
+}
+
+// CountComments - 文書のコメント数（返信を含む）を取得（文書へのアクセスは呼び出し側で確認済みであること）
+func (s *DocumentCommentService) CountComments(docID int) (int, error) {
+	return s.commentRepo.CountComments(docID)
+}
+
+// createComment - 検証済みの本文でコメントを保存
+func (s *DocumentCommentService) createComment(docID, userID int, parentID *int, body string) (*models.DocumentComment, error) {
+	comment := &models.DocumentComment{
+		DocumentID: docID,
+		ParentID:   parentID,
+		UserID:     userID,
+		Body:       body,
+	}
+	if err := s.commentRepo.CreateComment(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// buildCommentThreads - 作成日時順のコメント一覧を、スレッドの先頭と返信の入れ子に組み立てる
+// 先頭が見つからない返信は一覧に含めない
+func buildCommentThreads(comments []models.DocumentComment) []models.DocumentComment {
+	index := make(map[int]int, len(comments))
+	threads := make([]models.DocumentComment, 0)
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			index[comment.ID] = len(threads)
+			threads = append(threads, comment)
+		}
+	}
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			continue
+		}
+		if i, ok := index[*comment.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, comment)
+		}
+	}
+	return threads
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// MockDocumentCommentRepository - DocumentCommentRepositoryのモック
+type MockDocumentCommentRepository struct {
+	CreateCommentFunc      func(comment *models.DocumentComment) error
+	ListCommentsFunc       func(docID int) ([]models.DocumentComment, error)
+	GetCommentFunc         func(docID, commentID int) (*models.DocumentComment, error)
+	CountCommentsFunc      func(docID int) (int, error)
+	UpdateCommentBodyFunc  func(docID, commentID, userID int, body string) error
+	SetCommentResolvedFunc func(docID, commentID, userID int, resolved bool) error
+	DeleteCommentFunc      func(docID, commentID, userID int) error
+}
+
+func (m *MockDocumentCommentRepository) CreateComment(comment *models.DocumentComment) error {
+	if m.CreateCommentFunc != nil {
+		return m.CreateCommentFunc(comment)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockDocumentCommentRepository) ListComments(docID int) ([]models.DocumentComment, error) {
+	if m.ListCommentsFunc != nil {
+		return m.ListCommentsFunc(docID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDocumentCommentRepository) GetComment(docID, commentID int) (*models.DocumentComment, error) {
+	if m.GetCommentFunc != nil {
+		return m.GetCommentFunc(docID, commentID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDocumentCommentRepository) CountComments(docID int) (int, error) {
+	if m.CountCommentsFunc != nil {
+		return m.CountCommentsFunc(docID)
+	}
+	return 0, errors.New("not implemented")
+}
+
+func (m *MockDocumentCommentRepository) UpdateCommentBody(docID, commentID, userID int, body string) error {
+	if m.UpdateCommentBodyFunc != nil {
+		return m.UpdateCommentBodyFunc(docID, commentID, userID, body)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockDocumentCommentRepository) SetCommentResolved(docID, commentID, userID int, resolved bool) error {
+	if m.SetCommentResolvedFunc != nil {
+		return m.SetCommentResolvedFunc(docID, commentID, userID, resolved)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockDocumentCommentRepository) DeleteComment(docID, commentID, userID int) error {
+	if m.DeleteCommentFunc != nil {
+		return m.DeleteCommentFunc(docID, commentID, userID)
+	}
+	return errors.New("not implemented")
+}
+
+// documentCommentsByID - ID → コメント で GetComment を返すモックを作成する（ない場合は ErrNotFound）
+func documentCommentsByID(comments ...models.DocumentComment) func(docID, commentID int) (*models.DocumentComment, error) {
+	return func(docID, commentID int) (*models.DocumentComment, error) {
+		for i := range comments {
+			if comments[i].ID == commentID {
+				comment := comments[i]
+				return &comment, nil
+			}
+		}
+		return nil, apierror.ErrNotFound
+	}
+}
+
+func TestDocumentCommentService_ListThreads(t *testing.T) {
+	root1, root2 := 1, 2
+	commentRepo := &MockDocumentCommentRepository{
+		ListCommentsFunc: func(docID int) ([]models.DocumentComment, error) {
+			return []models.DocumentComment{
+				{ID: 1, DocumentID: docID, Body: "スレッド1"},
+				{ID: 2, DocumentID: docID, Body: "スレッド2", Resolved: true},
+				{ID: 3, DocumentID: docID, ParentID: &root1, Body: "返信1"},
+				{ID: 4, DocumentID: docID, ParentID: &root2, Body: "返信2"},
+				{ID: 5, DocumentID: docID, ParentID: &root1, Body: "返信3"},
+			}, nil
+		},
+	}
+	service := NewDocumentCommentService(ownedDocumentRepo(), commentRepo)
+
+	t.Run("正常系：返信をスレッドの先頭にまとめる", func(t *testing.T) {
+		threads, err := service.ListThreads(2, 10, nil)
+		if err != nil {
+			t.Fatalf("ListThreads() error = %v", err)
+		}
+		if len(threads) != 2 {
+			t.Fatalf("threads = %d, want 2", len(threads))
+		}
+		if len(threads[0].Replies) != 2 || threads[0].Replies[0].ID != 3 || threads[0].Replies[1].ID != 5 {
+			t.Errorf("threads[0].Replies = %+v, want [3 5]", threads[0].Replies)
+		}
+		if len(threads[1].Replies) != 1 || threads[1].Replies[0].ID != 4 {
+			t.Errorf("threads[1].Replies = %+v, want [4]", threads[1].Replies)
+		}
+	})
+
+	t.Run("正常系：解決状態で絞り込む", func(t *testing.T) {
+		resolved := false
+		threads, err := service.ListThreads(2, 10, &resolved)
+		if err != nil {
+			t.Fatalf("ListThreads() error = %v", err)
+		}
+		if len(threads) != 1 || threads[0].ID != 1 {
+			t.Errorf("threads = %+v, want only thread 1", threads)
+		}
+	})
+}
+
+func TestDocumentCommentService_Reply(t *testing.T) {
+	root := 1
+	comments := []models.DocumentComment{
+		{ID: 1, DocumentID: 2, Body: "スレッド"},
+		{ID: 3, DocumentID: 2, ParentID: &root, Body: "返信"},
+	}
+
+	tests := []struct {
+		name       string
+		commentID  int
+		body       string
+		wantParent int
+		wantCode   string
+		wantErr    error
+	}{
+		{name: "正常系：スレッドの先頭に返信", commentID: 1, body: "了解です", wantParent: 1},
+		{name: "正常系：返信への返信は先頭への返信になる", commentID: 3, body: "了解です", wantParent: 1},
+		{name: "異常系：空の返信", commentID: 1, body: "  ", wantCode: "INVALID_COMMENT"},
+		{name: "異常系：返信先が存在しない", commentID: 9, body: "了解です", wantErr: apierror.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *models.DocumentComment
+			commentRepo := &MockDocumentCommentRepository{
+				GetCommentFunc: documentCommentsByID(comments...),
+				CreateCommentFunc: func(comment *models.DocumentComment) error {
+					saved = comment
+					comment.ID = 10
+					return nil
+				},
+			}
+			service := NewDocumentCommentService(ownedDocumentRepo(), commentRepo)
+
+			_, err := service.Reply(2, 10, tt.commentID, tt.body)
+			switch {
+			case tt.wantCode != "":
+				var appErr *apierror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			case err != nil:
+				t.Fatalf("Reply() error = %v", err)
+			}
+
+			if saved == nil || saved.ParentID == nil || *saved.ParentID != tt.wantParent {
+				t.Errorf("saved = %+v, want parent %d", saved, tt.wantParent)
+			}
+		})
+	}
+}
+
+func TestDocumentCommentService_UpdateComment(t *testing.T) {
+	root := 1
+	comments := []models.DocumentComment{
+		{ID: 1, DocumentID: 2, Body: "スレッド"},
+		{ID: 3, DocumentID: 2, ParentID: &root, Body: "返信"},
+	}
+
+	t.Run("正常系：スレッドを解決する", func(t *testing.T) {
+		var resolvedID int
+		commentRepo := &MockDocumentCommentRepository{
+			GetCommentFunc: documentCommentsByID(comments...),
+			SetCommentResolvedFunc: func(docID, commentID, userID int, resolved bool) error {
+				resolvedID = commentID
+				return nil
+			},
+		}
+		service := NewDocumentCommentService(ownedDocumentRepo(), commentRepo)
+
+		resolved := true
+		if _, err := service.UpdateComment(2, 10, 1, models.DocumentCommentPatch{Resolved: &resolved}); err != nil {
+			t.Fatalf("UpdateComment() error = %v", err)
+		}
+		if resolvedID != 1 {
+			t.Errorf("resolved comment = %d, want 1", resolvedID)
+		}
+	})
+
+	t.Run("異常系：返信は解決できない", func(t *testing.T) {
+		commentRepo := &MockDocumentCommentRepository{
+			GetCommentFunc: documentCommentsByID(comments...),
+		}
+		service := NewDocumentCommentService(ownedDocumentRepo(), commentRepo)
+
+		resolved := true
+		_, err := service.UpdateComment(2, 10, 3, models.DocumentCommentPatch{Resolved: &resolved})
+		var appErr *apierror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != "REPLY_NOT_RESOLVABLE" {
+			t.Errorf("error = %v, want REPLY_NOT_RESOLVABLE", err)
+		}
+	})
+
+	t.Run("異常系：変更内容がない", func(t *testing.T) {
+		service := NewDocumentCommentService(ownedDocumentRepo(), &MockDocumentCommentRepository{})
+
+		_, err := service.UpdateComment(2, 10, 1, models.DocumentCommentPatch{})
+		var appErr *apierror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_REQUEST" {
+			t.Errorf("error = %v, want INVALID_REQUEST", err)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"sync"
+
+	"simple-notion-backend/internal/models"
+)
+
+// documentEventBuffer - 1つの購読者に溜めておけるイベントの数
+const documentEventBuffer = 64
+
+// DocumentEventBroker - ユーザーごとに文書の変更イベントを購読者（開いているタブ）に配信する
+// 受け取りが追いつかない購読者はイベントを取りこぼす前に購読を終了させる（再接続したタブが内容を取得し直す）
+// 状態はプロセス内のメモリにのみ保持する（複数のプロセスで動かす場合は、変更を受けたプロセスの購読者にのみ届く）
+type DocumentEventBroker struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan models.DocumentEvent]struct{}
+}
+
+// NewDocumentEventBroker - DocumentEventBrokerを初期化
+func NewDocumentEventBroker() *DocumentEventBroker {
+	return &DocumentEventBroker{
+		subscribers: make(map[int]map[chan models.DocumentEvent]struct{}),
+	}
+}
+
+// Subscribe - ユーザーの文書の変更イベントを購読する
+// 受け取りが追いつかずに購読が終了した場合はチャネルが閉じられる。返した関数で購読を解除する
+func (b *DocumentEventBroker) Subscribe(userID int) (<-chan models.DocumentEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan models.DocumentEvent, documentEventBuffer)
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan models.DocumentEvent]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(userID, ch)
+	}
+}
+
+// Publish - ユーザーのすべての購読者にイベントを配信する
+func (b *DocumentEventBroker) Publish(userID int, event models.DocumentEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+			b.remove(userID, ch)
+			close(ch)
+		}
+	}
+}
+
+// remove - 購読者を削除する（mu を取得した状態で呼ぶ）
+func (b *DocumentEventBroker) remove(userID int, ch chan models.DocumentEvent) {
+	delete(b.subscribers[userID], ch)
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+
+	"simple-notion-backend/internal/models"
+)
+
+// TestDocumentEventBroker - 文書の変更イベントの配信のテスト
+func TestDocumentEventBroker(t *testing.T) {
+	t.Run("正常系：同じユーザーのすべての購読者に届く", func(t *testing.T) {
+		broker := NewDocumentEventBroker()
+		first, unsubscribeFirst := broker.Subscribe(1)
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := broker.Subscribe(1)
+		defer unsubscribeSecond()
+		other, unsubscribeOther := broker.Subscribe(2)
+		defer unsubscribeOther()
+
+		broker.Publish(1, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: 10})
+
+		for i, ch := range []<-chan models.DocumentEvent{first, second} {
+			select {
+			case event := <-ch:
+				if event.DocumentID != 10 {
+					t.Errorf("subscriber %d DocumentID = %d, want 10", i, event.DocumentID)
+				}
+			default:
+				t.Errorf("subscriber %d received nothing", i)
+			}
+		}
+		select {
+		case event := <-other:
+			t.Errorf("other user received %+v", event)
+		default:
+		}
+	})
+
+	t.Run("正常系：購読を解除すると届かない", func(t *testing.T) {
+		broker := NewDocumentEventBroker()
+		_, unsubscribe := broker.Subscribe(1)
+		unsubscribe()
+
+		broker.Publish(1, models.DocumentEvent{Type: models.DocumentEventDeleted, DocumentID: 10})
+		if len(broker.subscribers) != 0 {
+			t.Errorf("subscribers = %d users, want 0", len(broker.subscribers))
+		}
+	})
+
+	t.Run("異常系：受け取りが追いつかない購読者は終了させる", func(t *testing.T) {
+		broker := NewDocumentEventBroker()
+		events, unsubscribe := broker.Subscribe(1)
+		defer unsubscribe()
+
+		for i := 0; i <= documentEventBuffer; i++ {
+			broker.Publish(1, models.DocumentEvent{Type: models.DocumentEventUpdated, DocumentID: i})
+		}
+
+		received := 0
+		for range events {
+			received++
+		}
+		if received != documentEventBuffer {
+			t.Errorf("received = %d, want %d before close", received, documentEventBuffer)
+		}
+	})
+}
@@ -1,10 +1,14 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/events"
 	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
 )
 
 // DocumentService - 文書操作の統合サービス
@@ -14,6 +18,8 @@ type DocumentService struct {
 	blockRepo    BlockRepositoryInterface
 	treeRepo     DocumentTreeRepositoryInterface
 	trashRepo    DocumentTrashRepositoryInterface
+	workspaces   WorkspaceRoleCheckerInterface // nil の場合はワークスペースでの役割を確認しない
+	bus          *events.Bus                   // nil の場合はイベントを発行しない
 }
 
 // NewDocumentService - DocumentServiceを初期化
@@ -22,17 +28,85 @@ func NewDocumentService(
 	blockRepo BlockRepositoryInterface,
 	treeRepo DocumentTreeRepositoryInterface,
 	trashRepo DocumentTrashRepositoryInterface,
+	workspaces WorkspaceRoleCheckerInterface,
+	bus *events.Bus,
 ) *DocumentService {
 	return &DocumentService{
 		documentRepo: documentRepo,
 		blockRepo:    blockRepo,
 		treeRepo:     treeRepo,
 		trashRepo:    trashRepo,
+		workspaces:   workspaces,
+		bus:          bus,
 	}
 }
 
+// publish - 文書のイベントを、文書の所有者宛てに発行する（共同編集者の操作でも所有者の Webhook に届く）
+func (s *DocumentService) publish(eventType events.Type, doc *models.Document, actorID int, permanent bool) {
+	s.bus.Publish(context.Background(), eventType, doc.UserID, events.DocumentData{
+		DocumentID:  doc.ID,
+		Title:       doc.Title,
+		ParentID:    doc.ParentID,
+		WorkspaceID: doc.WorkspaceID,
+		ActorID:     actorID,
+		Permanent:   permanent,
+	})
+}
+
+// GetDocument - 文書の基本情報を取得（他ユーザーの文書・ごみ箱内の文書は ErrNotFound）
+func (s *DocumentService) GetDocument(docID, userID int) (*models.Document, error) {
+	return s.documentRepo.GetDocument(docID, userID)
+}
+
+// editableDocument - 編集する文書を取得し、閲覧のみの場合は Forbidden を返す
+// 閲覧のみは viewer の共同編集者と、文書が属するワークスペースの guest（editor の共同編集者でない場合）
+func (s *DocumentService) editableDocument(docID, userID int) (*models.Document, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Role == models.DocumentRoleViewer {
+		return nil, apierror.NewForbidden("DOCUMENT_READ_ONLY", "この文書は閲覧のみ許可されています", apierror.ErrForbidden)
+	}
+	return doc, nil
+}
+
+// ownedDocument - 文書を取得し、所有者でない（共同編集者の）場合は Forbidden を返す
+// 文書ツリーはユーザーごとのため、子文書の作成先・移動先にできるのは自分の文書のみ
+func (s *DocumentService) ownedDocument(docID, userID int) (*models.Document, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return requireOwner(doc, userID)
+}
+
+// requireOwner - 文書の所有者でない場合は Forbidden を返す
+func requireOwner(doc *models.Document, userID int) (*models.Document, error) {
+	if doc.UserID != userID {
+		return nil, apierror.NewForbidden("DOCUMENT_OWNER_REQUIRED", "この操作は文書の所有者のみができます", apierror.ErrForbidden)
+	}
+	return doc, nil
+}
+
+// parentDocument - 子文書を作成する親を取得する
+// 個人の文書は所有者のみ、ワークスペースの文書は編集できるメンバー（guest 以外）も親にできる
+func (s *DocumentService) parentDocument(docID, userID int) (*models.Document, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.WorkspaceID == nil || s.workspaces == nil {
+		return requireOwner(doc, userID)
+	}
+	if err := requireWorkspaceEditor(s.workspaces, doc.WorkspaceID, userID); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
 // GetDocumentWithBlocks - 文書とブロック情報の統合取得
-// 既存のDocumentRepository.GetDocumentWithBlocksと同等の機能
+// 既存のDocumentRepository.GetDocumentWithBlocksと同等の機能（閲覧のみの共同編集者には ReadOnly として返す）
 func (s *DocumentService) GetDocumentWithBlocks(docID, userID int) (*models.DocumentWithBlocks, error) {
 	// 文書基本情報を取得
 	doc, err := s.documentRepo.GetDocument(docID, userID)
@@ -49,10 +123,13 @@ func (s *DocumentService) GetDocumentWithBlocks(docID, userID int) (*models.Docu
 	return &models.DocumentWithBlocks{
 		Document: *doc,
 		Blocks:   blocks,
+		ReadOnly: doc.Role == models.DocumentRoleViewer,
 	}, nil
 }
 
 // GetDocumentWithBlocksIncludingDeleted - 削除されたドキュメントも含めて文書とブロック情報を統合取得
+// ごみ箱画面でのプレビュー用。所有者の文書のみ取得でき、ごみ箱内の文書は ReadOnly として返す
+// （編集系の操作は GetDocument で存在確認するため、ごみ箱内の文書は復元するまで 404 になる）
 func (s *DocumentService) GetDocumentWithBlocksIncludingDeleted(docID, userID int) (*models.DocumentWithBlocks, error) {
 	// 削除されたドキュメントも含めて文書基本情報を取得
 	doc, err := s.documentRepo.GetDocumentIncludingDeleted(docID, userID)
@@ -69,70 +146,508 @@ func (s *DocumentService) GetDocumentWithBlocksIncludingDeleted(docID, userID in
 	return &models.DocumentWithBlocks{
 		Document: *doc,
 		Blocks:   blocks,
+		ReadOnly: doc.IsDeleted,
 	}, nil
 }
 
-// GetDocumentTree - 文書ツリー構造を取得
+// GetDocumentTree - ワークスペース（nil は個人の文書）の文書ツリー構造を取得
 // 既存のDocumentRepository.GetDocumentTreeと同等の機能
-func (s *DocumentService) GetDocumentTree(userID int) ([]models.DocumentTreeNode, error) {
-	return s.treeRepo.GetDocumentTree(userID)
+func (s *DocumentService) GetDocumentTree(userID int, workspaceID *int) ([]models.DocumentTreeNode, error) {
+	return s.treeRepo.GetDocumentTree(userID, workspaceID)
+}
+
+// GetDocumentTreeNode - 文書ツリーでの文書と子孫を取得（サイドバーへの変更の通知用）
+// 文書の所有者のツリー（文書が属するワークスペース）から探すため、共同編集者が変更した場合も取得できる
+func (s *DocumentService) GetDocumentTreeNode(docID, userID int) (*models.DocumentTreeNode, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := s.treeRepo.GetDocumentTree(doc.UserID, doc.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if node := findTreeNode(tree, docID); node != nil {
+		return node, nil
+	}
+	return nil, fmt.Errorf("document id=%d in tree: %w", docID, apierror.ErrNotFound)
+}
+
+// GetDocumentsByIDs - 複数の文書を1回の問い合わせで取得（文書ID → 文書。アクセスできない文書は含まない）
+// GraphQL の同じ階層の文書をまとめて取得するために使う（ブロック情報は含まない）
+func (s *DocumentService) GetDocumentsByIDs(docIDs []int, userID int) (map[int]*models.Document, error) {
+	docs, err := s.documentRepo.GetDocumentsByIDs(docIDs, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+
+	result := make(map[int]*models.Document, len(docs))
+	for i := range docs {
+		result[docs[i].ID] = &docs[i]
+	}
+	return result, nil
+}
+
+// GetBlocksByDocumentIDs - 複数の文書のブロックをまとめて取得（文書ID → ブロック一覧。アクセスできない文書は含まない）
+func (s *DocumentService) GetBlocksByDocumentIDs(docIDs []int, userID int) (map[int][]models.Block, error) {
+	docs, err := s.GetDocumentsByIDs(docIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	accessible := make([]int, 0, len(docs))
+	for id := range docs {
+		accessible = append(accessible, id)
+	}
+
+	blocks, err := s.blockRepo.GetBlocksByDocumentIDs(accessible)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocks: %w", err)
+	}
+
+	// ブロックのない文書も空の一覧として返す（アクセスできない文書と区別する）
+	result := make(map[int][]models.Block, len(accessible))
+	for _, id := range accessible {
+		result[id] = blocks[id]
+		if result[id] == nil {
+			result[id] = []models.Block{}
+		}
+	}
+	return result, nil
+}
+
+// GetDocumentTreeNodes - 複数の文書の文書ツリーでのノードをまとめて取得（文書ID → ノード。アクセスできない文書は含まない）
+// GetDocumentTreeNode と同じく文書の所有者のツリーから探し、所有者とワークスペースが同じ文書ではツリーを1回だけ取得する
+func (s *DocumentService) GetDocumentTreeNodes(docIDs []int, userID int) (map[int]*models.DocumentTreeNode, error) {
+	docs, err := s.GetDocumentsByIDs(docIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type treeKey struct {
+		ownerID     int
+		workspaceID int // 0 は個人の文書
+	}
+	trees := make(map[treeKey][]models.DocumentTreeNode)
+	result := make(map[int]*models.DocumentTreeNode, len(docs))
+	for id, doc := range docs {
+		key := treeKey{ownerID: doc.UserID}
+		if doc.WorkspaceID != nil {
+			key.workspaceID = *doc.WorkspaceID
+		}
+		tree, ok := trees[key]
+		if !ok {
+			if tree, err = s.treeRepo.GetDocumentTree(doc.UserID, doc.WorkspaceID); err != nil {
+				return nil, err
+			}
+			trees[key] = tree
+		}
+		if node := findTreeNode(tree, id); node != nil {
+			result[id] = node
+		}
+	}
+	return result, nil
+}
+
+// findTreeNode - ツリーから文書を再帰的に探す（ない場合は nil）
+func findTreeNode(nodes []models.DocumentTreeNode, docID int) *models.DocumentTreeNode {
+	for i := range nodes {
+		if nodes[i].ID == docID {
+			return &nodes[i]
+		}
+		if node := findTreeNode(nodes[i].Children, docID); node != nil {
+			return node
+		}
+	}
+	return nil
 }
 
 // CreateDocument - 新しい文書を作成
 // 既存のDocumentRepository.CreateDocumentと同等の機能
 func (s *DocumentService) CreateDocument(doc *models.Document) error {
 	// 親ドキュメント指定がある場合は所有権・存在を確認（404 防止と権限漏洩対策）
+	// ワークスペースの文書は、他のメンバーの文書の子文書も作成できる（子文書は親と同じワークスペースに属する）
 	if doc.ParentID != nil {
-		if _, err := s.documentRepo.GetDocument(*doc.ParentID, doc.UserID); err != nil {
-			// 親が存在しない／他ユーザーのものは ErrNotFound として伝搬
+		if _, err := s.parentDocument(*doc.ParentID, doc.UserID); err != nil {
+			// 親が存在しない／他ユーザーのものは ErrNotFound、共同編集者として参加している文書は Forbidden として伝搬
 			return fmt.Errorf("parent document id=%d: %w", *doc.ParentID, err)
 		}
 	}
-	return s.documentRepo.CreateDocument(doc)
+	// ワークスペースに作成できるのは guest 以外のメンバーのみ
+	if err := requireWorkspaceEditor(s.workspaces, doc.WorkspaceID, doc.UserID); err != nil {
+		return err
+	}
+	if err := s.documentRepo.CreateDocument(doc); err != nil {
+		return err
+	}
+	s.publish(events.DocumentCreated, doc, doc.UserID, false)
+	return nil
 }
 
 // UpdateDocument - 文書の基本情報のみを更新
 // 既存のDocumentRepository.UpdateDocumentと同等の機能
-func (s *DocumentService) UpdateDocument(docID, userID int, title, content string) error {
-	// 存在確認 + ゴミ箱チェックを兼ねる
-	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+func (s *DocumentService) UpdateDocument(ctx context.Context, docID, userID int, title, content string) error {
+	// 存在確認 + ゴミ箱チェック + 編集権限の確認を兼ねる
+	doc, err := s.editableDocument(docID, userID)
+	if err != nil {
 		return err
 	}
-	return s.documentRepo.UpdateDocument(docID, userID, title, content)
+	if err := s.documentRepo.UpdateDocument(ctx, docID, userID, title, content); err != nil {
+		return err
+	}
+	doc.Title = title
+	s.publish(events.DocumentUpdated, doc, userID, false)
+	return nil
 }
 
 // UpdateDocumentWithBlocks - 文書とブロック情報を統合更新
 // 文書の基本情報とブロック情報を一度に更新する高レベルな操作
-func (s *DocumentService) UpdateDocumentWithBlocks(docID, userID int, title, content string, blocks []models.Block) error {
-	// 存在確認（削除済みドキュメントへの編集は ErrNotFound として 404 を返す）
-	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+func (s *DocumentService) UpdateDocumentWithBlocks(ctx context.Context, docID, userID int, title, content string, blocks []models.Block) error {
+	// 存在確認（削除済みドキュメントへの編集は ErrNotFound として 404、閲覧のみの場合は 403 を返す）
+	doc, err := s.editableDocument(docID, userID)
+	if err != nil {
 		return fmt.Errorf("failed to update document: %w", err)
 	}
 
 	// 文書基本情報を更新
-	if err := s.documentRepo.UpdateDocument(docID, userID, title, content); err != nil {
+	if err := s.documentRepo.UpdateDocument(ctx, docID, userID, title, content); err != nil {
 		return fmt.Errorf("failed to update document: %w", err)
 	}
 
 	// ブロック情報を更新
-	if err := s.blockRepo.UpdateBlocks(docID, blocks); err != nil {
+	if err := s.blockRepo.UpdateBlocks(ctx, docID, userID, blocks); err != nil {
 		return fmt.Errorf("failed to update blocks: %w", err)
 	}
 
+	doc.Title = title
+	s.publish(events.DocumentUpdated, doc, userID, false)
+	return nil
+}
+
+// UpdateMergedDocument - 3方向マージの結果を保存する
+// 文書の行をロックし、マージ時点（merged.Current）から他の保存で変わっていない場合のみ保存する
+// 変わっていた場合は ErrConflict を返す（呼び出し側で最新の内容とマージし直す）
+func (s *DocumentService) UpdateMergedDocument(ctx context.Context, docID, userID int, merged *models.MergedDocument) error {
+	doc, err := s.editableDocument(docID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	if err := s.blockRepo.UpdateDocumentIfUnchanged(ctx, docID, userID, merged.Title, merged.Content, merged.Blocks, merged.Current); err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	doc.Title = merged.Title
+	s.publish(events.DocumentUpdated, doc, userID, false)
+	return nil
+}
+
+// UpdateDocumentWithOperations - 文書の基本情報を更新し、ブロックの差分操作を適用
+// ブロック全体を置き換えずに指定されたブロックだけを変更するため、他の編集者の変更を上書きしない
+func (s *DocumentService) UpdateDocumentWithOperations(ctx context.Context, docID, userID int, title, content string, ops []models.BlockOperation) error {
+	doc, err := s.editableDocument(docID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	if err := s.documentRepo.UpdateDocument(ctx, docID, userID, title, content); err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	if len(ops) > 0 {
+		if err := s.blockRepo.ApplyOperations(ctx, docID, userID, ops); err != nil {
+			return fmt.Errorf("failed to apply block operations: %w", err)
+		}
+	}
+
+	doc.Title = title
+	s.publish(events.DocumentUpdated, doc, userID, false)
+	return nil
+}
+
+// PatchDocument - 文書の部分更新（JSON Merge Patch）
+// パッチに含まれるフィールドのみを更新し、含まれないフィールドは他の編集者の保存を上書きしない
+func (s *DocumentService) PatchDocument(ctx context.Context, docID, userID int, patch models.DocumentPatch) error {
+	doc, err := s.editableDocument(docID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to patch document: %w", err)
+	}
+	if patch.IsEmpty() {
+		return nil
+	}
+
+	if patch.Title != nil || patch.Content != nil {
+		if err := s.documentRepo.PatchDocument(ctx, docID, userID, patch.Title, patch.Content); err != nil {
+			return fmt.Errorf("failed to patch document: %w", err)
+		}
+	}
+
+	if patch.Blocks != nil {
+		if err := s.blockRepo.UpdateBlocks(ctx, docID, userID, *patch.Blocks); err != nil {
+			return fmt.Errorf("failed to update blocks: %w", err)
+		}
+	}
+
+	if patch.Title != nil {
+		doc.Title = *patch.Title
+	}
+	s.publish(events.DocumentUpdated, doc, userID, false)
 	return nil
 }
 
+// PreviewBlockOperations - 差分操作を適用した後のブロック一覧をメモリ上で求める
+// 保存前の検証（リッチテキスト形式・コンテンツポリシー）に使う。DB は変更しない
+// 操作の形式が不正な場合は ValidationError、対象ブロックが存在しない場合は ErrConflict を返す
+func PreviewBlockOperations(blocks []models.Block, ops []models.BlockOperation) ([]models.Block, error) {
+	result := make([]models.Block, len(blocks))
+	copy(result, blocks)
+
+	indexOf := func(blockID int) int {
+		for i, b := range result {
+			if b.ID == blockID {
+				return i
+			}
+		}
+		return -1
+	}
+	clamp := func(position *int, max int) int {
+		if position == nil || *position < 0 || *position > max {
+			return max
+		}
+		return *position
+	}
+
+	for i, op := range ops {
+		if op.Op != models.BlockOpInsert && op.BlockID == 0 {
+			return nil, apierror.NewValidationError("INVALID_BLOCK_OPERATION",
+				fmt.Sprintf("操作 %d には blockId を指定してください", i), nil)
+		}
+
+		switch op.Op {
+		case models.BlockOpInsert:
+			if op.Type == "" || len(op.Content) == 0 {
+				return nil, apierror.NewValidationError("INVALID_BLOCK_OPERATION",
+					fmt.Sprintf("操作 %d（insert）には type と content を指定してください", i), nil)
+			}
+			pos := clamp(op.Position, len(result))
+			block := models.Block{ParentBlockID: op.ParentBlockID, Type: op.Type, Content: op.Content}
+			result = append(result[:pos], append([]models.Block{block}, result[pos:]...)...)
+
+		case models.BlockOpUpdate, models.BlockOpMove, models.BlockOpDelete:
+			idx := indexOf(op.BlockID)
+			if idx < 0 {
+				return nil, fmt.Errorf("operation %d: block id=%d no longer exists: %w", i, op.BlockID, apierror.ErrConflict)
+			}
+			block := result[idx]
+			result = append(result[:idx], result[idx+1:]...)
+			if op.Op == models.BlockOpDelete {
+				continue
+			}
+
+			if op.Type != "" {
+				block.Type = op.Type
+			}
+			if op.Content != nil {
+				block.Content = op.Content
+			}
+			pos := idx
+			if op.Position != nil {
+				pos = clamp(op.Position, len(result))
+			}
+			result = append(result[:pos], append([]models.Block{block}, result[pos:]...)...)
+
+		default:
+			return nil, apierror.NewValidationError("INVALID_BLOCK_OPERATION",
+				fmt.Sprintf("操作 %d の種類 %q は不明です", i, op.Op), nil)
+		}
+	}
+
+	for i := range result {
+		result[i].Position = i
+	}
+	return result, nil
+}
+
 // UpdateBlocks - ブロック情報のみを更新
-// 既存のDocumentRepository.UpdateBlocksと同等の機能
-func (s *DocumentService) UpdateBlocks(docID int, blocks []models.Block) error {
-	return s.blockRepo.UpdateBlocks(docID, blocks)
+// 既存のDocumentRepository.UpdateBlocksと同等の機能（変更履歴の編集者は記録しない）
+func (s *DocumentService) UpdateBlocks(ctx context.Context, docID int, blocks []models.Block) error {
+	return s.blockRepo.UpdateBlocks(ctx, docID, 0, blocks)
+}
+
+// UpdateSearchText - 文書の全文検索用のテキストを更新
+// テキストはブロックの JSON から取り出したもので、保存のたびに呼び出し側で作り直す
+func (s *DocumentService) UpdateSearchText(docID int, text string) error {
+	return s.documentRepo.UpdateSearchText(docID, text)
+}
+
+// SearchDocuments - ユーザーの文書をタイトルとブロックのテキストで全文検索し、関連度順に1ページ分を返す
+// 件数の指定がない場合は既定の件数、上限を超える場合は上限の件数を返す
+func (s *DocumentService) SearchDocuments(userID int, keyword string, page pagination.Params) (pagination.Page[models.DocumentSearchResult], error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return pagination.Page[models.DocumentSearchResult]{}, apierror.NewValidationError(
+			"INVALID_SEARCH_QUERY", "検索語を指定してください", nil,
+		)
+	}
+	return s.documentRepo.SearchDocuments(userID, keyword, page.Clamp(models.DefaultSearchLimit, models.MaxSearchLimit))
+}
+
+// GetBlock - 文書内の単一ブロックを取得
+// 文書が存在しない／他ユーザーのもの／ゴミ箱内の場合は ErrNotFound を返す
+func (s *DocumentService) GetBlock(docID, userID, blockID int) (*models.Block, error) {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	return s.blockRepo.GetBlock(docID, blockID)
+}
+
+// GetBlockByID - ブロックIDのみで単一ブロックを取得
+// ブロックの文書が存在しない／他ユーザーのもの／ゴミ箱内の場合は ErrNotFound を返す（ブロックの存在を明かさない）
+func (s *DocumentService) GetBlockByID(userID, blockID int) (*models.Block, error) {
+	block, err := s.blockRepo.GetBlockByID(blockID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.documentRepo.GetDocument(block.DocumentID, userID); err != nil {
+		return nil, fmt.Errorf("block id=%d: %w", blockID, err)
+	}
+	return block, nil
+}
+
+// CreateBlock - 文書にブロックを1件挿入
+// 文書全体を送り直さずに単一ブロックを保存するための操作
+func (s *DocumentService) CreateBlock(docID, userID int, block *models.Block) error {
+	if _, err := s.editableDocument(docID, userID); err != nil {
+		return err
+	}
+	if err := s.validatePlacement(docID, block.ParentBlockID, block.Type); err != nil {
+		return err
+	}
+	block.DocumentID = docID
+	return s.blockRepo.CreateBlock(block)
+}
+
+// CopyBlocks - 複製したブロック（models.PlanBlockCopy の結果）を文書の指定位置に挿入
+// 貼り付け先の文書と親ブロックの確認後、1トランザクションで保存する
+func (s *DocumentService) CopyBlocks(docID, userID int, parentID *int, position int, blocks []models.Block) error {
+	if len(blocks) == 0 {
+		return apierror.NewValidationError("INVALID_REQUEST", "コピーするブロックを指定してください", nil)
+	}
+	if len(blocks) > models.MaxCopyBlocks {
+		return apierror.NewValidationError("TOO_MANY_BLOCKS",
+			fmt.Sprintf("一度にコピーできるブロックは %d 件までです", models.MaxCopyBlocks), nil)
+	}
+	if _, err := s.editableDocument(docID, userID); err != nil {
+		return err
+	}
+	// 一覧内の親子はコピー元で検証済みのため、貼り付け先で最上位になるブロックのみ検証する
+	for _, block := range blocks {
+		if block.ParentBlockID != nil {
+			continue
+		}
+		if err := s.validatePlacement(docID, parentID, block.Type); err != nil {
+			return err
+		}
+	}
+	return s.blockRepo.CopyBlocks(docID, parentID, position, blocks)
+}
+
+// UpdateBlock - 文書内の単一ブロックを更新（閲覧のみの共同編集者は Forbidden）
+// ブロックIDのみを指定する変換（/blocks/{id}/convert）もここで編集権限を確認する
+func (s *DocumentService) UpdateBlock(docID, userID int, block *models.Block) error {
+	if _, err := s.editableDocument(docID, userID); err != nil {
+		return err
+	}
+	if err := s.validatePlacement(docID, block.ParentBlockID, block.Type); err != nil {
+		return err
+	}
+	block.DocumentID = docID
+	return s.blockRepo.UpdateBlock(block, userID)
+}
+
+// GetBlockHistory - ブロックの変更履歴を新しい順に取得
+// 文書の所有者と共同編集者（viewer を含む）が参照でき、削除済みのブロックの履歴も返す
+func (s *DocumentService) GetBlockHistory(docID, userID, blockID int) ([]models.BlockEditEvent, error) {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	return s.blockRepo.ListBlockHistory(docID, blockID)
+}
+
+// validatePlacement - 親ブロックの子（parentID が nil の場合はトップレベル）として blockType を置けるかを検証
+func (s *DocumentService) validatePlacement(docID int, parentID *int, blockType string) error {
+	parentType := ""
+	if parentID != nil {
+		parent, err := s.blockRepo.GetBlock(docID, *parentID)
+		if err != nil {
+			return err
+		}
+		parentType = parent.Type
+	}
+	if err := models.ValidateBlockPlacement(parentType, blockType); err != nil {
+		return apierror.NewValidationError("INVALID_BLOCK_HIERARCHY", "ブロックの親子関係が不正です", err)
+	}
+	return nil
+}
+
+// DeleteBlock - 文書内の単一ブロックを削除
+func (s *DocumentService) DeleteBlock(docID, userID, blockID int) error {
+	if _, err := s.editableDocument(docID, userID); err != nil {
+		return err
+	}
+	return s.blockRepo.DeleteBlock(docID, blockID)
+}
+
+// CreateSyncedBlock - ブロックを同期元として登録し、synced ブロックから参照する syncId を発行
+// synced ブロック自体は同期元にできない（同期の入れ子を作らない）
+func (s *DocumentService) CreateSyncedBlock(docID, userID, blockID int) (*models.SyncedBlock, error) {
+	if _, err := s.editableDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	block, err := s.blockRepo.GetBlock(docID, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "synced" {
+		return nil, apierror.NewValidationError("BLOCK_ALREADY_SYNCED",
+			"同期ブロックは同期元にできません。同期元のブロックを指定してください", nil)
+	}
+
+	synced := &models.SyncedBlock{UserID: userID, SourceDocumentID: docID, SourceBlockID: blockID}
+	if err := s.blockRepo.CreateSyncedBlock(synced); err != nil {
+		return nil, err
+	}
+	return synced, nil
+}
+
+// GetCollapsedBlockIDs - ユーザーが文書内で折りたたんでいるトグルブロックのIDを取得
+func (s *DocumentService) GetCollapsedBlockIDs(docID, userID int) ([]int, error) {
+	return s.blockRepo.GetCollapsedBlockIDs(userID, docID)
+}
+
+// SetBlockCollapsed - トグルブロックの開閉状態をユーザーごとに保存
+// 開閉状態は表示上の設定のため、文書の更新日時やバージョンには影響しない
+func (s *DocumentService) SetBlockCollapsed(docID, userID, blockID int, collapsed bool) error {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return err
+	}
+	block, err := s.blockRepo.GetBlock(docID, blockID)
+	if err != nil {
+		return err
+	}
+	if !models.CollapsibleBlockTypes[block.Type] {
+		return apierror.NewValidationError("BLOCK_NOT_COLLAPSIBLE",
+			fmt.Sprintf("%s ブロックは折りたためません", block.Type), nil)
+	}
+	return s.blockRepo.SetBlockCollapsed(userID, blockID, collapsed)
 }
 
 // MoveDocument - 文書を別の親文書の下に移動
 // 自身/子孫を親に設定する循環参照は ErrForbidden として 403 を返す
 func (s *DocumentService) MoveDocument(docID int, newParentID *int, userID int) error {
-	// 移動対象の存在 + 所有権確認
-	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+	// 移動対象の存在 + 所有権確認（共同編集者は移動できない）
+	doc, err := s.ownedDocument(docID, userID)
+	if err != nil {
 		return err
 	}
 
@@ -143,11 +658,17 @@ func (s *DocumentService) MoveDocument(docID int, newParentID *int, userID int)
 			return fmt.Errorf("cannot move document into itself: %w", apierror.ErrForbidden)
 		}
 
-		// 親候補の存在 + 所有権確認
-		if _, err := s.documentRepo.GetDocument(*newParentID, userID); err != nil {
+		// 親候補の存在 + 所有権確認（共同編集者として参加している文書の下には移動できない）
+		parent, err := s.ownedDocument(*newParentID, userID)
+		if err != nil {
 			return fmt.Errorf("parent document id=%d: %w", *newParentID, err)
 		}
 
+		// 子文書は親と同じワークスペースに属するため、ワークスペースをまたぐ移動は禁止
+		if !sameWorkspace(doc.WorkspaceID, parent.WorkspaceID) {
+			return apierror.NewValidationError("WORKSPACE_MISMATCH", "別のワークスペースの文書の下には移動できません", nil)
+		}
+
 		// 子孫を親に設定するのは禁止（循環参照）
 		isDescendant, err := s.isDescendantOf(*newParentID, docID, userID)
 		if err != nil {
@@ -161,6 +682,14 @@ func (s *DocumentService) MoveDocument(docID int, newParentID *int, userID int)
 	return s.treeRepo.MoveDocument(docID, newParentID, userID)
 }
 
+// sameWorkspace - 2つの文書が同じワークスペース（どちらも個人の文書の場合を含む）に属するか
+func sameWorkspace(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
 // isDescendantOf - candidateID が ancestorID の子孫かどうかを判定
 // ユーザーの全文書をロードして親リンクを辿る
 func (s *DocumentService) isDescendantOf(candidateID, ancestorID, userID int) (bool, error) {
@@ -204,7 +733,11 @@ func (s *DocumentService) SoftDeleteDocument(docID, userID int) error {
 	if doc.IsDeleted {
 		return fmt.Errorf("document id=%d is already in trash: %w", docID, apierror.ErrConflict)
 	}
-	return s.trashRepo.SoftDeleteDocument(docID, userID)
+	if err := s.trashRepo.SoftDeleteDocument(docID, userID); err != nil {
+		return err
+	}
+	s.publish(events.DocumentDeleted, doc, userID, false)
+	return nil
 }
 
 // RestoreDocument - ごみ箱から文書を復元
@@ -220,6 +753,27 @@ func (s *DocumentService) RestoreDocument(docID, userID int) error {
 	return s.trashRepo.RestoreDocument(docID, userID)
 }
 
+// BulkRestoreDocuments - 複数の文書をごみ箱から一括で復元
+// 削除前の親文書もごみ箱内にある場合、同時に復元するなら元の親の下に、そうでなければルートに戻す
+func (s *DocumentService) BulkRestoreDocuments(userID int, ids []int, strategy models.RestoreStrategy) ([]models.RestoreResult, error) {
+	if len(ids) == 0 {
+		return nil, apierror.NewValidationError("INVALID_REQUEST", "復元する文書を指定してください", nil)
+	}
+	if len(ids) > models.MaxBulkRestore {
+		return nil, apierror.NewValidationError("TOO_MANY_DOCUMENTS",
+			fmt.Sprintf("一度に復元できる文書は %d 件までです", models.MaxBulkRestore), nil)
+	}
+	switch strategy {
+	case models.RestoreToOriginalParent, models.RestoreToRoot:
+	case "":
+		strategy = models.RestoreToOriginalParent
+	default:
+		return nil, apierror.NewValidationError("INVALID_RESTORE_STRATEGY",
+			"strategy には restore-to-original-parent / restore-to-root のいずれかを指定してください", nil)
+	}
+	return s.trashRepo.BulkRestoreDocuments(userID, ids, strategy)
+}
+
 // PermanentDeleteDocument - 文書を完全削除
 // ゴミ箱に入っていない文書への完全削除は ErrConflict として 409 を返す
 func (s *DocumentService) PermanentDeleteDocument(docID, userID int) error {
@@ -230,13 +784,18 @@ func (s *DocumentService) PermanentDeleteDocument(docID, userID int) error {
 	if !doc.IsDeleted {
 		return fmt.Errorf("document id=%d must be in trash before permanent delete: %w", docID, apierror.ErrConflict)
 	}
-	return s.trashRepo.PermanentDeleteDocument(docID, userID)
+	if err := s.trashRepo.PermanentDeleteDocument(docID, userID); err != nil {
+		return err
+	}
+	s.publish(events.DocumentDeleted, doc, userID, true)
+	return nil
 }
 
-// GetTrashedDocuments - ごみ箱内の文書一覧を取得
-// 既存のDocumentRepository.GetTrashedDocumentsと同等の機能
-func (s *DocumentService) GetTrashedDocuments(userID int) ([]models.Document, error) {
-	return s.trashRepo.GetTrashedDocuments(userID)
+// GetTrashedDocuments - ごみ箱内の文書一覧を1ページ分取得
+// 検索語・削除日時の範囲・削除前の親文書による絞り込みと並び替えに対応
+// 件数の指定がない場合は既定の件数、上限を超える場合は上限の件数を返す
+func (s *DocumentService) GetTrashedDocuments(userID int, filter models.TrashFilter, page pagination.Params) (pagination.Page[models.Document], error) {
+	return s.trashRepo.GetTrashedDocuments(userID, filter, page.Clamp(pagination.DefaultLimit, pagination.MaxLimit))
 }
 
 // EmptyTrash - ユーザーのごみ箱を完全に空にする
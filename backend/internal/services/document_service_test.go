@@ -1,13 +1,17 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
 	"simple-notion-backend/internal/apierror"
 	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
 )
 
 // =======================================
@@ -17,10 +21,14 @@ import (
 // MockDocumentCoreRepository - DocumentCoreRepositoryのモック
 type MockDocumentCoreRepository struct {
 	GetDocumentFunc                 func(docID, userID int) (*models.Document, error)
+	GetDocumentsByIDsFunc           func(docIDs []int, userID int) ([]models.Document, error)
 	GetDocumentIncludingDeletedFunc func(docID, userID int) (*models.Document, error)
 	CreateDocumentFunc              func(doc *models.Document) error
-	UpdateDocumentFunc              func(docID, userID int, title, content string) error
+	UpdateDocumentFunc              func(ctx context.Context, docID, userID int, title, content string) error
+	PatchDocumentFunc               func(ctx context.Context, docID, userID int, title, content *string) error
 	GetAllDocumentsFunc             func(userID int) ([]models.Document, error)
+	UpdateSearchTextFunc            func(docID int, text string) error
+	SearchDocumentsFunc             func(userID int, keyword string, page pagination.Params) (pagination.Page[models.DocumentSearchResult], error)
 }
 
 func (m *MockDocumentCoreRepository) GetDocument(docID, userID int) (*models.Document, error) {
@@ -30,6 +38,13 @@ func (m *MockDocumentCoreRepository) GetDocument(docID, userID int) (*models.Doc
 	return nil, errors.New("not implemented")
 }
 
+func (m *MockDocumentCoreRepository) GetDocumentsByIDs(docIDs []int, userID int) ([]models.Document, error) {
+	if m.GetDocumentsByIDsFunc != nil {
+		return m.GetDocumentsByIDsFunc(docIDs, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
 func (m *MockDocumentCoreRepository) GetDocumentIncludingDeleted(docID, userID int) (*models.Document, error) {
 	if m.GetDocumentIncludingDeletedFunc != nil {
 		return m.GetDocumentIncludingDeletedFunc(docID, userID)
@@ -44,9 +59,16 @@ func (m *MockDocumentCoreRepository) CreateDocument(doc *models.Document) error
 	return errors.New("not implemented")
 }
 
-func (m *MockDocumentCoreRepository) UpdateDocument(docID, userID int, title, content string) error {
+func (m *MockDocumentCoreRepository) UpdateDocument(ctx context.Context, docID, userID int, title, content string) error {
 	if m.UpdateDocumentFunc != nil {
-		return m.UpdateDocumentFunc(docID, userID, title, content)
+		return m.UpdateDocumentFunc(ctx, docID, userID, title, content)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockDocumentCoreRepository) PatchDocument(ctx context.Context, docID, userID int, title, content *string) error {
+	if m.PatchDocumentFunc != nil {
+		return m.PatchDocumentFunc(ctx, docID, userID, title, content)
 	}
 	return errors.New("not implemented")
 }
@@ -58,10 +80,52 @@ func (m *MockDocumentCoreRepository) GetAllDocuments(userID int) ([]models.Docum
 	return nil, errors.New("not implemented")
 }
 
+func (m *MockDocumentCoreRepository) UpdateSearchText(docID int, text string) error {
+	if m.UpdateSearchTextFunc != nil {
+		return m.UpdateSearchTextFunc(docID, text)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockDocumentCoreRepository) SearchDocuments(userID int, keyword string, page pagination.Params) (pagination.Page[models.DocumentSearchResult], error) {
+	if m.SearchDocumentsFunc != nil {
+		return m.SearchDocumentsFunc(userID, keyword, page)
+	}
+	return pagination.Page[models.DocumentSearchResult]{}, errors.New("not implemented")
+}
+
 // MockBlockRepository - BlockRepositoryのモック
 type MockBlockRepository struct {
-	GetBlocksByDocumentIDFunc func(docID int) ([]models.Block, error)
-	UpdateBlocksFunc          func(docID int, blocks []models.Block) error
+	GetBlocksByDocumentIDFunc     func(docID int) ([]models.Block, error)
+	GetBlocksByDocumentIDsFunc    func(docIDs []int) (map[int][]models.Block, error)
+	UpdateDocumentIfUnchangedFunc func(ctx context.Context, docID, editorID int, title, content string, blocks []models.Block, expected models.DocumentContent) error
+	UpdateBlocksFunc              func(ctx context.Context, docID, editorID int, blocks []models.Block) error
+	GetBlockFunc                  func(docID, blockID int) (*models.Block, error)
+	GetBlockByIDFunc              func(blockID int) (*models.Block, error)
+	CreateBlockFunc               func(block *models.Block) error
+	CopyBlocksFunc                func(docID int, parentID *int, position int, blocks []models.Block) error
+	CreateSyncedBlockFunc         func(synced *models.SyncedBlock) error
+	UpdateBlockFunc               func(block *models.Block, editorID int) error
+	DeleteBlockFunc               func(docID, blockID int) error
+	ApplyOperationsFunc           func(ctx context.Context, docID, editorID int, ops []models.BlockOperation) error
+	ListBlockHistoryFunc          func(docID, blockID int) ([]models.BlockEditEvent, error)
+	CountBlocksByTypeFunc         func() ([]models.BlockTypeCount, error)
+	GetCollapsedBlockIDsFunc      func(userID, docID int) ([]int, error)
+	SetBlockCollapsedFunc         func(userID, blockID int, collapsed bool) error
+}
+
+func (m *MockBlockRepository) GetCollapsedBlockIDs(userID, docID int) ([]int, error) {
+	if m.GetCollapsedBlockIDsFunc != nil {
+		return m.GetCollapsedBlockIDsFunc(userID, docID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) SetBlockCollapsed(userID, blockID int, collapsed bool) error {
+	if m.SetBlockCollapsedFunc != nil {
+		return m.SetBlockCollapsedFunc(userID, blockID, collapsed)
+	}
+	return errors.New("not implemented")
 }
 
 func (m *MockBlockRepository) GetBlocksByDocumentID(docID int) ([]models.Block, error) {
@@ -71,22 +135,106 @@ func (m *MockBlockRepository) GetBlocksByDocumentID(docID int) ([]models.Block,
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockBlockRepository) UpdateBlocks(docID int, blocks []models.Block) error {
+func (m *MockBlockRepository) UpdateDocumentIfUnchanged(ctx context.Context, docID, editorID int, title, content string, blocks []models.Block, expected models.DocumentContent) error {
+	if m.UpdateDocumentIfUnchangedFunc != nil {
+		return m.UpdateDocumentIfUnchangedFunc(ctx, docID, editorID, title, content, blocks, expected)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) GetBlocksByDocumentIDs(docIDs []int) (map[int][]models.Block, error) {
+	if m.GetBlocksByDocumentIDsFunc != nil {
+		return m.GetBlocksByDocumentIDsFunc(docIDs)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) UpdateBlocks(ctx context.Context, docID, editorID int, blocks []models.Block) error {
 	if m.UpdateBlocksFunc != nil {
-		return m.UpdateBlocksFunc(docID, blocks)
+		return m.UpdateBlocksFunc(ctx, docID, editorID, blocks)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) GetBlock(docID, blockID int) (*models.Block, error) {
+	if m.GetBlockFunc != nil {
+		return m.GetBlockFunc(docID, blockID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) GetBlockByID(blockID int) (*models.Block, error) {
+	if m.GetBlockByIDFunc != nil {
+		return m.GetBlockByIDFunc(blockID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) CreateBlock(block *models.Block) error {
+	if m.CreateBlockFunc != nil {
+		return m.CreateBlockFunc(block)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) CreateSyncedBlock(synced *models.SyncedBlock) error {
+	if m.CreateSyncedBlockFunc != nil {
+		return m.CreateSyncedBlockFunc(synced)
 	}
 	return errors.New("not implemented")
 }
 
+func (m *MockBlockRepository) CopyBlocks(docID int, parentID *int, position int, blocks []models.Block) error {
+	if m.CopyBlocksFunc != nil {
+		return m.CopyBlocksFunc(docID, parentID, position, blocks)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) UpdateBlock(block *models.Block, editorID int) error {
+	if m.UpdateBlockFunc != nil {
+		return m.UpdateBlockFunc(block, editorID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) DeleteBlock(docID, blockID int) error {
+	if m.DeleteBlockFunc != nil {
+		return m.DeleteBlockFunc(docID, blockID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) ApplyOperations(ctx context.Context, docID, editorID int, ops []models.BlockOperation) error {
+	if m.ApplyOperationsFunc != nil {
+		return m.ApplyOperationsFunc(ctx, docID, editorID, ops)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) ListBlockHistory(docID, blockID int) ([]models.BlockEditEvent, error) {
+	if m.ListBlockHistoryFunc != nil {
+		return m.ListBlockHistoryFunc(docID, blockID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockBlockRepository) CountBlocksByType() ([]models.BlockTypeCount, error) {
+	if m.CountBlocksByTypeFunc != nil {
+		return m.CountBlocksByTypeFunc()
+	}
+	return nil, errors.New("not implemented")
+}
+
 // MockDocumentTreeRepository - DocumentTreeRepositoryのモック
 type MockDocumentTreeRepository struct {
-	GetDocumentTreeFunc func(userID int) ([]models.DocumentTreeNode, error)
+	GetDocumentTreeFunc func(userID int, workspaceID *int) ([]models.DocumentTreeNode, error)
 	MoveDocumentFunc    func(docID int, newParentID *int, userID int) error
 }
 
-func (m *MockDocumentTreeRepository) GetDocumentTree(userID int) ([]models.DocumentTreeNode, error) {
+func (m *MockDocumentTreeRepository) GetDocumentTree(userID int, workspaceID *int) ([]models.DocumentTreeNode, error) {
 	if m.GetDocumentTreeFunc != nil {
-		return m.GetDocumentTreeFunc(userID)
+		return m.GetDocumentTreeFunc(userID, workspaceID)
 	}
 	return nil, errors.New("not implemented")
 }
@@ -102,8 +250,9 @@ func (m *MockDocumentTreeRepository) MoveDocument(docID int, newParentID *int, u
 type MockDocumentTrashRepository struct {
 	SoftDeleteDocumentFunc      func(docID, userID int) error
 	RestoreDocumentFunc         func(docID, userID int) error
+	BulkRestoreDocumentsFunc    func(userID int, ids []int, strategy models.RestoreStrategy) ([]models.RestoreResult, error)
 	PermanentDeleteDocumentFunc func(docID, userID int) error
-	GetTrashedDocumentsFunc     func(userID int) ([]models.Document, error)
+	GetTrashedDocumentsFunc     func(userID int, filter models.TrashFilter, page pagination.Params) (pagination.Page[models.Document], error)
 	EmptyTrashFunc              func(userID int) error
 }
 
@@ -121,6 +270,13 @@ func (m *MockDocumentTrashRepository) RestoreDocument(docID, userID int) error {
 	return errors.New("not implemented")
 }
 
+func (m *MockDocumentTrashRepository) BulkRestoreDocuments(userID int, ids []int, strategy models.RestoreStrategy) ([]models.RestoreResult, error) {
+	if m.BulkRestoreDocumentsFunc != nil {
+		return m.BulkRestoreDocumentsFunc(userID, ids, strategy)
+	}
+	return nil, errors.New("not implemented")
+}
+
 func (m *MockDocumentTrashRepository) PermanentDeleteDocument(docID, userID int) error {
 	if m.PermanentDeleteDocumentFunc != nil {
 		return m.PermanentDeleteDocumentFunc(docID, userID)
@@ -128,11 +284,11 @@ func (m *MockDocumentTrashRepository) PermanentDeleteDocument(docID, userID int)
 	return errors.New("not implemented")
 }
 
-func (m *MockDocumentTrashRepository) GetTrashedDocuments(userID int) ([]models.Document, error) {
+func (m *MockDocumentTrashRepository) GetTrashedDocuments(userID int, filter models.TrashFilter, page pagination.Params) (pagination.Page[models.Document], error) {
 	if m.GetTrashedDocumentsFunc != nil {
-		return m.GetTrashedDocumentsFunc(userID)
+		return m.GetTrashedDocumentsFunc(userID, filter, page)
 	}
-	return nil, errors.New("not implemented")
+	return pagination.Page[models.Document]{}, errors.New("not implemented")
 }
 
 func (m *MockDocumentTrashRepository) EmptyTrash(userID int) error {
@@ -254,7 +410,7 @@ func TestGetDocumentWithBlocks(t *testing.T) {
 			tt.setupMocks(docRepo, blockRepo)
 
 			// サービスの初期化
-			service := NewDocumentService(docRepo, blockRepo, treeRepo, trashRepo)
+			service := NewDocumentService(docRepo, blockRepo, treeRepo, trashRepo, nil, nil)
 
 			// テスト実行
 			result, err := service.GetDocumentWithBlocks(tt.docID, tt.userID)
@@ -293,6 +449,68 @@ func TestGetDocumentWithBlocks(t *testing.T) {
 	}
 }
 
+// TestGetDocumentWithBlocksIncludingDeleted - ごみ箱内の文書のプレビュー取得のテスト
+func TestGetDocumentWithBlocksIncludingDeleted(t *testing.T) {
+	deletedAt := time.Now()
+
+	tests := []struct {
+		name         string
+		doc          *models.Document
+		wantReadOnly bool
+	}{
+		{
+			name:         "正常系：ごみ箱内の文書は読み取り専用",
+			doc:          &models.Document{ID: 1, UserID: 10, IsDeleted: true, DeletedAt: &deletedAt},
+			wantReadOnly: true,
+		},
+		{
+			name:         "正常系：通常の文書は編集可能",
+			doc:          &models.Document{ID: 1, UserID: 10},
+			wantReadOnly: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := &MockDocumentCoreRepository{
+				GetDocumentIncludingDeletedFunc: func(docID, userID int) (*models.Document, error) {
+					return tt.doc, nil
+				},
+			}
+			blockRepo := &MockBlockRepository{
+				GetBlocksByDocumentIDFunc: func(docID int) ([]models.Block, error) {
+					return []models.Block{{ID: 5, DocumentID: docID, Type: "text"}}, nil
+				},
+			}
+			service := NewDocumentService(docRepo, blockRepo, &MockDocumentTreeRepository{}, &MockDocumentTrashRepository{}, nil, nil)
+
+			result, err := service.GetDocumentWithBlocksIncludingDeleted(1, 10)
+			if err != nil {
+				t.Fatalf("GetDocumentWithBlocksIncludingDeleted() error = %v", err)
+			}
+			if result.ReadOnly != tt.wantReadOnly {
+				t.Errorf("ReadOnly = %v, want %v", result.ReadOnly, tt.wantReadOnly)
+			}
+			if len(result.Blocks) != 1 {
+				t.Errorf("Blocks count = %d, want 1", len(result.Blocks))
+			}
+		})
+	}
+
+	t.Run("異常系：他ユーザーの文書は取得できない", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentIncludingDeletedFunc: func(docID, userID int) (*models.Document, error) {
+				return nil, apierror.ErrNotFound
+			},
+		}
+		service := NewDocumentService(docRepo, &MockBlockRepository{}, &MockDocumentTreeRepository{}, &MockDocumentTrashRepository{}, nil, nil)
+
+		if _, err := service.GetDocumentWithBlocksIncludingDeleted(1, 99); !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
 // TestCreateDocument - 文書作成のテスト
 func TestCreateDocument(t *testing.T) {
 	tests := []struct {
@@ -316,6 +534,24 @@ func TestCreateDocument(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "異常系：共同編集者として参加している文書の下には作成できない",
+			doc: &models.Document{
+				UserID:   10,
+				ParentID: func() *int { id := 5; return &id }(),
+				Title:    "子文書",
+			},
+			setupMock: func(docRepo *MockDocumentCoreRepository) {
+				docRepo.GetDocumentFunc = func(docID, userID int) (*models.Document, error) {
+					return &models.Document{ID: docID, UserID: 20, Role: models.DocumentRoleEditor}, nil
+				}
+				docRepo.CreateDocumentFunc = func(doc *models.Document) error {
+					t.Error("CreateDocument should not be called")
+					return nil
+				}
+			},
+			wantErr: true,
+		},
 		{
 			name: "異常系：文書作成失敗",
 			doc: &models.Document{
@@ -338,7 +574,7 @@ func TestCreateDocument(t *testing.T) {
 			docRepo := &MockDocumentCoreRepository{}
 			tt.setupMock(docRepo)
 
-			service := NewDocumentService(docRepo, nil, nil, nil)
+			service := NewDocumentService(docRepo, nil, nil, nil, nil, nil)
 
 			// テスト実行
 			err := service.CreateDocument(tt.doc)
@@ -351,6 +587,108 @@ func TestCreateDocument(t *testing.T) {
 	}
 }
 
+// TestWorkspaceDocumentRoles - ワークスペースの文書を他のメンバーが作成・編集する場合の役割の確認のテスト
+// member / admin は他のメンバーの文書も編集でき、guest は閲覧のみ
+func TestWorkspaceDocumentRoles(t *testing.T) {
+	workspaceID := 1
+	workspaces := NewWorkspaceService(&MockWorkspaceRepository{
+		GetMemberRoleFunc: workspaceRoles(map[int]models.WorkspaceRole{
+			10: models.WorkspaceRoleMember,
+			11: models.WorkspaceRoleGuest,
+			12: models.WorkspaceRoleAdmin,
+		}),
+	}, &MockUserRepository{}, 0, 0, 0, nil)
+
+	// 文書 5 はメンバー 10 が作成したワークスペースの文書（役割は GetDocumentWithBlocks と同じく割り当てる）
+	newService := func(created *bool) *DocumentService {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				doc := &models.Document{ID: docID, UserID: 10, WorkspaceID: &workspaceID, Role: models.DocumentRoleEditor}
+				switch userID {
+				case 10:
+					doc.Role = models.DocumentRoleOwner
+				case 11:
+					doc.Role = models.DocumentRoleViewer
+				}
+				return doc, nil
+			},
+			CreateDocumentFunc: func(doc *models.Document) error {
+				*created = true
+				return nil
+			},
+			UpdateDocumentFunc: func(ctx context.Context, docID, userID int, title, content string) error {
+				return nil
+			},
+		}
+		blockRepo := &MockBlockRepository{
+			UpdateBlocksFunc: func(ctx context.Context, docID, editorID int, blocks []models.Block) error {
+				return nil
+			},
+		}
+		return NewDocumentService(docRepo, blockRepo, nil, nil, workspaces, nil)
+	}
+	parentID := 5
+
+	createTests := []struct {
+		name     string
+		doc      *models.Document
+		wantCode string
+	}{
+		{
+			name: "正常系：member はワークスペースに文書を作成できる",
+			doc:  &models.Document{UserID: 10, Title: "文書", WorkspaceID: &workspaceID},
+		},
+		{
+			name: "正常系：admin は他のメンバーの文書の下に子文書を作成できる",
+			doc:  &models.Document{UserID: 12, Title: "子文書", ParentID: &parentID, WorkspaceID: &workspaceID},
+		},
+		{
+			name:     "異常系：guest はワークスペースに文書を作成できない",
+			doc:      &models.Document{UserID: 11, Title: "文書", WorkspaceID: &workspaceID},
+			wantCode: "WORKSPACE_READ_ONLY",
+		},
+		{
+			name:     "異常系：guest は他のメンバーの文書の下に子文書を作成できない",
+			doc:      &models.Document{UserID: 11, Title: "子文書", ParentID: &parentID, WorkspaceID: &workspaceID},
+			wantCode: "WORKSPACE_READ_ONLY",
+		},
+		{
+			name:     "異常系：メンバーでないユーザーはワークスペースに文書を作成できない",
+			doc:      &models.Document{UserID: 13, Title: "文書", WorkspaceID: &workspaceID},
+			wantCode: "WORKSPACE_ACCESS_DENIED",
+		},
+	}
+	for _, tt := range createTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created bool
+			err := newService(&created).CreateDocument(tt.doc)
+			if tt.wantCode == "" {
+				if err != nil || !created {
+					t.Errorf("CreateDocument() error = %v, created = %v, want created", err, created)
+				}
+				return
+			}
+			assertAppErrorCode(t, err, http.StatusForbidden, tt.wantCode)
+			if created {
+				t.Error("CreateDocument should not be called")
+			}
+		})
+	}
+
+	t.Run("正常系：admin は他のメンバーの文書を編集できる", func(t *testing.T) {
+		var created bool
+		if err := newService(&created).UpdateDocumentWithBlocks(context.Background(), 5, 12, "タイトル", "", nil); err != nil {
+			t.Errorf("UpdateDocumentWithBlocks() error = %v", err)
+		}
+	})
+
+	t.Run("異常系：guest は他のメンバーの文書を編集できない", func(t *testing.T) {
+		var created bool
+		err := newService(&created).UpdateDocumentWithBlocks(context.Background(), 5, 11, "タイトル", "", nil)
+		assertAppErrorCode(t, err, http.StatusForbidden, "DOCUMENT_READ_ONLY")
+	})
+}
+
 // TestUpdateDocumentWithBlocks - 文書とブロック統合更新のテスト
 func TestUpdateDocumentWithBlocks(t *testing.T) {
 	tests := []struct {
@@ -383,10 +721,10 @@ func TestUpdateDocumentWithBlocks(t *testing.T) {
 				docRepo.GetDocumentFunc = func(docID, userID int) (*models.Document, error) {
 					return &models.Document{ID: docID, UserID: userID}, nil
 				}
-				docRepo.UpdateDocumentFunc = func(docID, userID int, title, content string) error {
+				docRepo.UpdateDocumentFunc = func(ctx context.Context, docID, userID int, title, content string) error {
 					return nil
 				}
-				blockRepo.UpdateBlocksFunc = func(docID int, blocks []models.Block) error {
+				blockRepo.UpdateBlocksFunc = func(ctx context.Context, docID, editorID int, blocks []models.Block) error {
 					return nil
 				}
 			},
@@ -403,7 +741,7 @@ func TestUpdateDocumentWithBlocks(t *testing.T) {
 				docRepo.GetDocumentFunc = func(docID, userID int) (*models.Document, error) {
 					return &models.Document{ID: docID, UserID: userID}, nil
 				}
-				docRepo.UpdateDocumentFunc = func(docID, userID int, title, content string) error {
+				docRepo.UpdateDocumentFunc = func(ctx context.Context, docID, userID int, title, content string) error {
 					return errors.New("update failed")
 				}
 			},
@@ -421,10 +759,10 @@ func TestUpdateDocumentWithBlocks(t *testing.T) {
 				docRepo.GetDocumentFunc = func(docID, userID int) (*models.Document, error) {
 					return &models.Document{ID: docID, UserID: userID}, nil
 				}
-				docRepo.UpdateDocumentFunc = func(docID, userID int, title, content string) error {
+				docRepo.UpdateDocumentFunc = func(ctx context.Context, docID, userID int, title, content string) error {
 					return nil
 				}
-				blockRepo.UpdateBlocksFunc = func(docID int, blocks []models.Block) error {
+				blockRepo.UpdateBlocksFunc = func(ctx context.Context, docID, editorID int, blocks []models.Block) error {
 					return errors.New("block update failed")
 				}
 			},
@@ -455,10 +793,10 @@ func TestUpdateDocumentWithBlocks(t *testing.T) {
 			blockRepo := &MockBlockRepository{}
 			tt.setupMocks(docRepo, blockRepo)
 
-			service := NewDocumentService(docRepo, blockRepo, nil, nil)
+			service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
 
 			// テスト実行
-			err := service.UpdateDocumentWithBlocks(tt.docID, tt.userID, tt.title, tt.content, tt.blocks)
+			err := service.UpdateDocumentWithBlocks(context.Background(), tt.docID, tt.userID, tt.title, tt.content, tt.blocks)
 
 			// エラーの検証
 			if (err != nil) != tt.wantErr {
@@ -526,7 +864,7 @@ func TestSoftDeleteDocument(t *testing.T) {
 			trashRepo := &MockDocumentTrashRepository{}
 			tt.setupMocks(docRepo, trashRepo)
 
-			service := NewDocumentService(docRepo, nil, nil, trashRepo)
+			service := NewDocumentService(docRepo, nil, nil, trashRepo, nil, nil)
 
 			err := service.SoftDeleteDocument(tt.docID, tt.userID)
 
@@ -597,7 +935,7 @@ func TestRestoreDocument(t *testing.T) {
 			trashRepo := &MockDocumentTrashRepository{}
 			tt.setupMocks(docRepo, trashRepo)
 
-			service := NewDocumentService(docRepo, nil, nil, trashRepo)
+			service := NewDocumentService(docRepo, nil, nil, trashRepo, nil, nil)
 
 			err := service.RestoreDocument(tt.docID, tt.userID)
 
@@ -611,6 +949,326 @@ func TestRestoreDocument(t *testing.T) {
 	}
 }
 
+// TestGetDocumentTreeNode - サイドバーに通知する文書ツリーのノードの取得のテスト
+func TestGetDocumentTreeNode(t *testing.T) {
+	workspaceID := 3
+	parentID := 1
+	docRepo := &MockDocumentCoreRepository{
+		GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+			if docID == 404 {
+				return nil, apierror.ErrNotFound
+			}
+			// 共同編集者（userID 20）が取得しても所有者は 10
+			return &models.Document{ID: docID, UserID: 10, WorkspaceID: &workspaceID}, nil
+		},
+	}
+	treeRepo := &MockDocumentTreeRepository{
+		GetDocumentTreeFunc: func(userID int, wsID *int) ([]models.DocumentTreeNode, error) {
+			if userID != 10 || wsID == nil || *wsID != workspaceID {
+				t.Errorf("GetDocumentTree(%d, %v), want owner's tree in workspace %d", userID, wsID, workspaceID)
+			}
+			return []models.DocumentTreeNode{{
+				Document: models.Document{ID: 1, UserID: 10, Title: "親"},
+				Children: []models.DocumentTreeNode{{
+					Document: models.Document{ID: 2, UserID: 10, ParentID: &parentID, Title: "子"},
+					Children: []models.DocumentTreeNode{},
+				}},
+			}}, nil
+		},
+	}
+	service := NewDocumentService(docRepo, nil, treeRepo, nil, nil, nil)
+
+	t.Run("正常系：子孫の文書もツリーから探す", func(t *testing.T) {
+		node, err := service.GetDocumentTreeNode(2, 20)
+		if err != nil {
+			t.Fatalf("GetDocumentTreeNode() error = %v", err)
+		}
+		if node.ID != 2 || node.Title != "子" || node.ParentID == nil || *node.ParentID != 1 {
+			t.Errorf("GetDocumentTreeNode() = %+v, want document 2 under 1", node.Document)
+		}
+	})
+
+	t.Run("正常系：子孫を含めて返す", func(t *testing.T) {
+		node, err := service.GetDocumentTreeNode(1, 10)
+		if err != nil {
+			t.Fatalf("GetDocumentTreeNode() error = %v", err)
+		}
+		if len(node.Children) != 1 || node.Children[0].ID != 2 {
+			t.Errorf("Children = %+v, want [2]", node.Children)
+		}
+	})
+
+	t.Run("異常系：ツリーにない文書は見つからない", func(t *testing.T) {
+		_, err := service.GetDocumentTreeNode(5, 10)
+		if !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("GetDocumentTreeNode() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("異常系：アクセスできない文書は見つからない", func(t *testing.T) {
+		_, err := service.GetDocumentTreeNode(404, 10)
+		if !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("GetDocumentTreeNode() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// TestBatchDocumentLoads - GraphQL の同じ階層の文書をまとめて取得するメソッドのテスト
+func TestBatchDocumentLoads(t *testing.T) {
+	workspaceID := 3
+	parentID := 1
+	docRepo := &MockDocumentCoreRepository{
+		GetDocumentsByIDsFunc: func(docIDs []int, userID int) ([]models.Document, error) {
+			// 404 はアクセスできない文書、3 は個人の文書
+			var docs []models.Document
+			for _, id := range docIDs {
+				switch id {
+				case 404:
+				case 3:
+					docs = append(docs, models.Document{ID: id, UserID: 10})
+				default:
+					docs = append(docs, models.Document{ID: id, UserID: 10, WorkspaceID: &workspaceID})
+				}
+			}
+			return docs, nil
+		},
+	}
+	blockRepo := &MockBlockRepository{
+		GetBlocksByDocumentIDsFunc: func(docIDs []int) (map[int][]models.Block, error) {
+			return map[int][]models.Block{1: {{ID: 100, DocumentID: 1}}}, nil
+		},
+	}
+	var treeCalls int
+	treeRepo := &MockDocumentTreeRepository{
+		GetDocumentTreeFunc: func(userID int, wsID *int) ([]models.DocumentTreeNode, error) {
+			treeCalls++
+			if wsID == nil {
+				return []models.DocumentTreeNode{{Document: models.Document{ID: 3, UserID: 10}}}, nil
+			}
+			return []models.DocumentTreeNode{{
+				Document: models.Document{ID: 1, UserID: 10},
+				Children: []models.DocumentTreeNode{{Document: models.Document{ID: 2, UserID: 10, ParentID: &parentID}}},
+			}}, nil
+		},
+	}
+	service := NewDocumentService(docRepo, blockRepo, treeRepo, nil, nil, nil)
+
+	t.Run("正常系：ブロックのない文書は空の一覧、アクセスできない文書は含まない", func(t *testing.T) {
+		blocks, err := service.GetBlocksByDocumentIDs([]int{1, 2, 404}, 20)
+		if err != nil {
+			t.Fatalf("GetBlocksByDocumentIDs() error = %v", err)
+		}
+		if len(blocks) != 2 || len(blocks[1]) != 1 || blocks[2] == nil || len(blocks[2]) != 0 {
+			t.Errorf("GetBlocksByDocumentIDs() = %+v, want blocks of 1 and empty blocks of 2", blocks)
+		}
+		if _, ok := blocks[404]; ok {
+			t.Error("GetBlocksByDocumentIDs() contains inaccessible document 404")
+		}
+	})
+
+	t.Run("正常系：所有者とワークスペースごとにツリーを1回だけ取得する", func(t *testing.T) {
+		treeCalls = 0
+		nodes, err := service.GetDocumentTreeNodes([]int{1, 2, 3, 404}, 20)
+		if err != nil {
+			t.Fatalf("GetDocumentTreeNodes() error = %v", err)
+		}
+		if len(nodes) != 3 || len(nodes[1].Children) != 1 || nodes[2].ID != 2 || nodes[3].ID != 3 {
+			t.Errorf("GetDocumentTreeNodes() = %+v, want nodes of 1, 2 and 3", nodes)
+		}
+		if treeCalls != 2 {
+			t.Errorf("GetDocumentTree calls = %d, want 2", treeCalls)
+		}
+	})
+}
+
+// TestBulkRestoreDocuments - ごみ箱からの一括復元の入力検証のテスト
+func TestBulkRestoreDocuments(t *testing.T) {
+	tests := []struct {
+		name         string
+		ids          []int
+		strategy     models.RestoreStrategy
+		wantStrategy models.RestoreStrategy
+		wantCode     string
+	}{
+		{name: "正常系：strategy の既定は元の親", ids: []int{1, 2}, wantStrategy: models.RestoreToOriginalParent},
+		{name: "正常系：ルートに復元", ids: []int{1}, strategy: models.RestoreToRoot, wantStrategy: models.RestoreToRoot},
+		{name: "異常系：文書の指定なし", ids: nil, wantCode: "INVALID_REQUEST"},
+		{name: "異常系：件数の上限超過", ids: make([]int, models.MaxBulkRestore+1), wantCode: "TOO_MANY_DOCUMENTS"},
+		{name: "異常系：不明な strategy", ids: []int{1}, strategy: "restore-to-parent", wantCode: "INVALID_RESTORE_STRATEGY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotStrategy models.RestoreStrategy
+			trashRepo := &MockDocumentTrashRepository{
+				BulkRestoreDocumentsFunc: func(userID int, ids []int, strategy models.RestoreStrategy) ([]models.RestoreResult, error) {
+					gotStrategy = strategy
+					return []models.RestoreResult{}, nil
+				},
+			}
+			service := NewDocumentService(&MockDocumentCoreRepository{}, &MockBlockRepository{}, &MockDocumentTreeRepository{}, trashRepo, nil, nil)
+
+			_, err := service.BulkRestoreDocuments(10, tt.ids, tt.strategy)
+			if tt.wantCode != "" {
+				var appErr *apierror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BulkRestoreDocuments() error = %v", err)
+			}
+			if gotStrategy != tt.wantStrategy {
+				t.Errorf("strategy = %q, want %q", gotStrategy, tt.wantStrategy)
+			}
+		})
+	}
+}
+
+func TestCopyBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []models.Block
+		docErr   error
+		wantCode string
+		wantErr  error
+	}{
+		{name: "正常系：貼り付け先に保存", blocks: []models.Block{{ID: 5, Type: "text"}}},
+		{name: "異常系：ブロックの指定なし", blocks: nil, wantCode: "INVALID_REQUEST"},
+		{name: "異常系：件数の上限超過", blocks: make([]models.Block, models.MaxCopyBlocks+1), wantCode: "TOO_MANY_BLOCKS"},
+		{name: "異常系：貼り付け先の文書がない", blocks: []models.Block{{ID: 5}}, docErr: apierror.ErrNotFound, wantErr: apierror.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := false
+			docRepo := &MockDocumentCoreRepository{
+				GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+					return &models.Document{ID: docID, UserID: userID}, tt.docErr
+				},
+			}
+			blockRepo := &MockBlockRepository{
+				CopyBlocksFunc: func(docID int, parentID *int, position int, blocks []models.Block) error {
+					copied = true
+					if docID != 2 || position != 3 || parentID != nil {
+						t.Errorf("CopyBlocks(%d, %v, %d) called with unexpected arguments", docID, parentID, position)
+					}
+					return nil
+				},
+			}
+			service := NewDocumentService(docRepo, blockRepo, &MockDocumentTreeRepository{}, &MockDocumentTrashRepository{}, nil, nil)
+
+			err := service.CopyBlocks(2, 10, nil, 3, tt.blocks)
+			switch {
+			case tt.wantCode != "":
+				var appErr *apierror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("error = %v, want code %s", err, tt.wantCode)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("CopyBlocks() error = %v", err)
+			}
+			if copied != (tt.wantCode == "" && tt.wantErr == nil) {
+				t.Errorf("repository called = %v", copied)
+			}
+		})
+	}
+}
+
+func TestCreateSyncedBlock(t *testing.T) {
+	tests := []struct {
+		name      string
+		blockType string
+		wantCode  string
+	}{
+		{name: "正常系：ブロックを同期元として登録", blockType: "text"},
+		{name: "異常系：synced ブロックは同期元にできない", blockType: "synced", wantCode: "BLOCK_ALREADY_SYNCED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := &MockDocumentCoreRepository{
+				GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+					return &models.Document{ID: docID, UserID: userID}, nil
+				},
+			}
+			blockRepo := &MockBlockRepository{
+				GetBlockFunc: func(docID, blockID int) (*models.Block, error) {
+					return &models.Block{ID: blockID, DocumentID: docID, Type: tt.blockType}, nil
+				},
+				CreateSyncedBlockFunc: func(synced *models.SyncedBlock) error {
+					synced.ID = 4
+					return nil
+				},
+			}
+			service := NewDocumentService(docRepo, blockRepo, &MockDocumentTreeRepository{}, &MockDocumentTrashRepository{}, nil, nil)
+
+			synced, err := service.CreateSyncedBlock(2, 10, 7)
+			if tt.wantCode != "" {
+				var appErr *apierror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateSyncedBlock() error = %v", err)
+			}
+			if synced.ID != 4 || synced.UserID != 10 || synced.SourceDocumentID != 2 || synced.SourceBlockID != 7 {
+				t.Errorf("synced = %+v", synced)
+			}
+		})
+	}
+}
+
+func TestSearchDocuments(t *testing.T) {
+	tests := []struct {
+		name      string
+		keyword   string
+		limit     int
+		wantLimit int
+		wantCode  string
+	}{
+		{name: "正常系：既定の件数", keyword: " 議事録 ", wantLimit: models.DefaultSearchLimit},
+		{name: "正常系：上限を超える件数は丸める", keyword: "議事録", limit: 1000, wantLimit: models.MaxSearchLimit},
+		{name: "異常系：空の検索語", keyword: "  ", wantCode: "INVALID_SEARCH_QUERY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotKeyword string
+			var gotLimit int
+			docRepo := &MockDocumentCoreRepository{
+				SearchDocumentsFunc: func(userID int, keyword string, page pagination.Params) (pagination.Page[models.DocumentSearchResult], error) {
+					gotKeyword, gotLimit = keyword, page.Limit
+					return pagination.Page[models.DocumentSearchResult]{Items: []models.DocumentSearchResult{{ID: 1, Title: "定例"}}}, nil
+				},
+			}
+			service := NewDocumentService(docRepo, &MockBlockRepository{}, &MockDocumentTreeRepository{}, &MockDocumentTrashRepository{}, nil, nil)
+
+			results, err := service.SearchDocuments(10, tt.keyword, pagination.Params{Limit: tt.limit})
+			if tt.wantCode != "" {
+				var appErr *apierror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SearchDocuments() error = %v", err)
+			}
+			if len(results.Items) != 1 || gotKeyword != "議事録" || gotLimit != tt.wantLimit {
+				t.Errorf("results = %+v, keyword = %q, limit = %d", results, gotKeyword, gotLimit)
+			}
+		})
+	}
+}
+
 // TestMoveDocument_Cycle - 循環参照／自身を親にする操作が ErrForbidden になることを確認
 func TestMoveDocument_Cycle(t *testing.T) {
 	intPtr := func(n int) *int { return &n }
@@ -658,6 +1316,35 @@ func TestMoveDocument_Cycle(t *testing.T) {
 			wantErr:     true,
 			wantErrType: apierror.ErrForbidden,
 		},
+		{
+			name:      "異常系：共同編集者として参加している文書の下への移動 → 403",
+			docID:     2,
+			newParent: intPtr(7),
+			userID:    10,
+			setupMocks: func(docRepo *MockDocumentCoreRepository, treeRepo *MockDocumentTreeRepository) {
+				docRepo.GetDocumentFunc = func(docID, userID int) (*models.Document, error) {
+					if docID == 7 {
+						return &models.Document{ID: docID, UserID: 20, Role: models.DocumentRoleEditor}, nil
+					}
+					return &models.Document{ID: docID, UserID: userID}, nil
+				}
+			},
+			wantErr:     true,
+			wantErrType: apierror.ErrForbidden,
+		},
+		{
+			name:      "異常系：共同編集者は文書を移動できない → 403",
+			docID:     7,
+			newParent: nil,
+			userID:    10,
+			setupMocks: func(docRepo *MockDocumentCoreRepository, treeRepo *MockDocumentTreeRepository) {
+				docRepo.GetDocumentFunc = func(docID, userID int) (*models.Document, error) {
+					return &models.Document{ID: docID, UserID: 20, Role: models.DocumentRoleEditor}, nil
+				}
+			},
+			wantErr:     true,
+			wantErrType: apierror.ErrForbidden,
+		},
 		{
 			name:      "正常系：兄弟への移動",
 			docID:     2,
@@ -688,7 +1375,7 @@ func TestMoveDocument_Cycle(t *testing.T) {
 			treeRepo := &MockDocumentTreeRepository{}
 			tt.setupMocks(docRepo, treeRepo)
 
-			service := NewDocumentService(docRepo, nil, treeRepo, nil)
+			service := NewDocumentService(docRepo, nil, treeRepo, nil, nil, nil)
 			err := service.MoveDocument(tt.docID, tt.newParent, tt.userID)
 
 			if (err != nil) != tt.wantErr {
@@ -700,3 +1387,488 @@ func TestMoveDocument_Cycle(t *testing.T) {
 		})
 	}
 }
+
+// TestBlockCRUD - 単一ブロック操作のテスト
+// 文書の所有権確認を通過した場合のみリポジトリに委譲されることを確認する
+func TestBlockCRUD(t *testing.T) {
+	t.Run("正常系：作成時に文書IDが設定される", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return &models.Document{ID: docID, UserID: userID}, nil
+			},
+		}
+		var created *models.Block
+		blockRepo := &MockBlockRepository{
+			CreateBlockFunc: func(block *models.Block) error {
+				created = block
+				block.ID = 42
+				return nil
+			},
+		}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		block := &models.Block{Type: "text", Content: json.RawMessage(`{}`), Position: -1}
+		if err := service.CreateBlock(1, 10, block); err != nil {
+			t.Fatalf("CreateBlock() error = %v", err)
+		}
+		if created == nil || created.DocumentID != 1 || block.ID != 42 {
+			t.Errorf("CreateBlock() block = %+v, want document_id=1 id=42", created)
+		}
+	})
+
+	t.Run("異常系：他人またはゴミ箱内の文書は 404", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return nil, apierror.ErrNotFound
+			},
+		}
+		blockRepo := &MockBlockRepository{}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		if err := service.CreateBlock(1, 10, &models.Block{Type: "text"}); !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("CreateBlock() error = %v, want ErrNotFound", err)
+		}
+		if err := service.UpdateBlock(1, 10, &models.Block{ID: 5}); !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("UpdateBlock() error = %v, want ErrNotFound", err)
+		}
+		if err := service.DeleteBlock(1, 10, 5); !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("DeleteBlock() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("異常系：閲覧のみの共同編集者は 403", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return &models.Document{ID: docID, UserID: 20, Role: models.DocumentRoleViewer}, nil
+			},
+		}
+		blockRepo := &MockBlockRepository{}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		err := service.CreateBlock(1, 10, &models.Block{Type: "text"})
+		assertAppErrorCode(t, err, http.StatusForbidden, "DOCUMENT_READ_ONLY")
+		err = service.UpdateBlock(1, 10, &models.Block{ID: 5, Type: "text"})
+		assertAppErrorCode(t, err, http.StatusForbidden, "DOCUMENT_READ_ONLY")
+		err = service.DeleteBlock(1, 10, 5)
+		assertAppErrorCode(t, err, http.StatusForbidden, "DOCUMENT_READ_ONLY")
+	})
+
+	t.Run("正常系：編集できる共同編集者はブロックを更新できる", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return &models.Document{ID: docID, UserID: 20, Role: models.DocumentRoleEditor}, nil
+			},
+		}
+		updated := false
+		blockRepo := &MockBlockRepository{
+			UpdateBlockFunc: func(block *models.Block, editorID int) error {
+				updated = true
+				return nil
+			},
+		}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		if err := service.UpdateBlock(1, 10, &models.Block{ID: 5, Type: "text"}); err != nil {
+			t.Fatalf("UpdateBlock() error = %v", err)
+		}
+		if !updated {
+			t.Error("UpdateBlock should be delegated to the repository")
+		}
+	})
+
+	t.Run("異常系：列レイアウトに置けない種類のブロック", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return &models.Document{ID: docID, UserID: userID}, nil
+			},
+		}
+		blockRepo := &MockBlockRepository{
+			GetBlockFunc: func(docID, blockID int) (*models.Block, error) {
+				return &models.Block{ID: blockID, DocumentID: docID, Type: "columnList"}, nil
+			},
+			CreateBlockFunc: func(block *models.Block) error {
+				t.Error("CreateBlock should not be called")
+				return nil
+			},
+		}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		parentID := 3
+		err := service.CreateBlock(1, 10, &models.Block{ParentBlockID: &parentID, Type: "text"})
+		assertAppErrorCode(t, err, http.StatusBadRequest, "INVALID_BLOCK_HIERARCHY")
+
+		err = service.CreateBlock(1, 10, &models.Block{Type: "column"})
+		assertAppErrorCode(t, err, http.StatusBadRequest, "INVALID_BLOCK_HIERARCHY")
+	})
+
+	t.Run("異常系：存在しないブロックの削除は 404", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return &models.Document{ID: docID, UserID: userID}, nil
+			},
+		}
+		blockRepo := &MockBlockRepository{
+			DeleteBlockFunc: func(docID, blockID int) error {
+				return apierror.ErrNotFound
+			},
+		}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		if err := service.DeleteBlock(1, 10, 999); !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("DeleteBlock() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// TestBlockHistory - ブロックの変更履歴の記録者と参照権限のテスト
+func TestBlockHistory(t *testing.T) {
+	t.Run("正常系：更新したユーザーを編集者として渡す", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return &models.Document{ID: docID, UserID: userID}, nil
+			},
+		}
+		var editor int
+		blockRepo := &MockBlockRepository{
+			UpdateBlockFunc: func(block *models.Block, editorID int) error {
+				editor = editorID
+				return nil
+			},
+		}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		if err := service.UpdateBlock(1, 10, &models.Block{ID: 5, Type: "text"}); err != nil {
+			t.Fatalf("UpdateBlock() error = %v", err)
+		}
+		if editor != 10 {
+			t.Errorf("editorID = %d, want 10", editor)
+		}
+	})
+
+	t.Run("正常系：履歴を返す", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return &models.Document{ID: docID, UserID: userID}, nil
+			},
+		}
+		blockRepo := &MockBlockRepository{
+			ListBlockHistoryFunc: func(docID, blockID int) ([]models.BlockEditEvent, error) {
+				return []models.BlockEditEvent{{ID: 1, DocumentID: docID, BlockID: blockID, OldType: "text", NewType: "text"}}, nil
+			},
+		}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		events, err := service.GetBlockHistory(1, 10, 5)
+		if err != nil {
+			t.Fatalf("GetBlockHistory() error = %v", err)
+		}
+		if len(events) != 1 || events[0].BlockID != 5 {
+			t.Errorf("events = %+v", events)
+		}
+	})
+
+	t.Run("異常系：他人の文書の履歴は 404", func(t *testing.T) {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return nil, apierror.ErrNotFound
+			},
+		}
+		service := NewDocumentService(docRepo, &MockBlockRepository{}, nil, nil, nil, nil)
+
+		if _, err := service.GetBlockHistory(1, 99, 5); !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("GetBlockHistory() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// TestSetBlockCollapsed - トグルの開閉状態の保存のテスト
+func TestSetBlockCollapsed(t *testing.T) {
+	docRepo := &MockDocumentCoreRepository{
+		GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+			return &models.Document{ID: docID, UserID: userID}, nil
+		},
+	}
+	blockTypes := map[int]string{5: "toggle", 6: "text"}
+
+	var saved []string
+	blockRepo := &MockBlockRepository{
+		GetBlockFunc: func(docID, blockID int) (*models.Block, error) {
+			blockType, ok := blockTypes[blockID]
+			if !ok {
+				return nil, apierror.ErrNotFound
+			}
+			return &models.Block{ID: blockID, DocumentID: docID, Type: blockType}, nil
+		},
+		SetBlockCollapsedFunc: func(userID, blockID int, collapsed bool) error {
+			saved = append(saved, fmt.Sprintf("%d:%d:%v", userID, blockID, collapsed))
+			return nil
+		},
+	}
+	service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+	t.Run("正常系：トグルブロックの開閉状態をユーザーごとに保存", func(t *testing.T) {
+		if err := service.SetBlockCollapsed(1, 10, 5, true); err != nil {
+			t.Fatalf("SetBlockCollapsed() error = %v", err)
+		}
+		if len(saved) != 1 || saved[0] != "10:5:true" {
+			t.Errorf("saved = %v, want [10:5:true]", saved)
+		}
+	})
+
+	t.Run("異常系：トグル以外のブロックは折りたためない", func(t *testing.T) {
+		err := service.SetBlockCollapsed(1, 10, 6, true)
+		var appErr *apierror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != "BLOCK_NOT_COLLAPSIBLE" {
+			t.Errorf("SetBlockCollapsed() error = %v, want BLOCK_NOT_COLLAPSIBLE", err)
+		}
+	})
+
+	t.Run("異常系：存在しないブロックは 404", func(t *testing.T) {
+		if err := service.SetBlockCollapsed(1, 10, 999, true); !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("SetBlockCollapsed() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// TestPreviewBlockOperations - 差分操作をメモリ上で適用した結果のテスト
+func TestPreviewBlockOperations(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	current := []models.Block{
+		{ID: 1, Type: "text", Content: json.RawMessage(`"a"`), Position: 0},
+		{ID: 2, Type: "text", Content: json.RawMessage(`"b"`), Position: 1},
+		{ID: 3, Type: "text", Content: json.RawMessage(`"c"`), Position: 2},
+	}
+
+	tests := []struct {
+		name        string
+		ops         []models.BlockOperation
+		wantIDs     []int
+		wantErrType error
+		wantStatus  int
+	}{
+		{
+			name: "挿入・更新・削除・移動を順に適用",
+			ops: []models.BlockOperation{
+				{Op: models.BlockOpInsert, Type: "text", Content: json.RawMessage(`"new"`), Position: intPtr(1)},
+				{Op: models.BlockOpUpdate, BlockID: 2, Content: json.RawMessage(`"b2"`)},
+				{Op: models.BlockOpDelete, BlockID: 1},
+				{Op: models.BlockOpMove, BlockID: 3, Position: intPtr(0)},
+			},
+			wantIDs: []int{3, 0, 2},
+		},
+		{
+			name:    "位置省略の挿入は末尾",
+			ops:     []models.BlockOperation{{Op: models.BlockOpInsert, Type: "text", Content: json.RawMessage(`"z"`)}},
+			wantIDs: []int{1, 2, 3, 0},
+		},
+		{
+			name:        "削除済みブロックへの操作は 409",
+			ops:         []models.BlockOperation{{Op: models.BlockOpUpdate, BlockID: 99, Content: json.RawMessage(`"x"`)}},
+			wantErrType: apierror.ErrConflict,
+		},
+		{
+			name:       "不明な操作は 400",
+			ops:        []models.BlockOperation{{Op: "replace", BlockID: 1}},
+			wantStatus: 400,
+		},
+		{
+			name:       "blockId なしの更新は 400",
+			ops:        []models.BlockOperation{{Op: models.BlockOpDelete}},
+			wantStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := PreviewBlockOperations(current, tt.ops)
+
+			if tt.wantErrType != nil || tt.wantStatus != 0 {
+				if tt.wantErrType != nil && !errors.Is(err, tt.wantErrType) {
+					t.Errorf("PreviewBlockOperations() error = %v, want %v", err, tt.wantErrType)
+				}
+				var appErr *apierror.AppError
+				if tt.wantStatus != 0 && (!errors.As(err, &appErr) || appErr.HTTPStatus != tt.wantStatus) {
+					t.Errorf("PreviewBlockOperations() error = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PreviewBlockOperations() error = %v", err)
+			}
+
+			if len(result) != len(tt.wantIDs) {
+				t.Fatalf("PreviewBlockOperations() len = %d, want %d", len(result), len(tt.wantIDs))
+			}
+			for i, block := range result {
+				if block.ID != tt.wantIDs[i] || block.Position != i {
+					t.Errorf("block[%d] = id %d pos %d, want id %d pos %d", i, block.ID, block.Position, tt.wantIDs[i], i)
+				}
+			}
+		})
+	}
+
+	t.Run("元のスライスは変更しない", func(t *testing.T) {
+		_, _ = PreviewBlockOperations(current, []models.BlockOperation{{Op: models.BlockOpDelete, BlockID: 1}})
+		if current[0].ID != 1 || len(current) != 3 {
+			t.Error("PreviewBlockOperations() modified the input blocks")
+		}
+	})
+}
+
+// TestUpdateDocumentWithOperations - 差分保存が全置換を使わないことを確認
+func TestUpdateDocumentWithOperations(t *testing.T) {
+	docRepo := &MockDocumentCoreRepository{
+		GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+			return &models.Document{ID: docID, UserID: userID}, nil
+		},
+		UpdateDocumentFunc: func(ctx context.Context, docID, userID int, title, content string) error {
+			return nil
+		},
+	}
+	var applied []models.BlockOperation
+	blockRepo := &MockBlockRepository{
+		ApplyOperationsFunc: func(ctx context.Context, docID, editorID int, ops []models.BlockOperation) error {
+			applied = ops
+			return nil
+		},
+		UpdateBlocksFunc: func(ctx context.Context, docID, editorID int, blocks []models.Block) error {
+			t.Error("UpdateBlocks should not be called for differential saves")
+			return nil
+		},
+	}
+	service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+	ops := []models.BlockOperation{{Op: models.BlockOpDelete, BlockID: 3}}
+	if err := service.UpdateDocumentWithOperations(context.Background(), 1, 10, "title", "", ops); err != nil {
+		t.Fatalf("UpdateDocumentWithOperations() error = %v", err)
+	}
+	if len(applied) != 1 || applied[0].BlockID != 3 {
+		t.Errorf("ApplyOperations() ops = %+v, want delete of block 3", applied)
+	}
+}
+
+// TestUpdateMergedDocument - マージした内容を、マージ時点から変わっていない場合のみ保存するテスト
+func TestUpdateMergedDocument(t *testing.T) {
+	docRepo := &MockDocumentCoreRepository{
+		GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+			return &models.Document{ID: docID, UserID: userID}, nil
+		},
+	}
+	merged := &models.MergedDocument{
+		Title:   "マージ後のタイトル",
+		Current: models.DocumentContent{Title: "マージ時点のタイトル"},
+	}
+
+	t.Run("正常系：マージ時点の内容を条件に保存する", func(t *testing.T) {
+		var expected models.DocumentContent
+		blockRepo := &MockBlockRepository{
+			UpdateDocumentIfUnchangedFunc: func(ctx context.Context, docID, editorID int, title, content string, blocks []models.Block, current models.DocumentContent) error {
+				expected = current
+				return nil
+			},
+		}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		if err := service.UpdateMergedDocument(context.Background(), 1, 10, merged); err != nil {
+			t.Fatalf("UpdateMergedDocument() error = %v", err)
+		}
+		if expected.Title != "マージ時点のタイトル" {
+			t.Errorf("expected = %+v, want content at merge time", expected)
+		}
+	})
+
+	t.Run("異常系：マージ後に他の保存があった場合は競合を返す", func(t *testing.T) {
+		blockRepo := &MockBlockRepository{
+			UpdateDocumentIfUnchangedFunc: func(ctx context.Context, docID, editorID int, title, content string, blocks []models.Block, current models.DocumentContent) error {
+				return apierror.ErrConflict
+			},
+		}
+		service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+		err := service.UpdateMergedDocument(context.Background(), 1, 10, merged)
+		if !errors.Is(err, apierror.ErrConflict) {
+			t.Errorf("UpdateMergedDocument() error = %v, want ErrConflict", err)
+		}
+	})
+}
+
+// TestPatchDocument - 部分更新で、パッチに含まれるフィールドのみを保存するテスト
+func TestPatchDocument(t *testing.T) {
+	title := "新しいタイトル"
+	blocks := []models.Block{{Type: "text", Content: json.RawMessage(`"b"`)}}
+
+	tests := []struct {
+		name        string
+		patch       models.DocumentPatch
+		getErr      error
+		wantPatched bool
+		wantBlocks  bool
+		wantErr     bool
+	}{
+		{
+			name:        "正常系：タイトルのみ",
+			patch:       models.DocumentPatch{Title: &title},
+			wantPatched: true,
+		},
+		{
+			name:       "正常系：ブロックのみ（タイトル・内容は保存しない）",
+			patch:      models.DocumentPatch{Blocks: &blocks},
+			wantBlocks: true,
+		},
+		{
+			name:  "正常系：空のパッチは何も保存しない",
+			patch: models.DocumentPatch{},
+		},
+		{
+			name:    "異常系：対象文書が存在しない（404）",
+			patch:   models.DocumentPatch{Title: &title},
+			getErr:  apierror.ErrNotFound,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patchedTitle, patchedContent *string
+			patched, blocksUpdated := false, false
+			docRepo := &MockDocumentCoreRepository{
+				GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+					if tt.getErr != nil {
+						return nil, tt.getErr
+					}
+					return &models.Document{ID: docID, UserID: userID, Title: "元のタイトル"}, nil
+				},
+				PatchDocumentFunc: func(ctx context.Context, docID, userID int, title, content *string) error {
+					patched = true
+					patchedTitle, patchedContent = title, content
+					return nil
+				},
+				UpdateDocumentFunc: func(ctx context.Context, docID, userID int, title, content string) error {
+					t.Error("UpdateDocument should not be called for patches")
+					return nil
+				},
+			}
+			blockRepo := &MockBlockRepository{
+				UpdateBlocksFunc: func(ctx context.Context, docID, editorID int, blocks []models.Block) error {
+					blocksUpdated = true
+					return nil
+				},
+			}
+			service := NewDocumentService(docRepo, blockRepo, nil, nil, nil, nil)
+
+			err := service.PatchDocument(context.Background(), 1, 10, tt.patch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PatchDocument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, apierror.ErrNotFound) {
+				t.Errorf("PatchDocument() error = %v, want ErrNotFound", err)
+			}
+			if patched != tt.wantPatched || blocksUpdated != tt.wantBlocks {
+				t.Errorf("patched = %v, blocksUpdated = %v, want %v, %v", patched, blocksUpdated, tt.wantPatched, tt.wantBlocks)
+			}
+			if patched && (patchedTitle == nil || *patchedTitle != title || patchedContent != nil) {
+				t.Errorf("PatchDocument() title = %v, content = %v, want only title", patchedTitle, patchedContent)
+			}
+		})
+	}
+}
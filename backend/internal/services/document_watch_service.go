@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/mailer"
+	"simple-notion-backend/internal/models"
+)
+
+// watchDigestDocument - 変更をまとめたメールに載せる1件の文書
+type watchDigestDocument struct {
+	ID        int
+	Title     string
+	ChangedAt string
+}
+
+// DocumentWatchService - 文書の変更の購読と、購読者への変更の知らせを担当するサービス
+// アプリ内通知は、同じ文書の未読の通知が残っている間は追加しない（頻繁に編集される文書で通知が溢れないようにする）
+// メールは変更をユーザーごとにまとめ、SendDigests を呼び出した時点で送る
+type DocumentWatchService struct {
+	documentRepo DocumentCoreRepositoryInterface
+	watchRepo    DocumentWatchRepositoryInterface
+	notifier     *NotificationService
+	mailer       MailerInterface // nil の場合はメールでの購読を受け付けない
+}
+
+// NewDocumentWatchService - DocumentWatchServiceを初期化
+func NewDocumentWatchService(
+	documentRepo DocumentCoreRepositoryInterface,
+	watchRepo DocumentWatchRepositoryInterface,
+	notifier *NotificationService,
+	mail MailerInterface,
+) *DocumentWatchService {
+	return &DocumentWatchService{
+		documentRepo: documentRepo,
+		watchRepo:    watchRepo,
+		notifier:     notifier,
+		mailer:       mail,
+	}
+}
+
+// Watch - 文書を購読する（delivery は in_app・email_digest、省略時 in_app。既に購読している場合は知らせ方を変更）
+func (s *DocumentWatchService) Watch(docID, userID int, delivery models.WatchDelivery) (*models.DocumentWatch, error) {
+	if delivery == "" {
+		delivery = models.WatchDeliveryInApp
+	}
+	if !delivery.Valid() {
+		return nil, apierror.NewValidationError("INVALID_WATCH_DELIVERY", "delivery には in_app・email_digest のいずれかを指定してください", nil)
+	}
+	if delivery == models.WatchDeliveryEmailDigest && s.mailer == nil {
+		return nil, apierror.NewValidationError("MAIL_NOT_CONFIGURED", "メールを送信できないため、メールでは購読できません", nil)
+	}
+
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	watch := &models.DocumentWatch{
+		DocumentID:    docID,
+		UserID:        userID,
+		DocumentTitle: doc.Title,
+		Delivery:      delivery,
+	}
+	if err := s.watchRepo.UpsertWatch(watch); err != nil {
+		return nil, err
+	}
+	return watch, nil
+}
+
+// Unwatch - 文書の購読を解除する（購読していない場合は ErrNotFound）
+func (s *DocumentWatchService) Unwatch(docID, userID int) error {
+	err := s.watchRepo.DeleteWatch(docID, userID)
+	if errors.Is(err, apierror.ErrNotFound) {
+		return apierror.NewNotFound("DOCUMENT_NOT_WATCHED", "この文書は購読していません", err)
+	}
+	return err
+}
+
+// ListWatches - ユーザーが購読している文書の一覧を取得
+func (s *DocumentWatchService) ListWatches(userID int) ([]models.DocumentWatch, error) {
+	return s.watchRepo.ListWatches(userID)
+}
+
+// NotifyChange - 文書の変更を、変更したユーザー以外の購読者に知らせる
+// 1人への通知の失敗で他の購読者への通知を止めないよう、個別のエラーはログに残して続行する
+func (s *DocumentWatchService) NotifyChange(ctx context.Context, docID, changedBy int) error {
+	watchers, err := s.watchRepo.ListWatchers(docID, changedBy)
+	if err != nil {
+		return err
+	}
+
+	for _, watcher := range watchers {
+		switch watcher.Delivery {
+		case models.WatchDeliveryEmailDigest:
+			if err := s.watchRepo.MarkChanged(docID, watcher.UserID); err != nil {
+				logctx.Printf(ctx, "Failed to record watched change for user %d on document %d: %v", watcher.UserID, docID, err)
+			}
+		default:
+			if watcher.HasUnread {
+				continue
+			}
+			message := fmt.Sprintf("購読している「%s」が変更されました", watcher.DocumentTitle)
+			link := fmt.Sprintf("/documents/%d", docID)
+			if err := s.notifier.NotifyWithLink(watcher.UserID, &docID, models.NotificationWatch, message, link); err != nil {
+				logctx.Printf(ctx, "Failed to notify watched change to user %d on document %d: %v", watcher.UserID, docID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SendDigests - 前回のメールの後に変更された購読中の文書を、ユーザーごとに1通のメールにまとめて送る
+// メールを送信待ちの列に追加できなかったユーザーの変更は、次回のまとめに含める
+func (s *DocumentWatchService) SendDigests(ctx context.Context) (sent int, err error) {
+	if s.mailer == nil {
+		return 0, nil
+	}
+
+	now := time.Now()
+	entries, err := s.watchRepo.ListPendingDigests()
+	if err != nil {
+		return 0, err
+	}
+
+	// entries はユーザーごとに並んでいる
+	for start := 0; start < len(entries); {
+		end := start
+		for end < len(entries) && entries[end].UserID == entries[start].UserID {
+			end++
+		}
+		if s.sendDigest(ctx, entries[start:end], now) {
+			sent++
+		}
+		start = end
+	}
+	return sent, nil
+}
+
+// sendDigest - 1人のユーザーに変更のまとめを送り、送った文書を送信済みにする
+func (s *DocumentWatchService) sendDigest(ctx context.Context, entries []models.WatchDigestEntry, at time.Time) bool {
+	user := entries[0]
+	documents := make([]watchDigestDocument, 0, len(entries))
+	docIDs := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		documents = append(documents, watchDigestDocument{
+			ID:        entry.DocumentID,
+			Title:     entry.DocumentTitle,
+			ChangedAt: entry.ChangedAt.Format("2006-01-02 15:04"),
+		})
+		docIDs = append(docIDs, entry.DocumentID)
+	}
+
+	name := user.Name
+	if name == "" {
+		name = user.Email
+	}
+	err := s.mailer.SendTemplate(user.Email, mailer.TemplateDocumentWatchDigest, map[string]interface{}{
+		"Name":      name,
+		"Documents": documents,
+	})
+	if err != nil {
+		logctx.Printf(ctx, "Failed to queue watch digest to user %d: %v", user.UserID, err)
+		return false
+	}
+
+	if err := s.watchRepo.MarkDigested(user.UserID, docIDs, at); err != nil {
+		logctx.Printf(ctx, "Failed to mark watch digest sent for user %d: %v", user.UserID, err)
+	}
+	return true
+}
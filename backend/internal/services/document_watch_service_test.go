@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/mailer"
+	"simple-notion-backend/internal/models"
+)
+
+// MockDocumentWatchRepository - DocumentWatchRepositoryのモック
+type MockDocumentWatchRepository struct {
+	UpsertWatchFunc        func(watch *models.DocumentWatch) error
+	DeleteWatchFunc        func(docID, userID int) error
+	ListWatchesFunc        func(userID int) ([]models.DocumentWatch, error)
+	ListWatchersFunc       func(docID, changedBy int) ([]models.DocumentWatcher, error)
+	MarkChangedFunc        func(docID, userID int) error
+	ListPendingDigestsFunc func() ([]models.WatchDigestEntry, error)
+	MarkDigestedFunc       func(userID int, docIDs []int, at time.Time) error
+}
+
+func (m *MockDocumentWatchRepository) UpsertWatch(watch *models.DocumentWatch) error {
+	if m.UpsertWatchFunc != nil {
+		return m.UpsertWatchFunc(watch)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockDocumentWatchRepository) DeleteWatch(docID, userID int) error {
+	if m.DeleteWatchFunc != nil {
+		return m.DeleteWatchFunc(docID, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockDocumentWatchRepository) ListWatches(userID int) ([]models.DocumentWatch, error) {
+	if m.ListWatchesFunc != nil {
+		return m.ListWatchesFunc(userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDocumentWatchRepository) ListWatchers(docID, changedBy int) ([]models.DocumentWatcher, error) {
+	if m.ListWatchersFunc != nil {
+		return m.ListWatchersFunc(docID, changedBy)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDocumentWatchRepository) MarkChanged(docID, userID int) error {
+	if m.MarkChangedFunc != nil {
+		return m.MarkChangedFunc(docID, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockDocumentWatchRepository) ListPendingDigests() ([]models.WatchDigestEntry, error) {
+	if m.ListPendingDigestsFunc != nil {
+		return m.ListPendingDigestsFunc()
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDocumentWatchRepository) MarkDigested(userID int, docIDs []int, at time.Time) error {
+	if m.MarkDigestedFunc != nil {
+		return m.MarkDigestedFunc(userID, docIDs, at)
+	}
+	return errors.New("not implemented")
+}
+
+func TestDocumentWatchService_Watch(t *testing.T) {
+	tests := []struct {
+		name         string
+		delivery     models.WatchDelivery
+		mail         MailerInterface
+		docErr       error
+		wantDelivery models.WatchDelivery
+		wantStatus   int
+		wantCode     string
+		wantErr      error
+	}{
+		{name: "正常系：省略時はアプリ内通知", wantDelivery: models.WatchDeliveryInApp},
+		{name: "正常系：メールでまとめて受け取る", delivery: models.WatchDeliveryEmailDigest, mail: &MockMailer{}, wantDelivery: models.WatchDeliveryEmailDigest},
+		{name: "異常系：不明な知らせ方", delivery: "sms", wantStatus: http.StatusBadRequest, wantCode: "INVALID_WATCH_DELIVERY"},
+		{name: "異常系：メールを送信しない設定", delivery: models.WatchDeliveryEmailDigest, wantStatus: http.StatusBadRequest, wantCode: "MAIL_NOT_CONFIGURED"},
+		{name: "異常系：アクセスできない文書", docErr: apierror.ErrNotFound, wantErr: apierror.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *models.DocumentWatch
+			documentRepo := &MockDocumentCoreRepository{
+				GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+					if tt.docErr != nil {
+						return nil, tt.docErr
+					}
+					return &models.Document{ID: docID, UserID: 1, Title: "設計メモ"}, nil
+				},
+			}
+			watchRepo := &MockDocumentWatchRepository{
+				UpsertWatchFunc: func(watch *models.DocumentWatch) error {
+					saved = watch
+					return nil
+				},
+			}
+			service := NewDocumentWatchService(documentRepo, watchRepo, nil, tt.mail)
+
+			watch, err := service.Watch(3, 2, tt.delivery)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				return
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Watch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Watch() error = %v", err)
+			}
+			if saved == nil || saved.DocumentID != 3 || saved.UserID != 2 || saved.Delivery != tt.wantDelivery {
+				t.Errorf("saved = %+v, want document 3 watched by user 2 with %s", saved, tt.wantDelivery)
+			}
+			if watch.DocumentTitle != "設計メモ" {
+				t.Errorf("DocumentTitle = %q, want 設計メモ", watch.DocumentTitle)
+			}
+		})
+	}
+}
+
+func TestDocumentWatchService_Unwatch(t *testing.T) {
+	watchRepo := &MockDocumentWatchRepository{
+		DeleteWatchFunc: func(docID, userID int) error {
+			return apierror.ErrNotFound
+		},
+	}
+	service := NewDocumentWatchService(ownedDocumentRepo(), watchRepo, nil, nil)
+
+	assertAppErrorCode(t, service.Unwatch(3, 2), http.StatusNotFound, "DOCUMENT_NOT_WATCHED")
+}
+
+func TestDocumentWatchService_NotifyChange(t *testing.T) {
+	var notified []models.Notification
+	var changed []int
+	watchRepo := &MockDocumentWatchRepository{
+		ListWatchersFunc: func(docID, changedBy int) ([]models.DocumentWatcher, error) {
+			if changedBy != 1 {
+				t.Errorf("changedBy = %d, want 1", changedBy)
+			}
+			return []models.DocumentWatcher{
+				{UserID: 2, DocumentTitle: "設計メモ", Delivery: models.WatchDeliveryInApp},
+				{UserID: 3, DocumentTitle: "設計メモ", Delivery: models.WatchDeliveryInApp, HasUnread: true},
+				{UserID: 4, DocumentTitle: "設計メモ", Delivery: models.WatchDeliveryEmailDigest},
+			}, nil
+		},
+		MarkChangedFunc: func(docID, userID int) error {
+			changed = append(changed, userID)
+			return nil
+		},
+	}
+	notifier := NewNotificationService(&MockNotificationRepository{
+		CreateNotificationFunc: func(n *models.Notification) error {
+			notified = append(notified, *n)
+			return nil
+		},
+	})
+	service := NewDocumentWatchService(ownedDocumentRepo(), watchRepo, notifier, &MockMailer{})
+
+	if err := service.NotifyChange(context.Background(), 5, 1); err != nil {
+		t.Fatalf("NotifyChange() error = %v", err)
+	}
+
+	// 未読の通知が残っているユーザーには追加せず、メールで受け取るユーザーは変更日時だけを記録する
+	if len(notified) != 1 {
+		t.Fatalf("notified = %d, want 1", len(notified))
+	}
+	if n := notified[0]; n.UserID != 2 || n.Type != models.NotificationWatch || n.Link != "/documents/5" {
+		t.Errorf("notification = %+v, want watch notification to user 2", n)
+	}
+	if !reflect.DeepEqual(changed, []int{4}) {
+		t.Errorf("changed = %v, want [4]", changed)
+	}
+}
+
+func TestDocumentWatchService_SendDigests(t *testing.T) {
+	changedAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	entries := []models.WatchDigestEntry{
+		{UserID: 2, Email: "bob@example.com", Name: "Bob", DocumentID: 5, DocumentTitle: "設計メモ", ChangedAt: changedAt},
+		{UserID: 2, Email: "bob@example.com", Name: "Bob", DocumentID: 6, DocumentTitle: "議事録", ChangedAt: changedAt},
+		{UserID: 3, Email: "carol@example.com", DocumentID: 5, DocumentTitle: "設計メモ", ChangedAt: changedAt},
+	}
+
+	t.Run("正常系：ユーザーごとに1通にまとめて送信済みにする", func(t *testing.T) {
+		digested := make(map[int][]int)
+		watchRepo := &MockDocumentWatchRepository{
+			ListPendingDigestsFunc: func() ([]models.WatchDigestEntry, error) { return entries, nil },
+			MarkDigestedFunc: func(userID int, docIDs []int, at time.Time) error {
+				digested[userID] = docIDs
+				return nil
+			},
+		}
+		mail := &MockMailer{}
+		service := NewDocumentWatchService(ownedDocumentRepo(), watchRepo, nil, mail)
+
+		sent, err := service.SendDigests(context.Background())
+		if err != nil {
+			t.Fatalf("SendDigests() error = %v", err)
+		}
+		if sent != 2 || len(mail.Sent) != 2 {
+			t.Fatalf("sent = %d (mails %d), want 2", sent, len(mail.Sent))
+		}
+		if mail.Sent[0].To != "bob@example.com" || mail.Sent[0].Template != mailer.TemplateDocumentWatchDigest {
+			t.Errorf("first mail = %+v, want digest to bob", mail.Sent[0])
+		}
+		if !reflect.DeepEqual(digested, map[int][]int{2: {5, 6}, 3: {5}}) {
+			t.Errorf("digested = %v", digested)
+		}
+	})
+
+	t.Run("異常系：送信待ちの列に追加できない場合は次回に含める", func(t *testing.T) {
+		watchRepo := &MockDocumentWatchRepository{
+			ListPendingDigestsFunc: func() ([]models.WatchDigestEntry, error) { return entries, nil },
+			MarkDigestedFunc: func(userID int, docIDs []int, at time.Time) error {
+				t.Errorf("MarkDigested(%d) called, want not called", userID)
+				return nil
+			},
+		}
+		service := NewDocumentWatchService(ownedDocumentRepo(), watchRepo, nil, &MockMailer{Err: mailer.ErrQueueFull})
+
+		sent, err := service.SendDigests(context.Background())
+		if err != nil || sent != 0 {
+			t.Errorf("SendDigests() = %d, %v, want 0, nil", sent, err)
+		}
+	})
+}
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/blockschema"
+	"simple-notion-backend/internal/models"
+)
+
+const (
+	// oembedTimeout - プロバイダーの oEmbed エンドポイントへの問い合わせのタイムアウト
+	oembedTimeout = 5 * time.Second
+	// oembedMaxBody - 読み込む oEmbed レスポンスの最大サイズ
+	oembedMaxBody = 256 << 10
+	// embedIframeAllow - 埋め込みの iframe に許可する機能
+	embedIframeAllow = "autoplay; encrypted-media; fullscreen; picture-in-picture"
+	// embedIframeSandbox - 埋め込みの iframe に付ける sandbox（トップレベルへの遷移やフォーム送信は許可しない）
+	embedIframeSandbox = "allow-scripts allow-same-origin allow-popups allow-presentation"
+)
+
+// embedProvider - oEmbed に対応した埋め込みのプロバイダー
+type embedProvider struct {
+	name     string
+	hosts    []string // 埋め込む URL のホスト
+	endpoint string   // oEmbed エンドポイント
+	// iframeHosts - プロバイダーの HTML に含まれる iframe の src として許可するホスト（空の場合は iframe を使わない）
+	iframeHosts []string
+}
+
+// embedProviders - 対応しているプロバイダー（設定の EMBED_PROVIDERS で使うものを選ぶ）
+var embedProviders = []embedProvider{
+	{
+		name:        "youtube",
+		hosts:       []string{"youtube.com", "www.youtube.com", "m.youtube.com", "youtu.be"},
+		endpoint:    "https://www.youtube.com/oembed",
+		iframeHosts: []string{"www.youtube.com", "www.youtube-nocookie.com"},
+	},
+	{
+		name:        "vimeo",
+		hosts:       []string{"vimeo.com", "www.vimeo.com", "player.vimeo.com"},
+		endpoint:    "https://vimeo.com/api/oembed.json",
+		iframeHosts: []string{"player.vimeo.com"},
+	},
+	{
+		name:     "twitter",
+		hosts:    []string{"twitter.com", "www.twitter.com", "mobile.twitter.com", "x.com", "www.x.com"},
+		endpoint: "https://publish.twitter.com/oembed",
+	},
+	{
+		name:        "figma",
+		hosts:       []string{"figma.com", "www.figma.com"},
+		endpoint:    "https://www.figma.com/api/oembed",
+		iframeHosts: []string{"www.figma.com", "embed.figma.com"},
+	},
+}
+
+// findEmbedProvider - 名前からプロバイダーを探す
+func findEmbedProvider(name string) (embedProvider, bool) {
+	for _, p := range embedProviders {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return embedProvider{}, false
+}
+
+// EmbedService - 外部サービスの URL を oEmbed で解決し、埋め込み用のパラメータを返す
+// 問い合わせ先は許可したプロバイダーの固定のエンドポイントのみで、返された HTML はサニタイズしてから返す
+type EmbedService struct {
+	client    *http.Client
+	providers []embedProvider
+}
+
+// NewEmbedService - EmbedServiceを初期化
+// allowed は使うプロバイダーの名前（youtube / vimeo / twitter / figma）で、不明な名前はエラーになる
+func NewEmbedService(allowed []string) (*EmbedService, error) {
+	providers := make([]embedProvider, 0, len(allowed))
+	for _, name := range allowed {
+		provider, ok := findEmbedProvider(strings.ToLower(strings.TrimSpace(name)))
+		if !ok {
+			return nil, fmt.Errorf("unknown embed provider %q", name)
+		}
+		providers = append(providers, provider)
+	}
+
+	return &EmbedService{
+		client:    &http.Client{Timeout: oembedTimeout},
+		providers: providers,
+	}, nil
+}
+
+// Providers - 許可しているプロバイダーの名前
+func (s *EmbedService) Providers() []string {
+	names := make([]string, len(s.providers))
+	for i, p := range s.providers {
+		names[i] = p.name
+	}
+	return names
+}
+
+// oembedResponse - oEmbed エンドポイントのレスポンス（使う項目のみ）
+type oembedResponse struct {
+	Type         string          `json:"type"`
+	Title        string          `json:"title"`
+	HTML         string          `json:"html"`
+	Width        oembedDimension `json:"width"`
+	Height       oembedDimension `json:"height"`
+	ThumbnailURL string          `json:"thumbnail_url"`
+}
+
+// oembedDimension - 幅・高さ（プロバイダーによって数値・文字列・null のいずれかで返る）
+type oembedDimension int
+
+func (d *oembedDimension) UnmarshalJSON(data []byte) error {
+	var number float64
+	if err := json.Unmarshal(data, &number); err == nil {
+		*d = oembedDimension(number)
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		if n, err := strconv.Atoi(text); err == nil {
+			*d = oembedDimension(n)
+		}
+	}
+	return nil
+}
+
+// Resolve - URL を oEmbed で解決する
+// 許可していないプロバイダーの URL は 400、埋め込めない（非公開・存在しない）場合は 404、
+// プロバイダーへの問い合わせに失敗した場合は 502 を返す
+func (s *EmbedService) Resolve(ctx context.Context, rawURL string, maxWidth int) (*models.EmbedContent, error) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, apierror.NewValidationError("INVALID_EMBED_URL", "埋め込むURLが不正です", err)
+	}
+
+	provider, ok := s.providerFor(target.Hostname())
+	if !ok {
+		return nil, apierror.NewValidationError("EMBED_PROVIDER_NOT_ALLOWED",
+			"このサービスの埋め込みには対応していません", nil).
+			WithDetails(map[string]interface{}{"providers": s.Providers()})
+	}
+
+	resp, err := s.fetch(ctx, provider, target.String(), maxWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	sanitized, iframe := sanitizeEmbedHTML(resp.HTML, provider.iframeHosts)
+	if iframe != nil {
+		if iframe.Width == 0 {
+			iframe.Width = int(resp.Width)
+		}
+		if iframe.Height == 0 {
+			iframe.Height = int(resp.Height)
+		}
+	}
+
+	return &models.EmbedContent{
+		URL:          target.String(),
+		Provider:     provider.name,
+		Type:         resp.Type,
+		Title:        truncateRunes(resp.Title, linkPreviewMaxTitle),
+		HTML:         sanitized,
+		Iframe:       iframe,
+		ThumbnailURL: resolvePreviewImage(resp.ThumbnailURL, nil),
+		Width:        int(resp.Width),
+		Height:       int(resp.Height),
+	}, nil
+}
+
+// providerFor - ホストに対応する、許可されたプロバイダーを探す
+func (s *EmbedService) providerFor(host string) (embedProvider, bool) {
+	host = strings.ToLower(host)
+	for _, p := range s.providers {
+		for _, h := range p.hosts {
+			if host == h {
+				return p, true
+			}
+		}
+	}
+	return embedProvider{}, false
+}
+
+// fetch - プロバイダーの oEmbed エンドポイントに問い合わせる
+func (s *EmbedService) fetch(ctx context.Context, provider embedProvider, target string, maxWidth int) (*oembedResponse, error) {
+	params := url.Values{"url": {target}, "format": {"json"}}
+	if maxWidth > 0 {
+		params.Set("maxwidth", strconv.Itoa(maxWidth))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, apierror.NewInternal(fmt.Errorf("failed to create oembed request: %w", err))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, apierror.NewBadGateway("EMBED_RESOLVE_FAILED", "埋め込み情報を取得できませんでした",
+			fmt.Errorf("oembed request to %s failed: %w", provider.name, err))
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound:
+		return nil, apierror.NewNotFound("EMBED_NOT_FOUND", "埋め込めないコンテンツです（非公開または存在しません）",
+			fmt.Errorf("oembed %s returned status %d", provider.name, resp.StatusCode))
+	case resp.StatusCode != http.StatusOK:
+		return nil, apierror.NewBadGateway("EMBED_RESOLVE_FAILED", "埋め込み情報を取得できませんでした",
+			fmt.Errorf("oembed %s returned status %d", provider.name, resp.StatusCode))
+	}
+
+	var result oembedResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, oembedMaxBody)).Decode(&result); err != nil {
+		return nil, apierror.NewBadGateway("EMBED_RESOLVE_FAILED", "埋め込み情報を取得できませんでした",
+			fmt.Errorf("failed to decode oembed response from %s: %w", provider.name, err))
+	}
+	return &result, nil
+}
+
+// embedAllowedTags - サニタイズ後の HTML に残すタグと、残す属性
+// iframe は src を検査したうえでサーバーが属性を組み立てる
+var embedAllowedTags = map[atom.Atom][]string{
+	atom.Blockquote: {"class", "lang", "dir"},
+	atom.P:          {"lang", "dir"},
+	atom.A:          {"href"},
+	atom.Br:         nil,
+	atom.Iframe:     nil,
+}
+
+// embedDroppedTags - 中身ごと取り除くタグ
+var embedDroppedTags = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Object:   true,
+	atom.Embed:    true,
+}
+
+// sanitizeEmbedHTML - プロバイダーの HTML から許可したタグ・属性だけを残す
+// iframe は src が iframeHosts の https URL の場合のみ残し、その iframe のパラメータも返す
+// 出力を再度サニタイズしても変わらない（保存済みの content の検証に使う）
+func sanitizeEmbedHTML(raw string, iframeHosts []string) (string, *models.EmbedIframe) {
+	var sb strings.Builder
+	var iframe *models.EmbedIframe
+	open := make(map[atom.Atom]int)
+	dropping := atom.Atom(0)
+
+	tokenizer := html.NewTokenizer(strings.NewReader(raw))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		if dropping != 0 {
+			if tt == html.EndTagToken && token.DataAtom == dropping {
+				dropping = 0
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			sb.WriteString(html.EscapeString(token.Data))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if embedDroppedTags[token.DataAtom] {
+				if tt == html.StartTagToken {
+					dropping = token.DataAtom
+				}
+				continue
+			}
+			allowedAttrs, ok := embedAllowedTags[token.DataAtom]
+			if !ok {
+				continue
+			}
+
+			if token.DataAtom == atom.Iframe {
+				candidate := embedIframeFromToken(token, iframeHosts)
+				if candidate == nil || iframe != nil {
+					// 許可していない iframe と、2つ目以降の iframe は中身ごと取り除く
+					if tt == html.StartTagToken {
+						dropping = atom.Iframe
+					}
+					continue
+				}
+				iframe = candidate
+				sb.WriteString(renderEmbedIframe(iframe))
+				if tt == html.StartTagToken {
+					dropping = atom.Iframe
+				}
+				continue
+			}
+
+			sb.WriteString("<" + token.DataAtom.String())
+			for _, name := range allowedAttrs {
+				value, ok := tokenAttr(token, name)
+				if !ok {
+					continue
+				}
+				if name == "href" {
+					u, err := url.Parse(value)
+					if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+						continue
+					}
+				}
+				sb.WriteString(fmt.Sprintf(` %s="%s"`, name, html.EscapeString(value)))
+			}
+			if token.DataAtom == atom.A {
+				sb.WriteString(` rel="noopener noreferrer nofollow" target="_blank"`)
+			}
+			sb.WriteString(">")
+			if token.DataAtom != atom.Br {
+				open[token.DataAtom]++
+			}
+
+		case html.EndTagToken:
+			if open[token.DataAtom] > 0 {
+				open[token.DataAtom]--
+				sb.WriteString("</" + token.DataAtom.String() + ">")
+			}
+		}
+	}
+
+	// 閉じられていないタグを閉じる（外側の HTML を壊さないため）
+	for _, tag := range []atom.Atom{atom.A, atom.P, atom.Blockquote} {
+		for ; open[tag] > 0; open[tag]-- {
+			sb.WriteString("</" + tag.String() + ">")
+		}
+	}
+
+	return strings.TrimSpace(sb.String()), iframe
+}
+
+// embedIframeFromToken - iframe タグの src が許可したホストの https URL であればパラメータを返す
+func embedIframeFromToken(token html.Token, iframeHosts []string) *models.EmbedIframe {
+	src, _ := tokenAttr(token, "src")
+	if !isAllowedIframeSrc(src, iframeHosts) {
+		return nil
+	}
+	width, _ := tokenAttr(token, "width")
+	height, _ := tokenAttr(token, "height")
+	w, _ := strconv.Atoi(width)
+	h, _ := strconv.Atoi(height)
+	return &models.EmbedIframe{
+		Src:     src,
+		Width:   w,
+		Height:  h,
+		Allow:   embedIframeAllow,
+		Sandbox: embedIframeSandbox,
+	}
+}
+
+// isAllowedIframeSrc - iframe の src が許可したホストの https URL かどうか
+func isAllowedIframeSrc(src string, iframeHosts []string) bool {
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	for _, host := range iframeHosts {
+		if strings.EqualFold(u.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderEmbedIframe - サーバーが決めた属性で iframe タグを組み立てる
+func renderEmbedIframe(iframe *models.EmbedIframe) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<iframe src="%s"`, html.EscapeString(iframe.Src)))
+	if iframe.Width > 0 {
+		sb.WriteString(fmt.Sprintf(` width="%d"`, iframe.Width))
+	}
+	if iframe.Height > 0 {
+		sb.WriteString(fmt.Sprintf(` height="%d"`, iframe.Height))
+	}
+	sb.WriteString(fmt.Sprintf(` allow="%s" sandbox="%s" loading="lazy" referrerpolicy="strict-origin-when-cross-origin" frameborder="0" allowfullscreen></iframe>`,
+		html.EscapeString(iframe.Allow), html.EscapeString(iframe.Sandbox)))
+	return sb.String()
+}
+
+// tokenAttr - タグの属性値を取得する
+func tokenAttr(token html.Token, name string) (string, bool) {
+	for _, attr := range token.Attr {
+		if attr.Namespace == "" && attr.Key == name {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// checkEmbedContent - embed ブロックの content を検証する（ブロックタイプのレジストリから呼ばれる）
+// クライアントが任意の HTML や iframe を保存できないよう、サニタイズ済みの形であることを確認する
+func checkEmbedContent(content json.RawMessage) error {
+	embed, err := models.DecodeEmbedContent(content)
+	if err != nil {
+		return &blockschema.ValidationError{Path: "content", Message: "invalid JSON"}
+	}
+	if !embed.HasWebURL() {
+		return &blockschema.ValidationError{Path: "content.url", Message: "must be an absolute http(s) URL"}
+	}
+
+	var iframeHosts []string
+	if embed.HTML != "" || embed.Iframe != nil {
+		provider, ok := findEmbedProvider(embed.Provider)
+		if !ok {
+			return &blockschema.ValidationError{Path: "content.provider", Message: fmt.Sprintf("unknown embed provider %q", embed.Provider)}
+		}
+		iframeHosts = provider.iframeHosts
+	}
+
+	if embed.HTML != "" {
+		if sanitized, _ := sanitizeEmbedHTML(embed.HTML, iframeHosts); sanitized != embed.HTML {
+			return &blockschema.ValidationError{Path: "content.html", Message: "must be sanitized by the embed resolver"}
+		}
+	}
+	if embed.Iframe != nil {
+		if !isAllowedIframeSrc(embed.Iframe.Src, iframeHosts) {
+			return &blockschema.ValidationError{Path: "content.iframe.src", Message: "is not allowed for the provider"}
+		}
+		if embed.Iframe.Sandbox != embedIframeSandbox {
+			return &blockschema.ValidationError{Path: "content.iframe.sandbox", Message: "must not be changed"}
+		}
+	}
+	return nil
+}
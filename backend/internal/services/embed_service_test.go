@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewEmbedService(t *testing.T) {
+	service, err := NewEmbedService([]string{"YouTube", " figma "})
+	if err != nil {
+		t.Fatalf("NewEmbedService() error = %v", err)
+	}
+	if got := strings.Join(service.Providers(), ","); got != "youtube,figma" {
+		t.Errorf("Providers() = %s, want youtube,figma", got)
+	}
+
+	if _, err := NewEmbedService([]string{"myspace"}); err == nil {
+		t.Error("NewEmbedService() should reject unknown providers")
+	}
+}
+
+func TestSanitizeEmbedHTML(t *testing.T) {
+	youtubeHosts := []string{"www.youtube.com"}
+
+	t.Run("正常系：許可したホストの iframe", func(t *testing.T) {
+		raw := `<iframe width="560" height="315" src="https://www.youtube.com/embed/abc" onload="alert(1)" frameborder="0" allowfullscreen></iframe>`
+
+		out, iframe := sanitizeEmbedHTML(raw, youtubeHosts)
+		if iframe == nil || iframe.Src != "https://www.youtube.com/embed/abc" || iframe.Width != 560 || iframe.Height != 315 {
+			t.Fatalf("iframe = %+v", iframe)
+		}
+		if iframe.Sandbox != embedIframeSandbox {
+			t.Errorf("Sandbox = %q, want %q", iframe.Sandbox, embedIframeSandbox)
+		}
+		if strings.Contains(out, "onload") || !strings.Contains(out, `sandbox="`) {
+			t.Errorf("sanitizeEmbedHTML() = %s", out)
+		}
+	})
+
+	t.Run("異常系：許可していないホストの iframe は取り除く", func(t *testing.T) {
+		out, iframe := sanitizeEmbedHTML(`<iframe src="https://evil.example/embed"></iframe>`, youtubeHosts)
+		if iframe != nil || out != "" {
+			t.Errorf("sanitizeEmbedHTML() = (%q, %+v), want empty", out, iframe)
+		}
+	})
+
+	t.Run("正常系：ツイートの blockquote からスクリプトを取り除く", func(t *testing.T) {
+		raw := `<blockquote class="twitter-tweet" style="x"><p lang="ja" dir="ltr">こんにちは <a href="https://t.co/abc" onclick="x">link</a></p>&mdash; user <a href="javascript:alert(1)">date</a></blockquote>
+<script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`
+
+		out, iframe := sanitizeEmbedHTML(raw, nil)
+		want := `<blockquote class="twitter-tweet"><p lang="ja" dir="ltr">こんにちは <a href="https://t.co/abc" rel="noopener noreferrer nofollow" target="_blank">link</a></p>— user <a rel="noopener noreferrer nofollow" target="_blank">date</a></blockquote>`
+		if iframe != nil || out != want {
+			t.Errorf("sanitizeEmbedHTML() =\n%s\nwant\n%s", out, want)
+		}
+	})
+
+	t.Run("正常系：サニタイズ済みの HTML は変わらない", func(t *testing.T) {
+		inputs := []string{
+			`<iframe src="https://www.youtube.com/embed/abc?a=1&b=2" width="560"></iframe>`,
+			`<blockquote class="q"><p>"引用" &amp; <b>太字</b></p><br/></blockquote>`,
+			`<p>閉じていない <a href="https://example.com">リンク`,
+		}
+		for _, raw := range inputs {
+			once, _ := sanitizeEmbedHTML(raw, youtubeHosts)
+			twice, _ := sanitizeEmbedHTML(once, youtubeHosts)
+			if once != twice {
+				t.Errorf("sanitizeEmbedHTML() is not idempotent:\n%s\n%s", once, twice)
+			}
+		}
+	})
+}
+
+func TestEmbedService_Resolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case "https://www.youtube.com/watch?v=private":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "https://www.youtube.com/watch?v=broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			if r.URL.Query().Get("format") != "json" || r.URL.Query().Get("maxwidth") != "640" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"type":          "video",
+				"title":         "動画",
+				"html":          `<iframe width="640" height="360" src="https://www.youtube.com/embed/abc"></iframe><script>alert(1)</script>`,
+				"width":         "640",
+				"height":        360,
+				"thumbnail_url": "https://i.ytimg.com/vi/abc/hqdefault.jpg",
+			})
+		}
+	}))
+	defer server.Close()
+
+	service, err := NewEmbedService([]string{"youtube"})
+	if err != nil {
+		t.Fatalf("NewEmbedService() error = %v", err)
+	}
+	service.providers[0].endpoint = server.URL
+
+	t.Run("正常系：サニタイズした HTML と iframe を返す", func(t *testing.T) {
+		embed, err := service.Resolve(context.Background(), "https://www.youtube.com/watch?v=abc", 640)
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if embed.Provider != "youtube" || embed.Type != "video" || embed.Title != "動画" || embed.Width != 640 || embed.Height != 360 {
+			t.Errorf("Resolve() = %+v", embed)
+		}
+		if embed.Iframe == nil || embed.Iframe.Src != "https://www.youtube.com/embed/abc" {
+			t.Errorf("Iframe = %+v", embed.Iframe)
+		}
+		if strings.Contains(embed.HTML, "<script") {
+			t.Errorf("HTML = %s, want sanitized", embed.HTML)
+		}
+
+		// 解決結果はそのまま embed ブロックとして保存できる
+		content, _ := json.Marshal(embed)
+		if err := checkEmbedContent(content); err != nil {
+			t.Errorf("checkEmbedContent() error = %v", err)
+		}
+	})
+
+	t.Run("異常系：許可していないプロバイダー", func(t *testing.T) {
+		_, err := service.Resolve(context.Background(), "https://vimeo.com/1", 0)
+		assertAppErrorCode(t, err, http.StatusBadRequest, "EMBED_PROVIDER_NOT_ALLOWED")
+	})
+
+	t.Run("異常系：不正な URL", func(t *testing.T) {
+		_, err := service.Resolve(context.Background(), "javascript:alert(1)", 0)
+		assertAppErrorCode(t, err, http.StatusBadRequest, "INVALID_EMBED_URL")
+	})
+
+	t.Run("異常系：非公開のコンテンツ", func(t *testing.T) {
+		_, err := service.Resolve(context.Background(), "https://www.youtube.com/watch?v=private", 640)
+		assertAppErrorCode(t, err, http.StatusNotFound, "EMBED_NOT_FOUND")
+	})
+
+	t.Run("異常系：プロバイダーのエラー", func(t *testing.T) {
+		_, err := service.Resolve(context.Background(), "https://www.youtube.com/watch?v=broken", 640)
+		assertAppErrorCode(t, err, http.StatusBadGateway, "EMBED_RESOLVE_FAILED")
+	})
+}
+
+func TestCheckEmbedContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "正常系：URL のみ", content: `{"url":"https://www.figma.com/file/abc"}`},
+		{name: "正常系：JSON 文字列", content: `"{\"url\":\"https://x.com/user/status/1\"}"`},
+		{name: "異常系：http(s) 以外の URL", content: `{"url":"javascript:alert(1)"}`, wantErr: true},
+		{name: "異常系：サニタイズされていない HTML", content: `{"url":"https://x.com/a","provider":"twitter","html":"<script>alert(1)</script>"}`, wantErr: true},
+		{name: "異常系：プロバイダーのない HTML", content: `{"url":"https://x.com/a","html":"<p>a</p>"}`, wantErr: true},
+		{name: "異常系：許可していない iframe", content: `{"url":"https://www.youtube.com/watch?v=1","provider":"youtube","iframe":{"src":"https://evil.example/","sandbox":"` + embedIframeSandbox + `"}}`, wantErr: true},
+		{name: "異常系：sandbox の変更", content: `{"url":"https://www.youtube.com/watch?v=1","provider":"youtube","iframe":{"src":"https://www.youtube.com/embed/1","sandbox":"allow-top-navigation"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkEmbedContent(json.RawMessage(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkEmbedContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+)
+
+// ExpirationService - 文書の有効期限（自己消去）を担当するサービス
+type ExpirationService struct {
+	documentRepo   DocumentCoreRepositoryInterface
+	trashRepo      DocumentTrashRepositoryInterface
+	expirationRepo ExpirationRepositoryInterface
+	notifier       *NotificationService
+	warnBefore     time.Duration
+}
+
+// NewExpirationService - ExpirationServiceを初期化
+// warnBefore は期限の何時間前に事前警告を通知するか
+func NewExpirationService(
+	documentRepo DocumentCoreRepositoryInterface,
+	trashRepo DocumentTrashRepositoryInterface,
+	expirationRepo ExpirationRepositoryInterface,
+	notifier *NotificationService,
+	warnBefore time.Duration,
+) *ExpirationService {
+	return &ExpirationService{
+		documentRepo:   documentRepo,
+		trashRepo:      trashRepo,
+		expirationRepo: expirationRepo,
+		notifier:       notifier,
+		warnBefore:     warnBefore,
+	}
+}
+
+// GetExpiration - 文書の有効期限を取得（未設定の場合は nil）
+func (s *ExpirationService) GetExpiration(docID, userID int) (*models.DocumentExpiration, error) {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	return s.expirationRepo.GetExpiration(docID)
+}
+
+// SetExpiration - 文書の有効期限を設定
+func (s *ExpirationService) SetExpiration(docID, userID int, expiresAt time.Time, action models.ExpirationAction) (*models.DocumentExpiration, error) {
+	if action == "" {
+		action = models.ExpirationActionTrash
+	}
+	if !action.IsValid() {
+		return nil, apierror.NewValidationError("INVALID_EXPIRATION_ACTION", "action には trash または delete を指定してください", nil)
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil, apierror.NewValidationError("INVALID_EXPIRATION", "有効期限には未来の日時を指定してください", nil)
+	}
+
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+
+	exp := &models.DocumentExpiration{
+		DocumentID: docID,
+		ExpiresAt:  expiresAt,
+		Action:     action,
+	}
+	if err := s.expirationRepo.SaveExpiration(exp); err != nil {
+		return nil, err
+	}
+
+	return exp, nil
+}
+
+// ClearExpiration - 文書の有効期限を解除
+func (s *ExpirationService) ClearExpiration(docID, userID int) error {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return err
+	}
+	return s.expirationRepo.DeleteExpiration(docID)
+}
+
+// ProcessExpirations - 事前警告の通知と、期限到達した文書の削除を行う（スケジューラーから定期実行）
+// 1件の失敗で他の文書の処理を止めないよう、個別のエラーはログに残して続行する
+func (s *ExpirationService) ProcessExpirations(ctx context.Context) (warned, expired int, err error) {
+	toWarn, err := s.expirationRepo.ListExpirationsToWarn(s.warnBefore)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list expirations to warn: %w", err)
+	}
+	for _, doc := range toWarn {
+		if err := s.warn(doc); err != nil {
+			logctx.Printf(ctx, "failed to warn expiration for document %d: %v", doc.DocumentID, err)
+			continue
+		}
+		warned++
+	}
+
+	due, err := s.expirationRepo.ListDueExpirations()
+	if err != nil {
+		return warned, 0, fmt.Errorf("failed to list due expirations: %w", err)
+	}
+	for _, doc := range due {
+		if err := s.expire(doc); err != nil {
+			logctx.Printf(ctx, "failed to expire document %d: %v", doc.DocumentID, err)
+			continue
+		}
+		expired++
+	}
+
+	return warned, expired, nil
+}
+
+// warn - 期限が近いことを所有者に通知
+func (s *ExpirationService) warn(doc models.ExpiringDocument) error {
+	docID := doc.DocumentID
+	message := fmt.Sprintf("「%s」は %s に%sされます", doc.Title,
+		doc.ExpiresAt.Format("2006-01-02 15:04"), expirationActionLabel(doc.Action))
+	if err := s.notifier.Notify(doc.UserID, &docID, models.NotificationExpiryWarning, message); err != nil {
+		return err
+	}
+	return s.expirationRepo.MarkWarned(docID)
+}
+
+// expire - 期限到達した文書をごみ箱へ移動または完全削除し、所有者に通知
+func (s *ExpirationService) expire(doc models.ExpiringDocument) error {
+	var err error
+	switch doc.Action {
+	case models.ExpirationActionDelete:
+		err = s.trashRepo.PermanentDeleteDocument(doc.DocumentID, doc.UserID)
+	default:
+		err = s.trashRepo.SoftDeleteDocument(doc.DocumentID, doc.UserID)
+	}
+	// 既に削除されている文書は期限設定だけを片付ける
+	if err != nil && !errors.Is(err, apierror.ErrNotFound) {
+		return err
+	}
+
+	// 完全削除の場合は ON DELETE CASCADE で期限設定も消えるため、ごみ箱移動時のみ明示的に解除する
+	if doc.Action != models.ExpirationActionDelete || err != nil {
+		if err := s.expirationRepo.DeleteExpiration(doc.DocumentID); err != nil {
+			return err
+		}
+	}
+
+	var notifyDocID *int
+	if doc.Action != models.ExpirationActionDelete {
+		docID := doc.DocumentID
+		notifyDocID = &docID
+	}
+	message := fmt.Sprintf("「%s」は有効期限に達したため%sされました", doc.Title, expirationActionLabel(doc.Action))
+	return s.notifier.Notify(doc.UserID, notifyDocID, models.NotificationExpired, message)
+}
+
+// expirationActionLabel - 通知メッセージ用の動作名
+func expirationActionLabel(action models.ExpirationAction) string {
+	if action == models.ExpirationActionDelete {
+		return "完全削除"
+	}
+	return "ごみ箱へ移動"
+}
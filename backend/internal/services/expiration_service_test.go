@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
+
+// MockExpirationRepository - ExpirationRepositoryのモック
+type MockExpirationRepository struct {
+	GetExpirationFunc         func(docID int) (*models.DocumentExpiration, error)
+	SaveExpirationFunc        func(exp *models.DocumentExpiration) error
+	DeleteExpirationFunc      func(docID int) error
+	ListExpirationsToWarnFunc func(warnBefore time.Duration) ([]models.ExpiringDocument, error)
+	MarkWarnedFunc            func(docID int) error
+	ListDueExpirationsFunc    func() ([]models.ExpiringDocument, error)
+}
+
+func (m *MockExpirationRepository) GetExpiration(docID int) (*models.DocumentExpiration, error) {
+	if m.GetExpirationFunc != nil {
+		return m.GetExpirationFunc(docID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockExpirationRepository) SaveExpiration(exp *models.DocumentExpiration) error {
+	if m.SaveExpirationFunc != nil {
+		return m.SaveExpirationFunc(exp)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockExpirationRepository) DeleteExpiration(docID int) error {
+	if m.DeleteExpirationFunc != nil {
+		return m.DeleteExpirationFunc(docID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockExpirationRepository) ListExpirationsToWarn(warnBefore time.Duration) ([]models.ExpiringDocument, error) {
+	if m.ListExpirationsToWarnFunc != nil {
+		return m.ListExpirationsToWarnFunc(warnBefore)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockExpirationRepository) MarkWarned(docID int) error {
+	if m.MarkWarnedFunc != nil {
+		return m.MarkWarnedFunc(docID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockExpirationRepository) ListDueExpirations() ([]models.ExpiringDocument, error) {
+	if m.ListDueExpirationsFunc != nil {
+		return m.ListDueExpirationsFunc()
+	}
+	return nil, errors.New("not implemented")
+}
+
+// MockNotificationRepository - NotificationRepositoryのモック
+type MockNotificationRepository struct {
+	CreateNotificationFunc func(n *models.Notification) error
+	ListNotificationsFunc  func(userID int, unreadOnly bool, page pagination.Params) (pagination.Page[models.Notification], error)
+	MarkReadFunc           func(id, userID int) error
+}
+
+func (m *MockNotificationRepository) CreateNotification(n *models.Notification) error {
+	if m.CreateNotificationFunc != nil {
+		return m.CreateNotificationFunc(n)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockNotificationRepository) ListNotifications(userID int, unreadOnly bool, page pagination.Params) (pagination.Page[models.Notification], error) {
+	if m.ListNotificationsFunc != nil {
+		return m.ListNotificationsFunc(userID, unreadOnly, page)
+	}
+	return pagination.Page[models.Notification]{}, errors.New("not implemented")
+}
+
+func (m *MockNotificationRepository) MarkRead(id, userID int) error {
+	if m.MarkReadFunc != nil {
+		return m.MarkReadFunc(id, userID)
+	}
+	return errors.New("not implemented")
+}
+
+// TestSetExpiration - 有効期限設定のテスト
+func TestSetExpiration(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		action    models.ExpirationAction
+		wantCode  string
+		wantSaved models.ExpirationAction
+	}{
+		{
+			name:      "正常系：action 省略時は trash",
+			expiresAt: time.Now().Add(time.Hour),
+			wantSaved: models.ExpirationActionTrash,
+		},
+		{
+			name:      "正常系：完全削除を指定",
+			expiresAt: time.Now().Add(time.Hour),
+			action:    models.ExpirationActionDelete,
+			wantSaved: models.ExpirationActionDelete,
+		},
+		{
+			name:      "異常系：過去の日時",
+			expiresAt: time.Now().Add(-time.Hour),
+			wantCode:  "INVALID_EXPIRATION",
+		},
+		{
+			name:      "異常系：不明な action",
+			expiresAt: time.Now().Add(time.Hour),
+			action:    "archive",
+			wantCode:  "INVALID_EXPIRATION_ACTION",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := &MockDocumentCoreRepository{
+				GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+					return &models.Document{ID: docID, UserID: userID}, nil
+				},
+			}
+			var saved models.ExpirationAction
+			expRepo := &MockExpirationRepository{
+				SaveExpirationFunc: func(exp *models.DocumentExpiration) error {
+					saved = exp.Action
+					return nil
+				},
+			}
+
+			service := NewExpirationService(docRepo, &MockDocumentTrashRepository{}, expRepo,
+				NewNotificationService(&MockNotificationRepository{}), time.Hour)
+			_, err := service.SetExpiration(1, 10, tt.expiresAt, tt.action)
+
+			if tt.wantCode != "" {
+				var appErr *apierror.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("SetExpiration() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetExpiration() unexpected error: %v", err)
+			}
+			if saved != tt.wantSaved {
+				t.Errorf("saved action = %s, want %s", saved, tt.wantSaved)
+			}
+		})
+	}
+}
+
+// TestProcessExpirations - 有効期限の定期処理のテスト
+func TestProcessExpirations(t *testing.T) {
+	var trashed, deleted, warned, cleared []int
+	notifications := make([]models.NotificationType, 0)
+
+	expRepo := &MockExpirationRepository{
+		ListExpirationsToWarnFunc: func(warnBefore time.Duration) ([]models.ExpiringDocument, error) {
+			return []models.ExpiringDocument{{DocumentID: 1, UserID: 10, Title: "a", ExpiresAt: time.Now().Add(time.Hour)}}, nil
+		},
+		MarkWarnedFunc: func(docID int) error {
+			warned = append(warned, docID)
+			return nil
+		},
+		ListDueExpirationsFunc: func() ([]models.ExpiringDocument, error) {
+			return []models.ExpiringDocument{
+				{DocumentID: 2, UserID: 10, Title: "b", Action: models.ExpirationActionTrash},
+				{DocumentID: 3, UserID: 10, Title: "c", Action: models.ExpirationActionDelete},
+				{DocumentID: 4, UserID: 10, Title: "d", Action: models.ExpirationActionTrash},
+			}, nil
+		},
+		DeleteExpirationFunc: func(docID int) error {
+			cleared = append(cleared, docID)
+			return nil
+		},
+	}
+	trashRepo := &MockDocumentTrashRepository{
+		SoftDeleteDocumentFunc: func(docID, userID int) error {
+			if docID == 4 {
+				return errors.New("db error")
+			}
+			trashed = append(trashed, docID)
+			return nil
+		},
+		PermanentDeleteDocumentFunc: func(docID, userID int) error {
+			deleted = append(deleted, docID)
+			return nil
+		},
+	}
+	notifRepo := &MockNotificationRepository{
+		CreateNotificationFunc: func(n *models.Notification) error {
+			notifications = append(notifications, n.Type)
+			return nil
+		},
+	}
+
+	service := NewExpirationService(&MockDocumentCoreRepository{}, trashRepo, expRepo,
+		NewNotificationService(notifRepo), time.Hour)
+	gotWarned, gotExpired, err := service.ProcessExpirations(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessExpirations() unexpected error: %v", err)
+	}
+
+	// 文書4 は削除に失敗するが、他の文書の処理は継続する
+	if gotWarned != 1 || gotExpired != 2 {
+		t.Errorf("ProcessExpirations() = (%d, %d), want (1, 2)", gotWarned, gotExpired)
+	}
+	if len(warned) != 1 || len(trashed) != 1 || len(deleted) != 1 {
+		t.Errorf("warned=%v trashed=%v deleted=%v", warned, trashed, deleted)
+	}
+	// ごみ箱移動時のみ期限設定を明示的に解除（完全削除は CASCADE で消える）
+	if len(cleared) != 1 || cleared[0] != 2 {
+		t.Errorf("cleared = %v, want [2]", cleared)
+	}
+	if len(notifications) != 3 {
+		t.Errorf("notifications = %v, want 3 entries", notifications)
+	}
+}
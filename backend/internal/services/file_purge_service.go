@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/repository"
+	"simple-notion-backend/internal/storage"
+)
+
+const (
+	// filePurgeBatchSize - 1回の実行で完全削除するファイルの最大件数
+	filePurgeBatchSize = 100
+	// filePurgeLease - 取得したファイルを他の処理が取得しないようにする時間（処理中に停止した場合はその後に再試行する）
+	filePurgeLease = 10 * time.Minute
+)
+
+// FilePurgeResult - 完全削除の実行結果
+type FilePurgeResult struct {
+	Purged int // ストレージとデータベースから削除したファイルの件数
+	Failed int // 削除に失敗し、後で再試行するファイルの件数
+}
+
+// FilePurgeService - 削除済みファイルと以前の版の完全削除（バックグラウンドジョブから呼び出す）
+// 削除から保持期間が経過したファイルのオブジェクトとサムネイルをストレージから削除し、メタデータの行も削除する
+// 他のファイルが同じオブジェクトを参照している場合は、メタデータの行のみ削除する
+// 新しい版で置き換えた以前の版も、置き換えから保持期間が経過したものを同じように削除する
+type FilePurgeService struct {
+	fileRepo         *repository.FileRepository
+	versionRepo      *repository.FileVersionRepository
+	objectStorage    storage.ObjectStorage
+	retention        time.Duration
+	versionRetention time.Duration
+}
+
+// NewFilePurgeService - FilePurgeServiceを初期化
+func NewFilePurgeService(
+	fileRepo *repository.FileRepository,
+	versionRepo *repository.FileVersionRepository,
+	objectStorage storage.ObjectStorage,
+	retention time.Duration,
+	versionRetention time.Duration,
+) *FilePurgeService {
+	return &FilePurgeService{
+		fileRepo:         fileRepo,
+		versionRepo:      versionRepo,
+		objectStorage:    objectStorage,
+		retention:        retention,
+		versionRetention: versionRetention,
+	}
+}
+
+// PurgeDeletedFiles - 保持期間が経過した削除済みファイルを完全に削除
+// 削除に失敗したファイルは失敗を記録し、試行回数に応じて間隔を空けて次回以降に再試行する
+func (s *FilePurgeService) PurgeDeletedFiles(ctx context.Context) (FilePurgeResult, error) {
+	var result FilePurgeResult
+
+	files, err := s.fileRepo.ClaimPurgeableFiles(ctx, s.retention, filePurgeBatchSize, filePurgeLease)
+	if err != nil {
+		return result, err
+	}
+
+	for _, file := range files {
+		if err := s.purge(ctx, file); err != nil {
+			logctx.Printf(ctx, "failed to purge file %d: %v", file.ID, err)
+			if recordErr := s.fileRepo.RecordPurgeFailure(ctx, file.ID, err); recordErr != nil {
+				return result, recordErr
+			}
+			result.Failed++
+			continue
+		}
+		result.Purged++
+	}
+	return result, nil
+}
+
+// purge - 1つのファイルをストレージ（参照されなくなったオブジェクトのみ）とデータベースから削除
+// ファイルの以前の版は保持期間にかかわらず先に削除する
+func (s *FilePurgeService) purge(ctx context.Context, file *models.FileMetadata) error {
+	versions, err := s.versionRepo.ListByFileID(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if err := s.purgeVersion(ctx, version); err != nil {
+			return err
+		}
+	}
+
+	referenced, err := s.fileRepo.IsObjectReferenced(ctx, file.FileKey)
+	if err != nil {
+		return err
+	}
+
+	if !referenced {
+		for _, thumbnailKey := range file.Thumbnails {
+			if err := s.objectStorage.DeleteFile(ctx, thumbnailKey); err != nil {
+				return fmt.Errorf("failed to delete thumbnail %s: %w", thumbnailKey, err)
+			}
+		}
+		if file.WebPFileKey != "" {
+			if err := s.objectStorage.DeleteFile(ctx, file.WebPFileKey); err != nil {
+				return fmt.Errorf("failed to delete webp %s: %w", file.WebPFileKey, err)
+			}
+		}
+		if err := s.objectStorage.DeleteFile(ctx, file.FileKey); err != nil {
+			return fmt.Errorf("failed to delete object %s: %w", file.FileKey, err)
+		}
+	}
+
+	return s.fileRepo.HardDelete(ctx, file.ID)
+}
+
+// PurgeExpiredVersions は 置き換えてから保持期間が経過した以前の版を削除します
+// 他の版・ファイルが参照していないオブジェクトは、サムネイル・WebP とあわせてストレージから削除する
+func (s *FilePurgeService) PurgeExpiredVersions(ctx context.Context) (FilePurgeResult, error) {
+	var result FilePurgeResult
+
+	versions, err := s.versionRepo.ListExpired(ctx, s.versionRetention, filePurgeBatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, version := range versions {
+		if err := s.purgeVersion(ctx, version); err != nil {
+			logctx.Printf(ctx, "failed to purge file version %d: %v", version.ID, err)
+			result.Failed++
+			continue
+		}
+		result.Purged++
+	}
+	return result, nil
+}
+
+// purgeVersion - 以前の版をストレージ（参照されなくなったオブジェクトのみ）とデータベースから削除
+func (s *FilePurgeService) purgeVersion(ctx context.Context, version *models.FileVersion) error {
+	shared, err := s.versionRepo.IsObjectShared(ctx, version.FileKey)
+	if err != nil {
+		return err
+	}
+
+	if !shared {
+		keys := make([]string, 0, len(version.Thumbnails)+2)
+		for _, thumbnailKey := range version.Thumbnails {
+			keys = append(keys, thumbnailKey)
+		}
+		if version.WebPFileKey != "" {
+			keys = append(keys, version.WebPFileKey)
+		}
+		keys = append(keys, version.FileKey)
+
+		for _, key := range keys {
+			if err := s.objectStorage.DeleteFile(ctx, key); err != nil {
+				return fmt.Errorf("failed to delete object %s: %w", key, err)
+			}
+		}
+	}
+
+	return s.versionRepo.Delete(ctx, version.ID)
+}
@@ -1,7 +1,11 @@
 package services
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"image"
@@ -10,13 +14,22 @@ import (
 	_ "image/png"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/events"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/mediaprobe"
+	"simple-notion-backend/internal/mimetype"
 	"simple-notion-backend/internal/models"
 	"simple-notion-backend/internal/repository"
 	"simple-notion-backend/internal/storage"
@@ -25,14 +38,49 @@ import (
 var (
 	// ErrStorageQuotaExceeded は ストレージクォータ超過エラー
 	ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+	// ErrWorkspaceStorageQuotaExceeded は ワークスペースのストレージクォータ超過エラー（ErrStorageQuotaExceeded としても判定できる）
+	ErrWorkspaceStorageQuotaExceeded = fmt.Errorf("workspace %w", ErrStorageQuotaExceeded)
+	// ErrFileTooLarge は 1ファイルのサイズ上限超過エラー
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrUnsupportedFileType は アップロードできない種類のファイルのエラー
+	ErrUnsupportedFileType = errors.New("unsupported file type")
+	// ErrContentTypeMismatch は 申告された MIME タイプとファイルの中身が一致しないエラー
+	ErrContentTypeMismatch = errors.New("content type mismatch")
 )
 
+// sniffLength は 中身から MIME タイプを判定するために読み込む先頭のバイト数です
+const sniffLength = 512
+
+// oleMagic は 旧形式の Office 文書（.doc / .xls / .ppt）が使う OLE 複合ファイルの先頭バイトです
+var oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// oleContentType は OLE 複合ファイルを検出したときの MIME タイプです（http.DetectContentType は判定できない）
+const oleContentType = "application/x-ole-storage"
+
+// mp3ContentType は ID3 タグのない MP3 を検出したときの MIME タイプです（http.DetectContentType は ID3 タグでしか判定できない）
+const mp3ContentType = "audio/mpeg"
+
+// heifBrandTypes は HEIF 系の画像の ftyp ボックスのメジャーブランドと MIME タイプの対応です（http.DetectContentType は判定できない）
+// 既定では許可していないが、設定で HEIC・AVIF などを許可した場合に中身を照合するために使う
+var heifBrandTypes = map[string]string{
+	"heic": "image/heic",
+	"heix": "image/heic",
+	"mif1": "image/heif",
+	"avif": "image/avif",
+}
+
 // FileService は ファイル管理のビジネスロジックを提供します
 type FileService struct {
-	fileRepo      *repository.FileRepository
-	objectStorage storage.ObjectStorage
-	maxFileSize   int64
-	presignExpiry int // 署名付きURLの有効期限（秒）
+	fileRepo         *repository.FileRepository
+	objectStorage    storage.ObjectStorage
+	maxFileSize      int64
+	maxVideoFileSize int64
+	maxAudioFileSize int64
+	presignExpiry    int               // 署名付きURLの有効期限（秒）
+	mediaProber      mediaprobe.Prober // nil の場合は動画・音声の再生時間と映像の大きさを取得しない
+	allowedTypes     *mimetype.Allowlist
+	workspaces       WorkspaceStorageCheckerInterface // nil の場合はワークスペースの役割とストレージクォータを確認しない
+	bus              *events.Bus                      // nil の場合はイベントを発行しない
 }
 
 // NewFileService は 新しい FileService インスタンスを作成します
@@ -40,18 +88,42 @@ func NewFileService(
 	fileRepo *repository.FileRepository,
 	objectStorage storage.ObjectStorage,
 	maxFileSize int64,
+	maxVideoFileSize int64,
+	maxAudioFileSize int64,
 	presignExpiry int,
+	mediaProber mediaprobe.Prober,
+	allowedTypes *mimetype.Allowlist,
+	workspaces WorkspaceStorageCheckerInterface,
+	bus *events.Bus,
 ) *FileService {
 	return &FileService{
-		fileRepo:      fileRepo,
-		objectStorage: objectStorage,
-		maxFileSize:   maxFileSize,
-		presignExpiry: presignExpiry,
+		fileRepo:         fileRepo,
+		objectStorage:    objectStorage,
+		maxFileSize:      maxFileSize,
+		maxVideoFileSize: maxVideoFileSize,
+		maxAudioFileSize: maxAudioFileSize,
+		presignExpiry:    presignExpiry,
+		mediaProber:      mediaProber,
+		allowedTypes:     allowedTypes,
+		workspaces:       workspaces,
+		bus:              bus,
 	}
 }
 
 // CheckStorageQuota は ユーザーのストレージクォータをチェックします
-func (s *FileService) CheckStorageQuota(ctx context.Context, userID int, newFileSize int64, quota int64) error {
+// quota は既定値で、ユーザーに個別のクォータが設定されている場合はそちらを使う
+// workspaceID が nil でない場合は、ワークスペースにアップロードできる役割か（guest は不可）と、
+// ワークスペースのストレージクォータ（メンバー全員の合計）もチェックする
+func (s *FileService) CheckStorageQuota(ctx context.Context, userID int, workspaceID *int, newFileSize int64, quota int64) error {
+	if err := requireWorkspaceEditor(s.workspaces, workspaceID, userID); err != nil {
+		return err
+	}
+
+	quota, err := resolveStorageQuota(ctx, s.fileRepo, userID, quota)
+	if err != nil {
+		return err
+	}
+
 	// 現在のストレージ使用量を取得
 	usage, err := s.fileRepo.GetUserStorageUsage(ctx, userID)
 	if err != nil {
@@ -63,10 +135,90 @@ func (s *FileService) CheckStorageQuota(ctx context.Context, userID int, newFile
 		return ErrStorageQuotaExceeded
 	}
 
+	if workspaceID != nil && s.workspaces != nil {
+		return s.workspaces.CheckStorageQuota(*workspaceID, newFileSize)
+	}
 	return nil
 }
 
+// GetStorageQuota は ユーザーのストレージクォータと使用量を返します（個別に設定されていない場合は defaultQuota）
+func (s *FileService) GetStorageQuota(ctx context.Context, userID int, defaultQuota int64) (*models.UserStorageQuota, error) {
+	custom, err := s.fileRepo.GetUserStorageQuota(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.fileRepo.GetUserStorageUsage(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user storage usage: %w", err)
+	}
+
+	quota := &models.UserStorageQuota{
+		UserID:     userID,
+		QuotaBytes: defaultQuota,
+		IsDefault:  custom == nil,
+		UsedBytes:  usage.TotalBytes,
+	}
+	if custom != nil {
+		quota.QuotaBytes = *custom
+	}
+	return quota, nil
+}
+
+// SetStorageQuota は ユーザーのストレージクォータを変更します（管理者向け）
+// quotaBytes が nil の場合は個別の設定を解除して既定値に戻す。使用量より小さくしても既存のファイルは削除しない
+func (s *FileService) SetStorageQuota(ctx context.Context, userID int, update models.StorageQuotaUpdate, defaultQuota int64) (*models.UserStorageQuota, error) {
+	if update.QuotaBytes != nil && *update.QuotaBytes < 0 {
+		return nil, apierror.NewValidationError("INVALID_QUOTA", "クォータは0以上のバイト数で指定してください", nil)
+	}
+
+	if err := s.fileRepo.SetUserStorageQuota(ctx, userID, update.QuotaBytes); err != nil {
+		return nil, err
+	}
+	return s.GetStorageQuota(ctx, userID, defaultQuota)
+}
+
+// storageReportUserLimit は ストレージ使用量の集計に含めるユーザーの最大件数です
+const storageReportUserLimit = 20
+
+// GetStorageUsageReport は インスタンス全体のストレージ使用量を集計します（管理者向け）
+// クォータが個別に設定されていないユーザーは defaultQuota と比べる
+func (s *FileService) GetStorageUsageReport(ctx context.Context, defaultQuota int64) (*models.StorageUsageReport, error) {
+	report, err := s.fileRepo.GetStorageUsageTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.GeneratedAt = time.Now()
+
+	if report.ByType, err = s.fileRepo.GetStorageUsageByType(ctx); err != nil {
+		return nil, err
+	}
+	if report.TopConsumers, err = s.fileRepo.ListTopStorageUsers(ctx, defaultQuota, storageReportUserLimit); err != nil {
+		return nil, err
+	}
+	if report.QuotaViolationCount, err = s.fileRepo.CountQuotaViolations(ctx, defaultQuota); err != nil {
+		return nil, err
+	}
+	if report.QuotaViolations, err = s.fileRepo.ListQuotaViolations(ctx, defaultQuota, storageReportUserLimit); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// resolveStorageQuota は ユーザーに個別に設定されたクォータ、未設定の場合は defaultQuota を返します
+func resolveStorageQuota(ctx context.Context, fileRepo *repository.FileRepository, userID int, defaultQuota int64) (int64, error) {
+	custom, err := fileRepo.GetUserStorageQuota(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user storage quota: %w", err)
+	}
+	if custom == nil {
+		return defaultQuota, nil
+	}
+	return *custom, nil
+}
+
 // UploadImage は 画像ファイルをアップロードします
+// サイズ超過は ErrFileTooLarge、許可していない MIME タイプは ErrUnsupportedFileType を返す
 func (s *FileService) UploadImage(
 	ctx context.Context,
 	userID int,
@@ -75,13 +227,16 @@ func (s *FileService) UploadImage(
 ) (*models.FileMetadata, string, error) {
 	// 1. ファイルサイズのバリデーション
 	if header.Size > s.maxFileSize {
-		return nil, "", fmt.Errorf("file size exceeds maximum allowed size of %d bytes", s.maxFileSize)
+		return nil, "", fmt.Errorf("%w: maximum allowed size is %d bytes", ErrFileTooLarge, s.maxFileSize)
 	}
 
 	// 2. MIMEタイプのバリデーション
 	contentType := header.Header.Get("Content-Type")
-	if !isValidImageType(contentType) {
-		return nil, "", fmt.Errorf("invalid image type: %s", contentType)
+	if !s.allowedTypes.Allowed(mimetype.Image, contentType) {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType)
+	}
+	if err := checkContentType(file, contentType); err != nil {
+		return nil, "", err
 	}
 
 	// 3. 画像の寸法を取得
@@ -95,38 +250,91 @@ func (s *FileService) UploadImage(
 		return nil, "", fmt.Errorf("failed to reset file pointer: %w", err)
 	}
 
-	// 4. 一意なファイルキーを生成
-	fileKey := generateFileKey(userID, header.Filename, "images")
-
-	// 5. ストレージにアップロード
-	err = s.objectStorage.UploadFile(ctx, fileKey, file, header.Size, contentType)
+	// 4. 内容のハッシュを計算
+	contentHash, err := hashContent(file)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to upload file to storage: %w", err)
+		return nil, "", err
 	}
 
-	// 6. メタデータをデータベースに保存
 	fileMeta := &models.FileMetadata{
 		UserID:       userID,
-		FileKey:      fileKey,
 		BucketName:   s.objectStorage.GetBucketName(),
 		OriginalName: header.Filename,
 		FileSize:     header.Size,
 		MimeType:     contentType,
 		FileType:     "image",
-		Width:        &dimensions.Width,
-		Height:       &dimensions.Height,
 		Status:       "active",
+		// サムネイルはバックグラウンドの ThumbnailService で作成する
+		ThumbnailStatus: models.ThumbnailStatusPending,
+		ContentHash:     contentHash,
+	}
+	if dimensions != nil {
+		fileMeta.Width, fileMeta.Height = &dimensions.Width, &dimensions.Height
+	} else {
+		// デコードできない形式はサムネイルを作成できない
+		fileMeta.ThumbnailStatus = models.ThumbnailStatusNone
 	}
 
-	err = s.fileRepo.Create(ctx, fileMeta)
+	// 5. 同じ内容のファイルを保存済みの場合はそのオブジェクトを参照し、なければストレージにアップロードして保存
+	if err := s.storeFile(ctx, fileMeta, file, "images"); err != nil {
+		return nil, "", err
+	}
+
+	// 6. 署名付きURLを生成
+	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileMeta.FileKey, time.Duration(s.presignExpiry)*time.Second)
 	if err != nil {
-		// アップロード済みのファイルを削除
-		_ = s.objectStorage.DeleteFile(ctx, fileKey)
-		return nil, "", fmt.Errorf("failed to save file metadata: %w", err)
+		return nil, "", fmt.Errorf("failed to generate presigned URL: %w", err)
 	}
 
-	// 7. 署名付きURLを生成
-	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileKey, time.Duration(s.presignExpiry)*time.Second)
+	return fileMeta, presignedURL, nil
+}
+
+// UploadFile は 文書ファイル（PDF・Word など）をアップロードします
+// サイズ超過は ErrFileTooLarge、許可していない MIME タイプは ErrUnsupportedFileType を返す
+func (s *FileService) UploadFile(
+	ctx context.Context,
+	userID int,
+	file multipart.File,
+	header *multipart.FileHeader,
+) (*models.FileMetadata, string, error) {
+	// 1. ファイルサイズのバリデーション
+	if header.Size > s.maxFileSize {
+		return nil, "", fmt.Errorf("%w: maximum allowed size is %d bytes", ErrFileTooLarge, s.maxFileSize)
+	}
+
+	// 2. MIMEタイプのバリデーション
+	contentType := header.Header.Get("Content-Type")
+	if !s.allowedTypes.Allowed(mimetype.Document, contentType) {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType)
+	}
+	if err := checkContentType(file, contentType); err != nil {
+		return nil, "", err
+	}
+
+	// 3. 内容のハッシュを計算
+	contentHash, err := hashContent(file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fileMeta := &models.FileMetadata{
+		UserID:       userID,
+		BucketName:   s.objectStorage.GetBucketName(),
+		OriginalName: header.Filename,
+		FileSize:     header.Size,
+		MimeType:     contentType,
+		FileType:     "file",
+		Status:       "active",
+		ContentHash:  contentHash,
+	}
+
+	// 4. 同じ内容のファイルを保存済みの場合はそのオブジェクトを参照し、なければストレージにアップロードして保存
+	if err := s.storeFile(ctx, fileMeta, file, "files"); err != nil {
+		return nil, "", err
+	}
+
+	// 5. 署名付きURLを生成
+	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileMeta.FileKey, time.Duration(s.presignExpiry)*time.Second)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate presigned URL: %w", err)
 	}
@@ -134,8 +342,176 @@ func (s *FileService) UploadImage(
 	return fileMeta, presignedURL, nil
 }
 
+// UploadVideo は 動画（MP4・WebM）をアップロードします
+// サイズ超過は ErrFileTooLarge、許可していない MIME タイプは ErrUnsupportedFileType を返す
+// 再生時間と映像の大きさは取得できた場合のみ記録する（取得できなくてもアップロードは成功させる）
+func (s *FileService) UploadVideo(
+	ctx context.Context,
+	userID int,
+	file multipart.File,
+	header *multipart.FileHeader,
+) (*models.FileMetadata, string, error) {
+	// 1. ファイルサイズのバリデーション（動画は文書・画像より大きい上限を使う）
+	if header.Size > s.maxVideoFileSize {
+		return nil, "", fmt.Errorf("%w: maximum allowed size is %d bytes", ErrFileTooLarge, s.maxVideoFileSize)
+	}
+
+	// 2. MIMEタイプのバリデーション
+	contentType := header.Header.Get("Content-Type")
+	if !s.allowedTypes.Allowed(mimetype.Video, contentType) {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType)
+	}
+	if err := checkContentType(file, contentType); err != nil {
+		return nil, "", err
+	}
+
+	fileMeta := &models.FileMetadata{
+		UserID:       userID,
+		BucketName:   s.objectStorage.GetBucketName(),
+		OriginalName: header.Filename,
+		FileSize:     header.Size,
+		MimeType:     contentType,
+		FileType:     "video",
+		Status:       "active",
+	}
+
+	// 3. 再生時間と映像の大きさを取得
+	probeMedia(ctx, s.mediaProber, file, header.Size, fileMeta)
+
+	// 4. 内容のハッシュを計算
+	contentHash, err := hashContent(file)
+	if err != nil {
+		return nil, "", err
+	}
+	fileMeta.ContentHash = contentHash
+
+	// 5. 同じ内容のファイルを保存済みの場合はそのオブジェクトを参照し、なければストレージにアップロードして保存
+	if err := s.storeFile(ctx, fileMeta, file, "videos"); err != nil {
+		return nil, "", err
+	}
+
+	// 6. 署名付きURLを生成
+	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileMeta.FileKey, time.Duration(s.presignExpiry)*time.Second)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	return fileMeta, presignedURL, nil
+}
+
+// UploadAudio は 音声（MP3・WAV・M4A・Ogg）をアップロードします
+// サイズ超過は ErrFileTooLarge、許可していない MIME タイプは ErrUnsupportedFileType を返す
+// 再生時間は取得できた場合のみ記録する（取得できなくてもアップロードは成功させる）
+func (s *FileService) UploadAudio(
+	ctx context.Context,
+	userID int,
+	file multipart.File,
+	header *multipart.FileHeader,
+) (*models.FileMetadata, string, error) {
+	// 1. ファイルサイズのバリデーション
+	if header.Size > s.maxAudioFileSize {
+		return nil, "", fmt.Errorf("%w: maximum allowed size is %d bytes", ErrFileTooLarge, s.maxAudioFileSize)
+	}
+
+	// 2. MIMEタイプのバリデーション
+	contentType := header.Header.Get("Content-Type")
+	if !s.allowedTypes.Allowed(mimetype.Audio, contentType) {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType)
+	}
+	if err := checkContentType(file, contentType); err != nil {
+		return nil, "", err
+	}
+
+	fileMeta := &models.FileMetadata{
+		UserID:       userID,
+		BucketName:   s.objectStorage.GetBucketName(),
+		OriginalName: header.Filename,
+		FileSize:     header.Size,
+		MimeType:     contentType,
+		FileType:     "audio",
+		Status:       "active",
+	}
+
+	// 3. 再生時間を取得
+	probeMedia(ctx, s.mediaProber, file, header.Size, fileMeta)
+
+	// 4. 内容のハッシュを計算
+	contentHash, err := hashContent(file)
+	if err != nil {
+		return nil, "", err
+	}
+	fileMeta.ContentHash = contentHash
+
+	// 5. 同じ内容のファイルを保存済みの場合はそのオブジェクトを参照し、なければストレージにアップロードして保存
+	if err := s.storeFile(ctx, fileMeta, file, "audio"); err != nil {
+		return nil, "", err
+	}
+
+	// 6. 署名付きURLを生成
+	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileMeta.FileKey, time.Duration(s.presignExpiry)*time.Second)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	return fileMeta, presignedURL, nil
+}
+
+// Upload は MIME タイプの種類に応じて、画像・動画・音声・文書ファイルのいずれかとしてアップロードします
+// どの種類でも許可していない MIME タイプは ErrUnsupportedFileType を返す
+func (s *FileService) Upload(
+	ctx context.Context,
+	userID int,
+	file multipart.File,
+	header *multipart.FileHeader,
+) (*models.FileMetadata, error) {
+	var upload func(context.Context, int, multipart.File, *multipart.FileHeader) (*models.FileMetadata, string, error)
+	contentType := header.Header.Get("Content-Type")
+	switch {
+	case s.allowedTypes.Allowed(mimetype.Image, contentType):
+		upload = s.UploadImage
+	case s.allowedTypes.Allowed(mimetype.Video, contentType):
+		upload = s.UploadVideo
+	case s.allowedTypes.Allowed(mimetype.Audio, contentType):
+		upload = s.UploadAudio
+	case s.allowedTypes.Allowed(mimetype.Document, contentType):
+		upload = s.UploadFile
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType)
+	}
+
+	fileMeta, _, err := upload(ctx, userID, file, header)
+	return fileMeta, err
+}
+
+// storeFile は アップロードされたファイルを保存し、fileMeta に ID とファイルキーを設定します
+// ユーザーが同じ内容のファイルを保存済みの場合は、ストレージにはアップロードせずそのオブジェクトを参照する
+func (s *FileService) storeFile(ctx context.Context, fileMeta *models.FileMetadata, file io.Reader, prefix string) error {
+	reused, err := createFileReference(ctx, s.fileRepo, fileMeta)
+	if err != nil {
+		return err
+	}
+	if reused {
+		publishFileUploaded(ctx, s.bus, fileMeta)
+		return nil
+	}
+
+	fileMeta.FileKey = generateFileKey(fileMeta.UserID, fileMeta.OriginalName, prefix)
+	if err := s.objectStorage.UploadFile(ctx, fileMeta.FileKey, file, fileMeta.FileSize, fileMeta.MimeType); err != nil {
+		return fmt.Errorf("failed to upload file to storage: %w", err)
+	}
+
+	if err := s.fileRepo.Create(ctx, fileMeta); err != nil {
+		// アップロード済みのファイルを削除
+		_ = s.objectStorage.DeleteFile(ctx, fileMeta.FileKey)
+		return fmt.Errorf("failed to save file metadata: %w", err)
+	}
+	publishFileUploaded(ctx, s.bus, fileMeta)
+	return nil
+}
+
 // GetPresignedURL は ファイルの署名付きURLを取得します
-func (s *FileService) GetPresignedURL(ctx context.Context, fileID int, userID int) (string, error) {
+// size にサムネイルのサイズ名を指定した場合はサムネイルの URL を返す（作成前の場合は元のファイル）
+func (s *FileService) GetPresignedURL(ctx context.Context, fileID int, userID int, size string) (string, error) {
 	// 1. ファイルメタデータを取得
 	fileMeta, err := s.fileRepo.GetByID(ctx, fileID)
 	if err != nil {
@@ -152,8 +528,14 @@ func (s *FileService) GetPresignedURL(ctx context.Context, fileID int, userID in
 		return "", fmt.Errorf("file is not available: status=%s", fileMeta.Status)
 	}
 
-	// 4. 署名付きURLを生成
-	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileMeta.FileKey, time.Duration(s.presignExpiry)*time.Second)
+	// 4. 配信するファイル（元のファイルまたはサムネイル）を決定
+	fileKey, err := ResolveFileKey(fileMeta, size)
+	if err != nil {
+		return "", err
+	}
+
+	// 5. 署名付きURLを生成
+	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileKey, time.Duration(s.presignExpiry)*time.Second)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
 	}
@@ -211,6 +593,21 @@ func (s *FileService) GetPresignedURLByFilename(ctx context.Context, filename st
 	return presignedURL, nil
 }
 
+// GetPresignedURLForKey は 配信するファイル（元のファイル・サムネイル・WebP）の署名付きURLを生成します（認証不要）
+// ファイルの状態は呼び出し側が GetFileMetadataByFilename で確認していることを前提とする
+func (s *FileService) GetPresignedURLForKey(ctx context.Context, fileKey string) (string, error) {
+	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileKey, s.PresignExpiry())
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+	return presignedURL, nil
+}
+
+// PresignExpiry は 生成する署名付きURLの有効期間を返します
+func (s *FileService) PresignExpiry() time.Duration {
+	return time.Duration(s.presignExpiry) * time.Second
+}
+
 // GetFileMetadataByFilename は ファイル名からファイルメタデータを取得します（認証不要）
 func (s *FileService) GetFileMetadataByFilename(ctx context.Context, filename string) (*models.FileMetadata, error) {
 	fileMeta, err := s.fileRepo.GetByFilename(ctx, filename)
@@ -236,6 +633,84 @@ func (s *FileService) GetFileObject(ctx context.Context, fileKey string) (io.Rea
 	return object, nil
 }
 
+// GetFileObjectRange は ストレージからファイルの offset バイト目から length バイトを取得します
+// 戻り値のio.ReadCloserは呼び出し側でCloseする必要があります
+func (s *FileService) GetFileObjectRange(ctx context.Context, fileKey string, offset, length int64) (io.ReadCloser, error) {
+	object, err := s.objectStorage.GetObjectRange(ctx, fileKey, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object from storage: %w", err)
+	}
+
+	return object, nil
+}
+
+// OpenFileForDownload は ユーザーのファイルのメタデータとストレージ上のオブジェクトを取得します
+// 戻り値のio.ReadCloserは呼び出し側でCloseする必要があります
+func (s *FileService) OpenFileForDownload(ctx context.Context, fileID int, userID int) (*models.FileMetadata, io.ReadCloser, error) {
+	// 1. ファイルメタデータを取得
+	fileMeta, err := s.fileRepo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+
+	// 2. アクセス権限チェック
+	if fileMeta.UserID != userID {
+		return nil, nil, fmt.Errorf("user %d does not own file %d: %w", userID, fileID, apierror.ErrForbidden)
+	}
+
+	// 3. ステータスチェック
+	if fileMeta.Status != "active" {
+		return nil, nil, fmt.Errorf("file metadata id=%d is %s: %w", fileID, fileMeta.Status, apierror.ErrNotFound)
+	}
+
+	// 4. ストレージからファイルを取得
+	object, err := s.GetFileObject(ctx, fileMeta.FileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fileMeta, object, nil
+}
+
+// UpdateFileDetails は ファイルの名前・代替テキスト・説明を変更します
+// 変更はダウンロード時のファイル名と、ファイルを参照している画像・ファイルブロックに反映される
+func (s *FileService) UpdateFileDetails(ctx context.Context, fileID int, userID int, update models.FileDetailsUpdate) (*models.FileMetadata, error) {
+	// 1. ファイルメタデータを取得
+	fileMeta, err := s.fileRepo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+
+	// 2. アクセス権限チェック
+	if fileMeta.UserID != userID {
+		return nil, fmt.Errorf("user %d does not own file %d: %w", userID, fileID, apierror.ErrForbidden)
+	}
+	if fileMeta.Status != "active" {
+		return nil, fmt.Errorf("file metadata id=%d is %s: %w", fileID, fileMeta.Status, apierror.ErrNotFound)
+	}
+
+	// 3. 変更内容のバリデーション
+	if err := validateFileDetails(update); err != nil {
+		return nil, err
+	}
+	if update.OriginalName != nil {
+		fileMeta.OriginalName = strings.TrimSpace(*update.OriginalName)
+	}
+	if update.AltText != nil {
+		fileMeta.AltText = *update.AltText
+	}
+	if update.Description != nil {
+		fileMeta.Description = *update.Description
+	}
+
+	// 4. 保存してブロックに反映
+	if err := s.fileRepo.UpdateDetails(ctx, fileMeta, update.AltText != nil); err != nil {
+		return nil, err
+	}
+
+	return fileMeta, nil
+}
+
 // DeleteFile は ファイルを削除します（ソフトデリート）
 func (s *FileService) DeleteFile(ctx context.Context, fileID int, userID int) error {
 	// 1. ファイルメタデータを取得
@@ -246,23 +721,16 @@ func (s *FileService) DeleteFile(ctx context.Context, fileID int, userID int) er
 
 	// 2. アクセス権限チェック
 	if fileMeta.UserID != userID {
-		return fmt.Errorf("access denied: user %d does not own file %d", userID, fileID)
+		return fmt.Errorf("user %d does not own file %d: %w", userID, fileID, apierror.ErrForbidden)
 	}
 
 	// 3. データベースで削除済みマーク
+	// ストレージからの削除は保持期間の経過後に FilePurgeService が行う
 	err = s.fileRepo.MarkAsDeleted(ctx, fileID)
 	if err != nil {
 		return fmt.Errorf("failed to mark file as deleted: %w", err)
 	}
 
-	// 4. ストレージから削除（非同期で行う方が良いが、ここでは同期的に実行）
-	// 本番環境では、後でクリーンアップジョブで削除する方が安全
-	err = s.objectStorage.DeleteFile(ctx, fileMeta.FileKey)
-	if err != nil {
-		// ログに記録するが、エラーは返さない（メタデータの削除は成功しているため）
-		// log.Printf("Warning: failed to delete file from storage: %v", err)
-	}
-
 	return nil
 }
 
@@ -276,16 +744,8 @@ func (s *FileService) CleanupOrphanedFiles(ctx context.Context) error {
 
 	// 2. 各ファイルを処理
 	for _, file := range orphanedFiles {
-		// ストレージから削除
-		err := s.objectStorage.DeleteFile(ctx, file.FileKey)
-		if err != nil {
-			// ログに記録して続行
-			// log.Printf("Warning: failed to delete orphaned file from storage: %v", err)
-			continue
-		}
-
-		// データベースで削除済みマーク
-		err = s.fileRepo.MarkAsDeleted(ctx, file.ID)
+		// データベースで削除済みマーク（ストレージからの削除は保持期間の経過後に FilePurgeService が行う）
+		err := s.fileRepo.MarkAsDeleted(ctx, file.ID)
 		if err != nil {
 			// log.Printf("Warning: failed to mark orphaned file as deleted: %v", err)
 		}
@@ -314,6 +774,151 @@ func (s *FileService) ListUserFiles(ctx context.Context, userID int) ([]*models.
 	return files, nil
 }
 
+// ファイル一覧のページサイズ
+const (
+	defaultFileListLimit = 50
+	maxFileListLimit     = 200
+)
+
+// ListFiles は ユーザーのファイルを条件で絞り込み、並べ替えた1ページ分を取得します
+// 不正な種類・並び順・ページングの指定はバリデーションエラーを返す
+func (s *FileService) ListFiles(ctx context.Context, userID int, opts models.FileListOptions) (*models.FileList, error) {
+	switch opts.FileType {
+	case "", "image", "file", "video", "audio":
+	default:
+		return nil, apierror.NewValidationError("INVALID_FILE_TYPE",
+			"type には image / file / video / audio のいずれかを指定してください", nil)
+	}
+	if opts.MimeType != "" && !strings.Contains(opts.MimeType, "/") {
+		return nil, apierror.NewValidationError("INVALID_MIME_TYPE",
+			"mime には image/png または image/* の形式で指定してください", nil)
+	}
+	if opts.UploadedFrom != nil && opts.UploadedTo != nil && !opts.UploadedFrom.Before(*opts.UploadedTo) {
+		return nil, apierror.NewValidationError("INVALID_DATE_RANGE",
+			"from には to より前の日時を指定してください", nil)
+	}
+	switch opts.Sort {
+	case "":
+		opts.Sort = "uploadedAt"
+	case "uploadedAt", "name", "size":
+	default:
+		return nil, apierror.NewValidationError("INVALID_SORT",
+			"sort には uploadedAt / name / size のいずれかを指定してください", nil)
+	}
+	switch opts.Order {
+	case "":
+		// 日時とサイズは新しい・大きい順、名前は昇順を既定にする
+		opts.Order = "desc"
+		if opts.Sort == "name" {
+			opts.Order = "asc"
+		}
+	case "asc", "desc":
+	default:
+		return nil, apierror.NewValidationError("INVALID_ORDER",
+			"order には asc / desc のいずれかを指定してください", nil)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = defaultFileListLimit
+	}
+	if opts.Limit < 0 || opts.Limit > maxFileListLimit {
+		return nil, apierror.NewValidationError("INVALID_PAGINATION",
+			fmt.Sprintf("limit には 1〜%d を指定してください", maxFileListLimit), nil)
+	}
+
+	// uploaded_at はタイムゾーンなしの UTC で保存している
+	if opts.UploadedFrom != nil {
+		from := opts.UploadedFrom.UTC()
+		opts.UploadedFrom = &from
+	}
+	if opts.UploadedTo != nil {
+		to := opts.UploadedTo.UTC()
+		opts.UploadedTo = &to
+	}
+
+	page, total, err := s.fileRepo.ListFiles(ctx, userID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user files: %w", err)
+	}
+
+	return &models.FileList{Page: page, Total: total}, nil
+}
+
+// ListDocumentFiles は 複数の文書に紐付いたログインユーザーのファイルを、文書ごとに新しい順で最大 limit 件ずつ取得します（文書ID → ファイル）
+// 文書ごとに ListFiles を呼ぶ代わりに1回の問い合わせで取得する（GraphQL の同じ階層の文書の添付ファイル）
+func (s *FileService) ListDocumentFiles(ctx context.Context, userID int, documentIDs []int, limit int) (map[int][]*models.FileMetadata, error) {
+	files, err := s.fileRepo.ListByDocumentIDs(ctx, userID, documentIDs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document files: %w", err)
+	}
+
+	result := make(map[int][]*models.FileMetadata, len(documentIDs))
+	for _, id := range documentIDs {
+		result[id] = []*models.FileMetadata{}
+	}
+	for _, f := range files {
+		if f.DocumentID != nil {
+			result[*f.DocumentID] = append(result[*f.DocumentID], f)
+		}
+	}
+	return result, nil
+}
+
+// WriteFilesZip は files のストレージ上のオブジェクトを ZIP として w に書き出します
+// オブジェクトを1つずつ読み込んでそのまま書き出すため、ファイル全体をメモリに保持しない
+// 画像・動画・音声は圧縮済みの形式のため、圧縮せずに格納する
+func (s *FileService) WriteFilesZip(ctx context.Context, w io.Writer, files []*models.FileMetadata) error {
+	archive := zip.NewWriter(w)
+	usedNames := make(map[string]bool, len(files))
+
+	for _, fileMeta := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		header := &zip.FileHeader{
+			Name:     zipEntryName(fileMeta, usedNames),
+			Method:   zip.Deflate,
+			Modified: fileMeta.UploadedAt,
+		}
+		if fileMeta.FileType != "file" {
+			header.Method = zip.Store
+		}
+		entry, err := archive.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to create zip entry for file %d: %w", fileMeta.ID, err)
+		}
+
+		object, err := s.GetFileObject(ctx, fileMeta.FileKey)
+		if err != nil {
+			return fmt.Errorf("failed to get file %d: %w", fileMeta.ID, err)
+		}
+		_, err = io.Copy(entry, object)
+		object.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write file %d to zip: %w", fileMeta.ID, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// zipEntryName は ZIP に格納するファイルの名前を返します（used に記録済みの名前と重複する場合は "name (2).ext" のように番号を付ける）
+// 元のファイル名にディレクトリが含まれていても、展開先の外に書き出されないよう名前の部分だけを使う
+func zipEntryName(fileMeta *models.FileMetadata, used map[string]bool) string {
+	name := path.Base(strings.ReplaceAll(fileMeta.OriginalName, "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		name = path.Base(fileMeta.FileKey)
+	}
+
+	candidate := name
+	ext := path.Ext(name)
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), n, ext)
+	}
+	used[candidate] = true
+	return candidate
+}
+
 // UpdateBlockID は ファイルメタデータのblock_idを更新します
 func (s *FileService) UpdateBlockID(ctx context.Context, fileID int, blockID int, userID int) error {
 	// 1. ファイルメタデータを取得
@@ -336,8 +941,243 @@ func (s *FileService) UpdateBlockID(ctx context.Context, fileID int, blockID int
 	return nil
 }
 
+// AttachToBlock は ファイルを文書のブロックに紐付けます
+// ブロックの複製・他の文書へのコピーで、ファイルの紐付け先を新しいブロックにする場合に使用します
+func (s *FileService) AttachToBlock(ctx context.Context, fileID int, userID int, documentID int, blockID int) error {
+	// 1. ファイルメタデータを取得
+	fileMeta, err := s.fileRepo.GetByID(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to get file metadata: %w", err)
+	}
+
+	// 2. アクセス権限チェック
+	if fileMeta.UserID != userID {
+		return fmt.Errorf("user %d does not own file %d: %w", userID, fileID, apierror.ErrForbidden)
+	}
+
+	// 3. document_id と block_id を更新
+	if err := s.fileRepo.UpdateAttachment(ctx, fileID, documentID, blockID); err != nil {
+		return fmt.Errorf("failed to update attachment: %w", err)
+	}
+
+	return nil
+}
+
+// AttachFile は エディタで挿入したファイルを文書（blockID を指定した場合はそのブロック）に紐付けます
+// 外した（orphaned の）ファイルを猶予期間内に紐付け直した場合は、クリーンアップの対象から外れる
+func (s *FileService) AttachFile(ctx context.Context, fileID int, userID int, documentID int, blockID *int) error {
+	fileMeta, err := s.fileRepo.GetByID(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	if fileMeta.UserID != userID {
+		return fmt.Errorf("user %d does not own file %d: %w", userID, fileID, apierror.ErrForbidden)
+	}
+
+	return s.fileRepo.Attach(ctx, fileID, userID, documentID, blockID)
+}
+
+// DetachFile は エディタでブロックから外したファイルを orphaned にします
+// 猶予期間が経過しても紐付け直されなければ CleanupOrphanedFiles で削除される
+func (s *FileService) DetachFile(ctx context.Context, fileID int, userID int) error {
+	fileMeta, err := s.fileRepo.GetByID(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	if fileMeta.UserID != userID {
+		return fmt.Errorf("user %d does not own file %d: %w", userID, fileID, apierror.ErrForbidden)
+	}
+
+	return s.fileRepo.Detach(ctx, fileID)
+}
+
+// CopyFile は 複製元と同じストレージ上のオブジェクトを参照する、新しいファイルメタデータを作成します
+// ブロックの複製で元ファイルと独立したファイルが必要な場合に使用し、複製分もストレージクォータに含める
+// オブジェクトは参照数で管理するため、どちらのファイルを削除してももう一方は残る
+func (s *FileService) CopyFile(ctx context.Context, fileID int, userID int, workspaceID *int, quota int64) (*models.FileMetadata, error) {
+	// 1. 複製元のファイルメタデータを取得
+	source, err := s.fileRepo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	if source.Status != "active" {
+		return nil, fmt.Errorf("file metadata id=%d is %s: %w", fileID, source.Status, apierror.ErrNotFound)
+	}
+
+	// 2. アクセス権限チェック
+	if source.UserID != userID {
+		return nil, fmt.Errorf("user %d does not own file %d: %w", userID, fileID, apierror.ErrForbidden)
+	}
+
+	// 3. クォータチェック
+	if err := s.CheckStorageQuota(ctx, userID, workspaceID, source.FileSize, quota); err != nil {
+		return nil, err
+	}
+
+	// 4. 複製元のオブジェクトを参照するメタデータを保存（block_id は複製したブロックの保存後に設定する）
+	copied := &models.FileMetadata{
+		UserID:       userID,
+		DocumentID:   source.DocumentID,
+		FileKey:      source.FileKey,
+		BucketName:   source.BucketName,
+		OriginalName: source.OriginalName,
+		FileSize:     source.FileSize,
+		MimeType:     source.MimeType,
+		FileType:     source.FileType,
+		Width:        source.Width,
+		Height:       source.Height,
+		DurationMs:   source.DurationMs,
+		AltText:      source.AltText,
+		Description:  source.Description,
+		Status:       "active",
+		ContentHash:  source.ContentHash,
+		Variant:      source.Variant,
+		WebPFileKey:  source.WebPFileKey,
+	}
+	if source.FileType == "image" {
+		copied.Thumbnails, copied.ThumbnailStatus = sharedThumbnails(source)
+	}
+
+	if err := s.fileRepo.CreateReference(ctx, copied); err != nil {
+		return nil, fmt.Errorf("failed to save file metadata: %w", err)
+	}
+
+	return copied, nil
+}
+
+// ResolveFileKey は 配信するファイルのキーを返します
+// size が空または "original" の場合は元のファイル、サムネイルのサイズ名の場合は作成済みのサムネイル
+// （作成前・作成に失敗した場合は元のファイル）を返す。未定義のサイズ名は INVALID_THUMBNAIL_SIZE を返す
+func ResolveFileKey(fileMeta *models.FileMetadata, size string) (string, error) {
+	if size == "" || size == "original" {
+		return fileMeta.FileKey, nil
+	}
+	if _, ok := models.ThumbnailSizes[size]; !ok {
+		return "", apierror.NewValidationError(
+			"INVALID_THUMBNAIL_SIZE", fmt.Sprintf("サムネイルのサイズ %q は指定できません", size), nil,
+		)
+	}
+	if key, ok := fileMeta.Thumbnails[size]; ok && fileMeta.ThumbnailStatus == models.ThumbnailStatusReady {
+		return key, nil
+	}
+	return fileMeta.FileKey, nil
+}
+
 // ヘルパー関数
 
+// ファイルの名前・代替テキスト・説明の最大文字数
+const (
+	maxFileNameLength        = 255
+	maxFileAltTextLength     = 1000
+	maxFileDescriptionLength = 5000
+)
+
+// validateFileDetails は ファイルの名前・代替テキスト・説明の変更内容をバリデーションします
+// ファイル名は空白のみ・パス区切り文字・制御文字を含むものを受け付けない
+func validateFileDetails(update models.FileDetailsUpdate) error {
+	if update.OriginalName == nil && update.AltText == nil && update.Description == nil {
+		return apierror.NewValidationError("NO_FILE_CHANGES", "変更する項目を指定してください", nil)
+	}
+
+	if update.OriginalName != nil {
+		name := strings.TrimSpace(*update.OriginalName)
+		switch {
+		case name == "" || name == "." || name == "..":
+			return apierror.NewValidationError("INVALID_FILENAME", "ファイル名を入力してください", nil)
+		case utf8.RuneCountInString(name) > maxFileNameLength:
+			return apierror.NewValidationError(
+				"INVALID_FILENAME", fmt.Sprintf("ファイル名は%d文字以内で入力してください", maxFileNameLength), nil,
+			)
+		case strings.ContainsAny(name, `/\`) || strings.IndexFunc(name, unicode.IsControl) >= 0:
+			return apierror.NewValidationError("INVALID_FILENAME", "ファイル名に使用できない文字が含まれています", nil)
+		}
+	}
+
+	if update.AltText != nil && utf8.RuneCountInString(*update.AltText) > maxFileAltTextLength {
+		return apierror.NewValidationError(
+			"INVALID_ALT_TEXT", fmt.Sprintf("代替テキストは%d文字以内で入力してください", maxFileAltTextLength), nil,
+		)
+	}
+	if update.Description != nil && utf8.RuneCountInString(*update.Description) > maxFileDescriptionLength {
+		return apierror.NewValidationError(
+			"INVALID_DESCRIPTION", fmt.Sprintf("説明は%d文字以内で入力してください", maxFileDescriptionLength), nil,
+		)
+	}
+	return nil
+}
+
+// publishFileUploaded は 登録したファイルの file.uploaded イベントを、アップロードしたユーザー宛てに発行します
+// 保存済みのオブジェクトを参照した場合も、ユーザーにとってはアップロードの完了のため発行する
+func publishFileUploaded(ctx context.Context, bus *events.Bus, fileMeta *models.FileMetadata) {
+	bus.Publish(ctx, events.FileUploaded, fileMeta.UserID, events.FileData{
+		FileID:       fileMeta.ID,
+		OriginalName: fileMeta.OriginalName,
+		MimeType:     fileMeta.MimeType,
+		FileType:     fileMeta.FileType,
+		FileSize:     fileMeta.FileSize,
+	})
+}
+
+// createFileReference は ユーザーが同じ種類・内容のファイルを保存済みの場合に、そのオブジェクトを参照するファイルメタデータを保存します
+// fileMeta には UserID・FileType・ContentHash を設定しておく。保存済みのファイルがない場合は何もせず false を返す
+func createFileReference(ctx context.Context, fileRepo *repository.FileRepository, fileMeta *models.FileMetadata) (bool, error) {
+	if fileMeta.ContentHash == "" {
+		return false, nil
+	}
+
+	existing, err := fileRepo.GetActiveByContentHash(ctx, fileMeta.UserID, fileMeta.FileType, fileMeta.ContentHash)
+	if errors.Is(err, apierror.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	reference := *fileMeta
+	reference.FileKey = existing.FileKey
+	reference.BucketName = existing.BucketName
+	reference.Width = existing.Width
+	reference.Height = existing.Height
+	reference.DurationMs = existing.DurationMs
+	reference.Variant = existing.Variant
+	reference.WebPFileKey = existing.WebPFileKey
+	if fileMeta.FileType == "image" {
+		reference.Thumbnails, reference.ThumbnailStatus = sharedThumbnails(existing)
+	}
+
+	if err := fileRepo.CreateReference(ctx, &reference); err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			// 参照がすべて削除された直後のオブジェクトは使わず、新しく保存する
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to save file metadata: %w", err)
+	}
+
+	*fileMeta = reference
+	return true, nil
+}
+
+// sharedThumbnails は 同じオブジェクトを参照する画像のサムネイルとその作成状況を返します
+// 作成済みのサムネイルは共有し、それ以外の場合は改めて作成する（キーはオブジェクトから決まるため同じものになる）
+func sharedThumbnails(source *models.FileMetadata) (map[string]string, string) {
+	if source.ThumbnailStatus == models.ThumbnailStatusReady {
+		return source.Thumbnails, models.ThumbnailStatusReady
+	}
+	return nil, models.ThumbnailStatusPending
+}
+
+// hashContent は ファイルの内容の SHA-256 を16進数で返し、読み込み位置を先頭に戻します
+func hashContent(file io.ReadSeeker) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to reset file pointer: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
 // ImageDimensions は 画像の寸法を表します
 type ImageDimensions struct {
 	Width  int
@@ -345,8 +1185,12 @@ type ImageDimensions struct {
 }
 
 // getImageDimensions は 画像ファイルから寸法を取得します
+// デコーダーのない形式（設定で許可した SVG・HEIC など）の場合は寸法を取得せず nil を返す
 func getImageDimensions(file multipart.File) (*ImageDimensions, error) {
 	img, _, err := image.Decode(file)
+	if errors.Is(err, image.ErrFormat) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image: %w", err)
 	}
@@ -386,14 +1230,132 @@ func sanitizeFilename(filename string) string {
 	return reg.ReplaceAllString(filename, "_")
 }
 
-// isValidImageType は 画像のMIMEタイプをバリデーションします
-func isValidImageType(contentType string) bool {
-	validTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
-		"image/webp": true,
-		"image/gif":  true,
+// probeMedia は 動画・音声の再生時間と映像の大きさを取得して fileMeta に設定します
+// prober が nil の場合や取得できなかった場合は設定しない（失敗はログのみ残す）
+func probeMedia(ctx context.Context, prober mediaprobe.Prober, r io.ReaderAt, size int64, fileMeta *models.FileMetadata) {
+	if prober == nil {
+		return
+	}
+
+	info, err := prober.Probe(ctx, r, size, fileMeta.MimeType)
+	if err != nil {
+		logctx.Printf(ctx, "failed to probe media %s: %v", fileMeta.OriginalName, err)
+		return
+	}
+	if info.Width > 0 && info.Height > 0 {
+		fileMeta.Width, fileMeta.Height = &info.Width, &info.Height
+	}
+	if info.Duration > 0 {
+		durationMs := info.Duration.Milliseconds()
+		fileMeta.DurationMs = &durationMs
+	}
+}
+
+// objectReaderAt は ストレージ上のファイルを範囲を指定して読み込む io.ReaderAt です
+type objectReaderAt struct {
+	ctx           context.Context
+	objectStorage storage.ObjectStorage
+	fileKey       string
+}
+
+// ReadAt は off バイト目から len(p) バイトを読み込みます（ファイルの末尾に達した場合は io.EOF）
+func (r *objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
 	}
-	return validTypes[strings.ToLower(contentType)]
+	object, err := r.objectStorage.GetObjectRange(r.ctx, r.fileKey, off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	defer object.Close()
+
+	n, err := io.ReadFull(object, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// checkContentType は ファイルの先頭バイトから判定した MIME タイプが申告された contentType と一致するかを確認します
+// 一致しない場合は ErrContentTypeMismatch を返し、読み込み位置は先頭に戻す
+func checkContentType(file io.ReadSeeker, contentType string) error {
+	detected, err := detectContentType(file)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to reset file pointer: %w", err)
+	}
+	if !contentTypeMatches(contentType, detected) {
+		return fmt.Errorf("%w: declared %s, detected %s", ErrContentTypeMismatch, contentType, detected)
+	}
+	return nil
+}
+
+// detectContentType は ファイルの先頭バイト（マジックナンバー）から MIME タイプを判定します
+func detectContentType(r io.Reader) (string, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read file header: %w", err)
+	}
+	head = head[:n]
+
+	if bytes.HasPrefix(head, oleMagic) {
+		return oleContentType, nil
+	}
+	if isMP3FrameSync(head) {
+		return mp3ContentType, nil
+	}
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		if contentType, ok := heifBrandTypes[string(head[8:12])]; ok {
+			return contentType, nil
+		}
+	}
+	return http.DetectContentType(head), nil
+}
+
+// isMP3FrameSync は 先頭が MPEG Audio Layer III のフレームヘッダー（フレーム同期）かどうかを返します
+func isMP3FrameSync(head []byte) bool {
+	return len(head) >= 4 && head[0] == 0xFF && head[1]&0xE0 == 0xE0 &&
+		head[1]&0x06 == 0x02 && head[1]&0x18 != 0x08 && head[2]&0xF0 != 0xF0 && head[2]&0x0C != 0x0C
+}
+
+// contentTypeMatches は 申告された MIME タイプと中身から判定した MIME タイプが矛盾しないかを返します
+// OOXML（.docx など）は ZIP、旧形式の Office 文書は OLE、テキストと CSV はテキストとして判定される
+// M4A は MP4、Ogg はコンテナとして判定される
+// SVG は XML またはテキスト、HEIC は HEIF として判定される場合がある（設定で許可した場合のみアップロードできる）
+func contentTypeMatches(declared, detected string) bool {
+	declared = baseMediaType(declared)
+	detected = baseMediaType(detected)
+
+	switch declared {
+	case "image/jpg":
+		return detected == "image/jpeg"
+	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation":
+		return detected == "application/zip"
+	case "application/msword", "application/vnd.ms-excel", "application/vnd.ms-powerpoint":
+		return detected == oleContentType
+	case "text/plain", "text/csv":
+		return detected == "text/plain"
+	case "audio/wav", "audio/wave", "audio/x-wav":
+		return detected == "audio/wave"
+	case "audio/mp4", "audio/x-m4a":
+		return detected == "video/mp4"
+	case "audio/ogg":
+		return detected == "application/ogg"
+	case "image/svg+xml":
+		return detected == "text/xml" || detected == "text/plain"
+	case "image/heic", "image/heif":
+		return detected == "image/heic" || detected == "image/heif"
+	default:
+		return detected == declared
+	}
+}
+
+// baseMediaType は MIME タイプからパラメータ（charset など）を除いて小文字にします
+func baseMediaType(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
 }
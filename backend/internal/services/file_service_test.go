@@ -1,7 +1,19 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
 	"testing"
+	"time"
+
+	"simple-notion-backend/internal/mimetype"
+	"simple-notion-backend/internal/models"
 )
 
 // TestFileService_SanitizeFilename は sanitizeFilename 関数のテストです
@@ -93,60 +105,229 @@ func TestFileService_GenerateFileKey(t *testing.T) {
 	}
 }
 
-// TestFileService_IsValidImageType は isValidImageType 関数のテストです
-func TestFileService_IsValidImageType(t *testing.T) {
+// TestCheckContentType は checkContentType 関数のテストです
+func TestCheckContentType(t *testing.T) {
+	pngHeader := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdfHeader := []byte("%PDF-1.7\n")
+	zipHeader := []byte("PK\x03\x04\x14\x00\x06\x00")
+	oleHeader := append(append([]byte{}, oleMagic...), make([]byte, 16)...)
+	exeHeader := []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff")
+
 	tests := []struct {
 		name        string
+		content     []byte
 		contentType string
-		expected    bool
+		wantErr     bool
+	}{
+		{name: "正常系：PNG", content: pngHeader, contentType: "image/png"},
+		{name: "正常系：PDF", content: pdfHeader, contentType: "application/pdf"},
+		{name: "正常系：docx は ZIP", content: zipHeader, contentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{name: "正常系：doc は OLE", content: oleHeader, contentType: "application/msword"},
+		{name: "正常系：CSV", content: []byte("id,name\n1,foo\n"), contentType: "text/csv"},
+		{name: "正常系：パラメータ付きのテキスト", content: []byte("hello"), contentType: "text/plain; charset=utf-8"},
+		{name: "正常系：ID3 タグのない MP3", content: []byte("\xff\xfb\x90\x64\x00\x00\x00\x00"), contentType: "audio/mpeg"},
+		{name: "正常系：ID3 タグのある MP3", content: []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), contentType: "audio/mpeg"},
+		{name: "正常系：WAV", content: []byte("RIFF\x24\x00\x00\x00WAVEfmt "), contentType: "audio/x-wav"},
+		{name: "正常系：Ogg", content: []byte("OggS\x00\x02\x00\x00"), contentType: "audio/ogg"},
+		{name: "正常系：SVG", content: []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), contentType: "image/svg+xml"},
+		{name: "正常系：HEIC", content: []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic"), contentType: "image/heic"},
+		{name: "正常系：HEIF ブランドの HEIC", content: []byte("\x00\x00\x00\x18ftypmif1\x00\x00\x00\x00mif1heic"), contentType: "image/heic"},
+		{name: "異常系：PDF と偽った実行ファイル", content: exeHeader, contentType: "application/pdf", wantErr: true},
+		{name: "異常系：HEIC と偽った MP4", content: []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), contentType: "image/heic", wantErr: true},
+		{name: "異常系：SVG と偽った PNG", content: pngHeader, contentType: "image/svg+xml", wantErr: true},
+		{name: "異常系：PNG と偽った JPEG", content: []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), contentType: "image/png", wantErr: true},
+		{name: "異常系：テキストと偽った PDF", content: pdfHeader, contentType: "text/plain", wantErr: true},
+		{name: "異常系：MP3 と偽った WAV", content: []byte("RIFF\x24\x00\x00\x00WAVEfmt "), contentType: "audio/mpeg", wantErr: true},
+		{name: "異常系：docx と偽った PDF", content: pdfHeader, contentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := bytes.NewReader(tt.content)
+			err := checkContentType(file, tt.contentType)
+			if tt.wantErr {
+				if !errors.Is(err, ErrContentTypeMismatch) {
+					t.Fatalf("checkContentType() error = %v, want ErrContentTypeMismatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkContentType() error = %v", err)
+			}
+			// 判定後は先頭から読み直せること
+			if pos, _ := file.Seek(0, io.SeekCurrent); pos != 0 {
+				t.Errorf("file position = %d, want 0", pos)
+			}
+		})
+	}
+}
+
+// TestHashContent は hashContent 関数のテストです
+func TestHashContent(t *testing.T) {
+	file := bytes.NewReader([]byte("hello"))
+	got, err := hashContent(file)
+	if err != nil {
+		t.Fatalf("hashContent() error = %v", err)
+	}
+	if want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; got != want {
+		t.Errorf("hashContent() = %q, want %q", got, want)
+	}
+	// 計算後は先頭から読み直せること
+	if pos, _ := file.Seek(0, io.SeekCurrent); pos != 0 {
+		t.Errorf("file position = %d, want 0", pos)
+	}
+}
+
+// TestSharedThumbnails は sharedThumbnails 関数のテストです
+func TestSharedThumbnails(t *testing.T) {
+	thumbnails := map[string]string{"thumb": "thumbnails/thumb/images/1/uuid_photo.png"}
+
+	tests := []struct {
+		name           string
+		source         *models.FileMetadata
+		wantThumbnails map[string]string
+		wantStatus     string
 	}{
 		{
-			name:        "JPEG画像",
-			contentType: "image/jpeg",
-			expected:    true,
-		},
-		{
-			name:        "PNG画像",
-			contentType: "image/png",
-			expected:    true,
-		},
-		{
-			name:        "WebP画像",
-			contentType: "image/webp",
-			expected:    true,
-		},
-		{
-			name:        "GIF画像",
-			contentType: "image/gif",
-			expected:    true,
-		},
-		{
-			name:        "無効なタイプ（PDF）",
-			contentType: "application/pdf",
-			expected:    false,
+			name:           "正常系：作成済みのサムネイルを共有",
+			source:         &models.FileMetadata{Thumbnails: thumbnails, ThumbnailStatus: models.ThumbnailStatusReady},
+			wantThumbnails: thumbnails,
+			wantStatus:     models.ThumbnailStatusReady,
 		},
 		{
-			name:        "無効なタイプ（テキスト）",
-			contentType: "text/plain",
-			expected:    false,
+			name:       "正常系：作成中の場合は作成待ち",
+			source:     &models.FileMetadata{ThumbnailStatus: models.ThumbnailStatusProcessing},
+			wantStatus: models.ThumbnailStatusPending,
 		},
 		{
-			name:        "空の文字列",
-			contentType: "",
-			expected:    false,
+			name:       "正常系：作成に失敗した場合は作成待ち",
+			source:     &models.FileMetadata{ThumbnailStatus: models.ThumbnailStatusFailed},
+			wantStatus: models.ThumbnailStatusPending,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isValidImageType(tt.contentType)
-			if result != tt.expected {
-				t.Errorf("isValidImageType(%q) = %v, want %v", tt.contentType, result, tt.expected)
+			gotThumbnails, gotStatus := sharedThumbnails(tt.source)
+			if gotStatus != tt.wantStatus {
+				t.Errorf("status = %q, want %q", gotStatus, tt.wantStatus)
+			}
+			if len(gotThumbnails) != len(tt.wantThumbnails) || gotThumbnails["thumb"] != tt.wantThumbnails["thumb"] {
+				t.Errorf("thumbnails = %v, want %v", gotThumbnails, tt.wantThumbnails)
+			}
+		})
+	}
+}
+
+// TestValidateFileDetails は validateFileDetails 関数のテストです
+func TestValidateFileDetails(t *testing.T) {
+	str := func(v string) *string { return &v }
+
+	tests := []struct {
+		name     string
+		update   models.FileDetailsUpdate
+		wantCode string
+	}{
+		{name: "正常系：ファイル名のみ", update: models.FileDetailsUpdate{OriginalName: str("議事録 2024.pdf")}},
+		{name: "正常系：代替テキストを空にする", update: models.FileDetailsUpdate{AltText: str("")}},
+		{name: "正常系：説明のみ", update: models.FileDetailsUpdate{Description: str("第1四半期の資料")}},
+		{name: "異常系：変更なし", update: models.FileDetailsUpdate{}, wantCode: "NO_FILE_CHANGES"},
+		{name: "異常系：空白のみのファイル名", update: models.FileDetailsUpdate{OriginalName: str("  ")}, wantCode: "INVALID_FILENAME"},
+		{name: "異常系：パス区切り文字", update: models.FileDetailsUpdate{OriginalName: str("../secret.pdf")}, wantCode: "INVALID_FILENAME"},
+		{name: "異常系：制御文字", update: models.FileDetailsUpdate{OriginalName: str("a\nb.pdf")}, wantCode: "INVALID_FILENAME"},
+		{name: "異常系：長すぎるファイル名", update: models.FileDetailsUpdate{OriginalName: str(strings.Repeat("あ", 256))}, wantCode: "INVALID_FILENAME"},
+		{name: "異常系：長すぎる代替テキスト", update: models.FileDetailsUpdate{AltText: str(strings.Repeat("a", 1001))}, wantCode: "INVALID_ALT_TEXT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFileDetails(tt.update)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("validateFileDetails() error = %v", err)
+				}
+				return
 			}
+			assertAppErrorCode(t, err, http.StatusBadRequest, tt.wantCode)
 		})
 	}
 }
 
+// TestResolveFileKey は ResolveFileKey 関数のテストです
+func TestResolveFileKey(t *testing.T) {
+	ready := &models.FileMetadata{
+		FileKey:         "images/1/uuid_photo.png",
+		Thumbnails:      map[string]string{"thumb": "thumbnails/thumb/images/1/uuid_photo.png"},
+		ThumbnailStatus: models.ThumbnailStatusReady,
+	}
+	pending := &models.FileMetadata{
+		FileKey:         "images/1/uuid_photo.png",
+		ThumbnailStatus: models.ThumbnailStatusPending,
+	}
+
+	tests := []struct {
+		name    string
+		file    *models.FileMetadata
+		size    string
+		want    string
+		wantErr bool
+	}{
+		{name: "正常系：サイズ指定なし", file: ready, size: "", want: "images/1/uuid_photo.png"},
+		{name: "正常系：original", file: ready, size: "original", want: "images/1/uuid_photo.png"},
+		{name: "正常系：作成済みのサムネイル", file: ready, size: "thumb", want: "thumbnails/thumb/images/1/uuid_photo.png"},
+		{name: "正常系：作成前は元のファイル", file: pending, size: "thumb", want: "images/1/uuid_photo.png"},
+		{name: "異常系：未定義のサイズ", file: ready, size: "huge", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveFileKey(tt.file, tt.size)
+			if tt.wantErr {
+				assertAppErrorCode(t, err, http.StatusBadRequest, "INVALID_THUMBNAIL_SIZE")
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveFileKey() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveFileKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestZipEntryName は zipEntryName 関数のテストです
+func TestZipEntryName(t *testing.T) {
+	used := map[string]bool{}
+	tests := []struct {
+		name string
+		file *models.FileMetadata
+		want string
+	}{
+		{name: "正常系：元のファイル名", file: &models.FileMetadata{OriginalName: "report.pdf"}, want: "report.pdf"},
+		{name: "正常系：同じ名前には番号を付ける", file: &models.FileMetadata{OriginalName: "report.pdf"}, want: "report (2).pdf"},
+		{name: "正常系：3つ目の同じ名前", file: &models.FileMetadata{OriginalName: "report.pdf"}, want: "report (3).pdf"},
+		{name: "正常系：ディレクトリを含む名前は名前の部分だけ", file: &models.FileMetadata{OriginalName: "../../etc\\passwd"}, want: "passwd"},
+		{name: "正常系：名前がない場合はファイルキー", file: &models.FileMetadata{FileKey: "files/1/uuid_memo.txt"}, want: "uuid_memo.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := zipEntryName(tt.file, used); got != tt.want {
+				t.Errorf("zipEntryName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestThumbnailKey は thumbnailKey 関数のテストです
+func TestThumbnailKey(t *testing.T) {
+	got := thumbnailKey("images/1/uuid_photo.png", "medium", ".jpg")
+	if want := "thumbnails/medium/images/1/uuid_photo.jpg"; got != want {
+		t.Errorf("thumbnailKey() = %q, want %q", got, want)
+	}
+}
+
 // TestFileService_CleanupOrphanedFiles は CleanupOrphanedFiles メソッドの基本的なテストです
 func TestFileService_CleanupOrphanedFiles(t *testing.T) {
 	// このテストは実際のデータベースとS3クライアントが必要なため、
@@ -176,3 +357,68 @@ func Benchmark_SanitizeFilename(b *testing.B) {
 		_ = sanitizeFilename(filename)
 	}
 }
+
+// TestFileService_SetStorageQuota_Validation は クォータの変更内容の検証のテストです
+func TestFileService_SetStorageQuota_Validation(t *testing.T) {
+	s := &FileService{}
+	negative := int64(-1)
+
+	_, err := s.SetStorageQuota(context.Background(), 1, models.StorageQuotaUpdate{QuotaBytes: &negative}, 100)
+	assertAppErrorCode(t, err, http.StatusBadRequest, "INVALID_QUOTA")
+}
+
+// TestFileService_CheckStorageQuota_WorkspaceRole は ワークスペースにアップロードできない役割の確認のテストです
+func TestFileService_CheckStorageQuota_WorkspaceRole(t *testing.T) {
+	workspaces := NewWorkspaceService(&MockWorkspaceRepository{
+		GetMemberRoleFunc: workspaceRoles(map[int]models.WorkspaceRole{11: models.WorkspaceRoleGuest}),
+	}, &MockUserRepository{}, 0, 0, 0, nil)
+	s := &FileService{workspaces: workspaces}
+	workspaceID := 1
+
+	err := s.CheckStorageQuota(context.Background(), 11, &workspaceID, 100, 1000)
+	assertAppErrorCode(t, err, http.StatusForbidden, "WORKSPACE_READ_ONLY")
+
+	err = s.CheckStorageQuota(context.Background(), 13, &workspaceID, 100, 1000)
+	assertAppErrorCode(t, err, http.StatusForbidden, "WORKSPACE_ACCESS_DENIED")
+}
+
+func TestFileService_ListFiles_Validation(t *testing.T) {
+	s := &FileService{}
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		opts         models.FileListOptions
+		expectedCode string
+	}{
+		{name: "異常系：不明な種類", opts: models.FileListOptions{FileType: "pdf"}, expectedCode: "INVALID_FILE_TYPE"},
+		{name: "異常系：MIME タイプの形式が不正", opts: models.FileListOptions{MimeType: "png"}, expectedCode: "INVALID_MIME_TYPE"},
+		{name: "異常系：from が to より後", opts: models.FileListOptions{UploadedFrom: &from, UploadedTo: &to}, expectedCode: "INVALID_DATE_RANGE"},
+		{name: "異常系：不明な並び順", opts: models.FileListOptions{Sort: "mimeType"}, expectedCode: "INVALID_SORT"},
+		{name: "異常系：不明な昇順・降順", opts: models.FileListOptions{Order: "up"}, expectedCode: "INVALID_ORDER"},
+		{name: "異常系：limit が上限を超える", opts: models.FileListOptions{Limit: maxFileListLimit + 1}, expectedCode: "INVALID_PAGINATION"},
+		{name: "異常系：limit が負", opts: models.FileListOptions{Limit: -1}, expectedCode: "INVALID_PAGINATION"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ListFiles(context.Background(), 1, tt.opts)
+			assertAppErrorCode(t, err, http.StatusBadRequest, tt.expectedCode)
+		})
+	}
+}
+
+func TestFileService_Upload_UnsupportedType(t *testing.T) {
+	s := &FileService{allowedTypes: mimetype.NewAllowlist(nil)}
+	header := &multipart.FileHeader{
+		Filename: "script.sh",
+		Header:   textproto.MIMEHeader{"Content-Type": {"application/x-sh"}},
+		Size:     4,
+	}
+
+	_, err := s.Upload(context.Background(), 1, nil, header)
+	if !errors.Is(err, ErrUnsupportedFileType) {
+		t.Errorf("err = %v, want ErrUnsupportedFileType", err)
+	}
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/mimetype"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/repository"
+)
+
+// FileVersionService は ファイルの版（同じファイルIDのまま内容を置き換える）のビジネスロジックを提供します
+// 置き換えた版は以前の版として記録し、保持期間が経過するまで復元できる（削除は FilePurgeService で行う）
+type FileVersionService struct {
+	fileRepo    *repository.FileRepository
+	versionRepo *repository.FileVersionRepository
+	files       *FileService // アップロードの検証・ストレージへの保存に使う
+}
+
+// NewFileVersionService は 新しい FileVersionService インスタンスを作成します
+func NewFileVersionService(
+	fileRepo *repository.FileRepository,
+	versionRepo *repository.FileVersionRepository,
+	files *FileService,
+) *FileVersionService {
+	return &FileVersionService{
+		fileRepo:    fileRepo,
+		versionRepo: versionRepo,
+		files:       files,
+	}
+}
+
+// ListVersions は ファイルの現在の版の番号と以前の版の一覧を取得します
+func (s *FileVersionService) ListVersions(ctx context.Context, fileID int, userID int) (*models.FileVersionList, error) {
+	if _, err := s.getOwnedFile(ctx, fileID, userID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.versionRepo.GetCurrentVersion(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.versionRepo.ListByFileID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		versions = []*models.FileVersion{}
+	}
+
+	return &models.FileVersionList{FileID: fileID, CurrentVersion: current, Versions: versions}, nil
+}
+
+// UploadVersion は ファイルの新しい版をアップロードします
+// 新しい版はファイルと同じ種類（画像・文書・動画・音声）である必要があり、種類ごとのサイズ上限を使う
+// サイズ超過は ErrFileTooLarge、許可していない MIME タイプは ErrUnsupportedFileType を返す
+func (s *FileVersionService) UploadVersion(
+	ctx context.Context,
+	fileID int,
+	userID int,
+	file multipart.File,
+	header *multipart.FileHeader,
+) (*models.FileMetadata, error) {
+	// 1. 置き換えるファイルを取得
+	current, err := s.getOwnedFile(ctx, fileID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. ファイルの種類に応じたサイズと MIME タイプのバリデーション
+	maxFileSize, isValidType := s.files.uploadLimits(current.FileType)
+	if header.Size > maxFileSize {
+		return nil, fmt.Errorf("%w: maximum allowed size is %d bytes", ErrFileTooLarge, maxFileSize)
+	}
+	contentType := header.Header.Get("Content-Type")
+	if !isValidType(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType)
+	}
+	if err := checkContentType(file, contentType); err != nil {
+		return nil, err
+	}
+
+	next := &models.FileMetadata{
+		ID:              current.ID,
+		UserID:          userID,
+		FileType:        current.FileType,
+		OriginalName:    header.Filename,
+		FileSize:        header.Size,
+		MimeType:        contentType,
+		ThumbnailStatus: models.ThumbnailStatusNone,
+	}
+
+	// 3. 画像の寸法・動画と音声の再生時間を取得
+	switch current.FileType {
+	case "image":
+		dimensions, err := getImageDimensions(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get image dimensions: %w", err)
+		}
+		// サムネイルはバックグラウンドの ThumbnailService で作成し直す（デコードできない形式は作成しない）
+		if dimensions != nil {
+			next.Width, next.Height = &dimensions.Width, &dimensions.Height
+			next.ThumbnailStatus = models.ThumbnailStatusPending
+		}
+	case "video", "audio":
+		probeMedia(ctx, s.files.mediaProber, file, header.Size, next)
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("failed to reset file pointer: %w", err)
+	}
+
+	// 4. 内容のハッシュを計算
+	contentHash, err := hashContent(file)
+	if err != nil {
+		return nil, err
+	}
+	next.ContentHash = contentHash
+
+	// 5. 新しいオブジェクトとしてストレージにアップロードし、ファイルの現在の版を置き換える
+	next.FileKey = generateFileKey(userID, header.Filename, fileKeyPrefix(current.FileType))
+	if err := s.files.objectStorage.UploadFile(ctx, next.FileKey, file, next.FileSize, next.MimeType); err != nil {
+		return nil, fmt.Errorf("failed to upload file to storage: %w", err)
+	}
+	if err := s.versionRepo.ReplaceCurrent(ctx, next, fileSrc(next.FileKey)); err != nil {
+		// アップロード済みのファイルを削除
+		_ = s.files.objectStorage.DeleteFile(ctx, next.FileKey)
+		return nil, fmt.Errorf("failed to replace file version: %w", err)
+	}
+
+	return s.fileRepo.GetByID(ctx, fileID)
+}
+
+// RestoreVersion は 以前の版の内容でファイルの新しい版を作成します（置き換える前の版も以前の版として残す）
+func (s *FileVersionService) RestoreVersion(ctx context.Context, fileID int, versionID int, userID int) (*models.FileMetadata, error) {
+	current, err := s.getOwnedFile(ctx, fileID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.versionRepo.GetByID(ctx, fileID, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := &models.FileMetadata{
+		ID:           fileID,
+		UserID:       userID,
+		FileType:     current.FileType,
+		FileKey:      version.FileKey,
+		OriginalName: version.OriginalName,
+		FileSize:     version.FileSize,
+		MimeType:     version.MimeType,
+		ContentHash:  version.ContentHash,
+		Width:        version.Width,
+		Height:       version.Height,
+		DurationMs:   version.DurationMs,
+		Variant:      version.Variant,
+		WebPFileKey:  version.WebPFileKey,
+		// 画像は版のサムネイルを戻す（記録がない場合は作成し直す）
+		ThumbnailStatus: models.ThumbnailStatusNone,
+	}
+	if current.FileType == "image" {
+		restored.Thumbnails, restored.ThumbnailStatus = version.Thumbnails, models.ThumbnailStatusReady
+		if len(version.Thumbnails) == 0 {
+			restored.ThumbnailStatus = models.ThumbnailStatusPending
+		}
+	}
+
+	if err := s.versionRepo.Restore(ctx, restored, versionID, fileSrc(restored.FileKey)); err != nil {
+		return nil, fmt.Errorf("failed to restore file version: %w", err)
+	}
+
+	return s.fileRepo.GetByID(ctx, fileID)
+}
+
+// getOwnedFile は ユーザーの削除されていないファイルを取得します
+// 他のユーザーのファイルは ErrForbidden、削除済み・孤立したファイルは ErrNotFound を返す
+func (s *FileVersionService) getOwnedFile(ctx context.Context, fileID int, userID int) (*models.FileMetadata, error) {
+	fileMeta, err := s.fileRepo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	if fileMeta.UserID != userID {
+		return nil, fmt.Errorf("user %d does not own file %d: %w", userID, fileID, apierror.ErrForbidden)
+	}
+	if fileMeta.Status != "active" {
+		return nil, fmt.Errorf("file metadata id=%d is %s: %w", fileID, fileMeta.Status, apierror.ErrNotFound)
+	}
+	return fileMeta, nil
+}
+
+// uploadLimits は ファイルの種類ごとのサイズ上限と MIME タイプのバリデーションを返します
+func (s *FileService) uploadLimits(fileType string) (int64, func(string) bool) {
+	maxFileSize, category := s.maxFileSize, mimetype.Document
+	switch fileType {
+	case "image":
+		category = mimetype.Image
+	case "video":
+		maxFileSize, category = s.maxVideoFileSize, mimetype.Video
+	case "audio":
+		maxFileSize, category = s.maxAudioFileSize, mimetype.Audio
+	}
+	return maxFileSize, func(contentType string) bool {
+		return s.allowedTypes.Allowed(category, contentType)
+	}
+}
+
+// fileKeyPrefix は ファイルの種類ごとのストレージのキーの先頭です
+func fileKeyPrefix(fileType string) string {
+	switch fileType {
+	case "image":
+		return "images"
+	case "video":
+		return "videos"
+	case "audio":
+		return "audio"
+	default:
+		return "files"
+	}
+}
+
+// fileSrc は ブロックの content の src に設定する、ファイルの配信 URL（相対パス）です
+func fileSrc(fileKey string) string {
+	return fmt.Sprintf("/api/uploads/%s", filepath.Base(fileKey))
+}
@@ -0,0 +1,47 @@
+package services
+
+import "testing"
+
+// TestFileService_UploadLimits は uploadLimits 関数のテストです
+func TestFileService_UploadLimits(t *testing.T) {
+	s := &FileService{maxFileSize: 10, maxVideoFileSize: 500, maxAudioFileSize: 50}
+
+	tests := []struct {
+		name           string
+		fileType       string
+		expectedSize   int64
+		validType      string
+		invalidType    string
+		expectedPrefix string
+	}{
+		{name: "正常系：画像", fileType: "image", expectedSize: 10, validType: "image/png", invalidType: "application/pdf", expectedPrefix: "images"},
+		{name: "正常系：文書", fileType: "file", expectedSize: 10, validType: "application/pdf", invalidType: "image/png", expectedPrefix: "files"},
+		{name: "正常系：動画", fileType: "video", expectedSize: 500, validType: "video/mp4", invalidType: "audio/mpeg", expectedPrefix: "videos"},
+		{name: "正常系：音声", fileType: "audio", expectedSize: 50, validType: "audio/mpeg", invalidType: "video/mp4", expectedPrefix: "audio"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize, isValidType := s.uploadLimits(tt.fileType)
+			if maxSize != tt.expectedSize {
+				t.Errorf("uploadLimits(%q) size = %d, want %d", tt.fileType, maxSize, tt.expectedSize)
+			}
+			if !isValidType(tt.validType) {
+				t.Errorf("uploadLimits(%q) should accept %q", tt.fileType, tt.validType)
+			}
+			if isValidType(tt.invalidType) {
+				t.Errorf("uploadLimits(%q) should reject %q", tt.fileType, tt.invalidType)
+			}
+			if got := fileKeyPrefix(tt.fileType); got != tt.expectedPrefix {
+				t.Errorf("fileKeyPrefix(%q) = %q, want %q", tt.fileType, got, tt.expectedPrefix)
+			}
+		})
+	}
+}
+
+// TestFileSrc は fileSrc 関数のテストです
+func TestFileSrc(t *testing.T) {
+	if got, want := fileSrc("images/1/uuid_photo.png"), "/api/uploads/uuid_photo.png"; got != want {
+		t.Errorf("fileSrc() = %q, want %q", got, want)
+	}
+}
@@ -1,25 +1,49 @@
 package services
 
-import "simple-notion-backend/internal/models"
+import (
+	"context"
+	"time"
+
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
 
 // DocumentCoreRepositoryInterface - DocumentCoreRepositoryのインターフェース
 type DocumentCoreRepositoryInterface interface {
 	GetDocument(docID, userID int) (*models.Document, error)
+	GetDocumentsByIDs(docIDs []int, userID int) ([]models.Document, error)
 	GetDocumentIncludingDeleted(docID, userID int) (*models.Document, error)
 	CreateDocument(doc *models.Document) error
-	UpdateDocument(docID, userID int, title, content string) error
+	UpdateDocument(ctx context.Context, docID, userID int, title, content string) error
+	PatchDocument(ctx context.Context, docID, userID int, title, content *string) error
 	GetAllDocuments(userID int) ([]models.Document, error)
+	UpdateSearchText(docID int, text string) error
+	SearchDocuments(userID int, keyword string, page pagination.Params) (pagination.Page[models.DocumentSearchResult], error)
 }
 
 // BlockRepositoryInterface - BlockRepositoryのインターフェース
 type BlockRepositoryInterface interface {
 	GetBlocksByDocumentID(docID int) ([]models.Block, error)
-	UpdateBlocks(docID int, blocks []models.Block) error
+	GetBlocksByDocumentIDs(docIDs []int) (map[int][]models.Block, error)
+	UpdateBlocks(ctx context.Context, docID, editorID int, blocks []models.Block) error
+	UpdateDocumentIfUnchanged(ctx context.Context, docID, editorID int, title, content string, blocks []models.Block, expected models.DocumentContent) error
+	GetBlock(docID, blockID int) (*models.Block, error)
+	GetBlockByID(blockID int) (*models.Block, error)
+	CreateBlock(block *models.Block) error
+	CopyBlocks(docID int, parentID *int, position int, blocks []models.Block) error
+	CreateSyncedBlock(synced *models.SyncedBlock) error
+	UpdateBlock(block *models.Block, editorID int) error
+	DeleteBlock(docID, blockID int) error
+	ApplyOperations(ctx context.Context, docID, editorID int, ops []models.BlockOperation) error
+	ListBlockHistory(docID, blockID int) ([]models.BlockEditEvent, error)
+	CountBlocksByType() ([]models.BlockTypeCount, error)
+	GetCollapsedBlockIDs(userID, docID int) ([]int, error)
+	SetBlockCollapsed(userID, blockID int, collapsed bool) error
 }
 
 // DocumentTreeRepositoryInterface - DocumentTreeRepositoryのインターフェース
 type DocumentTreeRepositoryInterface interface {
-	GetDocumentTree(userID int) ([]models.DocumentTreeNode, error)
+	GetDocumentTree(userID int, workspaceID *int) ([]models.DocumentTreeNode, error)
 	MoveDocument(docID int, newParentID *int, userID int) error
 }
 
@@ -27,7 +51,223 @@ type DocumentTreeRepositoryInterface interface {
 type DocumentTrashRepositoryInterface interface {
 	SoftDeleteDocument(docID, userID int) error
 	RestoreDocument(docID, userID int) error
+	BulkRestoreDocuments(userID int, ids []int, strategy models.RestoreStrategy) ([]models.RestoreResult, error)
 	PermanentDeleteDocument(docID, userID int) error
-	GetTrashedDocuments(userID int) ([]models.Document, error)
+	GetTrashedDocuments(userID int, filter models.TrashFilter, page pagination.Params) (pagination.Page[models.Document], error)
 	EmptyTrash(userID int) error
 }
+
+// DocumentVersionRepositoryInterface - DocumentVersionRepositoryのインターフェース
+type DocumentVersionRepositoryInterface interface {
+	CreateVersion(version *models.DocumentVersion) error
+	GetVersion(docID, versionNumber int) (*models.DocumentVersion, error)
+	GetVersionAtOrBefore(docID, versionNumber int) (*models.DocumentVersion, error)
+	ListVersions(docID int) ([]models.DocumentVersion, error)
+	MergeVersions(before time.Time, window time.Duration) (int, error)
+	DeleteVersionsBefore(before time.Time) (int, error)
+}
+
+// PublicationRepositoryInterface - PublicationRepositoryのインターフェース
+type PublicationRepositoryInterface interface {
+	GetPublication(docID int) (*models.DocumentPublication, error)
+	SavePublication(pub *models.DocumentPublication) error
+	ApplySchedules() (published, unpublished int64, err error)
+	GetPublicDocument(docID int) (*models.Document, error)
+}
+
+// SecurityEventRepositoryInterface - SecurityEventRepositoryのインターフェース
+type SecurityEventRepositoryInterface interface {
+	CreateEvent(event *models.SecurityEvent) error
+}
+
+// NotificationRepositoryInterface - NotificationRepositoryのインターフェース
+type NotificationRepositoryInterface interface {
+	CreateNotification(n *models.Notification) error
+	ListNotifications(userID int, unreadOnly bool, page pagination.Params) (pagination.Page[models.Notification], error)
+	MarkRead(id, userID int) error
+}
+
+// ExpirationRepositoryInterface - ExpirationRepositoryのインターフェース
+type ExpirationRepositoryInterface interface {
+	GetExpiration(docID int) (*models.DocumentExpiration, error)
+	SaveExpiration(exp *models.DocumentExpiration) error
+	DeleteExpiration(docID int) error
+	ListExpirationsToWarn(warnBefore time.Duration) ([]models.ExpiringDocument, error)
+	MarkWarned(docID int) error
+	ListDueExpirations() ([]models.ExpiringDocument, error)
+}
+
+// ReminderRepositoryInterface - ReminderRepositoryのインターフェース
+type ReminderRepositoryInterface interface {
+	CreateReminder(reminder *models.Reminder) error
+	ListReminders(userID int) ([]models.Reminder, error)
+	DismissReminder(id, userID int) error
+	ListDueReminders() ([]models.Reminder, error)
+	MarkDelivered(id int) error
+}
+
+// CustomBlockTypeRepositoryInterface - CustomBlockTypeRepositoryのインターフェース
+type CustomBlockTypeRepositoryInterface interface {
+	ListBlockTypes() ([]models.CustomBlockType, error)
+	CreateBlockType(bt *models.CustomBlockType) error
+	UpdateBlockType(bt *models.CustomBlockType) error
+	DeleteBlockType(name string) error
+}
+
+// UserRepositoryInterface - UserRepositoryのインターフェース
+type UserRepositoryInterface interface {
+	GetByID(id int) (*models.User, error)
+	GetByEmail(email string) (*models.User, error)
+}
+
+// WorkspaceRepositoryInterface - WorkspaceRepositoryのインターフェース
+type WorkspaceRepositoryInterface interface {
+	CreateWorkspace(workspace *models.Workspace) error
+	GetWorkspace(workspaceID, userID int) (*models.Workspace, error)
+	ListWorkspaces(userID int) ([]models.Workspace, error)
+	RenameWorkspace(workspaceID int, name string) error
+	DeleteWorkspace(workspaceID int) error
+	ListMembers(workspaceID int) ([]models.WorkspaceMember, error)
+	GetMemberRole(workspaceID, userID int) (models.WorkspaceRole, error)
+	AddMember(workspaceID, userID int, role models.WorkspaceRole) error
+	UpdateMemberRole(workspaceID, userID int, role models.WorkspaceRole) error
+	RemoveMember(workspaceID, userID int) error
+	GetUsage(workspaceID int) (documents, members int, err error)
+	GetStorageQuota(workspaceID int) (*int64, error)
+	SetStorageQuota(workspaceID int, quota *int64) error
+	GetStorageUsedBytes(workspaceID int) (int64, error)
+	ListMemberStorageUsage(workspaceID int) ([]models.WorkspaceMemberStorage, error)
+}
+
+// WorkspaceRoleCheckerInterface - ユーザーのワークスペースでの役割を返すインターフェース（WorkspaceService が実装する。メンバーでない場合は空文字）
+type WorkspaceRoleCheckerInterface interface {
+	MemberRole(workspaceID, userID int) (models.WorkspaceRole, error)
+}
+
+// WorkspaceStorageCheckerInterface - ワークスペースのストレージクォータと、アップロードするユーザーの役割を確認するインターフェース（WorkspaceService が実装する）
+type WorkspaceStorageCheckerInterface interface {
+	WorkspaceRoleCheckerInterface
+	CheckStorageQuota(workspaceID int, newFileSize int64) error
+}
+
+// WorkspaceInvitationRepositoryInterface - WorkspaceInvitationRepositoryのインターフェース
+type WorkspaceInvitationRepositoryInterface interface {
+	CreateInvitation(invitation *models.WorkspaceInvitation, tokenHash string) error
+	ListPendingInvitations(workspaceID int) ([]models.WorkspaceInvitation, error)
+	GetInvitationByTokenHash(tokenHash string) (*models.WorkspaceInvitation, error)
+	RevokeInvitation(workspaceID, invitationID int) error
+	AcceptInvitation(invitation *models.WorkspaceInvitation, userID int) (added bool, err error)
+}
+
+// MentionRepositoryInterface - MentionRepositoryのインターフェース
+type MentionRepositoryInterface interface {
+	SyncMentions(docID int, sourceType models.MentionSourceType, sourceID, mentionedBy int, mentions []models.Mention) ([]models.Mention, error)
+}
+
+// MailerInterface - Mailerのインターフェース（テンプレートからメールを作成し、送信待ちの列に追加する）
+type MailerInterface interface {
+	SendTemplate(to, name string, data interface{}) error
+}
+
+// ShareLinkRepositoryInterface - ShareLinkRepositoryのインターフェース
+type ShareLinkRepositoryInterface interface {
+	CreateShareLink(link *models.ShareLink, tokenHash string) error
+	ListShareLinks(docID int) ([]models.ShareLink, error)
+	GetShareLinkByTokenHash(tokenHash string) (*models.ShareLink, error)
+	RevokeShareLink(docID, linkID int) error
+	RecordAccess(linkID int) error
+	GetSharedDocument(docID int) (*models.Document, error)
+	CreateSnapshot(snapshot *models.DocumentSnapshot) error
+	GetSnapshot(snapshotID int) (*models.DocumentSnapshot, error)
+}
+
+// SnapshotFileCopierInterface - 文書のスナップショット用にファイルを複製するインターフェース（FileService が実装する）
+type SnapshotFileCopierInterface interface {
+	CopyFile(ctx context.Context, fileID int, userID int, workspaceID *int, quota int64) (*models.FileMetadata, error)
+	DeleteFile(ctx context.Context, fileID int, userID int) error
+}
+
+// CollaboratorRepositoryInterface - CollaboratorRepositoryのインターフェース
+type CollaboratorRepositoryInterface interface {
+	ListCollaborators(docID int) ([]models.DocumentCollaborator, error)
+	GetCollaboratorRole(docID, userID int) (models.DocumentRole, error)
+	AddCollaborator(collaborator *models.DocumentCollaborator) error
+	UpdateCollaboratorRole(docID, userID int, role models.DocumentRole) error
+	RemoveCollaborator(docID, userID int) error
+}
+
+// DocumentWatchRepositoryInterface - DocumentWatchRepositoryのインターフェース
+type DocumentWatchRepositoryInterface interface {
+	UpsertWatch(watch *models.DocumentWatch) error
+	DeleteWatch(docID, userID int) error
+	ListWatches(userID int) ([]models.DocumentWatch, error)
+	ListWatchers(docID, changedBy int) ([]models.DocumentWatcher, error)
+	MarkChanged(docID, userID int) error
+	ListPendingDigests() ([]models.WatchDigestEntry, error)
+	MarkDigested(userID int, docIDs []int, at time.Time) error
+}
+
+// MaintenanceRepositoryInterface - MaintenanceRepositoryのインターフェース
+type MaintenanceRepositoryInterface interface {
+	CountOrphanedBlocks() (int, error)
+	ListOrphanedBlocks(limit int) ([]models.OrphanedBlock, error)
+	DeleteOrphanedBlocks() (int64, error)
+	CountDanglingFileReferences() (int, error)
+	ListDanglingFileReferences(limit int) ([]models.DanglingFileReference, error)
+	DetachDanglingFileReferences() (int64, error)
+	DeleteDanglingFileReferences() (int64, error)
+	ListReferencedObjectKeys() ([]string, error)
+	ListFileObjectReferences() ([]models.FileObjectReference, error)
+}
+
+// StatsRepositoryInterface - StatsRepositoryのインターフェース
+type StatsRepositoryInterface interface {
+	GetUserStats(since time.Time) (models.UserStats, error)
+	GetDocumentStats() (models.DocumentStats, error)
+	GetStorageStats() (models.StorageStats, error)
+	GetUploadsPerDay(since time.Time) ([]models.DailyCount, error)
+	GetActiveUsersPerDay(since time.Time) ([]models.DailyCount, error)
+}
+
+// BlockCommentRepositoryInterface - BlockCommentRepositoryのインターフェース
+type BlockCommentRepositoryInterface interface {
+	CreateComment(comment *models.BlockComment) error
+	ListComments(docID int, filter models.BlockCommentFilter) ([]models.BlockComment, error)
+	GetComment(docID, commentID int) (*models.BlockComment, error)
+	UpdateCommentBody(docID, commentID, userID int, body string) error
+	SetCommentResolved(docID, commentID, userID int, resolved bool) error
+	DeleteComment(docID, commentID, userID int) error
+}
+
+// DocumentCommentRepositoryInterface - DocumentCommentRepositoryのインターフェース
+type DocumentCommentRepositoryInterface interface {
+	CreateComment(comment *models.DocumentComment) error
+	ListComments(docID int) ([]models.DocumentComment, error)
+	GetComment(docID, commentID int) (*models.DocumentComment, error)
+	CountComments(docID int) (int, error)
+	UpdateCommentBody(docID, commentID, userID int, body string) error
+	SetCommentResolved(docID, commentID, userID int, resolved bool) error
+	DeleteComment(docID, commentID, userID int) error
+}
+
+// TaskRepositoryInterface - TaskRepositoryのインターフェース
+type TaskRepositoryInterface interface {
+	ListOpenTasks(userID int) ([]models.Task, error)
+}
+
+// WebhookRepositoryInterface - WebhookRepositoryのインターフェース
+type WebhookRepositoryInterface interface {
+	CreateWebhook(webhook *models.Webhook) error
+	CountWebhooks(userID int) (int, error)
+	ListWebhooks(userID int) ([]models.Webhook, error)
+	GetWebhook(id, userID int) (*models.Webhook, error)
+	UpdateWebhook(webhook *models.Webhook) error
+	UpdateSecret(id, userID int, secret string) error
+	DeleteWebhook(id, userID int) error
+	EnqueueDeliveries(userID int, eventID, eventType string, payload []byte) (int, error)
+	ListDeliveries(webhookID, userID int, page pagination.Params) (pagination.Page[models.WebhookDelivery], error)
+	Redeliver(deliveryID, webhookID, userID int) (*models.WebhookDelivery, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDispatch, error)
+	RecordAttempt(deliveryID int, attempt models.WebhookAttempt) error
+	PruneDeliveries(before time.Time) (int, error)
+}
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+const (
+	// linkPreviewTimeout - 1回のプレビュー取得（リダイレクトを含む）にかける最大時間
+	linkPreviewTimeout = 5 * time.Second
+	// linkPreviewMaxBody - 読み込む HTML の最大サイズ（OpenGraph は head 内にあるため先頭だけで足りる）
+	linkPreviewMaxBody = 1 << 20
+	// linkPreviewMaxRedirects - 追跡するリダイレクトの最大回数
+	linkPreviewMaxRedirects = 5
+	// linkPreviewMaxCache - キャッシュするプレビューの最大件数（超えた場合は期限切れのものを削除する）
+	linkPreviewMaxCache = 1000
+	// linkPreviewMaxTitle, linkPreviewMaxDescription - 保存するタイトル・説明の最大文字数
+	linkPreviewMaxTitle       = 300
+	linkPreviewMaxDescription = 1000
+)
+
+// errBlockedAddress - 接続先が内部ネットワークのアドレスだった場合のエラー
+var errBlockedAddress = errors.New("link preview target resolves to a non-public address")
+
+// LinkPreviewService - ブックマークブロックのリンク先から OpenGraph のメタデータを取得する
+//
+// ユーザーが指定した URL にサーバーから接続するため、SSRF を防ぐ目的で次の制限を設ける:
+//   - http / https のみ（ユーザー情報付きの URL は拒否）
+//   - 接続時に解決後の IP アドレスを検査し、ループバック・プライベート・リンクローカル等には接続しない
+//     （名前解決の後で検査するため、DNS リバインディングやリダイレクト先も同じく拒否される）
+//   - 環境変数のプロキシは使わない、リダイレクトは最大5回、タイムアウト5秒、読み込むのは先頭1MBまで
+//
+// 取得結果は URL ごとに ttl の間メモリにキャッシュする
+type LinkPreviewService struct {
+	client *http.Client
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]*models.LinkPreview
+}
+
+// NewLinkPreviewService - LinkPreviewServiceを初期化
+func NewLinkPreviewService(ttl time.Duration) *LinkPreviewService {
+	dialer := &net.Dialer{
+		Timeout: linkPreviewTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%s: %w", address, errBlockedAddress)
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // 環境変数のプロキシを経由すると接続先の検査が効かないため使わない
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   linkPreviewTimeout,
+		ResponseHeaderTimeout: linkPreviewTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &LinkPreviewService{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   linkPreviewTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= linkPreviewMaxRedirects {
+					return fmt.Errorf("stopped after %d redirects", linkPreviewMaxRedirects)
+				}
+				_, err := validatePreviewURL(req.URL.String())
+				return err
+			},
+		},
+		ttl:   ttl,
+		cache: make(map[string]*models.LinkPreview),
+	}
+}
+
+// Fetch - URL のプレビューを取得（キャッシュがあればそれを返す）
+// URL が不正・内部ネットワーク宛ての場合は 400、取得に失敗した場合は 502 を返す
+func (s *LinkPreviewService) Fetch(ctx context.Context, rawURL string) (*models.LinkPreview, error) {
+	target, err := validatePreviewURL(rawURL)
+	if err != nil {
+		return nil, apierror.NewValidationError("INVALID_BOOKMARK_URL", "ブックマークのURLが不正です", err)
+	}
+	key := target.String()
+
+	if cached := s.cached(key, time.Now()); cached != nil {
+		return cached, nil
+	}
+
+	preview, err := s.fetch(ctx, target)
+	if err != nil {
+		if errors.Is(err, errBlockedAddress) {
+			return nil, apierror.NewValidationError("INVALID_BOOKMARK_URL", "このURLのプレビューは取得できません", err)
+		}
+		return nil, apierror.NewBadGateway("LINK_PREVIEW_FAILED", "リンク先のページを取得できませんでした", err)
+	}
+
+	s.store(key, preview)
+	return preview, nil
+}
+
+// fetch - リンク先の HTML を取得して OpenGraph のメタデータを取り出す
+func (s *LinkPreviewService) fetch(ctx context.Context, target *url.URL) (*models.LinkPreview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create preview request: %w", err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "SimpleNotionLinkPreview/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("link preview request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("link preview returned status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("link preview returned unsupported content type %q", mediaType)
+	}
+
+	preview := parseLinkPreview(io.LimitReader(resp.Body, linkPreviewMaxBody), resp.Request.URL)
+	preview.URL = target.String()
+	preview.FetchedAt = time.Now()
+	return preview, nil
+}
+
+// cached - 有効期限内のキャッシュを返す（なければ nil）
+func (s *LinkPreviewService) cached(key string, now time.Time) *models.LinkPreview {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	preview, ok := s.cache[key]
+	if !ok || now.Sub(preview.FetchedAt) >= s.ttl {
+		return nil
+	}
+	copied := *preview
+	return &copied
+}
+
+// store - 取得結果をキャッシュに保存（上限を超える場合は期限切れのものを先に削除する）
+func (s *LinkPreviewService) store(key string, preview *models.LinkPreview) {
+	if s.ttl <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cache) >= linkPreviewMaxCache {
+		now := time.Now()
+		for k, p := range s.cache {
+			if now.Sub(p.FetchedAt) >= s.ttl {
+				delete(s.cache, k)
+			}
+		}
+		if len(s.cache) >= linkPreviewMaxCache {
+			return
+		}
+	}
+	copied := *preview
+	s.cache[key] = &copied
+}
+
+// validatePreviewURL - プレビューを取得できる URL か検証する
+// http(s) の絶対 URL で、ユーザー情報を含まないものだけを受け付ける（フラグメントは取り除く）
+func validatePreviewURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("url has no host")
+	}
+	if u.User != nil {
+		return nil, errors.New("url must not contain user info")
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		return nil, fmt.Errorf("unsupported port %s", port)
+	}
+	u.Fragment = ""
+	return u, nil
+}
+
+// isPublicIP - インターネット上のアドレスかどうか
+// ループバック・プライベート・リンクローカル（クラウドのメタデータ API を含む）・マルチキャスト等は false
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, block := range nonPublicNetworks {
+		if block.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// nonPublicNetworks - net.IP のメソッドでは判定できない、外部から到達できないアドレス範囲
+var nonPublicNetworks = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",     // 「このネットワーク」
+		"100.64.0.0/10", // CGNAT
+		"192.0.0.0/24",  // IETF プロトコル割り当て
+		"198.18.0.0/15", // ベンチマーク用
+		"240.0.0.0/4",   // 予約済み（ブロードキャストを含む）
+		"64:ff9b::/96",  // NAT64（IPv4 の内部アドレスに変換されうる）
+		"2001:db8::/32", // ドキュメント用
+	}
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// parseLinkPreview - HTML の head から OpenGraph（なければ title / description / Twitter Card）を取り出す
+// 画像の相対 URL は base を基準に解決し、http(s) 以外の画像は捨てる
+func parseLinkPreview(body io.Reader, base *url.URL) *models.LinkPreview {
+	var og, fallback models.LinkPreview
+	tokenizer := html.NewTokenizer(body)
+	inTitle := false
+
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			return mergeLinkPreview(og, fallback, base)
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.Head:
+				return mergeLinkPreview(og, fallback, base)
+			case atom.Title:
+				inTitle = false
+			}
+		case html.TextToken:
+			if inTitle && fallback.Title == "" {
+				fallback.Title = strings.TrimSpace(string(tokenizer.Text()))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				return mergeLinkPreview(og, fallback, base)
+			case atom.Title:
+				inTitle = tt == html.StartTagToken
+			case atom.Meta:
+				if !hasAttr {
+					continue
+				}
+				var key, content string
+				for {
+					attrName, attrValue, more := tokenizer.TagAttr()
+					switch strings.ToLower(string(attrName)) {
+					case "property", "name":
+						key = strings.ToLower(strings.TrimSpace(string(attrValue)))
+					case "content":
+						content = strings.TrimSpace(string(attrValue))
+					}
+					if !more {
+						break
+					}
+				}
+				applyMetaTag(&og, &fallback, key, content)
+			}
+		}
+	}
+}
+
+// applyMetaTag - meta タグの値をプレビューに反映する（同じ項目は最初に出現したものを使う）
+func applyMetaTag(og, fallback *models.LinkPreview, key, content string) {
+	if content == "" {
+		return
+	}
+	setOnce := func(field *string) {
+		if *field == "" {
+			*field = content
+		}
+	}
+	switch key {
+	case "og:title":
+		setOnce(&og.Title)
+	case "og:description":
+		setOnce(&og.Description)
+	case "og:image", "og:image:url", "og:image:secure_url":
+		setOnce(&og.Image)
+	case "og:site_name":
+		setOnce(&og.SiteName)
+	case "twitter:title":
+		setOnce(&fallback.Title)
+	case "description", "twitter:description":
+		setOnce(&fallback.Description)
+	case "twitter:image", "twitter:image:src":
+		setOnce(&fallback.Image)
+	}
+}
+
+// mergeLinkPreview - OpenGraph の値を優先し、足りない項目を代替の値で補う
+func mergeLinkPreview(og, fallback models.LinkPreview, base *url.URL) *models.LinkPreview {
+	pick := func(primary, secondary string) string {
+		if primary != "" {
+			return primary
+		}
+		return secondary
+	}
+
+	return &models.LinkPreview{
+		Title:       truncateRunes(pick(og.Title, fallback.Title), linkPreviewMaxTitle),
+		Description: truncateRunes(pick(og.Description, fallback.Description), linkPreviewMaxDescription),
+		Image:       resolvePreviewImage(pick(og.Image, fallback.Image), base),
+		SiteName:    truncateRunes(og.SiteName, linkPreviewMaxTitle),
+	}
+}
+
+// resolvePreviewImage - 画像の URL を絶対 URL にする（http(s) 以外は空を返す）
+func resolvePreviewImage(image string, base *url.URL) string {
+	if image == "" {
+		return ""
+	}
+	ref, err := url.Parse(image)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	if (ref.Scheme != "http" && ref.Scheme != "https") || ref.Host == "" {
+		return ""
+	}
+	return ref.String()
+}
+
+// truncateRunes - 文字数（rune）で切り詰める
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginThrottle - メールアドレスごとにログイン失敗を数え、一定回数を超えたら一時的にロックする
+// アカウントの有無に関わらず同じように数えるため、ロック状態からアカウントの存在は分からない
+// 状態はプロセス内のメモリにのみ保持する（再起動でリセットされる）
+type LoginThrottle struct {
+	maxFailures int
+	window      time.Duration
+	lockout     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*loginAttempts
+}
+
+// loginAttempts - 1つのメールアドレスに対するログイン失敗の記録
+type loginAttempts struct {
+	failures    int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// NewLoginThrottle - LoginThrottleを初期化
+// window の間に maxFailures 回失敗すると lockout の間ロックする
+func NewLoginThrottle(maxFailures int, window, lockout time.Duration) *LoginThrottle {
+	return &LoginThrottle{
+		maxFailures: maxFailures,
+		window:      window,
+		lockout:     lockout,
+		entries:     make(map[string]*loginAttempts),
+	}
+}
+
+// Status - ロック中かどうかと、ロックが解除されるまでの残り時間を返す
+func (t *LoginThrottle) Status(email string, now time.Time) (locked bool, remaining time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.entries[throttleKey(email)]
+	if !ok || !now.Before(entry.lockedUntil) {
+		return false, 0
+	}
+	return true, entry.lockedUntil.Sub(now)
+}
+
+// RecordFailure - ログイン失敗を記録し、上限に達した場合はロックする
+// ロックされた（またはロック中の）場合は true と残り時間を返す
+func (t *LoginThrottle) RecordFailure(email string, now time.Time) (locked bool, remaining time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := throttleKey(email)
+	entry, ok := t.entries[key]
+	if !ok || now.Sub(entry.windowStart) >= t.window {
+		entry = &loginAttempts{windowStart: now}
+		t.entries[key] = entry
+	}
+	if now.Before(entry.lockedUntil) {
+		return true, entry.lockedUntil.Sub(now)
+	}
+
+	entry.failures++
+	if entry.failures >= t.maxFailures {
+		entry.lockedUntil = now.Add(t.lockout)
+		entry.failures = 0
+		entry.windowStart = now
+		return true, t.lockout
+	}
+	return false, 0
+}
+
+// RecordSuccess - ログイン成功時に失敗の記録を消す
+func (t *LoginThrottle) RecordSuccess(email string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.entries, throttleKey(email))
+}
+
+// Prune - 失敗の集計期間もロックも終わった記録を削除し、削除した件数を返す
+func (t *LoginThrottle) Prune(now time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pruned := 0
+	for key, entry := range t.entries {
+		if now.Sub(entry.windowStart) >= t.window && !now.Before(entry.lockedUntil) {
+			delete(t.entries, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// throttleKey - 大文字小文字・前後の空白の違いで制限を回避できないよう正規化する
+func throttleKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLoginThrottle - ログイン試行の制限のテスト
+func TestLoginThrottle(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	t.Run("正常系：上限回数の失敗でロックされ、期間後に解除される", func(t *testing.T) {
+		throttle := NewLoginThrottle(3, 15*time.Minute, 10*time.Minute)
+
+		for i := 0; i < 2; i++ {
+			if locked, _ := throttle.RecordFailure("user@example.com", now); locked {
+				t.Fatalf("RecordFailure() #%d locked = true, want false", i+1)
+			}
+		}
+		locked, remaining := throttle.RecordFailure("User@Example.com ", now)
+		if !locked || remaining != 10*time.Minute {
+			t.Fatalf("RecordFailure() = (%v, %v), want (true, 10m)", locked, remaining)
+		}
+
+		locked, remaining = throttle.Status("user@example.com", now.Add(4*time.Minute))
+		if !locked || remaining != 6*time.Minute {
+			t.Errorf("Status() = (%v, %v), want (true, 6m)", locked, remaining)
+		}
+
+		if locked, _ := throttle.Status("user@example.com", now.Add(10*time.Minute)); locked {
+			t.Error("Status() locked = true after lockout, want false")
+		}
+	})
+
+	t.Run("正常系：集計期間を過ぎた失敗は数えない", func(t *testing.T) {
+		throttle := NewLoginThrottle(2, 15*time.Minute, 10*time.Minute)
+
+		throttle.RecordFailure("user@example.com", now)
+		if locked, _ := throttle.RecordFailure("user@example.com", now.Add(20*time.Minute)); locked {
+			t.Error("RecordFailure() locked = true, want false")
+		}
+	})
+
+	t.Run("正常系：成功すると失敗の記録が消える", func(t *testing.T) {
+		throttle := NewLoginThrottle(2, 15*time.Minute, 10*time.Minute)
+
+		throttle.RecordFailure("user@example.com", now)
+		throttle.RecordSuccess("user@example.com")
+		if locked, _ := throttle.RecordFailure("user@example.com", now); locked {
+			t.Error("RecordFailure() locked = true, want false")
+		}
+	})
+
+	t.Run("正常系：期限切れの記録を削除する", func(t *testing.T) {
+		throttle := NewLoginThrottle(1, 15*time.Minute, 10*time.Minute)
+
+		throttle.RecordFailure("locked@example.com", now)
+		throttle.RecordFailure("old@example.com", now.Add(-20*time.Minute))
+
+		// ロック中・集計期間中の記録は残す
+		if pruned := throttle.Prune(now.Add(time.Minute)); pruned != 1 {
+			t.Errorf("Prune() = %d, want 1", pruned)
+		}
+		if pruned := throttle.Prune(now.Add(15 * time.Minute)); pruned != 1 {
+			t.Errorf("Prune() = %d, want 1", pruned)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/storage"
+)
+
+// orphanReportLimit - 検出結果に含める孤立データの最大件数（件数は全体を数える）
+const orphanReportLimit = 100
+
+// storageScanGracePeriod - ストレージの突き合わせで対象外にする、直近に更新されたオブジェクト・ファイルの期間
+// アップロード中（オブジェクトの保存とメタデータの記録の間）のデータを不整合として扱わないため
+const storageScanGracePeriod = time.Hour
+
+// MaintenanceService - 孤立データ（文書のないブロック、存在しないブロックを指すファイル）の検出と修復を担当するサービス
+type MaintenanceService struct {
+	maintenanceRepo MaintenanceRepositoryInterface
+	objectStorage   storage.ObjectStorage
+
+	mu            sync.Mutex
+	storageReport *models.StorageConsistencyReport // 直近のストレージの突き合わせ結果
+}
+
+// NewMaintenanceService - MaintenanceServiceを初期化
+func NewMaintenanceService(maintenanceRepo MaintenanceRepositoryInterface, objectStorage storage.ObjectStorage) *MaintenanceService {
+	return &MaintenanceService{
+		maintenanceRepo: maintenanceRepo,
+		objectStorage:   objectStorage,
+	}
+}
+
+// ScanOrphans - 孤立データを検出（データは変更しない）
+func (s *MaintenanceService) ScanOrphans() (*models.OrphanReport, error) {
+	report := &models.OrphanReport{ScannedAt: time.Now()}
+	var err error
+
+	if report.OrphanedBlockCount, err = s.maintenanceRepo.CountOrphanedBlocks(); err != nil {
+		return nil, fmt.Errorf("failed to count orphaned blocks: %w", err)
+	}
+	if report.OrphanedBlocks, err = s.maintenanceRepo.ListOrphanedBlocks(orphanReportLimit); err != nil {
+		return nil, fmt.Errorf("failed to list orphaned blocks: %w", err)
+	}
+	if report.DanglingFileCount, err = s.maintenanceRepo.CountDanglingFileReferences(); err != nil {
+		return nil, fmt.Errorf("failed to count dangling file references: %w", err)
+	}
+	if report.DanglingFiles, err = s.maintenanceRepo.ListDanglingFileReferences(orphanReportLimit); err != nil {
+		return nil, fmt.Errorf("failed to list dangling file references: %w", err)
+	}
+
+	return report, nil
+}
+
+// RepairOrphans - 指定された方法で孤立データを修復
+// ブロックの削除を先に行うため、削除したブロックを参照していたファイルも同じ呼び出しで修復対象になる
+func (s *MaintenanceService) RepairOrphans(opts models.OrphanRepairOptions) (*models.OrphanRepairResult, error) {
+	switch opts.FileAction {
+	case models.DanglingFileNone, models.DanglingFileDetach, models.DanglingFileDelete:
+	default:
+		return nil, apierror.NewValidationError("INVALID_FILE_ACTION",
+			"fileAction には detach / delete のいずれかを指定してください", nil)
+	}
+	if !opts.DeleteBlocks && opts.FileAction == models.DanglingFileNone {
+		return nil, apierror.NewValidationError("NO_REPAIR_ACTION",
+			"deleteBlocks または fileAction を指定してください", nil)
+	}
+
+	result := &models.OrphanRepairResult{}
+	var err error
+
+	if opts.DeleteBlocks {
+		if result.DeletedBlocks, err = s.maintenanceRepo.DeleteOrphanedBlocks(); err != nil {
+			return nil, fmt.Errorf("failed to delete orphaned blocks: %w", err)
+		}
+	}
+
+	switch opts.FileAction {
+	case models.DanglingFileDetach:
+		result.RepairedFiles, err = s.maintenanceRepo.DetachDanglingFileReferences()
+	case models.DanglingFileDelete:
+		result.RepairedFiles, err = s.maintenanceRepo.DeleteDanglingFileReferences()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to repair dangling file references: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetStorageReport - 直近のストレージの突き合わせ結果を取得（まだ実行していない場合は実行する）
+func (s *MaintenanceService) GetStorageReport(ctx context.Context) (*models.StorageConsistencyReport, error) {
+	s.mu.Lock()
+	report := s.storageReport
+	s.mu.Unlock()
+
+	if report != nil {
+		return report, nil
+	}
+	return s.ScanStorage(ctx)
+}
+
+// ScanStorage - ストレージ上のオブジェクトとデータベースを突き合わせ、不整合を検出（データは変更しない）
+// メタデータから参照されていないオブジェクトと、オブジェクトが存在しないファイルを報告する
+// CleanupOrphanedFiles はデータベースの記録だけを見るため、記録のないオブジェクトはこの検出でしか見つからない
+func (s *MaintenanceService) ScanStorage(ctx context.Context) (*models.StorageConsistencyReport, error) {
+	report := &models.StorageConsistencyReport{
+		UnreferencedObjects: make([]models.UnreferencedObject, 0),
+		MissingObjects:      make([]models.FileObjectReference, 0),
+		ScannedAt:           time.Now(),
+	}
+	cutoff := report.ScannedAt.Add(-storageScanGracePeriod)
+
+	// 1. ストレージ上のオブジェクトを先に取得する（後から記録されたメタデータは参照として扱える）
+	objects, err := s.objectStorage.ListObjects(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage objects: %w", err)
+	}
+	report.ObjectCount = len(objects)
+
+	// 2. メタデータから参照されていないオブジェクト
+	referenced, err := s.maintenanceRepo.ListReferencedObjectKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referenced object keys: %w", err)
+	}
+	referencedKeys := make(map[string]bool, len(referenced))
+	for _, key := range referenced {
+		referencedKeys[key] = true
+	}
+
+	storedKeys := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		storedKeys[obj.Key] = true
+		if referencedKeys[obj.Key] || obj.LastModified.After(cutoff) {
+			continue
+		}
+		report.UnreferencedObjectCount++
+		report.UnreferencedObjectSize += obj.Size
+		if len(report.UnreferencedObjects) < orphanReportLimit {
+			report.UnreferencedObjects = append(report.UnreferencedObjects, models.UnreferencedObject{
+				Key:          obj.Key,
+				Size:         obj.Size,
+				LastModified: obj.LastModified,
+			})
+		}
+	}
+
+	// 3. オブジェクトが存在しないファイル
+	files, err := s.maintenanceRepo.ListFileObjectReferences()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list file object references: %w", err)
+	}
+	for _, f := range files {
+		if storedKeys[f.FileKey] || f.UploadedAt.After(cutoff) {
+			continue
+		}
+		report.MissingObjectCount++
+		if len(report.MissingObjects) < orphanReportLimit {
+			report.MissingObjects = append(report.MissingObjects, f)
+		}
+	}
+
+	s.mu.Lock()
+	s.storageReport = report
+	s.mu.Unlock()
+
+	return report, nil
+}
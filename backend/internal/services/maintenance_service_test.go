@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/storage"
+)
+
+// MockMaintenanceRepository - MaintenanceRepositoryのモック
+type MockMaintenanceRepository struct {
+	OrphanedBlocks []models.OrphanedBlock
+	DanglingFiles  []models.DanglingFileReference
+	ReferencedKeys []string
+	FileObjects    []models.FileObjectReference
+	Calls          []string
+}
+
+func (m *MockMaintenanceRepository) CountOrphanedBlocks() (int, error) {
+	return len(m.OrphanedBlocks), nil
+}
+
+func (m *MockMaintenanceRepository) ListOrphanedBlocks(limit int) ([]models.OrphanedBlock, error) {
+	if len(m.OrphanedBlocks) > limit {
+		return m.OrphanedBlocks[:limit], nil
+	}
+	return m.OrphanedBlocks, nil
+}
+
+func (m *MockMaintenanceRepository) DeleteOrphanedBlocks() (int64, error) {
+	m.Calls = append(m.Calls, "DeleteOrphanedBlocks")
+	return int64(len(m.OrphanedBlocks)), nil
+}
+
+func (m *MockMaintenanceRepository) CountDanglingFileReferences() (int, error) {
+	return len(m.DanglingFiles), nil
+}
+
+func (m *MockMaintenanceRepository) ListDanglingFileReferences(limit int) ([]models.DanglingFileReference, error) {
+	if len(m.DanglingFiles) > limit {
+		return m.DanglingFiles[:limit], nil
+	}
+	return m.DanglingFiles, nil
+}
+
+func (m *MockMaintenanceRepository) DetachDanglingFileReferences() (int64, error) {
+	m.Calls = append(m.Calls, "DetachDanglingFileReferences")
+	return int64(len(m.DanglingFiles)), nil
+}
+
+func (m *MockMaintenanceRepository) DeleteDanglingFileReferences() (int64, error) {
+	m.Calls = append(m.Calls, "DeleteDanglingFileReferences")
+	return int64(len(m.DanglingFiles)), nil
+}
+
+func (m *MockMaintenanceRepository) ListReferencedObjectKeys() ([]string, error) {
+	return m.ReferencedKeys, nil
+}
+
+func (m *MockMaintenanceRepository) ListFileObjectReferences() ([]models.FileObjectReference, error) {
+	return m.FileObjects, nil
+}
+
+// TestScanOrphans - 孤立データ検出のテスト
+func TestScanOrphans(t *testing.T) {
+	repo := &MockMaintenanceRepository{
+		OrphanedBlocks: make([]models.OrphanedBlock, orphanReportLimit+5),
+		DanglingFiles:  []models.DanglingFileReference{{FileID: 1, BlockID: 9}},
+	}
+	service := NewMaintenanceService(repo, nil)
+
+	report, err := service.ScanOrphans()
+	if err != nil {
+		t.Fatalf("ScanOrphans() error = %v", err)
+	}
+	if report.OrphanedBlockCount != orphanReportLimit+5 || len(report.OrphanedBlocks) != orphanReportLimit {
+		t.Errorf("orphaned blocks = %d (listed %d), want %d (listed %d)",
+			report.OrphanedBlockCount, len(report.OrphanedBlocks), orphanReportLimit+5, orphanReportLimit)
+	}
+	if report.DanglingFileCount != 1 {
+		t.Errorf("DanglingFileCount = %d, want 1", report.DanglingFileCount)
+	}
+	if len(repo.Calls) != 0 {
+		t.Errorf("ScanOrphans() should not modify data, called %v", repo.Calls)
+	}
+}
+
+// TestRepairOrphans - 孤立データ修復のテスト
+func TestRepairOrphans(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       models.OrphanRepairOptions
+		wantCalls  []string
+		wantStatus int
+	}{
+		{
+			name:      "ブロック削除後にファイル参照を外す",
+			opts:      models.OrphanRepairOptions{DeleteBlocks: true, FileAction: models.DanglingFileDetach},
+			wantCalls: []string{"DeleteOrphanedBlocks", "DetachDanglingFileReferences"},
+		},
+		{
+			name:      "ファイルのみ削除扱い",
+			opts:      models.OrphanRepairOptions{FileAction: models.DanglingFileDelete},
+			wantCalls: []string{"DeleteDanglingFileReferences"},
+		},
+		{
+			name:       "修復内容の指定なし → 400",
+			opts:       models.OrphanRepairOptions{},
+			wantStatus: 400,
+		},
+		{
+			name:       "不明な fileAction → 400",
+			opts:       models.OrphanRepairOptions{FileAction: "purge"},
+			wantStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockMaintenanceRepository{}
+			service := NewMaintenanceService(repo, nil)
+
+			_, err := service.RepairOrphans(tt.opts)
+
+			if tt.wantStatus != 0 {
+				var appErr *apierror.AppError
+				if !errors.As(err, &appErr) || appErr.HTTPStatus != tt.wantStatus {
+					t.Errorf("RepairOrphans() error = %v, want status %d", err, tt.wantStatus)
+				}
+				if len(repo.Calls) != 0 {
+					t.Errorf("RepairOrphans() should not modify data on invalid options, called %v", repo.Calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RepairOrphans() error = %v", err)
+			}
+			if len(repo.Calls) != len(tt.wantCalls) {
+				t.Fatalf("calls = %v, want %v", repo.Calls, tt.wantCalls)
+			}
+			for i := range tt.wantCalls {
+				if repo.Calls[i] != tt.wantCalls[i] {
+					t.Errorf("calls = %v, want %v", repo.Calls, tt.wantCalls)
+				}
+			}
+		})
+	}
+}
+
+// TestScanStorage - ストレージとデータベースの突き合わせのテスト
+func TestScanStorage(t *testing.T) {
+	rootDir := t.TempDir()
+	objectStorage, err := storage.NewLocalStorage(rootDir, "", []byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+
+	ctx := context.Background()
+	old := time.Now().Add(-2 * storageScanGracePeriod)
+	for _, key := range []string{"images/1/a.png", "thumbnails/thumb/images/1/a.png", "images/1/stray.png", "images/1/uploading.png"} {
+		if err := objectStorage.UploadFile(ctx, key, strings.NewReader("data"), 4, "image/png"); err != nil {
+			t.Fatalf("UploadFile(%q) error = %v", key, err)
+		}
+		if key == "images/1/uploading.png" {
+			continue
+		}
+		path := filepath.Join(rootDir, filepath.FromSlash(key))
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("Chtimes(%q) error = %v", key, err)
+		}
+	}
+
+	repo := &MockMaintenanceRepository{
+		ReferencedKeys: []string{"images/1/a.png", "thumbnails/thumb/images/1/a.png", "images/1/gone.png", "images/1/new.png"},
+		FileObjects: []models.FileObjectReference{
+			{FileID: 1, FileKey: "images/1/a.png", Status: "active", UploadedAt: old},
+			{FileID: 2, FileKey: "images/1/gone.png", Status: "active", UploadedAt: old},
+			{FileID: 3, FileKey: "images/1/new.png", Status: "active", UploadedAt: time.Now()},
+		},
+	}
+	service := NewMaintenanceService(repo, objectStorage)
+
+	report, err := service.ScanStorage(ctx)
+	if err != nil {
+		t.Fatalf("ScanStorage() error = %v", err)
+	}
+	if report.ObjectCount != 4 {
+		t.Errorf("ObjectCount = %d, want 4", report.ObjectCount)
+	}
+	// 直近にアップロードされたオブジェクトは参照がなくても報告しない
+	if report.UnreferencedObjectCount != 1 || report.UnreferencedObjects[0].Key != "images/1/stray.png" {
+		t.Errorf("unreferenced objects = %+v, want only images/1/stray.png", report.UnreferencedObjects)
+	}
+	if report.UnreferencedObjectSize != 4 {
+		t.Errorf("UnreferencedObjectSize = %d, want 4", report.UnreferencedObjectSize)
+	}
+	// 直近にアップロードされたファイルはオブジェクトがなくても報告しない
+	if report.MissingObjectCount != 1 || report.MissingObjects[0].FileID != 2 {
+		t.Errorf("missing objects = %+v, want only file 2", report.MissingObjects)
+	}
+	if len(repo.Calls) != 0 {
+		t.Errorf("ScanStorage() should not modify data, called %v", repo.Calls)
+	}
+
+	cached, err := service.GetStorageReport(ctx)
+	if err != nil {
+		t.Fatalf("GetStorageReport() error = %v", err)
+	}
+	if cached != report {
+		t.Error("GetStorageReport() should return the latest scan result")
+	}
+}
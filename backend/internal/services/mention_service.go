@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+)
+
+// mentionPattern - 本文中の "@メールアドレス" 形式のメンション
+// メールアドレスの一部（user@example.com の @example.com）を拾わないよう、直前が英数字・記号でないものに限る
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.+\-@])@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// MentionText - メンションを探すテキストと、そのテキストを含むブロック（本文の場合は nil）
+type MentionText struct {
+	BlockID *int
+	Text    string
+}
+
+// MentionService - 文書・コメント内の @メンション の記録と、メンションされたユーザーへの通知を担当するサービス
+type MentionService struct {
+	mentionRepo   MentionRepositoryInterface
+	documentRepo  DocumentCoreRepositoryInterface
+	userRepo      UserRepositoryInterface
+	workspaceRepo WorkspaceRepositoryInterface
+	notifier      *NotificationService
+}
+
+// NewMentionService - MentionServiceを初期化
+func NewMentionService(
+	mentionRepo MentionRepositoryInterface,
+	documentRepo DocumentCoreRepositoryInterface,
+	userRepo UserRepositoryInterface,
+	workspaceRepo WorkspaceRepositoryInterface,
+	notifier *NotificationService,
+) *MentionService {
+	return &MentionService{
+		mentionRepo:   mentionRepo,
+		documentRepo:  documentRepo,
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+		notifier:      notifier,
+	}
+}
+
+// SyncDocument - 文書の本文・ブロックのメンションを記録し、新しくメンションされたユーザーに通知
+func (s *MentionService) SyncDocument(ctx context.Context, doc *models.Document, authorID int, texts []MentionText) error {
+	mentions := s.resolveMentions(ctx, doc, authorID, texts)
+	return s.sync(ctx, doc, authorID, models.MentionSourceDocument, doc.ID, mentions, func(m models.Mention) string {
+		if m.BlockID != nil {
+			return fmt.Sprintf("/documents/%d?block=%d", doc.ID, *m.BlockID)
+		}
+		return fmt.Sprintf("/documents/%d", doc.ID)
+	})
+}
+
+// SyncComment - コメント本文のメンションを記録し、新しくメンションされたユーザーに通知
+// コメントを削除した場合は body を空にして呼び出すと、そのコメントのメンションが削除される
+func (s *MentionService) SyncComment(ctx context.Context, docID, authorID int, sourceType models.MentionSourceType, commentID int, blockID *int, body string) error {
+	doc, err := s.documentRepo.GetDocument(docID, authorID)
+	if err != nil {
+		return err
+	}
+
+	mentions := s.resolveMentions(ctx, doc, authorID, []MentionText{{BlockID: blockID, Text: body}})
+	return s.sync(ctx, doc, authorID, sourceType, commentID, mentions, func(m models.Mention) string {
+		if blockID != nil {
+			return fmt.Sprintf("/documents/%d?block=%d&comment=%d", docID, *blockID, commentID)
+		}
+		return fmt.Sprintf("/documents/%d?comment=%d", docID, commentID)
+	})
+}
+
+// sync - メンションを保存し、新しく追加されたメンションのユーザーにリンク付きで通知
+// 1件の通知の失敗で他のユーザーへの通知を止めないよう、個別のエラーはログに残して続行する
+func (s *MentionService) sync(ctx context.Context, doc *models.Document, authorID int, sourceType models.MentionSourceType, sourceID int, mentions []models.Mention, link func(models.Mention) string) error {
+	added, err := s.mentionRepo.SyncMentions(doc.ID, sourceType, sourceID, authorID, mentions)
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("%sさんが「%s」であなたをメンションしました", s.authorName(authorID), doc.Title)
+	for _, m := range added {
+		if err := s.notifier.NotifyWithLink(m.UserID, &doc.ID, models.NotificationMention, message, link(m)); err != nil {
+			logctx.Printf(ctx, "Failed to notify mention to user %d on document %d: %v", m.UserID, doc.ID, err)
+		}
+	}
+	return nil
+}
+
+// resolveMentions - テキストからメンションされたユーザーを取り出す
+// 存在しないユーザー・書いた本人・文書にアクセスできないユーザーは除き、同じユーザーは最初のブロックの1件にまとめる
+func (s *MentionService) resolveMentions(ctx context.Context, doc *models.Document, authorID int, texts []MentionText) []models.Mention {
+	mentions := make([]models.Mention, 0)
+	seenEmails := make(map[string]bool)
+	seenUsers := make(map[int]bool)
+	for _, text := range texts {
+		for _, email := range extractMentionEmails(text.Text) {
+			if seenEmails[strings.ToLower(email)] {
+				continue
+			}
+			seenEmails[strings.ToLower(email)] = true
+
+			user, err := s.userRepo.GetByEmail(email)
+			if err != nil {
+				if !errors.Is(err, apierror.ErrNotFound) {
+					logctx.Printf(ctx, "Failed to resolve mention %s: %v", email, err)
+				}
+				continue
+			}
+			if seenUsers[user.ID] || user.ID == authorID || !s.canAccess(ctx, doc, user.ID) {
+				continue
+			}
+			seenUsers[user.ID] = true
+			mentions = append(mentions, models.Mention{BlockID: text.BlockID, UserID: user.ID})
+		}
+	}
+	return mentions
+}
+
+// canAccess - ユーザーが文書を閲覧できるか（所有者、または文書が属するワークスペースのメンバー）
+func (s *MentionService) canAccess(ctx context.Context, doc *models.Document, userID int) bool {
+	if doc.UserID == userID {
+		return true
+	}
+	if doc.WorkspaceID == nil {
+		return false
+	}
+	role, err := s.workspaceRepo.GetMemberRole(*doc.WorkspaceID, userID)
+	if err != nil {
+		if !errors.Is(err, apierror.ErrNotFound) {
+			logctx.Printf(ctx, "Failed to check workspace member %d for mention: %v", userID, err)
+		}
+		return false
+	}
+	return role != ""
+}
+
+// authorName - 通知に表示するメンションしたユーザーの名前（未設定の場合はメールアドレス）
+func (s *MentionService) authorName(authorID int) string {
+	user, err := s.userRepo.GetByID(authorID)
+	if err != nil {
+		return "ユーザー"
+	}
+	if user.Name != "" {
+		return user.Name
+	}
+	return user.Email
+}
+
+// extractMentionEmails - テキスト中のメンションのメールアドレスを出現順に取り出す
+func extractMentionEmails(text string) []string {
+	matches := mentionPattern.FindAllStringSubmatch(text, -1)
+	emails := make([]string, 0, len(matches))
+	for _, match := range matches {
+		emails = append(emails, strings.TrimRight(match[1], "."))
+	}
+	return emails
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// MockMentionRepository - MentionRepositoryのモック
+type MockMentionRepository struct {
+	SyncMentionsFunc func(docID int, sourceType models.MentionSourceType, sourceID, mentionedBy int, mentions []models.Mention) ([]models.Mention, error)
+}
+
+func (m *MockMentionRepository) SyncMentions(docID int, sourceType models.MentionSourceType, sourceID, mentionedBy int, mentions []models.Mention) ([]models.Mention, error) {
+	if m.SyncMentionsFunc != nil {
+		return m.SyncMentionsFunc(docID, sourceType, sourceID, mentionedBy, mentions)
+	}
+	return nil, errors.New("not implemented")
+}
+
+// mentionUsers - テスト用のユーザー（1: 作成者, 2: メンバー, 3: ワークスペース外）
+func mentionUsers() *MockUserRepository {
+	users := map[string]*models.User{
+		"owner@example.com":    {ID: 1, Email: "owner@example.com", Name: "Owner"},
+		"member@example.com":   {ID: 2, Email: "member@example.com"},
+		"outsider@example.com": {ID: 3, Email: "outsider@example.com"},
+	}
+	return &MockUserRepository{
+		GetByEmailFunc: func(email string) (*models.User, error) {
+			if user, ok := users[email]; ok {
+				return user, nil
+			}
+			return nil, apierror.ErrNotFound
+		},
+		GetByIDFunc: func(id int) (*models.User, error) {
+			for _, user := range users {
+				if user.ID == id {
+					return user, nil
+				}
+			}
+			return nil, apierror.ErrNotFound
+		},
+	}
+}
+
+func TestExtractMentionEmails(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "正常系：文頭と文中のメンション", text: "@a@example.com と @b@example.com さん、確認お願いします。", want: []string{"a@example.com", "b@example.com"}},
+		{name: "正常系：文末の句点は含めない", text: "担当は @a@example.com.", want: []string{"a@example.com"}},
+		{name: "正常系：メールアドレスだけではメンションにならない", text: "連絡先は a@example.com です", want: []string{}},
+		{name: "正常系：@ の直前が英数字の場合はメンションにならない", text: "x@a@example.com", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractMentionEmails(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractMentionEmails() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMentionService_SyncDocument(t *testing.T) {
+	workspaceID := 5
+	doc := &models.Document{ID: 2, UserID: 1, Title: "議事録", WorkspaceID: &workspaceID}
+	blockID := 7
+
+	var saved []models.Mention
+	var notified []models.Notification
+	mentionRepo := &MockMentionRepository{
+		SyncMentionsFunc: func(docID int, sourceType models.MentionSourceType, sourceID, mentionedBy int, mentions []models.Mention) ([]models.Mention, error) {
+			if sourceType != models.MentionSourceDocument || sourceID != docID {
+				t.Errorf("source = %s/%d, want document/%d", sourceType, sourceID, docID)
+			}
+			saved = mentions
+			return mentions, nil
+		},
+	}
+	workspaceRepo := &MockWorkspaceRepository{
+		GetMemberRoleFunc: workspaceRoles(map[int]models.WorkspaceRole{
+			1: models.WorkspaceRoleOwner,
+			2: models.WorkspaceRoleMember,
+		}),
+	}
+	notifier := NewNotificationService(&MockNotificationRepository{
+		CreateNotificationFunc: func(n *models.Notification) error {
+			notified = append(notified, *n)
+			return nil
+		},
+	})
+	service := NewMentionService(mentionRepo, ownedDocumentRepo(), mentionUsers(), workspaceRepo, notifier)
+
+	texts := []MentionText{
+		{Text: "@owner@example.com @unknown@example.com"},
+		{BlockID: &blockID, Text: "@member@example.com と @outsider@example.com"},
+		{Text: "再掲 @member@example.com"},
+	}
+	if err := service.SyncDocument(context.Background(), doc, 1, texts); err != nil {
+		t.Fatalf("SyncDocument() error = %v", err)
+	}
+
+	// 作成者本人・存在しないユーザー・ワークスペース外のユーザーは除き、重複は最初のブロックにまとめる
+	if len(saved) != 1 || saved[0].UserID != 2 || saved[0].BlockID == nil || *saved[0].BlockID != blockID {
+		t.Fatalf("saved = %+v, want only user 2 on block %d", saved, blockID)
+	}
+	if len(notified) != 1 {
+		t.Fatalf("notified = %d, want 1", len(notified))
+	}
+	if n := notified[0]; n.UserID != 2 || n.Type != models.NotificationMention || n.Link != "/documents/2?block=7" {
+		t.Errorf("notification = %+v, want mention to user 2 with block link", n)
+	}
+}
+
+func TestMentionService_SyncComment(t *testing.T) {
+	t.Run("正常系：新しく追加されたメンションだけを通知する", func(t *testing.T) {
+		var notified []models.Notification
+		mentionRepo := &MockMentionRepository{
+			SyncMentionsFunc: func(docID int, sourceType models.MentionSourceType, sourceID, mentionedBy int, mentions []models.Mention) ([]models.Mention, error) {
+				return nil, nil
+			},
+		}
+		notifier := NewNotificationService(&MockNotificationRepository{
+			CreateNotificationFunc: func(n *models.Notification) error {
+				notified = append(notified, *n)
+				return nil
+			},
+		})
+		service := NewMentionService(mentionRepo, ownedDocumentRepo(), mentionUsers(), &MockWorkspaceRepository{}, notifier)
+
+		if err := service.SyncComment(context.Background(), 2, 1, models.MentionSourceDocumentComment, 9, nil, "@member@example.com"); err != nil {
+			t.Fatalf("SyncComment() error = %v", err)
+		}
+		if len(notified) != 0 {
+			t.Errorf("notified = %+v, want none", notified)
+		}
+	})
+
+	t.Run("正常系：コメントへのリンク付きで通知する", func(t *testing.T) {
+		var notified []models.Notification
+		mentionRepo := &MockMentionRepository{
+			SyncMentionsFunc: func(docID int, sourceType models.MentionSourceType, sourceID, mentionedBy int, mentions []models.Mention) ([]models.Mention, error) {
+				return mentions, nil
+			},
+		}
+		// 個人の文書の所有者（ユーザー2）へのメンション
+		documentRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return &models.Document{ID: docID, UserID: 2, Title: "メモ"}, nil
+			},
+		}
+		notifier := NewNotificationService(&MockNotificationRepository{
+			CreateNotificationFunc: func(n *models.Notification) error {
+				notified = append(notified, *n)
+				return nil
+			},
+		})
+		service := NewMentionService(mentionRepo, documentRepo, mentionUsers(), &MockWorkspaceRepository{}, notifier)
+
+		blockID := 4
+		if err := service.SyncComment(context.Background(), 2, 1, models.MentionSourceBlockComment, 9, &blockID, "@member@example.com @outsider@example.com"); err != nil {
+			t.Fatalf("SyncComment() error = %v", err)
+		}
+		if len(notified) != 1 || notified[0].UserID != 2 || notified[0].Link != "/documents/2?block=4&comment=9" {
+			t.Errorf("notified = %+v, want user 2 with comment link", notified)
+		}
+		if want := "Ownerさんが「メモ」であなたをメンションしました"; len(notified) == 1 && notified[0].Message != want {
+			t.Errorf("message = %q, want %q", notified[0].Message, want)
+		}
+	})
+}
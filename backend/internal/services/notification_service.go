@@ -0,0 +1,45 @@
+package services
+
+import (
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
+
+// NotificationService - アプリ内通知の作成と取得を担当するサービス
+type NotificationService struct {
+	notificationRepo NotificationRepositoryInterface
+}
+
+// NewNotificationService - NotificationServiceを初期化
+func NewNotificationService(notificationRepo NotificationRepositoryInterface) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+	}
+}
+
+// Notify - ユーザーに通知を送信
+func (s *NotificationService) Notify(userID int, docID *int, notificationType models.NotificationType, message string) error {
+	return s.NotifyWithLink(userID, docID, notificationType, message, "")
+}
+
+// NotifyWithLink - 文書・ブロックなどへのリンク付きでユーザーに通知を送信
+func (s *NotificationService) NotifyWithLink(userID int, docID *int, notificationType models.NotificationType, message, link string) error {
+	return s.notificationRepo.CreateNotification(&models.Notification{
+		UserID:     userID,
+		DocumentID: docID,
+		Type:       notificationType,
+		Message:    message,
+		Link:       link,
+	})
+}
+
+// ListNotifications - ユーザーの通知一覧を新しい順に1ページ分取得
+// 件数の指定がない場合は既定の件数、上限を超える場合は上限の件数を返す
+func (s *NotificationService) ListNotifications(userID int, unreadOnly bool, page pagination.Params) (pagination.Page[models.Notification], error) {
+	return s.notificationRepo.ListNotifications(userID, unreadOnly, page.Clamp(pagination.DefaultLimit, pagination.MaxLimit))
+}
+
+// MarkRead - 通知を既読にする
+func (s *NotificationService) MarkRead(id, userID int) error {
+	return s.notificationRepo.MarkRead(id, userID)
+}
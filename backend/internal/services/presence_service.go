@@ -0,0 +1,375 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// presenceCursorInterval - 1つのタブのカーソルの移動を通知する最短の間隔（間に受けた移動は最後の位置だけを送る）
+const presenceCursorInterval = 100 * time.Millisecond
+
+// PresenceService - 文書を開いているユーザー（タブ）を記録し、変化を DocumentEventBroker で通知する
+// タブは文書を開いたときに Join、閉じたときに Leave を呼ぶ。変更の通知の接続（realtime connection）が
+// 続いている間は Touch で記録を延長し、接続が切れたタブの記録は LeaveClient で削除する
+// timeout の間 Join・Touch のないタブは期限切れとして扱う（ExpireStale で削除して通知する）
+// 開いているタブのカーソル・選択範囲は UpdateCursor で同じ文書を開いているユーザーに中継する（保存しない）
+// 状態はプロセス内のメモリにのみ保持する
+type PresenceService struct {
+	userRepo UserRepositoryInterface
+	events   *DocumentEventBroker // nil の場合は変化を通知しない
+	timeout  time.Duration
+
+	mu        sync.Mutex
+	documents map[int]*documentPresence
+	now       func() time.Time
+
+	cursorInterval time.Duration
+	afterFunc      func(d time.Duration, f func()) // 間隔を空けて送るカーソルの移動の予約
+}
+
+// documentPresence - 1つの文書を開いているタブ
+type documentPresence struct {
+	ownerID int
+	clients map[presenceClient]*presenceEntry
+}
+
+// presenceClient - ユーザーとタブの組み合わせ
+type presenceClient struct {
+	userID   int
+	clientID string
+}
+
+// presenceEntry - タブの表示名と最後に確認した日時、カーソルの位置
+type presenceEntry struct {
+	name     string
+	lastSeen time.Time
+
+	cursor        *models.DocumentCursor // 最後に受けたカーソルの位置（未送信の場合を含む）
+	cursorSentAt  time.Time
+	cursorPending bool // 間隔を空けて送る予約がある
+}
+
+// NewPresenceService - PresenceServiceを初期化
+func NewPresenceService(userRepo UserRepositoryInterface, events *DocumentEventBroker, timeout time.Duration) *PresenceService {
+	return &PresenceService{
+		userRepo:  userRepo,
+		events:    events,
+		timeout:   timeout,
+		documents: make(map[int]*documentPresence),
+		now:       time.Now,
+
+		cursorInterval: presenceCursorInterval,
+		afterFunc:      func(d time.Duration, f func()) { time.AfterFunc(d, f) },
+	}
+}
+
+// Join - タブが文書を開いたことを記録し、文書を開いているユーザーを返す
+// すでに記録済みのタブの場合は期限を延長する。変化は文書の所有者（doc.UserID）と開いているユーザーに通知する
+func (s *PresenceService) Join(doc *models.Document, userID int, clientID string) (*models.DocumentPresence, error) {
+	if clientID == "" {
+		return nil, apierror.NewValidationError("CLIENT_ID_REQUIRED", "X-Client-ID ヘッダーにタブの識別子を指定してください", nil)
+	}
+	client := presenceClient{userID: userID, clientID: clientID}
+
+	// 記録済みのタブは延長するだけ（定期的に呼ばれるため、ユーザー名を取得し直さない）
+	s.mu.Lock()
+	now := s.now()
+	s.expireLocked(now)
+	if presence, ok := s.documents[doc.ID]; ok {
+		if entry, ok := presence.clients[client]; ok {
+			entry.lastSeen = now
+			snapshot := s.snapshotLocked(doc.ID)
+			s.mu.Unlock()
+			return snapshot, nil
+		}
+	}
+	s.mu.Unlock()
+
+	name, err := s.viewerName(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	presence, ok := s.documents[doc.ID]
+	if !ok {
+		presence = &documentPresence{ownerID: doc.UserID, clients: make(map[presenceClient]*presenceEntry)}
+		s.documents[doc.ID] = presence
+	}
+	presence.clients[client] = &presenceEntry{name: name, lastSeen: s.now()}
+	s.notifyLocked(doc.ID)
+
+	return s.snapshotLocked(doc.ID), nil
+}
+
+// Leave - タブが文書を閉じたことを記録する（記録がない場合は何もしない）
+func (s *PresenceService) Leave(docID, userID int, clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.removeLocked(docID, presenceClient{userID: userID, clientID: clientID}) {
+		s.notifyLocked(docID)
+	}
+}
+
+// LeaveClient - タブが開いているすべての文書の記録を削除する（変更の通知の接続が切れた場合）
+func (s *PresenceService) LeaveClient(userID int, clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client := presenceClient{userID: userID, clientID: clientID}
+	for docID := range s.documents {
+		if s.removeLocked(docID, client) {
+			s.notifyLocked(docID)
+		}
+	}
+}
+
+// UpdateCursor - タブのカーソル・選択範囲を同じ文書を開いているユーザーに通知する（anchor が nil の場合はカーソルを外した）
+// focus を省略した場合は anchor と同じ位置（範囲を選択していない）として扱う
+// 文書を開いている（Join した）タブのみ送れる。cursorInterval より短い間隔の移動はまとめ、最後の位置だけを送る
+func (s *PresenceService) UpdateCursor(docID, userID int, clientID string, anchor, focus *models.DocumentCursorPosition) error {
+	if clientID == "" {
+		return apierror.NewValidationError("CLIENT_ID_REQUIRED", "X-Client-ID ヘッダーにタブの識別子を指定してください", nil)
+	}
+	if anchor == nil && focus != nil {
+		return apierror.NewValidationError("INVALID_CURSOR", "focus を指定する場合は anchor も指定してください", nil)
+	}
+	if focus == nil {
+		focus = anchor
+	}
+	for _, position := range []*models.DocumentCursorPosition{anchor, focus} {
+		if position != nil && (position.BlockID <= 0 || position.Offset < 0) {
+			return apierror.NewValidationError("INVALID_CURSOR", "カーソルの位置が不正です", nil)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client := presenceClient{userID: userID, clientID: clientID}
+	presence, ok := s.documents[docID]
+	if !ok || presence.clients[client] == nil {
+		return apierror.NewConflict("PRESENCE_REQUIRED", "文書を開いてからカーソルの位置を送ってください", nil)
+	}
+	entry := presence.clients[client]
+
+	now := s.now()
+	entry.lastSeen = now
+	entry.cursor = &models.DocumentCursor{
+		UserID:   userID,
+		Name:     entry.name,
+		ClientID: clientID,
+		Anchor:   anchor,
+		Focus:    focus,
+	}
+
+	if wait := s.cursorInterval - now.Sub(entry.cursorSentAt); wait > 0 {
+		if !entry.cursorPending {
+			entry.cursorPending = true
+			s.afterFunc(wait, func() { s.flushCursor(docID, client) })
+		}
+		return nil
+	}
+	s.publishCursorLocked(docID, entry.cursor)
+	entry.cursorSentAt = now
+	return nil
+}
+
+// flushCursor - 間隔を空けて送るよう予約したカーソルの最後の位置を通知する（タブが閉じられていた場合は何もしない）
+func (s *PresenceService) flushCursor(docID int, client presenceClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	presence, ok := s.documents[docID]
+	if !ok {
+		return
+	}
+	entry, ok := presence.clients[client]
+	if !ok || !entry.cursorPending {
+		return
+	}
+	entry.cursorPending = false
+	s.publishCursorLocked(docID, entry.cursor)
+	entry.cursorSentAt = s.now()
+}
+
+// publishCursorLocked - 文書を開いているユーザーに cursor.updated を通知する（mu を取得した状態で呼ぶ）
+// 送ったタブ自身にも届くため、タブは clientId で自分のカーソルを無視する
+func (s *PresenceService) publishCursorLocked(docID int, cursor *models.DocumentCursor) {
+	presence, ok := s.documents[docID]
+	if !ok || s.events == nil {
+		return
+	}
+
+	event := models.DocumentEvent{
+		Type:       models.DocumentEventCursor,
+		DocumentID: docID,
+		ClientID:   cursor.ClientID,
+		OccurredAt: s.now(),
+		Cursor:     cursor,
+	}
+	recipients := make(map[int]bool)
+	for client := range presence.clients {
+		recipients[client.userID] = true
+	}
+	for userID := range recipients {
+		s.events.Publish(userID, event)
+	}
+}
+
+// Touch - タブが開いているすべての文書の記録を延長する（変更の通知の接続が続いている場合）
+func (s *PresenceService) Touch(userID int, clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	client := presenceClient{userID: userID, clientID: clientID}
+	for _, presence := range s.documents {
+		if entry, ok := presence.clients[client]; ok {
+			entry.lastSeen = now
+		}
+	}
+}
+
+// Get - 文書を開いているユーザーを返す
+func (s *PresenceService) Get(docID int) *models.DocumentPresence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expireLocked(s.now())
+	return s.snapshotLocked(docID)
+}
+
+// ExpireStale - 期限切れのタブの記録を削除して通知し、削除した件数を返す
+func (s *PresenceService) ExpireStale() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.expireLocked(s.now())
+}
+
+// expireLocked - timeout の間確認していないタブの記録を削除して通知する（mu を取得した状態で呼ぶ）
+func (s *PresenceService) expireLocked(now time.Time) int {
+	expired := 0
+	for docID, presence := range s.documents {
+		changed := false
+		for client, entry := range presence.clients {
+			if now.Sub(entry.lastSeen) >= s.timeout {
+				changed = s.removeLocked(docID, client) || changed
+				expired++
+			}
+		}
+		if changed {
+			s.notifyLocked(docID)
+		}
+	}
+	return expired
+}
+
+// removeLocked - タブの記録を削除し、削除したかを返す（mu を取得した状態で呼ぶ）
+// カーソルを送っていたタブの場合は、残っているタブにカーソルを外したことを通知する
+// 文書を開いているタブがなくなった場合も、所有者に通知するため notifyLocked より前に文書を削除しない
+func (s *PresenceService) removeLocked(docID int, client presenceClient) bool {
+	presence, ok := s.documents[docID]
+	if !ok {
+		return false
+	}
+	entry, ok := presence.clients[client]
+	if !ok {
+		return false
+	}
+	delete(presence.clients, client)
+
+	// カーソルを表示しているユーザーに、タブのカーソルを消すよう通知する
+	if entry.cursor != nil && entry.cursor.Anchor != nil {
+		s.publishCursorLocked(docID, &models.DocumentCursor{
+			UserID:   client.userID,
+			Name:     entry.name,
+			ClientID: client.clientID,
+		})
+	}
+	return true
+}
+
+// notifyLocked - 文書の所有者と開いているユーザーに presence.updated を通知する（mu を取得した状態で呼ぶ）
+// タブがなくなった文書はここで削除する
+func (s *PresenceService) notifyLocked(docID int) {
+	presence, ok := s.documents[docID]
+	if !ok {
+		return
+	}
+	snapshot := s.snapshotLocked(docID)
+	if len(presence.clients) == 0 {
+		delete(s.documents, docID)
+	}
+	if s.events == nil {
+		return
+	}
+
+	event := models.DocumentEvent{
+		Type:       models.DocumentEventPresence,
+		DocumentID: docID,
+		OccurredAt: s.now(),
+		Viewers:    snapshot.Viewers,
+	}
+	recipients := map[int]bool{presence.ownerID: true}
+	for _, viewer := range snapshot.Viewers {
+		recipients[viewer.UserID] = true
+	}
+	for userID := range recipients {
+		s.events.Publish(userID, event)
+	}
+}
+
+// snapshotLocked - 文書を開いているユーザーを、タブをユーザーごとにまとめて返す（mu を取得した状態で呼ぶ）
+func (s *PresenceService) snapshotLocked(docID int) *models.DocumentPresence {
+	result := &models.DocumentPresence{DocumentID: docID, Viewers: []models.DocumentViewer{}}
+	presence, ok := s.documents[docID]
+	if !ok {
+		return result
+	}
+
+	viewers := make(map[int]*models.DocumentViewer)
+	for client, entry := range presence.clients {
+		viewer, ok := viewers[client.userID]
+		if !ok {
+			viewer = &models.DocumentViewer{UserID: client.userID, Name: entry.name}
+			viewers[client.userID] = viewer
+		}
+		viewer.Tabs++
+		if entry.lastSeen.After(viewer.LastSeenAt) {
+			viewer.LastSeenAt = entry.lastSeen
+		}
+	}
+	for _, viewer := range viewers {
+		result.Viewers = append(result.Viewers, *viewer)
+	}
+	sort.Slice(result.Viewers, func(i, j int) bool {
+		return result.Viewers[i].UserID < result.Viewers[j].UserID
+	})
+	return result
+}
+
+// viewerName - 表示するユーザー名（名前がない場合はメールアドレス）
+func (s *PresenceService) viewerName(userID int) (string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return "", apierror.NewUnauthorized("UNAUTHORIZED", "認証が必要です", err)
+		}
+		return "", fmt.Errorf("failed to get user %d: %w", userID, err)
+	}
+	if user.Name != "" {
+		return user.Name, nil
+	}
+	return user.Email, nil
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// MockUserRepository - UserRepositoryのモック
+type MockUserRepository struct {
+	GetByIDFunc    func(id int) (*models.User, error)
+	GetByEmailFunc func(email string) (*models.User, error)
+}
+
+func (m *MockUserRepository) GetByID(id int) (*models.User, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockUserRepository) GetByEmail(email string) (*models.User, error) {
+	if m.GetByEmailFunc != nil {
+		return m.GetByEmailFunc(email)
+	}
+	return nil, errors.New("not implemented")
+}
+
+// newTestPresenceService - 時刻を進められる PresenceService を作成する
+func newTestPresenceService(events *DocumentEventBroker) (*PresenceService, *time.Time) {
+	userRepo := &MockUserRepository{
+		GetByIDFunc: func(id int) (*models.User, error) {
+			if id == 2 {
+				return &models.User{ID: id, Email: "viewer@example.com"}, nil
+			}
+			return &models.User{ID: id, Email: "owner@example.com", Name: "Owner"}, nil
+		},
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	service := NewPresenceService(userRepo, events, time.Minute)
+	service.now = func() time.Time { return now }
+	return service, &now
+}
+
+// TestPresenceService_Join - 文書を開いたタブの記録のテスト
+func TestPresenceService_Join(t *testing.T) {
+	doc := &models.Document{ID: 10, UserID: 1}
+
+	t.Run("正常系：同じユーザーのタブは1人にまとめる", func(t *testing.T) {
+		service, _ := newTestPresenceService(nil)
+
+		if _, err := service.Join(doc, 1, "tab-a"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+		if _, err := service.Join(doc, 1, "tab-b"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+		presence, err := service.Join(doc, 2, "tab-c")
+		if err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+
+		if len(presence.Viewers) != 2 {
+			t.Fatalf("Viewers = %d, want 2", len(presence.Viewers))
+		}
+		if presence.Viewers[0].UserID != 1 || presence.Viewers[0].Tabs != 2 || presence.Viewers[0].Name != "Owner" {
+			t.Errorf("Viewers[0] = %+v, want owner with 2 tabs", presence.Viewers[0])
+		}
+		if presence.Viewers[1].Name != "viewer@example.com" {
+			t.Errorf("Viewers[1].Name = %q, want email fallback", presence.Viewers[1].Name)
+		}
+	})
+
+	t.Run("正常系：所有者と開いているユーザーに通知する", func(t *testing.T) {
+		broker := NewDocumentEventBroker()
+		ownerEvents, unsubscribeOwner := broker.Subscribe(1)
+		defer unsubscribeOwner()
+		viewerEvents, unsubscribeViewer := broker.Subscribe(2)
+		defer unsubscribeViewer()
+		service, _ := newTestPresenceService(broker)
+
+		if _, err := service.Join(doc, 2, "tab-c"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+
+		for name, ch := range map[string]<-chan models.DocumentEvent{"owner": ownerEvents, "viewer": viewerEvents} {
+			select {
+			case event := <-ch:
+				if event.Type != models.DocumentEventPresence || len(event.Viewers) != 1 {
+					t.Errorf("%s event = %+v, want presence with 1 viewer", name, event)
+				}
+			default:
+				t.Errorf("%s received nothing", name)
+			}
+		}
+	})
+
+	t.Run("正常系：記録済みのタブは通知せずに延長する", func(t *testing.T) {
+		broker := NewDocumentEventBroker()
+		events, unsubscribe := broker.Subscribe(1)
+		defer unsubscribe()
+		service, now := newTestPresenceService(broker)
+
+		if _, err := service.Join(doc, 1, "tab-a"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+		<-events
+
+		*now = now.Add(40 * time.Second)
+		if _, err := service.Join(doc, 1, "tab-a"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+		select {
+		case event := <-events:
+			t.Errorf("unexpected event %+v", event)
+		default:
+		}
+
+		*now = now.Add(40 * time.Second)
+		if got := service.ExpireStale(); got != 0 {
+			t.Errorf("ExpireStale() = %d, want 0", got)
+		}
+	})
+
+	t.Run("異常系：タブの識別子がない場合はエラー", func(t *testing.T) {
+		service, _ := newTestPresenceService(nil)
+
+		_, err := service.Join(doc, 1, "")
+		var appErr *apierror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != "CLIENT_ID_REQUIRED" {
+			t.Errorf("Join() error = %v, want CLIENT_ID_REQUIRED", err)
+		}
+	})
+}
+
+// TestPresenceService_Leave - 文書を閉じたタブの記録の削除のテスト
+func TestPresenceService_Leave(t *testing.T) {
+	doc := &models.Document{ID: 10, UserID: 1}
+
+	t.Run("正常系：最後のタブが閉じると空の一覧を通知する", func(t *testing.T) {
+		broker := NewDocumentEventBroker()
+		events, unsubscribe := broker.Subscribe(1)
+		defer unsubscribe()
+		service, _ := newTestPresenceService(broker)
+
+		if _, err := service.Join(doc, 1, "tab-a"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+		<-events
+
+		service.Leave(doc.ID, 1, "tab-a")
+
+		select {
+		case event := <-events:
+			if event.Type != models.DocumentEventPresence || len(event.Viewers) != 0 {
+				t.Errorf("event = %+v, want presence with no viewers", event)
+			}
+		default:
+			t.Error("received nothing")
+		}
+		if len(service.documents) != 0 {
+			t.Errorf("documents = %d, want 0", len(service.documents))
+		}
+	})
+
+	t.Run("正常系：接続が切れたタブはすべての文書から削除する", func(t *testing.T) {
+		service, _ := newTestPresenceService(nil)
+		other := &models.Document{ID: 11, UserID: 1}
+
+		for _, d := range []*models.Document{doc, other} {
+			if _, err := service.Join(d, 1, "tab-a"); err != nil {
+				t.Fatalf("Join() error = %v", err)
+			}
+		}
+		if _, err := service.Join(doc, 1, "tab-b"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+
+		service.LeaveClient(1, "tab-a")
+
+		if got := service.Get(doc.ID); len(got.Viewers) != 1 || got.Viewers[0].Tabs != 1 {
+			t.Errorf("Get(%d) = %+v, want 1 viewer with 1 tab", doc.ID, got)
+		}
+		if got := service.Get(other.ID); len(got.Viewers) != 0 {
+			t.Errorf("Get(%d) = %+v, want no viewers", other.ID, got)
+		}
+	})
+}
+
+// TestPresenceService_ExpireStale - 期限切れのタブの削除のテスト
+func TestPresenceService_ExpireStale(t *testing.T) {
+	doc := &models.Document{ID: 10, UserID: 1}
+
+	t.Run("正常系：Touch のないタブだけ削除する", func(t *testing.T) {
+		service, now := newTestPresenceService(nil)
+
+		if _, err := service.Join(doc, 1, "tab-a"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+		if _, err := service.Join(doc, 2, "tab-c"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+
+		*now = now.Add(30 * time.Second)
+		service.Touch(1, "tab-a")
+		*now = now.Add(40 * time.Second)
+
+		if got := service.ExpireStale(); got != 1 {
+			t.Errorf("ExpireStale() = %d, want 1", got)
+		}
+		presence := service.Get(doc.ID)
+		if len(presence.Viewers) != 1 || presence.Viewers[0].UserID != 1 {
+			t.Errorf("Viewers = %+v, want only user 1", presence.Viewers)
+		}
+	})
+}
+
+// TestPresenceService_UpdateCursor - カーソルの移動の通知のテスト
+func TestPresenceService_UpdateCursor(t *testing.T) {
+	doc := &models.Document{ID: 10, UserID: 1}
+	position := func(blockID, offset int) *models.DocumentCursorPosition {
+		return &models.DocumentCursorPosition{BlockID: blockID, Offset: offset}
+	}
+
+	// newJoined - ユーザー1・2が文書を開いた状態を作り、ユーザー2のイベントと予約したカーソルの送信を返す
+	newJoined := func(t *testing.T) (*PresenceService, *time.Time, <-chan models.DocumentEvent, *[]func()) {
+		broker := NewDocumentEventBroker()
+		events, unsubscribe := broker.Subscribe(2)
+		t.Cleanup(unsubscribe)
+		service, now := newTestPresenceService(broker)
+		var scheduled []func()
+		service.afterFunc = func(d time.Duration, f func()) { scheduled = append(scheduled, f) }
+
+		if _, err := service.Join(doc, 1, "tab-a"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+		if _, err := service.Join(doc, 2, "tab-c"); err != nil {
+			t.Fatalf("Join() error = %v", err)
+		}
+		for len(events) > 0 {
+			<-events
+		}
+		return service, now, events, &scheduled
+	}
+
+	t.Run("正常系：開いているユーザーにカーソルを通知する", func(t *testing.T) {
+		service, _, events, _ := newJoined(t)
+
+		if err := service.UpdateCursor(10, 1, "tab-a", position(5, 3), position(5, 8)); err != nil {
+			t.Fatalf("UpdateCursor() error = %v", err)
+		}
+
+		select {
+		case event := <-events:
+			if event.Type != models.DocumentEventCursor || event.Cursor == nil {
+				t.Fatalf("event = %+v, want cursor.updated", event)
+			}
+			cursor := event.Cursor
+			if cursor.UserID != 1 || cursor.ClientID != "tab-a" || cursor.Name != "Owner" ||
+				cursor.Anchor.Offset != 3 || cursor.Focus.Offset != 8 {
+				t.Errorf("Cursor = %+v, want owner's selection 3-8", cursor)
+			}
+		default:
+			t.Fatal("viewer received nothing")
+		}
+	})
+
+	t.Run("正常系：短い間隔の移動はまとめて最後の位置を送る", func(t *testing.T) {
+		service, now, events, scheduled := newJoined(t)
+
+		if err := service.UpdateCursor(10, 1, "tab-a", position(5, 1), nil); err != nil {
+			t.Fatalf("UpdateCursor() error = %v", err)
+		}
+		<-events
+
+		*now = now.Add(30 * time.Millisecond)
+		for offset := 2; offset <= 4; offset++ {
+			if err := service.UpdateCursor(10, 1, "tab-a", position(5, offset), nil); err != nil {
+				t.Fatalf("UpdateCursor() error = %v", err)
+			}
+		}
+		if len(events) != 0 {
+			t.Fatalf("events = %d, want throttled", len(events))
+		}
+		if len(*scheduled) != 1 {
+			t.Fatalf("scheduled = %d, want 1", len(*scheduled))
+		}
+
+		(*scheduled)[0]()
+		event := <-events
+		if event.Cursor.Anchor.Offset != 4 || event.Cursor.Focus.Offset != 4 {
+			t.Errorf("Cursor = %+v, want latest offset 4", event.Cursor)
+		}
+	})
+
+	t.Run("正常系：タブを閉じるとカーソルを消すよう通知する", func(t *testing.T) {
+		service, _, events, _ := newJoined(t)
+
+		if err := service.UpdateCursor(10, 1, "tab-a", position(5, 1), nil); err != nil {
+			t.Fatalf("UpdateCursor() error = %v", err)
+		}
+		<-events
+
+		service.Leave(10, 1, "tab-a")
+		event := <-events
+		if event.Type != models.DocumentEventCursor || event.Cursor.ClientID != "tab-a" || event.Cursor.Anchor != nil {
+			t.Errorf("event = %+v, want cleared cursor", event)
+		}
+	})
+
+	t.Run("異常系：文書を開いていないタブは送れない", func(t *testing.T) {
+		service, _, _, _ := newJoined(t)
+
+		err := service.UpdateCursor(10, 1, "tab-x", position(5, 1), nil)
+		var appErr *apierror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != "PRESENCE_REQUIRED" {
+			t.Errorf("UpdateCursor() error = %v, want PRESENCE_REQUIRED", err)
+		}
+	})
+
+	t.Run("異常系：不正な位置はエラー", func(t *testing.T) {
+		service, _, _, _ := newJoined(t)
+
+		for name, args := range map[string][2]*models.DocumentCursorPosition{
+			"focus だけ指定": {nil, position(5, 1)},
+			"負のオフセット":    {position(5, -1), nil},
+			"ブロックIDがない":  {position(0, 1), nil},
+		} {
+			err := service.UpdateCursor(10, 1, "tab-a", args[0], args[1])
+			var appErr *apierror.AppError
+			if !errors.As(err, &appErr) || appErr.Code != "INVALID_CURSOR" {
+				t.Errorf("%s: UpdateCursor() error = %v, want INVALID_CURSOR", name, err)
+			}
+		}
+	})
+}
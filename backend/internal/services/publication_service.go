@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// PublicationService - 文書の公開設定と予約公開/非公開を担当するサービス
+type PublicationService struct {
+	documentRepo    DocumentCoreRepositoryInterface
+	blockRepo       BlockRepositoryInterface
+	publicationRepo PublicationRepositoryInterface
+}
+
+// NewPublicationService - PublicationServiceを初期化
+func NewPublicationService(
+	documentRepo DocumentCoreRepositoryInterface,
+	blockRepo BlockRepositoryInterface,
+	publicationRepo PublicationRepositoryInterface,
+) *PublicationService {
+	return &PublicationService{
+		documentRepo:    documentRepo,
+		blockRepo:       blockRepo,
+		publicationRepo: publicationRepo,
+	}
+}
+
+// GetPublication - 文書の公開設定を取得
+func (s *PublicationService) GetPublication(docID, userID int) (*models.DocumentPublication, error) {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	return s.publicationRepo.GetPublication(docID)
+}
+
+// SetPublication - 文書の公開状態と予約日時を更新
+// 公開予約日時・非公開予約日時が両方指定された場合は、非公開予約日時の方が後でなければならない
+func (s *PublicationService) SetPublication(docID, userID int, isPublic bool, publishAt, unpublishAt *time.Time) (*models.DocumentPublication, error) {
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return nil, apierror.NewValidationError("INVALID_SCHEDULE", "非公開日時は公開日時より後に設定してください", nil)
+	}
+
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+
+	pub := &models.DocumentPublication{
+		DocumentID:  docID,
+		IsPublic:    isPublic,
+		PublishAt:   publishAt,
+		UnpublishAt: unpublishAt,
+	}
+	if err := s.publicationRepo.SavePublication(pub); err != nil {
+		return nil, err
+	}
+
+	return pub, nil
+}
+
+// GetPublicDocument - 公開中の文書をブロック付きで取得（認証不要）
+func (s *PublicationService) GetPublicDocument(docID int) (*models.DocumentWithBlocks, error) {
+	doc, err := s.publicationRepo.GetPublicDocument(docID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.blockRepo.GetBlocksByDocumentID(docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocks: %w", err)
+	}
+
+	return &models.DocumentWithBlocks{
+		Document: *doc,
+		Blocks:   blocks,
+	}, nil
+}
+
+// ApplySchedules - 予約日時に到達した文書の公開状態を切り替える（スケジューラーから定期実行）
+func (s *PublicationService) ApplySchedules() (published, unpublished int64, err error) {
+	return s.publicationRepo.ApplySchedules()
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// MockPublicationRepository - PublicationRepositoryのモック
+type MockPublicationRepository struct {
+	GetPublicationFunc    func(docID int) (*models.DocumentPublication, error)
+	SavePublicationFunc   func(pub *models.DocumentPublication) error
+	ApplySchedulesFunc    func() (int64, int64, error)
+	GetPublicDocumentFunc func(docID int) (*models.Document, error)
+}
+
+func (m *MockPublicationRepository) GetPublication(docID int) (*models.DocumentPublication, error) {
+	if m.GetPublicationFunc != nil {
+		return m.GetPublicationFunc(docID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockPublicationRepository) SavePublication(pub *models.DocumentPublication) error {
+	if m.SavePublicationFunc != nil {
+		return m.SavePublicationFunc(pub)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockPublicationRepository) ApplySchedules() (int64, int64, error) {
+	if m.ApplySchedulesFunc != nil {
+		return m.ApplySchedulesFunc()
+	}
+	return 0, 0, errors.New("not implemented")
+}
+
+func (m *MockPublicationRepository) GetPublicDocument(docID int) (*models.Document, error) {
+	if m.GetPublicDocumentFunc != nil {
+		return m.GetPublicDocumentFunc(docID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+// TestSetPublication - 公開設定更新のテスト
+func TestSetPublication(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		publishAt   *time.Time
+		unpublishAt *time.Time
+		docErr      error
+		wantErr     error
+		wantCode    string
+		wantSaved   bool
+	}{
+		{
+			name:        "正常系：公開と非公開の予約を設定",
+			publishAt:   &now,
+			unpublishAt: &later,
+			wantSaved:   true,
+		},
+		{
+			name:      "正常系：予約なしで公開",
+			wantSaved: true,
+		},
+		{
+			name:        "異常系：非公開日時が公開日時より前",
+			publishAt:   &later,
+			unpublishAt: &now,
+			wantCode:    "INVALID_SCHEDULE",
+		},
+		{
+			name:    "異常系：他ユーザーの文書（404）",
+			docErr:  apierror.ErrNotFound,
+			wantErr: apierror.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := &MockDocumentCoreRepository{
+				GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+					if tt.docErr != nil {
+						return nil, tt.docErr
+					}
+					return &models.Document{ID: docID, UserID: userID}, nil
+				},
+			}
+			saved := false
+			pubRepo := &MockPublicationRepository{
+				SavePublicationFunc: func(pub *models.DocumentPublication) error {
+					saved = true
+					return nil
+				},
+			}
+
+			service := NewPublicationService(docRepo, &MockBlockRepository{}, pubRepo)
+			_, err := service.SetPublication(1, 10, true, tt.publishAt, tt.unpublishAt)
+
+			var appErr *apierror.AppError
+			switch {
+			case tt.wantCode != "":
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("SetPublication() error = %v, want code %s", err, tt.wantCode)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("SetPublication() error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("SetPublication() unexpected error: %v", err)
+			}
+			if saved != tt.wantSaved {
+				t.Errorf("SavePublication called = %v, want %v", saved, tt.wantSaved)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+)
+
+// maxReminderNoteLength - リマインダーのメモの最大文字数
+const maxReminderNoteLength = 500
+
+// ReminderService - 文書リマインダーの登録と配信を担当するサービス
+type ReminderService struct {
+	documentRepo DocumentCoreRepositoryInterface
+	reminderRepo ReminderRepositoryInterface
+	notifier     *NotificationService
+}
+
+// NewReminderService - ReminderServiceを初期化
+func NewReminderService(
+	documentRepo DocumentCoreRepositoryInterface,
+	reminderRepo ReminderRepositoryInterface,
+	notifier *NotificationService,
+) *ReminderService {
+	return &ReminderService{
+		documentRepo: documentRepo,
+		reminderRepo: reminderRepo,
+		notifier:     notifier,
+	}
+}
+
+// CreateReminder - 文書にリマインダーを設定
+func (s *ReminderService) CreateReminder(docID, userID int, remindAt time.Time, note string) (*models.Reminder, error) {
+	if !remindAt.After(time.Now()) {
+		return nil, apierror.NewValidationError("INVALID_REMIND_AT", "リマインダーには未来の日時を指定してください", nil)
+	}
+	note = strings.TrimSpace(note)
+	if len([]rune(note)) > maxReminderNoteLength {
+		return nil, apierror.NewValidationError("NOTE_TOO_LONG",
+			fmt.Sprintf("メモは%d文字以内で入力してください", maxReminderNoteLength), nil)
+	}
+
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder := &models.Reminder{
+		DocumentID:    docID,
+		UserID:        userID,
+		DocumentTitle: doc.Title,
+		RemindAt:      remindAt,
+		Note:          note,
+	}
+	if err := s.reminderRepo.CreateReminder(reminder); err != nil {
+		return nil, err
+	}
+
+	return reminder, nil
+}
+
+// ListReminders - ユーザーの未解除リマインダー一覧を取得
+func (s *ReminderService) ListReminders(userID int) ([]models.Reminder, error) {
+	return s.reminderRepo.ListReminders(userID)
+}
+
+// DismissReminder - リマインダーを解除（未送信なら以後送信しない）
+func (s *ReminderService) DismissReminder(id, userID int) error {
+	return s.reminderRepo.DismissReminder(id, userID)
+}
+
+// DeliverDueReminders - 通知日時に到達したリマインダーを配信（スケジューラーから定期実行）
+// 1件の失敗で他のリマインダーの配信を止めないよう、個別のエラーはログに残して続行する
+func (s *ReminderService) DeliverDueReminders(ctx context.Context) (int, error) {
+	due, err := s.reminderRepo.ListDueReminders()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due reminders: %w", err)
+	}
+
+	delivered := 0
+	for _, rem := range due {
+		docID := rem.DocumentID
+		message := fmt.Sprintf("リマインダー:「%s」", rem.DocumentTitle)
+		if rem.Note != "" {
+			message += " - " + rem.Note
+		}
+		if err := s.notifier.Notify(rem.UserID, &docID, models.NotificationReminder, message); err != nil {
+			logctx.Printf(ctx, "failed to deliver reminder %d: %v", rem.ID, err)
+			continue
+		}
+		if err := s.reminderRepo.MarkDelivered(rem.ID); err != nil {
+			logctx.Printf(ctx, "failed to mark reminder %d as delivered: %v", rem.ID, err)
+			continue
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// MockReminderRepository - ReminderRepositoryのモック
+type MockReminderRepository struct {
+	CreateReminderFunc   func(reminder *models.Reminder) error
+	ListRemindersFunc    func(userID int) ([]models.Reminder, error)
+	DismissReminderFunc  func(id, userID int) error
+	ListDueRemindersFunc func() ([]models.Reminder, error)
+	MarkDeliveredFunc    func(id int) error
+}
+
+func (m *MockReminderRepository) CreateReminder(reminder *models.Reminder) error {
+	if m.CreateReminderFunc != nil {
+		return m.CreateReminderFunc(reminder)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockReminderRepository) ListReminders(userID int) ([]models.Reminder, error) {
+	if m.ListRemindersFunc != nil {
+		return m.ListRemindersFunc(userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockReminderRepository) DismissReminder(id, userID int) error {
+	if m.DismissReminderFunc != nil {
+		return m.DismissReminderFunc(id, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockReminderRepository) ListDueReminders() ([]models.Reminder, error) {
+	if m.ListDueRemindersFunc != nil {
+		return m.ListDueRemindersFunc()
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockReminderRepository) MarkDelivered(id int) error {
+	if m.MarkDeliveredFunc != nil {
+		return m.MarkDeliveredFunc(id)
+	}
+	return errors.New("not implemented")
+}
+
+// TestCreateReminder - リマインダー作成のテスト
+func TestCreateReminder(t *testing.T) {
+	tests := []struct {
+		name     string
+		remindAt time.Time
+		note     string
+		docErr   error
+		wantCode string
+		wantErr  error
+	}{
+		{
+			name:     "正常系：未来の日時で作成",
+			remindAt: time.Now().Add(time.Hour),
+			note:     "  レビューする  ",
+		},
+		{
+			name:     "異常系：過去の日時",
+			remindAt: time.Now().Add(-time.Minute),
+			wantCode: "INVALID_REMIND_AT",
+		},
+		{
+			name:     "異常系：メモが長すぎる",
+			remindAt: time.Now().Add(time.Hour),
+			note:     strings.Repeat("あ", maxReminderNoteLength+1),
+			wantCode: "NOTE_TOO_LONG",
+		},
+		{
+			name:     "異常系：他ユーザーの文書（404）",
+			remindAt: time.Now().Add(time.Hour),
+			docErr:   apierror.ErrNotFound,
+			wantErr:  apierror.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := &MockDocumentCoreRepository{
+				GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+					if tt.docErr != nil {
+						return nil, tt.docErr
+					}
+					return &models.Document{ID: docID, UserID: userID, Title: "仕様書"}, nil
+				},
+			}
+			reminderRepo := &MockReminderRepository{
+				CreateReminderFunc: func(reminder *models.Reminder) error {
+					reminder.ID = 1
+					return nil
+				},
+			}
+
+			service := NewReminderService(docRepo, reminderRepo, NewNotificationService(&MockNotificationRepository{}))
+			reminder, err := service.CreateReminder(1, 10, tt.remindAt, tt.note)
+
+			var appErr *apierror.AppError
+			switch {
+			case tt.wantCode != "":
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("CreateReminder() error = %v, want code %s", err, tt.wantCode)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("CreateReminder() error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("CreateReminder() unexpected error: %v", err)
+			default:
+				if reminder.Note != "レビューする" || reminder.DocumentTitle != "仕様書" {
+					t.Errorf("CreateReminder() = %+v", reminder)
+				}
+			}
+		})
+	}
+}
+
+// TestDeliverDueReminders - リマインダー配信のテスト
+func TestDeliverDueReminders(t *testing.T) {
+	var delivered []int
+	reminderRepo := &MockReminderRepository{
+		ListDueRemindersFunc: func() ([]models.Reminder, error) {
+			return []models.Reminder{
+				{ID: 1, DocumentID: 5, UserID: 10, DocumentTitle: "a"},
+				{ID: 2, DocumentID: 6, UserID: 11, DocumentTitle: "b", Note: "締切"},
+			}, nil
+		},
+		MarkDeliveredFunc: func(id int) error {
+			delivered = append(delivered, id)
+			return nil
+		},
+	}
+	notifRepo := &MockNotificationRepository{
+		CreateNotificationFunc: func(n *models.Notification) error {
+			// ユーザー11 への通知は失敗させる（送信済みにしない）
+			if n.UserID == 11 {
+				return errors.New("db error")
+			}
+			if n.Type != models.NotificationReminder {
+				t.Errorf("notification type = %s, want %s", n.Type, models.NotificationReminder)
+			}
+			return nil
+		},
+	}
+
+	service := NewReminderService(&MockDocumentCoreRepository{}, reminderRepo, NewNotificationService(notifRepo))
+	count, err := service.DeliverDueReminders(context.Background())
+	if err != nil {
+		t.Fatalf("DeliverDueReminders() unexpected error: %v", err)
+	}
+	if count != 1 || len(delivered) != 1 || delivered[0] != 1 {
+		t.Errorf("DeliverDueReminders() = %d, delivered = %v, want 1, [1]", count, delivered)
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"simple-notion-backend/internal/models"
+)
+
+// SecurityEventService - セキュリティイベントの記録を担当するサービス
+type SecurityEventService struct {
+	eventRepo SecurityEventRepositoryInterface
+}
+
+// NewSecurityEventService - SecurityEventServiceを初期化
+func NewSecurityEventService(eventRepo SecurityEventRepositoryInterface) *SecurityEventService {
+	return &SecurityEventService{
+		eventRepo: eventRepo,
+	}
+}
+
+// SecretFinding - 検出された秘密情報の種類と箇所（値そのものは持たない）
+type SecretFinding struct {
+	Rule     string `json:"rule"`
+	Location string `json:"location"`
+}
+
+// RecordSecretsDetected - 文書保存時に秘密情報を検出したことを記録
+func (s *SecurityEventService) RecordSecretsDetected(userID, docID int, findings []SecretFinding) error {
+	details, err := json.Marshal(map[string]interface{}{
+		"findings": findings,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal security event details: %w", err)
+	}
+
+	return s.eventRepo.CreateEvent(&models.SecurityEvent{
+		UserID:     &userID,
+		DocumentID: &docID,
+		EventType:  models.SecurityEventSecretDetected,
+		Details:    details,
+	})
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/password"
+)
+
+// 共有リンクのパスワードの文字数
+const (
+	minShareLinkPasswordLength = 4
+	maxShareLinkPasswordLength = 128
+)
+
+// shareTokenBytes - 共有リンクのトークンの長さ（バイト、URL には base64url で埋め込む）
+const shareTokenBytes = 32
+
+// ShareLinkService - アカウントなしで文書を閲覧できる共有リンクの作成・無効化と、リンクからの閲覧を担当するサービス
+// リンクの作成・一覧・無効化は文書の所有者のみができる
+// 通常のリンクは最新の文書を、スナップショットのリンクは作成時点で固定した内容を公開する
+type ShareLinkService struct {
+	documentRepo  DocumentCoreRepositoryInterface
+	blockRepo     BlockRepositoryInterface
+	shareLinkRepo ShareLinkRepositoryInterface
+	hasher        *password.Hasher
+	throttle      *LoginThrottle              // nil の場合はパスワードの試行回数を制限しない
+	files         SnapshotFileCopierInterface // nil の場合はスナップショットで元のファイルを参照したままにする
+	storageQuota  int64                       // スナップショット用にファイルを複製する際のクォータ
+}
+
+// NewShareLinkService - ShareLinkServiceを初期化
+func NewShareLinkService(
+	documentRepo DocumentCoreRepositoryInterface,
+	blockRepo BlockRepositoryInterface,
+	shareLinkRepo ShareLinkRepositoryInterface,
+	hasher *password.Hasher,
+	throttle *LoginThrottle,
+	files SnapshotFileCopierInterface,
+	storageQuota int64,
+) *ShareLinkService {
+	return &ShareLinkService{
+		documentRepo:  documentRepo,
+		blockRepo:     blockRepo,
+		shareLinkRepo: shareLinkRepo,
+		hasher:        hasher,
+		throttle:      throttle,
+		files:         files,
+		storageQuota:  storageQuota,
+	}
+}
+
+// CreateShareLink - 文書の共有リンクを作成（expiresAt・linkPassword は任意）
+// 作成したリンクのトークンは返り値にのみ含まれ、以後は取得できない
+func (s *ShareLinkService) CreateShareLink(docID, userID int, expiresAt *time.Time, linkPassword string) (*models.ShareLink, error) {
+	if err := validateShareLinkOptions(expiresAt, linkPassword); err != nil {
+		return nil, err
+	}
+	if _, err := s.ownedDocument(docID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.createLink(docID, userID, nil, expiresAt, linkPassword)
+}
+
+// CreateSnapshotLink - 現在の文書の内容を固定したスナップショットを作成し、その共有リンクを作成（expiresAt・linkPassword は任意）
+// 画像・ファイルブロックのファイルはスナップショット用に複製する（他のユーザーがアップロードしたファイルは元のファイルを参照する）
+// 作成したリンクのトークンは返り値にのみ含まれ、以後は取得できない
+func (s *ShareLinkService) CreateSnapshotLink(ctx context.Context, docID, userID int, expiresAt *time.Time, linkPassword string) (*models.ShareLink, error) {
+	if err := validateShareLinkOptions(expiresAt, linkPassword); err != nil {
+		return nil, err
+	}
+	doc, err := s.ownedDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.blockRepo.GetBlocksByDocumentID(docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocks: %w", err)
+	}
+	fileIDs, err := s.copySnapshotFiles(ctx, doc, userID, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.DocumentSnapshot{
+		DocumentID: docID,
+		Title:      doc.Title,
+		Content:    doc.Content,
+		Blocks:     blocks,
+		FileIDs:    fileIDs,
+		CreatedBy:  &userID,
+	}
+	if err := s.shareLinkRepo.CreateSnapshot(snapshot); err != nil {
+		s.discardSnapshotFiles(ctx, userID, fileIDs)
+		return nil, err
+	}
+
+	return s.createLink(docID, userID, &snapshot.ID, expiresAt, linkPassword)
+}
+
+// createLink - トークンを作成して共有リンクを保存する
+func (s *ShareLinkService) createLink(docID, userID int, snapshotID *int, expiresAt *time.Time, linkPassword string) (*models.ShareLink, error) {
+	token, err := generateShareToken()
+	if err != nil {
+		return nil, err
+	}
+	link := &models.ShareLink{
+		DocumentID: docID,
+		SnapshotID: snapshotID,
+		ExpiresAt:  expiresAt,
+		CreatedBy:  &userID,
+	}
+	if linkPassword != "" {
+		link.PasswordHash, err = s.hasher.Hash(linkPassword)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash share link password: %w", err)
+		}
+	}
+
+	if err := s.shareLinkRepo.CreateShareLink(link, hashShareToken(token)); err != nil {
+		return nil, err
+	}
+	link.Token = token
+	return link, nil
+}
+
+// copySnapshotFiles - スナップショットのブロックが参照するファイルを複製し、ブロックの参照を複製したファイルに書き換える
+// 複製できない場合（ストレージクォータの超過など）は、それまでに複製したファイルを削除してエラーを返す
+func (s *ShareLinkService) copySnapshotFiles(ctx context.Context, doc *models.Document, userID int, blocks []models.Block) ([]int, error) {
+	fileIDs := make([]int, 0)
+	if s.files == nil {
+		return fileIDs, nil
+	}
+
+	for i := range blocks {
+		fileID, ok := models.MediaFileID(blocks[i].Type, blocks[i].Content)
+		if !ok {
+			continue
+		}
+
+		copied, err := s.files.CopyFile(ctx, fileID, userID, doc.WorkspaceID, s.storageQuota)
+		if err != nil {
+			if errors.Is(err, apierror.ErrForbidden) || errors.Is(err, apierror.ErrNotFound) {
+				continue
+			}
+			s.discardSnapshotFiles(ctx, userID, fileIDs)
+			return nil, err
+		}
+		fileIDs = append(fileIDs, copied.ID)
+
+		content, err := models.WithFileReference(blocks[i].Type, blocks[i].Content, copied, fileSrc(copied.FileKey))
+		if err != nil {
+			s.discardSnapshotFiles(ctx, userID, fileIDs)
+			return nil, apierror.NewInternal(err)
+		}
+		blocks[i].Content = content
+	}
+	return fileIDs, nil
+}
+
+// discardSnapshotFiles - 保存できなかったスナップショット用に複製したファイルを削除する
+func (s *ShareLinkService) discardSnapshotFiles(ctx context.Context, userID int, fileIDs []int) {
+	for _, fileID := range fileIDs {
+		if err := s.files.DeleteFile(ctx, fileID, userID); err != nil {
+			logctx.Printf(ctx, "Failed to delete snapshot file %d: %v", fileID, err)
+		}
+	}
+}
+
+// ListShareLinks - 文書の共有リンク一覧を取得（無効化・期限切れのリンクを含む）
+func (s *ShareLinkService) ListShareLinks(docID, userID int) ([]models.ShareLink, error) {
+	if _, err := s.ownedDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	return s.shareLinkRepo.ListShareLinks(docID)
+}
+
+// RevokeShareLink - 共有リンクを無効化（以後そのリンクからは閲覧できない）
+func (s *ShareLinkService) RevokeShareLink(docID, userID, linkID int) error {
+	if _, err := s.ownedDocument(docID, userID); err != nil {
+		return err
+	}
+	return s.shareLinkRepo.RevokeShareLink(docID, linkID)
+}
+
+// GetSharedDocument - 共有リンクのトークンから文書をブロック付きで取得（認証不要）
+// スナップショットのリンクは、作成時点で固定した内容を返す
+// 存在しない・無効化したリンクは区別せず SHARE_LINK_NOT_FOUND を返す
+// パスワード付きのリンクは、失敗が続くと LoginThrottle の設定に従って一時的にロックする
+func (s *ShareLinkService) GetSharedDocument(ctx context.Context, token, linkPassword string) (*models.DocumentWithBlocks, error) {
+	link, err := s.shareLinkRepo.GetShareLinkByTokenHash(hashShareToken(token))
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return nil, shareLinkNotFound(err)
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if link.RevokedAt != nil {
+		return nil, shareLinkNotFound(nil)
+	}
+	if link.Expired(now) {
+		return nil, apierror.NewNotFound("SHARE_LINK_EXPIRED", "共有リンクの有効期限が切れています", nil)
+	}
+
+	if link.HasPassword {
+		if err := s.verifyPassword(link, linkPassword, now); err != nil {
+			return nil, err
+		}
+	}
+
+	var shared *models.DocumentWithBlocks
+	if link.SnapshotID != nil {
+		shared, err = s.loadSnapshot(*link.SnapshotID)
+	} else {
+		shared, err = s.loadDocument(link.DocumentID)
+	}
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return nil, shareLinkNotFound(err)
+		}
+		return nil, err
+	}
+
+	// 閲覧の記録に失敗しても閲覧自体は成功として扱う
+	if err := s.shareLinkRepo.RecordAccess(link.ID); err != nil {
+		logctx.Printf(ctx, "Failed to record access to share link %d: %v", link.ID, err)
+	}
+
+	return shared, nil
+}
+
+// loadDocument - 共有リンクで公開する最新の文書をブロック付きで取得
+func (s *ShareLinkService) loadDocument(docID int) (*models.DocumentWithBlocks, error) {
+	doc, err := s.shareLinkRepo.GetSharedDocument(docID)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.blockRepo.GetBlocksByDocumentID(docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocks: %w", err)
+	}
+
+	return &models.DocumentWithBlocks{
+		Document: *doc,
+		Blocks:   blocks,
+		ReadOnly: true,
+	}, nil
+}
+
+// loadSnapshot - 共有リンクで公開する、内容を固定した文書を取得
+func (s *ShareLinkService) loadSnapshot(snapshotID int) (*models.DocumentWithBlocks, error) {
+	snapshot, err := s.shareLinkRepo.GetSnapshot(snapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DocumentWithBlocks{
+		Document: models.Document{
+			ID:        snapshot.DocumentID,
+			Title:     snapshot.Title,
+			Content:   snapshot.Content,
+			CreatedAt: snapshot.CreatedAt,
+			UpdatedAt: snapshot.CreatedAt,
+		},
+		Blocks:     snapshot.Blocks,
+		ReadOnly:   true,
+		SnapshotAt: &snapshot.CreatedAt,
+	}, nil
+}
+
+// verifyPassword - 共有リンクのパスワードを検証し、失敗を記録する
+func (s *ShareLinkService) verifyPassword(link *models.ShareLink, linkPassword string, now time.Time) error {
+	key := "share-link:" + strconv.Itoa(link.ID)
+	if s.throttle != nil {
+		if locked, remaining := s.throttle.Status(key, now); locked {
+			return shareLinkLocked(remaining)
+		}
+	}
+	if linkPassword == "" {
+		return apierror.NewUnauthorized("SHARE_PASSWORD_REQUIRED", "この共有リンクの閲覧にはパスワードが必要です", nil)
+	}
+
+	if _, err := s.hasher.Verify(linkPassword, link.PasswordHash); err != nil {
+		if !errors.Is(err, password.ErrMismatch) {
+			return fmt.Errorf("failed to verify share link password: %w", err)
+		}
+		if s.throttle != nil {
+			if locked, remaining := s.throttle.RecordFailure(key, now); locked {
+				return shareLinkLocked(remaining)
+			}
+		}
+		return apierror.NewUnauthorized("SHARE_PASSWORD_INVALID", "パスワードが正しくありません", err)
+	}
+
+	if s.throttle != nil {
+		s.throttle.RecordSuccess(key)
+	}
+	return nil
+}
+
+// ownedDocument - 文書の所有者であることを確認して文書を返す（ワークスペースの他のメンバーは共有リンクを管理できない）
+func (s *ShareLinkService) ownedDocument(docID, userID int) (*models.Document, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.UserID != userID {
+		return nil, apierror.NewForbidden("SHARE_OWNER_REQUIRED", "共有リンクを管理できるのは文書の所有者のみです", nil)
+	}
+	return doc, nil
+}
+
+// validateShareLinkOptions - 共有リンクの有効期限とパスワードを検証する
+func validateShareLinkOptions(expiresAt *time.Time, linkPassword string) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return apierror.NewValidationError("INVALID_EXPIRES_AT", "有効期限には未来の日時を指定してください", nil)
+	}
+	if linkPassword != "" {
+		length := len([]rune(linkPassword))
+		if length < minShareLinkPasswordLength || length > maxShareLinkPasswordLength {
+			return apierror.NewValidationError("INVALID_SHARE_PASSWORD",
+				fmt.Sprintf("パスワードは%d〜%d文字で入力してください", minShareLinkPasswordLength, maxShareLinkPasswordLength), nil)
+		}
+	}
+	return nil
+}
+
+// shareLinkNotFound - 存在しない・無効化した共有リンクのエラー
+func shareLinkNotFound(cause error) error {
+	return apierror.NewNotFound("SHARE_LINK_NOT_FOUND", "共有リンクが見つかりません", cause)
+}
+
+// shareLinkLocked - パスワードの失敗が続いてロックした共有リンクのエラー（retryAfter に解除までの秒数を含める）
+func shareLinkLocked(remaining time.Duration) error {
+	seconds := int((remaining + time.Second - 1) / time.Second)
+	return apierror.NewTooManyRequests(
+		"SHARE_LINK_LOCKED",
+		fmt.Sprintf("パスワードの試行回数が多すぎます。%d秒後に再度お試しください", seconds),
+		nil,
+	).WithDetails(map[string]int{"retryAfter": seconds})
+}
+
+// generateShareToken - 推測できない共有リンクのトークンを作成する
+func generateShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashShareToken - 保存・照合に使うトークンのハッシュ
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
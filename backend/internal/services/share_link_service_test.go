@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/password"
+)
+
+// MockShareLinkRepository - ShareLinkRepositoryのモック
+type MockShareLinkRepository struct {
+	CreateShareLinkFunc         func(link *models.ShareLink, tokenHash string) error
+	ListShareLinksFunc          func(docID int) ([]models.ShareLink, error)
+	GetShareLinkByTokenHashFunc func(tokenHash string) (*models.ShareLink, error)
+	RevokeShareLinkFunc         func(docID, linkID int) error
+	RecordAccessFunc            func(linkID int) error
+	GetSharedDocumentFunc       func(docID int) (*models.Document, error)
+	CreateSnapshotFunc          func(snapshot *models.DocumentSnapshot) error
+	GetSnapshotFunc             func(snapshotID int) (*models.DocumentSnapshot, error)
+}
+
+func (m *MockShareLinkRepository) CreateShareLink(link *models.ShareLink, tokenHash string) error {
+	if m.CreateShareLinkFunc != nil {
+		return m.CreateShareLinkFunc(link, tokenHash)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockShareLinkRepository) ListShareLinks(docID int) ([]models.ShareLink, error) {
+	if m.ListShareLinksFunc != nil {
+		return m.ListShareLinksFunc(docID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockShareLinkRepository) GetShareLinkByTokenHash(tokenHash string) (*models.ShareLink, error) {
+	if m.GetShareLinkByTokenHashFunc != nil {
+		return m.GetShareLinkByTokenHashFunc(tokenHash)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockShareLinkRepository) RevokeShareLink(docID, linkID int) error {
+	if m.RevokeShareLinkFunc != nil {
+		return m.RevokeShareLinkFunc(docID, linkID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockShareLinkRepository) RecordAccess(linkID int) error {
+	if m.RecordAccessFunc != nil {
+		return m.RecordAccessFunc(linkID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockShareLinkRepository) GetSharedDocument(docID int) (*models.Document, error) {
+	if m.GetSharedDocumentFunc != nil {
+		return m.GetSharedDocumentFunc(docID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockShareLinkRepository) CreateSnapshot(snapshot *models.DocumentSnapshot) error {
+	if m.CreateSnapshotFunc != nil {
+		return m.CreateSnapshotFunc(snapshot)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockShareLinkRepository) GetSnapshot(snapshotID int) (*models.DocumentSnapshot, error) {
+	if m.GetSnapshotFunc != nil {
+		return m.GetSnapshotFunc(snapshotID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+// MockSnapshotFileCopier - スナップショット用のファイルの複製のモック（元のファイルID + 100 のファイルを作成する）
+type MockSnapshotFileCopier struct {
+	Owned   map[int]bool // ユーザーがアップロードしたファイル（それ以外は ErrForbidden）
+	CopyErr error
+	Deleted []int
+}
+
+func (m *MockSnapshotFileCopier) CopyFile(ctx context.Context, fileID int, userID int, workspaceID *int, quota int64) (*models.FileMetadata, error) {
+	if !m.Owned[fileID] {
+		return nil, apierror.ErrForbidden
+	}
+	if m.CopyErr != nil {
+		return nil, m.CopyErr
+	}
+	return &models.FileMetadata{ID: fileID + 100, FileKey: "images/1/photo.png", BucketName: "uploads"}, nil
+}
+
+func (m *MockSnapshotFileCopier) DeleteFile(ctx context.Context, fileID int, userID int) error {
+	m.Deleted = append(m.Deleted, fileID)
+	return nil
+}
+
+// testShareHasher - テスト用の軽量なパスワードハッシャー
+func testShareHasher() *password.Hasher {
+	return password.NewHasher(password.Params{Memory: 1024, Iterations: 1, Parallelism: 1})
+}
+
+func TestShareLinkService_CreateShareLink(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		userID     int
+		expiresAt  *time.Time
+		password   string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "正常系：期限・パスワードなし", userID: 10},
+		{name: "正常系：期限・パスワード付き", userID: 10, expiresAt: &future, password: "secret"},
+		{name: "異常系：過去の有効期限", userID: 10, expiresAt: &past, wantStatus: http.StatusBadRequest, wantCode: "INVALID_EXPIRES_AT"},
+		{name: "異常系：短すぎるパスワード", userID: 10, password: "abc", wantStatus: http.StatusBadRequest, wantCode: "INVALID_SHARE_PASSWORD"},
+		{name: "異常系：所有者以外", userID: 11, wantStatus: http.StatusForbidden, wantCode: "SHARE_OWNER_REQUIRED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 文書の所有者はユーザー10（ワークスペースのメンバーとしてユーザー11も閲覧できる）
+			documentRepo := &MockDocumentCoreRepository{
+				GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+					return &models.Document{ID: docID, UserID: 10}, nil
+				},
+			}
+			var savedHash string
+			var saved *models.ShareLink
+			shareLinkRepo := &MockShareLinkRepository{
+				CreateShareLinkFunc: func(link *models.ShareLink, tokenHash string) error {
+					link.ID = 1
+					link.HasPassword = link.PasswordHash != ""
+					savedHash, saved = tokenHash, link
+					return nil
+				},
+			}
+			service := NewShareLinkService(documentRepo, &MockBlockRepository{}, shareLinkRepo, testShareHasher(), nil, nil, 0)
+
+			link, err := service.CreateShareLink(2, tt.userID, tt.expiresAt, tt.password)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateShareLink() error = %v", err)
+			}
+
+			// トークンそのものは保存せず、ハッシュのみを保存する
+			if link.Token == "" || savedHash != hashShareToken(link.Token) || savedHash == link.Token {
+				t.Errorf("token = %q, saved hash = %q", link.Token, savedHash)
+			}
+			if tt.password != "" && (saved.PasswordHash == "" || saved.PasswordHash == tt.password || !link.HasPassword) {
+				t.Errorf("password hash = %q, want hashed password", saved.PasswordHash)
+			}
+		})
+	}
+}
+
+func TestShareLinkService_GetSharedDocument(t *testing.T) {
+	hasher := testShareHasher()
+	passwordHash, err := hasher.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	revoked := now.Add(-time.Hour)
+	snapshotID := 9
+
+	// トークン → リンク
+	links := map[string]models.ShareLink{
+		"open":     {ID: 1, DocumentID: 2},
+		"expired":  {ID: 2, DocumentID: 2, ExpiresAt: &expired},
+		"revoked":  {ID: 3, DocumentID: 2, RevokedAt: &revoked},
+		"password": {ID: 4, DocumentID: 2, PasswordHash: passwordHash, HasPassword: true},
+		"snapshot": {ID: 5, DocumentID: 2, SnapshotID: &snapshotID},
+	}
+	newService := func(throttle *LoginThrottle) (*ShareLinkService, *int) {
+		accessed := 0
+		shareLinkRepo := &MockShareLinkRepository{
+			GetShareLinkByTokenHashFunc: func(tokenHash string) (*models.ShareLink, error) {
+				for token, link := range links {
+					if hashShareToken(token) == tokenHash {
+						link := link
+						return &link, nil
+					}
+				}
+				return nil, apierror.ErrNotFound
+			},
+			GetSharedDocumentFunc: func(docID int) (*models.Document, error) {
+				return &models.Document{ID: docID, Title: "共有された文書"}, nil
+			},
+			GetSnapshotFunc: func(snapshotID int) (*models.DocumentSnapshot, error) {
+				return &models.DocumentSnapshot{ID: snapshotID, DocumentID: 2, Title: "固定した文書", Blocks: []models.Block{{ID: 1, DocumentID: 2}}}, nil
+			},
+			RecordAccessFunc: func(linkID int) error {
+				accessed++
+				return nil
+			},
+		}
+		blockRepo := &MockBlockRepository{
+			GetBlocksByDocumentIDFunc: func(docID int) ([]models.Block, error) {
+				return []models.Block{{ID: 1, DocumentID: docID}}, nil
+			},
+		}
+		return NewShareLinkService(&MockDocumentCoreRepository{}, blockRepo, shareLinkRepo, hasher, throttle, nil, 0), &accessed
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		password   string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "正常系：パスワードなしのリンク", token: "open"},
+		{name: "正常系：正しいパスワード", token: "password", password: "secret"},
+		{name: "正常系：スナップショットのリンク", token: "snapshot"},
+		{name: "異常系：存在しないリンク", token: "unknown", wantStatus: http.StatusNotFound, wantCode: "SHARE_LINK_NOT_FOUND"},
+		{name: "異常系：無効化したリンク", token: "revoked", wantStatus: http.StatusNotFound, wantCode: "SHARE_LINK_NOT_FOUND"},
+		{name: "異常系：期限切れのリンク", token: "expired", wantStatus: http.StatusNotFound, wantCode: "SHARE_LINK_EXPIRED"},
+		{name: "異常系：パスワードがない", token: "password", wantStatus: http.StatusUnauthorized, wantCode: "SHARE_PASSWORD_REQUIRED"},
+		{name: "異常系：パスワードが違う", token: "password", password: "wrong", wantStatus: http.StatusUnauthorized, wantCode: "SHARE_PASSWORD_INVALID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, accessed := newService(nil)
+
+			doc, err := service.GetSharedDocument(context.Background(), tt.token, tt.password)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				if *accessed != 0 {
+					t.Errorf("accessed = %d, want 0", *accessed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetSharedDocument() error = %v", err)
+			}
+			if !doc.ReadOnly || len(doc.Blocks) != 1 || *accessed != 1 {
+				t.Errorf("doc = %+v, accessed = %d, want read-only document with blocks", doc, *accessed)
+			}
+			// スナップショットのリンクは最新の文書ではなく固定した内容を返す
+			if wantSnapshot := tt.token == "snapshot"; (doc.SnapshotAt != nil) != wantSnapshot || (wantSnapshot && doc.Title != "固定した文書") {
+				t.Errorf("doc = %+v, want snapshot = %v", doc, wantSnapshot)
+			}
+		})
+	}
+
+	t.Run("異常系：パスワードの失敗が続くとロックする", func(t *testing.T) {
+		service, _ := newService(NewLoginThrottle(2, time.Minute, time.Minute))
+
+		service.GetSharedDocument(context.Background(), "password", "wrong")
+		_, err := service.GetSharedDocument(context.Background(), "password", "wrong")
+		assertAppErrorCode(t, err, http.StatusTooManyRequests, "SHARE_LINK_LOCKED")
+
+		// ロック中は正しいパスワードでも閲覧できない
+		_, err = service.GetSharedDocument(context.Background(), "password", "secret")
+		assertAppErrorCode(t, err, http.StatusTooManyRequests, "SHARE_LINK_LOCKED")
+	})
+}
+
+func TestShareLinkService_CreateSnapshotLink(t *testing.T) {
+	ownedFile := json.RawMessage(`{"fileId":1,"src":"/api/uploads/old.png","alt":"写真"}`)
+	otherFile := json.RawMessage(`{"fileId":2,"src":"/api/uploads/other.png"}`)
+	newBlocks := func() []models.Block {
+		return []models.Block{
+			{ID: 1, Type: "text", Content: json.RawMessage(`"本文"`)},
+			{ID: 2, Type: "image", Content: ownedFile},
+			{ID: 3, Type: "image", Content: otherFile},
+		}
+	}
+	documentRepo := &MockDocumentCoreRepository{
+		GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+			return &models.Document{ID: docID, UserID: 10, Title: "設計メモ", Content: "概要"}, nil
+		},
+	}
+	blockRepo := &MockBlockRepository{
+		GetBlocksByDocumentIDFunc: func(docID int) ([]models.Block, error) { return newBlocks(), nil },
+	}
+
+	t.Run("正常系：内容とファイルの複製を固定する", func(t *testing.T) {
+		var snapshot *models.DocumentSnapshot
+		var link *models.ShareLink
+		shareLinkRepo := &MockShareLinkRepository{
+			CreateSnapshotFunc: func(s *models.DocumentSnapshot) error {
+				s.ID = 9
+				snapshot = s
+				return nil
+			},
+			CreateShareLinkFunc: func(l *models.ShareLink, tokenHash string) error {
+				link = l
+				return nil
+			},
+		}
+		files := &MockSnapshotFileCopier{Owned: map[int]bool{1: true}}
+		service := NewShareLinkService(documentRepo, blockRepo, shareLinkRepo, testShareHasher(), nil, files, 0)
+
+		created, err := service.CreateSnapshotLink(context.Background(), 2, 10, nil, "")
+		if err != nil {
+			t.Fatalf("CreateSnapshotLink() error = %v", err)
+		}
+		if created.Token == "" || link.SnapshotID == nil || *link.SnapshotID != 9 {
+			t.Fatalf("link = %+v, want token and snapshot 9", link)
+		}
+		if snapshot.Title != "設計メモ" || snapshot.Content != "概要" || len(snapshot.Blocks) != 3 {
+			t.Errorf("snapshot = %+v, want title, content and 3 blocks", snapshot)
+		}
+		// 自分のファイルは複製を参照し、他のユーザーのファイルは元のファイルを参照したままにする
+		if fileID, _ := models.MediaFileID("image", snapshot.Blocks[1].Content); fileID != 101 {
+			t.Errorf("owned file block references %d, want copy 101", fileID)
+		}
+		if fileID, _ := models.MediaFileID("image", snapshot.Blocks[2].Content); fileID != 2 {
+			t.Errorf("other file block references %d, want original 2", fileID)
+		}
+		if len(snapshot.FileIDs) != 1 || snapshot.FileIDs[0] != 101 {
+			t.Errorf("FileIDs = %v, want [101]", snapshot.FileIDs)
+		}
+	})
+
+	t.Run("異常系：スナップショットを保存できない場合は複製したファイルを削除する", func(t *testing.T) {
+		shareLinkRepo := &MockShareLinkRepository{
+			CreateSnapshotFunc: func(s *models.DocumentSnapshot) error { return errors.New("db error") },
+		}
+		files := &MockSnapshotFileCopier{Owned: map[int]bool{1: true}}
+		service := NewShareLinkService(documentRepo, blockRepo, shareLinkRepo, testShareHasher(), nil, files, 0)
+
+		if _, err := service.CreateSnapshotLink(context.Background(), 2, 10, nil, ""); err == nil {
+			t.Fatal("CreateSnapshotLink() error = nil, want error")
+		}
+		if len(files.Deleted) != 1 || files.Deleted[0] != 101 {
+			t.Errorf("Deleted = %v, want [101]", files.Deleted)
+		}
+	})
+
+	t.Run("異常系：所有者以外", func(t *testing.T) {
+		service := NewShareLinkService(documentRepo, blockRepo, &MockShareLinkRepository{}, testShareHasher(), nil, nil, 0)
+
+		_, err := service.CreateSnapshotLink(context.Background(), 2, 11, nil, "")
+		assertAppErrorCode(t, err, http.StatusForbidden, "SHARE_OWNER_REQUIRED")
+	})
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"simple-notion-backend/internal/models"
+)
+
+// statsTermDays - 日ごとの推移（アップロード数・アクティブユーザー数）と新規ユーザー数を集計する日数
+const statsTermDays = 30
+
+// StatsService - 管理者向けのインスタンス統計を集計・キャッシュするサービス
+// 集計は全テーブルを走査するため、結果を ttl の間キャッシュし、スケジューラーから定期的に更新する
+type StatsService struct {
+	statsRepo StatsRepositoryInterface
+	blockRepo BlockRepositoryInterface
+	ttl       time.Duration
+
+	mu     sync.Mutex
+	cached *models.InstanceStats
+}
+
+// NewStatsService - StatsServiceを初期化
+func NewStatsService(statsRepo StatsRepositoryInterface, blockRepo BlockRepositoryInterface, ttl time.Duration) *StatsService {
+	return &StatsService{
+		statsRepo: statsRepo,
+		blockRepo: blockRepo,
+		ttl:       ttl,
+	}
+}
+
+// GetStats - キャッシュが有効ならそれを返し、期限切れの場合は集計し直す
+func (s *StatsService) GetStats() (*models.InstanceStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Since(s.cached.GeneratedAt) < s.ttl {
+		return s.cached, nil
+	}
+	return s.refreshLocked()
+}
+
+// Refresh - 統計を集計し直してキャッシュを更新
+func (s *StatsService) Refresh() (*models.InstanceStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.refreshLocked()
+}
+
+// refreshLocked - 統計を集計してキャッシュに保存（s.mu を保持した状態で呼び出す）
+// 集計に失敗した場合は以前のキャッシュを残す
+func (s *StatsService) refreshLocked() (*models.InstanceStats, error) {
+	now := time.Now()
+	since := now.AddDate(0, 0, -(statsTermDays - 1))
+	stats := &models.InstanceStats{GeneratedAt: now}
+	var err error
+
+	if stats.Users, err = s.statsRepo.GetUserStats(since); err != nil {
+		return nil, fmt.Errorf("failed to get user stats: %w", err)
+	}
+	if stats.Documents, err = s.statsRepo.GetDocumentStats(); err != nil {
+		return nil, fmt.Errorf("failed to get document stats: %w", err)
+	}
+	if stats.Blocks.ByType, err = s.blockRepo.CountBlocksByType(); err != nil {
+		return nil, fmt.Errorf("failed to count blocks by type: %w", err)
+	}
+	for _, c := range stats.Blocks.ByType {
+		stats.Blocks.Total += c.Count
+	}
+	if stats.Storage, err = s.statsRepo.GetStorageStats(); err != nil {
+		return nil, fmt.Errorf("failed to get storage stats: %w", err)
+	}
+	if stats.UploadsPerDay, err = s.statsRepo.GetUploadsPerDay(since); err != nil {
+		return nil, fmt.Errorf("failed to get uploads per day: %w", err)
+	}
+	if stats.ActiveUsersPerDay, err = s.statsRepo.GetActiveUsersPerDay(since); err != nil {
+		return nil, fmt.Errorf("failed to get active users per day: %w", err)
+	}
+
+	s.cached = stats
+	return stats, nil
+}
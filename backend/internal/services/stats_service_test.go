@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/models"
+)
+
+// MockStatsRepository - StatsRepositoryのモック
+type MockStatsRepository struct {
+	UserStatsCalls int
+	Err            error
+}
+
+func (m *MockStatsRepository) GetUserStats(since time.Time) (models.UserStats, error) {
+	m.UserStatsCalls++
+	return models.UserStats{Total: 10, NewInTerm: 3}, m.Err
+}
+
+func (m *MockStatsRepository) GetDocumentStats() (models.DocumentStats, error) {
+	return models.DocumentStats{Total: 40, Trashed: 5}, nil
+}
+
+func (m *MockStatsRepository) GetStorageStats() (models.StorageStats, error) {
+	return models.StorageStats{Files: 2, Bytes: 2048}, nil
+}
+
+func (m *MockStatsRepository) GetUploadsPerDay(since time.Time) ([]models.DailyCount, error) {
+	return []models.DailyCount{{Date: since.Format("2006-01-02"), Count: 2}}, nil
+}
+
+func (m *MockStatsRepository) GetActiveUsersPerDay(since time.Time) ([]models.DailyCount, error) {
+	return []models.DailyCount{{Date: since.Format("2006-01-02"), Count: 1}}, nil
+}
+
+// TestStatsService - 統計の集計とキャッシュのテスト
+func TestStatsService(t *testing.T) {
+	blockRepo := &MockBlockRepository{
+		CountBlocksByTypeFunc: func() ([]models.BlockTypeCount, error) {
+			return []models.BlockTypeCount{{Type: "text", Count: 7}, {Type: "image", Count: 2}}, nil
+		},
+	}
+
+	t.Run("正常系：集計結果をまとめる", func(t *testing.T) {
+		service := NewStatsService(&MockStatsRepository{}, blockRepo, time.Minute)
+
+		stats, err := service.GetStats()
+		if err != nil {
+			t.Fatalf("GetStats() error = %v", err)
+		}
+		if stats.Users.Total != 10 || stats.Documents.Trashed != 5 || stats.Storage.Bytes != 2048 {
+			t.Errorf("GetStats() = %+v", stats)
+		}
+		if stats.Blocks.Total != 9 || len(stats.Blocks.ByType) != 2 {
+			t.Errorf("Blocks = %+v, want total 9 with 2 types", stats.Blocks)
+		}
+		if len(stats.UploadsPerDay) != 1 || len(stats.ActiveUsersPerDay) != 1 {
+			t.Errorf("daily counts = %v / %v", stats.UploadsPerDay, stats.ActiveUsersPerDay)
+		}
+	})
+
+	t.Run("正常系：有効期間内はキャッシュを返す", func(t *testing.T) {
+		repo := &MockStatsRepository{}
+		service := NewStatsService(repo, blockRepo, time.Minute)
+
+		first, _ := service.GetStats()
+		second, _ := service.GetStats()
+		if repo.UserStatsCalls != 1 || first != second {
+			t.Errorf("aggregated %d times, want 1 (cached)", repo.UserStatsCalls)
+		}
+
+		if _, err := service.Refresh(); err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+		if repo.UserStatsCalls != 2 {
+			t.Errorf("Refresh() should aggregate again, calls = %d", repo.UserStatsCalls)
+		}
+	})
+
+	t.Run("正常系：有効期間が0の場合は毎回集計する", func(t *testing.T) {
+		repo := &MockStatsRepository{}
+		service := NewStatsService(repo, blockRepo, 0)
+
+		service.GetStats()
+		service.GetStats()
+		if repo.UserStatsCalls != 2 {
+			t.Errorf("aggregated %d times, want 2", repo.UserStatsCalls)
+		}
+	})
+
+	t.Run("異常系：集計に失敗した場合は以前のキャッシュを残す", func(t *testing.T) {
+		repo := &MockStatsRepository{}
+		service := NewStatsService(repo, blockRepo, time.Minute)
+		cached, _ := service.GetStats()
+
+		repo.Err = errors.New("db down")
+		if _, err := service.Refresh(); err == nil {
+			t.Fatal("Refresh() error = nil, want error")
+		}
+		stats, err := service.GetStats()
+		if err != nil || stats != cached {
+			t.Errorf("GetStats() = %v, %v, want previous cache", stats, err)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"time"
+
+	"simple-notion-backend/internal/models"
+)
+
+// TaskService - 全文書の todo ブロックを「自分のタスク」として扱うサービス
+type TaskService struct {
+	taskRepo TaskRepositoryInterface
+}
+
+// NewTaskService - TaskServiceを初期化
+func NewTaskService(taskRepo TaskRepositoryInterface) *TaskService {
+	return &TaskService{
+		taskRepo: taskRepo,
+	}
+}
+
+// ListOpenTasks - ユーザーの未完了タスクを取得し、期限切れ（今日より前が期限）のものに印を付ける
+func (s *TaskService) ListOpenTasks(userID int, now time.Time) ([]models.Task, error) {
+	tasks, err := s.taskRepo.ListOpenTasks(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := now.Format("2006-01-02")
+	for i := range tasks {
+		// 期限は YYYY-MM-DD 形式で検証済みのため、文字列の比較で日付の前後を判定できる
+		if due := tasks[i].DueDate; due != nil && *due < today {
+			tasks[i].Overdue = true
+		}
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/models"
+)
+
+// MockTaskRepository - TaskRepositoryのモック
+type MockTaskRepository struct {
+	ListOpenTasksFunc func(userID int) ([]models.Task, error)
+}
+
+func (m *MockTaskRepository) ListOpenTasks(userID int) ([]models.Task, error) {
+	if m.ListOpenTasksFunc != nil {
+		return m.ListOpenTasksFunc(userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+// TestListOpenTasks - 未完了タスク取得のテスト
+func TestListOpenTasks(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	t.Run("正常系：期限切れのタスクに印を付ける", func(t *testing.T) {
+		repo := &MockTaskRepository{
+			ListOpenTasksFunc: func(userID int) ([]models.Task, error) {
+				if userID != 3 {
+					t.Errorf("userID = %d, want 3", userID)
+				}
+				return []models.Task{
+					{BlockID: 1, DueDate: strPtr("2026-10-14")},
+					{BlockID: 2, DueDate: strPtr("2026-10-15")},
+					{BlockID: 3},
+				}, nil
+			},
+		}
+
+		tasks, err := NewTaskService(repo).ListOpenTasks(3, now)
+		if err != nil {
+			t.Fatalf("ListOpenTasks() error = %v", err)
+		}
+		want := map[int]bool{1: true, 2: false, 3: false}
+		for _, task := range tasks {
+			if task.Overdue != want[task.BlockID] {
+				t.Errorf("task %d Overdue = %v, want %v", task.BlockID, task.Overdue, want[task.BlockID])
+			}
+		}
+	})
+
+	t.Run("異常系：リポジトリのエラーを返す", func(t *testing.T) {
+		repo := &MockTaskRepository{
+			ListOpenTasksFunc: func(userID int) ([]models.Task, error) {
+				return nil, errors.New("db error")
+			},
+		}
+		if _, err := NewTaskService(repo).ListOpenTasks(3, now); err == nil {
+			t.Error("ListOpenTasks() error = nil, want error")
+		}
+	})
+}
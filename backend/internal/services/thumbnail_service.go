@@ -0,0 +1,191 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/repository"
+	"simple-notion-backend/internal/storage"
+	"simple-notion-backend/internal/thumbnail"
+	"simple-notion-backend/internal/webp"
+)
+
+const (
+	// thumbnailBatchSize - 1回の実行でサムネイルを作成する画像の最大件数
+	thumbnailBatchSize = 20
+	// thumbnailStaleAfter - 作成中のまま止まった画像を再処理するまでの時間
+	thumbnailStaleAfter = 10 * time.Minute
+)
+
+// ImageOptimization - 画像の WebP 変換の設定
+type ImageOptimization struct {
+	Enabled      bool  // WebP に変換するか
+	MinSize      int64 // 変換する画像の最小サイズ（バイト）
+	KeepOriginal bool  // 元の画像を残して WebP を別に保存するか（false の場合は WebP で置き換える）
+}
+
+// ThumbnailService - 画像のサムネイル作成（バックグラウンドジョブから呼び出す）
+// アップロード時は作成待ちとして記録するだけにし、アップロードの応答を遅らせない
+// 設定に応じて、サムネイルの作成後に大きな JPEG/PNG を WebP に変換する
+type ThumbnailService struct {
+	fileRepo      *repository.FileRepository
+	objectStorage storage.ObjectStorage
+	optimization  ImageOptimization
+}
+
+// NewThumbnailService - ThumbnailServiceを初期化
+func NewThumbnailService(fileRepo *repository.FileRepository, objectStorage storage.ObjectStorage, optimization ImageOptimization) *ThumbnailService {
+	return &ThumbnailService{
+		fileRepo:      fileRepo,
+		objectStorage: objectStorage,
+		optimization:  optimization,
+	}
+}
+
+// ProcessPending - 作成待ちの画像のサムネイルを作成し、作成した画像の件数を返す
+// 作成に失敗した画像は failed として記録し、配信時は元の画像を返す
+func (s *ThumbnailService) ProcessPending(ctx context.Context) (int, error) {
+	files, err := s.fileRepo.ClaimPendingThumbnails(ctx, thumbnailBatchSize, thumbnailStaleAfter)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, file := range files {
+		thumbnails, err := s.generate(ctx, file)
+		status := models.ThumbnailStatusReady
+		if err != nil {
+			logctx.Printf(ctx, "failed to generate thumbnails for file %d: %v", file.ID, err)
+			status = models.ThumbnailStatusFailed
+		}
+		if err := s.fileRepo.UpdateThumbnails(ctx, file.ID, thumbnails, status); err != nil {
+			return processed, err
+		}
+		if status == models.ThumbnailStatusReady {
+			processed++
+		}
+
+		// WebP への変換に失敗しても元の画像を配信できるため、ログのみ残す
+		if s.shouldOptimize(file) {
+			if err := s.optimize(ctx, file); err != nil {
+				logctx.Printf(ctx, "failed to convert file %d to webp: %v", file.ID, err)
+			}
+		}
+	}
+	return processed, nil
+}
+
+// shouldOptimize - WebP に変換する画像か（変換済みの画像・小さい画像は変換しない）
+func (s *ThumbnailService) shouldOptimize(file *models.FileMetadata) bool {
+	if !s.optimization.Enabled || file.FileSize < s.optimization.MinSize {
+		return false
+	}
+	if file.Variant != "" && file.Variant != models.FileVariantOriginal {
+		return false
+	}
+	return file.MimeType == "image/jpeg" || file.MimeType == "image/png"
+}
+
+// optimize - 画像を可逆圧縮の WebP に変換して保存
+// 変換後の方が大きい場合は何もしない。元の画像を残す設定の場合は optimized/ 以下に別に保存し、
+// それ以外の場合は同じキーのオブジェクトを WebP で置き換える（共有している参照もすべて WebP になる）
+func (s *ThumbnailService) optimize(ctx context.Context, file *models.FileMetadata) error {
+	source, err := s.readObject(ctx, file.FileKey)
+	if err != nil {
+		return err
+	}
+	img, _, err := thumbnail.Decode(bytes.NewReader(source))
+	if err != nil {
+		return err
+	}
+
+	var encoded bytes.Buffer
+	if err := webp.EncodeLossless(&encoded, img); err != nil {
+		return err
+	}
+	if encoded.Len() >= len(source) {
+		return nil
+	}
+	data := encoded.Bytes()
+
+	if s.optimization.KeepOriginal {
+		key := webpFileKey(file.FileKey)
+		if err := s.objectStorage.UploadFile(ctx, key, bytes.NewReader(data), int64(len(data)), "image/webp"); err != nil {
+			return err
+		}
+		return s.fileRepo.SetWebPVariant(ctx, file.FileKey, key)
+	}
+
+	if err := s.objectStorage.UploadFile(ctx, file.FileKey, bytes.NewReader(data), int64(len(data)), "image/webp"); err != nil {
+		return err
+	}
+	contentHash, err := hashContent(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return s.fileRepo.ReplaceWithWebP(ctx, file.FileKey, int64(len(data)), contentHash)
+}
+
+// generate - 1つの画像からすべてのサイズのサムネイルを作成してストレージに保存
+func (s *ThumbnailService) generate(ctx context.Context, file *models.FileMetadata) (map[string]string, error) {
+	source, err := s.readObject(ctx, file.FileKey)
+	if err != nil {
+		return map[string]string{}, err
+	}
+
+	// 小さいサイズから作成する（途中で失敗しても作成済みのキーは記録して削除できるようにする）
+	sizes := make([]string, 0, len(models.ThumbnailSizes))
+	for size := range models.ThumbnailSizes {
+		sizes = append(sizes, size)
+	}
+	sort.Slice(sizes, func(i, j int) bool { return models.ThumbnailSizes[sizes[i]] < models.ThumbnailSizes[sizes[j]] })
+
+	thumbnails := map[string]string{}
+	for _, size := range sizes {
+		thumb, err := thumbnail.Generate(bytes.NewReader(source), models.ThumbnailSizes[size])
+		if err != nil {
+			return thumbnails, err
+		}
+
+		key := thumbnailKey(file.FileKey, size, thumb.Ext)
+		if err := s.objectStorage.UploadFile(ctx, key, bytes.NewReader(thumb.Data), int64(len(thumb.Data)), thumb.ContentType); err != nil {
+			return thumbnails, err
+		}
+		thumbnails[size] = key
+	}
+	return thumbnails, nil
+}
+
+// readObject - ストレージからファイルの内容を読み込む
+func (s *ThumbnailService) readObject(ctx context.Context, fileKey string) ([]byte, error) {
+	object, err := s.objectStorage.GetObject(ctx, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(object); err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", fileKey, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// webpFileKey - 元のファイルのキーから、元の画像を残す場合の WebP のキーを作る
+// 例: images/1/uuid_photo.png → optimized/images/1/uuid_photo.webp
+func webpFileKey(fileKey string) string {
+	return "optimized/" + strings.TrimSuffix(fileKey, path.Ext(fileKey)) + ".webp"
+}
+
+// thumbnailKey - 元のファイルのキーからサムネイルのキーを作る
+// 例: images/1/uuid_photo.png → thumbnails/thumb/images/1/uuid_photo.png
+func thumbnailKey(fileKey, size, ext string) string {
+	return fmt.Sprintf("thumbnails/%s/%s%s", size, strings.TrimSuffix(fileKey, path.Ext(fileKey)), ext)
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"simple-notion-backend/internal/models"
+)
+
+// uploadProgressRetention - 購読者がいないアップロードの進捗を、最後の更新から保持する時間
+// アップロードのリクエストより遅れて購読したクライアントにも結果を返すため
+const uploadProgressRetention = time.Minute
+
+// UploadProgressTracker - アップロードIDごとに最新の進捗を保持し、購読者に配信する
+// 進捗は同じユーザーにのみ配信する（他のユーザーのアップロードIDを指定しても何も届かない）
+// 状態はプロセス内のメモリにのみ保持する（複数のプロセスで動かす場合は、アップロードを受けたプロセスに購読する必要がある）
+type UploadProgressTracker struct {
+	mu      sync.Mutex
+	entries map[string]*uploadProgressEntry
+	now     func() time.Time
+}
+
+// uploadProgressEntry - 1つのアップロードの最新の進捗と購読者
+type uploadProgressEntry struct {
+	latest      *models.UploadProgress
+	subscribers map[chan models.UploadProgress]struct{}
+	updatedAt   time.Time
+}
+
+// NewUploadProgressTracker - UploadProgressTrackerを初期化
+func NewUploadProgressTracker() *UploadProgressTracker {
+	return &UploadProgressTracker{
+		entries: make(map[string]*uploadProgressEntry),
+		now:     time.Now,
+	}
+}
+
+// Publish - 進捗を記録し、購読者に配信する
+// 購読者が受け取っていない古い進捗は最新の進捗で置き換える（遅い購読者でアップロードを止めない）
+func (t *UploadProgressTracker) Publish(userID int, progress models.UploadProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+
+	key := uploadProgressKey(userID, progress.UploadID)
+	entry, ok := t.entries[key]
+	if !ok {
+		entry = &uploadProgressEntry{subscribers: make(map[chan models.UploadProgress]struct{})}
+		t.entries[key] = entry
+	}
+	entry.latest = &progress
+	entry.updatedAt = now
+
+	for ch := range entry.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- progress
+	}
+}
+
+// Subscribe - アップロードの進捗を購読する
+// 記録済みの進捗があればすぐに届く。返した関数で購読を解除する
+func (t *UploadProgressTracker) Subscribe(userID int, uploadID string) (<-chan models.UploadProgress, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweep(t.now())
+
+	key := uploadProgressKey(userID, uploadID)
+	entry, ok := t.entries[key]
+	if !ok {
+		// アップロードの開始より先に購読した場合
+		entry = &uploadProgressEntry{subscribers: make(map[chan models.UploadProgress]struct{})}
+		t.entries[key] = entry
+	}
+
+	ch := make(chan models.UploadProgress, 1)
+	if entry.latest != nil {
+		ch <- *entry.latest
+	}
+	entry.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		delete(entry.subscribers, ch)
+		if len(entry.subscribers) == 0 && entry.latest == nil && t.entries[key] == entry {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// sweep - 購読者がおらず、保持期間の間更新されていない進捗を削除する（mu を取得した状態で呼ぶ）
+// 中断したままの分割アップロードの進捗もここで削除される（次のチャンクを受信すると再び記録する）
+func (t *UploadProgressTracker) sweep(now time.Time) {
+	for key, entry := range t.entries {
+		if len(entry.subscribers) == 0 && now.Sub(entry.updatedAt) >= uploadProgressRetention {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// uploadProgressKey - ユーザーとアップロードIDの組み合わせのキー
+func uploadProgressKey(userID int, uploadID string) string {
+	return strconv.Itoa(userID) + ":" + uploadID
+}
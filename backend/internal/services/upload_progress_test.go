@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/models"
+)
+
+// TestUploadProgressTracker - アップロードの進捗の配信のテスト
+func TestUploadProgressTracker(t *testing.T) {
+	t.Run("正常系：購読後の進捗が届き、古い進捗は最新の進捗で置き換えられる", func(t *testing.T) {
+		tracker := NewUploadProgressTracker()
+		updates, unsubscribe := tracker.Subscribe(1, "upload-0001")
+		defer unsubscribe()
+
+		tracker.Publish(1, models.UploadProgress{UploadID: "upload-0001", Stage: models.UploadStageReceiving, ReceivedBytes: 10, TotalBytes: 100})
+		tracker.Publish(1, models.UploadProgress{UploadID: "upload-0001", Stage: models.UploadStageReceiving, ReceivedBytes: 60, TotalBytes: 100})
+
+		select {
+		case got := <-updates:
+			if got.ReceivedBytes != 60 {
+				t.Errorf("ReceivedBytes = %d, want 60", got.ReceivedBytes)
+			}
+		default:
+			t.Fatal("no progress delivered")
+		}
+	})
+
+	t.Run("正常系：アップロードより後に購読しても最新の進捗が届く", func(t *testing.T) {
+		tracker := NewUploadProgressTracker()
+		tracker.Publish(1, models.UploadProgress{UploadID: "upload-0001", Stage: models.UploadStageCompleted, FileID: 5})
+
+		updates, unsubscribe := tracker.Subscribe(1, "upload-0001")
+		defer unsubscribe()
+
+		select {
+		case got := <-updates:
+			if !got.Finished() || got.FileID != 5 {
+				t.Errorf("progress = %+v, want completed fileId 5", got)
+			}
+		default:
+			t.Fatal("no progress delivered")
+		}
+	})
+
+	t.Run("異常系：他のユーザーのアップロードの進捗は届かない", func(t *testing.T) {
+		tracker := NewUploadProgressTracker()
+		updates, unsubscribe := tracker.Subscribe(2, "upload-0001")
+		defer unsubscribe()
+
+		tracker.Publish(1, models.UploadProgress{UploadID: "upload-0001", Stage: models.UploadStageReceiving})
+
+		select {
+		case got := <-updates:
+			t.Errorf("progress = %+v, want nothing", got)
+		default:
+		}
+	})
+
+	t.Run("正常系：購読者のいない古い進捗は削除される", func(t *testing.T) {
+		now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+		tracker := NewUploadProgressTracker()
+		tracker.now = func() time.Time { return now }
+
+		tracker.Publish(1, models.UploadProgress{UploadID: "upload-0001", Stage: models.UploadStageCompleted})
+		now = now.Add(uploadProgressRetention)
+		tracker.Publish(1, models.UploadProgress{UploadID: "upload-0002", Stage: models.UploadStageReceiving})
+
+		if _, ok := tracker.entries[uploadProgressKey(1, "upload-0001")]; ok {
+			t.Error("expired progress was not removed")
+		}
+		if len(tracker.entries) != 1 {
+			t.Errorf("entries = %d, want 1", len(tracker.entries))
+		}
+	})
+}
@@ -0,0 +1,596 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/events"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/mediaprobe"
+	"simple-notion-backend/internal/mimetype"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/repository"
+	"simple-notion-backend/internal/storage"
+)
+
+const (
+	// expiredUploadSessionBatch - 1回のクリーンアップで破棄する期限切れセッションの最大件数
+	expiredUploadSessionBatch = 100
+	// directUploadURLExpiry - 直接アップロード用の署名付きURLの有効期限
+	directUploadURLExpiry = time.Hour
+)
+
+// UploadSessionService - 大きなファイルのアップロードのビジネスロジック
+// 再開可能な分割アップロードでは、チャンクをストレージのマルチパートアップロードのパートとして保存し、完了時に結合する
+// 直接アップロードでは、署名付きURLでブラウザからストレージへ送らせ、確認時にサイズと種類を照合して登録する
+// どちらも同じユーザーの同じ内容のファイルを保存済みの場合は、受信したファイルを削除して保存済みのオブジェクトを参照する
+type UploadSessionService struct {
+	sessionRepo      *repository.UploadSessionRepository
+	fileRepo         *repository.FileRepository
+	objectStorage    storage.ObjectStorage
+	maxFileSize      int64
+	maxVideoFileSize int64
+	maxAudioFileSize int64
+	sessionTTL       time.Duration
+	presignExpiry    int               // 署名付きURLの有効期限（秒）
+	mediaProber      mediaprobe.Prober // nil の場合は動画・音声の再生時間と映像の大きさを取得しない
+	allowedTypes     *mimetype.Allowlist
+	workspaces       WorkspaceStorageCheckerInterface // nil の場合はワークスペースの役割とストレージクォータを確認しない
+	bus              *events.Bus                      // nil の場合はイベントを発行しない
+}
+
+// NewUploadSessionService - UploadSessionServiceを初期化
+func NewUploadSessionService(
+	sessionRepo *repository.UploadSessionRepository,
+	fileRepo *repository.FileRepository,
+	objectStorage storage.ObjectStorage,
+	maxFileSize int64,
+	maxVideoFileSize int64,
+	maxAudioFileSize int64,
+	sessionTTL time.Duration,
+	presignExpiry int,
+	mediaProber mediaprobe.Prober,
+	allowedTypes *mimetype.Allowlist,
+	workspaces WorkspaceStorageCheckerInterface,
+	bus *events.Bus,
+) *UploadSessionService {
+	return &UploadSessionService{
+		sessionRepo:      sessionRepo,
+		fileRepo:         fileRepo,
+		objectStorage:    objectStorage,
+		maxFileSize:      maxFileSize,
+		maxVideoFileSize: maxVideoFileSize,
+		maxAudioFileSize: maxAudioFileSize,
+		sessionTTL:       sessionTTL,
+		presignExpiry:    presignExpiry,
+		mediaProber:      mediaProber,
+		allowedTypes:     allowedTypes,
+		workspaces:       workspaces,
+		bus:              bus,
+	}
+}
+
+// CreateSession - 分割アップロードを開始
+// ファイル全体のサイズでサイズ上限とストレージクォータを確認する
+func (s *UploadSessionService) CreateSession(ctx context.Context, userID int, workspaceID *int, filename, contentType string, size, quota int64) (*models.UploadSession, error) {
+	if size > int64(models.MaxUploadParts)*models.MaxUploadChunkSize {
+		return nil, apierror.NewPayloadTooLarge("FILE_TOO_LARGE", "ファイルサイズが分割アップロードの上限を超えています", nil)
+	}
+	if err := s.checkNewUpload(ctx, userID, workspaceID, filename, contentType, size, quota); err != nil {
+		return nil, err
+	}
+
+	fileKey := generateFileKey(userID, filename, s.uploadKeyPrefix(contentType))
+	uploadID, err := s.objectStorage.CreateMultipartUpload(ctx, fileKey, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.UploadSession{
+		ID:              uuid.New().String(),
+		UserID:          userID,
+		FileKey:         fileKey,
+		StorageUploadID: uploadID,
+		OriginalName:    filename,
+		MimeType:        contentType,
+		TotalSize:       size,
+		ExpiresAt:       time.Now().Add(s.sessionTTL),
+	}
+	if err := s.sessionRepo.CreateSession(session); err != nil {
+		_ = s.objectStorage.AbortMultipartUpload(ctx, fileKey, uploadID)
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetSession - セッションの状態（受信済みのオフセット）を取得
+func (s *UploadSessionService) GetSession(userID int, id string) (*models.UploadSession, error) {
+	return s.sessionRepo.GetSession(id, userID)
+}
+
+// AppendChunk - offset の位置からチャンクを追記
+// offset が受信済みサイズと一致しない場合は UPLOAD_OFFSET_MISMATCH（409）を返し、details に現在のオフセットを含める
+func (s *UploadSessionService) AppendChunk(ctx context.Context, userID int, id string, offset int64, chunk io.Reader, size int64) (*models.UploadSession, error) {
+	session, err := s.sessionRepo.GetSession(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUploadChunk(session, offset, size); err != nil {
+		return nil, err
+	}
+
+	part := models.UploadPart{PartNumber: session.PartCount + 1, Size: size}
+	part.ETag, err = s.objectStorage.UploadPart(ctx, session.FileKey, session.StorageUploadID, part.PartNumber, chunk, size)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.sessionTTL)
+	if err := s.sessionRepo.AppendPart(session.ID, offset, part, expiresAt); err != nil {
+		if errors.Is(err, apierror.ErrConflict) {
+			// 同じ位置への追記が先に完了していた
+			return nil, offsetMismatchError(s.currentOffset(session), err)
+		}
+		return nil, err
+	}
+
+	session.Offset += size
+	session.PartCount++
+	session.ExpiresAt = expiresAt
+	return session, nil
+}
+
+// CompleteSession - 受信したパートを結合してファイルとして登録
+// 結合後のファイルは通常のアップロードと同じく file_metadata に保存する
+func (s *UploadSessionService) CompleteSession(ctx context.Context, userID int, workspaceID *int, id string, quota int64) (*models.FileMetadata, string, error) {
+	session, err := s.sessionRepo.GetSession(id, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !session.Complete() {
+		return nil, "", apierror.NewConflict(
+			"UPLOAD_INCOMPLETE",
+			fmt.Sprintf("アップロードが完了していません（%d / %d バイト）", session.Offset, session.TotalSize),
+			nil,
+		).WithDetails(map[string]int64{"offset": session.Offset, "size": session.TotalSize})
+	}
+
+	// 同時に進めていた他のアップロードで容量を使い切っている場合がある
+	if err := s.checkQuota(ctx, userID, workspaceID, session.TotalSize, quota); err != nil {
+		return nil, "", err
+	}
+
+	parts, err := s.sessionRepo.ListParts(session.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	completed := make([]storage.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, storage.CompletedPart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+	if err := s.objectStorage.CompleteMultipartUpload(ctx, session.FileKey, session.StorageUploadID, completed); err != nil {
+		return nil, "", err
+	}
+
+	contentHash, err := s.inspectStoredObject(ctx, session.FileKey, session.MimeType)
+	if err != nil {
+		// 結合済みのファイルは再送できないため、ファイルとセッションごと破棄する
+		if delErr := s.objectStorage.DeleteFile(ctx, session.FileKey); delErr != nil {
+			logctx.Printf(ctx, "failed to delete rejected upload %s: %v", session.FileKey, delErr)
+		} else if delErr := s.sessionRepo.DeleteSession(session.ID); delErr != nil {
+			logctx.Printf(ctx, "failed to delete rejected upload session %s: %v", session.ID, delErr)
+		}
+		return nil, "", err
+	}
+
+	fileMeta := &models.FileMetadata{
+		UserID:       userID,
+		FileKey:      session.FileKey,
+		BucketName:   s.objectStorage.GetBucketName(),
+		OriginalName: session.OriginalName,
+		FileSize:     session.TotalSize,
+		MimeType:     session.MimeType,
+		FileType:     s.uploadFileType(session.MimeType),
+		Status:       "active",
+		ContentHash:  contentHash,
+	}
+	s.probeStoredMedia(ctx, fileMeta)
+	reused, err := createFileReference(ctx, s.fileRepo, fileMeta)
+	if err != nil {
+		return nil, "", err
+	}
+	if reused {
+		// 同じ内容のファイルを保存済みのため、結合したファイルは削除する
+		if err := s.objectStorage.DeleteFile(ctx, session.FileKey); err != nil {
+			logctx.Printf(ctx, "failed to delete duplicate upload %s: %v", session.FileKey, err)
+		}
+	} else if err := s.fileRepo.Create(ctx, fileMeta); err != nil {
+		_ = s.objectStorage.DeleteFile(ctx, session.FileKey)
+		return nil, "", fmt.Errorf("failed to save file metadata: %w", err)
+	}
+
+	if err := s.sessionRepo.DeleteSession(session.ID); err != nil {
+		// ファイルは登録済みのため、セッションは期限切れのクリーンアップに任せる
+		logctx.Printf(ctx, "failed to delete completed upload session %s: %v", session.ID, err)
+	}
+	publishFileUploaded(ctx, s.bus, fileMeta)
+
+	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileMeta.FileKey, time.Duration(s.presignExpiry)*time.Second)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	return fileMeta, presignedURL, nil
+}
+
+// AbortSession - 分割アップロードを中止し、受信済みのパートを破棄
+func (s *UploadSessionService) AbortSession(ctx context.Context, userID int, id string) error {
+	session, err := s.sessionRepo.GetSession(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.discard(ctx, session)
+}
+
+// CreateDirectUpload - 直接アップロード用の署名付きURLを発行
+// ブラウザは返された URL に Content-Type ヘッダーを付けて PUT し、その後 ConfirmDirectUpload を呼び出す
+func (s *UploadSessionService) CreateDirectUpload(ctx context.Context, userID int, workspaceID *int, filename, contentType string, size, quota int64) (*models.DirectUpload, string, error) {
+	if err := s.checkNewUpload(ctx, userID, workspaceID, filename, contentType, size, quota); err != nil {
+		return nil, "", err
+	}
+
+	urlExpiry := directUploadURLExpiry
+	if s.sessionTTL < urlExpiry {
+		urlExpiry = s.sessionTTL
+	}
+
+	upload := &models.DirectUpload{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		FileKey:      generateFileKey(userID, filename, s.uploadKeyPrefix(contentType)),
+		OriginalName: filename,
+		MimeType:     contentType,
+		FileSize:     size,
+		ExpiresAt:    time.Now().Add(s.sessionTTL),
+	}
+	uploadURL, err := s.objectStorage.GetPresignedUploadURL(ctx, upload.FileKey, contentType, urlExpiry)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.sessionRepo.CreateDirectUpload(upload); err != nil {
+		return nil, "", err
+	}
+
+	return upload, uploadURL, nil
+}
+
+// DirectUploadHeaders - 直接アップロードの署名付きURLへ送る必要のあるヘッダー（ストレージによって異なる）
+func (s *UploadSessionService) DirectUploadHeaders(contentType string) map[string]string {
+	return storage.PresignedUploadHeaders(s.objectStorage, contentType)
+}
+
+// ConfirmDirectUpload - 直接アップロードされたファイルを確認してファイルとして登録
+// ストレージ上のサイズと Content-Type が発行時の申告と異なる場合は、ファイルを削除して UPLOAD_VERIFICATION_FAILED を返す
+// ファイルの中身（先頭バイト）が申告した種類と一致しない場合は CONTENT_TYPE_MISMATCH を返す
+func (s *UploadSessionService) ConfirmDirectUpload(ctx context.Context, userID int, workspaceID *int, id string, quota int64) (*models.FileMetadata, string, error) {
+	upload, err := s.sessionRepo.GetDirectUpload(id, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	info, err := s.objectStorage.StatFile(ctx, upload.FileKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, "", apierror.NewConflict("UPLOAD_NOT_RECEIVED", "ファイルがまだアップロードされていません", err)
+		}
+		return nil, "", err
+	}
+
+	if err := checkUploadedFile(upload, info); err != nil {
+		s.discardDirectUploadQuietly(ctx, upload)
+		return nil, "", err
+	}
+	contentHash, err := s.inspectStoredObject(ctx, upload.FileKey, upload.MimeType)
+	if err != nil {
+		s.discardDirectUploadQuietly(ctx, upload)
+		return nil, "", err
+	}
+	if err := s.checkQuota(ctx, userID, workspaceID, info.Size, quota); err != nil {
+		s.discardDirectUploadQuietly(ctx, upload)
+		return nil, "", err
+	}
+
+	fileMeta := &models.FileMetadata{
+		UserID:       userID,
+		FileKey:      upload.FileKey,
+		BucketName:   s.objectStorage.GetBucketName(),
+		OriginalName: upload.OriginalName,
+		FileSize:     info.Size,
+		MimeType:     upload.MimeType,
+		FileType:     s.uploadFileType(upload.MimeType),
+		Status:       "active",
+		ContentHash:  contentHash,
+	}
+	s.probeStoredMedia(ctx, fileMeta)
+	reused, err := createFileReference(ctx, s.fileRepo, fileMeta)
+	if err != nil {
+		return nil, "", err
+	}
+	if reused {
+		// 同じ内容のファイルを保存済みのため、アップロードされたファイルと記録は削除する
+		s.discardDirectUploadQuietly(ctx, upload)
+	} else {
+		if err := s.fileRepo.Create(ctx, fileMeta); err != nil {
+			// アップロード済みのファイルは残し、確認をやり直せるようにする
+			return nil, "", fmt.Errorf("failed to save file metadata: %w", err)
+		}
+		if err := s.sessionRepo.DeleteDirectUpload(upload.ID); err != nil {
+			// ファイルは登録済みのため、記録は期限切れのクリーンアップに任せる
+			logctx.Printf(ctx, "failed to delete confirmed direct upload %s: %v", upload.ID, err)
+		}
+	}
+	publishFileUploaded(ctx, s.bus, fileMeta)
+
+	presignedURL, err := s.objectStorage.GetPresignedURL(ctx, fileMeta.FileKey, time.Duration(s.presignExpiry)*time.Second)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	return fileMeta, presignedURL, nil
+}
+
+// CleanupExpiredSessions - 期限切れの分割アップロードと確認されなかった直接アップロードを破棄し、破棄した件数を返す
+func (s *UploadSessionService) CleanupExpiredSessions(ctx context.Context) (int, error) {
+	sessions, err := s.sessionRepo.ListExpiredSessions(expiredUploadSessionBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	discarded := 0
+	for i := range sessions {
+		if err := s.discard(ctx, &sessions[i]); err != nil {
+			logctx.Printf(ctx, "failed to discard expired upload session %s: %v", sessions[i].ID, err)
+			continue
+		}
+		discarded++
+	}
+
+	uploads, err := s.sessionRepo.ListExpiredDirectUploads(expiredUploadSessionBatch)
+	if err != nil {
+		return discarded, err
+	}
+	for i := range uploads {
+		if err := s.discardDirectUpload(ctx, &uploads[i]); err != nil {
+			logctx.Printf(ctx, "failed to discard expired direct upload %s: %v", uploads[i].ID, err)
+			continue
+		}
+		discarded++
+	}
+	return discarded, nil
+}
+
+// discard - ストレージ側の分割アップロードを中止してセッションを削除
+func (s *UploadSessionService) discard(ctx context.Context, session *models.UploadSession) error {
+	if err := s.objectStorage.AbortMultipartUpload(ctx, session.FileKey, session.StorageUploadID); err != nil {
+		return err
+	}
+	return s.sessionRepo.DeleteSession(session.ID)
+}
+
+// discardDirectUpload - 直接アップロードされたファイル（未アップロードの場合は何もしない）と記録を削除
+// file_metadata に登録済みのファイルは記録のみ削除する
+func (s *UploadSessionService) discardDirectUpload(ctx context.Context, upload *models.DirectUpload) error {
+	if _, err := s.fileRepo.GetByFileKey(ctx, upload.FileKey); err != nil {
+		if !errors.Is(err, apierror.ErrNotFound) {
+			return err
+		}
+		if err := s.objectStorage.DeleteFile(ctx, upload.FileKey); err != nil {
+			return err
+		}
+	}
+	return s.sessionRepo.DeleteDirectUpload(upload.ID)
+}
+
+// discardDirectUploadQuietly - 検証に失敗した直接アップロードを破棄（失敗は期限切れのクリーンアップに任せる）
+func (s *UploadSessionService) discardDirectUploadQuietly(ctx context.Context, upload *models.DirectUpload) {
+	if err := s.discardDirectUpload(ctx, upload); err != nil {
+		logctx.Printf(ctx, "failed to discard direct upload %s: %v", upload.ID, err)
+	}
+}
+
+// checkNewUpload - アップロードを始める前にファイル名・サイズ・種類・ストレージクォータを確認
+func (s *UploadSessionService) checkNewUpload(ctx context.Context, userID int, workspaceID *int, filename, contentType string, size, quota int64) error {
+	if filename == "" {
+		return apierror.NewValidationError("INVALID_FILENAME", "ファイル名を指定してください", nil)
+	}
+	if size <= 0 {
+		return apierror.NewValidationError("INVALID_UPLOAD_SIZE", "ファイルサイズを指定してください", nil)
+	}
+	maxFileSize := s.maxFileSize
+	switch {
+	case s.allowedTypes.Allowed(mimetype.Video, contentType):
+		maxFileSize = min(maxFileSize, s.maxVideoFileSize)
+	case s.allowedTypes.Allowed(mimetype.Audio, contentType):
+		maxFileSize = min(maxFileSize, s.maxAudioFileSize)
+	}
+	if size > maxFileSize {
+		return apierror.NewPayloadTooLarge(
+			"FILE_TOO_LARGE", fmt.Sprintf("ファイルサイズが上限（%d バイト）を超えています", maxFileSize), nil,
+		)
+	}
+	if !s.allowedTypes.Allowed(mimetype.Document, contentType) &&
+		!s.allowedTypes.Allowed(mimetype.Video, contentType) &&
+		!s.allowedTypes.Allowed(mimetype.Audio, contentType) {
+		return apierror.NewValidationError(
+			"UNSUPPORTED_FILE_TYPE", "この種類のファイルはアップロードできません", fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType),
+		)
+	}
+	return s.checkQuota(ctx, userID, workspaceID, size, quota)
+}
+
+// probeStoredMedia - ストレージに保存された動画・音声の再生時間と映像の大きさを取得（それ以外は何もしない）
+func (s *UploadSessionService) probeStoredMedia(ctx context.Context, fileMeta *models.FileMetadata) {
+	if fileMeta.FileType != "video" && fileMeta.FileType != "audio" {
+		return
+	}
+	reader := &objectReaderAt{ctx: ctx, objectStorage: s.objectStorage, fileKey: fileMeta.FileKey}
+	probeMedia(ctx, s.mediaProber, reader, fileMeta.FileSize, fileMeta)
+}
+
+// uploadFileType - 分割・直接アップロードしたファイルの種類（動画は "video"、音声は "audio"、それ以外は "file"）
+func (s *UploadSessionService) uploadFileType(contentType string) string {
+	switch {
+	case s.allowedTypes.Allowed(mimetype.Video, contentType):
+		return "video"
+	case s.allowedTypes.Allowed(mimetype.Audio, contentType):
+		return "audio"
+	default:
+		return "file"
+	}
+}
+
+// uploadKeyPrefix - 分割・直接アップロードしたファイルのキーの先頭
+func (s *UploadSessionService) uploadKeyPrefix(contentType string) string {
+	switch {
+	case s.allowedTypes.Allowed(mimetype.Video, contentType):
+		return "videos"
+	case s.allowedTypes.Allowed(mimetype.Audio, contentType):
+		return "audio"
+	default:
+		return "files"
+	}
+}
+
+// inspectStoredObject - ストレージに保存されたファイルを読み込み、先頭バイトから判定した MIME タイプを申告と照合して内容の SHA-256 を返す
+func (s *UploadSessionService) inspectStoredObject(ctx context.Context, fileKey, contentType string) (string, error) {
+	object, err := s.objectStorage.GetObject(ctx, fileKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	defer object.Close()
+
+	hasher := sha256.New()
+	detected, err := detectContentType(io.TeeReader(object, hasher))
+	if err != nil {
+		return "", err
+	}
+	if !contentTypeMatches(contentType, detected) {
+		return "", apierror.NewValidationError(
+			"CONTENT_TYPE_MISMATCH",
+			"ファイルの中身が指定された種類と一致しません",
+			fmt.Errorf("%w: declared %s, detected %s", ErrContentTypeMismatch, contentType, detected),
+		)
+	}
+
+	if _, err := io.Copy(hasher, object); err != nil {
+		return "", fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// checkQuota - ストレージクォータを確認（quota は既定値で、ユーザーに個別のクォータが設定されている場合はそちらを使う）
+// workspaceID が nil でない場合は、ワークスペースにアップロードできる役割か（guest は不可）と、
+// ワークスペースのストレージクォータ（メンバー全員の合計）も確認する
+func (s *UploadSessionService) checkQuota(ctx context.Context, userID int, workspaceID *int, size, quota int64) error {
+	if err := requireWorkspaceEditor(s.workspaces, workspaceID, userID); err != nil {
+		return err
+	}
+
+	quota, err := resolveStorageQuota(ctx, s.fileRepo, userID, quota)
+	if err != nil {
+		return err
+	}
+
+	usage, err := s.fileRepo.GetUserStorageUsage(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user storage usage: %w", err)
+	}
+	if usage.TotalBytes+size > quota {
+		return apierror.NewPayloadTooLarge("QUOTA_EXCEEDED", "ストレージ容量の上限を超えています", ErrStorageQuotaExceeded)
+	}
+
+	if workspaceID != nil && s.workspaces != nil {
+		if err := s.workspaces.CheckStorageQuota(*workspaceID, size); err != nil {
+			if errors.Is(err, ErrWorkspaceStorageQuotaExceeded) {
+				return apierror.NewPayloadTooLarge("WORKSPACE_QUOTA_EXCEEDED", "ワークスペースのストレージ容量の上限を超えています", err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// currentOffset - 競合後の最新のオフセットを取得（取得できない場合は手元の値）
+func (s *UploadSessionService) currentOffset(session *models.UploadSession) int64 {
+	latest, err := s.sessionRepo.GetSession(session.ID, session.UserID)
+	if err != nil {
+		return session.Offset
+	}
+	return latest.Offset
+}
+
+// checkUploadChunk - チャンクの位置とサイズを検証
+// 最後のチャンク以外はストレージのパートの最小サイズ以上である必要がある
+func checkUploadChunk(session *models.UploadSession, offset, size int64) error {
+	if offset != session.Offset {
+		return offsetMismatchError(session.Offset, nil)
+	}
+	if size <= 0 {
+		return apierror.NewValidationError("INVALID_CHUNK_SIZE", "チャンクが空です", nil)
+	}
+	if size > models.MaxUploadChunkSize {
+		return apierror.NewPayloadTooLarge(
+			"CHUNK_TOO_LARGE", fmt.Sprintf("チャンクが大きすぎます（上限 %d バイト）", models.MaxUploadChunkSize), nil,
+		)
+	}
+	if offset+size > session.TotalSize {
+		return apierror.NewValidationError("INVALID_CHUNK_SIZE", "チャンクがファイルサイズを超えています", nil)
+	}
+	if offset+size < session.TotalSize && size < models.MinUploadChunkSize {
+		return apierror.NewValidationError(
+			"INVALID_CHUNK_SIZE", fmt.Sprintf("最後以外のチャンクは %d バイト以上にしてください", models.MinUploadChunkSize), nil,
+		)
+	}
+	if offset+size < session.TotalSize && session.PartCount+1 >= models.MaxUploadParts {
+		return apierror.NewValidationError("TOO_MANY_CHUNKS", "チャンク数が上限を超えています。チャンクを大きくしてください", nil)
+	}
+	return nil
+}
+
+// offsetMismatchError - 送信されたオフセットが受信済みサイズと一致しない場合のエラー
+func offsetMismatchError(current int64, cause error) *apierror.AppError {
+	return apierror.NewConflict(
+		"UPLOAD_OFFSET_MISMATCH",
+		fmt.Sprintf("オフセットが一致しません（受信済み %d バイト）", current),
+		cause,
+	).WithDetails(map[string]int64{"offset": current})
+}
+
+// checkUploadedFile - 直接アップロードされたファイルのサイズと Content-Type を発行時の申告と照合
+func checkUploadedFile(upload *models.DirectUpload, info *storage.FileInfo) error {
+	if info.Size != upload.FileSize {
+		return apierror.NewValidationError(
+			"UPLOAD_VERIFICATION_FAILED",
+			fmt.Sprintf("アップロードされたファイルのサイズが一致しません（%d / %d バイト）", info.Size, upload.FileSize),
+			nil,
+		)
+	}
+	if !strings.EqualFold(strings.TrimSpace(strings.Split(info.ContentType, ";")[0]), upload.MimeType) {
+		return apierror.NewValidationError(
+			"UPLOAD_VERIFICATION_FAILED",
+			fmt.Sprintf("アップロードされたファイルの種類が一致しません（%s）", info.ContentType),
+			nil,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/storage"
+)
+
+func TestCheckUploadChunk(t *testing.T) {
+	const total = 3 * models.MinUploadChunkSize
+	tests := []struct {
+		name     string
+		session  models.UploadSession
+		offset   int64
+		size     int64
+		wantCode string
+		status   int
+	}{
+		{name: "正常系：最初のチャンク", offset: 0, size: models.MinUploadChunkSize},
+		{name: "正常系：最後のチャンクは最小サイズ未満でもよい", session: models.UploadSession{Offset: total - 10}, offset: total - 10, size: 10},
+		{name: "異常系：オフセットの不一致", session: models.UploadSession{Offset: models.MinUploadChunkSize}, offset: 0, size: models.MinUploadChunkSize, wantCode: "UPLOAD_OFFSET_MISMATCH", status: http.StatusConflict},
+		{name: "異常系：空のチャンク", offset: 0, size: 0, wantCode: "INVALID_CHUNK_SIZE", status: http.StatusBadRequest},
+		{name: "異常系：最後以外のチャンクが小さすぎる", offset: 0, size: 1024, wantCode: "INVALID_CHUNK_SIZE", status: http.StatusBadRequest},
+		{name: "異常系：ファイルサイズを超える", session: models.UploadSession{Offset: total - 10}, offset: total - 10, size: 11, wantCode: "INVALID_CHUNK_SIZE", status: http.StatusBadRequest},
+		{name: "異常系：チャンクの上限超過", session: models.UploadSession{TotalSize: 2 * models.MaxUploadChunkSize}, offset: 0, size: models.MaxUploadChunkSize + 1, wantCode: "CHUNK_TOO_LARGE", status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := tt.session
+			if session.TotalSize == 0 {
+				session.TotalSize = total
+			}
+			err := checkUploadChunk(&session, tt.offset, tt.size)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("checkUploadChunk() error = %v", err)
+				}
+				return
+			}
+			assertAppErrorCode(t, err, tt.status, tt.wantCode)
+		})
+	}
+}
+
+func TestCheckUploadedFile(t *testing.T) {
+	upload := &models.DirectUpload{MimeType: "application/pdf", FileSize: 1024}
+	tests := []struct {
+		name    string
+		info    storage.FileInfo
+		wantErr bool
+	}{
+		{name: "正常系：申告どおり", info: storage.FileInfo{Size: 1024, ContentType: "application/pdf"}},
+		{name: "正常系：パラメータ付きの Content-Type", info: storage.FileInfo{Size: 1024, ContentType: "Application/PDF; charset=binary"}},
+		{name: "異常系：サイズが異なる", info: storage.FileInfo{Size: 2048, ContentType: "application/pdf"}, wantErr: true},
+		{name: "異常系：種類が異なる", info: storage.FileInfo{Size: 1024, ContentType: "text/html"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkUploadedFile(upload, &tt.info)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("checkUploadedFile() error = %v", err)
+				}
+				return
+			}
+			assertAppErrorCode(t, err, http.StatusBadRequest, "UPLOAD_VERIFICATION_FAILED")
+		})
+	}
+}
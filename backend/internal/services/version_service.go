@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// VersionService - 文書バージョンの保存と差分計算を担当するサービス
+type VersionService struct {
+	documentRepo DocumentCoreRepositoryInterface
+	blockRepo    BlockRepositoryInterface
+	versionRepo  DocumentVersionRepositoryInterface
+}
+
+// NewVersionService - VersionServiceを初期化
+func NewVersionService(
+	documentRepo DocumentCoreRepositoryInterface,
+	blockRepo BlockRepositoryInterface,
+	versionRepo DocumentVersionRepositoryInterface,
+) *VersionService {
+	return &VersionService{
+		documentRepo: documentRepo,
+		blockRepo:    blockRepo,
+		versionRepo:  versionRepo,
+	}
+}
+
+// CreateSnapshot - 文書の現在状態（タイトル・本文・ブロック）を新しいバージョンとして保存
+func (s *VersionService) CreateSnapshot(docID, userID int) (*models.DocumentVersion, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.blockRepo.GetBlocksByDocumentID(docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocks: %w", err)
+	}
+
+	version := &models.DocumentVersion{
+		DocumentID: docID,
+		Title:      doc.Title,
+		Content:    doc.Content,
+		Blocks:     blocks,
+		CreatedBy:  &userID,
+	}
+	if err := s.versionRepo.CreateVersion(version); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// VersionPruneResult - 古いバージョンの整理の結果
+type VersionPruneResult struct {
+	Merged  int // 間引いた（同じ区間の後の版にまとめた）版の数
+	Expired int // 保持期間を過ぎて削除した版の数
+}
+
+// PruneVersions - 保存のたびに記録する版を整理する（文書の最新の版は編集の基準にするため常に残す）
+// mergeAfter を過ぎた版は文書・作成者・mergeWindow の区間ごとに最後の版だけを残し、retention を過ぎた版は削除する
+// 作成から mergeAfter 以内の版は、編集中のクライアントの baseVersion になりうるため間引かない（0 の場合は処理しない）
+func (s *VersionService) PruneVersions(mergeAfter, mergeWindow, retention time.Duration) (VersionPruneResult, error) {
+	var result VersionPruneResult
+	now := time.Now()
+
+	if mergeAfter > 0 && mergeWindow >= time.Second {
+		merged, err := s.versionRepo.MergeVersions(now.Add(-mergeAfter), mergeWindow)
+		if err != nil {
+			return result, err
+		}
+		result.Merged = merged
+	}
+
+	if retention > 0 {
+		expired, err := s.versionRepo.DeleteVersionsBefore(now.Add(-retention))
+		if err != nil {
+			return result, err
+		}
+		result.Expired = expired
+	}
+
+	return result, nil
+}
+
+// ListVersions - 文書のバージョン一覧を取得
+func (s *VersionService) ListVersions(docID, userID int) ([]models.DocumentVersion, error) {
+	if _, err := s.documentRepo.GetDocument(docID, userID); err != nil {
+		return nil, err
+	}
+	return s.versionRepo.ListVersions(docID)
+}
+
+// LatestVersion - 文書の最新のバージョン番号を返す（バージョンがない場合は 0）
+// 編集を始めるときに取得し、保存時に baseVersion として送ると同時編集をマージできる
+func (s *VersionService) LatestVersion(docID int) (int, error) {
+	versions, err := s.versionRepo.ListVersions(docID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return 0, nil
+	}
+	return versions[0].VersionNumber, nil
+}
+
+// MergeWithBase - 基準バージョン以降の他の保存（現在の文書）と、送信された内容を3方向マージする
+// 基準バージョン以降に変更がない場合は送信された内容をそのまま返す。ブロックのマージは mergeBlocks を参照
+// タイトル・本文は送信側が変更していなければ現在の値を残し、双方が変更した場合は送信された値を優先して競合として返す
+// 基準バージョンが古い版の整理（PruneVersions）で削除されている場合は、それ以前で残っている最も新しい版を基準にする
+// 保存は DocumentService.UpdateMergedDocument で行い、マージ後に他の保存があった場合はマージし直す
+func (s *VersionService) MergeWithBase(docID, userID, baseVersion int, title, content string, blocks []models.Block) (*models.MergedDocument, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.LatestVersion(docID)
+	if err != nil {
+		return nil, err
+	}
+	if baseVersion < 1 || baseVersion > latest {
+		return nil, apierror.NewValidationError("INVALID_BASE_VERSION",
+			fmt.Sprintf("バージョン %d は存在しません", baseVersion), nil)
+	}
+
+	base, err := s.versionRepo.GetVersionAtOrBefore(docID, baseVersion)
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			// 基準バージョン以前の版がすべて削除されている（マージできないため、文書を読み込み直してもらう）
+			return nil, apierror.NewConflict("BASE_VERSION_EXPIRED",
+				fmt.Sprintf("バージョン %d は保存期間を過ぎたため削除されました。文書を読み込み直してください", baseVersion), err)
+		}
+		return nil, fmt.Errorf("failed to get base version: %w", err)
+	}
+
+	current, err := s.blockRepo.GetBlocksByDocumentID(docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocks: %w", err)
+	}
+
+	merged := &models.MergedDocument{
+		Merge:   models.DocumentMerge{BaseVersion: base.VersionNumber, Conflicts: make([]models.MergeConflict, 0)},
+		Current: models.DocumentContent{Title: doc.Title, Content: doc.Content, Blocks: current},
+	}
+	merged.Title = mergeField(base.Title, doc.Title, title, "title", &merged.Merge)
+	merged.Content = mergeField(base.Content, doc.Content, content, "content", &merged.Merge)
+
+	merged.Blocks = blocks
+	if !equalKeys(blockSignatures(base.Blocks), blockSignatures(current)) {
+		// 送信されたブロックは Position の順に並べてから比べる（一括保存は一覧の順ではなく Position で並ぶ）
+		sorted := make([]models.Block, len(blocks))
+		copy(sorted, blocks)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Position < sorted[j].Position })
+
+		var conflicts []models.MergeConflict
+		merged.Blocks, conflicts = mergeBlocks(base.Blocks, current, sorted)
+		merged.Merge.Merged = true
+		merged.Merge.Conflicts = append(merged.Merge.Conflicts, conflicts...)
+	}
+
+	return merged, nil
+}
+
+// mergeField - タイトル・本文を3方向マージする（双方が異なる値に変更した場合は送信された値を優先し、競合として記録）
+func mergeField(base, current, incoming, field string, merge *models.DocumentMerge) string {
+	if current == base || current == incoming {
+		return incoming
+	}
+	merge.Merged = true
+	if incoming == base {
+		return current
+	}
+	merge.Conflicts = append(merge.Conflicts, models.MergeConflict{Field: field})
+	return incoming
+}
+
+// DiffVersions - 2つのバージョン間のブロック差分を計算
+// toVersion が nil の場合は現在の文書と比較する
+func (s *VersionService) DiffVersions(docID, userID, fromVersion int, toVersion *int) (*models.DocumentDiff, error) {
+	doc, err := s.documentRepo.GetDocument(docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.versionRepo.GetVersion(docID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	toTitle := doc.Title
+	var toBlocks []models.Block
+	if toVersion != nil {
+		to, err := s.versionRepo.GetVersion(docID, *toVersion)
+		if err != nil {
+			return nil, err
+		}
+		toTitle = to.Title
+		toBlocks = to.Blocks
+	} else {
+		toBlocks, err = s.blockRepo.GetBlocksByDocumentID(docID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blocks: %w", err)
+		}
+	}
+
+	changes := diffBlocks(from.Blocks, toBlocks)
+	diff := &models.DocumentDiff{
+		DocumentID:   docID,
+		FromVersion:  fromVersion,
+		ToVersion:    toVersion,
+		TitleChanged: from.Title != toTitle,
+		FromTitle:    from.Title,
+		ToTitle:      toTitle,
+		Changes:      changes,
+	}
+	for _, c := range changes {
+		switch c.Change {
+		case models.BlockAdded:
+			diff.Summary.Added++
+		case models.BlockRemoved:
+			diff.Summary.Removed++
+		case models.BlockChanged:
+			diff.Summary.Changed++
+		}
+	}
+
+	return diff, nil
+}
+
+// diffBlocks - ブロック列の差分を計算
+// ブロックIDは保存のたびに振り直されるため、type + content の一致で同一ブロックとみなす。
+// matchBlocks（最長共通部分列、大きすぎる場合は近似）で一致しなかった区間では、削除と追加を先頭から対応付けて changed とする。
+func diffBlocks(before, after []models.Block) []models.BlockChange {
+	match := matchBlocks(blockSignatures(before), blockSignatures(after))
+
+	changes := make([]models.BlockChange, 0)
+	var deleted, inserted []models.Block
+
+	// 一致区間に達するたびに、溜めた削除/追加を changed/removed/added に変換する
+	flush := func() {
+		paired := len(deleted)
+		if len(inserted) < paired {
+			paired = len(inserted)
+		}
+		for k := 0; k < paired; k++ {
+			changes = append(changes, models.BlockChange{
+				Change: models.BlockChanged,
+				Before: toBlockSnapshot(deleted[k]),
+				After:  toBlockSnapshot(inserted[k]),
+			})
+		}
+		for _, b := range deleted[paired:] {
+			changes = append(changes, models.BlockChange{Change: models.BlockRemoved, Before: toBlockSnapshot(b)})
+		}
+		for _, b := range inserted[paired:] {
+			changes = append(changes, models.BlockChange{Change: models.BlockAdded, After: toBlockSnapshot(b)})
+		}
+		deleted, inserted = nil, nil
+	}
+
+	j := 0
+	for i, b := range before {
+		if match[i] < 0 {
+			deleted = append(deleted, b)
+			continue
+		}
+		inserted = append(inserted, after[j:match[i]]...)
+		flush()
+		j = match[i] + 1
+	}
+	inserted = append(inserted, after[j:]...)
+	flush()
+
+	return changes
+}
+
+// blockSignature - ブロックの比較キー（type + 正規化済み content）を生成
+func blockSignature(b models.Block) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b.Content); err != nil {
+		return b.Type + "\x00" + string(b.Content)
+	}
+	return b.Type + "\x00" + buf.String()
+}
+
+// toBlockSnapshot - 差分表示用のブロック情報に変換
+func toBlockSnapshot(b models.Block) *models.BlockSnapshot {
+	return &models.BlockSnapshot{
+		Type:     b.Type,
+		Content:  b.Content,
+		Position: b.Position,
+	}
+}
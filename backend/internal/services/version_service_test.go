@@ -0,0 +1,388 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/models"
+)
+
+// MockDocumentVersionRepository - DocumentVersionRepositoryのモック
+type MockDocumentVersionRepository struct {
+	CreateVersionFunc        func(version *models.DocumentVersion) error
+	GetVersionFunc           func(docID, versionNumber int) (*models.DocumentVersion, error)
+	GetVersionAtOrBeforeFunc func(docID, versionNumber int) (*models.DocumentVersion, error)
+	ListVersionsFunc         func(docID int) ([]models.DocumentVersion, error)
+
+	MergeVersionsFunc        func(before time.Time, window time.Duration) (int, error)
+	DeleteVersionsBeforeFunc func(before time.Time) (int, error)
+}
+
+func (m *MockDocumentVersionRepository) CreateVersion(version *models.DocumentVersion) error {
+	if m.CreateVersionFunc != nil {
+		return m.CreateVersionFunc(version)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockDocumentVersionRepository) GetVersion(docID, versionNumber int) (*models.DocumentVersion, error) {
+	if m.GetVersionFunc != nil {
+		return m.GetVersionFunc(docID, versionNumber)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDocumentVersionRepository) GetVersionAtOrBefore(docID, versionNumber int) (*models.DocumentVersion, error) {
+	if m.GetVersionAtOrBeforeFunc != nil {
+		return m.GetVersionAtOrBeforeFunc(docID, versionNumber)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDocumentVersionRepository) ListVersions(docID int) ([]models.DocumentVersion, error) {
+	if m.ListVersionsFunc != nil {
+		return m.ListVersionsFunc(docID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockDocumentVersionRepository) MergeVersions(before time.Time, window time.Duration) (int, error) {
+	if m.MergeVersionsFunc != nil {
+		return m.MergeVersionsFunc(before, window)
+	}
+	return 0, errors.New("not implemented")
+}
+
+func (m *MockDocumentVersionRepository) DeleteVersionsBefore(before time.Time) (int, error) {
+	if m.DeleteVersionsBeforeFunc != nil {
+		return m.DeleteVersionsBeforeFunc(before)
+	}
+	return 0, errors.New("not implemented")
+}
+
+// textBlock - テスト用の段落ブロックを生成
+func textBlock(text string, position int) models.Block {
+	return models.Block{
+		Type:     "text",
+		Content:  json.RawMessage(`{"text":"` + text + `"}`),
+		Position: position,
+	}
+}
+
+// TestDiffBlocks - ブロック差分計算のテスト
+func TestDiffBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  []models.Block
+		after   []models.Block
+		want    []models.BlockChangeType
+		summary models.DiffSummary
+	}{
+		{
+			name:   "変更なし",
+			before: []models.Block{textBlock("a", 0), textBlock("b", 1)},
+			after:  []models.Block{textBlock("a", 0), textBlock("b", 1)},
+			want:   []models.BlockChangeType{},
+		},
+		{
+			name:   "末尾にブロック追加",
+			before: []models.Block{textBlock("a", 0)},
+			after:  []models.Block{textBlock("a", 0), textBlock("b", 1)},
+			want:   []models.BlockChangeType{models.BlockAdded},
+		},
+		{
+			name:   "先頭のブロック削除",
+			before: []models.Block{textBlock("a", 0), textBlock("b", 1)},
+			after:  []models.Block{textBlock("b", 0)},
+			want:   []models.BlockChangeType{models.BlockRemoved},
+		},
+		{
+			name:   "中間ブロックの内容変更",
+			before: []models.Block{textBlock("a", 0), textBlock("b", 1), textBlock("c", 2)},
+			after:  []models.Block{textBlock("a", 0), textBlock("B", 1), textBlock("c", 2)},
+			want:   []models.BlockChangeType{models.BlockChanged},
+		},
+		{
+			name:   "変更と追加の混在",
+			before: []models.Block{textBlock("a", 0), textBlock("b", 1)},
+			after:  []models.Block{textBlock("A", 0), textBlock("x", 1), textBlock("b", 2)},
+			want:   []models.BlockChangeType{models.BlockChanged, models.BlockAdded},
+		},
+		{
+			name:   "空白の違いは同一とみなす",
+			before: []models.Block{{Type: "text", Content: json.RawMessage(`{"text": "a"}`)}},
+			after:  []models.Block{{Type: "text", Content: json.RawMessage(`{"text":"a"}`)}},
+			want:   []models.BlockChangeType{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffBlocks(tt.before, tt.after)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("diffBlocks() returned %d changes, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, c := range got {
+				if c.Change != tt.want[i] {
+					t.Errorf("changes[%d] = %s, want %s", i, c.Change, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestDiffVersions - バージョン間差分取得のテスト
+func TestDiffVersions(t *testing.T) {
+	toVersion := 2
+
+	tests := []struct {
+		name        string
+		toVersion   *int
+		setupMocks  func(*MockDocumentCoreRepository, *MockBlockRepository, *MockDocumentVersionRepository)
+		wantErrType error
+		wantSummary models.DiffSummary
+		wantTitle   bool
+	}{
+		{
+			name:      "正常系：現在の文書との比較",
+			toVersion: nil,
+			setupMocks: func(docRepo *MockDocumentCoreRepository, blockRepo *MockBlockRepository, versionRepo *MockDocumentVersionRepository) {
+				docRepo.GetDocumentFunc = func(docID, userID int) (*models.Document, error) {
+					return &models.Document{ID: docID, UserID: userID, Title: "新タイトル"}, nil
+				}
+				versionRepo.GetVersionFunc = func(docID, versionNumber int) (*models.DocumentVersion, error) {
+					return &models.DocumentVersion{Title: "旧タイトル", Blocks: []models.Block{textBlock("a", 0)}}, nil
+				}
+				blockRepo.GetBlocksByDocumentIDFunc = func(docID int) ([]models.Block, error) {
+					return []models.Block{textBlock("a", 0), textBlock("b", 1)}, nil
+				}
+			},
+			wantSummary: models.DiffSummary{Added: 1},
+			wantTitle:   true,
+		},
+		{
+			name:      "正常系：バージョン同士の比較",
+			toVersion: &toVersion,
+			setupMocks: func(docRepo *MockDocumentCoreRepository, blockRepo *MockBlockRepository, versionRepo *MockDocumentVersionRepository) {
+				docRepo.GetDocumentFunc = func(docID, userID int) (*models.Document, error) {
+					return &models.Document{ID: docID, UserID: userID, Title: "タイトル"}, nil
+				}
+				versionRepo.GetVersionFunc = func(docID, versionNumber int) (*models.DocumentVersion, error) {
+					if versionNumber == 1 {
+						return &models.DocumentVersion{Title: "タイトル", Blocks: []models.Block{textBlock("a", 0)}}, nil
+					}
+					return &models.DocumentVersion{Title: "タイトル", Blocks: []models.Block{textBlock("b", 0)}}, nil
+				}
+			},
+			wantSummary: models.DiffSummary{Changed: 1},
+		},
+		{
+			name:      "異常系：バージョンが存在しない（404）",
+			toVersion: nil,
+			setupMocks: func(docRepo *MockDocumentCoreRepository, blockRepo *MockBlockRepository, versionRepo *MockDocumentVersionRepository) {
+				docRepo.GetDocumentFunc = func(docID, userID int) (*models.Document, error) {
+					return &models.Document{ID: docID, UserID: userID}, nil
+				}
+				versionRepo.GetVersionFunc = func(docID, versionNumber int) (*models.DocumentVersion, error) {
+					return nil, apierror.ErrNotFound
+				}
+			},
+			wantErrType: apierror.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := &MockDocumentCoreRepository{}
+			blockRepo := &MockBlockRepository{}
+			versionRepo := &MockDocumentVersionRepository{}
+			tt.setupMocks(docRepo, blockRepo, versionRepo)
+
+			service := NewVersionService(docRepo, blockRepo, versionRepo)
+
+			diff, err := service.DiffVersions(1, 10, 1, tt.toVersion)
+
+			if tt.wantErrType != nil {
+				if !errors.Is(err, tt.wantErrType) {
+					t.Errorf("DiffVersions() error = %v, want %v", err, tt.wantErrType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DiffVersions() unexpected error: %v", err)
+			}
+			if diff.Summary != tt.wantSummary {
+				t.Errorf("DiffVersions() summary = %+v, want %+v", diff.Summary, tt.wantSummary)
+			}
+			if diff.TitleChanged != tt.wantTitle {
+				t.Errorf("DiffVersions() titleChanged = %v, want %v", diff.TitleChanged, tt.wantTitle)
+			}
+		})
+	}
+}
+
+// TestMergeWithBase - 基準バージョンとの3方向マージのテスト
+func TestMergeWithBase(t *testing.T) {
+	newService := func(current []models.Block) *VersionService {
+		docRepo := &MockDocumentCoreRepository{
+			GetDocumentFunc: func(docID, userID int) (*models.Document, error) {
+				return &models.Document{ID: docID, UserID: userID, Title: "他のタブのタイトル", Content: "本文"}, nil
+			},
+		}
+		blockRepo := &MockBlockRepository{
+			GetBlocksByDocumentIDFunc: func(docID int) ([]models.Block, error) {
+				return current, nil
+			},
+		}
+		// 最新は 5。古い版の整理で 1・2・4 は削除され、3 と 5 が残っている
+		versionRepo := &MockDocumentVersionRepository{
+			ListVersionsFunc: func(docID int) ([]models.DocumentVersion, error) {
+				return []models.DocumentVersion{{VersionNumber: 5}, {VersionNumber: 3}}, nil
+			},
+			GetVersionAtOrBeforeFunc: func(docID, versionNumber int) (*models.DocumentVersion, error) {
+				switch {
+				case versionNumber >= 5:
+					return &models.DocumentVersion{VersionNumber: 5, Title: "他のタブのタイトル", Content: "本文", Blocks: current}, nil
+				case versionNumber >= 3:
+					return &models.DocumentVersion{VersionNumber: 3, Title: "タイトル", Content: "本文", Blocks: textBlocks("a", "b")}, nil
+				}
+				return nil, apierror.ErrNotFound
+			},
+		}
+		return NewVersionService(docRepo, blockRepo, versionRepo)
+	}
+
+	t.Run("正常系：他の保存の変更を取り込む", func(t *testing.T) {
+		service := newService(textBlocks("a", "b", "c"))
+
+		merged, err := service.MergeWithBase(1, 10, 3, "タイトル", "本文", textBlocks("a2", "b"))
+		if err != nil {
+			t.Fatalf("MergeWithBase() unexpected error: %v", err)
+		}
+		if merged.Title != "他のタブのタイトル" {
+			t.Errorf("Title = %q, want current title", merged.Title)
+		}
+		if got, want := blockTexts(t, merged.Blocks), blockTexts(t, textBlocks("a2", "b", "c")); !equalKeys(got, want) {
+			t.Errorf("Blocks = %v, want %v", got, want)
+		}
+		if !merged.Merge.Merged || len(merged.Merge.Conflicts) != 0 {
+			t.Errorf("Merge = %+v, want merged without conflicts", merged.Merge)
+		}
+	})
+
+	t.Run("正常系：他の保存がない場合は送信された内容のまま", func(t *testing.T) {
+		service := newService(textBlocks("a", "b"))
+		incoming := textBlocks("b", "a")
+
+		merged, err := service.MergeWithBase(1, 10, 3, "他のタブのタイトル", "本文", incoming)
+		if err != nil {
+			t.Fatalf("MergeWithBase() unexpected error: %v", err)
+		}
+		if merged.Merge.Merged {
+			t.Errorf("Merged = true, want false")
+		}
+		if got, want := blockTexts(t, merged.Blocks), blockTexts(t, incoming); !equalKeys(got, want) {
+			t.Errorf("Blocks = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("正常系：双方がタイトルを変更した場合は送信側を優先して競合を返す", func(t *testing.T) {
+		service := newService(textBlocks("a", "b"))
+
+		merged, err := service.MergeWithBase(1, 10, 3, "自分のタイトル", "本文", textBlocks("a", "b"))
+		if err != nil {
+			t.Fatalf("MergeWithBase() unexpected error: %v", err)
+		}
+		if merged.Title != "自分のタイトル" {
+			t.Errorf("Title = %q, want incoming title", merged.Title)
+		}
+		if len(merged.Merge.Conflicts) != 1 || merged.Merge.Conflicts[0].Field != "title" {
+			t.Errorf("Conflicts = %+v, want title conflict", merged.Merge.Conflicts)
+		}
+	})
+
+	t.Run("正常系：基準バージョンが削除されている場合はそれ以前に残っている版を基準にする", func(t *testing.T) {
+		service := newService(textBlocks("a", "b", "c"))
+
+		merged, err := service.MergeWithBase(1, 10, 4, "タイトル", "本文", textBlocks("a2", "b"))
+		if err != nil {
+			t.Fatalf("MergeWithBase() unexpected error: %v", err)
+		}
+		if merged.Merge.BaseVersion != 3 {
+			t.Errorf("BaseVersion = %d, want 3", merged.Merge.BaseVersion)
+		}
+		if got, want := blockTexts(t, merged.Blocks), blockTexts(t, textBlocks("a2", "b", "c")); !equalKeys(got, want) {
+			t.Errorf("Blocks = %v, want %v", got, want)
+		}
+		if merged.Current.Title != "他のタブのタイトル" || len(merged.Current.Blocks) != 3 {
+			t.Errorf("Current = %+v, want document at merge time", merged.Current)
+		}
+	})
+
+	t.Run("異常系：基準バージョン以前の版がすべて削除されている場合は競合を返す", func(t *testing.T) {
+		service := newService(nil)
+
+		_, err := service.MergeWithBase(1, 10, 2, "タイトル", "本文", nil)
+		var appErr *apierror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != "BASE_VERSION_EXPIRED" || appErr.HTTPStatus != http.StatusConflict {
+			t.Errorf("MergeWithBase() error = %v, want 409 BASE_VERSION_EXPIRED", err)
+		}
+	})
+
+	t.Run("異常系：基準バージョンが存在しない", func(t *testing.T) {
+		service := newService(nil)
+
+		_, err := service.MergeWithBase(1, 10, 99, "タイトル", "本文", nil)
+		var appErr *apierror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_BASE_VERSION" {
+			t.Errorf("MergeWithBase() error = %v, want INVALID_BASE_VERSION", err)
+		}
+	})
+}
+
+// TestPruneVersions - 古いバージョンの間引きと削除のテスト
+func TestPruneVersions(t *testing.T) {
+	t.Run("正常系：期間を過ぎた版を間引いてから保持期間を過ぎた版を削除する", func(t *testing.T) {
+		var mergeBefore, deleteBefore time.Time
+		var mergeWindow time.Duration
+		repo := &MockDocumentVersionRepository{
+			MergeVersionsFunc: func(before time.Time, window time.Duration) (int, error) {
+				mergeBefore, mergeWindow = before, window
+				return 5, nil
+			},
+			DeleteVersionsBeforeFunc: func(before time.Time) (int, error) {
+				deleteBefore = before
+				return 2, nil
+			},
+		}
+		service := NewVersionService(nil, nil, repo)
+
+		result, err := service.PruneVersions(24*time.Hour, time.Hour, 90*24*time.Hour)
+		if err != nil {
+			t.Fatalf("PruneVersions() error = %v", err)
+		}
+		if result.Merged != 5 || result.Expired != 2 {
+			t.Errorf("PruneVersions() = %+v, want merged 5 and expired 2", result)
+		}
+		if since := time.Since(mergeBefore); since < 24*time.Hour || since > 24*time.Hour+time.Minute || mergeWindow != time.Hour {
+			t.Errorf("MergeVersions(%v, %v), want 1 day ago and 1 hour", mergeBefore, mergeWindow)
+		}
+		if since := time.Since(deleteBefore); since < 90*24*time.Hour || since > 90*24*time.Hour+time.Minute {
+			t.Errorf("DeleteVersionsBefore(%v), want 90 days ago", deleteBefore)
+		}
+	})
+
+	t.Run("正常系：0 の期間は処理しない", func(t *testing.T) {
+		service := NewVersionService(nil, nil, &MockDocumentVersionRepository{})
+
+		result, err := service.PruneVersions(0, time.Hour, 0)
+		if err != nil || result.Merged != 0 || result.Expired != 0 {
+			t.Errorf("PruneVersions() = %+v, %v, want no pruning", result, err)
+		}
+	})
+}
@@ -0,0 +1,472 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/events"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
+
+// Webhook の送信に付けるヘッダー
+const (
+	WebhookEventHeader     = "X-Webhook-Event"     // イベントの種類
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"  // 配信の ID（再送すると変わる。重複の判定にはイベントの id を使う）
+	WebhookTimestampHeader = "X-Webhook-Timestamp" // 署名した時刻（Unix 秒）
+	WebhookSignatureHeader = "X-Webhook-Signature" // "sha256=" + HMAC-SHA256(鍵, 時刻 + "." + 本文) の16進数
+)
+
+const (
+	// webhookMaxPerUser - 1人のユーザーが登録できる Webhook の数
+	webhookMaxPerUser = 20
+	// webhookMaxDescription - 説明の最大文字数
+	webhookMaxDescription = 255
+	// webhookSecretPrefix, webhookSecretBytes - 署名の鍵の形式（whsec_ + 32バイトの16進数）
+	webhookSecretPrefix = "whsec_"
+	webhookSecretBytes  = 32
+	// webhookDeliveryBatch - 1回の実行で送信する配信の最大件数
+	webhookDeliveryBatch = 50
+	// webhookDeliveryWorkers - 同時に送信する配信の数
+	webhookDeliveryWorkers = 4
+	// webhookClaimLease - 取得した配信を他のプロセスが取得しないようにする時間（送信中に停止した場合はその後に再試行する）
+	webhookClaimLease = 5 * time.Minute
+	// webhookMaxRetryDelay - 再試行の間隔の上限
+	webhookMaxRetryDelay = 6 * time.Hour
+	// webhookMaxResponseBody - 配信の記録に保存する応答の本文の最大バイト数
+	webhookMaxResponseBody = 1024
+)
+
+// errWebhookBlockedAddress - 送信先が内部ネットワークのアドレスだった場合のエラー
+var errWebhookBlockedAddress = errors.New("webhook target resolves to a non-public address")
+
+// WebhookOptions - Webhook の送信の設定
+type WebhookOptions struct {
+	MaxAttempts          int           // 1つの配信の送信試行回数（超えると failed）
+	RetryDelay           time.Duration // 最初の再試行までの待ち時間（以降は倍にしていく）
+	Timeout              time.Duration // 1回の送信のタイムアウト
+	AllowPrivateNetworks bool          // ループバック・プライベートアドレスへの送信を許可する（開発用）
+}
+
+// WebhookDeliveryResult - 配信の実行結果
+type WebhookDeliveryResult struct {
+	Succeeded int // 2xx の応答を受け取った配信の件数
+	Retrying  int // 失敗し、後で再試行する配信の件数
+	Failed    int // 試行回数の上限まで失敗した配信の件数
+}
+
+// WebhookService - Webhook の登録と、イベントの配信を担当するサービス
+//
+// イベントバスから受け取ったイベントは、購読している Webhook ごとに配信として記録するだけで、
+// 送信はバックグラウンドジョブの DeliverDue で行う（リクエストの処理を送信先の応答で遅らせない）。
+// 送信は HMAC-SHA256 で署名し、2xx 以外の応答・接続の失敗は間隔を倍にしながら再試行する。
+// LinkPreviewService と同じく、既定では内部ネットワークのアドレスには送信しない（SSRF を防ぐ）
+type WebhookService struct {
+	webhookRepo WebhookRepositoryInterface
+	client      *http.Client
+	opts        WebhookOptions
+}
+
+// NewWebhookService - WebhookServiceを初期化
+func NewWebhookService(webhookRepo WebhookRepositoryInterface, opts WebhookOptions) *WebhookService {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%s: %w", address, errWebhookBlockedAddress)
+			}
+			return nil
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // 環境変数のプロキシを経由すると接続先の検査が効かないため使わない
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          webhookDeliveryWorkers,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			// リダイレクトは追わず、3xx は失敗として記録する（登録した URL 以外には送らない）
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		opts: opts,
+	}
+}
+
+// CreateWebhook - Webhook を登録する（署名の鍵は返り値にのみ含まれ、以降は再発行しないと確認できない）
+func (s *WebhookService) CreateWebhook(userID int, input models.WebhookInput) (*models.Webhook, error) {
+	webhook := &models.Webhook{
+		UserID:      userID,
+		URL:         strings.TrimSpace(input.URL),
+		Events:      input.Events,
+		Description: strings.TrimSpace(input.Description),
+	}
+	if err := s.validate(webhook); err != nil {
+		return nil, err
+	}
+
+	count, err := s.webhookRepo.CountWebhooks(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= webhookMaxPerUser {
+		return nil, apierror.NewConflict("WEBHOOK_LIMIT_EXCEEDED",
+			fmt.Sprintf("登録できる Webhook は %d 件までです", webhookMaxPerUser), nil)
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+	if err := s.webhookRepo.CreateWebhook(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// ListWebhooks - ユーザーが登録した Webhook の一覧を取得
+func (s *WebhookService) ListWebhooks(userID int) ([]models.Webhook, error) {
+	return s.webhookRepo.ListWebhooks(userID)
+}
+
+// GetWebhook - ユーザーの Webhook を取得（見つからない場合は WEBHOOK_NOT_FOUND）
+func (s *WebhookService) GetWebhook(id, userID int) (*models.Webhook, error) {
+	webhook, err := s.webhookRepo.GetWebhook(id, userID)
+	if err != nil {
+		return nil, webhookNotFound(err)
+	}
+	return webhook, nil
+}
+
+// UpdateWebhook - Webhook の URL・イベント・説明・有効かどうかを変更する（指定しなかった項目は変更しない）
+// 無効にした Webhook の送信待ちの配信は、有効に戻すまで送信しない
+func (s *WebhookService) UpdateWebhook(id, userID int, update models.WebhookUpdate) (*models.Webhook, error) {
+	webhook, err := s.GetWebhook(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.URL != nil {
+		webhook.URL = strings.TrimSpace(*update.URL)
+	}
+	if update.Events != nil {
+		webhook.Events = *update.Events
+	}
+	if update.Description != nil {
+		webhook.Description = strings.TrimSpace(*update.Description)
+	}
+	if update.Active != nil {
+		webhook.Active = *update.Active
+	}
+	if err := s.validate(webhook); err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookRepo.UpdateWebhook(webhook); err != nil {
+		return nil, webhookNotFound(err)
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook - Webhook とその配信の記録を削除する
+func (s *WebhookService) DeleteWebhook(id, userID int) error {
+	return webhookNotFound(s.webhookRepo.DeleteWebhook(id, userID))
+}
+
+// RotateSecret - 署名の鍵を再発行する（以降の送信は新しい鍵で署名する）
+func (s *WebhookService) RotateSecret(id, userID int) (*models.Webhook, error) {
+	webhook, err := s.GetWebhook(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.webhookRepo.UpdateSecret(id, userID, secret); err != nil {
+		return nil, webhookNotFound(err)
+	}
+	webhook.Secret = secret
+	return webhook, nil
+}
+
+// ListDeliveries - Webhook の配信の記録を新しい順に1ページ分取得
+func (s *WebhookService) ListDeliveries(id, userID int, page pagination.Params) (pagination.Page[models.WebhookDelivery], error) {
+	if _, err := s.GetWebhook(id, userID); err != nil {
+		return pagination.Page[models.WebhookDelivery]{}, err
+	}
+	return s.webhookRepo.ListDeliveries(id, userID, page.Clamp(pagination.DefaultLimit, pagination.MaxLimit))
+}
+
+// Redeliver - 配信と同じ内容を、新しい配信として送信待ちに追加する（受け取り側の不具合を直した後の再送用）
+func (s *WebhookService) Redeliver(id, deliveryID, userID int) (*models.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.Redeliver(deliveryID, id, userID)
+	if errors.Is(err, apierror.ErrNotFound) {
+		return nil, apierror.NewNotFound("WEBHOOK_DELIVERY_NOT_FOUND", "Webhook の配信が見つかりません", err)
+	}
+	return delivery, err
+}
+
+// HandleEvent - イベントを、受け取るユーザーの購読している Webhook ごとに配信として記録する（イベントバスの購読者）
+// 記録に失敗しても発行元の操作は成功しているため、ログに残して続行する
+func (s *WebhookService) HandleEvent(ctx context.Context, event events.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logctx.Printf(ctx, "Failed to encode webhook payload for event %s: %v", event.ID, err)
+		return
+	}
+	if _, err := s.webhookRepo.EnqueueDeliveries(event.UserID, event.ID, string(event.Type), payload); err != nil {
+		logctx.Printf(ctx, "Failed to enqueue webhook deliveries for event %s (%s): %v", event.ID, event.Type, err)
+	}
+}
+
+// DeliverDue - 送信する時刻になった配信を送信し、結果を記録する（バックグラウンドジョブから呼び出す）
+// 停止のために ctx が取り消された場合、送信中の配信は記録せず、取得の期限が切れた後に再試行する
+func (s *WebhookService) DeliverDue(ctx context.Context) (WebhookDeliveryResult, error) {
+	var result WebhookDeliveryResult
+
+	dispatches, err := s.webhookRepo.ClaimDueDeliveries(webhookDeliveryBatch, webhookClaimLease)
+	if err != nil {
+		return result, err
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan models.WebhookDispatch)
+	for i := 0; i < webhookDeliveryWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dispatch := range queue {
+				attempt := s.send(ctx, dispatch)
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := s.webhookRepo.RecordAttempt(dispatch.DeliveryID, attempt); err != nil {
+					logctx.Printf(ctx, "Failed to record webhook delivery %d: %v", dispatch.DeliveryID, err)
+					continue
+				}
+
+				mu.Lock()
+				switch {
+				case attempt.Status == models.WebhookDeliverySucceeded:
+					result.Succeeded++
+				case attempt.Status == models.WebhookDeliveryFailed:
+					result.Failed++
+				default:
+					result.Retrying++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, dispatch := range dispatches {
+		queue <- dispatch
+	}
+	close(queue)
+	wg.Wait()
+
+	return result, nil
+}
+
+// PruneDeliveries - 送信を終えた配信の記録のうち、保持期間を過ぎたものを削除する
+func (s *WebhookService) PruneDeliveries(retention time.Duration) (int, error) {
+	return s.webhookRepo.PruneDeliveries(time.Now().Add(-retention))
+}
+
+// send - 配信を1回送信し、結果を返す
+func (s *WebhookService) send(ctx context.Context, dispatch models.WebhookDispatch) models.WebhookAttempt {
+	start := time.Now()
+	timestamp := start.Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.URL, bytes.NewReader(dispatch.Payload))
+	if err != nil {
+		return s.failedAttempt(models.WebhookAttempt{Error: err.Error()}, dispatch.Attempts+1, start)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SimpleNotionWebhook/1.0")
+	req.Header.Set(WebhookEventHeader, dispatch.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(dispatch.DeliveryID))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(dispatch.Secret, timestamp, dispatch.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return s.failedAttempt(models.WebhookAttempt{Error: err.Error()}, dispatch.Attempts+1, start)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBody))
+	status := resp.StatusCode
+	attempt := models.WebhookAttempt{
+		ResponseStatus: &status,
+		ResponseBody:   sanitizeResponseBody(body),
+	}
+	if status >= 200 && status < 300 {
+		attempt.Status = models.WebhookDeliverySucceeded
+		attempt.Duration = time.Since(start)
+		return attempt
+	}
+	attempt.Error = fmt.Sprintf("unexpected status %d", status)
+	return s.failedAttempt(attempt, dispatch.Attempts+1, start)
+}
+
+// failedAttempt - 失敗した送信を、試行回数が上限に達していなければ再試行、達していれば failed にする
+func (s *WebhookService) failedAttempt(attempt models.WebhookAttempt, attempts int, start time.Time) models.WebhookAttempt {
+	attempt.Duration = time.Since(start)
+	if attempts >= s.opts.MaxAttempts {
+		attempt.Status = models.WebhookDeliveryFailed
+		return attempt
+	}
+	next := time.Now().Add(webhookRetryDelay(s.opts.RetryDelay, attempts))
+	attempt.Status = models.WebhookDeliveryPending
+	attempt.NextAttemptAt = &next
+	return attempt
+}
+
+// validate - Webhook の URL・イベント・説明を検証し、イベントの重複を取り除く
+func (s *WebhookService) validate(webhook *models.Webhook) error {
+	if err := validateWebhookURL(webhook.URL, s.opts.AllowPrivateNetworks); err != nil {
+		return apierror.NewValidationError("INVALID_WEBHOOK_URL",
+			"url には http(s) の URL を指定してください（内部ネットワークのアドレスは指定できません）", err)
+	}
+
+	if len(webhook.Events) == 0 {
+		return apierror.NewValidationError("INVALID_WEBHOOK_EVENTS", "events に送るイベントを1つ以上指定してください", nil)
+	}
+	seen := make(map[string]bool, len(webhook.Events))
+	unique := make([]string, 0, len(webhook.Events))
+	for _, name := range webhook.Events {
+		if !events.Type(name).Valid() {
+			known := make([]string, 0, len(events.Types))
+			for _, t := range events.Types {
+				known = append(known, string(t))
+			}
+			return apierror.NewValidationError("INVALID_WEBHOOK_EVENTS",
+				fmt.Sprintf("イベント %q は購読できません（%s）", name, strings.Join(known, "・")), nil)
+		}
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	webhook.Events = unique
+
+	if utf8.RuneCountInString(webhook.Description) > webhookMaxDescription {
+		return apierror.NewValidationError("INVALID_WEBHOOK_DESCRIPTION",
+			fmt.Sprintf("description は %d 文字以内で入力してください", webhookMaxDescription), nil)
+	}
+	return nil
+}
+
+// SignWebhookPayload - 送信する本文の署名（WebhookSignatureHeader の値）を作る
+// 受け取り側は同じ鍵で "時刻.本文" の HMAC-SHA256 を計算して比較し、時刻が古すぎる送信は拒否する
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay - attempts 回目の送信に失敗した後、次に送信するまでの間隔（base から倍にしていき、上限で止める）
+func webhookRetryDelay(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxRetryDelay {
+		delay = webhookMaxRetryDelay
+	}
+	return delay
+}
+
+// validateWebhookURL - Webhook に登録できる URL か検証する
+// http(s) の絶対 URL で、ユーザー情報を含まないものだけを受け付ける
+// 内部ネットワークを許可しない場合は、IP アドレスと localhost を直接指定した URL も拒否する（名前解決後のアドレスは送信時に検査する）
+func validateWebhookURL(rawURL string, allowPrivate bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("url has no host")
+	}
+	if u.User != nil {
+		return errors.New("url must not contain user info")
+	}
+	if allowPrivate {
+		return nil
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return errWebhookBlockedAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return errWebhookBlockedAddress
+	}
+	return nil
+}
+
+// generateWebhookSecret - 署名の鍵を作る
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
+
+// sanitizeResponseBody - 応答の本文をテキストとして保存できる形にする（不正な UTF-8 と NUL を取り除く）
+func sanitizeResponseBody(body []byte) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", "")
+}
+
+// webhookNotFound - リポジトリの ErrNotFound を WEBHOOK_NOT_FOUND にする
+func webhookNotFound(err error) error {
+	if errors.Is(err, apierror.ErrNotFound) {
+		return apierror.NewNotFound("WEBHOOK_NOT_FOUND", "Webhook が見つかりません", err)
+	}
+	return err
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/events"
+	"simple-notion-backend/internal/models"
+	"simple-notion-backend/internal/pagination"
+)
+
+// MockWebhookRepository - WebhookRepositoryのモック
+type MockWebhookRepository struct {
+	CreateWebhookFunc      func(webhook *models.Webhook) error
+	CountWebhooksFunc      func(userID int) (int, error)
+	GetWebhookFunc         func(id, userID int) (*models.Webhook, error)
+	UpdateWebhookFunc      func(webhook *models.Webhook) error
+	UpdateSecretFunc       func(id, userID int, secret string) error
+	EnqueueDeliveriesFunc  func(userID int, eventID, eventType string, payload []byte) (int, error)
+	RedeliverFunc          func(deliveryID, webhookID, userID int) (*models.WebhookDelivery, error)
+	ClaimDueDeliveriesFunc func(limit int, lease time.Duration) ([]models.WebhookDispatch, error)
+	RecordAttemptFunc      func(deliveryID int, attempt models.WebhookAttempt) error
+}
+
+func (m *MockWebhookRepository) CreateWebhook(webhook *models.Webhook) error {
+	if m.CreateWebhookFunc != nil {
+		return m.CreateWebhookFunc(webhook)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) CountWebhooks(userID int) (int, error) {
+	if m.CountWebhooksFunc != nil {
+		return m.CountWebhooksFunc(userID)
+	}
+	return 0, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) ListWebhooks(userID int) ([]models.Webhook, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) GetWebhook(id, userID int) (*models.Webhook, error) {
+	if m.GetWebhookFunc != nil {
+		return m.GetWebhookFunc(id, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) UpdateWebhook(webhook *models.Webhook) error {
+	if m.UpdateWebhookFunc != nil {
+		return m.UpdateWebhookFunc(webhook)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) UpdateSecret(id, userID int, secret string) error {
+	if m.UpdateSecretFunc != nil {
+		return m.UpdateSecretFunc(id, userID, secret)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(id, userID int) error {
+	return errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) EnqueueDeliveries(userID int, eventID, eventType string, payload []byte) (int, error) {
+	if m.EnqueueDeliveriesFunc != nil {
+		return m.EnqueueDeliveriesFunc(userID, eventID, eventType, payload)
+	}
+	return 0, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) ListDeliveries(webhookID, userID int, page pagination.Params) (pagination.Page[models.WebhookDelivery], error) {
+	return pagination.Page[models.WebhookDelivery]{}, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) Redeliver(deliveryID, webhookID, userID int) (*models.WebhookDelivery, error) {
+	if m.RedeliverFunc != nil {
+		return m.RedeliverFunc(deliveryID, webhookID, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDispatch, error) {
+	if m.ClaimDueDeliveriesFunc != nil {
+		return m.ClaimDueDeliveriesFunc(limit, lease)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) RecordAttempt(deliveryID int, attempt models.WebhookAttempt) error {
+	if m.RecordAttemptFunc != nil {
+		return m.RecordAttemptFunc(deliveryID, attempt)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWebhookRepository) PruneDeliveries(before time.Time) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+// TestWebhookServiceCreateWebhook - Webhook の登録のテスト
+func TestWebhookServiceCreateWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      models.WebhookInput
+		count      int
+		wantStatus int
+		wantCode   string
+		wantEvents []string
+	}{
+		{
+			name:       "正常系：登録すると鍵を返し、重複したイベントは1つにする",
+			input:      models.WebhookInput{URL: " https://example.com/hook ", Events: []string{"document.created", "file.uploaded", "document.created"}},
+			wantEvents: []string{"document.created", "file.uploaded"},
+		},
+		{
+			name:       "異常系：http(s) 以外の URL",
+			input:      models.WebhookInput{URL: "ftp://example.com/hook", Events: []string{"document.created"}},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_WEBHOOK_URL",
+		},
+		{
+			name:       "異常系：内部ネットワークのアドレス",
+			input:      models.WebhookInput{URL: "http://169.254.169.254/latest", Events: []string{"document.created"}},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_WEBHOOK_URL",
+		},
+		{
+			name:       "異常系：localhost",
+			input:      models.WebhookInput{URL: "http://localhost:8080/hook", Events: []string{"document.created"}},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_WEBHOOK_URL",
+		},
+		{
+			name:       "異常系：イベントの指定なし",
+			input:      models.WebhookInput{URL: "https://example.com/hook"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_WEBHOOK_EVENTS",
+		},
+		{
+			name:       "異常系：不明なイベント",
+			input:      models.WebhookInput{URL: "https://example.com/hook", Events: []string{"document.viewed"}},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_WEBHOOK_EVENTS",
+		},
+		{
+			name:       "異常系：説明が長すぎる",
+			input:      models.WebhookInput{URL: "https://example.com/hook", Events: []string{"user.login"}, Description: strings.Repeat("あ", webhookMaxDescription+1)},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "INVALID_WEBHOOK_DESCRIPTION",
+		},
+		{
+			name:       "異常系：登録数の上限",
+			input:      models.WebhookInput{URL: "https://example.com/hook", Events: []string{"user.login"}},
+			count:      webhookMaxPerUser,
+			wantStatus: http.StatusConflict,
+			wantCode:   "WEBHOOK_LIMIT_EXCEEDED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *models.Webhook
+			repo := &MockWebhookRepository{
+				CountWebhooksFunc: func(userID int) (int, error) { return tt.count, nil },
+				CreateWebhookFunc: func(webhook *models.Webhook) error {
+					created = webhook
+					webhook.ID = 1
+					webhook.Active = true
+					return nil
+				},
+			}
+			service := NewWebhookService(repo, WebhookOptions{MaxAttempts: 3, RetryDelay: time.Second, Timeout: time.Second})
+
+			webhook, err := service.CreateWebhook(10, tt.input)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				if created != nil {
+					t.Error("webhook was created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateWebhook() error = %v", err)
+			}
+			if webhook.URL != "https://example.com/hook" || webhook.UserID != 10 {
+				t.Errorf("webhook = %+v", webhook)
+			}
+			if strings.Join(webhook.Events, ",") != strings.Join(tt.wantEvents, ",") {
+				t.Errorf("Events = %v, want %v", webhook.Events, tt.wantEvents)
+			}
+			if !strings.HasPrefix(webhook.Secret, webhookSecretPrefix) || len(webhook.Secret) != len(webhookSecretPrefix)+webhookSecretBytes*2 {
+				t.Errorf("Secret = %q", webhook.Secret)
+			}
+		})
+	}
+}
+
+// TestWebhookServiceUpdateWebhook - Webhook の変更のテスト
+func TestWebhookServiceUpdateWebhook(t *testing.T) {
+	t.Run("正常系：指定した項目のみ変更する", func(t *testing.T) {
+		repo := &MockWebhookRepository{
+			GetWebhookFunc: func(id, userID int) (*models.Webhook, error) {
+				return &models.Webhook{ID: id, UserID: userID, URL: "https://example.com/hook", Events: []string{"user.login"}, Active: true}, nil
+			},
+			UpdateWebhookFunc: func(webhook *models.Webhook) error { return nil },
+		}
+		service := NewWebhookService(repo, WebhookOptions{})
+
+		active := false
+		webhook, err := service.UpdateWebhook(1, 10, models.WebhookUpdate{Active: &active})
+		if err != nil {
+			t.Fatalf("UpdateWebhook() error = %v", err)
+		}
+		if webhook.Active || webhook.URL != "https://example.com/hook" || len(webhook.Events) != 1 {
+			t.Errorf("webhook = %+v", webhook)
+		}
+	})
+
+	t.Run("異常系：他のユーザーの Webhook", func(t *testing.T) {
+		repo := &MockWebhookRepository{
+			GetWebhookFunc: func(id, userID int) (*models.Webhook, error) {
+				return nil, apierror.ErrNotFound
+			},
+		}
+		service := NewWebhookService(repo, WebhookOptions{})
+
+		_, err := service.UpdateWebhook(1, 10, models.WebhookUpdate{})
+		assertAppErrorCode(t, err, http.StatusNotFound, "WEBHOOK_NOT_FOUND")
+	})
+}
+
+// TestWebhookServiceHandleEvent - イベントを配信として記録するテスト
+func TestWebhookServiceHandleEvent(t *testing.T) {
+	var gotUserID int
+	var gotType string
+	var gotPayload map[string]interface{}
+	repo := &MockWebhookRepository{
+		EnqueueDeliveriesFunc: func(userID int, eventID, eventType string, payload []byte) (int, error) {
+			gotUserID, gotType = userID, eventType
+			if err := json.Unmarshal(payload, &gotPayload); err != nil {
+				t.Fatalf("payload is not JSON: %v", err)
+			}
+			return 1, nil
+		},
+	}
+	service := NewWebhookService(repo, WebhookOptions{})
+
+	bus := events.NewBus()
+	bus.Subscribe(service.HandleEvent)
+	bus.Publish(context.Background(), events.DocumentCreated, 7, events.DocumentData{DocumentID: 3, Title: "議事録", ActorID: 8})
+
+	if gotUserID != 7 || gotType != "document.created" {
+		t.Errorf("enqueued for user %d type %q, want 7 document.created", gotUserID, gotType)
+	}
+	if gotPayload["type"] != "document.created" || gotPayload["id"] == "" {
+		t.Errorf("payload = %v", gotPayload)
+	}
+	if _, ok := gotPayload["userId"]; ok {
+		t.Error("payload must not include the receiving user")
+	}
+	data, _ := gotPayload["data"].(map[string]interface{})
+	if data["documentId"] != float64(3) || data["actorId"] != float64(8) {
+		t.Errorf("payload data = %v", data)
+	}
+}
+
+// TestWebhookServiceDeliverDue - 配信の送信と結果の記録のテスト
+func TestWebhookServiceDeliverDue(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"evt-1","type":"document.created"}`)
+
+	tests := []struct {
+		name       string
+		status     int
+		attempts   int // これまでの試行回数
+		wantStatus models.WebhookDeliveryStatus
+		wantNext   bool
+		wantResult WebhookDeliveryResult
+	}{
+		{
+			name:       "正常系：2xx は成功",
+			status:     http.StatusOK,
+			wantStatus: models.WebhookDeliverySucceeded,
+			wantResult: WebhookDeliveryResult{Succeeded: 1},
+		},
+		{
+			name:       "異常系：5xx は再試行",
+			status:     http.StatusInternalServerError,
+			wantStatus: models.WebhookDeliveryPending,
+			wantNext:   true,
+			wantResult: WebhookDeliveryResult{Retrying: 1},
+		},
+		{
+			name:       "異常系：リダイレクトは追わず再試行",
+			status:     http.StatusFound,
+			wantStatus: models.WebhookDeliveryPending,
+			wantNext:   true,
+			wantResult: WebhookDeliveryResult{Retrying: 1},
+		},
+		{
+			name:       "異常系：試行回数の上限で失敗",
+			status:     http.StatusBadRequest,
+			attempts:   2,
+			wantStatus: models.WebhookDeliveryFailed,
+			wantResult: WebhookDeliveryResult{Failed: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				timestamp, _ := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+				if got, want := r.Header.Get(WebhookSignatureHeader), SignWebhookPayload(secret, timestamp, body); got != want {
+					t.Errorf("signature = %q, want %q", got, want)
+				}
+				if r.Header.Get(WebhookEventHeader) != "document.created" || r.Header.Get(WebhookDeliveryHeader) != "5" {
+					t.Errorf("headers = %v", r.Header)
+				}
+				if tt.status == http.StatusFound {
+					w.Header().Set("Location", "/elsewhere")
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("ok\x00"))
+			}))
+			defer server.Close()
+
+			var mu sync.Mutex
+			var recorded []models.WebhookAttempt
+			repo := &MockWebhookRepository{
+				ClaimDueDeliveriesFunc: func(limit int, lease time.Duration) ([]models.WebhookDispatch, error) {
+					return []models.WebhookDispatch{{
+						DeliveryID: 5, WebhookID: 1, EventID: "evt-1", EventType: "document.created",
+						Payload: payload, Attempts: tt.attempts, URL: server.URL + "/hook", Secret: secret,
+					}}, nil
+				},
+				RecordAttemptFunc: func(deliveryID int, attempt models.WebhookAttempt) error {
+					mu.Lock()
+					defer mu.Unlock()
+					recorded = append(recorded, attempt)
+					return nil
+				},
+			}
+			service := NewWebhookService(repo, WebhookOptions{
+				MaxAttempts:          3,
+				RetryDelay:           time.Minute,
+				Timeout:              5 * time.Second,
+				AllowPrivateNetworks: true,
+			})
+
+			result, err := service.DeliverDue(context.Background())
+			if err != nil {
+				t.Fatalf("DeliverDue() error = %v", err)
+			}
+			if result != tt.wantResult {
+				t.Errorf("result = %+v, want %+v", result, tt.wantResult)
+			}
+			if len(recorded) != 1 {
+				t.Fatalf("recorded %d attempts, want 1", len(recorded))
+			}
+			attempt := recorded[0]
+			if attempt.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", attempt.Status, tt.wantStatus)
+			}
+			if (attempt.NextAttemptAt != nil) != tt.wantNext {
+				t.Errorf("NextAttemptAt = %v, want set %v", attempt.NextAttemptAt, tt.wantNext)
+			}
+			if attempt.ResponseStatus == nil || *attempt.ResponseStatus != tt.status {
+				t.Errorf("ResponseStatus = %v, want %d", attempt.ResponseStatus, tt.status)
+			}
+			if attempt.ResponseBody != "ok" {
+				t.Errorf("ResponseBody = %q, want %q", attempt.ResponseBody, "ok")
+			}
+		})
+	}
+}
+
+// TestWebhookServiceBlocksPrivateNetworks - 内部ネットワークへの送信を拒否するテスト
+func TestWebhookServiceBlocksPrivateNetworks(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	var recorded models.WebhookAttempt
+	repo := &MockWebhookRepository{
+		ClaimDueDeliveriesFunc: func(limit int, lease time.Duration) ([]models.WebhookDispatch, error) {
+			return []models.WebhookDispatch{{DeliveryID: 1, URL: server.URL, Secret: "whsec_test", Payload: []byte(`{}`)}}, nil
+		},
+		RecordAttemptFunc: func(deliveryID int, attempt models.WebhookAttempt) error {
+			recorded = attempt
+			return nil
+		},
+	}
+	service := NewWebhookService(repo, WebhookOptions{MaxAttempts: 1, Timeout: time.Second})
+
+	result, err := service.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatalf("DeliverDue() error = %v", err)
+	}
+	if requested {
+		t.Error("request reached a loopback address")
+	}
+	if result.Failed != 1 || !strings.Contains(recorded.Error, "non-public") {
+		t.Errorf("result = %+v, attempt = %+v", result, recorded)
+	}
+}
+
+// TestWebhookRetryDelay - 再試行の間隔のテスト
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{30, webhookMaxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		if got := webhookRetryDelay(30*time.Second, tt.attempts); got != tt.want {
+			t.Errorf("webhookRetryDelay(30s, %d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// TestSignWebhookPayload - 署名のテスト
+func TestSignWebhookPayload(t *testing.T) {
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	got := SignWebhookPayload("secret", 1700000000, []byte(`{"a":1}`))
+	want := "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	if got != want {
+		t.Errorf("SignWebhookPayload() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/mailer"
+	"simple-notion-backend/internal/models"
+)
+
+// WorkspaceInvitationService - メールで送るワークスペースへの招待の作成・取り消しと、招待リンクからの参加を担当するサービス
+// 招待の作成・一覧・取り消しはメンバーを追加できる役割（所有者・管理者）のみができ、管理者として招待できるのは所有者のみ
+// 招待リンクのトークンは共有リンクと同じ形式で、保存するのはハッシュのみ
+type WorkspaceInvitationService struct {
+	workspaceRepo  WorkspaceRepositoryInterface
+	invitationRepo WorkspaceInvitationRepositoryInterface
+	userRepo       UserRepositoryInterface
+	workspaces     *WorkspaceService // メンバー数の上限の確認に使う
+	mailer         MailerInterface   // nil の場合は招待を作成できない（リンクを届ける手段がないため）
+	ttl            time.Duration     // 招待リンクの有効期間
+	now            func() time.Time
+}
+
+// NewWorkspaceInvitationService - WorkspaceInvitationServiceを初期化
+func NewWorkspaceInvitationService(
+	workspaceRepo WorkspaceRepositoryInterface,
+	invitationRepo WorkspaceInvitationRepositoryInterface,
+	userRepo UserRepositoryInterface,
+	workspaces *WorkspaceService,
+	mailer MailerInterface,
+	ttl time.Duration,
+) *WorkspaceInvitationService {
+	return &WorkspaceInvitationService{
+		workspaceRepo:  workspaceRepo,
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		workspaces:     workspaces,
+		mailer:         mailer,
+		ttl:            ttl,
+		now:            time.Now,
+	}
+}
+
+// CreateInvitation - メールアドレスにワークスペースへの招待リンクを送る（role は admin・member・guest、省略時 member）
+// 同じメールアドレスへの未承諾の招待がある場合は取り消し、新しいリンクを送り直す
+func (s *WorkspaceInvitationService) CreateInvitation(ctx context.Context, workspaceID, userID int, email string, role models.WorkspaceRole) (*models.WorkspaceInvitation, error) {
+	if role == "" {
+		role = models.WorkspaceRoleMember
+	}
+	if !role.Valid() || role == models.WorkspaceRoleOwner {
+		return nil, apierror.NewValidationError("INVALID_WORKSPACE_ROLE", "role には admin・member・guest のいずれかを指定してください", nil)
+	}
+	email = strings.TrimSpace(email)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, apierror.NewValidationError("INVALID_EMAIL", "メールアドレスの形式が不正です", err)
+	}
+
+	workspace, err := s.workspaceRepo.GetWorkspace(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !workspace.Role.CanManageMembers() || (role == models.WorkspaceRoleAdmin && workspace.Role != models.WorkspaceRoleOwner) {
+		return nil, apierror.NewForbidden("WORKSPACE_ADMIN_REQUIRED", "メンバーを招待する権限がありません", nil)
+	}
+	if s.mailer == nil {
+		return nil, apierror.NewNotFound("WORKSPACE_INVITATIONS_DISABLED", "メールの送信が設定されていないため招待できません", nil)
+	}
+
+	if err := s.checkNotMember(workspaceID, email); err != nil {
+		return nil, err
+	}
+	if err := s.workspaces.CheckMemberLimit(workspaceID); err != nil {
+		return nil, err
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		return nil, err
+	}
+	invitedBy := userID
+	invitation := &models.WorkspaceInvitation{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        role,
+		Token:       token,
+		InvitedBy:   &invitedBy,
+		ExpiresAt:   s.now().Add(s.ttl),
+	}
+	if err := s.invitationRepo.CreateInvitation(invitation, hashShareToken(token)); err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.SendTemplate(email, mailer.TemplateWorkspaceInvitation, map[string]string{
+		"InviterName":   s.userName(ctx, userID),
+		"WorkspaceName": workspace.Name,
+		"Role":          workspaceRoleLabels[role],
+		"Token":         token,
+		"ExpiresAt":     invitation.ExpiresAt.Format("2006-01-02 15:04"),
+	})
+	if err != nil {
+		// 届かない招待を残さないよう取り消す
+		if revokeErr := s.invitationRepo.RevokeInvitation(workspaceID, invitation.ID); revokeErr != nil {
+			logctx.Printf(ctx, "Failed to revoke undelivered invitation %d: %v", invitation.ID, revokeErr)
+		}
+		return nil, apierror.NewInternal(fmt.Errorf("failed to queue invitation mail: %w", err))
+	}
+
+	return invitation, nil
+}
+
+// ListInvitations - 承諾待ちの招待一覧を取得（所有者・管理者のみ）
+func (s *WorkspaceInvitationService) ListInvitations(workspaceID, userID int) ([]models.WorkspaceInvitation, error) {
+	if err := s.checkManager(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	return s.invitationRepo.ListPendingInvitations(workspaceID)
+}
+
+// RevokeInvitation - 承諾待ちの招待を取り消す（所有者・管理者のみ。取り消したリンクからは参加できない）
+func (s *WorkspaceInvitationService) RevokeInvitation(workspaceID, userID, invitationID int) error {
+	if err := s.checkManager(workspaceID, userID); err != nil {
+		return err
+	}
+	if err := s.invitationRepo.RevokeInvitation(workspaceID, invitationID); err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return apierror.NewNotFound("INVITATION_NOT_FOUND", "承諾待ちの招待が見つかりません", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetInvitation - 招待リンクのトークンから承諾待ちの招待を取得（承諾・取り消し済みは NotFound、期限切れは Conflict）
+func (s *WorkspaceInvitationService) GetInvitation(token string) (*models.WorkspaceInvitation, error) {
+	invitation, err := s.invitationRepo.GetInvitationByTokenHash(hashShareToken(token))
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return nil, invitationNotFound(err)
+		}
+		return nil, err
+	}
+	if !invitation.Pending() {
+		return nil, invitationNotFound(nil)
+	}
+	if invitation.Expired(s.now()) {
+		return nil, apierror.NewConflict("INVITATION_EXPIRED", "招待リンクの有効期限が切れています。招待した人に送り直してもらってください", nil)
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation - 招待を承諾し、ユーザーを招待の役割でワークスペースのメンバーに追加する
+// 招待されたメールアドレスのアカウントでのみ承諾できる（大文字・小文字は区別しない）。既にメンバーの場合は役割を変えない
+func (s *WorkspaceInvitationService) AcceptInvitation(token string, userID int) (*models.WorkspaceMember, error) {
+	invitation, err := s.GetInvitation(token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, apierror.NewForbidden("INVITATION_EMAIL_MISMATCH", "招待されたメールアドレスのアカウントでログインしてください", nil)
+	}
+
+	role, err := s.workspaces.MemberRole(invitation.WorkspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		if err := s.workspaces.CheckMemberLimit(invitation.WorkspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	added, err := s.invitationRepo.AcceptInvitation(invitation, userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return nil, invitationNotFound(err)
+		}
+		return nil, err
+	}
+	if added {
+		role = invitation.Role
+	}
+
+	return &models.WorkspaceMember{
+		WorkspaceID: invitation.WorkspaceID,
+		UserID:      user.ID,
+		Email:       user.Email,
+		Name:        user.Name,
+		Role:        role,
+	}, nil
+}
+
+// checkManager - メンバーを管理できる役割（所有者・管理者）かを確認
+func (s *WorkspaceInvitationService) checkManager(workspaceID, userID int) error {
+	role, err := s.workspaceRepo.GetMemberRole(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !role.CanManageMembers() {
+		return apierror.NewForbidden("WORKSPACE_ADMIN_REQUIRED", "招待を管理する権限がありません", nil)
+	}
+	return nil
+}
+
+// checkNotMember - メールアドレスのユーザーが既にメンバーでないかを確認（アカウントがない場合は確認しない）
+func (s *WorkspaceInvitationService) checkNotMember(workspaceID int, email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user by email: %w", err)
+	}
+	role, err := s.workspaces.MemberRole(workspaceID, user.ID)
+	if err != nil {
+		return err
+	}
+	if role != "" {
+		return apierror.NewConflict("ALREADY_WORKSPACE_MEMBER", "既にワークスペースのメンバーです", nil)
+	}
+	return nil
+}
+
+// userName - メールに表示するユーザーの名前（未設定の場合はメールアドレス）
+func (s *WorkspaceInvitationService) userName(ctx context.Context, userID int) string {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logctx.Printf(ctx, "Failed to load inviter %d for invitation mail: %v", userID, err)
+		return "ユーザー"
+	}
+	if user.Name == "" {
+		return user.Email
+	}
+	return user.Name
+}
+
+// invitationNotFound - 招待が存在しない・承諾・取り消し済みの場合のエラー
+func invitationNotFound(cause error) error {
+	return apierror.NewNotFound("INVITATION_NOT_FOUND", "招待が見つからないか、既に使用・取り消しされています", cause)
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/mailer"
+	"simple-notion-backend/internal/models"
+)
+
+// MockWorkspaceInvitationRepository - WorkspaceInvitationRepositoryのモック
+type MockWorkspaceInvitationRepository struct {
+	CreateInvitationFunc         func(invitation *models.WorkspaceInvitation, tokenHash string) error
+	ListPendingInvitationsFunc   func(workspaceID int) ([]models.WorkspaceInvitation, error)
+	GetInvitationByTokenHashFunc func(tokenHash string) (*models.WorkspaceInvitation, error)
+	RevokeInvitationFunc         func(workspaceID, invitationID int) error
+	AcceptInvitationFunc         func(invitation *models.WorkspaceInvitation, userID int) (bool, error)
+}
+
+func (m *MockWorkspaceInvitationRepository) CreateInvitation(invitation *models.WorkspaceInvitation, tokenHash string) error {
+	if m.CreateInvitationFunc != nil {
+		return m.CreateInvitationFunc(invitation, tokenHash)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWorkspaceInvitationRepository) ListPendingInvitations(workspaceID int) ([]models.WorkspaceInvitation, error) {
+	if m.ListPendingInvitationsFunc != nil {
+		return m.ListPendingInvitationsFunc(workspaceID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWorkspaceInvitationRepository) GetInvitationByTokenHash(tokenHash string) (*models.WorkspaceInvitation, error) {
+	if m.GetInvitationByTokenHashFunc != nil {
+		return m.GetInvitationByTokenHashFunc(tokenHash)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWorkspaceInvitationRepository) RevokeInvitation(workspaceID, invitationID int) error {
+	if m.RevokeInvitationFunc != nil {
+		return m.RevokeInvitationFunc(workspaceID, invitationID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWorkspaceInvitationRepository) AcceptInvitation(invitation *models.WorkspaceInvitation, userID int) (bool, error) {
+	if m.AcceptInvitationFunc != nil {
+		return m.AcceptInvitationFunc(invitation, userID)
+	}
+	return false, errors.New("not implemented")
+}
+
+// newTestInvitationService - ユーザー1が所有者・2が管理者・3がメンバーのワークスペース（メンバー上限 memberLimit）の招待サービスを作成する
+func newTestInvitationService(invitationRepo *MockWorkspaceInvitationRepository, mail MailerInterface, memberLimit int) *WorkspaceInvitationService {
+	roles := map[int]models.WorkspaceRole{
+		1: models.WorkspaceRoleOwner,
+		2: models.WorkspaceRoleAdmin,
+		3: models.WorkspaceRoleMember,
+	}
+	workspaceRepo := &MockWorkspaceRepository{
+		GetMemberRoleFunc: workspaceRoles(roles),
+		GetWorkspaceFunc: func(workspaceID, userID int) (*models.Workspace, error) {
+			role, ok := roles[userID]
+			if !ok {
+				return nil, apierror.ErrNotFound
+			}
+			return &models.Workspace{ID: workspaceID, Name: "開発チーム", OwnerID: 1, Role: role}, nil
+		},
+		GetUsageFunc: func(workspaceID int) (int, int, error) { return 0, len(roles), nil },
+	}
+	users := map[string]*models.User{
+		"owner@example.com":  {ID: 1, Email: "owner@example.com", Name: "Owner"},
+		"member@example.com": {ID: 3, Email: "member@example.com"},
+		"carol@example.com":  {ID: 4, Email: "carol@example.com", Name: "Carol"},
+	}
+	userRepo := &MockUserRepository{
+		GetByEmailFunc: func(email string) (*models.User, error) {
+			if user, ok := users[email]; ok {
+				return user, nil
+			}
+			return nil, apierror.ErrNotFound
+		},
+		GetByIDFunc: func(id int) (*models.User, error) {
+			for _, user := range users {
+				if user.ID == id {
+					return user, nil
+				}
+			}
+			return nil, apierror.ErrNotFound
+		},
+	}
+	workspaces := NewWorkspaceService(workspaceRepo, userRepo, 0, memberLimit, 0, nil)
+	return NewWorkspaceInvitationService(workspaceRepo, invitationRepo, userRepo, workspaces, mail, 24*time.Hour)
+}
+
+func TestWorkspaceInvitationService_CreateInvitation(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      int
+		email       string
+		role        models.WorkspaceRole
+		memberLimit int
+		noMailer    bool
+		mailErr     error
+		wantStatus  int
+		wantCode    string
+	}{
+		{name: "正常系：管理者がメンバーとして招待", userID: 2, email: "new@example.com"},
+		{name: "正常系：所有者が管理者として招待", userID: 1, email: " new@example.com ", role: models.WorkspaceRoleAdmin},
+		{name: "異常系：不正なメールアドレス", userID: 1, email: "not-an-email", wantStatus: http.StatusBadRequest, wantCode: "INVALID_EMAIL"},
+		{name: "異常系：owner は指定できない", userID: 1, email: "new@example.com", role: models.WorkspaceRoleOwner, wantStatus: http.StatusBadRequest, wantCode: "INVALID_WORKSPACE_ROLE"},
+		{name: "異常系：メンバーは招待できない", userID: 3, email: "new@example.com", wantStatus: http.StatusForbidden, wantCode: "WORKSPACE_ADMIN_REQUIRED"},
+		{name: "異常系：管理者は管理者を招待できない", userID: 2, email: "new@example.com", role: models.WorkspaceRoleAdmin, wantStatus: http.StatusForbidden, wantCode: "WORKSPACE_ADMIN_REQUIRED"},
+		{name: "異常系：既にメンバー", userID: 1, email: "member@example.com", wantStatus: http.StatusConflict, wantCode: "ALREADY_WORKSPACE_MEMBER"},
+		{name: "異常系：メンバー数の上限", userID: 1, email: "new@example.com", memberLimit: 3, wantStatus: http.StatusConflict, wantCode: "WORKSPACE_MEMBER_LIMIT"},
+		{name: "異常系：メール送信が未設定", userID: 1, email: "new@example.com", noMailer: true, wantStatus: http.StatusNotFound, wantCode: "WORKSPACE_INVITATIONS_DISABLED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *models.WorkspaceInvitation
+			var savedHash string
+			invitationRepo := &MockWorkspaceInvitationRepository{
+				CreateInvitationFunc: func(invitation *models.WorkspaceInvitation, tokenHash string) error {
+					invitation.ID = 7
+					saved, savedHash = invitation, tokenHash
+					return nil
+				},
+			}
+			mail := &MockMailer{Err: tt.mailErr}
+			var mailIface MailerInterface = mail
+			if tt.noMailer {
+				mailIface = nil
+			}
+			service := newTestInvitationService(invitationRepo, mailIface, tt.memberLimit)
+
+			invitation, err := service.CreateInvitation(context.Background(), 5, tt.userID, tt.email, tt.role)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				if saved != nil || len(mail.Sent) != 0 {
+					t.Error("invitation should not be created or mailed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateInvitation() error = %v", err)
+			}
+
+			// トークンはハッシュのみを保存し、メールのリンクにだけ含める
+			if invitation.Token == "" || savedHash != hashShareToken(invitation.Token) {
+				t.Errorf("token hash = %q, want hash of token", savedHash)
+			}
+			if saved.Email != "new@example.com" || saved.InvitedBy == nil || *saved.InvitedBy != tt.userID {
+				t.Errorf("saved = %+v", saved)
+			}
+			if len(mail.Sent) != 1 || mail.Sent[0].To != "new@example.com" || mail.Sent[0].Template != mailer.TemplateWorkspaceInvitation {
+				t.Fatalf("sent = %+v", mail.Sent)
+			}
+			data := mail.Sent[0].Data.(map[string]string)
+			if data["Token"] != invitation.Token || data["WorkspaceName"] != "開発チーム" {
+				t.Errorf("mail data = %+v", data)
+			}
+		})
+	}
+
+	t.Run("異常系：メールを送信待ちにできない場合は招待を取り消す", func(t *testing.T) {
+		revoked := 0
+		invitationRepo := &MockWorkspaceInvitationRepository{
+			CreateInvitationFunc: func(invitation *models.WorkspaceInvitation, tokenHash string) error {
+				invitation.ID = 7
+				return nil
+			},
+			RevokeInvitationFunc: func(workspaceID, invitationID int) error {
+				revoked = invitationID
+				return nil
+			},
+		}
+		service := newTestInvitationService(invitationRepo, &MockMailer{Err: mailer.ErrQueueFull}, 0)
+
+		if _, err := service.CreateInvitation(context.Background(), 5, 1, "new@example.com", ""); err == nil {
+			t.Fatal("CreateInvitation() error = nil, want error")
+		}
+		if revoked != 7 {
+			t.Errorf("revoked = %d, want 7", revoked)
+		}
+	})
+}
+
+func TestWorkspaceInvitationService_GetInvitation(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	accepted := now.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		invitation *models.WorkspaceInvitation
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "正常系：承諾待ち", invitation: &models.WorkspaceInvitation{ID: 1, ExpiresAt: now.Add(time.Hour)}},
+		{name: "異常系：存在しない", wantStatus: http.StatusNotFound, wantCode: "INVITATION_NOT_FOUND"},
+		{name: "異常系：承諾済み", invitation: &models.WorkspaceInvitation{ID: 1, ExpiresAt: now.Add(time.Hour), AcceptedAt: &accepted}, wantStatus: http.StatusNotFound, wantCode: "INVITATION_NOT_FOUND"},
+		{name: "異常系：期限切れ", invitation: &models.WorkspaceInvitation{ID: 1, ExpiresAt: now}, wantStatus: http.StatusConflict, wantCode: "INVITATION_EXPIRED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invitationRepo := &MockWorkspaceInvitationRepository{
+				GetInvitationByTokenHashFunc: func(tokenHash string) (*models.WorkspaceInvitation, error) {
+					if tokenHash != hashShareToken("token") || tt.invitation == nil {
+						return nil, apierror.ErrNotFound
+					}
+					return tt.invitation, nil
+				},
+			}
+			service := newTestInvitationService(invitationRepo, &MockMailer{}, 0)
+			service.now = func() time.Time { return now }
+
+			invitation, err := service.GetInvitation("token")
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				return
+			}
+			if err != nil || invitation.ID != 1 {
+				t.Fatalf("GetInvitation() = %+v, %v", invitation, err)
+			}
+		})
+	}
+}
+
+func TestWorkspaceInvitationService_AcceptInvitation(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     int
+		email      string
+		acceptErr  error
+		wantRole   models.WorkspaceRole
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "正常系：招待された役割で参加", userID: 4, email: "Carol@Example.com", wantRole: models.WorkspaceRoleGuest},
+		{name: "正常系：既にメンバーの場合は役割を変えない", userID: 3, email: "member@example.com", wantRole: models.WorkspaceRoleMember},
+		{name: "異常系：別のメールアドレスのアカウント", userID: 4, email: "other@example.com", wantStatus: http.StatusForbidden, wantCode: "INVITATION_EMAIL_MISMATCH"},
+		{name: "異常系：同時に承諾された", userID: 4, email: "carol@example.com", acceptErr: apierror.ErrNotFound, wantStatus: http.StatusNotFound, wantCode: "INVITATION_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acceptedBy int
+			invitationRepo := &MockWorkspaceInvitationRepository{
+				GetInvitationByTokenHashFunc: func(tokenHash string) (*models.WorkspaceInvitation, error) {
+					return &models.WorkspaceInvitation{
+						ID: 9, WorkspaceID: 5, Email: tt.email, Role: models.WorkspaceRoleGuest,
+						ExpiresAt: time.Now().Add(time.Hour),
+					}, nil
+				},
+				AcceptInvitationFunc: func(invitation *models.WorkspaceInvitation, userID int) (bool, error) {
+					if tt.acceptErr != nil {
+						return false, tt.acceptErr
+					}
+					acceptedBy = userID
+					return tt.userID == 4, nil
+				},
+			}
+			service := newTestInvitationService(invitationRepo, &MockMailer{}, 0)
+
+			member, err := service.AcceptInvitation("token", tt.userID)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				return
+			}
+			if err != nil {
+				t.Fatalf("AcceptInvitation() error = %v", err)
+			}
+			if acceptedBy != tt.userID || member.WorkspaceID != 5 || member.Role != tt.wantRole {
+				t.Errorf("member = %+v, acceptedBy = %d, want role %s", member, acceptedBy, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestWorkspaceInvitationService_RevokeInvitation(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     int
+		revokeErr  error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "正常系：管理者が取り消し", userID: 2},
+		{name: "異常系：メンバーは取り消せない", userID: 3, wantStatus: http.StatusForbidden, wantCode: "WORKSPACE_ADMIN_REQUIRED"},
+		{name: "異常系：承諾待ちでない", userID: 1, revokeErr: apierror.ErrNotFound, wantStatus: http.StatusNotFound, wantCode: "INVITATION_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked := 0
+			invitationRepo := &MockWorkspaceInvitationRepository{
+				RevokeInvitationFunc: func(workspaceID, invitationID int) error {
+					if tt.revokeErr != nil {
+						return tt.revokeErr
+					}
+					revoked = invitationID
+					return nil
+				},
+			}
+			service := newTestInvitationService(invitationRepo, &MockMailer{}, 0)
+
+			err := service.RevokeInvitation(5, tt.userID, 7)
+			if tt.wantCode != "" {
+				assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+				return
+			}
+			if err != nil || revoked != 7 {
+				t.Fatalf("RevokeInvitation() = %v, revoked = %d", err, revoked)
+			}
+		})
+	}
+}
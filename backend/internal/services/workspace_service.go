@@ -0,0 +1,445 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/logctx"
+	"simple-notion-backend/internal/mailer"
+	"simple-notion-backend/internal/models"
+)
+
+// maxWorkspaceNameLength - ワークスペース名の最大文字数
+const maxWorkspaceNameLength = 100
+
+// workspaceRoleLabels - メールで表示する役割の名前
+var workspaceRoleLabels = map[models.WorkspaceRole]string{
+	models.WorkspaceRoleOwner:  "所有者",
+	models.WorkspaceRoleAdmin:  "管理者",
+	models.WorkspaceRoleMember: "メンバー",
+	models.WorkspaceRoleGuest:  "ゲスト（閲覧のみ）",
+}
+
+// WorkspaceService - ワークスペースとメンバーの管理、ワークスペースごとの上限の確認を担当するサービス
+// メンバーでないワークスペースは存在しないものとして扱う（ErrNotFound）
+type WorkspaceService struct {
+	workspaceRepo WorkspaceRepositoryInterface
+	userRepo      UserRepositoryInterface
+	documentLimit int             // ワークスペースあたりの文書数の上限（0 は無制限）
+	memberLimit   int             // ワークスペースあたりのメンバー数の上限（0 は無制限）
+	storageQuota  int64           // 個別に設定されていないワークスペースのストレージクォータ（0 は無制限）
+	mailer        MailerInterface // nil の場合は追加したメンバーにメールを送信しない
+}
+
+// NewWorkspaceService - WorkspaceServiceを初期化
+func NewWorkspaceService(
+	workspaceRepo WorkspaceRepositoryInterface,
+	userRepo UserRepositoryInterface,
+	documentLimit int,
+	memberLimit int,
+	storageQuota int64,
+	mailer MailerInterface,
+) *WorkspaceService {
+	return &WorkspaceService{
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		documentLimit: documentLimit,
+		memberLimit:   memberLimit,
+		storageQuota:  storageQuota,
+		mailer:        mailer,
+	}
+}
+
+// CreateWorkspace - ワークスペースを作成（作成したユーザーが所有者になる）
+func (s *WorkspaceService) CreateWorkspace(userID int, name string) (*models.Workspace, error) {
+	name, err := validateWorkspaceName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace := &models.Workspace{Name: name, OwnerID: userID}
+	if err := s.workspaceRepo.CreateWorkspace(workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// ListWorkspaces - ユーザーがメンバーのワークスペース一覧を取得
+func (s *WorkspaceService) ListWorkspaces(userID int) ([]models.Workspace, error) {
+	return s.workspaceRepo.ListWorkspaces(userID)
+}
+
+// GetWorkspace - ワークスペースを文書数・メンバー数と上限とともに取得
+func (s *WorkspaceService) GetWorkspace(workspaceID, userID int) (*models.Workspace, error) {
+	workspace, err := s.workspaceRepo.GetWorkspace(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, members, err := s.workspaceRepo.GetUsage(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	workspace.Usage = &models.WorkspaceUsage{
+		Documents:     documents,
+		DocumentLimit: s.documentLimit,
+		Members:       members,
+		MemberLimit:   s.memberLimit,
+	}
+	return workspace, nil
+}
+
+// RenameWorkspace - ワークスペースの名前を変更（所有者・管理者のみ）
+func (s *WorkspaceService) RenameWorkspace(workspaceID, userID int, name string) (*models.Workspace, error) {
+	name, err := validateWorkspaceName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.GetWorkspace(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !workspace.Role.CanManageMembers() {
+		return nil, apierror.NewForbidden("WORKSPACE_ADMIN_REQUIRED", "ワークスペースの管理者権限が必要です", nil)
+	}
+
+	if err := s.workspaceRepo.RenameWorkspace(workspaceID, name); err != nil {
+		return nil, err
+	}
+	workspace.Name = name
+	return workspace, nil
+}
+
+// DeleteWorkspace - ワークスペースを削除（所有者のみ。属していた文書は作成したユーザーの個人の文書に戻る）
+func (s *WorkspaceService) DeleteWorkspace(workspaceID, userID int) error {
+	workspace, err := s.workspaceRepo.GetWorkspace(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if workspace.Role != models.WorkspaceRoleOwner {
+		return apierror.NewForbidden("WORKSPACE_OWNER_REQUIRED", "ワークスペースの所有者のみ削除できます", nil)
+	}
+	return s.workspaceRepo.DeleteWorkspace(workspaceID)
+}
+
+// ListMembers - ワークスペースのメンバー一覧を取得（guest 以外のメンバーのみ）
+func (s *WorkspaceService) ListMembers(workspaceID, userID int) ([]models.WorkspaceMember, error) {
+	role, err := s.workspaceRepo.GetMemberRole(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == models.WorkspaceRoleGuest {
+		return nil, apierror.NewForbidden("WORKSPACE_MEMBER_REQUIRED", "ゲストはメンバー一覧を閲覧できません", nil)
+	}
+	return s.workspaceRepo.ListMembers(workspaceID)
+}
+
+// AddMember - メールアドレスで指定したユーザーをメンバーに追加（所有者・管理者のみ）
+// 管理者として追加できるのは所有者のみで、所有者として追加することはできない
+func (s *WorkspaceService) AddMember(ctx context.Context, workspaceID, userID int, email string, role models.WorkspaceRole) (*models.WorkspaceMember, error) {
+	if role == "" {
+		role = models.WorkspaceRoleMember
+	}
+	if !role.Valid() || role == models.WorkspaceRoleOwner {
+		return nil, apierror.NewValidationError("INVALID_WORKSPACE_ROLE", "role には admin・member・guest のいずれかを指定してください", nil)
+	}
+
+	actorRole, err := s.workspaceRepo.GetMemberRole(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actorRole.CanManageMembers() || (role == models.WorkspaceRoleAdmin && actorRole != models.WorkspaceRoleOwner) {
+		return nil, apierror.NewForbidden("WORKSPACE_ADMIN_REQUIRED", "メンバーを追加する権限がありません", nil)
+	}
+
+	if err := s.CheckMemberLimit(workspaceID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return nil, apierror.NewNotFound("USER_NOT_FOUND", "指定したメールアドレスのユーザーが見つかりません", err)
+		}
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	if err := s.workspaceRepo.AddMember(workspaceID, user.ID, role); err != nil {
+		if errors.Is(err, apierror.ErrConflict) {
+			return nil, apierror.NewConflict("ALREADY_WORKSPACE_MEMBER", "既にワークスペースのメンバーです", err)
+		}
+		return nil, err
+	}
+	s.mailMemberAdded(ctx, workspaceID, userID, user, role)
+
+	return &models.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      user.ID,
+		Email:       user.Email,
+		Name:        user.Name,
+		Role:        role,
+	}, nil
+}
+
+// mailMemberAdded - 追加したメンバーに、ワークスペースに追加されたことをメールで知らせる
+// メンバーの追加自体は完了しているため、失敗してもログに残すだけにする
+func (s *WorkspaceService) mailMemberAdded(ctx context.Context, workspaceID, inviterID int, member *models.User, role models.WorkspaceRole) {
+	if s.mailer == nil {
+		return
+	}
+
+	workspace, err := s.workspaceRepo.GetWorkspace(workspaceID, inviterID)
+	if err != nil {
+		logctx.Printf(ctx, "Failed to load workspace %d for member mail: %v", workspaceID, err)
+		return
+	}
+	inviterName := "ユーザー"
+	if inviter, err := s.userRepo.GetByID(inviterID); err == nil {
+		inviterName = inviter.Name
+		if inviterName == "" {
+			inviterName = inviter.Email
+		}
+	}
+
+	err = s.mailer.SendTemplate(member.Email, mailer.TemplateWorkspaceMemberAdded, map[string]string{
+		"InviterName":   inviterName,
+		"WorkspaceName": workspace.Name,
+		"Role":          workspaceRoleLabels[role],
+	})
+	if err != nil {
+		logctx.Printf(ctx, "Failed to queue member mail for workspace %d to user %d: %v", workspaceID, member.ID, err)
+	}
+}
+
+// UpdateMemberRole - メンバーの役割を admin / member / guest に変更する（所有者の役割は変更できない）
+// 所有者はすべての役割を変更でき、管理者は管理者以外のメンバーの member と guest の切り替えのみができる
+func (s *WorkspaceService) UpdateMemberRole(workspaceID, userID, memberID int, role models.WorkspaceRole) error {
+	if !role.Valid() || role == models.WorkspaceRoleOwner {
+		return apierror.NewValidationError("INVALID_WORKSPACE_ROLE", "role には admin・member・guest のいずれかを指定してください", nil)
+	}
+
+	actorRole, err := s.workspaceRepo.GetMemberRole(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !actorRole.CanManageMembers() {
+		return apierror.NewForbidden("WORKSPACE_ADMIN_REQUIRED", "役割を変更する権限がありません", nil)
+	}
+
+	memberRole, err := s.workspaceRepo.GetMemberRole(workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+	if memberRole == models.WorkspaceRoleOwner {
+		return apierror.NewValidationError("CANNOT_CHANGE_OWNER_ROLE", "所有者の役割は変更できません", nil)
+	}
+	if actorRole != models.WorkspaceRoleOwner && (memberRole == models.WorkspaceRoleAdmin || role == models.WorkspaceRoleAdmin) {
+		return apierror.NewForbidden("WORKSPACE_OWNER_REQUIRED", "管理者の任命・解任ができるのは所有者のみです", nil)
+	}
+
+	return s.workspaceRepo.UpdateMemberRole(workspaceID, memberID, role)
+}
+
+// RemoveMember - メンバーを削除する（自分自身の場合は退出）
+// 所有者は退出できない。管理者が削除できるのは member と guest のみで、管理者の削除は所有者のみができる
+func (s *WorkspaceService) RemoveMember(workspaceID, userID, memberID int) error {
+	actorRole, err := s.workspaceRepo.GetMemberRole(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	memberRole, err := s.workspaceRepo.GetMemberRole(workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case memberRole == models.WorkspaceRoleOwner:
+		return apierror.NewValidationError("CANNOT_REMOVE_OWNER", "所有者はワークスペースから削除・退出できません", nil)
+	case memberID == userID:
+		// 自分自身の退出は役割によらずできる
+	case actorRole == models.WorkspaceRoleOwner:
+	case actorRole == models.WorkspaceRoleAdmin && memberRole != models.WorkspaceRoleAdmin:
+	default:
+		return apierror.NewForbidden("WORKSPACE_ADMIN_REQUIRED", "メンバーを削除する権限がありません", nil)
+	}
+
+	return s.workspaceRepo.RemoveMember(workspaceID, memberID)
+}
+
+// MemberRole - ユーザーのワークスペースでの役割を返す（メンバーでない場合は空文字。トークンに含まれるワークスペースの確認用）
+func (s *WorkspaceService) MemberRole(workspaceID, userID int) (models.WorkspaceRole, error) {
+	role, err := s.workspaceRepo.GetMemberRole(workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// requireWorkspaceEditor - ワークスペース（nil は個人の文書）で文書の作成・変更とファイルのアップロードができるかを確認
+// メンバーでない場合と、閲覧のみの役割（guest）の場合は Forbidden を返す（checker が nil の場合は確認しない）
+func requireWorkspaceEditor(checker WorkspaceRoleCheckerInterface, workspaceID *int, userID int) error {
+	if workspaceID == nil || checker == nil {
+		return nil
+	}
+
+	role, err := checker.MemberRole(*workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return apierror.NewForbidden("WORKSPACE_ACCESS_DENIED", "このワークスペースのメンバーではありません", apierror.ErrForbidden)
+	}
+	if !role.CanEdit() {
+		return apierror.NewForbidden("WORKSPACE_READ_ONLY", "このワークスペースでは閲覧のみ許可されています", apierror.ErrForbidden)
+	}
+	return nil
+}
+
+// CheckDocumentLimit - ワークスペースに文書をもう1つ作成できるかを確認（上限に達している場合は Conflict）
+func (s *WorkspaceService) CheckDocumentLimit(workspaceID int) error {
+	if s.documentLimit <= 0 {
+		return nil
+	}
+
+	documents, _, err := s.workspaceRepo.GetUsage(workspaceID)
+	if err != nil {
+		return err
+	}
+	if documents >= s.documentLimit {
+		return apierror.NewConflict("WORKSPACE_DOCUMENT_LIMIT",
+			fmt.Sprintf("ワークスペースの文書は%d件までです（ごみ箱内の文書を含む）", s.documentLimit), nil)
+	}
+	return nil
+}
+
+// CheckMemberLimit - ワークスペースにメンバーをもう1人追加できるかを確認（上限に達している場合は Conflict）
+func (s *WorkspaceService) CheckMemberLimit(workspaceID int) error {
+	if s.memberLimit <= 0 {
+		return nil
+	}
+
+	_, members, err := s.workspaceRepo.GetUsage(workspaceID)
+	if err != nil {
+		return err
+	}
+	if members >= s.memberLimit {
+		return apierror.NewConflict("WORKSPACE_MEMBER_LIMIT",
+			fmt.Sprintf("ワークスペースのメンバーは%d人までです", s.memberLimit), nil)
+	}
+	return nil
+}
+
+// CheckStorageQuota - ワークスペースにファイルを追加できるかを確認（超える場合は ErrWorkspaceStorageQuotaExceeded）
+// 使用量は現在のメンバー全員のファイルの合計で、ユーザーごとのクォータとは別に確認する
+func (s *WorkspaceService) CheckStorageQuota(workspaceID int, newFileSize int64) error {
+	quota, _, err := s.resolveStorageQuota(workspaceID)
+	if err != nil {
+		return err
+	}
+	if quota <= 0 {
+		return nil
+	}
+
+	used, err := s.workspaceRepo.GetStorageUsedBytes(workspaceID)
+	if err != nil {
+		return err
+	}
+	if used+newFileSize > quota {
+		return ErrWorkspaceStorageQuotaExceeded
+	}
+	return nil
+}
+
+// GetStorageUsage - ワークスペースのストレージクォータと、メンバーごとの使用量を取得（guest 以外のメンバーのみ）
+func (s *WorkspaceService) GetStorageUsage(workspaceID, userID int) (*models.WorkspaceStorageUsage, error) {
+	role, err := s.workspaceRepo.GetMemberRole(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == models.WorkspaceRoleGuest {
+		return nil, apierror.NewForbidden("WORKSPACE_MEMBER_REQUIRED", "ゲストはストレージの使用量を閲覧できません", nil)
+	}
+
+	usage, err := s.StorageQuota(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.workspaceRepo.ListMemberStorageUsage(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	// 合計はメンバーごとの使用量から求める（一覧と合計が食い違わないようにする）
+	usage.UsedBytes = 0
+	usage.FileCount = 0
+	for _, member := range members {
+		usage.UsedBytes += member.UsedBytes
+		usage.FileCount += member.FileCount
+	}
+	usage.Members = members
+	return usage, nil
+}
+
+// StorageQuota - ワークスペースのストレージクォータと合計の使用量を取得（管理者向け、メンバーかどうかは確認しない）
+func (s *WorkspaceService) StorageQuota(workspaceID int) (*models.WorkspaceStorageUsage, error) {
+	quota, isDefault, err := s.resolveStorageQuota(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := s.workspaceRepo.GetStorageUsedBytes(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WorkspaceStorageUsage{
+		WorkspaceID: workspaceID,
+		QuotaBytes:  quota,
+		IsDefault:   isDefault,
+		UsedBytes:   used,
+	}, nil
+}
+
+// SetStorageQuota - ワークスペースのストレージクォータを変更（管理者向け）
+// quotaBytes が nil の場合は個別の設定を解除して既定値に戻す。0 は無制限。使用量より小さくしても既存のファイルは削除しない
+func (s *WorkspaceService) SetStorageQuota(workspaceID int, update models.StorageQuotaUpdate) (*models.WorkspaceStorageUsage, error) {
+	if update.QuotaBytes != nil && *update.QuotaBytes < 0 {
+		return nil, apierror.NewValidationError("INVALID_QUOTA", "クォータは0以上のバイト数で指定してください", nil)
+	}
+
+	if err := s.workspaceRepo.SetStorageQuota(workspaceID, update.QuotaBytes); err != nil {
+		return nil, err
+	}
+	return s.StorageQuota(workspaceID)
+}
+
+// resolveStorageQuota - ワークスペースに個別に設定されたクォータ、未設定の場合は既定値を返す
+func (s *WorkspaceService) resolveStorageQuota(workspaceID int) (quota int64, isDefault bool, err error) {
+	custom, err := s.workspaceRepo.GetStorageQuota(workspaceID)
+	if err != nil {
+		return 0, false, err
+	}
+	if custom == nil {
+		return s.storageQuota, true, nil
+	}
+	return *custom, false, nil
+}
+
+// validateWorkspaceName - ワークスペース名の前後の空白を除き、長さを検証
+func validateWorkspaceName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", apierror.NewValidationError("WORKSPACE_NAME_REQUIRED", "ワークスペース名を入力してください", nil)
+	}
+	if len([]rune(name)) > maxWorkspaceNameLength {
+		return "", apierror.NewValidationError("WORKSPACE_NAME_TOO_LONG",
+			fmt.Sprintf("ワークスペース名は%d文字以内で入力してください", maxWorkspaceNameLength), nil)
+	}
+	return name, nil
+}
@@ -0,0 +1,651 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"simple-notion-backend/internal/apierror"
+	"simple-notion-backend/internal/mailer"
+	"simple-notion-backend/internal/models"
+)
+
+// MockWorkspaceRepository - WorkspaceRepositoryのモック
+type MockWorkspaceRepository struct {
+	CreateWorkspaceFunc  func(workspace *models.Workspace) error
+	GetWorkspaceFunc     func(workspaceID, userID int) (*models.Workspace, error)
+	ListWorkspacesFunc   func(userID int) ([]models.Workspace, error)
+	RenameWorkspaceFunc  func(workspaceID int, name string) error
+	DeleteWorkspaceFunc  func(workspaceID int) error
+	ListMembersFunc      func(workspaceID int) ([]models.WorkspaceMember, error)
+	GetMemberRoleFunc    func(workspaceID, userID int) (models.WorkspaceRole, error)
+	AddMemberFunc        func(workspaceID, userID int, role models.WorkspaceRole) error
+	UpdateMemberRoleFunc func(workspaceID, userID int, role models.WorkspaceRole) error
+	RemoveMemberFunc     func(workspaceID, userID int) error
+	GetUsageFunc         func(workspaceID int) (int, int, error)
+
+	GetStorageQuotaFunc        func(workspaceID int) (*int64, error)
+	SetStorageQuotaFunc        func(workspaceID int, quota *int64) error
+	GetStorageUsedBytesFunc    func(workspaceID int) (int64, error)
+	ListMemberStorageUsageFunc func(workspaceID int) ([]models.WorkspaceMemberStorage, error)
+}
+
+func (m *MockWorkspaceRepository) CreateWorkspace(workspace *models.Workspace) error {
+	if m.CreateWorkspaceFunc != nil {
+		return m.CreateWorkspaceFunc(workspace)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) GetWorkspace(workspaceID, userID int) (*models.Workspace, error) {
+	if m.GetWorkspaceFunc != nil {
+		return m.GetWorkspaceFunc(workspaceID, userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) ListWorkspaces(userID int) ([]models.Workspace, error) {
+	if m.ListWorkspacesFunc != nil {
+		return m.ListWorkspacesFunc(userID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) RenameWorkspace(workspaceID int, name string) error {
+	if m.RenameWorkspaceFunc != nil {
+		return m.RenameWorkspaceFunc(workspaceID, name)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) DeleteWorkspace(workspaceID int) error {
+	if m.DeleteWorkspaceFunc != nil {
+		return m.DeleteWorkspaceFunc(workspaceID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) ListMembers(workspaceID int) ([]models.WorkspaceMember, error) {
+	if m.ListMembersFunc != nil {
+		return m.ListMembersFunc(workspaceID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) GetMemberRole(workspaceID, userID int) (models.WorkspaceRole, error) {
+	if m.GetMemberRoleFunc != nil {
+		return m.GetMemberRoleFunc(workspaceID, userID)
+	}
+	return "", errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) AddMember(workspaceID, userID int, role models.WorkspaceRole) error {
+	if m.AddMemberFunc != nil {
+		return m.AddMemberFunc(workspaceID, userID, role)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) UpdateMemberRole(workspaceID, userID int, role models.WorkspaceRole) error {
+	if m.UpdateMemberRoleFunc != nil {
+		return m.UpdateMemberRoleFunc(workspaceID, userID, role)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) RemoveMember(workspaceID, userID int) error {
+	if m.RemoveMemberFunc != nil {
+		return m.RemoveMemberFunc(workspaceID, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) GetUsage(workspaceID int) (int, int, error) {
+	if m.GetUsageFunc != nil {
+		return m.GetUsageFunc(workspaceID)
+	}
+	return 0, 0, errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) GetStorageQuota(workspaceID int) (*int64, error) {
+	if m.GetStorageQuotaFunc != nil {
+		return m.GetStorageQuotaFunc(workspaceID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) SetStorageQuota(workspaceID int, quota *int64) error {
+	if m.SetStorageQuotaFunc != nil {
+		return m.SetStorageQuotaFunc(workspaceID, quota)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) GetStorageUsedBytes(workspaceID int) (int64, error) {
+	if m.GetStorageUsedBytesFunc != nil {
+		return m.GetStorageUsedBytesFunc(workspaceID)
+	}
+	return 0, errors.New("not implemented")
+}
+
+func (m *MockWorkspaceRepository) ListMemberStorageUsage(workspaceID int) ([]models.WorkspaceMemberStorage, error) {
+	if m.ListMemberStorageUsageFunc != nil {
+		return m.ListMemberStorageUsageFunc(workspaceID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+// workspaceRoles - ユーザーID → 役割 で GetMemberRole を返すモックを作成する（ない場合は ErrNotFound）
+func workspaceRoles(roles map[int]models.WorkspaceRole) func(workspaceID, userID int) (models.WorkspaceRole, error) {
+	return func(workspaceID, userID int) (models.WorkspaceRole, error) {
+		role, ok := roles[userID]
+		if !ok {
+			return "", apierror.ErrNotFound
+		}
+		return role, nil
+	}
+}
+
+// MockMailer - Mailerのモック（送信待ちの列に追加したメールを記録する）
+type MockMailer struct {
+	Sent []MockMail
+	Err  error
+}
+
+// MockMail - MockMailer に送信したメール
+type MockMail struct {
+	To       string
+	Template string
+	Data     interface{}
+}
+
+func (m *MockMailer) SendTemplate(to, name string, data interface{}) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Sent = append(m.Sent, MockMail{To: to, Template: name, Data: data})
+	return nil
+}
+
+// TestWorkspaceService_CreateWorkspace - ワークスペース作成のテスト
+func TestWorkspaceService_CreateWorkspace(t *testing.T) {
+	t.Run("正常系：名前の前後の空白を除いて作成する", func(t *testing.T) {
+		repo := &MockWorkspaceRepository{
+			CreateWorkspaceFunc: func(workspace *models.Workspace) error {
+				workspace.ID = 1
+				return nil
+			},
+		}
+		service := NewWorkspaceService(repo, &MockUserRepository{}, 0, 0, 0, nil)
+
+		workspace, err := service.CreateWorkspace(1, "  Team  ")
+		if err != nil {
+			t.Fatalf("CreateWorkspace() error = %v", err)
+		}
+		if workspace.Name != "Team" || workspace.OwnerID != 1 {
+			t.Errorf("workspace = %+v, want name Team owned by 1", workspace)
+		}
+	})
+
+	t.Run("異常系：名前が空の場合はエラー", func(t *testing.T) {
+		service := NewWorkspaceService(&MockWorkspaceRepository{}, &MockUserRepository{}, 0, 0, 0, nil)
+
+		_, err := service.CreateWorkspace(1, "   ")
+		assertAppErrorCode(t, err, http.StatusBadRequest, "WORKSPACE_NAME_REQUIRED")
+	})
+}
+
+// TestWorkspaceService_AddMember - メンバー追加のテスト
+func TestWorkspaceService_AddMember(t *testing.T) {
+	roles := map[int]models.WorkspaceRole{
+		1: models.WorkspaceRoleOwner,
+		2: models.WorkspaceRoleAdmin,
+		3: models.WorkspaceRoleMember,
+	}
+	userRepo := &MockUserRepository{
+		GetByEmailFunc: func(email string) (*models.User, error) {
+			if email == "new@example.com" {
+				return &models.User{ID: 4, Email: email}, nil
+			}
+			return nil, apierror.ErrNotFound
+		},
+	}
+	newRepo := func(members int, added *models.WorkspaceRole) *MockWorkspaceRepository {
+		return &MockWorkspaceRepository{
+			GetMemberRoleFunc: workspaceRoles(roles),
+			GetUsageFunc: func(workspaceID int) (int, int, error) {
+				return 0, members, nil
+			},
+			AddMemberFunc: func(workspaceID, userID int, role models.WorkspaceRole) error {
+				if _, ok := roles[userID]; ok {
+					return apierror.ErrConflict
+				}
+				*added = role
+				return nil
+			},
+		}
+	}
+
+	t.Run("正常系：役割の省略時は member として追加する", func(t *testing.T) {
+		var added models.WorkspaceRole
+		service := NewWorkspaceService(newRepo(3, &added), userRepo, 0, 10, 0, nil)
+
+		member, err := service.AddMember(context.Background(), 1, 2, " new@example.com ", "")
+		if err != nil {
+			t.Fatalf("AddMember() error = %v", err)
+		}
+		if member.UserID != 4 || added != models.WorkspaceRoleMember {
+			t.Errorf("member = %+v, role = %q, want user 4 as member", member, added)
+		}
+	})
+
+	t.Run("正常系：管理者は guest として招待できる", func(t *testing.T) {
+		var added models.WorkspaceRole
+		service := NewWorkspaceService(newRepo(3, &added), userRepo, 0, 0, 0, nil)
+
+		if _, err := service.AddMember(context.Background(), 1, 2, "new@example.com", models.WorkspaceRoleGuest); err != nil {
+			t.Fatalf("AddMember() error = %v", err)
+		}
+		if added != models.WorkspaceRoleGuest {
+			t.Errorf("role = %q, want guest", added)
+		}
+	})
+
+	t.Run("正常系：所有者は管理者として追加できる", func(t *testing.T) {
+		var added models.WorkspaceRole
+		service := NewWorkspaceService(newRepo(3, &added), userRepo, 0, 0, 0, nil)
+
+		if _, err := service.AddMember(context.Background(), 1, 1, "new@example.com", models.WorkspaceRoleAdmin); err != nil {
+			t.Fatalf("AddMember() error = %v", err)
+		}
+		if added != models.WorkspaceRoleAdmin {
+			t.Errorf("role = %q, want admin", added)
+		}
+	})
+
+	t.Run("正常系：追加したメンバーにメールを送信する", func(t *testing.T) {
+		var added models.WorkspaceRole
+		repo := newRepo(3, &added)
+		repo.GetWorkspaceFunc = func(workspaceID, userID int) (*models.Workspace, error) {
+			return &models.Workspace{ID: workspaceID, Name: "開発チーム"}, nil
+		}
+		inviters := &MockUserRepository{
+			GetByEmailFunc: userRepo.GetByEmailFunc,
+			GetByIDFunc: func(id int) (*models.User, error) {
+				return &models.User{ID: id, Email: "admin@example.com", Name: "Admin"}, nil
+			},
+		}
+		mail := &MockMailer{}
+		service := NewWorkspaceService(repo, inviters, 0, 0, 0, mail)
+
+		if _, err := service.AddMember(context.Background(), 1, 2, "new@example.com", models.WorkspaceRoleGuest); err != nil {
+			t.Fatalf("AddMember() error = %v", err)
+		}
+		if len(mail.Sent) != 1 || mail.Sent[0].To != "new@example.com" || mail.Sent[0].Template != mailer.TemplateWorkspaceMemberAdded {
+			t.Fatalf("sent = %+v, want member added mail to new@example.com", mail.Sent)
+		}
+		data := mail.Sent[0].Data.(map[string]string)
+		if data["WorkspaceName"] != "開発チーム" || data["InviterName"] != "Admin" {
+			t.Errorf("data = %v, want workspace and inviter names", data)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		actorID    int
+		email      string
+		role       models.WorkspaceRole
+		members    int
+		wantStatus int
+		wantCode   string
+	}{
+		{"異常系：member は追加できない", 3, "new@example.com", "", 3, http.StatusForbidden, "WORKSPACE_ADMIN_REQUIRED"},
+		{"異常系：管理者は管理者を追加できない", 2, "new@example.com", models.WorkspaceRoleAdmin, 3, http.StatusForbidden, "WORKSPACE_ADMIN_REQUIRED"},
+		{"異常系：所有者として追加できない", 1, "new@example.com", models.WorkspaceRoleOwner, 3, http.StatusBadRequest, "INVALID_WORKSPACE_ROLE"},
+		{"異常系：メンバー数の上限", 1, "new@example.com", "", 10, http.StatusConflict, "WORKSPACE_MEMBER_LIMIT"},
+		{"異常系：ユーザーが存在しない", 1, "unknown@example.com", "", 3, http.StatusNotFound, "USER_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added models.WorkspaceRole
+			service := NewWorkspaceService(newRepo(tt.members, &added), userRepo, 0, 10, 0, nil)
+
+			_, err := service.AddMember(context.Background(), 1, tt.actorID, tt.email, tt.role)
+			assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+			if added != "" {
+				t.Errorf("member was added with role %q", added)
+			}
+		})
+	}
+
+	t.Run("異常系：既にメンバーの場合は Conflict", func(t *testing.T) {
+		var added models.WorkspaceRole
+		repo := newRepo(3, &added)
+		existing := &MockUserRepository{
+			GetByEmailFunc: func(email string) (*models.User, error) {
+				return &models.User{ID: 3, Email: email}, nil
+			},
+		}
+		service := NewWorkspaceService(repo, existing, 0, 0, 0, nil)
+
+		_, err := service.AddMember(context.Background(), 1, 1, "member@example.com", "")
+		assertAppErrorCode(t, err, http.StatusConflict, "ALREADY_WORKSPACE_MEMBER")
+	})
+
+	t.Run("異常系：メンバーでないワークスペースは NotFound", func(t *testing.T) {
+		var added models.WorkspaceRole
+		service := NewWorkspaceService(newRepo(3, &added), userRepo, 0, 0, 0, nil)
+
+		_, err := service.AddMember(context.Background(), 1, 99, "new@example.com", "")
+		if !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("AddMember() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// TestWorkspaceService_RemoveMember - メンバー削除・退出のテスト
+func TestWorkspaceService_RemoveMember(t *testing.T) {
+	roles := map[int]models.WorkspaceRole{
+		1: models.WorkspaceRoleOwner,
+		2: models.WorkspaceRoleAdmin,
+		3: models.WorkspaceRoleMember,
+		4: models.WorkspaceRoleAdmin,
+		5: models.WorkspaceRoleGuest,
+	}
+
+	tests := []struct {
+		name       string
+		actorID    int
+		memberID   int
+		wantStatus int
+		wantCode   string // 空の場合は成功
+	}{
+		{"正常系：所有者は管理者を削除できる", 1, 2, 0, ""},
+		{"正常系：管理者は member を削除できる", 2, 3, 0, ""},
+		{"正常系：管理者は guest を削除できる", 2, 5, 0, ""},
+		{"正常系：member は自分自身が退出できる", 3, 3, 0, ""},
+		{"異常系：所有者は退出できない", 1, 1, http.StatusBadRequest, "CANNOT_REMOVE_OWNER"},
+		{"異常系：管理者は所有者を削除できない", 2, 1, http.StatusBadRequest, "CANNOT_REMOVE_OWNER"},
+		{"異常系：管理者は他の管理者を削除できない", 2, 4, http.StatusForbidden, "WORKSPACE_ADMIN_REQUIRED"},
+		{"異常系：member は他のメンバーを削除できない", 3, 2, http.StatusForbidden, "WORKSPACE_ADMIN_REQUIRED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := 0
+			repo := &MockWorkspaceRepository{
+				GetMemberRoleFunc: workspaceRoles(roles),
+				RemoveMemberFunc: func(workspaceID, userID int) error {
+					removed = userID
+					return nil
+				},
+			}
+			service := NewWorkspaceService(repo, &MockUserRepository{}, 0, 0, 0, nil)
+
+			err := service.RemoveMember(1, tt.actorID, tt.memberID)
+			if tt.wantCode == "" {
+				if err != nil || removed != tt.memberID {
+					t.Errorf("RemoveMember() error = %v, removed = %d, want %d", err, removed, tt.memberID)
+				}
+				return
+			}
+			assertAppErrorCode(t, err, tt.wantStatus, tt.wantCode)
+			if removed != 0 {
+				t.Errorf("removed = %d, want none", removed)
+			}
+		})
+	}
+}
+
+// TestWorkspaceService_UpdateMemberRole - 役割変更のテスト
+func TestWorkspaceService_UpdateMemberRole(t *testing.T) {
+	roles := map[int]models.WorkspaceRole{
+		1: models.WorkspaceRoleOwner,
+		2: models.WorkspaceRoleAdmin,
+		3: models.WorkspaceRoleMember,
+		4: models.WorkspaceRoleAdmin,
+		5: models.WorkspaceRoleGuest,
+	}
+	newService := func(updated *models.WorkspaceRole) *WorkspaceService {
+		repo := &MockWorkspaceRepository{
+			GetMemberRoleFunc: workspaceRoles(roles),
+			UpdateMemberRoleFunc: func(workspaceID, userID int, role models.WorkspaceRole) error {
+				*updated = role
+				return nil
+			},
+		}
+		return NewWorkspaceService(repo, &MockUserRepository{}, 0, 0, 0, nil)
+	}
+
+	t.Run("正常系：所有者は member を管理者にできる", func(t *testing.T) {
+		var updated models.WorkspaceRole
+		if err := newService(&updated).UpdateMemberRole(1, 1, 3, models.WorkspaceRoleAdmin); err != nil {
+			t.Fatalf("UpdateMemberRole() error = %v", err)
+		}
+		if updated != models.WorkspaceRoleAdmin {
+			t.Errorf("role = %q, want admin", updated)
+		}
+	})
+
+	t.Run("正常系：管理者は member を guest にできる", func(t *testing.T) {
+		var updated models.WorkspaceRole
+		if err := newService(&updated).UpdateMemberRole(1, 2, 3, models.WorkspaceRoleGuest); err != nil {
+			t.Fatalf("UpdateMemberRole() error = %v", err)
+		}
+		if updated != models.WorkspaceRoleGuest {
+			t.Errorf("role = %q, want guest", updated)
+		}
+	})
+
+	t.Run("異常系：管理者は管理者を任命できない", func(t *testing.T) {
+		var updated models.WorkspaceRole
+		err := newService(&updated).UpdateMemberRole(1, 2, 3, models.WorkspaceRoleAdmin)
+		assertAppErrorCode(t, err, http.StatusForbidden, "WORKSPACE_OWNER_REQUIRED")
+	})
+
+	t.Run("異常系：管理者は他の管理者の役割を変更できない", func(t *testing.T) {
+		var updated models.WorkspaceRole
+		err := newService(&updated).UpdateMemberRole(1, 2, 4, models.WorkspaceRoleMember)
+		assertAppErrorCode(t, err, http.StatusForbidden, "WORKSPACE_OWNER_REQUIRED")
+	})
+
+	t.Run("異常系：member は役割を変更できない", func(t *testing.T) {
+		var updated models.WorkspaceRole
+		err := newService(&updated).UpdateMemberRole(1, 3, 5, models.WorkspaceRoleMember)
+		assertAppErrorCode(t, err, http.StatusForbidden, "WORKSPACE_ADMIN_REQUIRED")
+		if updated != "" {
+			t.Errorf("role was updated to %q", updated)
+		}
+	})
+
+	t.Run("異常系：所有者の役割は変更できない", func(t *testing.T) {
+		var updated models.WorkspaceRole
+		err := newService(&updated).UpdateMemberRole(1, 1, 1, models.WorkspaceRoleMember)
+		assertAppErrorCode(t, err, http.StatusBadRequest, "CANNOT_CHANGE_OWNER_ROLE")
+	})
+}
+
+// TestWorkspaceService_CheckDocumentLimit - 文書数の上限の確認のテスト
+func TestWorkspaceService_CheckDocumentLimit(t *testing.T) {
+	repo := &MockWorkspaceRepository{
+		GetUsageFunc: func(workspaceID int) (int, int, error) {
+			return 5, 1, nil
+		},
+	}
+
+	t.Run("正常系：上限が 0 の場合は確認しない", func(t *testing.T) {
+		service := NewWorkspaceService(&MockWorkspaceRepository{}, &MockUserRepository{}, 0, 0, 0, nil)
+		if err := service.CheckDocumentLimit(1); err != nil {
+			t.Errorf("CheckDocumentLimit() error = %v", err)
+		}
+	})
+
+	t.Run("正常系：上限未満", func(t *testing.T) {
+		service := NewWorkspaceService(repo, &MockUserRepository{}, 6, 0, 0, nil)
+		if err := service.CheckDocumentLimit(1); err != nil {
+			t.Errorf("CheckDocumentLimit() error = %v", err)
+		}
+	})
+
+	t.Run("異常系：上限に達している", func(t *testing.T) {
+		service := NewWorkspaceService(repo, &MockUserRepository{}, 5, 0, 0, nil)
+		assertAppErrorCode(t, service.CheckDocumentLimit(1), http.StatusConflict, "WORKSPACE_DOCUMENT_LIMIT")
+	})
+}
+
+// TestWorkspaceService_MemberRole - 役割の確認のテスト
+func TestWorkspaceService_MemberRole(t *testing.T) {
+	repo := &MockWorkspaceRepository{
+		GetMemberRoleFunc: workspaceRoles(map[int]models.WorkspaceRole{1: models.WorkspaceRoleGuest}),
+	}
+	service := NewWorkspaceService(repo, &MockUserRepository{}, 0, 0, 0, nil)
+
+	if role, err := service.MemberRole(1, 1); err != nil || role != models.WorkspaceRoleGuest {
+		t.Errorf("MemberRole(1, 1) = %q, %v, want guest", role, err)
+	}
+	if role, err := service.MemberRole(1, 2); err != nil || role != "" {
+		t.Errorf("MemberRole(1, 2) = %q, %v, want empty", role, err)
+	}
+}
+
+// TestWorkspaceService_ListMembers - メンバー一覧の取得のテスト
+func TestWorkspaceService_ListMembers(t *testing.T) {
+	repo := &MockWorkspaceRepository{
+		GetMemberRoleFunc: workspaceRoles(map[int]models.WorkspaceRole{
+			1: models.WorkspaceRoleMember,
+			2: models.WorkspaceRoleGuest,
+		}),
+		ListMembersFunc: func(workspaceID int) ([]models.WorkspaceMember, error) {
+			return []models.WorkspaceMember{{WorkspaceID: workspaceID, UserID: 1}}, nil
+		},
+	}
+	service := NewWorkspaceService(repo, &MockUserRepository{}, 0, 0, 0, nil)
+
+	t.Run("正常系：member は一覧を取得できる", func(t *testing.T) {
+		members, err := service.ListMembers(1, 1)
+		if err != nil || len(members) != 1 {
+			t.Errorf("ListMembers() = %v, %v, want 1 member", members, err)
+		}
+	})
+
+	t.Run("異常系：guest は一覧を取得できない", func(t *testing.T) {
+		_, err := service.ListMembers(1, 2)
+		assertAppErrorCode(t, err, http.StatusForbidden, "WORKSPACE_MEMBER_REQUIRED")
+	})
+}
+
+// storageRepo - ストレージクォータ（nil は未設定）と使用量を返すモックを作成する
+func storageRepo(quota *int64, used int64) *MockWorkspaceRepository {
+	return &MockWorkspaceRepository{
+		GetMemberRoleFunc: workspaceRoles(map[int]models.WorkspaceRole{
+			1: models.WorkspaceRoleOwner,
+			2: models.WorkspaceRoleGuest,
+		}),
+		GetStorageQuotaFunc:     func(workspaceID int) (*int64, error) { return quota, nil },
+		GetStorageUsedBytesFunc: func(workspaceID int) (int64, error) { return used, nil },
+		ListMemberStorageUsageFunc: func(workspaceID int) ([]models.WorkspaceMemberStorage, error) {
+			return []models.WorkspaceMemberStorage{
+				{UserID: 1, FileCount: 3, UsedBytes: used - 100},
+				{UserID: 2, FileCount: 1, UsedBytes: 100},
+			}, nil
+		},
+	}
+}
+
+// TestWorkspaceService_CheckStorageQuota - ワークスペースのストレージクォータの確認のテスト
+func TestWorkspaceService_CheckStorageQuota(t *testing.T) {
+	custom := int64(2000)
+	unlimited := int64(0)
+
+	tests := []struct {
+		name         string
+		quota        *int64
+		defaultQuota int64
+		size         int64
+		wantErr      bool
+	}{
+		{"正常系：既定値の範囲内", nil, 1500, 500, false},
+		{"異常系：既定値を超える", nil, 1500, 501, true},
+		{"正常系：個別の設定が既定値より優先される", &custom, 1500, 1000, false},
+		{"正常系：既定値が 0 の場合は無制限", nil, 0, 1 << 40, false},
+		{"正常系：個別に 0 を設定した場合は無制限", &unlimited, 1500, 1 << 40, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewWorkspaceService(storageRepo(tt.quota, 1000), &MockUserRepository{}, 0, 0, tt.defaultQuota, nil)
+
+			err := service.CheckStorageQuota(1, tt.size)
+			if tt.wantErr {
+				if !errors.Is(err, ErrWorkspaceStorageQuotaExceeded) || !errors.Is(err, ErrStorageQuotaExceeded) {
+					t.Errorf("CheckStorageQuota() error = %v, want ErrWorkspaceStorageQuotaExceeded", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("CheckStorageQuota() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestWorkspaceService_GetStorageUsage - ワークスペースのストレージ使用量の取得のテスト
+func TestWorkspaceService_GetStorageUsage(t *testing.T) {
+	service := NewWorkspaceService(storageRepo(nil, 1000), &MockUserRepository{}, 0, 0, 5000, nil)
+
+	t.Run("正常系：メンバーごとの使用量を合計する", func(t *testing.T) {
+		usage, err := service.GetStorageUsage(1, 1)
+		if err != nil {
+			t.Fatalf("GetStorageUsage() error = %v", err)
+		}
+		if usage.QuotaBytes != 5000 || !usage.IsDefault {
+			t.Errorf("quota = %d (default %v), want 5000 (default)", usage.QuotaBytes, usage.IsDefault)
+		}
+		if usage.UsedBytes != 1000 || usage.FileCount != 4 || len(usage.Members) != 2 {
+			t.Errorf("usage = %+v, want 1000 bytes, 4 files, 2 members", usage)
+		}
+	})
+
+	t.Run("異常系：guest は使用量を閲覧できない", func(t *testing.T) {
+		_, err := service.GetStorageUsage(1, 2)
+		assertAppErrorCode(t, err, http.StatusForbidden, "WORKSPACE_MEMBER_REQUIRED")
+	})
+
+	t.Run("異常系：メンバーでない場合は見つからない", func(t *testing.T) {
+		_, err := service.GetStorageUsage(1, 3)
+		if !errors.Is(err, apierror.ErrNotFound) {
+			t.Errorf("GetStorageUsage() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// TestWorkspaceService_SetStorageQuota - ワークスペースのストレージクォータの変更のテスト
+func TestWorkspaceService_SetStorageQuota(t *testing.T) {
+	t.Run("正常系：設定したクォータを返す", func(t *testing.T) {
+		var saved *int64
+		repo := storageRepo(nil, 0)
+		repo.SetStorageQuotaFunc = func(workspaceID int, quota *int64) error {
+			saved = quota
+			return nil
+		}
+		repo.GetStorageQuotaFunc = func(workspaceID int) (*int64, error) { return saved, nil }
+		service := NewWorkspaceService(repo, &MockUserRepository{}, 0, 0, 5000, nil)
+
+		quota := int64(3000)
+		usage, err := service.SetStorageQuota(1, models.StorageQuotaUpdate{QuotaBytes: &quota})
+		if err != nil {
+			t.Fatalf("SetStorageQuota() error = %v", err)
+		}
+		if usage.QuotaBytes != 3000 || usage.IsDefault {
+			t.Errorf("quota = %d (default %v), want 3000", usage.QuotaBytes, usage.IsDefault)
+		}
+	})
+
+	t.Run("異常系：負の値は指定できない", func(t *testing.T) {
+		service := NewWorkspaceService(storageRepo(nil, 0), &MockUserRepository{}, 0, 0, 5000, nil)
+
+		quota := int64(-1)
+		_, err := service.SetStorageQuota(1, models.StorageQuotaUpdate{QuotaBytes: &quota})
+		assertAppErrorCode(t, err, http.StatusBadRequest, "INVALID_QUOTA")
+	})
+}
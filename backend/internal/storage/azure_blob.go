@@ -0,0 +1,470 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureAPIVersion は 使用する Azure Blob Storage REST API のバージョンです
+const azureAPIVersion = "2021-08-06"
+
+// AzureBlobClient は Azure Blob Storage の REST API を直接呼び出す ObjectStorage の実装です
+// 認証は共有キー（SharedKey）、署名付きURLはサービス SAS で生成する
+type AzureBlobClient struct {
+	httpClient *http.Client
+	account    string
+	key        []byte
+	container  string
+	endpoint   string // 例: https://{account}.blob.core.windows.net（Azurite の場合は http://host:10000/{account}）
+}
+
+// コンパイル時にObjectStorageインターフェースを満たすことを確認
+var _ ObjectStorage = (*AzureBlobClient)(nil)
+
+// NewAzureBlobClient は 新しい AzureBlobClient インスタンスを作成します
+// accountKey はポータルに表示される Base64 形式のアクセスキー、endpoint が空の場合は既定のエンドポイントを使う
+func NewAzureBlobClient(account, accountKey, container, endpoint string) (*AzureBlobClient, error) {
+	key, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure storage account key: %w", err)
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	}
+
+	c := &AzureBlobClient{
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+		account:    account,
+		key:        key,
+		container:  container,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+	}
+
+	if err := c.EnsureBucket(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure container: %w", err)
+	}
+
+	log.Printf("Azure Blob client initialized successfully (container: %s, endpoint: %s)", container, c.endpoint)
+
+	return c, nil
+}
+
+// EnsureBucket は コンテナが存在することを確認し、存在しない場合は作成します
+func (c *AzureBlobClient) EnsureBucket(ctx context.Context) error {
+	query := url.Values{"restype": {"container"}}
+	resp, err := c.do(ctx, http.MethodPut, "", query, nil, -1, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create container: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		log.Printf("Container '%s' created successfully", c.container)
+		return nil
+	case http.StatusConflict:
+		return nil
+	default:
+		return fmt.Errorf("failed to create container: %w", azureError(resp))
+	}
+}
+
+// GetBucketName は コンテナ名を返します
+func (c *AzureBlobClient) GetBucketName() string {
+	return c.container
+}
+
+// UploadFile は ファイルをブロック BLOB としてアップロードします
+func (c *AzureBlobClient) UploadFile(ctx context.Context, fileKey string, reader io.Reader, size int64, contentType string) error {
+	headers := http.Header{}
+	headers.Set("x-ms-blob-type", "BlockBlob")
+	headers.Set("Content-Type", contentType)
+
+	resp, err := c.do(ctx, http.MethodPut, fileKey, nil, headers, size, reader)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to upload file: %w", azureError(resp))
+	}
+
+	log.Printf("File uploaded successfully: %s (size: %d bytes)", fileKey, size)
+	return nil
+}
+
+// GetObject は Azure Blob Storage からファイルを取得します
+// 戻り値のio.ReadCloserは呼び出し側でCloseする必要があります
+func (c *AzureBlobClient) GetObject(ctx context.Context, fileKey string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, fileKey, nil, nil, -1, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", fileKey, ErrObjectNotFound)
+	default:
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("failed to get object: %w", azureError(resp))
+	}
+}
+
+// GetObjectRange は Azure Blob Storage からファイルの一部を取得します
+func (c *AzureBlobClient) GetObjectRange(ctx context.Context, fileKey string, offset, length int64) (io.ReadCloser, error) {
+	headers := http.Header{}
+	headers.Set("x-ms-range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	resp, err := c.do(ctx, http.MethodGet, fileKey, nil, headers, -1, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", fileKey, ErrObjectNotFound)
+	default:
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("failed to get object: %w", azureError(resp))
+	}
+}
+
+// DeleteFile は Azure Blob Storage からファイルを削除します（存在しない場合も成功とする）
+func (c *AzureBlobClient) DeleteFile(ctx context.Context, fileKey string) error {
+	resp, err := c.do(ctx, http.MethodDelete, fileKey, nil, nil, -1, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete file: %w", azureError(resp))
+	}
+
+	log.Printf("File deleted successfully: %s", fileKey)
+	return nil
+}
+
+// StatFile は ファイルのサイズと Content-Type を取得します
+func (c *AzureBlobClient) StatFile(ctx context.Context, fileKey string) (*FileInfo, error) {
+	resp, err := c.do(ctx, http.MethodHead, fileKey, nil, nil, -1, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &FileInfo{Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", fileKey, ErrObjectNotFound)
+	default:
+		return nil, fmt.Errorf("failed to stat object: %w", azureError(resp))
+	}
+}
+
+// azureBlobList は List Blobs のレスポンスです
+type azureBlobList struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			ContentLength int64  `xml:"Content-Length"`
+			LastModified  string `xml:"Last-Modified"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// ListObjects は コンテナ内の prefix で始まるファイルの一覧を取得します
+func (c *AzureBlobClient) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	marker := ""
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil, -1, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		var list azureBlobList
+		if resp.StatusCode != http.StatusOK {
+			err = azureError(resp)
+		} else {
+			err = xml.NewDecoder(resp.Body).Decode(&list)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		for _, blob := range list.Blobs {
+			lastModified, _ := time.Parse(http.TimeFormat, blob.Properties.LastModified)
+			objects = append(objects, ObjectInfo{Key: blob.Name, Size: blob.Properties.ContentLength, LastModified: lastModified})
+		}
+		if list.NextMarker == "" {
+			return objects, nil
+		}
+		marker = list.NextMarker
+	}
+}
+
+// GetPresignedURL は 読み取り専用の SAS を付けたURLを生成します
+func (c *AzureBlobClient) GetPresignedURL(ctx context.Context, fileKey string, expires time.Duration) (string, error) {
+	return c.sasURL(fileKey, "r", time.Now().Add(expires)), nil
+}
+
+// GetPresignedUploadURL は ブラウザから直接 PUT でアップロードするための SAS を付けたURLを生成します
+// アップロード時は Content-Type に加えて x-ms-blob-type ヘッダーが必要（presignedUploadHeaders を参照）
+func (c *AzureBlobClient) GetPresignedUploadURL(ctx context.Context, fileKey string, contentType string, expires time.Duration) (string, error) {
+	return c.sasURL(fileKey, "cw", time.Now().Add(expires)), nil
+}
+
+// presignedUploadHeaders は 署名付きURLへのアップロードで Content-Type の他に必要なヘッダーを返します
+func (c *AzureBlobClient) presignedUploadHeaders() map[string]string {
+	return map[string]string{"x-ms-blob-type": "BlockBlob"}
+}
+
+// CreateMultipartUpload は 分割アップロードを開始し、アップロードIDを返します
+// Azure には開始の操作がないため、ブロックIDの接頭辞とコミット時の Content-Type をアップロードIDに含める
+func (c *AzureBlobClient) CreateMultipartUpload(ctx context.Context, fileKey string, contentType string) (string, error) {
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", fmt.Errorf("failed to generate upload id: %w", err)
+	}
+	return hex.EncodeToString(idBytes) + ":" + contentType, nil
+}
+
+// UploadPart は ブロックを1つアップロードし、ブロックIDを ETag として返します
+func (c *AzureBlobClient) UploadPart(ctx context.Context, fileKey, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	blockID := azureBlockID(uploadID, partNumber)
+	query := url.Values{"comp": {"block"}, "blockid": {blockID}}
+
+	resp, err := c.do(ctx, http.MethodPut, fileKey, query, nil, size, reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to upload part %d: %w", partNumber, azureError(resp))
+	}
+
+	return blockID, nil
+}
+
+// CompleteMultipartUpload は アップロード済みのブロックをコミットして1つのファイルにします
+func (c *AzureBlobClient) CompleteMultipartUpload(ctx context.Context, fileKey, uploadID string, parts []CompletedPart) error {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?><BlockList>`)
+	for _, part := range parts {
+		fmt.Fprintf(&body, "<Latest>%s</Latest>", part.ETag)
+	}
+	body.WriteString("</BlockList>")
+
+	headers := http.Header{}
+	if _, contentType, ok := strings.Cut(uploadID, ":"); ok {
+		headers.Set("x-ms-blob-content-type", contentType)
+	}
+
+	query := url.Values{"comp": {"blocklist"}}
+	resp, err := c.do(ctx, http.MethodPut, fileKey, query, headers, int64(body.Len()), &body)
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to complete multipart upload: %w", azureError(resp))
+	}
+
+	log.Printf("Multipart upload completed successfully: %s (parts: %d)", fileKey, len(parts))
+	return nil
+}
+
+// AbortMultipartUpload は 分割アップロードを中止します
+// コミットされていないブロックは Azure 側で一定期間（7日）後に自動的に破棄されるため、何もしない
+func (c *AzureBlobClient) AbortMultipartUpload(ctx context.Context, fileKey, uploadID string) error {
+	return nil
+}
+
+// azureBlockID は アップロードIDとパート番号からブロックIDを作成します（同じ BLOB のブロックIDは同じ長さである必要がある）
+func azureBlockID(uploadID string, partNumber int) string {
+	prefix, _, _ := strings.Cut(uploadID, ":")
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s-%05d", prefix, partNumber)))
+}
+
+// do は 共有キーで署名したリクエストを送信します（fileKey が空の場合はコンテナに対する操作）
+func (c *AzureBlobClient) do(ctx context.Context, method, fileKey string, query url.Values, headers http.Header, size int64, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.resourceURL(fileKey, query), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range headers {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+	if size >= 0 {
+		req.ContentLength = size
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("Authorization", "SharedKey "+c.account+":"+c.sharedKeySignature(req))
+
+	return c.httpClient.Do(req)
+}
+
+// resourceURL は コンテナまたは BLOB のURLを返します
+func (c *AzureBlobClient) resourceURL(fileKey string, query url.Values) string {
+	u := c.endpoint + "/" + url.PathEscape(c.container)
+	if fileKey != "" {
+		segments := strings.Split(fileKey, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		u += "/" + strings.Join(segments, "/")
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// sharedKeySignature は リクエストの共有キー署名を計算します
+// https://learn.microsoft.com/rest/api/storageservices/authorize-with-shared-key
+func (c *AzureBlobClient) sharedKeySignature(req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date（x-ms-date を使う）
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + canonicalizedHeaders(req.Header) + c.canonicalizedResource(req.URL)
+
+	return c.hmacBase64(stringToSign)
+}
+
+// canonicalizedHeaders は x-ms- で始まるヘッダーを名前順に "名前:値\n" の形式で連結します
+func canonicalizedHeaders(header http.Header) string {
+	var names []string
+	for name := range header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + strings.TrimSpace(header.Get(name)) + "\n")
+	}
+	return b.String()
+}
+
+// canonicalizedResource は "/アカウント/パス" とクエリパラメータ（名前順）を連結します
+func (c *AzureBlobClient) canonicalizedResource(u *url.URL) string {
+	var b strings.Builder
+	b.WriteString("/" + c.account + u.EscapedPath())
+
+	query := u.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		b.WriteString("\n" + strings.ToLower(name) + ":" + strings.Join(values, ","))
+	}
+	return b.String()
+}
+
+// sasURL は BLOB のサービス SAS を付けたURLを生成します
+// https://learn.microsoft.com/rest/api/storageservices/create-service-sas
+func (c *AzureBlobClient) sasURL(fileKey, permissions string, expiresAt time.Time) string {
+	expiry := expiresAt.UTC().Format("2006-01-02T15:04:05Z")
+	stringToSign := strings.Join([]string{
+		permissions,
+		"", // signedStart
+		expiry,
+		"/blob/" + c.account + "/" + c.container + "/" + fileKey,
+		"", // signedIdentifier
+		"", // signedIP
+		"", // signedProtocol
+		azureAPIVersion,
+		"b",                // signedResource
+		"",                 // signedSnapshotTime
+		"",                 // signedEncryptionScope
+		"", "", "", "", "", // rscc, rscd, rsce, rscl, rsct
+	}, "\n")
+
+	query := url.Values{}
+	query.Set("sv", azureAPIVersion)
+	query.Set("sr", "b")
+	query.Set("sp", permissions)
+	query.Set("se", expiry)
+	query.Set("sig", c.hmacBase64(stringToSign))
+	return c.resourceURL(fileKey, nil) + "?" + query.Encode()
+}
+
+// hmacBase64 は アカウントキーによる HMAC-SHA256 を Base64 で返します
+func (c *AzureBlobClient) hmacBase64(s string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(s))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// azureError は エラーレスポンスのステータスとエラーコードをエラーにします
+func azureError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var azureErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if xml.Unmarshal(body, &azureErr) == nil && azureErr.Code != "" {
+		return fmt.Errorf("azure blob storage: %s (%s)", resp.Status, azureErr.Code)
+	}
+	return fmt.Errorf("azure blob storage: %s", resp.Status)
+}
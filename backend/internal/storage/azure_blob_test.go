@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestAzureBlobClient は ハンドラーで応答するテスト用サーバーに接続した AzureBlobClient を作成します
+func newTestAzureBlobClient(t *testing.T, handler http.HandlerFunc) *AzureBlobClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// コンテナの作成（初期化時）
+		if r.URL.Query().Get("restype") == "container" && r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	key := base64.StdEncoding.EncodeToString([]byte("test-key"))
+	c, err := NewAzureBlobClient("devaccount", key, "files", server.URL+"/devaccount")
+	if err != nil {
+		t.Fatalf("NewAzureBlobClient() error = %v", err)
+	}
+	return c
+}
+
+// TestAzureBlobClient_Requests は 各操作が送る REST API のリクエストのテストです
+func TestAzureBlobClient_Requests(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("正常系：アップロード", func(t *testing.T) {
+		c := newTestAzureBlobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut || r.URL.Path != "/devaccount/files/images/1/a b.png" {
+				t.Errorf("request = %s %s", r.Method, r.URL.Path)
+			}
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" || r.Header.Get("Content-Type") != "image/png" {
+				t.Errorf("headers = %v", r.Header)
+			}
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devaccount:") {
+				t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+			}
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != "png" {
+				t.Errorf("body = %q", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+		})
+		if err := c.UploadFile(ctx, "images/1/a b.png", strings.NewReader("png"), 3, "image/png"); err != nil {
+			t.Errorf("UploadFile() error = %v", err)
+		}
+	})
+
+	t.Run("異常系：存在しないファイル", func(t *testing.T) {
+		c := newTestAzureBlobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		if _, err := c.StatFile(ctx, "files/1/missing.pdf"); !errors.Is(err, ErrObjectNotFound) {
+			t.Errorf("StatFile() error = %v, want ErrObjectNotFound", err)
+		}
+		if _, err := c.GetObject(ctx, "files/1/missing.pdf"); !errors.Is(err, ErrObjectNotFound) {
+			t.Errorf("GetObject() error = %v, want ErrObjectNotFound", err)
+		}
+	})
+
+	t.Run("正常系：一覧（続きのページを取得する）", func(t *testing.T) {
+		c := newTestAzureBlobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("prefix") != "files/" {
+				t.Errorf("prefix = %q", r.URL.Query().Get("prefix"))
+			}
+			if r.URL.Query().Get("marker") == "" {
+				io.WriteString(w, `<EnumerationResults><Blobs><Blob><Name>files/1/a.pdf</Name><Properties><Content-Length>10</Content-Length></Properties></Blob></Blobs><NextMarker>next</NextMarker></EnumerationResults>`)
+				return
+			}
+			io.WriteString(w, `<EnumerationResults><Blobs><Blob><Name>files/1/b.pdf</Name><Properties><Content-Length>20</Content-Length></Properties></Blob></Blobs><NextMarker/></EnumerationResults>`)
+		})
+		objects, err := c.ListObjects(ctx, "files/")
+		if err != nil {
+			t.Fatalf("ListObjects() error = %v", err)
+		}
+		if len(objects) != 2 || objects[0].Key != "files/1/a.pdf" || objects[1].Size != 20 {
+			t.Errorf("ListObjects() = %+v", objects)
+		}
+	})
+
+	t.Run("正常系：分割アップロード", func(t *testing.T) {
+		var blockList string
+		c := newTestAzureBlobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Query().Get("comp") {
+			case "block":
+				w.WriteHeader(http.StatusCreated)
+			case "blocklist":
+				if r.Header.Get("x-ms-blob-content-type") != "video/mp4" {
+					t.Errorf("x-ms-blob-content-type = %q", r.Header.Get("x-ms-blob-content-type"))
+				}
+				body, _ := io.ReadAll(r.Body)
+				blockList = string(body)
+				w.WriteHeader(http.StatusCreated)
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			}
+		})
+
+		uploadID, err := c.CreateMultipartUpload(ctx, "files/1/v.mp4", "video/mp4")
+		if err != nil {
+			t.Fatalf("CreateMultipartUpload() error = %v", err)
+		}
+		etag1, err := c.UploadPart(ctx, "files/1/v.mp4", uploadID, 1, strings.NewReader("a"), 1)
+		if err != nil {
+			t.Fatalf("UploadPart() error = %v", err)
+		}
+		etag2, err := c.UploadPart(ctx, "files/1/v.mp4", uploadID, 2, strings.NewReader("b"), 1)
+		if err != nil {
+			t.Fatalf("UploadPart() error = %v", err)
+		}
+		if len(etag1) != len(etag2) {
+			t.Errorf("block ids must have the same length: %q %q", etag1, etag2)
+		}
+
+		parts := []CompletedPart{{PartNumber: 1, ETag: etag1}, {PartNumber: 2, ETag: etag2}}
+		if err := c.CompleteMultipartUpload(ctx, "files/1/v.mp4", uploadID, parts); err != nil {
+			t.Fatalf("CompleteMultipartUpload() error = %v", err)
+		}
+		if !strings.Contains(blockList, "<Latest>"+etag1+"</Latest><Latest>"+etag2+"</Latest>") {
+			t.Errorf("block list = %s", blockList)
+		}
+	})
+}
+
+// TestAzureBlobClient_SASURL は 署名付きURL（サービス SAS）のテストです
+func TestAzureBlobClient_SASURL(t *testing.T) {
+	c := newTestAzureBlobClient(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	presigned, err := c.GetPresignedUploadURL(context.Background(), "files/1/a.pdf", "application/pdf", time.Hour)
+	if err != nil {
+		t.Fatalf("GetPresignedUploadURL() error = %v", err)
+	}
+	parsed, err := url.Parse(presigned)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+
+	query := parsed.Query()
+	if parsed.Path != "/devaccount/files/files/1/a.pdf" || query.Get("sp") != "cw" || query.Get("sr") != "b" || query.Get("sig") == "" {
+		t.Errorf("presigned url = %s", presigned)
+	}
+
+	headers := PresignedUploadHeaders(c, "application/pdf")
+	if headers["Content-Type"] != "application/pdf" || headers["x-ms-blob-type"] != "BlockBlob" {
+		t.Errorf("PresignedUploadHeaders() = %v", headers)
+	}
+}
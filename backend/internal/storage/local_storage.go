@@ -0,0 +1,440 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LocalStorageBucketName は ローカルディスクに保存したファイルのメタデータに記録するバケット名です
+const LocalStorageBucketName = "local"
+
+// ローカルディスクのルートディレクトリ直下の作業用ディレクトリ（"." で始まるためファイルキーとは衝突しない）
+const (
+	localMetaDir      = ".meta"      // ファイルの Content-Type
+	localMultipartDir = ".multipart" // 分割アップロードのパート
+	localTempDir      = ".tmp"       // 書き込み中のファイル
+)
+
+// ErrInvalidObjectKey は ルートディレクトリの外を指す・作業用ディレクトリを指すファイルキーのエラーです
+var ErrInvalidObjectKey = errors.New("invalid object key")
+
+// LocalStorage は ファイルをローカルディスクに保存する ObjectStorage の実装です
+// MinIO/S3 を用意しない小規模な環境向けで、署名付きURLはアプリケーション自身（SignedURLPrefix）で配信する
+type LocalStorage struct {
+	rootDir string
+	signer  urlSigner
+}
+
+// コンパイル時にSignedURLStorageインターフェースを満たすことを確認
+var _ SignedURLStorage = (*LocalStorage)(nil)
+
+// NewLocalStorage は 新しい LocalStorage インスタンスを作成します
+// signingKey は署名付きURLの署名に使用する
+func NewLocalStorage(rootDir, baseURL string, signingKey []byte) (*LocalStorage, error) {
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage directory: %w", err)
+	}
+
+	s := &LocalStorage{
+		rootDir: absRoot,
+		signer:  newURLSigner(baseURL, signingKey),
+	}
+
+	if err := s.EnsureBucket(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure storage directory: %w", err)
+	}
+
+	log.Printf("Local storage initialized successfully (dir: %s)", absRoot)
+
+	return s, nil
+}
+
+// EnsureBucket は ルートディレクトリと作業用ディレクトリを作成します
+func (s *LocalStorage) EnsureBucket(ctx context.Context) error {
+	for _, dir := range []string{s.rootDir, s.workDir(localMetaDir), s.workDir(localMultipartDir), s.workDir(localTempDir)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// GetBucketName は バケット名（LocalStorageBucketName）を返します
+func (s *LocalStorage) GetBucketName() string {
+	return LocalStorageBucketName
+}
+
+// UploadFile は ファイルをローカルディスクに保存します
+func (s *LocalStorage) UploadFile(ctx context.Context, fileKey string, reader io.Reader, size int64, contentType string) error {
+	target, err := s.objectPath(fileKey)
+	if err != nil {
+		return err
+	}
+
+	written, err := s.writeFile(target, reader)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	if size >= 0 && written != size {
+		os.Remove(target)
+		return fmt.Errorf("failed to upload file: wrote %d bytes, expected %d", written, size)
+	}
+
+	if err := s.writeContentType(fileKey, contentType); err != nil {
+		return err
+	}
+
+	log.Printf("File uploaded successfully: %s (size: %d bytes)", fileKey, written)
+	return nil
+}
+
+// GetObject は ローカルディスクからファイルを取得します
+// 戻り値のio.ReadCloserは呼び出し側でCloseする必要があります
+func (s *LocalStorage) GetObject(ctx context.Context, fileKey string) (io.ReadCloser, error) {
+	return s.openObject(fileKey)
+}
+
+// openObject は ファイルを読み込み用に開きます
+func (s *LocalStorage) openObject(fileKey string) (*os.File, error) {
+	target, err := s.objectPath(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %w", fileKey, ErrObjectNotFound)
+		}
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	return file, nil
+}
+
+// GetObjectRange は ローカルディスクからファイルの一部を取得します
+func (s *LocalStorage) GetObjectRange(ctx context.Context, fileKey string, offset, length int64) (io.ReadCloser, error) {
+	file, err := s.openObject(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	return &limitedReadCloser{Reader: io.LimitReader(file, length), Closer: file}, nil
+}
+
+// limitedReadCloser は 読み込む長さを制限したファイルです
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// DeleteFile は ローカルディスクからファイルを削除します（存在しない場合も成功とする）
+func (s *LocalStorage) DeleteFile(ctx context.Context, fileKey string) error {
+	target, err := s.objectPath(fileKey)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	if err := os.Remove(s.metaPath(fileKey)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file content type: %w", err)
+	}
+
+	log.Printf("File deleted successfully: %s", fileKey)
+	return nil
+}
+
+// StatFile は ファイルのサイズと Content-Type を取得します
+func (s *LocalStorage) StatFile(ctx context.Context, fileKey string) (*FileInfo, error) {
+	target, err := s.objectPath(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %w", fileKey, ErrObjectNotFound)
+		}
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	contentType, err := os.ReadFile(s.metaPath(fileKey))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read file content type: %w", err)
+	}
+
+	return &FileInfo{Size: info.Size(), ContentType: string(contentType)}, nil
+}
+
+// ListObjects は prefix で始まるファイルの一覧をファイルキー順に取得します
+func (s *LocalStorage) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(s.rootDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != s.rootDir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.rootDir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// GetPresignedURL は ファイルを取得するための署名付きURLを生成します
+func (s *LocalStorage) GetPresignedURL(ctx context.Context, fileKey string, expires time.Duration) (string, error) {
+	if _, err := s.objectPath(fileKey); err != nil {
+		return "", err
+	}
+	return s.signer.signedURL(http.MethodGet, fileKey, "", time.Now().Add(expires)), nil
+}
+
+// GetPresignedUploadURL は ブラウザから直接 PUT でアップロードするための署名付きURLを生成します
+func (s *LocalStorage) GetPresignedUploadURL(ctx context.Context, fileKey string, contentType string, expires time.Duration) (string, error) {
+	if _, err := s.objectPath(fileKey); err != nil {
+		return "", err
+	}
+	return s.signer.signedURL(http.MethodPut, fileKey, contentType, time.Now().Add(expires)), nil
+}
+
+// VerifySignedURL は 署名付きURLのクエリ（expires / signature）を検証します
+// PUT の場合は contentType にリクエストの Content-Type ヘッダーを指定する
+func (s *LocalStorage) VerifySignedURL(method, fileKey, contentType string, query url.Values) error {
+	return s.signer.verify(method, fileKey, contentType, query)
+}
+
+// CreateMultipartUpload は 分割アップロードを開始し、アップロードIDを返します
+func (s *LocalStorage) CreateMultipartUpload(ctx context.Context, fileKey string, contentType string) (string, error) {
+	if _, err := s.objectPath(fileKey); err != nil {
+		return "", err
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", fmt.Errorf("failed to generate upload id: %w", err)
+	}
+	uploadID := hex.EncodeToString(idBytes)
+
+	dir := s.workDir(localMultipartDir, uploadID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "content-type"), []byte(contentType), 0o644); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+// UploadPart は 分割アップロードのパートを1つ保存し、ETag（内容の MD5）を返します
+func (s *LocalStorage) UploadPart(ctx context.Context, fileKey, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	dir, err := s.multipartDir(uploadID)
+	if err != nil {
+		return "", err
+	}
+
+	hash := md5.New()
+	written, err := s.writeFile(partPath(dir, partNumber), io.TeeReader(reader, hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+	if written != size {
+		return "", fmt.Errorf("failed to upload part %d: wrote %d bytes, expected %d", partNumber, written, size)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// CompleteMultipartUpload は 保存済みのパートを順に結合して1つのファイルにします
+func (s *LocalStorage) CompleteMultipartUpload(ctx context.Context, fileKey, uploadID string, parts []CompletedPart) error {
+	target, err := s.objectPath(fileKey)
+	if err != nil {
+		return err
+	}
+	dir, err := s.multipartDir(uploadID)
+	if err != nil {
+		return err
+	}
+
+	contentType, err := os.ReadFile(filepath.Join(dir, "content-type"))
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(concatParts(pw, dir, parts))
+	}()
+	if _, err := s.writeFile(target, pr); err != nil {
+		pr.CloseWithError(err)
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	if err := s.writeContentType(fileKey, string(contentType)); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf("failed to remove multipart upload %s: %v", uploadID, err)
+	}
+
+	log.Printf("Multipart upload completed successfully: %s (parts: %d)", fileKey, len(parts))
+	return nil
+}
+
+// AbortMultipartUpload は 分割アップロードを中止し、保存済みのパートを削除します
+func (s *LocalStorage) AbortMultipartUpload(ctx context.Context, fileKey, uploadID string) error {
+	dir, err := s.multipartDir(uploadID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+// concatParts は パートを指定された順に w へ書き出します（ETag が一致しないパートがあればエラー）
+func concatParts(w io.Writer, dir string, parts []CompletedPart) error {
+	for _, part := range parts {
+		file, err := os.Open(partPath(dir, part.PartNumber))
+		if err != nil {
+			return fmt.Errorf("part %d: %w", part.PartNumber, err)
+		}
+
+		hash := md5.New()
+		_, err = io.Copy(io.MultiWriter(w, hash), file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("part %d: %w", part.PartNumber, err)
+		}
+		if hex.EncodeToString(hash.Sum(nil)) != strings.Trim(part.ETag, `"`) {
+			return fmt.Errorf("part %d: etag mismatch", part.PartNumber)
+		}
+	}
+	return nil
+}
+
+// partPath は 分割アップロードのパートのファイルパスを返します
+func partPath(dir string, partNumber int) string {
+	return filepath.Join(dir, fmt.Sprintf("part-%05d", partNumber))
+}
+
+// objectPath は ファイルキーに対応するファイルパスを返します
+// 正規化されていないキー、ルートディレクトリの外や作業用ディレクトリ（"." で始まる要素）を指すキーは ErrInvalidObjectKey
+func (s *LocalStorage) objectPath(fileKey string) (string, error) {
+	if fileKey == "" || path.Clean(fileKey) != fileKey || path.IsAbs(fileKey) || strings.Contains(fileKey, `\`) {
+		return "", fmt.Errorf("%q: %w", fileKey, ErrInvalidObjectKey)
+	}
+	for _, segment := range strings.Split(fileKey, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return "", fmt.Errorf("%q: %w", fileKey, ErrInvalidObjectKey)
+		}
+	}
+	return filepath.Join(s.rootDir, filepath.FromSlash(fileKey)), nil
+}
+
+// metaPath は ファイルの Content-Type を保存するファイルパスを返します
+func (s *LocalStorage) metaPath(fileKey string) string {
+	return filepath.Join(s.workDir(localMetaDir), filepath.FromSlash(fileKey))
+}
+
+// multipartDir は 分割アップロードのパートを保存するディレクトリを返します
+func (s *LocalStorage) multipartDir(uploadID string) (string, error) {
+	if _, err := hex.DecodeString(uploadID); err != nil || uploadID == "" {
+		return "", fmt.Errorf("invalid upload id %q", uploadID)
+	}
+	dir := s.workDir(localMultipartDir, uploadID)
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("multipart upload %s: %w", uploadID, ErrObjectNotFound)
+	}
+	return dir, nil
+}
+
+// workDir は ルートディレクトリ直下の作業用ディレクトリのパスを返します
+func (s *LocalStorage) workDir(elem ...string) string {
+	return filepath.Join(append([]string{s.rootDir}, elem...)...)
+}
+
+// writeFile は reader の内容を一時ファイルに書き込んでから target に置き換えます（書き込み途中のファイルを読ませない）
+func (s *LocalStorage) writeFile(target string, reader io.Reader) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(s.workDir(localTempDir), "upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, err
+	}
+
+	return written, os.Rename(tmp.Name(), target)
+}
+
+// writeContentType は ファイルの Content-Type を保存します
+func (s *LocalStorage) writeContentType(fileKey, contentType string) error {
+	metaPath := s.metaPath(fileKey)
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0o755); err != nil {
+		return fmt.Errorf("failed to save file content type: %w", err)
+	}
+	if err := os.WriteFile(metaPath, []byte(contentType), 0o644); err != nil {
+		return fmt.Errorf("failed to save file content type: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorage {
+	t.Helper()
+	s, err := NewLocalStorage(t.TempDir(), "", []byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+	return s
+}
+
+// TestLocalStorage_UploadGetDelete は 保存・取得・一覧・削除の一連の操作のテストです
+func TestLocalStorage_UploadGetDelete(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStorage(t)
+
+	if err := s.UploadFile(ctx, "files/1/a.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+
+	info, err := s.StatFile(ctx, "files/1/a.txt")
+	if err != nil {
+		t.Fatalf("StatFile() error = %v", err)
+	}
+	if info.Size != 5 || info.ContentType != "text/plain" {
+		t.Errorf("StatFile() = %+v, want size 5 text/plain", info)
+	}
+
+	object, err := s.GetObject(ctx, "files/1/a.txt")
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	data, _ := io.ReadAll(object)
+	object.Close()
+	if string(data) != "hello" {
+		t.Errorf("GetObject() = %q, want %q", data, "hello")
+	}
+
+	object, err = s.GetObjectRange(ctx, "files/1/a.txt", 1, 10)
+	if err != nil {
+		t.Fatalf("GetObjectRange() error = %v", err)
+	}
+	data, _ = io.ReadAll(object)
+	object.Close()
+	if string(data) != "ello" {
+		t.Errorf("GetObjectRange() = %q, want %q", data, "ello")
+	}
+
+	objects, err := s.ListObjects(ctx, "files/")
+	if err != nil {
+		t.Fatalf("ListObjects() error = %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != "files/1/a.txt" {
+		t.Errorf("ListObjects() = %+v, want only files/1/a.txt", objects)
+	}
+
+	if err := s.DeleteFile(ctx, "files/1/a.txt"); err != nil {
+		t.Fatalf("DeleteFile() error = %v", err)
+	}
+	if _, err := s.StatFile(ctx, "files/1/a.txt"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("StatFile() after delete error = %v, want ErrObjectNotFound", err)
+	}
+}
+
+// TestLocalStorage_ObjectPath は ファイルキーの検証のテストです
+func TestLocalStorage_ObjectPath(t *testing.T) {
+	s := newTestLocalStorage(t)
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "正常系：通常のキー", key: "images/1/uuid_a.png", wantErr: false},
+		{name: "異常系：空のキー", key: "", wantErr: true},
+		{name: "異常系：親ディレクトリへの参照", key: "../etc/passwd", wantErr: true},
+		{name: "異常系：途中の親ディレクトリへの参照", key: "images/../../a.png", wantErr: true},
+		{name: "異常系：絶対パス", key: "/etc/passwd", wantErr: true},
+		{name: "異常系：作業用ディレクトリ", key: ".meta/images/a.png", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.objectPath(tt.key)
+			if tt.wantErr != errors.Is(err, ErrInvalidObjectKey) {
+				t.Errorf("objectPath(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestLocalStorage_SignedURL は 署名付きURLの生成と検証のテストです
+func TestLocalStorage_SignedURL(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStorage(t)
+
+	uploadURL, err := s.GetPresignedUploadURL(ctx, "files/1/a b.pdf", "application/pdf", time.Hour)
+	if err != nil {
+		t.Fatalf("GetPresignedUploadURL() error = %v", err)
+	}
+	parsed, err := url.Parse(uploadURL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if parsed.Path != SignedURLPrefix+"files/1/a b.pdf" {
+		t.Errorf("path = %q, want %q", parsed.Path, SignedURLPrefix+"files/1/a b.pdf")
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		key         string
+		contentType string
+		query       url.Values
+		wantErr     bool
+	}{
+		{name: "正常系：発行時と同じ条件", method: http.MethodPut, key: "files/1/a b.pdf", contentType: "application/pdf", query: parsed.Query()},
+		{name: "異常系：メソッドが異なる", method: http.MethodGet, key: "files/1/a b.pdf", contentType: "application/pdf", query: parsed.Query(), wantErr: true},
+		{name: "異常系：Content-Type が異なる", method: http.MethodPut, key: "files/1/a b.pdf", contentType: "text/html", query: parsed.Query(), wantErr: true},
+		{name: "異常系：キーが異なる", method: http.MethodPut, key: "files/1/other.pdf", contentType: "application/pdf", query: parsed.Query(), wantErr: true},
+		{name: "異常系：署名なし", method: http.MethodPut, key: "files/1/a b.pdf", contentType: "application/pdf", query: url.Values{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.VerifySignedURL(tt.method, tt.key, tt.contentType, tt.query)
+			if tt.wantErr != errors.Is(err, ErrInvalidSignature) || (!tt.wantErr && err != nil) {
+				t.Errorf("VerifySignedURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("異常系：有効期限切れ", func(t *testing.T) {
+		expired, _ := s.GetPresignedURL(ctx, "files/1/a.pdf", -time.Minute)
+		parsed, _ := url.Parse(expired)
+		if err := s.VerifySignedURL(http.MethodGet, "files/1/a.pdf", "", parsed.Query()); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifySignedURL() error = %v, want ErrInvalidSignature", err)
+		}
+	})
+}
+
+// TestLocalStorage_MultipartUpload は 分割アップロードのパートの結合のテストです
+func TestLocalStorage_MultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStorage(t)
+
+	uploadID, err := s.CreateMultipartUpload(ctx, "files/1/big.bin", "application/octet-stream")
+	if err != nil {
+		t.Fatalf("CreateMultipartUpload() error = %v", err)
+	}
+
+	// パートは順不同で届いても番号順に結合される
+	etag2, err := s.UploadPart(ctx, "files/1/big.bin", uploadID, 2, strings.NewReader("world"), 5)
+	if err != nil {
+		t.Fatalf("UploadPart(2) error = %v", err)
+	}
+	etag1, err := s.UploadPart(ctx, "files/1/big.bin", uploadID, 1, strings.NewReader("hello "), 6)
+	if err != nil {
+		t.Fatalf("UploadPart(1) error = %v", err)
+	}
+
+	parts := []CompletedPart{{PartNumber: 1, ETag: etag1}, {PartNumber: 2, ETag: etag2}}
+	if err := s.CompleteMultipartUpload(ctx, "files/1/big.bin", uploadID, parts); err != nil {
+		t.Fatalf("CompleteMultipartUpload() error = %v", err)
+	}
+
+	object, err := s.GetObject(ctx, "files/1/big.bin")
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	defer object.Close()
+	data, _ := io.ReadAll(object)
+	if string(data) != "hello world" {
+		t.Errorf("GetObject() = %q, want %q", data, "hello world")
+	}
+
+	if err := s.AbortMultipartUpload(ctx, "files/1/big.bin", uploadID); err == nil {
+		t.Error("AbortMultipartUpload() after complete should fail")
+	}
+}
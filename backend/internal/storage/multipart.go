@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+const (
+	// multipartUploadThreshold は UploadFile を分割アップロードに切り替えるファイルサイズです
+	multipartUploadThreshold = 64 << 20
+	// minMultipartPartSize は 分割アップロードの1パートのサイズです（最後のパートを除く）
+	minMultipartPartSize = 16 << 20
+	// maxMultipartParts は 分割アップロードのパート数の上限です（S3 の制限）
+	maxMultipartParts = 10000
+	// multipartPartAttempts は 1つのパートのアップロードを試みる回数です
+	multipartPartAttempts = 3
+)
+
+// multipartRetryDelay は パートを再送するまでの待ち時間です（再送のたびに倍にする）
+var multipartRetryDelay = time.Second
+
+// multipartUploader は 分割アップロードに対応したストレージです
+type multipartUploader interface {
+	CreateMultipartUpload(ctx context.Context, fileKey string, contentType string) (string, error)
+	UploadPart(ctx context.Context, fileKey, uploadID string, partNumber int, reader io.Reader, size int64) (string, error)
+	CompleteMultipartUpload(ctx context.Context, fileKey, uploadID string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, fileKey, uploadID string) error
+}
+
+// multipartPartSize は size バイトのファイルをパート数の上限に収まるように分割する際の1パートのサイズです
+func multipartPartSize(size int64) int64 {
+	partSize := int64(minMultipartPartSize)
+	if size > partSize*maxMultipartParts {
+		partSize = (size + maxMultipartParts - 1) / maxMultipartParts
+	}
+	return partSize
+}
+
+// uploadMultipart は reader の内容を partSize バイトずつのパートに分けてアップロードします
+// 失敗したパートはそのパートだけを再送し、再送しても失敗した場合は分割アップロードを中止する
+func uploadMultipart(ctx context.Context, u multipartUploader, fileKey string, reader io.Reader, size int64, contentType string, partSize int64) error {
+	uploadID, err := u.CreateMultipartUpload(ctx, fileKey, contentType)
+	if err != nil {
+		return err
+	}
+
+	if err := uploadParts(ctx, u, fileKey, uploadID, reader, size, partSize); err != nil {
+		// リクエストがキャンセルされていてもアップロード済みのパートは破棄する
+		if abortErr := u.AbortMultipartUpload(context.WithoutCancel(ctx), fileKey, uploadID); abortErr != nil {
+			log.Printf("Failed to abort multipart upload %s: %v", fileKey, abortErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// uploadParts は パートを順にアップロードし、すべて揃ったら結合します
+func uploadParts(ctx context.Context, u multipartUploader, fileKey, uploadID string, reader io.Reader, size int64, partSize int64) error {
+	// 再送できるように、1パート分をメモリに読み込んでから送る
+	buf := make([]byte, min(partSize, size))
+	parts := make([]CompletedPart, 0, (size+partSize-1)/partSize)
+
+	for offset, partNumber := int64(0), 1; offset < size; partNumber++ {
+		n, err := io.ReadFull(reader, buf[:min(partSize, size-offset)])
+		if err != nil {
+			return fmt.Errorf("failed to read part %d: %w", partNumber, err)
+		}
+
+		etag, err := uploadPartWithRetry(ctx, u, fileKey, uploadID, partNumber, buf[:n])
+		if err != nil {
+			return err
+		}
+		parts = append(parts, CompletedPart{PartNumber: partNumber, ETag: etag})
+		offset += int64(n)
+	}
+
+	return u.CompleteMultipartUpload(ctx, fileKey, uploadID, parts)
+}
+
+// uploadPartWithRetry は 1つのパートを最大 multipartPartAttempts 回まで送ります
+func uploadPartWithRetry(ctx context.Context, u multipartUploader, fileKey, uploadID string, partNumber int, data []byte) (string, error) {
+	delay := multipartRetryDelay
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		var etag string
+		etag, err = u.UploadPart(ctx, fileKey, uploadID, partNumber, bytes.NewReader(data), int64(len(data)))
+		if err == nil {
+			return etag, nil
+		}
+		if attempt == multipartPartAttempts {
+			break
+		}
+
+		log.Printf("Retrying part %d of %s (attempt %d): %v", partNumber, fileKey, attempt, err)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	return "", fmt.Errorf("part %d failed after %d attempts: %w", partNumber, multipartPartAttempts, err)
+}
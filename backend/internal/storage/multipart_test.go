@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// flakyUploader は 指定した回数だけパートのアップロードに失敗するストレージです
+type flakyUploader struct {
+	*LocalStorage
+	failures map[int]int // パート番号ごとの残りの失敗回数
+	attempts map[int]int
+	aborted  bool
+}
+
+func (f *flakyUploader) UploadPart(ctx context.Context, fileKey, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	f.attempts[partNumber]++
+	if f.failures[partNumber] > 0 {
+		f.failures[partNumber]--
+		// 途中まで読んだ状態で失敗させる（再送時は先頭から送り直す必要がある）
+		_, _ = io.CopyN(io.Discard, reader, 1)
+		return "", errors.New("connection reset")
+	}
+	return f.LocalStorage.UploadPart(ctx, fileKey, uploadID, partNumber, reader, size)
+}
+
+func (f *flakyUploader) AbortMultipartUpload(ctx context.Context, fileKey, uploadID string) error {
+	f.aborted = true
+	return f.LocalStorage.AbortMultipartUpload(ctx, fileKey, uploadID)
+}
+
+// TestUploadMultipart は パート単位の再送を含む分割アップロードのテストです
+func TestUploadMultipart(t *testing.T) {
+	multipartRetryDelay = 0
+	const content = "0123456789abcdefghij"
+
+	tests := []struct {
+		name        string
+		failures    map[int]int
+		wantErr     bool
+		wantAttempt map[int]int
+	}{
+		{
+			name:        "正常系：失敗なし",
+			failures:    map[int]int{},
+			wantAttempt: map[int]int{1: 1, 2: 1, 3: 1},
+		},
+		{
+			name:        "正常系：失敗したパートだけを再送",
+			failures:    map[int]int{2: multipartPartAttempts - 1},
+			wantAttempt: map[int]int{1: 1, 2: multipartPartAttempts, 3: 1},
+		},
+		{
+			name:        "異常系：再送しても失敗した場合は中止",
+			failures:    map[int]int{2: multipartPartAttempts},
+			wantErr:     true,
+			wantAttempt: map[int]int{1: 1, 2: multipartPartAttempts},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			u := &flakyUploader{
+				LocalStorage: newTestLocalStorage(t),
+				failures:     tt.failures,
+				attempts:     map[int]int{},
+			}
+
+			err := uploadMultipart(ctx, u, "files/1/large.bin", strings.NewReader(content), int64(len(content)), "application/octet-stream", 8)
+
+			for part, want := range tt.wantAttempt {
+				if u.attempts[part] != want {
+					t.Errorf("attempts for part %d = %d, want %d", part, u.attempts[part], want)
+				}
+			}
+			if len(u.attempts) != len(tt.wantAttempt) {
+				t.Errorf("attempted parts = %v, want %v", u.attempts, tt.wantAttempt)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("uploadMultipart() error = nil, want error")
+				}
+				if !u.aborted {
+					t.Error("failed multipart upload should be aborted")
+				}
+				if _, err := u.StatFile(ctx, "files/1/large.bin"); !errors.Is(err, ErrObjectNotFound) {
+					t.Errorf("StatFile() error = %v, want ErrObjectNotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("uploadMultipart() error = %v", err)
+			}
+
+			object, err := u.GetObject(ctx, "files/1/large.bin")
+			if err != nil {
+				t.Fatalf("GetObject() error = %v", err)
+			}
+			defer object.Close()
+			data, _ := io.ReadAll(object)
+			if string(data) != content {
+				t.Errorf("uploaded content = %q, want %q", data, content)
+			}
+		})
+	}
+}
+
+// TestMultipartPartSize は パート数の上限に収まるパートサイズの計算のテストです
+func TestMultipartPartSize(t *testing.T) {
+	if got := multipartPartSize(500 << 20); got != minMultipartPartSize {
+		t.Errorf("multipartPartSize(500MB) = %d, want %d", got, minMultipartPartSize)
+	}
+
+	size := int64(minMultipartPartSize)*maxMultipartParts + 1
+	partSize := multipartPartSize(size)
+	if (size+partSize-1)/partSize > maxMultipartParts {
+		t.Errorf("multipartPartSize(%d) = %d exceeds %d parts", size, partSize, maxMultipartParts)
+	}
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProxyStorage は 署名付きURLをストレージではなくアプリケーション自身（SignedURLPrefix）に向ける ObjectStorage です
+// MinIO/S3 にブラウザから接続できない環境向けで、ファイルの取得・直接アップロードはバックエンドが中継する
+// 署名付きURL以外の操作は元のストレージをそのまま使う
+type ProxyStorage struct {
+	ObjectStorage
+	signer urlSigner
+}
+
+// コンパイル時にSignedURLStorageインターフェースを満たすことを確認
+var _ SignedURLStorage = (*ProxyStorage)(nil)
+
+// NewProxyStorage は objectStorage への接続を中継する ProxyStorage インスタンスを作成します
+// baseURL は署名付きURLの前に付けるURL（空の場合は相対パス）、signingKey は署名に使用する
+func NewProxyStorage(objectStorage ObjectStorage, baseURL string, signingKey []byte) *ProxyStorage {
+	return &ProxyStorage{
+		ObjectStorage: objectStorage,
+		signer:        newURLSigner(baseURL, signingKey),
+	}
+}
+
+// GetPresignedURL は バックエンドを経由してファイルを取得する署名付きURLを生成します
+func (p *ProxyStorage) GetPresignedURL(ctx context.Context, fileKey string, expires time.Duration) (string, error) {
+	if err := validateProxyKey(fileKey); err != nil {
+		return "", err
+	}
+	return p.signer.signedURL(http.MethodGet, fileKey, "", time.Now().Add(expires)), nil
+}
+
+// GetPresignedUploadURL は バックエンドを経由して直接アップロードする署名付きURLを生成します
+func (p *ProxyStorage) GetPresignedUploadURL(ctx context.Context, fileKey string, contentType string, expires time.Duration) (string, error) {
+	if err := validateProxyKey(fileKey); err != nil {
+		return "", err
+	}
+	return p.signer.signedURL(http.MethodPut, fileKey, contentType, time.Now().Add(expires)), nil
+}
+
+// VerifySignedURL は 署名付きURLのクエリ（expires / signature）を検証します
+func (p *ProxyStorage) VerifySignedURL(method, fileKey, contentType string, query url.Values) error {
+	if err := validateProxyKey(fileKey); err != nil {
+		return err
+	}
+	return p.signer.verify(method, fileKey, contentType, query)
+}
+
+// validateProxyKey は 空のファイルキーと、".." などのパスとして解釈されるセグメントを含むファイルキーを拒否します
+func validateProxyKey(fileKey string) error {
+	if fileKey == "" {
+		return fmt.Errorf("empty key: %w", ErrInvalidObjectKey)
+	}
+	for _, segment := range strings.Split(fileKey, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("key %q: %w", fileKey, ErrInvalidObjectKey)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProxyStorage_SignedURL は バックエンドを経由する署名付きURLの生成と検証のテストです
+func TestProxyStorage_SignedURL(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestLocalStorage(t)
+	p := NewProxyStorage(backend, "https://app.example.com/", []byte("proxy-secret"))
+
+	getURL, err := p.GetPresignedURL(ctx, "images/1/a.png", time.Hour)
+	if err != nil {
+		t.Fatalf("GetPresignedURL() error = %v", err)
+	}
+	parsed, err := url.Parse(getURL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if parsed.Host != "app.example.com" || parsed.Path != SignedURLPrefix+"images/1/a.png" {
+		t.Errorf("GetPresignedURL() = %q, want app.example.com%simages/1/a.png", getURL, SignedURLPrefix)
+	}
+	if err := p.VerifySignedURL(http.MethodGet, "images/1/a.png", "", parsed.Query()); err != nil {
+		t.Errorf("VerifySignedURL() error = %v", err)
+	}
+
+	// 署名は ProxyStorage の鍵で行われ、元のストレージの鍵では検証できない
+	if err := backend.VerifySignedURL(http.MethodGet, "images/1/a.png", "", parsed.Query()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("backend VerifySignedURL() error = %v, want ErrInvalidSignature", err)
+	}
+
+	t.Run("異常系：有効期限切れ", func(t *testing.T) {
+		expired, _ := p.GetPresignedURL(ctx, "images/1/a.png", -time.Minute)
+		parsed, _ := url.Parse(expired)
+		if err := p.VerifySignedURL(http.MethodGet, "images/1/a.png", "", parsed.Query()); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifySignedURL() error = %v, want ErrInvalidSignature", err)
+		}
+	})
+
+	t.Run("異常系：アップロード用URLの Content-Type が異なる", func(t *testing.T) {
+		uploadURL, err := p.GetPresignedUploadURL(ctx, "files/1/a.pdf", "application/pdf", time.Hour)
+		if err != nil {
+			t.Fatalf("GetPresignedUploadURL() error = %v", err)
+		}
+		parsed, _ := url.Parse(uploadURL)
+		if err := p.VerifySignedURL(http.MethodPut, "files/1/a.pdf", "text/html", parsed.Query()); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifySignedURL() error = %v, want ErrInvalidSignature", err)
+		}
+	})
+}
+
+// TestProxyStorage_InvalidKey は パスとして解釈されるファイルキーを拒否するテストです
+func TestProxyStorage_InvalidKey(t *testing.T) {
+	p := NewProxyStorage(newTestLocalStorage(t), "", []byte("proxy-secret"))
+
+	for _, key := range []string{"", "images/../a.png", "/images/a.png", "images/./a.png"} {
+		if _, err := p.GetPresignedURL(context.Background(), key, time.Hour); !errors.Is(err, ErrInvalidObjectKey) {
+			t.Errorf("GetPresignedURL(%q) error = %v, want ErrInvalidObjectKey", key, err)
+		}
+	}
+}
+
+// TestProxyStorage_Delegates は 署名付きURL以外の操作が元のストレージに委譲されるテストです
+func TestProxyStorage_Delegates(t *testing.T) {
+	ctx := context.Background()
+	p := NewProxyStorage(newTestLocalStorage(t), "", []byte("proxy-secret"))
+
+	if err := p.UploadFile(ctx, "files/1/a.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	object, err := p.GetObjectRange(ctx, "files/1/a.txt", 1, 3)
+	if err != nil {
+		t.Fatalf("GetObjectRange() error = %v", err)
+	}
+	defer object.Close()
+	data, _ := io.ReadAll(object)
+	if string(data) != "ell" {
+		t.Errorf("GetObjectRange() = %q, want %q", data, "ell")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -18,7 +19,7 @@ type S3Client struct {
 	client           *minio.Client
 	bucketName       string
 	region           string
-	externalEndpoint string // ブラウザからアクセス可能なエンドポイント
+	externalEndpoint string // ブラウザからアクセス可能なエンドポイント（空の場合は置換しない）
 }
 
 // コンパイル時にObjectStorageインターフェースを満たすことを確認
@@ -57,6 +58,37 @@ func NewS3Client(endpoint, accessKey, secretKey, bucketName, region string, useS
 	return s3Client, nil
 }
 
+// gcsEndpoint は Google Cloud Storage の S3 互換（XML API）エンドポイントです
+const gcsEndpoint = "storage.googleapis.com"
+
+// NewGCSClient は Google Cloud Storage に接続する S3Client インスタンスを作成します
+// GCS の XML API は S3 互換のため、HMAC キー（アクセスID とシークレット）で S3Client をそのまま使う
+// 署名付きURLは GCS のエンドポイントのまま返す
+func NewGCSClient(accessID, secret, bucketName, region string) (*S3Client, error) {
+	minioClient, err := minio.New(gcsEndpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessID, secret, ""),
+		Secure: true,
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcs client: %w", err)
+	}
+
+	gcsClient := &S3Client{
+		client:     minioClient,
+		bucketName: bucketName,
+		region:     region,
+	}
+
+	if err := gcsClient.EnsureBucket(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure bucket: %w", err)
+	}
+
+	log.Printf("GCS Client initialized successfully (bucket: %s)", bucketName)
+
+	return gcsClient, nil
+}
+
 // EnsureBucket は バケットが存在することを確認し、存在しない場合は作成します
 func (s *S3Client) EnsureBucket(ctx context.Context) error {
 	// バケットの存在確認
@@ -83,7 +115,18 @@ func (s *S3Client) EnsureBucket(ctx context.Context) error {
 }
 
 // UploadFile は ファイルを MinIO/S3 にアップロードします
+// multipartUploadThreshold を超えるファイルは分割アップロードし、失敗したパートだけを再送する
 func (s *S3Client) UploadFile(ctx context.Context, fileKey string, reader io.Reader, size int64, contentType string) error {
+	if size > multipartUploadThreshold {
+		partSize := multipartPartSize(size)
+		if err := uploadMultipart(ctx, s, fileKey, reader, size, contentType, partSize); err != nil {
+			return fmt.Errorf("failed to upload file: %w", err)
+		}
+
+		log.Printf("File uploaded successfully: %s (size: %d bytes, part size: %d bytes)", fileKey, size, partSize)
+		return nil
+	}
+
 	_, err := s.client.PutObject(ctx, s.bucketName, fileKey, reader, size, minio.PutObjectOptions{
 		ContentType: contentType,
 	})
@@ -106,6 +149,21 @@ func (s *S3Client) GetObject(ctx context.Context, fileKey string) (io.ReadCloser
 	return object, nil
 }
 
+// GetObjectRange は MinIO/S3 からファイルの一部を取得します
+func (s *S3Client) GetObjectRange(ctx context.Context, fileKey string, offset, length int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(offset, offset+length-1); err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucketName, fileKey, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	return object, nil
+}
+
 // DeleteFile は MinIO/S3 からファイルを削除します
 func (s *S3Client) DeleteFile(ctx context.Context, fileKey string) error {
 	err := s.client.RemoveObject(ctx, s.bucketName, fileKey, minio.RemoveObjectOptions{})
@@ -136,8 +194,43 @@ func (s *S3Client) GetPresignedURL(ctx context.Context, fileKey string, expires
 	return convertedURL, nil
 }
 
+// GetPresignedUploadURL は ブラウザから直接 PUT でアップロードするための署名付きURLを生成します
+func (s *S3Client) GetPresignedUploadURL(ctx context.Context, fileKey string, contentType string, expires time.Duration) (string, error) {
+	// Content-Type を署名に含め、発行時と異なる種類のファイルをアップロードできないようにする
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+	presignedURL, err := s.client.PresignHeader(ctx, http.MethodPut, s.bucketName, fileKey, expires, nil, headers)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned upload URL: %w", err)
+	}
+
+	convertedURL, err := s.convertToExternalURL(presignedURL.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to convert to external URL: %w", err)
+	}
+
+	return convertedURL, nil
+}
+
+// StatFile は ファイルのサイズと Content-Type を取得します
+func (s *S3Client) StatFile(ctx context.Context, fileKey string) (*FileInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucketName, fileKey, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%s: %w", fileKey, ErrObjectNotFound)
+		}
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	return &FileInfo{Size: info.Size, ContentType: info.ContentType}, nil
+}
+
 // convertToExternalURL は 内部エンドポイントを外部エンドポイントに変換します
 func (s *S3Client) convertToExternalURL(internalURL string) (string, error) {
+	if s.externalEndpoint == "" {
+		return internalURL, nil
+	}
+
 	parsedURL, err := url.Parse(internalURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse URL: %w", err)
@@ -166,17 +259,73 @@ func (s *S3Client) StatObject(ctx context.Context, fileKey string) (*minio.Objec
 	return &info, nil
 }
 
-// ListObjects は バケット内のファイル一覧を取得します
-func (s *S3Client) ListObjects(ctx context.Context, prefix string) <-chan minio.ObjectInfo {
-	objectCh := s.client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{
+// ListObjects は バケット内の prefix で始まるファイルの一覧を取得します
+func (s *S3Client) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for object := range s.client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{
 		Prefix:    prefix,
 		Recursive: true,
-	})
+	}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", object.Err)
+		}
+		objects = append(objects, ObjectInfo{Key: object.Key, Size: object.Size, LastModified: object.LastModified})
+	}
 
-	return objectCh
+	return objects, nil
 }
 
 // GetBucketName は バケット名を返します
 func (s *S3Client) GetBucketName() string {
 	return s.bucketName
 }
+
+// CreateMultipartUpload は 分割アップロードを開始し、アップロードIDを返します
+func (s *S3Client) CreateMultipartUpload(ctx context.Context, fileKey string, contentType string) (string, error) {
+	core := minio.Core{Client: s.client}
+	uploadID, err := core.NewMultipartUpload(ctx, s.bucketName, fileKey, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+// UploadPart は 分割アップロードのパートを1つアップロードし、ETag を返します
+func (s *S3Client) UploadPart(ctx context.Context, fileKey, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	core := minio.Core{Client: s.client}
+	part, err := core.PutObjectPart(ctx, s.bucketName, fileKey, uploadID, partNumber, reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+
+	return part.ETag, nil
+}
+
+// CompleteMultipartUpload は アップロード済みのパートを結合して1つのファイルにします
+func (s *S3Client) CompleteMultipartUpload(ctx context.Context, fileKey, uploadID string, parts []CompletedPart) error {
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+
+	core := minio.Core{Client: s.client}
+	if _, err := core.CompleteMultipartUpload(ctx, s.bucketName, fileKey, uploadID, completeParts, minio.PutObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	log.Printf("Multipart upload completed successfully: %s (parts: %d)", fileKey, len(parts))
+	return nil
+}
+
+// AbortMultipartUpload は 分割アップロードを中止し、アップロード済みのパートを破棄します
+func (s *S3Client) AbortMultipartUpload(ctx context.Context, fileKey, uploadID string) error {
+	core := minio.Core{Client: s.client}
+	if err := core.AbortMultipartUpload(ctx, s.bucketName, fileKey, uploadID); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignedURLPrefix は アプリケーション自身が署名付きURLでファイルを配信・受け付けるパスです
+const SignedURLPrefix = "/api/storage/objects/"
+
+// ErrInvalidSignature は 署名付きURLの署名が一致しない・有効期限が切れている場合のエラーです
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// SignedURLStorage は 署名付きURLをアプリケーション自身（SignedURLPrefix）で配信する ObjectStorage です
+// 実装: LocalStorage（ローカルディスク）、ProxyStorage（ストレージへの接続をバックエンドが中継する）
+type SignedURLStorage interface {
+	ObjectStorage
+
+	// VerifySignedURL は 署名付きURLのクエリ（expires / signature）を検証します
+	// PUT の場合は contentType にリクエストの Content-Type ヘッダーを指定する
+	VerifySignedURL(method, fileKey, contentType string, query url.Values) error
+}
+
+// urlSigner は SignedURLPrefix 配下の署名付きURLを作成・検証します
+type urlSigner struct {
+	baseURL    string // 署名付きURLの前に付けるURL（空の場合は相対パス）
+	signingKey []byte
+}
+
+func newURLSigner(baseURL string, signingKey []byte) urlSigner {
+	return urlSigner{baseURL: strings.TrimSuffix(baseURL, "/"), signingKey: signingKey}
+}
+
+// signedURL は SignedURLPrefix 配下の署名付きURLを生成します
+func (s urlSigner) signedURL(method, fileKey, contentType string, expiresAt time.Time) string {
+	segments := strings.Split(fileKey, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", hex.EncodeToString(s.sign(method, fileKey, contentType, expiresAt.Unix())))
+
+	return s.baseURL + SignedURLPrefix + strings.Join(segments, "/") + "?" + query.Encode()
+}
+
+// verify は 署名付きURLのクエリ（expires / signature）を検証します
+func (s urlSigner) verify(method, fileKey, contentType string, query url.Values) error {
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing expires: %w", ErrInvalidSignature)
+	}
+	if time.Now().Unix() > expiresAt {
+		return fmt.Errorf("url expired at %d: %w", expiresAt, ErrInvalidSignature)
+	}
+
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil || !hmac.Equal(signature, s.sign(method, fileKey, contentType, expiresAt)) {
+		return fmt.Errorf("signature mismatch: %w", ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+// sign は メソッド・ファイルキー・Content-Type・有効期限に対する署名を計算します
+func (s urlSigner) sign(method, fileKey, contentType string, expiresAt int64) []byte {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "local-storage\n%s\n%s\n%s\n%d", method, fileKey, contentType, expiresAt)
+	return mac.Sum(nil)
+}
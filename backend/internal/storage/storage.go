@@ -2,12 +2,16 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"io"
 	"time"
 )
 
+// ErrObjectNotFound は ストレージにファイルが存在しない場合のエラーです
+var ErrObjectNotFound = errors.New("object not found")
+
 // ObjectStorage は オブジェクトストレージ操作の抽象インターフェースです
-// S3互換ストレージ（MinIO、RustFS、AWS S3等）の実装を切り替え可能にします
+// S3互換ストレージ（MinIO、RustFS、AWS S3等）とローカルディスクの実装を切り替え可能にします
 type ObjectStorage interface {
 	// UploadFile は ファイルをストレージにアップロードします
 	// fileKey: ストレージ内でのファイルパス（例: "images/1/uuid_filename.jpg"）
@@ -20,14 +24,29 @@ type ObjectStorage interface {
 	// 戻り値のio.ReadCloserは呼び出し側でCloseする必要があります
 	GetObject(ctx context.Context, fileKey string) (io.ReadCloser, error)
 
+	// GetObjectRange は ファイルの offset バイト目から length バイトを取得します（動画の部分配信などに使う）
+	// ファイルの末尾を超える範囲は末尾までを返します。戻り値のio.ReadCloserは呼び出し側でCloseする必要があります
+	GetObjectRange(ctx context.Context, fileKey string, offset, length int64) (io.ReadCloser, error)
+
 	// DeleteFile は ストレージからファイルを削除します
 	DeleteFile(ctx context.Context, fileKey string) error
 
+	// ListObjects は prefix で始まるファイルの一覧を取得します
+	ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error)
+
 	// GetPresignedURL は 署名付きURLを生成します
 	// 戻り値はブラウザからアクセス可能なURLである必要があります
 	// expires: URLの有効期限
 	GetPresignedURL(ctx context.Context, fileKey string, expires time.Duration) (string, error)
 
+	// GetPresignedUploadURL は ブラウザから直接 PUT でアップロードするための署名付きURLを生成します
+	// contentType は署名に含まれ、アップロード時に同じ Content-Type ヘッダーを送る必要があります
+	GetPresignedUploadURL(ctx context.Context, fileKey string, contentType string, expires time.Duration) (string, error)
+
+	// StatFile は ファイルのサイズと Content-Type を取得します
+	// ファイルが存在しない場合は ErrObjectNotFound を返します
+	StatFile(ctx context.Context, fileKey string) (*FileInfo, error)
+
 	// GetBucketName は バケット名を返します
 	// FileMetadata保存時にバケット名を記録するために使用します
 	GetBucketName() string
@@ -35,4 +54,52 @@ type ObjectStorage interface {
 	// EnsureBucket は バケットの存在を確認し、存在しない場合は作成します
 	// 初期化時に呼び出されます
 	EnsureBucket(ctx context.Context) error
+
+	// CreateMultipartUpload は 分割アップロードを開始し、アップロードIDを返します
+	CreateMultipartUpload(ctx context.Context, fileKey string, contentType string) (string, error)
+
+	// UploadPart は 分割アップロードのパートを1つアップロードし、ETag を返します
+	// partNumber: 1 から始まるパート番号（同じ番号で再送した場合は上書きされる）
+	UploadPart(ctx context.Context, fileKey, uploadID string, partNumber int, reader io.Reader, size int64) (string, error)
+
+	// CompleteMultipartUpload は アップロード済みのパートを結合して1つのファイルにします
+	CompleteMultipartUpload(ctx context.Context, fileKey, uploadID string, parts []CompletedPart) error
+
+	// AbortMultipartUpload は 分割アップロードを中止し、アップロード済みのパートを破棄します
+	AbortMultipartUpload(ctx context.Context, fileKey, uploadID string) error
+}
+
+// CompletedPart は 分割アップロードで結合するパートです
+type CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// FileInfo は ストレージ上のファイルの情報です
+type FileInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// ObjectInfo は ファイル一覧の1件です
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// uploadHeaderProvider は 署名付きURLへのアップロードで Content-Type 以外のヘッダーを必要とするストレージです
+type uploadHeaderProvider interface {
+	presignedUploadHeaders() map[string]string
+}
+
+// PresignedUploadHeaders は GetPresignedUploadURL で発行したURLへアップロードする際に送る必要のあるヘッダーを返します
+func PresignedUploadHeaders(s ObjectStorage, contentType string) map[string]string {
+	headers := map[string]string{"Content-Type": contentType}
+	if provider, ok := s.(uploadHeaderProvider); ok {
+		for name, value := range provider.presignedUploadHeaders() {
+			headers[name] = value
+		}
+	}
+	return headers
 }
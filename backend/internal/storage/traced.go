@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"simple-notion-backend/internal/tracing"
+)
+
+// TracedStorage は ストレージの操作をスパンとして記録する ObjectStorage です
+// スパンは Context にスパンがある呼び出し（リクエストの処理中）でのみ記録する
+type TracedStorage struct {
+	ObjectStorage
+}
+
+// コンパイル時にObjectStorageインターフェースを満たすことを確認
+var _ ObjectStorage = (*TracedStorage)(nil)
+
+// NewTracedStorage は objectStorage の操作を記録する TracedStorage インスタンスを作成します
+func NewTracedStorage(objectStorage ObjectStorage) *TracedStorage {
+	return &TracedStorage{ObjectStorage: objectStorage}
+}
+
+// start は 操作のスパンを開始します
+func (t *TracedStorage) start(ctx context.Context, operation, fileKey string) (context.Context, *tracing.Span) {
+	attrs := []tracing.Attribute{
+		tracing.String("storage.operation", operation),
+		tracing.String("storage.bucket", t.ObjectStorage.GetBucketName()),
+	}
+	if fileKey != "" {
+		attrs = append(attrs, tracing.String("storage.key", fileKey))
+	}
+	return tracing.StartChild(ctx, "storage."+operation, tracing.SpanKindClient, attrs...)
+}
+
+// finishSpan は 操作のスパンを終了します（ファイルが存在しない応答は失敗として扱わない）
+func finishSpan(span *tracing.Span, err error) {
+	if err != nil && !errors.Is(err, ErrObjectNotFound) {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+func (t *TracedStorage) UploadFile(ctx context.Context, fileKey string, reader io.Reader, size int64, contentType string) (err error) {
+	ctx, span := t.start(ctx, "UploadFile", fileKey)
+	span.SetAttributes(tracing.Int64("storage.size", size))
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.UploadFile(ctx, fileKey, reader, size, contentType)
+}
+
+// GetObject は ファイルを取得します（スパンは内容の読み取りを待たずに、応答を受け取った時点で終了する）
+func (t *TracedStorage) GetObject(ctx context.Context, fileKey string) (_ io.ReadCloser, err error) {
+	ctx, span := t.start(ctx, "GetObject", fileKey)
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.GetObject(ctx, fileKey)
+}
+
+func (t *TracedStorage) GetObjectRange(ctx context.Context, fileKey string, offset, length int64) (_ io.ReadCloser, err error) {
+	ctx, span := t.start(ctx, "GetObjectRange", fileKey)
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.GetObjectRange(ctx, fileKey, offset, length)
+}
+
+func (t *TracedStorage) DeleteFile(ctx context.Context, fileKey string) (err error) {
+	ctx, span := t.start(ctx, "DeleteFile", fileKey)
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.DeleteFile(ctx, fileKey)
+}
+
+func (t *TracedStorage) ListObjects(ctx context.Context, prefix string) (_ []ObjectInfo, err error) {
+	ctx, span := t.start(ctx, "ListObjects", "")
+	span.SetAttributes(tracing.String("storage.prefix", prefix))
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.ListObjects(ctx, prefix)
+}
+
+func (t *TracedStorage) GetPresignedURL(ctx context.Context, fileKey string, expires time.Duration) (_ string, err error) {
+	ctx, span := t.start(ctx, "GetPresignedURL", fileKey)
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.GetPresignedURL(ctx, fileKey, expires)
+}
+
+func (t *TracedStorage) GetPresignedUploadURL(ctx context.Context, fileKey string, contentType string, expires time.Duration) (_ string, err error) {
+	ctx, span := t.start(ctx, "GetPresignedUploadURL", fileKey)
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.GetPresignedUploadURL(ctx, fileKey, contentType, expires)
+}
+
+func (t *TracedStorage) StatFile(ctx context.Context, fileKey string) (_ *FileInfo, err error) {
+	ctx, span := t.start(ctx, "StatFile", fileKey)
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.StatFile(ctx, fileKey)
+}
+
+func (t *TracedStorage) EnsureBucket(ctx context.Context) (err error) {
+	ctx, span := t.start(ctx, "EnsureBucket", "")
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.EnsureBucket(ctx)
+}
+
+func (t *TracedStorage) CreateMultipartUpload(ctx context.Context, fileKey string, contentType string) (_ string, err error) {
+	ctx, span := t.start(ctx, "CreateMultipartUpload", fileKey)
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.CreateMultipartUpload(ctx, fileKey, contentType)
+}
+
+func (t *TracedStorage) UploadPart(ctx context.Context, fileKey, uploadID string, partNumber int, reader io.Reader, size int64) (_ string, err error) {
+	ctx, span := t.start(ctx, "UploadPart", fileKey)
+	span.SetAttributes(tracing.Int("storage.part_number", partNumber), tracing.Int64("storage.size", size))
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.UploadPart(ctx, fileKey, uploadID, partNumber, reader, size)
+}
+
+func (t *TracedStorage) CompleteMultipartUpload(ctx context.Context, fileKey, uploadID string, parts []CompletedPart) (err error) {
+	ctx, span := t.start(ctx, "CompleteMultipartUpload", fileKey)
+	span.SetAttributes(tracing.Int("storage.parts", len(parts)))
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.CompleteMultipartUpload(ctx, fileKey, uploadID, parts)
+}
+
+func (t *TracedStorage) AbortMultipartUpload(ctx context.Context, fileKey, uploadID string) (err error) {
+	ctx, span := t.start(ctx, "AbortMultipartUpload", fileKey)
+	defer func() { finishSpan(span, err) }()
+	return t.ObjectStorage.AbortMultipartUpload(ctx, fileKey, uploadID)
+}
+
+// presignedUploadHeaders は 元のストレージが必要とするアップロード時のヘッダーを返します
+func (t *TracedStorage) presignedUploadHeaders() map[string]string {
+	if provider, ok := t.ObjectStorage.(uploadHeaderProvider); ok {
+		return provider.presignedUploadHeaders()
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package thumbnail は アップロードされた画像の縮小版（サムネイル）の作成を提供します
+//
+// 縮小は標準ライブラリのみで行い、出力先の1ピクセルに対応する元画像の範囲を平均する（面積平均法）。
+// 透過を含む可能性がある PNG / GIF は PNG、それ以外は JPEG で出力する。
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// MaxSourcePixels は サムネイルを作成する元画像の最大ピクセル数です（展開時のメモリ使用量を抑える）
+const MaxSourcePixels = 40_000_000
+
+// jpegQuality は JPEG で出力する際の品質です
+const jpegQuality = 85
+
+// ErrImageTooLarge は 元画像のピクセル数が MaxSourcePixels を超える場合のエラーです
+var ErrImageTooLarge = errors.New("image too large for thumbnail")
+
+// Thumbnail は 作成したサムネイルです
+type Thumbnail struct {
+	Data        []byte
+	ContentType string
+	Ext         string // ストレージのキーに付ける拡張子（"." を含む）
+	Width       int
+	Height      int
+}
+
+// Generate は 画像を長辺が maxSize 以下になるよう縮小したサムネイルを作成します
+// 元画像が maxSize より小さい場合は拡大せず、そのままの大きさで再エンコードする
+func Generate(r io.Reader, maxSize int) (*Thumbnail, error) {
+	src, format, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := fitSize(src.Bounds().Dx(), src.Bounds().Dy(), maxSize)
+	dst := Resize(src, width, height)
+
+	thumb := &Thumbnail{Width: width, Height: height}
+	var out bytes.Buffer
+	if format == "png" || format == "gif" {
+		err = png.Encode(&out, dst)
+		thumb.ContentType, thumb.Ext = "image/png", ".png"
+	} else {
+		err = jpeg.Encode(&out, dst, &jpeg.Options{Quality: jpegQuality})
+		thumb.ContentType, thumb.Ext = "image/jpeg", ".jpg"
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	thumb.Data = out.Bytes()
+	return thumb, nil
+}
+
+// Decode は 画像をデコードし、画像と形式名（"png" / "jpeg" など）を返します
+// ピクセル数が MaxSourcePixels を超える画像は展開せずに ErrImageTooLarge を返す
+func Decode(r io.Reader) (image.Image, string, error) {
+	var buf bytes.Buffer
+	config, format, err := image.DecodeConfig(io.TeeReader(r, &buf))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image config: %w", err)
+	}
+	if config.Width*config.Height > MaxSourcePixels {
+		return nil, "", fmt.Errorf("%dx%d: %w", config.Width, config.Height, ErrImageTooLarge)
+	}
+
+	src, _, err := image.Decode(io.MultiReader(&buf, r))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	return src, format, nil
+}
+
+// fitSize は 縦横比を保ったまま長辺が maxSize 以下になる大きさを返します（拡大はしない）
+func fitSize(width, height, maxSize int) (int, int) {
+	if width <= maxSize && height <= maxSize {
+		return width, height
+	}
+	if width >= height {
+		return maxSize, max(1, height*maxSize/width)
+	}
+	return max(1, width*maxSize/height), maxSize
+}
+
+// Resize は 画像を width x height に縮小します（面積平均法）
+func Resize(src image.Image, width, height int) *image.NRGBA {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcH/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcW/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/width)
+
+			// 透過部分の色が混ざらないよう、乗算済みアルファのまま平均する
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
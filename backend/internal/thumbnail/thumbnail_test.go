@@ -0,0 +1,99 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("正常系：長辺を上限に合わせて縦横比を保つ", func(t *testing.T) {
+		src := image.NewRGBA(image.Rect(0, 0, 400, 100))
+		thumb, err := Generate(bytes.NewReader(encodePNG(t, src)), 200)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if thumb.Width != 200 || thumb.Height != 50 {
+			t.Errorf("size = %dx%d, want 200x50", thumb.Width, thumb.Height)
+		}
+		if thumb.ContentType != "image/png" || thumb.Ext != ".png" {
+			t.Errorf("format = %s %s, want image/png .png", thumb.ContentType, thumb.Ext)
+		}
+		decoded, err := png.Decode(bytes.NewReader(thumb.Data))
+		if err != nil {
+			t.Fatalf("png.Decode() error = %v", err)
+		}
+		if decoded.Bounds().Dx() != 200 || decoded.Bounds().Dy() != 50 {
+			t.Errorf("decoded size = %v", decoded.Bounds())
+		}
+	})
+
+	t.Run("正常系：小さい画像は拡大しない", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 30, 60)), nil); err != nil {
+			t.Fatalf("jpeg.Encode() error = %v", err)
+		}
+		thumb, err := Generate(&buf, 200)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if thumb.Width != 30 || thumb.Height != 60 || thumb.ContentType != "image/jpeg" {
+			t.Errorf("thumb = %dx%d %s, want 30x60 image/jpeg", thumb.Width, thumb.Height, thumb.ContentType)
+		}
+	})
+
+	t.Run("異常系：画像ではない", func(t *testing.T) {
+		if _, err := Generate(bytes.NewReader([]byte("not an image")), 200); err == nil {
+			t.Error("Generate() expected error")
+		}
+	})
+
+	t.Run("異常系：ピクセル数の上限超過", func(t *testing.T) {
+		// 展開せずにヘッダーだけで判定されることを確認するため、小さい画像のヘッダーを書き換える
+		data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+		// IHDR の幅・高さ（ビッグエンディアン）を 10000x10000 にし、チャンクの CRC を計算し直す
+		copy(data[16:24], []byte{0, 0, 0x27, 0x10, 0, 0, 0x27, 0x10})
+		binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+		_, err := Generate(bytes.NewReader(data), 200)
+		if !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("Generate() error = %v, want ErrImageTooLarge", err)
+		}
+	})
+}
+
+func TestResize(t *testing.T) {
+	// 左半分が黒、右半分が白の画像を 2x1 に縮小すると左右の色が保たれる
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			c := color.RGBA{A: 255}
+			if x >= 2 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+
+	dst := Resize(src, 2, 1)
+	if got := dst.NRGBAAt(0, 0); got != (color.NRGBA{A: 255}) {
+		t.Errorf("left = %v, want black", got)
+	}
+	if got := dst.NRGBAAt(1, 0); got != (color.NRGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("right = %v, want white", got)
+	}
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader は W3C Trace Context で呼び出し元のトレースを伝えるヘッダーです
+const TraceparentHeader = "traceparent"
+
+// remoteParent は 呼び出し元（ロードバランサー・フロントエンドなど）から引き継いだスパンです
+type remoteParent struct {
+	traceID TraceID
+	spanID  SpanID
+	sampled bool
+}
+
+type remoteKey struct{}
+
+// Extract は ヘッダーの traceparent を読み取り、次に Start するスパンが呼び出し元のトレースを引き継ぐように設定します
+// traceparent がない・形式が不正な場合は ctx をそのまま返す（新しいトレースを開始する）
+func Extract(ctx context.Context, header http.Header) context.Context {
+	parent, ok := parseTraceparent(header.Get(TraceparentHeader))
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, parent)
+}
+
+// remoteFromContext は Extract で設定した呼び出し元のスパンを返します
+func remoteFromContext(ctx context.Context) (remoteParent, bool) {
+	parent, ok := ctx.Value(remoteKey{}).(remoteParent)
+	return parent, ok
+}
+
+// parseTraceparent は "バージョン-トレースID-親のスパンID-フラグ" 形式の traceparent を読み取ります
+// バージョン 00 以外も、先頭の4つのフィールドが読み取れれば受け付ける（仕様の前方互換の規定に従う）
+func parseTraceparent(value string) (remoteParent, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return remoteParent{}, false
+	}
+
+	var parent remoteParent
+	if !decodeHex(parent.traceID[:], parts[1]) || !decodeHex(parent.spanID[:], parts[2]) {
+		return remoteParent{}, false
+	}
+	var flags [1]byte
+	if !decodeHex(flags[:], parts[3]) {
+		return remoteParent{}, false
+	}
+	if !parent.traceID.IsValid() || !parent.spanID.IsValid() {
+		return remoteParent{}, false
+	}
+	parent.sampled = flags[0]&0x01 == 0x01
+	return parent, true
+}
+
+// decodeHex は 小文字の16進数の s を dst の長さちょうどに読み取ります
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
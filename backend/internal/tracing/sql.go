@@ -0,0 +1,188 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+)
+
+// maxStatementLength は db.statement 属性に含める SQL の最大長です
+const maxStatementLength = 2048
+
+// WrapConnector は connector の接続で実行する SQL をスパンとして記録する driver.Connector を返します（sql.OpenDB に渡す）
+// スパンは Context にスパンがある呼び出しでのみ記録する（バックグラウンドジョブなどの呼び出しでトレースを増やさない）
+// Context を受け取らない Exec・Query でも、BeginTx に Context を渡したトランザクション内であればトランザクションの子として記録する
+func WrapConnector(connector driver.Connector) driver.Connector {
+	return &tracedConnector{Connector: connector}
+}
+
+type tracedConnector struct {
+	driver.Connector
+}
+
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn}, nil
+}
+
+// tracedConn は SQL の実行をスパンとして記録する接続です
+// database/sql は1つの接続を同時に1つの呼び出しにしか使わないため、txCtx はロックなしで扱える
+type tracedConn struct {
+	driver.Conn
+	txCtx context.Context // 実行中のトランザクションのスパンを設定した Context（トランザクション外・記録しない場合は nil）
+}
+
+var (
+	_ driver.ExecerContext      = (*tracedConn)(nil)
+	_ driver.QueryerContext     = (*tracedConn)(nil)
+	_ driver.ConnPrepareContext = (*tracedConn)(nil)
+	_ driver.ConnBeginTx        = (*tracedConn)(nil)
+	_ driver.Pinger             = (*tracedConn)(nil)
+	_ driver.SessionResetter    = (*tracedConn)(nil)
+	_ driver.Validator          = (*tracedConn)(nil)
+)
+
+// startQuery は SQL のスパンを開始します（記録しない場合は nil を返す）
+func (c *tracedConn) startQuery(ctx context.Context, query string) *Span {
+	if SpanFromContext(ctx) == nil {
+		if c.txCtx == nil {
+			return nil
+		}
+		ctx = c.txCtx
+	}
+
+	if len(query) > maxStatementLength {
+		query = query[:maxStatementLength] + "..."
+	}
+	operation := sqlOperation(query)
+	_, span := StartChild(ctx, operation, SpanKindClient,
+		String("db.system", "postgresql"),
+		String("db.operation", operation),
+		String("db.statement", query),
+	)
+	return span
+}
+
+// endQuery は SQL のスパンを終了します（driver.ErrSkip は database/sql が別の方法で実行し直すため失敗として扱わない）
+func endQuery(span *Span, err error) {
+	if err != nil && err != driver.ErrSkip {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	span := c.startQuery(ctx, query)
+	result, err := execer.ExecContext(ctx, query, args)
+	endQuery(span, err)
+	return result, err
+}
+
+// QueryContext は SQL を実行します（スパンは結果の読み取りを待たずに、最初の応答を受け取った時点で終了する）
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	span := c.startQuery(ctx, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	endQuery(span, err)
+	return rows, err
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// BeginTx は トランザクションを開始し、ctx にスパンがある場合はトランザクション全体のスパンを開始します
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	txCtx, span := StartChild(ctx, "TRANSACTION", SpanKindClient, String("db.system", "postgresql"))
+	if span == nil {
+		return tx, nil
+	}
+	c.txCtx = txCtx
+	return &tracedTx{Tx: tx, conn: c, span: span}, nil
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	c.txCtx = nil
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// tracedTx は コミット・ロールバックでトランザクションのスパンを終了するトランザクションです
+type tracedTx struct {
+	driver.Tx
+	conn *tracedConn
+	span *Span
+}
+
+func (t *tracedTx) Commit() error {
+	err := t.Tx.Commit()
+	t.finish("commit", err)
+	return err
+}
+
+func (t *tracedTx) Rollback() error {
+	err := t.Tx.Rollback()
+	t.finish("rollback", err)
+	return err
+}
+
+func (t *tracedTx) finish(outcome string, err error) {
+	t.conn.txCtx = nil
+	t.span.SetAttributes(String("db.transaction.outcome", outcome))
+	t.span.RecordError(err)
+	t.span.End()
+}
+
+// sqlOperation は SQL の最初のキーワード（SELECT・INSERT など）を返します（先頭のコメント行は読み飛ばす）
+func sqlOperation(query string) string {
+	for _, line := range strings.Split(query, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "--") {
+			continue
+		}
+		return strings.ToUpper(strings.TrimRight(fields[0], "(;"))
+	}
+	return "SQL"
+}
@@ -0,0 +1,303 @@
+// Package tracing は OpenTelemetry 形式の分散トレーシング（スパンの記録と OTLP/HTTP での送信）を提供します
+// HTTP リクエスト・SQL・オブジェクトストレージの操作をスパンとして記録し、文書の保存などの遅い処理の内訳を追えるようにする
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKind は スパンの種類です（OTLP の値と同じ）
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1 // アプリケーション内部の処理
+	SpanKindServer   SpanKind = 2 // 受け付けたリクエストの処理
+	SpanKindClient   SpanKind = 3 // データベース・ストレージなど外部への呼び出し
+)
+
+// statusCode は スパンの結果です（OTLP の値と同じ）
+type statusCode int
+
+const (
+	statusUnset statusCode = 0
+	statusError statusCode = 2
+)
+
+// TraceID は トレース（1つのリクエストから始まる一連のスパン）の ID です
+type TraceID [16]byte
+
+// String は ID を16進数で返します
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid は ID が全て 0 でないかを返します
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// SpanID は スパンの ID です
+type SpanID [8]byte
+
+// String は ID を16進数で返します
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid は ID が全て 0 でないかを返します
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// Attribute は スパンに付ける属性です（値は string / int / int64 / bool）
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String は 文字列の属性を作成します
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Int は 整数の属性を作成します
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+// Int64 は 整数の属性を作成します
+func Int64(key string, value int64) Attribute { return Attribute{Key: key, Value: value} }
+
+// Bool は 真偽値の属性を作成します
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// 既定値（Options で 0 以下を指定した場合に使う）
+const (
+	defaultServiceName   = "simple-notion-backend"
+	defaultQueueSize     = 2048
+	defaultBatchSize     = 512
+	defaultFlushInterval = 5 * time.Second
+	defaultExportTimeout = 10 * time.Second
+)
+
+// Options は Tracer の送信先とサンプリングの設定です
+type Options struct {
+	Endpoint      string        // OTLP/HTTP のエンドポイント（例: http://otel-collector:4318、/v1/traces を付けて送信する）
+	ServiceName   string        // service.name に設定するサービス名
+	SampleRatio   float64       // 呼び出し元から引き継がないトレースを記録する割合（0〜1）
+	QueueSize     int           // 送信待ちのスパンの最大件数（超えた分は破棄する）
+	BatchSize     int           // 1回の送信でまとめるスパンの最大件数
+	FlushInterval time.Duration // 送信待ちのスパンを送信する間隔
+	ExportTimeout time.Duration // 1回の送信のタイムアウト
+}
+
+// Tracer は スパンを作成し、終了したスパンをバックグラウンドで OTLP の送信先へ送ります
+// nil の Tracer は何も記録しない（トレーシングを無効にした場合もそのまま呼び出せる）
+type Tracer struct {
+	sampleRatio float64
+	exporter    *exporter
+}
+
+// New は 新しい Tracer インスタンスを作成します
+// 送信待ちのスパンを送るゴルーチンを起動します（Shutdown で停止する）
+func New(opts Options) (*Tracer, error) {
+	endpoint, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("tracing: invalid OTLP endpoint %q", opts.Endpoint)
+	}
+	if opts.SampleRatio < 0 || opts.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing: sample ratio must be between 0 and 1, got %v", opts.SampleRatio)
+	}
+
+	if opts.ServiceName == "" {
+		opts.ServiceName = defaultServiceName
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.ExportTimeout <= 0 {
+		opts.ExportTimeout = defaultExportTimeout
+	}
+
+	return &Tracer{
+		sampleRatio: opts.SampleRatio,
+		exporter:    newExporter(endpoint.String()+"/v1/traces", opts),
+	}, nil
+}
+
+// Start は スパンを開始します
+// ctx にスパンがある場合はその子、Extract で呼び出し元のトレースを設定した場合はその続き、どちらもない場合は新しいトレースを開始する
+// 戻り値の Context には開始したスパンが設定される。スパンは End で終了する必要がある
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{tracer: t, name: name, kind: kind, start: time.Now(), spanID: newSpanID()}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.traceID, span.parentID, span.sampled = parent.traceID, parent.spanID, parent.sampled
+	} else if remote, ok := remoteFromContext(ctx); ok {
+		span.traceID, span.parentID, span.sampled = remote.traceID, remote.spanID, remote.sampled
+	} else {
+		span.traceID = newTraceID()
+		span.sampled = t.shouldSample(span.traceID)
+	}
+	span.SetAttributes(attrs...)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// StartChild は ctx のスパンの子を開始します（ctx にスパンがない場合は何も記録せず nil を返す）
+// データベース・ストレージの呼び出しなど、リクエストの中でのみ記録したい処理に使う
+func StartChild(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name, kind, attrs...)
+}
+
+// Shutdown は 新しいスパンの受け付けを止め、送信待ちのスパンを送り終えるまで待ちます
+// ctx の期限が過ぎた場合は残りのスパンを破棄します
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.exporter.shutdown(ctx)
+}
+
+// shouldSample は 新しいトレースを記録するかを ID から決めます（同じ ID では常に同じ結果になる）
+func (t *Tracer) shouldSample(id TraceID) bool {
+	if t.sampleRatio >= 1 {
+		return true
+	}
+	return float64(binary.BigEndian.Uint64(id[8:])>>11)/float64(1<<53) < t.sampleRatio
+}
+
+// Span は 1つの処理の開始から終了までの記録です
+// nil の Span に対する操作は何もしない（トレーシングが無効・親のスパンがない場合）
+type Span struct {
+	tracer   *Tracer
+	traceID  TraceID
+	spanID   SpanID
+	parentID SpanID
+	sampled  bool
+	kind     SpanKind
+	start    time.Time
+
+	mu            sync.Mutex
+	name          string
+	end           time.Time
+	attrs         []Attribute
+	status        statusCode
+	statusMessage string
+	ended         bool
+}
+
+type spanKey struct{}
+
+// SpanFromContext は ctx に設定されたスパンを返します（ない場合は nil）
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// TraceID は スパンのトレース ID を16進数で返します（nil の場合は空文字列）
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.traceID.String()
+}
+
+// SetName は スパンの名前を変更します（ルーティング後にルートのパターンで名前を付ける場合など）
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.name = name
+	}
+}
+
+// SetAttributes は スパンに属性を追加します
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil || !s.sampled || len(attrs) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.attrs = append(s.attrs, attrs...)
+	}
+}
+
+// RecordError は スパンを失敗として記録します（err が nil の場合は何もしない）
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.SetError(err.Error())
+}
+
+// SetError は スパンを失敗として記録します
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.status = statusError
+		s.statusMessage = message
+	}
+}
+
+// End は スパンを終了し、記録する場合は送信待ちに追加します（2回目以降の呼び出しは何もしない）
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.sampled {
+		s.tracer.exporter.enqueue(s)
+	}
+}
+
+// newTraceID は ランダムなトレース ID を生成します
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		mustRead(id[:])
+	}
+	return id
+}
+
+// newSpanID は ランダムなスパン ID を生成します
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		mustRead(id[:])
+	}
+	return id
+}
+
+// mustRead は b を乱数で埋めます（crypto/rand の読み取りは失敗しない）
+func mustRead(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(errors.New("tracing: failed to read random bytes: " + err.Error()))
+	}
+}
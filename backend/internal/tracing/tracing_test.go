@@ -0,0 +1,198 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestTracer は 送信先を持つテスト用の Tracer を作成します
+func newTestTracer(t *testing.T, endpoint string, ratio float64) *Tracer {
+	t.Helper()
+	tracer, err := New(Options{Endpoint: endpoint, SampleRatio: ratio, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { tracer.Shutdown(context.Background()) })
+	return tracer
+}
+
+// TestParseTraceparent - traceparent ヘッダーの読み取りのテスト
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantOK      bool
+		wantSampled bool
+	}{
+		{name: "正常系：記録する", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantOK: true, wantSampled: true},
+		{name: "正常系：記録しない", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", wantOK: true},
+		{name: "正常系：将来のバージョンは先頭の4つを読む", value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantOK: true, wantSampled: true},
+		{name: "異常系：空", value: ""},
+		{name: "異常系：トレース ID が全て 0", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "異常系：大文字", value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{name: "異常系：長さが不正", value: "00-4bf92f35-00f067aa0ba902b7-01"},
+		{name: "異常系：無効なバージョン", value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTraceparent(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("parseTraceparent(%q) ok = %v, want %v", tt.value, ok, tt.wantOK)
+			}
+			if ok && got.sampled != tt.wantSampled {
+				t.Errorf("parseTraceparent(%q) sampled = %v, want %v", tt.value, got.sampled, tt.wantSampled)
+			}
+		})
+	}
+}
+
+// TestTracerStart - 親子関係と呼び出し元のトレースの引き継ぎのテスト
+func TestTracerStart(t *testing.T) {
+	tracer := newTestTracer(t, "http://127.0.0.1:1", 1)
+
+	t.Run("正常系：子のスパンは親と同じトレースになる", func(t *testing.T) {
+		ctx, parent := tracer.Start(context.Background(), "parent", SpanKindServer)
+		_, child := StartChild(ctx, "child", SpanKindClient)
+
+		if child == nil {
+			t.Fatal("StartChild() = nil, want span")
+		}
+		if child.traceID != parent.traceID || child.parentID != parent.spanID {
+			t.Errorf("child trace = %s/%s, want %s/%s", child.traceID, child.parentID, parent.traceID, parent.spanID)
+		}
+	})
+
+	t.Run("正常系：呼び出し元のトレースを引き継ぐ", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		_, span := tracer.Start(Extract(context.Background(), header), "server", SpanKindServer)
+
+		if span.TraceID() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.parentID.String() != "00f067aa0ba902b7" {
+			t.Errorf("span trace = %s/%s, want remote parent", span.TraceID(), span.parentID)
+		}
+	})
+
+	t.Run("正常系：親のスパンがない場合は子を記録しない", func(t *testing.T) {
+		if _, span := StartChild(context.Background(), "orphan", SpanKindClient); span != nil {
+			t.Errorf("StartChild() = %v, want nil", span)
+		}
+	})
+
+	t.Run("正常系：nil の Tracer は何も記録しない", func(t *testing.T) {
+		var disabled *Tracer
+		ctx, span := disabled.Start(context.Background(), "noop", SpanKindServer)
+		span.SetAttributes(String("key", "value"))
+		span.End()
+		if span != nil || SpanFromContext(ctx) != nil {
+			t.Errorf("Start() on nil tracer = %v, want nil", span)
+		}
+	})
+}
+
+// TestShouldSample - 記録する割合のテスト
+func TestShouldSample(t *testing.T) {
+	never := newTestTracer(t, "http://127.0.0.1:1", 0)
+	always := newTestTracer(t, "http://127.0.0.1:1", 1)
+
+	for i := 0; i < 100; i++ {
+		id := newTraceID()
+		if never.shouldSample(id) {
+			t.Fatalf("shouldSample(%s) with ratio 0 = true", id)
+		}
+		if !always.shouldSample(id) {
+			t.Fatalf("shouldSample(%s) with ratio 1 = false", id)
+		}
+	}
+}
+
+// TestExporter - 終了したスパンを OTLP/HTTP（JSON）で送信することのテスト
+func TestExporter(t *testing.T) {
+	received := make(chan otlpRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s, want JSON to /v1/traces", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var body otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		received <- body
+	}))
+	defer server.Close()
+
+	tracer, err := New(Options{Endpoint: server.URL, ServiceName: "test-service", SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, parent := tracer.Start(context.Background(), "PUT /api/documents/{id}", SpanKindServer)
+	_, child := StartChild(ctx, "UPDATE", SpanKindClient, String("db.system", "postgresql"), Int("rows", 1))
+	child.SetError("deadlock detected")
+	child.End()
+	parent.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	body := <-received
+	if len(body.ResourceSpans) != 1 || len(body.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("resourceSpans = %+v, want 1 resource with 1 scope", body.ResourceSpans)
+	}
+	if got := *body.ResourceSpans[0].Resource.Attributes[0].Value.StringValue; got != "test-service" {
+		t.Errorf("service.name = %q, want %q", got, "test-service")
+	}
+	spans := body.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	if spans[0].Name != "UPDATE" || spans[0].ParentSpanID != spans[1].SpanID || spans[0].Status.Code != statusError {
+		t.Errorf("child span = %+v, want failed UPDATE under the server span", spans[0])
+	}
+	if spans[1].Kind != SpanKindServer || spans[1].ParentSpanID != "" {
+		t.Errorf("server span = %+v, want root server span", spans[1])
+	}
+}
+
+// TestNewValidation - 設定の検証のテスト
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "異常系：スキームがない", opts: Options{Endpoint: "otel-collector:4318", SampleRatio: 1}},
+		{name: "異常系：割合が範囲外", opts: Options{Endpoint: "http://otel-collector:4318", SampleRatio: 1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts); err == nil {
+				t.Error("New() error = nil, want error")
+			}
+		})
+	}
+}
+
+// TestSQLOperation - SQL のスパン名のテスト
+func TestSQLOperation(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "SELECT id FROM documents", want: "SELECT"},
+		{query: "\n  -- name: UpdateDocument\n  update documents SET title = $1", want: "UPDATE"},
+		{query: "WITH moved AS (SELECT 1) DELETE FROM blocks", want: "WITH"},
+		{query: "  ", want: "SQL"},
+	}
+
+	for _, tt := range tests {
+		if got := sqlOperation(tt.query); got != tt.want {
+			t.Errorf("sqlOperation(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+// Package webp は 画像を WebP（ロスレス形式、VP8L）にエンコードする機能を提供します
+//
+// 標準ライブラリには WebP のエンコーダーがないため、VP8L の仕様（RFC 9649）に沿って実装している。
+// 緑の減算と予測の変換を行ったうえで、LZ77 の後方参照とプレフィックス符号（ハフマン符号）で圧縮する。
+// 色キャッシュやメタプレフィックス符号は使わない（圧縮率は libwebp より劣るが、PNG と同程度になる）。
+package webp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// MaxDimension は VP8L でエンコードできる画像の幅・高さの上限です
+const MaxDimension = 1 << 14
+
+// ErrImageTooLarge は 画像の幅・高さが MaxDimension を超える場合のエラーです
+var ErrImageTooLarge = errors.New("image too large for webp")
+
+const (
+	// predictorBits は 予測モードを切り替えるタイルの大きさ（2^predictorBits ピクセル四方）です
+	predictorBits = 4
+
+	// LZ77 の後方参照
+	minMatchLength = 3
+	maxMatchLength = 4096
+	maxDistance    = 1<<20 - 120
+	hashBits       = 16
+	maxChainLength = 32
+
+	// プレフィックス符号
+	numLiteralCodes  = 256
+	numLengthCodes   = 24
+	numDistanceCodes = 40
+	maxCodeLength    = 15
+	maxCodeLengthLen = 7 // 符号長を符号化する符号の最大長
+)
+
+// 変換の種類
+const (
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+)
+
+// codeLengthCodeOrder は 符号長を符号化する符号の符号長を書き出す順序です
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// predictorModes は タイルごとに試す予測モードです（L / T / L と T の平均 / Select / ClampAddSubtractFull）
+var predictorModes = []int{1, 2, 7, 11, 12}
+
+// EncodeLossless は 画像をロスレスの WebP としてエンコードします
+func EncodeLossless(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 {
+		return fmt.Errorf("invalid image size %dx%d", width, height)
+	}
+	if width > MaxDimension || height > MaxDimension {
+		return fmt.Errorf("%dx%d: %w", width, height, ErrImageTooLarge)
+	}
+
+	argb, hasAlpha := toARGB(img)
+
+	bw := &bitWriter{}
+	bw.writeBits(0x2f, 8) // VP8L のシグネチャ
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	if hasAlpha {
+		bw.writeBits(1, 1)
+	} else {
+		bw.writeBits(0, 1)
+	}
+	bw.writeBits(0, 3) // バージョン
+
+	// 変換（デコーダーは逆順に元に戻すため、書き出した順に適用する）
+	bw.writeBits(1, 1)
+	bw.writeBits(transformSubtractGreen, 2)
+	subtractGreen(argb)
+
+	bw.writeBits(1, 1)
+	bw.writeBits(transformPredictor, 2)
+	bw.writeBits(predictorBits-2, 3)
+	modes := predict(argb, width, height)
+	tilesW := subSampleSize(width, predictorBits)
+	encodeImage(bw, modes, tilesW, false)
+
+	bw.writeBits(0, 1) // 変換の終わり
+
+	encodeImage(bw, argb, width, true)
+
+	return writeContainer(w, bw.bytes())
+}
+
+// toARGB は 画像をストレートアルファの ARGB（0xAARRGGBB）の配列に変換し、透過を含むかを返します
+func toARGB(img image.Image) ([]uint32, bool) {
+	bounds := img.Bounds()
+	argb := make([]uint32, 0, bounds.Dx()*bounds.Dy())
+	hasAlpha := false
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A != 0xff {
+				hasAlpha = true
+			}
+			argb = append(argb, uint32(c.A)<<24|uint32(c.R)<<16|uint32(c.G)<<8|uint32(c.B))
+		}
+	}
+	return argb, hasAlpha
+}
+
+// subtractGreen は 赤と青から緑を引きます（緑の減算の変換）
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		green := (p >> 8) & 0xff
+		red := ((p >> 16) - green) & 0xff
+		blue := (p - green) & 0xff
+		argb[i] = p&0xff00ff00 | red<<16 | blue
+	}
+}
+
+// predict は タイルごとに予測モードを選び、各ピクセルを予測との差分に置き換えます
+// 戻り値は予測モードを緑に持つサブ画像（タイルの配列）
+func predict(argb []uint32, width, height int) []uint32 {
+	tileSize := 1 << predictorBits
+	tilesW := subSampleSize(width, predictorBits)
+	tilesH := subSampleSize(height, predictorBits)
+	modes := make([]uint32, tilesW*tilesH)
+
+	// 予測には元の値を使うため、差分は別の配列に書き出す
+	residuals := make([]uint32, len(argb))
+	for ty := 0; ty < tilesH; ty++ {
+		for tx := 0; tx < tilesW; tx++ {
+			x0, y0 := tx*tileSize, ty*tileSize
+			x1, y1 := min(x0+tileSize, width), min(y0+tileSize, height)
+
+			bestMode, bestCost := predictorModes[0], -1
+			for _, mode := range predictorModes {
+				cost := 0
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						cost += residualCost(subPixels(argb[y*width+x], predictPixel(argb, width, x, y, mode)))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					bestMode, bestCost = mode, cost
+				}
+			}
+
+			modes[ty*tilesW+tx] = 0xff000000 | uint32(bestMode)<<8
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					residuals[y*width+x] = subPixels(argb[y*width+x], predictPixel(argb, width, x, y, bestMode))
+				}
+			}
+		}
+	}
+
+	copy(argb, residuals)
+	return modes
+}
+
+// predictPixel は (x, y) のピクセルの予測値を返します
+// 左上は不透明な黒、1行目は左、1列目は上のピクセルで予測する（仕様で決められている）
+func predictPixel(argb []uint32, width, x, y, mode int) uint32 {
+	i := y*width + x
+	switch {
+	case x == 0 && y == 0:
+		return 0xff000000
+	case y == 0:
+		return argb[i-1]
+	case x == 0:
+		return argb[i-width]
+	}
+
+	left, top, topLeft := argb[i-1], argb[i-width], argb[i-width-1]
+	switch mode {
+	case 1:
+		return left
+	case 2:
+		return top
+	case 7:
+		return average2(left, top)
+	case 11:
+		return selectPredictor(left, top, topLeft)
+	case 12:
+		return clampAddSubtractFull(left, top, topLeft)
+	default:
+		panic(fmt.Sprintf("webp: unsupported predictor mode %d", mode))
+	}
+}
+
+// channel は ピクセルの shift ビット目から始まるチャンネルの値を返します
+func channel(p uint32, shift uint) int {
+	return int((p >> shift) & 0xff)
+}
+
+// average2 は チャンネルごとの平均（切り捨て）を返します
+func average2(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+// selectPredictor は L + T - TL に近い方（左または上）のピクセルを返します
+func selectPredictor(left, top, topLeft uint32) uint32 {
+	distLeft, distTop := 0, 0
+	for _, shift := range []uint{24, 16, 8, 0} {
+		estimate := channel(left, shift) + channel(top, shift) - channel(topLeft, shift)
+		distLeft += abs(estimate - channel(left, shift))
+		distTop += abs(estimate - channel(top, shift))
+	}
+	if distLeft < distTop {
+		return left
+	}
+	return top
+}
+
+// clampAddSubtractFull は チャンネルごとに L + T - TL を 0〜255 に収めた値を返します
+func clampAddSubtractFull(left, top, topLeft uint32) uint32 {
+	var p uint32
+	for _, shift := range []uint{24, 16, 8, 0} {
+		v := channel(left, shift) + channel(top, shift) - channel(topLeft, shift)
+		p |= uint32(min(max(v, 0), 255)) << shift
+	}
+	return p
+}
+
+// subPixels は チャンネルごとの差（256 を法とする）を返します
+func subPixels(a, b uint32) uint32 {
+	alphaGreen := 0x00ff00ff + (a & 0xff00ff00) - (b & 0xff00ff00)
+	redBlue := 0xff00ff00 + (a & 0x00ff00ff) - (b & 0x00ff00ff)
+	return (alphaGreen & 0xff00ff00) | (redBlue & 0x00ff00ff)
+}
+
+// residualCost は 予測の差分の大きさ（チャンネルごとの 0 からの距離の和）を返します
+func residualCost(p uint32) int {
+	cost := 0
+	for _, shift := range []uint{24, 16, 8, 0} {
+		v := channel(p, shift)
+		cost += min(v, 256-v)
+	}
+	return cost
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// subSampleSize は 2^bits ピクセル四方のタイルで区切った場合のタイル数を返します
+func subSampleSize(size, bits int) int {
+	return (size + (1 << bits) - 1) >> bits
+}
+
+// symbol は LZ77 で符号化した1要素（リテラルのピクセルまたは後方参照）です
+type symbol struct {
+	pixel    uint32
+	length   int // 0 の場合はリテラル
+	distance int // 距離の符号（後方参照の場合のみ）
+}
+
+// encodeImage は 画像を LZ77 とプレフィックス符号で書き出します
+// topLevel が true の場合はメタプレフィックス符号の有無（常になし）も書き出す
+func encodeImage(bw *bitWriter, argb []uint32, width int, topLevel bool) {
+	symbols := backwardReferences(argb, width)
+
+	bw.writeBits(0, 1) // 色キャッシュなし
+	if topLevel {
+		bw.writeBits(0, 1) // メタプレフィックス符号なし
+	}
+
+	// 5つのプレフィックス符号（緑＋長さ、赤、青、アルファ、距離）の出現回数を数える
+	counts := [5][]int{
+		make([]int, numLiteralCodes+numLengthCodes),
+		make([]int, numLiteralCodes),
+		make([]int, numLiteralCodes),
+		make([]int, numLiteralCodes),
+		make([]int, numDistanceCodes),
+	}
+	for _, s := range symbols {
+		if s.length == 0 {
+			counts[0][(s.pixel>>8)&0xff]++
+			counts[1][(s.pixel>>16)&0xff]++
+			counts[2][s.pixel&0xff]++
+			counts[3][s.pixel>>24]++
+			continue
+		}
+		lengthCode, _, _ := prefixEncode(s.length)
+		counts[0][numLiteralCodes+lengthCode]++
+		distanceCode, _, _ := prefixEncode(s.distance)
+		counts[4][distanceCode]++
+	}
+
+	var codes [5]*prefixCode
+	for i := range codes {
+		codes[i] = writePrefixCode(bw, counts[i])
+	}
+
+	for _, s := range symbols {
+		if s.length == 0 {
+			codes[0].write(bw, int((s.pixel>>8)&0xff))
+			codes[1].write(bw, int((s.pixel>>16)&0xff))
+			codes[2].write(bw, int(s.pixel&0xff))
+			codes[3].write(bw, int(s.pixel>>24))
+			continue
+		}
+		lengthCode, extraBits, extraValue := prefixEncode(s.length)
+		codes[0].write(bw, numLiteralCodes+lengthCode)
+		bw.writeBits(extraValue, extraBits)
+		distanceCode, extraBits, extraValue := prefixEncode(s.distance)
+		codes[4].write(bw, distanceCode)
+		bw.writeBits(extraValue, extraBits)
+	}
+}
+
+// backwardReferences は ハッシュチェーンで一致する過去のピクセル列を探し、リテラルと後方参照の列にします
+func backwardReferences(argb []uint32, width int) []symbol {
+	n := len(argb)
+	head := make([]int32, 1<<hashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	prev := make([]int32, n)
+
+	hash := func(i int) uint32 {
+		return ((argb[i]*0x1e35a7bd + argb[i+1]*0x9e3779b1) >> (32 - hashBits)) & (1<<hashBits - 1)
+	}
+	insert := func(i int) {
+		if i+1 < n {
+			h := hash(i)
+			prev[i] = head[h]
+			head[h] = int32(i)
+		}
+	}
+
+	symbols := make([]symbol, 0, n/2)
+	for i := 0; i < n; {
+		bestLength, bestDistance := 0, 0
+		if i+minMatchLength <= n {
+			limit := min(maxMatchLength, n-i)
+			candidate := head[hash(i)]
+			for chain := 0; candidate >= 0 && chain < maxChainLength; chain++ {
+				distance := i - int(candidate)
+				if distance > maxDistance {
+					break
+				}
+				length := 0
+				for length < limit && argb[int(candidate)+length] == argb[i+length] {
+					length++
+				}
+				if length > bestLength {
+					bestLength, bestDistance = length, distance
+					if length == limit {
+						break
+					}
+				}
+				candidate = prev[candidate]
+			}
+		}
+
+		if bestLength >= minMatchLength {
+			symbols = append(symbols, symbol{length: bestLength, distance: distanceToCode(bestDistance, width)})
+			for j := 0; j < bestLength; j++ {
+				insert(i + j)
+			}
+			i += bestLength
+			continue
+		}
+
+		symbols = append(symbols, symbol{pixel: argb[i]})
+		insert(i)
+		i++
+	}
+	return symbols
+}
+
+// distanceToCode は ピクセル数の距離を距離の符号に変換します
+// 1つ上（幅と同じ距離）と1つ左は近傍の表の符号、それ以外は距離 + 120 を使う
+func distanceToCode(distance, width int) int {
+	switch distance {
+	case width:
+		return 1
+	case 1:
+		return 2
+	default:
+		return distance + 120
+	}
+}
+
+// prefixEncode は 長さ・距離の値をプレフィックス符号と追加ビットに分けます
+func prefixEncode(value int) (code int, extraBits int, extraValue uint32) {
+	d := value - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	highest := 31
+	for d>>highest == 0 {
+		highest--
+	}
+	second := (d >> (highest - 1)) & 1
+	extraBits = highest - 1
+	return 2*highest + second, extraBits, uint32(d & (1<<extraBits - 1))
+}
+
+// writeContainer は VP8L のデータを RIFF コンテナに入れて書き出します
+func writeContainer(w io.Writer, data []byte) error {
+	padding := len(data) & 1
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(12+len(data)+padding))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(len(data)))
+
+	bw.Write(header)
+	bw.Write(data)
+	if padding == 1 {
+		bw.WriteByte(0)
+	}
+	return bw.Flush()
+}
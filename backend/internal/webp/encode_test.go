@@ -0,0 +1,366 @@
+package webp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// testDecoder は エンコード結果を検証するための VP8L のデコーダーです（このパッケージが書き出す機能のみ対応）
+type testDecoder struct {
+	data []byte
+	pos  int // 読み出したビット数
+}
+
+func (d *testDecoder) readBits(n int) uint32 {
+	var v uint32
+	for i := 0; i < n; i++ {
+		if d.pos/8 >= len(d.data) {
+			panic("unexpected end of data")
+		}
+		bit := (d.data[d.pos/8] >> (d.pos % 8)) & 1
+		v |= uint32(bit) << i
+		d.pos++
+	}
+	return v
+}
+
+// testCode は 符号長から作ったデコード用の表です（符号 → シンボル）
+type testCode struct {
+	single  int // シンボルが1種類の場合のシンボル（0 ビット）
+	symbols map[[2]uint32]int
+}
+
+func newTestCode(lengths []int) (*testCode, error) {
+	var used []int
+	for symbol, length := range lengths {
+		if length > 0 {
+			used = append(used, symbol)
+		}
+	}
+	if len(used) == 0 {
+		return nil, errors.New("empty code")
+	}
+	if len(used) == 1 {
+		return &testCode{single: used[0]}, nil
+	}
+
+	// 完全な符号であることを確認する
+	kraft := 0.0
+	for _, symbol := range used {
+		kraft += 1 / float64(uint64(1)<<lengths[symbol])
+	}
+	if kraft != 1 {
+		return nil, fmt.Errorf("incomplete code (kraft sum %v)", kraft)
+	}
+
+	code := &testCode{single: -1, symbols: map[[2]uint32]int{}}
+	next := uint32(0)
+	prevLength := 0
+	for length := 1; length <= 15; length++ {
+		for _, symbol := range used {
+			if lengths[symbol] != length {
+				continue
+			}
+			next <<= length - prevLength
+			prevLength = length
+			code.symbols[[2]uint32{uint32(length), next}] = symbol
+			next++
+		}
+	}
+	return code, nil
+}
+
+func (d *testDecoder) readSymbol(code *testCode) int {
+	if code.single >= 0 {
+		return code.single
+	}
+	var value uint32
+	for length := 1; length <= 15; length++ {
+		value = value<<1 | d.readBits(1)
+		if symbol, ok := code.symbols[[2]uint32{uint32(length), value}]; ok {
+			return symbol
+		}
+	}
+	panic("invalid code")
+}
+
+func (d *testDecoder) readPrefixCode(alphabetSize int) (*testCode, error) {
+	lengths := make([]int, alphabetSize)
+	if d.readBits(1) == 1 {
+		numSymbols := d.readBits(1) + 1
+		first := d.readBits(1 + 7*int(d.readBits(1)))
+		lengths[first] = 1
+		if numSymbols == 2 {
+			lengths[d.readBits(8)] = 1
+		}
+		return newTestCode(lengths)
+	}
+
+	codeLengthLengths := make([]int, 19)
+	numCodeLengths := int(d.readBits(4)) + 4
+	for i := 0; i < numCodeLengths; i++ {
+		codeLengthLengths[codeLengthCodeOrder[i]] = int(d.readBits(3))
+	}
+	codeLengthCode, err := newTestCode(codeLengthLengths)
+	if err != nil {
+		return nil, err
+	}
+	maxSymbol := alphabetSize
+	if d.readBits(1) == 1 {
+		lengthBits := 2 + 2*int(d.readBits(3))
+		maxSymbol = 2 + int(d.readBits(lengthBits))
+	}
+
+	prevLength := 8
+	for symbol := 0; symbol < alphabetSize && maxSymbol > 0; maxSymbol-- {
+		v := d.readSymbol(codeLengthCode)
+		switch {
+		case v < 16:
+			lengths[symbol] = v
+			symbol++
+			if v != 0 {
+				prevLength = v
+			}
+		case v == 16:
+			for n := 3 + int(d.readBits(2)); n > 0; n-- {
+				lengths[symbol] = prevLength
+				symbol++
+			}
+		case v == 17:
+			symbol += 3 + int(d.readBits(3))
+		default:
+			symbol += 11 + int(d.readBits(7))
+		}
+	}
+	return newTestCode(lengths)
+}
+
+func (d *testDecoder) readPrefixValue(code int) int {
+	if code < 4 {
+		return code + 1
+	}
+	extraBits := (code - 2) >> 1
+	offset := (2 + code&1) << extraBits
+	return offset + int(d.readBits(extraBits)) + 1
+}
+
+func (d *testDecoder) decodeImage(width, height int, topLevel bool) ([]uint32, error) {
+	if d.readBits(1) != 0 {
+		return nil, errors.New("color cache is not supported")
+	}
+	if topLevel && d.readBits(1) != 0 {
+		return nil, errors.New("meta prefix codes are not supported")
+	}
+
+	var codes [5]*testCode
+	for i, size := range []int{280, 256, 256, 256, 40} {
+		code, err := d.readPrefixCode(size)
+		if err != nil {
+			return nil, fmt.Errorf("prefix code %d: %w", i, err)
+		}
+		codes[i] = code
+	}
+
+	pixels := make([]uint32, 0, width*height)
+	for len(pixels) < width*height {
+		green := d.readSymbol(codes[0])
+		if green < 256 {
+			red := d.readSymbol(codes[1])
+			blue := d.readSymbol(codes[2])
+			alpha := d.readSymbol(codes[3])
+			pixels = append(pixels, uint32(alpha)<<24|uint32(red)<<16|uint32(green)<<8|uint32(blue))
+			continue
+		}
+		length := d.readPrefixValue(green - 256)
+		distanceCode := d.readPrefixValue(d.readSymbol(codes[4]))
+		var distance int
+		switch {
+		case distanceCode == 1:
+			distance = width
+		case distanceCode == 2:
+			distance = 1
+		case distanceCode > 120:
+			distance = distanceCode - 120
+		default:
+			return nil, fmt.Errorf("unsupported distance code %d", distanceCode)
+		}
+		if distance > len(pixels) || len(pixels)+length > width*height {
+			return nil, fmt.Errorf("invalid backward reference (distance %d, length %d)", distance, length)
+		}
+		for i := 0; i < length; i++ {
+			pixels = append(pixels, pixels[len(pixels)-distance])
+		}
+	}
+	return pixels, nil
+}
+
+func addPixels(a, b uint32) uint32 {
+	alphaGreen := (a & 0xff00ff00) + (b & 0xff00ff00)
+	redBlue := (a & 0x00ff00ff) + (b & 0x00ff00ff)
+	return (alphaGreen & 0xff00ff00) | (redBlue & 0x00ff00ff)
+}
+
+// decodeForTest は WebP（VP8L）をデコードして ARGB の配列を返します
+func decodeForTest(data []byte) (int, int, []uint32, error) {
+	if len(data) < 20 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" || string(data[12:16]) != "VP8L" {
+		return 0, 0, nil, errors.New("invalid container")
+	}
+	if int(binary.LittleEndian.Uint32(data[4:8])) != len(data)-8 {
+		return 0, 0, nil, errors.New("invalid riff size")
+	}
+	chunkSize := int(binary.LittleEndian.Uint32(data[16:20]))
+	d := &testDecoder{data: data[20 : 20+chunkSize]}
+
+	if d.readBits(8) != 0x2f {
+		return 0, 0, nil, errors.New("invalid signature")
+	}
+	width := int(d.readBits(14)) + 1
+	height := int(d.readBits(14)) + 1
+	d.readBits(1)
+	if d.readBits(3) != 0 {
+		return 0, 0, nil, errors.New("invalid version")
+	}
+
+	var transforms []func([]uint32)
+	for d.readBits(1) == 1 {
+		switch d.readBits(2) {
+		case transformSubtractGreen:
+			transforms = append(transforms, func(argb []uint32) {
+				for i, p := range argb {
+					green := (p >> 8) & 0xff
+					argb[i] = p&0xff00ff00 | ((p>>16+green)&0xff)<<16 | (p+green)&0xff
+				}
+			})
+		case transformPredictor:
+			bits := int(d.readBits(3)) + 2
+			tilesW := subSampleSize(width, bits)
+			modes, err := d.decodeImage(tilesW, subSampleSize(height, bits), false)
+			if err != nil {
+				return 0, 0, nil, err
+			}
+			transforms = append(transforms, func(argb []uint32) {
+				for y := 0; y < height; y++ {
+					for x := 0; x < width; x++ {
+						mode := int(modes[(y>>bits)*tilesW+x>>bits]>>8) & 0xf
+						i := y*width + x
+						argb[i] = addPixels(argb[i], predictPixel(argb, width, x, y, mode))
+					}
+				}
+			})
+		default:
+			return 0, 0, nil, errors.New("unsupported transform")
+		}
+	}
+
+	argb, err := d.decodeImage(width, height, true)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	for i := len(transforms) - 1; i >= 0; i-- {
+		transforms[i](argb)
+	}
+	return width, height, argb, nil
+}
+
+func TestEncodeLossless(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 67, 35))
+	noise := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	flat := image.NewNRGBA(image.Rect(0, 0, 100, 50))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			if x < 67 && y < 35 {
+				gradient.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 3), G: uint8(y * 7), B: uint8(x + y), A: 255})
+			}
+			if x < 40 && y < 30 {
+				noise.SetNRGBA(x, y, color.NRGBA{R: uint8(rng.Intn(256)), G: uint8(rng.Intn(256)), B: uint8(rng.Intn(256)), A: uint8(rng.Intn(256))})
+			}
+			flat.SetNRGBA(x, y, color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	offset := image.NewNRGBA(image.Rect(10, 20, 13, 21))
+	offset.SetNRGBA(11, 20, color.NRGBA{R: 1, G: 2, B: 3, A: 128})
+
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{name: "正常系：グラデーション（幅がタイルの倍数でない）", img: gradient},
+		{name: "正常系：透過を含むノイズ", img: noise},
+		{name: "正常系：単色（後方参照のみ）", img: flat},
+		{name: "正常系：1ピクセル", img: image.NewNRGBA(image.Rect(0, 0, 1, 1))},
+		{name: "正常系：原点以外から始まる画像", img: offset},
+		{name: "正常系：グレースケール", img: image.NewGray(image.Rect(0, 0, 20, 5))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeLossless(&buf, tt.img); err != nil {
+				t.Fatalf("EncodeLossless() error = %v", err)
+			}
+
+			width, height, argb, err := decodeForTest(buf.Bytes())
+			if err != nil {
+				t.Fatalf("decode error = %v", err)
+			}
+			bounds := tt.img.Bounds()
+			if width != bounds.Dx() || height != bounds.Dy() {
+				t.Fatalf("size = %dx%d, want %dx%d", width, height, bounds.Dx(), bounds.Dy())
+			}
+
+			want, _ := toARGB(tt.img)
+			for i := range want {
+				if argb[i] != want[i] {
+					t.Fatalf("pixel %d = %08x, want %08x", i, argb[i], want[i])
+				}
+			}
+		})
+	}
+
+	t.Run("正常系：単色の画像は小さくなる", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := EncodeLossless(&buf, flat); err != nil {
+			t.Fatalf("EncodeLossless() error = %v", err)
+		}
+		if buf.Len() > 200 {
+			t.Errorf("encoded size = %d bytes, want <= 200", buf.Len())
+		}
+	})
+
+	t.Run("異常系：大きすぎる画像", func(t *testing.T) {
+		err := EncodeLossless(&bytes.Buffer{}, image.NewGray(image.Rect(0, 0, MaxDimension+1, 1)))
+		if !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("EncodeLossless() error = %v, want ErrImageTooLarge", err)
+		}
+	})
+}
+
+func TestCodeLengths_Limit(t *testing.T) {
+	// フィボナッチ数列の出現回数は制限なしでは非常に深い木になる
+	counts := make([]int, 30)
+	a, b := 1, 1
+	for i := range counts {
+		counts[i] = a
+		a, b = b, a+b
+	}
+
+	lengths := codeLengths(counts, maxCodeLength)
+	kraft := 0.0
+	for _, length := range lengths {
+		if length > maxCodeLength {
+			t.Fatalf("code length %d exceeds %d", length, maxCodeLength)
+		}
+		kraft += 1 / float64(uint64(1)<<length)
+	}
+	if kraft != 1 {
+		t.Errorf("kraft sum = %v, want 1 (complete code)", kraft)
+	}
+}
@@ -0,0 +1,227 @@
+package webp
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// bitWriter は 下位ビットから順にビット列を書き出します
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+// writeBits は value の下位 n ビットを書き出します
+func (w *bitWriter) writeBits(value uint32, n int) {
+	if n == 0 {
+		return
+	}
+	w.acc |= uint64(value&(1<<n-1)) << w.nbits
+	w.nbits += uint(n)
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nbits -= 8
+	}
+}
+
+// bytes は 書き出したビット列を返します（最後のバイトの余りは 0 で埋める）
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nbits = 0, 0
+	}
+	return w.buf
+}
+
+// prefixCode は 書き出したプレフィックス符号です
+type prefixCode struct {
+	lengths []int
+	codes   []uint32 // 下位ビットから書き出せるよう反転した符号
+}
+
+// write は シンボルの符号を書き出します（シンボルが1種類の符号は 0 ビット）
+func (c *prefixCode) write(w *bitWriter, symbol int) {
+	w.writeBits(c.codes[symbol], c.lengths[symbol])
+}
+
+// writePrefixCode は 出現回数から符号を作って書き出し、シンボルの書き出しに使う符号を返します
+// 使われるシンボルが 8 ビットに収まる 2 種類以下の場合は単純な符号、それ以外は符号長を書き出す
+func writePrefixCode(w *bitWriter, counts []int) *prefixCode {
+	var used []int
+	for symbol, count := range counts {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		return writeSimplePrefixCode(w, len(counts), used)
+	}
+
+	lengths := codeLengths(counts, maxCodeLength)
+	w.writeBits(0, 1) // 通常の符号
+	writeCodeLengths(w, lengths)
+	return &prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+}
+
+// writeSimplePrefixCode は 1〜2 種類のシンボルの単純な符号を書き出します
+// 1 種類の場合はそのシンボルを 0 ビット、2 種類の場合はそれぞれ 1 ビットで表す
+func writeSimplePrefixCode(w *bitWriter, alphabetSize int, used []int) *prefixCode {
+	if len(used) == 0 {
+		used = []int{0}
+	}
+
+	w.writeBits(1, 1) // 単純な符号
+	w.writeBits(uint32(len(used)-1), 1)
+	if used[0] < 2 {
+		w.writeBits(0, 1)
+		w.writeBits(uint32(used[0]), 1)
+	} else {
+		w.writeBits(1, 1)
+		w.writeBits(uint32(used[0]), 8)
+	}
+	if len(used) == 2 {
+		w.writeBits(uint32(used[1]), 8)
+	}
+
+	code := &prefixCode{lengths: make([]int, alphabetSize), codes: make([]uint32, alphabetSize)}
+	if len(used) == 2 {
+		code.lengths[used[0]], code.codes[used[0]] = 1, 0
+		code.lengths[used[1]], code.codes[used[1]] = 1, 1
+	}
+	return code
+}
+
+// writeCodeLengths は 符号長を、符号長を符号化する符号（0〜15 のリテラルのみ使う）で書き出します
+func writeCodeLengths(w *bitWriter, lengths []int) {
+	counts := make([]int, len(codeLengthCodeOrder))
+	for _, length := range lengths {
+		counts[length]++
+	}
+	// シンボルが1種類の符号は 0 ビットとして扱われるため、必ず2種類以上にする
+	used := 0
+	for _, count := range counts {
+		if count > 0 {
+			used++
+		}
+	}
+	if used < 2 {
+		if counts[0] == 0 {
+			counts[0] = 1
+		} else {
+			counts[1] = 1
+		}
+	}
+
+	codeLengthLengths := codeLengths(counts, maxCodeLengthLen)
+	w.writeBits(uint32(len(codeLengthCodeOrder)-4), 4)
+	for _, symbol := range codeLengthCodeOrder {
+		w.writeBits(uint32(codeLengthLengths[symbol]), 3)
+	}
+
+	w.writeBits(0, 1) // 全てのシンボルの符号長を書き出す
+	code := &prefixCode{lengths: codeLengthLengths, codes: canonicalCodes(codeLengthLengths)}
+	for _, length := range lengths {
+		code.write(w, length)
+	}
+}
+
+// huffmanNode は 符号長を求めるためのハフマン木の節です
+type huffmanNode struct {
+	count  int
+	symbol int // 葉の場合のシンボル（節の場合は -1）
+	left   *huffmanNode
+	right  *huffmanNode
+	order  int // 同じ出現回数の節の順序を決めるための番号
+}
+
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int { return len(h) }
+func (h huffmanHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count < h[j].count
+	}
+	return h[i].order < h[j].order
+}
+func (h huffmanHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *huffmanHeap) Push(x interface{}) { *h = append(*h, x.(*huffmanNode)) }
+func (h *huffmanHeap) Pop() interface{} {
+	old := *h
+	node := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return node
+}
+
+// codeLengths は 出現回数から maxLength ビット以下の符号長を求めます（2種類以上のシンボルが必要）
+// 最大長を超える場合は、少ない出現回数を底上げして木を作り直す（完全な符号のまま長さを抑える）
+func codeLengths(counts []int, maxLength int) []int {
+	for countMin := 1; ; countMin *= 2 {
+		lengths := make([]int, len(counts))
+		var h huffmanHeap
+		for symbol, count := range counts {
+			if count > 0 {
+				h = append(h, &huffmanNode{count: max(count, countMin), symbol: symbol, order: len(h)})
+			}
+		}
+		order := len(h)
+		heap.Init(&h)
+		for h.Len() > 1 {
+			a := heap.Pop(&h).(*huffmanNode)
+			b := heap.Pop(&h).(*huffmanNode)
+			heap.Push(&h, &huffmanNode{count: a.count + b.count, symbol: -1, left: a, right: b, order: order})
+			order++
+		}
+
+		deepest := 0
+		var walk func(node *huffmanNode, depth int)
+		walk = func(node *huffmanNode, depth int) {
+			if node.symbol >= 0 {
+				lengths[node.symbol] = depth
+				deepest = max(deepest, depth)
+				return
+			}
+			walk(node.left, depth+1)
+			walk(node.right, depth+1)
+		}
+		walk(h[0], 0)
+
+		if deepest <= maxLength {
+			return lengths
+		}
+	}
+}
+
+// canonicalCodes は 符号長から正準ハフマン符号を作り、下位ビットから書き出せるよう反転して返します
+func canonicalCodes(lengths []int) []uint32 {
+	symbols := make([]int, 0, len(lengths))
+	for symbol, length := range lengths {
+		if length > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool { return lengths[symbols[i]] < lengths[symbols[j]] })
+
+	codes := make([]uint32, len(lengths))
+	code, prevLength := uint32(0), 0
+	for i, symbol := range symbols {
+		length := lengths[symbol]
+		if i > 0 {
+			code = (code + 1) << (length - prevLength)
+		}
+		prevLength = length
+		codes[symbol] = reverseBits(code, length)
+	}
+	return codes
+}
+
+// reverseBits は code の下位 n ビットを反転します
+func reverseBits(code uint32, n int) uint32 {
+	var reversed uint32
+	for i := 0; i < n; i++ {
+		reversed = reversed<<1 | (code>>i)&1
+	}
+	return reversed
+}
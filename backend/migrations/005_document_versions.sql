@@ -0,0 +1,21 @@
+-- Migration: 005_document_versions.sql
+-- 説明: 文書の保存履歴（バージョン）を保持し、差分表示に利用する
+
+CREATE TABLE IF NOT EXISTS document_versions (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    version_number INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT DEFAULT '',
+    -- 保存時点のブロック一覧（models.Block の JSON 配列）
+    blocks JSONB NOT NULL DEFAULT '[]',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_document_versions_number UNIQUE (document_id, version_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_versions_document_id ON document_versions(document_id, version_number DESC);
+
+COMMENT ON TABLE document_versions IS '文書保存時のスナップショット（差分表示用）';
+COMMENT ON COLUMN document_versions.version_number IS '文書ごとに 1 から始まる連番';
@@ -0,0 +1,16 @@
+-- Migration: 006_document_publications.sql
+-- 説明: 文書の公開状態と公開/非公開の予約日時を管理する
+
+CREATE TABLE IF NOT EXISTS document_publications (
+    document_id INTEGER PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    -- 予約日時（スケジューラーが到達時に is_public を切り替え、値をクリアする）
+    publish_at TIMESTAMP,
+    unpublish_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_publications_publish_at ON document_publications(publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_document_publications_unpublish_at ON document_publications(unpublish_at) WHERE unpublish_at IS NOT NULL;
+
+COMMENT ON TABLE document_publications IS '文書の公開設定（予約公開・予約非公開を含む）';
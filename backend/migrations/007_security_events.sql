@@ -0,0 +1,17 @@
+-- Migration: 007_security_events.sql
+-- 説明: 秘密情報の検出などセキュリティ上注意が必要な操作を記録する
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    document_id INTEGER REFERENCES documents(id) ON DELETE SET NULL,
+    event_type VARCHAR(50) NOT NULL,
+    -- 検出ルール名や箇所など（検出した値そのものは保存しない）
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_security_events_event_type ON security_events(event_type, created_at DESC);
+
+COMMENT ON TABLE security_events IS 'セキュリティイベントの監査ログ';
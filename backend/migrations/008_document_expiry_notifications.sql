@@ -0,0 +1,32 @@
+-- Migration: 008_document_expiry_notifications.sql
+-- 説明: アプリ内通知と、文書の有効期限（期限到達で自動的にごみ箱へ移動/完全削除）を追加する
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    document_id INTEGER REFERENCES documents(id) ON DELETE SET NULL,
+    type VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at DESC);
+
+COMMENT ON TABLE notifications IS 'ユーザーへのアプリ内通知';
+
+CREATE TABLE IF NOT EXISTS document_expirations (
+    document_id INTEGER PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    -- 期限到達時の動作: trash（ごみ箱へ移動） / delete（完全削除）
+    action VARCHAR(20) NOT NULL DEFAULT 'trash',
+    -- 事前警告の通知日時（未通知は NULL）
+    warned_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_document_expirations_action CHECK (action IN ('trash', 'delete'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_expirations_expires_at ON document_expirations(expires_at);
+
+COMMENT ON TABLE document_expirations IS '文書の有効期限（自己消去）設定';
@@ -0,0 +1,21 @@
+-- Migration: 009_document_reminders.sql
+-- 説明: 文書に対するリマインダー（指定日時にアプリ内通知を送る）を追加する
+
+CREATE TABLE IF NOT EXISTS document_reminders (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    remind_at TIMESTAMP NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    -- 通知済み日時（未送信は NULL）
+    delivered_at TIMESTAMP,
+    -- ユーザーが解除した日時（解除済みのリマインダーは送信しない）
+    dismissed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_reminders_user_id ON document_reminders(user_id, remind_at);
+CREATE INDEX IF NOT EXISTS idx_document_reminders_due ON document_reminders(remind_at)
+    WHERE delivered_at IS NULL AND dismissed_at IS NULL;
+
+COMMENT ON TABLE document_reminders IS '文書のリマインダー';
@@ -0,0 +1,18 @@
+-- Migration: 010_custom_block_types.sql
+-- 説明: 管理者が登録するカスタムブロックタイプ（content の JSON Schema とエクスポート用テンプレート）を追加する
+
+CREATE TABLE IF NOT EXISTS custom_block_types (
+    id SERIAL PRIMARY KEY,
+    -- blocks.type に保存される名前
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    -- ブロックの content を検証する JSON Schema
+    schema JSONB NOT NULL DEFAULT '{}',
+    -- エクスポート時に content を描画する text/template
+    export_template TEXT NOT NULL DEFAULT '',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE custom_block_types IS '管理者が登録したカスタムブロックタイプ';
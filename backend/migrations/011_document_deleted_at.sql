@@ -0,0 +1,10 @@
+-- Migration: 011_document_deleted_at.sql
+-- 説明: ごみ箱の絞り込み・並び替え用に、文書をごみ箱へ移動した日時を記録する
+
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- 既存のごみ箱内文書は、ごみ箱移動時に更新される updated_at で補完する
+UPDATE documents SET deleted_at = updated_at WHERE is_deleted = true AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_documents_trash ON documents(user_id, deleted_at DESC)
+    WHERE is_deleted = true;
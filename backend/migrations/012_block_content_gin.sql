@@ -0,0 +1,30 @@
+-- Migration: 012_block_content_gin.sql
+-- 説明: ブロックの content を JSONB の演算子で検索できるようにする
+--       （タイプ別の検索・fileKey による添付ファイルの追跡・タイプ別の集計）
+
+-- 画像・ファイル・ToDo ブロックで JSON 文字列として保存されていた content をオブジェクトに戻す
+-- 解析できない content はそのまま残す
+DO $$
+DECLARE
+    r RECORD;
+BEGIN
+    FOR r IN
+        SELECT id, content #>> '{}' AS raw
+        FROM blocks
+        WHERE type IN ('image', 'file', 'todo')
+          AND jsonb_typeof(content) = 'string'
+          AND ltrim(content #>> '{}') LIKE '{%'
+    LOOP
+        BEGIN
+            UPDATE blocks SET content = r.raw::jsonb WHERE id = r.id;
+        EXCEPTION WHEN invalid_text_representation THEN
+            RAISE NOTICE 'block % has invalid JSON content, skipped', r.id;
+        END;
+    END LOOP;
+END $$;
+
+-- content の包含検索（@>）用
+CREATE INDEX IF NOT EXISTS idx_blocks_content ON blocks USING GIN (content jsonb_path_ops);
+
+-- タイプ別の検索・集計用
+CREATE INDEX IF NOT EXISTS idx_blocks_type ON blocks(type);
@@ -0,0 +1,8 @@
+-- Migration: 013_nested_blocks.sql
+-- 説明: ブロックの入れ子（トグルリスト・インデントした箇条書き・カラム）のため親ブロックへの参照を追加
+
+ALTER TABLE blocks
+    ADD COLUMN IF NOT EXISTS parent_block_id INTEGER REFERENCES blocks(id) ON DELETE CASCADE;
+
+-- 子ブロックの取得・親の削除時のカスケード用
+CREATE INDEX IF NOT EXISTS idx_blocks_parent_block_id ON blocks(parent_block_id);
@@ -0,0 +1,12 @@
+-- Migration: 014_block_collapse_states.sql
+-- 説明: トグルブロックの開閉状態をユーザーごとに保存する（行があるブロックは折りたたまれている）
+
+CREATE TABLE IF NOT EXISTS block_collapse_states (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    block_id INTEGER NOT NULL REFERENCES blocks(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, block_id)
+);
+
+-- ブロック削除時のカスケード用
+CREATE INDEX IF NOT EXISTS idx_block_collapse_states_block_id ON block_collapse_states(block_id);
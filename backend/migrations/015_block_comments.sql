@@ -0,0 +1,20 @@
+-- Migration: 015_block_comments.sql
+-- 説明: 文書内のブロック（段落など）に対するコメントと、その解決状態を保存する
+
+CREATE TABLE IF NOT EXISTS block_comments (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    -- 文書全体の保存でブロックが作り直されても ID を付け替えて残すため、外部キーにはしない
+    block_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    -- 解決した日時とユーザー（未解決は NULL）
+    resolved_at TIMESTAMP,
+    resolved_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_block_comments_block ON block_comments(document_id, block_id, created_at);
+
+COMMENT ON TABLE block_comments IS 'ブロックへのコメント';
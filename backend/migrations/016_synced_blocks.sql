@@ -0,0 +1,17 @@
+-- Migration: 016_synced_blocks.sql
+-- 説明: 他の文書に埋め込める同期ブロックの同期元を登録する
+--       synced ブロックの content は syncId でこの表を参照し、読み込み時に同期元の現在の内容を解決する
+
+CREATE TABLE IF NOT EXISTS synced_blocks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source_document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    -- 文書全体の保存でブロックが作り直されても ID を付け替えて残すため、外部キーにはしない
+    source_block_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- 同じブロックを複数回登録しても同じ syncId を返す
+CREATE UNIQUE INDEX IF NOT EXISTS idx_synced_blocks_source ON synced_blocks(source_document_id, source_block_id);
+
+COMMENT ON TABLE synced_blocks IS '同期ブロックの同期元';
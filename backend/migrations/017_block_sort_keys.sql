@@ -0,0 +1,27 @@
+-- Migration: 017_block_sort_keys.sql
+-- 説明: ブロックの並び順を整数の位置から文字列の並び順キーに置き換える
+--       キーの間に新しいキーを作れるため、ブロックの挿入・移動で他の行の位置を書き換える必要がない
+--       API の position はキーの順で数えた文書内の位置として読み込み時に求める
+
+-- COLLATE "C" でバイト順に比較する（アプリケーションでのキーの比較と一致させる）
+ALTER TABLE blocks
+    ADD COLUMN IF NOT EXISTS sort_key TEXT COLLATE "C";
+
+-- 既存のブロックは位置の順に固定長のキーを振る（16進数の文字は並び順キーの文字の一部）
+UPDATE blocks b
+SET sort_key = lpad(to_hex(ordered.rn), 8, '0') || 'V'
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY document_id ORDER BY position, id) AS rn
+    FROM blocks
+) ordered
+WHERE b.id = ordered.id;
+
+ALTER TABLE blocks
+    ALTER COLUMN sort_key SET NOT NULL;
+
+-- 文書内の順序での取得・前後のキーの検索用
+CREATE INDEX IF NOT EXISTS idx_blocks_sort_key ON blocks(document_id, sort_key, id);
+
+-- idx_blocks_position も列と一緒に削除される
+ALTER TABLE blocks
+    DROP COLUMN IF EXISTS position;
@@ -0,0 +1,31 @@
+-- Migration: 018_document_search.sql
+-- 説明: 文書の全文検索のため、ブロックの JSON から取り出したテキストと検索用の tsvector を追加する
+--       search_text はアプリケーションが保存時に更新する（リッチテキストの JSON ではなく本文のテキスト）
+--       search_vector はタイトルと search_text から自動で生成される
+
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS search_text TEXT NOT NULL DEFAULT '';
+
+-- 既存の文書は、文字列の content をそのまま連結したテキストで仮に埋める（次回の保存で置き換わる）
+UPDATE documents d
+SET search_text = sub.text
+FROM (
+    SELECT document_id,
+           string_agg(
+               CASE WHEN jsonb_typeof(content) = 'string' THEN content #>> '{}' ELSE content::text END,
+               E'\n' ORDER BY sort_key, id
+           ) AS text
+    FROM blocks
+    GROUP BY document_id
+) sub
+WHERE d.id = sub.document_id;
+
+-- 日本語を含むため言語ごとの語幹処理はせず 'simple' 設定で分割する
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', search_text), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_documents_search_vector ON documents USING GIN (search_vector);
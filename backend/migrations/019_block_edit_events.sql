@@ -0,0 +1,20 @@
+-- Migration: 019_block_edit_events.sql
+-- 説明: ブロックの種類・内容の変更履歴（変更前後の内容・編集者・日時）を保存する
+
+CREATE TABLE IF NOT EXISTS block_edit_events (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    -- 文書全体の保存でブロックが作り直されても ID を付け替えて残すため、外部キーにはしない
+    block_id INTEGER NOT NULL,
+    -- 編集者が退会しても履歴は残す（不明な場合は NULL）
+    editor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    old_type VARCHAR(50) NOT NULL,
+    new_type VARCHAR(50) NOT NULL,
+    old_content JSONB NOT NULL,
+    new_content JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_block_edit_events_block ON block_edit_events(document_id, block_id, created_at);
+
+COMMENT ON TABLE block_edit_events IS 'ブロックの変更履歴';
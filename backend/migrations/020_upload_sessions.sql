@@ -0,0 +1,32 @@
+-- Migration: 020_upload_sessions.sql
+-- 説明: 再開可能な分割アップロードのセッションと、受信済みのパート（S3 マルチパートアップロードのパート）を保存する
+
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id UUID PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    file_key VARCHAR(512) NOT NULL,
+    -- ストレージ側のマルチパートアップロードID
+    storage_upload_id TEXT NOT NULL,
+    original_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    total_size BIGINT NOT NULL CHECK (total_size > 0),
+    received_size BIGINT NOT NULL DEFAULT 0 CHECK (received_size >= 0 AND received_size <= total_size),
+    -- チャンクを受信するたびに延長し、期限を過ぎたセッションはバックグラウンドジョブで破棄する
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_user ON upload_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires_at ON upload_sessions(expires_at);
+
+CREATE TABLE IF NOT EXISTS upload_session_parts (
+    session_id UUID NOT NULL REFERENCES upload_sessions(id) ON DELETE CASCADE,
+    part_number INTEGER NOT NULL CHECK (part_number BETWEEN 1 AND 10000),
+    etag TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    PRIMARY KEY (session_id, part_number)
+);
+
+COMMENT ON TABLE upload_sessions IS '再開可能な分割アップロードのセッション';
+COMMENT ON TABLE upload_session_parts IS '分割アップロードで受信済みのパート';
@@ -0,0 +1,19 @@
+-- Migration: 021_direct_uploads.sql
+-- 説明: 署名付きURLでストレージへ直接アップロードする際の発行済みURL（確認前のアップロード）を保存する
+
+CREATE TABLE IF NOT EXISTS direct_uploads (
+    id UUID PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    file_key VARCHAR(500) NOT NULL UNIQUE,
+    original_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    -- 発行時に申告されたサイズ（確認時にストレージ上のサイズと照合する）
+    file_size BIGINT NOT NULL CHECK (file_size > 0),
+    -- 期限までに確認されなかったアップロードはバックグラウンドジョブで破棄する
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_direct_uploads_expires_at ON direct_uploads(expires_at);
+
+COMMENT ON TABLE direct_uploads IS '署名付きURLによる直接アップロード（確認前）';
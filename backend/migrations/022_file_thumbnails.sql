@@ -0,0 +1,30 @@
+-- Migration: 022_file_thumbnails.sql
+-- 説明: 画像ファイルのサムネイル（サイズ名 → ストレージのキー）と作成状況を file_metadata に記録する
+
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS thumbnails JSONB NOT NULL DEFAULT '{}'::jsonb;
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS thumbnail_status VARCHAR(20) NOT NULL DEFAULT 'none';
+-- 作成中のまま止まったものを再処理するために、状態を変更した日時を記録する
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS thumbnail_updated_at TIMESTAMP;
+
+ALTER TABLE file_metadata DROP CONSTRAINT IF EXISTS chk_thumbnail_status;
+ALTER TABLE file_metadata ADD CONSTRAINT chk_thumbnail_status
+    CHECK (thumbnail_status IN ('none', 'pending', 'processing', 'ready', 'failed'));
+
+-- 既存の画像もバックグラウンドでサムネイルを作成する
+UPDATE file_metadata SET thumbnail_status = 'pending'
+WHERE file_type = 'image' AND status = 'active' AND thumbnail_status = 'none';
+
+CREATE INDEX IF NOT EXISTS idx_file_metadata_thumbnail_pending ON file_metadata(uploaded_at)
+    WHERE thumbnail_status IN ('pending', 'processing');
+
+-- 孤立ファイル検出用ビューに追加した列を含める（fm.* は作成時の列で固定されるため作り直す）
+CREATE OR REPLACE VIEW orphaned_files AS
+SELECT fm.*
+FROM file_metadata fm
+LEFT JOIN blocks b ON fm.block_id = b.id
+WHERE fm.status = 'active'
+  AND (fm.block_id IS NOT NULL AND b.id IS NULL)
+  AND fm.uploaded_at < NOW() - INTERVAL '24 hours';
+
+COMMENT ON COLUMN file_metadata.thumbnails IS 'サムネイルのサイズ名とストレージのキー (例: {"thumb": "thumbnails/thumb/images/1/uuid_name.jpg"})';
+COMMENT ON COLUMN file_metadata.thumbnail_status IS 'サムネイルの作成状況 (none, pending, processing, ready, failed)';
@@ -0,0 +1,40 @@
+-- Migration: 023_file_content_hash.sql
+-- 説明: アップロードしたファイルの SHA-256 を記録し、同じユーザーの同じ内容のファイルはストレージ上の1つのオブジェクトを共有する
+-- 共有しているオブジェクトは参照数（file_objects.ref_count）が 0 になったときにだけ削除する
+
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS content_hash CHAR(64);
+
+-- 同じオブジェクトを複数のファイルメタデータから参照できるようにする（file_key の検索用インデックスは残す）
+ALTER TABLE file_metadata DROP CONSTRAINT IF EXISTS file_metadata_file_key_key;
+
+CREATE INDEX IF NOT EXISTS idx_file_metadata_content_hash ON file_metadata(user_id, content_hash)
+    WHERE status = 'active' AND content_hash IS NOT NULL;
+
+-- ストレージ上のオブジェクトごとの参照数
+CREATE TABLE IF NOT EXISTS file_objects (
+    file_key VARCHAR(500) PRIMARY KEY,
+    ref_count INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_file_objects_ref_count CHECK (ref_count >= 0)
+);
+
+-- 既存のファイルは削除済みでないメタデータの件数を参照数とする
+INSERT INTO file_objects (file_key, ref_count)
+SELECT file_key, COUNT(*)
+FROM file_metadata
+WHERE status <> 'deleted'
+GROUP BY file_key
+ON CONFLICT (file_key) DO NOTHING;
+
+-- 孤立ファイル検出用ビューに追加した列を含める（fm.* は作成時の列で固定されるため作り直す）
+CREATE OR REPLACE VIEW orphaned_files AS
+SELECT fm.*
+FROM file_metadata fm
+LEFT JOIN blocks b ON fm.block_id = b.id
+WHERE fm.status = 'active'
+  AND (fm.block_id IS NOT NULL AND b.id IS NULL)
+  AND fm.uploaded_at < NOW() - INTERVAL '24 hours';
+
+COMMENT ON COLUMN file_metadata.content_hash IS 'ファイルの内容の SHA-256（16進数）。同じ内容のファイルの検出に使う';
+COMMENT ON TABLE file_objects IS 'MinIO/S3 のオブジェクトごとの参照数（参照しているファイルメタデータの件数）';
@@ -0,0 +1,23 @@
+-- Migration: 024_file_purge.sql
+-- 説明: 削除済みのファイルを保持期間の経過後にストレージとデータベースから完全に削除するため、削除処理の試行状況を記録する
+
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS purge_attempts INTEGER NOT NULL DEFAULT 0;
+-- 次に削除を試みる日時（処理中の予約と、失敗した場合の再試行の待機に使う）
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS next_purge_at TIMESTAMP;
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS last_purge_error TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_file_metadata_deleted_at ON file_metadata(deleted_at)
+    WHERE status = 'deleted';
+
+-- 孤立ファイル検出用ビューに追加した列を含める（fm.* は作成時の列で固定されるため作り直す）
+CREATE OR REPLACE VIEW orphaned_files AS
+SELECT fm.*
+FROM file_metadata fm
+LEFT JOIN blocks b ON fm.block_id = b.id
+WHERE fm.status = 'active'
+  AND (fm.block_id IS NOT NULL AND b.id IS NULL)
+  AND fm.uploaded_at < NOW() - INTERVAL '24 hours';
+
+COMMENT ON COLUMN file_metadata.purge_attempts IS '完全削除を試みた回数';
+COMMENT ON COLUMN file_metadata.next_purge_at IS '次に完全削除を試みる日時';
+COMMENT ON COLUMN file_metadata.last_purge_error IS '最後に完全削除に失敗したときのエラー';
@@ -0,0 +1,17 @@
+-- Migration: 025_file_details.sql
+-- 説明: ファイルの代替テキストと説明を file_metadata に記録する（ファイル名の変更と合わせて PATCH /api/files/{id} で編集する）
+
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS alt_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+
+-- 孤立ファイル検出用ビューに追加した列を含める（fm.* は作成時の列で固定されるため作り直す）
+CREATE OR REPLACE VIEW orphaned_files AS
+SELECT fm.*
+FROM file_metadata fm
+LEFT JOIN blocks b ON fm.block_id = b.id
+WHERE fm.status = 'active'
+  AND (fm.block_id IS NOT NULL AND b.id IS NULL)
+  AND fm.uploaded_at < NOW() - INTERVAL '24 hours';
+
+COMMENT ON COLUMN file_metadata.alt_text IS '画像の代替テキスト';
+COMMENT ON COLUMN file_metadata.description IS 'ファイルの説明';
@@ -0,0 +1,23 @@
+-- Migration: 026_file_detach.sql
+-- 説明: エディタでブロックから外したファイルを orphaned として記録し、猶予期間の経過後に孤立ファイルとしてクリーンアップする
+
+-- ファイルを orphaned にした日時（猶予期間内に紐付け直した場合は NULL に戻す）
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS orphaned_at TIMESTAMP;
+
+-- 既存の orphaned のファイルは変更日時が分からないため、移行時点から猶予期間を数える
+UPDATE file_metadata SET orphaned_at = NOW() WHERE status = 'orphaned' AND orphaned_at IS NULL;
+
+-- 孤立ファイル検出用ビュー
+-- ブロックが削除されたファイルに加えて、エディタから外されて猶予期間が経過したファイルを含める
+CREATE OR REPLACE VIEW orphaned_files AS
+SELECT fm.*
+FROM file_metadata fm
+LEFT JOIN blocks b ON fm.block_id = b.id
+WHERE (fm.status = 'active'
+       AND (fm.block_id IS NOT NULL AND b.id IS NULL)
+       AND fm.uploaded_at < NOW() - INTERVAL '24 hours')
+   OR (fm.status = 'orphaned'
+       AND fm.orphaned_at < NOW() - INTERVAL '24 hours');
+
+COMMENT ON COLUMN file_metadata.orphaned_at IS 'ファイルをブロックから外した日時';
+COMMENT ON VIEW orphaned_files IS 'ブロックが削除された、またはエディタから外されてから24時間以上経過した孤立ファイル';
@@ -0,0 +1,5 @@
+-- Migration: 027_user_storage_quota.sql
+-- 説明: ユーザーごとのストレージクォータ（管理者が PUT /api/admin/users/{id}/storage-quota で変更する）
+-- NULL の場合は設定（USER_STORAGE_QUOTA）の値を既定値として使う
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS storage_quota BIGINT CHECK (storage_quota >= 0);
@@ -0,0 +1,23 @@
+-- Migration: 028_image_optimization.sql
+-- 説明: 大きな JPEG/PNG 画像を WebP に変換して保存容量と転送量を削減し、変換後の形式を記録する
+
+-- 保存している画像の形式（original: 変換していない、webp: WebP に変換済み）
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS variant VARCHAR(20) NOT NULL DEFAULT 'original'
+    CHECK (variant IN ('original', 'webp'));
+
+-- 元の画像を残す設定の場合の WebP のオブジェクトキー（元の画像を WebP で置き換えた場合は NULL）
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS webp_file_key VARCHAR(500);
+
+-- 孤立ファイル検出用ビューに追加した列を含める（fm.* は作成時の列で固定されるため作り直す）
+CREATE OR REPLACE VIEW orphaned_files AS
+SELECT fm.*
+FROM file_metadata fm
+LEFT JOIN blocks b ON fm.block_id = b.id
+WHERE (fm.status = 'active'
+       AND (fm.block_id IS NOT NULL AND b.id IS NULL)
+       AND fm.uploaded_at < NOW() - INTERVAL '24 hours')
+   OR (fm.status = 'orphaned'
+       AND fm.orphaned_at < NOW() - INTERVAL '24 hours');
+
+COMMENT ON COLUMN file_metadata.variant IS '保存している画像の形式 (original, webp)';
+COMMENT ON COLUMN file_metadata.webp_file_key IS '元の画像を残して WebP を別に保存した場合の WebP のオブジェクトキー';
@@ -0,0 +1,21 @@
+-- Migration: 029_video_uploads.sql
+-- 説明: 動画（MP4 / WebM）のアップロードに対応し、動画の再生時間を file_metadata に記録する（映像の大きさは width / height に記録する）
+
+ALTER TABLE file_metadata DROP CONSTRAINT IF EXISTS chk_file_type;
+ALTER TABLE file_metadata ADD CONSTRAINT chk_file_type CHECK (file_type IN ('image', 'file', 'video'));
+
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS duration_ms BIGINT;
+
+-- 孤立ファイル検出用ビューに追加した列を含める（fm.* は作成時の列で固定されるため作り直す）
+CREATE OR REPLACE VIEW orphaned_files AS
+SELECT fm.*
+FROM file_metadata fm
+LEFT JOIN blocks b ON fm.block_id = b.id
+WHERE (fm.status = 'active'
+       AND (fm.block_id IS NOT NULL AND b.id IS NULL)
+       AND fm.uploaded_at < NOW() - INTERVAL '24 hours')
+   OR (fm.status = 'orphaned'
+       AND fm.orphaned_at < NOW() - INTERVAL '24 hours');
+
+COMMENT ON COLUMN file_metadata.file_type IS 'ファイルの種類 (image, file or video)';
+COMMENT ON COLUMN file_metadata.duration_ms IS '動画の再生時間（ミリ秒）。取得できなかった場合は NULL';
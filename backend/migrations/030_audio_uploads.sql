@@ -0,0 +1,8 @@
+-- Migration: 030_audio_uploads.sql
+-- 説明: 音声（MP3 / WAV / M4A / Ogg）のアップロードに対応する（再生時間は duration_ms に記録する）
+
+ALTER TABLE file_metadata DROP CONSTRAINT IF EXISTS chk_file_type;
+ALTER TABLE file_metadata ADD CONSTRAINT chk_file_type CHECK (file_type IN ('image', 'file', 'video', 'audio'));
+
+COMMENT ON COLUMN file_metadata.file_type IS 'ファイルの種類 (image, file, video or audio)';
+COMMENT ON COLUMN file_metadata.duration_ms IS '動画・音声の再生時間（ミリ秒）。取得できなかった場合は NULL';
@@ -0,0 +1,51 @@
+-- Migration: 031_file_versions.sql
+-- 説明: 既存のファイル（同じファイルID）に新しい版をアップロードできるようにし、置き換えた版を記録して復元できるようにする
+-- 置き換えた版のオブジェクトは版の行が参照数（file_objects.ref_count）を1つ持ち、保持期間の経過後に削除する
+
+-- 現在の版の番号（新しい版をアップロード・復元するたびに増やす）
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS file_versions (
+    id SERIAL PRIMARY KEY,
+    file_id INTEGER NOT NULL REFERENCES file_metadata(id) ON DELETE CASCADE,
+    version_number INTEGER NOT NULL,
+
+    file_key VARCHAR(500) NOT NULL,
+    original_name VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    content_hash CHAR(64),
+    width INTEGER,
+    height INTEGER,
+    duration_ms BIGINT,
+
+    -- 版のオブジェクトから作成したサムネイル・WebP（復元時に戻し、版の削除時にあわせて削除する）
+    thumbnails JSONB NOT NULL DEFAULT '{}'::jsonb,
+    variant VARCHAR(20) NOT NULL DEFAULT 'original',
+    webp_file_key VARCHAR(500),
+
+    uploaded_at TIMESTAMP NOT NULL,
+    replaced_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_file_versions_number UNIQUE (file_id, version_number)
+);
+
+-- 保持期間が経過した版の検索用
+CREATE INDEX IF NOT EXISTS idx_file_versions_replaced_at ON file_versions(replaced_at);
+
+-- 孤立ファイル検出用ビューに追加した列を含める（fm.* は作成時の列で固定されるため作り直す）
+CREATE OR REPLACE VIEW orphaned_files AS
+SELECT fm.*
+FROM file_metadata fm
+LEFT JOIN blocks b ON fm.block_id = b.id
+WHERE (fm.status = 'active'
+       AND (fm.block_id IS NOT NULL AND b.id IS NULL)
+       AND fm.uploaded_at < NOW() - INTERVAL '24 hours')
+   OR (fm.status = 'orphaned'
+       AND fm.orphaned_at < NOW() - INTERVAL '24 hours');
+
+COMMENT ON COLUMN file_metadata.version IS '現在の版の番号';
+COMMENT ON TABLE file_versions IS '新しい版で置き換えたファイルの以前の版';
+COMMENT ON COLUMN file_versions.version_number IS '版の番号（file_metadata.version の置き換え前の値）';
+COMMENT ON COLUMN file_versions.uploaded_at IS 'この版をアップロードした日時';
+COMMENT ON COLUMN file_versions.replaced_at IS 'この版を新しい版で置き換えた日時（保持期間はここから数える）';
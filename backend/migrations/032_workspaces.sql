@@ -0,0 +1,33 @@
+-- Migration: 032_workspaces.sql
+-- 説明: 文書をまとめるワークスペースと、そのメンバー（役割つき）を保存する
+-- 文書は作成したユーザーの所有のまま、作成時に選択していたワークスペースに属する（NULL は個人の文書）
+-- ワークスペースを削除した場合、属していた文書は作成したユーザーの個人の文書に戻る
+
+CREATE TABLE IF NOT EXISTS workspaces (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member')),
+    joined_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+-- ユーザーが参加しているワークスペースの一覧用
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user ON workspace_members(user_id);
+
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS workspace_id INTEGER REFERENCES workspaces(id) ON DELETE SET NULL;
+
+-- ワークスペースごとの文書ツリーと文書数の上限の確認用
+CREATE INDEX IF NOT EXISTS idx_documents_workspace ON documents(workspace_id) WHERE workspace_id IS NOT NULL;
+
+COMMENT ON TABLE workspaces IS '文書をまとめるワークスペース';
+COMMENT ON COLUMN workspaces.owner_id IS 'ワークスペースの所有者（workspace_members にも role = owner で登録する）';
+COMMENT ON TABLE workspace_members IS 'ワークスペースのメンバー';
+COMMENT ON COLUMN documents.workspace_id IS '文書が属するワークスペース（NULL は個人の文書）';
@@ -0,0 +1,7 @@
+-- Migration: 033_workspace_guests.sql
+-- 説明: ワークスペースの役割に閲覧のみの guest を追加する（guest は選択中のワークスペースで文書の変更・ファイルのアップロードができない）
+
+ALTER TABLE workspace_members DROP CONSTRAINT IF EXISTS workspace_members_role_check;
+ALTER TABLE workspace_members ADD CONSTRAINT workspace_members_role_check CHECK (role IN ('owner', 'admin', 'member', 'guest'));
+
+COMMENT ON COLUMN workspace_members.role IS 'メンバーの役割 (owner, admin, member or guest)';
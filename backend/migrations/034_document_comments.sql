@@ -0,0 +1,22 @@
+-- Migration: 034_document_comments.sql
+-- 説明: 文書全体に対するコメントのスレッド（先頭のコメントと返信）と、スレッドの解決状態を保存する
+-- 返信は1階層のみ（返信への返信はスレッドの先頭のコメントへの返信として保存する）
+
+CREATE TABLE IF NOT EXISTS document_comments (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    -- 返信先のスレッドの先頭のコメント（スレッドの先頭は NULL）。先頭を削除すると返信も削除する
+    parent_id INTEGER REFERENCES document_comments(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    -- スレッドを解決した日時とユーザー（未解決・返信は NULL）
+    resolved_at TIMESTAMP,
+    resolved_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_comments_document ON document_comments(document_id, created_at);
+
+COMMENT ON TABLE document_comments IS '文書へのコメントのスレッド';
+COMMENT ON COLUMN document_comments.parent_id IS '返信先のスレッドの先頭のコメント（スレッドの先頭は NULL）';
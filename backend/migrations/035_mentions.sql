@@ -0,0 +1,26 @@
+-- Migration: 035_mentions.sql
+-- 説明: 文書の本文・ブロックとコメントに含まれる @メンション を記録し、通知に文書・ブロックへのリンクを持たせる
+-- メンションは保存のたびに書き込み元（source）単位で作り直し、新しく追加されたユーザーにのみ通知する
+
+CREATE TABLE IF NOT EXISTS mentions (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    -- 書き込み元: document（本文・ブロック。source_id は文書ID） / block_comment / document_comment（source_id はコメントID）
+    source_type VARCHAR(20) NOT NULL,
+    source_id INTEGER NOT NULL,
+    -- メンションを含むブロック（本文・文書へのコメントの場合は NULL）。ブロックは保存のたびに作り直されるため外部キーにはしない
+    block_id INTEGER,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    mentioned_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_mentions_source_type CHECK (source_type IN ('document', 'block_comment', 'document_comment')),
+    CONSTRAINT uq_mentions_source_user UNIQUE (document_id, source_type, source_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mentions_user ON mentions(user_id, created_at DESC);
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS link TEXT;
+
+COMMENT ON TABLE mentions IS '文書・コメント内のユーザーへのメンション';
+COMMENT ON COLUMN notifications.link IS '通知から開く文書・ブロック・コメントへのリンク（アプリ内の相対パス）';
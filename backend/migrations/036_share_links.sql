@@ -0,0 +1,22 @@
+-- Migration: 036_share_links.sql
+-- 説明: アカウントなしで単一の文書を閲覧できる共有リンク（有効期限・パスワードは任意、所有者が無効化できる）
+-- リンクのトークンはハッシュ（SHA-256）のみを保存し、作成時にだけ返す
+
+CREATE TABLE IF NOT EXISTS document_share_links (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    password_hash TEXT,
+    expires_at TIMESTAMP,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP,
+    last_accessed_at TIMESTAMP,
+    access_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_share_links_document ON document_share_links(document_id, created_at DESC);
+
+COMMENT ON TABLE document_share_links IS 'アカウントなしで文書を閲覧できる共有リンク';
+COMMENT ON COLUMN document_share_links.token_hash IS 'リンクのトークンの SHA-256（16進数）';
+COMMENT ON COLUMN document_share_links.password_hash IS '閲覧に必要なパスワードのハッシュ（NULL の場合は不要）';
@@ -0,0 +1,18 @@
+-- Migration: 037_document_collaborators.sql
+-- 説明: 文書の所有者以外に閲覧・編集を許可する共同編集者（所有者のみが追加・役割の変更・削除できる）
+-- 権限はリクエストごとにこのテーブルを参照して確認するため、変更は次のリクエストから反映される
+
+CREATE TABLE IF NOT EXISTS document_collaborators (
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    added_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (document_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_collaborators_user ON document_collaborators(user_id);
+
+COMMENT ON TABLE document_collaborators IS '文書の共同編集者（所有者は含まない）';
+COMMENT ON COLUMN document_collaborators.role IS 'editor: 編集できる / viewer: 閲覧のみ';
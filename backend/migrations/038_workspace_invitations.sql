@@ -0,0 +1,25 @@
+-- Migration: 038_workspace_invitations.sql
+-- 説明: メールで送るワークスペースへの招待（リンクを開いたユーザーがアカウントの作成・ログイン後に参加する）
+-- 招待リンクのトークンはハッシュ（SHA-256）のみを保存し、メールにだけ含める
+
+CREATE TABLE IF NOT EXISTS workspace_invitations (
+    id SERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'member', 'guest')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_invitations_pending
+    ON workspace_invitations(workspace_id, LOWER(email))
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+COMMENT ON TABLE workspace_invitations IS 'メールで送るワークスペースへの招待';
+COMMENT ON COLUMN workspace_invitations.token_hash IS '招待リンクのトークンの SHA-256（16進数）';
+COMMENT ON COLUMN workspace_invitations.revoked_at IS '取り消した日時（同じメールアドレスを招待し直した場合も設定する）';
@@ -0,0 +1,5 @@
+-- Migration: 039_workspace_storage_quota.sql
+-- 説明: ワークスペースごとのストレージクォータ（管理者が PUT /api/admin/workspaces/{id}/storage-quota で変更する）
+-- NULL の場合は設定（WORKSPACE_STORAGE_QUOTA）の値を既定値として使う。使用量はメンバー全員のファイルの合計
+
+ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS storage_quota BIGINT CHECK (storage_quota >= 0);
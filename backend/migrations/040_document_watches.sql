@@ -0,0 +1,22 @@
+-- Migration: 040_document_watches.sql
+-- 説明: 文書の変更の購読（変更されるとアプリ内通知、またはまとめたメールで知らせる）
+-- 通知する時点で文書にアクセスできない（共同編集者から外れた）ユーザーには送らない
+
+CREATE TABLE IF NOT EXISTS document_watches (
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delivery VARCHAR(20) NOT NULL DEFAULT 'in_app' CHECK (delivery IN ('in_app', 'email_digest')),
+    last_changed_at TIMESTAMP,
+    digested_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (document_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_watches_user ON document_watches(user_id);
+CREATE INDEX IF NOT EXISTS idx_document_watches_digest ON document_watches(last_changed_at)
+    WHERE delivery = 'email_digest' AND last_changed_at IS NOT NULL;
+
+COMMENT ON TABLE document_watches IS '文書の変更の購読';
+COMMENT ON COLUMN document_watches.delivery IS 'in_app: 変更ごとにアプリ内通知 / email_digest: 変更をまとめて定期的にメールで送る';
+COMMENT ON COLUMN document_watches.last_changed_at IS '購読したユーザー以外が最後に変更した日時（email_digest のみ記録）';
+COMMENT ON COLUMN document_watches.digested_at IS 'この日時までの変更をメールで送った';
@@ -0,0 +1,24 @@
+-- Migration: 041_document_snapshots.sql
+-- 説明: 作成時点の文書の内容を固定した共有リンク（元の文書を編集しても共有した内容は変わらない）
+-- 画像・ファイルブロックのファイルはスナップショット用に複製し、元のブロックからファイルを外しても残るようにする
+
+CREATE TABLE IF NOT EXISTS document_snapshots (
+    id SERIAL PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    blocks JSONB NOT NULL DEFAULT '[]',
+    file_ids INTEGER[] NOT NULL DEFAULT '{}',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_snapshots_document ON document_snapshots(document_id);
+
+ALTER TABLE document_share_links
+    ADD COLUMN IF NOT EXISTS snapshot_id INTEGER REFERENCES document_snapshots(id) ON DELETE CASCADE;
+
+COMMENT ON TABLE document_snapshots IS '共有リンクで公開する、作成時点で固定した文書の内容';
+COMMENT ON COLUMN document_snapshots.blocks IS '作成時点のブロック（同期ブロックは同期元の内容を含めて固定する）';
+COMMENT ON COLUMN document_snapshots.file_ids IS 'スナップショット用に複製したファイル';
+COMMENT ON COLUMN document_share_links.snapshot_id IS '固定した内容を公開するスナップショット（NULL の場合は最新の文書を公開する）';
@@ -0,0 +1,47 @@
+-- Migration: 042_webhooks.sql
+-- 説明: ユーザーが登録した URL へのイベントの送信（Webhook）と、その配信の記録
+-- 配信はイベントの発生時に記録し、バックグラウンドで送信する（失敗した場合は間隔を倍にしながら再試行する）
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    events TEXT[] NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_user ON webhooks(user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id VARCHAR(36) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_attempt_at TIMESTAMP,
+    response_status INTEGER,
+    response_body TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC, id DESC);
+
+COMMENT ON TABLE webhooks IS 'ユーザーが登録した、イベントを送る URL';
+COMMENT ON COLUMN webhooks.secret IS '送信する内容の HMAC-SHA256 署名の鍵（署名に使うため平文で保存する）';
+COMMENT ON COLUMN webhooks.events IS '送るイベントの種類（document.created など）';
+COMMENT ON TABLE webhook_deliveries IS '1つのイベントを1つの Webhook に送る配信と、最後の試行の記録';
+COMMENT ON COLUMN webhook_deliveries.payload IS '送信する JSON（再送しても同じ内容を送る）';
+COMMENT ON COLUMN webhook_deliveries.next_attempt_at IS '次に送信する日時（送信中は他のプロセスが重ねて送らないよう先に延ばす）';
+COMMENT ON COLUMN webhook_deliveries.response_body IS '応答の本文の先頭（最大 1KB）';
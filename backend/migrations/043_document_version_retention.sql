@@ -0,0 +1,4 @@
+-- Migration: 043_document_version_retention.sql
+-- 説明: 古い文書のバージョンの間引き・削除（VersionService.PruneVersions）で作成日時から検索するためのインデックス
+
+CREATE INDEX IF NOT EXISTS idx_document_versions_created_at ON document_versions(created_at);
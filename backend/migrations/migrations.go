@@ -0,0 +1,54 @@
+// Package migrations は DB のマイグレーション（PostgreSQL の初期化時に docker-entrypoint-initdb.d から適用する SQL）を埋め込みます
+// アプリケーションからは、適用済みかどうかの確認（/readyz）に使う
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// createTablePattern は CREATE TABLE 文で作成するテーブル名を取り出します
+var createTablePattern = regexp.MustCompile(`(?i)\bCREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([a-z_][a-z0-9_]*)`)
+
+// Tables は 全てのマイグレーションを適用した場合に存在するテーブルの一覧を返します（名前順）
+func Tables() ([]string, error) {
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		content, err := files.ReadFile(entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		for _, match := range createTablePattern.FindAllStringSubmatch(stripComments(string(content)), -1) {
+			seen[strings.ToLower(match[1])] = true
+		}
+	}
+
+	tables := make([]string, 0, len(seen))
+	for table := range seen {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// stripComments は 行コメント（--）を取り除きます（コメント内の CREATE TABLE を数えない）
+func stripComments(sql string) string {
+	lines := strings.Split(sql, "\n")
+	for i, line := range lines {
+		if idx := strings.Index(line, "--"); idx >= 0 {
+			lines[i] = line[:idx]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
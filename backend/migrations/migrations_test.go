@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"testing"
+)
+
+// TestTables - マイグレーションで作成するテーブルの一覧のテスト
+func TestTables(t *testing.T) {
+	tables, err := Tables()
+	if err != nil {
+		t.Fatalf("Tables() error = %v", err)
+	}
+
+	found := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		found[table] = true
+	}
+	for _, want := range []string{"users", "documents", "blocks", "webhooks", "webhook_deliveries"} {
+		if !found[want] {
+			t.Errorf("Tables() = %v, want to include %q", tables, want)
+		}
+	}
+}
+
+// TestStripComments - 行コメントの除去のテスト
+func TestStripComments(t *testing.T) {
+	sql := "-- CREATE TABLE old_table\nCREATE TABLE users (id INT); -- CREATE TABLE other\n"
+	got := createTablePattern.FindAllStringSubmatch(stripComments(sql), -1)
+	if len(got) != 1 || got[0][1] != "users" {
+		t.Errorf("tables = %v, want only users", got)
+	}
+}
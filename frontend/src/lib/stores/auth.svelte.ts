@@ -17,12 +17,13 @@ class AuthStore {
 
   /**
    * エラーレスポンスをパースする
+   * エラーは application/problem+json（RFC 7807）で返るため、+json も JSON として扱う
    * JSONパースに失敗した場合はテキストをそのまま返す
    */
   private async parseErrorResponse(response: Response): Promise<string> {
     try {
       const contentType = response.headers.get('content-type')
-      if (contentType && contentType.includes('application/json')) {
+      if (contentType && /application\/(.+\+)?json/.test(contentType)) {
         const error = await response.json()
         return error.error || error.message || response.statusText
       }
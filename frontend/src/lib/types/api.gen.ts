@@ -0,0 +1,858 @@
+// Code generated by sdkgen from the OpenAPI spec. DO NOT EDIT.
+
+export interface ApiError {
+  detail: string
+  details?: Record<string, unknown>
+  error: string
+  instance?: string
+  message: string
+  request_id?: string
+  status: number
+  title: string
+  type: string
+}
+
+export interface Operations {
+  postAdminBlockTypes: {
+    method: 'POST'
+    path: '/api/admin/block-types'
+    params: Record<string, never>
+    authenticated: true
+  }
+  deleteAdminBlockTypesByName: {
+    method: 'DELETE'
+    path: '/api/admin/block-types/{name}'
+    params: { name: string }
+    authenticated: true
+  }
+  putAdminBlockTypesByName: {
+    method: 'PUT'
+    path: '/api/admin/block-types/{name}'
+    params: { name: string }
+    authenticated: true
+  }
+  getAdminMaintenanceOrphans: {
+    method: 'GET'
+    path: '/api/admin/maintenance/orphans'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postAdminMaintenanceOrphansRepair: {
+    method: 'POST'
+    path: '/api/admin/maintenance/orphans/repair'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getAdminMaintenanceStorage: {
+    method: 'GET'
+    path: '/api/admin/maintenance/storage'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getAdminStats: {
+    method: 'GET'
+    path: '/api/admin/stats'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getAdminStorageUsage: {
+    method: 'GET'
+    path: '/api/admin/storage/usage'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getAdminUsersByIdStorageQuota: {
+    method: 'GET'
+    path: '/api/admin/users/{id}/storage-quota'
+    params: { id: number }
+    authenticated: true
+  }
+  putAdminUsersByIdStorageQuota: {
+    method: 'PUT'
+    path: '/api/admin/users/{id}/storage-quota'
+    params: { id: number }
+    authenticated: true
+  }
+  getAdminWorkspacesByIdStorageQuota: {
+    method: 'GET'
+    path: '/api/admin/workspaces/{id}/storage-quota'
+    params: { id: number }
+    authenticated: true
+  }
+  putAdminWorkspacesByIdStorageQuota: {
+    method: 'PUT'
+    path: '/api/admin/workspaces/{id}/storage-quota'
+    params: { id: number }
+    authenticated: true
+  }
+  postAuthLogin: {
+    method: 'POST'
+    path: '/api/auth/login'
+    params: Record<string, never>
+    authenticated: false
+  }
+  getAuthLoginStatus: {
+    method: 'GET'
+    path: '/api/auth/login-status'
+    params: Record<string, never>
+    authenticated: false
+  }
+  postAuthLogout: {
+    method: 'POST'
+    path: '/api/auth/logout'
+    params: Record<string, never>
+    authenticated: false
+  }
+  getAuthMe: {
+    method: 'GET'
+    path: '/api/auth/me'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postAuthRegister: {
+    method: 'POST'
+    path: '/api/auth/register'
+    params: Record<string, never>
+    authenticated: false
+  }
+  putAuthWorkspace: {
+    method: 'PUT'
+    path: '/api/auth/workspace'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postBatch: {
+    method: 'POST'
+    path: '/api/batch'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getBlockTypes: {
+    method: 'GET'
+    path: '/api/block-types'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postBlocksByIdConvert: {
+    method: 'POST'
+    path: '/api/blocks/{id}/convert'
+    params: { id: number }
+    authenticated: true
+  }
+  getDocuments: {
+    method: 'GET'
+    path: '/api/documents'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postDocuments: {
+    method: 'POST'
+    path: '/api/documents'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getDocumentsEvents: {
+    method: 'GET'
+    path: '/api/documents/events'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getDocumentsSearch: {
+    method: 'GET'
+    path: '/api/documents/search'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getDocumentsTree: {
+    method: 'GET'
+    path: '/api/documents/tree'
+    params: Record<string, never>
+    authenticated: true
+  }
+  deleteDocumentsById: {
+    method: 'DELETE'
+    path: '/api/documents/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  getDocumentsById: {
+    method: 'GET'
+    path: '/api/documents/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  patchDocumentsById: {
+    method: 'PATCH'
+    path: '/api/documents/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  putDocumentsById: {
+    method: 'PUT'
+    path: '/api/documents/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  postDocumentsByIdBlocks: {
+    method: 'POST'
+    path: '/api/documents/{id}/blocks'
+    params: { id: number }
+    authenticated: true
+  }
+  postDocumentsByIdBlocksCopy: {
+    method: 'POST'
+    path: '/api/documents/{id}/blocks/copy'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteDocumentsByIdBlocksByBlockId: {
+    method: 'DELETE'
+    path: '/api/documents/{id}/blocks/{blockId}'
+    params: { id: number; blockId: number }
+    authenticated: true
+  }
+  patchDocumentsByIdBlocksByBlockId: {
+    method: 'PATCH'
+    path: '/api/documents/{id}/blocks/{blockId}'
+    params: { id: number; blockId: number }
+    authenticated: true
+  }
+  putDocumentsByIdBlocksByBlockIdCollapsed: {
+    method: 'PUT'
+    path: '/api/documents/{id}/blocks/{blockId}/collapsed'
+    params: { id: number; blockId: number }
+    authenticated: true
+  }
+  postDocumentsByIdBlocksByBlockIdComments: {
+    method: 'POST'
+    path: '/api/documents/{id}/blocks/{blockId}/comments'
+    params: { id: number; blockId: number }
+    authenticated: true
+  }
+  postDocumentsByIdBlocksByBlockIdDuplicate: {
+    method: 'POST'
+    path: '/api/documents/{id}/blocks/{blockId}/duplicate'
+    params: { id: number; blockId: number }
+    authenticated: true
+  }
+  getDocumentsByIdBlocksByBlockIdHistory: {
+    method: 'GET'
+    path: '/api/documents/{id}/blocks/{blockId}/history'
+    params: { id: number; blockId: number }
+    authenticated: true
+  }
+  postDocumentsByIdBlocksByBlockIdPreview: {
+    method: 'POST'
+    path: '/api/documents/{id}/blocks/{blockId}/preview'
+    params: { id: number; blockId: number }
+    authenticated: true
+  }
+  postDocumentsByIdBlocksByBlockIdSync: {
+    method: 'POST'
+    path: '/api/documents/{id}/blocks/{blockId}/sync'
+    params: { id: number; blockId: number }
+    authenticated: true
+  }
+  getDocumentsByIdCollaborators: {
+    method: 'GET'
+    path: '/api/documents/{id}/collaborators'
+    params: { id: number }
+    authenticated: true
+  }
+  postDocumentsByIdCollaborators: {
+    method: 'POST'
+    path: '/api/documents/{id}/collaborators'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteDocumentsByIdCollaboratorsByUserId: {
+    method: 'DELETE'
+    path: '/api/documents/{id}/collaborators/{userId}'
+    params: { id: number; userId: number }
+    authenticated: true
+  }
+  patchDocumentsByIdCollaboratorsByUserId: {
+    method: 'PATCH'
+    path: '/api/documents/{id}/collaborators/{userId}'
+    params: { id: number; userId: number }
+    authenticated: true
+  }
+  getDocumentsByIdComments: {
+    method: 'GET'
+    path: '/api/documents/{id}/comments'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteDocumentsByIdCommentsByCommentId: {
+    method: 'DELETE'
+    path: '/api/documents/{id}/comments/{commentId}'
+    params: { id: number; commentId: number }
+    authenticated: true
+  }
+  patchDocumentsByIdCommentsByCommentId: {
+    method: 'PATCH'
+    path: '/api/documents/{id}/comments/{commentId}'
+    params: { id: number; commentId: number }
+    authenticated: true
+  }
+  putDocumentsByIdCursor: {
+    method: 'PUT'
+    path: '/api/documents/{id}/cursor'
+    params: { id: number }
+    authenticated: true
+  }
+  getDocumentsByIdDiff: {
+    method: 'GET'
+    path: '/api/documents/{id}/diff'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteDocumentsByIdExpiration: {
+    method: 'DELETE'
+    path: '/api/documents/{id}/expiration'
+    params: { id: number }
+    authenticated: true
+  }
+  getDocumentsByIdExpiration: {
+    method: 'GET'
+    path: '/api/documents/{id}/expiration'
+    params: { id: number }
+    authenticated: true
+  }
+  putDocumentsByIdExpiration: {
+    method: 'PUT'
+    path: '/api/documents/{id}/expiration'
+    params: { id: number }
+    authenticated: true
+  }
+  getDocumentsByIdExport: {
+    method: 'GET'
+    path: '/api/documents/{id}/export'
+    params: { id: number }
+    authenticated: true
+  }
+  putDocumentsByIdMove: {
+    method: 'PUT'
+    path: '/api/documents/{id}/move'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteDocumentsByIdPermanent: {
+    method: 'DELETE'
+    path: '/api/documents/{id}/permanent'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteDocumentsByIdPresence: {
+    method: 'DELETE'
+    path: '/api/documents/{id}/presence'
+    params: { id: number }
+    authenticated: true
+  }
+  getDocumentsByIdPresence: {
+    method: 'GET'
+    path: '/api/documents/{id}/presence'
+    params: { id: number }
+    authenticated: true
+  }
+  putDocumentsByIdPresence: {
+    method: 'PUT'
+    path: '/api/documents/{id}/presence'
+    params: { id: number }
+    authenticated: true
+  }
+  getDocumentsByIdPublication: {
+    method: 'GET'
+    path: '/api/documents/{id}/publication'
+    params: { id: number }
+    authenticated: true
+  }
+  putDocumentsByIdPublication: {
+    method: 'PUT'
+    path: '/api/documents/{id}/publication'
+    params: { id: number }
+    authenticated: true
+  }
+  postDocumentsByIdReminders: {
+    method: 'POST'
+    path: '/api/documents/{id}/reminders'
+    params: { id: number }
+    authenticated: true
+  }
+  putDocumentsByIdRestore: {
+    method: 'PUT'
+    path: '/api/documents/{id}/restore'
+    params: { id: number }
+    authenticated: true
+  }
+  getDocumentsByIdShareLinks: {
+    method: 'GET'
+    path: '/api/documents/{id}/share-links'
+    params: { id: number }
+    authenticated: true
+  }
+  postDocumentsByIdShareLinks: {
+    method: 'POST'
+    path: '/api/documents/{id}/share-links'
+    params: { id: number }
+    authenticated: true
+  }
+  postDocumentsByIdShareLinksSnapshot: {
+    method: 'POST'
+    path: '/api/documents/{id}/share-links/snapshot'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteDocumentsByIdShareLinksByLinkId: {
+    method: 'DELETE'
+    path: '/api/documents/{id}/share-links/{linkId}'
+    params: { id: number; linkId: number }
+    authenticated: true
+  }
+  getDocumentsByIdThreads: {
+    method: 'GET'
+    path: '/api/documents/{id}/threads'
+    params: { id: number }
+    authenticated: true
+  }
+  postDocumentsByIdThreads: {
+    method: 'POST'
+    path: '/api/documents/{id}/threads'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteDocumentsByIdThreadsByCommentId: {
+    method: 'DELETE'
+    path: '/api/documents/{id}/threads/{commentId}'
+    params: { id: number; commentId: number }
+    authenticated: true
+  }
+  patchDocumentsByIdThreadsByCommentId: {
+    method: 'PATCH'
+    path: '/api/documents/{id}/threads/{commentId}'
+    params: { id: number; commentId: number }
+    authenticated: true
+  }
+  postDocumentsByIdThreadsByCommentIdReplies: {
+    method: 'POST'
+    path: '/api/documents/{id}/threads/{commentId}/replies'
+    params: { id: number; commentId: number }
+    authenticated: true
+  }
+  getDocumentsByIdVersions: {
+    method: 'GET'
+    path: '/api/documents/{id}/versions'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteDocumentsByIdWatch: {
+    method: 'DELETE'
+    path: '/api/documents/{id}/watch'
+    params: { id: number }
+    authenticated: true
+  }
+  putDocumentsByIdWatch: {
+    method: 'PUT'
+    path: '/api/documents/{id}/watch'
+    params: { id: number }
+    authenticated: true
+  }
+  getEmbedsProviders: {
+    method: 'GET'
+    path: '/api/embeds/providers'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getEmbedsResolve: {
+    method: 'GET'
+    path: '/api/embeds/resolve'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getFiles: {
+    method: 'GET'
+    path: '/api/files'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getFilesExport: {
+    method: 'GET'
+    path: '/api/files/export'
+    params: Record<string, never>
+    authenticated: true
+  }
+  patchFilesById: {
+    method: 'PATCH'
+    path: '/api/files/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  putFilesByIdAttach: {
+    method: 'PUT'
+    path: '/api/files/{id}/attach'
+    params: { id: number }
+    authenticated: true
+  }
+  putFilesByIdDetach: {
+    method: 'PUT'
+    path: '/api/files/{id}/detach'
+    params: { id: number }
+    authenticated: true
+  }
+  getFilesByIdDownload: {
+    method: 'GET'
+    path: '/api/files/{id}/download'
+    params: { id: number }
+    authenticated: true
+  }
+  getFilesByIdUrl: {
+    method: 'GET'
+    path: '/api/files/{id}/url'
+    params: { id: number }
+    authenticated: true
+  }
+  getFilesByIdVersions: {
+    method: 'GET'
+    path: '/api/files/{id}/versions'
+    params: { id: number }
+    authenticated: true
+  }
+  postFilesByIdVersions: {
+    method: 'POST'
+    path: '/api/files/{id}/versions'
+    params: { id: number }
+    authenticated: true
+  }
+  postFilesByIdVersionsByVersionIdRestore: {
+    method: 'POST'
+    path: '/api/files/{id}/versions/{versionId}/restore'
+    params: { id: number; versionId: number }
+    authenticated: true
+  }
+  getGraphql: {
+    method: 'GET'
+    path: '/api/graphql'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postGraphql: {
+    method: 'POST'
+    path: '/api/graphql'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getHealth: {
+    method: 'GET'
+    path: '/api/health'
+    params: Record<string, never>
+    authenticated: false
+  }
+  getInvitationsByToken: {
+    method: 'GET'
+    path: '/api/invitations/{token}'
+    params: { token: string }
+    authenticated: false
+  }
+  postInvitationsByTokenAccept: {
+    method: 'POST'
+    path: '/api/invitations/{token}/accept'
+    params: { token: string }
+    authenticated: true
+  }
+  postInvitationsByTokenRegister: {
+    method: 'POST'
+    path: '/api/invitations/{token}/register'
+    params: { token: string }
+    authenticated: false
+  }
+  getNotifications: {
+    method: 'GET'
+    path: '/api/notifications'
+    params: Record<string, never>
+    authenticated: true
+  }
+  putNotificationsByIdRead: {
+    method: 'PUT'
+    path: '/api/notifications/{id}/read'
+    params: { id: number }
+    authenticated: true
+  }
+  getOpenapiJson: {
+    method: 'GET'
+    path: '/api/openapi.json'
+    params: Record<string, never>
+    authenticated: false
+  }
+  getPublicDocumentsById: {
+    method: 'GET'
+    path: '/api/public/documents/{id}'
+    params: { id: number }
+    authenticated: false
+  }
+  getReminders: {
+    method: 'GET'
+    path: '/api/reminders'
+    params: Record<string, never>
+    authenticated: true
+  }
+  putRemindersByIdDismiss: {
+    method: 'PUT'
+    path: '/api/reminders/{id}/dismiss'
+    params: { id: number }
+    authenticated: true
+  }
+  getShareByToken: {
+    method: 'GET'
+    path: '/api/share/{token}'
+    params: { token: string }
+    authenticated: false
+  }
+  getStatus: {
+    method: 'GET'
+    path: '/api/status'
+    params: Record<string, never>
+    authenticated: false
+  }
+  getStorageUsage: {
+    method: 'GET'
+    path: '/api/storage/usage'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getTasks: {
+    method: 'GET'
+    path: '/api/tasks'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postTrashRestore: {
+    method: 'POST'
+    path: '/api/trash/restore'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postUploadAudio: {
+    method: 'POST'
+    path: '/api/upload/audio'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postUploadDirect: {
+    method: 'POST'
+    path: '/api/upload/direct'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postUploadDirectByIdConfirm: {
+    method: 'POST'
+    path: '/api/upload/direct/{id}/confirm'
+    params: { id: string }
+    authenticated: true
+  }
+  postUploadFile: {
+    method: 'POST'
+    path: '/api/upload/file'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postUploadImage: {
+    method: 'POST'
+    path: '/api/upload/image'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getUploadProgressById: {
+    method: 'GET'
+    path: '/api/upload/progress/{id}'
+    params: { id: string }
+    authenticated: true
+  }
+  postUploadSessions: {
+    method: 'POST'
+    path: '/api/upload/sessions'
+    params: Record<string, never>
+    authenticated: true
+  }
+  deleteUploadSessionsById: {
+    method: 'DELETE'
+    path: '/api/upload/sessions/{id}'
+    params: { id: string }
+    authenticated: true
+  }
+  getUploadSessionsById: {
+    method: 'GET'
+    path: '/api/upload/sessions/{id}'
+    params: { id: string }
+    authenticated: true
+  }
+  patchUploadSessionsById: {
+    method: 'PATCH'
+    path: '/api/upload/sessions/{id}'
+    params: { id: string }
+    authenticated: true
+  }
+  postUploadSessionsByIdComplete: {
+    method: 'POST'
+    path: '/api/upload/sessions/{id}/complete'
+    params: { id: string }
+    authenticated: true
+  }
+  postUploadVideo: {
+    method: 'POST'
+    path: '/api/upload/video'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getUploadsByFilename: {
+    method: 'GET'
+    path: '/api/uploads/{filename}'
+    params: { filename: string }
+    authenticated: false
+  }
+  getWatches: {
+    method: 'GET'
+    path: '/api/watches'
+    params: Record<string, never>
+    authenticated: true
+  }
+  getWebhooks: {
+    method: 'GET'
+    path: '/api/webhooks'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postWebhooks: {
+    method: 'POST'
+    path: '/api/webhooks'
+    params: Record<string, never>
+    authenticated: true
+  }
+  deleteWebhooksById: {
+    method: 'DELETE'
+    path: '/api/webhooks/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  getWebhooksById: {
+    method: 'GET'
+    path: '/api/webhooks/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  patchWebhooksById: {
+    method: 'PATCH'
+    path: '/api/webhooks/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  getWebhooksByIdDeliveries: {
+    method: 'GET'
+    path: '/api/webhooks/{id}/deliveries'
+    params: { id: number }
+    authenticated: true
+  }
+  postWebhooksByIdDeliveriesByDeliveryIdRedeliver: {
+    method: 'POST'
+    path: '/api/webhooks/{id}/deliveries/{deliveryId}/redeliver'
+    params: { id: number; deliveryId: number }
+    authenticated: true
+  }
+  postWebhooksByIdSecret: {
+    method: 'POST'
+    path: '/api/webhooks/{id}/secret'
+    params: { id: number }
+    authenticated: true
+  }
+  getWorkspaces: {
+    method: 'GET'
+    path: '/api/workspaces'
+    params: Record<string, never>
+    authenticated: true
+  }
+  postWorkspaces: {
+    method: 'POST'
+    path: '/api/workspaces'
+    params: Record<string, never>
+    authenticated: true
+  }
+  deleteWorkspacesById: {
+    method: 'DELETE'
+    path: '/api/workspaces/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  getWorkspacesById: {
+    method: 'GET'
+    path: '/api/workspaces/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  patchWorkspacesById: {
+    method: 'PATCH'
+    path: '/api/workspaces/{id}'
+    params: { id: number }
+    authenticated: true
+  }
+  getWorkspacesByIdInvitations: {
+    method: 'GET'
+    path: '/api/workspaces/{id}/invitations'
+    params: { id: number }
+    authenticated: true
+  }
+  postWorkspacesByIdInvitations: {
+    method: 'POST'
+    path: '/api/workspaces/{id}/invitations'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteWorkspacesByIdInvitationsByInvitationId: {
+    method: 'DELETE'
+    path: '/api/workspaces/{id}/invitations/{invitationId}'
+    params: { id: number; invitationId: number }
+    authenticated: true
+  }
+  getWorkspacesByIdMembers: {
+    method: 'GET'
+    path: '/api/workspaces/{id}/members'
+    params: { id: number }
+    authenticated: true
+  }
+  postWorkspacesByIdMembers: {
+    method: 'POST'
+    path: '/api/workspaces/{id}/members'
+    params: { id: number }
+    authenticated: true
+  }
+  deleteWorkspacesByIdMembersByUserId: {
+    method: 'DELETE'
+    path: '/api/workspaces/{id}/members/{userId}'
+    params: { id: number; userId: number }
+    authenticated: true
+  }
+  putWorkspacesByIdMembersByUserId: {
+    method: 'PUT'
+    path: '/api/workspaces/{id}/members/{userId}'
+    params: { id: number; userId: number }
+    authenticated: true
+  }
+  getWorkspacesByIdStorage: {
+    method: 'GET'
+    path: '/api/workspaces/{id}/storage'
+    params: { id: number }
+    authenticated: true
+  }
+  getHealthz: {
+    method: 'GET'
+    path: '/healthz'
+    params: Record<string, never>
+    authenticated: false
+  }
+  getReadyz: {
+    method: 'GET'
+    path: '/readyz'
+    params: Record<string, never>
+    authenticated: false
+  }
+}
+
+export type OperationId = keyof Operations